@@ -0,0 +1,198 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/util/gitutil"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/util/result"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// auditLogEnvVar may be set to the path of a file to record audit records to, enabling the audit log for all projects.
+const auditLogEnvVar = "PULUMI_AUDIT_LOG"
+
+// auditedCommands are the commands that change a stack's state, and so are recorded in the audit log.
+var auditedCommands = map[string]bool{
+	"cancel":                        true,
+	"config rotate":                 true,
+	"destroy":                       true,
+	"import":                        true,
+	"refresh":                       true,
+	"stack change-secrets-provider": true,
+	"stack import":                  true,
+	"stack migrate":                 true,
+	"stack rename":                  true,
+	"stack rm":                      true,
+	"stack rollback":                true,
+	"stack rotate-secrets-key":      true,
+	"state delete":                  true,
+	"state encrypt":                 true,
+	"state import":                  true,
+	"state lock":                    true,
+	"state move":                    true,
+	"state rename":                  true,
+	"state unlock":                  true,
+	"state unprotect":               true,
+	"up":                            true,
+}
+
+// auditRecord is a single entry in the audit log.
+type auditRecord struct {
+	Time     time.Time `json:"time"`
+	Command  string    `json:"command"`
+	Args     []string  `json:"args,omitempty"`
+	Project  string    `json:"project,omitempty"`
+	Stack    string    `json:"stack,omitempty"`
+	User     string    `json:"user,omitempty"`
+	GitHead  string    `json:"gitHead,omitempty"`
+	Result   string    `json:"result"`
+	Error    string    `json:"error,omitempty"`
+	Duration string    `json:"duration"`
+}
+
+// auditSettings returns where audit records for the given project, which may be nil, go if the audit log is enabled.
+func auditSettings(proj *workspace.Project) (path string, useSyslog bool, enabled bool, err error) {
+	var audit *workspace.ProjectAuditConfig
+	if proj != nil {
+		audit = proj.Audit
+	}
+
+	path = os.Getenv(auditLogEnvVar)
+	if audit == nil && path == "" {
+		return "", false, false, nil
+	}
+	if audit != nil {
+		useSyslog = audit.Syslog
+		if path == "" {
+			path = audit.Path
+		}
+	}
+	if path == "" {
+		if path, err = workspace.GetAuditLogPath(); err != nil {
+			return "", false, false, err
+		}
+	}
+	return path, useSyslog, true, nil
+}
+
+// newAuditRecord creates the audit record for an invocation of cmd in the given project, which may be nil.
+func newAuditRecord(cmd *cobra.Command, args []string, start time.Time, res result.Result,
+	proj *workspace.Project, root string) auditRecord {
+
+	rec := auditRecord{
+		Time:     start.UTC(),
+		Command:  strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" "),
+		Args:     args,
+		Result:   "succeeded",
+		Duration: time.Since(start).Round(time.Millisecond).String(),
+	}
+	if res != nil {
+		rec.Result = "failed"
+		if err := res.Error(); err != nil {
+			rec.Error = err.Error()
+		}
+	}
+
+	if f := cmd.Flag("stack"); f != nil && f.Value.String() != "" {
+		rec.Stack = f.Value.String()
+	} else if w, err := workspace.New(); err == nil {
+		rec.Stack = w.Settings().Stack
+	}
+	if proj != nil {
+		rec.Project = string(proj.Name)
+		if repo, err := gitutil.GetGitRepository(root); err == nil && repo != nil {
+			if head, err := repo.Head(); err == nil {
+				rec.GitHead = head.Hash().String()
+			}
+		}
+	}
+	if u, err := user.Current(); err == nil {
+		rec.User = u.Username
+	}
+	return rec
+}
+
+// appendAuditRecord appends the record to the audit log at path as a single line of JSON.
+func appendAuditRecord(path string, rec auditRecord) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(b); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// recordAudit records an invocation of cmd in the audit log if the command changes a stack or reveals secrets, and the
+// log is enabled.
+// Failing to record is reported as a warning rather than failing the command, which has already run.
+func recordAudit(cmd *cobra.Command, args []string, start time.Time, res result.Result) {
+	// Commands that reveal secrets are recorded as well, so that the disclosure of secrets can be audited.
+	revealsSecrets := false
+	if f := cmd.Flag("show-secrets"); f != nil && f.Changed && f.Value.String() != "false" {
+		revealsSecrets = true
+	}
+	if !auditedCommands[strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")] && !revealsSecrets {
+		return
+	}
+
+	proj, root, err := readProject(pulumiAppProj)
+	if err != nil {
+		proj, root = nil, ""
+	}
+
+	path, useSyslog, enabled, err := auditSettings(proj)
+	if err != nil {
+		logging.Warningf("could not record audit log entry: %v", err)
+		return
+	} else if !enabled {
+		return
+	}
+
+	rec := newAuditRecord(cmd, args, start, res, proj, root)
+	if err = appendAuditRecord(path, rec); err != nil {
+		logging.Warningf("could not record audit log entry in %s: %v", path, err)
+	}
+	if useSyslog {
+		b, err := json.Marshal(rec)
+		if err == nil {
+			err = writeAuditSyslog(string(b))
+		}
+		if err != nil {
+			logging.Warningf("could not forward audit log entry to syslog: %v", err)
+		}
+	}
+}
@@ -11,18 +11,24 @@
 // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 // See the License for the specific language governing permissions and
 // limitations under the License.
+
+// +build !windows
+
 package cmd
 
 import (
-	"github.com/pulumi/pulumi/pkg/secrets/service"
-
-	"github.com/pulumi/pulumi/pkg/backend/httpstate"
-	"github.com/pulumi/pulumi/pkg/secrets"
+	"log/syslog"
 )
 
-func newServiceSecretsManager(s httpstate.Stack) (secrets.Manager, error) {
-	client := s.Backend().(httpstate.Backend).Client()
-	id := s.StackIdentifier()
-
-	return service.NewServiceSecretsManager(client, id)
+// writeAuditSyslog forwards an audit record to the local system log.
+func writeAuditSyslog(msg string) error {
+	w, err := syslog.New(syslog.LOG_NOTICE|syslog.LOG_USER, "pulumi")
+	if err != nil {
+		return err
+	}
+	if err = w.Notice(msg); err != nil {
+		_ = w.Close()
+		return err
+	}
+	return w.Close()
 }
@@ -0,0 +1,26 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build windows
+
+package cmd
+
+import (
+	"github.com/pkg/errors"
+)
+
+// writeAuditSyslog forwards an audit record to the local system log, which Windows does not have.
+func writeAuditSyslog(msg string) error {
+	return errors.New("syslog forwarding is not supported on Windows")
+}
@@ -0,0 +1,113 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/util/result"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestAuditSettings(t *testing.T) {
+	assert.NoError(t, os.Unsetenv(auditLogEnvVar))
+
+	_, _, enabled, err := auditSettings(nil)
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+	_, _, enabled, err = auditSettings(&workspace.Project{})
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+
+	proj := &workspace.Project{Audit: &workspace.ProjectAuditConfig{Path: "/var/log/pulumi.log", Syslog: true}}
+	path, useSyslog, enabled, err := auditSettings(proj)
+	assert.NoError(t, err)
+	assert.True(t, enabled)
+	assert.True(t, useSyslog)
+	assert.Equal(t, "/var/log/pulumi.log", path)
+
+	// The environment enables the log everywhere and overrides the project's path.
+	assert.NoError(t, os.Setenv(auditLogEnvVar, "/tmp/audit.log"))
+	defer os.Unsetenv(auditLogEnvVar)
+	path, _, enabled, err = auditSettings(nil)
+	assert.NoError(t, err)
+	assert.True(t, enabled)
+	assert.Equal(t, "/tmp/audit.log", path)
+	path, _, _, err = auditSettings(proj)
+	assert.NoError(t, err)
+	assert.Equal(t, "/tmp/audit.log", path)
+}
+
+func TestAppendAuditRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "logs", "audit.log")
+
+	root := &cobra.Command{Use: "pulumi"}
+	stack := &cobra.Command{Use: "stack"}
+	rm := &cobra.Command{Use: "rm"}
+	rm.Flags().StringP("stack", "s", "", "")
+	root.AddCommand(stack)
+	stack.AddCommand(rm)
+	assert.NoError(t, rm.ParseFlags([]string{"--stack", "dev"}))
+
+	proj := &workspace.Project{Name: "proj"}
+	start := time.Now()
+	assert.NoError(t, appendAuditRecord(path, newAuditRecord(rm, nil, start, nil, proj, dir)))
+	assert.NoError(t, appendAuditRecord(path,
+		newAuditRecord(rm, []string{"--force"}, start, result.FromError(errors.New("boom")), proj, dir)))
+
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	var records []auditRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec auditRecord
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &rec))
+		records = append(records, rec)
+	}
+	assert.Len(t, records, 2)
+	assert.Equal(t, "stack rm", records[0].Command)
+	assert.Equal(t, "dev", records[0].Stack)
+	assert.Equal(t, "proj", records[0].Project)
+	assert.Equal(t, "succeeded", records[0].Result)
+	assert.Equal(t, "failed", records[1].Result)
+	assert.Equal(t, "boom", records[1].Error)
+	assert.Equal(t, []string{"--force"}, records[1].Args)
+}
+
+func TestAuditedCommandsExist(t *testing.T) {
+	root := NewPulumiCmd()
+	for name := range auditedCommands {
+		found, _, err := root.Find(strings.Fields(name))
+		if assert.NoError(t, err, name) {
+			assert.Equal(t, "pulumi "+name, found.CommandPath())
+		}
+	}
+}
@@ -15,13 +15,16 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/pulumi/pulumi/pkg/util/result"
 
 	"github.com/spf13/cobra"
 
+	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/backend/filestate"
 	"github.com/pulumi/pulumi/pkg/backend/httpstate"
 	"github.com/pulumi/pulumi/pkg/diag/colors"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
@@ -41,7 +44,11 @@ func newCancelCmd() *cobra.Command {
 			"inconsistent state if a resource operation was pending when the update was canceled.\n" +
 			"\n" +
 			"After this command completes successfully, the stack will be ready for further\n" +
-			"updates.",
+			"updates.\n" +
+			"\n" +
+			"For stacks in self-managed backends, this command breaks the lock that an update\n" +
+			"which was interrupted, or whose process exited, left on the stack. It doesn't stop\n" +
+			"an update that is still running.",
 		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
 			// Use the stack provided or, if missing, default to the current one.
 			if len(args) > 0 {
@@ -61,28 +68,37 @@ func newCancelCmd() *cobra.Command {
 				return result.FromError(err)
 			}
 
-			// Ensure that we are targeting the Pulumi cloud.
-			backend, ok := s.Backend().(httpstate.Backend)
-			if !ok {
-				return result.Error("the `cancel` command is not supported for local stacks")
+			// The Pulumi service cancels the update itself; self-managed backends can only break the stack's lock.
+			var cancel func(ctx context.Context, stackRef backend.StackReference) error
+			prompt := "This will irreversibly cancel the currently running update for '%s'!"
+			done := "The currently running update for '%s' has been canceled!"
+			switch b := s.Backend().(type) {
+			case httpstate.Backend:
+				cancel = b.CancelCurrentUpdate
+			case filestate.Backend:
+				cancel = b.CancelCurrentUpdate
+				prompt = "This will break the lock on '%s'; make sure no update to it is still running!"
+				done = "The lock on '%s' has been broken!"
+			default:
+				return result.Error("the `cancel` command is not supported for this backend")
 			}
 
 			// Ensure the user really wants to do this.
+			if !yes && !cmdutil.Interactive() {
+				return result.FromError(errYesRequired("canceling an update"))
+			}
 			stackName := string(s.Ref().Name())
-			prompt := fmt.Sprintf("This will irreversibly cancel the currently running update for '%s'!", stackName)
-			if !yes && !confirmPrompt(prompt, stackName, opts) {
+			if !yes && !confirmPrompt(fmt.Sprintf(prompt, stackName), stackName, opts) {
 				fmt.Println("confirmation declined")
 				return result.Bail()
 			}
 
 			// Cancel the update.
-			if err := backend.CancelCurrentUpdate(commandContext(), s.Ref()); err != nil {
+			if err := cancel(commandContext(), s.Ref()); err != nil {
 				return result.FromError(err)
 			}
 
-			msg := fmt.Sprintf(
-				"%sThe currently running update for '%s' has been canceled!%s",
-				colors.SpecAttention, stackName, colors.Reset)
+			msg := fmt.Sprintf(colors.SpecAttention+done+colors.Reset, stackName)
 			fmt.Println(opts.Color.Colorize(msg))
 
 			return nil
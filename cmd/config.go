@@ -22,6 +22,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	zxcvbn "github.com/nbutton23/zxcvbn-go"
 	"github.com/pkg/errors"
@@ -30,8 +31,11 @@ import (
 
 	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/secrets"
+	"github.com/pulumi/pulumi/pkg/secrets/refs"
 	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 	"github.com/pulumi/pulumi/pkg/workspace"
@@ -39,7 +43,7 @@ import (
 
 func newConfigCmd() *cobra.Command {
 	var stack string
-	var showSecrets bool
+	var showSecrets showSecretsFlag
 	var jsonOut bool
 
 	cmd := &cobra.Command{
@@ -63,9 +67,7 @@ func newConfigCmd() *cobra.Command {
 		}),
 	}
 
-	cmd.Flags().BoolVar(
-		&showSecrets, "show-secrets", false,
-		"Show secret values when listing config instead of displaying blinded values")
+	addShowSecretsFlag(cmd, &showSecrets, "Show secret values when listing config instead of displaying blinded values")
 	cmd.Flags().BoolVarP(
 		&jsonOut, "json", "j", false,
 		"Emit output as JSON")
@@ -80,12 +82,17 @@ func newConfigCmd() *cobra.Command {
 	cmd.AddCommand(newConfigRmCmd(&stack))
 	cmd.AddCommand(newConfigSetCmd(&stack))
 	cmd.AddCommand(newConfigRefreshCmd(&stack))
+	cmd.AddCommand(newConfigRotateCmd(&stack))
+	cmd.AddCommand(newConfigLintCmd(&stack))
+	cmd.AddCommand(newConfigAuditCmd(&stack))
+	cmd.AddCommand(newConfigEnvCmd(&stack))
 
 	return cmd
 }
 
 func newConfigGetCmd(stack *string) *cobra.Command {
 	var jsonOut bool
+	var path bool
 
 	getCmd := &cobra.Command{
 		Use:   "get <key>",
@@ -101,22 +108,27 @@ func newConfigGetCmd(stack *string) *cobra.Command {
 				return err
 			}
 
-			key, err := parseConfigKey(args[0])
+			key, keyPath, err := parseConfigKeyPath(args[0], path)
 			if err != nil {
 				return errors.Wrap(err, "invalid configuration key")
 			}
 
-			return getConfig(s, key, jsonOut)
+			return getConfig(s, key, keyPath, jsonOut)
 		}),
 	}
 	getCmd.Flags().BoolVarP(
 		&jsonOut, "json", "j", false,
 		"Emit output as JSON")
+	getCmd.Flags().BoolVar(
+		&path, "path", false,
+		"The key contains a path to a value within a structured value, e.g. 'vpc.subnets[0]'")
 
 	return getCmd
 }
 
 func newConfigRmCmd(stack *string) *cobra.Command {
+	var path bool
+
 	rmCmd := &cobra.Command{
 		Use:   "rm <key>",
 		Short: "Remove configuration value",
@@ -131,7 +143,7 @@ func newConfigRmCmd(stack *string) *cobra.Command {
 				return err
 			}
 
-			key, err := parseConfigKey(args[0])
+			key, keyPath, err := parseConfigKeyPath(args[0], path)
 			if err != nil {
 				return errors.Wrap(err, "invalid configuration key")
 			}
@@ -142,12 +154,20 @@ func newConfigRmCmd(stack *string) *cobra.Command {
 			}
 
 			if ps.Config != nil {
-				delete(ps.Config, key)
+				if err = ps.Config.Remove(key, keyPath); err != nil {
+					return err
+				}
+			}
+			if !ps.Config[key].Secure() {
+				ps.ForgetSecret(key)
 			}
 
 			return saveProjectStack(s, ps)
 		}),
 	}
+	rmCmd.PersistentFlags().BoolVar(
+		&path, "path", false,
+		"The key contains a path to a value within a structured value, e.g. 'vpc.subnets[0]'")
 
 	return rmCmd
 }
@@ -226,13 +246,17 @@ func newConfigRefreshCmd(stack *string) *cobra.Command {
 func newConfigSetCmd(stack *string) *cobra.Command {
 	var plaintext bool
 	var secret bool
+	var path bool
 
 	setCmd := &cobra.Command{
 		Use:   "set <key> [value]",
 		Short: "Set configuration value",
 		Long: "Configuration values can be accessed when a stack is being deployed and used to configure behavior. \n" +
 			"If a value is not present on the command line, pulumi will prompt for the value. Multi-line values\n" +
-			"may be set by piping a file to standard in.",
+			"may be set by piping a file to standard in.\n" +
+			"\n" +
+			"With --path, the key is a path to a value within a structured value, e.g. 'vpc.subnets[0]', and the\n" +
+			"maps and lists along the path are created as needed.",
 		Args: cmdutil.RangeArgs(1, 2),
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			opts := display.Options{
@@ -245,7 +269,7 @@ func newConfigSetCmd(stack *string) *cobra.Command {
 				return err
 			}
 
-			key, err := parseConfigKey(args[0])
+			key, keyPath, err := parseConfigKeyPath(args[0], path)
 			if err != nil {
 				return errors.Wrap(err, "invalid configuration key")
 			}
@@ -260,6 +284,8 @@ func newConfigSetCmd(stack *string) *cobra.Command {
 					return readerr
 				}
 				value = cmdutil.RemoveTrailingNewline(string(b))
+			case !cmdutil.Interactive():
+				return cmdutil.NonInteractiveError("a value is required", "pass it as an argument or on stdin")
 			case secret:
 				value, err = cmdutil.ReadConsoleNoEcho("value")
 				if err != nil {
@@ -279,7 +305,7 @@ func newConfigSetCmd(stack *string) *cobra.Command {
 				if cerr != nil {
 					return cerr
 				}
-				enc, eerr := c.EncryptValue(value)
+				enc, eerr := config.EncryptValueAt(c, value, config.ValueLocation{ConfigKey: key.String()})
 				if eerr != nil {
 					return eerr
 				}
@@ -301,7 +327,14 @@ func newConfigSetCmd(stack *string) *cobra.Command {
 				return err
 			}
 
-			ps.Config[key] = v
+			if err = ps.Config.Set(key, keyPath, v); err != nil {
+				return err
+			}
+			if secret {
+				ps.MarkSecretRotated(key, time.Now())
+			} else if !ps.Config[key].Secure() {
+				ps.ForgetSecret(key)
+			}
 
 			return saveProjectStack(s, ps)
 		}),
@@ -313,6 +346,9 @@ func newConfigSetCmd(stack *string) *cobra.Command {
 	setCmd.PersistentFlags().BoolVar(
 		&secret, "secret", false,
 		"Encrypt the value instead of storing it in plaintext")
+	setCmd.PersistentFlags().BoolVar(
+		&path, "path", false,
+		"The key contains a path to a value within a structured value, e.g. 'vpc.subnets[0]'")
 
 	return setCmd
 }
@@ -355,6 +391,29 @@ func parseConfigKey(key string) (config.Key, error) {
 	return config.ParseKey(key)
 }
 
+// parseConfigKeyPath parses a configuration key. If path is true, the key's name is a property path, such as
+// 'vpc.subnets[0]', whose first element is the name of the key and whose remaining elements are returned as the path
+// to a value within the key's structured value.
+func parseConfigKeyPath(key string, path bool) (config.Key, []interface{}, error) {
+	k, err := parseConfigKey(key)
+	if err != nil || !path {
+		return k, nil, err
+	}
+
+	p, err := resource.ParsePropertyPath(k.Name())
+	if err != nil {
+		return config.Key{}, nil, err
+	}
+	if len(p) == 0 {
+		return config.Key{}, nil, errors.New("empty path")
+	}
+	name, ok := p[0].(string)
+	if !ok {
+		return config.Key{}, nil, errors.New("the first element of a path must be the name of a key")
+	}
+	return config.MustMakeKey(k.Namespace(), name), p[1:], nil
+}
+
 func prettyKey(k config.Key) string {
 	proj, err := workspace.DetectProject()
 	if err != nil {
@@ -378,25 +437,43 @@ type configValueJSON struct {
 	// When the value is encrypted and --show-secrets was not passed, the value will not be set.
 	Value  *string `json:"value,omitempty"`
 	Secret bool    `json:"secret"`
+	// When the value is a structured value, ObjectValue is its maps and lists, unless it contains secrets and
+	// --show-secrets was not passed.
+	ObjectValue interface{} `json:"objectValue,omitempty"`
 }
 
-func listConfig(stack backend.Stack, showSecrets bool, jsonOut bool) error {
+func listConfig(stack backend.Stack, showSecrets showSecretsFlag, jsonOut bool) error {
 	ps, err := loadProjectStack(stack)
 	if err != nil {
 		return err
 	}
 
-	cfg := ps.Config
+	// Show the values the stack inherits from its project and environments, too.
+	layers, err := getStackConfigLayers(stack, ps)
+	if err != nil {
+		return err
+	}
+	cfg := layers.Merge()
 
 	// By default, we will use a blinding decrypter to show "[secret]". If requested, display secrets in plaintext.
-	decrypter := config.NewBlindingDecrypter()
-	if cfg.HasSecureValue() && showSecrets {
+	blinding := config.NewBlindingDecrypter()
+	decrypter := blinding
+	if cfg.HasSecureValue() && showSecrets.any() {
+		if err = showSecrets.record(stack, "config", 0); err != nil {
+			return err
+		}
 		dec, decerr := getStackDencrypter(stack)
 		if decerr != nil {
 			return decerr
 		}
 		decrypter = dec
 	}
+	decrypterFor := func(key config.Key) config.Decrypter {
+		if showSecrets.revealsConfig(key) {
+			return decrypter
+		}
+		return blinding
+	}
 
 	var keys config.KeyArray
 	for key := range cfg {
@@ -413,7 +490,7 @@ func listConfig(stack backend.Stack, showSecrets bool, jsonOut bool) error {
 				Secret: cfg[key].Secure(),
 			}
 
-			decrypted, err := cfg[key].Value(decrypter)
+			decrypted, err := cfg[key].Value(decrypterFor(key))
 			if err != nil {
 				return errors.Wrap(err, "could not decrypt configuration value")
 			}
@@ -422,8 +499,12 @@ func listConfig(stack backend.Stack, showSecrets bool, jsonOut bool) error {
 			// If the value was a secret value and we aren't showing secrets, then the above would have set value
 			// to "[secret]" which is reasonable when printing for human display, but for our JSON output, we'd rather
 			// just elide the value.
-			if cfg[key].Secure() && !showSecrets {
+			if cfg[key].Secure() && !showSecrets.revealsConfig(key) {
 				entry.Value = nil
+			} else if cfg[key].Object() {
+				if entry.ObjectValue, err = parseObjectValue(decrypted); err != nil {
+					return err
+				}
 			}
 
 			configValues[key.String()] = entry
@@ -436,7 +517,7 @@ func listConfig(stack backend.Stack, showSecrets bool, jsonOut bool) error {
 	} else {
 		rows := []cmdutil.TableRow{}
 		for _, key := range keys {
-			decrypted, err := cfg[key].Value(decrypter)
+			decrypted, err := cfg[key].Value(decrypterFor(key))
 			if err != nil {
 				return errors.Wrap(err, "could not decrypt configuration value")
 			}
@@ -453,49 +534,94 @@ func listConfig(stack backend.Stack, showSecrets bool, jsonOut bool) error {
 	return nil
 }
 
-func getConfig(stack backend.Stack, key config.Key, jsonOut bool) error {
+func getConfig(stack backend.Stack, key config.Key, path []interface{}, jsonOut bool) error {
 	ps, err := loadProjectStack(stack)
 	if err != nil {
 		return err
 	}
 
-	cfg := ps.Config
+	layers, err := getStackConfigLayers(stack, ps)
+	if err != nil {
+		return err
+	}
+	v, ok, err := layers.Merge().Get(key, path)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.Errorf(
+			"configuration key '%s' not found for stack '%s'", prettyKeyPath(key, path), stack.Ref())
+	}
 
-	if v, ok := cfg[key]; ok {
-		var d config.Decrypter
-		if v.Secure() {
-			var err error
-			if d, err = getStackDencrypter(stack); err != nil {
-				return errors.Wrap(err, "could not create a decrypter")
-			}
-		} else {
-			d = config.NewPanicCrypter()
+	var d config.Decrypter
+	if v.Secure() {
+		if d, err = getStackDencrypter(stack); err != nil {
+			return errors.Wrap(err, "could not create a decrypter")
 		}
-		raw, err := v.Value(d)
-		if err != nil {
-			return errors.Wrap(err, "could not decrypt configuration value")
+	} else {
+		d = config.NewPanicCrypter()
+	}
+	raw, err := v.Value(d)
+	if err != nil {
+		return errors.Wrap(err, "could not decrypt configuration value")
+	}
+
+	var obj interface{}
+	if v.Object() {
+		if obj, err = parseObjectValue(raw); err != nil {
+			return err
 		}
+	}
 
-		if jsonOut {
-			value := configValueJSON{
-				Value:  &raw,
-				Secret: v.Secure(),
-			}
+	switch {
+	case jsonOut:
+		value := configValueJSON{
+			Value:       &raw,
+			Secret:      v.Secure(),
+			ObjectValue: obj,
+		}
 
-			out, err := json.MarshalIndent(value, "", "  ")
-			if err != nil {
-				return err
-			}
-			fmt.Println(string(out))
-		} else {
-			fmt.Printf("%v\n", raw)
+		out, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return err
 		}
+		fmt.Println(string(out))
+	case v.Object():
+		out, err := json.MarshalIndent(obj, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+	default:
+		fmt.Printf("%v\n", raw)
+	}
 
-		return nil
+	return nil
+}
+
+// parseObjectValue parses the JSON representation of a structured configuration value.
+func parseObjectValue(raw string) (interface{}, error) {
+	dec := json.NewDecoder(strings.NewReader(raw))
+	dec.UseNumber()
+	var obj interface{}
+	if err := dec.Decode(&obj); err != nil {
+		return nil, errors.Wrap(err, "could not parse structured configuration value")
 	}
+	return obj, nil
+}
 
-	return errors.Errorf(
-		"configuration key '%s' not found for stack '%s'", prettyKey(key), stack.Ref())
+// prettyKeyPath formats a configuration key and a path within its value, as accepted by --path.
+func prettyKeyPath(k config.Key, path []interface{}) string {
+	s := prettyKey(k)
+	for _, elem := range path {
+		switch elem := elem.(type) {
+		case int:
+			s += fmt.Sprintf("[%d]", elem)
+		default:
+			s += fmt.Sprintf(".%v", elem)
+		}
+	}
+	return s
 }
 
 var (
@@ -517,7 +643,8 @@ const (
 // that suffers from false positives, but is better (a) than our prior approach of unconditionally printing a warning
 // for all plaintext values, and (b)  to be paranoid about such things. Inspired by the gas linter and securego project.
 func looksLikeSecret(k config.Key, v string) bool {
-	if !keyPattern.MatchString(k.Name()) {
+	// References to external secrets are safe to store in plaintext, since they are resolved at deployment time.
+	if !keyPattern.MatchString(k.Name()) || refs.IsReference(v) {
 		return false
 	}
 
@@ -532,6 +659,22 @@ func looksLikeSecret(k config.Key, v string) bool {
 		(info.Entropy >= (entropyThreshold/2) && entropyPerChar >= entropyPerCharThreshold))
 }
 
+// getStackConfigLayers returns the layers of the given stack's configuration, whose settings are ps. If the stack's
+// project cannot be found, the stack's own configuration is the only layer.
+func getStackConfigLayers(stack backend.Stack, ps *workspace.ProjectStack) (config.Layers, error) {
+	proj, projPath, err := workspace.DetectProjectAndPath()
+	if err != nil {
+		return config.Layers{{Name: "stack configuration", Config: ps.Config}}, nil
+	}
+	stackPath := stackConfigFile
+	if stackPath == "" {
+		if stackPath, err = workspace.ProjectStackPath(proj, projPath, stack.Ref().Name()); err != nil {
+			return nil, err
+		}
+	}
+	return proj.StackConfigLayers(projPath, ps, stackPath)
+}
+
 // getStackConfiguration loads configuration information for a given stack. If stackConfigFile is non empty,
 // it is uses instead of the default configuration file for the stack
 func getStackConfiguration(stack backend.Stack, sm secrets.Manager) (backend.StackConfiguration, error) {
@@ -540,23 +683,38 @@ func getStackConfiguration(stack backend.Stack, sm secrets.Manager) (backend.Sta
 		return backend.StackConfiguration{}, errors.Wrap(err, "loading stack configuration")
 	}
 
-	// If there are no secrets in the configuration, we should never use the decrypter, so it is safe to return
-	// one which panics if it is used. This provides for some nice UX in the common case (since, for example, building
-	// the correct decrypter for the local backend would involve prompting for a passphrase)
-	if !workspaceStack.Config.HasSecureValue() {
-		return backend.StackConfiguration{
-			Config:    workspaceStack.Config,
-			Decrypter: config.NewPanicCrypter(),
-		}, nil
+	// Stacks inherit the project's config values and defaults, and those of their environments, for any keys they do
+	// not set themselves.
+	layers, err := getStackConfigLayers(stack, workspaceStack)
+	if err != nil {
+		return backend.StackConfiguration{}, errors.Wrap(err, "loading stack configuration")
 	}
+	cfg := layers.Merge()
 
-	crypter, err := sm.Decrypter()
-	if err != nil {
-		return backend.StackConfiguration{}, errors.Wrap(err, "getting configuration decrypter")
+	// If there are no secrets in the configuration, we should never use the decrypter, so it is safe to use one
+	// which panics if it is used. This provides for some nice UX in the common case (since, for example, building
+	// the correct decrypter for the local backend would involve prompting for a passphrase)
+	var crypter config.Decrypter = config.NewPanicCrypter()
+	if workspaceStack.Config.HasSecureValue() {
+		if crypter, err = sm.Decrypter(); err != nil {
+			return backend.StackConfiguration{}, errors.Wrap(err, "getting configuration decrypter")
+		}
 	}
 
+	// References to external secrets are resolved as they are decrypted, and each resolution is reported so that
+	// accesses to external secrets can be audited.
+	crypter = refs.NewDecrypter(crypter, func(ref string) {
+		cmdutil.Diag().Infoerrf(diag.Message("", "resolved external secret %s"), ref)
+	})
+	recordCrashConfig(cfg)
+
 	return backend.StackConfiguration{
-		Config:    workspaceStack.Config,
-		Decrypter: crypter,
+		Config:          refs.ReferenceConfig(cfg),
+		Decrypter:       crypter,
+		Transformations: workspaceStack.Transformations,
+		NamePrefix:      workspaceStack.NamePrefix,
+		NameSuffix:      workspaceStack.NameSuffix,
+		Confirmation:    workspaceStack.Confirmation,
+		Guardrails:      workspaceStack.Guardrails,
 	}, nil
 }
@@ -0,0 +1,219 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// configAuditSecret describes a single secret in a stack's configuration.
+type configAuditSecret struct {
+	Key string `json:"key"`
+	// Rotated is when the secret was last set or rotated, if known.
+	Rotated *time.Time `json:"rotated,omitempty"`
+	// Used is true if the last deployment's resources contain the secret's value. It is nil if the stack has never
+	// been deployed.
+	Used *bool `json:"used,omitempty"`
+}
+
+// configAudit is the result of auditing a stack's configuration.
+type configAudit struct {
+	Secrets []configAuditSecret `json:"secrets"`
+	// Plaintext lists the keys of plaintext values that look like secrets.
+	Plaintext []string `json:"plaintext"`
+}
+
+// auditConfig reports on the secrets in the given stack configuration. A secret is considered used if its value
+// appears in the inputs or outputs of any resource in the given deployment.
+func auditConfig(proj *workspace.Project, ps *workspace.ProjectStack, decrypter config.Decrypter,
+	snap *deploy.Snapshot) (configAudit, error) {
+
+	audit := configAudit{Secrets: []configAuditSecret{}, Plaintext: []string{}}
+	for k, v := range ps.Config {
+		value, err := v.Value(decrypter)
+		if err != nil {
+			return configAudit{}, err
+		}
+
+		if !v.Secure() {
+			if looksLikeSecret(k, value) {
+				audit.Plaintext = append(audit.Plaintext, prettyKeyForProject(k, proj))
+			}
+			continue
+		}
+
+		secret := configAuditSecret{Key: prettyKeyForProject(k, proj)}
+		if md := ps.SecretMetadata[k.String()]; md != nil {
+			rotated := md.Rotated
+			secret.Rotated = &rotated
+		}
+		if snap != nil {
+			used := false
+			for _, res := range snap.Resources {
+				if propertyValueContains(resource.NewObjectProperty(res.Inputs), value) ||
+					propertyValueContains(resource.NewObjectProperty(res.Outputs), value) {
+					used = true
+					break
+				}
+			}
+			secret.Used = &used
+		}
+		audit.Secrets = append(audit.Secrets, secret)
+	}
+
+	sort.Slice(audit.Secrets, func(i, j int) bool { return audit.Secrets[i].Key < audit.Secrets[j].Key })
+	sort.Strings(audit.Plaintext)
+	return audit, nil
+}
+
+// propertyValueContains returns true if any string within the given property value contains s.
+func propertyValueContains(v resource.PropertyValue, s string) bool {
+	if s == "" {
+		return false
+	}
+
+	switch {
+	case v.IsString():
+		return strings.Contains(v.StringValue(), s)
+	case v.IsSecret():
+		return propertyValueContains(v.SecretValue().Element, s)
+	case v.IsArray():
+		for _, e := range v.ArrayValue() {
+			if propertyValueContains(e, s) {
+				return true
+			}
+		}
+	case v.IsObject():
+		for _, e := range v.ObjectValue() {
+			if propertyValueContains(e, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func newConfigAuditCmd(stack *string) *cobra.Command {
+	var jsonOut bool
+
+	auditCmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Report on the secrets in a stack's configuration",
+		Long: "Report on the secrets in a stack's configuration.\n" +
+			"\n" +
+			"For each secret, the report shows when it was last set or rotated and whether the stack's last\n" +
+			"deployment uses it. A secret is considered used if its value appears in any resource's inputs or\n" +
+			"outputs. Rotation times are recorded in the stack's configuration file by `pulumi config set --secret`\n" +
+			"and `pulumi config rotate`, so secrets set before they were tracked show no rotation time.\n" +
+			"\n" +
+			"The report also lists plaintext values that look like secrets.",
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			s, err := requireStack(*stack, true, opts, true /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+
+			proj, _, err := readProject(pulumiAppProj)
+			if err != nil {
+				return err
+			}
+
+			ps, err := loadProjectStack(s)
+			if err != nil {
+				return err
+			}
+
+			decrypter := config.NopDecrypter
+			if ps.Config.HasSecureValue() {
+				if decrypter, err = getStackDencrypter(s); err != nil {
+					return err
+				}
+			}
+
+			snap, err := s.Snapshot(commandContext())
+			if err != nil {
+				return err
+			}
+			if snap != nil && len(snap.Resources) == 0 {
+				snap = nil
+			}
+
+			audit, err := auditConfig(proj, ps, decrypter, snap)
+			if err != nil {
+				return err
+			}
+
+			if jsonOut {
+				return printJSON(audit)
+			}
+
+			if len(audit.Secrets) == 0 {
+				fmt.Println("No secrets found")
+			} else {
+				rows := []cmdutil.TableRow{}
+				for _, secret := range audit.Secrets {
+					rotated, used := "unknown", "unknown"
+					if secret.Rotated != nil {
+						rotated = humanize.Time(*secret.Rotated)
+					}
+					if secret.Used != nil {
+						used = "no"
+						if *secret.Used {
+							used = "yes"
+						}
+					}
+					rows = append(rows, cmdutil.TableRow{Columns: []string{secret.Key, rotated, used}})
+				}
+				cmdutil.PrintTable(cmdutil.Table{
+					Headers: []string{"SECRET", "LAST ROTATED", "USED BY LAST DEPLOYMENT"},
+					Rows:    rows,
+				})
+			}
+
+			if len(audit.Plaintext) > 0 {
+				fmt.Println()
+				fmt.Println("Plaintext values that look like secrets; use `pulumi config set --secret` to encrypt them:")
+				for _, key := range audit.Plaintext {
+					fmt.Printf("    %s\n", key)
+				}
+			}
+			return nil
+		}),
+	}
+
+	auditCmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false,
+		"Emit output as JSON")
+
+	return auditCmd
+}
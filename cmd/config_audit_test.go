@@ -0,0 +1,77 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestAuditConfig(t *testing.T) {
+	proj := &workspace.Project{Name: "proj"}
+	rotated := time.Date(2019, 8, 1, 12, 0, 0, 0, time.UTC)
+
+	// The secure values are "encrypted" with the no-op crypter, so their ciphertext is their value.
+	ps := &workspace.ProjectStack{
+		Config: config.Map{
+			config.MustMakeKey("proj", "dbPassword"): config.NewSecureValue("hunter2"),
+			config.MustMakeKey("proj", "oldToken"):   config.NewSecureValue("abc123"),
+			config.MustMakeKey("proj", "apiToken"):   config.NewValue("1415fc1f4eaeb5e096ee58c1480016638fff29bf"),
+			config.MustMakeKey("aws", "region"):      config.NewValue("us-west-2"),
+		},
+	}
+	ps.MarkSecretRotated(config.MustMakeKey("proj", "dbPassword"), rotated)
+
+	// Without a deployment, usage is unknown.
+	audit, err := auditConfig(proj, ps, config.NopDecrypter, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, configAudit{
+		Secrets: []configAuditSecret{
+			{Key: "dbPassword", Rotated: &rotated},
+			{Key: "oldToken"},
+		},
+		Plaintext: []string{"apiToken"},
+	}, audit)
+
+	// A secret is used if its value appears within any resource property, even as part of a larger string.
+	urn := resource.NewURN("stack", "proj", "", "test:index:Database", "db")
+	inputs := resource.PropertyMap{
+		"connection": resource.MakeSecret(resource.NewStringProperty("postgres://admin:hunter2@db")),
+	}
+	snap := &deploy.Snapshot{
+		Resources: []*resource.State{
+			resource.NewState(urn.Type(), urn, true, false, "id", inputs, nil, "", false, false, nil, nil, "",
+				nil, false, nil, nil, nil),
+		},
+	}
+	used, unused := true, false
+	audit, err = auditConfig(proj, ps, config.NopDecrypter, snap)
+	assert.NoError(t, err)
+	assert.Equal(t, []configAuditSecret{
+		{Key: "dbPassword", Rotated: &rotated, Used: &used},
+		{Key: "oldToken", Used: &unused},
+	}, audit.Secrets)
+
+	// Setting a value in plaintext forgets its rotation history.
+	ps.ForgetSecret(config.MustMakeKey("proj", "dbPassword"))
+	assert.Nil(t, ps.SecretMetadata)
+}
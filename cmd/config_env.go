@@ -0,0 +1,146 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+// configEnvValueJSON is the JSON form of an effective configuration value and the layer it comes from.
+type configEnvValueJSON struct {
+	configValueJSON
+	Source string `json:"source"`
+}
+
+func newConfigEnvCmd(stack *string) *cobra.Command {
+	var jsonOut bool
+	var showSecrets showSecretsFlag
+
+	envCmd := &cobra.Command{
+		Use:   "env",
+		Short: "Show a stack's effective configuration and where each value comes from",
+		Long: "Show a stack's effective configuration and where each value comes from.\n" +
+			"\n" +
+			"A stack's configuration is layered from the following sources, each of which overrides the\n" +
+			"ones before it:\n" +
+			"\n" +
+			"* the defaults declared by the project's `configschema` in Pulumi.yaml\n" +
+			"* the project's `configvalues` in Pulumi.yaml, which are shared by all of its stacks\n" +
+			"* the environment files listed by the stack's `environments`, in order; an environment file\n" +
+			"  is named environments/<env>.yaml, in the project's config directory, and has the same form\n" +
+			"  as a stack configuration file, so it may list environments of its own\n" +
+			"* the stack's own configuration file, Pulumi.<stack>.yaml\n" +
+			"\n" +
+			"Secrets may only be set in the stack's own configuration.",
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			s, err := requireStack(*stack, true, opts, true /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+			ps, err := loadProjectStack(s)
+			if err != nil {
+				return err
+			}
+			layers, err := getStackConfigLayers(s, ps)
+			if err != nil {
+				return err
+			}
+			cfg := layers.Merge()
+
+			// By default, we will use a blinding decrypter to show "[secret]". If requested, display secrets in
+			// plaintext.
+			blinding := config.NewBlindingDecrypter()
+			decrypter := blinding
+			if cfg.HasSecureValue() && showSecrets.any() {
+				if err = showSecrets.record(s, "config env", 0); err != nil {
+					return err
+				}
+				if decrypter, err = getStackDencrypter(s); err != nil {
+					return err
+				}
+			}
+			decrypterFor := func(key config.Key) config.Decrypter {
+				if showSecrets.revealsConfig(key) {
+					return decrypter
+				}
+				return blinding
+			}
+
+			var keys config.KeyArray
+			for key := range cfg {
+				keys = append(keys, key)
+			}
+			sort.Sort(keys)
+
+			if jsonOut {
+				values := make(map[string]configEnvValueJSON)
+				for _, key := range keys {
+					source, _ := layers.Source(key)
+					entry := configEnvValueJSON{configValueJSON: configValueJSON{Secret: cfg[key].Secure()}, Source: source}
+					if !cfg[key].Secure() || showSecrets.revealsConfig(key) {
+						v, err := cfg[key].Value(decrypterFor(key))
+						if err != nil {
+							return errors.Wrap(err, "could not decrypt configuration value")
+						}
+						entry.Value = &v
+					}
+					values[key.String()] = entry
+				}
+				return printJSON(values)
+			}
+
+			fmt.Println("Configuration layers, from lowest to highest precedence:")
+			for _, l := range layers {
+				fmt.Printf("    %s\n", l.Name)
+			}
+			fmt.Println()
+
+			rows := []cmdutil.TableRow{}
+			for _, key := range keys {
+				v, err := cfg[key].Value(decrypterFor(key))
+				if err != nil {
+					return errors.Wrap(err, "could not decrypt configuration value")
+				}
+				source, _ := layers.Source(key)
+				rows = append(rows, cmdutil.TableRow{Columns: []string{prettyKey(key), v, source}})
+			}
+			cmdutil.PrintTable(cmdutil.Table{
+				Headers: []string{"KEY", "VALUE", "SOURCE"},
+				Rows:    rows,
+			})
+			return nil
+		}),
+	}
+
+	envCmd.Flags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit output as JSON")
+	addShowSecretsFlag(envCmd, &showSecrets,
+		"Show secret values when listing config instead of displaying blinded values")
+
+	return envCmd
+}
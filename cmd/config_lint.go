@@ -0,0 +1,237 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// The kinds of problems reported by `pulumi config lint`.
+const (
+	configLintUnused   = "unused"
+	configLintSecret   = "secret"
+	configLintType     = "type"
+	configLintShadowed = "shadowed"
+)
+
+// configLintIssue is a single problem found in a stack's configuration.
+type configLintIssue struct {
+	Key     string `json:"key"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// isProblem returns false for issues that are only informational. Overriding a project default is the intended use
+// of hierarchical configuration, so shadowed keys are reported but are not problems.
+func (issue configLintIssue) isProblem() bool {
+	return issue.Kind != configLintShadowed
+}
+
+// snapshotProject returns the name of the project that produced the given deployment, or "" if it is not known.
+func snapshotProject(snap *deploy.Snapshot) tokens.PackageName {
+	if snap == nil || len(snap.Resources) == 0 {
+		return ""
+	}
+	return snap.Resources[0].URN.Project()
+}
+
+// lintConfig checks the given stack configuration against the project's config schema and, if there is one, the
+// stack's latest deployment. Keys are considered unused if they belong neither to the project nor to a provider used
+// by the last deployment, or if they belong to the project but are not declared by a non-empty config schema.
+func lintConfig(proj *workspace.Project, cfg config.Map, snap *deploy.Snapshot) ([]configLintIssue, error) {
+	var issues []configLintIssue
+	report := func(k config.Key, kind, format string, args ...interface{}) {
+		issues = append(issues, configLintIssue{
+			Key:     prettyKeyForProject(k, proj),
+			Kind:    kind,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	schema := make(map[config.Key]workspace.ProjectConfigType)
+	for k, t := range proj.ConfigSchema {
+		key, err := proj.ConfigSchemaKey(k)
+		if err != nil {
+			return nil, errors.Wrapf(err, "config schema for '%s'", k)
+		}
+		schema[key] = t
+	}
+
+	var packages map[string]bool
+	if snap != nil && len(snap.Resources) > 0 {
+		packages = make(map[string]bool)
+		for _, res := range snap.Resources {
+			if providers.IsProviderType(res.Type) {
+				packages[string(providers.GetProviderPackage(res.Type))] = true
+			}
+		}
+	}
+
+	for k, v := range cfg {
+		t, declared := schema[k]
+
+		switch {
+		case k.Namespace() == string(proj.Name):
+			if !declared && len(schema) > 0 {
+				report(k, configLintUnused, "not declared by the project's config schema")
+			}
+		case packages != nil && !packages[k.Namespace()]:
+			report(k, configLintUnused, "no '%s' provider was used by the last deployment", k.Namespace())
+		}
+
+		if v.Secure() {
+			continue
+		}
+
+		value, err := v.Value(config.NopDecrypter)
+		if err != nil {
+			return nil, err
+		}
+		if t.Secret {
+			report(k, configLintSecret, "declared secret but stored in plaintext; use `pulumi config set --secret`")
+		} else if looksLikeSecret(k, value) {
+			report(k, configLintSecret, "looks like a secret but is stored in plaintext; use `pulumi config set --secret`")
+		}
+		if declared {
+			if err := t.Check(value); err != nil {
+				report(k, configLintType, "%v", err)
+			}
+		}
+		if def, ok := t.DefaultValue(); declared && ok {
+			if def == value {
+				report(k, configLintShadowed, "redundantly sets the project default value")
+			} else {
+				report(k, configLintShadowed, "overrides the project default value %q", def)
+			}
+		}
+	}
+
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].Key != issues[j].Key {
+			return issues[i].Key < issues[j].Key
+		}
+		return issues[i].Kind < issues[j].Kind
+	})
+	return issues, nil
+}
+
+func newConfigLintCmd(stack *string) *cobra.Command {
+	var jsonOut bool
+
+	lintCmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Check a stack's configuration for common problems",
+		Long: "Check a stack's configuration for common problems.\n" +
+			"\n" +
+			"The following problems are reported:\n" +
+			"\n" +
+			"* keys that are unused, because they belong to no provider used by the last deployment, or\n" +
+			"  are not declared by the project's `configschema`\n" +
+			"* plaintext values that look like secrets, or that the config schema declares secret\n" +
+			"* values that do not match the type declared by the config schema\n" +
+			"\n" +
+			"Values that override a project-wide default declared by the config schema are listed as\n" +
+			"\"shadowed\" for information. The project's schema and defaults are only used if the stack\n" +
+			"belongs to the current project.\n" +
+			"\n" +
+			"The command exits with an error if any problems other than shadowed values are found.",
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			s, err := requireStack(*stack, true, opts, true /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+
+			proj, _, err := readProject(pulumiAppProj)
+			if err != nil {
+				return err
+			}
+
+			ps, err := loadProjectStack(s)
+			if err != nil {
+				return err
+			}
+
+			snap, err := s.Snapshot(commandContext())
+			if err != nil {
+				return err
+			}
+
+			// The current project's schema and defaults say nothing about the stacks of other projects.
+			if name := snapshotProject(snap); name != "" && name != proj.Name {
+				proj = &workspace.Project{Name: name}
+			}
+
+			issues, err := lintConfig(proj, ps.Config, snap)
+			if err != nil {
+				return err
+			}
+
+			if jsonOut {
+				if issues == nil {
+					issues = []configLintIssue{}
+				}
+				if err = printJSON(issues); err != nil {
+					return err
+				}
+			} else if len(issues) > 0 {
+				rows := []cmdutil.TableRow{}
+				for _, issue := range issues {
+					rows = append(rows, cmdutil.TableRow{Columns: []string{issue.Key, issue.Kind, issue.Message}})
+				}
+				cmdutil.PrintTable(cmdutil.Table{
+					Headers: []string{"KEY", "KIND", "DETAILS"},
+					Rows:    rows,
+				})
+			}
+
+			problems := 0
+			for _, issue := range issues {
+				if issue.isProblem() {
+					problems++
+				}
+			}
+			if problems > 0 {
+				return errors.Errorf("%d configuration problem(s) found", problems)
+			}
+			if !jsonOut {
+				fmt.Println("No configuration problems found")
+			}
+			return nil
+		}),
+	}
+
+	lintCmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false,
+		"Emit output as JSON")
+
+	return lintCmd
+}
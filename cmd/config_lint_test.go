@@ -0,0 +1,91 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestLintConfig(t *testing.T) {
+	proj := &workspace.Project{
+		Name: "proj",
+		ConfigSchema: map[string]workspace.ProjectConfigType{
+			"port":      {Type: workspace.ConfigTypeInteger, Default: 8080},
+			"password":  {Secret: true},
+			"password2": {Secret: true},
+			"replicas":  {Type: workspace.ConfigTypeInteger, Default: 1},
+		},
+	}
+	cfg := config.Map{
+		config.MustMakeKey("proj", "port"):      config.NewValue("eighty"),
+		config.MustMakeKey("proj", "password"):  config.NewValue("hunter2"),
+		config.MustMakeKey("proj", "replicas"):  config.NewValue("1"),
+		config.MustMakeKey("proj", "apiToken"):  config.NewValue("1415fc1f4eaeb5e096ee58c1480016638fff29bf"),
+		config.MustMakeKey("proj", "undecl"):    config.NewSecureValue("ciphertext"),
+		config.MustMakeKey("aws", "region"):     config.NewValue("us-west-2"),
+		config.MustMakeKey("gcp", "project"):    config.NewValue("my-project"),
+		config.MustMakeKey("proj", "password2"): config.NewSecureValue("ciphertext"),
+	}
+
+	// Without a deployment, provider keys cannot be checked.
+	issues, err := lintConfig(proj, cfg, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []configLintIssue{
+		{Key: "apiToken", Kind: configLintSecret,
+			Message: "looks like a secret but is stored in plaintext; use `pulumi config set --secret`"},
+		{Key: "apiToken", Kind: configLintUnused, Message: "not declared by the project's config schema"},
+		{Key: "password", Kind: configLintSecret,
+			Message: "declared secret but stored in plaintext; use `pulumi config set --secret`"},
+		{Key: "port", Kind: configLintShadowed, Message: "overrides the project default value \"8080\""},
+		{Key: "port", Kind: configLintType, Message: "\"eighty\" is not a valid integer"},
+		{Key: "replicas", Kind: configLintShadowed, Message: "redundantly sets the project default value"},
+		{Key: "undecl", Kind: configLintUnused, Message: "not declared by the project's config schema"},
+	}, issues)
+
+	// With a deployment that only used the AWS provider, GCP keys are unused.
+	urn := resource.NewURN("stack", "proj", "", providers.MakeProviderType("aws"), "default")
+	snap := &deploy.Snapshot{Resources: []*resource.State{{URN: urn, Type: providers.MakeProviderType("aws")}}}
+	issues, err = lintConfig(proj, cfg, snap)
+	assert.NoError(t, err)
+	assert.Contains(t, issues, configLintIssue{
+		Key: "gcp:project", Kind: configLintUnused, Message: "no 'gcp' provider was used by the last deployment"})
+	for _, issue := range issues {
+		assert.NotEqual(t, "aws:region", issue.Key)
+	}
+}
+
+func TestConfigLintIssueIsProblem(t *testing.T) {
+	assert.True(t, configLintIssue{Kind: configLintUnused}.isProblem())
+	assert.True(t, configLintIssue{Kind: configLintType}.isProblem())
+	assert.False(t, configLintIssue{Kind: configLintShadowed}.isProblem())
+}
+
+func TestSnapshotProject(t *testing.T) {
+	assert.Equal(t, tokens.PackageName(""), snapshotProject(nil))
+	assert.Equal(t, tokens.PackageName(""), snapshotProject(&deploy.Snapshot{}))
+
+	urn := resource.NewURN("stack", "other", "", "pulumi:pulumi:Stack", "other-stack")
+	snap := &deploy.Snapshot{Resources: []*resource.State{{URN: urn}}}
+	assert.Equal(t, tokens.PackageName("other"), snapshotProject(snap))
+}
@@ -0,0 +1,379 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gocloud.dev/runtimevar"
+	_ "gocloud.dev/runtimevar/awsparamstore"    // support for awsparamstore://
+	_ "gocloud.dev/runtimevar/gcpruntimeconfig" // support for gcpruntimeconfig://
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/result"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// rotationStrategy produces a new value for a configuration key that is being rotated.
+type rotationStrategy interface {
+	NewValue(ctx context.Context, key config.Key) (string, error)
+}
+
+// randomRotation generates a random alphanumeric value of the given length.
+type randomRotation struct {
+	length int
+}
+
+const randomRotationAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+func (r randomRotation) NewValue(ctx context.Context, key config.Key) (string, error) {
+	if r.length <= 0 {
+		return "", errors.New("the length of a generated value must be positive")
+	}
+
+	value := make([]byte, r.length)
+	max := big.NewInt(int64(len(randomRotationAlphabet)))
+	for i := range value {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		value[i] = randomRotationAlphabet[n.Int64()]
+	}
+	return string(value), nil
+}
+
+// commandRotation runs a shell command and uses its standard output as the new value. The key being rotated is
+// passed to the command in the PULUMI_CONFIG_KEY environment variable.
+type commandRotation struct {
+	command string
+}
+
+func (r commandRotation) NewValue(ctx context.Context, key config.Key) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", r.command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", r.command)
+	}
+	cmd.Env = append(os.Environ(), "PULUMI_CONFIG_KEY="+key.String())
+	cmd.Stderr = os.Stderr
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return "", errors.Wrapf(err, "running rotation command")
+	}
+
+	value := cmdutil.RemoveTrailingNewline(stdout.String())
+	if value == "" {
+		return "", errors.New("rotation command did not print a value")
+	}
+	return value, nil
+}
+
+// urlRotation fetches the new value from a secret or parameter store, such as
+// `awsparamstore://my-param?region=us-east-1` or `gcpruntimeconfig://projects/p/configs/c/variables/v`.
+type urlRotation struct {
+	url string
+}
+
+func (r urlRotation) NewValue(ctx context.Context, key config.Key) (string, error) {
+	v, err := runtimevar.OpenVariable(ctx, r.url)
+	if err != nil {
+		return "", errors.Wrapf(err, "opening %s", r.url)
+	}
+	defer contract.IgnoreClose(v)
+
+	snapshot, err := v.Latest(ctx)
+	if err != nil {
+		return "", errors.Wrapf(err, "fetching %s", r.url)
+	}
+	switch value := snapshot.Value.(type) {
+	case string:
+		return value, nil
+	case []byte:
+		return string(value), nil
+	default:
+		return "", errors.Errorf("%s does not contain a string value", r.url)
+	}
+}
+
+func newConfigRotateCmd(stack *string) *cobra.Command {
+	var breakFreeze string
+	var generate bool
+	var length int
+	var command string
+	var fromURL string
+	var message string
+	var update bool
+	var yes bool
+
+	rotateCmd := &cobra.Command{
+		Use:   "rotate <key>",
+		Short: "Rotate a secret configuration value",
+		Long: "Rotate a secret configuration value.\n" +
+			"\n" +
+			"The new value is produced by exactly one of the following strategies, and is always stored\n" +
+			"encrypted:\n" +
+			"\n" +
+			"* `--generate` generates a random alphanumeric value of the given `--length`.\n" +
+			"* `--command` runs a shell command and uses its output. The key being rotated is passed to\n" +
+			"  the command in the `PULUMI_CONFIG_KEY` environment variable.\n" +
+			"* `--from-url` fetches the value from a secret or parameter store, such as\n" +
+			"  `awsparamstore://my-param?region=us-east-1`.\n" +
+			"\n" +
+			"If `--update` is passed, the stack is then updated so that the resources consuming the value\n" +
+			"pick it up. The update targets the resources whose inputs contain the old value, along with\n" +
+			"their dependents; all other resources are left unchanged.",
+		Args: cmdutil.ExactArgs(1),
+		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
+			var strategies []rotationStrategy
+			if generate {
+				strategies = append(strategies, randomRotation{length: length})
+			}
+			if command != "" {
+				strategies = append(strategies, commandRotation{command: command})
+			}
+			if fromURL != "" {
+				strategies = append(strategies, urlRotation{url: fromURL})
+			}
+			if len(strategies) != 1 {
+				return result.Errorf("exactly one of --generate, --command, or --from-url must be specified")
+			}
+
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			s, err := requireStack(*stack, true, opts, true /*setCurrent*/)
+			if err != nil {
+				return result.FromError(err)
+			}
+
+			key, err := parseConfigKey(args[0])
+			if err != nil {
+				return result.FromError(errors.Wrap(err, "invalid configuration key"))
+			}
+
+			ps, err := loadProjectStack(s)
+			if err != nil {
+				return result.FromError(err)
+			}
+			if _, has := ps.Config[key]; !has {
+				return result.Errorf(
+					"configuration key '%s' not found for stack '%s'; use `pulumi config set` to set it first",
+					prettyKey(key), s.Ref())
+			}
+
+			// Check that the update can run, and find the resources it must update, before the value is rotated.
+			var up *rotationUpdate
+			if update {
+				decrypter, err := getStackDencrypter(s)
+				if err != nil {
+					return result.FromError(err)
+				}
+				old, err := ps.Config[key].Value(decrypter)
+				if err != nil {
+					return result.FromError(err)
+				}
+				if up, err = newRotationUpdate(s, key, old, message, breakFreeze); err != nil {
+					return result.FromError(err)
+				}
+			}
+
+			value, err := strategies[0].NewValue(commandContext(), key)
+			if err != nil {
+				return result.FromError(err)
+			}
+
+			c, err := getStackEncrypter(s)
+			if err != nil {
+				return result.FromError(err)
+			}
+			enc, err := config.EncryptValueAt(c, value, config.ValueLocation{ConfigKey: key.String()})
+			if err != nil {
+				return result.FromError(err)
+			}
+			ps.Config[key] = config.NewSecureValue(enc)
+			ps.MarkSecretRotated(key, time.Now())
+			if err = saveProjectStack(s, ps); err != nil {
+				return result.FromError(err)
+			}
+
+			fmt.Printf("Rotated configuration value '%s'\n", prettyKey(key))
+
+			if !update {
+				return nil
+			}
+			return up.run(s, yes)
+		}),
+	}
+
+	rotateCmd.PersistentFlags().BoolVar(
+		&generate, "generate", false,
+		"Generate a random alphanumeric value")
+	rotateCmd.PersistentFlags().IntVar(
+		&length, "length", 32,
+		"The length of the value generated by --generate")
+	rotateCmd.PersistentFlags().StringVar(
+		&command, "command", "",
+		"Run the given shell command and use its output as the new value")
+	rotateCmd.PersistentFlags().StringVar(
+		&fromURL, "from-url", "",
+		"Fetch the new value from the secret or parameter store at the given URL")
+	rotateCmd.PersistentFlags().BoolVar(
+		&update, "update", false,
+		"Update the stack after rotating the value, so that resources consuming it pick it up")
+	rotateCmd.PersistentFlags().BoolVarP(
+		&yes, "yes", "y", false,
+		"Automatically approve and perform the update after previewing it")
+	rotateCmd.PersistentFlags().StringVarP(
+		&message, "message", "m", "",
+		"Optional message to associate with the update run by --update")
+	rotateCmd.PersistentFlags().StringVar(
+		&breakFreeze, "break-freeze", "",
+		"Run the update of --update during one of the stack's freeze windows, giving the justification that is "+
+			"recorded with it")
+
+	return rotateCmd
+}
+
+// rotationUpdate is the update run after rotating a configuration value, so that resources pick up its new value.
+type rotationUpdate struct {
+	proj    *workspace.Project
+	root    string
+	m       *backend.UpdateMetadata
+	targets []resource.URN // the resources whose inputs contain the value being rotated.
+}
+
+// newRotationUpdate prepares the update of the given stack after the given key, whose current value is old, is
+// rotated, checking that it may run before the value is rotated. If message is empty, the update describes the
+// rotation.
+func newRotationUpdate(s backend.Stack, key config.Key, old, message, breakFreeze string) (*rotationUpdate, error) {
+	proj, root, err := readProject(pulumiAppProj)
+	if err != nil {
+		return nil, err
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("Rotate configuration value '%s'", prettyKey(key))
+	}
+	m, err := getUpdateMetadata(message, root)
+	if err != nil {
+		return nil, errors.Wrap(err, "gathering environment metadata")
+	}
+	if err = checkUpdateMessage(proj, s, m); err != nil {
+		return nil, err
+	}
+	if err = checkFreezeWindows(s, m, breakFreeze); err != nil {
+		return nil, err
+	}
+
+	snap, err := s.Snapshot(commandContext())
+	if err != nil {
+		return nil, err
+	}
+	return &rotationUpdate{proj: proj, root: root, m: m, targets: resourcesConsumingValue(snap, old)}, nil
+}
+
+// resourcesConsumingValue returns the URNs of the resources in the given deployment whose inputs contain the given
+// value.
+func resourcesConsumingValue(snap *deploy.Snapshot, value string) []resource.URN {
+	if snap == nil {
+		return nil
+	}
+	var urns []resource.URN
+	for _, res := range snap.Resources {
+		if !res.Delete && propertyValueContains(resource.NewObjectProperty(res.Inputs), value) {
+			urns = append(urns, res.URN)
+		}
+	}
+	return urns
+}
+
+// run updates the resources that consume the rotated value, and their dependents.
+func (up *rotationUpdate) run(s backend.Stack, yes bool) result.Result {
+	if len(up.targets) == 0 {
+		fmt.Println("No resources consume the rotated value; skipping the update")
+		return nil
+	}
+
+	interactive := cmdutil.Interactive()
+	if !interactive {
+		yes = true // auto-approve changes, since we cannot prompt.
+	}
+
+	opts, err := updateFlagsToOptions(interactive, false /*skipPreview*/, yesFlag{approve: yes})
+	if err != nil {
+		return result.FromError(err)
+	}
+	opts.Display = display.Options{
+		Color:         cmdutil.GetGlobalColorization(),
+		IsInteractive: interactive,
+		Type:          display.DisplayProgress,
+	}
+	opts.Engine = engine.UpdateOptions{
+		Parallel:         defaultParallel,
+		UseLegacyDiff:    useLegacyDiff(),
+		UpdateTargets:    up.targets,
+		TargetDependents: true,
+	}
+
+	sm, err := getStackSecretsManager(s)
+	if err != nil {
+		return result.FromError(errors.Wrap(err, "getting secrets manager"))
+	}
+
+	cfg, err := getStackConfiguration(s, sm)
+	if err != nil {
+		return result.FromError(errors.Wrap(err, "getting stack configuration"))
+	}
+
+	_, res := s.Update(commandContext(), backend.UpdateOperation{
+		Proj:               up.proj,
+		Root:               up.root,
+		M:                  up.m,
+		Opts:               opts,
+		StackConfiguration: cfg,
+		SecretsManager:     sm,
+		Scopes:             cancellationScopes,
+	})
+	switch {
+	case res != nil && res.Error() == context.Canceled:
+		return result.FromError(errors.New("update cancelled"))
+	case res != nil:
+		return PrintEngineResult(res)
+	default:
+		return nil
+	}
+}
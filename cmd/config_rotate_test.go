@@ -0,0 +1,55 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+)
+
+func TestResourcesConsumingValue(t *testing.T) {
+	db := resource.NewURN("stack", "proj", "", "test:index:Database", "db")
+	app := resource.NewURN("stack", "proj", "", "test:index:App", "app")
+	old := resource.NewURN("stack", "proj", "", "test:index:Database", "old")
+	snap := &deploy.Snapshot{
+		Resources: []*resource.State{
+			{
+				URN: db,
+				Inputs: resource.PropertyMap{
+					"connection": resource.MakeSecret(resource.NewStringProperty("postgres://admin:hunter2@db")),
+				},
+			},
+			{
+				URN:     app,
+				Inputs:  resource.PropertyMap{"name": resource.NewStringProperty("app")},
+				Outputs: resource.PropertyMap{"password": resource.NewStringProperty("hunter2")},
+			},
+			{
+				URN:    old,
+				Delete: true,
+				Inputs: resource.PropertyMap{"password": resource.NewStringProperty("hunter2")},
+			},
+		},
+	}
+
+	// Only live resources whose inputs contain the value are targeted.
+	assert.Equal(t, []resource.URN{db}, resourcesConsumingValue(snap, "hunter2"))
+	assert.Empty(t, resourcesConsumingValue(snap, "swordfish"))
+	assert.Empty(t, resourcesConsumingValue(nil, "hunter2"))
+}
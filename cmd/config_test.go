@@ -34,6 +34,27 @@ func TestPrettyKeyForProject(t *testing.T) {
 	assert.Equal(t, "other-package:bar", prettyKeyForProject(config.MustMakeKey("other-package", "bar"), proj))
 }
 
+func TestParseConfigKeyPath(t *testing.T) {
+	key, path, err := parseConfigKeyPath("proj:vpc.subnets[0]", true)
+	assert.NoError(t, err)
+	assert.Equal(t, config.MustMakeKey("proj", "vpc"), key)
+	assert.Equal(t, []interface{}{"subnets", 0}, path)
+
+	key, path, err = parseConfigKeyPath(`proj:["key.with.dots"].nested`, true)
+	assert.NoError(t, err)
+	assert.Equal(t, config.MustMakeKey("proj", "key.with.dots"), key)
+	assert.Equal(t, []interface{}{"nested"}, path)
+
+	// Without --path, the name is taken literally.
+	key, path, err = parseConfigKeyPath("proj:vpc.subnets[0]", false)
+	assert.NoError(t, err)
+	assert.Equal(t, config.MustMakeKey("proj", "vpc.subnets[0]"), key)
+	assert.Nil(t, path)
+
+	_, _, err = parseConfigKeyPath("proj:[0].subnets", true)
+	assert.Error(t, err)
+}
+
 func TestSecretDetection(t *testing.T) {
 	assert.True(t, looksLikeSecret(config.MustMakeKey("test", "token"), "1415fc1f4eaeb5e096ee58c1480016638fff29bf"))
 	assert.True(t, looksLikeSecret(config.MustMakeKey("test", "apiToken"), "1415fc1f4eaeb5e096ee58c1480016638fff29bf"))
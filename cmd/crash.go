@@ -0,0 +1,177 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	survey "gopkg.in/AlecAivazis/survey.v1"
+	surveycore "gopkg.in/AlecAivazis/survey.v1/core"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend/httpstate/client"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/crash"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/version"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// crashContext holds what is known about the running command, for inclusion in crash reports.
+var crashContext struct {
+	sync.Mutex
+	command string
+	config  map[string]string
+}
+
+// recordCrashCommand records the command being run.
+func recordCrashCommand(command string) {
+	crashContext.Lock()
+	defer crashContext.Unlock()
+	crashContext.command = command
+}
+
+// recordCrashConfig records the stack configuration in use, with secret values redacted.
+func recordCrashConfig(cfg config.Map) {
+	crashContext.Lock()
+	defer crashContext.Unlock()
+	crashContext.config = sanitizeCrashConfig(cfg)
+}
+
+// sanitizeCrashConfig renders a configuration map for a crash report, redacting secret values and anything the log
+// filters would redact.
+func sanitizeCrashConfig(cfg config.Map) map[string]string {
+	sanitized := make(map[string]string, len(cfg))
+	for k, v := range cfg {
+		if v.Secure() {
+			sanitized[k.String()] = "[secret]"
+			continue
+		}
+		s, err := v.Value(config.NewBlindingDecrypter())
+		if err != nil {
+			s = "[unknown]"
+		}
+		sanitized[k.String()] = logging.FilterString(s)
+	}
+	return sanitized
+}
+
+// newCrashReport builds a crash report for the given panic.
+func newCrashReport(payload interface{}, stack string) apitype.CrashReport {
+	crashContext.Lock()
+	command, cfg := crashContext.command, crashContext.config
+	crashContext.Unlock()
+
+	report := apitype.CrashReport{
+		Time:         time.Now().Unix(),
+		Version:      version.Version,
+		GoVersion:    runtime.Version(),
+		OS:           runtime.GOOS,
+		Arch:         runtime.GOARCH,
+		Command:      command,
+		Panic:        logging.FilterString(fmt.Sprint(payload)),
+		Stack:        stack,
+		RecentEvents: crash.RecentEvents(),
+		Config:       cfg,
+	}
+
+	// Listing plugins is best-effort; a crash report without them is still useful.
+	if plugins, err := workspace.GetPlugins(); err == nil {
+		for _, p := range plugins {
+			var v string
+			if p.Version != nil {
+				v = p.Version.String()
+			}
+			report.Plugins = append(report.Plugins, apitype.CrashReportPlugin{
+				Name:    p.Name,
+				Kind:    string(p.Kind),
+				Version: v,
+			})
+		}
+	}
+	return report
+}
+
+// writeCrashReport writes a crash report to the crash report directory, returning the path of the file written.
+func writeCrashReport(report apitype.CrashReport) (string, error) {
+	dir, err := workspace.GetCrashReportDir()
+	if err != nil {
+		return "", err
+	}
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	b, err := json.MarshalIndent(report, "", "    ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("crash-%s.json", time.Unix(report.Time, 0).UTC().Format("20060102-150405")))
+	if err = ioutil.WriteFile(path, b, 0600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// ReportCrash writes a crash report for the given panic to a local file and tells the user where to find it. If the
+// CLI is interactive and logged into the Pulumi service, it also offers to upload the report.
+func ReportCrash(payload interface{}, stack string) {
+	report := newCrashReport(payload, stack)
+	path, err := writeCrashReport(report)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not write a crash report: %v\n", err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "A crash report has been written to %s.\n", path)
+	fmt.Fprintln(os.Stderr, "Please review it and attach it to your report. Secret configuration values are redacted.")
+
+	if !cmdutil.Interactive() {
+		return
+	}
+	creds, err := workspace.GetStoredCredentials()
+	if err != nil || creds.Current == "" || creds.AccessTokens[creds.Current] == "" {
+		return
+	}
+	if !confirmCrashReportUpload(creds.Current) {
+		return
+	}
+	pc := client.NewClient(creds.Current, creds.AccessTokens[creds.Current], cmdutil.Diag())
+	if err = pc.SubmitCrashReport(context.Background(), report); err != nil {
+		fmt.Fprintf(os.Stderr, "Could not upload the crash report: %v\n", err)
+		return
+	}
+	fmt.Fprintln(os.Stderr, "The crash report has been uploaded. Thank you!")
+}
+
+// confirmCrashReportUpload asks the user whether to upload a crash report to the given service.
+func confirmCrashReportUpload(cloudURL string) bool {
+	surveycore.DisableColor = true
+	surveycore.QuestionIcon = ""
+
+	confirm := false
+	prompt := fmt.Sprintf("Upload the crash report to %s?", cloudURL)
+	if err := survey.AskOne(&survey.Confirm{Message: prompt}, &confirm, nil); err != nil {
+		return false
+	}
+	return confirm
+}
@@ -0,0 +1,34 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource/config"
+)
+
+func TestSanitizeCrashConfig(t *testing.T) {
+	cfg := config.Map{
+		config.MustMakeKey("proj", "region"): config.NewValue("us-west-2"),
+		config.MustMakeKey("proj", "token"):  config.NewSecureValue("c2VjcmV0"),
+	}
+	assert.Equal(t, map[string]string{
+		"proj:region": "us-west-2",
+		"proj:token":  "[secret]",
+	}, sanitizeCrashConfig(cfg))
+}
@@ -14,15 +14,13 @@
 package cmd
 
 import (
-	"reflect"
 	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/pulumi/pulumi/pkg/backend"
-	"github.com/pulumi/pulumi/pkg/backend/filestate"
-	"github.com/pulumi/pulumi/pkg/backend/httpstate"
 	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/secrets"
+	"github.com/pulumi/pulumi/pkg/secrets/stacksecrets"
 )
 
 func getStackEncrypter(s backend.Stack) (config.Encrypter, error) {
@@ -44,32 +42,16 @@ func getStackDencrypter(s backend.Stack) (config.Decrypter, error) {
 }
 
 func getStackSecretsManager(s backend.Stack) (secrets.Manager, error) {
-	ps, err := loadProjectStack(s)
+	configFile, err := getProjectStackPath(s)
 	if err != nil {
 		return nil, err
 	}
-
-	if ps.SecretsProvider != "default" && ps.SecretsProvider != "passphrase" && ps.SecretsProvider != "" {
-		return newCloudSecretsManager(s.Ref().Name(), stackConfigFile, ps.SecretsProvider)
-	}
-
-	if ps.EncryptionSalt != "" {
-		return newPassphraseSecretsManager(s.Ref().Name(), stackConfigFile)
-	}
-
-	switch stack := s.(type) {
-	case httpstate.Stack:
-		return newServiceSecretsManager(stack)
-	case filestate.Stack:
-		return newPassphraseSecretsManager(s.Ref().Name(), stackConfigFile)
-	}
-
-	return nil, errors.Errorf("unknown stack type %s", reflect.TypeOf(s))
+	return stacksecrets.Manager(s, configFile, newPassphraseSecretsManager)
 }
 
 func validateSecretsProvider(typ string) error {
 	kind := strings.SplitN(typ, ":", 2)[0]
-	supportedKinds := []string{"default", "passphrase", "awskms", "azurekeyvault", "gcpkms", "hashivault"}
+	supportedKinds := []string{"default", "passphrase", "awskms", "azurekeyvault", "gcpkms", "hashivault", "vault"}
 	for _, supportedKind := range supportedKinds {
 		if kind == supportedKind {
 			return nil
@@ -14,18 +14,13 @@
 package cmd
 
 import (
-	cryptorand "crypto/rand"
-	"encoding/base64"
-	"fmt"
 	"os"
 
 	"github.com/pulumi/pulumi/pkg/diag"
-	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/secrets"
 	"github.com/pulumi/pulumi/pkg/secrets/passphrase"
-	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/secrets/stacksecrets"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
-	"github.com/pulumi/pulumi/pkg/util/contract"
 	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
@@ -33,20 +28,15 @@ func readPassphrase(prompt string) (string, error) {
 	if phrase, ok := os.LookupEnv("PULUMI_CONFIG_PASSPHRASE"); ok {
 		return phrase, nil
 	}
+	if !cmdutil.Interactive() {
+		return "", cmdutil.NonInteractiveError("a passphrase is required", "set PULUMI_CONFIG_PASSPHRASE")
+	}
 	return cmdutil.ReadConsoleNoEcho(prompt)
 }
 
-func newPassphraseSecretsManager(stackName tokens.QName, configFile string) (secrets.Manager, error) {
-	contract.Assertf(stackName != "", "stackName %s", "!= \"\"")
-
-	if configFile == "" {
-		f, err := workspace.DetectProjectStackPath(stackName)
-		if err != nil {
-			return nil, err
-		}
-		configFile = f
-	}
-
+// newPassphraseSecretsManager returns a secrets manager that protects secrets with a passphrase, which is read from
+// PULUMI_CONFIG_PASSPHRASE or the console, for the stack whose settings are in the given configuration file.
+func newPassphraseSecretsManager(configFile string) (secrets.Manager, error) {
 	info, err := workspace.LoadProjectStack(configFile)
 	if err != nil {
 		return nil, err
@@ -61,7 +51,7 @@ func newPassphraseSecretsManager(stackName tokens.QName, configFile string) (sec
 				return nil, phraseErr
 			}
 
-			sm, smerr := passphrase.NewPassphaseSecretsManager(phrase, info.EncryptionSalt)
+			sm, smerr := stacksecrets.NewPassphraseManager(configFile, phrase)
 			switch {
 			case smerr == passphrase.ErrIncorrectPassphrase:
 				cmdutil.Diag().Errorf(diag.Message("", "incorrect passphrase"))
@@ -74,8 +64,6 @@ func newPassphraseSecretsManager(stackName tokens.QName, configFile string) (sec
 		}
 	}
 
-	var phrase string
-
 	// Get a the passphrase from the user, ensuring that they match.
 	for {
 		// Here, the stack does not have an EncryptionSalt, so we will get a passphrase and create one
@@ -89,29 +77,9 @@ func newPassphraseSecretsManager(stackName tokens.QName, configFile string) (sec
 		}
 
 		if first == second {
-			phrase = first
-			break
+			return stacksecrets.NewPassphraseManager(configFile, first)
 		}
 		// If they didn't match, print an error and try again
 		cmdutil.Diag().Errorf(diag.Message("", "passphrases do not match"))
 	}
-
-	// Produce a new salt.
-	salt := make([]byte, 8)
-	_, err = cryptorand.Read(salt)
-	contract.Assertf(err == nil, "could not read from system random")
-
-	// Encrypt a message and store it with the salt so we can test if the password is correct later.
-	crypter := config.NewSymmetricCrypterFromPassphrase(phrase, salt)
-	msg, err := crypter.EncryptValue("pulumi")
-	contract.AssertNoError(err)
-
-	// Now store the result and save it.
-	info.EncryptionSalt = fmt.Sprintf("v1:%s:%s", base64.StdEncoding.EncodeToString(salt), msg)
-	if err = info.Save(configFile); err != nil {
-		return nil, err
-	}
-
-	// Finally, build the full secrets manager from the state we just saved
-	return passphrase.NewPassphaseSecretsManager(phrase, info.EncryptionSalt)
 }
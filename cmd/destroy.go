@@ -20,6 +20,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"github.com/pulumi/pulumi/pkg/apitype"
 	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/backend/display"
 	"github.com/pulumi/pulumi/pkg/engine"
@@ -29,12 +30,16 @@ import (
 
 func newDestroyCmd() *cobra.Command {
 	var debug bool
+	var eventLogPath string
+	var displayPlugins []string
 	var stack string
 
 	var message string
+	var breakFreeze string
 
 	// Flags for engine.UpdateOptions.
 	var diffDisplay bool
+	var overrideGuardrails bool
 	var parallel int
 	var refresh bool
 	var showConfig bool
@@ -42,7 +47,7 @@ func newDestroyCmd() *cobra.Command {
 	var showSames bool
 	var skipPreview bool
 	var suppressOutputs bool
-	var yes bool
+	var yes yesFlag
 
 	var cmd = &cobra.Command{
 		Use:        "destroy",
@@ -59,7 +64,7 @@ func newDestroyCmd() *cobra.Command {
 		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
 			interactive := cmdutil.Interactive()
 			if !interactive {
-				yes = true // auto-approve changes, since we cannot prompt.
+				yes.approve = true // auto-approve changes, since we cannot prompt.
 			}
 
 			opts, err := updateFlagsToOptions(interactive, skipPreview, yes)
@@ -67,11 +72,6 @@ func newDestroyCmd() *cobra.Command {
 				return result.FromError(err)
 			}
 
-			var displayType = display.DisplayProgress
-			if diffDisplay {
-				displayType = display.DisplayDiff
-			}
-
 			opts.Display = display.Options{
 				Color:                cmdutil.GetGlobalColorization(),
 				ShowConfig:           showConfig,
@@ -79,9 +79,23 @@ func newDestroyCmd() *cobra.Command {
 				ShowSameResources:    showSames,
 				SuppressOutputs:      suppressOutputs,
 				IsInteractive:        interactive,
-				Type:                 displayType,
+				Type:                 getDisplayType(diffDisplay),
+				Verbosity:            displayVerbosity,
 				Debug:                debug,
+				LowBandwidth:         useLowBandwidth(),
+			}
+
+			closeEventLog, err := openEventLog(eventLogPath, &opts.Display)
+			if err != nil {
+				return result.FromError(err)
+			}
+			defer closeEventLog()
+
+			closeDisplayPlugins, err := openDisplayPlugins(displayPlugins, &opts.Display)
+			if err != nil {
+				return result.FromError(err)
 			}
+			defer closeDisplayPlugins()
 
 			s, err := requireStack(stack, false, opts.Display, true /*setCurrent*/)
 			if err != nil {
@@ -96,6 +110,15 @@ func newDestroyCmd() *cobra.Command {
 			if err != nil {
 				return result.FromError(errors.Wrap(err, "gathering environment metadata"))
 			}
+			if err = checkUpdateMessage(proj, s, m); err != nil {
+				return result.FromError(err)
+			}
+			if err = checkFreezeWindows(s, m, breakFreeze); err != nil {
+				return result.FromError(err)
+			}
+			if overrideGuardrails {
+				m.Environment[backend.GuardrailsOverridden] = "true"
+			}
 
 			sm, err := getStackSecretsManager(s)
 			if err != nil {
@@ -108,13 +131,15 @@ func newDestroyCmd() *cobra.Command {
 			}
 
 			opts.Engine = engine.UpdateOptions{
-				Parallel:      parallel,
-				Debug:         debug,
-				Refresh:       refresh,
-				UseLegacyDiff: useLegacyDiff(),
+				Parallel:           parallel,
+				Debug:              debug,
+				Refresh:            refresh,
+				UseLegacyDiff:      useLegacyDiff(),
+				Memory:             memoryOptions(),
+				OverrideGuardrails: overrideGuardrails,
 			}
 
-			_, res := s.Destroy(commandContext(), backend.UpdateOperation{
+			op := backend.UpdateOperation{
 				Proj:               proj,
 				Root:               root,
 				M:                  m,
@@ -122,7 +147,10 @@ func newDestroyCmd() *cobra.Command {
 				StackConfiguration: cfg,
 				SecretsManager:     sm,
 				Scopes:             cancellationScopes,
-			})
+			}
+			last := recordLastUpdate(s, apitype.DestroyUpdate, opts.PreviewOnly, &op)
+			changes, res := s.Destroy(commandContext(), op)
+			last.finish(changes, res)
 			if res != nil && res.Error() == context.Canceled {
 				return result.FromError(errors.New("destroy cancelled"))
 			}
@@ -133,6 +161,14 @@ func newDestroyCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVarP(
 		&debug, "debug", "d", false,
 		"Print detailed debugging output during resource operations")
+	cmd.PersistentFlags().StringVar(
+		&eventLogPath, "event-log", "",
+		"Log every engine event to the given file, as newline-delimited JSON")
+	cmd.PersistentFlags().StringArrayVar(
+		&displayPlugins, "display-plugin", nil,
+		"Also render engine events with the given display plugin, as `name[=arg]`: junit=<path> writes JUnit XML, "+
+			"github[=<file>] writes GitHub Actions annotations, and other plugins are run as pulumi-display-<name> "+
+			"executables on the PATH. May be repeated")
 	cmd.PersistentFlags().StringVarP(
 		&stack, "stack", "s", "",
 		"The name of the stack to operate on. Defaults to the current stack")
@@ -142,11 +178,17 @@ func newDestroyCmd() *cobra.Command {
 	cmd.PersistentFlags().StringVarP(
 		&message, "message", "m", "",
 		"Optional message to associate with the destroy operation")
+	cmd.PersistentFlags().StringVar(
+		&breakFreeze, "break-freeze", "",
+		"Run the destroy during one of the stack's freeze windows, giving the justification that is recorded with it")
 
 	// Flags for engine.UpdateOptions.
 	cmd.PersistentFlags().BoolVar(
 		&diffDisplay, "diff", false,
 		"Display operation as a rich diff showing the overall change")
+	cmd.PersistentFlags().BoolVar(
+		&overrideGuardrails, "override-guardrails", false,
+		"Proceed even if the destroy exceeds the stack's guardrails; the override is recorded with the update")
 	cmd.PersistentFlags().IntVarP(
 		&parallel, "parallel", "p", defaultParallel,
 		"Allow P resource operations to run in parallel at once (1 for no parallelism). Defaults to unbounded.")
@@ -168,9 +210,7 @@ func newDestroyCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(
 		&suppressOutputs, "suppress-outputs", false,
 		"Suppress display of stack outputs (in case they contain sensitive values)")
-	cmd.PersistentFlags().BoolVarP(
-		&yes, "yes", "y", false,
-		"Automatically approve and perform the destroy after previewing it")
+	addYesFlag(cmd, &yes, "destroy")
 
 	return cmd
 }
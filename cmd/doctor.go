@@ -0,0 +1,382 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/filestate"
+	"github.com/pulumi/pulumi/pkg/backend/httpstate"
+	"github.com/pulumi/pulumi/pkg/backend/httpstate/client"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// doctorStatus is the outcome of a single `pulumi doctor` check.
+type doctorStatus string
+
+const (
+	doctorPass doctorStatus = "pass"
+	doctorWarn doctorStatus = "warn"
+	doctorFail doctorStatus = "fail"
+	doctorSkip doctorStatus = "skip"
+)
+
+// doctorResult is the result of a single `pulumi doctor` check, along with a suggested remediation for problems.
+type doctorResult struct {
+	Check       string       `json:"check"`
+	Status      doctorStatus `json:"status"`
+	Message     string       `json:"message"`
+	Remediation string       `json:"remediation,omitempty"`
+}
+
+// maxClockSkew is the largest difference between the local clock and the service's clock that is considered healthy.
+// Larger differences can cause access tokens and signed URLs to be rejected.
+const maxClockSkew = 5 * time.Minute
+
+// languageExecutables maps project runtimes to the executables they require, in order of preference.
+var languageExecutables = map[string][]string{
+	"nodejs": {"node"},
+	"python": {"python3", "python"},
+	"go":     {"go"},
+	"dotnet": {"dotnet"},
+}
+
+func newDoctorCmd() *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose problems with your Pulumi environment",
+		Long: "Diagnose problems with your Pulumi environment.\n" +
+			"\n" +
+			"Runs a series of checks of the current backend, your credentials, installed plugins, the\n" +
+			"language runtime of the current project, the permissions of the Pulumi workspace, and your\n" +
+			"system clock, and prints a report that suggests how to fix any problems found.",
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			ctx := commandContext()
+
+			var results []doctorResult
+			results = append(results, checkBackend(ctx)...)
+			results = append(results, checkPlugins())
+			results = append(results, checkLanguageRuntime())
+			results = append(results, checkWorkspacePermissions()...)
+
+			if jsonOut {
+				if err := printJSON(results); err != nil {
+					return err
+				}
+			} else {
+				printDoctorReport(results)
+			}
+
+			failures := 0
+			for _, r := range results {
+				if r.Status == doctorFail {
+					failures++
+				}
+			}
+			if failures > 0 {
+				return errors.Errorf("%d check(s) failed", failures)
+			}
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit output as JSON")
+
+	return cmd
+}
+
+// printDoctorReport prints the results of the checks, followed by the remediation for each problem found.
+func printDoctorReport(results []doctorResult) {
+	rows := []cmdutil.TableRow{}
+	for _, r := range results {
+		rows = append(rows, cmdutil.TableRow{Columns: []string{r.Check, colorizeDoctorStatus(r.Status), r.Message}})
+	}
+	cmdutil.PrintTable(cmdutil.Table{
+		Headers: []string{"CHECK", "STATUS", "DETAILS"},
+		Rows:    rows,
+	})
+
+	first := true
+	for _, r := range results {
+		if r.Remediation == "" || (r.Status != doctorFail && r.Status != doctorWarn) {
+			continue
+		}
+		if first {
+			fmt.Printf("\nTo fix the problems found:\n")
+			first = false
+		}
+		fmt.Printf("  * %s: %s\n", r.Check, r.Remediation)
+	}
+}
+
+func colorizeDoctorStatus(status doctorStatus) string {
+	var color string
+	switch status {
+	case doctorPass:
+		color = colors.SpecCreate
+	case doctorWarn:
+		color = colors.SpecWarning
+	case doctorFail:
+		color = colors.SpecError
+	default:
+		color = colors.SpecUnimportant
+	}
+	return cmdutil.GetGlobalColorization().Colorize(color + strings.ToUpper(string(status)) + colors.Reset)
+}
+
+// checkBackend checks that the current backend is reachable and, for the Pulumi service, that the stored access token
+// is valid and that the local clock agrees with the service's.
+func checkBackend(ctx context.Context) []doctorResult {
+	cloudURL, err := workspace.GetCurrentCloudURL()
+	if err != nil {
+		return []doctorResult{{Check: "backend", Status: doctorFail, Message: err.Error(),
+			Remediation: "Check that ~/.pulumi/credentials.json is valid JSON, or run `pulumi login`"}}
+	}
+
+	if filestate.IsFileStateBackendURL(cloudURL) {
+		return []doctorResult{
+			checkFileStateBackend(ctx, cloudURL),
+			{Check: "credentials", Status: doctorSkip, Message: "not used by self-managed backends"},
+			{Check: "clock", Status: doctorSkip, Message: "not checked for self-managed backends"},
+		}
+	}
+
+	cloudURL = httpstate.ValueOrDefaultURL(cloudURL)
+	results := []doctorResult{}
+
+	// Any response at all means the service is reachable.
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	req, err := http.NewRequest(http.MethodGet, cloudURL, nil)
+	if err != nil {
+		return []doctorResult{{Check: "backend", Status: doctorFail, Message: err.Error()}}
+	}
+	resp, err := httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return []doctorResult{
+			{Check: "backend", Status: doctorFail, Message: fmt.Sprintf("could not reach %s: %v", cloudURL, err),
+				Remediation: "Check your network connection and any HTTP proxy settings " +
+					"(HTTPS_PROXY), or use `pulumi login --local` to work offline"},
+			{Check: "credentials", Status: doctorSkip, Message: "the backend is unreachable"},
+			{Check: "clock", Status: doctorSkip, Message: "the backend is unreachable"},
+		}
+	}
+	_ = resp.Body.Close()
+	results = append(results, doctorResult{Check: "backend", Status: doctorPass, Message: cloudURL + " is reachable"})
+	results = append(results, checkAccessToken(ctx, cloudURL))
+
+	serverTime, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		results = append(results, doctorResult{Check: "clock", Status: doctorSkip,
+			Message: "the backend did not report its time"})
+	} else {
+		results = append(results, checkClockSkew(time.Now(), serverTime))
+	}
+	return results
+}
+
+// checkFileStateBackend checks that the stacks in a self-managed backend can be listed.
+func checkFileStateBackend(ctx context.Context, url string) doctorResult {
+	b, err := filestate.New(cmdutil.Diag(), url)
+	if err == nil {
+		_, err = b.ListStacks(ctx, backend.ListStacksFilter{NamesOnly: true})
+	}
+	if err != nil {
+		return doctorResult{Check: "backend", Status: doctorFail, Message: fmt.Sprintf("%s: %v", url, err),
+			Remediation: "Check that the backend's location exists and that you have access to it"}
+	}
+	return doctorResult{Check: "backend", Status: doctorPass, Message: url + " is accessible"}
+}
+
+// checkAccessToken checks that the access token for the given service is valid.
+func checkAccessToken(ctx context.Context, cloudURL string) doctorResult {
+	token := os.Getenv(httpstate.AccessTokenEnvVar)
+	if token == "" {
+		var err error
+		if token, err = workspace.GetAccessToken(cloudURL); err != nil {
+			return doctorResult{Check: "credentials", Status: doctorFail, Message: err.Error()}
+		}
+	}
+	if token == "" {
+		return doctorResult{Check: "credentials", Status: doctorFail, Message: "not logged in to " + cloudURL,
+			Remediation: "Run `pulumi login`, or set " + httpstate.AccessTokenEnvVar}
+	}
+
+	name, err := client.NewClient(cloudURL, token, cmdutil.Diag()).GetPulumiAccountName(ctx)
+	if err != nil {
+		if errResp, ok := errors.Cause(err).(*apitype.ErrorResponse); ok && errResp.Code == http.StatusUnauthorized {
+			return doctorResult{Check: "credentials", Status: doctorFail,
+				Message:     "the access token is invalid or has been revoked",
+				Remediation: "Run `pulumi login` to log in again, or update " + httpstate.AccessTokenEnvVar}
+		}
+		return doctorResult{Check: "credentials", Status: doctorFail, Message: err.Error()}
+	}
+	return doctorResult{Check: "credentials", Status: doctorPass, Message: "logged in as " + name}
+}
+
+// checkClockSkew checks that the local time is close enough to the given server time.
+func checkClockSkew(local, server time.Time) doctorResult {
+	skew := local.Sub(server)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > maxClockSkew {
+		return doctorResult{Check: "clock", Status: doctorFail,
+			Message:     fmt.Sprintf("the local clock differs from the backend's by %v", skew.Round(time.Second)),
+			Remediation: "Synchronize your system clock, for example by enabling NTP"}
+	}
+	return doctorResult{Check: "clock", Status: doctorPass, Message: "the local clock is in sync with the backend"}
+}
+
+// checkPlugins checks that every installed plugin has an executable binary.
+func checkPlugins() doctorResult {
+	plugins, err := workspace.GetPlugins()
+	if err != nil {
+		return doctorResult{Check: "plugins", Status: doctorFail, Message: err.Error()}
+	}
+
+	var broken []string
+	for _, plug := range plugins {
+		path, err := plug.FilePath()
+		if err == nil {
+			err = checkPluginBinary(path)
+		}
+		if err != nil {
+			broken = append(broken, fmt.Sprintf("%s (%v)", plug, err))
+		}
+	}
+	if len(broken) > 0 {
+		return doctorResult{Check: "plugins", Status: doctorFail,
+			Message: fmt.Sprintf("%d of %d plugin(s) are damaged: %s", len(broken), len(plugins),
+				strings.Join(broken, ", ")),
+			Remediation: "Remove the damaged plugins with `pulumi plugin rm` and reinstall them with " +
+				"`pulumi plugin install`"}
+	}
+	return doctorResult{Check: "plugins", Status: doctorPass, Message: fmt.Sprintf("%d plugin(s) installed", len(plugins))}
+}
+
+// checkPluginBinary checks that the plugin binary at the given path exists and is executable.
+func checkPluginBinary(path string) error {
+	info, err := os.Stat(path)
+	switch {
+	case os.IsNotExist(err):
+		return errors.New("binary is missing")
+	case err != nil:
+		return err
+	case !info.Mode().IsRegular() || info.Size() == 0:
+		return errors.New("binary is not a valid file")
+	case runtime.GOOS != "windows" && info.Mode()&0111 == 0:
+		return errors.New("binary is not executable")
+	}
+	return nil
+}
+
+// checkLanguageRuntime checks that the language runtime and language host of the current project are available.
+func checkLanguageRuntime() doctorResult {
+	proj, err := workspace.DetectProject()
+	if err != nil {
+		return doctorResult{Check: "runtime", Status: doctorSkip, Message: "not in a Pulumi project"}
+	}
+
+	rt := proj.Runtime.Name()
+	if _, path, err := workspace.GetPluginPath(workspace.LanguagePlugin, rt, nil); err != nil || path == "" {
+		return doctorResult{Check: "runtime", Status: doctorFail,
+			Message:     fmt.Sprintf("the %s language host (pulumi-language-%s) was not found", rt, rt),
+			Remediation: "Reinstall Pulumi, or add the directory containing the pulumi binary to your PATH"}
+	}
+
+	executables, known := languageExecutables[rt]
+	if !known {
+		return doctorResult{Check: "runtime", Status: doctorPass,
+			Message: fmt.Sprintf("the %s language host is installed", rt)}
+	}
+	for _, exe := range executables {
+		if path, err := exec.LookPath(exe); err == nil {
+			return doctorResult{Check: "runtime", Status: doctorPass, Message: fmt.Sprintf("%s found at %s", exe, path)}
+		}
+	}
+	return doctorResult{Check: "runtime", Status: doctorFail,
+		Message:     fmt.Sprintf("%s is required by the %s runtime but was not found", executables[0], rt),
+		Remediation: fmt.Sprintf("Install %s and make sure it is on your PATH", executables[0])}
+}
+
+// checkWorkspacePermissions checks that the Pulumi workspace directory is writable and that stored credentials are
+// not readable by other users.
+func checkWorkspacePermissions() []doctorResult {
+	dir := os.Getenv(workspace.PulumiCredentialsPathEnvVar)
+	if dir == "" {
+		u, err := user.Current()
+		if err != nil {
+			return []doctorResult{{Check: "workspace", Status: doctorFail, Message: err.Error()}}
+		}
+		dir = filepath.Join(u.HomeDir, workspace.BookkeepingDir)
+	}
+
+	return []doctorResult{
+		checkDirWritable(dir),
+		checkCredentialsMode(filepath.Join(dir, "credentials.json")),
+	}
+}
+
+// checkDirWritable checks that files can be created in the given directory, if it exists.
+func checkDirWritable(dir string) doctorResult {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return doctorResult{Check: "workspace", Status: doctorPass, Message: dir + " will be created when needed"}
+	}
+	f, err := ioutil.TempFile(dir, ".doctor")
+	if err != nil {
+		return doctorResult{Check: "workspace", Status: doctorFail, Message: fmt.Sprintf("%s is not writable", dir),
+			Remediation: fmt.Sprintf("Make sure you own %s and can write to it", dir)}
+	}
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+	return doctorResult{Check: "workspace", Status: doctorPass, Message: dir + " is writable"}
+}
+
+// checkCredentialsMode checks that the given credentials file, if it exists, is not accessible to other users.
+func checkCredentialsMode(path string) doctorResult {
+	info, err := os.Stat(path)
+	switch {
+	case os.IsNotExist(err):
+		return doctorResult{Check: "credentials file", Status: doctorSkip, Message: path + " does not exist"}
+	case err != nil:
+		return doctorResult{Check: "credentials file", Status: doctorFail, Message: err.Error()}
+	case runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0:
+		return doctorResult{Check: "credentials file", Status: doctorWarn,
+			Message:     fmt.Sprintf("%s is accessible to other users (mode %v)", path, info.Mode().Perm()),
+			Remediation: fmt.Sprintf("Run `chmod 600 %s`", path)}
+	}
+	return doctorResult{Check: "credentials file", Status: doctorPass, Message: path + " is private"}
+}
@@ -0,0 +1,76 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckClockSkew(t *testing.T) {
+	now := time.Now()
+	assert.Equal(t, doctorPass, checkClockSkew(now, now.Add(30*time.Second)).Status)
+	assert.Equal(t, doctorPass, checkClockSkew(now, now.Add(-30*time.Second)).Status)
+
+	r := checkClockSkew(now, now.Add(-10*time.Minute))
+	assert.Equal(t, doctorFail, r.Status)
+	assert.Equal(t, "the local clock differs from the backend's by 10m0s", r.Message)
+	assert.NotEmpty(t, r.Remediation)
+}
+
+func TestCheckPluginBinary(t *testing.T) {
+	dir, err := ioutil.TempDir("", "doctor")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.EqualError(t, checkPluginBinary(filepath.Join(dir, "missing")), "binary is missing")
+
+	empty := filepath.Join(dir, "empty")
+	assert.NoError(t, ioutil.WriteFile(empty, nil, 0755))
+	assert.EqualError(t, checkPluginBinary(empty), "binary is not a valid file")
+
+	good := filepath.Join(dir, "good")
+	assert.NoError(t, ioutil.WriteFile(good, []byte("#!/bin/sh\n"), 0755))
+	assert.NoError(t, checkPluginBinary(good))
+
+	if runtime.GOOS != "windows" {
+		noexec := filepath.Join(dir, "noexec")
+		assert.NoError(t, ioutil.WriteFile(noexec, []byte("#!/bin/sh\n"), 0644))
+		assert.EqualError(t, checkPluginBinary(noexec), "binary is not executable")
+	}
+}
+
+func TestCheckCredentialsMode(t *testing.T) {
+	dir, err := ioutil.TempDir("", "doctor")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "credentials.json")
+	assert.Equal(t, doctorSkip, checkCredentialsMode(path).Status)
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte("{}"), 0600))
+	assert.Equal(t, doctorPass, checkCredentialsMode(path).Status)
+
+	if runtime.GOOS != "windows" {
+		assert.NoError(t, os.Chmod(path, 0644))
+		assert.Equal(t, doctorWarn, checkCredentialsMode(path).Status)
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/skratchdot/open-golang/open"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// registryDocsBaseURL is the root of the reference documentation for provider packages.
+const registryDocsBaseURL = "https://www.pulumi.com/docs/reference/pkg/nodejs/pulumi/"
+
+func newExplainCmd() *cobra.Command {
+	var schemaPath string
+	var web bool
+
+	cmd := &cobra.Command{
+		Use:   "explain <type>",
+		Short: "Show the documentation for a resource type",
+		Long: "Show the documentation for a resource type.\n" +
+			"\n" +
+			"This command renders the input and output properties of a resource type, such as\n" +
+			"`aws:s3/bucket:Bucket`, along with whether they are required and their descriptions. The type's\n" +
+			"package schema is read from the file given by `--schema`, or from a `schema.json` file installed\n" +
+			"alongside the package's resource plugin.\n" +
+			"\n" +
+			"If no schema is available, or `--web` is passed, the type's reference documentation is opened\n" +
+			"in your web browser instead.",
+		Args: cmdutil.ExactArgs(1),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			tok, err := parseExplainType(args[0])
+			if err != nil {
+				return err
+			}
+
+			url := registryDocsURL(tok)
+			if web {
+				openDocs(url)
+				return nil
+			}
+
+			var schema *workspace.PackageSchema
+			if schemaPath != "" {
+				schema, err = workspace.LoadPackageSchema(tok.Package(), schemaPath)
+			} else {
+				schema, err = workspace.LoadInstalledPackageSchema(tok.Package())
+			}
+			if err != nil {
+				return err
+			}
+			if schema == nil {
+				fmt.Printf("No schema is installed for package %s.\n", tok.Package())
+				if cmdutil.Interactive() {
+					openDocs(url)
+				} else {
+					fmt.Printf("See the documentation at %s\n", url)
+				}
+				return nil
+			}
+
+			res, has := schema.Resources[string(tok)]
+			if !has {
+				return errors.Errorf("package %s has no resource type %s", tok.Package(), tok)
+			}
+			renderResourceSchema(os.Stdout, tok, res)
+			fmt.Printf("\nDocumentation: %s\n", url)
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVar(
+		&schemaPath, "schema", "",
+		"Read the package schema from the given file, rather than the one installed with the resource plugin")
+	cmd.PersistentFlags().BoolVar(
+		&web, "web", false,
+		"Open the type's reference documentation in your web browser")
+
+	return cmd
+}
+
+// parseExplainType checks that the argument is a fully qualified type token.
+func parseExplainType(arg string) (tokens.Type, error) {
+	parts := strings.Split(arg, tokens.TokenDelimiter)
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", errors.Errorf("'%s' is not a type token; expected <package>:<module>:<type>, "+
+			"e.g. aws:s3/bucket:Bucket", arg)
+	}
+	return tokens.Type(arg), nil
+}
+
+// registryDocsURL returns the reference documentation URL for a resource type. By convention, a type's module often
+// ends with the type's name (e.g. `s3/bucket` for `Bucket`); that segment is not part of the documentation's path.
+func registryDocsURL(tok tokens.Type) string {
+	name := string(tok.Name())
+
+	var segments []string
+	if mod := string(tok.Module().Name()); mod != "index" {
+		segments = strings.Split(mod, "/")
+		if n := len(segments); n > 1 && strings.EqualFold(segments[n-1], name) {
+			segments = segments[:n-1]
+		}
+	}
+
+	url := registryDocsBaseURL + string(tok.Package()) + "/"
+	if len(segments) > 0 {
+		url += strings.Join(segments, "/") + "/"
+	}
+	return url + "#" + name
+}
+
+// renderResourceSchema writes a resource type's description and properties.
+func renderResourceSchema(w io.Writer, tok tokens.Type, res workspace.ResourceSchema) {
+	fmt.Fprintf(w, "%s\n", tok)
+	if res.DeprecationMessage != "" {
+		fmt.Fprintf(w, "\nDeprecated: %s\n", res.DeprecationMessage)
+	}
+	if res.Description != "" {
+		fmt.Fprintf(w, "\n%s\n", indentText(res.Description, "    "))
+	}
+
+	fmt.Fprintf(w, "\nInputs:\n")
+	renderProperties(w, res.InputProperties, res.RequiredInputs)
+	fmt.Fprintf(w, "\nOutputs:\n")
+	renderProperties(w, res.Properties, res.Required)
+}
+
+func renderProperties(w io.Writer, props map[string]workspace.PropertySchema, required []string) {
+	if len(props) == 0 {
+		fmt.Fprintf(w, "    (none)\n")
+		return
+	}
+
+	isRequired := make(map[string]bool)
+	for _, name := range required {
+		isRequired[name] = true
+	}
+
+	var names []string
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		prop := props[name]
+		requiredness := "optional"
+		if isRequired[name] {
+			requiredness = "required"
+		}
+		if prop.DeprecationMessage != "" {
+			requiredness += ", deprecated"
+		}
+		fmt.Fprintf(w, "    %s %s (%s)\n", name, prop.TypeString(), requiredness)
+		if prop.DeprecationMessage != "" {
+			fmt.Fprintf(w, "%s\n", indentText("Deprecated: "+prop.DeprecationMessage, "        "))
+		}
+		if prop.Description != "" {
+			fmt.Fprintf(w, "%s\n", indentText(prop.Description, "        "))
+		}
+	}
+}
+
+// indentText prefixes each non-empty line of the given text with the indent.
+func indentText(text, indent string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// openDocs opens the given documentation URL in the user's web browser, printing it if that is not possible.
+func openDocs(url string) {
+	if err := open.Run(url); err != nil {
+		fmt.Printf("We couldn't launch your web browser. Please visit:\n\n%s\n", url)
+		return
+	}
+	fmt.Printf("Opened %s in your web browser.\n", url)
+}
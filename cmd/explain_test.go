@@ -0,0 +1,101 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestParseExplainType(t *testing.T) {
+	tok, err := parseExplainType("aws:s3/bucket:Bucket")
+	assert.NoError(t, err)
+	assert.Equal(t, tokens.Type("aws:s3/bucket:Bucket"), tok)
+
+	for _, arg := range []string{"Bucket", "aws:Bucket", "aws::Bucket", "a:b:c:d"} {
+		_, err = parseExplainType(arg)
+		assert.Error(t, err, arg)
+	}
+}
+
+func TestRegistryDocsURL(t *testing.T) {
+	for tok, expected := range map[tokens.Type]string{
+		"aws:s3/bucket:Bucket":                   registryDocsBaseURL + "aws/s3/#Bucket",
+		"aws:index:Provider":                     registryDocsBaseURL + "aws/#Provider",
+		"kubernetes:apps/v1:Deployment":          registryDocsBaseURL + "kubernetes/apps/v1/#Deployment",
+		"azure:core/resourceGroup:ResourceGroup": registryDocsBaseURL + "azure/core/#ResourceGroup",
+	} {
+		assert.Equal(t, expected, registryDocsURL(tok))
+	}
+}
+
+func TestExplainSchema(t *testing.T) {
+	dir, err := ioutil.TempDir("", "explain")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "schema.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{
+		"name": "aws",
+		"resources": {
+			"aws:s3/bucket:Bucket": {
+				"description": "Provides an S3 bucket resource.",
+				"inputProperties": {
+					"bucket": {"type": "string", "description": "The name of the bucket."},
+					"tags": {"type": "object", "additionalProperties": {"type": "string"}},
+					"corsRules": {"type": "array", "items": {"$ref": "#/types/aws:s3/BucketCorsRule"}}
+				},
+				"requiredInputs": ["bucket"],
+				"properties": {
+					"arn": {"type": "string", "description": "The ARN of the bucket."}
+				},
+				"required": ["arn"]
+			}
+		}
+	}`), 0600))
+
+	schema, err := workspace.LoadPackageSchema("aws", path)
+	assert.NoError(t, err)
+	res, has := schema.Resources["aws:s3/bucket:Bucket"]
+	assert.True(t, has)
+
+	var buf bytes.Buffer
+	renderResourceSchema(&buf, "aws:s3/bucket:Bucket", res)
+	assert.Equal(t, "aws:s3/bucket:Bucket\n"+
+		"\n"+
+		"    Provides an S3 bucket resource.\n"+
+		"\n"+
+		"Inputs:\n"+
+		"    bucket string (required)\n"+
+		"        The name of the bucket.\n"+
+		"    corsRules []BucketCorsRule (optional)\n"+
+		"    tags map[string]string (optional)\n"+
+		"\n"+
+		"Outputs:\n"+
+		"    arn string (required)\n"+
+		"        The ARN of the bucket.\n", buf.String())
+
+	// A schema for a different package is rejected.
+	_, err = workspace.LoadPackageSchema("gcp", path)
+	assert.Error(t, err)
+}
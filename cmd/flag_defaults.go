@@ -0,0 +1,117 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// defaultableFlags are the flags whose defaults may be set through the environment or flag defaults files.
+var defaultableFlags = []string{"color", "diff", "inline-diff", "non-interactive", "parallel"}
+
+// flagDefaultEnvVar returns the name of the environment variable that sets the default for a flag, e.g.
+// PULUMI_NON_INTERACTIVE for --non-interactive.
+func flagDefaultEnvVar(flag string) string {
+	return "PULUMI_" + strings.ToUpper(strings.Replace(flag, "-", "_", -1))
+}
+
+// flagDefaultsSource is a named source of flag defaults.
+type flagDefaultsSource struct {
+	name     string
+	defaults *workspace.FlagDefaults
+}
+
+// loadFlagDefaults loads the project's and the user's flag defaults files, in order of precedence.
+func loadFlagDefaults() ([]flagDefaultsSource, error) {
+	var paths []string
+	if projPath, err := workspace.DetectProjectPath(); err == nil && projPath != "" {
+		paths = append(paths, workspace.GetProjectFlagDefaultsPath(projPath))
+	}
+	if userPath, err := workspace.GetUserFlagDefaultsPath(); err == nil {
+		paths = append(paths, userPath)
+	}
+
+	var sources []flagDefaultsSource
+	for _, path := range paths {
+		fd, err := workspace.LoadFlagDefaults(path)
+		if err != nil {
+			return nil, err
+		}
+		if fd != nil {
+			sources = append(sources, flagDefaultsSource{name: path, defaults: fd})
+		}
+	}
+	return sources, nil
+}
+
+// applyFlagDefaults sets the defaults of cmd's defaultable flags that were not passed on the command line. A flag's
+// default comes from, in order of precedence: its environment variable, the project's .pulumirc file, and the user's
+// ~/.pulumi/config file.
+func applyFlagDefaults(cmd *cobra.Command, sources []flagDefaultsSource) error {
+	command := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	for _, s := range sources {
+		for _, flags := range append([]map[string]string{s.defaults.Flags}, s.defaults.Commands[command]) {
+			for name := range flags {
+				if !isDefaultableFlag(name) {
+					return errors.Errorf("%s: the default for --%s cannot be set; only %s are supported",
+						s.name, name, strings.Join(defaultableFlags, ", "))
+				}
+			}
+		}
+	}
+
+	for _, name := range defaultableFlags {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || flag.Changed {
+			continue
+		}
+
+		envVar := flagDefaultEnvVar(name)
+		value, source, has := os.Getenv(envVar), envVar, false
+		if value != "" {
+			has = true
+		} else {
+			for _, s := range sources {
+				if value, has = s.defaults.Lookup(command, name); has {
+					source = s.name
+					break
+				}
+			}
+		}
+
+		if has {
+			if err := flag.Value.Set(value); err != nil {
+				return errors.Wrapf(err, "invalid default for --%s from %s", name, source)
+			}
+		}
+	}
+
+	return nil
+}
+
+func isDefaultableFlag(name string) bool {
+	for _, f := range defaultableFlags {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
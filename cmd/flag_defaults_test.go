@@ -0,0 +1,83 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func loadTestFlagDefaults(t *testing.T, dir, name, contents string) flagDefaultsSource {
+	path := filepath.Join(dir, name)
+	assert.NoError(t, ioutil.WriteFile(path, []byte(contents), 0600))
+	fd, err := workspace.LoadFlagDefaults(path)
+	assert.NoError(t, err)
+	return flagDefaultsSource{name: path, defaults: fd}
+}
+
+func TestApplyFlagDefaults(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flags")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	project := loadTestFlagDefaults(t, dir, ".pulumirc", "commands:\n  up:\n    parallel: 4\n")
+	user := loadTestFlagDefaults(t, dir, "config",
+		"color: never\nparallel: 2\ndiff: true\ncommands:\n  up:\n    diff: false\n")
+
+	newCmd := func() (*cobra.Command, *string, *bool, *int) {
+		var color string
+		var diff bool
+		var parallel int
+		root := &cobra.Command{Use: "pulumi"}
+		root.PersistentFlags().StringVar(&color, "color", "auto", "")
+		up := &cobra.Command{Use: "up", Run: func(*cobra.Command, []string) {}}
+		up.Flags().BoolVar(&diff, "diff", false, "")
+		up.Flags().IntVarP(&parallel, "parallel", "p", 10, "")
+		root.AddCommand(up)
+		return up, &color, &diff, &parallel
+	}
+
+	// Project defaults beat user defaults, and command-specific defaults beat global ones.
+	up, color, diff, parallel := newCmd()
+	assert.NoError(t, up.ParseFlags(nil))
+	assert.NoError(t, applyFlagDefaults(up, []flagDefaultsSource{project, user}))
+	assert.Equal(t, "never", *color)
+	assert.False(t, *diff)
+	assert.Equal(t, 4, *parallel)
+
+	// The environment beats the files, and the command line beats everything.
+	assert.NoError(t, os.Setenv("PULUMI_PARALLEL", "8"))
+	defer os.Unsetenv("PULUMI_PARALLEL")
+	up, color, _, parallel = newCmd()
+	assert.NoError(t, up.ParseFlags([]string{"--color", "always"}))
+	assert.NoError(t, applyFlagDefaults(up, []flagDefaultsSource{project, user}))
+	assert.Equal(t, "always", *color)
+	assert.Equal(t, 8, *parallel)
+
+	// Bad values and unsupported flags are errors.
+	up, _, _, _ = newCmd()
+	assert.NoError(t, up.ParseFlags(nil))
+	bad := loadTestFlagDefaults(t, dir, "bad", "diff: sometimes\n")
+	assert.Error(t, applyFlagDefaults(up, []flagDefaultsSource{bad}))
+	unsupported := loadTestFlagDefaults(t, dir, "unsupported", "refresh: true\n")
+	assert.Error(t, applyFlagDefaults(up, []flagDefaultsSource{unsupported}))
+}
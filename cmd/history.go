@@ -35,7 +35,7 @@ import (
 func newHistoryCmd() *cobra.Command {
 	var stack string
 	var jsonOut bool
-	var showSecrets bool
+	var showSecrets showSecretsFlag
 	var cmd = &cobra.Command{
 		Use:        "history",
 		Aliases:    []string{"hist"},
@@ -59,7 +59,10 @@ This command lists data about previous updates for a stack.`,
 				return errors.Wrap(err, "getting history")
 			}
 			var decrypter config.Decrypter
-			if showSecrets {
+			if showSecrets.any() && jsonOut {
+				if err = showSecrets.record(s, "stack history", 0); err != nil {
+					return err
+				}
 				crypter, err := getStackDencrypter(s)
 				if err != nil {
 					return errors.Wrap(err, "decrypting secrets")
@@ -68,7 +71,7 @@ This command lists data about previous updates for a stack.`,
 			}
 
 			if jsonOut {
-				return displayUpdatesJSON(updates, decrypter)
+				return displayUpdatesJSON(updates, decrypter, showSecrets)
 			}
 
 			return displayUpdatesConsole(updates, opts)
@@ -77,9 +80,7 @@ This command lists data about previous updates for a stack.`,
 	cmd.PersistentFlags().StringVarP(
 		&stack, "stack", "s", "",
 		"Choose a stack other than the currently selected one")
-	cmd.Flags().BoolVar(
-		&showSecrets, "show-secrets", false,
-		"Show secret values when listing config instead of displaying blinded values")
+	addShowSecretsFlag(cmd, &showSecrets, "Show secret values when listing config instead of displaying blinded values")
 	cmd.PersistentFlags().BoolVarP(
 		&jsonOut, "json", "j", false, "Emit output as JSON")
 	return cmd
@@ -94,13 +95,14 @@ type updateInfoJSON struct {
 	Environment map[string]string          `json:"environment"`
 	Config      map[string]configValueJSON `json:"config"`
 	Result      string                     `json:"result,omitempty"`
+	Version     int                        `json:"version,omitempty"`
 
 	// These values are only present once the update finishes
 	EndTime         *string         `json:"endTime,omitempty"`
 	ResourceChanges *map[string]int `json:"resourceChanges,omitempty"`
 }
 
-func displayUpdatesJSON(updates []backend.UpdateInfo, decrypter config.Decrypter) error {
+func displayUpdatesJSON(updates []backend.UpdateInfo, decrypter config.Decrypter, showSecrets showSecretsFlag) error {
 	makeStringRef := func(s string) *string {
 		return &s
 	}
@@ -119,7 +121,7 @@ func displayUpdatesJSON(updates []backend.UpdateInfo, decrypter config.Decrypter
 			configValue := configValueJSON{
 				Secret: v.Secure(),
 			}
-			if !v.Secure() || (v.Secure() && decrypter != nil) {
+			if !v.Secure() || (decrypter != nil && showSecrets.revealsConfig(k)) {
 				value, err := v.Value(decrypter)
 				contract.AssertNoError(err)
 				configValue.Value = makeStringRef(value)
@@ -128,6 +130,7 @@ func displayUpdatesJSON(updates []backend.UpdateInfo, decrypter config.Decrypter
 			info.Config[k.String()] = configValue
 		}
 		info.Result = string(update.Result)
+		info.Version = update.Version
 		if update.Result != backend.InProgressResult {
 			info.EndTime = makeStringRef(time.Unix(update.EndTime, 0).UTC().Format(timeFormat))
 			resourceChanges := make(map[string]int)
@@ -156,6 +159,9 @@ func displayUpdatesConsole(updates []backend.UpdateInfo, opts display.Options) e
 	for _, update := range updates {
 
 		fmt.Printf("UpdateKind: %v\n", update.Kind)
+		if update.Version != 0 {
+			fmt.Printf("Version: %v\n", update.Version)
+		}
 		if update.Result == "succeeded" {
 			fmt.Print(opts.Color.Colorize(fmt.Sprintf("%sStatus: %v%s\n", colors.Green, update.Result, colors.Reset)))
 		} else {
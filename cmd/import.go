@@ -0,0 +1,466 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/result"
+)
+
+func newImportCmd() *cobra.Command {
+	var debug bool
+	var eventLogPath string
+	var displayPlugins []string
+	var message string
+	var breakFreeze string
+	var out string
+	var protect bool
+	var stack string
+
+	// Flags for engine.UpdateOptions.
+	var diffDisplay bool
+	var skipPreview bool
+	var suppressOutputs bool
+	var yes yesFlag
+
+	var cmd = &cobra.Command{
+		Use:   "import <type> <name> <id>",
+		Short: "Import an existing resource into a stack",
+		Long: "Import an existing resource into a stack.\n" +
+			"\n" +
+			"This command reads the resource with the given type and ID from its provider, and adopts it into\n" +
+			"the current stack's state under the given name, without running the stack's program. The\n" +
+			"resource is managed by the default provider for its package, configured from the stack's\n" +
+			"configuration. The stack's other resources are left as they are.\n" +
+			"\n" +
+			"Once the resource has been imported, the code that defines it in the project's language is\n" +
+			"printed, or written to the file given by `--out`. Add it to the program so that later updates\n" +
+			"keep the resource rather than deleting it. Code can be generated for Node.js and Python\n" +
+			"projects.\n" +
+			"\n" +
+			"For example, to import an S3 bucket:\n" +
+			"\n" +
+			"    pulumi import aws:s3/bucket:Bucket my-bucket my-bucket-1234",
+		Args: cmdutil.ExactArgs(3),
+		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
+			typ, err := tokens.ParseTypeToken(args[0])
+			if err != nil {
+				return result.FromError(errors.Wrapf(err, "invalid resource type %q", args[0]))
+			}
+			imp := deploy.Import{
+				Type:    typ,
+				Name:    tokens.QName(args[1]),
+				ID:      resource.ID(args[2]),
+				Protect: protect,
+			}
+
+			interactive := cmdutil.Interactive()
+			if !interactive {
+				yes.approve = true // auto-approve changes, since we cannot prompt.
+			}
+
+			opts, err := updateFlagsToOptions(interactive, skipPreview, yes)
+			if err != nil {
+				return result.FromError(err)
+			}
+
+			opts.Display = display.Options{
+				Color:           cmdutil.GetGlobalColorization(),
+				SuppressOutputs: suppressOutputs,
+				IsInteractive:   interactive,
+				Type:            getDisplayType(diffDisplay),
+				Verbosity:       displayVerbosity,
+				Debug:           debug,
+			}
+
+			closeEventLog, err := openEventLog(eventLogPath, &opts.Display)
+			if err != nil {
+				return result.FromError(err)
+			}
+			defer closeEventLog()
+
+			closeDisplayPlugins, err := openDisplayPlugins(displayPlugins, &opts.Display)
+			if err != nil {
+				return result.FromError(err)
+			}
+			defer closeDisplayPlugins()
+
+			s, err := requireStack(stack, false, opts.Display, true /*setCurrent*/)
+			if err != nil {
+				return result.FromError(err)
+			}
+
+			proj, root, err := readProject(pulumiAppProj)
+			if err != nil {
+				return result.FromError(err)
+			}
+
+			m, err := getUpdateMetadata(message, root)
+			if err != nil {
+				return result.FromError(errors.Wrap(err, "gathering environment metadata"))
+			}
+			if err = checkUpdateMessage(proj, s, m); err != nil {
+				return result.FromError(err)
+			}
+			if err = checkFreezeWindows(s, m, breakFreeze); err != nil {
+				return result.FromError(err)
+			}
+
+			sm, err := getStackSecretsManager(s)
+			if err != nil {
+				return result.FromError(errors.Wrap(err, "getting secrets manager"))
+			}
+
+			cfg, err := getStackConfiguration(s, sm)
+			if err != nil {
+				return result.FromError(errors.Wrap(err, "getting stack configuration"))
+			}
+
+			opts.Engine = engine.UpdateOptions{
+				Debug:         debug,
+				UseLegacyDiff: useLegacyDiff(),
+				Memory:        memoryOptions(),
+				Imports:       []deploy.Import{imp},
+			}
+
+			_, res := s.Update(commandContext(), backend.UpdateOperation{
+				Proj:               proj,
+				Root:               root,
+				M:                  m,
+				Opts:               opts,
+				StackConfiguration: cfg,
+				SecretsManager:     sm,
+				Scopes:             cancellationScopes,
+			})
+			switch {
+			case res != nil && res.Error() == context.Canceled:
+				return result.FromError(errors.New("import cancelled"))
+			case res != nil:
+				return PrintEngineResult(res)
+			}
+
+			// Generate the code that defines the imported resource from the state it was imported with.
+			snap, err := s.Snapshot(commandContext())
+			if err != nil {
+				return result.FromError(err)
+			}
+			var state *resource.State
+			if snap != nil {
+				for _, r := range snap.Resources {
+					if r.Type == imp.Type && r.URN.Name() == imp.Name && r.ID == imp.ID && !r.Delete {
+						state = r
+					}
+				}
+			}
+			if state == nil {
+				// The import was declined or only previewed.
+				return nil
+			}
+
+			code, err := generateImportCode(proj.Runtime.Name(), state)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+				return nil
+			}
+			if out != "" {
+				if err = ioutil.WriteFile(out, []byte(code), 0600); err != nil {
+					return result.FromError(errors.Wrap(err, "writing generated code"))
+				}
+				fmt.Printf("The code that defines the imported resource has been written to %s.\n", out)
+				return nil
+			}
+			fmt.Printf("\nAdd the following code to your program to keep managing the imported resource:\n\n%s", code)
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().BoolVarP(
+		&debug, "debug", "d", false,
+		"Print detailed debugging output during resource operations")
+	cmd.PersistentFlags().StringVar(
+		&eventLogPath, "event-log", "",
+		"Log every engine event to the given file, as newline-delimited JSON")
+	cmd.PersistentFlags().StringArrayVar(
+		&displayPlugins, "display-plugin", nil,
+		"Also render engine events with the given display plugin, as `name[=arg]`: junit=<path> writes JUnit XML, "+
+			"github[=<file>] writes GitHub Actions annotations, and other plugins are run as pulumi-display-<name> "+
+			"executables on the PATH. May be repeated")
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().StringVar(
+		&stackConfigFile, "config-file", "",
+		"Use the configuration values in the specified file rather than detecting the file name")
+	cmd.PersistentFlags().StringVarP(
+		&message, "message", "m", "",
+		"Optional message to associate with the import operation")
+	cmd.PersistentFlags().StringVar(
+		&breakFreeze, "break-freeze", "",
+		"Run the import during one of the stack's freeze windows, giving the justification that is recorded with it")
+	cmd.PersistentFlags().StringVarP(
+		&out, "out", "o", "",
+		"Write the code that defines the imported resource to the given file, rather than printing it")
+	cmd.PersistentFlags().BoolVar(
+		&protect, "protect", false,
+		"Protect the imported resource, so that it cannot be deleted until it is unprotected")
+
+	// Flags for engine.UpdateOptions.
+	cmd.PersistentFlags().BoolVar(
+		&diffDisplay, "diff", false,
+		"Display operation as a rich diff showing the overall change")
+	cmd.PersistentFlags().BoolVar(
+		&skipPreview, "skip-preview", false,
+		"Do not perform a preview before performing the import")
+	cmd.PersistentFlags().BoolVar(
+		&suppressOutputs, "suppress-outputs", false,
+		"Suppress display of stack outputs (in case they contain sensitive values)")
+	addYesFlag(cmd, &yes, "import")
+
+	return cmd
+}
+
+// generateImportCode returns the code that defines the given imported resource in a program in the given language.
+// The resource is defined with the inputs it was imported with, other than those whose names start with "__", which
+// providers use for their own bookkeeping.
+func generateImportCode(runtime string, res *resource.State) (string, error) {
+	switch runtime {
+	case "nodejs":
+		return generateNodeJSImportCode(res), nil
+	case "python":
+		return generatePythonImportCode(res), nil
+	default:
+		return "", errors.Errorf("code cannot be generated for %s programs; define the resource %s with the "+
+			"inputs shown by `pulumi stack export`", runtime, res.URN)
+	}
+}
+
+// importInputKeys returns the sorted keys of the inputs to define an imported resource with.
+func importInputKeys(inputs resource.PropertyMap) []resource.PropertyKey {
+	var keys []resource.PropertyKey
+	for k := range inputs {
+		if !strings.HasPrefix(string(k), "__") {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// importModulePath returns the path of the module that defines the given type within its package, e.g. ["s3"] for
+// aws:s3/bucket:Bucket, or nothing for types in the package's index module.
+func importModulePath(t tokens.Type) []string {
+	mod := string(t.Module().Name())
+	if i := strings.Index(mod, "/"); i != -1 {
+		mod = mod[:i]
+	}
+	if mod == "" || mod == "index" {
+		return nil
+	}
+	return []string{mod}
+}
+
+// importVariableName returns a variable name for the resource with the given name, in camelCase or snake_case.
+func importVariableName(name string, snake bool) string {
+	var words []string
+	for _, w := range strings.FieldsFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		words = append(words, strings.ToLower(w))
+	}
+	if len(words) == 0 {
+		return "resource"
+	}
+	if snake {
+		name = strings.Join(words, "_")
+	} else {
+		name = words[0]
+		for _, w := range words[1:] {
+			name += strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	if unicode.IsDigit(rune(name[0])) {
+		name = "_" + name
+	}
+	return name
+}
+
+var jsIdentifierRegexp = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+func generateNodeJSImportCode(res *resource.State) string {
+	pkg := importVariableName(string(res.Type.Package()), false)
+	ctor := strings.Join(append(append([]string{pkg}, importModulePath(res.Type)...), string(res.Type.Name())), ".")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "import * as pulumi from \"@pulumi/pulumi\";\n")
+	fmt.Fprintf(&b, "import * as %s from \"@pulumi/%s\";\n\n", pkg, res.Type.Package())
+	fmt.Fprintf(&b, "const %s = new %s(%s, {\n", importVariableName(string(res.URN.Name()), false), ctor,
+		strconv.Quote(string(res.URN.Name())))
+	for _, k := range importInputKeys(res.Inputs) {
+		fmt.Fprintf(&b, "    %s: %s,\n", nodeJSKey(string(k)), nodeJSValue(res.Inputs[k], "    "))
+	}
+	if res.Protect {
+		fmt.Fprintf(&b, "}, { protect: true });\n")
+	} else {
+		fmt.Fprintf(&b, "});\n")
+	}
+	return b.String()
+}
+
+func nodeJSKey(k string) string {
+	if jsIdentifierRegexp.MatchString(k) {
+		return k
+	}
+	return strconv.Quote(k)
+}
+
+// nodeJSValue renders the given value as a JavaScript expression, indenting nested lines by the given indent.
+func nodeJSValue(v resource.PropertyValue, indent string) string {
+	switch {
+	case v.IsNull():
+		return "undefined"
+	case v.IsBool():
+		return strconv.FormatBool(v.BoolValue())
+	case v.IsNumber():
+		return strconv.FormatFloat(v.NumberValue(), 'f', -1, 64)
+	case v.IsString():
+		return jsonString(v.StringValue())
+	case v.IsSecret():
+		return "pulumi.secret(" + nodeJSValue(v.SecretValue().Element, indent) + ")"
+	case v.IsArray():
+		if len(v.ArrayValue()) == 0 {
+			return "[]"
+		}
+		s := "[\n"
+		for _, e := range v.ArrayValue() {
+			s += indent + "    " + nodeJSValue(e, indent+"    ") + ",\n"
+		}
+		return s + indent + "]"
+	case v.IsObject():
+		obj := v.ObjectValue()
+		if len(obj) == 0 {
+			return "{}"
+		}
+		s := "{\n"
+		for _, k := range obj.StableKeys() {
+			s += indent + "    " + nodeJSKey(string(k)) + ": " + nodeJSValue(obj[k], indent+"    ") + ",\n"
+		}
+		return s + indent + "}"
+	default:
+		// Assets, archives, and unknown values can't be imported.
+		return "undefined"
+	}
+}
+
+func generatePythonImportCode(res *resource.State) string {
+	pkg := importVariableName(string(res.Type.Package()), true)
+	ctor := strings.Join(append(append([]string{pkg}, importModulePath(res.Type)...), string(res.Type.Name())), ".")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "import pulumi\n")
+	fmt.Fprintf(&b, "import pulumi_%s as %s\n\n", pkg, pkg)
+	fmt.Fprintf(&b, "%s = %s(%s", importVariableName(string(res.URN.Name()), true), ctor,
+		jsonString(string(res.URN.Name())))
+	for _, k := range importInputKeys(res.Inputs) {
+		fmt.Fprintf(&b, ",\n    %s=%s", pythonName(string(k)), pythonValue(res.Inputs[k], "    "))
+	}
+	if res.Protect {
+		fmt.Fprintf(&b, ",\n    opts=pulumi.ResourceOptions(protect=True)")
+	}
+	fmt.Fprintf(&b, ")\n")
+	return b.String()
+}
+
+// pythonName converts a camelCase property name to the snake_case name the Python SDKs use.
+func pythonName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteRune('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// pythonValue renders the given value as a Python expression, indenting nested lines by the given indent.
+func pythonValue(v resource.PropertyValue, indent string) string {
+	switch {
+	case v.IsNull():
+		return "None"
+	case v.IsBool():
+		if v.BoolValue() {
+			return "True"
+		}
+		return "False"
+	case v.IsNumber():
+		return strconv.FormatFloat(v.NumberValue(), 'f', -1, 64)
+	case v.IsString():
+		return jsonString(v.StringValue())
+	case v.IsSecret():
+		return "pulumi.Output.secret(" + pythonValue(v.SecretValue().Element, indent) + ")"
+	case v.IsArray():
+		if len(v.ArrayValue()) == 0 {
+			return "[]"
+		}
+		s := "[\n"
+		for _, e := range v.ArrayValue() {
+			s += indent + "    " + pythonValue(e, indent+"    ") + ",\n"
+		}
+		return s + indent + "]"
+	case v.IsObject():
+		obj := v.ObjectValue()
+		if len(obj) == 0 {
+			return "{}"
+		}
+		s := "{\n"
+		for _, k := range obj.StableKeys() {
+			s += indent + "    " + jsonString(string(k)) + ": " + pythonValue(obj[k], indent+"    ") + ",\n"
+		}
+		return s + indent + "}"
+	default:
+		// Assets, archives, and unknown values can't be imported.
+		return "None"
+	}
+}
+
+// jsonString quotes the given string as JSON does, which is also a valid JavaScript and Python string literal.
+func jsonString(s string) string {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return strconv.Quote(s)
+	}
+	return string(b)
+}
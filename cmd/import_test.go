@@ -0,0 +1,83 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+func TestGenerateImportCode(t *testing.T) {
+	typ := tokens.Type("aws:s3/bucket:Bucket")
+	res := &resource.State{
+		Type: typ,
+		URN:  resource.NewURN("dev", "proj", "", typ, "my-bucket"),
+		ID:   "my-bucket-1234",
+		Inputs: resource.PropertyMap{
+			"__defaults":   resource.NewArrayProperty(nil),
+			"acl":          resource.NewStringProperty("private"),
+			"forceDestroy": resource.NewBoolProperty(false),
+			"tags": resource.NewObjectProperty(resource.PropertyMap{
+				"Name": resource.NewStringProperty("bucket"),
+			}),
+			"password": resource.MakeSecret(resource.NewStringProperty("hush")),
+		},
+		Protect: true,
+	}
+
+	code, err := generateImportCode("nodejs", res)
+	assert.NoError(t, err)
+	assert.Equal(t, `import * as pulumi from "@pulumi/pulumi";
+import * as aws from "@pulumi/aws";
+
+const myBucket = new aws.s3.Bucket("my-bucket", {
+    acl: "private",
+    forceDestroy: false,
+    password: pulumi.secret("hush"),
+    tags: {
+        Name: "bucket",
+    },
+}, { protect: true });
+`, code)
+
+	code, err = generateImportCode("python", res)
+	assert.NoError(t, err)
+	assert.Equal(t, `import pulumi
+import pulumi_aws as aws
+
+my_bucket = aws.s3.Bucket("my-bucket",
+    acl="private",
+    force_destroy=False,
+    password=pulumi.Output.secret("hush"),
+    tags={
+        "Name": "bucket",
+    },
+    opts=pulumi.ResourceOptions(protect=True))
+`, code)
+
+	_, err = generateImportCode("go", res)
+	assert.Error(t, err)
+}
+
+func TestImportVariableName(t *testing.T) {
+	assert.Equal(t, "myBucket", importVariableName("my-bucket", false))
+	assert.Equal(t, "my_bucket", importVariableName("my-bucket", true))
+	assert.Equal(t, "_1Bucket", importVariableName("1-bucket", false))
+	assert.Equal(t, "resource", importVariableName("--", false))
+}
@@ -0,0 +1,196 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/util/result"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func newLastCmd() *cobra.Command {
+	var stack string
+	var jsonOut bool
+	var cmd = &cobra.Command{
+		Use:   "last",
+		Short: "Show the result of the last operation on a stack",
+		Long: "Show the result of the last operation on a stack\n" +
+			"\n" +
+			"This command shows the summary of the last update, preview, refresh, or destroy of a stack that was\n" +
+			"run from this project on this machine, including the errors it reported if it failed, and the link to\n" +
+			"its result. The summary is kept in the workspace, so it can be shown even after the terminal that ran\n" +
+			"the operation has been closed, and without asking the stack's backend.",
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			w, err := workspace.New()
+			if err != nil {
+				return err
+			}
+			if stack == "" {
+				if stack = w.Settings().Stack; stack == "" {
+					return errors.New("no stack selected; please use `pulumi stack select` or pass --stack")
+				}
+			}
+
+			summary, err := w.LastUpdate(stack)
+			if err != nil {
+				return err
+			}
+			if summary == nil {
+				return errors.Errorf("no operations on stack '%s' have been recorded on this machine", stack)
+			}
+
+			if jsonOut {
+				return printJSON(summary)
+			}
+			displayLastUpdate(summary, cmdutil.GetGlobalColorization())
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "",
+		"Choose a stack other than the currently selected one")
+	cmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit output as JSON")
+
+	return cmd
+}
+
+// displayLastUpdate prints the given summary of an operation on a stack.
+func displayLastUpdate(summary *workspace.UpdateSummary, color colors.Colorization) {
+	label := backend.ActionLabel(apitype.UpdateKind(summary.Kind), summary.DryRun)
+	fmt.Printf("%s (%s):\n", label, summary.Stack)
+
+	if summary.Succeeded {
+		fmt.Print(color.Colorize(fmt.Sprintf("%sStatus: succeeded%s\n", colors.Green, colors.Reset)))
+	} else {
+		fmt.Print(color.Colorize(fmt.Sprintf("%sStatus: failed%s\n", colors.Red, colors.Reset)))
+	}
+	duration := summary.EndTime.Sub(summary.StartTime).Round(time.Second)
+	fmt.Printf("Started %s, took %s\n", humanize.Time(summary.StartTime), duration)
+
+	if len(summary.ResourceChanges) > 0 {
+		fmt.Printf("Resources:\n")
+		for _, op := range deploy.StepOps {
+			if count := summary.ResourceChanges[string(op)]; count > 0 {
+				text := op.PastTense()
+				if op == deploy.OpSame {
+					text = "unchanged"
+				}
+				fmt.Print(color.Colorize(fmt.Sprintf("    %s%d %s%s\n", op.Prefix(), count, text, colors.Reset)))
+			}
+		}
+	}
+
+	if len(summary.Errors) > 0 {
+		fmt.Printf("Errors:\n")
+		for _, msg := range summary.Errors {
+			fmt.Print(color.Colorize(fmt.Sprintf("    %s%s%s\n", colors.SpecError, msg, colors.Reset)))
+		}
+	}
+
+	if summary.Permalink != "" {
+		fmt.Print(color.Colorize(colors.SpecHeadline + "Permalink: " +
+			colors.Underline + colors.BrightBlue + summary.Permalink + colors.Reset + "\n"))
+	}
+}
+
+// lastUpdateRecorder records the summary of an operation on a stack in the workspace, so that `pulumi last` can show
+// it again later.
+type lastUpdateRecorder struct {
+	summary workspace.UpdateSummary
+	changes engine.ResourceChanges
+	events  chan engine.Event
+	done    chan bool
+}
+
+// recordLastUpdate starts recording the summary of the given operation on the given stack, by observing its events.
+// Once the operation has finished, its result must be passed to the recorder's finish method.
+func recordLastUpdate(s backend.Stack, kind apitype.UpdateKind, dryRun bool,
+	op *backend.UpdateOperation) *lastUpdateRecorder {
+
+	r := &lastUpdateRecorder{
+		summary: workspace.UpdateSummary{
+			Stack:     s.Ref().String(),
+			Kind:      string(kind),
+			DryRun:    dryRun,
+			StartTime: time.Now(),
+		},
+		events: make(chan engine.Event),
+		done:   make(chan bool),
+	}
+	op.Events, op.Permalink = r.events, &r.summary.Permalink
+
+	go func() {
+		for e := range r.events {
+			switch e.Type {
+			case engine.DiagEvent:
+				payload := e.Payload.(engine.DiagEventPayload)
+				if payload.Severity == diag.Error {
+					r.summary.Errors = append(r.summary.Errors, colors.Never.Colorize(payload.Message))
+				}
+			case engine.SummaryEvent:
+				r.changes = e.Payload.(engine.SummaryEventPayload).ResourceChanges
+			}
+		}
+		close(r.done)
+	}()
+	return r
+}
+
+// finish records the summary of the operation, given what it returned. A summary that cannot be recorded is not an
+// error, since the operation itself is done.
+func (r *lastUpdateRecorder) finish(changes engine.ResourceChanges, res result.Result) {
+	close(r.events)
+	<-r.done
+
+	r.summary.EndTime = time.Now()
+	r.summary.Succeeded = res == nil
+	if res != nil && res.Error() != nil {
+		r.summary.Errors = append(r.summary.Errors, res.Error().Error())
+	}
+	if changes == nil {
+		changes = r.changes
+	}
+	if len(changes) > 0 {
+		r.summary.ResourceChanges = make(map[string]int)
+		for op, count := range changes {
+			r.summary.ResourceChanges[string(op)] = count
+		}
+	}
+
+	w, err := workspace.New()
+	if err == nil {
+		err = w.SaveLastUpdate(&r.summary)
+	}
+	if err != nil {
+		logging.V(3).Infof("error recording the last operation on stack %s: %v", r.summary.Stack, err)
+	}
+}
@@ -25,7 +25,9 @@ import (
 	"github.com/pulumi/pulumi/pkg/backend/display"
 	"github.com/pulumi/pulumi/pkg/diag/colors"
 	"github.com/pulumi/pulumi/pkg/operations"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
 )
 
 // We use RFC 5424 timestamps with millisecond precision for displaying time stamps on log entries. Go does not
@@ -44,7 +46,12 @@ func newLogsCmd() *cobra.Command {
 	logsCmd := &cobra.Command{
 		Use:   "logs",
 		Short: "[PREVIEW] Show aggregated logs for a stack",
-		Args:  cmdutil.NoArgs,
+		Long: "[PREVIEW] Show aggregated logs for a stack\n" +
+			"\n" +
+			"The logs of all of the stack's resources are merged in the order they were written. The logs of\n" +
+			"resources in the `aws`, `gcp`, and `cloud` packages are read directly from their cloud, and those\n" +
+			"of other resources are requested from the resource plugins that manage them, if they support it.",
+		Args: cmdutil.NoArgs,
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			opts := display.Options{
 				Color: cmdutil.GetGlobalColorization(),
@@ -83,6 +90,13 @@ func newLogsCmd() *cobra.Command {
 				)
 			}
 
+			// Provider plugins that supply logs are loaded once, and shared by every query when following logs.
+			plugctx, err := plugin.NewContext(cmdutil.Diag(), cmdutil.Diag(), nil, nil, "", nil, nil)
+			if err != nil {
+				return err
+			}
+			defer contract.IgnoreClose(plugctx)
+
 			// IDEA: This map will grow forever as new log entries are found.  We may need to do a more approximate
 			// approach here to ensure we don't grow memory unboundedly while following logs.
 			//
@@ -94,6 +108,7 @@ func newLogsCmd() *cobra.Command {
 				logs, err := s.GetLogs(commandContext(), cfg, operations.LogQuery{
 					StartTime:      startTime,
 					ResourceFilter: resourceFilter,
+					Host:           plugctx.Host,
 				})
 				if err != nil {
 					return errors.Wrapf(err, "failed to get logs")
@@ -54,6 +54,7 @@ func newNewCmd() *cobra.Command {
 	var generateOnly bool
 	var name string
 	var offline bool
+	var parameterArray []string
 	var stack string
 	var yes bool
 	var secretsProvider string
@@ -239,8 +240,14 @@ func newNewCmd() *cobra.Command {
 				}
 			}
 
+			// Prompt for the template's parameters, if it has any.
+			parameters, err := templateParameterValues(template, parameterArray, yes, opts)
+			if err != nil {
+				return err
+			}
+
 			// Actually copy the files.
-			if err = template.CopyTemplateFiles(cwd, force, name, description); err != nil {
+			if err = template.CopyTemplateFiles(cwd, force, name, description, parameters); err != nil {
 				if os.IsNotExist(err) {
 					return errors.Wrapf(err, "template '%s' not found", templateNameOrURL)
 				}
@@ -358,7 +365,10 @@ func newNewCmd() *cobra.Command {
 		"The project name; if not specified, a prompt will request it")
 	cmd.PersistentFlags().BoolVarP(
 		&offline, "offline", "o", false,
-		"Use locally cached templates without making any network requests")
+		"Use locally cached templates without making any network requests; see 'pulumi template'")
+	cmd.PersistentFlags().StringArrayVarP(
+		&parameterArray, "parameter", "p", []string{},
+		"Template parameter values, as NAME=VALUE; parameters that are not specified will be prompted for")
 	cmd.PersistentFlags().StringVarP(
 		&stack, "stack", "s", "",
 		"The stack name; either an existing stack or stack to create; if not specified, a prompt will request it")
@@ -367,7 +377,7 @@ func newNewCmd() *cobra.Command {
 		"Skip prompts and proceed with default values")
 	cmd.PersistentFlags().StringVar(
 		&secretsProvider, "secrets-provider", "default", "The type of the provider that should be used to encrypt and "+
-			"decrypt secrets (possible choices: default, passphrase, awskms, azurekeyvault, gcpkms, hashivault)")
+			"decrypt secrets (possible choices: default, passphrase, awskms, azurekeyvault, gcpkms, hashivault, vault)")
 
 	return cmd
 }
@@ -737,7 +747,7 @@ func promptForConfig(
 		// Encrypt the value if needed.
 		var v config.Value
 		if secret {
-			enc, err := encrypter.EncryptValue(value)
+			enc, err := config.EncryptValueAt(encrypter, value, config.ValueLocation{ConfigKey: k.String()})
 			if err != nil {
 				return nil, err
 			}
@@ -825,6 +835,84 @@ func promptForValue(
 	}
 }
 
+// templateParameterValues returns the values of the template's parameters, keyed by name. Values passed via
+// command line flags as `-p NAME=VALUE` are used as given; the others are prompted for, or take their default
+// values if yes is true.
+func templateParameterValues(
+	template workspace.Template, parameterArray []string, yes bool, opts display.Options) (map[string]string, error) {
+
+	given := make(map[string]string)
+	for _, p := range parameterArray {
+		kvp := strings.SplitN(p, "=", 2)
+		if len(kvp) != 2 {
+			return nil, errors.Errorf("template parameter '%s' must be of the form NAME=VALUE", p)
+		}
+		given[kvp[0]] = kvp[1]
+	}
+
+	values := make(map[string]string)
+	for _, p := range template.Parameters {
+		p := p
+		defaultValue, hasDefault := p.DefaultValue()
+
+		value, ok := given[p.Name]
+		delete(given, p.Name)
+		switch {
+		case ok:
+		case yes && !hasDefault:
+			return nil, errors.Errorf("template parameter '%s' has no default value; pass one with `-p %s=<value>`",
+				p.Name, p.Name)
+		case p.Type == workspace.TemplateParameterTypeChoice && opts.IsInteractive && !yes:
+			message := p.Description
+			if message == "" {
+				message = p.Name
+			}
+			if err := survey.AskOne(&survey.Select{
+				Message: opts.Color.Colorize(colors.SpecPrompt + message + ":" + colors.Reset),
+				Options: p.Choices,
+				Default: defaultValue,
+			}, &value, nil); err != nil {
+				return nil, errors.Wrapf(err, "choosing a value for template parameter '%s'", p.Name)
+			}
+		default:
+			valueType := p.Description
+			if valueType == "" {
+				valueType = p.Name
+			}
+			isValidFn := func(value string) error {
+				_, err := p.Check(value)
+				return err
+			}
+			// Parameters without a default value must be given one.
+			for {
+				var err error
+				if value, err = promptForValue(yes, valueType, defaultValue, false, isValidFn, opts); err != nil {
+					return nil, err
+				}
+				if value != "" || hasDefault {
+					break
+				}
+			}
+		}
+
+		checked, err := p.Check(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "template parameter '%s'", p.Name)
+		}
+		values[p.Name] = checked
+	}
+
+	if len(given) > 0 {
+		var unknown []string
+		for name := range given {
+			unknown = append(unknown, name)
+		}
+		sort.Strings(unknown)
+		return nil, errors.Errorf("template '%s' has no parameter named '%s'", template.Name, unknown[0])
+	}
+	return values, nil
+}
+
 // templatesToOptionArrayAndMap returns an array of option strings and a map of option strings to templates.
 // Each option string is made up of the template name and description with some padding in between.
 func templatesToOptionArrayAndMap(templates []workspace.Template) ([]string, map[string]workspace.Template) {
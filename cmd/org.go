@@ -0,0 +1,187 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/backend/httpstate"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+// orgUsageDateFormat is the format of the dates accepted by `pulumi org usage`.
+const orgUsageDateFormat = "2006-01-02"
+
+func newOrgCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "org",
+		Short: "Manage organizations",
+		Long: "Manage organizations\n" +
+			"\n" +
+			"Organizations are only supported by the Pulumi service.",
+		Args: cmdutil.NoArgs,
+	}
+
+	cmd.AddCommand(newOrgLsCmd())
+	cmd.AddCommand(newOrgMembersCmd())
+	cmd.AddCommand(newOrgInviteCmd())
+	cmd.AddCommand(newOrgUsageCmd())
+
+	return cmd
+}
+
+// writeOrgUsageCSV writes an organization's usage as CSV, with one row per stack followed by a row of totals.
+func writeOrgUsageCSV(w io.Writer, orgName string, summary apitype.OrgUsageSummary) error {
+	start := time.Unix(summary.PeriodStart, 0).UTC().Format(orgUsageDateFormat)
+	end := time.Unix(summary.PeriodEnd, 0).UTC().Format(orgUsageDateFormat)
+
+	out := csv.NewWriter(w)
+	records := [][]string{{
+		"organization", "project", "stack", "period_start", "period_end", "resources_under_management",
+		"deployment_minutes",
+	}}
+	for _, u := range summary.StackUsage {
+		records = append(records, []string{
+			orgName, u.ProjectName, u.StackName, start, end,
+			strconv.Itoa(u.ResourcesUnderManagement), strconv.Itoa(u.DeploymentMinutes),
+		})
+	}
+	records = append(records, []string{
+		orgName, "", "", start, end,
+		strconv.Itoa(summary.ResourcesUnderManagement), strconv.Itoa(summary.DeploymentMinutes),
+	})
+	return out.WriteAll(records)
+}
+
+func newOrgUsageCmd() *cobra.Command {
+	var since string
+	var until string
+	var jsonOut bool
+	var csvOut bool
+
+	cmd := &cobra.Command{
+		Use:   "usage [<org-name>]",
+		Short: "Show an organization's usage of the Pulumi service",
+		Long: "Show an organization's usage of the Pulumi service\n" +
+			"\n" +
+			"This command shows the number of stacks in an organization, the resources they manage, and the\n" +
+			"minutes spent deploying them, broken down by stack. By default it shows the usage of the current\n" +
+			"user's personal organization over its current billing period; `--since` and `--until` select a\n" +
+			"different period, as dates of the form YYYY-MM-DD.\n" +
+			"\n" +
+			"For chargeback reporting, `--csv` emits one row per stack followed by a row of totals:\n" +
+			"* `pulumi org usage acmecorp --since 2019-08-01 --until 2019-09-01 --csv > usage.csv`",
+		Args: cmdutil.MaximumNArgs(1),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			if jsonOut && csvOut {
+				return errors.New("only one of --json or --csv may be specified, not both")
+			}
+
+			var sinceTime, untilTime time.Time
+			var err error
+			if since != "" {
+				if sinceTime, err = time.Parse(orgUsageDateFormat, since); err != nil {
+					return errors.Errorf("invalid --since date '%s'; expected YYYY-MM-DD", since)
+				}
+			}
+			if until != "" {
+				if untilTime, err = time.Parse(orgUsageDateFormat, until); err != nil {
+					return errors.Errorf("invalid --until date '%s'; expected YYYY-MM-DD", until)
+				}
+			}
+
+			b, err := currentBackend(display.Options{Color: cmdutil.GetGlobalColorization()})
+			if err != nil {
+				return err
+			}
+			cloudBackend, ok := b.(httpstate.Backend)
+			if !ok {
+				return errors.New("organization usage is only available from the Pulumi service")
+			}
+
+			var orgName string
+			if len(args) > 0 {
+				orgName = args[0]
+			} else if orgName, err = b.CurrentUser(); err != nil {
+				return err
+			}
+
+			summary, err := cloudBackend.Client().GetOrgUsageSummary(commandContext(), orgName, sinceTime, untilTime)
+			if err != nil {
+				return err
+			}
+			sort.Slice(summary.StackUsage, func(i, j int) bool {
+				if summary.StackUsage[i].ProjectName != summary.StackUsage[j].ProjectName {
+					return summary.StackUsage[i].ProjectName < summary.StackUsage[j].ProjectName
+				}
+				return summary.StackUsage[i].StackName < summary.StackUsage[j].StackName
+			})
+
+			switch {
+			case jsonOut:
+				return printJSON(summary)
+			case csvOut:
+				return writeOrgUsageCSV(os.Stdout, orgName, summary)
+			}
+
+			fmt.Printf("Usage of organization %s from %s to %s:\n", orgName,
+				time.Unix(summary.PeriodStart, 0).UTC().Format(orgUsageDateFormat),
+				time.Unix(summary.PeriodEnd, 0).UTC().Format(orgUsageDateFormat))
+			fmt.Printf("    Stacks: %d\n", summary.Stacks)
+			fmt.Printf("    Resources under management: %d\n", summary.ResourcesUnderManagement)
+			fmt.Printf("    Deployment minutes: %d\n", summary.DeploymentMinutes)
+			if len(summary.StackUsage) == 0 {
+				return nil
+			}
+
+			fmt.Println()
+			rows := []cmdutil.TableRow{}
+			for _, u := range summary.StackUsage {
+				rows = append(rows, cmdutil.TableRow{Columns: []string{
+					u.ProjectName + "/" + u.StackName,
+					strconv.Itoa(u.ResourcesUnderManagement),
+					strconv.Itoa(u.DeploymentMinutes),
+				}})
+			}
+			cmdutil.PrintTable(cmdutil.Table{
+				Headers: []string{"STACK", "RESOURCES", "DEPLOYMENT MINUTES"},
+				Rows:    rows,
+			})
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVar(
+		&since, "since", "", "The first day of the period to show usage for (YYYY-MM-DD)")
+	cmd.PersistentFlags().StringVar(
+		&until, "until", "", "The day after the last day of the period to show usage for (YYYY-MM-DD)")
+	cmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit output as JSON")
+	cmd.PersistentFlags().BoolVar(
+		&csvOut, "csv", false, "Emit output as CSV, with one row per stack followed by a row of totals")
+
+	return cmd
+}
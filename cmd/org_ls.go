@@ -0,0 +1,75 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func newOrgLsCmd() *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "ls",
+		Short: "List the organizations you belong to",
+		Long: "List the organizations you belong to\n" +
+			"\n" +
+			"This command lists the organizations of the Pulumi service that the current user belongs to, along\n" +
+			"with the user's role in each.",
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			b, _, err := requireOrgBackend("")
+			if err != nil {
+				return err
+			}
+
+			orgs, err := b.Client().ListOrganizations(commandContext())
+			if err != nil {
+				return err
+			}
+			sort.Slice(orgs, func(i, j int) bool { return orgs[i].Name < orgs[j].Name })
+
+			if jsonOut {
+				if orgs == nil {
+					orgs = []apitype.Organization{}
+				}
+				return printJSON(orgs)
+			}
+
+			rows := []cmdutil.TableRow{}
+			for _, org := range orgs {
+				rows = append(rows, cmdutil.TableRow{Columns: []string{
+					org.Name, org.DisplayName, string(org.Role), strconv.Itoa(org.Members),
+				}})
+			}
+			cmdutil.PrintTable(cmdutil.Table{
+				Headers: []string{"NAME", "DISPLAY NAME", "ROLE", "MEMBERS"},
+				Rows:    rows,
+			})
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit output as JSON")
+
+	return cmd
+}
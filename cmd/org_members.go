@@ -0,0 +1,236 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/backend/httpstate"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/result"
+)
+
+// requireOrgBackend returns the current backend, which must be the Pulumi service, and the name of the organization to
+// operate on: orgName, if it is set, or else the current user's personal organization.
+func requireOrgBackend(orgName string) (httpstate.Backend, string, error) {
+	b, err := currentBackend(display.Options{Color: cmdutil.GetGlobalColorization()})
+	if err != nil {
+		return nil, "", err
+	}
+	cloudBackend, ok := b.(httpstate.Backend)
+	if !ok {
+		return nil, "", errors.New("organizations are only supported by the Pulumi service")
+	}
+	if orgName == "" {
+		if orgName, err = b.CurrentUser(); err != nil {
+			return nil, "", err
+		}
+	}
+	return cloudBackend, orgName, nil
+}
+
+// parseOrganizationRole parses the role of a member of an organization.
+func parseOrganizationRole(role string) (apitype.OrganizationRole, error) {
+	switch r := apitype.OrganizationRole(role); r {
+	case apitype.OrganizationRoleMember, apitype.OrganizationRoleAdmin:
+		return r, nil
+	default:
+		return "", errors.Errorf("unknown role '%s'; expected '%s' or '%s'", role,
+			apitype.OrganizationRoleMember, apitype.OrganizationRoleAdmin)
+	}
+}
+
+func newOrgMembersCmd() *cobra.Command {
+	var orgName string
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "members",
+		Short: "Manage the members of an organization",
+		Long: "Manage the members of an organization\n" +
+			"\n" +
+			"This command lists the members of an organization and their roles. Members are either admins, who\n" +
+			"may manage the organization, or members, whose access to stacks is granted by their teams and stack\n" +
+			"permissions. By default, the current user's personal organization is used; pass `--org` to manage\n" +
+			"another. To add members, use `pulumi org invite`.",
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			b, orgName, err := requireOrgBackend(orgName)
+			if err != nil {
+				return err
+			}
+
+			members, err := b.Client().ListOrganizationMembers(commandContext(), orgName)
+			if err != nil {
+				return err
+			}
+			sort.Slice(members, func(i, j int) bool { return members[i].Name < members[j].Name })
+
+			if jsonOut {
+				if members == nil {
+					members = []apitype.OrganizationMember{}
+				}
+				return printJSON(members)
+			}
+
+			rows := []cmdutil.TableRow{}
+			for _, m := range members {
+				rows = append(rows, cmdutil.TableRow{Columns: []string{m.Name, string(m.Role)}})
+			}
+			cmdutil.PrintTable(cmdutil.Table{
+				Headers: []string{"NAME", "ROLE"},
+				Rows:    rows,
+			})
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVar(
+		&orgName, "org", "",
+		"The organization to manage. Defaults to the current user's personal organization")
+	cmd.Flags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit output as JSON")
+
+	cmd.AddCommand(newOrgMembersSetRoleCmd(&orgName))
+	cmd.AddCommand(newOrgMembersRmCmd(&orgName))
+
+	return cmd
+}
+
+func newOrgMembersSetRoleCmd(orgName *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-role <user> <role>",
+		Short: "Change the role of a member of an organization",
+		Long: "Change the role of a member of an organization\n" +
+			"\n" +
+			"The role is either `admin` or `member`.",
+		Args: cmdutil.ExactArgs(2),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			role, err := parseOrganizationRole(args[1])
+			if err != nil {
+				return err
+			}
+			b, orgName, err := requireOrgBackend(*orgName)
+			if err != nil {
+				return err
+			}
+
+			if err = b.Client().UpdateOrganizationMember(commandContext(), orgName, args[0], role); err != nil {
+				return err
+			}
+			fmt.Printf("%s is now %s of organization %s\n", args[0], articleRole(role), orgName)
+			return nil
+		}),
+	}
+}
+
+func newOrgMembersRmCmd(orgName *string) *cobra.Command {
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "rm <user>",
+		Short: "Remove a member from an organization",
+		Long: "Remove a member from an organization\n" +
+			"\n" +
+			"The user is also removed from all of the organization's teams, and loses all access to its stacks.",
+		Args: cmdutil.ExactArgs(1),
+		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
+			opts := display.Options{Color: cmdutil.GetGlobalColorization()}
+			b, orgName, err := requireOrgBackend(*orgName)
+			if err != nil {
+				return result.FromError(err)
+			}
+
+			user := args[0]
+			if !yes && !cmdutil.Interactive() {
+				return result.FromError(errYesRequired("removing a member"))
+			}
+			prompt := fmt.Sprintf("This will remove %s from organization %s.", user, orgName)
+			if !yes && !confirmPrompt(prompt, user, opts) {
+				fmt.Println("confirmation declined")
+				return result.Bail()
+			}
+
+			if err = b.Client().RemoveOrganizationMember(commandContext(), orgName, user); err != nil {
+				return result.FromError(err)
+			}
+			fmt.Printf("Removed %s from organization %s\n", user, orgName)
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().BoolVarP(
+		&yes, "yes", "y", false,
+		"Skip confirmation prompts, and proceed with the removal anyway")
+
+	return cmd
+}
+
+func newOrgInviteCmd() *cobra.Command {
+	var orgName string
+	var role string
+
+	cmd := &cobra.Command{
+		Use:   "invite <email>...",
+		Short: "Invite users to join an organization",
+		Long: "Invite users to join an organization\n" +
+			"\n" +
+			"Each user is sent an invitation to the given email address, and becomes a member of the organization\n" +
+			"once they accept it. By default, the current user's personal organization is used, and users are\n" +
+			"invited as members; pass `--org` and `--role admin` to invite admins to another organization.",
+		Args: cmdutil.ArgsFunc(cobra.MinimumNArgs(1)),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			r, err := parseOrganizationRole(role)
+			if err != nil {
+				return err
+			}
+			b, orgName, err := requireOrgBackend(orgName)
+			if err != nil {
+				return err
+			}
+
+			for _, email := range args {
+				if err = b.Client().InviteOrganizationMember(commandContext(), orgName, email, r); err != nil {
+					return errors.Wrapf(err, "inviting %s", email)
+				}
+				fmt.Printf("Invited %s to join organization %s as %s\n", email, orgName, articleRole(r))
+			}
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVar(
+		&orgName, "org", "",
+		"The organization to invite users to. Defaults to the current user's personal organization")
+	cmd.PersistentFlags().StringVar(
+		&role, "role", string(apitype.OrganizationRoleMember),
+		"The role of the invited users: `admin` or `member`")
+
+	return cmd
+}
+
+// articleRole returns the given role with its indefinite article, e.g. "an admin".
+func articleRole(role apitype.OrganizationRole) string {
+	if role == apitype.OrganizationRoleAdmin {
+		return "an admin"
+	}
+	return "a " + string(role)
+}
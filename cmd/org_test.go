@@ -0,0 +1,61 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+)
+
+func TestWriteOrgUsageCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeOrgUsageCSV(&buf, "acme", apitype.OrgUsageSummary{
+		PeriodStart:              1564617600,
+		PeriodEnd:                1567296000,
+		Stacks:                   2,
+		ResourcesUnderManagement: 40,
+		DeploymentMinutes:        95,
+		StackUsage: []apitype.StackUsage{
+			{ProjectName: "web", StackName: "prod", ResourcesUnderManagement: 30, DeploymentMinutes: 60},
+			{ProjectName: "web", StackName: "dev, test", ResourcesUnderManagement: 10, DeploymentMinutes: 35},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t,
+		"organization,project,stack,period_start,period_end,resources_under_management,deployment_minutes\n"+
+			"acme,web,prod,2019-08-01,2019-09-01,30,60\n"+
+			"acme,web,\"dev, test\",2019-08-01,2019-09-01,10,35\n"+
+			"acme,,,2019-08-01,2019-09-01,40,95\n",
+		buf.String())
+}
+
+func TestParseOrganizationRole(t *testing.T) {
+	role, err := parseOrganizationRole("admin")
+	assert.NoError(t, err)
+	assert.Equal(t, apitype.OrganizationRoleAdmin, role)
+	assert.Equal(t, "an admin", articleRole(role))
+
+	role, err = parseOrganizationRole("member")
+	assert.NoError(t, err)
+	assert.Equal(t, apitype.OrganizationRoleMember, role)
+	assert.Equal(t, "a member", articleRole(role))
+
+	_, err = parseOrganizationRole("owner")
+	assert.Error(t, err)
+}
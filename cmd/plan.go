@@ -0,0 +1,82 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func newPlanCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Review saved update plans",
+		Long: "Review saved update plans\n" +
+			"\n" +
+			"Plans are saved by `pulumi preview --save-plan` and applied by `pulumi up --plan`. Subcommands of\n" +
+			"this command show a plan's changes and attach reviewers' notes to them, which are shown when the\n" +
+			"plan is applied.",
+		Args: cmdutil.NoArgs,
+	}
+
+	cmd.AddCommand(newPlanShowCmd())
+	cmd.AddCommand(newPlanAnnotateCmd())
+	return cmd
+}
+
+func newPlanShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <plan-file>",
+		Args:  cmdutil.ExactArgs(1),
+		Short: "Show the changes in a saved update plan",
+		Long: "Show the changes in a saved update plan\n" +
+			"\n" +
+			"Lists the URN of each resource the plan changes, the operations planned for it, and any notes\n" +
+			"attached to them. Resources the plan leaves as they are are not shown.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			plan, err := readUpdatePlan(args[0])
+			if err != nil {
+				return err
+			}
+
+			for _, urn := range plan.Resources() {
+				var ops []string
+				for _, op := range plan.Ops(urn) {
+					if op != deploy.OpSame {
+						ops = append(ops, string(op))
+					}
+				}
+				if len(ops) == 0 {
+					continue
+				}
+
+				fmt.Printf("%s: %s\n", urn, strings.Join(ops, ", "))
+				for _, note := range plan.Notes(urn) {
+					if note.Author != "" {
+						fmt.Printf("    note from %s: %s\n", note.Author, note.Message)
+					} else {
+						fmt.Printf("    note: %s\n", note.Message)
+					}
+				}
+			}
+			return nil
+		}),
+	}
+}
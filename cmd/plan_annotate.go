@@ -0,0 +1,62 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func newPlanAnnotateCmd() *cobra.Command {
+	var author string
+	cmd := &cobra.Command{
+		Use:   "annotate <plan-file> <resource URN> <note>",
+		Args:  cmdutil.ExactArgs(3),
+		Short: "Attach a note to a resource's changes in a saved update plan",
+		Long: "Attach a note to a resource's changes in a saved update plan\n" +
+			"\n" +
+			"The note is saved in the plan file, and is shown alongside the resource's changes when the plan\n" +
+			"is applied with `pulumi up --plan`. This lets reviewers comment on an update's changes before it\n" +
+			"is run.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			path, urn := args[0], resource.URN(args[1])
+
+			plan, err := readUpdatePlan(path)
+			if err != nil {
+				return err
+			}
+			if err = plan.AddNote(urn, engine.PlanNote{Author: author, Message: args[2]}); err != nil {
+				return err
+			}
+			if err = writeUpdatePlan(path, plan); err != nil {
+				return err
+			}
+
+			fmt.Printf("Added a note to %s\n", urn)
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVar(
+		&author, "author", "",
+		"The name of the reviewer writing the note")
+
+	return cmd
+}
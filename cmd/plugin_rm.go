@@ -92,6 +92,9 @@ func newPluginRmCmd() *cobra.Command {
 			}
 
 			// Confirm that the user wants to do this (unless --yes was passed), and do the deletes.
+			if !yes && !cmdutil.Interactive() {
+				return errYesRequired("removing plugins")
+			}
 			var suffix string
 			if len(deletes) != 1 {
 				suffix = "s"
@@ -28,6 +28,13 @@ func newPolicyCmd() *cobra.Command {
 
 	cmd.AddCommand(newPolicyPublishCmd())
 	cmd.AddCommand(newPolicyApplyCmd())
+	cmd.AddCommand(newPolicyDisableCmd())
+	cmd.AddCommand(newPolicyLsCmd())
+	cmd.AddCommand(newPolicyRmCmd())
+	cmd.AddCommand(newPolicyNewCmd())
+	cmd.AddCommand(newPolicyTestCmd())
+	cmd.AddCommand(newPolicyValidateCmd())
+	cmd.AddCommand(newPolicyViolationsCmd())
 
 	return cmd
 }
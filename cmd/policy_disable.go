@@ -0,0 +1,49 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func newPolicyDisableCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "disable <orgName>/<policyPackName>",
+		Args:  cmdutil.ExactArgs(1),
+		Short: "Disable a policy pack for a Pulumi organization",
+		Long: "Disable a policy pack for a Pulumi organization\n" +
+			"\n" +
+			"The policies of the pack are no longer enforced during updates of the organization's stacks, whichever\n" +
+			"version of the pack was applied. The pack's versions remain published, and may be applied again.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			policyPack, err := requirePolicyPack(args[0])
+			if err != nil {
+				return err
+			}
+
+			if err = policyPack.Disable(commandContext()); err != nil {
+				return err
+			}
+			fmt.Printf("Disabled policy pack %s\n", policyPack.Ref())
+			return nil
+		}),
+	}
+
+	return cmd
+}
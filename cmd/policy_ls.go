@@ -0,0 +1,93 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func newPolicyLsCmd() *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "ls [org-name]",
+		Short: "List the policy packs published to an organization",
+		Long: "List the policy packs published to an organization\n" +
+			"\n" +
+			"This command lists the policy packs that have been published to an organization, which defaults to the\n" +
+			"current user's, along with their versions and the version that is applied to the organization, if any.",
+		Args: cmdutil.MaximumNArgs(1),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			var orgName string
+			if len(args) > 0 {
+				orgName = args[0]
+			}
+			b, orgName, err := requireOrgBackend(orgName)
+			if err != nil {
+				return err
+			}
+
+			packs, err := b.Client().ListPolicyPacks(commandContext(), orgName)
+			if err != nil {
+				return err
+			}
+			sort.Slice(packs, func(i, j int) bool { return packs[i].Name < packs[j].Name })
+
+			if jsonOut {
+				if packs == nil {
+					packs = []apitype.PolicyPackSummary{}
+				}
+				return printJSON(packs)
+			}
+
+			rows := []cmdutil.TableRow{}
+			for _, pack := range packs {
+				applied := "-"
+				if pack.AppliedVersion != 0 {
+					applied = strconv.Itoa(pack.AppliedVersion)
+				}
+				rows = append(rows, cmdutil.TableRow{Columns: []string{
+					pack.Name, pack.DisplayName, formatPolicyPackVersions(pack.Versions), applied,
+				}})
+			}
+			cmdutil.PrintTable(cmdutil.Table{
+				Headers: []string{"NAME", "DISPLAY NAME", "VERSIONS", "APPLIED"},
+				Rows:    rows,
+			})
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit output as JSON")
+
+	return cmd
+}
+
+// formatPolicyPackVersions formats a list of policy pack versions for display.
+func formatPolicyPackVersions(versions []int) string {
+	strs := make([]string, len(versions))
+	for i, v := range versions {
+		strs[i] = strconv.Itoa(v)
+	}
+	return strings.Join(strs, ", ")
+}
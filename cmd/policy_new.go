@@ -0,0 +1,184 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func newPolicyNewCmd() *cobra.Command {
+	var dir string
+	var force bool
+	var generateOnly bool
+	var name string
+	var offline bool
+
+	cmd := &cobra.Command{
+		Use:   "new [template|url]",
+		Short: "Create a new Pulumi policy pack",
+		Long: "Create a new Pulumi policy pack from a template.\n" +
+			"\n" +
+			"To create a policy pack from a specific template, pass the template name (such as\n" +
+			"`aws-typescript`). If no template name is provided, a list of suggested templates will be\n" +
+			"presented which can be selected interactively.\n" +
+			"\n" +
+			"Once created, the policy pack can be tested locally with `pulumi policy test`.",
+		Args: cmdutil.MaximumNArgs(1),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			opts := display.Options{
+				Color:         cmdutil.GetGlobalColorization(),
+				IsInteractive: cmdutil.Interactive(),
+			}
+
+			// Validate name (if specified) before further operations.
+			if name != "" && workspace.ValidateProjectName(name) != nil {
+				return errors.Errorf("'%s' is not a valid policy pack name. %s.", name, workspace.ValidateProjectName(name))
+			}
+
+			// Get the directory to create the policy pack in, creating it if necessary.
+			cwd, err := os.Getwd()
+			if err != nil {
+				return errors.Wrap(err, "getting the working directory")
+			}
+			if dir != "" {
+				if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+					return errors.Wrap(err, "creating the directory")
+				}
+				if err = os.Chdir(dir); err != nil {
+					return errors.Wrap(err, "changing the working directory")
+				}
+				if cwd, err = os.Getwd(); err != nil {
+					return errors.Wrap(err, "getting the working directory")
+				}
+			}
+
+			// Return an error if the directory isn't empty.
+			if !force {
+				if err = errorIfNotEmptyDirectory(cwd); err != nil {
+					return err
+				}
+			}
+
+			templateNameOrURL := ""
+			if len(args) > 0 {
+				templateNameOrURL = args[0]
+			}
+
+			// Retrieve the policy template repo and pick a template from it.
+			repo, err := workspace.RetrievePolicyTemplates(templateNameOrURL, offline)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				contract.IgnoreError(repo.Delete())
+			}()
+
+			templates, err := repo.Templates()
+			if err != nil {
+				return err
+			}
+
+			var template workspace.Template
+			if len(templates) == 0 {
+				return errors.New("no templates")
+			} else if len(templates) == 1 {
+				template = templates[0]
+			} else {
+				if template, err = chooseTemplate(templates, opts); err != nil {
+					return err
+				}
+			}
+
+			// Do a dry run, if we're not forcing files to be overwritten.
+			if !force {
+				if err = template.CopyTemplateFilesDryRun(cwd); err != nil {
+					if os.IsNotExist(err) {
+						return errors.Wrapf(err, "template '%s' not found", templateNameOrURL)
+					}
+					return err
+				}
+			}
+
+			// Policy packs are named after their directory unless a name was given explicitly.
+			name = workspace.ValueOrSanitizedDefaultProjectName(name, template.ProjectName, filepath.Base(cwd))
+			description := template.ProjectDescription
+
+			// Policy packs are created without prompting, so their templates' parameters take their default values.
+			parameters, err := templateParameterValues(template, nil, true /*yes*/, opts)
+			if err != nil {
+				return err
+			}
+
+			if err = template.CopyTemplateFiles(cwd, force, name, description, parameters); err != nil {
+				if os.IsNotExist(err) {
+					return errors.Wrapf(err, "template '%s' not found", templateNameOrURL)
+				}
+				return err
+			}
+
+			fmt.Printf("Created policy pack '%s'\n", name)
+			fmt.Println()
+
+			if !generateOnly {
+				if err := installDependencies(); err != nil {
+					return err
+				}
+			}
+
+			fmt.Println(
+				opts.Color.Colorize(
+					colors.BrightGreen+colors.Bold+"Your new policy pack is ready to go!"+colors.Reset) +
+					" " + cmdutil.EmojiOr("✨", ""))
+			fmt.Println()
+			fmt.Println("To test your policies against a recorded preview, run `pulumi policy test <fixture>`.")
+			fmt.Println("To publish them to the Pulumi service, run `pulumi policy publish <orgName>/" + name + "`.")
+
+			if template.Quickstart != "" {
+				fmt.Println(template.Quickstart)
+			}
+
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVar(
+		&dir, "dir", "",
+		"The location to place the generated policy pack; if not specified, the current directory is used")
+	cmd.PersistentFlags().BoolVarP(
+		&force, "force", "f", false,
+		"Forces content to be generated even if it would change existing files")
+	cmd.PersistentFlags().BoolVarP(
+		&generateOnly, "generate-only", "g", false,
+		"Generate the policy pack only; do not install dependencies")
+	cmd.PersistentFlags().StringVarP(
+		&name, "name", "n", "",
+		"The policy pack name; if not specified, the name of the directory is used")
+	cmd.PersistentFlags().BoolVarP(
+		&offline, "offline", "o", false,
+		"Use locally cached templates without making any network requests")
+
+	return cmd
+}
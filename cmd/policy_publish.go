@@ -16,6 +16,8 @@ package cmd
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/pulumi/pulumi/pkg/engine"
 
@@ -112,3 +114,39 @@ func requirePolicyPack(policyPack string) (backend.PolicyPack, error) {
 
 	return nil, fmt.Errorf("Could not find PolicyPack %q", policyPack)
 }
+
+// parseVersionedPolicyPack splits a string like "myOrg/mySecurityRules@3" into its PolicyPack name and version.
+func parseVersionedPolicyPack(s string) (string, int, error) {
+	at := strings.LastIndex(s, "@")
+	if at == -1 {
+		return "", 0, errors.Errorf(
+			"could not parse policy pack %q; must be of the form <orgName>/<policyPackName>@<version>", s)
+	}
+	version, err := strconv.Atoi(s[at+1:])
+	if err != nil || version <= 0 {
+		return "", 0, errors.Errorf("could not parse version of policy pack %q (should be a positive integer)", s)
+	}
+	return s[:at], version, nil
+}
+
+// requireRemotePolicies downloads the given versions of published PolicyPacks so that they may be run locally as
+// part of an update.
+func requireRemotePolicies(policyPacks []string) ([]engine.RequiredPolicy, error) {
+	var policies []engine.RequiredPolicy
+	for _, s := range policyPacks {
+		name, version, err := parseVersionedPolicyPack(s)
+		if err != nil {
+			return nil, err
+		}
+		policyPack, err := requirePolicyPack(name)
+		if err != nil {
+			return nil, err
+		}
+		policy, err := policyPack.Required(commandContext(), version)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting policy pack %q", s)
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
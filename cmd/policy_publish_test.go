@@ -0,0 +1,61 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVersionedPolicyPack(t *testing.T) {
+	tests := []struct {
+		PolicyPack  string
+		WantName    string
+		WantVersion int
+		WantErr     bool
+	}{
+		{PolicyPack: "acme/security@1", WantName: "acme/security", WantVersion: 1},
+		{PolicyPack: "acme/security@12", WantName: "acme/security", WantVersion: 12},
+
+		// Only the last @ separates the version, so the name may contain one.
+		{PolicyPack: "acme/sec@rity@3", WantName: "acme/sec@rity", WantVersion: 3},
+
+		// Missing @
+		{PolicyPack: "", WantErr: true},
+		{PolicyPack: "acme/security", WantErr: true},
+		{PolicyPack: "acme/security-3", WantErr: true},
+
+		// Missing, non-numeric or non-positive version
+		{PolicyPack: "acme/security@", WantErr: true},
+		{PolicyPack: "acme/security@latest", WantErr: true},
+		{PolicyPack: "acme/security@1.0", WantErr: true},
+		{PolicyPack: "acme/security@0", WantErr: true},
+		{PolicyPack: "acme/security@-1", WantErr: true},
+		{PolicyPack: "acme/sec@rity", WantErr: true},
+	}
+
+	for _, test := range tests {
+		name, version, err := parseVersionedPolicyPack(test.PolicyPack)
+		if test.WantErr {
+			assert.Error(t, err, "parseVersionedPolicyPack(%q)", test.PolicyPack)
+			continue
+		}
+		if assert.NoError(t, err, "parseVersionedPolicyPack(%q)", test.PolicyPack) {
+			assert.Equal(t, test.WantName, name, "parseVersionedPolicyPack(%q) name", test.PolicyPack)
+			assert.Equal(t, test.WantVersion, version, "parseVersionedPolicyPack(%q) version", test.PolicyPack)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/result"
+)
+
+func newPolicyRmCmd() *cobra.Command {
+	var yes bool
+
+	var cmd = &cobra.Command{
+		Use:   "rm <orgName>/<policyPackName> <version|all>",
+		Args:  cmdutil.ExactArgs(2),
+		Short: "Remove a policy pack from a Pulumi organization",
+		Long: "Remove a policy pack from a Pulumi organization\n" +
+			"\n" +
+			"This command removes the given version of a policy pack from an organization, or every version of it if\n" +
+			"the version is 'all'. A version that is applied to the organization can't be removed; run\n" +
+			"`pulumi policy disable` first.",
+		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
+			opts := display.Options{Color: cmdutil.GetGlobalColorization()}
+
+			version, err := parsePolicyPackVersion(args[1])
+			if err != nil {
+				return result.FromError(err)
+			}
+
+			policyPack, err := requirePolicyPack(args[0])
+			if err != nil {
+				return result.FromError(err)
+			}
+
+			// Check that the version exists and isn't applied, so that a mistake is caught before anything is removed.
+			versions, err := policyPack.Versions(commandContext())
+			if err != nil {
+				return result.FromError(err)
+			}
+			found := false
+			for _, v := range versions {
+				if version != 0 && v.Version != version {
+					continue
+				}
+				found = true
+				if v.Applied {
+					return result.Errorf("version %d of policy pack %s is applied to the organization; "+
+						"run `pulumi policy disable` first", v.Version, policyPack.Ref())
+				}
+			}
+			if !found {
+				if version == 0 {
+					return result.Errorf("policy pack %s has no published versions", policyPack.Ref())
+				}
+				return result.Errorf("policy pack %s has no version %d", policyPack.Ref(), version)
+			}
+
+			what := fmt.Sprintf("version %d of policy pack %s", version, policyPack.Ref())
+			if version == 0 {
+				what = fmt.Sprintf("every version of policy pack %s", policyPack.Ref())
+			}
+			if !yes && !cmdutil.Interactive() {
+				return result.FromError(errYesRequired("removing a policy pack"))
+			}
+			prompt := fmt.Sprintf("This will permanently remove %s.", what)
+			if !yes && !confirmPrompt(prompt, args[0], opts) {
+				fmt.Println("confirmation declined")
+				return result.Bail()
+			}
+
+			if err = policyPack.Remove(commandContext(), version); err != nil {
+				return result.FromError(err)
+			}
+			fmt.Printf("Removed %s\n", what)
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().BoolVarP(
+		&yes, "yes", "y", false,
+		"Skip confirmation prompts, and proceed with removal anyway")
+
+	return cmd
+}
+
+// parsePolicyPackVersion parses the version of a policy pack to remove, returning 0 for every version.
+func parsePolicyPackVersion(s string) (int, error) {
+	if s == "all" {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(s)
+	if err != nil || version < 1 {
+		return 0, errors.Errorf("invalid version '%s'; expected a positive integer or 'all'", s)
+	}
+	return version, nil
+}
@@ -0,0 +1,39 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePolicyPackVersion(t *testing.T) {
+	v, err := parsePolicyPackVersion("all")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, v)
+
+	v, err = parsePolicyPackVersion("3")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, v)
+
+	for _, s := range []string{"", "0", "-1", "latest", "1.0"} {
+		_, err = parsePolicyPackVersion(s)
+		assert.Error(t, err, s)
+	}
+
+	assert.Equal(t, "1, 2, 5", formatPolicyPackVersions([]int{1, 2, 5}))
+	assert.Equal(t, "", formatPolicyPackVersions(nil))
+}
@@ -0,0 +1,266 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+// policyTestFixture is a recorded preview, as produced by `pulumi preview --json`, optionally annotated with the
+// policy violations that the policy pack is expected to report for it.
+type policyTestFixture struct {
+	Steps []struct {
+		Op       deploy.StepOp       `json:"op"`
+		URN      resource.URN        `json:"urn"`
+		NewState *apitype.ResourceV3 `json:"newState,omitempty"`
+	} `json:"steps,omitempty"`
+
+	// ExpectedViolations, if present, lists exactly the violations the policy pack should report. If absent, the
+	// fixture passes as long as no mandatory policy is violated.
+	ExpectedViolations *[]policyTestViolation `json:"expectedViolations,omitempty"`
+}
+
+// policyTestViolation is a policy violation reported for a single resource.
+type policyTestViolation struct {
+	URN              resource.URN             `json:"urn"`
+	Policy           string                   `json:"policy"`
+	Message          string                   `json:"message,omitempty"`
+	EnforcementLevel apitype.EnforcementLevel `json:"enforcementLevel,omitempty"`
+}
+
+// policyTestRemediation is a modification of a single resource's inputs, made by a policy so that the resource
+// complies with it.
+type policyTestRemediation struct {
+	URN         resource.URN `json:"urn"`
+	Policy      string       `json:"policy"`
+	Description string       `json:"description,omitempty"`
+}
+
+// policyTestResult is the outcome of running a policy pack against a single fixture.
+type policyTestResult struct {
+	Violations   []policyTestViolation   // all violations reported by the policy pack.
+	Remediations []policyTestRemediation // all remediations made by the policy pack.
+	Unexpected   []policyTestViolation   // violations that were reported but not expected.
+	Missing      []policyTestViolation   // violations that were expected but not reported.
+}
+
+// Passed returns true if the fixture's expectations were met.
+func (r policyTestResult) Passed() bool {
+	return len(r.Unexpected) == 0 && len(r.Missing) == 0
+}
+
+func newPolicyTestCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "test <fixture>...",
+		Args:  cmdutil.ArgsFunc(cobra.MinimumNArgs(1)),
+		Short: "Run the policy pack in the current directory against recorded previews",
+		Long: "Run the policy pack in the current directory against recorded previews.\n" +
+			"\n" +
+			"Each fixture is the output of `pulumi preview --json` for some program. Every resource the preview\n" +
+			"would create or update is checked against the policy pack, without requiring a live stack.\n" +
+			"\n" +
+			"By default, a fixture fails if any mandatory policy is violated. A fixture can instead list the\n" +
+			"violations it expects in a top-level `expectedViolations` array of `{\"urn\": ..., \"policy\": ...}`\n" +
+			"objects, in which case it fails unless exactly those violations are reported.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			analyzer, plugctx, err := loadLocalPolicyPack()
+			if err != nil {
+				return err
+			}
+			defer contract.IgnoreClose(plugctx)
+
+			color := cmdutil.GetGlobalColorization()
+			failed := 0
+			for _, path := range args {
+				fixture, err := loadPolicyTestFixture(path)
+				if err != nil {
+					return err
+				}
+				result, err := runPolicyTestFixture(analyzer, fixture)
+				if err != nil {
+					return errors.Wrapf(err, "running policies against %s", path)
+				}
+
+				if result.Passed() {
+					fmt.Println(color.Colorize(fmt.Sprintf("%sPASS%s %s", colors.SpecCreate, colors.Reset, path)))
+				} else {
+					failed++
+					fmt.Println(color.Colorize(fmt.Sprintf("%sFAIL%s %s", colors.SpecError, colors.Reset, path)))
+				}
+				for _, v := range result.Unexpected {
+					fmt.Printf("    unexpected violation of %s by %s: %s\n", v.Policy, v.URN, v.Message)
+				}
+				for _, v := range result.Missing {
+					fmt.Printf("    expected violation of %s by %s was not reported\n", v.Policy, v.URN)
+				}
+				for _, r := range result.Remediations {
+					fmt.Printf("    %s remediated %s: %s\n", r.Policy, r.URN, r.Description)
+				}
+			}
+
+			if failed > 0 {
+				return errors.Errorf("%d of %d policy test fixtures failed", failed, len(args))
+			}
+			return nil
+		}),
+	}
+
+	return cmd
+}
+
+// loadLocalPolicyPack loads the policy pack in the current directory. The returned plugin context must be closed once
+// the policy pack is no longer needed.
+func loadLocalPolicyPack() (plugin.Analyzer, *plugin.Context, error) {
+	proj, root, err := readProject(pulumiPolicyProj)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	projinfo := &engine.Projinfo{Proj: proj, Root: root}
+	pwd, _ /*main*/, plugctx, err := engine.ProjectInfoContext(
+		projinfo, nil, nil, cmdutil.Diag(), cmdutil.Diag(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	analyzer, err := plugctx.Host.PolicyAnalyzer(tokens.QName(proj.Name), pwd)
+	if err != nil {
+		contract.IgnoreClose(plugctx)
+		return nil, nil, err
+	}
+	return analyzer, plugctx, nil
+}
+
+// loadPolicyTestFixture reads a policy test fixture from the given file.
+func loadPolicyTestFixture(path string) (policyTestFixture, error) {
+	b, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return policyTestFixture{}, errors.Wrapf(err, "reading fixture %s", path)
+	}
+	var fixture policyTestFixture
+	if err = json.Unmarshal(b, &fixture); err != nil {
+		return policyTestFixture{}, errors.Wrapf(err, "could not parse fixture %s", path)
+	}
+	return fixture, nil
+}
+
+// policyResource is a resource to be checked against a policy pack.
+type policyResource struct {
+	URN    resource.URN
+	Type   tokens.Type
+	Inputs resource.PropertyMap
+}
+
+// resources returns the resources the fixture's preview would create or update.
+func (fixture policyTestFixture) resources() []policyResource {
+	var resources []policyResource
+	for _, step := range fixture.Steps {
+		// Deleted resources have no new state, and are never analyzed by the engine either.
+		if step.NewState == nil {
+			continue
+		}
+		resources = append(resources, policyResource{
+			URN:    step.URN,
+			Type:   step.NewState.Type,
+			Inputs: resource.NewPropertyMapFromMap(step.NewState.Inputs),
+		})
+	}
+	return resources
+}
+
+// analyzePolicyResources checks each of the given resources against a policy pack, and returns the violations that it
+// reports and the remediations that it makes, in the order of the resources.
+func analyzePolicyResources(analyzer plugin.Analyzer,
+	resources []policyResource) ([]policyTestViolation, []policyTestRemediation, error) {
+
+	var violations []policyTestViolation
+	var remediations []policyTestRemediation
+	for _, res := range resources {
+		diags, rems, err := analyzer.Analyze(res.Type, res.Inputs)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "analyzing %s", res.URN)
+		}
+		for _, d := range diags {
+			violations = append(violations, policyTestViolation{
+				URN:              res.URN,
+				Policy:           d.PolicyName,
+				Message:          d.Message,
+				EnforcementLevel: d.EnforcementLevel,
+			})
+		}
+		for _, r := range rems {
+			remediations = append(remediations, policyTestRemediation{
+				URN:         res.URN,
+				Policy:      r.PolicyName,
+				Description: r.Description,
+			})
+		}
+	}
+	return violations, remediations, nil
+}
+
+// runPolicyTestFixture analyzes each resource the given fixture would create or update, and compares the resulting
+// violations against the fixture's expectations.
+func runPolicyTestFixture(analyzer plugin.Analyzer, fixture policyTestFixture) (policyTestResult, error) {
+	violations, remediations, err := analyzePolicyResources(analyzer, fixture.resources())
+	if err != nil {
+		return policyTestResult{}, err
+	}
+	result := policyTestResult{Violations: violations, Remediations: remediations}
+
+	if fixture.ExpectedViolations == nil {
+		for _, v := range result.Violations {
+			if v.EnforcementLevel == apitype.Mandatory {
+				result.Unexpected = append(result.Unexpected, v)
+			}
+		}
+		return result, nil
+	}
+
+	key := func(v policyTestViolation) string { return string(v.URN) + "\x00" + v.Policy }
+	expected := make(map[string]policyTestViolation)
+	for _, v := range *fixture.ExpectedViolations {
+		expected[key(v)] = v
+	}
+	for _, v := range result.Violations {
+		if _, has := expected[key(v)]; has {
+			delete(expected, key(v))
+		} else {
+			result.Unexpected = append(result.Unexpected, v)
+		}
+	}
+	for _, v := range expected {
+		result.Missing = append(result.Missing, v)
+	}
+	sort.Slice(result.Missing, func(i, j int) bool { return key(result.Missing[i]) < key(result.Missing[j]) })
+	return result, nil
+}
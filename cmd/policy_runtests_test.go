@@ -0,0 +1,129 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// publicBucketAnalyzer reports a mandatory violation for every bucket with a public ACL, or, if remediate is set, makes
+// the bucket private.
+type publicBucketAnalyzer struct {
+	remediate bool
+}
+
+func (a *publicBucketAnalyzer) Close() error       { return nil }
+func (a *publicBucketAnalyzer) Name() tokens.QName { return "test" }
+
+func (a *publicBucketAnalyzer) Analyze(t tokens.Type,
+	props resource.PropertyMap) ([]plugin.AnalyzeDiagnostic, []plugin.Remediation, error) {
+
+	if acl, has := props["acl"]; has && acl.IsString() && acl.StringValue() == "public-read" {
+		if a.remediate {
+			remediated := props.Copy()
+			remediated["acl"] = resource.NewStringProperty("private")
+			return nil, []plugin.Remediation{{
+				PolicyName:  "no-public-buckets",
+				Description: "made the bucket private",
+				Properties:  remediated,
+			}}, nil
+		}
+		return []plugin.AnalyzeDiagnostic{{
+			PolicyName:       "no-public-buckets",
+			Message:          "buckets must not be public",
+			EnforcementLevel: apitype.Mandatory,
+		}}, nil, nil
+	}
+	return nil, nil, nil
+}
+
+func (a *publicBucketAnalyzer) GetAnalyzerInfo() (plugin.AnalyzerInfo, error) {
+	return plugin.AnalyzerInfo{Name: "test"}, nil
+}
+
+func (a *publicBucketAnalyzer) GetPluginInfo() (workspace.PluginInfo, error) {
+	return workspace.PluginInfo{Name: "test"}, nil
+}
+
+const policyTestPreview = `{
+	"steps": [
+		{"op": "create", "urn": "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::public",
+		 "newState": {"urn": "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::public", "custom": true,
+		              "type": "aws:s3/bucket:Bucket", "inputs": {"acl": "public-read"}}},
+		{"op": "create", "urn": "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::private",
+		 "newState": {"urn": "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::private", "custom": true,
+		              "type": "aws:s3/bucket:Bucket", "inputs": {"acl": "private"}}},
+		{"op": "delete", "urn": "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::old"}
+	]%s
+}`
+
+func TestRunPolicyTestFixture(t *testing.T) {
+	load := func(expectations string) policyTestFixture {
+		var fixture policyTestFixture
+		assert.NoError(t, json.Unmarshal([]byte(fmt.Sprintf(policyTestPreview, expectations)), &fixture))
+		return fixture
+	}
+
+	// Without expectations, mandatory violations fail the fixture.
+	result, err := runPolicyTestFixture(&publicBucketAnalyzer{}, load(""))
+	assert.NoError(t, err)
+	assert.False(t, result.Passed())
+	assert.Len(t, result.Violations, 1)
+	assert.Equal(t, "no-public-buckets", result.Unexpected[0].Policy)
+
+	// Expected violations pass.
+	result, err = runPolicyTestFixture(&publicBucketAnalyzer{}, load(`,
+	"expectedViolations": [
+		{"urn": "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::public", "policy": "no-public-buckets"}
+	]`))
+	assert.NoError(t, err)
+	assert.True(t, result.Passed())
+
+	// Expected violations that are not reported fail.
+	result, err = runPolicyTestFixture(&publicBucketAnalyzer{}, load(`,
+	"expectedViolations": [
+		{"urn": "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::public", "policy": "no-public-buckets"},
+		{"urn": "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::private", "policy": "no-public-buckets"}
+	]`))
+	assert.NoError(t, err)
+	assert.False(t, result.Passed())
+	assert.Empty(t, result.Unexpected)
+	assert.Len(t, result.Missing, 1)
+}
+
+func TestRunPolicyTestFixtureRemediations(t *testing.T) {
+	var fixture policyTestFixture
+	assert.NoError(t, json.Unmarshal([]byte(fmt.Sprintf(policyTestPreview, "")), &fixture))
+
+	// Remediations are reported, but don't fail the fixture.
+	result, err := runPolicyTestFixture(&publicBucketAnalyzer{remediate: true}, fixture)
+	assert.NoError(t, err)
+	assert.True(t, result.Passed())
+	assert.Equal(t, []policyTestRemediation{{
+		URN:         "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::public",
+		Policy:      "no-public-buckets",
+		Description: "made the bucket private",
+	}}, result.Remediations)
+}
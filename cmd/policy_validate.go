@@ -0,0 +1,176 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+func newPolicyValidateCmd() *cobra.Command {
+	var jsonOut bool
+
+	var cmd = &cobra.Command{
+		Use:   "validate <file>",
+		Args:  cmdutil.ExactArgs(1),
+		Short: "Check a stack snapshot or recorded preview against the policy pack in the current directory",
+		Long: "Check a stack snapshot or recorded preview against the policy pack in the current directory.\n" +
+			"\n" +
+			"The file is either the output of `pulumi stack export`, in which case every resource in the stack is\n" +
+			"checked, or the output of `pulumi preview --json`, in which case every resource the preview would\n" +
+			"create or update is checked. The Pulumi service is not contacted, and secret values are hidden from\n" +
+			"the policy pack.\n" +
+			"\n" +
+			"Every violation is reported along with the resource that caused it, as is every remediation the\n" +
+			"policy pack would make to a resource's inputs. The command fails if any mandatory policy is violated.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			resources, err := loadPolicyValidationResources(args[0])
+			if err != nil {
+				return err
+			}
+
+			analyzer, plugctx, err := loadLocalPolicyPack()
+			if err != nil {
+				return err
+			}
+			defer contract.IgnoreClose(plugctx)
+
+			violations, remediations, err := analyzePolicyResources(analyzer, resources)
+			if err != nil {
+				return err
+			}
+
+			mandatory := 0
+			for _, v := range violations {
+				if v.EnforcementLevel == apitype.Mandatory {
+					mandatory++
+				}
+			}
+
+			if jsonOut {
+				if violations == nil {
+					violations = []policyTestViolation{}
+				}
+				if remediations == nil {
+					remediations = []policyTestRemediation{}
+				}
+				if err = printJSON(policyValidateJSON{Violations: violations, Remediations: remediations}); err != nil {
+					return err
+				}
+			} else if len(violations) == 0 && len(remediations) == 0 {
+				fmt.Printf("No policy violations found in %d resources.\n", len(resources))
+			} else {
+				rows := []cmdutil.TableRow{}
+				for _, v := range violations {
+					rows = append(rows, cmdutil.TableRow{Columns: []string{
+						string(v.EnforcementLevel), v.Policy, string(v.URN), v.Message,
+					}})
+				}
+				for _, r := range remediations {
+					rows = append(rows, cmdutil.TableRow{Columns: []string{
+						"remediated", r.Policy, string(r.URN), r.Description,
+					}})
+				}
+				cmdutil.PrintTable(cmdutil.Table{
+					Headers: []string{"SEVERITY", "POLICY", "RESOURCE", "MESSAGE"},
+					Rows:    rows,
+				})
+			}
+
+			if mandatory > 0 {
+				return errors.Errorf("%d mandatory policy violations found", mandatory)
+			}
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit output as JSON")
+
+	return cmd
+}
+
+// policyValidateJSON is the output of `pulumi policy validate --json`.
+type policyValidateJSON struct {
+	Violations   []policyTestViolation   `json:"violations"`
+	Remediations []policyTestRemediation `json:"remediations"`
+}
+
+// loadPolicyValidationResources reads the resources to check against a policy pack from the given file, which holds
+// either an exported stack or a recorded preview.
+func loadPolicyValidationResources(path string) ([]policyResource, error) {
+	b, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s", path)
+	}
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(b, &fields); err != nil {
+		return nil, errors.Wrapf(err, "could not parse %s", path)
+	}
+
+	// A recorded preview is also a policy test fixture.
+	if _, isDeployment := fields["deployment"]; !isDeployment {
+		if _, isPreview := fields["steps"]; !isPreview {
+			return nil, errors.Errorf("%s is neither an exported stack nor a recorded preview", path)
+		}
+		var fixture policyTestFixture
+		if err = json.Unmarshal(b, &fixture); err != nil {
+			return nil, errors.Wrapf(err, "could not parse preview %s", path)
+		}
+		return fixture.resources(), nil
+	}
+
+	var untyped apitype.UntypedDeployment
+	if err = json.Unmarshal(b, &untyped); err != nil {
+		return nil, errors.Wrapf(err, "could not parse deployment %s", path)
+	}
+	deployment, err := stack.UnmarshalUntypedDeployment(&untyped)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read deployment %s", path)
+	}
+
+	// Secrets are blinded rather than decrypted, so that no secrets provider is needed.
+	var resources []policyResource
+	for _, res := range deployment.Resources {
+		// Resources pending deletion are no longer part of the program, so policies don't apply to them.
+		if res.Delete {
+			continue
+		}
+		state, err := stack.DeserializeResource(res, blindingPropertyDecrypter{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading resource %s", res.URN)
+		}
+		resources = append(resources, policyResource{URN: state.URN, Type: state.Type, Inputs: state.Inputs})
+	}
+	return resources, nil
+}
+
+// blindingPropertyDecrypter blinds the secret property values of a serialized resource. Unlike those of configuration
+// values, their plaintexts are JSON values.
+type blindingPropertyDecrypter struct{}
+
+func (blindingPropertyDecrypter) DecryptValue(ciphertext string) (string, error) {
+	return `"[secret]"`, nil
+}
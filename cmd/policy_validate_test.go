@@ -0,0 +1,87 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/resource"
+)
+
+const policyValidateSnapshot = `{
+	"version": 3,
+	"deployment": {
+		"manifest": {"time": "2019-09-01T00:00:00Z", "magic": "", "version": ""},
+		"resources": [
+			{"urn": "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::public", "custom": true,
+			 "type": "aws:s3/bucket:Bucket",
+			 "inputs": {"acl": "public-read", "password": {
+				"4dabf18193072939515e22adb298388d": "1b47061264138c4ac30d75fd1eb44270",
+				"ciphertext": "AAABAA=="}}},
+			{"urn": "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::old", "custom": true, "delete": true,
+			 "type": "aws:s3/bucket:Bucket", "inputs": {"acl": "public-read"}},
+			{"urn": "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::private", "custom": true,
+			 "type": "aws:s3/bucket:Bucket", "inputs": {"acl": "private"}}
+		]
+	}
+}`
+
+func TestLoadPolicyValidationResources(t *testing.T) {
+	dir, err := ioutil.TempDir("", "policy-validate")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	write := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, ioutil.WriteFile(path, []byte(contents), 0600))
+		return path
+	}
+
+	// Resources pending deletion are skipped, and secrets are blinded.
+	resources, err := loadPolicyValidationResources(write("stack.json", policyValidateSnapshot))
+	assert.NoError(t, err)
+	if assert.Len(t, resources, 2) {
+		assert.Equal(t, resource.URN("urn:pulumi:dev::proj::aws:s3/bucket:Bucket::public"), resources[0].URN)
+		password := resources[0].Inputs["password"]
+		assert.True(t, password.IsSecret())
+		assert.Equal(t, "[secret]", password.SecretValue().Element.StringValue())
+		assert.Equal(t, resource.URN("urn:pulumi:dev::proj::aws:s3/bucket:Bucket::private"), resources[1].URN)
+	}
+
+	violations, remediations, err := analyzePolicyResources(&publicBucketAnalyzer{}, resources)
+	assert.NoError(t, err)
+	assert.Equal(t, []policyTestViolation{{
+		URN:              "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::public",
+		Policy:           "no-public-buckets",
+		Message:          "buckets must not be public",
+		EnforcementLevel: apitype.Mandatory,
+	}}, violations)
+	assert.Empty(t, remediations)
+
+	// Recorded previews are read like policy test fixtures.
+	resources, err = loadPolicyValidationResources(write("preview.json", fmt.Sprintf(policyTestPreview, "")))
+	assert.NoError(t, err)
+	assert.Len(t, resources, 2)
+
+	_, err = loadPolicyValidationResources(write("other.json", `{"config": {}}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,269 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/backend/httpstate"
+	"github.com/pulumi/pulumi/pkg/backend/httpstate/client"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func newPolicyViolationsCmd() *cobra.Command {
+	var enforcementLevel string
+	var jsonOut bool
+	var policy string
+	var policyPack string
+	var project string
+	var since string
+	var stack string
+	var trend bool
+	var until string
+
+	cmd := &cobra.Command{
+		Use:   "violations [<org-name>]",
+		Short: "Show the policy violations that have occurred in an organization's stacks",
+		Long: "Show the policy violations that have occurred in an organization's stacks\n" +
+			"\n" +
+			"This command lists the policy violations recorded during the updates and previews of an\n" +
+			"organization's stacks, newest first. By default it shows the violations in the current user's\n" +
+			"personal organization; `--stack` shows those of a single stack instead. The violations can be\n" +
+			"filtered by project, policy pack, policy, enforcement level, and by date with `--since` and\n" +
+			"`--until`, as dates of the form YYYY-MM-DD.\n" +
+			"\n" +
+			"To see how violations trend over time, `--trend` counts them by day instead:\n" +
+			"* `pulumi policy violations acmecorp --since 2019-08-01 --trend`\n" +
+			"\n" +
+			"Policy violations are only recorded by the Pulumi service.",
+		Args: cmdutil.MaximumNArgs(1),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			var filter client.PolicyViolationsFilter
+			var err error
+			if since != "" {
+				if filter.Since, err = time.Parse(orgUsageDateFormat, since); err != nil {
+					return errors.Errorf("invalid --since date '%s'; expected YYYY-MM-DD", since)
+				}
+			}
+			if until != "" {
+				if filter.Until, err = time.Parse(orgUsageDateFormat, until); err != nil {
+					return errors.Errorf("invalid --until date '%s'; expected YYYY-MM-DD", until)
+				}
+			}
+			switch level := apitype.EnforcementLevel(enforcementLevel); level {
+			case "":
+			case apitype.Advisory, apitype.Mandatory:
+				filter.EnforcementLevel = &level
+			default:
+				return errors.Errorf("invalid enforcement level '%s'; expected '%s' or '%s'",
+					enforcementLevel, apitype.Advisory, apitype.Mandatory)
+			}
+			if policyPack != "" {
+				filter.PolicyPack = &policyPack
+			}
+			if policy != "" {
+				filter.Policy = &policy
+			}
+
+			if stack != "" {
+				if len(args) > 0 || project != "" {
+					return errors.New("--stack cannot be combined with an organization name or --project")
+				}
+				pc, stackID, err := requireServiceStackClient(stack, "policy violations")
+				if err != nil {
+					return err
+				}
+				return showPolicyViolations(pc, stackID.Owner, policyViolationsFilterForStack(filter, stackID),
+					trend, jsonOut)
+			}
+
+			b, err := currentBackend(display.Options{Color: cmdutil.GetGlobalColorization()})
+			if err != nil {
+				return err
+			}
+			cloudBackend, ok := b.(httpstate.Backend)
+			if !ok {
+				return errors.New("policy violations are only recorded by the Pulumi service")
+			}
+			var orgName string
+			if len(args) > 0 {
+				orgName = args[0]
+			} else if orgName, err = b.CurrentUser(); err != nil {
+				return err
+			}
+			if project != "" {
+				filter.Project = &project
+			}
+			return showPolicyViolations(cloudBackend.Client(), orgName, filter, trend, jsonOut)
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "", "Show the violations of the given stack only")
+	cmd.PersistentFlags().StringVar(
+		&project, "project", "", "Show the violations of the given project's stacks only")
+	cmd.PersistentFlags().StringVar(
+		&policyPack, "policy-pack", "", "Show the violations of the given policy pack's policies only")
+	cmd.PersistentFlags().StringVar(
+		&policy, "policy", "", "Show the violations of policies with the given name only")
+	cmd.PersistentFlags().StringVar(
+		&enforcementLevel, "enforcement-level", "",
+		"Show the violations of policies with the given enforcement level only (advisory or mandatory)")
+	cmd.PersistentFlags().StringVar(
+		&since, "since", "", "The first day of the period to show violations for (YYYY-MM-DD)")
+	cmd.PersistentFlags().StringVar(
+		&until, "until", "", "The day after the last day of the period to show violations for (YYYY-MM-DD)")
+	cmd.PersistentFlags().BoolVar(
+		&trend, "trend", false, "Count the violations by day instead of listing them")
+	cmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit output as JSON")
+
+	return cmd
+}
+
+// policyViolationsFilterForStack returns the given filter, restricted to the violations of the given stack.
+func policyViolationsFilterForStack(filter client.PolicyViolationsFilter,
+	stackID client.StackIdentifier) client.PolicyViolationsFilter {
+
+	filter.Project, filter.Stack = &stackID.Project, &stackID.Stack
+	return filter
+}
+
+// showPolicyViolations fetches the organization's policy violations that match the filter, and prints them, or how
+// many occurred each day if trend is true.
+func showPolicyViolations(pc *client.Client, orgName string, filter client.PolicyViolationsFilter,
+	trend, jsonOut bool) error {
+
+	violations, err := pc.GetPolicyViolations(commandContext(), orgName, filter)
+	if err != nil {
+		return err
+	}
+
+	if trend {
+		days := countPolicyViolationsByDay(violations)
+		if jsonOut {
+			return printJSON(days)
+		}
+		rows := []cmdutil.TableRow{}
+		for _, d := range days {
+			rows = append(rows, cmdutil.TableRow{Columns: []string{
+				d.Date, strconv.Itoa(d.Mandatory), strconv.Itoa(d.Advisory), strconv.Itoa(d.Total),
+			}})
+		}
+		cmdutil.PrintTable(cmdutil.Table{
+			Headers: []string{"DATE", "MANDATORY", "ADVISORY", "TOTAL"},
+			Rows:    rows,
+		})
+		return nil
+	}
+
+	if jsonOut {
+		if violations == nil {
+			violations = []apitype.PolicyViolation{}
+		}
+		return printJSON(violations)
+	}
+	if len(violations) == 0 {
+		fmt.Println("No policy violations found")
+		return nil
+	}
+
+	rows := []cmdutil.TableRow{}
+	for _, v := range violations {
+		update := "preview"
+		if v.UpdateVersion != 0 {
+			update = "#" + strconv.Itoa(v.UpdateVersion)
+		}
+		res := naString
+		if v.ResourceURN != "" {
+			res = string(resource.URN(v.ResourceURN).Name())
+		}
+		rows = append(rows, cmdutil.TableRow{Columns: []string{
+			time.Unix(v.Timestamp, 0).UTC().Format(timeFormat),
+			v.ProjectName + "/" + v.StackName,
+			update,
+			v.PolicyPackName + "/" + v.PolicyName,
+			string(v.EnforcementLevel),
+			res,
+		}})
+	}
+	cmdutil.PrintTable(cmdutil.Table{
+		Headers: []string{"TIME", "STACK", "UPDATE", "POLICY", "LEVEL", "RESOURCE"},
+		Rows:    rows,
+	})
+	return nil
+}
+
+// policyViolationsDay counts the policy violations that occurred on a single day.
+type policyViolationsDay struct {
+	Date      string `json:"date"`
+	Mandatory int    `json:"mandatory"`
+	Advisory  int    `json:"advisory"`
+	Total     int    `json:"total"`
+}
+
+// countPolicyViolationsByDay counts the given violations by the (UTC) day they occurred on, oldest first. Days between
+// the first and last violations on which none occurred are included with counts of zero, so that the counts can be
+// plotted as a series.
+func countPolicyViolationsByDay(violations []apitype.PolicyViolation) []policyViolationsDay {
+	if len(violations) == 0 {
+		return []policyViolationsDay{}
+	}
+
+	counts := make(map[string]*policyViolationsDay)
+	var first, last time.Time
+	for _, v := range violations {
+		day := time.Unix(v.Timestamp, 0).UTC().Truncate(24 * time.Hour)
+		if first.IsZero() || day.Before(first) {
+			first = day
+		}
+		if day.After(last) {
+			last = day
+		}
+
+		date := day.Format(orgUsageDateFormat)
+		c, ok := counts[date]
+		if !ok {
+			c = &policyViolationsDay{Date: date}
+			counts[date] = c
+		}
+		switch v.EnforcementLevel {
+		case apitype.Mandatory:
+			c.Mandatory++
+		case apitype.Advisory:
+			c.Advisory++
+		}
+		c.Total++
+	}
+
+	var days []policyViolationsDay
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		date := day.Format(orgUsageDateFormat)
+		if c, ok := counts[date]; ok {
+			days = append(days, *c)
+		} else {
+			days = append(days, policyViolationsDay{Date: date})
+		}
+	}
+	return days
+}
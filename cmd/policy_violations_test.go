@@ -0,0 +1,46 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+)
+
+func TestCountPolicyViolationsByDay(t *testing.T) {
+	assert.Equal(t, []policyViolationsDay{}, countPolicyViolationsByDay(nil))
+
+	at := func(date string, hour int) int64 {
+		day, err := time.Parse(orgUsageDateFormat, date)
+		assert.NoError(t, err)
+		return day.Add(time.Duration(hour) * time.Hour).Unix()
+	}
+	violations := []apitype.PolicyViolation{
+		{Timestamp: at("2019-09-04", 23), EnforcementLevel: apitype.Mandatory},
+		{Timestamp: at("2019-09-04", 1), EnforcementLevel: apitype.Advisory},
+		{Timestamp: at("2019-09-04", 0), EnforcementLevel: apitype.Advisory},
+		{Timestamp: at("2019-09-01", 12), EnforcementLevel: apitype.Mandatory},
+	}
+	assert.Equal(t, []policyViolationsDay{
+		{Date: "2019-09-01", Mandatory: 1, Total: 1},
+		{Date: "2019-09-02"},
+		{Date: "2019-09-03"},
+		{Date: "2019-09-04", Mandatory: 1, Advisory: 2, Total: 3},
+	}, countPolicyViolationsByDay(violations))
+}
@@ -18,33 +18,53 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"github.com/pulumi/pulumi/pkg/apitype"
 	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/backend/display"
 	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 	"github.com/pulumi/pulumi/pkg/util/result"
+	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
 func newPreviewCmd() *cobra.Command {
+	var checkOnly bool
 	var debug bool
+	var eventLogPath string
+	var displayPlugins []string
 	var expectNop bool
 	var message string
+	var savePlan string
 	var stack string
 
 	// Flags for engine.UpdateOptions.
 	var policyPackPaths []string
+	var remotePolicyPacks []string
 	var diffDisplay bool
+	var inlineDiffs bool
 	var jsonDisplay bool
+	var againstVersion int
+	var overrideGuardrails bool
+	var strictDeprecations bool
 	var parallel int
 	var showConfig bool
 	var showReplacementSteps bool
 	var showSames bool
+	var skipPreflight bool
+	var strict bool
+	var strictAllow []string
 	var suppressOutputs bool
 
+	// Flags for sandboxing the language host.
+	var sandboxUser string
+	var sandboxNoNetwork bool
+	var sandboxReadOnly bool
+
 	var cmd = &cobra.Command{
 		Use:        "preview",
 		Aliases:    []string{"pre"},
-		SuggestFor: []string{"build", "plan"},
+		SuggestFor: []string{"build"},
 		Short:      "Show a preview of updates to a stack's resources",
 		Long: "Show a preview of updates a stack's resources.\n" +
 			"\n" +
@@ -59,9 +79,16 @@ func newPreviewCmd() *cobra.Command {
 			"`--cwd` flag to use a different directory.",
 		Args: cmdutil.NoArgs,
 		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
-			var displayType = display.DisplayProgress
-			if diffDisplay {
-				displayType = display.DisplayDiff
+			if againstVersion < 0 {
+				return result.Error("--against-version must be a positive update version")
+			}
+			if checkOnly && savePlan != "" {
+				return result.Error("--save-plan cannot be used with --check-only, as a check-only preview does " +
+					"not find every operation an update would perform")
+			}
+			if savePlan != "" && againstVersion != 0 {
+				return result.Error("--save-plan cannot be used with --against-version, as the plan would not " +
+					"apply to the stack's current state")
 			}
 
 			opts := backend.UpdateOptions{
@@ -70,6 +97,14 @@ func newPreviewCmd() *cobra.Command {
 					Parallel:             parallel,
 					Debug:                debug,
 					UseLegacyDiff:        useLegacyDiff(),
+					Memory:               memoryOptions(),
+					StrictPreview:        strict,
+					StrictPreviewInvokes: strictAllow,
+					SkipPreflight:        skipPreflight,
+					OverrideGuardrails:   overrideGuardrails,
+					StrictDeprecations:   strictDeprecations,
+					CheckOnly:            checkOnly,
+					Timings:              loadOperationTimings(),
 				},
 				Display: display.Options{
 					Color:                cmdutil.GetGlobalColorization(),
@@ -78,21 +113,50 @@ func newPreviewCmd() *cobra.Command {
 					ShowSameResources:    showSames,
 					SuppressOutputs:      suppressOutputs,
 					IsInteractive:        cmdutil.Interactive(),
-					Type:                 displayType,
+					Type:                 getDisplayType(diffDisplay),
+					InlineDiffs:          inlineDiffs,
+					Verbosity:            displayVerbosity,
 					JSONDisplay:          jsonDisplay,
 					Debug:                debug,
+					LowBandwidth:         useLowBandwidth(),
 				},
+				AgainstVersion: againstVersion,
+			}
+			if savePlan != "" {
+				opts.Engine.SavePlan = engine.NewUpdatePlan()
+			}
+
+			closeEventLog, err := openEventLog(eventLogPath, &opts.Display)
+			if err != nil {
+				return result.FromError(err)
 			}
+			defer closeEventLog()
+
+			closeDisplayPlugins, err := openDisplayPlugins(displayPlugins, &opts.Display)
+			if err != nil {
+				return result.FromError(err)
+			}
+			defer closeDisplayPlugins()
 
 			s, err := requireStack(stack, true, opts.Display, true /*setCurrent*/)
 			if err != nil {
 				return result.FromError(err)
 			}
 
+			// Published policy packs can be simulated against the preview before they are applied to the org.
+			if len(remotePolicyPacks) > 0 {
+				remotePolicies, err := requireRemotePolicies(remotePolicyPacks)
+				if err != nil {
+					return result.FromError(err)
+				}
+				opts.Engine.RequiredPolicies = append(opts.Engine.RequiredPolicies, remotePolicies...)
+			}
+
 			proj, root, err := readProject(pulumiAppProj)
 			if err != nil {
 				return result.FromError(err)
 			}
+			opts.Engine.Sandbox = sandboxOptions(proj, sandboxUser, sandboxNoNetwork, sandboxReadOnly)
 
 			m, err := getUpdateMetadata("", root)
 			if err != nil {
@@ -109,7 +173,7 @@ func newPreviewCmd() *cobra.Command {
 				return result.FromError(errors.Wrap(err, "getting stack configuration"))
 			}
 
-			changes, res := s.Preview(commandContext(), backend.UpdateOperation{
+			op := backend.UpdateOperation{
 				Proj:               proj,
 				Root:               root,
 				M:                  m,
@@ -117,22 +181,66 @@ func newPreviewCmd() *cobra.Command {
 				StackConfiguration: cfg,
 				SecretsManager:     sm,
 				Scopes:             cancellationScopes,
-			})
+			}
+			// Long inline diffs are truncated in a terminal, so collect the resources' steps to let the user expand
+			// them once the preview completes.
+			var events []engine.Event
+			var eventsChannel chan engine.Event
+			eventsDone := make(chan bool)
+			if opts.Display.InlineDiffs && opts.Display.IsInteractive && cmdutil.VirtualTerminal() {
+				eventsChannel = make(chan engine.Event)
+				go func() {
+					for e := range eventsChannel {
+						if e.Type == engine.ResourcePreEvent {
+							events = append(events, e)
+						}
+					}
+					close(eventsDone)
+				}()
+				op.Events = eventsChannel
+			}
+
+			last := recordLastUpdate(s, apitype.PreviewUpdate, true, &op)
+			changes, res := s.Preview(commandContext(), op)
+			last.finish(changes, res)
+			if eventsChannel != nil {
+				close(eventsChannel)
+				<-eventsDone
+			}
 
 			switch {
 			case res != nil:
 				return PrintEngineResult(res)
 			case expectNop && changes != nil && changes.HasChanges():
 				return result.FromError(errors.New("error: no changes were expected but changes were proposed"))
-			default:
-				return nil
+			case savePlan != "":
+				if err = writeUpdatePlan(savePlan, opts.Engine.SavePlan); err != nil {
+					return result.FromError(errors.Wrap(err, "saving plan"))
+				}
+			}
+
+			if events != nil {
+				return backend.ExpandResourceDiffs(events, opts.Display)
 			}
+			return nil
 		}),
 	}
 
+	cmd.PersistentFlags().BoolVar(
+		&checkOnly, "check-only", false,
+		"Only validate the program's resources with their providers, reporting every invalid resource; "+
+			"invokes, reads, and policy packs are skipped, so the preview is faster but its results are incomplete")
 	cmd.PersistentFlags().BoolVarP(
 		&debug, "debug", "d", false,
 		"Print detailed debugging output during resource operations")
+	cmd.PersistentFlags().StringVar(
+		&eventLogPath, "event-log", "",
+		"Log every engine event to the given file, as newline-delimited JSON")
+	cmd.PersistentFlags().StringArrayVar(
+		&displayPlugins, "display-plugin", nil,
+		"Also render engine events with the given display plugin, as `name[=arg]`: junit=<path> writes JUnit XML, "+
+			"github[=<file>] writes GitHub Actions annotations, and other plugins are run as pulumi-display-<name> "+
+			"executables on the PATH. May be repeated")
 	cmd.PersistentFlags().BoolVar(
 		&expectNop, "expect-no-changes", false,
 		"Return an error if any changes are proposed by this preview")
@@ -147,16 +255,40 @@ func newPreviewCmd() *cobra.Command {
 		&message, "message", "m", "",
 		"Optional message to associate with the preview operation")
 
+	cmd.PersistentFlags().StringVar(
+		&savePlan, "save-plan", "",
+		"Save the operations and resource inputs this preview finds to the given file, so that `pulumi up --plan` "+
+			"can later make exactly those changes")
+
+	cmd.PersistentFlags().IntVar(
+		&againstVersion, "against-version", 0,
+		"Preview against the state left by the given prior update version (see `pulumi history`) rather than the "+
+			"stack's current state, e.g. to assess what rolling back to it would change")
+
 	// Flags for engine.UpdateOptions.
 	cmd.PersistentFlags().StringSliceVar(
 		&policyPackPaths, "policy-pack", []string{},
 		"Run one or more analyzers as part of this update")
+	cmd.PersistentFlags().StringSliceVar(
+		&remotePolicyPacks, "policy-pack-remote", []string{},
+		"Run one or more published policy packs, given as <orgName>/<policyPackName>@<version>, locally "+
+			"as part of this preview without applying them to the organization")
 	cmd.PersistentFlags().BoolVar(
 		&diffDisplay, "diff", false,
 		"Display operation as a rich diff showing the overall change")
+	cmd.PersistentFlags().BoolVar(
+		&inlineDiffs, "inline-diff", false,
+		"Show the full property diff of each changed resource in the progress display; long diffs can be expanded "+
+			"and paged once the preview completes")
 	cmd.Flags().BoolVarP(
 		&jsonDisplay, "json", "j", false,
 		"Serialize the preview diffs, operations, and overall output as JSON")
+	cmd.PersistentFlags().BoolVar(
+		&overrideGuardrails, "override-guardrails", false,
+		"Preview the update even if it exceeds the stack's guardrails")
+	cmd.PersistentFlags().BoolVar(
+		&strictDeprecations, "strict-deprecations", false,
+		"Fail if the program uses deprecated resource types or properties, rather than warning")
 	cmd.PersistentFlags().IntVarP(
 		&parallel, "parallel", "p", defaultParallel,
 		"Allow P resource operations to run in parallel at once (1 for no parallelism). Defaults to unbounded.")
@@ -169,9 +301,46 @@ func newPreviewCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(
 		&showSames, "show-sames", false,
 		"Show resources that needn't be updated because they haven't changed, alongside those that do")
+	cmd.PersistentFlags().BoolVar(
+		&skipPreflight, "skip-preflight", false,
+		"Do not check plugins, provider credentials, and configuration before performing the preview")
+	cmd.PersistentFlags().BoolVar(
+		&strict, "strict", false,
+		"Reject and report any provider function invoked by the program that --strict-allow does not permit, "+
+			"and any resource write; providers are only configured if --strict-allow permits invokes")
+	cmd.PersistentFlags().StringArrayVar(
+		&strictAllow, "strict-allow", nil,
+		"A provider function that is a read, e.g. aws:index/getAmi:getAmi, which a --strict preview may invoke. "+
+			"May be repeated")
 	cmd.PersistentFlags().BoolVar(
 		&suppressOutputs, "suppress-outputs", false,
 		"Suppress display of stack outputs (in case they contain sensitive values)")
 
+	// Flags for sandboxing the language host.
+	cmd.PersistentFlags().StringVar(
+		&sandboxUser, "sandbox-user", "",
+		"Run the program's language host as the given OS user, overriding the project's sandbox user")
+	cmd.PersistentFlags().BoolVar(
+		&sandboxNoNetwork, "sandbox-no-network", false,
+		"Run the program's language host without network access, except to the engine (Linux only; requires "+
+			"bubblewrap)")
+	cmd.PersistentFlags().BoolVar(
+		&sandboxReadOnly, "sandbox-read-only", false,
+		"Mount the project directory as read-only for the program (Linux only; requires bubblewrap)")
+
 	return cmd
 }
+
+// sandboxOptions combines the sandbox flags of `pulumi preview` with the project's sandbox settings. The flags add to
+// the restrictions the project requests; they cannot lift them. A user given by flag overrides the project's.
+func sandboxOptions(proj *workspace.Project, user string, noNetwork, readOnly bool) *plugin.SandboxOptions {
+	opts := &plugin.SandboxOptions{User: user, NoNetwork: noNetwork, ReadOnlyProjectDir: readOnly}
+	if cfg := proj.Sandbox; cfg != nil {
+		if opts.User == "" {
+			opts.User = cfg.User
+		}
+		opts.NoNetwork = opts.NoNetwork || cfg.NoNetwork
+		opts.ReadOnlyProjectDir = opts.ReadOnlyProjectDir || cfg.ReadOnly
+	}
+	return opts
+}
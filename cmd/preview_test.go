@@ -0,0 +1,40 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestSandboxOptions(t *testing.T) {
+	// Without project settings, the flags are used as they are.
+	proj := &workspace.Project{}
+	assert.Equal(t, &plugin.SandboxOptions{NoNetwork: true},
+		sandboxOptions(proj, "", true, false))
+
+	// The project's settings apply when no flags are given.
+	proj.Sandbox = &workspace.ProjectSandboxConfig{User: "nobody", NoNetwork: true}
+	assert.Equal(t, &plugin.SandboxOptions{User: "nobody", NoNetwork: true},
+		sandboxOptions(proj, "", false, false))
+
+	// Flags add restrictions, and a user given by flag overrides the project's.
+	assert.Equal(t, &plugin.SandboxOptions{User: "preview", NoNetwork: true, ReadOnlyProjectDir: true},
+		sandboxOptions(proj, "preview", false, true))
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/backend/display"
 	"github.com/pulumi/pulumi/pkg/backend/filestate"
 	"github.com/pulumi/pulumi/pkg/backend/httpstate"
@@ -54,8 +55,10 @@ func NewPulumiCmd() *cobra.Command {
 	var tracing string
 	var tracingHeaderFlag string
 	var profiling string
-	var verbose int
+	var verbose string
 	var color string
+	var accessibleVerbosity string
+	var start time.Time
 
 	cmd := &cobra.Command{
 		Use:   "pulumi",
@@ -75,12 +78,44 @@ func NewPulumiCmd() *cobra.Command {
 			"    - pulumi config   : Alter your stack's configuration or secrets\n" +
 			"    - pulumi destroy  : Tear down your stack's resources entirely\n" +
 			"\n" +
+			"Defaults for the --color, --diff, --inline-diff, --non-interactive, and --parallel flags may be\n" +
+			"set with environment variables (e.g. PULUMI_NON_INTERACTIVE=true), in a .pulumirc file next to\n" +
+			"Pulumi.yaml, or in ~/.pulumi/config, in that order of precedence. Flags passed on the command line\n" +
+			"always win.\n" +
+			"\n" +
 			"For more information, please visit the project page: https://www.pulumi.com/docs/",
 		PersistentPreRun: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			start = time.Now()
+			recordCrashCommand(cmd.CommandPath())
+
 			// We run this method for its side-effects. On windows, this will enable the windows terminal
 			// to understand ANSI escape codes.
 			_, _, _ = term.StdStreams()
 
+			v, err := display.ParseVerbosity(accessibleVerbosity)
+			if err != nil {
+				return err
+			}
+			displayVerbosity = v
+			if accessibleDisplay {
+				cmdutil.Emoji = false
+			}
+
+			if cwd != "" {
+				if err := os.Chdir(cwd); err != nil {
+					return err
+				}
+			}
+
+			// Fill in any flags that weren't passed explicitly from the environment and flag defaults files.
+			flagDefaults, err := loadFlagDefaults()
+			if err != nil {
+				return err
+			}
+			if err = applyFlagDefaults(cmd, flagDefaults); err != nil {
+				return err
+			}
+
 			// For all commands, attempt to grab out the --color value provided so we
 			// can set the GlobalColorization value to be used by any code that doesn't
 			// get DisplayOptions passed in.
@@ -92,13 +127,24 @@ func NewPulumiCmd() *cobra.Command {
 				}
 			}
 
-			if cwd != "" {
-				if err := os.Chdir(cwd); err != nil {
+			if mockMode {
+				if mockOptions, err = getMockOptions(); err != nil {
 					return err
 				}
+				// Stacks in mock mode hold nothing worth protecting, so don't prompt for a passphrase.
+				if _, ok := os.LookupEnv("PULUMI_CONFIG_PASSPHRASE"); !ok {
+					if err := os.Setenv("PULUMI_CONFIG_PASSPHRASE", ""); err != nil {
+						return err
+					}
+				}
 			}
 
-			logging.InitLogging(logToStderr, verbose, logFlow)
+			verboseLevel, verboseScopes, err := logging.ParseVerbosity(verbose)
+			if err != nil {
+				return err
+			}
+			logging.InitLogging(logToStderr, verboseLevel, logFlow)
+			logging.SetScopedVerbosity(verboseScopes)
 			cmdutil.InitTracing("pulumi-cli", "pulumi", tracing)
 			if tracingHeaderFlag != "" {
 				tracingHeader = tracingHeaderFlag
@@ -119,6 +165,8 @@ func NewPulumiCmd() *cobra.Command {
 			return nil
 		}),
 		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			recordAudit(cmd, args, start, cmdutil.CommandResult())
+
 			logging.Flush()
 			cmdutil.CloseTracing()
 
@@ -137,6 +185,8 @@ func NewPulumiCmd() *cobra.Command {
 		"Enable emojis in the output")
 	cmd.PersistentFlags().BoolVar(&filestate.DisableIntegrityChecking, "disable-integrity-checking", false,
 		"Disable integrity checking of checkpoint files")
+	cmd.PersistentFlags().BoolVar(&backend.ContinuousIntegrityChecking, "continuous-integrity-checking", false,
+		"Verify the integrity of the checkpoint after every step of an update, failing at the first violation")
 	cmd.PersistentFlags().BoolVar(&logFlow, "logflow", false,
 		"Flow log settings to child processes (like plugins)")
 	cmd.PersistentFlags().BoolVar(&logToStderr, "logtostderr", false,
@@ -147,14 +197,24 @@ func NewPulumiCmd() *cobra.Command {
 		"Emit tracing to a Zipkin-compatible tracing endpoint")
 	cmd.PersistentFlags().StringVar(&profiling, "profiling", "",
 		"Emit CPU and memory profiles and an execution trace to '[filename].[pid].{cpu,mem,trace}', respectively")
-	cmd.PersistentFlags().IntVarP(&verbose, "verbose", "v", 0,
-		"Enable verbose logging (e.g., v=3); anything >3 is very verbose")
+	cmd.PersistentFlags().BoolVar(&accessibleDisplay, "accessible", cmdutil.IsTruthy(os.Getenv("PULUMI_ACCESSIBLE")),
+		"Display updates as plain, linear text suitable for screen readers")
+	cmd.PersistentFlags().StringVar(&accessibleVerbosity, "accessible-verbosity",
+		os.Getenv("PULUMI_ACCESSIBLE_VERBOSITY"),
+		"How much detail the accessible display reports. Choices are: quiet, normal, verbose")
+	cmd.PersistentFlags().StringVarP(&verbose, "verbose", "v", "",
+		"Enable verbose logging (e.g., v=3); anything >3 is very verbose. Levels may be scoped to parts of the CLI, "+
+			"e.g. v=backend=9,engine=3,plugin=5")
 	cmd.PersistentFlags().StringVar(
 		&color, "color", "auto", "Colorize output. Choices are: always, never, raw, auto")
+	cmd.PersistentFlags().BoolVar(&mockMode, "mock", cmdutil.IsTruthy(os.Getenv("PULUMI_MOCK")),
+		"Simulate resources with synthetic providers, using stacks kept in memory, so that no cloud "+
+			"credentials are needed")
 
 	// Common commands:
 	//     - Getting Started Commands
 	cmd.AddCommand(newNewCmd())
+	cmd.AddCommand(newTemplateCmd())
 	//     - Deploy Commands
 	cmd.AddCommand(newUpCmd())
 	cmd.AddCommand(newPreviewCmd())
@@ -166,15 +226,22 @@ func NewPulumiCmd() *cobra.Command {
 	cmd.AddCommand(newLoginCmd())
 	cmd.AddCommand(newLogoutCmd())
 	cmd.AddCommand(newWhoAmICmd())
+	cmd.AddCommand(newOrgCmd())
+	cmd.AddCommand(newWebhookCmd())
 	//     - Advanced Commands:
 	cmd.AddCommand(newCancelCmd())
 	cmd.AddCommand(newRefreshCmd())
+	cmd.AddCommand(newImportCmd())
 	cmd.AddCommand(newStateCmd())
+	cmd.AddCommand(newPlanCmd())
 	//     - Other Commands:
 	cmd.AddCommand(newLogsCmd())
 	cmd.AddCommand(newPluginCmd())
 	cmd.AddCommand(newVersionCmd())
 	cmd.AddCommand(newHistoryCmd())
+	cmd.AddCommand(newLastCmd())
+	cmd.AddCommand(newDoctorCmd())
+	cmd.AddCommand(newExplainCmd())
 
 	// Less common, and thus hidden, commands:
 	cmd.AddCommand(newGenCompletionCmd(cmd))
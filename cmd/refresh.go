@@ -16,10 +16,14 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"github.com/pulumi/pulumi/pkg/apitype"
 	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/backend/display"
 	"github.com/pulumi/pulumi/pkg/engine"
@@ -27,10 +31,26 @@ import (
 	"github.com/pulumi/pulumi/pkg/util/result"
 )
 
+// refreshDriftExitCode is the exit code of a refresh run with --detect-drift that finds drift, so that scheduled drift
+// detection jobs can tell drift apart from other failures.
+const refreshDriftExitCode = 2
+
+// driftReportJSON is the machine-readable drift report that `pulumi refresh --drift-report` writes.
+type driftReportJSON struct {
+	Stack     string                   `json:"stack"`
+	Drifted   bool                     `json:"drifted"`
+	Resources []engine.DriftedResource `json:"resources"`
+}
+
 func newRefreshCmd() *cobra.Command {
 	var debug bool
+	var detectDrift bool
+	var driftReportPath string
+	var eventLogPath string
+	var displayPlugins []string
 	var expectNop bool
 	var message string
+	var breakFreeze string
 	var stack string
 
 	// Flags for engine.UpdateOptions.
@@ -41,7 +61,7 @@ func newRefreshCmd() *cobra.Command {
 	var showSames bool
 	var skipPreview bool
 	var suppressOutputs bool
-	var yes bool
+	var yes yesFlag
 
 	var cmd = &cobra.Command{
 		Use:   "refresh",
@@ -53,24 +73,32 @@ func newRefreshCmd() *cobra.Command {
 			"the program text isn't updated accordingly, subsequent updates may still appear to be out of\n" +
 			"synch with respect to the cloud provider's source of truth.\n" +
 			"\n" +
+			"To detect drift without adopting it, pass `--detect-drift`. The refresh is then only previewed,\n" +
+			"and the command exits with code 2 if any resource's live state differs from the stack's state.\n" +
+			"Pass `--drift-report` to write the resources that have drifted to a file as JSON; the report\n" +
+			"describes what the refresh's preview finds.\n" +
+			"\n" +
 			"The program to run is loaded from the project in the current directory. Use the `-C` or\n" +
 			"`--cwd` flag to use a different directory.",
 		Args: cmdutil.NoArgs,
 		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
+			if detectDrift && skipPreview {
+				return result.Error("--detect-drift cannot be combined with --skip-preview")
+			}
+			if driftReportPath != "" && skipPreview {
+				return result.Error("--drift-report cannot be combined with --skip-preview")
+			}
+
 			interactive := cmdutil.Interactive()
 			if !interactive {
-				yes = true // auto-approve changes, since we cannot prompt.
+				yes.approve = true // auto-approve changes, since we cannot prompt.
 			}
 
 			opts, err := updateFlagsToOptions(interactive, skipPreview, yes)
 			if err != nil {
 				return result.FromError(err)
 			}
-
-			var displayType = display.DisplayProgress
-			if diffDisplay {
-				displayType = display.DisplayDiff
-			}
+			opts.PreviewOnly = detectDrift
 
 			opts.Display = display.Options{
 				Color:                cmdutil.GetGlobalColorization(),
@@ -79,9 +107,23 @@ func newRefreshCmd() *cobra.Command {
 				ShowSameResources:    showSames,
 				SuppressOutputs:      suppressOutputs,
 				IsInteractive:        interactive,
-				Type:                 displayType,
+				Type:                 getDisplayType(diffDisplay),
+				Verbosity:            displayVerbosity,
 				Debug:                debug,
+				LowBandwidth:         useLowBandwidth(),
+			}
+
+			closeEventLog, err := openEventLog(eventLogPath, &opts.Display)
+			if err != nil {
+				return result.FromError(err)
+			}
+			defer closeEventLog()
+
+			closeDisplayPlugins, err := openDisplayPlugins(displayPlugins, &opts.Display)
+			if err != nil {
+				return result.FromError(err)
 			}
+			defer closeDisplayPlugins()
 
 			s, err := requireStack(stack, true, opts.Display, true /*setCurrent*/)
 			if err != nil {
@@ -97,6 +139,15 @@ func newRefreshCmd() *cobra.Command {
 			if err != nil {
 				return result.FromError(errors.Wrap(err, "gathering environment metadata"))
 			}
+			if err = checkUpdateMessage(proj, s, m); err != nil {
+				return result.FromError(err)
+			}
+			// Detecting drift leaves the stack's state as it is, so it may run during a freeze window.
+			if !detectDrift {
+				if err = checkFreezeWindows(s, m, breakFreeze); err != nil {
+					return result.FromError(err)
+				}
+			}
 
 			sm, err := getStackSecretsManager(s)
 			if err != nil {
@@ -108,13 +159,20 @@ func newRefreshCmd() *cobra.Command {
 				return result.FromError(errors.Wrap(err, "getting stack configuration"))
 			}
 
+			var drift *engine.DriftReport
+			if detectDrift || driftReportPath != "" {
+				drift = engine.NewDriftReport()
+			}
+
 			opts.Engine = engine.UpdateOptions{
 				Parallel:      parallel,
 				Debug:         debug,
 				UseLegacyDiff: useLegacyDiff(),
+				Memory:        memoryOptions(),
+				DriftReport:   drift,
 			}
 
-			changes, res := s.Refresh(commandContext(), backend.UpdateOperation{
+			op := backend.UpdateOperation{
 				Proj:               proj,
 				Root:               root,
 				M:                  m,
@@ -122,24 +180,38 @@ func newRefreshCmd() *cobra.Command {
 				StackConfiguration: cfg,
 				SecretsManager:     sm,
 				Scopes:             cancellationScopes,
-			})
-
-			switch {
-			case res != nil && res.Error() == context.Canceled:
-				return result.FromError(errors.New("refresh cancelled"))
-			case res != nil:
-				return PrintEngineResult(res)
-			case expectNop && changes != nil && changes.HasChanges():
-				return result.FromError(errors.New("error: no changes were expected but changes occurred"))
-			default:
-				return nil
 			}
+			last := recordLastUpdate(s, apitype.RefreshUpdate, opts.PreviewOnly, &op)
+			changes, res := s.Refresh(commandContext(), op)
+			last.finish(changes, res)
+
+			if res == nil && driftReportPath != "" {
+				if err = writeDriftReport(driftReportPath, s.Ref().String(), drift); err != nil {
+					return result.FromError(err)
+				}
+			}
+
+			return refreshResult(res, changes, expectNop, detectDrift, drift)
 		}),
 	}
 
 	cmd.PersistentFlags().BoolVarP(
 		&debug, "debug", "d", false,
 		"Print detailed debugging output during resource operations")
+	cmd.PersistentFlags().BoolVar(
+		&detectDrift, "detect-drift", false,
+		"Only preview the refresh, and exit with code 2 if any resource has drifted from the stack's state")
+	cmd.PersistentFlags().StringVar(
+		&driftReportPath, "drift-report", "",
+		"Write the resources whose live state differs from the stack's state to the given file, as JSON")
+	cmd.PersistentFlags().StringVar(
+		&eventLogPath, "event-log", "",
+		"Log every engine event to the given file, as newline-delimited JSON")
+	cmd.PersistentFlags().StringArrayVar(
+		&displayPlugins, "display-plugin", nil,
+		"Also render engine events with the given display plugin, as `name[=arg]`: junit=<path> writes JUnit XML, "+
+			"github[=<file>] writes GitHub Actions annotations, and other plugins are run as pulumi-display-<name> "+
+			"executables on the PATH. May be repeated")
 	cmd.PersistentFlags().BoolVar(
 		&expectNop, "expect-no-changes", false,
 		"Return an error if any changes occur during this update")
@@ -153,6 +225,9 @@ func newRefreshCmd() *cobra.Command {
 	cmd.PersistentFlags().StringVarP(
 		&message, "message", "m", "",
 		"Optional message to associate with the update operation")
+	cmd.PersistentFlags().StringVar(
+		&breakFreeze, "break-freeze", "",
+		"Run the refresh during one of the stack's freeze windows, giving the justification that is recorded with it")
 
 	// Flags for engine.UpdateOptions.
 	cmd.PersistentFlags().BoolVar(
@@ -173,9 +248,48 @@ func newRefreshCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(
 		&suppressOutputs, "suppress-outputs", false,
 		"Suppress display of stack outputs (in case they contain sensitive values)")
-	cmd.PersistentFlags().BoolVarP(
-		&yes, "yes", "y", false,
-		"Automatically approve and perform the refresh after previewing it")
+	addYesFlag(cmd, &yes, "refresh")
 
 	return cmd
 }
+
+// refreshResult returns the result of a refresh that finished with the given result and changes. A refresh that detects
+// drift fails with refreshDriftExitCode if the given drift report has recorded any, and a refresh that expects no
+// changes fails with the standard error exit code if there are any.
+func refreshResult(res result.Result, changes engine.ResourceChanges, expectNop, detectDrift bool,
+	drift *engine.DriftReport) result.Result {
+
+	switch {
+	case res != nil && res.Error() == context.Canceled:
+		return result.FromError(errors.New("refresh cancelled"))
+	case res != nil:
+		return PrintEngineResult(res)
+	case detectDrift && drift.HasDrift():
+		return result.FromError(&cmdutil.ExitCodeError{
+			Code: refreshDriftExitCode,
+			Err:  errors.Errorf("drift detected in %d resources", len(drift.Resources())),
+		})
+	case expectNop && changes != nil && changes.HasChanges():
+		return result.FromError(errors.New("error: no changes were expected but changes occurred"))
+	default:
+		return nil
+	}
+}
+
+// writeDriftReport writes the given drift report for the named stack to a file at the given path, as JSON.
+func writeDriftReport(path string, stackName string, drift *engine.DriftReport) error {
+	report := driftReportJSON{
+		Stack:     stackName,
+		Drifted:   drift.HasDrift(),
+		Resources: drift.Resources(),
+	}
+	b, err := json.MarshalIndent(report, "", "    ")
+	if err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(path, append(b, '\n'), 0600); err != nil {
+		return errors.Wrap(err, "writing drift report")
+	}
+	fmt.Printf("Wrote the drift report to %s\n", path)
+	return nil
+}
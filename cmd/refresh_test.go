@@ -0,0 +1,81 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func TestRefreshResult(t *testing.T) {
+	drifted := engine.DriftedResource{
+		URN:  "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::b",
+		Type: "aws:s3/bucket:Bucket",
+		Op:   deploy.OpDelete,
+	}
+	changes := engine.ResourceChanges{deploy.OpDelete: 1}
+
+	// Detected drift fails with the drift exit code.
+	res := refreshResult(nil, changes, true, true, engine.NewDriftReport(drifted))
+	if assert.NotNil(t, res) {
+		exitErr, ok := res.Error().(*cmdutil.ExitCodeError)
+		if assert.True(t, ok) {
+			assert.Equal(t, refreshDriftExitCode, exitErr.Code)
+		}
+	}
+	assert.Nil(t, refreshResult(nil, nil, true, true, engine.NewDriftReport()))
+
+	// Unexpected changes outside of drift detection keep the standard exit code.
+	res = refreshResult(nil, changes, true, false, nil)
+	if assert.NotNil(t, res) {
+		_, ok := res.Error().(*cmdutil.ExitCodeError)
+		assert.False(t, ok)
+	}
+	assert.Nil(t, refreshResult(nil, changes, false, false, nil))
+	assert.Nil(t, refreshResult(nil, nil, true, false, nil))
+}
+
+func TestWriteDriftReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "drift-report")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	drifted := engine.DriftedResource{
+		URN:  "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::b",
+		Type: "aws:s3/bucket:Bucket",
+		Op:   deploy.OpUpdate,
+	}
+	path := filepath.Join(dir, "drift.json")
+	assert.NoError(t, writeDriftReport(path, "dev", engine.NewDriftReport(drifted)))
+
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	var report driftReportJSON
+	assert.NoError(t, json.Unmarshal(b, &report))
+	assert.Equal(t, driftReportJSON{
+		Stack:     "dev",
+		Drifted:   true,
+		Resources: []engine.DriftedResource{drifted},
+	}, report)
+}
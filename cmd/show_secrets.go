@@ -0,0 +1,131 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+)
+
+// showAllSecrets is the value of a bare --show-secrets flag, which reveals every secret.
+const showAllSecrets = "*"
+
+// showSecretsFlag is the value of a --show-secrets flag. A bare --show-secrets reveals every secret, while
+// --show-secrets=<path>, which may be repeated, reveals only the secrets at the given paths. The paths of a stack's
+// deployment are of the form `[<urn>#]{inputs|outputs}[.<property path>]`, and those of its configuration are
+// configuration keys.
+type showSecretsFlag []string
+
+func (f *showSecretsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *showSecretsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+func (f *showSecretsFlag) Type() string {
+	return "path"
+}
+
+// addShowSecretsFlag adds the --show-secrets flag to cmd.
+func addShowSecretsFlag(cmd *cobra.Command, show *showSecretsFlag, usage string) {
+	flag := cmd.Flags().VarPF(show, "show-secrets", "", usage+"; with --show-secrets=<path>, only those at the "+
+		"given path, which may be repeated")
+	flag.NoOptDefVal = showAllSecrets
+}
+
+// any returns true if any secrets are to be revealed.
+func (f showSecretsFlag) any() bool {
+	return len(f) > 0
+}
+
+// all returns true if every secret is to be revealed.
+func (f showSecretsFlag) all() bool {
+	for _, p := range f {
+		if p == showAllSecrets {
+			return true
+		}
+	}
+	return false
+}
+
+// secretPaths returns the paths of the deployment's secrets to reveal, or nil if every secret is to be revealed.
+func (f showSecretsFlag) secretPaths() ([]stack.SecretPath, error) {
+	if f.all() {
+		return nil, nil
+	}
+	var paths []stack.SecretPath
+	for _, p := range f {
+		path, err := stack.ParseSecretPath(p)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// revealsConfig returns true if the value of the given configuration key is to be revealed. Keys may be given by their
+// full names, or by the names that `pulumi config` shows.
+func (f showSecretsFlag) revealsConfig(key config.Key) bool {
+	for _, p := range f {
+		if p == showAllSecrets || p == key.String() || p == prettyKey(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// record records in the stack's audit log, if its backend keeps one, that the given command is revealing the stack's
+// secrets of the given update version, or of its current state if 0. It must be called before any secret is revealed,
+// so that secrets are never revealed unaudited.
+func (f showSecretsFlag) record(s backend.Stack, command string, version int) error {
+	b, ok := s.Backend().(backend.SecretsAuditBackend)
+	if !ok || !f.any() {
+		return nil
+	}
+	var paths []string
+	if !f.all() {
+		paths = f
+	}
+	if err := b.RecordSecretsRevealed(commandContext(), s.Ref(), command, version, paths); err != nil {
+		return errors.Wrap(err, "recording the revealing of secrets in the audit log")
+	}
+	return nil
+}
+
+// revealStackOutputs returns the given outputs of the stack's root resource with the secrets to be revealed replaced
+// by their plaintext values, and the others blinded.
+func (f showSecretsFlag) revealStackOutputs(root *resource.State) (resource.PropertyMap, error) {
+	outputs := root.Outputs
+	if f.any() {
+		paths, err := f.secretPaths()
+		if err != nil {
+			return nil, err
+		}
+		outputs = stack.RevealPropertySecrets(root, "outputs", outputs, paths)
+	}
+	return display.MassageSecrets(outputs, false), nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+)
+
+// auditBackend is a backend that records the revealing of secrets.
+type auditBackend struct {
+	backend.Backend
+
+	commands []string
+	paths    [][]string
+}
+
+func (b *auditBackend) RecordSecretsRevealed(ctx context.Context, stackRef backend.StackReference, command string,
+	version int, paths []string) error {
+
+	b.commands, b.paths = append(b.commands, command), append(b.paths, paths)
+	return nil
+}
+
+// auditStack is a stack of an auditBackend.
+type auditStack struct {
+	backend.Stack
+
+	b *auditBackend
+}
+
+func (s *auditStack) Backend() backend.Backend    { return s.b }
+func (s *auditStack) Ref() backend.StackReference { return nil }
+
+func parseShowSecretsFlag(t *testing.T, args ...string) showSecretsFlag {
+	var show showSecretsFlag
+	cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+	addShowSecretsFlag(cmd, &show, "Show secrets")
+	cmd.SetArgs(args)
+	assert.NoError(t, cmd.Execute())
+	return show
+}
+
+func TestShowSecretsFlag(t *testing.T) {
+	show := parseShowSecretsFlag(t)
+	assert.False(t, show.any())
+
+	// A bare --show-secrets reveals everything.
+	show = parseShowSecretsFlag(t, "--show-secrets")
+	assert.True(t, show.all())
+	paths, err := show.secretPaths()
+	assert.NoError(t, err)
+	assert.Nil(t, paths)
+	assert.True(t, show.revealsConfig(config.MustMakeKey("aws", "secretKey")))
+
+	// Paths reveal only the secrets they name.
+	show = parseShowSecretsFlag(t, "--show-secrets=outputs.dbPassword", "--show-secrets=webshop:dbPassword")
+	assert.True(t, show.any())
+	assert.False(t, show.all())
+	assert.True(t, show.revealsConfig(config.MustMakeKey("webshop", "dbPassword")))
+	assert.False(t, show.revealsConfig(config.MustMakeKey("aws", "secretKey")))
+	_, err = show.secretPaths()
+	assert.Error(t, err)
+}
+
+func TestShowSecretsFlagRecord(t *testing.T) {
+	b := &auditBackend{}
+	s := &auditStack{b: b}
+
+	// Nothing is recorded when no secrets are revealed.
+	assert.NoError(t, parseShowSecretsFlag(t).record(s, "stack output", 0))
+	assert.Empty(t, b.commands)
+
+	assert.NoError(t, parseShowSecretsFlag(t, "--show-secrets").record(s, "stack output", 0))
+	assert.NoError(t, parseShowSecretsFlag(t, "--show-secrets=dbPassword").record(s, "config", 0))
+	assert.Equal(t, []string{"stack output", "config"}, b.commands)
+	assert.Equal(t, [][]string{nil, {"dbPassword"}}, b.paths)
+}
+
+func TestGetStackOutputsShowSecrets(t *testing.T) {
+	urn := resource.NewURN("prod", "webshop", "", resource.RootStackType, "webshop-prod")
+	root := &resource.State{
+		URN:  urn,
+		Type: resource.RootStackType,
+		Outputs: resource.PropertyMap{
+			"dbPassword": resource.MakeSecret(resource.NewStringProperty("hunter2")),
+			"apiKey":     resource.MakeSecret(resource.NewStringProperty("abc")),
+			"url":        resource.NewStringProperty("https://example.com"),
+		},
+	}
+	snap := deploy.NewSnapshot(deploy.Manifest{}, nil, []*resource.State{root}, nil)
+
+	outputs, err := getStackOutputs(snap, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"dbPassword": "[secret]",
+		"apiKey":     "[secret]",
+		"url":        "https://example.com",
+	}, outputs)
+
+	outputs, err = getStackOutputs(snap, parseShowSecretsFlag(t, "--show-secrets=outputs.dbPassword"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"dbPassword": "hunter2",
+		"apiKey":     "[secret]",
+		"url":        "https://example.com",
+	}, outputs)
+
+	outputs, err = getStackOutputs(snap, parseShowSecretsFlag(t, "--show-secrets"))
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", outputs["apiKey"])
+}
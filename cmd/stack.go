@@ -20,6 +20,7 @@ import (
 	"sort"
 
 	"github.com/dustin/go-humanize"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/pulumi/pulumi/pkg/backend/display"
@@ -30,7 +31,7 @@ import (
 func newStackCmd() *cobra.Command {
 	var showIDs bool
 	var showURNs bool
-	var showSecrets bool
+	var showSecrets showSecretsFlag
 	var stackName string
 
 	cmd := &cobra.Command{
@@ -129,11 +130,15 @@ func newStackCmd() *cobra.Command {
 					Prefix:  "    ",
 				})
 
+				if err = showSecrets.record(s, "stack", 0); err != nil {
+					return err
+				}
 				outputs, err := getStackOutputs(snap, showSecrets)
 				if err != nil {
-					fmt.Printf("\n")
-					printStackOutputs(outputs)
+					return errors.Wrap(err, "getting outputs")
 				}
+				fmt.Printf("\n")
+				printStackOutputs(outputs)
 			}
 
 			// Add a link to the pulumi.com console page for this stack, if it has one.
@@ -158,19 +163,27 @@ func newStackCmd() *cobra.Command {
 		&showIDs, "show-ids", "i", false, "Display each resource's provider-assigned unique ID")
 	cmd.PersistentFlags().BoolVarP(
 		&showURNs, "show-urns", "u", false, "Display each resource's Pulumi-assigned globally unique URN")
-	cmd.PersistentFlags().BoolVar(
-		&showSecrets, "show-secrets", false, "Display stack outputs which are marked as secret in plaintext")
+	addShowSecretsFlag(cmd, &showSecrets, "Display stack outputs which are marked as secret in plaintext")
 
+	cmd.AddCommand(newStackChangeSecretsProviderCmd())
+	cmd.AddCommand(newStackEventsCmd())
 	cmd.AddCommand(newStackExportCmd())
 	cmd.AddCommand(newStackGraphCmd())
+	cmd.AddCommand(newHistoryCmd())
 	cmd.AddCommand(newStackImportCmd())
 	cmd.AddCommand(newStackInitCmd())
 	cmd.AddCommand(newStackLsCmd())
+	cmd.AddCommand(newStackMigrateCmd())
 	cmd.AddCommand(newStackOutputCmd())
+	cmd.AddCommand(newStackPermissionCmd())
 	cmd.AddCommand(newStackRmCmd())
+	cmd.AddCommand(newStackRollbackCmd())
+	cmd.AddCommand(newStackRotateSecretsKeyCmd())
 	cmd.AddCommand(newStackSelectCmd())
 	cmd.AddCommand(newStackTagCmd())
 	cmd.AddCommand(newStackRenameCmd())
+	cmd.AddCommand(newStackRewrapSecretsCmd())
+	cmd.AddCommand(newStackWaitCmd())
 
 	return cmd
 }
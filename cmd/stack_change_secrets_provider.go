@@ -0,0 +1,168 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/result"
+)
+
+func newStackChangeSecretsProviderCmd() *cobra.Command {
+	var stackName string
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "change-secrets-provider <new-secrets-provider>",
+		Args:  cmdutil.ExactArgs(1),
+		Short: "Change the secrets provider of a stack",
+		Long: "Change the secrets provider of a stack.\n" +
+			"\n" +
+			"This command re-encrypts the secrets in a stack's configuration and state with a new secrets\n" +
+			"provider, which may be any provider accepted by `pulumi stack init --secrets-provider`. For example:\n" +
+			"\n" +
+			"* `pulumi stack change-secrets-provider \"awskms://alias/ExampleAlias?region=us-east-1\"`\n" +
+			"\n" +
+			"The re-encrypted state and configuration are checked before the command completes. If anything\n" +
+			"fails, the stack's original state and configuration are restored.",
+		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			secretsProvider := args[0]
+			if err := validateSecretsProvider(secretsProvider); err != nil {
+				return result.FromError(err)
+			}
+
+			s, err := requireStack(stackName, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return result.FromError(err)
+			}
+
+			// Decrypt everything before changing anything.
+			ctx := commandContext()
+			configPath, err := getProjectStackPath(s)
+			if err != nil {
+				return result.FromError(err)
+			}
+			originalConfig, err := ioutil.ReadFile(configPath)
+			if err != nil && !os.IsNotExist(err) {
+				return result.FromError(err)
+			}
+			ps, err := loadProjectStack(s)
+			if err != nil {
+				return result.FromError(err)
+			}
+			sm, err := getStackSecretsManager(s)
+			if err != nil {
+				return result.FromError(err)
+			}
+			dec, err := sm.Decrypter()
+			if err != nil {
+				return result.FromError(err)
+			}
+			plaintextConfig, err := copyStackConfig(ps.Config, dec, config.NopEncrypter)
+			if err != nil {
+				return result.FromError(err)
+			}
+			deployment, err := s.ExportDeployment(ctx)
+			if err != nil {
+				return result.FromError(errors.Wrap(err, "exporting the stack's state"))
+			}
+			snap, err := stack.DeserializeUntypedDeployment(deployment, stackSecretsProvider{sm: sm})
+			if err != nil {
+				return result.FromError(errors.Wrap(err, "reading the stack's state"))
+			}
+
+			// Ensure the user really wants to do this.
+			if !yes && !cmdutil.Interactive() {
+				return result.FromError(errYesRequired("changing a stack's secrets provider"))
+			}
+			prompt := fmt.Sprintf("This will re-encrypt the secrets of %d resources and %d configuration values "+
+				"of stack '%s' with %s.", len(snap.Resources), len(ps.Config), s.Ref(), secretsProvider)
+			if !yes && !confirmPrompt(prompt, s.Ref().String(), opts) {
+				fmt.Println("confirmation declined")
+				return result.Bail()
+			}
+
+			if err = changeSecretsProvider(s, secretsProvider, plaintextConfig, snap, deployment); err != nil {
+				// Put back the original configuration and, if it was replaced, the original state.
+				if originalConfig == nil {
+					contract.IgnoreError(os.Remove(configPath))
+				} else {
+					contract.IgnoreError(ioutil.WriteFile(configPath, originalConfig, 0644))
+				}
+				current, exportErr := s.ExportDeployment(ctx)
+				if exportErr != nil || !bytes.Equal(current.Deployment, deployment.Deployment) {
+					if importErr := s.ImportDeployment(ctx, deployment); importErr != nil {
+						fmt.Fprintf(os.Stderr, "warning: could not restore the original state of stack '%s': %v\n",
+							s.Ref(), importErr)
+					}
+				}
+				return result.FromError(errors.Wrap(err, "changing the secrets provider failed and was rolled back"))
+			}
+
+			fmt.Printf("Changed the secrets provider of stack '%s' to %s.\n", s.Ref(), secretsProvider)
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().BoolVarP(
+		&yes, "yes", "y", false,
+		"Skip confirmation prompts, and proceed with the change anyway")
+
+	return cmd
+}
+
+// changeSecretsProvider configures the given secrets provider for the given stack, and re-encrypts the stack's
+// configuration and state with it.
+func changeSecretsProvider(s backend.Stack, secretsProvider string, plaintextConfig config.Map,
+	snap *deploy.Snapshot, deployment *apitype.UntypedDeployment) error {
+
+	// The new secrets provider is configured in the stack's configuration file, so clear out the old one's settings.
+	ps, err := loadProjectStack(s)
+	if err != nil {
+		return err
+	}
+	ps.SecretsProvider, ps.EncryptedKey, ps.EncryptionSalt = "", "", ""
+	ps.Config = make(config.Map)
+	ps.SecretMetadata = nil
+	if err = saveProjectStack(s, ps); err != nil {
+		return err
+	}
+	if err = initSecretsProvider(s.Backend(), s.Ref().Name(), secretsProvider); err != nil {
+		return err
+	}
+
+	return reencryptStack(s, plaintextConfig, snap, deployment)
+}
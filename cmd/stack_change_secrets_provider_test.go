@@ -0,0 +1,135 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	_ "gocloud.dev/secrets/localsecrets" // support for base64key://
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend/filestate"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/secrets/cloud"
+)
+
+func TestChangeSecretsProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	defer func(old string) { stackConfigFile = old }(stackConfigFile)
+	stackConfigFile = filepath.Join(dir, "Pulumi.dev.yaml")
+	defer func(old string, had bool) {
+		if had {
+			os.Setenv("PULUMI_CONFIG_PASSPHRASE", old)
+		} else {
+			os.Unsetenv("PULUMI_CONFIG_PASSPHRASE")
+		}
+	}(os.LookupEnv("PULUMI_CONFIG_PASSPHRASE"))
+	assert.NoError(t, os.Setenv("PULUMI_CONFIG_PASSPHRASE", "password"))
+
+	sink := diag.DefaultSink(ioutil.Discard, ioutil.Discard, diag.FormatOptions{Color: colors.Never})
+	b, err := filestate.New(sink, filestate.FilePathPrefix+filepath.ToSlash(dir))
+	if !assert.NoError(t, err) {
+		return
+	}
+	ref, err := b.ParseStackReference("dev")
+	if !assert.NoError(t, err) {
+		return
+	}
+	s, err := createStack(b, ref, nil, false /*setCurrent*/, "passphrase")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Give the stack a secret in both its configuration and its state.
+	key := config.MustMakeKey("test", "secret")
+	plaintextConfig := config.Map{key: config.NewSecureValue("hush")}
+	urn := resource.NewURN("dev", "test", "", "test:index:Resource", "a")
+	snap := deploy.NewSnapshot(deploy.Manifest{}, nil, []*resource.State{{
+		URN:     urn,
+		Type:    urn.Type(),
+		Custom:  true,
+		ID:      "a",
+		Outputs: resource.PropertyMap{"secret": resource.MakeSecret(resource.NewStringProperty("hush"))},
+	}}, nil)
+	sm, err := getStackSecretsManager(s)
+	if !assert.NoError(t, err) {
+		return
+	}
+	sdp, err := stack.SerializeDeployment(snap, sm)
+	if !assert.NoError(t, err) {
+		return
+	}
+	bytes, err := json.Marshal(sdp)
+	if !assert.NoError(t, err) {
+		return
+	}
+	deployment := &apitype.UntypedDeployment{Version: apitype.DeploymentSchemaVersionCurrent, Deployment: bytes}
+	if !assert.NoError(t, s.ImportDeployment(commandContext(), deployment)) {
+		return
+	}
+
+	// Change to a key management service, for which the local keeper stands in.
+	provider := "base64key://" + base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))
+	if !assert.NoError(t, changeSecretsProvider(s, provider, plaintextConfig, snap, deployment)) {
+		return
+	}
+
+	ps, err := loadProjectStack(s)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, provider, ps.SecretsProvider)
+	assert.Empty(t, ps.EncryptionSalt)
+
+	sm, err = getStackSecretsManager(s)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, cloud.Type, sm.Type())
+	dec, err := sm.Decrypter()
+	assert.NoError(t, err)
+	value, err := ps.Config[key].Value(dec)
+	assert.NoError(t, err)
+	assert.Equal(t, "hush", value)
+
+	exported, err := s.ExportDeployment(commandContext())
+	if !assert.NoError(t, err) {
+		return
+	}
+	changed, err := stack.DeserializeUntypedDeployment(exported, stack.DefaultSecretsProvider)
+	if assert.NoError(t, err) && assert.Len(t, changed.Resources, 1) {
+		assert.Equal(t, cloud.Type, changed.SecretsManager.Type())
+		secret := changed.Resources[0].Outputs["secret"]
+		assert.True(t, secret.IsSecret())
+		assert.Equal(t, "hush", secret.SecretValue().Element.StringValue())
+	}
+}
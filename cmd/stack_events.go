@@ -0,0 +1,138 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+// stackEventsPollInterval is how often a followed activity feed is polled for new events.
+const stackEventsPollInterval = 5 * time.Second
+
+func newStackEventsCmd() *cobra.Command {
+	var follow bool
+	var jsonOut bool
+	var stack string
+
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: "Show a stack's activity feed",
+		Long: "Show a stack's activity feed\n" +
+			"\n" +
+			"This command prints the timeline of a stack's updates, tag changes, and permission changes,\n" +
+			"oldest first. Pass `--follow` to keep printing new events as they occur. The activity feed\n" +
+			"is only available for stacks managed by the Pulumi service.",
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			pc, stackID, err := requireServiceStackClient(stack, "stack activity feeds")
+			if err != nil {
+				return err
+			}
+
+			list := func(ctx context.Context, token *string) (apitype.ListStackActivityResponse, error) {
+				return pc.ListStackActivity(ctx, stackID, token)
+			}
+
+			// Without --follow, JSON output is a single array; when following, each event is printed on its own line.
+			if jsonOut && !follow {
+				var events []apitype.StackActivityEvent
+				err = readStackActivity(commandContext(), list, false, func(e apitype.StackActivityEvent) error {
+					events = append(events, e)
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+				return printJSON(events)
+			}
+
+			return readStackActivity(commandContext(), list, follow, func(e apitype.StackActivityEvent) error {
+				if jsonOut {
+					b, err := json.Marshal(e)
+					if err != nil {
+						return err
+					}
+					fmt.Println(string(b))
+					return nil
+				}
+				fmt.Println(formatStackActivityEvent(e))
+				return nil
+			})
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().BoolVarP(
+		&follow, "follow", "f", false, "Follow the feed, printing new events as they occur")
+	cmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit output as JSON")
+
+	return cmd
+}
+
+// readStackActivity pages through a stack's activity feed, passing each event to emit. If follow is true, it then
+// polls the feed for new events until the context is canceled.
+func readStackActivity(ctx context.Context,
+	list func(ctx context.Context, token *string) (apitype.ListStackActivityResponse, error),
+	follow bool, emit func(apitype.StackActivityEvent) error) error {
+
+	var token *string
+	for {
+		resp, err := list(ctx, token)
+		if err != nil {
+			return err
+		}
+		for _, e := range resp.Events {
+			if err = emit(e); err != nil {
+				return err
+			}
+		}
+		if resp.ContinuationToken != nil {
+			token = resp.ContinuationToken
+		}
+
+		// Keep paging until the feed is exhausted, then either stop or wait for new events.
+		if len(resp.Events) > 0 && resp.ContinuationToken != nil {
+			continue
+		}
+		if !follow {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(stackEventsPollInterval):
+		}
+	}
+}
+
+// formatStackActivityEvent renders an activity feed event as a single line.
+func formatStackActivityEvent(e apitype.StackActivityEvent) string {
+	msg := e.Message
+	if e.Kind == apitype.StackActivityUpdate && e.Version != 0 {
+		msg = fmt.Sprintf("#%d %s", e.Version, msg)
+	}
+	return fmt.Sprintf("%s  %-17s  %-15s  %s",
+		time.Unix(e.Timestamp, 0).UTC().Format(timeFormat), e.Kind, e.User, msg)
+}
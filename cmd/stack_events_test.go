@@ -0,0 +1,65 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+)
+
+func TestReadStackActivity(t *testing.T) {
+	tok := func(s string) *string { return &s }
+	pages := map[string]apitype.ListStackActivityResponse{
+		"": {
+			Events:            []apitype.StackActivityEvent{{Message: "a"}, {Message: "b"}},
+			ContinuationToken: tok("1"),
+		},
+		"1": {
+			Events:            []apitype.StackActivityEvent{{Message: "c"}},
+			ContinuationToken: tok("2"),
+		},
+		"2": {ContinuationToken: tok("2")},
+	}
+
+	var tokens []string
+	list := func(ctx context.Context, token *string) (apitype.ListStackActivityResponse, error) {
+		t := ""
+		if token != nil {
+			t = *token
+		}
+		tokens = append(tokens, t)
+		return pages[t], nil
+	}
+
+	var messages []string
+	err := readStackActivity(context.Background(), list, false, func(e apitype.StackActivityEvent) error {
+		messages = append(messages, e.Message)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, messages)
+	assert.Equal(t, []string{"", "1", "2"}, tokens)
+}
+
+func TestFormatStackActivityEvent(t *testing.T) {
+	assert.Equal(t, "1970-01-01T00:00:01.000Z  update             alice            #3 succeeded",
+		formatStackActivityEvent(apitype.StackActivityEvent{
+			Kind: apitype.StackActivityUpdate, Timestamp: 1, User: "alice", Message: "succeeded", Version: 3,
+		}))
+}
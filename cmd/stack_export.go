@@ -21,13 +21,20 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 )
 
 func newStackExportCmd() *cobra.Command {
+	var anonymize bool
 	var file string
+	var showSecrets showSecretsFlag
 	var stackName string
+	var version int
 
 	cmd := &cobra.Command{
 		Use:   "export",
@@ -38,7 +45,21 @@ func newStackExportCmd() *cobra.Command {
 			"The deployment can then be hand-edited and used to update the stack via\n" +
 			"`pulumi stack import`. This process may be used to correct inconsistencies\n" +
 			"in a stack's state due to failed deployments, manual changes to cloud\n" +
-			"resources, etc.",
+			"resources, etc.\n" +
+			"\n" +
+			"Pass `--anonymize` to replace the names, IDs, IP addresses, credentials and secrets\n" +
+			"in the deployment with pseudonyms, so that it can be shared with others, e.g. to\n" +
+			"reproduce a bug, without revealing details of your infrastructure. The deployment's\n" +
+			"structure and the dependencies between its resources are preserved.\n" +
+			"\n" +
+			"Pass `--version` to export the deployment left by a prior update instead (see\n" +
+			"`pulumi stack history`).\n" +
+			"\n" +
+			"Pass `--show-secrets` to decrypt the deployment's secrets, or `--show-secrets=<path>` to\n" +
+			"decrypt only those at the given path, which may be repeated. Paths are of the form\n" +
+			"`[<urn>#]{inputs|outputs}[.<property path>]`; without a URN, they refer to the stack's\n" +
+			"resource, so `--show-secrets=outputs.dbPassword` decrypts the stack output dbPassword.\n" +
+			"Revealing secrets is recorded in the stack's audit log, if the backend keeps one.",
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			opts := display.Options{
 				Color: cmdutil.GetGlobalColorization(),
@@ -50,11 +71,44 @@ func newStackExportCmd() *cobra.Command {
 				return err
 			}
 
-			deployment, err := s.ExportDeployment(commandContext())
+			if version < 0 {
+				return errors.Errorf("'%d' is not a valid update version", version)
+			}
+			if anonymize && showSecrets.any() {
+				return errors.New("--anonymize and --show-secrets cannot be used together")
+			}
+			secretPaths, err := showSecrets.secretPaths()
 			if err != nil {
 				return err
 			}
 
+			var deployment *apitype.UntypedDeployment
+			if version != 0 {
+				b, ok := s.Backend().(backend.DeploymentHistoryBackend)
+				if !ok {
+					return errors.New("exporting prior versions of a stack is not supported by this backend")
+				}
+				deployment, err = b.ExportDeploymentVersion(commandContext(), s.Ref(), version)
+			} else {
+				deployment, err = s.ExportDeployment(commandContext())
+			}
+			if err != nil {
+				return err
+			}
+			if anonymize {
+				if deployment, err = anonymizeDeployment(deployment); err != nil {
+					return err
+				}
+			}
+			if showSecrets.any() {
+				if err = showSecrets.record(s, "stack export", version); err != nil {
+					return err
+				}
+				if deployment, err = revealDeploymentSecrets(deployment, secretPaths); err != nil {
+					return err
+				}
+			}
+
 			// Read from stdin or a specified file.
 			writer := os.Stdout
 			if file != "" {
@@ -77,5 +131,70 @@ func newStackExportCmd() *cobra.Command {
 		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
 	cmd.PersistentFlags().StringVarP(
 		&file, "file", "", "", "A filename to write stack output to")
+	cmd.PersistentFlags().IntVar(
+		&version, "version", 0, "The update version whose deployment to export. Defaults to the current deployment")
+	cmd.PersistentFlags().BoolVar(
+		&anonymize, "anonymize", false,
+		"Replace identifying values in the deployment with pseudonyms, so that it can be shared")
+	addShowSecretsFlag(cmd, &showSecrets, "Decrypt the deployment's secrets")
 	return cmd
 }
+
+// revealDeploymentSecrets returns the given deployment with the secrets at the given paths, or every secret if there
+// are no paths, decrypted.
+func revealDeploymentSecrets(deployment *apitype.UntypedDeployment,
+	paths []stack.SecretPath) (*apitype.UntypedDeployment, error) {
+
+	if deployment.Version != apitype.DeploymentSchemaVersionCurrent {
+		return nil, errors.Errorf("the secrets of deployments of version %d cannot be revealed", deployment.Version)
+	}
+
+	var dep apitype.DeploymentV3
+	if err := json.Unmarshal(deployment.Deployment, &dep); err != nil {
+		return nil, errors.Wrap(err, "could not read deployment")
+	}
+
+	var dec config.Decrypter = config.NewPanicCrypter()
+	if dep.SecretsProviders != nil && dep.SecretsProviders.Type != "" {
+		sm, err := stack.DefaultSecretsProvider.OfType(dep.SecretsProviders.Type, dep.SecretsProviders.State)
+		if err != nil {
+			return nil, err
+		}
+		if dec, err = sm.Decrypter(); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := stack.RevealSecrets(&dep, dec, paths); err != nil {
+		return nil, err
+	}
+	bytes, err := json.Marshal(dep)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not reveal secrets")
+	}
+	return &apitype.UntypedDeployment{
+		Version:    deployment.Version,
+		Deployment: bytes,
+	}, nil
+}
+
+// anonymizeDeployment returns the given deployment with the values that identify infrastructure replaced with
+// pseudonyms.
+func anonymizeDeployment(deployment *apitype.UntypedDeployment) (*apitype.UntypedDeployment, error) {
+	if deployment.Version != apitype.DeploymentSchemaVersionCurrent {
+		return nil, errors.Errorf("deployments of version %d cannot be anonymized", deployment.Version)
+	}
+
+	var dep apitype.DeploymentV3
+	if err := json.Unmarshal(deployment.Deployment, &dep); err != nil {
+		return nil, errors.Wrap(err, "could not read deployment")
+	}
+	bytes, err := json.Marshal(stack.AnonymizeDeployment(&dep))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not anonymize deployment")
+	}
+	return &apitype.UntypedDeployment{
+		Version:    deployment.Version,
+		Deployment: bytes,
+	}, nil
+}
@@ -15,13 +15,16 @@
 package cmd
 
 import (
+	"encoding/json"
+	"io"
 	"os"
 
 	"github.com/pulumi/pulumi/pkg/backend/display"
 	"github.com/pulumi/pulumi/pkg/graph"
 	"github.com/pulumi/pulumi/pkg/graph/dotconv"
 	"github.com/pulumi/pulumi/pkg/resource"
-	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 	"github.com/spf13/cobra"
 )
@@ -40,6 +43,9 @@ var parentEdgeColor string
 
 func newStackGraphCmd() *cobra.Command {
 	var stackName string
+	var jsonOut bool
+	var depth int
+	var types []string
 
 	cmd := &cobra.Command{
 		Use:   "graph",
@@ -48,8 +54,13 @@ func newStackGraphCmd() *cobra.Command {
 		Long: "Export a stack's dependency graph to a file.\n" +
 			"\n" +
 			"This command can be used to view the dependency graph that a Pulumi program\n" +
-			"admitted when it was ran. This graph is output in the DOT format. This command operates\n" +
-			"on your stack's most recent deployment.",
+			"admitted when it was ran. This graph is output in the DOT format, or with --json, as JSON that\n" +
+			"lists each resource's parent, provider, and dependencies, including those of each of its\n" +
+			"properties. This command operates on your stack's most recent deployment.\n" +
+			"\n" +
+			"The graphs of large stacks can be narrowed: --type keeps only the resources of the given types,\n" +
+			"and --depth collapses resources nested more deeply than the given number of levels below the\n" +
+			"stack into their ancestors at that level.",
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			opts := display.Options{
 				Color: cmdutil.GetGlobalColorization(),
@@ -64,13 +75,21 @@ func newStackGraphCmd() *cobra.Command {
 				return err
 			}
 
-			dg := makeDependencyGraph(snap)
+			var resources []*resource.State
+			if snap != nil {
+				resources = filterGraphResources(snap.Resources, depth, types)
+			}
 			file, err := os.Create(args[0])
 			if err != nil {
 				return err
 			}
 
-			if err := dotconv.Print(dg, file); err != nil {
+			if jsonOut {
+				err = writeStackGraphJSON(resources, file)
+			} else {
+				err = dotconv.Print(makeDependencyGraph(resources), file)
+			}
+			if err != nil {
 				_ = file.Close()
 				return err
 			}
@@ -90,9 +109,161 @@ func newStackGraphCmd() *cobra.Command {
 		"Sets the color of dependency edges in the graph")
 	cmd.PersistentFlags().StringVar(&parentEdgeColor, "parent-edge-color", "#AA6639",
 		"Sets the color of parent edges in the graph")
+	cmd.PersistentFlags().BoolVarP(&jsonOut, "json", "j", false,
+		"Write the graph as JSON rather than in the DOT format")
+	cmd.PersistentFlags().IntVar(&depth, "depth", 0,
+		"Collapse resources nested more than this many levels below the stack into their ancestors; 0 means no limit")
+	cmd.PersistentFlags().StringArrayVar(&types, "type", nil,
+		"Include only resources of the given type, such as aws:s3/bucket:Bucket; may be repeated")
 	return cmd
 }
 
+// filterGraphResources returns the resources that the graph of a stack with the given resources includes, given the
+// maximum depth of nesting to show, if it is positive, and the types of resources to show, if there are any. The
+// returned resources are copies whose parents and dependencies refer only to other returned resources: a resource
+// that is nested too deeply is replaced by its ancestor at the maximum depth, and one that is not of the given types
+// is left out, so that its children are parented by its nearest ancestor that is included.
+func filterGraphResources(resources []*resource.State, depth int, types []string) []*resource.State {
+	parents := make(map[resource.URN]resource.URN)
+	for _, res := range resources {
+		parents[res.URN] = res.Parent
+	}
+
+	// Work out which resource represents each one once deeply nested resources are collapsed.
+	represent := make(map[resource.URN]resource.URN)
+	for _, res := range resources {
+		var ancestors []resource.URN
+		for urn := res.URN; urn != ""; urn = parents[urn] {
+			ancestors = append(ancestors, urn)
+		}
+		// The stack's resource is at level zero, its children at level one, and so on.
+		represent[res.URN] = res.URN
+		if level := len(ancestors) - 1; depth > 0 && level > depth {
+			represent[res.URN] = ancestors[level-depth]
+		}
+	}
+
+	included := make(map[resource.URN]bool)
+	for _, res := range resources {
+		if represent[res.URN] != res.URN {
+			continue
+		}
+		if len(types) == 0 {
+			included[res.URN] = true
+			continue
+		}
+		for _, typ := range types {
+			if res.Type == tokens.Type(typ) {
+				included[res.URN] = true
+				break
+			}
+		}
+	}
+
+	// mapDependencies maps the given dependencies to those of the included resources that represent them.
+	mapDependencies := func(self resource.URN, deps []resource.URN) []resource.URN {
+		var result []resource.URN
+		seen := make(map[resource.URN]bool)
+		for _, dep := range deps {
+			if rep, ok := represent[dep]; ok && included[rep] && rep != self && !seen[rep] {
+				result = append(result, rep)
+				seen[rep] = true
+			}
+		}
+		return result
+	}
+
+	// A resource depends on whatever the resources it represents depend on.
+	deps := make(map[resource.URN][]resource.URN)
+	for _, res := range resources {
+		rep := represent[res.URN]
+		deps[rep] = append(deps[rep], res.Dependencies...)
+	}
+
+	var result []*resource.State
+	for _, res := range resources {
+		if !included[res.URN] {
+			continue
+		}
+
+		filtered := *res
+		filtered.Dependencies = mapDependencies(res.URN, deps[res.URN])
+		filtered.PropertyDependencies = make(map[resource.PropertyKey][]resource.URN)
+		for k, v := range res.PropertyDependencies {
+			filtered.PropertyDependencies[k] = mapDependencies(res.URN, v)
+		}
+
+		filtered.Parent = ""
+		for parent := res.Parent; parent != ""; parent = parents[parent] {
+			if included[parent] {
+				filtered.Parent = parent
+				break
+			}
+		}
+		result = append(result, &filtered)
+	}
+	return result
+}
+
+// stackGraphJSON is the shape of the --json output of `pulumi stack graph`.
+type stackGraphJSON struct {
+	Resources []stackGraphResourceJSON `json:"resources"`
+}
+
+type stackGraphResourceJSON struct {
+	URN                  string              `json:"urn"`
+	Type                 string              `json:"type"`
+	Parent               string              `json:"parent,omitempty"`
+	Provider             string              `json:"provider,omitempty"`
+	Dependencies         []string            `json:"dependencies,omitempty"`
+	PropertyDependencies map[string][]string `json:"propertyDependencies,omitempty"`
+}
+
+// writeStackGraphJSON writes the graph of the given resources to the given writer as JSON. Like the DOT output, it
+// leaves out parents if --ignore-parent-edges is set, and dependencies if --ignore-dependency-edges is set.
+func writeStackGraphJSON(resources []*resource.State, w io.Writer) error {
+	urns := func(deps []resource.URN) []string {
+		var result []string
+		for _, dep := range deps {
+			result = append(result, string(dep))
+		}
+		return result
+	}
+
+	graphJSON := stackGraphJSON{Resources: []stackGraphResourceJSON{}}
+	for _, res := range resources {
+		resJSON := stackGraphResourceJSON{
+			URN:  string(res.URN),
+			Type: string(res.Type),
+		}
+		if !ignoreParentEdges {
+			resJSON.Parent = string(res.Parent)
+		}
+		if ref, err := providers.ParseReference(res.Provider); err == nil {
+			resJSON.Provider = string(ref.URN())
+		}
+		if ignoreDependencyEdges {
+			graphJSON.Resources = append(graphJSON.Resources, resJSON)
+			continue
+		}
+		resJSON.Dependencies = urns(res.Dependencies)
+		if len(res.PropertyDependencies) > 0 {
+			resJSON.PropertyDependencies = make(map[string][]string)
+			for k, deps := range res.PropertyDependencies {
+				resJSON.PropertyDependencies[string(k)] = urns(deps)
+			}
+		}
+		graphJSON.Resources = append(graphJSON.Resources, resJSON)
+	}
+
+	b, err := json.MarshalIndent(graphJSON, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
 // All of the types and code within this file are to provide implementations of the interfaces
 // in the `graph` package, so that we can use the `dotconv` package to output our graph in the
 // DOT format.
@@ -206,14 +377,14 @@ func (dg *dependencyGraph) Roots() []graph.Edge {
 	return rootEdges
 }
 
-// Makes a dependency graph from a deployment snapshot, allocating a vertex
+// Makes a dependency graph from the resources of a deployment snapshot, allocating a vertex
 // for every resource in the graph.
-func makeDependencyGraph(snapshot *deploy.Snapshot) *dependencyGraph {
+func makeDependencyGraph(resources []*resource.State) *dependencyGraph {
 	dg := &dependencyGraph{
 		vertices: make(map[resource.URN]*dependencyVertex),
 	}
 
-	for _, resource := range snapshot.Resources {
+	for _, resource := range resources {
 		vertex := &dependencyVertex{
 			graph:    dg,
 			resource: resource,
@@ -0,0 +1,107 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+func TestFilterGraphResources(t *testing.T) {
+	newResource := func(typ tokens.Type, name string, parent resource.URN, deps ...resource.URN) *resource.State {
+		urn := resource.NewURN("dev", "proj", "", typ, tokens.QName(name))
+		return &resource.State{URN: urn, Type: typ, Parent: parent, Dependencies: deps}
+	}
+
+	// stack
+	// ├── component
+	// │   └── bucket
+	// │       └── object (depends on role)
+	// └── role
+	stack := newResource("pulumi:pulumi:Stack", "dev", "")
+	component := newResource("my:index:Component", "component", stack.URN)
+	bucket := newResource("aws:s3/bucket:Bucket", "bucket", component.URN)
+	role := newResource("aws:iam/role:Role", "role", stack.URN)
+	object := newResource("aws:s3/bucketObject:BucketObject", "object", bucket.URN, role.URN, bucket.URN)
+	object.PropertyDependencies = map[resource.PropertyKey][]resource.URN{"role": {role.URN}}
+	resources := []*resource.State{stack, component, bucket, role, object}
+
+	urns := func(resources []*resource.State) []resource.URN {
+		var result []resource.URN
+		for _, res := range resources {
+			result = append(result, res.URN)
+		}
+		return result
+	}
+
+	// Without filters, every resource is kept as it is.
+	assert.Equal(t, urns(resources), urns(filterGraphResources(resources, 0, nil)))
+
+	// Collapsing to one level below the stack folds the component's descendants into it, along with their
+	// dependencies.
+	collapsed := filterGraphResources(resources, 1, nil)
+	if assert.Equal(t, []resource.URN{stack.URN, component.URN, role.URN}, urns(collapsed)) {
+		assert.Equal(t, []resource.URN{role.URN}, collapsed[1].Dependencies)
+		assert.Equal(t, stack.URN, collapsed[1].Parent)
+	}
+
+	// Filtering by type reparents resources to their nearest included ancestor and drops excluded dependencies.
+	typed := filterGraphResources(resources, 0, []string{"aws:s3/bucket:Bucket", "aws:s3/bucketObject:BucketObject"})
+	if assert.Equal(t, []resource.URN{bucket.URN, object.URN}, urns(typed)) {
+		assert.Equal(t, resource.URN(""), typed[0].Parent)
+		assert.Equal(t, bucket.URN, typed[1].Parent)
+		assert.Equal(t, []resource.URN{bucket.URN}, typed[1].Dependencies)
+		assert.Empty(t, typed[1].PropertyDependencies["role"])
+	}
+
+	// The original resources are left alone.
+	assert.Equal(t, []resource.URN{role.URN, bucket.URN}, object.Dependencies)
+	assert.Equal(t, component.URN, bucket.Parent)
+
+	var buf bytes.Buffer
+	assert.NoError(t, writeStackGraphJSON(filterGraphResources(resources, 0, nil), &buf))
+	var graph stackGraphJSON
+	if assert.NoError(t, json.Unmarshal(buf.Bytes(), &graph)) && assert.Len(t, graph.Resources, 5) {
+		assert.Equal(t, stackGraphResourceJSON{
+			URN:                  string(object.URN),
+			Type:                 string(object.Type),
+			Parent:               string(bucket.URN),
+			Dependencies:         []string{string(role.URN), string(bucket.URN)},
+			PropertyDependencies: map[string][]string{"role": {string(role.URN)}},
+		}, graph.Resources[4])
+	}
+
+	// The edge filters apply to the JSON output just as they do to the DOT output.
+	defer func(parents, dependencies bool) {
+		ignoreParentEdges, ignoreDependencyEdges = parents, dependencies
+	}(ignoreParentEdges, ignoreDependencyEdges)
+	ignoreParentEdges, ignoreDependencyEdges = true, true
+
+	buf.Reset()
+	assert.NoError(t, writeStackGraphJSON(filterGraphResources(resources, 0, nil), &buf))
+	graph = stackGraphJSON{}
+	if assert.NoError(t, json.Unmarshal(buf.Bytes(), &graph)) && assert.Len(t, graph.Resources, 5) {
+		assert.Equal(t, stackGraphResourceJSON{
+			URN:  string(object.URN),
+			Type: string(object.Type),
+		}, graph.Resources[4])
+	}
+}
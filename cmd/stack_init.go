@@ -15,16 +15,27 @@
 package cmd
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/backend/httpstate"
+	"github.com/pulumi/pulumi/pkg/backend/state"
+	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 )
 
 func newStackInitCmd() *cobra.Command {
 	var secretsProvider string
 	var stackName string
+	var copyConfigFrom string
+	var copyPermissions bool
+	var orSelect bool
 
 	cmd := &cobra.Command{
 		Use:   "init [<org-name>/]<stack-name>",
@@ -51,7 +62,22 @@ func newStackInitCmd() *cobra.Command {
 			"* `pulumi stack init --secrets-provider=\"awskms://1234abcd-12ab-34cd-56ef-1234567890ab?region=us-east-1\"`\n" +
 			"* `pulumi stack init --secrets-provider=\"azurekeyvault://mykeyvaultname.vault.azure.net/keys/mykeyname\"`\n" +
 			"* `pulumi stack init --secrets-provider=\"gcpkms://projects/<p>/locations/<l>/keyRings/<r>/cryptoKeys/<k>\"`\n" +
-			"* `pulumi stack init --secrets-provider=\"hashivault://mykey\"`",
+			"* `pulumi stack init --secrets-provider=\"hashivault://mykey\"`\n" +
+			"\n" +
+			"To use a key in the transit secrets engine of a HashiCorp Vault server, authenticating with the token\n" +
+			"in VAULT_TOKEN or, with `auth=approle`, the AppRole IDs in VAULT_ROLE_ID and VAULT_SECRET_ID, use:\n" +
+			"* `pulumi stack init --secrets-provider=\"vault://mykey?address=https://vault.example.com:8200\"`\n" +
+			"Unlike `hashivault://`, which only authenticates with VAULT_SERVER_TOKEN and uses the `transit` mount,\n" +
+			"`vault://` keys support AppRole, other mounts, and `pulumi stack rotate-secrets-key`.\n" +
+			"\n" +
+			"To create a stack from the definition of an existing one, pass `--copy-config-from` with the name\n" +
+			"of the existing stack. Its configuration is copied, with secrets re-encrypted for the new stack,\n" +
+			"along with its tags if both stacks are managed by the Pulumi service. Pass `--copy-permissions`\n" +
+			"to also grant the new stack's collaborators the same access to it:\n" +
+			"* `pulumi stack init staging --copy-config-from production --copy-permissions`\n" +
+			"\n" +
+			"To create the stack only if it does not already exist, and otherwise select it, pass `--or-select`.\n" +
+			"Nothing is copied to a stack that is selected rather than created.",
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			opts := display.Options{
 				Color: cmdutil.GetGlobalColorization(),
@@ -74,8 +100,22 @@ func newStackInitCmd() *cobra.Command {
 			if err := validateSecretsProvider(secretsProvider); err != nil {
 				return err
 			}
+			if copyPermissions && copyConfigFrom == "" {
+				return errors.New("--copy-permissions requires --copy-config-from")
+			}
 
-			if stackName == "" && cmdutil.Interactive() {
+			// Load the stack to copy from before creating the new one, so that a bad name doesn't leave a stack behind.
+			var source backend.Stack
+			if copyConfigFrom != "" {
+				if source, err = requireStack(copyConfigFrom, false, opts, false /*setCurrent*/); err != nil {
+					return err
+				}
+			}
+
+			if stackName == "" {
+				if !cmdutil.Interactive() {
+					return cmdutil.NonInteractiveError("a stack name is required", "pass it as an argument")
+				}
 				hint := "Please enter your desired stack name."
 				if b.SupportsOrganizations() {
 					hint += "\nTo create a stack in an organization, " +
@@ -98,14 +138,182 @@ func newStackInitCmd() *cobra.Command {
 			}
 
 			var createOpts interface{} // Backend-specific config options, none currently.
-			_, err = createStack(b, stackRef, createOpts, true /*setCurrent*/, secretsProvider)
-			return err
+			if orSelect {
+				s, created, err := createOrSelectStack(b, stackRef, createOpts, secretsProvider)
+				if err != nil {
+					return err
+				}
+				if !created {
+					fmt.Printf("Selected existing stack '%s'\n", s.Ref())
+					return nil
+				}
+				if source == nil {
+					return nil
+				}
+				return copyStackDefinition(source, s, copyPermissions)
+			}
+
+			s, err := createStack(b, stackRef, createOpts, true /*setCurrent*/, secretsProvider)
+			if err != nil || source == nil {
+				return err
+			}
+			return copyStackDefinition(source, s, copyPermissions)
 		}),
 	}
 	cmd.PersistentFlags().StringVarP(
 		&stackName, "stack", "s", "", "The name of the stack to create")
 	cmd.PersistentFlags().StringVar(
 		&secretsProvider, "secrets-provider", "default", "The type of the provider that should be used to encrypt and "+
-			"decrypt secrets (possible choices: default, passphrase, awskms, azurekeyvault, gcpkms, hashivault)")
+			"decrypt secrets (possible choices: default, passphrase, awskms, azurekeyvault, gcpkms, hashivault, vault)")
+	cmd.PersistentFlags().StringVar(
+		&copyConfigFrom, "copy-config-from", "",
+		"The name of an existing stack whose configuration and tags should be copied to the new stack")
+	cmd.PersistentFlags().BoolVar(
+		&copyPermissions, "copy-permissions", false,
+		"Also copy the existing stack's user and team permissions (requires --copy-config-from)")
+	cmd.PersistentFlags().BoolVar(
+		&orSelect, "or-select", false,
+		"Select the stack instead of failing if it already exists")
 	return cmd
 }
+
+// createOrSelectStack creates the stack with the given name and selects it, or, if the stack already exists, just
+// selects it. It returns true if the stack was created.
+func createOrSelectStack(b backend.Backend, stackRef backend.StackReference, opts interface{},
+	secretsProvider string) (backend.Stack, bool, error) {
+
+	// Look for the stack first, so that selecting an existing stack doesn't initialize its secrets provider.
+	s, err := b.GetStack(commandContext(), stackRef)
+	if err != nil {
+		return nil, false, err
+	}
+	if s == nil {
+		s, err = createStack(b, stackRef, opts, true /*setCurrent*/, secretsProvider)
+		if err == nil {
+			return s, true, nil
+		}
+
+		// The stack may have been created by someone else since we looked for it, in which case we select it.
+		if _, exists := err.(*backend.StackAlreadyExistsError); !exists {
+			return nil, false, err
+		}
+		if s, err = b.GetStack(commandContext(), stackRef); err != nil {
+			return nil, false, err
+		} else if s == nil {
+			return nil, false, errors.Errorf("stack '%s' already exists, but could not be found", stackRef)
+		}
+	}
+
+	if err = state.SetCurrentStack(s.Ref().String()); err != nil {
+		return nil, false, err
+	}
+	return s, false, nil
+}
+
+// copyStackDefinition copies the configuration, tags, and optionally permissions of one stack to a new stack.
+func copyStackDefinition(source, target backend.Stack, copyPermissions bool) error {
+	sourceStack, err := loadProjectStack(source)
+	if err != nil {
+		return errors.Wrap(err, "loading the configuration to copy")
+	}
+	targetStack, err := loadProjectStack(target)
+	if err != nil {
+		return err
+	}
+
+	// Secret values must be decrypted with the source stack's secrets provider and re-encrypted with the new one's.
+	var dec config.Decrypter = config.NewPanicCrypter()
+	var enc config.Encrypter = config.NewPanicCrypter()
+	if sourceStack.Config.HasSecureValue() {
+		if dec, err = getStackDencrypter(source); err != nil {
+			return errors.Wrap(err, "getting the decrypter for the configuration to copy")
+		}
+		if enc, err = getStackEncrypter(target); err != nil {
+			return errors.Wrap(err, "getting the encrypter for the new stack")
+		}
+	}
+	if targetStack.Config, err = copyStackConfig(sourceStack.Config, dec, enc); err != nil {
+		return err
+	}
+	if err = saveProjectStack(target, targetStack); err != nil {
+		return errors.Wrap(err, "saving the copied configuration")
+	}
+	fmt.Printf("Copied %d configuration values from stack %s.\n", len(targetStack.Config), source.Ref())
+
+	// Tags and permissions are only kept by the Pulumi service.
+	sourceCloud, sourceOK := source.(httpstate.Stack)
+	targetCloud, targetOK := target.(httpstate.Stack)
+	if !sourceOK || !targetOK {
+		if copyPermissions {
+			return errors.New("permissions can only be copied between stacks managed by the Pulumi service")
+		}
+		return nil
+	}
+
+	ctx := commandContext()
+	tags, err := backend.GetStackTags(ctx, source)
+	if err != nil {
+		return errors.Wrap(err, "getting the tags to copy")
+	}
+	if userTags := userStackTags(tags); len(userTags) > 0 {
+		targetTags, err := backend.GetStackTags(ctx, target)
+		if err != nil {
+			return err
+		}
+		if targetTags == nil {
+			targetTags = make(map[apitype.StackTagName]string)
+		}
+		for name, value := range userTags {
+			targetTags[name] = value
+		}
+		if err = backend.UpdateStackTags(ctx, target, targetTags); err != nil {
+			return errors.Wrap(err, "copying tags")
+		}
+		fmt.Printf("Copied %d tags from stack %s.\n", len(userTags), source.Ref())
+	}
+
+	if copyPermissions {
+		pc := sourceCloud.Backend().(httpstate.Backend).Client()
+		collaborators, err := pc.ListStackPermissions(ctx, sourceCloud.StackIdentifier())
+		if err != nil {
+			return errors.Wrap(err, "getting the permissions to copy")
+		}
+		for _, c := range collaborators {
+			err = pc.GrantStackPermission(ctx, targetCloud.StackIdentifier(), c.Kind, c.Name, c.Permission)
+			if err != nil {
+				return errors.Wrapf(err, "granting %s %s access to the new stack", c.Kind, c.Name)
+			}
+		}
+		fmt.Printf("Copied %d permissions from stack %s.\n", len(collaborators), source.Ref())
+	}
+	return nil
+}
+
+// copyStackConfig returns a copy of a stack's configuration, with secret values decrypted by dec and re-encrypted by
+// enc.
+func copyStackConfig(cfg config.Map, dec config.Decrypter, enc config.Encrypter) (config.Map, error) {
+	copied := make(config.Map, len(cfg))
+	for k, v := range cfg {
+		c, err := v.Copy(dec, enc)
+		if err != nil {
+			return nil, errors.Wrapf(err, "copying %s", k)
+		}
+		copied[k] = c
+	}
+	return copied, nil
+}
+
+// userStackTags returns the tags that were set by users, leaving out those the CLI sets automatically on each update.
+func userStackTags(tags map[apitype.StackTagName]string) map[apitype.StackTagName]string {
+	user := make(map[apitype.StackTagName]string)
+outer:
+	for name, value := range tags {
+		for _, prefix := range []string{"pulumi:", "gitHub:", "vcs:"} {
+			if strings.HasPrefix(name, prefix) {
+				continue outer
+			}
+		}
+		user[name] = value
+	}
+	return user
+}
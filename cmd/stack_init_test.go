@@ -0,0 +1,144 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/filestate"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestCopyStackConfig(t *testing.T) {
+	source := config.NewSymmetricCrypter(make([]byte, 32))
+	target := config.NewSymmetricCrypter([]byte("0123456789abcdef0123456789abcdef"))
+
+	ciphertext, err := source.EncryptValue("hunter2")
+	assert.NoError(t, err)
+
+	region, password := config.MustMakeKey("proj", "region"), config.MustMakeKey("proj", "password")
+	copied, err := copyStackConfig(config.Map{
+		region:   config.NewValue("us-west-2"),
+		password: config.NewSecureValue(ciphertext),
+	}, source, target)
+	assert.NoError(t, err)
+
+	assert.Equal(t, config.NewValue("us-west-2"), copied[region])
+	assert.True(t, copied[password].Secure())
+	plaintext, err := copied[password].Value(target)
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter2", plaintext)
+
+	// Secrets that cannot be decrypted are reported rather than copied.
+	_, err = copyStackConfig(config.Map{password: config.NewSecureValue(ciphertext)}, target, target)
+	assert.Error(t, err)
+}
+
+func TestUserStackTags(t *testing.T) {
+	assert.Equal(t, map[apitype.StackTagName]string{"team": "infra"}, userStackTags(map[apitype.StackTagName]string{
+		"team":                       "infra",
+		apitype.ProjectNameTag:       "proj",
+		apitype.GitHubOwnerNameTag:   "pulumi",
+		apitype.VCSRepositoryKindTag: "github.com",
+	}))
+}
+
+// racingBackend is a backend in which another client creates each stack between the first time it is looked for and
+// the time it is created, so that creating it fails as it does when the Pulumi service responds 409 Conflict.
+type racingBackend struct {
+	backend.Backend
+	creates int
+}
+
+func (b *racingBackend) GetStack(ctx context.Context, ref backend.StackReference) (backend.Stack, error) {
+	if b.creates == 0 {
+		return nil, nil
+	}
+	return b.Backend.GetStack(ctx, ref)
+}
+
+func (b *racingBackend) CreateStack(ctx context.Context, ref backend.StackReference,
+	opts interface{}) (backend.Stack, error) {
+
+	b.creates++
+	if _, err := b.Backend.CreateStack(ctx, ref, opts); err != nil {
+		return nil, err
+	}
+	return b.Backend.CreateStack(ctx, ref, opts)
+}
+
+func TestCreateOrSelectExistingStack(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stack-init")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	cwd, err := os.Getwd()
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() { assert.NoError(t, os.Chdir(cwd)) }()
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "Pulumi.yaml"), []byte("name: proj\nruntime: nodejs\n"), 0600))
+	if !assert.NoError(t, os.Chdir(dir)) {
+		return
+	}
+
+	defer func(old string, had bool) {
+		if had {
+			os.Setenv("PULUMI_CONFIG_PASSPHRASE", old)
+		} else {
+			os.Unsetenv("PULUMI_CONFIG_PASSPHRASE")
+		}
+	}(os.LookupEnv("PULUMI_CONFIG_PASSPHRASE"))
+	assert.NoError(t, os.Setenv("PULUMI_CONFIG_PASSPHRASE", "password"))
+
+	sink := diag.DefaultSink(ioutil.Discard, ioutil.Discard, diag.FormatOptions{Color: colors.Never})
+	fb, err := filestate.New(sink, filestate.FilePathPrefix+filepath.ToSlash(dir))
+	if !assert.NoError(t, err) {
+		return
+	}
+	b := &racingBackend{Backend: fb}
+	ref, err := b.ParseStackReference("dev")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// The stack that already exists is selected instead.
+	s, created, err := createOrSelectStack(b, ref, nil, "passphrase")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.False(t, created)
+	assert.Equal(t, 1, b.creates)
+	assert.Equal(t, "dev", s.Ref().String())
+
+	w, err := workspace.New()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "dev", w.Settings().Stack)
+}
@@ -15,11 +15,16 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"os"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/dustin/go-humanize"
+	"github.com/dustin/go-humanize/english"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
@@ -37,6 +42,8 @@ func newStackLsCmd() *cobra.Command {
 	var orgFilter string
 	var projFilter string
 	var tagFilter string
+	var updateStatus bool
+	var parallel int
 
 	cmd := &cobra.Command{
 		Use:   "ls",
@@ -49,7 +56,11 @@ func newStackLsCmd() *cobra.Command {
 			"\n" +
 			"Results may be further filtered by passing additional flags. Tag filters may include\n" +
 			"the tag name as well as the tag value, separated by an equals sign. For example\n" +
-			"'environment=production' or just 'gcp:project'.",
+			"'environment=production' or just 'gcp:project'.\n" +
+			"\n" +
+			"Passing --update-status adds the kind and result of each stack's latest update, e.g.\n" +
+			"'update failed'. These are fetched for several stacks at once; a stack whose status can't\n" +
+			"be fetched is listed with an unknown status rather than failing the command.",
 		Args: cmdutil.NoArgs,
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			// Build up the stack filters. We do not support accepting empty strings as filters
@@ -111,11 +122,23 @@ func newStackLsCmd() *cobra.Command {
 				return stackSummaries[i].Name().String() < stackSummaries[j].Name().String()
 			})
 
+			// Fetch each stack's update status, if requested.
+			var statuses []string
+			if updateStatus {
+				var failures int
+				statuses, failures, err = getStackUpdateStatuses(commandContext(), stackSummaries, parallel,
+					b.GetHistory)
+				if failures > 0 {
+					fmt.Fprintf(os.Stderr, "warning: could not get the update status of %d %s: %v\n",
+						failures, english.PluralWord(failures, "stack", ""), err)
+				}
+			}
+
 			if jsonOut {
-				return formatStackSummariesJSON(b, current, stackSummaries)
+				return formatStackSummariesJSON(b, current, stackSummaries, statuses)
 			}
 
-			return formatStackSummariesConsole(b, current, stackSummaries)
+			return formatStackSummariesConsole(b, current, stackSummaries, statuses)
 		}),
 	}
 	cmd.PersistentFlags().BoolVarP(
@@ -130,6 +153,11 @@ func newStackLsCmd() *cobra.Command {
 		&projFilter, "project", "p", "", "Filter returned stacks to those with a specific project name")
 	cmd.PersistentFlags().StringVarP(
 		&tagFilter, "tag", "t", "", "Filter returned stacks to those in a specific tag (tag-name or tag-name=tag-value)")
+	cmd.PersistentFlags().BoolVar(
+		&updateStatus, "update-status", false, "Show the kind and result of each stack's latest update")
+	cmd.PersistentFlags().IntVar(
+		&parallel, "parallel", defaultStackStatusParallelism,
+		"The number of stacks whose update status is fetched at once (with --update-status)")
 
 	return cmd
 }
@@ -155,9 +183,11 @@ type stackSummaryJSON struct {
 	UpdateInProgress bool   `json:"updateInProgress"`
 	ResourceCount    *int   `json:"resourceCount,omitempty"`
 	URL              string `json:"url,omitempty"`
+	UpdateStatus     string `json:"updateStatus,omitempty"`
 }
 
-func formatStackSummariesJSON(b backend.Backend, currentStack string, stackSummaries []backend.StackSummary) error {
+func formatStackSummariesJSON(b backend.Backend, currentStack string, stackSummaries []backend.StackSummary,
+	statuses []string) error {
 	output := make([]stackSummaryJSON, len(stackSummaries))
 	for idx, summary := range stackSummaries {
 		summaryJSON := stackSummaryJSON{
@@ -180,24 +210,32 @@ func formatStackSummariesJSON(b backend.Backend, currentStack string, stackSumma
 			}
 		}
 
+		if statuses != nil {
+			summaryJSON.UpdateStatus = statuses[idx]
+		}
+
 		output[idx] = summaryJSON
 	}
 
 	return printJSON(output)
 }
 
-func formatStackSummariesConsole(b backend.Backend, currentStack string, stackSummaries []backend.StackSummary) error {
+func formatStackSummariesConsole(b backend.Backend, currentStack string, stackSummaries []backend.StackSummary,
+	statuses []string) error {
 	_, showURLColumn := b.(httpstate.Backend)
 
 	// Header string and formatting options to align columns.
 	headers := []string{"NAME", "LAST UPDATE", "RESOURCE COUNT"}
+	if statuses != nil {
+		headers = append(headers, "UPDATE STATUS")
+	}
 	if showURLColumn {
 		headers = append(headers, "URL")
 	}
 
 	rows := []cmdutil.TableRow{}
 
-	for _, summary := range stackSummaries {
+	for idx, summary := range stackSummaries {
 		const none = "n/a"
 
 		// Name column
@@ -224,6 +262,13 @@ func formatStackSummariesConsole(b backend.Backend, currentStack string, stackSu
 
 		// Render the columns.
 		columns := []string{name, lastUpdate, resourceCount}
+		if statuses != nil {
+			status := statuses[idx]
+			if status == "" {
+				status = "unknown"
+			}
+			columns = append(columns, status)
+		}
 		if showURLColumn {
 			url := none
 			if httpBackend, ok := b.(httpstate.Backend); ok {
@@ -250,3 +295,65 @@ func isUpdateInProgress(u backend.StackSummary) bool {
 	// When an update is in progress the last update time is set to zero.
 	return u.LastUpdate() != nil && u.LastUpdate().Unix() == 0
 }
+
+// defaultStackStatusParallelism is the default number of stacks whose update status is fetched at once.
+const defaultStackStatusParallelism = 10
+
+// getStackUpdateStatuses fetches the status of the latest update of each of the given stacks with the given history
+// function, fetching up to parallel of them at once. The statuses are returned in the same order as the stacks. A
+// stack whose status can't be fetched has an empty status; the number of such stacks is returned along with the first
+// of their errors.
+func getStackUpdateStatuses(ctx context.Context, stackSummaries []backend.StackSummary, parallel int,
+	getHistory func(context.Context, backend.StackReference) ([]backend.UpdateInfo, error)) ([]string, int, error) {
+
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	statuses := make([]string, len(stackSummaries))
+	var lock sync.Mutex
+	var failures int
+	var firstErr error
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range indices {
+				history, err := getHistory(ctx, stackSummaries[idx].Name())
+				if err != nil {
+					lock.Lock()
+					if failures == 0 {
+						firstErr = errors.Wrapf(err, "getting the history of stack '%s'", stackSummaries[idx].Name())
+					}
+					failures++
+					lock.Unlock()
+					continue
+				}
+				statuses[idx] = describeUpdateStatus(history)
+			}
+		}()
+	}
+	for idx := range stackSummaries {
+		indices <- idx
+	}
+	close(indices)
+	wg.Wait()
+
+	return statuses, failures, firstErr
+}
+
+// describeUpdateStatus describes the latest of the given updates, newest first, e.g. "update succeeded".
+func describeUpdateStatus(history []backend.UpdateInfo) string {
+	if len(history) == 0 {
+		return "n/a"
+	}
+
+	latest := history[0]
+	if latest.Result == backend.InProgressResult {
+		return fmt.Sprintf("%s in progress", latest.Kind)
+	}
+	return fmt.Sprintf("%s %s", latest.Kind, latest.Result)
+}
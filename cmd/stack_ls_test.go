@@ -15,9 +15,18 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/tokens"
 )
 
 func TestParseTagFilter(t *testing.T) {
@@ -61,3 +70,62 @@ func TestParseTagFilter(t *testing.T) {
 		}
 	}
 }
+
+type testStackReference string
+
+func (r testStackReference) String() string     { return string(r) }
+func (r testStackReference) Name() tokens.QName { return tokens.QName(r) }
+
+type testStackSummary string
+
+func (s testStackSummary) Name() backend.StackReference { return testStackReference(s) }
+func (s testStackSummary) LastUpdate() *time.Time       { return nil }
+func (s testStackSummary) ResourceCount() *int          { return nil }
+
+func TestGetStackUpdateStatuses(t *testing.T) {
+	var summaries []backend.StackSummary
+	for i := 0; i < 50; i++ {
+		summaries = append(summaries, testStackSummary(fmt.Sprintf("stack%d", i)))
+	}
+
+	var active, maxActive int32
+	getHistory := func(ctx context.Context, ref backend.StackReference) ([]backend.UpdateInfo, error) {
+		n := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		for {
+			max := atomic.LoadInt32(&maxActive)
+			if n <= max || atomic.CompareAndSwapInt32(&maxActive, max, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+
+		switch ref.String() {
+		case "stack0":
+			return nil, nil
+		case "stack1":
+			return []backend.UpdateInfo{{Kind: apitype.UpdateUpdate, Result: backend.InProgressResult}}, nil
+		case "stack7", "stack8":
+			return nil, errors.New("unavailable")
+		default:
+			return []backend.UpdateInfo{
+				{Kind: apitype.RefreshUpdate, Result: backend.FailedResult},
+				{Kind: apitype.UpdateUpdate, Result: backend.SucceededResult},
+			}, nil
+		}
+	}
+
+	// Statuses are fetched concurrently, but no more than the given number at once, and failures don't stop the rest.
+	statuses, failures, err := getStackUpdateStatuses(context.Background(), summaries, 4, getHistory)
+	assert.True(t, atomic.LoadInt32(&maxActive) <= 4)
+	assert.Equal(t, 2, failures)
+	assert.Error(t, err)
+	if assert.Len(t, statuses, 50) {
+		assert.Equal(t, "n/a", statuses[0])
+		assert.Equal(t, "update in progress", statuses[1])
+		assert.Equal(t, "refresh failed", statuses[2])
+		assert.Equal(t, "", statuses[7])
+		assert.Equal(t, "", statuses[8])
+		assert.Equal(t, "refresh failed", statuses[49])
+	}
+}
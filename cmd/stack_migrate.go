@@ -0,0 +1,406 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/backend/state"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/secrets"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/result"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// stackSecretsProvider deserializes deployments with a stack's own secrets manager, whose passphrase, if any, has
+// already been prompted for, rather than with one read from the environment.
+type stackSecretsProvider struct {
+	sm secrets.Manager
+}
+
+func (p stackSecretsProvider) OfType(ty string, state json.RawMessage) (secrets.Manager, error) {
+	if p.sm != nil && p.sm.Type() == ty {
+		return p.sm, nil
+	}
+	return stack.DefaultSecretsProvider.OfType(ty, state)
+}
+
+// verifyMigratedDeployment checks that a deployment imported into a new backend has the same resources as the one it
+// was migrated from.
+func verifyMigratedDeployment(source, target *apitype.UntypedDeployment) error {
+	resources := func(d *apitype.UntypedDeployment) ([]apitype.ResourceV3, error) {
+		var deployment apitype.DeploymentV3
+		if err := json.Unmarshal(d.Deployment, &deployment); err != nil {
+			return nil, err
+		}
+		return deployment.Resources, nil
+	}
+
+	sourceResources, err := resources(source)
+	if err != nil {
+		return errors.Wrap(err, "reading the source deployment")
+	}
+	targetResources, err := resources(target)
+	if err != nil {
+		return errors.Wrap(err, "reading the migrated deployment")
+	}
+
+	if len(sourceResources) != len(targetResources) {
+		return errors.Errorf("the migrated deployment has %d resources, but the source has %d",
+			len(targetResources), len(sourceResources))
+	}
+	for i, res := range sourceResources {
+		if targetResources[i].URN != res.URN || targetResources[i].ID != res.ID {
+			return errors.Errorf("resource %s (ID %q) was migrated as %s (ID %q)",
+				res.URN, res.ID, targetResources[i].URN, targetResources[i].ID)
+		}
+	}
+	return nil
+}
+
+func newStackMigrateCmd() *cobra.Command {
+	var stackName string
+	var to string
+	var targetStackName string
+	var secretsProvider string
+	var removeSource bool
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Args:  cmdutil.NoArgs,
+		Short: "Move a stack to a different backend",
+		Long: "Move a stack to a different backend.\n" +
+			"\n" +
+			"This command copies a stack's state and configuration from the current backend to the backend at\n" +
+			"the URL given by `--to`, which may be any URL accepted by `pulumi login`. Secrets in both the\n" +
+			"state and the configuration are re-encrypted with the new stack's secrets provider, chosen with\n" +
+			"`--secrets-provider` as for `pulumi stack init`. The stack's update history is not copied, as\n" +
+			"backends cannot import it.\n" +
+			"\n" +
+			"The migrated stack is checked against the original before the CLI is logged in to the new backend\n" +
+			"and the migrated stack is selected. If anything fails, the new stack is removed and the original\n" +
+			"configuration is left untouched. The original stack is kept unless `--remove-source` is passed; if\n" +
+			"it is kept under the same name, its configuration file is saved with a `.bak` suffix:\n" +
+			"\n" +
+			"* `pulumi stack migrate --to s3://my-pulumi-state-bucket --remove-source`",
+		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			if to == "" {
+				return result.Error("the URL of the backend to migrate to must be passed with --to")
+			}
+			if err := validateSecretsProvider(secretsProvider); err != nil {
+				return result.FromError(err)
+			}
+
+			source, err := requireStack(stackName, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return result.FromError(err)
+			}
+			if targetStackName == "" {
+				targetStackName = string(source.Ref().Name())
+			}
+
+			// Read everything that needs migrating before changing anything.
+			ctx := commandContext()
+			configPath, err := getProjectStackPath(source)
+			if err != nil {
+				return result.FromError(err)
+			}
+			ps, err := loadProjectStack(source)
+			if err != nil {
+				return result.FromError(err)
+			}
+			sm, err := getStackSecretsManager(source)
+			if err != nil {
+				return result.FromError(err)
+			}
+			dec, err := sm.Decrypter()
+			if err != nil {
+				return result.FromError(err)
+			}
+			plaintextConfig, err := copyStackConfig(ps.Config, dec, config.NopEncrypter)
+			if err != nil {
+				return result.FromError(err)
+			}
+			deployment, err := source.ExportDeployment(ctx)
+			if err != nil {
+				return result.FromError(errors.Wrap(err, "exporting the stack's state"))
+			}
+			snap, err := stack.DeserializeUntypedDeployment(deployment, stackSecretsProvider{sm: sm})
+			if err != nil {
+				return result.FromError(errors.Wrap(err, "reading the stack's state"))
+			}
+			history, err := source.Backend().GetHistory(ctx, source.Ref())
+			if err != nil {
+				return result.FromError(errors.Wrap(err, "reading the stack's history"))
+			}
+
+			// Logging in to the new backend makes it the current one, so switch back if the migration fails.
+			creds, err := workspace.GetStoredCredentials()
+			if err != nil {
+				return result.FromError(err)
+			}
+			target, err := loginToBackend(to, opts)
+			if err != nil {
+				return result.FromError(errors.Wrap(err, "logging in to the new backend"))
+			}
+			if target.URL() == source.Backend().URL() {
+				contract.IgnoreError(restoreCurrentBackend(creds.Current))
+				return result.Errorf("stack '%s' is already managed by %s", source.Ref(), target.URL())
+			}
+
+			targetRef, err := target.ParseStackReference(targetStackName)
+			if err == nil {
+				var existing backend.Stack
+				if existing, err = target.GetStack(ctx, targetRef); err == nil && existing != nil {
+					err = errors.Errorf("stack '%s' already exists in %s", targetRef, target.URL())
+				}
+			}
+			// A stack migrated under a new name gets a new configuration file, which must not clobber another stack's.
+			targetConfigPath := configPath
+			if err == nil && targetRef.Name() != source.Ref().Name() && stackConfigFile == "" {
+				if targetConfigPath, err = workspace.DetectProjectStackPath(targetRef.Name()); err == nil {
+					if _, statErr := os.Stat(targetConfigPath); statErr == nil {
+						err = errors.Errorf("configuration file %s already exists", targetConfigPath)
+					}
+				}
+			}
+			if err != nil {
+				contract.IgnoreError(restoreCurrentBackend(creds.Current))
+				return result.FromError(err)
+			}
+
+			// Ensure the user really wants to do this.
+			if !yes && !cmdutil.Interactive() {
+				contract.IgnoreError(restoreCurrentBackend(creds.Current))
+				return result.FromError(errYesRequired("migrating a stack"))
+			}
+			prompt := fmt.Sprintf("This will migrate %d resources and %d configuration values of stack '%s' to %s.",
+				len(snap.Resources), len(ps.Config), source.Ref(), target.URL())
+			if removeSource {
+				prompt += fmt.Sprintf("\nThe stack will then be removed from %s, along with its %d updates of history.",
+					source.Backend().URL(), len(history))
+			}
+			if !yes && !confirmPrompt(prompt, source.Ref().String(), opts) {
+				contract.IgnoreError(restoreCurrentBackend(creds.Current))
+				fmt.Println("confirmation declined")
+				return result.Bail()
+			}
+
+			// The migrated stack's configuration is written to a file of its own until the migration has been
+			// verified, as it would otherwise replace the source stack's configuration when both have the same name.
+			stagingPath := migrationStagingPath(targetConfigPath)
+			migrated, err := migrateStack(
+				target, targetRef, stagingPath, secretsProvider, ps, plaintextConfig, snap, deployment)
+			var backupPath string
+			if err == nil {
+				backupPath, err = installMigratedConfig(stagingPath, targetConfigPath, configPath, !removeSource)
+			}
+			if err != nil {
+				// Leave things as they were: the source stack and its configuration are untouched, so only the new
+				// stack, its configuration file and the current backend need putting back.
+				if migrated != nil {
+					if _, rmErr := migrated.Remove(ctx, true /*force*/); rmErr != nil {
+						fmt.Fprintf(os.Stderr, "warning: could not remove the partially migrated stack '%s': %v\n",
+							migrated.Ref(), rmErr)
+					}
+				}
+				contract.IgnoreError(os.Remove(stagingPath))
+				contract.IgnoreError(restoreCurrentBackend(creds.Current))
+				return result.FromError(errors.Wrap(err, "migration failed and was rolled back"))
+			}
+
+			if err = state.SetCurrentStack(migrated.Ref().String()); err != nil {
+				return result.FromError(err)
+			}
+			fmt.Printf("Migrated stack '%s' to %s as '%s'.\n", source.Ref(), target.URL(), migrated.Ref())
+			if backupPath != "" {
+				fmt.Printf("The original stack's configuration was saved to %s.\n", backupPath)
+			}
+
+			if !removeSource {
+				if len(history) > 0 {
+					fmt.Printf("Its %d updates of history remain with the original stack in %s.\n",
+						len(history), source.Backend().URL())
+				}
+				return nil
+			}
+			if _, err = source.Remove(ctx, true /*force*/); err != nil {
+				return result.FromError(errors.Wrapf(err, "removing the original stack '%s'", source.Ref()))
+			}
+			fmt.Printf("Removed the original stack from %s.\n", source.Backend().URL())
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to migrate. Defaults to the current stack")
+	cmd.PersistentFlags().StringVar(
+		&to, "to", "", "The URL of the backend to migrate the stack to")
+	cmd.PersistentFlags().StringVar(
+		&targetStackName, "target-stack", "",
+		"The name of the stack in the new backend, e.g. to place it in an organization. Defaults to the current name")
+	cmd.PersistentFlags().StringVar(
+		&secretsProvider, "secrets-provider", "default", "The type of the provider that should be used to encrypt and "+
+			"decrypt the migrated stack's secrets (possible choices: default, passphrase, awskms, azurekeyvault, "+
+			"gcpkms, hashivault, vault)")
+	cmd.PersistentFlags().BoolVar(
+		&removeSource, "remove-source", false,
+		"Remove the original stack, without destroying its resources, once the migration has been verified")
+	cmd.PersistentFlags().BoolVarP(
+		&yes, "yes", "y", false,
+		"Skip confirmation prompts, and proceed with the migration anyway")
+
+	return cmd
+}
+
+// migrationStagingPath returns the path at which a migrated stack's configuration is written until the migration has
+// been verified. It keeps the file's extension, which determines its format.
+func migrationStagingPath(configPath string) string {
+	ext := filepath.Ext(configPath)
+	return strings.TrimSuffix(configPath, ext) + ".migrating" + ext
+}
+
+// installMigratedConfig moves the verified configuration of a migrated stack from the given staging path to the stack's
+// configuration file. If that file is the source stack's own configuration and the source stack is kept, the source's
+// configuration is first moved aside, so that the source stack can still be used; the path it was moved to is returned.
+func installMigratedConfig(stagingPath, targetConfigPath, sourceConfigPath string, keepSource bool) (string, error) {
+	var backupPath string
+	if keepSource && targetConfigPath == sourceConfigPath {
+		if _, err := os.Stat(sourceConfigPath); err == nil {
+			backupPath = sourceConfigPath + ".bak"
+			if err = os.Rename(sourceConfigPath, backupPath); err != nil {
+				return "", errors.Wrap(err, "saving the original stack's configuration")
+			}
+		}
+	}
+	if err := os.Rename(stagingPath, targetConfigPath); err != nil {
+		if backupPath != "" {
+			contract.IgnoreError(os.Rename(backupPath, sourceConfigPath))
+		}
+		return "", errors.Wrap(err, "saving the migrated stack's configuration")
+	}
+	return backupPath, nil
+}
+
+// migrateStack creates a stack in the target backend with the given configuration and state, re-encrypting their
+// secrets, and checks that it matches its source. The new stack's configuration is written to the given path. The
+// stack is returned if it was created, even if the migration then failed, so that it can be removed.
+func migrateStack(target backend.Backend, targetRef backend.StackReference, configPath, secretsProvider string,
+	ps *workspace.ProjectStack, plaintextConfig config.Map, snap *deploy.Snapshot,
+	deployment *apitype.UntypedDeployment) (backend.Stack, error) {
+
+	// Every read and write of the new stack's configuration, including by its secrets manager, goes to configPath.
+	defer func(old string) { stackConfigFile = old }(stackConfigFile)
+	stackConfigFile = configPath
+
+	// The new stack's secrets provider is configured in its configuration file, so start that file afresh.
+	ps.SecretsProvider, ps.EncryptedKey, ps.EncryptionSalt = "", "", ""
+	ps.Config = make(config.Map)
+	ps.SecretMetadata = nil
+	if err := ps.Save(configPath); err != nil {
+		return nil, err
+	}
+
+	migrated, err := createStack(target, targetRef, nil, false /*setCurrent*/, secretsProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	return migrated, reencryptStack(migrated, plaintextConfig, snap, deployment)
+}
+
+// reencryptStack saves the given configuration and state to the given stack, encrypting their secrets with the stack's
+// secrets manager, and checks that the stack's state matches the given original deployment and that the configuration
+// can be decrypted again.
+func reencryptStack(s backend.Stack, plaintextConfig config.Map, snap *deploy.Snapshot,
+	deployment *apitype.UntypedDeployment) error {
+
+	sm, err := getStackSecretsManager(s)
+	if err != nil {
+		return err
+	}
+	enc, err := sm.Encrypter()
+	if err != nil {
+		return err
+	}
+	ps, err := loadProjectStack(s)
+	if err != nil {
+		return err
+	}
+	if ps.Config, err = copyStackConfig(plaintextConfig, config.NopDecrypter, enc); err != nil {
+		return err
+	}
+	if err = saveProjectStack(s, ps); err != nil {
+		return errors.Wrap(err, "saving the re-encrypted configuration")
+	}
+
+	sdp, err := stack.SerializeDeployment(snap, sm)
+	if err != nil {
+		return errors.Wrap(err, "re-encrypting the stack's state")
+	}
+	bytes, err := json.Marshal(sdp)
+	if err != nil {
+		return err
+	}
+	ctx := commandContext()
+	if err = s.ImportDeployment(ctx, &apitype.UntypedDeployment{
+		Version:    apitype.DeploymentSchemaVersionCurrent,
+		Deployment: bytes,
+	}); err != nil {
+		return errors.Wrap(err, "importing the stack's state")
+	}
+
+	// Check that the state arrived intact, and that the configuration can be decrypted again.
+	imported, err := s.ExportDeployment(ctx)
+	if err != nil {
+		return errors.Wrap(err, "exporting the re-encrypted state")
+	}
+	if err = verifyMigratedDeployment(deployment, imported); err != nil {
+		return err
+	}
+	dec, err := sm.Decrypter()
+	if err != nil {
+		return err
+	}
+	for k, v := range ps.Config {
+		plaintext, decErr := v.Value(dec)
+		if decErr != nil {
+			return errors.Wrapf(decErr, "decrypting the re-encrypted value of %s", k)
+		}
+		if expected, _ := plaintextConfig[k].Value(config.NopDecrypter); plaintext != expected {
+			return errors.Errorf("the re-encrypted value of %s does not match the original", k)
+		}
+	}
+	return nil
+}
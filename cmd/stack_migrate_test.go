@@ -0,0 +1,204 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend/filestate"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestVerifyMigratedDeployment(t *testing.T) {
+	deployment := func(resources ...apitype.ResourceV3) *apitype.UntypedDeployment {
+		b, err := json.Marshal(apitype.DeploymentV3{Resources: resources})
+		assert.NoError(t, err)
+		return &apitype.UntypedDeployment{Version: 3, Deployment: b}
+	}
+	bucket := apitype.ResourceV3{URN: "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::b", ID: "b-1234", Custom: true}
+	table := apitype.ResourceV3{URN: "urn:pulumi:dev::proj::aws:dynamodb/table:Table::t", ID: "t-5678", Custom: true}
+
+	// Re-encrypted properties do not matter, only which resources were migrated.
+	migratedBucket := bucket
+	migratedBucket.Outputs = map[string]interface{}{"secret": map[string]interface{}{
+		resource.SigKey: resource.SecretSig, "ciphertext": "v2:abcd",
+	}}
+	assert.NoError(t, verifyMigratedDeployment(deployment(bucket, table), deployment(migratedBucket, table)))
+
+	assert.EqualError(t, verifyMigratedDeployment(deployment(bucket, table), deployment(bucket)),
+		"the migrated deployment has 1 resources, but the source has 2")
+
+	changed := table
+	changed.ID = "t-0000"
+	assert.EqualError(t, verifyMigratedDeployment(deployment(bucket, table), deployment(bucket, changed)),
+		`resource urn:pulumi:dev::proj::aws:dynamodb/table:Table::t (ID "t-5678") was migrated as `+
+			`urn:pulumi:dev::proj::aws:dynamodb/table:Table::t (ID "t-0000")`)
+}
+
+func TestMigrateStackWithSameName(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migrate")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	defer func(old string) { stackConfigFile = old }(stackConfigFile)
+	configPath := filepath.Join(dir, "Pulumi.dev.yaml")
+	stackConfigFile = configPath
+	defer func(old string, had bool) {
+		if had {
+			os.Setenv("PULUMI_CONFIG_PASSPHRASE", old)
+		} else {
+			os.Unsetenv("PULUMI_CONFIG_PASSPHRASE")
+		}
+	}(os.LookupEnv("PULUMI_CONFIG_PASSPHRASE"))
+	assert.NoError(t, os.Setenv("PULUMI_CONFIG_PASSPHRASE", "password"))
+
+	for _, name := range []string{"from", "to"} {
+		assert.NoError(t, os.Mkdir(filepath.Join(dir, name), 0700))
+	}
+
+	sink := diag.DefaultSink(ioutil.Discard, ioutil.Discard, diag.FormatOptions{Color: colors.Never})
+	from, err := filestate.New(sink, filestate.FilePathPrefix+filepath.ToSlash(filepath.Join(dir, "from")))
+	if !assert.NoError(t, err) {
+		return
+	}
+	to, err := filestate.New(sink, filestate.FilePathPrefix+filepath.ToSlash(filepath.Join(dir, "to")))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Give the source stack a secret in both its configuration and its state.
+	ref, err := from.ParseStackReference("dev")
+	if !assert.NoError(t, err) {
+		return
+	}
+	source, err := createStack(from, ref, nil, false /*setCurrent*/, "passphrase")
+	if !assert.NoError(t, err) {
+		return
+	}
+	sm, err := getStackSecretsManager(source)
+	if !assert.NoError(t, err) {
+		return
+	}
+	enc, err := sm.Encrypter()
+	if !assert.NoError(t, err) {
+		return
+	}
+	key := config.MustMakeKey("test", "secret")
+	plaintextConfig := config.Map{key: config.NewSecureValue("hush")}
+	ps, err := loadProjectStack(source)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if ps.Config, err = copyStackConfig(plaintextConfig, config.NopDecrypter, enc); !assert.NoError(t, err) {
+		return
+	}
+	if !assert.NoError(t, saveProjectStack(source, ps)) {
+		return
+	}
+	urn := resource.NewURN("dev", "test", "", "test:index:Resource", "a")
+	snap := deploy.NewSnapshot(deploy.Manifest{}, nil, []*resource.State{{
+		URN:     urn,
+		Type:    urn.Type(),
+		Custom:  true,
+		ID:      "a",
+		Outputs: resource.PropertyMap{"secret": resource.MakeSecret(resource.NewStringProperty("hush"))},
+	}}, nil)
+	sdp, err := stack.SerializeDeployment(snap, sm)
+	if !assert.NoError(t, err) {
+		return
+	}
+	bytes, err := json.Marshal(sdp)
+	if !assert.NoError(t, err) {
+		return
+	}
+	deployment := &apitype.UntypedDeployment{Version: apitype.DeploymentSchemaVersionCurrent, Deployment: bytes}
+	if !assert.NoError(t, source.ImportDeployment(commandContext(), deployment)) {
+		return
+	}
+	original, err := ioutil.ReadFile(configPath)
+	if !assert.NoError(t, err) {
+		return
+	}
+	load := func() *workspace.ProjectStack {
+		ps, err := workspace.LoadProjectStack(configPath)
+		assert.NoError(t, err)
+		return ps
+	}
+
+	// A migration that fails verification leaves the source stack's configuration untouched.
+	stagingPath := migrationStagingPath(configPath)
+	mismatched := &apitype.UntypedDeployment{Version: apitype.DeploymentSchemaVersionCurrent, Deployment: []byte("{}")}
+	migrated, err := migrateStack(to, ref, stagingPath, "passphrase", load(), plaintextConfig, snap, mismatched)
+	assert.Error(t, err)
+	if assert.NotNil(t, migrated) {
+		_, err = migrated.Remove(commandContext(), true /*force*/)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, os.Remove(stagingPath))
+	current, err := ioutil.ReadFile(configPath)
+	assert.NoError(t, err)
+	assert.Equal(t, string(original), string(current))
+
+	// A verified migration is only installed over the source stack's configuration afterwards, and the source's
+	// configuration is kept alongside it.
+	migrated, err = migrateStack(to, ref, stagingPath, "passphrase", load(), plaintextConfig, snap, deployment)
+	if !assert.NoError(t, err) {
+		return
+	}
+	current, err = ioutil.ReadFile(configPath)
+	assert.NoError(t, err)
+	assert.Equal(t, string(original), string(current))
+
+	backupPath, err := installMigratedConfig(stagingPath, configPath, configPath, true /*keepSource*/)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, configPath+".bak", backupPath)
+	backup, err := ioutil.ReadFile(backupPath)
+	assert.NoError(t, err)
+	assert.Equal(t, string(original), string(backup))
+	_, err = os.Stat(stagingPath)
+	assert.True(t, os.IsNotExist(err))
+
+	// The installed configuration belongs to the migrated stack, whose secrets manager can decrypt it.
+	sm, err = getStackSecretsManager(migrated)
+	if !assert.NoError(t, err) {
+		return
+	}
+	dec, err := sm.Decrypter()
+	if !assert.NoError(t, err) {
+		return
+	}
+	installed := load()
+	assert.NotEqual(t, ps.EncryptionSalt, installed.EncryptionSalt)
+	value, err := installed.Config[key].Value(dec)
+	assert.NoError(t, err)
+	assert.Equal(t, "hush", value)
+}
@@ -16,10 +16,13 @@ package cmd
 
 import (
 	"fmt"
+	"net/http"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/backend/display"
 	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/resource/deploy"
@@ -29,7 +32,7 @@ import (
 
 func newStackOutputCmd() *cobra.Command {
 	var jsonOut bool
-	var showSecrets bool
+	var showSecrets showSecretsFlag
 	var stackName string
 
 	cmd := &cobra.Command{
@@ -39,22 +42,34 @@ func newStackOutputCmd() *cobra.Command {
 		Long: "Show a stack's output properties.\n" +
 			"\n" +
 			"By default, this command lists all output properties exported from a stack.\n" +
-			"If a specific property-name is supplied, just that property's value is shown.",
+			"If a specific property-name is supplied, just that property's value is shown.\n" +
+			"\n" +
+			"The outputs of a stack in another project or organization can be read by naming it with\n" +
+			"`--stack <org>/<project>/<stack>`, provided that you have access to it, so that scripts can\n" +
+			"consume them without a Pulumi program or project:\n" +
+			"* `VPC_ID=$(pulumi stack output --stack acmecorp/network/prod vpcId)`\n" +
+			"\n" +
+			"Pass `--show-secrets` to display secret outputs in plaintext, or `--show-secrets=outputs.<name>`\n" +
+			"to display only the named output. Revealing secrets is recorded in the stack's audit log, if the\n" +
+			"backend keeps one.",
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			opts := display.Options{
 				Color: cmdutil.GetGlobalColorization(),
 			}
 
-			// Fetch the current stack and its output properties.
-			s, err := requireStack(stackName, false, opts, true /*setCurrent*/)
+			// Fetch the stack and its output properties.
+			s, err := requireOutputStack(stackName, opts)
 			if err != nil {
 				return err
 			}
 			snap, err := s.Snapshot(commandContext())
 			if err != nil {
-				return err
+				return stackAccessError(s.Ref().String(), err)
 			}
 
+			if err = showSecrets.record(s, "stack output", 0); err != nil {
+				return err
+			}
 			outputs, err := getStackOutputs(snap, showSecrets)
 			if err != nil {
 				return errors.Wrap(err, "getting outputs")
@@ -76,7 +91,7 @@ func newStackOutputCmd() *cobra.Command {
 						fmt.Printf("%v\n", stringifyOutput(v))
 					}
 				} else {
-					return errors.Errorf("current stack does not have output property '%v'", name)
+					return errors.Errorf("stack '%s' does not have output property '%v'", s.Ref(), name)
 				}
 			} else if jsonOut {
 				if err := printJSON(outputs); err != nil {
@@ -93,13 +108,48 @@ func newStackOutputCmd() *cobra.Command {
 		&jsonOut, "json", "j", false, "Emit output as JSON")
 	cmd.PersistentFlags().StringVarP(
 		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
-	cmd.PersistentFlags().BoolVar(
-		&showSecrets, "show-secrets", false, "Display outputs which are marked as secret in plaintext")
+	addShowSecretsFlag(cmd, &showSecrets, "Display outputs which are marked as secret in plaintext")
 
 	return cmd
 }
 
-func getStackOutputs(snap *deploy.Snapshot, showSecrets bool) (map[string]interface{}, error) {
+// requireOutputStack returns the stack whose outputs to show, which is the current stack if no name is given. The stack
+// may belong to another project or organization; whether the user may read it is up to the backend.
+func requireOutputStack(stackName string, opts display.Options) (backend.Stack, error) {
+	if stackName == "" {
+		return requireStack(stackName, false, opts, true /*setCurrent*/)
+	}
+
+	b, err := currentBackend(opts)
+	if err != nil {
+		return nil, err
+	}
+	stackRef, err := b.ParseStackReference(stackName)
+	if err != nil {
+		return nil, err
+	}
+	s, err := b.GetStack(commandContext(), stackRef)
+	if err != nil {
+		return nil, stackAccessError(stackName, err)
+	}
+	if s == nil {
+		// The Pulumi service doesn't reveal whether a stack that the user can't read exists.
+		return nil, errors.Errorf("no stack named '%s' found, or you do not have access to it", stackName)
+	}
+	return s, nil
+}
+
+// stackAccessError explains an error reading the named stack that was caused by the user not having access to it.
+// Other errors are returned unchanged.
+func stackAccessError(stackName string, err error) error {
+	if errResp, ok := err.(*apitype.ErrorResponse); ok &&
+		(errResp.Code == http.StatusForbidden || errResp.Code == http.StatusUnauthorized) {
+		return errors.Errorf("you do not have access to stack '%s': %s", stackName, errResp.Message)
+	}
+	return err
+}
+
+func getStackOutputs(snap *deploy.Snapshot, showSecrets showSecretsFlag) (map[string]interface{}, error) {
 	state, err := stack.GetRootStackResource(snap)
 	if err != nil {
 		return nil, err
@@ -109,8 +159,13 @@ func getStackOutputs(snap *deploy.Snapshot, showSecrets bool) (map[string]interf
 		return map[string]interface{}{}, nil
 	}
 
-	// massageSecrets will remove all the secrets from the property map, so it should be safe to pass a panic
+	outputs, err := showSecrets.revealStackOutputs(state)
+	if err != nil {
+		return nil, err
+	}
+
+	// revealStackOutputs will remove all the secrets from the property map, so it should be safe to pass a panic
 	// crypter. This also ensure that if for some reason we didn't remove everything, we don't accidentally disclose
 	// secret values!
-	return stack.SerializeProperties(display.MassageSecrets(state.Outputs, showSecrets), config.NewPanicCrypter())
+	return stack.SerializeProperties(outputs, config.NewPanicCrypter())
 }
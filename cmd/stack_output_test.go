@@ -15,9 +15,13 @@
 package cmd
 
 import (
+	"net/http"
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
 )
 
 func TestStringifyOutput(t *testing.T) {
@@ -36,3 +40,15 @@ func TestStringifyOutput(t *testing.T) {
 	assert.Equal(t, "[\"hello\",\"goodbye\"]", stringifyOutput(arr))
 	assert.Equal(t, "{\"bar\":{\"baz\":true},\"foo\":42}", stringifyOutput(obj))
 }
+
+func TestStackAccessError(t *testing.T) {
+	forbidden := &apitype.ErrorResponse{Code: http.StatusForbidden, Message: "not a member of acmecorp"}
+	assert.EqualError(t, stackAccessError("acmecorp/network/prod", forbidden),
+		"you do not have access to stack 'acmecorp/network/prod': not a member of acmecorp")
+
+	// Other errors are returned as-is.
+	other := errors.New("connection refused")
+	assert.Equal(t, other, stackAccessError("acmecorp/network/prod", other))
+	notFound := &apitype.ErrorResponse{Code: http.StatusNotFound}
+	assert.Equal(t, notFound, stackAccessError("acmecorp/network/prod", notFound))
+}
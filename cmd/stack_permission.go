@@ -0,0 +1,366 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/backend/httpstate"
+	"github.com/pulumi/pulumi/pkg/backend/httpstate/client"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/result"
+)
+
+// stackPermissionNames maps the names used on the command line to stack permissions.
+var stackPermissionNames = map[string]apitype.StackPermission{
+	"none":  apitype.StackPermissionNone,
+	"read":  apitype.StackPermissionRead,
+	"write": apitype.StackPermissionWrite,
+	"admin": apitype.StackPermissionAdmin,
+}
+
+// parseStackPermission parses the command line name of a stack permission.
+func parseStackPermission(s string) (apitype.StackPermission, error) {
+	if p, ok := stackPermissionNames[s]; ok {
+		return p, nil
+	}
+	return 0, errors.Errorf("unknown permission '%s'; must be one of none, read, write, or admin", s)
+}
+
+// formatStackPermission returns the command line name of a stack permission.
+func formatStackPermission(p apitype.StackPermission) string {
+	for name, perm := range stackPermissionNames {
+		if perm == p {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+// collaboratorKind returns the kind of collaborator being operated upon.
+func collaboratorKind(team bool) apitype.StackCollaboratorKind {
+	if team {
+		return apitype.StackCollaboratorTeam
+	}
+	return apitype.StackCollaboratorUser
+}
+
+// requireServiceStackClient returns the service client and identifier for the given stack, which must be managed by
+// the Pulumi service. The feature is named in the error returned for other stacks.
+func requireServiceStackClient(stackName, feature string) (*client.Client, client.StackIdentifier, error) {
+	opts := display.Options{
+		Color: cmdutil.GetGlobalColorization(),
+	}
+	s, err := requireStack(stackName, false, opts, true /*setCurrent*/)
+	if err != nil {
+		return nil, client.StackIdentifier{}, err
+	}
+
+	cs, ok := s.(httpstate.Stack)
+	if !ok {
+		return nil, client.StackIdentifier{}, errors.Errorf(
+			"%s are only supported for stacks managed by the Pulumi service", feature)
+	}
+	return cs.Backend().(httpstate.Backend).Client(), cs.StackIdentifier(), nil
+}
+
+func newStackPermissionCmd() *cobra.Command {
+	var stack string
+
+	cmd := &cobra.Command{
+		Use:   "permission",
+		Short: "Manage stack permissions",
+		Long: "Manage stack permissions\n" +
+			"\n" +
+			"Users and teams in a stack's organization can be granted read, write, or admin access to\n" +
+			"the stack. The `ls`, `grant`, and `revoke` commands can be used to manage these grants, and\n" +
+			"the `set` command sets one of them, or with `--replace`, replaces all of them at once.\n" +
+			"Stack permissions are only supported for stacks managed by the Pulumi service.",
+		Args: cmdutil.NoArgs,
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+
+	cmd.AddCommand(newStackPermissionLsCmd(&stack))
+	cmd.AddCommand(newStackPermissionGrantCmd(&stack))
+	cmd.AddCommand(newStackPermissionRevokeCmd(&stack))
+	cmd.AddCommand(newStackPermissionSetCmd(&stack))
+
+	return cmd
+}
+
+func newStackPermissionLsCmd(stack *string) *cobra.Command {
+	var jsonOut bool
+	cmd := &cobra.Command{
+		Use:   "ls",
+		Short: "List the users and teams with access to a stack",
+		Args:  cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			pc, stackID, err := requireServiceStackClient(*stack, "stack permissions")
+			if err != nil {
+				return err
+			}
+
+			collaborators, err := pc.ListStackPermissions(commandContext(), stackID)
+			if err != nil {
+				return err
+			}
+
+			if jsonOut {
+				return printJSON(collaborators)
+			}
+
+			sort.Slice(collaborators, func(i, j int) bool {
+				if collaborators[i].Kind != collaborators[j].Kind {
+					return collaborators[i].Kind < collaborators[j].Kind
+				}
+				return collaborators[i].Name < collaborators[j].Name
+			})
+
+			rows := []cmdutil.TableRow{}
+			for _, c := range collaborators {
+				rows = append(rows, cmdutil.TableRow{
+					Columns: []string{c.Name, string(c.Kind), formatStackPermission(c.Permission)},
+				})
+			}
+			cmdutil.PrintTable(cmdutil.Table{
+				Headers: []string{"NAME", "KIND", "PERMISSION"},
+				Rows:    rows,
+			})
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit output as JSON")
+
+	return cmd
+}
+
+func newStackPermissionGrantCmd(stack *string) *cobra.Command {
+	var team bool
+	cmd := &cobra.Command{
+		Use:   "grant <name> <permission>",
+		Short: "Grant a user or team access to a stack",
+		Long: "Grant a user or team access to a stack\n" +
+			"\n" +
+			"The permission must be one of `read`, `write`, or `admin`, and replaces any permission\n" +
+			"previously granted to the user or team.",
+		Args: cmdutil.SpecificArgs([]string{"name", "permission"}),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			permission, err := parseStackPermission(args[1])
+			if err != nil {
+				return err
+			}
+
+			pc, stackID, err := requireServiceStackClient(*stack, "stack permissions")
+			if err != nil {
+				return err
+			}
+
+			return pc.GrantStackPermission(commandContext(), stackID, collaboratorKind(team), args[0], permission)
+		}),
+	}
+
+	cmd.PersistentFlags().BoolVar(
+		&team, "team", false, "Grant access to the team with the given name, rather than a user")
+
+	return cmd
+}
+
+func newStackPermissionRevokeCmd(stack *string) *cobra.Command {
+	var team bool
+	cmd := &cobra.Command{
+		Use:   "revoke <name>",
+		Short: "Revoke a user's or team's access to a stack",
+		Args:  cmdutil.SpecificArgs([]string{"name"}),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			pc, stackID, err := requireServiceStackClient(*stack, "stack permissions")
+			if err != nil {
+				return err
+			}
+
+			return pc.RevokeStackPermission(commandContext(), stackID, collaboratorKind(team), args[0])
+		}),
+	}
+
+	cmd.PersistentFlags().BoolVar(
+		&team, "team", false, "Revoke access from the team with the given name, rather than a user")
+
+	return cmd
+}
+
+// stackPermissionsFile is the format of the file read by `pulumi stack permission set`, which maps the names of users
+// and teams to the command line names of their permissions.
+type stackPermissionsFile struct {
+	Users map[string]string `json:"users,omitempty"`
+	Teams map[string]string `json:"teams,omitempty"`
+}
+
+// parseStackPermissionsFile parses the contents of a file read by `pulumi stack permission set`.
+func parseStackPermissionsFile(b []byte) (apitype.StackPermissions, error) {
+	var file stackPermissionsFile
+	if err := json.Unmarshal(b, &file); err != nil {
+		return apitype.StackPermissions{}, errors.Wrap(err, "could not parse permissions")
+	}
+
+	parse := func(names map[string]string) (map[string]apitype.StackPermission, error) {
+		perms := make(map[string]apitype.StackPermission)
+		for name, s := range names {
+			p, err := parseStackPermission(s)
+			if err != nil {
+				return nil, errors.Wrapf(err, "permission for '%s'", name)
+			}
+			perms[name] = p
+		}
+		return perms, nil
+	}
+
+	users, err := parse(file.Users)
+	if err != nil {
+		return apitype.StackPermissions{}, err
+	}
+	teams, err := parse(file.Teams)
+	if err != nil {
+		return apitype.StackPermissions{}, err
+	}
+	return apitype.StackPermissions{Users: users, Teams: teams}, nil
+}
+
+// describeStackPermissionChange describes a change made by `pulumi stack permission set`.
+func describeStackPermissionChange(c apitype.StackCollaborator) string {
+	if c.Permission == apitype.StackPermissionNone {
+		return fmt.Sprintf("access from %s %s", c.Kind, c.Name)
+	}
+	return fmt.Sprintf("%s access to %s %s", formatStackPermission(c.Permission), c.Kind, c.Name)
+}
+
+func newStackPermissionSetCmd(stack *string) *cobra.Command {
+	var team bool
+	var replace string
+	var yes bool
+	cmd := &cobra.Command{
+		Use:   "set [<name> <permission>]",
+		Short: "Set the access of a user or team to a stack",
+		Long: "Set the access of a user or team to a stack\n" +
+			"\n" +
+			"The permission must be one of `none`, `read`, `write`, or `admin`. The permission of the\n" +
+			"named user or team is changed only if it differs, and no other grant is affected.\n" +
+			"\n" +
+			"To replace all of the stack's grants at once, pass `--replace` with a JSON file that maps\n" +
+			"the names of users and teams to their permissions instead, for example:\n" +
+			"\n" +
+			"    {\"users\": {\"alice\": \"admin\"}, \"teams\": {\"ops\": \"write\"}}\n" +
+			"\n" +
+			"Every user and team not named in the file, possibly including you, then has its access\n" +
+			"revoked, so the changes must be confirmed.",
+		Args: cmdutil.MaximumNArgs(2),
+		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
+			var perms apitype.StackPermissions
+			switch {
+			case replace != "" && len(args) != 0:
+				return result.Errorf("a name and permission cannot be given along with --replace")
+			case replace != "":
+				b, err := ioutil.ReadFile(replace)
+				if err != nil {
+					return result.FromError(err)
+				}
+				if perms, err = parseStackPermissionsFile(b); err != nil {
+					return result.FromError(errors.Wrapf(err, "reading %s", replace))
+				}
+			case len(args) != 2:
+				return result.Errorf("a name and permission are required, unless --replace is given")
+			default:
+				permission, err := parseStackPermission(args[1])
+				if err != nil {
+					return result.FromError(err)
+				}
+				grant := map[string]apitype.StackPermission{args[0]: permission}
+				if team {
+					perms.Teams = grant
+				} else {
+					perms.Users = grant
+				}
+			}
+
+			pc, stackID, err := requireServiceStackClient(*stack, "stack permissions")
+			if err != nil {
+				return result.FromError(err)
+			}
+
+			changes, err := pc.DiffStackPermissions(commandContext(), stackID, perms, replace != "")
+			if err != nil {
+				return result.FromError(err)
+			}
+			if len(changes) == 0 {
+				fmt.Println("Stack permissions are already up to date")
+				return nil
+			}
+
+			if replace != "" {
+				if !yes && !cmdutil.Interactive() {
+					return result.FromError(errYesRequired("replacing stack permissions"))
+				}
+				prompt := "This will change the following stack permissions:\n"
+				for _, c := range changes {
+					verb := "grant"
+					if c.Permission == apitype.StackPermissionNone {
+						verb = "revoke"
+					}
+					prompt += fmt.Sprintf("    %s %s\n", verb, describeStackPermissionChange(c))
+				}
+				opts := display.Options{
+					Color: cmdutil.GetGlobalColorization(),
+				}
+				if !yes && !confirmPrompt(prompt, stackID.Stack, opts) {
+					fmt.Println("confirmation declined")
+					return result.Bail()
+				}
+			}
+
+			changes, err = pc.ApplyStackPermissionChanges(commandContext(), stackID, changes)
+			for _, c := range changes {
+				if c.Permission == apitype.StackPermissionNone {
+					fmt.Printf("Revoked %s\n", describeStackPermissionChange(c))
+				} else {
+					fmt.Printf("Granted %s\n", describeStackPermissionChange(c))
+				}
+			}
+			if err != nil {
+				return result.FromError(err)
+			}
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().BoolVar(
+		&team, "team", false, "Set the access of the team with the given name, rather than a user")
+	cmd.PersistentFlags().StringVar(
+		&replace, "replace", "", "Replace all of the stack's grants with those in the given JSON file")
+	cmd.PersistentFlags().BoolVarP(
+		&yes, "yes", "y", false,
+		"Skip confirmation prompts, and proceed with replacement anyway")
+
+	return cmd
+}
@@ -0,0 +1,42 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+)
+
+func TestParseStackPermissionsFile(t *testing.T) {
+	perms, err := parseStackPermissionsFile([]byte(`{"users":{"alice":"admin","bob":"none"},"teams":{"ops":"write"}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, apitype.StackPermissions{
+		Users: map[string]apitype.StackPermission{
+			"alice": apitype.StackPermissionAdmin,
+			"bob":   apitype.StackPermissionNone,
+		},
+		Teams: map[string]apitype.StackPermission{"ops": apitype.StackPermissionWrite},
+	}, perms)
+
+	_, err = parseStackPermissionsFile([]byte(`{"teams":{"ops":"owner"}}`))
+	assert.EqualError(t, err,
+		"permission for 'ops': unknown permission 'owner'; must be one of none, read, write, or admin")
+
+	_, err = parseStackPermissionsFile([]byte(`["alice"]`))
+	assert.Error(t, err)
+}
@@ -61,6 +61,8 @@ func newStackRenameCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			// Keep the stack's file in the directory it is in, which may be nested in the project's config directory.
+			newConfigPath = filepath.Join(filepath.Dir(oldConfigPath), filepath.Base(newConfigPath))
 
 			if err := s.Rename(commandContext(), tokens.QName(args[0])); err != nil {
 				return err
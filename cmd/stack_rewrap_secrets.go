@@ -0,0 +1,229 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/secrets/service"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func newStackRewrapSecretsCmd() *cobra.Command {
+	var stackName string
+	cmd := &cobra.Command{
+		Use:   "rewrap-secrets",
+		Args:  cmdutil.NoArgs,
+		Short: "Re-encrypt a stack's escrowed secrets with the Pulumi service",
+		Long: "Re-encrypt a stack's escrowed secrets with the Pulumi service.\n" +
+			"\n" +
+			"While the Pulumi service is unavailable, secrets may be encrypted under the local escrow key in\n" +
+			"the " + service.EscrowKeyEnvVar + " environment variable, if the stack's organization allows it.\n" +
+			"Once the service is available again, this command re-encrypts every such secret in the stack's\n" +
+			"configuration and state with the service, and clears the journal of escrowed secrets. The escrow\n" +
+			"key must still be set.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			s, err := requireStack(stackName, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+			sm, err := getStackSecretsManager(s)
+			if err != nil {
+				return err
+			}
+			rewrapper, err := service.NewRewrapper(sm)
+			if err != nil {
+				return err
+			}
+			journal, err := rewrapper.Journal()
+			if err != nil {
+				return err
+			}
+			for _, entry := range journal {
+				fmt.Printf("Escrowed at %s: %s\n", entry.Time.Format("2006-01-02 15:04:05"),
+					describeValueLocation(entry.ValueLocation))
+			}
+
+			// Re-wrap the configuration first; the state is imported only if anything in it changed.
+			ps, err := loadProjectStack(s)
+			if err != nil {
+				return err
+			}
+			cfg, configCount, err := rewrapStackConfig(ps.Config, rewrapper.Rewrap)
+			if err != nil {
+				return err
+			}
+			if configCount > 0 {
+				ps.Config = cfg
+				if err = saveProjectStack(s, ps); err != nil {
+					return errors.Wrap(err, "saving stack config")
+				}
+			}
+
+			ctx := commandContext()
+			deployment, err := s.ExportDeployment(ctx)
+			if err != nil {
+				return errors.Wrap(err, "exporting the stack's state")
+			}
+			rewrapped, stateCount, err := rewrapDeploymentSecrets(deployment, rewrapper.Rewrap)
+			if err != nil {
+				return err
+			}
+			if stateCount > 0 {
+				if err = s.ImportDeployment(ctx, rewrapped); err != nil {
+					return errors.Wrap(err, "importing the stack's state")
+				}
+			}
+
+			if err = rewrapper.ClearJournal(); err != nil {
+				return errors.Wrap(err, "clearing the escrow journal")
+			}
+			fmt.Printf("Re-encrypted %d configuration values and %d secrets in the state of stack '%s' with the "+
+				"Pulumi service.\n", configCount, stateCount, s.Ref())
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+
+	return cmd
+}
+
+// describeValueLocation returns a description of where an escrowed value is stored, for display.
+func describeValueLocation(loc config.ValueLocation) string {
+	switch {
+	case loc.ConfigKey != "":
+		return fmt.Sprintf("configuration value %s", loc.ConfigKey)
+	case loc.Resource != "":
+		return fmt.Sprintf("property %s of resource %s", loc.Property, loc.Resource)
+	default:
+		return "unknown location"
+	}
+}
+
+// rewrapEncrypter is given the ciphertexts of secrets in place of their plaintexts, and re-wraps them.
+type rewrapEncrypter func(ciphertext string) (string, error)
+
+func (rewrap rewrapEncrypter) EncryptValue(ciphertext string) (string, error) {
+	return rewrap(ciphertext)
+}
+
+// rewrapStackConfig returns a copy of the given configuration with the ciphertext of each secret value re-wrapped by
+// the given function, along with the number of values that changed.
+func rewrapStackConfig(cfg config.Map, rewrap func(string) (string, error)) (config.Map, int, error) {
+	count := 0
+	counting := rewrapEncrypter(func(ciphertext string) (string, error) {
+		rewrapped, err := rewrap(ciphertext)
+		if err == nil && rewrapped != ciphertext {
+			count++
+		}
+		return rewrapped, err
+	})
+
+	copied, err := copyStackConfig(cfg, config.NopDecrypter, counting)
+	if err != nil {
+		return nil, 0, err
+	}
+	return copied, count, nil
+}
+
+// rewrapDeploymentSecrets returns a copy of the given deployment with the ciphertext of each secret in its resources'
+// inputs and outputs re-wrapped by the given function, along with the number of secrets that changed.
+func rewrapDeploymentSecrets(deployment *apitype.UntypedDeployment,
+	rewrap func(string) (string, error)) (*apitype.UntypedDeployment, int, error) {
+
+	d, err := stack.UnmarshalUntypedDeployment(deployment)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "reading the stack's state")
+	}
+
+	count := 0
+	var walk func(v interface{}) (interface{}, error)
+	walk = func(v interface{}) (interface{}, error) {
+		switch v := v.(type) {
+		case []interface{}:
+			for i, elem := range v {
+				w, err := walk(elem)
+				if err != nil {
+					return nil, err
+				}
+				v[i] = w
+			}
+		case map[string]interface{}:
+			if sig, ok := v[resource.SigKey].(string); ok && sig == resource.SecretSig {
+				ciphertext, ok := v["ciphertext"].(string)
+				if !ok {
+					return v, nil
+				}
+				rewrapped, err := rewrap(ciphertext)
+				if err != nil {
+					return nil, err
+				}
+				if rewrapped != ciphertext {
+					v["ciphertext"] = rewrapped
+					count++
+				}
+				return v, nil
+			}
+			for k, elem := range v {
+				w, err := walk(elem)
+				if err != nil {
+					return nil, err
+				}
+				v[k] = w
+			}
+		}
+		return v, nil
+	}
+	walkResource := func(res *apitype.ResourceV3) error {
+		for _, props := range []map[string]interface{}{res.Inputs, res.Outputs} {
+			if _, err := walk(props); err != nil {
+				return errors.Wrapf(err, "re-wrapping the secrets of resource '%s'", res.URN)
+			}
+		}
+		return nil
+	}
+	for i := range d.Resources {
+		if err = walkResource(&d.Resources[i]); err != nil {
+			return nil, 0, err
+		}
+	}
+	for i := range d.PendingOperations {
+		if err = walkResource(&d.PendingOperations[i].Resource); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	bytes, err := json.Marshal(d)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &apitype.UntypedDeployment{Version: apitype.DeploymentSchemaVersionCurrent, Deployment: bytes}, count, nil
+}
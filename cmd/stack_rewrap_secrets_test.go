@@ -0,0 +1,99 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+)
+
+// testRewrap re-wraps the "escrow:" ciphertexts it is given as "svc:" ciphertexts.
+func testRewrap(ciphertext string) (string, error) {
+	if !strings.HasPrefix(ciphertext, "escrow:") {
+		return ciphertext, nil
+	}
+	return "svc:" + strings.TrimPrefix(ciphertext, "escrow:"), nil
+}
+
+func TestRewrapStackConfig(t *testing.T) {
+	key := func(name string) config.Key { return config.MustMakeKey("proj", name) }
+	cfg := config.Map{
+		key("escrowed"): config.NewSecureValue("escrow:a"),
+		key("wrapped"):  config.NewSecureValue("svc:b"),
+		key("plain"):    config.NewValue("escrow:c"),
+	}
+
+	rewrapped, count, err := rewrapStackConfig(cfg, testRewrap)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 1, count)
+	assert.Equal(t, config.Map{
+		key("escrowed"): config.NewSecureValue("svc:a"),
+		key("wrapped"):  config.NewSecureValue("svc:b"),
+		key("plain"):    config.NewValue("escrow:c"),
+	}, rewrapped)
+}
+
+func TestRewrapDeploymentSecrets(t *testing.T) {
+	secret := func(ciphertext string) map[string]interface{} {
+		return map[string]interface{}{resource.SigKey: resource.SecretSig, "ciphertext": ciphertext}
+	}
+	res := apitype.ResourceV3{
+		URN:  "urn:pulumi:dev::proj::pkgA:m:typA::resA",
+		Type: "pkgA:m:typA",
+		Inputs: map[string]interface{}{
+			"password": secret("escrow:a"),
+			"list":     []interface{}{"escrow:plain", secret("escrow:b")},
+		},
+		Outputs: map[string]interface{}{
+			"password": secret("svc:c"),
+		},
+	}
+	bytes, err := json.Marshal(apitype.DeploymentV3{
+		Resources:         []apitype.ResourceV3{res},
+		PendingOperations: []apitype.OperationV2{{Resource: res, Type: apitype.OperationTypeUpdating}},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	rewrapped, count, err := rewrapDeploymentSecrets(&apitype.UntypedDeployment{
+		Version:    apitype.DeploymentSchemaVersionCurrent,
+		Deployment: bytes,
+	}, testRewrap)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 4, count)
+
+	d, err := stack.UnmarshalUntypedDeployment(rewrapped)
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, r := range []apitype.ResourceV3{d.Resources[0], d.PendingOperations[0].Resource} {
+		assert.Equal(t, secret("svc:a"), r.Inputs["password"])
+		assert.Equal(t, []interface{}{"escrow:plain", secret("svc:b")}, r.Inputs["list"])
+		assert.Equal(t, secret("svc:c"), r.Outputs["password"])
+	}
+}
@@ -64,6 +64,9 @@ func newStackRmCmd() *cobra.Command {
 			}
 
 			// Ensure the user really wants to do this.
+			if !yes && !cmdutil.Interactive() {
+				return result.FromError(errYesRequired("removing a stack"))
+			}
 			prompt := fmt.Sprintf("This will permanently remove the '%s' stack!", s.Ref())
 			if !yes && !confirmPrompt(prompt, s.Ref().String(), opts) {
 				fmt.Println("confirmation declined")
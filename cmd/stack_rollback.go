@@ -0,0 +1,287 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	survey "gopkg.in/AlecAivazis/survey.v1"
+	surveycore "gopkg.in/AlecAivazis/survey.v1/core"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/result"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func newStackRollbackCmd() *cobra.Command {
+	var breakFreeze string
+	var force bool
+	var message string
+	var stackName string
+	var update bool
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "rollback <version>",
+		Args:  cmdutil.ExactArgs(1),
+		Short: "Roll a stack's state back to the state left by a prior update",
+		Long: "Roll a stack's state back to the state left by a prior update.\n" +
+			"\n" +
+			"This command replaces the stack's current state with the state recorded at the end of the\n" +
+			"given update version (see `pulumi history`). Before doing so, it compares the two states:\n" +
+			"resources that exist today but not in the prior state would no longer be managed by the\n" +
+			"stack, so the rollback is refused unless `--force` is passed. Resources that have been\n" +
+			"deleted since are reported, since the restored state would refer to them.\n" +
+			"\n" +
+			"Rolling back only changes the stack's state. Pass `--update` to then run `pulumi up` with\n" +
+			"the program in the current directory, which should be the version of the program that\n" +
+			"produced the restored state, to converge the stack's resources with it.",
+		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
+			version, err := strconv.Atoi(args[0])
+			if err != nil || version <= 0 {
+				return result.Errorf("'%s' is not a valid update version", args[0])
+			}
+
+			opts := display.Options{
+				Color:         cmdutil.GetGlobalColorization(),
+				IsInteractive: cmdutil.Interactive(),
+				Type:          getDisplayType(false /*diffDisplay*/),
+				Verbosity:     displayVerbosity,
+			}
+
+			s, err := requireStack(stackName, false, opts, true /*setCurrent*/)
+			if err != nil {
+				return result.FromError(err)
+			}
+			b, ok := s.Backend().(backend.DeploymentHistoryBackend)
+			if !ok {
+				return result.Error("rolling back is not supported by this backend; " +
+					"use `pulumi stack export` and `pulumi stack import` instead")
+			}
+
+			// Load both the current state and the state to restore, so that they can be compared.
+			current, err := s.Snapshot(commandContext())
+			if err != nil {
+				return result.FromError(err)
+			}
+			deployment, err := b.ExportDeploymentVersion(commandContext(), s.Ref(), version)
+			if err != nil {
+				return result.FromError(errors.Wrapf(err, "loading the state of version %d", version))
+			}
+			prior, err := stack.DeserializeUntypedDeployment(deployment, stack.DefaultSecretsProvider)
+			if err != nil {
+				return result.FromError(errors.Wrapf(err, "could not deserialize the state of version %d", version))
+			}
+
+			untracked, deleted := compareRollbackStates(current, prior)
+			for _, urn := range deleted {
+				cmdutil.Diag().Warningf(diag.Message(urn,
+					"this resource was deleted after version %d; the restored state will still refer to it"), version)
+			}
+			for _, urn := range untracked {
+				cmdutil.Diag().Warningf(diag.Message(urn,
+					"this resource was created after version %d and will no longer be managed by the stack"), version)
+			}
+			if len(untracked) > 0 && !force {
+				return result.Errorf("rolling back would leave %d resources running without being managed by the "+
+					"stack; delete them first, or rerun with --force to proceed anyway", len(untracked))
+			}
+
+			// Check that the update can run before the state is changed.
+			var up *rollbackUpdate
+			if update {
+				if up, err = newRollbackUpdate(s, version, message, breakFreeze); err != nil {
+					return result.FromError(err)
+				}
+			}
+
+			if !yes {
+				if !cmdutil.Interactive() {
+					return result.FromError(errYesRequired("rolling back a stack"))
+				}
+				if !confirmRollback(opts, s.Ref().String(), version) {
+					fmt.Println("confirmation declined")
+					return result.Bail()
+				}
+			}
+
+			if err = s.ImportDeployment(commandContext(), deployment); err != nil {
+				return result.FromError(errors.Wrap(err, "could not import deployment"))
+			}
+			fmt.Printf("Stack %s rolled back to the state of version %d.\n", s.Ref(), version)
+			if len(deleted) > 0 && !update {
+				fmt.Println("Run `pulumi refresh` to remove resources that no longer exist from the stack's state.")
+			}
+
+			if !update {
+				return nil
+			}
+			return up.run(s, opts, yes)
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().BoolVarP(
+		&force, "force", "f", false,
+		"Roll back even if resources created since the given version would no longer be managed by the stack")
+	cmd.PersistentFlags().BoolVar(
+		&update, "update", false,
+		"After rolling back, run an update to converge the stack's resources with the restored state")
+	cmd.PersistentFlags().StringVarP(
+		&message, "message", "m", "",
+		"Optional message to associate with the update run by --update")
+	cmd.PersistentFlags().StringVar(
+		&breakFreeze, "break-freeze", "",
+		"Run the update of --update during one of the stack's freeze windows, giving the justification that is "+
+			"recorded with it")
+	cmd.PersistentFlags().BoolVarP(
+		&yes, "yes", "y", false,
+		"Skip confirmation prompts, and automatically approve the update if --update is passed")
+
+	return cmd
+}
+
+// compareRollbackStates compares a stack's current state with the prior state it is about to be rolled back to. It
+// returns the resources that would no longer be managed by the stack, because they exist today but not in the prior
+// state, and the resources that the restored state would refer to, but which have been deleted since.
+func compareRollbackStates(current, prior *deploy.Snapshot) ([]resource.URN, []resource.URN) {
+	urns := func(snap *deploy.Snapshot) map[resource.URN]bool {
+		m := make(map[resource.URN]bool)
+		if snap != nil {
+			for _, res := range snap.Resources {
+				// Only resources whose lifecycle the stack manages matter.
+				if res.Custom && !res.External && !res.Delete {
+					m[res.URN] = true
+				}
+			}
+		}
+		return m
+	}
+	currentURNs, priorURNs := urns(current), urns(prior)
+
+	var untracked, deleted []resource.URN
+	if current != nil {
+		for _, res := range current.Resources {
+			if currentURNs[res.URN] && !priorURNs[res.URN] {
+				untracked = append(untracked, res.URN)
+				delete(currentURNs, res.URN)
+			}
+		}
+	}
+	if prior != nil {
+		for _, res := range prior.Resources {
+			if priorURNs[res.URN] && !currentURNs[res.URN] {
+				deleted = append(deleted, res.URN)
+				delete(priorURNs, res.URN)
+			}
+		}
+	}
+	return untracked, deleted
+}
+
+// confirmRollback asks the user to confirm rolling back the stack.
+func confirmRollback(opts display.Options, stackName string, version int) bool {
+	surveycore.DisableColor = true
+	surveycore.QuestionIcon = ""
+	surveycore.SelectFocusIcon = opts.Color.Colorize(colors.BrightGreen + ">" + colors.Reset)
+	prompt := opts.Color.Colorize(colors.Yellow + "warning" + colors.Reset + ": ")
+	prompt += fmt.Sprintf("This will replace the state of stack %s with the state of version %d. Confirm?",
+		stackName, version)
+
+	confirm := false
+	if err := survey.AskOne(&survey.Confirm{Message: prompt}, &confirm, nil); err != nil {
+		return false
+	}
+	return confirm
+}
+
+// rollbackUpdate is the update run after rolling back a stack, which converges the stack's resources with the
+// restored state.
+type rollbackUpdate struct {
+	proj *workspace.Project
+	root string
+	m    *backend.UpdateMetadata
+}
+
+// newRollbackUpdate prepares the update of the stack using the program in the current directory, checking that it may
+// run before the stack's state is rolled back. If message is empty, the update describes the rollback.
+func newRollbackUpdate(s backend.Stack, version int, message, breakFreeze string) (*rollbackUpdate, error) {
+	proj, root, err := readProject(pulumiAppProj)
+	if err != nil {
+		return nil, err
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("Roll back to the state of version %d", version)
+	}
+	m, err := getUpdateMetadata(message, root)
+	if err != nil {
+		return nil, errors.Wrap(err, "gathering environment metadata")
+	}
+	if err = checkUpdateMessage(proj, s, m); err != nil {
+		return nil, err
+	}
+	if err = checkFreezeWindows(s, m, breakFreeze); err != nil {
+		return nil, err
+	}
+	return &rollbackUpdate{proj: proj, root: root, m: m}, nil
+}
+
+// run runs the update.
+func (up *rollbackUpdate) run(s backend.Stack, opts display.Options, yes bool) result.Result {
+	sm, err := getStackSecretsManager(s)
+	if err != nil {
+		return result.FromError(errors.Wrap(err, "getting secrets manager"))
+	}
+
+	cfg, err := getStackConfiguration(s, sm)
+	if err != nil {
+		return result.FromError(errors.Wrap(err, "getting stack configuration"))
+	}
+
+	_, res := s.Update(commandContext(), backend.UpdateOperation{
+		Proj: up.proj,
+		Root: up.root,
+		M:    up.m,
+		Opts: backend.UpdateOptions{
+			Engine: engine.UpdateOptions{
+				Parallel:      defaultParallel,
+				UseLegacyDiff: useLegacyDiff(),
+			},
+			Display:     opts,
+			AutoApprove: yes || !cmdutil.Interactive(),
+		},
+		StackConfiguration: cfg,
+		SecretsManager:     sm,
+		Scopes:             cancellationScopes,
+	})
+	if res != nil && res.Error() == context.Canceled {
+		return result.FromError(errors.New("update cancelled"))
+	}
+	return PrintEngineResult(res)
+}
@@ -0,0 +1,45 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+func TestCompareRollbackStates(t *testing.T) {
+	res := func(name string, custom bool) *resource.State {
+		urn := resource.NewURN("dev", "proj", "", "test:index:Resource", tokens.QName(name))
+		return &resource.State{URN: urn, Type: urn.Type(), Custom: custom}
+	}
+	a, b, c, comp := res("a", true), res("b", true), res("c", true), res("comp", false)
+
+	current := &deploy.Snapshot{Resources: []*resource.State{a, b, comp}}
+	prior := &deploy.Snapshot{Resources: []*resource.State{a, c}}
+
+	untracked, deleted := compareRollbackStates(current, prior)
+	assert.Equal(t, []resource.URN{b.URN}, untracked)
+	assert.Equal(t, []resource.URN{c.URN}, deleted)
+
+	// Rolling back a stack that has no state yet leaves nothing untracked.
+	untracked, deleted = compareRollbackStates(nil, prior)
+	assert.Empty(t, untracked)
+	assert.Equal(t, []resource.URN{a.URN, c.URN}, deleted)
+}
@@ -0,0 +1,91 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/secrets/vault"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func newStackRotateSecretsKeyCmd() *cobra.Command {
+	var stack string
+	var rewrapOnly bool
+	var cmd = &cobra.Command{
+		Use:   "rotate-secrets-key",
+		Args:  cmdutil.NoArgs,
+		Short: "Rotate the Vault key that protects a stack's secrets",
+		Long: "Rotate the Vault key that protects a stack's secrets.\n" +
+			"\n" +
+			"This command rotates the transit key of a stack whose secrets provider is a HashiCorp Vault key, and\n" +
+			"re-encrypts the stack's data key with the key's new version, so that older versions of the key may be\n" +
+			"retired. The data key itself is unchanged, so the stack's secrets need not be re-encrypted.\n" +
+			"\n" +
+			"If the key was rotated in Vault, pass `--rewrap-only` to re-encrypt the data key with the key's latest\n" +
+			"version without rotating it again.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			s, err := requireStack(stack, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+			ps, err := loadProjectStack(s)
+			if err != nil {
+				return err
+			}
+			if !vault.IsVaultSecretsProvider(ps.SecretsProvider) || ps.EncryptedKey == "" {
+				return errors.Errorf("the secrets of stack '%s' are not protected by a Vault key", s.Ref())
+			}
+
+			dataKey, err := base64.StdEncoding.DecodeString(ps.EncryptedKey)
+			if err != nil {
+				return err
+			}
+			if rewrapOnly {
+				dataKey, err = vault.RewrapDataKey(ps.SecretsProvider, dataKey)
+			} else {
+				dataKey, err = vault.RotateKey(ps.SecretsProvider, dataKey)
+			}
+			if err != nil {
+				return err
+			}
+
+			ps.EncryptedKey = base64.StdEncoding.EncodeToString(dataKey)
+			if err = saveProjectStack(s, ps); err != nil {
+				return errors.Wrap(err, "saving stack config")
+			}
+
+			fmt.Printf("Re-encrypted the data key of %s with the latest version of its Vault key\n", s.Ref())
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().BoolVar(
+		&rewrapOnly, "rewrap-only", false,
+		"Re-encrypt the data key with the key's latest version, without rotating the key")
+	return cmd
+}
@@ -0,0 +1,101 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func newStackWaitCmd() *cobra.Command {
+	var stack string
+	var timeout time.Duration
+	var pollInterval time.Duration
+	var cmd = &cobra.Command{
+		Use:   "wait [<stack-name>]",
+		Args:  cmdutil.MaximumNArgs(1),
+		Short: "Wait until a stack has no update in progress",
+		Long: "Wait until a stack has no update in progress.\n" +
+			"\n" +
+			"This command returns once no update, refresh or destroy is running against the stack,\n" +
+			"which makes it useful for orchestrating operations across several stacks.\n" +
+			"It returns straight away if the stack is already ready. If --timeout is given and the\n" +
+			"stack is still busy once it elapses, the command fails.\n" +
+			"\n" +
+			"For stacks in self-managed backends, this command waits until the stack is no longer\n" +
+			"locked. A lock left behind by an update whose process exited is reported as an error\n" +
+			"rather than waited for; run `pulumi cancel` to break it.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			// Use the stack provided or, if missing, default to the current one.
+			if len(args) > 0 {
+				if stack != "" {
+					return errors.New("only one of --stack or argument stack name may be specified, not both")
+				}
+				stack = args[0]
+			}
+			if timeout < 0 {
+				return errors.New("--timeout must not be negative")
+			}
+			if pollInterval <= 0 {
+				return errors.New("--poll-interval must be positive")
+			}
+
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			s, err := requireStack(stack, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+
+			ctx := commandContext()
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+
+			if err = s.Backend().WaitForStackReady(ctx, s.Ref(), pollInterval); err != nil {
+				if errors.Cause(err) == context.DeadlineExceeded {
+					return errors.Errorf("timed out after %v: %v", timeout, err)
+				}
+				return err
+			}
+
+			fmt.Printf("Stack '%s' has no update in progress\n", s.Ref())
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().DurationVar(
+		&timeout, "timeout", 0,
+		"The longest to wait for the stack's update to finish, for example 10m. Defaults to waiting indefinitely")
+	cmd.PersistentFlags().DurationVar(
+		&pollInterval, "poll-interval", 5*time.Second,
+		"How often to check whether the stack's update has finished")
+
+	return cmd
+}
@@ -24,6 +24,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/backend/display"
 	"github.com/pulumi/pulumi/pkg/diag/colors"
 	"github.com/pulumi/pulumi/pkg/resource"
@@ -48,7 +49,13 @@ troubleshooting a stack or when performing specific edits that otherwise would r
 	}
 
 	cmd.AddCommand(newStateDeleteCommand())
+	cmd.AddCommand(newStateMoveCommand())
+	cmd.AddCommand(newStateRenameCommand())
 	cmd.AddCommand(newStateUnprotectCommand())
+	cmd.AddCommand(newStateEncryptCommand())
+	cmd.AddCommand(newStateLockCommand())
+	cmd.AddCommand(newStateUnlockCommand())
+	cmd.AddCommand(newStateImportCommand())
 	return cmd
 }
 
@@ -90,6 +97,10 @@ func locateStackResource(opts display.Options, snap *deploy.Snapshot, urn resour
 			message += " (Protected)"
 		}
 
+		if ambiguousResource.Locked {
+			message += " (Locked)"
+		}
+
 		if ambiguousResource.Delete {
 			message += " (Pending Deletion)"
 		}
@@ -98,6 +109,11 @@ func locateStackResource(opts display.Options, snap *deploy.Snapshot, urn resour
 		optionMap[message] = ambiguousResource
 	}
 
+	if !cmdutil.Interactive() {
+		return nil, cmdutil.NonInteractiveError(
+			fmt.Sprintf("multiple resources with the URN '%s' exist", urn), "rerun interactively to choose one")
+	}
+
 	var option string
 	if err := survey.AskOne(&survey.Select{
 		Message:  prompt,
@@ -167,19 +183,25 @@ func runTotalStateEdit(
 		contract.AssertNoErrorf(snap.VerifyIntegrity(), "state edit produced an invalid snapshot")
 	}
 
+	// Once we've mutated the snapshot, import it back into the backend so that it can be persisted.
+	return result.WrapIfNonNil(importStackSnapshot(s, snap))
+}
+
+// importStackSnapshot serializes the given snapshot, encrypting its secrets with the snapshot's secrets manager, and
+// imports it into the given stack.
+func importStackSnapshot(s backend.Stack, snap *deploy.Snapshot) error {
 	sdep, err := stack.SerializeDeployment(snap, snap.SecretsManager)
 	if err != nil {
-		return result.FromError(errors.Wrap(err, "serializing deployment"))
+		return errors.Wrap(err, "serializing deployment")
 	}
 
-	// Once we've mutated the snapshot, import it back into the backend so that it can be persisted.
 	bytes, err := json.Marshal(sdep)
 	if err != nil {
-		return result.FromError(err)
+		return err
 	}
 	dep := apitype.UntypedDeployment{
 		Version:    apitype.DeploymentSchemaVersionCurrent,
 		Deployment: bytes,
 	}
-	return result.WrapIfNonNil(s.ImportDeployment(commandContext(), &dep))
+	return s.ImportDeployment(commandContext(), &dep)
 }
@@ -82,6 +82,10 @@ pulumi state delete 'urn:pulumi:stage::demo::eks:index:Cluster$pulumi:providers:
 					return result.Error(
 						"This resource can't be safely deleted because it is protected. " +
 							"Re-run this command with --force to force deletion")
+				case edit.ResourceLockedError:
+					return result.Error(
+						"This resource can't be deleted because it is locked. " +
+							"Run `pulumi state unlock` to unlock it first")
 				default:
 					return res
 				}
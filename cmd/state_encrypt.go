@@ -0,0 +1,86 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/result"
+)
+
+func newStateEncryptCommand() *cobra.Command {
+	var decrypt bool
+	var stackName string
+
+	cmd := &cobra.Command{
+		Use:   "encrypt",
+		Short: "Encrypt a stack's state at rest",
+		Long: `Encrypt a stack's state at rest
+
+This command rewrites the stack's existing state encrypted with the key of the stack's secrets provider, and causes
+every later checkpoint of the stack to be encrypted in the same way. Reading the state then requires access to that
+key: the passphrase, or the cloud key management service or Vault key the stack is configured with.
+
+Pass --decrypt to rewrite the state unencrypted again.
+
+Stacks whose state is encrypted can still be listed and selected without the key, which is only needed once the
+state itself is read. To encrypt the state of every stack with a secrets provider as it is written, set the
+PULUMI_ENCRYPT_STATE environment variable.`,
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+			s, err := requireStack(stackName, false, opts, true /*setCurrent*/)
+			if err != nil {
+				return result.FromError(err)
+			}
+
+			b, ok := s.Backend().(backend.StateEncryptionBackend)
+			if !ok {
+				return result.Errorf("the %s backend does not support encrypting state at rest", s.Backend().Name())
+			}
+
+			sm, err := getStackSecretsManager(s)
+			if err != nil {
+				return result.FromError(errors.Wrap(err, "getting secrets manager"))
+			}
+
+			if err = b.SetStateEncryption(commandContext(), s.Ref(), sm, !decrypt); err != nil {
+				return result.FromError(err)
+			}
+			if decrypt {
+				fmt.Printf("The state of stack %s is no longer encrypted at rest\n", s.Ref())
+			} else {
+				fmt.Printf("The state of stack %s is now encrypted at rest\n", s.Ref())
+			}
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+	cmd.Flags().BoolVar(
+		&decrypt, "decrypt", false,
+		"Rewrite the stack's state unencrypted")
+	return cmd
+}
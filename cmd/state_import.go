@@ -0,0 +1,181 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/operations"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/resource/edit"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/result"
+)
+
+func newStateImportCommand() *cobra.Command {
+	var stack string
+	var parent string
+	var provider string
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "import <type> <name> <id>",
+		Short: "Import an existing resource into a stack's state",
+		Long: `Import an existing resource into a stack's state
+
+This command reads the resource with the given type and ID from its provider, and adds it to the stack's state under
+the given name, without running the stack's program. The resource is read with the stack's default provider for its
+package, or with the provider resource given by --provider, which must already be in the stack's state. It is added
+as a child of the stack's root resource, or of the resource given by --parent.
+
+The resource is marked as external, just as if the program had read it: Pulumi will not update or delete it. To adopt
+it, declare the resource in the program under the same name and parent, with the 'import' resource option set to its
+ID. The next update then takes ownership of the resource, rather than creating a new one.
+
+Make sure that URNs are single-quoted to avoid having characters unexpectedly interpreted by the shell.
+
+Example:
+pulumi state import aws:s3/bucket:Bucket my-bucket my-bucket-1234`,
+		Args: cmdutil.ExactArgs(3),
+		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
+			typ, err := tokens.ParseTypeToken(args[0])
+			if err != nil {
+				return result.FromError(errors.Wrapf(err, "invalid resource type %q", args[0]))
+			}
+			name, id := tokens.QName(args[1]), resource.ID(args[2])
+
+			var imported *resource.State
+			// Show the confirmation prompt if the user didn't pass the --yes parameter to skip it.
+			res := runTotalStateEdit(stack, !yes, func(opts display.Options, snap *deploy.Snapshot) error {
+				state, err := readStateImport(opts, snap, typ, name, id, resource.URN(parent), resource.URN(provider))
+				if err != nil {
+					return err
+				}
+				if err = edit.ImportResource(snap, state); err != nil {
+					return err
+				}
+				imported = state
+				return nil
+			})
+			if res != nil {
+				return res
+			}
+			fmt.Printf("Resource successfully imported as %s\n", imported.URN)
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+	cmd.Flags().StringVar(&parent, "parent", "",
+		"The URN of the resource to import the resource as a child of. Defaults to the stack's root resource")
+	cmd.Flags().StringVar(&provider, "provider", "",
+		"The URN of the provider resource to read the resource with. Defaults to the default provider for its package")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompts")
+
+	return cmd
+}
+
+// readStateImport reads the resource with the given type and ID from its provider, and returns the state with which
+// to import it into the given snapshot under the given name and parent. The resource is marked as external.
+func readStateImport(opts display.Options, snap *deploy.Snapshot, typ tokens.Type, name tokens.QName, id resource.ID,
+	parentURN, providerURN resource.URN) (*resource.State, error) {
+
+	if snap == nil {
+		return nil, errors.New("the stack has no resources; run 'pulumi import' to import a resource into it")
+	}
+
+	var root *resource.State
+	for _, r := range snap.Resources {
+		if r.Type == resource.RootStackType && r.Parent == "" && !r.Delete {
+			root = r
+			break
+		}
+	}
+	if root == nil {
+		return nil, errors.New("the stack has no root resource")
+	}
+
+	parent := root
+	if parentURN != "" {
+		p, err := locateStackResource(opts, snap, parentURN)
+		if err != nil {
+			return nil, err
+		}
+		parent = p
+	}
+
+	if providerURN == "" {
+		providerURN = resource.NewURN(root.URN.Stack(), root.URN.Project(), "",
+			providers.MakeProviderType(typ.Package()), "default")
+	}
+	prov, err := locateStackResource(opts, snap, providerURN)
+	if err != nil {
+		return nil, errors.Wrapf(err, "finding the provider for %s", typ)
+	}
+	if !providers.IsProviderType(prov.Type) || providers.GetProviderPackage(prov.Type) != typ.Package() {
+		return nil, errors.Errorf("%q is not a provider for package %s", prov.URN, typ.Package())
+	}
+	ref, err := providers.NewReference(prov.URN, prov.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Construct the URN just as the engine would for a resource registered with this parent.
+	parentType := tokens.Type("")
+	if parent.Type != resource.RootStackType {
+		parentType = parent.URN.QualifiedType()
+	}
+	urn := resource.NewURN(root.URN.Stack(), root.URN.Project(), parentType, typ, name)
+
+	state := resource.NewState(typ, urn, true, false, id, resource.PropertyMap{}, nil, parent.URN, false, true,
+		nil, nil, ref.String(), nil, false, nil, nil, nil)
+
+	plugctx, err := plugin.NewContext(cmdutil.Diag(), cmdutil.Diag(), nil, nil, "", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer contract.IgnoreClose(plugctx)
+
+	p, err := operations.NewProviderSource(plugctx.Host, snap.Resources).Provider(state)
+	if err != nil {
+		return nil, err
+	}
+	read, _, err := p.Read(urn, id, nil, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s", urn)
+	}
+	if read.Outputs == nil {
+		return nil, errors.Errorf("resource '%v' does not exist", id)
+	}
+	if read.ID != "" {
+		state.ID = read.ID
+	}
+	if read.Inputs != nil {
+		state.Inputs = read.Inputs
+	}
+	state.Outputs = read.Outputs
+	return state, nil
+}
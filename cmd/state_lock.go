@@ -0,0 +1,91 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/edit"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/result"
+)
+
+func newStateLockCommand() *cobra.Command {
+	var stack string
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "lock <resource URN>",
+		Short: "Lock a resource in a stack's state",
+		Long: `Lock a resource in a stack's state
+
+This command sets the 'locked' bit on a resource. Any update that would modify, replace, or delete a locked
+resource fails before that resource is changed, until the resource is unlocked with 'pulumi state unlock'. The
+update's preview fails in the same way, so 'pulumi up' stops before the update starts. Unlike 'protect', which only
+prevents deletion, locking also prevents in-place updates.
+
+Make sure that URNs are single-quoted to avoid having characters unexpectedly interpreted by the shell.`,
+		Args: cmdutil.ExactArgs(1),
+		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
+			// Show the confirmation prompt if the user didn't pass the --yes parameter to skip it.
+			res := runStateEdit(stack, !yes, resource.URN(args[0]), edit.LockResource)
+			if res != nil {
+				return res
+			}
+			fmt.Println("Resource successfully locked")
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompts")
+
+	return cmd
+}
+
+func newStateUnlockCommand() *cobra.Command {
+	var stack string
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "unlock <resource URN>",
+		Short: "Unlock a resource in a stack's state",
+		Long: `Unlock a resource in a stack's state
+
+This command clears the 'locked' bit on a resource, allowing updates to modify or delete it again.`,
+		Args: cmdutil.ExactArgs(1),
+		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
+			// Show the confirmation prompt if the user didn't pass the --yes parameter to skip it.
+			res := runStateEdit(stack, !yes, resource.URN(args[0]), edit.UnlockResource)
+			if res != nil {
+				return res
+			}
+			fmt.Println("Resource successfully unlocked")
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompts")
+
+	return cmd
+}
@@ -0,0 +1,197 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	survey "gopkg.in/AlecAivazis/survey.v1"
+	surveycore "gopkg.in/AlecAivazis/survey.v1/core"
+
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/edit"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/result"
+	"github.com/pulumi/pulumi/pkg/version"
+)
+
+func newStateMoveCommand() *cobra.Command {
+	var stackName string
+	var destName string
+	var includeDependents bool
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "move <resource URN>",
+		Short: "Moves a resource from one stack's state to another's",
+		Long: `Moves a resource from one stack's state to another's
+
+This command moves a resource, along with every resource parented to it, from a stack's state into the state of the
+stack given by --dest. The moved resources' URNs are rewritten to name the destination stack. This is useful when
+splitting a stack into several, after moving the resources' code into the destination stack's program.
+
+Resources that depend on a moved resource must be moved along with it; pass --include-dependents to do so. Every other
+resource that a moved resource depends on must already exist in the destination stack, except for providers, which
+are copied. The destination stack's state is written first, and is restored if the source stack's state cannot be
+written.
+
+Make sure that URNs are single-quoted to avoid having characters unexpectedly interpreted by the shell.
+
+Example:
+pulumi state move 'urn:pulumi:prod::monolith::aws:rds/instance:Instance::db' --dest database-prod
+`,
+		Args: cmdutil.ExactArgs(1),
+		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
+			urn := resource.URN(args[0])
+			if destName == "" {
+				return result.Error("a destination stack must be given with --dest")
+			}
+
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+			source, err := requireStack(stackName, false, opts, true /*setCurrent*/)
+			if err != nil {
+				return result.FromError(err)
+			}
+			dest, err := requireStack(destName, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return result.FromError(err)
+			}
+			if dest.Ref().String() == source.Ref().String() {
+				return result.Error("the destination stack must differ from the source stack")
+			}
+
+			ctx := commandContext()
+			sourceSnap, err := source.Snapshot(ctx)
+			if err != nil {
+				return result.FromError(err)
+			}
+			if sourceSnap == nil {
+				return result.Errorf("stack %s has no resources", source.Ref())
+			}
+			destSnap, err := dest.Snapshot(ctx)
+			if err != nil {
+				return result.FromError(err)
+			}
+			if destSnap == nil {
+				sm, smErr := getStackSecretsManager(dest)
+				if smErr != nil {
+					return result.FromError(smErr)
+				}
+				destSnap = deploy.NewSnapshot(deploy.Manifest{
+					Time:    time.Now(),
+					Version: version.Version,
+				}, sm, nil, nil)
+			}
+
+			res, err := locateStackResource(opts, sourceSnap, urn)
+			if err != nil {
+				return result.FromError(err)
+			}
+
+			if !yes && cmdutil.Interactive() {
+				confirm := false
+				surveycore.DisableColor = true
+				surveycore.QuestionIcon = ""
+				surveycore.SelectFocusIcon = opts.Color.Colorize(colors.BrightGreen + ">" + colors.Reset)
+				prompt := opts.Color.Colorize(colors.Yellow + "warning" + colors.Reset + ": ")
+				prompt += fmt.Sprintf("This command will edit the state of stacks %s and %s directly. Confirm?",
+					source.Ref(), dest.Ref())
+				if err = survey.AskOne(&survey.Confirm{
+					Message: prompt,
+				}, &confirm, nil); err != nil || !confirm {
+					fmt.Println("confirmation declined")
+					return result.Bail()
+				}
+			}
+
+			// As with other state edits, only insist that the edit leaves the snapshots valid if they were valid to
+			// begin with.
+			sourceIsAlreadyHosed := sourceSnap.VerifyIntegrity() != nil
+			destIsAlreadyHosed := destSnap.VerifyIntegrity() != nil
+			moved, err := edit.MoveResources(sourceSnap, destSnap, res, dest.Ref().Name(), includeDependents)
+			if err != nil {
+				switch e := err.(type) {
+				case edit.ResourceHasDependenciesError:
+					message := "This resource can't be moved because the following resources depend on it:\n"
+					for _, dependentResource := range e.Dependencies {
+						depUrn := dependentResource.URN
+						message += fmt.Sprintf(" * %-15q (%s)\n", depUrn.Name(), depUrn)
+					}
+
+					message += "\nRe-run this command with --include-dependents to move them too."
+					return result.Error(message)
+				case edit.ResourceDependencyNotMovedError:
+					return result.Errorf(
+						"Resource %q can't be moved because it depends on %q, which is neither being moved nor "+
+							"present in stack %s. Move that resource first.", e.Moved.URN, e.Dependency, dest.Ref())
+				default:
+					return result.FromError(err)
+				}
+			}
+			if !sourceIsAlreadyHosed {
+				contract.AssertNoErrorf(sourceSnap.VerifyIntegrity(), "state move produced an invalid source snapshot")
+			}
+			if !destIsAlreadyHosed {
+				contract.AssertNoErrorf(destSnap.VerifyIntegrity(),
+					"state move produced an invalid destination snapshot")
+			}
+
+			// Write the destination first, so that the moved resources are never absent from both stacks. If the
+			// source can't be written, put the destination back the way it was.
+			original, err := dest.ExportDeployment(ctx)
+			if err != nil {
+				return result.FromError(errors.Wrap(err, "exporting the destination stack's state"))
+			}
+			if err = importStackSnapshot(dest, destSnap); err != nil {
+				return result.FromError(errors.Wrapf(err, "writing the state of stack %s", dest.Ref()))
+			}
+			if err = importStackSnapshot(source, sourceSnap); err != nil {
+				if restoreErr := dest.ImportDeployment(ctx, original); restoreErr != nil {
+					return result.Errorf("writing the state of stack %s failed: %v; restoring the state of stack %s "+
+						"also failed, so the moved resources are present in both stacks: %v",
+						source.Ref(), err, dest.Ref(), restoreErr)
+				}
+				return result.FromError(errors.Wrapf(err, "writing the state of stack %s", source.Ref()))
+			}
+
+			for _, r := range moved {
+				fmt.Printf("Moved %s\n", r.URN)
+			}
+			fmt.Printf("Moved %d resources to stack %s\n", len(moved), dest.Ref())
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "",
+		"The name of the stack to move the resource from. Defaults to the current stack")
+	cmd.Flags().StringVar(
+		&destName, "dest", "",
+		"The name of the stack to move the resource to")
+	cmd.Flags().BoolVar(
+		&includeDependents, "include-dependents", false,
+		"Also move the resources that depend on the resource")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompts")
+	return cmd
+}
@@ -0,0 +1,88 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/edit"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/result"
+)
+
+func newStateRenameCommand() *cobra.Command {
+	var stack string
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "rename <resource URN> <new name>",
+		Short: "Renames a resource in a stack's state",
+		Long: `Renames a resource in a stack's state
+
+This command changes the name in a resource's URN, and rewrites every reference to the URN from the resource's
+children, from the resources that depend on it and, if it is a provider, from the resources that use it. After
+renaming a resource in the program, renaming it in the state too lets the next update keep the resource instead of
+replacing it.
+
+The URNs of the resource's children do not include its name, and are left as they are.
+
+Make sure that URNs are single-quoted to avoid having characters unexpectedly interpreted by the shell.
+
+Example:
+pulumi state rename 'urn:pulumi:prod::web::aws:s3/bucket:Bucket::logs' access-logs
+`,
+		Args: cmdutil.ExactArgs(2),
+		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
+			urn, newName := resource.URN(args[0]), tokens.QName(args[1])
+			// Show the confirmation prompt if the user didn't pass the --yes parameter to skip it.
+			showPrompt := !yes
+
+			var renamed resource.URN
+			res := runTotalStateEdit(stack, showPrompt, func(_ display.Options, snap *deploy.Snapshot) error {
+				// Resources that share the URN are renamed together, so any of them will do.
+				candidates := edit.LocateResource(snap, urn)
+				if len(candidates) == 0 {
+					return errors.Errorf("No such resource %q exists in the current state", urn)
+				}
+				if err := edit.RenameResource(snap, candidates[0], newName); err != nil {
+					return err
+				}
+				renamed = candidates[0].URN
+				return nil
+			})
+			if res != nil {
+				if e, ok := res.Error().(edit.ResourceAlreadyExistsError); ok {
+					return result.Errorf("This resource can't be renamed because the state already contains %q", e.URN)
+				}
+				return res
+			}
+			fmt.Printf("Renamed %s to %s\n", urn, renamed)
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompts")
+	return cmd
+}
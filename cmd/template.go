@@ -0,0 +1,164 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/dustin/go-humanize"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func newTemplateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "template",
+		Short: "Manage the templates that 'pulumi new' can use",
+		Long: "Manage the templates that 'pulumi new' can use.\n" +
+			"\n" +
+			"Templates are cached in ~/.pulumi/templates (or the directory given by PULUMI_TEMPLATE_PATH). The\n" +
+			"cache holds the Pulumi templates, which 'pulumi new' downloads, along with any templates that have\n" +
+			"been added to it from other URLs or paths. Cached templates can be used by name, and without network\n" +
+			"access by passing --offline to 'pulumi new'.",
+		Args: cmdutil.NoArgs,
+	}
+
+	cmd.AddCommand(newTemplateLsCmd())
+	cmd.AddCommand(newTemplateAddCmd())
+	cmd.AddCommand(newTemplateRmCmd())
+	cmd.AddCommand(newTemplateUpdateCmd())
+
+	return cmd
+}
+
+func newTemplateLsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List the cached templates",
+		Args:    cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			repo, err := workspace.RetrieveTemplates("", true /*offline*/)
+			if err != nil {
+				return err
+			}
+			templates, err := repo.Templates()
+			if err != nil {
+				return errors.Wrap(err, "listing templates")
+			}
+			added, err := workspace.GetRegisteredTemplates()
+			if err != nil {
+				return errors.Wrap(err, "loading added templates")
+			}
+			if len(templates) == 0 {
+				fmt.Println("No templates are cached; run 'pulumi template update' to download the Pulumi templates.")
+				return nil
+			}
+
+			sources := make(map[string]workspace.RegisteredTemplate)
+			for _, t := range added {
+				sources[t.Name] = t
+			}
+
+			var rows []cmdutil.TableRow
+			for _, t := range templates {
+				name := filepath.Base(t.Dir)
+				source, updated := "pulumi", naString
+				if a, ok := sources[name]; ok {
+					source, updated = a.Source, humanize.Time(a.Updated)
+				}
+				rows = append(rows, cmdutil.TableRow{Columns: []string{name, t.Description, source, updated}})
+			}
+			cmdutil.PrintTable(cmdutil.Table{
+				Headers: []string{"NAME", "DESCRIPTION", "SOURCE", "UPDATED"},
+				Rows:    rows,
+			})
+			return nil
+		}),
+	}
+}
+
+func newTemplateAddCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "add <name> <url|path>",
+		Short: "Add a template to the cache",
+		Long: "Add a template to the cache.\n" +
+			"\n" +
+			"This command copies the template at the given URL or path into the template cache under the given\n" +
+			"name, so that 'pulumi new <name>' can use it, even offline. The URL or path must refer to a single\n" +
+			"template: a directory that contains a Pulumi.yaml file. 'pulumi new --offline <url>' also uses the\n" +
+			"cached copy of a template that was added from that URL.",
+		Args: cmdutil.ExactArgs(2),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			added, err := workspace.AddTemplate(args[0], args[1])
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Added template '%s' from %s\n", added.Name, added.Source)
+			return nil
+		}),
+	}
+}
+
+func newTemplateRmCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "rm <name>",
+		Aliases: []string{"remove"},
+		Short:   "Remove an added template from the cache",
+		Long: "Remove an added template from the cache.\n" +
+			"\n" +
+			"Only templates that were added with 'pulumi template add' can be removed.",
+		Args: cmdutil.ExactArgs(1),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			if err := workspace.RemoveTemplate(args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("Removed template '%s'\n", args[0])
+			return nil
+		}),
+	}
+}
+
+func newTemplateUpdateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "update [name...]",
+		Short: "Update the cached templates",
+		Long: "Update the cached templates.\n" +
+			"\n" +
+			"This command downloads the latest Pulumi templates, and copies each added template from its source\n" +
+			"again. If names are given, only the added templates with those names are updated.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				if err := workspace.UpdatePulumiTemplates(); err != nil {
+					return errors.Wrap(err, "updating the Pulumi templates")
+				}
+				fmt.Println("Updated the Pulumi templates")
+			}
+
+			updated, err := workspace.UpdateTemplates(args)
+			if err != nil {
+				return err
+			}
+			for _, t := range updated {
+				fmt.Printf("Updated template '%s' from %s\n", t.Name, t.Source)
+			}
+			return nil
+		}),
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"io/ioutil"
 	"math"
 	"os"
+	"time"
 
 	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/contract"
@@ -27,6 +28,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"github.com/pulumi/pulumi/pkg/apitype"
 	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/backend/display"
 	"github.com/pulumi/pulumi/pkg/engine"
@@ -46,22 +48,34 @@ const (
 // nolint: vetshadow
 func newUpCmd() *cobra.Command {
 	var debug bool
+	var eventLogPath string
+	var displayPlugins []string
 	var expectNop bool
 	var message string
+	var breakFreeze string
+	var planFile string
+	var requireApproval bool
+	var approvalTimeout time.Duration
 	var stack string
 	var configArray []string
 
 	// Flags for engine.UpdateOptions.
 	var policyPackPaths []string
 	var diffDisplay bool
+	var inlineDiffs bool
+	var overrideGuardrails bool
+	var strictDeprecations bool
 	var parallel int
 	var refresh bool
 	var showConfig bool
 	var showReplacementSteps bool
 	var showSames bool
+	var skipPreflight bool
 	var skipPreview bool
 	var suppressOutputs bool
-	var yes bool
+	var targets []string
+	var targetDependents bool
+	var yes yesFlag
 	var secretsProvider string
 
 	// up implementation used when the source of the Pulumi program is in the current working directory.
@@ -92,6 +106,15 @@ func newUpCmd() *cobra.Command {
 		if err != nil {
 			return result.FromError(errors.Wrap(err, "gathering environment metadata"))
 		}
+		if err = checkUpdateMessage(proj, s, m); err != nil {
+			return result.FromError(err)
+		}
+		if err = checkFreezeWindows(s, m, breakFreeze); err != nil {
+			return result.FromError(err)
+		}
+		if overrideGuardrails {
+			m.Environment[backend.GuardrailsOverridden] = "true"
+		}
 
 		sm, err := getStackSecretsManager(s)
 		if err != nil {
@@ -103,15 +126,30 @@ func newUpCmd() *cobra.Command {
 			return result.FromError(errors.Wrap(err, "getting stack configuration"))
 		}
 
+		var plan *engine.UpdatePlan
+		if planFile != "" {
+			if plan, err = readUpdatePlan(planFile); err != nil {
+				return result.FromError(errors.Wrap(err, "loading plan"))
+			}
+		}
+
 		opts.Engine = engine.UpdateOptions{
 			LocalPolicyPackPaths: policyPackPaths,
 			Parallel:             parallel,
 			Debug:                debug,
 			Refresh:              refresh,
 			UseLegacyDiff:        useLegacyDiff(),
+			Memory:               memoryOptions(),
+			SkipPreflight:        skipPreflight,
+			OverrideGuardrails:   overrideGuardrails,
+			StrictDeprecations:   strictDeprecations,
+			UpdateTargets:        targetURNs(targets),
+			TargetDependents:     targetDependents,
+			Plan:                 plan,
+			Timings:              loadOperationTimings(),
 		}
 
-		changes, res := s.Update(commandContext(), backend.UpdateOperation{
+		op := backend.UpdateOperation{
 			Proj:               proj,
 			Root:               root,
 			M:                  m,
@@ -119,7 +157,11 @@ func newUpCmd() *cobra.Command {
 			StackConfiguration: cfg,
 			SecretsManager:     sm,
 			Scopes:             cancellationScopes,
-		})
+		}
+		last := recordLastUpdate(s, apitype.UpdateUpdate, opts.PreviewOnly, &op)
+		changes, res := s.Update(commandContext(), op)
+		last.finish(changes, res)
+		saveOperationTimings(opts.Engine.Timings)
 		switch {
 		case res != nil && res.Error() == context.Canceled:
 			return result.FromError(errors.New("update cancelled"))
@@ -193,7 +235,7 @@ func newUpCmd() *cobra.Command {
 		if name == "" {
 			defaultValue := workspace.ValueOrSanitizedDefaultProjectName(name, template.ProjectName, template.Name)
 			name, err = promptForValue(
-				yes, "project name", defaultValue, false, workspace.ValidateProjectName, opts.Display)
+				yes.approve, "project name", defaultValue, false, workspace.ValidateProjectName, opts.Display)
 			if err != nil {
 				return result.FromError(err)
 			}
@@ -204,14 +246,20 @@ func newUpCmd() *cobra.Command {
 			defaultValue := workspace.ValueOrDefaultProjectDescription(
 				description, template.ProjectDescription, template.Description)
 			description, err = promptForValue(
-				yes, "project description", defaultValue, false, workspace.ValidateProjectDescription, opts.Display)
+				yes.approve, "project description", defaultValue, false, workspace.ValidateProjectDescription, opts.Display)
 			if err != nil {
 				return result.FromError(err)
 			}
 		}
 
+		// Prompt for the template's parameters, if it has any.
+		parameters, err := templateParameterValues(template, nil, yes.approve, opts.Display)
+		if err != nil {
+			return result.FromError(err)
+		}
+
 		// Copy the template files from the repo to the temporary "virtual workspace" directory.
-		if err = template.CopyTemplateFiles(temp, true, name, description); err != nil {
+		if err = template.CopyTemplateFiles(temp, true, name, description, parameters); err != nil {
 			return result.FromError(err)
 		}
 
@@ -229,7 +277,7 @@ func newUpCmd() *cobra.Command {
 
 		// Create the stack, if needed.
 		if s == nil {
-			if s, err = promptAndCreateStack(stack, name, false /*setCurrent*/, yes,
+			if s, err = promptAndCreateStack(stack, name, false /*setCurrent*/, yes.approve,
 				opts.Display, secretsProvider); err != nil {
 				return result.FromError(err)
 			}
@@ -237,7 +285,7 @@ func newUpCmd() *cobra.Command {
 		}
 
 		// Prompt for config values (if needed) and save.
-		if err = handleConfig(s, templateNameOrURL, template, configArray, yes, opts.Display); err != nil {
+		if err = handleConfig(s, templateNameOrURL, template, configArray, yes.approve, opts.Display); err != nil {
 			return result.FromError(err)
 		}
 
@@ -250,6 +298,15 @@ func newUpCmd() *cobra.Command {
 		if err != nil {
 			return result.FromError(errors.Wrap(err, "gathering environment metadata"))
 		}
+		if err = checkUpdateMessage(proj, s, m); err != nil {
+			return result.FromError(err)
+		}
+		if err = checkFreezeWindows(s, m, breakFreeze); err != nil {
+			return result.FromError(err)
+		}
+		if overrideGuardrails {
+			m.Environment[backend.GuardrailsOverridden] = "true"
+		}
 
 		sm, err := getStackSecretsManager(s)
 		if err != nil {
@@ -266,6 +323,13 @@ func newUpCmd() *cobra.Command {
 			Parallel:             parallel,
 			Debug:                debug,
 			Refresh:              refresh,
+			SkipPreflight:        skipPreflight,
+			OverrideGuardrails:   overrideGuardrails,
+			StrictDeprecations:   strictDeprecations,
+			UpdateTargets:        targetURNs(targets),
+			TargetDependents:     targetDependents,
+			Timings:              loadOperationTimings(),
+			Memory:               memoryOptions(),
 		}
 
 		// TODO for the URL case:
@@ -273,7 +337,7 @@ func newUpCmd() *cobra.Command {
 		// - attempt `destroy` on any update errors.
 		// - show template.Quickstart?
 
-		changes, res := s.Update(commandContext(), backend.UpdateOperation{
+		op := backend.UpdateOperation{
 			Proj:               proj,
 			Root:               root,
 			M:                  m,
@@ -281,7 +345,11 @@ func newUpCmd() *cobra.Command {
 			StackConfiguration: cfg,
 			SecretsManager:     sm,
 			Scopes:             cancellationScopes,
-		})
+		}
+		last := recordLastUpdate(s, apitype.UpdateUpdate, opts.PreviewOnly, &op)
+		changes, res := s.Update(commandContext(), op)
+		last.finish(changes, res)
+		saveOperationTimings(opts.Engine.Timings)
 		switch {
 		case res != nil && res.Error() == context.Canceled:
 			return result.FromError(errors.New("update cancelled"))
@@ -314,18 +382,18 @@ func newUpCmd() *cobra.Command {
 		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
 			interactive := cmdutil.Interactive()
 			if !interactive {
-				yes = true // auto-approve changes, since we cannot prompt.
+				yes.approve = true // auto-approve changes, since we cannot prompt.
 			}
 
 			opts, err := updateFlagsToOptions(interactive, skipPreview, yes)
 			if err != nil {
 				return result.FromError(err)
 			}
-
-			var displayType = display.DisplayProgress
-			if diffDisplay {
-				displayType = display.DisplayDiff
+			if approvalTimeout < 0 {
+				return result.FromError(errors.New("--approval-timeout must not be negative"))
 			}
+			opts.RequireApproval = requireApproval
+			opts.ApprovalTimeout = approvalTimeout
 
 			opts.Display = display.Options{
 				Color:                cmdutil.GetGlobalColorization(),
@@ -334,11 +402,29 @@ func newUpCmd() *cobra.Command {
 				ShowSameResources:    showSames,
 				SuppressOutputs:      suppressOutputs,
 				IsInteractive:        interactive,
-				Type:                 displayType,
+				Type:                 getDisplayType(diffDisplay),
+				InlineDiffs:          inlineDiffs,
+				Verbosity:            displayVerbosity,
 				Debug:                debug,
+				LowBandwidth:         useLowBandwidth(),
 			}
 
+			closeEventLog, err := openEventLog(eventLogPath, &opts.Display)
+			if err != nil {
+				return result.FromError(err)
+			}
+			defer closeEventLog()
+
+			closeDisplayPlugins, err := openDisplayPlugins(displayPlugins, &opts.Display)
+			if err != nil {
+				return result.FromError(err)
+			}
+			defer closeDisplayPlugins()
+
 			if len(args) > 0 {
+				if planFile != "" {
+					return result.Error("--plan cannot be used when creating a stack from a template")
+				}
 				return upTemplateNameOrURL(args[0], opts)
 			}
 
@@ -349,6 +435,14 @@ func newUpCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVarP(
 		&debug, "debug", "d", false,
 		"Print detailed debugging output during resource operations")
+	cmd.PersistentFlags().StringVar(
+		&eventLogPath, "event-log", "",
+		"Log every engine event to the given file, as newline-delimited JSON")
+	cmd.PersistentFlags().StringArrayVar(
+		&displayPlugins, "display-plugin", nil,
+		"Also render engine events with the given display plugin, as `name[=arg]`: junit=<path> writes JUnit XML, "+
+			"github[=<file>] writes GitHub Actions annotations, and other plugins are run as pulumi-display-<name> "+
+			"executables on the PATH. May be repeated")
 	cmd.PersistentFlags().BoolVar(
 		&expectNop, "expect-no-changes", false,
 		"Return an error if any changes occur during this update")
@@ -363,12 +457,27 @@ func newUpCmd() *cobra.Command {
 		"Config to use during the update")
 	cmd.PersistentFlags().StringVar(
 		&secretsProvider, "secrets-provider", "default", "The type of the provider that should be used to encrypt and "+
-			"decrypt secrets (possible choices: default, passphrase, awskms, azurekeyvault, gcpkms, hashivault). Only"+
+			"decrypt secrets (possible choices: default, passphrase, awskms, azurekeyvault, gcpkms, hashivault, vault). Only"+
 			"used when creating a new stack from an existing template")
 
 	cmd.PersistentFlags().StringVarP(
 		&message, "message", "m", "",
 		"Optional message to associate with the update operation")
+	cmd.PersistentFlags().StringVar(
+		&breakFreeze, "break-freeze", "",
+		"Run the update during one of the stack's freeze windows, giving the justification that is recorded with it")
+	cmd.PersistentFlags().StringVar(
+		&planFile, "plan", "",
+		"Make only the changes in the given plan, saved by `pulumi preview --save-plan`; the update's preview fails "+
+			"if the update would do anything else, and so does the update itself")
+	cmd.PersistentFlags().BoolVar(
+		&requireApproval, "require-approval", false,
+		"Submit the update's plan for approval by the stack's organization, and wait until it is approved before "+
+			"updating")
+	cmd.PersistentFlags().DurationVar(
+		&approvalTimeout, "approval-timeout", 24*time.Hour,
+		"With --require-approval, the longest to wait for the update to be approved, for example 30m. 0 waits "+
+			"indefinitely")
 
 	// Flags for engine.UpdateOptions.
 	cmd.PersistentFlags().StringSliceVar(
@@ -377,6 +486,16 @@ func newUpCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(
 		&diffDisplay, "diff", false,
 		"Display operation as a rich diff showing the overall change")
+	cmd.PersistentFlags().BoolVar(
+		&inlineDiffs, "inline-diff", false,
+		"Show the full property diff of each changed resource in the progress display; long diffs can be expanded "+
+			"and paged once the preview completes")
+	cmd.PersistentFlags().BoolVar(
+		&overrideGuardrails, "override-guardrails", false,
+		"Proceed even if the update exceeds the stack's guardrails; the override is recorded with the update")
+	cmd.PersistentFlags().BoolVar(
+		&strictDeprecations, "strict-deprecations", false,
+		"Fail if the program uses deprecated resource types or properties, rather than warning")
 	cmd.PersistentFlags().IntVarP(
 		&parallel, "parallel", "p", defaultParallel,
 		"Allow P resource operations to run in parallel at once (1 for no parallelism). Defaults to unbounded.")
@@ -392,15 +511,22 @@ func newUpCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(
 		&showSames, "show-sames", false,
 		"Show resources that don't need be updated because they haven't changed, alongside those that do")
+	cmd.PersistentFlags().BoolVar(
+		&skipPreflight, "skip-preflight", false,
+		"Do not check plugins, provider credentials, configuration, and permissions before performing the update")
 	cmd.PersistentFlags().BoolVar(
 		&skipPreview, "skip-preview", false,
 		"Do not perform a preview before performing the update")
 	cmd.PersistentFlags().BoolVar(
 		&suppressOutputs, "suppress-outputs", false,
 		"Suppress display of stack outputs (in case they contain sensitive values)")
-	cmd.PersistentFlags().BoolVarP(
-		&yes, "yes", "y", false,
-		"Automatically approve and perform the update after previewing it")
+	cmd.PersistentFlags().StringArrayVarP(
+		&targets, "target", "t", []string{},
+		"Update only the resource with the given URN, leaving all others as they are; may be given more than once")
+	cmd.PersistentFlags().BoolVar(
+		&targetDependents, "target-dependents", false,
+		"Also update the resources that depend on the targets given with --target")
+	addYesFlag(cmd, &yes, "update")
 
 	return cmd
 }
@@ -19,6 +19,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -26,26 +27,35 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	multierror "github.com/hashicorp/go-multierror"
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
 	survey "gopkg.in/AlecAivazis/survey.v1"
 	surveycore "gopkg.in/AlecAivazis/survey.v1/core"
 	git "gopkg.in/src-d/go-git.v4"
 
+	"github.com/pulumi/pulumi/pkg/apitype"
 	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/backend/display"
 	"github.com/pulumi/pulumi/pkg/backend/filestate"
 	"github.com/pulumi/pulumi/pkg/backend/httpstate"
 	"github.com/pulumi/pulumi/pkg/backend/state"
+	"github.com/pulumi/pulumi/pkg/diag"
 	"github.com/pulumi/pulumi/pkg/diag/colors"
 	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/secrets/stacksecrets"
+	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/cancel"
 	"github.com/pulumi/pulumi/pkg/util/ciutil"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 	"github.com/pulumi/pulumi/pkg/util/contract"
 	"github.com/pulumi/pulumi/pkg/util/gitutil"
+	"github.com/pulumi/pulumi/pkg/util/i18n"
 	"github.com/pulumi/pulumi/pkg/util/logging"
 	"github.com/pulumi/pulumi/pkg/util/tracing"
 	"github.com/pulumi/pulumi/pkg/workspace"
@@ -59,16 +69,118 @@ func useLegacyDiff() bool {
 	return cmdutil.IsTruthy(os.Getenv("PULUMI_ENABLE_LEGACY_DIFF"))
 }
 
+// useLowBandwidth returns true if verbose events should be summarized, and their full payloads fetched on demand.
+func useLowBandwidth() bool {
+	return cmdutil.IsTruthy(os.Getenv(httpstate.LowBandwidthEnvVar))
+}
+
+// memoryOptions returns the bounds on the memory an operation uses, which are set by environment variables so that
+// they can be set once for a CI environment.
+func memoryOptions() engine.MemoryOptions {
+	// An unset or invalid limit on diff details means there is no limit.
+	maxDiffDetails, _ := strconv.Atoi(os.Getenv("PULUMI_MAX_DIFF_DETAILS"))
+	return engine.MemoryOptions{
+		InternProperties: cmdutil.IsTruthy(os.Getenv("PULUMI_INTERN_PROPERTIES")),
+		MaxDiffDetails:   maxDiffDetails,
+		StreamSnapshots:  cmdutil.IsTruthy(os.Getenv("PULUMI_STREAM_CHECKPOINTS")),
+		ReportPeakUsage:  cmdutil.IsTruthy(os.Getenv("PULUMI_REPORT_PEAK_MEMORY")),
+	}
+}
+
+var (
+	// mockMode is true if the CLI simulates the lifecycles of resources rather than deploying them, using stacks kept
+	// in memory apart from any backend that the user is logged in to.
+	mockMode bool
+	// mockOptions configures the synthetic providers that simulate resource operations in mock mode.
+	mockOptions plugin.MockOptions
+	// mockStateBackend is the in-memory backend used in mock mode, once it has been created.
+	mockStateBackend backend.Backend
+)
+
+// getMockOptions returns the configuration of the synthetic providers used in mock mode, which is read from the
+// PULUMI_MOCK_LATENCY, PULUMI_MOCK_FAILURE_RATE, and PULUMI_MOCK_FAIL environment variables.
+func getMockOptions() (plugin.MockOptions, error) {
+	opts := plugin.MockOptions{Latency: time.Second}
+	if s := os.Getenv("PULUMI_MOCK_LATENCY"); s != "" {
+		latency, err := time.ParseDuration(s)
+		if err != nil {
+			return plugin.MockOptions{}, errors.Wrap(err, "parsing PULUMI_MOCK_LATENCY")
+		}
+		opts.Latency = latency
+	}
+	if s := os.Getenv("PULUMI_MOCK_FAILURE_RATE"); s != "" {
+		rate, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return plugin.MockOptions{}, errors.Wrap(err, "parsing PULUMI_MOCK_FAILURE_RATE")
+		}
+		if rate < 0 || rate > 1 {
+			return plugin.MockOptions{}, errors.New("PULUMI_MOCK_FAILURE_RATE must be between 0 and 1")
+		}
+		opts.FailureRate = rate
+	}
+	for _, fail := range strings.Split(os.Getenv("PULUMI_MOCK_FAIL"), ",") {
+		if fail = strings.TrimSpace(fail); fail != "" {
+			opts.Fail = append(opts.Fail, fail)
+		}
+	}
+	return opts, nil
+}
+
+// mockBackend returns the built-in backend used in mock mode, which keeps the state of its stacks in memory for as
+// long as the command runs.
+func mockBackend() (backend.Backend, error) {
+	if mockStateBackend == nil {
+		b, err := withStateSecretsManagers(filestate.NewMock(cmdutil.Diag(), mockOptions), nil)
+		if err != nil {
+			return nil, err
+		}
+		mockStateBackend = b
+	}
+	return mockStateBackend, nil
+}
+
 func currentBackend(opts display.Options) (backend.Backend, error) {
+	if mockMode {
+		return mockBackend()
+	}
+
 	url, err := workspace.GetCurrentCloudURL()
 	if err != nil {
 		return nil, errors.Wrapf(err, "could not get cloud url")
 	}
 
 	if filestate.IsFileStateBackendURL(url) {
-		return filestate.New(cmdutil.Diag(), url)
+		return withStateSecretsManagers(filestate.New(cmdutil.Diag(), url))
+	}
+	return withStateSecretsManagers(httpstate.Login(commandContext(), cmdutil.Diag(), url, opts))
+}
+
+// loginToBackend logs in to the backend at the given URL, making it the current backend.
+func loginToBackend(url string, opts display.Options) (backend.Backend, error) {
+	if filestate.IsFileStateBackendURL(url) {
+		return withStateSecretsManagers(filestate.Login(cmdutil.Diag(), url))
 	}
-	return httpstate.Login(commandContext(), cmdutil.Diag(), url, opts)
+	return withStateSecretsManagers(httpstate.Login(commandContext(), cmdutil.Diag(), url, opts))
+}
+
+// withStateSecretsManagers lets the given backend decrypt the state of its stacks that is encrypted at rest, with the
+// key of the secrets manager each stack is configured with.
+func withStateSecretsManagers(b backend.Backend, err error) (backend.Backend, error) {
+	if sb, ok := b.(backend.StateEncryptionBackend); ok && err == nil {
+		sb.SetStateSecretsManagerSource(getStackSecretsManager)
+	}
+	return b, err
+}
+
+// restoreCurrentBackend makes the backend with the given URL the current one again, keeping any credentials stored
+// since it was current.
+func restoreCurrentBackend(url string) error {
+	creds, err := workspace.GetStoredCredentials()
+	if err != nil {
+		return err
+	}
+	creds.Current = url
+	return workspace.StoreCredentials(creds)
 }
 
 // This is used to control the contents of the tracing header.
@@ -95,21 +207,9 @@ func createStack(
 	b backend.Backend, stackRef backend.StackReference, opts interface{}, setCurrent bool,
 	secretsProvider string) (backend.Stack, error) {
 
-	// As part of creating the stack, we also need to configure the secrets provider for the stack. Today, we only
-	// have to do this configuration step when you are using the passphrase provider (which is used for all filestate,
-	// stacks and well as httpstate stacks that opted into this by passing --secrets-provider passphrase
-	// while initialing a stack).  The only other supported provider today (the provider that uses the pulumi service
-	// does not need to be initialized explicitly, as creating the stack inside the Pulumi service does this).
-	if _, ok := b.(filestate.Backend); ok || secretsProvider == "passphrase" {
-		if _, pharseErr := newPassphraseSecretsManager(stackRef.Name(), stackConfigFile); pharseErr != nil {
-			return nil, pharseErr
-		}
-	} else if secretsProvider != "" && secretsProvider != "default" {
-		// All other non-default secrets providers are handled by the cloud secrets provider which
-		// uses a URL schema to identify the provider
-		if _, secretsErr := newCloudSecretsManager(stackRef.Name(), stackConfigFile, secretsProvider); secretsErr != nil {
-			return nil, secretsErr
-		}
+	// As part of creating the stack, we also need to configure the secrets provider for the stack.
+	if err := initSecretsProvider(b, stackRef.Name(), secretsProvider); err != nil {
+		return nil, err
 	}
 
 	stack, err := b.CreateStack(commandContext(), stackRef, opts)
@@ -130,6 +230,20 @@ func createStack(
 	return stack, nil
 }
 
+// initSecretsProvider configures the given secrets provider in the configuration file of the stack with the given
+// name, which is managed by the given backend.
+func initSecretsProvider(b backend.Backend, stackName tokens.QName, secretsProvider string) error {
+	configFile := stackConfigFile
+	if configFile == "" {
+		f, err := workspace.DetectProjectStackPath(stackName)
+		if err != nil {
+			return err
+		}
+		configFile = f
+	}
+	return stacksecrets.Init(b, configFile, secretsProvider, newPassphraseSecretsManager)
+}
+
 // requireStack will require that a stack exists.  If stackName is blank, the currently selected stack from
 // the workspace is returned.  If no stack with either the given name, or a currently selected stack, exists,
 // and we are in an interactive terminal, the user will be prompted to create a new stack.
@@ -157,6 +271,11 @@ func requireStack(
 		return stack, err
 	}
 
+	// Stacks in mock mode only last as long as the command, so create the stack without asking.
+	if offerNew && mockMode {
+		return createStack(b, stackRef, nil, setCurrent, "")
+	}
+
 	// No stack was found.  If we're in a terminal, prompt to create one.
 	if offerNew && cmdutil.Interactive() {
 		fmt.Printf("The stack '%s' does not exist.\n", stackName)
@@ -186,6 +305,19 @@ func requireCurrentStack(offerNew bool, opts display.Options, setCurrent bool) (
 		return stack, nil
 	}
 
+	// In mock mode, create the selected stack, or one named "dev" if none is selected.
+	if offerNew && mockMode {
+		w, err := workspace.New()
+		if err != nil {
+			return nil, err
+		}
+		stackName := w.Settings().Stack
+		if stackName == "" {
+			stackName = "dev"
+		}
+		return requireStack(stackName, offerNew, opts, setCurrent)
+	}
+
 	// If no current stack exists, and we are interactive, prompt to select or create one.
 	return chooseStack(b, offerNew, opts, setCurrent)
 }
@@ -195,14 +327,18 @@ func requireCurrentStack(offerNew bool, opts display.Options, setCurrent bool) (
 func chooseStack(
 	b backend.Backend, offerNew bool, opts display.Options, setCurrent bool) (backend.Stack, error) {
 	// Prepare our error in case we need to issue it.  Bail early if we're not interactive.
-	var chooseStackErr string
+	chooseStackErr := i18n.Errorf("no stack selected; please use `pulumi stack select` to choose one")
 	if offerNew {
-		chooseStackErr = "no stack selected; please use `pulumi stack select` or `pulumi stack init` to choose one"
-	} else {
-		chooseStackErr = "no stack selected; please use `pulumi stack select` to choose one"
+		chooseStackErr = i18n.Errorf(
+			"no stack selected; please use `pulumi stack select` or `pulumi stack init` to choose one")
 	}
-	if !cmdutil.Interactive() {
-		return nil, errors.New(chooseStackErr)
+	if reason := cmdutil.NonInteractiveReason(); reason != "" {
+		if offerNew {
+			return nil, i18n.Errorf("no stack selected, but prompting is not possible because %s; please use "+
+				"`pulumi stack select` or `pulumi stack init` to choose one", reason)
+		}
+		return nil, i18n.Errorf("no stack selected, but prompting is not possible because %s; please use "+
+			"`pulumi stack select` to choose one", reason)
 	}
 
 	proj, err := workspace.DetectProject()
@@ -212,7 +348,7 @@ func chooseStack(
 
 	// List stacks as available options.
 	project := string(proj.Name)
-	summaries, err := b.ListStacks(commandContext(), backend.ListStacksFilter{Project: &project})
+	summaries, err := b.ListStacks(commandContext(), backend.ListStacksFilter{Project: &project, NamesOnly: true})
 	if err != nil {
 		return nil, errors.Wrapf(err, "could not query backend for stacks")
 	}
@@ -259,7 +395,7 @@ func chooseStack(
 		Options: options,
 		Default: current,
 	}, &option, nil); err != nil {
-		return nil, errors.New(chooseStackErr)
+		return nil, chooseStackErr
 	}
 
 	if option == newOption {
@@ -400,6 +536,77 @@ func getUpdateMetadata(msg, root string) (*backend.UpdateMetadata, error) {
 	return m, nil
 }
 
+// checkUpdateMessage enforces the update message policies that apply to the stack on the update's message: that of the
+// stack, or else of the project, and, for stacks managed by the Pulumi service, that of the stack's organization.
+// Interactive sessions are prompted for a compliant message; otherwise, a non-compliant message is an error.
+func checkUpdateMessage(proj *workspace.Project, s backend.Stack, m *backend.UpdateMetadata) error {
+	var policies []*workspace.UpdateMessagePolicy
+	ps, err := loadProjectStack(s)
+	if err != nil {
+		return err
+	}
+	if ps.UpdateMessage != nil {
+		policies = append(policies, ps.UpdateMessage)
+	} else if proj.UpdateMessage != nil {
+		policies = append(policies, proj.UpdateMessage)
+	}
+	if cloudStack, ok := s.(httpstate.Stack); ok {
+		client := cloudStack.Backend().(httpstate.Backend).Client()
+		orgPolicy, err := client.GetUpdateMessagePolicy(commandContext(), cloudStack.StackIdentifier().Owner)
+		if err != nil {
+			return errors.Wrap(err, "getting the organization's update message policy")
+		}
+		if orgPolicy != nil {
+			policies = append(policies, &workspace.UpdateMessagePolicy{
+				Pattern:     orgPolicy.Pattern,
+				Description: orgPolicy.Description,
+			})
+		}
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+
+	check := func(msg string) error {
+		for _, policy := range policies {
+			if err := policy.Check(msg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	err = check(m.Message)
+	if err == nil {
+		return nil
+	}
+	if !cmdutil.Interactive() {
+		return errors.Wrap(err, "pass a compliant message with --message")
+	}
+
+	prompt := err.Error() + "\nUpdate message:"
+	prompt = cmdutil.GetGlobalColorization().Colorize(colors.SpecPrompt + prompt + colors.Reset)
+	var msg string
+	if err = survey.AskOne(&survey.Input{Message: prompt}, &msg, func(ans interface{}) error {
+		return check(ans.(string))
+	}); err != nil {
+		return errors.Wrap(err, "no update message given")
+	}
+	m.Message = msg
+	return nil
+}
+
+// checkFreezeWindows refuses to update the stack during any of its freeze windows, unless the freeze is broken with a
+// justification, in which case the justification is recorded in the update's metadata.
+func checkFreezeWindows(s backend.Stack, m *backend.UpdateMetadata, justification string) error {
+	err := httpstate.CheckFreezeWindows(commandContext(), s, m, justification)
+	if _, ok := err.(httpstate.FreezeError); ok {
+		return errors.New(err.Error() + "\nTo update the stack anyway, pass a justification with --break-freeze; " +
+			"it is recorded with the update")
+	}
+	return err
+}
+
 // addGitMetadata populate's the environment metadata bag with Git-related values.
 func addGitMetadata(repoRoot string, m *backend.UpdateMetadata) error {
 	var allErrors *multierror.Error
@@ -624,16 +831,183 @@ func printJSON(v interface{}) error {
 	return nil
 }
 
+var (
+	// accessibleDisplay is true if updates should be displayed as plain, linear text for screen readers.
+	accessibleDisplay bool
+	// displayVerbosity controls how much detail the accessible display reports.
+	displayVerbosity display.Verbosity
+)
+
+// getDisplayType returns the type of display to use for an update, given the value of its --diff flag.
+func getDisplayType(diffDisplay bool) display.Type {
+	switch {
+	case accessibleDisplay:
+		return display.DisplayAccessible
+	case diffDisplay:
+		return display.DisplayDiff
+	default:
+		return display.DisplayProgress
+	}
+}
+
+// openEventLog creates the file at the given path and sets it as the display's event log, to which each of the
+// operation's engine events is written as a line of JSON. The returned function closes the file. If the path is
+// empty, there is no event log.
+func openEventLog(path string, opts *display.Options) (func(), error) {
+	if path == "" {
+		return func() {}, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating event log")
+	}
+	opts.EventLog = f
+	return func() { contract.IgnoreClose(f) }, nil
+}
+
+// openDisplayPlugins creates the display plugins chosen by the given specs, each of the form `name[=arg]`, and sets
+// them as the display's renderers, which receive the operation's engine events alongside the display. The returned
+// function closes the plugins, reporting any that fail as warnings.
+func openDisplayPlugins(specs []string, opts *display.Options) (func(), error) {
+	var renderers []display.Renderer
+	closeAll := func() {
+		for _, r := range renderers {
+			if err := r.Close(); err != nil {
+				cmdutil.Diag().Warningf(diag.Message("", err.Error()))
+			}
+		}
+	}
+	for _, spec := range specs {
+		r, err := display.NewRenderer(spec)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		renderers = append(renderers, r)
+	}
+	opts.Renderers = renderers
+	return closeAll, nil
+}
+
+// yesFlagPreviewOK is the value of --yes that approves only updates whose preview is free of deletes and replaces.
+const yesFlagPreviewOK = "preview-ok"
+
+// yesFlag is the value of the --yes flag. A plain --yes approves any update after previewing it, while
+// --yes=preview-ok approves only updates whose preview neither deletes nor replaces resources.
+type yesFlag struct {
+	approve  bool // true if updates may be approved automatically.
+	safeOnly bool // true if only updates without deletes or replaces may be approved automatically.
+}
+
+func (y *yesFlag) String() string {
+	if y.safeOnly {
+		return yesFlagPreviewOK
+	}
+	return strconv.FormatBool(y.approve)
+}
+
+func (y *yesFlag) Set(v string) error {
+	if v == yesFlagPreviewOK {
+		y.approve, y.safeOnly = true, true
+		return nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return errors.Errorf("must be true, false, or %s", yesFlagPreviewOK)
+	}
+	y.approve, y.safeOnly = b, false
+	return nil
+}
+
+// Type is "bool" so that the flag's usage reads like that of a switch.
+func (y *yesFlag) Type() string {
+	return "bool"
+}
+
+// addYesFlag adds the --yes flag for the given kind of operation to cmd.
+func addYesFlag(cmd *cobra.Command, yes *yesFlag, op string) {
+	flag := cmd.PersistentFlags().VarPF(yes, "yes", "y", fmt.Sprintf(
+		"Automatically approve and perform the %s after previewing it; with --yes=%s, only if the preview "+
+			"neither deletes nor replaces resources", op, yesFlagPreviewOK))
+	flag.NoOptDefVal = "true"
+}
+
+// errYesRequired returns the error reported when an operation needs the user's confirmation, but the CLI cannot prompt
+// for it.
+func errYesRequired(operation string) error {
+	return cmdutil.NonInteractiveError(operation+" requires confirmation", "pass --yes to proceed")
+}
+
+// loadOperationTimings loads the history of how long resource operations have taken. The history only improves the
+// estimates shown by previews, so a history that cannot be loaded is ignored.
+func loadOperationTimings() *workspace.OperationTimings {
+	timings, err := workspace.GetOperationTimings("")
+	if err != nil {
+		logging.V(5).Infof("could not load operation timings: %v", err)
+		return nil
+	}
+	return timings
+}
+
+// saveOperationTimings saves the history of how long resource operations have taken, if there is one.
+func saveOperationTimings(timings *workspace.OperationTimings) {
+	if timings == nil {
+		return
+	}
+	if err := timings.Save(); err != nil {
+		logging.V(5).Infof("could not save operation timings: %v", err)
+	}
+}
+
+// targetURNs converts the resource URNs given with --target flags to the engine's update targets.
+func targetURNs(targets []string) []resource.URN {
+	var urns []resource.URN
+	for _, t := range targets {
+		urns = append(urns, resource.URN(t))
+	}
+	return urns
+}
+
+// writeUpdatePlan saves the given plan to the file at the given path.
+func writeUpdatePlan(path string, plan *engine.UpdatePlan) error {
+	versioned, err := engine.SerializeUpdatePlan(plan)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(versioned, "", "    ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0600)
+}
+
+// readUpdatePlan loads the plan saved in the file at the given path.
+func readUpdatePlan(path string) (*engine.UpdatePlan, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var versioned apitype.VersionedUpdatePlan
+	if err = json.Unmarshal(b, &versioned); err != nil {
+		return nil, errors.Wrapf(err, "could not parse %s", path)
+	}
+	return engine.DeserializeUpdatePlan(&versioned)
+}
+
 // updateFlagsToOptions ensures that the given update flags represent a valid combination.  If so, an UpdateOptions
 // is returned with a nil-error; otherwise, the non-nil error contains information about why the combination is invalid.
-func updateFlagsToOptions(interactive, skipPreview, yes bool) (backend.UpdateOptions, error) {
-	if !interactive && !yes {
+func updateFlagsToOptions(interactive, skipPreview bool, yes yesFlag) (backend.UpdateOptions, error) {
+	if !interactive && !yes.approve {
+		return backend.UpdateOptions{}, errYesRequired("approving the update")
+	}
+	if skipPreview && yes.safeOnly {
 		return backend.UpdateOptions{},
-			errors.New("--yes must be passed in non-interactive mode")
+			errors.Errorf("--yes=%s cannot be used with --skip-preview", yesFlagPreviewOK)
 	}
 
 	return backend.UpdateOptions{
-		AutoApprove: yes,
-		SkipPreview: skipPreview,
+		AutoApprove:         yes.approve,
+		AutoApproveSafeOnly: yes.safeOnly,
+		SkipPreview:         skipPreview,
 	}, nil
 }
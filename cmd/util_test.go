@@ -14,10 +14,15 @@
 package cmd
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
 	pul_testing "github.com/pulumi/pulumi/pkg/testing"
 	"github.com/pulumi/pulumi/pkg/util/gitutil"
 	"github.com/stretchr/testify/assert"
@@ -226,3 +231,50 @@ func TestReadingGitLabMetadata(t *testing.T) {
 		assertEnvValue(t, test, backend.VCSRepoKind, gitutil.GitLabHostName)
 	}
 }
+
+func TestUpdatePlanFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plan")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "plan.json")
+	assert.NoError(t, writeUpdatePlan(path, engine.NewUpdatePlan()))
+	plan, err := readUpdatePlan(path)
+	if assert.NoError(t, err) {
+		assert.Empty(t, plan.Resources())
+	}
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte("not a plan"), 0600))
+	_, err = readUpdatePlan(path)
+	assert.Error(t, err)
+}
+
+func TestGetMockOptions(t *testing.T) {
+	for _, name := range []string{"PULUMI_MOCK_LATENCY", "PULUMI_MOCK_FAILURE_RATE", "PULUMI_MOCK_FAIL"} {
+		defer os.Setenv(name, os.Getenv(name))
+	}
+
+	os.Setenv("PULUMI_MOCK_LATENCY", "")
+	os.Setenv("PULUMI_MOCK_FAILURE_RATE", "")
+	os.Setenv("PULUMI_MOCK_FAIL", "")
+	opts, err := getMockOptions()
+	assert.NoError(t, err)
+	assert.Equal(t, plugin.MockOptions{Latency: time.Second}, opts)
+
+	os.Setenv("PULUMI_MOCK_LATENCY", "250ms")
+	os.Setenv("PULUMI_MOCK_FAILURE_RATE", "0.5")
+	os.Setenv("PULUMI_MOCK_FAIL", "bucket, aws:s3/bucket:Bucket")
+	opts, err = getMockOptions()
+	assert.NoError(t, err)
+	assert.Equal(t, plugin.MockOptions{
+		Latency:     250 * time.Millisecond,
+		FailureRate: 0.5,
+		Fail:        []string{"bucket", "aws:s3/bucket:Bucket"},
+	}, opts)
+
+	os.Setenv("PULUMI_MOCK_FAILURE_RATE", "2")
+	_, err = getMockOptions()
+	assert.EqualError(t, err, "PULUMI_MOCK_FAILURE_RATE must be between 0 and 1")
+}
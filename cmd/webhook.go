@@ -0,0 +1,375 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/backend/httpstate"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+)
+
+// ngrokAPIURL is the URL of the API with which a running ngrok agent reports its tunnels.
+const ngrokAPIURL = "http://127.0.0.1:4040/api/tunnels"
+
+func newWebhookCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "webhook",
+		Short: "Develop and test webhooks",
+		Long: "Develop and test webhooks\n" +
+			"\n" +
+			"Webhooks notify an HTTP endpoint of the events of an organization's stacks, or of a single\n" +
+			"stack. They are only supported by the Pulumi service.",
+		Args: cmdutil.NoArgs,
+	}
+
+	cmd.AddCommand(newWebhookServeCmd())
+
+	return cmd
+}
+
+func newWebhookServeCmd() *cobra.Command {
+	var printPayloads bool
+	var address string
+	var payloadURL string
+	var tunnel bool
+	var orgName string
+	var stack string
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Receive webhook deliveries locally",
+		Long: "Receive webhook deliveries locally\n" +
+			"\n" +
+			"This command listens for HTTP requests on a local address, and registers a temporary webhook\n" +
+			"that delivers the events of an organization's stacks, or of the stack given by `--stack`, to it.\n" +
+			"Each delivery is written to stdout as a line of JSON, or, with `--print`, pretty-printed along\n" +
+			"with its kind and whether its signature is valid. The webhook is removed when the command is\n" +
+			"interrupted.\n" +
+			"\n" +
+			"Since the Pulumi service can't reach a local address, deliveries must be forwarded to it:\n" +
+			"`--tunnel` runs ngrok to do so, and `--url` gives the public URL of any other tunnel.",
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			if tunnel && payloadURL != "" {
+				return errors.New("only one of --tunnel or --url may be specified, not both")
+			}
+			if stack != "" && orgName != "" {
+				return errors.New("only one of --stack or --org may be specified, not both")
+			}
+
+			listener, err := net.Listen("tcp", address)
+			if err != nil {
+				return errors.Wrap(err, "listening for webhook deliveries")
+			}
+			secret := newWebhookSecret()
+			server := &http.Server{Handler: newWebhookHandler(os.Stdout, secret, printPayloads)}
+			go func() {
+				if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+					logging.V(3).Infof("webhook listener failed: %v", err)
+				}
+			}()
+			defer contract.IgnoreClose(server)
+
+			localURL := "http://" + listener.Addr().String()
+			switch {
+			case tunnel:
+				publicURL, stop, err := startNgrokTunnel(listener.Addr().(*net.TCPAddr).Port)
+				if err != nil {
+					return err
+				}
+				defer stop()
+				payloadURL = publicURL
+			case payloadURL == "":
+				payloadURL = localURL
+			}
+
+			remove, err := registerWebhook(commandContext(), orgName, stack, apitype.CreateWebhookRequest{
+				DisplayName: "pulumi webhook serve",
+				PayloadURL:  payloadURL,
+				Secret:      secret,
+				Active:      true,
+			})
+			if err != nil {
+				return err
+			}
+			defer remove()
+
+			fmt.Fprintf(os.Stderr, "Listening on %s for deliveries to %s; press ^C to stop and remove the webhook.\n",
+				localURL, payloadURL)
+			sigint := make(chan os.Signal, 1)
+			signal.Notify(sigint, os.Interrupt)
+			<-sigint
+			signal.Stop(sigint)
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().BoolVar(
+		&printPayloads, "print", false,
+		"Pretty-print each delivery, rather than writing it as a line of JSON")
+	cmd.PersistentFlags().StringVar(
+		&address, "address", "127.0.0.1:0",
+		"The local address on which to listen for deliveries. Defaults to a free port on the loopback interface")
+	cmd.PersistentFlags().StringVar(
+		&payloadURL, "url", "",
+		"The public URL of a tunnel that forwards requests to the local address, to which to deliver events")
+	cmd.PersistentFlags().BoolVar(
+		&tunnel, "tunnel", false,
+		"Run ngrok to forward deliveries from a public URL to the local address")
+	cmd.PersistentFlags().StringVar(
+		&orgName, "org", "",
+		"The organization whose stacks' events to deliver. Defaults to the current user's organization")
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "",
+		"The name of the stack whose events to deliver, rather than those of all of the organization's stacks")
+
+	return cmd
+}
+
+// newWebhookSecret returns a random secret with which deliveries to a webhook are signed.
+func newWebhookSecret() string {
+	secret := make([]byte, 32)
+	_, err := cryptorand.Read(secret)
+	contract.Assertf(err == nil, "could not read from system random")
+	return hex.EncodeToString(secret)
+}
+
+// registerWebhook creates a webhook for the given stack or, if there is none, for the given organization, and returns
+// a function that deletes it.
+func registerWebhook(ctx context.Context, orgName, stack string,
+	req apitype.CreateWebhookRequest) (func(), error) {
+
+	if stack != "" {
+		pc, stackID, err := requireServiceStackClient(stack, "webhooks")
+		if err != nil {
+			return nil, err
+		}
+		hook, err := pc.CreateStackWebhook(ctx, stackID, req)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating webhook")
+		}
+		return func() {
+			if err := pc.DeleteStackWebhook(context.Background(), stackID, hook.Name); err != nil {
+				fmt.Fprintf(os.Stderr, "error: failed to remove webhook %s: %v\n", hook.Name, err)
+			}
+		}, nil
+	}
+
+	b, err := currentBackend(display.Options{Color: cmdutil.GetGlobalColorization()})
+	if err != nil {
+		return nil, err
+	}
+	cloudBackend, ok := b.(httpstate.Backend)
+	if !ok {
+		return nil, errors.New("webhooks are only supported by the Pulumi service")
+	}
+	if orgName == "" {
+		if orgName, err = b.CurrentUser(); err != nil {
+			return nil, err
+		}
+	}
+	pc := cloudBackend.Client()
+	hook, err := pc.CreateOrganizationWebhook(ctx, orgName, req)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating webhook")
+	}
+	return func() {
+		if err := pc.DeleteOrganizationWebhook(context.Background(), orgName, hook.Name); err != nil {
+			fmt.Fprintf(os.Stderr, "error: failed to remove webhook %s: %v\n", hook.Name, err)
+		}
+	}, nil
+}
+
+// webhookHandler writes each webhook delivery it receives, and checks the delivery's signature.
+type webhookHandler struct {
+	w      io.Writer
+	secret string
+	pretty bool
+	lock   sync.Mutex
+}
+
+// webhookDelivery is a webhook delivery as it is written when it isn't pretty-printed.
+type webhookDelivery struct {
+	Kind           string          `json:"kind"`
+	ID             string          `json:"id"`
+	SignatureValid bool            `json:"signatureValid"`
+	Payload        json.RawMessage `json:"payload"`
+}
+
+func newWebhookHandler(w io.Writer, secret string, pretty bool) *webhookHandler {
+	return &webhookHandler{w: w, secret: secret, pretty: pretty}
+}
+
+func (h *webhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "webhook deliveries must be POSTed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	delivery := webhookDelivery{
+		Kind:           r.Header.Get(apitype.WebhookKindHeader),
+		ID:             r.Header.Get(apitype.WebhookIDHeader),
+		SignatureValid: h.validSignature(body, r.Header.Get(apitype.WebhookSignatureHeader)),
+		Payload:        body,
+	}
+	if !json.Valid(body) {
+		// Keep the output valid JSON even if the payload isn't.
+		payload, err := json.Marshal(string(body))
+		contract.AssertNoError(err)
+		delivery.Payload = payload
+	}
+	if err = h.write(delivery); err != nil {
+		logging.V(3).Infof("failed to write webhook delivery: %v", err)
+	}
+
+	if !delivery.SignatureValid {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// validSignature returns true if the given signature is that of the payload, signed with the handler's secret.
+func (h *webhookHandler) validSignature(payload []byte, signature string) bool {
+	actual, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(h.secret))
+	_, err = mac.Write(payload)
+	contract.AssertNoError(err)
+	return hmac.Equal(actual, mac.Sum(nil))
+}
+
+func (h *webhookHandler) write(delivery webhookDelivery) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if !h.pretty {
+		var line bytes.Buffer
+		if err := json.NewEncoder(&line).Encode(delivery); err != nil {
+			return err
+		}
+		_, err := h.w.Write(line.Bytes())
+		return err
+	}
+
+	signature := "valid signature"
+	if !delivery.SignatureValid {
+		signature = "INVALID SIGNATURE"
+	}
+	var payload bytes.Buffer
+	if err := json.Indent(&payload, delivery.Payload, "    ", "  "); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(h.w, "%s %s event (delivery %s, %s):\n    %s\n\n",
+		time.Now().Format(time.RFC3339), delivery.Kind, delivery.ID, signature, payload.String())
+	return err
+}
+
+// startNgrokTunnel runs ngrok to forward requests from a public URL to the given local port, and returns the public URL
+// and a function that stops ngrok.
+func startNgrokTunnel(port int) (string, func(), error) {
+	ngrok, err := exec.LookPath("ngrok")
+	if err != nil {
+		return "", nil, errors.New("--tunnel requires ngrok, which was not found on the PATH; " +
+			"install it from https://ngrok.com/download, or use --url with another tunnel")
+	}
+
+	cmd := exec.Command(ngrok, "http", strconv.Itoa(port), "--log", "stderr")
+	if err = cmd.Start(); err != nil {
+		return "", nil, errors.Wrap(err, "starting ngrok")
+	}
+	stop := func() {
+		if err := cmd.Process.Kill(); err != nil {
+			logging.V(3).Infof("failed to stop ngrok: %v", err)
+		}
+		_ = cmd.Wait()
+	}
+
+	// ngrok reports the tunnel's URL through its API once the tunnel has been established.
+	for deadline := time.Now().Add(15 * time.Second); ; time.Sleep(250 * time.Millisecond) {
+		publicURL, err := ngrokPublicURL(ngrokAPIURL)
+		if err == nil && publicURL != "" {
+			return publicURL, stop, nil
+		}
+		if time.Now().After(deadline) {
+			stop()
+			if err == nil {
+				err = errors.New("no HTTPS tunnel was established")
+			}
+			return "", nil, errors.Wrap(err, "waiting for ngrok")
+		}
+	}
+}
+
+// ngrokPublicURL returns the public URL of the HTTPS tunnel reported by the ngrok API at the given URL, or "" if there
+// is no such tunnel yet.
+func ngrokPublicURL(apiURL string) (string, error) {
+	resp, err := http.Get(apiURL)
+	if err != nil {
+		return "", err
+	}
+	defer contract.IgnoreClose(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("ngrok API returned %s", resp.Status)
+	}
+
+	var tunnels struct {
+		Tunnels []struct {
+			PublicURL string `json:"public_url"`
+			Proto     string `json:"proto"`
+		} `json:"tunnels"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&tunnels); err != nil {
+		return "", errors.Wrap(err, "decoding ngrok tunnels")
+	}
+	for _, t := range tunnels.Tunnels {
+		if t.Proto == "https" {
+			return t.PublicURL, nil
+		}
+	}
+	return "", nil
+}
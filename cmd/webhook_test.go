@@ -0,0 +1,93 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	deliver := func(h http.Handler, payload, secret string) int {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(payload))
+		req.Header.Set(apitype.WebhookKindHeader, "update")
+		req.Header.Set(apitype.WebhookIDHeader, "1")
+		mac := hmac.New(sha256.New, []byte(secret))
+		_, err := mac.Write([]byte(payload))
+		assert.NoError(t, err)
+		req.Header.Set(apitype.WebhookSignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Each delivery is written as a line of JSON, and those with invalid signatures are rejected.
+	var out bytes.Buffer
+	h := newWebhookHandler(&out, "secret", false)
+	assert.Equal(t, http.StatusOK, deliver(h, `{"stackName":"dev"}`, "secret"))
+	assert.Equal(t, http.StatusUnauthorized, deliver(h, "not json", "other"))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if assert.Len(t, lines, 2) {
+		var delivery webhookDelivery
+		assert.NoError(t, json.Unmarshal([]byte(lines[0]), &delivery))
+		assert.Equal(t, "update", delivery.Kind)
+		assert.Equal(t, "1", delivery.ID)
+		assert.True(t, delivery.SignatureValid)
+		assert.JSONEq(t, `{"stackName":"dev"}`, string(delivery.Payload))
+
+		assert.NoError(t, json.Unmarshal([]byte(lines[1]), &delivery))
+		assert.False(t, delivery.SignatureValid)
+		assert.Equal(t, `"not json"`, string(delivery.Payload))
+	}
+
+	// Pretty-printed deliveries describe the event and indent its payload.
+	out.Reset()
+	h = newWebhookHandler(&out, "secret", true)
+	assert.Equal(t, http.StatusOK, deliver(h, `{"stackName":"dev"}`, "secret"))
+	assert.Contains(t, out.String(), "update event (delivery 1, valid signature):")
+	assert.Contains(t, out.String(), `"stackName": "dev"`)
+}
+
+func TestNgrokPublicURL(t *testing.T) {
+	tunnels := `{"tunnels":[]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(tunnels))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	url, err := ngrokPublicURL(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "", url)
+
+	tunnels = `{"tunnels":[{"public_url":"http://a.ngrok.io","proto":"http"},` +
+		`{"public_url":"https://a.ngrok.io","proto":"https"}]}`
+	url, err = ngrokPublicURL(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://a.ngrok.io", url)
+}
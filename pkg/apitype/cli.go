@@ -19,3 +19,25 @@ type CLIVersionResponse struct {
 	LatestVersion        string `json:"latestVersion"`
 	OldestWithoutWarning string `json:"oldestWithoutWarning"`
 }
+
+// CrashReportPlugin describes a plugin installed at the time of a crash.
+type CrashReportPlugin struct {
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`
+	Version string `json:"version,omitempty"`
+}
+
+// CrashReport describes a crash of the CLI. Secret configuration values are redacted before the report is written.
+type CrashReport struct {
+	Time         int64               `json:"time"`
+	Version      string              `json:"version"`
+	GoVersion    string              `json:"goVersion"`
+	OS           string              `json:"os"`
+	Arch         string              `json:"arch"`
+	Command      string              `json:"command,omitempty"`
+	Panic        string              `json:"panic"`
+	Stack        string              `json:"stack"`
+	RecentEvents []string            `json:"recentEvents,omitempty"`
+	Config       map[string]string   `json:"config,omitempty"`
+	Plugins      []CrashReportPlugin `json:"plugins,omitempty"`
+}
@@ -46,9 +46,21 @@ const (
 
 // VersionedCheckpoint is a version number plus a json document. The version number describes what
 // version of the Checkpoint structure the Checkpoint member's json document can decode into.
+//
+// If the checkpoint is encrypted at rest, Encrypted holds it in place of the Checkpoint member.
 type VersionedCheckpoint struct {
-	Version    int             `json:"version"`
-	Checkpoint json.RawMessage `json:"checkpoint"`
+	Version    int                    `json:"version"`
+	Checkpoint json.RawMessage        `json:"checkpoint,omitempty"`
+	Encrypted  *EncryptedCheckpointV1 `json:"encrypted,omitempty"`
+}
+
+// EncryptedCheckpointV1 is a checkpoint's or deployment's json document, encrypted with a key from the stack's secrets
+// provider so that it can be stored at rest without the backend's storage being able to read it.
+type EncryptedCheckpointV1 struct {
+	// SecretsProviders describes the secrets provider whose key the checkpoint is encrypted with.
+	SecretsProviders SecretsProvidersV1 `json:"secrets_providers"`
+	// Ciphertext is the encrypted json document.
+	Ciphertext string `json:"ciphertext"`
 }
 
 // CheckpointV1 is a serialized deployment target plus a record of the latest deployment.
@@ -164,6 +176,8 @@ type UntypedDeployment struct {
 	// permit round-tripping of stack contents when an older client is talking to a newer server.  If we unmarshaled
 	// the contents, and then remarshaled them, we could end up losing important information.
 	Deployment json.RawMessage `json:"deployment,omitempty"`
+	// Encrypted holds the deployment in place of the Deployment member if it is encrypted at rest.
+	Encrypted *EncryptedCheckpointV1 `json:"encrypted,omitempty"`
 }
 
 // ResourceV1 describes a Cloud resource constructed by Pulumi.
@@ -267,6 +281,10 @@ type ResourceV3 struct {
 	Parent resource.URN `json:"parent,omitempty" yaml:"parent,omitempty"`
 	// Protect is set to true when this resource is "protected" and may not be deleted.
 	Protect bool `json:"protect,omitempty" yaml:"protect,omitempty"`
+	// Locked is set to true when this resource is "locked" and may be neither modified nor deleted.
+	Locked bool `json:"locked,omitempty" yaml:"locked,omitempty"`
+	// RetainOnDelete is set to true when deleting this resource should remove it from the state without deleting it.
+	RetainOnDelete bool `json:"retainOnDelete,omitempty" yaml:"retainOnDelete,omitempty"`
 	// External is set to true when the lifecycle of this resource is not managed by Pulumi.
 	External bool `json:"external,omitempty" yaml:"external,omitempty"`
 	// Dependencies contains the dependency edges to other resources that this depends on.
@@ -323,6 +341,9 @@ type ConfigValue struct {
 	String string `json:"string"`
 	// Secret is true if this value is a secret and false otherwise.
 	Secret bool `json:"secret"`
+	// Object is true if this value is a structured value, in which case String is its JSON representation and the
+	// secrets within it are objects of the form {"secure": <ciphertext>}.
+	Object bool `json:"object,omitempty"`
 }
 
 // StackTagName is the key for the tags bag in stack. This is just a string, but we use a type alias to provide a richer
@@ -363,6 +384,12 @@ type Stack struct {
 	ActiveUpdate string                  `json:"activeUpdate"`
 	Resources    []ResourceV1            `json:"resources,omitempty"`
 	Tags         map[StackTagName]string `json:"tags,omitempty"`
+	// TagsETag is an entity tag that changes whenever the stack's tags do, if reported by the service. It may be
+	// passed in an If-Match header to change the tags only if they have not changed since they were read.
+	TagsETag string `json:"tagsETag,omitempty"`
+
+	// Permission is the calling user's permission on the stack, or nil if the service does not report it.
+	Permission *StackPermission `json:"permission,omitempty"`
 
 	Version int `json:"version"`
 }
@@ -20,6 +20,10 @@ package apitype
 //
 // The types aren't versioned in the same manner as Resource, Deployment, and Checkpoint (see
 // apitype/migrate). So care must be taken if these are ever returned from the service to the CLI.
+//
+// The CLI also writes these events to the file given by the `--event-log` flag of `pulumi up`, `preview`, `refresh`,
+// and `destroy`, as newline-delimited JSON: each line is one EngineEvent. Sequence numbers start at zero for each
+// operation, so the log of an update that is previewed first holds the preview's events followed by the update's.
 
 // CancelEvent is emitted when the user initiates a cancellation of the update in progress, or
 // the update successfully completes.
@@ -58,6 +62,20 @@ type PolicyEvent struct {
 	EnforcementLevel string `json:"enforcementLevel"`
 }
 
+// PolicyRemediationEvent is emitted whenever a policy remediates a resource, modifying its inputs.
+type PolicyRemediationEvent struct {
+	ResourceURN       string `json:"resourceUrn,omitempty"`
+	Message           string `json:"message"`
+	Color             string `json:"color"`
+	PolicyName        string `json:"policyName"`
+	PolicyPackName    string `json:"policyPackName"`
+	PolicyPackVersion string `json:"policyPackVersion"`
+
+	// Before and After are the resource's inputs before and after the remediation.
+	Before map[string]interface{} `json:"before,omitempty"`
+	After  map[string]interface{} `json:"after,omitempty"`
+}
+
 // PreludeEvent is emitted at the start of an update.
 type PreludeEvent struct {
 	// Config contains the keys and values for the update.
@@ -74,6 +92,8 @@ type SummaryEvent struct {
 	// ResourceChanges contains the count for resource change by type. The keys are deploy.StepOp,
 	// which is not exported in this package.
 	ResourceChanges map[string]int `json:"resourceChanges"`
+	// PeakMemoryBytes is the peak heap usage of the update, if it was measured.
+	PeakMemoryBytes uint64 `json:"peakMemoryBytes,omitempty"`
 }
 
 // DiffKind describes the kind of a particular property diff.
@@ -144,6 +164,8 @@ type StepEventStateMetadata struct {
 	Parent string `json:"parent"`
 	// Protect is true to "protect" this resource (protected resources cannot be deleted).
 	Protect bool `json:"protect,omitempty"`
+	// RetainOnDelete is true if deleting this resource only removes it from the state.
+	RetainOnDelete bool `json:"retainOnDelete,omitempty"`
 	// Inputs contains the resource's input properties (as specified by the program). Secrets have
 	// filtered out, and large assets have been replaced by hashes as applicable.
 	Inputs map[string]interface{} `json:"inputs"`
@@ -200,6 +222,8 @@ type EngineEvent struct {
 	ResOutputsEvent  *ResOutputsEvent   `json:"resOutputsEvent,omitempty"`
 	ResOpFailedEvent *ResOpFailedEvent  `json:"resOpFailedEvent,omitempty"`
 	PolicyEvent      *PolicyEvent       `json:"policyEvent,omitempty"`
+
+	PolicyRemediationEvent *PolicyRemediationEvent `json:"policyRemediationEvent,omitempty"`
 }
 
 // EngineEventBatch is a group of engine events.
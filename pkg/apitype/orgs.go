@@ -0,0 +1,133 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apitype
+
+// OrganizationRole is the role of a member of an organization.
+type OrganizationRole string
+
+const (
+	// OrganizationRoleMember may use the organization's stacks according to their teams and stack permissions.
+	OrganizationRoleMember OrganizationRole = "member"
+	// OrganizationRoleAdmin may manage the organization, its members, and its teams, and has access to all of its
+	// stacks.
+	OrganizationRoleAdmin OrganizationRole = "admin"
+)
+
+// Organization is an organization of the Pulumi service, as seen by the current user.
+type Organization struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName,omitempty"`
+	// Role is the current user's role within the organization.
+	Role OrganizationRole `json:"role"`
+	// Members is the number of users who belong to the organization.
+	Members int `json:"members"`
+}
+
+// ListOrganizationsResponse is the response from listing the organizations the current user belongs to.
+type ListOrganizationsResponse struct {
+	Organizations []Organization `json:"organizations"`
+}
+
+// InviteOrganizationMemberRequest is the request to invite a user to join an organization.
+type InviteOrganizationMemberRequest struct {
+	// Email is the email address of the user to invite. The user need not have signed up to the service yet.
+	Email string           `json:"email"`
+	Role  OrganizationRole `json:"role"`
+}
+
+// UpdateOrganizationMemberRequest is the request to change the role of a member of an organization.
+type UpdateOrganizationMemberRequest struct {
+	Role OrganizationRole `json:"role"`
+}
+
+// OrganizationMember is a user who belongs to an organization.
+type OrganizationMember struct {
+	Name string           `json:"name"`
+	Role OrganizationRole `json:"role"`
+}
+
+// UpdateMessagePolicy requires the messages of updates to an organization's stacks to match a template, such as one
+// that includes a ticket ID.
+type UpdateMessagePolicy struct {
+	// Pattern is a regular expression that update messages must match. If empty, the organization has no policy.
+	Pattern string `json:"pattern"`
+	// Description optionally describes the required format to users.
+	Description string `json:"description,omitempty"`
+}
+
+// ListOrganizationMembersResponse is the response from listing the members of an organization.
+type ListOrganizationMembersResponse struct {
+	Members []OrganizationMember `json:"members"`
+}
+
+// Team is a group of an organization's members who are granted access to stacks together.
+type Team struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	// Members lists the names of the team's members. It is only returned when getting a single team.
+	Members []string `json:"members,omitempty"`
+}
+
+// ListOrganizationTeamsResponse is the response from listing the teams of an organization.
+type ListOrganizationTeamsResponse struct {
+	Teams []Team `json:"teams"`
+}
+
+// CreateTeamRequest is the request to create a team within an organization.
+type CreateTeamRequest struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// TeamStackPermission is a team's access to one of its organization's stacks.
+type TeamStackPermission struct {
+	ProjectName string          `json:"projectName"`
+	StackName   string          `json:"stackName"`
+	Permission  StackPermission `json:"permission"`
+}
+
+// GetTeamPermissionsResponse is the response from listing the stacks that a team has been granted access to.
+type GetTeamPermissionsResponse struct {
+	Stacks []TeamStackPermission `json:"stacks"`
+}
+
+// OrgUsageSummary summarizes an organization's use of the Pulumi service over a period of time.
+type OrgUsageSummary struct {
+	// PeriodStart and PeriodEnd are the Unix timestamps of the start and end of the period.
+	PeriodStart int64 `json:"periodStart"`
+	PeriodEnd   int64 `json:"periodEnd"`
+
+	// Stacks is the number of stacks in the organization at the end of the period.
+	Stacks int `json:"stacks"`
+	// ResourcesUnderManagement is the average number of resources managed by the organization's stacks over the
+	// period.
+	ResourcesUnderManagement int `json:"resourcesUnderManagement"`
+	// DeploymentMinutes is the total duration of the organization's updates over the period, in minutes.
+	DeploymentMinutes int `json:"deploymentMinutes"`
+
+	// StackUsage breaks the organization's usage down by stack, for chargeback reporting.
+	StackUsage []StackUsage `json:"stackUsage"`
+}
+
+// StackUsage is a single stack's share of its organization's usage over a period of time.
+type StackUsage struct {
+	ProjectName              string `json:"projectName"`
+	StackName                string `json:"stackName"`
+	ResourcesUnderManagement int    `json:"resourcesUnderManagement"`
+	DeploymentMinutes        int    `json:"deploymentMinutes"`
+}
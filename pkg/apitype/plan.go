@@ -0,0 +1,65 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apitype
+
+import (
+	"encoding/json"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+)
+
+const (
+	// UpdatePlanSchemaVersionCurrent is the current version of the `UpdatePlan` schema.
+	// Any plans newer than this version will be rejected.
+	UpdatePlanSchemaVersionCurrent = 1
+)
+
+// VersionedUpdatePlan is a version number plus a json document. The version number describes what version of the
+// UpdatePlan structure the Plan member's json document can decode into.
+type VersionedUpdatePlan struct {
+	Version int             `json:"version"`
+	Plan    json.RawMessage `json:"plan"`
+}
+
+// UpdatePlanV1 records the operations that a preview found an update of a stack would perform, so that a later update
+// can be constrained to perform only those operations.
+type UpdatePlanV1 struct {
+	// Resources maps the URN of each resource the preview visited to the operations planned for it.
+	Resources map[resource.URN]ResourcePlanV1 `json:"resources"`
+}
+
+// ResourcePlanV1 records the operations planned for a single resource.
+type ResourcePlanV1 struct {
+	// Ops are the step operations planned for the resource, such as "create", "update", or "replace".
+	Ops []string `json:"ops"`
+	// Inputs are the inputs planned for the resource, if it is to be created, updated, or replaced. Secret values are
+	// elided, so that the plan can be shared, and any secret matches them.
+	Inputs map[string]interface{} `json:"inputs,omitempty"`
+	// Unknowns are the names of the planned inputs whose values were not known when the plan was made, and which may
+	// therefore take any value.
+	Unknowns []string `json:"unknowns,omitempty"`
+	// Diffs are the names of the properties that the plan expects to change, if it is to be updated or replaced.
+	Diffs []string `json:"diffs,omitempty"`
+	// Notes are reviewers' notes on the changes planned for the resource, which are shown when the plan is applied.
+	Notes []PlanNoteV1 `json:"notes,omitempty"`
+}
+
+// PlanNoteV1 is a reviewer's note on the changes planned for a resource.
+type PlanNoteV1 struct {
+	// Author is the reviewer who wrote the note, if known.
+	Author string `json:"author,omitempty"`
+	// Message is the text of the note.
+	Message string `json:"message"`
+}
@@ -91,6 +91,9 @@ type GetPolicyPackResponse struct {
 	DisplayName string   `json:"displayName"`
 	Version     int      `json:"version"`
 	Policies    []Policy `json:"policies"`
+
+	// Where the Policy Pack can be downloaded from.
+	PackLocation string `json:"packLocation,omitempty"`
 }
 
 // ApplyPolicyPackRequest is the request to apply a Policy Pack to an organization.
@@ -99,6 +102,43 @@ type ApplyPolicyPackRequest struct {
 	Version int    `json:"version"`
 }
 
+// PolicyPackSummary describes a Policy Pack that has been published to an organization.
+type PolicyPackSummary struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+
+	// Versions are the published versions of the Policy Pack, in ascending order.
+	Versions []int `json:"versions"`
+
+	// AppliedVersion is the version of the Policy Pack that is applied to the organization, or 0 if none is.
+	AppliedVersion int `json:"appliedVersion,omitempty"`
+}
+
+// ListPolicyPacksResponse is the response to listing the Policy Packs published to an organization.
+type ListPolicyPacksResponse struct {
+	PolicyPacks []PolicyPackSummary `json:"policyPacks"`
+}
+
+// PolicyPackVersion describes a published version of a Policy Pack.
+type PolicyPackVersion struct {
+	Version int `json:"version"`
+
+	// Applied is true if this version of the Policy Pack is applied to the organization.
+	Applied bool `json:"applied,omitempty"`
+
+	// Timestamp is when the version was published, in Unix seconds.
+	Timestamp int64 `json:"timestamp"`
+
+	Policies []Policy `json:"policies,omitempty"`
+}
+
+// ListPolicyPackVersionsResponse is the response to listing the published versions of a Policy Pack.
+type ListPolicyPackVersionsResponse struct {
+	Name        string              `json:"name"`
+	DisplayName string              `json:"displayName"`
+	Versions    []PolicyPackVersion `json:"versions"`
+}
+
 // GetStackPolicyPacksResponse is the response to getting the applicable Policy Packs
 // for a particular stack. This allows the CLI to download the packs prior to
 // starting an update.
@@ -106,3 +146,30 @@ type GetStackPolicyPacksResponse struct {
 	// RequiredPolicies is a list of required Policy Packs to run during the update.
 	RequiredPolicies []RequiredPolicy `json:"requiredPolicies,omitempty"`
 }
+
+// PolicyViolation is a record of a resource having violated a policy during an update of a stack.
+type PolicyViolation struct {
+	// ProjectName and StackName identify the stack whose update the violation occurred in.
+	ProjectName string `json:"projectName"`
+	StackName   string `json:"stackName"`
+	// UpdateVersion is the version of the update the violation occurred in, or 0 if it occurred during a preview.
+	UpdateVersion int `json:"updateVersion,omitempty"`
+	// Timestamp is when the violation occurred, in Unix seconds.
+	Timestamp int64 `json:"timestamp"`
+
+	ResourceURN       string           `json:"resourceUrn,omitempty"`
+	PolicyPackName    string           `json:"policyPackName"`
+	PolicyPackVersion string           `json:"policyPackVersion"`
+	PolicyName        string           `json:"policyName"`
+	EnforcementLevel  EnforcementLevel `json:"enforcementLevel"`
+	Message           string           `json:"message"`
+}
+
+// GetPolicyViolationsResponse is a page of the policy violations that have occurred in an organization's stacks,
+// newest first.
+type GetPolicyViolationsResponse struct {
+	Violations []PolicyViolation `json:"violations"`
+
+	// ContinuationToken, if set, may be passed back to fetch the next page of violations.
+	ContinuationToken *string `json:"continuationToken,omitempty"`
+}
@@ -33,6 +33,9 @@ type StackSummary struct {
 // ListStacksResponse returns a set of stack summaries. This call is designed to be inexpensive.
 type ListStacksResponse struct {
 	Stacks []StackSummary `json:"stacks"`
+
+	// ContinuationToken is an opaque value used to fetch the next page of stacks. It is nil on the last page.
+	ContinuationToken *string `json:"continuationToken,omitempty"`
 }
 
 // CreateStackRequest defines the request body for creating a new Stack
@@ -71,9 +74,26 @@ type DecryptValueResponse struct {
 	Plaintext []byte `json:"plaintext"`
 }
 
+// GetSecretsEscrowResponse describes whether an organization allows the CLI to fall back to a locally configured
+// escrow key when the service is unable to encrypt secrets.
+type GetSecretsEscrowResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
 // ExportStackResponse defines the response body for exporting a Stack.
 type ExportStackResponse UntypedDeployment
 
+// RecordSecretsRevealedRequest defines the request body for recording that a stack's secrets were revealed, so that
+// their disclosure appears in the stack's audit log.
+type RecordSecretsRevealedRequest struct {
+	// Command is the CLI command that revealed the secrets, e.g. "stack export".
+	Command string `json:"command"`
+	// Version is the update version whose secrets were revealed, or 0 for the current deployment.
+	Version int `json:"version,omitempty"`
+	// Paths are the paths of the values whose secrets were revealed, or empty if every secret was revealed.
+	Paths []string `json:"paths,omitempty"`
+}
+
 // ImportStackRequest defines the request body for importing a Stack.
 type ImportStackRequest UntypedDeployment
 
@@ -81,3 +101,119 @@ type ImportStackRequest UntypedDeployment
 type ImportStackResponse struct {
 	UpdateID string `json:"updateId"`
 }
+
+// StackPermission is the level of access that a collaborator has to a stack.
+type StackPermission int
+
+const (
+	// StackPermissionNone grants no access to the stack.
+	StackPermissionNone StackPermission = 0
+	// StackPermissionRead grants read-only access to the stack.
+	StackPermissionRead StackPermission = 101
+	// StackPermissionWrite grants access to read and update the stack.
+	StackPermissionWrite StackPermission = 102
+	// StackPermissionAdmin grants full access to the stack, including managing its collaborators.
+	StackPermissionAdmin StackPermission = 103
+)
+
+// StackCollaboratorKind is the kind of principal that has been granted access to a stack.
+type StackCollaboratorKind string
+
+const (
+	// StackCollaboratorUser is an individual user.
+	StackCollaboratorUser StackCollaboratorKind = "user"
+	// StackCollaboratorTeam is a team within the stack's organization.
+	StackCollaboratorTeam StackCollaboratorKind = "team"
+)
+
+// StackCollaborator is a user or team that has been granted access to a stack.
+type StackCollaborator struct {
+	Name       string                `json:"name"`
+	Kind       StackCollaboratorKind `json:"kind"`
+	Permission StackPermission       `json:"permission"`
+}
+
+// ListStackPermissionsResponse is the response from listing the collaborators of a stack.
+type ListStackPermissionsResponse struct {
+	Collaborators []StackCollaborator `json:"collaborators"`
+}
+
+// StackPermissions is the complete set of permissions that have been granted on a stack, keyed by the names of the
+// users and teams they have been granted to.
+type StackPermissions struct {
+	Users map[string]StackPermission `json:"users,omitempty"`
+	Teams map[string]StackPermission `json:"teams,omitempty"`
+}
+
+// GrantStackPermissionRequest is the request to grant a collaborator access to a stack.
+type GrantStackPermissionRequest struct {
+	Permission StackPermission `json:"permission"`
+}
+
+// StackActivityKind is the kind of an entry in a stack's activity feed.
+type StackActivityKind string
+
+const (
+	// StackActivityUpdate records an update, refresh, or destroy of the stack.
+	StackActivityUpdate StackActivityKind = "update"
+	// StackActivityTagChange records a change to the stack's tags.
+	StackActivityTagChange StackActivityKind = "tag-change"
+	// StackActivityPermissionChange records a grant or revocation of access to the stack.
+	StackActivityPermissionChange StackActivityKind = "permission-change"
+)
+
+// StackActivityEvent is an entry in a stack's activity feed.
+type StackActivityEvent struct {
+	Kind StackActivityKind `json:"kind"`
+	// Timestamp is the time of the event, in seconds since the Unix epoch.
+	Timestamp int64 `json:"timestamp"`
+	// User is the login of the user who caused the event.
+	User string `json:"user"`
+	// Message is a human-readable description of the event.
+	Message string `json:"message"`
+	// Version is the version of the update, for update events.
+	Version int `json:"version,omitempty"`
+}
+
+// ListStackActivityResponse is a page of a stack's activity feed, oldest event first.
+type ListStackActivityResponse struct {
+	Events []StackActivityEvent `json:"events"`
+
+	// ContinuationToken is an opaque value used to resume the feed after the returned events. It is returned even if
+	// no events were, so that the feed can be followed as new events occur.
+	ContinuationToken *string `json:"continuationToken,omitempty"`
+}
+
+// FreezeWindow is a period during which updates to a stack, or to all of its organization's stacks, are frozen.
+type FreezeWindow struct {
+	// Start and End are the times the window starts and ends, in seconds since the Unix epoch.
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+	// Reason is a human-readable explanation of the freeze.
+	Reason string `json:"reason,omitempty"`
+	// OrgWide is true if the window applies to all of the organization's stacks, rather than to a single stack.
+	OrgWide bool `json:"orgWide,omitempty"`
+}
+
+// ListFreezeWindowsResponse is the response from listing the freeze windows that apply to a stack.
+type ListFreezeWindowsResponse struct {
+	FreezeWindows []FreezeWindow `json:"freezeWindows"`
+}
+
+// StackSchemaType describes the type of one of a stack's configuration keys or outputs.
+type StackSchemaType struct {
+	// Type is the type of the value: string, integer, number, boolean, array, or object. If it is empty, the value
+	// may have any type.
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+	Secret      bool   `json:"secret,omitempty"`
+}
+
+// StackSchema is the schema of a stack's configuration and outputs, which is published as the stack is updated so that
+// other stacks may check their references to it.
+type StackSchema struct {
+	// Config maps fully qualified configuration keys to their types.
+	Config map[string]StackSchemaType `json:"config,omitempty"`
+	// Outputs maps the names of the stack's outputs to their types.
+	Outputs map[string]StackSchemaType `json:"outputs,omitempty"`
+}
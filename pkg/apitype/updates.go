@@ -85,6 +85,9 @@ type StartUpdateRequest struct {
 	// Tags contains an updated set of Tags for the stack. If non-nil, will replace the current
 	// set of tags associated with the stack.
 	Tags map[StackTagName]string `json:"tags,omitempty"`
+	// MergeTags, if true, merges Tags into the stack's current tags rather than replacing them: each of Tags is set,
+	// and the stack's other tags are left as they are.
+	MergeTags bool `json:"mergeTags,omitempty"`
 }
 
 // StartUpdateResponse is the result of the command to start an update.
@@ -97,6 +100,34 @@ type StartUpdateResponse struct {
 	Token string `json:"token,omitempty"`
 }
 
+// RequestApprovalRequest submits an update's plan for approval by the stack's organization. The update may not be
+// started until the plan is approved.
+type RequestApprovalRequest struct {
+	// Plan is the plan of the update, which records the operations the update will perform.
+	Plan VersionedUpdatePlan `json:"plan"`
+}
+
+// ApprovalStatus is an enum describing the state of an update's request for approval.
+type ApprovalStatus string
+
+const (
+	// ApprovalPending is returned when no approver has decided on the update yet.
+	ApprovalPending ApprovalStatus = "pending"
+	// ApprovalApproved is returned when an approver has approved the update.
+	ApprovalApproved ApprovalStatus = "approved"
+	// ApprovalRejected is returned when an approver has rejected the update.
+	ApprovalRejected ApprovalStatus = "rejected"
+)
+
+// GetApprovalStatusResponse is the state of an update's request for approval.
+type GetApprovalStatusResponse struct {
+	Status ApprovalStatus `json:"status"`
+	// Approver is the user who approved or rejected the update, if any has.
+	Approver string `json:"approver,omitempty"`
+	// Comment is the approver's explanation of their decision, if they gave one.
+	Comment string `json:"comment,omitempty"`
+}
+
 // UpdateEventKind is an enum for the type of update events.
 type UpdateEventKind string
 
@@ -112,6 +143,9 @@ type UpdateEvent struct {
 	Index  string                 `json:"index"`
 	Kind   UpdateEventKind        `json:"kind"`
 	Fields map[string]interface{} `json:"fields"`
+	// Truncated is true if the service elided some of the event's fields because summarized events were requested.
+	// The full event may be fetched by its index.
+	Truncated bool `json:"truncated,omitempty"`
 }
 
 // UpdateStatus is an enum describing the current state during the lifecycle of an update.
@@ -205,6 +239,8 @@ type PatchUpdateCheckpointRequest struct {
 	IsInvalid  bool            `json:"isInvalid"`
 	Version    int             `json:"version"`
 	Deployment json.RawMessage `json:"deployment,omitempty"`
+	// Encrypted holds the deployment in place of the Deployment member if the stack's state is encrypted at rest.
+	Encrypted *EncryptedCheckpointV1 `json:"encrypted,omitempty"`
 }
 
 // AppendUpdateLogEntryRequest defines the body of a request to the append update log entry endpoint of the service API.
@@ -0,0 +1,52 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apitype
+
+// Webhook is an HTTP endpoint to which the Pulumi service delivers notifications of events on an organization's
+// stacks, or on a single stack.
+type Webhook struct {
+	OrganizationName string `json:"organizationName"`
+	// ProjectName and StackName identify the stack whose events the webhook is notified of. They are empty for
+	// webhooks that are notified of the events of all of an organization's stacks.
+	ProjectName string `json:"projectName,omitempty"`
+	StackName   string `json:"stackName,omitempty"`
+
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	// PayloadURL is the URL to which events are delivered.
+	PayloadURL string `json:"payloadUrl"`
+	// Secret, if set, is used to sign the payloads delivered to the webhook. The signature is the hex-encoded
+	// HMAC-SHA256 of the payload, and is sent in the WebhookSignatureHeader header.
+	Secret string `json:"secret,omitempty"`
+	Active bool   `json:"active"`
+}
+
+// CreateWebhookRequest is the request to create a webhook.
+type CreateWebhookRequest struct {
+	DisplayName string `json:"displayName"`
+	PayloadURL  string `json:"payloadUrl"`
+	Secret      string `json:"secret,omitempty"`
+	Active      bool   `json:"active"`
+}
+
+const (
+	// WebhookKindHeader is the header of a webhook delivery that holds the kind of event its payload describes, such
+	// as "stack" or "update".
+	WebhookKindHeader = "Pulumi-Webhook-Kind"
+	// WebhookIDHeader is the header of a webhook delivery that holds the delivery's unique ID.
+	WebhookIDHeader = "Pulumi-Webhook-ID"
+	// WebhookSignatureHeader is the header of a webhook delivery that holds the signature of its payload.
+	WebhookSignatureHeader = "Pulumi-Webhook-Signature"
+)
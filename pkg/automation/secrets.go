@@ -0,0 +1,47 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package automation
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/secrets"
+	"github.com/pulumi/pulumi/pkg/secrets/stacksecrets"
+)
+
+// secretsManager returns the secrets manager of the given stack, whose settings are in the given configuration file.
+// It chooses the same secrets provider as the CLI would.
+func (w *Workspace) secretsManager(s backend.Stack, configPath string) (secrets.Manager, error) {
+	return stacksecrets.Manager(s, configPath, w.passphraseSecretsManager)
+}
+
+// passphraseSecretsManager returns a secrets manager that protects secrets with the workspace's passphrase. If the
+// stack whose settings are in the given configuration file has not used a passphrase before, the passphrase is
+// recorded in the file so that it can be checked later.
+func (w *Workspace) passphraseSecretsManager(configPath string) (secrets.Manager, error) {
+	phrase := w.opts.Passphrase
+	if phrase == "" {
+		env, ok := os.LookupEnv("PULUMI_CONFIG_PASSPHRASE")
+		if !ok {
+			return nil, errors.New("a passphrase is required; set WorkspaceOptions.Passphrase or " +
+				"PULUMI_CONFIG_PASSPHRASE")
+		}
+		phrase = env
+	}
+	return stacksecrets.NewPassphraseManager(configPath, phrase)
+}
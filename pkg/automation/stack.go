@@ -0,0 +1,408 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package automation
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/httpstate"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/secrets/refs"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/cancel"
+	"github.com/pulumi/pulumi/pkg/util/result"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// Stack is a stack of a Workspace's project.
+type Stack struct {
+	ws         *Workspace
+	stack      backend.Stack
+	configPath string
+}
+
+// Name returns the name of the stack.
+func (s *Stack) Name() string {
+	return s.stack.Ref().String()
+}
+
+// Ref returns the backend's reference to the stack.
+func (s *Stack) Ref() backend.StackReference {
+	return s.stack.Ref()
+}
+
+// GetConfig returns the value of the stack's configuration key with the given name, decrypting it if it is a secret.
+// As with the CLI, a key without a namespace is in the namespace of the project.
+func (s *Stack) GetConfig(key string) (string, bool, error) {
+	k, err := s.parseConfigKey(key)
+	if err != nil {
+		return "", false, err
+	}
+	ps, err := workspace.LoadProjectStack(s.configPath)
+	if err != nil {
+		return "", false, err
+	}
+
+	v, ok := ps.Config[k]
+	if !ok {
+		return "", false, nil
+	}
+	var decrypter config.Decrypter = config.NewPanicCrypter()
+	if v.Secure() {
+		sm, err := s.ws.secretsManager(s.stack, s.configPath)
+		if err != nil {
+			return "", false, errors.Wrap(err, "getting secrets manager")
+		}
+		if decrypter, err = sm.Decrypter(); err != nil {
+			return "", false, errors.Wrap(err, "getting configuration decrypter")
+		}
+	}
+	value, err := v.Value(decrypter)
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// SetConfig sets the stack's configuration key with the given name to the given value. If secret is true, the value
+// is encrypted with the stack's secrets provider.
+func (s *Stack) SetConfig(key, value string, secret bool) error {
+	k, err := s.parseConfigKey(key)
+	if err != nil {
+		return err
+	}
+
+	v := config.NewValue(value)
+	if secret {
+		sm, err := s.ws.secretsManager(s.stack, s.configPath)
+		if err != nil {
+			return errors.Wrap(err, "getting secrets manager")
+		}
+		encrypter, err := sm.Encrypter()
+		if err != nil {
+			return errors.Wrap(err, "getting configuration encrypter")
+		}
+		ciphertext, err := config.EncryptValueAt(encrypter, value, config.ValueLocation{ConfigKey: k.String()})
+		if err != nil {
+			return err
+		}
+		v = config.NewSecureValue(ciphertext)
+	}
+
+	// Load the configuration only now, since getting the secrets manager may have updated the file.
+	ps, err := workspace.LoadProjectStack(s.configPath)
+	if err != nil {
+		return err
+	}
+	ps.Config[k] = v
+	return ps.Save(s.configPath)
+}
+
+// RemoveConfig removes the stack's configuration key with the given name, if it is set.
+func (s *Stack) RemoveConfig(key string) error {
+	k, err := s.parseConfigKey(key)
+	if err != nil {
+		return err
+	}
+	ps, err := workspace.LoadProjectStack(s.configPath)
+	if err != nil {
+		return err
+	}
+	delete(ps.Config, k)
+	return ps.Save(s.configPath)
+}
+
+func (s *Stack) parseConfigKey(key string) (config.Key, error) {
+	if !strings.Contains(key, tokens.TokenDelimiter) {
+		key = fmt.Sprintf("%s:%s", s.ws.proj.Name, key)
+	}
+	return config.ParseKey(key)
+}
+
+// Outputs returns the outputs of the stack's latest update. Secret outputs are not decrypted.
+func (s *Stack) Outputs(ctx context.Context) (resource.PropertyMap, error) {
+	// Get the stack afresh, since the state of the stack this one was created with may predate later operations.
+	latest, err := s.ws.backend.GetStack(ctx, s.stack.Ref())
+	if err != nil {
+		return nil, err
+	}
+	if latest == nil {
+		return nil, errors.Errorf("stack %s no longer exists", s.Name())
+	}
+	snap, err := latest.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := stack.GetRootStackResource(snap)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting root stack resource")
+	}
+	if res == nil {
+		return resource.PropertyMap{}, nil
+	}
+	return res.Outputs, nil
+}
+
+// OperationOptions controls an operation on a stack.
+type OperationOptions struct {
+	// Message is recorded with the operation in the stack's history.
+	Message string
+	// Environment is additional metadata about the environment running the operation, such as the commit being
+	// deployed, that is recorded with the operation in the stack's history.
+	Environment map[string]string
+	// BreakFreeze is the justification for running the operation during one of the stack's freeze windows, which is
+	// recorded with it. Without one, operations that change the stack are refused during a freeze.
+	BreakFreeze string
+	// Parallel is the number of resource operations to run at once. If zero, the engine's default is used.
+	Parallel int
+	// Refresh refreshes the stack's state before previewing or updating it.
+	Refresh bool
+	// Debug displays debug output from the program and its providers.
+	Debug bool
+	// PolicyPackPaths are the paths to policy packs to run during the operation.
+	PolicyPackPaths []string
+	// OnEvent, if non-nil, is called with each engine event that the operation produces, such as the start and
+	// completion of each resource operation. It is called from a single goroutine, in the order the events occur.
+	OnEvent func(e engine.Event)
+}
+
+// PreviewResult describes the changes a preview found.
+type PreviewResult struct {
+	// Changes counts the resource operations the update would perform, by kind.
+	Changes engine.ResourceChanges
+}
+
+// UpResult describes the changes an update made.
+type UpResult struct {
+	// Changes counts the resource operations the update performed, by kind.
+	Changes engine.ResourceChanges
+	// Outputs are the stack's outputs after the update.
+	Outputs resource.PropertyMap
+}
+
+// RefreshResult describes the changes a refresh made to the stack's state.
+type RefreshResult struct {
+	// Changes counts the resources whose state was refreshed, by kind of change.
+	Changes engine.ResourceChanges
+}
+
+// DestroyResult describes the resources a destroy deleted.
+type DestroyResult struct {
+	// Changes counts the resource operations the destroy performed, by kind.
+	Changes engine.ResourceChanges
+}
+
+// Preview previews the changes an update of the stack would make.
+func (s *Stack) Preview(ctx context.Context, opts OperationOptions) (PreviewResult, error) {
+	changes, err := s.run(ctx, apitype.PreviewUpdate, opts)
+	return PreviewResult{Changes: changes}, err
+}
+
+// Up updates the stack's resources to match its program.
+func (s *Stack) Up(ctx context.Context, opts OperationOptions) (UpResult, error) {
+	changes, err := s.run(ctx, apitype.UpdateUpdate, opts)
+	if err != nil {
+		return UpResult{Changes: changes}, err
+	}
+	outputs, err := s.Outputs(ctx)
+	if err != nil {
+		return UpResult{Changes: changes}, errors.Wrap(err, "getting stack outputs")
+	}
+	return UpResult{Changes: changes, Outputs: outputs}, nil
+}
+
+// Refresh refreshes the stack's state from the cloud provider.
+func (s *Stack) Refresh(ctx context.Context, opts OperationOptions) (RefreshResult, error) {
+	changes, err := s.run(ctx, apitype.RefreshUpdate, opts)
+	return RefreshResult{Changes: changes}, err
+}
+
+// Destroy deletes all of the stack's resources.
+func (s *Stack) Destroy(ctx context.Context, opts OperationOptions) (DestroyResult, error) {
+	changes, err := s.run(ctx, apitype.DestroyUpdate, opts)
+	return DestroyResult{Changes: changes}, err
+}
+
+// run performs an operation of the given kind on the stack. Canceling the context cancels the operation gracefully,
+// as pressing ^C does in the CLI.
+func (s *Stack) run(ctx context.Context, kind apitype.UpdateKind, opts OperationOptions) (engine.ResourceChanges,
+	error) {
+
+	sm, err := s.ws.secretsManager(s.stack, s.configPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting secrets manager")
+	}
+	cfg, err := s.configuration(sm.Decrypter)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting stack configuration")
+	}
+
+	m := &backend.UpdateMetadata{
+		Message:     opts.Message,
+		Environment: make(map[string]string),
+	}
+	for k, v := range opts.Environment {
+		m.Environment[k] = v
+	}
+	if kind != apitype.PreviewUpdate {
+		if err = httpstate.CheckFreezeWindows(ctx, s.stack, m, opts.BreakFreeze); err != nil {
+			return nil, err
+		}
+	}
+
+	op := backend.UpdateOperation{
+		Proj: s.ws.proj,
+		Root: filepath.Dir(s.ws.projPath),
+		M:    m,
+		Opts: backend.UpdateOptions{
+			Engine: engine.UpdateOptions{
+				LocalPolicyPackPaths: opts.PolicyPackPaths,
+				Parallel:             opts.Parallel,
+				Debug:                opts.Debug,
+				Refresh:              opts.Refresh,
+				Memory:               s.ws.opts.Memory,
+			},
+			Display:     s.ws.displayOptions(),
+			AutoApprove: true,
+			// Guardrails are checked against the plan that an update's preview records, before anything is changed.
+			SkipPreview: cfg.Guardrails == nil,
+		},
+		StackConfiguration: cfg,
+		SecretsManager:     sm,
+		Scopes:             contextScopeSource{ctx: ctx},
+	}
+
+	if opts.OnEvent != nil {
+		events, eventsDone := make(chan engine.Event), make(chan bool)
+		go func() {
+			for e := range events {
+				opts.OnEvent(e)
+			}
+			close(eventsDone)
+		}()
+		op.Events = events
+		defer func() {
+			close(events)
+			<-eventsDone
+		}()
+	}
+
+	var changes engine.ResourceChanges
+	var res result.Result
+	switch kind {
+	case apitype.PreviewUpdate:
+		changes, res = backend.PreviewStack(ctx, s.stack, op)
+	case apitype.UpdateUpdate:
+		changes, res = backend.UpdateStack(ctx, s.stack, op)
+	case apitype.RefreshUpdate:
+		changes, res = backend.RefreshStack(ctx, s.stack, op)
+	case apitype.DestroyUpdate:
+		changes, res = backend.DestroyStack(ctx, s.stack, op)
+	}
+	if res != nil {
+		if res.IsBail() {
+			// The reason for the failure has already been displayed.
+			return changes, errors.Errorf("%s of stack %s failed", kind, s.Name())
+		}
+		return changes, res.Error()
+	}
+	return changes, nil
+}
+
+// configuration returns the stack's configuration for an operation, decrypting secrets with the decrypter returned by
+// the given function.
+func (s *Stack) configuration(decrypter func() (config.Decrypter, error)) (backend.StackConfiguration, error) {
+	ps, err := workspace.LoadProjectStack(s.configPath)
+	if err != nil {
+		return backend.StackConfiguration{}, errors.Wrap(err, "loading stack configuration")
+	}
+
+	// Stacks inherit the project's config values and defaults, and those of their environments, for any keys they do
+	// not set themselves.
+	layers, err := s.ws.proj.StackConfigLayers(s.ws.projPath, ps, s.configPath)
+	if err != nil {
+		return backend.StackConfiguration{}, errors.Wrap(err, "loading stack configuration")
+	}
+	cfg := layers.Merge()
+
+	// If there are no secrets in the configuration, we should never use the decrypter, so it is safe to use one
+	// which panics if it is used.
+	var crypter config.Decrypter = config.NewPanicCrypter()
+	if ps.Config.HasSecureValue() {
+		if crypter, err = decrypter(); err != nil {
+			return backend.StackConfiguration{}, errors.Wrap(err, "getting configuration decrypter")
+		}
+	}
+
+	return backend.StackConfiguration{
+		Config:          refs.ReferenceConfig(cfg),
+		Decrypter:       refs.NewDecrypter(crypter, nil),
+		Transformations: ps.Transformations,
+		NamePrefix:      ps.NamePrefix,
+		NameSuffix:      ps.NameSuffix,
+		Confirmation:    ps.Confirmation,
+		Guardrails:      ps.Guardrails,
+	}, nil
+}
+
+// contextScopeSource is a source of cancellation scopes that are canceled when a context is done.
+type contextScopeSource struct {
+	ctx context.Context
+}
+
+func (src contextScopeSource) NewScope(events chan<- engine.Event, isPreview bool) backend.CancellationScope {
+	cancelContext, cancelSource := cancel.NewContext(context.Background())
+
+	scope := &contextScope{
+		context: cancelContext,
+		closed:  make(chan bool),
+		done:    make(chan bool),
+	}
+	go func() {
+		defer close(scope.done)
+		select {
+		case <-src.ctx.Done():
+			cancelSource.Cancel()
+		case <-scope.closed:
+		}
+	}()
+	return scope
+}
+
+// contextScope is a cancellation scope that is canceled when a context is done, until the scope is closed.
+type contextScope struct {
+	context *cancel.Context
+	closed  chan bool
+	done    chan bool
+}
+
+func (s *contextScope) Context() *cancel.Context {
+	return s.context
+}
+
+func (s *contextScope) Close() {
+	close(s.closed)
+	<-s.done
+}
@@ -0,0 +1,224 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package automation
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pbempty "github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/util/rpcutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+	"github.com/pulumi/pulumi/sdk/go/pulumi"
+	pulumirpc "github.com/pulumi/pulumi/sdk/proto/go"
+)
+
+// testRuntime is the runtime of the projects these tests operate on. The test binary serves as its language host,
+// running testProgram in-process, when it is run under the name of that language host's plugin.
+const testRuntime = "automationtest"
+
+func TestMain(m *testing.M) {
+	if filepath.Base(os.Args[0]) == "pulumi-language-"+testRuntime {
+		serveTestLanguageHost()
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// testProgram is the program of the projects these tests operate on. It registers a single component, which needs no
+// provider, and exports an output.
+func testProgram(ctx *pulumi.Context) error {
+	if _, err := ctx.RegisterResource("test:index:Component", "component", false, nil); err != nil {
+		return err
+	}
+	ctx.Export("greeting", "hello")
+	return nil
+}
+
+// serveTestLanguageHost serves the language host of the test runtime, as the language host plugins do.
+func serveTestLanguageHost() {
+	port, done, err := rpcutil.Serve(0, nil, []func(*grpc.Server) error{
+		func(srv *grpc.Server) error {
+			pulumirpc.RegisterLanguageRuntimeServer(srv, testLanguageHost{})
+			return nil
+		},
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not start language host RPC server: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("%d\n", port)
+	if err := <-done; err != nil {
+		os.Exit(1)
+	}
+}
+
+// testLanguageHost runs testProgram in-process.
+type testLanguageHost struct{}
+
+func (testLanguageHost) GetRequiredPlugins(ctx context.Context,
+	req *pulumirpc.GetRequiredPluginsRequest) (*pulumirpc.GetRequiredPluginsResponse, error) {
+	return &pulumirpc.GetRequiredPluginsResponse{}, nil
+}
+
+func (testLanguageHost) Run(ctx context.Context, req *pulumirpc.RunRequest) (*pulumirpc.RunResponse, error) {
+	pctx, err := pulumi.NewContext(ctx, pulumi.RunInfo{
+		Project:     req.GetProject(),
+		Stack:       req.GetStack(),
+		Config:      req.GetConfig(),
+		Parallel:    int(req.GetParallel()),
+		DryRun:      req.GetDryRun(),
+		MonitorAddr: req.GetMonitorAddress(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer pctx.Close()
+
+	if err = pulumi.RunWithContext(pctx, testProgram); err != nil {
+		return &pulumirpc.RunResponse{Error: err.Error()}, nil
+	}
+	return &pulumirpc.RunResponse{}, nil
+}
+
+func (testLanguageHost) GetPluginInfo(ctx context.Context, req *pbempty.Empty) (*pulumirpc.PluginInfo, error) {
+	return &pulumirpc.PluginInfo{}, nil
+}
+
+// useTestLanguageHost puts the test binary on $PATH, in the given directory, as the test runtime's language host. It
+// returns a function that restores $PATH.
+func useTestLanguageHost(t *testing.T, dir string) func() {
+	path := os.Getenv("PATH")
+	exe, err := os.Executable()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Symlink(exe, filepath.Join(dir, "pulumi-language-"+testRuntime)))
+	assert.NoError(t, os.Setenv("PATH", dir+string(os.PathListSeparator)+path))
+	return func() { os.Setenv("PATH", path) }
+}
+
+// newTestStack creates a stack of a project of the test runtime, in a self-managed backend in the given directory.
+func newTestStack(t *testing.T, dir string) (*Stack, bool) {
+	proj := &workspace.Project{Name: "test", Runtime: workspace.NewProjectRuntimeInfo(testRuntime, nil)}
+	if !assert.NoError(t, proj.Save(filepath.Join(dir, "Pulumi.yaml"))) {
+		return nil, false
+	}
+	stateDir := filepath.Join(dir, "state")
+	if !assert.NoError(t, os.MkdirAll(stateDir, 0700)) {
+		return nil, false
+	}
+
+	ctx := context.Background()
+	sink := diag.DefaultSink(ioutil.Discard, ioutil.Discard, diag.FormatOptions{Color: colors.Never})
+	b, err := Login(ctx, sink, "file://"+filepath.ToSlash(stateDir))
+	if !assert.NoError(t, err) {
+		return nil, false
+	}
+	ws, err := NewWorkspace(dir, b, WorkspaceOptions{Passphrase: "password"})
+	if !assert.NoError(t, err) {
+		return nil, false
+	}
+	s, err := ws.CreateStack(ctx, "dev")
+	return s, assert.NoError(t, err)
+}
+
+func TestStackPreviewAndUp(t *testing.T) {
+	dir, err := ioutil.TempDir("", "automation")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	defer useTestLanguageHost(t, dir)()
+
+	s, ok := newTestStack(t, dir)
+	if !ok {
+		return
+	}
+	ctx := context.Background()
+
+	// A preview reports the stack and its component as creates, but changes nothing.
+	preview, err := s.Preview(ctx, OperationOptions{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, engine.ResourceChanges{deploy.OpCreate: 2}, preview.Changes)
+	outputs, err := s.Outputs(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, outputs)
+
+	up, err := s.Up(ctx, OperationOptions{Message: "first"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, engine.ResourceChanges{deploy.OpCreate: 2}, up.Changes)
+	assert.Equal(t, resource.PropertyMap{"greeting": resource.NewStringProperty("hello")}, up.Outputs)
+
+	// Once the stack is up to date, there is nothing left to change.
+	preview, err = s.Preview(ctx, OperationOptions{})
+	assert.NoError(t, err)
+	assert.False(t, preview.Changes.HasChanges())
+	up, err = s.Up(ctx, OperationOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, engine.ResourceChanges{deploy.OpSame: 2}, up.Changes)
+}
+
+func TestStackOnEvent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "automation")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	defer useTestLanguageHost(t, dir)()
+
+	s, ok := newTestStack(t, dir)
+	if !ok {
+		return
+	}
+
+	var events []engine.Event
+	_, err = s.Up(context.Background(), OperationOptions{
+		OnEvent: func(e engine.Event) { events = append(events, e) },
+	})
+	if !assert.NoError(t, err) || !assert.NotEmpty(t, events) {
+		return
+	}
+
+	// Each resource's operation is reported as it starts, and the operation ends with its summary.
+	started := make(map[resource.URN]deploy.StepOp)
+	var summaries int
+	for _, e := range events {
+		switch p := e.Payload.(type) {
+		case engine.ResourcePreEventPayload:
+			started[p.Metadata.URN] = p.Metadata.Op
+		case engine.SummaryEventPayload:
+			summaries++
+		}
+	}
+	urn := resource.NewURN("dev", "test", "", "test:index:Component", "component")
+	assert.Len(t, started, 2)
+	assert.Equal(t, deploy.OpCreate, started[urn])
+	assert.Equal(t, 1, summaries)
+}
@@ -0,0 +1,218 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package automation drives Pulumi deployments from Go code. It offers the operations of the CLI -- creating and
+// selecting stacks, editing their configuration, and previewing, updating, refreshing, and destroying them -- as
+// library calls that run the engine in-process, rather than by running the CLI as a subprocess.
+//
+// Unlike the CLI, this package never prompts: operations are approved automatically, and secrets that are protected
+// by a passphrase require the passphrase to be supplied up front.
+package automation
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/backend/filestate"
+	"github.com/pulumi/pulumi/pkg/backend/httpstate"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/secrets/stacksecrets"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// Login returns the backend at the given URL, which may be that of the Pulumi service or of a self-managed backend
+// such as file://~. If the URL is empty, the backend the CLI is currently logged in to is used. Credentials for the
+// Pulumi service are read from the PULUMI_ACCESS_TOKEN environment variable or from those stored by the CLI.
+func Login(ctx context.Context, d diag.Sink, url string) (backend.Backend, error) {
+	if url == "" {
+		current, err := workspace.GetCurrentCloudURL()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not get cloud url")
+		}
+		url = current
+	}
+
+	if filestate.IsFileStateBackendURL(url) {
+		return filestate.New(d, url)
+	}
+	return httpstate.Login(ctx, d, url, display.Options{Color: colors.Never})
+}
+
+// WorkspaceOptions controls how a Workspace's stacks are managed.
+type WorkspaceOptions struct {
+	// Passphrase protects the secrets of stacks that use the passphrase secrets provider. If empty, the
+	// PULUMI_CONFIG_PASSPHRASE environment variable is used.
+	Passphrase string
+	// SecretsProvider is the secrets provider of new stacks: "default", "passphrase", or the URL of a key in a
+	// cloud key management service or Vault server, such as "awskms://alias/mykey" or "vault://mykey". If empty,
+	// the backend's default is used.
+	SecretsProvider string
+	// Display controls how the progress of operations is written to stdout. Progress is never displayed
+	// interactively, and is not colorized unless Display.Color says otherwise.
+	Display display.Options
+	// Memory bounds the memory that operations on the workspace's stacks use.
+	Memory engine.MemoryOptions
+}
+
+// Workspace is a Pulumi project on disk, whose stacks are managed by a backend.
+type Workspace struct {
+	backend  backend.Backend
+	proj     *workspace.Project
+	projPath string
+	opts     WorkspaceOptions
+}
+
+// NewWorkspace returns a workspace for the Pulumi project in the given directory, or in the nearest of its parents
+// that contains a Pulumi.yaml file, whose stacks are managed by the given backend.
+func NewWorkspace(dir string, b backend.Backend, opts WorkspaceOptions) (*Workspace, error) {
+	projPath, err := workspace.DetectProjectPathFrom(dir)
+	if err != nil {
+		return nil, err
+	}
+	if projPath == "" {
+		return nil, errors.Errorf("no Pulumi project found in %s", dir)
+	}
+	proj, err := workspace.LoadProject(projPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading project %q", projPath)
+	}
+
+	return &Workspace{
+		backend:  b,
+		proj:     proj,
+		projPath: projPath,
+		opts:     opts,
+	}, nil
+}
+
+// Backend returns the backend that manages the workspace's stacks.
+func (w *Workspace) Backend() backend.Backend {
+	return w.backend
+}
+
+// Project returns the workspace's project.
+func (w *Workspace) Project() *workspace.Project {
+	return w.proj
+}
+
+// CreateStack creates a new stack of the workspace's project with the given name, and returns it.
+func (w *Workspace) CreateStack(ctx context.Context, name string) (*Stack, error) {
+	ref, err := w.backend.ParseStackReference(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// As with the CLI, stacks that use the passphrase or a cloud secrets provider must have it configured before
+	// they are created; the Pulumi service configures its own secrets provider as part of creating the stack.
+	configPath, err := w.configPath(ref.Name())
+	if err != nil {
+		return nil, err
+	}
+	err = stacksecrets.Init(w.backend, configPath, w.opts.SecretsProvider, w.passphraseSecretsManager)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := w.backend.CreateStack(ctx, ref, nil)
+	if err != nil {
+		// If it's a StackAlreadyExistsError, don't wrap it.
+		if _, ok := err.(*backend.StackAlreadyExistsError); ok {
+			return nil, err
+		}
+		return nil, errors.Wrapf(err, "could not create stack")
+	}
+	return w.newStack(s, configPath), nil
+}
+
+// SelectStack returns the existing stack of the workspace's project with the given name.
+func (w *Workspace) SelectStack(ctx context.Context, name string) (*Stack, error) {
+	ref, err := w.backend.ParseStackReference(name)
+	if err != nil {
+		return nil, err
+	}
+	s, err := w.backend.GetStack(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, errors.Errorf("no stack named '%s' found", name)
+	}
+	configPath, err := w.configPath(ref.Name())
+	if err != nil {
+		return nil, err
+	}
+	return w.newStack(s, configPath), nil
+}
+
+// ListStacks returns the names of the stacks of the workspace's project.
+func (w *Workspace) ListStacks(ctx context.Context) ([]string, error) {
+	project := string(w.proj.Name)
+	summaries, err := w.backend.ListStacks(ctx, backend.ListStacksFilter{Project: &project, NamesOnly: true})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(summaries))
+	for i, summary := range summaries {
+		names[i] = summary.Name().String()
+	}
+	return names, nil
+}
+
+// RemoveStack removes the stack of the workspace's project with the given name. If force is true, the stack is
+// removed even if it still has resources; otherwise, removing a stack with resources is an error.
+func (w *Workspace) RemoveStack(ctx context.Context, name string, force bool) error {
+	s, err := w.SelectStack(ctx, name)
+	if err != nil {
+		return err
+	}
+	hasResources, err := backend.RemoveStack(ctx, s.stack, force)
+	if err != nil {
+		if hasResources {
+			return errors.Errorf("'%s' still has resources; removal rejected", name)
+		}
+		return err
+	}
+	return nil
+}
+
+func (w *Workspace) newStack(s backend.Stack, configPath string) *Stack {
+	return &Stack{
+		ws:         w,
+		stack:      s,
+		configPath: configPath,
+	}
+}
+
+// configPath returns the path of the configuration file of the stack with the given name.
+func (w *Workspace) configPath(stackName tokens.QName) (string, error) {
+	return workspace.ProjectStackPath(w.proj, w.projPath, stackName)
+}
+
+// displayOptions returns the options with which to display the progress of operations.
+func (w *Workspace) displayOptions() display.Options {
+	opts := w.opts.Display
+	if opts.Color == "" {
+		opts.Color = colors.Never
+	}
+	// Operations are never interactive, since there is no one to interact with.
+	opts.IsInteractive = false
+	return opts
+}
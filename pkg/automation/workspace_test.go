@@ -0,0 +1,143 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package automation
+
+import (
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	_ "gocloud.dev/secrets/localsecrets" // support for base64key://
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestWorkspaceStacksAndConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "automation")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	proj := &workspace.Project{Name: "test", Runtime: workspace.NewProjectRuntimeInfo("go", nil)}
+	assert.NoError(t, proj.Save(filepath.Join(dir, "Pulumi.yaml")))
+	stateDir := filepath.Join(dir, "state")
+	assert.NoError(t, os.MkdirAll(stateDir, 0700))
+
+	ctx := context.Background()
+	sink := diag.DefaultSink(ioutil.Discard, ioutil.Discard, diag.FormatOptions{Color: colors.Never})
+	b, err := Login(ctx, sink, "file://"+filepath.ToSlash(stateDir))
+	if !assert.NoError(t, err) {
+		return
+	}
+	ws, err := NewWorkspace(dir, b, WorkspaceOptions{Passphrase: "password"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	s, err := ws.CreateStack(ctx, "dev")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "dev", s.Name())
+
+	names, err := ws.ListStacks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"dev"}, names)
+
+	// Keys without a namespace are in the project's namespace, and secrets are stored encrypted.
+	assert.NoError(t, s.SetConfig("plain", "value", false))
+	assert.NoError(t, s.SetConfig("test:secret", "hush", true))
+
+	value, ok, err := s.GetConfig("test:plain")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "value", value)
+
+	value, ok, err = s.GetConfig("secret")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "hush", value)
+
+	ps, err := workspace.LoadProjectStack(filepath.Join(dir, "Pulumi.dev.yaml"))
+	if assert.NoError(t, err) {
+		stored := ps.Config[config.MustMakeKey("test", "secret")]
+		assert.True(t, stored.Secure())
+		ciphertext, err := stored.Value(config.NopDecrypter)
+		assert.NoError(t, err)
+		assert.NotEqual(t, "hush", ciphertext)
+	}
+
+	assert.NoError(t, s.RemoveConfig("plain"))
+	_, ok, err = s.GetConfig("plain")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, ws.RemoveStack(ctx, "dev", false))
+	_, err = ws.SelectStack(ctx, "dev")
+	assert.Error(t, err)
+}
+
+func TestWorkspaceCloudSecretsProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "automation")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	proj := &workspace.Project{Name: "test", Runtime: workspace.NewProjectRuntimeInfo("go", nil)}
+	assert.NoError(t, proj.Save(filepath.Join(dir, "Pulumi.yaml")))
+	stateDir := filepath.Join(dir, "state")
+	assert.NoError(t, os.MkdirAll(stateDir, 0700))
+
+	ctx := context.Background()
+	sink := diag.DefaultSink(ioutil.Discard, ioutil.Discard, diag.FormatOptions{Color: colors.Never})
+	b, err := Login(ctx, sink, "file://"+filepath.ToSlash(stateDir))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Self-managed backends use the given key management service rather than a passphrase.
+	provider := "base64key://" + base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))
+	ws, err := NewWorkspace(dir, b, WorkspaceOptions{SecretsProvider: provider})
+	if !assert.NoError(t, err) {
+		return
+	}
+	s, err := ws.CreateStack(ctx, "dev")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ps, err := workspace.LoadProjectStack(filepath.Join(dir, "Pulumi.dev.yaml"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, provider, ps.SecretsProvider)
+		assert.NotEmpty(t, ps.EncryptedKey)
+		assert.Empty(t, ps.EncryptionSalt)
+	}
+
+	assert.NoError(t, s.SetConfig("secret", "hush", true))
+	value, ok, err := s.GetConfig("secret")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "hush", value)
+}
@@ -30,8 +30,11 @@ import (
 	"github.com/pulumi/pulumi/pkg/diag/colors"
 	"github.com/pulumi/pulumi/pkg/engine"
 	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
 	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/i18n"
 	"github.com/pulumi/pulumi/pkg/util/result"
+	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
 // ApplierOptions is a bag of configuration settings for an Applier.
@@ -52,10 +55,10 @@ func ActionLabel(kind apitype.UpdateKind, dryRun bool) string {
 	contract.Assert(v.text != "")
 
 	if dryRun {
-		return "Previewing " + v.previewText
+		return i18n.Sprintf("Previewing %s", v.previewText)
 	}
 
-	return v.text
+	return i18n.T(v.text)
 }
 
 var updateTextMap = map[apitype.UpdateKind]struct {
@@ -72,9 +75,10 @@ var updateTextMap = map[apitype.UpdateKind]struct {
 type response string
 
 const (
-	yes     response = "yes"
-	no      response = "no"
-	details response = "details"
+	yes             response = "yes"
+	no              response = "no"
+	details         response = "details"
+	resourceDetails response = "resource details"
 )
 
 func PreviewThenPrompt(ctx context.Context, kind apitype.UpdateKind, stack Stack,
@@ -116,18 +120,102 @@ func PreviewThenPrompt(ctx context.Context, kind apitype.UpdateKind, stack Stack
 		return changes, res
 	}
 
-	// If there are no changes, or we're auto-approving or just previewing, we can skip the confirmation prompt.
-	if op.Opts.AutoApprove || kind == apitype.PreviewUpdate {
+	// If we're just previewing, we can skip the confirmation prompt.
+	if kind == apitype.PreviewUpdate || op.Opts.PreviewOnly {
 		close(eventsChannel)
 		return changes, nil
 	}
 
-	// Otherwise, ensure the user wants to proceed.
+	// Likewise if the update is auto-approved; otherwise, ensure the user wants to proceed.
+	policy := newConfirmationPolicy(op.Opts, op.StackConfiguration.Confirmation, changes)
+	if policy.autoApprove {
+		close(eventsChannel)
+		return changes, nil
+	}
+	if !op.Opts.Display.IsInteractive {
+		close(eventsChannel)
+		return changes, result.FromError(errors.Errorf("%s, and confirmation is not possible in non-interactive "+
+			"mode", policy.reason(stack.Ref().Name().String())))
+	}
+
 	res = confirmBeforeUpdating(kind, stack, events, op.Opts)
+	if res == nil && policy.typeStackName {
+		res = confirmStackName(kind, stack, op.Opts)
+	}
 	close(eventsChannel)
 	return changes, res
 }
 
+// confirmationPolicy describes how an update must be confirmed.
+type confirmationPolicy struct {
+	autoApprove   bool // true if the update may proceed without confirmation.
+	typeStackName bool // true if the user must type the stack's name to confirm the update.
+
+	destructive int    // the number of resources that the update deletes or replaces.
+	mode        string // the stack's auto-approval setting.
+	safeOnly    bool   // true if auto-approval is restricted to non-destructive updates.
+	threshold   int    // the number of destructive changes that require typing the stack's name.
+}
+
+// newConfirmationPolicy decides how an update whose preview produced the given changes must be confirmed, given the
+// update options and the stack's confirmation settings, which may be nil.
+func newConfirmationPolicy(opts UpdateOptions, cfg *workspace.StackConfirmation,
+	changes engine.ResourceChanges) confirmationPolicy {
+
+	p := confirmationPolicy{
+		destructive: changes[deploy.OpDelete] + changes[deploy.OpReplace],
+		mode:        workspace.AutoApproveAlways,
+		safeOnly:    opts.AutoApproveSafeOnly,
+	}
+	if cfg != nil {
+		if cfg.AutoApprove != "" {
+			p.mode = cfg.AutoApprove
+		}
+		p.safeOnly = p.safeOnly || cfg.AutoApprove == workspace.AutoApprovePreviewOK
+		p.threshold = cfg.TypeStackName
+	}
+
+	p.typeStackName = p.threshold > 0 && p.destructive >= p.threshold
+	p.autoApprove = opts.AutoApprove && p.mode != workspace.AutoApproveNever &&
+		!(p.safeOnly && p.destructive > 0) && !p.typeStackName
+	return p
+}
+
+// reason explains why an update to the given stack was not auto-approved.
+func (p confirmationPolicy) reason(stackName string) string {
+	switch {
+	case p.typeStackName:
+		return fmt.Sprintf("the preview deletes or replaces %d resources; stack %s requires its name to be typed to "+
+			"confirm updates that delete or replace %d or more", p.destructive, stackName, p.threshold)
+	case p.mode == workspace.AutoApproveNever:
+		return fmt.Sprintf("stack %s requires every update to be confirmed", stackName)
+	case p.safeOnly && p.destructive > 0:
+		return fmt.Sprintf("the preview deletes or replaces %d resources, so the update to stack %s cannot be "+
+			"approved automatically", p.destructive, stackName)
+	default:
+		return fmt.Sprintf("the update to stack %s requires confirmation", stackName)
+	}
+}
+
+// confirmStackName asks the user to type the name of the stack to confirm a destructive update. A nil result means
+// the name was typed correctly.
+func confirmStackName(kind apitype.UpdateKind, stack Stack, opts UpdateOptions) result.Result {
+	name := stack.Ref().Name().String()
+	prompt := "\b" + opts.Display.Color.Colorize(colors.SpecPrompt+
+		fmt.Sprintf("This %s deletes or replaces many resources. Type the name of the stack (%s) to confirm",
+			kind, name)+colors.Reset)
+
+	var response string
+	if err := survey.AskOne(&survey.Input{Message: prompt}, &response, nil); err != nil {
+		return result.FromError(errors.Wrap(err, i18n.Sprintf("confirmation cancelled, not proceeding with the %s", kind)))
+	}
+	if strings.TrimSpace(response) != name {
+		fmt.Print(i18n.Sprintf("confirmation declined, not proceeding with the %s\n", kind))
+		return result.Bail()
+	}
+	return nil
+}
+
 // confirmBeforeUpdating asks the user whether to proceed. A nil error means yes.
 func confirmBeforeUpdating(kind apitype.UpdateKind, stack Stack,
 	events []engine.Event, opts UpdateOptions) result.Result {
@@ -140,24 +228,28 @@ func confirmBeforeUpdating(kind apitype.UpdateKind, stack Stack,
 
 		choices := []string{string(yes), string(no)}
 
-		// For non-previews, we can also offer a detailed summary.
+		// For non-previews, we can also offer a detailed summary. When full diffs are shown inline, long ones are
+		// truncated, so also offer to expand the diff of a single resource.
 		if !opts.SkipPreview {
 			choices = append(choices, string(details))
+			if opts.Display.InlineDiffs {
+				choices = append(choices, string(resourceDetails))
+			}
 		}
 
 		var previewWarning string
 		if opts.SkipPreview {
-			previewWarning = colors.SpecWarning + " without a preview" + colors.Bold
+			previewWarning = colors.SpecWarning + i18n.T(" without a preview") + colors.Bold
 		}
 
 		// Create a prompt. If this is a refresh, we'll add some extra text so it's clear we aren't updating resources.
 		prompt := "\b" + opts.Display.Color.Colorize(
-			colors.SpecPrompt+fmt.Sprintf("Do you want to perform this %s%s?",
+			colors.SpecPrompt+i18n.Sprintf("Do you want to perform this %s%s?",
 				kind, previewWarning)+colors.Reset)
 		if kind == apitype.RefreshUpdate {
 			prompt += "\n" +
 				opts.Display.Color.Colorize(colors.SpecImportant+
-					"No resources will be modified as part of this refresh; just your stack's state will be."+
+					i18n.T("No resources will be modified as part of this refresh; just your stack's state will be.")+
 					colors.Reset)
 		}
 
@@ -167,11 +259,11 @@ func confirmBeforeUpdating(kind apitype.UpdateKind, stack Stack,
 			Options: choices,
 			Default: string(no),
 		}, &response, nil); err != nil {
-			return result.FromError(errors.Wrapf(err, "confirmation cancelled, not proceeding with the %s", kind))
+			return result.FromError(errors.Wrap(err, i18n.Sprintf("confirmation cancelled, not proceeding with the %s", kind)))
 		}
 
 		if response == string(no) {
-			fmt.Printf("confirmation declined, not proceeding with the %s\n", kind)
+			fmt.Print(i18n.Sprintf("confirmation declined, not proceeding with the %s\n", kind))
 			return result.Bail()
 		}
 
@@ -185,18 +277,142 @@ func confirmBeforeUpdating(kind apitype.UpdateKind, stack Stack,
 			contract.IgnoreError(err)
 			continue
 		}
+
+		if response == string(resourceDetails) {
+			if res := showResourceDiff(events, opts.Display); res != nil {
+				return res
+			}
+			continue
+		}
+	}
+}
+
+// showResourceDiff asks the user to choose one of the resources changed by a preview, and pages its complete diff.
+func showResourceDiff(events []engine.Event, opts display.Options) result.Result {
+	urns, steps := changedResources(events)
+	if len(urns) == 0 {
+		fmt.Println("No resources are changed.")
+		return nil
+	}
+
+	urn, res := selectResource(urns, opts)
+	if res != nil {
+		return res
+	}
+	return pageResourceDiff(steps[urn], opts)
+}
+
+// ExpandResourceDiffs lets the user expand the complete diffs of the resources changed by a preview, one at a time,
+// until they choose to stop. The progress display truncates long diffs that it shows inline, so this is offered after
+// interactive previews that show them.
+func ExpandResourceDiffs(events []engine.Event, opts display.Options) result.Result {
+	urns, steps := changedResources(events)
+	if len(urns) == 0 {
+		return nil
+	}
+
+	const done = "done"
+	for {
+		urn, res := selectResource(append([]string{done}, urns...), opts)
+		if res != nil {
+			return res
+		}
+		if urn == done {
+			return nil
+		}
+		if res = pageResourceDiff(steps[urn], opts); res != nil {
+			return res
+		}
+	}
+}
+
+// changedResources returns the URNs of the resources changed by a preview, in the order the preview reached them, and
+// the steps of every resource it reached.
+func changedResources(events []engine.Event) ([]string, map[string]engine.StepEventMetadata) {
+	steps := make(map[string]engine.StepEventMetadata)
+	var urns []string
+	for _, e := range events {
+		if e.Type != engine.ResourcePreEvent {
+			continue
+		}
+		step := e.Payload.(engine.ResourcePreEventPayload).Metadata
+		if _, has := steps[string(step.URN)]; !has && display.HasInlineDiff(step) {
+			urns = append(urns, string(step.URN))
+		}
+		steps[string(step.URN)] = step
+	}
+	return urns, steps
+}
+
+// selectResource asks the user to choose one of the given options, which are usually resource URNs.
+func selectResource(options []string, opts display.Options) (string, result.Result) {
+	var urn string
+	if err := survey.AskOne(&survey.Select{
+		Message:  "\b" + opts.Color.Colorize(colors.SpecPrompt+"Which resource's diff do you want to see?"+colors.Reset),
+		Options:  options,
+		PageSize: 15,
+	}, &urn, nil); err != nil {
+		return "", result.FromError(errors.Wrap(err, "selection cancelled"))
 	}
+	return urn, nil
+}
+
+// pageResourceDiff shows the complete diff of a step, through the user's pager if it is too long for the terminal.
+func pageResourceDiff(step engine.StepEventMetadata, opts display.Options) result.Result {
+	diff := display.RenderResourceDiff(step, true /*planning*/, opts.Debug, 0 /*maxLines*/, opts)
+	if err := display.Page(diff); err != nil {
+		return result.FromError(errors.Wrap(err, "showing diff"))
+	}
+	return nil
 }
 
 func PreviewThenPromptThenExecute(ctx context.Context, kind apitype.UpdateKind, stack Stack,
 	op UpdateOperation, apply Applier) (engine.ResourceChanges, result.Result) {
 	// Preview the operation to the user and ask them if they want to proceed.
 
+	// The confirmation policy depends on the preview's changes, so it cannot be enforced without one.
+	if op.Opts.SkipPreview && kind != apitype.PreviewUpdate &&
+		(op.Opts.AutoApproveSafeOnly || restrictsConfirmation(op.StackConfiguration.Confirmation)) {
+		return nil, result.Errorf("the preview cannot be skipped when updates to stack %s must be confirmed "+
+			"based on their preview", stack.Ref().Name())
+	}
+
+	// An update that must be approved is approved based on the plan its preview records, unless it already has one.
+	// Likewise, the engine checks the plan of an update to a stack with guardrails before the update changes anything.
+	// Refreshes and imports never delete or replace resources, so guardrails do not apply to them.
+	guarded := op.StackConfiguration.Guardrails != nil && !op.Opts.Engine.OverrideGuardrails &&
+		kind != apitype.RefreshUpdate && len(op.Opts.Engine.Imports) == 0
+	if (op.Opts.RequireApproval || guarded) && kind != apitype.PreviewUpdate {
+		if op.Opts.SkipPreview && op.Opts.Engine.Plan == nil {
+			if op.Opts.RequireApproval {
+				return nil, result.Errorf("the preview cannot be skipped when updates to stack %s must be approved",
+					stack.Ref().Name())
+			}
+			return nil, result.Errorf("the preview cannot be skipped when stack %s has guardrails, unless the "+
+				"%s is constrained to a saved plan or the guardrails are overridden", stack.Ref().Name(), kind)
+		}
+		if op.Opts.Engine.Plan == nil {
+			op.Opts.Engine.SavePlan = engine.NewUpdatePlan()
+		}
+	}
+
+	if op.Opts.PreviewOnly && op.Opts.SkipPreview {
+		return nil, result.Errorf("the preview cannot be skipped when only the preview of the %s is performed", kind)
+	}
+
 	if !op.Opts.SkipPreview {
 		changes, res := PreviewThenPrompt(ctx, kind, stack, op, apply)
-		if res != nil || kind == apitype.PreviewUpdate {
+		if res != nil || kind == apitype.PreviewUpdate || op.Opts.PreviewOnly {
 			return changes, res
 		}
+
+		// The pre-flight checks ran before the preview, so there is no need to run them again.
+		op.Opts.Engine.SkipPreflight = true
+	}
+
+	// Constrain an update that must be approved, or that is checked against guardrails, to the plan of its preview.
+	if (op.Opts.RequireApproval || guarded) && op.Opts.Engine.Plan == nil {
+		op.Opts.Engine.Plan, op.Opts.Engine.SavePlan = op.Opts.Engine.SavePlan, nil
 	}
 
 	// Perform the change (!DryRun) and show the cloud link to the result.
@@ -208,6 +424,13 @@ func PreviewThenPromptThenExecute(ctx context.Context, kind apitype.UpdateKind,
 	return apply(ctx, kind, stack, op, opts, nil /*events*/)
 }
 
+// restrictsConfirmation returns true if the given stack confirmation settings, which may be nil, restrict how
+// updates may be approved.
+func restrictsConfirmation(cfg *workspace.StackConfirmation) bool {
+	return cfg != nil && ((cfg.AutoApprove != "" && cfg.AutoApprove != workspace.AutoApproveAlways) ||
+		cfg.TypeStackName > 0)
+}
+
 func createDiff(updateKind apitype.UpdateKind, events []engine.Event, displayOpts display.Options) string {
 	buff := &bytes.Buffer{}
 
@@ -0,0 +1,90 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestConfirmationPolicy(t *testing.T) {
+	safe := engine.ResourceChanges{deploy.OpCreate: 3, deploy.OpUpdate: 1}
+	destructive := engine.ResourceChanges{deploy.OpCreate: 1, deploy.OpDelete: 2, deploy.OpReplace: 1}
+
+	yes := UpdateOptions{AutoApprove: true}
+	previewOK := UpdateOptions{AutoApprove: true, AutoApproveSafeOnly: true}
+
+	// Without --yes, updates always need confirmation.
+	assert.False(t, newConfirmationPolicy(UpdateOptions{}, nil, safe).autoApprove)
+
+	// --yes approves anything; --yes=preview-ok only non-destructive updates.
+	assert.True(t, newConfirmationPolicy(yes, nil, destructive).autoApprove)
+	assert.True(t, newConfirmationPolicy(previewOK, nil, safe).autoApprove)
+	assert.False(t, newConfirmationPolicy(previewOK, nil, destructive).autoApprove)
+
+	// Stacks can restrict --yes to non-destructive updates, or disable it entirely.
+	cfg := &workspace.StackConfirmation{AutoApprove: workspace.AutoApprovePreviewOK}
+	assert.True(t, newConfirmationPolicy(yes, cfg, safe).autoApprove)
+	assert.False(t, newConfirmationPolicy(yes, cfg, destructive).autoApprove)
+	cfg = &workspace.StackConfirmation{AutoApprove: workspace.AutoApproveNever}
+	p := newConfirmationPolicy(yes, cfg, safe)
+	assert.False(t, p.autoApprove)
+	assert.Equal(t, "stack dev requires every update to be confirmed", p.reason("dev"))
+
+	// Typing the stack's name is required at or above the threshold, even with --yes.
+	cfg = &workspace.StackConfirmation{TypeStackName: 3}
+	p = newConfirmationPolicy(yes, cfg, destructive)
+	assert.False(t, p.autoApprove)
+	assert.True(t, p.typeStackName)
+	assert.Equal(t, 3, p.destructive)
+	p = newConfirmationPolicy(UpdateOptions{}, &workspace.StackConfirmation{TypeStackName: 4}, destructive)
+	assert.False(t, p.typeStackName)
+
+	assert.False(t, restrictsConfirmation(nil))
+	assert.False(t, restrictsConfirmation(&workspace.StackConfirmation{AutoApprove: workspace.AutoApproveAlways}))
+	assert.True(t, restrictsConfirmation(cfg))
+}
+
+func TestChangedResources(t *testing.T) {
+	stackURN := resource.NewURN("dev", "proj", "", "pulumi:pulumi:Stack", "proj-dev")
+	aURN := resource.NewURN("dev", "proj", "", "aws:s3/bucket:Bucket", "a")
+	bURN := resource.NewURN("dev", "proj", "", "aws:s3/bucket:Bucket", "b")
+	cURN := resource.NewURN("dev", "proj", "", "aws:s3/bucket:Bucket", "c")
+	pre := func(op deploy.StepOp, urn resource.URN) engine.Event {
+		return engine.Event{
+			Type:    engine.ResourcePreEvent,
+			Payload: engine.ResourcePreEventPayload{Metadata: engine.StepEventMetadata{Op: op, URN: urn, Type: urn.Type()}},
+		}
+	}
+
+	// Only resources that are changed can be expanded, in the order the preview reached them, and each only once.
+	urns, steps := changedResources([]engine.Event{
+		pre(deploy.OpUpdate, stackURN),
+		pre(deploy.OpUpdate, bURN),
+		pre(deploy.OpSame, cURN),
+		pre(deploy.OpCreate, aURN),
+		{Type: engine.ResourceOutputsEvent},
+		pre(deploy.OpCreate, aURN),
+	})
+	assert.Equal(t, []string{string(bURN), string(aURN)}, urns)
+	assert.Len(t, steps, 4)
+	assert.Equal(t, deploy.OpCreate, steps[string(aURN)].Op)
+}
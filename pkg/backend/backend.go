@@ -94,6 +94,10 @@ type ListStacksFilter struct {
 	Project      *string
 	TagName      *string
 	TagValue     *string
+
+	// NamesOnly allows the backend to return summaries that only name their stacks, omitting details such as the
+	// time of each stack's last update, which may be expensive to fetch.
+	NamesOnly bool
 }
 
 // Backend is an interface that represents actions the engine will interact with to manage stacks of cloud resources.
@@ -137,6 +141,9 @@ type Backend interface {
 
 	// Query against the resource outputs in a stack's state checkpoint.
 	Query(ctx context.Context, stackRef StackReference, op UpdateOperation) result.Result
+	// WaitForStackReady waits until no update of the stack is in progress, checking at the given interval. It returns an
+	// error wrapping the context's error if the context ends first.
+	WaitForStackReady(ctx context.Context, stackRef StackReference, pollInterval time.Duration) error
 
 	// GetHistory returns all updates for the stack. The returned UpdateInfo slice will be in
 	// descending order (newest first).
@@ -162,6 +169,40 @@ type Backend interface {
 	CurrentUser() (string, error)
 }
 
+// StateEncryptionBackend is implemented by backends that can encrypt the state of their stacks with a key from each
+// stack's secrets provider before persisting it, so that the backend's storage never holds the state in plaintext.
+type StateEncryptionBackend interface {
+	Backend
+
+	// SetStateEncryption rewrites the stack's state encrypted with the key of the given secrets manager or, if encrypt
+	// is false, unencrypted. Every later checkpoint of the stack is persisted the same way.
+	SetStateEncryption(ctx context.Context, stackRef StackReference, sm secrets.Manager, encrypt bool) error
+	// SetStateSecretsManagerSource sets the function that resolves each stack's configured secrets manager, whose key
+	// decrypts the stack's state if it is encrypted at rest. Stacks are loaded without decrypting their state, which
+	// is only decrypted once it is needed.
+	SetStateSecretsManagerSource(source StateSecretsManagerSource)
+}
+
+// DeploymentHistoryBackend is implemented by backends that keep the deployment left by each of a stack's updates.
+type DeploymentHistoryBackend interface {
+	Backend
+
+	// ExportDeploymentVersion exports the stack's deployment as of the given update version.
+	ExportDeploymentVersion(ctx context.Context, stackRef StackReference,
+		version int) (*apitype.UntypedDeployment, error)
+}
+
+// SecretsAuditBackend is implemented by backends that keep an audit log of the disclosure of stacks' secrets.
+type SecretsAuditBackend interface {
+	Backend
+
+	// RecordSecretsRevealed records that the given command revealed the secrets of the stack's deployment at the
+	// given update version (or the current deployment, if 0) at the given paths (or everywhere, if empty). The
+	// paths of the secrets of the stack's configuration are configuration keys.
+	RecordSecretsRevealed(ctx context.Context, stackRef StackReference, command string, version int,
+		paths []string) error
+}
+
 // UpdateOperation is a complete stack update operation (preview, update, refresh, or destroy).
 type UpdateOperation struct {
 	Proj               *workspace.Project
@@ -171,12 +212,23 @@ type UpdateOperation struct {
 	SecretsManager     secrets.Manager
 	StackConfiguration StackConfiguration
 	Scopes             CancellationScopeSource
+
+	// Events, if non-nil, receives each engine event that the operation produces, in addition to the display. The
+	// backend does not close the channel.
+	Events chan<- engine.Event
+	// Permalink, if non-nil, is set to the link to the operation's persisted result, if the backend displays one.
+	Permalink *string
 }
 
 // StackConfiguration holds the configuration for a stack and it's associated decrypter.
 type StackConfiguration struct {
-	Config    config.Map
-	Decrypter config.Decrypter
+	Config          config.Map
+	Decrypter       config.Decrypter
+	Transformations []workspace.ResourceTransformation
+	NamePrefix      string
+	NameSuffix      string
+	Confirmation    *workspace.StackConfirmation
+	Guardrails      *workspace.StackGuardrails
 }
 
 // UpdateOptions is the full set of update options, including backend and engine options.
@@ -188,8 +240,23 @@ type UpdateOptions struct {
 
 	// AutoApprove, when true, will automatically approve previews.
 	AutoApprove bool
+	// AutoApproveSafeOnly, when true, restricts AutoApprove to previews that neither delete nor replace resources.
+	AutoApproveSafeOnly bool
 	// SkipPreview, when true, causes the preview step to be skipped.
 	SkipPreview bool
+	// PreviewOnly, when true, causes only the preview step to be performed: the operation is neither confirmed nor
+	// carried out, and the stack's state is left as it is.
+	PreviewOnly bool
+	// AgainstVersion, if non-zero, runs a preview against the state left by the given update version rather than the
+	// stack's current state. It is only valid for previews.
+	AgainstVersion int
+	// RequireApproval, when true, submits the plan recorded by the preview for approval by the stack's organization,
+	// and waits until it is approved before the update starts. The update may then perform only the operations in the
+	// approved plan. Only the Pulumi service supports approvals.
+	RequireApproval bool
+	// ApprovalTimeout, if positive, is the longest to wait for an update to be approved when RequireApproval is set.
+	// Updates that are not approved in time fail.
+	ApprovalTimeout time.Duration
 }
 
 // CancellationScope provides a scoped source of cancellation and termination requests.
@@ -242,6 +309,15 @@ func (c *backendClient) GetStackOutputs(ctx context.Context, name string) (resou
 	return res.Outputs, nil
 }
 
+// NormalizeStackReference returns the canonical name of the stack with the given name.
+func (c *backendClient) NormalizeStackReference(name string) (string, error) {
+	ref, err := c.backend.ParseStackReference(name)
+	if err != nil {
+		return "", err
+	}
+	return ref.String(), nil
+}
+
 func (c *backendClient) GetStackResourceOutputs(
 	ctx context.Context, name string) (resource.PropertyMap, error) {
 	ref, err := c.backend.ParseStackReference(name)
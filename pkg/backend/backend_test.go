@@ -17,6 +17,7 @@ package backend
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -139,6 +140,7 @@ type mockBackend struct {
 	RenameStackF            func(context.Context, StackReference, tokens.QName) error
 	GetStackCrypterF        func(StackReference) (config.Crypter, error)
 	QueryF                  func(context.Context, StackReference, UpdateOperation) result.Result
+	WaitForStackReadyF      func(context.Context, StackReference, time.Duration) error
 	GetLatestConfigurationF func(context.Context, StackReference) (config.Map, error)
 	GetHistoryF             func(context.Context, StackReference) ([]UpdateInfo, error)
 	GetStackTagsF           func(context.Context, StackReference) (map[apitype.StackTagName]string, error)
@@ -285,6 +287,15 @@ func (be *mockBackend) Query(ctx context.Context, stackRef StackReference,
 	panic("not implemented")
 }
 
+func (be *mockBackend) WaitForStackReady(ctx context.Context, stackRef StackReference,
+	pollInterval time.Duration) error {
+
+	if be.WaitForStackReadyF != nil {
+		return be.WaitForStackReadyF(ctx, stackRef, pollInterval)
+	}
+	panic("not implemented")
+}
+
 func (be *mockBackend) GetHistory(ctx context.Context, stackRef StackReference) ([]UpdateInfo, error) {
 	if be.GetHistoryF != nil {
 		return be.GetHistoryF(ctx, stackRef)
@@ -0,0 +1,254 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/dustin/go-humanize/english"
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+// Verbosity controls how much detail the accessible display reports.
+type Verbosity int
+
+const (
+	// VerbosityNormal reports problems, completed resource operations, program output, and the summary.
+	VerbosityNormal Verbosity = iota
+	// VerbosityQuiet reports only problems and the summary.
+	VerbosityQuiet
+	// VerbosityVerbose additionally reports resource operations as they start and unchanged resources.
+	VerbosityVerbose
+)
+
+// ParseVerbosity parses the name of a verbosity level: quiet, normal, or verbose.
+func ParseVerbosity(s string) (Verbosity, error) {
+	switch s {
+	case "", "normal":
+		return VerbosityNormal, nil
+	case "quiet":
+		return VerbosityQuiet, nil
+	case "verbose":
+		return VerbosityVerbose, nil
+	default:
+		return VerbosityNormal, errors.Errorf("unknown verbosity '%s'; must be one of quiet, normal, or verbose", s)
+	}
+}
+
+// ShowAccessibleEvents displays the engine events as a linear sequence of plain-text lines, each starting with a
+// word that describes what kind of line it is (e.g. `DONE:` or `ERROR:`). Nothing is ever redrawn, and no colors,
+// emoji, or symbols are used, so the output reads well with a screen reader.
+func ShowAccessibleEvents(op string, action apitype.UpdateKind,
+	events <-chan engine.Event, done chan<- bool, opts Options, isPreview bool) {
+
+	defer close(done)
+
+	fprintIgnoreError(os.Stdout, fmt.Sprintf("STARTED: %s\n", op))
+	for event := range events {
+		out := os.Stdout
+		if event.Type == engine.DiagEvent {
+			payload := event.Payload.(engine.DiagEventPayload)
+			if payload.Severity == diag.Error || payload.Severity == diag.Warning {
+				out = os.Stderr
+			}
+		}
+
+		if msg := renderAccessibleEvent(event, opts, isPreview); msg != "" {
+			fprintIgnoreError(out, msg)
+		}
+
+		if event.Type == engine.CancelEvent {
+			return
+		}
+	}
+}
+
+// renderAccessibleEvent renders a single engine event for the accessible display, or returns the empty string if the
+// event is not reported at the display's verbosity.
+func renderAccessibleEvent(event engine.Event, opts Options, isPreview bool) string {
+	normal, verbose := opts.Verbosity != VerbosityQuiet, opts.Verbosity == VerbosityVerbose
+
+	switch event.Type {
+	case engine.CancelEvent:
+		return ""
+	case engine.PreludeEvent:
+		return colors.Never.Colorize(renderPreludeEvent(event.Payload.(engine.PreludeEventPayload), opts))
+	case engine.SummaryEvent:
+		return renderAccessibleSummary(event.Payload.(engine.SummaryEventPayload))
+	case engine.StdoutColorEvent:
+		if !normal {
+			return ""
+		}
+		return accessibleLine("OUTPUT", event.Payload.(engine.StdoutEventPayload).Message)
+	case engine.DiagEvent:
+		return renderAccessibleDiag(event.Payload.(engine.DiagEventPayload), opts)
+	case engine.PolicyViolationEvent:
+		payload := event.Payload.(engine.PolicyViolationEventPayload)
+		return accessibleLine(fmt.Sprintf("POLICY VIOLATION (%s)", payload.EnforcementLevel),
+			fmt.Sprintf("%s: %s: %s", payload.PolicyPackName, payload.PolicyName, payload.Message))
+	case engine.PolicyRemediationEvent:
+		payload := event.Payload.(engine.PolicyRemediationEventPayload)
+		return accessibleLine("POLICY REMEDIATION", payload.Message)
+
+	case engine.ResourcePreEvent:
+		payload := event.Payload.(engine.ResourcePreEventPayload)
+		if payload.Debug && !opts.Debug {
+			return ""
+		}
+		if isPreview {
+			// Previews report each planned step as soon as it is known.
+			return renderAccessibleStep("PLANNED", payload.Metadata, true, false, verbose)
+		}
+		if !verbose {
+			return ""
+		}
+		return renderAccessibleStep("STARTING", payload.Metadata, false, false, verbose)
+	case engine.ResourceOutputsEvent:
+		payload := event.Payload.(engine.ResourceOutputsEventPayload)
+		if isPreview || !normal || (payload.Debug && !opts.Debug) {
+			return ""
+		}
+		return renderAccessibleStep("DONE", payload.Metadata, false, true, verbose)
+	case engine.ResourceOperationFailed:
+		payload := event.Payload.(engine.ResourceOperationFailedPayload)
+		return renderAccessibleStep("FAILED", payload.Metadata, false, false, true)
+
+	default:
+		contract.Failf("unknown event type '%s'", event.Type)
+		return ""
+	}
+}
+
+// renderAccessibleStep renders a resource step, e.g. `DONE: created aws:s3/bucket:Bucket my-bucket`. Unchanged
+// resources and the root stack are only reported if showSames is true.
+func renderAccessibleStep(prefix string, step engine.StepEventMetadata, isPreview, done, showSames bool) string {
+	if step.Op == deploy.OpSame && !showSames && prefix != "FAILED" {
+		return ""
+	}
+	if isRootStack(step) && prefix != "FAILED" {
+		return ""
+	}
+
+	d := &ProgressDisplay{isPreview: isPreview}
+	var description string
+	switch {
+	case prefix == "FAILED":
+		description = d.getStepDoneDescription(step, true /*failed*/)
+	case isPreview:
+		description = d.getPreviewText(step)
+	case done:
+		description = d.getStepDoneDescription(step, false /*failed*/)
+	default:
+		description = d.getStepInProgressDescription(step)
+	}
+	description = strings.Trim(colors.Never.Colorize(description), "*")
+	if description == "" {
+		description = "unchanged"
+	}
+
+	return accessibleLine(prefix, fmt.Sprintf("%s %s %s", description, step.URN.Type(), step.URN.Name()))
+}
+
+// renderAccessibleDiag renders a diagnostic, e.g. `WARNING: aws:s3/bucket:Bucket my-bucket: message`.
+func renderAccessibleDiag(payload engine.DiagEventPayload, opts Options) string {
+	var prefix string
+	switch payload.Severity {
+	case diag.Error:
+		prefix = "ERROR"
+	case diag.Warning:
+		prefix = "WARNING"
+	case diag.Info, diag.Infoerr:
+		if opts.Verbosity == VerbosityQuiet || (payload.Ephemeral && opts.Verbosity != VerbosityVerbose) {
+			return ""
+		}
+		prefix = "INFO"
+	case diag.Debug:
+		if !opts.Debug {
+			return ""
+		}
+		prefix = "DEBUG"
+	default:
+		contract.Failf("unknown severity '%s'", payload.Severity)
+	}
+
+	msg := payload.Message
+	if payload.URN != "" {
+		msg = fmt.Sprintf("%s %s: %s", payload.URN.Type(), payload.URN.Name(), msg)
+	}
+	return accessibleLine(prefix, msg)
+}
+
+// renderAccessibleSummary renders the summary of an update, e.g. `SUMMARY: 1 created, 2 unchanged. Duration: 3s.`
+func renderAccessibleSummary(event engine.SummaryEventPayload) string {
+	var parts []string
+	for _, op := range deploy.StepOps {
+		if op == deploy.OpSame || op == deploy.OpRead || op == deploy.OpReadDiscard || op == deploy.OpReadReplacement {
+			continue
+		}
+		if c := event.ResourceChanges[op]; c > 0 {
+			if event.IsPreview {
+				parts = append(parts, fmt.Sprintf("%d to %s", c, op))
+			} else {
+				parts = append(parts, fmt.Sprintf("%d %s", c, op.PastTense()))
+			}
+		}
+	}
+	if c := event.ResourceChanges[deploy.OpSame]; c > 0 {
+		parts = append(parts, fmt.Sprintf("%d unchanged", c))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "no changes")
+	}
+
+	var buf bytes.Buffer
+	fprintfIgnoreError(&buf, "SUMMARY: %s.", strings.Join(parts, ", "))
+	if event.Duration > 0 {
+		fprintfIgnoreError(&buf, " Duration: %s.", event.Duration)
+	}
+	if event.PeakMemory > 0 {
+		fprintfIgnoreError(&buf, " Peak memory: %s.", humanize.IBytes(event.PeakMemory))
+	}
+	buf.WriteString("\n")
+	for _, item := range sortedDeprecations(event.Deprecations) {
+		c := event.Deprecations[item]
+		buf.WriteString(accessibleLine("WARNING", fmt.Sprintf("%d %s of deprecated %s",
+			c, english.PluralWord(c, "use", ""), item)))
+	}
+	if event.MaybeCorrupt {
+		buf.WriteString(accessibleLine("WARNING",
+			"one or more resources may have been left in an unknown state; run `pulumi refresh` to reconcile"))
+	}
+	return buf.String()
+}
+
+// accessibleLine renders a message with the given prefix, without colors. Continuation lines are indented.
+func accessibleLine(prefix, msg string) string {
+	msg = strings.TrimRight(colors.Never.Colorize(msg), "\n")
+	if msg == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s: %s\n", prefix, strings.Replace(msg, "\n", "\n    ", -1))
+}
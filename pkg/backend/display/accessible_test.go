@@ -0,0 +1,103 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+)
+
+func TestParseVerbosity(t *testing.T) {
+	for s, expected := range map[string]Verbosity{
+		"":        VerbosityNormal,
+		"normal":  VerbosityNormal,
+		"quiet":   VerbosityQuiet,
+		"verbose": VerbosityVerbose,
+	} {
+		v, err := ParseVerbosity(s)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, v)
+	}
+
+	_, err := ParseVerbosity("loud")
+	assert.Error(t, err)
+}
+
+func TestRenderAccessibleEvent(t *testing.T) {
+	urn := resource.NewURN("dev", "proj", "", "aws:s3/bucket:Bucket", "my-bucket")
+	step := func(op deploy.StepOp) engine.StepEventMetadata {
+		return engine.StepEventMetadata{Op: op, URN: urn, Type: urn.Type()}
+	}
+	pre := func(op deploy.StepOp) engine.Event {
+		return engine.Event{Type: engine.ResourcePreEvent,
+			Payload: engine.ResourcePreEventPayload{Metadata: step(op)}}
+	}
+	outputs := func(op deploy.StepOp) engine.Event {
+		return engine.Event{Type: engine.ResourceOutputsEvent,
+			Payload: engine.ResourceOutputsEventPayload{Metadata: step(op)}}
+	}
+	diagEvent := func(sev diag.Severity, msg string) engine.Event {
+		return engine.Event{Type: engine.DiagEvent,
+			Payload: engine.DiagEventPayload{URN: urn, Severity: sev, Message: colors.Red + msg + colors.Reset}}
+	}
+
+	quiet := Options{Verbosity: VerbosityQuiet}
+	normal := Options{Verbosity: VerbosityNormal}
+	verbose := Options{Verbosity: VerbosityVerbose}
+
+	// Updates report completed steps, and only report starts and unchanged resources when verbose.
+	assert.Equal(t, "", renderAccessibleEvent(pre(deploy.OpCreate), normal, false))
+	assert.Equal(t, "STARTING: creating aws:s3/bucket:Bucket my-bucket\n",
+		renderAccessibleEvent(pre(deploy.OpCreate), verbose, false))
+	assert.Equal(t, "DONE: created aws:s3/bucket:Bucket my-bucket\n",
+		renderAccessibleEvent(outputs(deploy.OpCreate), normal, false))
+	assert.Equal(t, "", renderAccessibleEvent(outputs(deploy.OpCreate), quiet, false))
+	assert.Equal(t, "", renderAccessibleEvent(outputs(deploy.OpSame), normal, false))
+	assert.Equal(t, "DONE: unchanged aws:s3/bucket:Bucket my-bucket\n",
+		renderAccessibleEvent(outputs(deploy.OpSame), verbose, false))
+
+	// Previews report planned steps.
+	assert.Equal(t, "PLANNED: create aws:s3/bucket:Bucket my-bucket\n",
+		renderAccessibleEvent(pre(deploy.OpCreate), normal, true))
+
+	// Failures and problems are always reported, without colors.
+	failed := engine.Event{Type: engine.ResourceOperationFailed,
+		Payload: engine.ResourceOperationFailedPayload{Metadata: step(deploy.OpCreate)}}
+	assert.Equal(t, "FAILED: creating failed aws:s3/bucket:Bucket my-bucket\n",
+		renderAccessibleEvent(failed, quiet, false))
+	assert.Equal(t, "ERROR: aws:s3/bucket:Bucket my-bucket: access denied\n    try again\n",
+		renderAccessibleEvent(diagEvent(diag.Error, "access denied\ntry again\n"), quiet, false))
+	assert.Equal(t, "", renderAccessibleEvent(diagEvent(diag.Info, "hello"), quiet, false))
+	assert.Equal(t, "INFO: aws:s3/bucket:Bucket my-bucket: hello\n",
+		renderAccessibleEvent(diagEvent(diag.Info, "hello"), normal, false))
+	assert.Equal(t, "", renderAccessibleEvent(diagEvent(diag.Debug, "hello"), verbose, false))
+
+	summary := engine.Event{Type: engine.SummaryEvent, Payload: engine.SummaryEventPayload{
+		ResourceChanges: engine.ResourceChanges{deploy.OpCreate: 1, deploy.OpSame: 2},
+		Duration:        5 * time.Second,
+	}}
+	assert.Equal(t, "SUMMARY: 1 created, 2 unchanged. Duration: 5s.\n",
+		renderAccessibleEvent(summary, quiet, false))
+	assert.Equal(t, "SUMMARY: no changes.\n", renderAccessibleEvent(engine.Event{Type: engine.SummaryEvent,
+		Payload: engine.SummaryEventPayload{IsPreview: true}}, normal, true))
+}
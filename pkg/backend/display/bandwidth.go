@@ -0,0 +1,66 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/engine"
+)
+
+// LowBandwidthMaxLines is the number of lines of a verbose event's text shown in low-bandwidth mode.
+const LowBandwidthMaxLines = 5
+
+// SummarizeText shortens the text of a verbose event to its first maxLines lines, noting how many were elided.
+func SummarizeText(text string, maxLines int) string {
+	lines := strings.SplitAfter(text, "\n")
+	// A trailing newline leaves an empty final element, which is not a line of its own.
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) <= maxLines {
+		return text
+	}
+	return strings.Join(lines[:maxLines], "") + fmt.Sprintf("    ... %d more lines elided\n", len(lines)-maxLines)
+}
+
+// ExpandsDiagnostic returns true if a diagnostic of the given severity is shown in full in low-bandwidth mode. Errors
+// and warnings always are; everything else is summarized unless debug output was requested.
+func ExpandsDiagnostic(severity diag.Severity, opts Options) bool {
+	return severity == diag.Error || severity == diag.Warning || opts.Debug
+}
+
+// summarizeEvents passes each event from the given channel on to the returned channel, shortening the messages of
+// diagnostics that are not expanded in low-bandwidth mode. The returned channel is closed once the given channel is.
+func summarizeEvents(events <-chan engine.Event, opts Options) <-chan engine.Event {
+	out := make(chan engine.Event)
+	go func() {
+		defer close(out)
+
+		for e := range events {
+			if e.Type == engine.DiagEvent {
+				payload := e.Payload.(engine.DiagEventPayload)
+				if !ExpandsDiagnostic(payload.Severity, opts) {
+					payload.Message = SummarizeText(payload.Message, LowBandwidthMaxLines)
+					e.Payload = payload
+				}
+			}
+			out <- e
+		}
+	}()
+	return out
+}
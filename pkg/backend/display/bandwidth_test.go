@@ -0,0 +1,58 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/engine"
+)
+
+func TestSummarizeText(t *testing.T) {
+	assert.Equal(t, "", SummarizeText("", 2))
+	assert.Equal(t, "a\nb\n", SummarizeText("a\nb\n", 2))
+	assert.Equal(t, "a\nb", SummarizeText("a\nb", 2))
+	assert.Equal(t, "a\nb\n    ... 2 more lines elided\n", SummarizeText("a\nb\nc\nd\n", 2))
+	assert.Equal(t, "a\n    ... 2 more lines elided\n", SummarizeText("a\nb\nc", 1))
+}
+
+func TestSummarizeEvents(t *testing.T) {
+	long := "1\n2\n3\n4\n5\n6\n7\n"
+	summarized := "1\n2\n3\n4\n5\n    ... 2 more lines elided\n"
+
+	summarize := func(opts Options, severity diag.Severity) string {
+		events := make(chan engine.Event, 1)
+		events <- engine.Event{Type: engine.DiagEvent, Payload: engine.DiagEventPayload{
+			Severity: severity,
+			Message:  long,
+		}}
+		close(events)
+
+		e := <-summarizeEvents(events, opts)
+		return e.Payload.(engine.DiagEventPayload).Message
+	}
+
+	opts := Options{LowBandwidth: true}
+	assert.Equal(t, summarized, summarize(opts, diag.Info))
+	assert.Equal(t, summarized, summarize(opts, diag.Debug))
+	assert.Equal(t, long, summarize(opts, diag.Warning))
+	assert.Equal(t, long, summarize(opts, diag.Error))
+
+	opts.Debug = true
+	assert.Equal(t, long, summarize(opts, diag.Info))
+}
@@ -21,8 +21,10 @@ import (
 	"math"
 	"os"
 	"sort"
+	"strings"
 	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/dustin/go-humanize/english"
 
 	"github.com/pulumi/pulumi/pkg/apitype"
@@ -115,6 +117,8 @@ func RenderDiffEvent(action apitype.UpdateKind, event engine.Event,
 		return renderDiffDiagEvent(event.Payload.(engine.DiagEventPayload), opts)
 	case engine.PolicyViolationEvent:
 		return renderDiffPolicyViolationEvent(event.Payload.(engine.PolicyViolationEventPayload), opts)
+	case engine.PolicyRemediationEvent:
+		return renderDiffPolicyRemediationEvent(event.Payload.(engine.PolicyRemediationEventPayload), opts)
 
 	default:
 		contract.Failf("unknown event type '%s'", event.Type)
@@ -133,6 +137,10 @@ func renderDiffPolicyViolationEvent(payload engine.PolicyViolationEventPayload,
 	return opts.Color.Colorize(payload.Prefix + payload.Message)
 }
 
+func renderDiffPolicyRemediationEvent(payload engine.PolicyRemediationEventPayload, opts Options) string {
+	return opts.Color.Colorize(payload.Prefix + payload.Message)
+}
+
 func renderStdoutColorEvent(payload engine.StdoutEventPayload, opts Options) string {
 	return opts.Color.Colorize(payload.Message)
 }
@@ -208,6 +216,21 @@ func renderSummaryEvent(action apitype.UpdateKind, event engine.SummaryEventPayl
 		fprintfIgnoreError(out, "\n")
 	}
 
+	if event.IsPreview {
+		renderProviderChanges(out, event.ProviderChanges, opts)
+		renderCriticalPath(out, event.CriticalPath, opts)
+	}
+
+	if len(event.Deprecations) > 0 {
+		fprintIgnoreError(out, opts.Color.Colorize(
+			fmt.Sprintf("\n%sDeprecations:%s\n", colors.SpecHeadline, colors.Reset)))
+		for _, item := range sortedDeprecations(event.Deprecations) {
+			c := event.Deprecations[item]
+			fprintIgnoreError(out, opts.Color.Colorize(fmt.Sprintf("    %s%d %s of %s%s\n",
+				colors.SpecWarning, c, english.PluralWord(c, "use", ""), item, colors.Reset)))
+		}
+	}
+
 	// For actual deploys, we print some additional summary information
 	if !event.IsPreview {
 		// Round up to the nearest second.  It's not useful to spit out time with 9 digits of
@@ -219,9 +242,87 @@ func renderSummaryEvent(action apitype.UpdateKind, event engine.SummaryEventPayl
 			colors.SpecHeadline, colors.Reset, roundedDuration)))
 	}
 
+	if event.PeakMemory > 0 {
+		fprintIgnoreError(out, opts.Color.Colorize(fmt.Sprintf("%sPeak memory:%s %s\n",
+			colors.SpecHeadline, colors.Reset, humanize.IBytes(event.PeakMemory))))
+	}
+
 	return out.String()
 }
 
+// renderProviderChanges breaks a preview's changes down by provider package. Nothing is rendered if all of the changes
+// belong to a single provider, as the overall summary already says everything there is to say.
+func renderProviderChanges(out io.Writer, providerChanges map[string]engine.ResourceChanges, opts Options) {
+	if len(providerChanges) < 2 {
+		return
+	}
+
+	var names []string
+	for name := range providerChanges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fprintIgnoreError(out, opts.Color.Colorize(
+		fmt.Sprintf("\n%sChanges by provider:%s\n", colors.SpecHeadline, colors.Reset)))
+	for _, name := range names {
+		var pieces []string
+		for _, op := range deploy.StepOps {
+			if c := providerChanges[name][op]; c > 0 {
+				pieces = append(pieces, fmt.Sprintf("%s%d to %s%s", op.Prefix(), c, op, colors.Reset))
+			}
+		}
+		fprintIgnoreError(out, opts.Color.Colorize(fmt.Sprintf("    %s: %s\n", name, strings.Join(pieces, ", "))))
+	}
+}
+
+// renderCriticalPath renders the chain of dependent changes that is expected to take the longest. Chains of a single
+// change are not worth calling out.
+func renderCriticalPath(out io.Writer, path []engine.CriticalPathStep, opts Options) {
+	if len(path) < 2 {
+		return
+	}
+
+	var total time.Duration
+	unknown := 0
+	for _, step := range path {
+		total += step.Expected
+		if !step.Known {
+			unknown++
+		}
+	}
+
+	var expected string
+	switch {
+	case unknown == len(path):
+		expected = "no timing history"
+	case unknown > 0:
+		expected = fmt.Sprintf("at least %s expected", total.Round(time.Second))
+	default:
+		expected = fmt.Sprintf("~%s expected", total.Round(time.Second))
+	}
+	fprintIgnoreError(out, opts.Color.Colorize(fmt.Sprintf("\n%sCritical path:%s %d dependent changes, %s\n",
+		colors.SpecHeadline, colors.Reset, len(path), expected)))
+	for _, step := range path {
+		duration := "unknown"
+		if step.Known {
+			duration = "~" + step.Expected.Round(time.Second).String()
+		}
+		fprintIgnoreError(out, opts.Color.Colorize(fmt.Sprintf("    %s%s %s%s (%s)\n",
+			step.Op.Prefix(), step.URN.Type(), step.URN.Name(), colors.Reset, duration)))
+	}
+}
+
+// sortedDeprecations returns the deprecated items used by an update, sorted by name.
+func sortedDeprecations(deprecations map[string]int) []string {
+	var items []string
+	for item := range deprecations {
+		items = append(items, item)
+	}
+	sort.Strings(items)
+	return items
+}
+
 func renderPreludeEvent(event engine.PreludeEventPayload, opts Options) string {
 	// Only if we have been instructed to show configuration values will we print anything during the prelude.
 	if !opts.ShowConfig {
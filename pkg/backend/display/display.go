@@ -33,6 +33,30 @@ func ShowEvents(
 	op string, action apitype.UpdateKind, stack tokens.QName, proj tokens.PackageName,
 	events <-chan engine.Event, done chan<- bool, opts Options, isPreview bool) {
 
+	if opts.EventLog != nil {
+		events = logEvents(events, opts.EventLog, opts.Debug)
+	}
+
+	if len(opts.Renderers) > 0 {
+		// Signal that the events have been displayed only once the renderers have finished with them, too.
+		info := RenderInfo{Operation: op, Action: action, Stack: stack, Project: proj, IsPreview: isPreview}
+		var rendered <-chan bool
+		events, rendered = renderEvents(info, events, opts.Renderers, opts.Debug)
+
+		displayed := make(chan bool)
+		go func(done chan<- bool) {
+			<-displayed
+			<-rendered
+			close(done)
+		}(done)
+		done = displayed
+	}
+
+	// The event log and renderers see the full events; only what is printed to the console is summarized.
+	if opts.LowBandwidth {
+		events = summarizeEvents(events, opts)
+	}
+
 	if opts.JSONDisplay {
 		// TODO[pulumi/pulumi#2390]: enable JSON display for real deployments.
 		contract.Assertf(isPreview, "JSON display only available in preview mode")
@@ -45,6 +69,8 @@ func ShowEvents(
 		ShowDiffEvents(op, action, events, done, opts)
 	case DisplayProgress:
 		ShowProgressEvents(op, action, stack, proj, events, done, opts, isPreview)
+	case DisplayAccessible:
+		ShowAccessibleEvents(op, action, events, done, opts, isPreview)
 	case DisplayQuery:
 		contract.Failf("DisplayQuery can only be used in query mode, which should be invoked " +
 			"directly instead of through ShowEvents")
@@ -0,0 +1,293 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+)
+
+// logEvents writes each event from the given channel to w as a line of JSON, in the form of an apitype.EngineEvent,
+// before passing it on to the returned channel. The returned channel is closed once the given channel is closed and
+// all of its events have been written. Debug diagnostics are only written if debug is true.
+func logEvents(events <-chan engine.Event, w io.Writer, debug bool) <-chan engine.Event {
+	out := make(chan engine.Event)
+	go func() {
+		defer close(out)
+
+		buf := bufio.NewWriter(w)
+		enc := json.NewEncoder(buf)
+		seq := 0
+		for e := range events {
+			isDebug := e.Type == engine.DiagEvent && e.Payload.(engine.DiagEventPayload).Severity == diag.Debug
+			if !isDebug || debug {
+				if err := writeEventLogEntry(enc, e, seq); err != nil {
+					logging.V(3).Infof("error writing engine event to the event log: %v", err)
+				} else {
+					seq++
+				}
+
+				// Flush each event, so that the log can be followed while the operation runs.
+				if err := buf.Flush(); err != nil {
+					logging.V(3).Infof("error flushing the event log: %v", err)
+				}
+			}
+			out <- e
+		}
+	}()
+	return out
+}
+
+// writeEventLogEntry encodes a single event with the given sequence number.
+func writeEventLogEntry(enc *json.Encoder, e engine.Event, seq int) error {
+	apiEvent, err := ConvertEngineEvent(e)
+	if err != nil {
+		return err
+	}
+	apiEvent.Sequence = seq
+	apiEvent.Timestamp = int(time.Now().Unix())
+	return enc.Encode(apiEvent)
+}
+
+func convertStepEventMetadata(md engine.StepEventMetadata) apitype.StepEventMetadata {
+	keys := make([]string, len(md.Keys))
+	for i, v := range md.Keys {
+		keys[i] = string(v)
+	}
+	var diffs []string
+	for _, v := range md.Diffs {
+		diffs = append(diffs, string(v))
+	}
+	var detailedDiff map[string]apitype.PropertyDiff
+	if md.DetailedDiff != nil {
+		detailedDiff = make(map[string]apitype.PropertyDiff)
+		for k, v := range md.DetailedDiff {
+			var d apitype.DiffKind
+			switch v.Kind {
+			case plugin.DiffAdd:
+				d = apitype.DiffAdd
+			case plugin.DiffAddReplace:
+				d = apitype.DiffAddReplace
+			case plugin.DiffDelete:
+				d = apitype.DiffDelete
+			case plugin.DiffDeleteReplace:
+				d = apitype.DiffDeleteReplace
+			case plugin.DiffUpdate:
+				d = apitype.DiffUpdate
+			case plugin.DiffUpdateReplace:
+				d = apitype.DiffUpdateReplace
+			default:
+				contract.Failf("unrecognized diff kind %v", v)
+			}
+			detailedDiff[k] = apitype.PropertyDiff{
+				Kind:      d,
+				InputDiff: v.InputDiff,
+			}
+		}
+	}
+
+	return apitype.StepEventMetadata{
+		Op:   string(md.Op),
+		URN:  string(md.URN),
+		Type: string(md.Type),
+
+		Old: convertStepEventStateMetadata(md.Old),
+		New: convertStepEventStateMetadata(md.New),
+
+		Keys:         keys,
+		Diffs:        diffs,
+		DetailedDiff: detailedDiff,
+		Logical:      md.Logical,
+		Provider:     md.Provider,
+	}
+}
+
+func convertStepEventStateMetadata(md *engine.StepEventStateMetadata) *apitype.StepEventStateMetadata {
+	if md == nil {
+		return nil
+	}
+
+	inputs := make(map[string]interface{})
+	for k, v := range md.Inputs {
+		inputs[string(k)] = v
+	}
+	outputs := make(map[string]interface{})
+	for k, v := range md.Outputs {
+		outputs[string(k)] = v
+	}
+
+	return &apitype.StepEventStateMetadata{
+		Type: string(md.Type),
+		URN:  string(md.URN),
+
+		Custom:         md.Custom,
+		Delete:         md.Delete,
+		ID:             string(md.ID),
+		Parent:         string(md.Parent),
+		Protect:        md.Protect,
+		RetainOnDelete: md.RetainOnDelete,
+		Inputs:         inputs,
+		Outputs:        outputs,
+		InitErrors:     md.InitErrors,
+	}
+}
+
+// ConvertEngineEvent converts a raw engine.Event into an apitype.EngineEvent used in the Pulumi
+// REST API and in event logs. Returns an error if the engine event is unknown or not in an expected format.
+// EngineEvent.{ Sequence, Timestamp } are expected to be set by the caller.
+func ConvertEngineEvent(e engine.Event) (apitype.EngineEvent, error) {
+	var apiEvent apitype.EngineEvent
+
+	// Error to return if the payload doesn't match expected.
+	eventTypePayloadMismatch := errors.Errorf("unexpected payload for event type %v", e.Type)
+
+	switch e.Type {
+	case engine.CancelEvent:
+		apiEvent.CancelEvent = &apitype.CancelEvent{}
+
+	case engine.StdoutColorEvent:
+		p, ok := e.Payload.(engine.StdoutEventPayload)
+		if !ok {
+			return apiEvent, eventTypePayloadMismatch
+		}
+		apiEvent.StdoutEvent = &apitype.StdoutEngineEvent{
+			Message: p.Message,
+			Color:   string(p.Color),
+		}
+
+	case engine.DiagEvent:
+		p, ok := e.Payload.(engine.DiagEventPayload)
+		if !ok {
+			return apiEvent, eventTypePayloadMismatch
+		}
+		apiEvent.DiagnosticEvent = &apitype.DiagnosticEvent{
+			URN:       string(p.URN),
+			Prefix:    p.Prefix,
+			Message:   p.Message,
+			Color:     string(p.Color),
+			Severity:  string(p.Severity),
+			Ephemeral: p.Ephemeral,
+		}
+
+	case engine.PolicyViolationEvent:
+		p, ok := e.Payload.(engine.PolicyViolationEventPayload)
+		if !ok {
+			return apiEvent, eventTypePayloadMismatch
+		}
+		apiEvent.PolicyEvent = &apitype.PolicyEvent{
+			ResourceURN:       string(p.ResourceURN),
+			Message:           p.Message,
+			Color:             string(p.Color),
+			PolicyName:        p.PolicyName,
+			PolicyPackName:    p.PolicyPackName,
+			PolicyPackVersion: p.PolicyPackVersion,
+			EnforcementLevel:  string(p.EnforcementLevel),
+		}
+
+	case engine.PolicyRemediationEvent:
+		p, ok := e.Payload.(engine.PolicyRemediationEventPayload)
+		if !ok {
+			return apiEvent, eventTypePayloadMismatch
+		}
+		apiEvent.PolicyRemediationEvent = &apitype.PolicyRemediationEvent{
+			ResourceURN:       string(p.ResourceURN),
+			Message:           p.Message,
+			Color:             string(p.Color),
+			PolicyName:        p.PolicyName,
+			PolicyPackName:    p.PolicyPackName,
+			PolicyPackVersion: p.PolicyPackVersion,
+			Before:            p.Before.Mappable(),
+			After:             p.After.Mappable(),
+		}
+
+	case engine.PreludeEvent:
+		p, ok := e.Payload.(engine.PreludeEventPayload)
+		if !ok {
+			return apiEvent, eventTypePayloadMismatch
+		}
+		// Convert the config bag.
+		cfg := make(map[string]string)
+		for k, v := range p.Config {
+			cfg[k] = v
+		}
+		apiEvent.PreludeEvent = &apitype.PreludeEvent{
+			Config: cfg,
+		}
+
+	case engine.SummaryEvent:
+		p, ok := e.Payload.(engine.SummaryEventPayload)
+		if !ok {
+			return apiEvent, eventTypePayloadMismatch
+		}
+		// Convert the resource changes.
+		changes := make(map[string]int)
+		for op, count := range p.ResourceChanges {
+			changes[string(op)] = count
+		}
+		apiEvent.SummaryEvent = &apitype.SummaryEvent{
+			MaybeCorrupt:    p.MaybeCorrupt,
+			DurationSeconds: int(p.Duration.Seconds()),
+			ResourceChanges: changes,
+			PeakMemoryBytes: p.PeakMemory,
+		}
+
+	case engine.ResourcePreEvent:
+		p, ok := e.Payload.(engine.ResourcePreEventPayload)
+		if !ok {
+			return apiEvent, eventTypePayloadMismatch
+		}
+		apiEvent.ResourcePreEvent = &apitype.ResourcePreEvent{
+			Metadata: convertStepEventMetadata(p.Metadata),
+			Planning: p.Planning,
+		}
+
+	case engine.ResourceOutputsEvent:
+		p, ok := e.Payload.(engine.ResourceOutputsEventPayload)
+		if !ok {
+			return apiEvent, eventTypePayloadMismatch
+		}
+		apiEvent.ResOutputsEvent = &apitype.ResOutputsEvent{
+			Metadata: convertStepEventMetadata(p.Metadata),
+			Planning: p.Planning,
+		}
+
+	case engine.ResourceOperationFailed:
+		p, ok := e.Payload.(engine.ResourceOperationFailedPayload)
+		if !ok {
+			return apiEvent, eventTypePayloadMismatch
+		}
+		apiEvent.ResOpFailedEvent = &apitype.ResOpFailedEvent{
+			Metadata: convertStepEventMetadata(p.Metadata),
+			Status:   int(p.Status),
+			Steps:    p.Steps,
+		}
+
+	default:
+		return apiEvent, errors.Errorf("unknown event type %q", e.Type)
+	}
+
+	return apiEvent, nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+)
+
+func TestLogEvents(t *testing.T) {
+	urn := resource.NewURN("dev", "proj", "", "aws:s3/bucket:Bucket", "my-bucket")
+	sent := []engine.Event{
+		{Type: engine.DiagEvent, Payload: engine.DiagEventPayload{Severity: diag.Debug, Message: "debug"}},
+		{Type: engine.ResourcePreEvent, Payload: engine.ResourcePreEventPayload{
+			Metadata: engine.StepEventMetadata{Op: deploy.OpCreate, URN: urn, Type: urn.Type()},
+		}},
+		{Type: engine.DiagEvent, Payload: engine.DiagEventPayload{URN: urn, Severity: diag.Error, Message: "oops"}},
+		{Type: engine.SummaryEvent, Payload: engine.SummaryEventPayload{
+			ResourceChanges: engine.ResourceChanges{deploy.OpCreate: 1},
+		}},
+	}
+
+	var buf bytes.Buffer
+	events := make(chan engine.Event)
+	logged := logEvents(events, &buf, false)
+	go func() {
+		for _, e := range sent {
+			events <- e
+		}
+		close(events)
+	}()
+
+	// Every event is passed on, whether or not it is logged.
+	var received []engine.Event
+	for e := range logged {
+		received = append(received, e)
+	}
+	assert.Equal(t, sent, received)
+
+	// Debug diagnostics are not logged, and the rest are numbered in order.
+	var lines []apitype.EngineEvent
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var e apitype.EngineEvent
+		if assert.NoError(t, json.Unmarshal(scanner.Bytes(), &e)) {
+			lines = append(lines, e)
+		}
+	}
+	if assert.Len(t, lines, 3) {
+		assert.Equal(t, 0, lines[0].Sequence)
+		if assert.NotNil(t, lines[0].ResourcePreEvent) {
+			assert.Equal(t, "create", lines[0].ResourcePreEvent.Metadata.Op)
+			assert.Equal(t, string(urn), lines[0].ResourcePreEvent.Metadata.URN)
+		}
+		assert.Equal(t, 1, lines[1].Sequence)
+		if assert.NotNil(t, lines[1].DiagnosticEvent) {
+			assert.Equal(t, "oops", lines[1].DiagnosticEvent.Message)
+			assert.Equal(t, "error", lines[1].DiagnosticEvent.Severity)
+		}
+		assert.Equal(t, 2, lines[2].Sequence)
+		if assert.NotNil(t, lines[2].SummaryEvent) {
+			assert.Equal(t, map[string]int{"create": 1}, lines[2].SummaryEvent.ResourceChanges)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+)
+
+func init() {
+	RegisterRenderer("github", newGitHubRenderer)
+}
+
+// gitHubRenderer writes the results of a command's final operation as GitHub Actions workflow commands, which GitHub
+// shows as annotations on the workflow run and, if they name a file, inline on that file in pull requests. Errors and
+// mandatory policy violations are errors, advisory violations and deletes and replacements are warnings, and other
+// changes are notices. The annotations are written once all of the operations have finished, so that they do not
+// interleave with the display, and only for the last of them, so that `pulumi up` does not report its preview's
+// changes a second time.
+//
+// Annotations about a resource are placed at its declaration in the program's source, where that is known. Others are
+// attached to the renderer's file, if one was given.
+type gitHubRenderer struct {
+	file    string
+	w       io.Writer
+	sources *sourcePositions
+	results []*operationResults
+}
+
+func newGitHubRenderer(file string) (Renderer, error) {
+	root, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	// GitHub resolves the files of annotations against the root of the repository that was checked out.
+	relative := root
+	if workspace := os.Getenv("GITHUB_WORKSPACE"); workspace != "" {
+		relative = workspace
+	}
+	return &gitHubRenderer{file: file, w: os.Stdout, sources: newSourcePositions(root, relative)}, nil
+}
+
+func (r *gitHubRenderer) AcceptsEvent(t engine.EventType) bool {
+	return ciEventTypes[t]
+}
+
+func (r *gitHubRenderer) Render(info RenderInfo, events <-chan apitype.EngineEvent) error {
+	r.results = append(r.results, collectResults(info, events))
+	return nil
+}
+
+func (r *gitHubRenderer) Close() error {
+	if len(r.results) == 0 {
+		return nil
+	}
+	res := r.results[len(r.results)-1]
+
+	for _, msg := range res.Errors {
+		if err := r.annotate("error", res.Name(), msg, nil); err != nil {
+			return err
+		}
+	}
+	if err := r.annotateViolations(res, "", res.Violations); err != nil {
+		return err
+	}
+
+	for _, rr := range res.Resources {
+		for _, msg := range rr.Failures {
+			pos := r.position(rr.URN)
+			if err := r.annotate("error", fmt.Sprintf("%s: %s", res.Name(), rr.URN), msg, pos); err != nil {
+				return err
+			}
+		}
+		if err := r.annotateViolations(res, rr.URN, rr.Violations); err != nil {
+			return err
+		}
+		if rr.Changed() {
+			level := "notice"
+			if rr.Op == string(deploy.OpDelete) || rr.Op == string(deploy.OpReplace) {
+				level = "warning"
+			}
+			msg := fmt.Sprintf("%s %s", rr.Op, rr.URN)
+			if len(rr.Diffs) > 0 {
+				msg += fmt.Sprintf(" (changes %s)", strings.Join(rr.Diffs, ", "))
+			}
+			if err := r.annotate(level, res.Name(), msg, r.position(rr.URN)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// position returns the position of the declaration of the resource with the given URN, or nil if it isn't known.
+func (r *gitHubRenderer) position(urn string) *sourcePosition {
+	if r.sources == nil || strings.Count(urn, resource.URNNameDelimiter) < 3 {
+		return nil
+	}
+	return r.sources.lookup(string(resource.URN(urn).Name()))
+}
+
+func (r *gitHubRenderer) annotateViolations(res *operationResults, urn string,
+	violations []apitype.PolicyEvent) error {
+
+	for _, v := range violations {
+		level := "warning"
+		if isMandatory(v) {
+			level = "error"
+		}
+		msg := describeViolation(v)
+		var pos *sourcePosition
+		if urn != "" {
+			msg = fmt.Sprintf("%s: %s", urn, msg)
+			pos = r.position(urn)
+		}
+		if err := r.annotate(level, fmt.Sprintf("%s: policy violation", res.Name()), msg, pos); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// annotate writes a single workflow command of the given level: error, warning, or notice. The annotation is placed
+// at the given source position if it is non-nil, and otherwise attached to the renderer's file, if any.
+func (r *gitHubRenderer) annotate(level, title, message string, pos *sourcePosition) error {
+	params := "title=" + escapeGitHubProperty(title)
+	switch {
+	case pos != nil:
+		params = fmt.Sprintf("file=%s,line=%d,%s", escapeGitHubProperty(pos.File), pos.Line, params)
+	case r.file != "":
+		params = "file=" + escapeGitHubProperty(r.file) + "," + params
+	}
+	_, err := fmt.Fprintf(r.w, "::%s %s::%s\n", level, params, escapeGitHubData(message))
+	return err
+}
+
+// escapeGitHubData escapes the message of a workflow command.
+func escapeGitHubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeGitHubProperty escapes a parameter of a workflow command.
+func escapeGitHubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
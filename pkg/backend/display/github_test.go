@@ -0,0 +1,95 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+)
+
+func TestGitHubAnnotations(t *testing.T) {
+	var buf bytes.Buffer
+	r := &gitHubRenderer{file: "index.ts", w: &buf}
+
+	res := collectTestResults(RenderInfo{Project: "proj", Stack: "dev", Action: apitype.UpdateUpdate, IsPreview: true})
+	r.results = append(r.results, res)
+	assert.NoError(t, r.Close())
+
+	title := "file=index.ts,title=proj/dev preview"
+	assert.Equal(t, []string{
+		"::error " + title + "::preview failed",
+		"::warning " + title + "%3A policy violation::[hygiene] owner-tag: stack has no owner tag",
+		"::error " + title + "%3A policy violation::urn:pulumi:dev::proj::aws:s3/bucket:Bucket::logs: " +
+			"[security] no-public-buckets: buckets must not be public",
+		"::notice " + title + "::update urn:pulumi:dev::proj::aws:s3/bucket:Bucket::logs (changes acl, tags)",
+		"::error " + title + "%3A urn%3Apulumi%3Adev%3A%3Aproj%3A%3Aaws%3Ards/instance%3AInstance%3A%3Adb" +
+			"::instance quota exceeded",
+		"::warning " + title + "::replace urn:pulumi:dev::proj::aws:rds/instance:Instance::db (changes engine)",
+	}, strings.Split(strings.TrimSpace(buf.String()), "\n"))
+
+	assert.Equal(t, "a%25b%0Ac", escapeGitHubData("a%b\nc"))
+	assert.Equal(t, "a%3Ab%2Cc", escapeGitHubProperty("a:b,c"))
+}
+
+func TestGitHubAnnotationsAtSourcePositions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-github-")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	// The bucket is declared once. The database's name appears twice, so its position is ambiguous, and the copy of
+	// the bucket's name in a dependency is ignored.
+	program := "import * as aws from \"@pulumi/aws\";\n" +
+		"\n" +
+		"const logs = new aws.s3.Bucket('logs', { acl: \"private\" });\n" +
+		"const db = new aws.rds.Instance(\"db\");\n" +
+		"const replica = new aws.rds.Instance(\"replica\", { replicateSourceDb: \"db\" });\n"
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "src", "node_modules", "dep"), 0700))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "src", "index.ts"), []byte(program), 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "src", "node_modules", "dep", "index.js"),
+		[]byte("bucket(\"logs\");\n"), 0600))
+
+	var buf bytes.Buffer
+	r := &gitHubRenderer{file: "Pulumi.yaml", w: &buf, sources: newSourcePositions(filepath.Join(dir, "src"), dir)}
+
+	// `pulumi up` renders its preview and its update; only the update is annotated.
+	r.results = append(r.results,
+		collectTestResults(RenderInfo{Project: "proj", Stack: "dev", Action: apitype.UpdateUpdate, IsPreview: true}),
+		collectTestResults(RenderInfo{Project: "proj", Stack: "dev", Action: apitype.UpdateUpdate}))
+	assert.NoError(t, r.Close())
+
+	title := "title=proj/dev update"
+	assert.Equal(t, []string{
+		"::error file=Pulumi.yaml," + title + "::preview failed",
+		"::warning file=Pulumi.yaml," + title + "%3A policy violation::[hygiene] owner-tag: stack has no owner tag",
+		"::error file=src/index.ts,line=3," + title + "%3A policy violation::" +
+			"urn:pulumi:dev::proj::aws:s3/bucket:Bucket::logs: [security] no-public-buckets: buckets must not be public",
+		"::notice file=src/index.ts,line=3," + title +
+			"::update urn:pulumi:dev::proj::aws:s3/bucket:Bucket::logs (changes acl, tags)",
+		"::error file=Pulumi.yaml," + title + "%3A urn%3Apulumi%3Adev%3A%3Aproj%3A%3Aaws%3Ards/instance%3AInstance%3A%3Adb" +
+			"::instance quota exceeded",
+		"::warning file=Pulumi.yaml," + title +
+			"::replace urn:pulumi:dev::proj::aws:rds/instance:Instance::db (changes engine)",
+	}, strings.Split(strings.TrimSpace(buf.String()), "\n"))
+}
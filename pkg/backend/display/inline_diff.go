@@ -0,0 +1,58 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+)
+
+// MaxInlineDiffLines is the number of lines of a resource's diff that the progress display shows inline in a terminal
+// before eliding the rest. The complete diff can be expanded once the operation completes.
+const MaxInlineDiffLines = 40
+
+// HasInlineDiff returns true if the given step changes its resource, and so has a diff worth showing inline.
+func HasInlineDiff(step engine.StepEventMetadata) bool {
+	switch step.Op {
+	case deploy.OpSame, deploy.OpRead, deploy.OpReadDiscard, deploy.OpReadReplacement, deploy.OpRefresh:
+		return false
+	default:
+		return !isRootStack(step)
+	}
+}
+
+// RenderResourceDiff renders the complete property diff of a step, regardless of whether diffs are otherwise being
+// summarized. If maxLines is positive, the diff is truncated to that many lines, and a note saying how many were
+// elided is added in their place.
+func RenderResourceDiff(step engine.StepEventMetadata, planning, debug bool, maxLines int, opts Options) string {
+	opts.SummaryDiff = false
+
+	var buf bytes.Buffer
+	renderDiff(&buf, step, planning, debug, make(map[resource.URN]engine.StepEventMetadata), opts)
+
+	diff := strings.TrimRight(buf.String(), "\n")
+	lines := strings.Split(diff, "\n")
+	if maxLines <= 0 || len(lines) <= maxLines {
+		return diff + "\n"
+	}
+	return strings.Join(lines[:maxLines], "\n") + opts.Color.Colorize(colors.Reset) + "\n" +
+		fmt.Sprintf("    ... %d more lines, shown when the resource is expanded\n", len(lines)-maxLines)
+}
@@ -0,0 +1,73 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+)
+
+func TestHasInlineDiff(t *testing.T) {
+	urn := resource.NewURN("dev", "proj", "", "aws:s3/bucket:Bucket", "my-bucket")
+	stackURN := resource.NewURN("dev", "proj", "", "pulumi:pulumi:Stack", "proj-dev")
+
+	for op, expected := range map[deploy.StepOp]bool{
+		deploy.OpSame:    false,
+		deploy.OpRead:    false,
+		deploy.OpRefresh: false,
+		deploy.OpCreate:  true,
+		deploy.OpUpdate:  true,
+		deploy.OpDelete:  true,
+		deploy.OpReplace: true,
+	} {
+		step := engine.StepEventMetadata{Op: op, URN: urn, Type: urn.Type()}
+		assert.Equal(t, expected, HasInlineDiff(step), string(op))
+	}
+
+	root := engine.StepEventMetadata{Op: deploy.OpUpdate, URN: stackURN, Type: stackURN.Type()}
+	assert.False(t, HasInlineDiff(root))
+}
+
+func TestRenderResourceDiff(t *testing.T) {
+	urn := resource.NewURN("dev", "proj", "", "aws:s3/bucket:Bucket", "my-bucket")
+	inputs := resource.PropertyMap{}
+	for i := 0; i < 10; i++ {
+		inputs[resource.PropertyKey(fmt.Sprintf("prop%02d", i))] = resource.NewStringProperty("value")
+	}
+	state := &engine.StepEventStateMetadata{URN: urn, Type: urn.Type(), Inputs: inputs}
+	step := engine.StepEventMetadata{Op: deploy.OpCreate, URN: urn, Type: urn.Type(), New: state, Res: state}
+	opts := Options{Color: colors.Never, SummaryDiff: true}
+
+	// With no limit, every property is shown, even though diffs would otherwise be summarized.
+	full := RenderResourceDiff(step, true, false, 0, opts)
+	for k := range inputs {
+		assert.Contains(t, full, string(k))
+	}
+	lines := strings.Split(strings.TrimRight(full, "\n"), "\n")
+	assert.True(t, len(lines) > 5)
+
+	// With a limit, the diff is truncated and the number of elided lines is noted.
+	truncated := RenderResourceDiff(step, true, false, 5, opts)
+	assert.Contains(t, truncated, fmt.Sprintf("... %d more lines", len(lines)-5))
+	assert.NotContains(t, truncated, "prop09")
+}
@@ -122,6 +122,14 @@ func ShowJSONEvents(op string, action apitype.UpdateKind, events <-chan engine.E
 					Severity: p.Severity,
 				})
 			}
+		case engine.PolicyRemediationEvent:
+			// Report remediations as informational messages, as the steps already reflect their changes.
+			p := e.Payload.(engine.PolicyRemediationEventPayload)
+			digest.Diagnostics = append(digest.Diagnostics, previewDiagnostic{
+				URN:      p.ResourceURN,
+				Message:  colors.Never.Colorize(p.Prefix + p.Message),
+				Severity: diag.Info,
+			})
 		case engine.StdoutColorEvent:
 			// Append stdout events as informational messages, and elide all colorization.
 			p := e.Payload.(engine.StdoutEventPayload)
@@ -186,6 +194,7 @@ func ShowJSONEvents(op string, action apitype.UpdateKind, events <-chan engine.E
 			digest.Duration = p.Duration
 			digest.ChangeSummary = p.ResourceChanges
 			digest.MaybeCorrupt = p.MaybeCorrupt
+			digest.PeakMemory = p.PeakMemory
 		default:
 			contract.Failf("unknown event type '%s'", e.Type)
 		}
@@ -214,6 +223,8 @@ type previewDigest struct {
 	ChangeSummary engine.ResourceChanges `json:"changeSummary,omitempty"`
 	// MaybeCorrupt indicates whether one or more resources may be corrupt.
 	MaybeCorrupt bool `json:"maybeCorrupt,omitempty"`
+	// PeakMemory records the peak heap usage of the preview in bytes, if it was measured.
+	PeakMemory uint64 `json:"peakMemory,omitempty"`
 }
 
 // propertyDiff contains information about the difference in a single property value.
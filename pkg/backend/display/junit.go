@@ -0,0 +1,162 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/engine"
+)
+
+func init() {
+	RegisterRenderer("junit", newJUnitRenderer)
+}
+
+// junitRenderer writes the results of a command's operations to a file as JUnit XML, which CI systems display as test
+// results. Each operation is a test suite, and each resource is a test case that fails if any of its steps fail or it
+// violates a mandatory policy. Errors and violations that do not concern a resource fail a test case of their own.
+type junitRenderer struct {
+	path    string
+	results []*operationResults
+}
+
+func newJUnitRenderer(path string) (Renderer, error) {
+	if path == "" {
+		return nil, errors.New("the path of the file to write JUnit XML to is required, as junit=<path>")
+	}
+	return &junitRenderer{path: path}, nil
+}
+
+func (r *junitRenderer) AcceptsEvent(t engine.EventType) bool {
+	return ciEventTypes[t]
+}
+
+func (r *junitRenderer) Render(info RenderInfo, events <-chan apitype.EngineEvent) error {
+	r.results = append(r.results, collectResults(info, events))
+	return nil
+}
+
+func (r *junitRenderer) Close() error {
+	b, err := marshalJUnit(r.results)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.path, b, 0644)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string         `xml:"name,attr"`
+	ClassName string         `xml:"classname,attr"`
+	Failures  []junitFailure `xml:"failure"`
+	SystemOut string         `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Type    string `xml:"type,attr"`
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// newJUnitFailure returns a failure whose message is the first line of the given text.
+func newJUnitFailure(typ, text string) junitFailure {
+	message := text
+	if nl := strings.Index(message, "\n"); nl != -1 {
+		message = message[:nl]
+	}
+	return junitFailure{Type: typ, Message: message, Text: text}
+}
+
+// marshalJUnit returns the JUnit XML document that reports the given operations' results.
+func marshalJUnit(results []*operationResults) ([]byte, error) {
+	doc := junitTestSuites{}
+	for _, res := range results {
+		suite := junitTestSuite{Name: res.Name()}
+
+		if len(res.Errors) > 0 || len(res.Violations) > 0 {
+			tc := junitTestCase{Name: fmt.Sprintf("%s/%s", res.Info.Project, res.Info.Stack), ClassName: "stack"}
+			for _, msg := range res.Errors {
+				tc.Failures = append(tc.Failures, newJUnitFailure("error", msg))
+			}
+			tc.Failures, tc.SystemOut = appendJUnitViolations(tc.Failures, tc.SystemOut, res.Violations)
+			suite.Cases = append(suite.Cases, tc)
+		}
+
+		for _, rr := range res.Resources {
+			tc := junitTestCase{Name: rr.URN, ClassName: rr.Type}
+			if rr.Changed() {
+				tc.SystemOut = rr.Op
+				if len(rr.Diffs) > 0 {
+					tc.SystemOut += ": " + strings.Join(rr.Diffs, ", ")
+				}
+				tc.SystemOut += "\n"
+			}
+			for _, msg := range rr.Failures {
+				tc.Failures = append(tc.Failures, newJUnitFailure("error", msg))
+			}
+			tc.Failures, tc.SystemOut = appendJUnitViolations(tc.Failures, tc.SystemOut, rr.Violations)
+			suite.Cases = append(suite.Cases, tc)
+		}
+
+		for _, tc := range suite.Cases {
+			suite.Tests++
+			if len(tc.Failures) > 0 {
+				suite.Failures++
+			}
+		}
+		doc.Tests += suite.Tests
+		doc.Failures += suite.Failures
+		doc.Suites = append(doc.Suites, suite)
+	}
+
+	b, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(b, '\n')...), nil
+}
+
+// appendJUnitViolations reports mandatory policy violations as failures, and advisory ones as output.
+func appendJUnitViolations(failures []junitFailure, out string,
+	violations []apitype.PolicyEvent) ([]junitFailure, string) {
+
+	for _, v := range violations {
+		if isMandatory(v) {
+			failures = append(failures, newJUnitFailure("policy", describeViolation(v)))
+		} else {
+			out += fmt.Sprintf("advisory policy violation: %s\n", describeViolation(v))
+		}
+	}
+	return failures, out
+}
@@ -0,0 +1,122 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+)
+
+// testResultEvents are the events of an operation that changes, fails, and reports policy violations for resources.
+func testResultEvents() []apitype.EngineEvent {
+	bucket := "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::logs"
+	db := "urn:pulumi:dev::proj::aws:rds/instance:Instance::db"
+	stack := "urn:pulumi:dev::proj::pulumi:pulumi:Stack::proj-dev"
+	return []apitype.EngineEvent{
+		{ResourcePreEvent: &apitype.ResourcePreEvent{Metadata: apitype.StepEventMetadata{
+			Op: "same", URN: stack, Type: "pulumi:pulumi:Stack"}}},
+		{ResourcePreEvent: &apitype.ResourcePreEvent{Metadata: apitype.StepEventMetadata{
+			Op: "update", URN: bucket, Type: "aws:s3/bucket:Bucket", Diffs: []string{"acl", "tags"}}}},
+		{PolicyEvent: &apitype.PolicyEvent{ResourceURN: bucket, Message: "buckets must not be public",
+			PolicyName: "no-public-buckets", PolicyPackName: "security", EnforcementLevel: "mandatory"}},
+		{ResourcePreEvent: &apitype.ResourcePreEvent{Metadata: apitype.StepEventMetadata{
+			Op: "replace", URN: db, Type: "aws:rds/instance:Instance", Diffs: []string{"engine"}}}},
+		{ResourcePreEvent: &apitype.ResourcePreEvent{Metadata: apitype.StepEventMetadata{
+			Op: "create-replacement", URN: db, Type: "aws:rds/instance:Instance"}}},
+		{DiagnosticEvent: &apitype.DiagnosticEvent{URN: db, Severity: "error",
+			Message: "<{%fg 1%}>instance quota exceeded<{%reset%}>\n"}},
+		{PolicyEvent: &apitype.PolicyEvent{Message: "stack has no owner tag",
+			PolicyName: "owner-tag", PolicyPackName: "hygiene", EnforcementLevel: "advisory"}},
+		{DiagnosticEvent: &apitype.DiagnosticEvent{Severity: "error", Message: "preview failed\n"}},
+	}
+}
+
+func collectTestResults(info RenderInfo) *operationResults {
+	events := make(chan apitype.EngineEvent)
+	go func() {
+		for _, e := range testResultEvents() {
+			events <- e
+		}
+		close(events)
+	}()
+	return collectResults(info, events)
+}
+
+func TestCollectResults(t *testing.T) {
+	res := collectTestResults(RenderInfo{Project: "proj", Stack: "dev", Action: apitype.UpdateUpdate, IsPreview: true})
+	assert.Equal(t, "proj/dev preview", res.Name())
+	assert.Equal(t, []string{"preview failed"}, res.Errors)
+	if assert.Len(t, res.Violations, 1) {
+		assert.Equal(t, "[hygiene] owner-tag: stack has no owner tag", describeViolation(res.Violations[0]))
+	}
+
+	if assert.Len(t, res.Resources, 3) {
+		assert.False(t, res.Resources[0].Changed())
+
+		bucket := res.Resources[1]
+		assert.Equal(t, "update", bucket.Op)
+		assert.Equal(t, []string{"acl", "tags"}, bucket.Diffs)
+		assert.Len(t, bucket.Violations, 1)
+
+		// The steps of a replacement are reported as the replace, and colors are removed from messages.
+		db := res.Resources[2]
+		assert.Equal(t, "replace", db.Op)
+		assert.Equal(t, []string{"instance quota exceeded"}, db.Failures)
+	}
+}
+
+func TestMarshalJUnit(t *testing.T) {
+	res := collectTestResults(RenderInfo{Project: "proj", Stack: "dev", Action: apitype.UpdateUpdate, IsPreview: true})
+	b, err := marshalJUnit([]*operationResults{res})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var doc junitTestSuites
+	if !assert.NoError(t, xml.Unmarshal(b, &doc)) {
+		return
+	}
+	assert.Equal(t, 4, doc.Tests)
+	assert.Equal(t, 3, doc.Failures)
+	if !assert.Len(t, doc.Suites, 1) || !assert.Len(t, doc.Suites[0].Cases, 4) {
+		return
+	}
+	assert.Equal(t, "proj/dev preview", doc.Suites[0].Name)
+
+	cases := doc.Suites[0].Cases
+	assert.Equal(t, "proj/dev", cases[0].Name)
+	assert.Equal(t, []junitFailure{{Type: "error", Message: "preview failed", Text: "preview failed"}},
+		cases[0].Failures)
+	assert.Equal(t, "advisory policy violation: [hygiene] owner-tag: stack has no owner tag\n", cases[0].SystemOut)
+
+	assert.Empty(t, cases[1].Failures)
+	assert.Empty(t, cases[1].SystemOut)
+
+	assert.Equal(t, "aws:s3/bucket:Bucket", cases[2].ClassName)
+	assert.Equal(t, "update: acl, tags\n", cases[2].SystemOut)
+	if assert.Len(t, cases[2].Failures, 1) {
+		assert.Equal(t, "policy", cases[2].Failures[0].Type)
+	}
+
+	assert.Equal(t, "replace: engine\n", cases[3].SystemOut)
+	assert.Len(t, cases[3].Failures, 1)
+
+	_, err = newJUnitRenderer("")
+	assert.Error(t, err)
+}
@@ -14,7 +14,11 @@
 
 package display
 
-import "github.com/pulumi/pulumi/pkg/diag/colors"
+import (
+	"io"
+
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+)
 
 // Type of output to display.
 type Type int
@@ -26,6 +30,8 @@ const (
 	DisplayDiff
 	// DisplayQuery displays query output.
 	DisplayQuery
+	// DisplayAccessible displays an update as plain, linear text suitable for screen readers.
+	DisplayAccessible
 )
 
 // Options controls how the output of events are rendered
@@ -40,4 +46,9 @@ type Options struct {
 	Type                 Type                // type of display (rich diff, progress, or query).
 	JSONDisplay          bool                // true if we should emit the entire diff as JSON.
 	Debug                bool                // true to enable debug output.
+	Verbosity            Verbosity           // how much detail the accessible display reports.
+	LowBandwidth         bool                // true to summarize verbose events and fetch full payloads on demand.
+	InlineDiffs          bool                // true to show full property diffs in the progress display.
+	EventLog             io.Writer           // if non-nil, receives each event as a line of JSON.
+	Renderers            []Renderer          // renderers that each receive the events, alongside the display.
 }
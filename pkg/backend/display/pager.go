@@ -0,0 +1,58 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Page writes text to stdout. If stdout is a terminal that is too short to show all of the text at once, the text is
+// shown through the user's pager instead, so that it can be scrolled.
+func Page(text string) error {
+	_, height, err := terminal.GetSize(int(os.Stdout.Fd()))
+	if err != nil || strings.Count(text, "\n") < height {
+		_, err = os.Stdout.WriteString(text)
+		return err
+	}
+
+	args := pagerCommand()
+	// nolint: gas
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = strings.NewReader(text), os.Stdout, os.Stderr
+	if err = cmd.Run(); err != nil {
+		// If the pager can't be run, fall back to writing the text directly.
+		if _, ok := err.(*exec.ExitError); !ok {
+			_, err = os.Stdout.WriteString(text)
+		}
+	}
+	return err
+}
+
+// pagerCommand returns the command line of the user's pager: $PAGER if it is set, and otherwise `less -R`, which
+// passes colors through, or `more` on Windows.
+func pagerCommand() []string {
+	if pager := strings.Fields(os.Getenv("PAGER")); len(pager) > 0 {
+		return pager
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"more"}
+	}
+	return []string{"less", "-R"}
+}
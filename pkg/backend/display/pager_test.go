@@ -0,0 +1,46 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPagerCommand(t *testing.T) {
+	old, had := os.LookupEnv("PAGER")
+	defer func() {
+		if had {
+			os.Setenv("PAGER", old)
+		} else {
+			os.Unsetenv("PAGER")
+		}
+	}()
+
+	// $PAGER is split into a command and its arguments.
+	os.Setenv("PAGER", "less -S -R")
+	assert.Equal(t, []string{"less", "-S", "-R"}, pagerCommand())
+
+	// Otherwise, a pager that passes colors through is used.
+	os.Unsetenv("PAGER")
+	if runtime.GOOS == "windows" {
+		assert.Equal(t, []string{"more"}, pagerCommand())
+	} else {
+		assert.Equal(t, []string{"less", "-R"}, pagerCommand())
+	}
+}
@@ -192,6 +192,8 @@ func getEventUrnAndMetadata(event engine.Event) (resource.URN, *engine.StepEvent
 		return event.Payload.(engine.DiagEventPayload).URN, nil
 	} else if event.Type == engine.PolicyViolationEvent {
 		return event.Payload.(engine.PolicyViolationEventPayload).ResourceURN, nil
+	} else if event.Type == engine.PolicyRemediationEvent {
+		return event.Payload.(engine.PolicyRemediationEventPayload).ResourceURN, nil
 	}
 
 	return "", nil
@@ -269,7 +271,9 @@ func ShowProgressEvents(op string, action apitype.UpdateKind, stack tokens.QName
 
 	terminalWidth, terminalHeight, err := terminal.GetSize(int(os.Stdout.Fd()))
 	contract.IgnoreError(err)
-	display.isTerminal = opts.IsInteractive
+	// Only redraw the display in place if the terminal can move the cursor itself; legacy Windows consoles get the
+	// same line-by-line output as a non-interactive session.
+	display.isTerminal = opts.IsInteractive && cmdutil.VirtualTerminal()
 	display.terminalWidth = terminalWidth
 	display.terminalHeight = terminalHeight
 
@@ -737,6 +741,11 @@ func (display *ProgressDisplay) processEndSteps() {
 		}
 	}
 
+	// If requested, show the full property diff of each changed resource.
+	if display.opts.InlineDiffs && display.writeInlineDiffs() {
+		wroteDiagnosticHeader = true
+	}
+
 	// If we get stack outputs, display them at the end.
 	var wroteOutputs bool
 	if display.stackUrn != "" && display.seenStackOutputs && !display.opts.SuppressOutputs {
@@ -765,6 +774,37 @@ func (display *ProgressDisplay) processEndSteps() {
 	}
 }
 
+// writeInlineDiffs writes the property diffs of the resources that were changed, in URN order, returning true if it
+// wrote any. In a terminal, long diffs are truncated; the user can expand them once the operation completes. Otherwise
+// they are written in full.
+func (display *ProgressDisplay) writeInlineDiffs() bool {
+	var urns []string
+	for urn, row := range display.eventUrnToResourceRow {
+		if HasInlineDiff(row.Step()) {
+			urns = append(urns, string(urn))
+		}
+	}
+	if len(urns) == 0 {
+		return false
+	}
+	sort.Strings(urns)
+
+	display.writeBlankLine()
+	display.writeSimpleMessage(display.opts.Color.Colorize(colors.SpecHeadline + "Diffs:" + colors.Reset))
+	for _, urn := range urns {
+		step := display.eventUrnToResourceRow[resource.URN(urn)].Step()
+		maxLines := 0
+		if display.isTerminal {
+			maxLines = MaxInlineDiffLines
+		}
+		diff := RenderResourceDiff(step, display.isPreview, display.opts.Debug, maxLines, display.opts)
+		for _, line := range splitIntoDisplayableLines(diff) {
+			display.writeSimpleMessage("  " + strings.TrimRightFunc(line, unicode.IsSpace))
+		}
+	}
+	return true
+}
+
 func (display *ProgressDisplay) mergeStreamPayloadsToSinglePayload(
 	payloads []engine.DiagEventPayload) engine.DiagEventPayload {
 	buf := bytes.Buffer{}
@@ -963,6 +1003,9 @@ func (display *ProgressDisplay) processNormalEvent(event engine.Event) {
 	} else if event.Type == engine.PolicyViolationEvent {
 		// also record this policy violation so we print it at the end.
 		row.RecordPolicyViolationEvent(event)
+	} else if event.Type == engine.PolicyRemediationEvent {
+		// also record this policy remediation so we print it at the end.
+		row.RecordPolicyRemediationEvent(event)
 	} else {
 		contract.Failf("Unhandled event type '%s'", event.Type)
 	}
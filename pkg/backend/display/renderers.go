@@ -0,0 +1,264 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+)
+
+// RenderInfo describes the operation whose events a Renderer is given.
+type RenderInfo struct {
+	Operation string             // the description of the operation, e.g. "Previewing update".
+	Action    apitype.UpdateKind // the kind of update the operation is part of.
+	Stack     tokens.QName       // the stack the operation targets.
+	Project   tokens.PackageName // the project of the stack.
+	IsPreview bool               // true if the operation is a preview.
+}
+
+// Renderer renders engine events alongside the CLI's own display, e.g. into a report or a file of test results. A
+// command may run several operations -- `pulumi up` previews an update before running it -- and a renderer is given
+// the events of each in turn. Renderers are registered with RegisterRenderer so that they can be chosen by name.
+type Renderer interface {
+	// Render renders the events of a single operation. It is called once per operation, and should read events until
+	// the channel is closed. If it returns early, the operation's remaining events are discarded.
+	Render(info RenderInfo, events <-chan apitype.EngineEvent) error
+	// Close is called once the command's operations have finished, so that the renderer can complete its output.
+	Close() error
+}
+
+// EventFilter may be implemented by a Renderer that only renders some types of events. Events of other types are
+// never sent to it.
+type EventFilter interface {
+	// AcceptsEvent returns true if the renderer renders events of the given type.
+	AcceptsEvent(t engine.EventType) bool
+}
+
+// RendererFactory creates a Renderer from the argument it was chosen with, which is empty if there is none.
+type RendererFactory func(arg string) (Renderer, error)
+
+// ExternalRendererPrefix is the prefix of the executables that render events as external display plugins.
+const ExternalRendererPrefix = "pulumi-display-"
+
+var (
+	renderersLock sync.Mutex
+	renderers     = make(map[string]RendererFactory)
+)
+
+// RegisterRenderer makes the renderer created by the given factory available under the given name. It is typically
+// called from an init function; registering the same name twice is an error.
+func RegisterRenderer(name string, factory RendererFactory) {
+	contract.Require(name != "", "name")
+	contract.Require(factory != nil, "factory")
+
+	renderersLock.Lock()
+	defer renderersLock.Unlock()
+	_, has := renderers[name]
+	contract.Assertf(!has, "renderer %q is already registered", name)
+	renderers[name] = factory
+}
+
+// RegisteredRenderers returns the sorted names of the registered renderers.
+func RegisteredRenderers() []string {
+	renderersLock.Lock()
+	defer renderersLock.Unlock()
+
+	var names []string
+	for name := range renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewRenderer creates the renderer chosen by the given spec, of the form `name[=arg]`. If no renderer is registered
+// under the name, an executable named pulumi-display-<name> on the PATH is run as an external renderer: each event is
+// written to its standard input as a line of JSON, in the form of an apitype.EngineEvent, and the argument, if any, is
+// passed as its only command line argument.
+func NewRenderer(spec string) (Renderer, error) {
+	name, arg := spec, ""
+	if eq := strings.Index(spec, "="); eq != -1 {
+		name, arg = spec[:eq], spec[eq+1:]
+	}
+	if name == "" {
+		return nil, errors.Errorf("invalid display plugin %q: missing name", spec)
+	}
+
+	renderersLock.Lock()
+	factory, has := renderers[name]
+	renderersLock.Unlock()
+
+	var r Renderer
+	var err error
+	if has {
+		r, err = factory(arg)
+	} else {
+		r, err = newExternalRenderer(name, arg)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "display plugin %s", name)
+	}
+	return &namedRenderer{name: name, Renderer: r}, nil
+}
+
+// namedRenderer identifies the renderer in the errors it returns.
+type namedRenderer struct {
+	Renderer
+	name string
+}
+
+func (r *namedRenderer) AcceptsEvent(t engine.EventType) bool {
+	if f, ok := r.Renderer.(EventFilter); ok {
+		return f.AcceptsEvent(t)
+	}
+	return true
+}
+
+func (r *namedRenderer) Render(info RenderInfo, events <-chan apitype.EngineEvent) error {
+	return errors.Wrapf(r.Renderer.Render(info, events), "display plugin %s", r.name)
+}
+
+func (r *namedRenderer) Close() error {
+	return errors.Wrapf(r.Renderer.Close(), "display plugin %s", r.name)
+}
+
+// externalRenderer writes events to the standard input of an external display plugin.
+type externalRenderer struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	buf   *bufio.Writer
+	enc   *json.Encoder
+}
+
+func newExternalRenderer(name, arg string) (Renderer, error) {
+	path, err := exec.LookPath(ExternalRendererPrefix + name)
+	if err != nil {
+		return nil, errors.Errorf("no display plugin named %q is registered, and %s%s was not found on the PATH",
+			name, ExternalRendererPrefix, name)
+	}
+
+	var args []string
+	if arg != "" {
+		args = append(args, arg)
+	}
+	cmd := exec.Command(path, args...)
+	// The plugin's own output must not interleave with the display on stdout.
+	cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	buf := bufio.NewWriter(stdin)
+	return &externalRenderer{cmd: cmd, stdin: stdin, buf: buf, enc: json.NewEncoder(buf)}, nil
+}
+
+func (r *externalRenderer) Render(info RenderInfo, events <-chan apitype.EngineEvent) error {
+	for e := range events {
+		if err := r.enc.Encode(e); err != nil {
+			return err
+		}
+		if err := r.buf.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *externalRenderer) Close() error {
+	flushErr := r.buf.Flush()
+	contract.IgnoreClose(r.stdin)
+	if err := r.cmd.Wait(); err != nil {
+		return err
+	}
+	return flushErr
+}
+
+// renderEvents passes each event from the given channel on to the returned channel, and sends each that is not a
+// debug diagnostic -- unless debug is true -- to those of the given renderers that accept it, in the form of an
+// apitype.EngineEvent. The returned done channel is closed once every renderer has finished rendering.
+func renderEvents(info RenderInfo, events <-chan engine.Event, rs []Renderer,
+	debug bool) (<-chan engine.Event, <-chan bool) {
+
+	out, done := make(chan engine.Event), make(chan bool)
+
+	var wg sync.WaitGroup
+	inputs := make([]chan apitype.EngineEvent, len(rs))
+	for i, r := range rs {
+		inputs[i] = make(chan apitype.EngineEvent)
+		wg.Add(1)
+		go func(r Renderer, input <-chan apitype.EngineEvent) {
+			defer wg.Done()
+			if err := r.Render(info, input); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+			}
+			// Discard whatever the renderer didn't read, so that the operation isn't blocked.
+			for range input {
+			}
+		}(r, inputs[i])
+	}
+
+	go func() {
+		seq := 0
+		for e := range events {
+			isDebug := e.Type == engine.DiagEvent && e.Payload.(engine.DiagEventPayload).Severity == diag.Debug
+			if !isDebug || debug {
+				apiEvent, err := ConvertEngineEvent(e)
+				if err != nil {
+					logging.V(3).Infof("error converting engine event for rendering: %v", err)
+				} else {
+					apiEvent.Sequence = seq
+					apiEvent.Timestamp = int(time.Now().Unix())
+					seq++
+
+					for i, r := range rs {
+						if f, ok := r.(EventFilter); !ok || f.AcceptsEvent(e.Type) {
+							inputs[i] <- apiEvent
+						}
+					}
+				}
+			}
+			out <- e
+		}
+		close(out)
+
+		for _, input := range inputs {
+			close(input)
+		}
+		wg.Wait()
+		close(done)
+	}()
+	return out, done
+}
@@ -0,0 +1,173 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+)
+
+type testRenderer struct {
+	arg     string
+	infos   []RenderInfo
+	events  []apitype.EngineEvent
+	closed  bool
+	accepts func(t engine.EventType) bool
+}
+
+func (r *testRenderer) AcceptsEvent(t engine.EventType) bool {
+	return r.accepts == nil || r.accepts(t)
+}
+
+func (r *testRenderer) Render(info RenderInfo, events <-chan apitype.EngineEvent) error {
+	r.infos = append(r.infos, info)
+	for e := range events {
+		r.events = append(r.events, e)
+	}
+	return nil
+}
+
+func (r *testRenderer) Close() error {
+	r.closed = true
+	return nil
+}
+
+func renderTestEvents(t *testing.T, info RenderInfo, sent []engine.Event, rs ...Renderer) {
+	events := make(chan engine.Event)
+	out, done := renderEvents(info, events, rs, false)
+	go func() {
+		for _, e := range sent {
+			events <- e
+		}
+		close(events)
+	}()
+
+	// Every event is passed on, whether or not it is rendered.
+	var received []engine.Event
+	for e := range out {
+		received = append(received, e)
+	}
+	<-done
+	assert.Equal(t, sent, received)
+}
+
+func TestRenderers(t *testing.T) {
+	var created *testRenderer
+	RegisterRenderer("test-renderer", func(arg string) (Renderer, error) {
+		created = &testRenderer{arg: arg, accepts: func(t engine.EventType) bool { return t != engine.DiagEvent }}
+		return created, nil
+	})
+	assert.Contains(t, RegisteredRenderers(), "test-renderer")
+
+	r, err := NewRenderer("test-renderer=report.html")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "report.html", created.arg)
+
+	urn := resource.NewURN("dev", "proj", "", "aws:s3/bucket:Bucket", "my-bucket")
+	sent := []engine.Event{
+		{Type: engine.DiagEvent, Payload: engine.DiagEventPayload{Severity: diag.Error, Message: "oops"}},
+		{Type: engine.ResourcePreEvent, Payload: engine.ResourcePreEventPayload{
+			Metadata: engine.StepEventMetadata{Op: deploy.OpCreate, URN: urn, Type: urn.Type()},
+		}},
+	}
+
+	// Each operation is rendered in turn, and the renderer only receives the events it accepts.
+	preview := RenderInfo{Operation: "Previewing update", Action: apitype.UpdateUpdate, Stack: "dev", IsPreview: true}
+	update := RenderInfo{Operation: "Updating", Action: apitype.UpdateUpdate, Stack: "dev"}
+	renderTestEvents(t, preview, sent, r)
+	renderTestEvents(t, update, sent, r)
+	assert.Equal(t, []RenderInfo{preview, update}, created.infos)
+	if assert.Len(t, created.events, 2) {
+		for _, e := range created.events {
+			if assert.NotNil(t, e.ResourcePreEvent) {
+				assert.Equal(t, string(urn), e.ResourcePreEvent.Metadata.URN)
+			}
+		}
+	}
+
+	assert.NoError(t, r.Close())
+	assert.True(t, created.closed)
+
+	_, err = NewRenderer("=arg")
+	assert.Error(t, err)
+	_, err = NewRenderer("no-such-renderer")
+	assert.Error(t, err)
+}
+
+func TestExternalRenderer(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("external renderer test uses a shell script")
+	}
+
+	dir, err := ioutil.TempDir("", "display")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	// The plugin is given its argument, and reads the events from its standard input.
+	script := "#!/bin/sh\ncat > \"$1\"\n"
+	err = ioutil.WriteFile(filepath.Join(dir, ExternalRendererPrefix+"copy"), []byte(script), 0700)
+	if !assert.NoError(t, err) {
+		return
+	}
+	path := os.Getenv("PATH")
+	defer os.Setenv("PATH", path)
+	assert.NoError(t, os.Setenv("PATH", dir+string(os.PathListSeparator)+path))
+
+	output := filepath.Join(dir, "events.json")
+	r, err := NewRenderer("copy=" + output)
+	if !assert.NoError(t, err) {
+		return
+	}
+	sent := []engine.Event{
+		{Type: engine.DiagEvent, Payload: engine.DiagEventPayload{Severity: diag.Debug, Message: "debug"}},
+		{Type: engine.DiagEvent, Payload: engine.DiagEventPayload{Severity: diag.Warning, Message: "careful"}},
+		{Type: engine.SummaryEvent, Payload: engine.SummaryEventPayload{}},
+	}
+	renderTestEvents(t, RenderInfo{Operation: "Updating"}, sent, r)
+	assert.NoError(t, r.Close())
+
+	// Debug diagnostics are not rendered.
+	f, err := os.Open(output)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer f.Close()
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if assert.Len(t, lines, 2) {
+		assert.True(t, strings.Contains(lines[0], `"careful"`))
+		assert.True(t, strings.Contains(lines[1], `"summaryEvent"`))
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+)
+
+// resourceResult is the outcome of an operation for a single resource, as reported to CI systems.
+type resourceResult struct {
+	URN  string
+	Type string
+	// Op is the step that changes the resource, or "same" if it is unchanged.
+	Op string
+	// Diffs are the keys of the properties that the step changes.
+	Diffs []string
+	// Failures are the errors that the resource's steps reported.
+	Failures []string
+	// Violations are the policy violations that the resource was reported for.
+	Violations []apitype.PolicyEvent
+}
+
+// Changed returns true if the resource is changed by the operation.
+func (r *resourceResult) Changed() bool {
+	return r.Op != string(deploy.OpSame)
+}
+
+// operationResults collects the outcome of an operation from its events: the resources it changes, the errors it
+// reports, and the policy violations it finds.
+type operationResults struct {
+	Info RenderInfo
+	// Resources are the results for each resource, in the order the operation first reported them.
+	Resources []*resourceResult
+	// Errors are the errors that the operation reported that do not concern any particular resource.
+	Errors []string
+	// Violations are the policy violations that do not concern any particular resource.
+	Violations []apitype.PolicyEvent
+
+	byURN map[string]*resourceResult
+}
+
+// ciEventTypes are the types of events that are needed to collect operation results.
+var ciEventTypes = map[engine.EventType]bool{
+	engine.DiagEvent:               true,
+	engine.ResourcePreEvent:        true,
+	engine.ResourceOperationFailed: true,
+	engine.PolicyViolationEvent:    true,
+}
+
+// reportedOps are the steps that are reported as changes to resources. The steps that make up replacements are left
+// out in favor of the replace itself.
+var reportedOps = map[string]bool{
+	string(deploy.OpCreate):  true,
+	string(deploy.OpUpdate):  true,
+	string(deploy.OpDelete):  true,
+	string(deploy.OpReplace): true,
+	string(deploy.OpImport):  true,
+}
+
+// collectResults reads the events of an operation until the channel is closed, and returns its results.
+func collectResults(info RenderInfo, events <-chan apitype.EngineEvent) *operationResults {
+	results := &operationResults{Info: info, byURN: make(map[string]*resourceResult)}
+	for e := range events {
+		switch {
+		case e.ResourcePreEvent != nil:
+			md := e.ResourcePreEvent.Metadata
+			r := results.resource(md.URN, md.Type)
+			if reportedOps[md.Op] && !r.Changed() {
+				r.Op, r.Diffs = md.Op, md.Diffs
+			}
+		case e.ResOpFailedEvent != nil:
+			md := e.ResOpFailedEvent.Metadata
+			r := results.resource(md.URN, md.Type)
+			if len(r.Failures) == 0 {
+				r.Failures = append(r.Failures, fmt.Sprintf("%s of %s failed", md.Op, md.URN))
+			}
+		case e.DiagnosticEvent != nil && e.DiagnosticEvent.Severity == string(diag.Error):
+			msg := strings.TrimSpace(colors.Never.Colorize(e.DiagnosticEvent.Message))
+			if e.DiagnosticEvent.URN != "" {
+				r := results.resource(e.DiagnosticEvent.URN, "")
+				r.Failures = append(r.Failures, msg)
+			} else {
+				results.Errors = append(results.Errors, msg)
+			}
+		case e.PolicyEvent != nil:
+			v := *e.PolicyEvent
+			v.Message = strings.TrimSpace(colors.Never.Colorize(v.Message))
+			if v.ResourceURN != "" {
+				r := results.resource(v.ResourceURN, "")
+				r.Violations = append(r.Violations, v)
+			} else {
+				results.Violations = append(results.Violations, v)
+			}
+		}
+	}
+	return results
+}
+
+// resource returns the result for the resource with the given URN, adding it if it has not been reported yet.
+func (results *operationResults) resource(urn, typ string) *resourceResult {
+	r, has := results.byURN[urn]
+	if !has {
+		r = &resourceResult{URN: urn, Op: string(deploy.OpSame)}
+		results.byURN[urn] = r
+		results.Resources = append(results.Resources, r)
+	}
+	if r.Type == "" {
+		r.Type = typ
+	}
+	return r
+}
+
+// Kind returns the kind of the operation, e.g. "preview" or "update".
+func (results *operationResults) Kind() string {
+	if results.Info.IsPreview {
+		return "preview"
+	}
+	return string(results.Info.Action)
+}
+
+// Name returns the name of the operation, e.g. "myproject/dev preview".
+func (results *operationResults) Name() string {
+	return fmt.Sprintf("%s/%s %s", results.Info.Project, results.Info.Stack, results.Kind())
+}
+
+// describeViolation returns a one-line description of a policy violation.
+func describeViolation(v apitype.PolicyEvent) string {
+	return fmt.Sprintf("[%s] %s: %s", v.PolicyPackName, v.PolicyName, v.Message)
+}
+
+// isMandatory returns true if a policy violation blocks the operation.
+func isMandatory(v apitype.PolicyEvent) bool {
+	return v.EnforcementLevel == string(apitype.Mandatory)
+}
@@ -60,6 +60,7 @@ type ResourceRow interface {
 	DiagInfo() *DiagInfo
 	RecordDiagEvent(diagEvent engine.Event)
 	RecordPolicyViolationEvent(diagEvent engine.Event)
+	RecordPolicyRemediationEvent(diagEvent engine.Event)
 }
 
 // Implementation of a Row, used for the header of the grid.
@@ -252,6 +253,18 @@ func (data *resourceRowData) RecordPolicyViolationEvent(event engine.Event) {
 	data.recordDiagEventPayload(payload)
 }
 
+func (data *resourceRowData) RecordPolicyRemediationEvent(event engine.Event) {
+	// As with policy violations, remediations are displayed as diagnostics.
+	prPayload := event.Payload.(engine.PolicyRemediationEventPayload)
+	data.recordDiagEventPayload(engine.DiagEventPayload{
+		URN:      prPayload.ResourceURN,
+		Prefix:   prPayload.Prefix,
+		Message:  prPayload.Message,
+		Color:    prPayload.Color,
+		Severity: diag.Info,
+	})
+}
+
 type column int
 
 const (
@@ -371,6 +384,9 @@ func (data *resourceRowData) getInfoColumn() string {
 	if colors.Never.Colorize(changes) != "" {
 		appendDiagMessage("[" + changes + "]")
 	}
+	if engine.IsRetainedDelete(data.step) {
+		appendDiagMessage("[retain]")
+	}
 
 	diagInfo := data.diagInfo
 	if data.display.done {
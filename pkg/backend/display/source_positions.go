@@ -0,0 +1,119 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+// sourcePosition is a line of a program's source file.
+type sourcePosition struct {
+	File string // the file's path, relative to the directory annotations are resolved against.
+	Line int    // the 1-based line number.
+}
+
+// sourceExtensions are the extensions of the program source files that are searched for resource declarations.
+var sourceExtensions = map[string]bool{
+	".cs": true, ".fs": true, ".go": true, ".js": true, ".py": true, ".ts": true, ".vb": true,
+}
+
+// skippedSourceDirs are directories that hold dependencies or build outputs rather than a program's own source.
+var skippedSourceDirs = map[string]bool{
+	"bin": true, "node_modules": true, "obj": true, "vendor": true, "venv": true,
+}
+
+// maxSourceFileSize is the size of the largest source file that is searched for resource declarations.
+const maxSourceFileSize = 1 << 20
+
+// stringLiteralRegexp matches the string literals on a line of source code.
+var stringLiteralRegexp = regexp.MustCompile("\"([^\"\\\\]*)\"|'([^'\\\\]*)'|`([^`]*)`")
+
+// sourcePositions finds where resources are declared in a program's source. Resources' names are almost always given
+// as string literals, so it indexes the string literals in the source files beneath the program's directory, and
+// places a resource at the literal that matches its name. A resource's position is only known if its name appears
+// exactly once.
+type sourcePositions struct {
+	root     string                      // the directory holding the program's source.
+	relative string                      // the directory that positions' files are relative to.
+	literals map[string][]sourcePosition // the positions of each string literal, built on first use.
+}
+
+func newSourcePositions(root, relative string) *sourcePositions {
+	return &sourcePositions{root: root, relative: relative}
+}
+
+// lookup returns the position of the declaration of the resource with the given name, or nil if it isn't known.
+func (s *sourcePositions) lookup(name string) *sourcePosition {
+	if s.literals == nil {
+		s.literals = make(map[string][]sourcePosition)
+		s.index()
+	}
+	if positions := s.literals[name]; len(positions) == 1 {
+		return &positions[0]
+	}
+	return nil
+}
+
+// index records the string literals of each source file beneath the root. Files that can't be read are skipped.
+func (s *sourcePositions) index() {
+	err := filepath.Walk(s.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if name := info.Name(); path != s.root && (strings.HasPrefix(name, ".") || skippedSourceDirs[name]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !sourceExtensions[filepath.Ext(path)] || info.Size() > maxSourceFileSize {
+			return nil
+		}
+
+		file, err := filepath.Rel(s.relative, path)
+		if err != nil || strings.HasPrefix(file, "..") {
+			file = path
+		}
+		s.indexFile(path, filepath.ToSlash(file))
+		return nil
+	})
+	contract.IgnoreError(err)
+}
+
+// indexFile records the string literals of a single source file, as positions in the given file.
+func (s *sourcePositions) indexFile(path, file string) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer contract.IgnoreClose(f)
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxSourceFileSize)
+	for line := 1; scanner.Scan(); line++ {
+		for _, m := range stringLiteralRegexp.FindAllStringSubmatch(scanner.Text(), -1) {
+			literal := m[1] + m[2] + m[3]
+			if literal != "" {
+				s.literals[literal] = append(s.literals[literal], sourcePosition{File: file, Line: line})
+			}
+		}
+	}
+}
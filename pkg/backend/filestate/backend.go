@@ -31,7 +31,8 @@ import (
 	_ "gocloud.dev/blob/azureblob" // driver for azblob://
 	_ "gocloud.dev/blob/fileblob"  // driver for file://
 	_ "gocloud.dev/blob/gcsblob"   // driver for gs://
-	_ "gocloud.dev/blob/s3blob"    // driver for s3://
+	"gocloud.dev/blob/memblob"
+	_ "gocloud.dev/blob/s3blob" // driver for s3://
 	"gocloud.dev/gcerrors"
 
 	"github.com/pulumi/pulumi/pkg/apitype"
@@ -45,12 +46,15 @@ import (
 	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/resource/deploy"
 	"github.com/pulumi/pulumi/pkg/resource/edit"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
 	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/secrets"
 	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/contract"
 	"github.com/pulumi/pulumi/pkg/util/logging"
 	"github.com/pulumi/pulumi/pkg/util/result"
 	"github.com/pulumi/pulumi/pkg/util/validation"
+	"github.com/pulumi/pulumi/pkg/version"
 	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
@@ -58,6 +62,9 @@ import (
 type Backend interface {
 	backend.Backend
 	local() // at the moment, no local specific info, so just use a marker function.
+
+	// CancelCurrentUpdate breaks the lock on a stack that an interrupted update left behind.
+	CancelCurrentUpdate(ctx context.Context, stackRef backend.StackReference) error
 }
 
 type localBackend struct {
@@ -70,6 +77,13 @@ type localBackend struct {
 	url         string
 
 	bucket Bucket
+
+	// encryption records the stacks whose checkpoints are encrypted at rest, as their checkpoints are loaded or their
+	// encryption is changed, so that later checkpoints of them are encrypted too.
+	encryption backend.StateEncryption
+
+	// mock, if non-nil, configures the synthetic providers that simulate every operation on the stacks' resources.
+	mock *plugin.MockOptions
 }
 
 type localBackendReference struct {
@@ -134,6 +148,22 @@ func New(d diag.Sink, originalURL string) (Backend, error) {
 	}, nil
 }
 
+// MockURL is the URL of the backends returned by NewMock.
+const MockURL = "mem://"
+
+// NewMock returns a backend that keeps the state of its stacks in memory, so that they last only as long as the
+// backend, and that simulates every operation on their resources with synthetic providers, rather than the real ones,
+// which need no credentials.
+func NewMock(d diag.Sink, opts plugin.MockOptions) Backend {
+	return &localBackend{
+		d:           d,
+		originalURL: MockURL,
+		url:         MockURL,
+		bucket:      &wrappedBucket{bucket: memblob.OpenBucket(nil)},
+		mock:        &opts,
+	}
+}
+
 // massageBlobPath takes the path the user provided and converts it to an appropriate form go-cloud
 // can support.  Importantly, s3/azblob/gs paths should not be be touched. This will only affect
 // file:// paths which have a few oddities around them that we want to ensure work properly.
@@ -231,7 +261,8 @@ func (b *localBackend) CreateStack(ctx context.Context, stackRef backend.StackRe
 		return nil, errors.New("invalid empty stack name")
 	}
 
-	if _, _, err := b.getStack(stackName); err == nil {
+	// Don't decrypt the state of an existing stack, which may fail even though the stack exists.
+	if _, _, _, err := b.loadStack(stackName, false /*decrypt*/); err == nil {
 		return nil, &backend.StackAlreadyExistsError{StackName: string(stackName)}
 	}
 
@@ -255,29 +286,41 @@ func (b *localBackend) CreateStack(ctx context.Context, stackRef backend.StackRe
 }
 
 func (b *localBackend) GetStack(ctx context.Context, stackRef backend.StackReference) (backend.Stack, error) {
+	// A stack whose state is encrypted at rest is returned without decrypting it, so that it can be listed and
+	// selected without the key of its secrets provider. Its state is decrypted when its snapshot is first needed.
 	stackName := stackRef.Name()
-	snapshot, path, err := b.getStack(stackName)
+	snapshot, path, encrypted, err := b.loadStack(stackName, false /*decrypt*/)
 	switch {
 	case gcerrors.Code(errors.Cause(err)) == gcerrors.NotFound:
 		return nil, nil
 	case err != nil:
 		return nil, err
+	case encrypted:
+		return newEncryptedStack(stackRef, path, b), nil
 	default:
 		return newStack(stackRef, path, snapshot, b), nil
 	}
 }
 
 func (b *localBackend) ListStacks(
-	ctx context.Context, _ backend.ListStacksFilter) ([]backend.StackSummary, error) {
+	ctx context.Context, filter backend.ListStacksFilter) ([]backend.StackSummary, error) {
 	stacks, err := b.getLocalStacks()
 	if err != nil {
 		return nil, err
 	}
 
 	// Note that the provided stack filter is not honored, since fields like
-	// organizations and tags aren't persisted in the local backend.
+	// organizations and tags aren't persisted in the local backend. If only the stacks'
+	// names are needed, though, we can avoid reading their checkpoints.
 	var results []backend.StackSummary
 	for _, stackName := range stacks {
+		if filter.NamesOnly {
+			ref := localBackendReference{name: stackName}
+			localStack := newStack(ref, b.stackPath(stackName), nil, b).(*localStack)
+			results = append(results, newLocalStackSummary(localStack))
+			continue
+		}
+
 		stack, err := b.GetStack(ctx, localBackendReference{name: stackName})
 		if err != nil {
 			return nil, err
@@ -292,6 +335,12 @@ func (b *localBackend) ListStacks(
 
 func (b *localBackend) RemoveStack(ctx context.Context, stackRef backend.StackReference, force bool) (bool, error) {
 	stackName := stackRef.Name()
+	unlock, err := b.lockStack(stackName)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
 	snapshot, _, err := b.getStack(stackName)
 	if err != nil {
 		return false, err
@@ -307,6 +356,12 @@ func (b *localBackend) RemoveStack(ctx context.Context, stackRef backend.StackRe
 
 func (b *localBackend) RenameStack(ctx context.Context, stackRef backend.StackReference, newName tokens.QName) error {
 	stackName := stackRef.Name()
+	unlock, err := b.lockStack(stackName)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	snap, _, err := b.getStack(stackName)
 	if err != nil {
 		return err
@@ -329,6 +384,7 @@ func (b *localBackend) RenameStack(ctx context.Context, stackRef backend.StackRe
 	}
 
 	// Now save the snapshot with a new name (we pass nil to re-use the existing secrets manager from the snapshot)
+	b.setStateEncrypted(newName, b.isStateEncrypted(stackName))
 	if _, err = b.saveStack(newName, snap, nil); err != nil {
 		return err
 	}
@@ -336,6 +392,9 @@ func (b *localBackend) RenameStack(ctx context.Context, stackRef backend.StackRe
 	// To remove the old stack, just make a backup of the file and don't write out anything new.
 	file := b.stackPath(stackName)
 	backupTarget(b.bucket, file)
+	if err = b.clearJournal(stackName); err != nil {
+		return err
+	}
 
 	// And rename the histoy folder as well.
 	return b.renameHistory(stackName, newName)
@@ -365,6 +424,11 @@ func (b *localBackend) PackPolicies(
 
 func (b *localBackend) Preview(ctx context.Context, stackRef backend.StackReference,
 	op backend.UpdateOperation) (engine.ResourceChanges, result.Result) {
+	// The local backend does not keep the state left by each update, so it can only preview against the latest.
+	if op.Opts.AgainstVersion != 0 {
+		return nil, result.Error("previewing against a prior version is only supported by the Pulumi Service backend")
+	}
+
 	// Get the stack.
 	stack, err := b.GetStack(ctx, stackRef)
 	if err != nil {
@@ -423,9 +487,17 @@ func (b *localBackend) apply(
 	op backend.UpdateOperation, opts backend.ApplierOptions,
 	events chan<- engine.Event) (engine.ResourceChanges, result.Result) {
 
+	if op.Opts.RequireApproval {
+		return nil, result.Error("approvals are only supported by the Pulumi service; " +
+			"stacks managed by a local backend cannot require them")
+	}
+
 	stackRef := stack.Ref()
 	stackName := stackRef.Name()
 	actionLabel := backend.ActionLabel(kind, opts.DryRun)
+	if b.mock != nil {
+		op.Opts.Engine.Mock = b.mock
+	}
 
 	if !op.Opts.Display.JSONDisplay {
 		// Print a banner so it's clear this is a local deployment.
@@ -433,12 +505,26 @@ func (b *localBackend) apply(
 			colors.SpecHeadline+"%s (%s):"+colors.Reset+"\n"), actionLabel, stackRef)
 	}
 
+	// Updates that change the stack's state need exclusive access to it; previews only read it.
+	if !opts.DryRun {
+		unlock, err := b.lockStack(stackName)
+		if err != nil {
+			return nil, result.FromError(err)
+		}
+		defer unlock()
+	}
+
 	// Start the update.
 	update, err := b.newUpdate(stackName, op)
 	if err != nil {
 		return nil, result.FromError(err)
 	}
 
+	// Have the engine's pre-flight checks verify that the update can write the stack before making any changes.
+	if kind == apitype.UpdateUpdate {
+		op.Opts.Engine.CheckWritable = func() error { return b.checkWritable(stackName) }
+	}
+
 	// Spawn a display loop to show events on the CLI.
 	displayEvents := make(chan engine.Event)
 	displayDone := make(chan bool)
@@ -460,13 +546,16 @@ func (b *localBackend) apply(
 			if events != nil {
 				events <- e
 			}
+			if op.Events != nil {
+				op.Events <- e
+			}
 		}
 
 		close(eventsDone)
 	}()
 
 	// Create the management machinery.
-	persister := b.newSnapshotPersister(stackName, op.SecretsManager)
+	persister := b.newSnapshotPersister(stackName, op.SecretsManager, op.Opts.Engine.Memory.StreamSnapshots)
 	manager := backend.NewSnapshotManager(persister, update.GetTarget().Snapshot)
 	engineCtx := &engine.Context{
 		Cancel:          scope.Context(),
@@ -499,6 +588,10 @@ func (b *localBackend) apply(
 	close(engineEvents)
 	contract.IgnoreClose(manager)
 
+	// If the checkpoint was journaled, compact the journal so that the checkpoint is complete before it is recorded in
+	// the stack's history.
+	compactErr := persister.Close()
+
 	// Make sure the goroutine writing to displayEvents and events has exited before proceeding.
 	<-eventsDone
 	close(displayEvents)
@@ -522,9 +615,9 @@ func (b *localBackend) apply(
 		ResourceChanges: changes,
 	}
 
-	var saveErr error
+	saveErr := compactErr
 	var backupErr error
-	if !opts.DryRun {
+	if !opts.DryRun && saveErr == nil {
 		saveErr = b.addToHistory(stackName, info)
 		backupErr = b.backupStack(stackName)
 	}
@@ -558,6 +651,9 @@ func (b *localBackend) apply(
 				return changes, result.FromError(errors.Wrap(err, "Could not get signed url for stack location"))
 			}
 		}
+		if op.Permalink != nil {
+			*op.Permalink = link
+		}
 
 		fmt.Printf(op.Opts.Display.Color.Colorize(
 			colors.SpecHeadline+"Permalink: "+
@@ -589,16 +685,21 @@ func (b *localBackend) GetLogs(ctx context.Context, stackRef backend.StackRefere
 	query operations.LogQuery) ([]operations.LogEntry, error) {
 
 	stackName := stackRef.Name()
-	target, err := b.getTarget(stackName, cfg.Config, cfg.Decrypter)
+	target, err := b.getTarget(stackName, cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	return GetLogsForTarget(target, query)
+	return GetLogsForTarget(b.d, target, query)
 }
 
-// GetLogsForTarget fetches stack logs using the config, decrypter, and checkpoint in the given target.
-func GetLogsForTarget(target *deploy.Target, query operations.LogQuery) ([]operations.LogEntry, error) {
+// GetLogsForTarget fetches stack logs using the config, decrypter, and checkpoint in the given target. The logs of
+// resources whose packages have no built-in support for logs are requested from their provider plugins, which are
+// loaded by the query's host. If the query has no host, one is created for it that reports any diagnostics to the
+// given sink.
+func GetLogsForTarget(d diag.Sink, target *deploy.Target,
+	query operations.LogQuery) ([]operations.LogEntry, error) {
+
 	contract.Assert(target != nil)
 	contract.Assert(target.Snapshot != nil)
 
@@ -607,8 +708,19 @@ func GetLogsForTarget(target *deploy.Target, query operations.LogQuery) ([]opera
 		return nil, err
 	}
 
+	host := query.Host
+	if host == nil {
+		plugctx, err := plugin.NewContext(d, d, nil, nil, "", nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer contract.IgnoreClose(plugctx)
+		host = plugctx.Host
+	}
+
 	components := operations.NewResourceTree(target.Snapshot.Resources)
-	ops := components.OperationsProvider(config)
+	source := operations.NewProviderSource(host, target.Snapshot.Resources)
+	ops := components.PluginOperationsProvider(config, source)
 	logs, err := ops.GetLogs(query)
 	if logs == nil {
 		return nil, err
@@ -645,15 +757,48 @@ func (b *localBackend) ExportDeployment(ctx context.Context,
 	}, nil
 }
 
+func (b *localBackend) ExportDeploymentVersion(ctx context.Context, stackRef backend.StackReference,
+	version int) (*apitype.UntypedDeployment, error) {
+
+	chk, err := b.getHistoryCheckpoint(stackRef.Name(), version)
+	if err != nil {
+		return nil, err
+	}
+
+	deployment := chk.Latest
+	if deployment == nil {
+		deployment = &apitype.DeploymentV3{}
+	}
+	data, err := json.Marshal(deployment)
+	if err != nil {
+		return nil, err
+	}
+
+	return &apitype.UntypedDeployment{
+		Version:    3,
+		Deployment: json.RawMessage(data),
+	}, nil
+}
+
 func (b *localBackend) ImportDeployment(ctx context.Context, stackRef backend.StackReference,
 	deployment *apitype.UntypedDeployment) error {
 
 	stackName := stackRef.Name()
-	_, _, err := b.getStack(stackName)
+	unlock, err := b.lockStack(stackName)
 	if err != nil {
 		return err
 	}
+	defer unlock()
 
+	if _, _, err = b.getStack(stackName); err != nil {
+		return err
+	}
+
+	// A deployment that is encrypted at rest is decrypted with the key of the stack's secrets provider.
+	secretsProv := b.encryption.SecretsProvider(newEncryptedStack(stackRef, b.stackPath(stackName), b))
+	if deployment, err = stack.DecryptDeployment(deployment, secretsProv); err != nil {
+		return err
+	}
 	snap, err := stack.DeserializeUntypedDeployment(deployment, stack.DefaultSecretsProvider)
 	if err != nil {
 		return err
@@ -663,6 +808,44 @@ func (b *localBackend) ImportDeployment(ctx context.Context, stackRef backend.St
 	return err
 }
 
+func (b *localBackend) SetStateEncryption(ctx context.Context, stackRef backend.StackReference, sm secrets.Manager,
+	encrypt bool) error {
+
+	stackName := stackRef.Name()
+	unlock, err := b.lockStack(stackName)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	snap, _, err := b.getStack(stackName)
+	if err != nil {
+		return err
+	}
+	if sm == nil && snap != nil {
+		sm = snap.SecretsManager
+	}
+	if snap == nil {
+		// Write an empty deployment, so that the checkpoint records the secrets provider it is encrypted with.
+		snap = deploy.NewSnapshot(deploy.Manifest{
+			Time:    time.Now(),
+			Version: version.Version,
+		}, sm, nil, nil)
+	}
+
+	wasEncrypted := b.isStateEncrypted(stackName)
+	b.setStateEncrypted(stackName, encrypt)
+	if _, err = b.saveStack(stackName, snap, sm); err != nil {
+		b.setStateEncrypted(stackName, wasEncrypted)
+		return err
+	}
+	return nil
+}
+
+func (b *localBackend) SetStateSecretsManagerSource(source backend.StateSecretsManagerSource) {
+	b.encryption.SetSecretsManagerSource(source)
+}
+
 func (b *localBackend) Logout() error {
 	return workspace.DeleteAccessToken(b.originalURL)
 }
@@ -699,9 +882,9 @@ func (b *localBackend) getLocalStacks() ([]tokens.QName, error) {
 			continue
 		}
 
-		// Read in this stack's information.
+		// Read in this stack's information, without decrypting it if it is encrypted.
 		name := tokens.QName(stackfn[:len(stackfn)-len(ext)])
-		_, _, err := b.getStack(name)
+		_, _, _, err := b.loadStack(name, false /*decrypt*/)
 		if err != nil {
 			logging.V(5).Infof("error reading stack: %v (%v) skipping", name, err)
 			continue // failure reading the stack information.
@@ -1,12 +1,32 @@
 package filestate
 
 import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
 	"os/user"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	_ "gocloud.dev/secrets/localsecrets" // support for base64key://
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/secrets"
+	"github.com/pulumi/pulumi/pkg/secrets/cloud"
+	"github.com/pulumi/pulumi/pkg/tokens"
 )
 
 func TestMassageBlobPath(t *testing.T) {
@@ -63,3 +83,427 @@ func TestMassageBlobPath(t *testing.T) {
 		testMassagePath(t, FilePathPrefix+"/1/2/3/../4/..", FilePathPrefix+expected)
 	})
 }
+
+func TestStreamedCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filestate")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	sink := diag.DefaultSink(ioutil.Discard, ioutil.Discard, diag.FormatOptions{Color: colors.Never})
+	be, err := New(sink, FilePathPrefix+filepath.ToSlash(dir))
+	if !assert.NoError(t, err) {
+		return
+	}
+	b := be.(*localBackend)
+
+	urn := resource.NewURN("dev", "proj", "", "test:index:Resource", "a")
+	res := &resource.State{
+		URN:     urn,
+		Type:    urn.Type(),
+		Custom:  true,
+		ID:      "a",
+		Inputs:  resource.PropertyMap{"size": resource.NewNumberProperty(3)},
+		Outputs: resource.PropertyMap{"size": resource.NewNumberProperty(3)},
+	}
+	snap := deploy.NewSnapshot(deploy.Manifest{}, nil, []*resource.State{res}, nil)
+
+	// Streamed checkpoints are written compactly, and read back like any other.
+	file, err := b.saveStackCheckpoint("dev", snap, nil, true)
+	if !assert.NoError(t, err) {
+		return
+	}
+	contents, err := b.bucket.ReadAll(context.Background(), file)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(contents), "\n"))
+
+	loaded, _, err := b.getStack("dev")
+	if assert.NoError(t, err) && assert.Len(t, loaded.Resources, 1) {
+		assert.Equal(t, urn, loaded.Resources[0].URN)
+		assert.Equal(t, res.Outputs, loaded.Resources[0].Outputs)
+	}
+}
+
+func TestJournaledCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filestate")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	sink := diag.DefaultSink(ioutil.Discard, ioutil.Discard, diag.FormatOptions{Color: colors.Never})
+	be, err := New(sink, FilePathPrefix+filepath.ToSlash(dir))
+	if !assert.NoError(t, err) {
+		return
+	}
+	b := be.(*localBackend)
+
+	newResource := func(name string, size float64) *resource.State {
+		urn := resource.NewURN("dev", "proj", "", "test:index:Resource", tokens.QName(name))
+		return &resource.State{
+			URN:     urn,
+			Type:    urn.Type(),
+			Custom:  true,
+			ID:      resource.ID(name),
+			Inputs:  resource.PropertyMap{"size": resource.NewNumberProperty(size)},
+			Outputs: resource.PropertyMap{"size": resource.NewNumberProperty(size)},
+		}
+	}
+	newSnapshot := func(resources ...*resource.State) *deploy.Snapshot {
+		return deploy.NewSnapshot(deploy.Manifest{Time: time.Now()}, nil, resources, nil)
+	}
+
+	persister := b.newSnapshotPersister("dev", nil, true)
+	persister.journaled = true
+
+	// The first snapshot is written in full; later ones only to the journal, from which they are read back.
+	a, c := newResource("a", 1), newResource("c", 3)
+	var others []*resource.State
+	for i := 0; i < 8; i++ {
+		others = append(others, newResource(fmt.Sprintf("other-%d", i), 0))
+	}
+	assert.NoError(t, persister.Save(newSnapshot(append([]*resource.State{a}, others...)...)))
+	assert.NoError(t, persister.Save(newSnapshot(append([]*resource.State{a, newResource("b", 2)}, others...)...)))
+	assert.NoError(t, persister.Save(newSnapshot(append([]*resource.State{a, newResource("b", 4), c}, others...)...)))
+
+	files, err := listBucket(b.bucket, b.journalDirectory("dev"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	loaded, _, err := b.getStack("dev")
+	if assert.NoError(t, err) && assert.Len(t, loaded.Resources, 11) {
+		assert.Equal(t, c.URN, loaded.Resources[2].URN)
+		assert.Equal(t, resource.NewNumberProperty(4), loaded.Resources[1].Outputs["size"])
+	}
+
+	// Closing the persister compacts the journal into a full checkpoint.
+	assert.NoError(t, persister.Close())
+	files, err = listBucket(b.bucket, b.journalDirectory("dev"))
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+
+	chk, err := b.getCheckpoint("dev")
+	if assert.NoError(t, err) && assert.Len(t, chk.Latest.Resources, 11) {
+		assert.Equal(t, map[string]interface{}{"size": float64(4)}, chk.Latest.Resources[1].Outputs)
+	}
+
+	// Journal entries that are not based on the current checkpoint are ignored.
+	assert.NoError(t, persister.Save(newSnapshot(a)))
+	_, err = b.saveStackCheckpoint("dev", newSnapshot(a, c), nil, false)
+	assert.NoError(t, err)
+	loaded, _, err = b.getStack("dev")
+	if assert.NoError(t, err) {
+		assert.Len(t, loaded.Resources, 2)
+	}
+}
+
+func TestEncryptedCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filestate")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	sink := diag.DefaultSink(ioutil.Discard, ioutil.Discard, diag.FormatOptions{Color: colors.Never})
+	be, err := New(sink, FilePathPrefix+filepath.ToSlash(dir))
+	if !assert.NoError(t, err) {
+		return
+	}
+	b := be.(*localBackend)
+
+	url := "base64key://" + base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))
+	dataKey, err := cloud.GenerateNewDataKey(url)
+	if !assert.NoError(t, err) {
+		return
+	}
+	sm, err := cloud.NewCloudSecretsManager(url, dataKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	urn := resource.NewURN("dev", "proj", "", "test:index:Resource", "a")
+	res := &resource.State{
+		URN:     urn,
+		Type:    urn.Type(),
+		Custom:  true,
+		ID:      "a",
+		Inputs:  resource.PropertyMap{"password": resource.NewStringProperty("hunter2")},
+		Outputs: resource.PropertyMap{"password": resource.NewStringProperty("hunter2")},
+	}
+	snap := deploy.NewSnapshot(deploy.Manifest{}, sm, []*resource.State{res}, nil)
+	file, err := b.saveStack("dev", snap, sm)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Once the state is encrypted, neither it nor any later checkpoint contains the plaintext.
+	ref := localBackendReference{name: "dev"}
+	if !assert.NoError(t, b.SetStateEncryption(context.Background(), ref, sm, true)) {
+		return
+	}
+	contents, err := b.bucket.ReadAll(context.Background(), file)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(contents), "hunter2")
+
+	loaded, _, err := b.getStack("dev")
+	if assert.NoError(t, err) && assert.Len(t, loaded.Resources, 1) {
+		assert.Equal(t, res.Outputs, loaded.Resources[0].Outputs)
+	}
+
+	// A new backend finds that the state is encrypted when it loads it.
+	be, err = New(sink, FilePathPrefix+filepath.ToSlash(dir))
+	if !assert.NoError(t, err) {
+		return
+	}
+	b = be.(*localBackend)
+	loaded, _, err = b.getStack("dev")
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = b.saveStack("dev", loaded, nil)
+	assert.NoError(t, err)
+	contents, err = b.bucket.ReadAll(context.Background(), file)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(contents), "hunter2")
+
+	// Decrypting the state writes it unencrypted again.
+	assert.NoError(t, b.SetStateEncryption(context.Background(), ref, nil, false))
+	contents, err = b.bucket.ReadAll(context.Background(), file)
+	assert.NoError(t, err)
+	assert.Contains(t, string(contents), "hunter2")
+}
+
+func TestEncryptedStackDecryptsLazily(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filestate")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	sink := diag.DefaultSink(ioutil.Discard, ioutil.Discard, diag.FormatOptions{Color: colors.Never})
+	be, err := New(sink, FilePathPrefix+filepath.ToSlash(dir))
+	if !assert.NoError(t, err) {
+		return
+	}
+	b := be.(*localBackend)
+
+	url := "base64key://" + base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))
+	dataKey, err := cloud.GenerateNewDataKey(url)
+	if !assert.NoError(t, err) {
+		return
+	}
+	sm, err := cloud.NewCloudSecretsManager(url, dataKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	urn := resource.NewURN("dev", "proj", "", "test:index:Resource", "a")
+	res := &resource.State{URN: urn, Type: urn.Type(), Custom: true, ID: "a"}
+	snap := deploy.NewSnapshot(deploy.Manifest{}, sm, []*resource.State{res}, nil)
+	if _, err = b.saveStack("dev", snap, sm); !assert.NoError(t, err) {
+		return
+	}
+	if _, err = b.saveStack("prod", deploy.NewSnapshot(deploy.Manifest{}, nil, nil, nil), nil); !assert.NoError(t, err) {
+		return
+	}
+	ref := localBackendReference{name: "dev"}
+	if !assert.NoError(t, b.SetStateEncryption(context.Background(), ref, sm, true)) {
+		return
+	}
+
+	// A new backend lists and loads the encrypted stack without its key, and only fails once its state is read.
+	be, err = New(sink, FilePathPrefix+filepath.ToSlash(dir))
+	if !assert.NoError(t, err) {
+		return
+	}
+	b = be.(*localBackend)
+	b.SetStateSecretsManagerSource(func(s backend.Stack) (secrets.Manager, error) {
+		return nil, errors.New("no key")
+	})
+
+	summaries, err := b.ListStacks(context.Background(), backend.ListStacksFilter{})
+	if assert.NoError(t, err) {
+		assert.Len(t, summaries, 2)
+	}
+	s, err := b.GetStack(context.Background(), ref)
+	if !assert.NoError(t, err) || !assert.NotNil(t, s) {
+		return
+	}
+	_, err = s.Snapshot(context.Background())
+	assert.Error(t, err)
+
+	// With the stack's own secrets manager, the state is decrypted.
+	b.SetStateSecretsManagerSource(func(s backend.Stack) (secrets.Manager, error) {
+		assert.Equal(t, ref.Name(), s.Ref().Name())
+		return sm, nil
+	})
+	s, err = b.GetStack(context.Background(), ref)
+	if !assert.NoError(t, err) {
+		return
+	}
+	loaded, err := s.Snapshot(context.Background())
+	if assert.NoError(t, err) && assert.NotNil(t, loaded) {
+		assert.Len(t, loaded.Resources, 1)
+	}
+}
+
+func TestStackLocking(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filestate")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	sink := diag.DefaultSink(ioutil.Discard, ioutil.Discard, diag.FormatOptions{Color: colors.Never})
+	be, err := New(sink, FilePathPrefix+filepath.ToSlash(dir))
+	if !assert.NoError(t, err) {
+		return
+	}
+	b := be.(*localBackend)
+	ref := localBackendReference{name: "dev"}
+
+	// Only one process at a time can lock a stack, and releasing the lock removes it.
+	unlock, err := b.lockStack("dev")
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = b.lockStack("dev")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Wait for its update to finish")
+	}
+	unlock()
+	files, err := listBucket(b.bucket, b.lockDirectory("dev"))
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+	assert.Error(t, b.CancelCurrentUpdate(context.Background(), ref))
+
+	// A lock whose lease has expired is reported as stale, and can be broken.
+	stale := newLockContent()
+	stale.Renewed = stale.Renewed.Add(-2 * lockLeaseDuration)
+	assert.NoError(t, b.writeLock(filepath.Join(b.lockDirectory("dev"), "stale.json"), stale))
+	_, err = b.lockStack("dev")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "exited without releasing it")
+	}
+	assert.NoError(t, b.CancelCurrentUpdate(context.Background(), ref))
+	unlock, err = b.lockStack("dev")
+	if assert.NoError(t, err) {
+		unlock()
+	}
+}
+
+func TestWaitForStackReady(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filestate")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	sink := diag.DefaultSink(ioutil.Discard, ioutil.Discard, diag.FormatOptions{Color: colors.Never})
+	be, err := New(sink, FilePathPrefix+filepath.ToSlash(dir))
+	if !assert.NoError(t, err) {
+		return
+	}
+	b := be.(*localBackend)
+	ref := localBackendReference{name: "dev"}
+
+	// An unlocked stack is ready straight away.
+	assert.NoError(t, b.WaitForStackReady(context.Background(), ref, time.Millisecond))
+
+	// A locked stack is ready once its lock is released, and waiting for it times out while it is held.
+	unlock, err := b.lockStack("dev")
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	err = b.WaitForStackReady(ctx, ref, time.Millisecond)
+	cancel()
+	if assert.Error(t, err) {
+		assert.Equal(t, context.DeadlineExceeded, errors.Cause(err))
+		assert.Contains(t, err.Error(), "stack dev is still locked by")
+	}
+	time.AfterFunc(50*time.Millisecond, unlock)
+	assert.NoError(t, b.WaitForStackReady(context.Background(), ref, time.Millisecond))
+
+	// A stale lock will never be released, so it is reported rather than waited for.
+	stale := newLockContent()
+	stale.Renewed = stale.Renewed.Add(-2 * lockLeaseDuration)
+	assert.NoError(t, b.writeLock(filepath.Join(b.lockDirectory("dev"), "stale.json"), stale))
+	err = b.WaitForStackReady(context.Background(), ref, time.Millisecond)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "exited without releasing it")
+	}
+}
+
+func TestHistoryVersions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filestate")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	sink := diag.DefaultSink(ioutil.Discard, ioutil.Discard, diag.FormatOptions{Color: colors.Never})
+	be, err := New(sink, FilePathPrefix+filepath.ToSlash(dir))
+	if !assert.NoError(t, err) {
+		return
+	}
+	b := be.(*localBackend)
+	ref := localBackendReference{name: "dev"}
+
+	// Each update records the checkpoint it left under the next version.
+	update := func(name string) {
+		urn := resource.NewURN("dev", "proj", "", "test:index:Resource", tokens.QName(name))
+		res := &resource.State{URN: urn, Type: urn.Type(), Custom: true, ID: resource.ID(name)}
+		_, err := b.saveStack("dev", deploy.NewSnapshot(deploy.Manifest{}, nil, []*resource.State{res}, nil), nil)
+		assert.NoError(t, err)
+		assert.NoError(t, b.addToHistory("dev", backend.UpdateInfo{Kind: apitype.UpdateUpdate}))
+	}
+	update("a")
+	update("b")
+	update("c")
+
+	history, err := b.GetHistory(context.Background(), ref)
+	if assert.NoError(t, err) && assert.Len(t, history, 3) {
+		assert.Equal(t, []int{3, 2, 1}, []int{history[0].Version, history[1].Version, history[2].Version})
+	}
+	deployment, err := b.ExportDeploymentVersion(context.Background(), ref, 2)
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(deployment.Deployment), "test:index:Resource::b")
+		assert.NotContains(t, string(deployment.Deployment), "test:index:Resource::c")
+	}
+	_, err = b.ExportDeploymentVersion(context.Background(), ref, 4)
+	assert.Error(t, err)
+
+	// Only the most recent updates are kept if the history's retention is limited.
+	assert.NoError(t, os.Setenv(HistoryRetentionEnvVar, "2"))
+	defer func() { assert.NoError(t, os.Unsetenv(HistoryRetentionEnvVar)) }()
+	update("d")
+	history, err = b.GetHistory(context.Background(), ref)
+	if assert.NoError(t, err) && assert.Len(t, history, 2) {
+		assert.Equal(t, []int{4, 3}, []int{history[0].Version, history[1].Version})
+	}
+	_, err = b.ExportDeploymentVersion(context.Background(), ref, 2)
+	assert.Error(t, err)
+	_, err = b.ExportDeploymentVersion(context.Background(), ref, 3)
+	assert.NoError(t, err)
+}
+
+func TestMockBackendKeepsStateInMemory(t *testing.T) {
+	sink := diag.DefaultSink(ioutil.Discard, ioutil.Discard, diag.FormatOptions{Color: colors.Never})
+	b := NewMock(sink, plugin.MockOptions{}).(*localBackend)
+	assert.Equal(t, MockURL, b.url)
+
+	if _, err := b.saveStack("dev", deploy.NewSnapshot(deploy.Manifest{}, nil, nil, nil), nil); !assert.NoError(t, err) {
+		return
+	}
+	summaries, err := b.ListStacks(context.Background(), backend.ListStacksFilter{})
+	if assert.NoError(t, err) {
+		assert.Len(t, summaries, 1)
+	}
+
+	// Each mock backend starts out without any stacks.
+	summaries, err = NewMock(sink, plugin.MockOptions{}).ListStacks(context.Background(), backend.ListStacksFilter{})
+	if assert.NoError(t, err) {
+		assert.Len(t, summaries, 0)
+	}
+}
@@ -20,6 +20,7 @@ type Bucket interface {
 	SignedURL(ctx context.Context, key string, opts *blob.SignedURLOptions) (string, error)
 	ReadAll(ctx context.Context, key string) (_ []byte, err error)
 	WriteAll(ctx context.Context, key string, p []byte, opts *blob.WriterOptions) (err error)
+	NewWriter(ctx context.Context, key string, opts *blob.WriterOptions) (_ *blob.Writer, err error)
 	Exists(ctx context.Context, key string) (bool, error)
 }
 
@@ -55,6 +56,10 @@ func (b *wrappedBucket) WriteAll(ctx context.Context, key string, p []byte, opts
 	return b.bucket.WriteAll(ctx, filepath.ToSlash(key), p, opts)
 }
 
+func (b *wrappedBucket) NewWriter(ctx context.Context, key string, opts *blob.WriterOptions) (*blob.Writer, error) {
+	return b.bucket.NewWriter(ctx, filepath.ToSlash(key), opts)
+}
+
 func (b *wrappedBucket) Exists(ctx context.Context, key string) (bool, error) {
 	return b.bucket.Exists(ctx, filepath.ToSlash(key))
 }
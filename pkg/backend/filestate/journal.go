@@ -0,0 +1,140 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/fsutil"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// JournalCheckpointsEnvVar, when set, causes the checkpoints of stacks to be journaled while they are updated: rather
+// than rewriting the whole checkpoint after every step, only the resources that the step changed are written. The
+// journal is compacted into a full checkpoint periodically and when the update finishes. Checkpoints that are
+// encrypted at rest are always written in full.
+const JournalCheckpointsEnvVar = "PULUMI_JOURNAL_CHECKPOINTS"
+
+// journalCompactionInterval is the number of entries after which a stack's journal is compacted.
+const journalCompactionInterval = 100
+
+func (b *localBackend) journalDirectory(stack tokens.QName) string {
+	contract.Require(stack != "", "stack")
+	return filepath.Join(b.StateDir(), workspace.JournalDir, fsutil.QnamePath(stack))
+}
+
+// journalBase returns the identity of a full checkpoint whose deployment was taken at the given time, on which a
+// journal may be based.
+func journalBase(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// splitDeployment splits the given deployment into the header and resource records that are kept in a journal.
+func splitDeployment(dep *apitype.DeploymentV3) (json.RawMessage, []json.RawMessage, error) {
+	header := *dep
+	header.Resources = nil
+	headerBytes, err := json.Marshal(header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	records := make([]json.RawMessage, len(dep.Resources))
+	for i, res := range dep.Resources {
+		if records[i], err = json.Marshal(res); err != nil {
+			return nil, nil, err
+		}
+	}
+	return headerBytes, records, nil
+}
+
+// writeJournalEntry writes the given entry to the journal of the given stack, and returns its size.
+func (b *localBackend) writeJournalEntry(stack tokens.QName, entry deploy.JournalEntry) (int, error) {
+	byts, err := json.Marshal(entry)
+	if err != nil {
+		return 0, err
+	}
+	file := filepath.Join(b.journalDirectory(stack), fmt.Sprintf("%08d.json", entry.Sequence))
+	if err = b.bucket.WriteAll(context.TODO(), file, byts, nil); err != nil {
+		return 0, errors.Wrap(err, "An IO error occurred during the current operation")
+	}
+
+	logging.V(7).Infof("Saved stack %s checkpoint journal entry to: %s", stack, file)
+	return len(byts), nil
+}
+
+// clearJournal removes the journal of the given stack.
+func (b *localBackend) clearJournal(stack tokens.QName) error {
+	return removeAllByPrefix(b.bucket, b.journalDirectory(stack))
+}
+
+// replayJournal applies the journal of the given stack, if it has one, to its checkpoint. An entry that cannot be
+// read ends the journal, since the entries after it cannot be applied.
+func (b *localBackend) replayJournal(stack tokens.QName, chk *apitype.CheckpointV3) error {
+	if chk.Latest == nil {
+		return nil
+	}
+	files, err := listBucket(b.bucket, b.journalDirectory(stack))
+	if err != nil || len(files) == 0 {
+		return err
+	}
+
+	var entries []deploy.JournalEntry
+	for _, file := range files {
+		byts, err := b.bucket.ReadAll(context.TODO(), file.Key)
+		if err != nil {
+			logging.V(5).Infof("error reading journal entry: %v (%v) skipping the rest", file.Key, err)
+			break
+		}
+		var entry deploy.JournalEntry
+		if err = json.Unmarshal(byts, &entry); err != nil {
+			logging.V(5).Infof("error reading journal entry: %v (%v) skipping the rest", file.Key, err)
+			break
+		}
+		entries = append(entries, entry)
+	}
+
+	_, records, err := splitDeployment(chk.Latest)
+	if err != nil {
+		return err
+	}
+	header, records, err := deploy.ReplaySnapshotJournal(journalBase(chk.Latest.Manifest.Time), records, entries)
+	if err != nil || header == nil {
+		return err
+	}
+
+	var dep apitype.DeploymentV3
+	if err = json.Unmarshal(header, &dep); err != nil {
+		return errors.Wrapf(err, "replaying the checkpoint journal of stack %s", stack)
+	}
+	dep.Resources = make([]apitype.ResourceV3, len(records))
+	for i, record := range records {
+		if err = json.Unmarshal(record, &dep.Resources[i]); err != nil {
+			return errors.Wrapf(err, "replaying the checkpoint journal of stack %s", stack)
+		}
+	}
+	chk.Latest = &dep
+	return nil
+}
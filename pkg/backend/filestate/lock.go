@@ -0,0 +1,253 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/fsutil"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/util/retry"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// lockLeaseDuration is how long a stack's lock remains valid without being renewed. The process that holds a lock
+// renews it periodically, so a lock whose lease has expired was most likely left behind by a process that exited
+// without releasing it.
+var lockLeaseDuration = 5 * time.Minute
+
+// lockContent is the content of a stack's lock file, which identifies the process that holds the lock.
+type lockContent struct {
+	Pid       int       `json:"pid"`
+	Username  string    `json:"username"`
+	Hostname  string    `json:"hostname"`
+	Timestamp time.Time `json:"timestamp"`
+	Renewed   time.Time `json:"renewed"`
+}
+
+func newLockContent() *lockContent {
+	content := &lockContent{Pid: os.Getpid(), Timestamp: time.Now()}
+	content.Renewed = content.Timestamp
+	if u, err := user.Current(); err == nil {
+		content.Username = u.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		content.Hostname = host
+	}
+	return content
+}
+
+// String describes the process that holds the lock.
+func (l *lockContent) String() string {
+	return fmt.Sprintf("%s@%s (pid %d), acquired at %s", l.Username, l.Hostname, l.Pid,
+		l.Timestamp.Format(time.RFC3339))
+}
+
+// expired returns true if the lock's lease has expired.
+func (l *lockContent) expired() bool {
+	return time.Since(l.Renewed) > lockLeaseDuration
+}
+
+func (b *localBackend) lockDirectory(stack tokens.QName) string {
+	contract.Require(stack != "", "stack")
+	return filepath.Join(b.StateDir(), workspace.LockDir, fsutil.QnamePath(stack))
+}
+
+// lockStack acquires the lock on the given stack, which ensures that no other process changes the stack's state until
+// the returned function is called to release the lock.
+//
+// Each process that locks a stack writes its own lock file and then checks for others', rather than relying on the
+// bucket to create files atomically, which not every bucket supports. Two processes that race to lock the same stack
+// may then both fail, but never both succeed.
+func (b *localBackend) lockStack(stack tokens.QName) (func(), error) {
+	lockPath := filepath.Join(b.lockDirectory(stack), uuid.NewV4().String()+".json")
+	content := newLockContent()
+	if err := b.writeLock(lockPath, content); err != nil {
+		return nil, errors.Wrapf(err, "locking stack %s", stack)
+	}
+
+	if err := b.checkForLocks(stack, lockPath); err != nil {
+		if deleteErr := b.bucket.Delete(context.TODO(), lockPath); deleteErr != nil {
+			logging.V(3).Infof("failed to remove lock %s: %v", lockPath, deleteErr)
+		}
+		return nil, err
+	}
+
+	// Renew the lock's lease until it is released.
+	stop, done := make(chan bool), make(chan bool)
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(lockLeaseDuration / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				content.Renewed = time.Now()
+				if err := b.writeLock(lockPath, content); err != nil {
+					logging.V(3).Infof("failed to renew lock %s: %v", lockPath, err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-done
+		if err := b.bucket.Delete(context.TODO(), lockPath); err != nil {
+			logging.V(3).Infof("failed to remove lock %s: %v", lockPath, err)
+		}
+	}, nil
+}
+
+func (b *localBackend) writeLock(lockPath string, content *lockContent) error {
+	bytes, err := json.Marshal(content)
+	if err != nil {
+		return err
+	}
+	return b.bucket.WriteAll(context.TODO(), lockPath, bytes, nil)
+}
+
+// checkForLocks returns an error if the given stack has any lock other than the one at the given path.
+func (b *localBackend) checkForLocks(stack tokens.QName, lockPath string) error {
+	holders, stale, err := b.listLockHolders(stack, lockPath)
+	if err != nil {
+		return err
+	}
+	return lockedStackError(stack, holders, stale)
+}
+
+// listLockHolders describes the holders of the given stack's locks, other than the one at the given path. It also
+// returns whether every one of those locks is stale, that is, whether their leases have all expired.
+func (b *localBackend) listLockHolders(stack tokens.QName, lockPath string) ([]string, bool, error) {
+	files, err := listBucket(b.bucket, b.lockDirectory(stack))
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "checking the locks of stack %s", stack)
+	}
+
+	var holders []string
+	stale := true
+	for _, file := range files {
+		if file.IsDir || path.Base(file.Key) == path.Base(filepath.ToSlash(lockPath)) {
+			continue
+		}
+		bytes, err := b.bucket.ReadAll(context.TODO(), file.Key)
+		if err != nil {
+			// The lock may have just been released.
+			logging.V(5).Infof("failed to read lock %s: %v", file.Key, err)
+			continue
+		}
+		var content lockContent
+		if err = json.Unmarshal(bytes, &content); err != nil {
+			holders = append(holders, fmt.Sprintf("an unreadable lock at %s", file.Key))
+			continue
+		}
+		holder := content.String()
+		if content.expired() {
+			holder += fmt.Sprintf(", whose lease expired at %s", content.Renewed.Add(lockLeaseDuration).Format(time.RFC3339))
+		} else {
+			stale = false
+		}
+		holders = append(holders, holder)
+	}
+	return holders, stale, nil
+}
+
+// lockedStackError returns an error describing the given holders of the stack's locks, or nil if there are none.
+func lockedStackError(stack tokens.QName, holders []string, stale bool) error {
+	if len(holders) == 0 {
+		return nil
+	}
+
+	msg := fmt.Sprintf("stack %s is locked by %s", stack, strings.Join(holders, "; "))
+	if stale {
+		return errors.Errorf("%s. The process that held the lock appears to have exited without releasing it; if "+
+			"no other update to the stack is running, run `pulumi cancel` to break the lock", msg)
+	}
+	return errors.Errorf("%s. Wait for its update to finish, or, if it is no longer running, run `pulumi cancel` to "+
+		"break the lock", msg)
+}
+
+// CancelCurrentUpdate breaks the locks on the given stack, so that it can be updated again after an update was
+// interrupted without releasing its lock. It doesn't stop an update that is still running.
+func (b *localBackend) CancelCurrentUpdate(ctx context.Context, stackRef backend.StackReference) error {
+	dir := b.lockDirectory(stackRef.Name())
+	files, err := listBucket(b.bucket, dir)
+	if err != nil {
+		return errors.Wrapf(err, "listing the locks of stack %s", stackRef)
+	}
+	if len(files) == 0 {
+		return errors.Errorf("stack %s is not locked", stackRef)
+	}
+	for _, file := range files {
+		if file.IsDir {
+			continue
+		}
+		if err = b.bucket.Delete(ctx, file.Key); err != nil {
+			return errors.Wrapf(err, "removing lock %s", file.Key)
+		}
+	}
+	return nil
+}
+
+// WaitForStackReady waits until the given stack is no longer locked by an update, checking its locks at the given
+// interval. Locks whose leases have all expired were left behind by processes that exited, so rather than waiting for
+// them, an error is returned straight away.
+func (b *localBackend) WaitForStackReady(ctx context.Context, stackRef backend.StackReference,
+	pollInterval time.Duration) error {
+
+	stack := stackRef.Name()
+	var holders []string
+	backoff := 1.0
+	ready, _, err := retry.Until(ctx, retry.Acceptor{
+		Delay:    &pollInterval,
+		Backoff:  &backoff,
+		MaxDelay: &pollInterval,
+		Accept: func(try int, nextRetryTime time.Duration) (bool, interface{}, error) {
+			var stale bool
+			var err error
+			if holders, stale, err = b.listLockHolders(stack, ""); err != nil {
+				return false, nil, err
+			}
+			if len(holders) == 0 {
+				return true, nil, nil
+			}
+			if stale {
+				return false, nil, lockedStackError(stack, holders, stale)
+			}
+			logging.V(7).Infof("stack %s is locked; checking again in %v", stack, nextRetryTime)
+			return false, nil, nil
+		},
+	})
+	if err != nil || ready {
+		return err
+	}
+	return errors.Wrapf(ctx.Err(), "stack %s is still locked by %s", stack, strings.Join(holders, "; "))
+}
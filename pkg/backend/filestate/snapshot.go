@@ -15,9 +15,15 @@
 package filestate
 
 import (
+	"os"
+
+	"github.com/pkg/errors"
+
 	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
 	"github.com/pulumi/pulumi/pkg/secrets"
 	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 )
 
 // localSnapshotManager is a simple SnapshotManager implementation that persists snapshots
@@ -26,6 +32,16 @@ type localSnapshotPersister struct {
 	name    tokens.QName
 	backend *localBackend
 	sm      secrets.Manager
+	stream  bool // true if checkpoints should be written without first being formatted for readability.
+
+	// If the checkpoint is journaled, journal records the snapshots saved since the last full checkpoint, and
+	// uncompacted is the last of them, if there are any. journalSize and baseSize are the sizes of the journal's
+	// entries and of the full checkpoint, which bound the journal's growth.
+	journaled   bool
+	journal     *deploy.SnapshotJournal
+	uncompacted *deploy.Snapshot
+	journalSize int
+	baseSize    int
 }
 
 func (sp *localSnapshotPersister) SecretsManager() secrets.Manager {
@@ -33,11 +49,79 @@ func (sp *localSnapshotPersister) SecretsManager() secrets.Manager {
 }
 
 func (sp *localSnapshotPersister) Save(snapshot *deploy.Snapshot) error {
-	_, err := sp.backend.saveStack(sp.name, snapshot, sp.sm)
-	return err
+	sm := sp.sm
+	if sm == nil && snapshot != nil {
+		sm = snapshot.SecretsManager
+	}
+	if !sp.journaled || snapshot == nil || sp.backend.encryptsState(sp.name, snapshot, sm) {
+		_, err := sp.backend.saveStackCheckpoint(sp.name, snapshot, sp.sm, sp.stream)
+		return err
+	}
+
+	// Start a new journal if there is none yet, or if the current one has grown large enough to be compacted.
+	if sp.journal == nil || sp.journal.Len() >= journalCompactionInterval || sp.journalSize >= sp.baseSize {
+		return sp.compact(snapshot)
+	}
+
+	dep, err := stack.SerializeDeployment(snapshot, sp.sm)
+	if err != nil {
+		return errors.Wrap(err, "serializing deployment")
+	}
+	header, records, err := splitDeployment(dep)
+	if err != nil {
+		return errors.Wrap(err, "serializing deployment")
+	}
+	size, err := sp.backend.writeJournalEntry(sp.name, sp.journal.Record(header, records))
+	if err != nil {
+		return err
+	}
+	sp.uncompacted, sp.journalSize = snapshot, sp.journalSize+size
+
+	// As with full checkpoints, check the integrity of the snapshot only after it has been written.
+	if !DisableIntegrityChecking {
+		if verifyerr := snapshot.VerifyIntegrity(); verifyerr != nil {
+			return errors.Wrapf(verifyerr, "%s: snapshot integrity failure; it was already written, but is invalid",
+				sp.backend.journalDirectory(sp.name))
+		}
+	}
+	return nil
+}
+
+// Close writes the last snapshot that was saved as a full checkpoint, if it was only written to the journal.
+func (sp *localSnapshotPersister) Close() error {
+	if sp.uncompacted == nil {
+		return nil
+	}
+	return sp.compact(sp.uncompacted)
+}
+
+// compact writes the given snapshot as a full checkpoint and starts a new journal based on it.
+func (sp *localSnapshotPersister) compact(snapshot *deploy.Snapshot) error {
+	if _, err := sp.backend.saveStackCheckpoint(sp.name, snapshot, sp.sm, sp.stream); err != nil {
+		return err
+	}
+	if err := sp.backend.clearJournal(sp.name); err != nil {
+		return err
+	}
 
+	dep, err := stack.SerializeDeployment(snapshot, sp.sm)
+	if err != nil {
+		return errors.Wrap(err, "serializing deployment")
+	}
+	header, records, err := splitDeployment(dep)
+	if err != nil {
+		return errors.Wrap(err, "serializing deployment")
+	}
+	sp.journal = deploy.NewSnapshotJournal(journalBase(dep.Manifest.Time), records)
+	sp.uncompacted, sp.journalSize, sp.baseSize = nil, 0, len(header)
+	for _, record := range records {
+		sp.baseSize += len(record)
+	}
+	return nil
 }
 
-func (b *localBackend) newSnapshotPersister(stackName tokens.QName, sm secrets.Manager) *localSnapshotPersister {
-	return &localSnapshotPersister{name: stackName, backend: b, sm: sm}
+func (b *localBackend) newSnapshotPersister(stackName tokens.QName, sm secrets.Manager,
+	stream bool) *localSnapshotPersister {
+	return &localSnapshotPersister{name: stackName, backend: b, sm: sm, stream: stream,
+		journaled: cmdutil.IsTruthy(os.Getenv(JournalCheckpointsEnvVar))}
 }
@@ -18,6 +18,8 @@ import (
 	"context"
 	"time"
 
+	"github.com/pkg/errors"
+
 	"github.com/pulumi/pulumi/pkg/tokens"
 
 	"github.com/pulumi/pulumi/pkg/apitype"
@@ -36,10 +38,11 @@ type Stack interface {
 
 // localStack is a local stack descriptor.
 type localStack struct {
-	ref      backend.StackReference // the stack's reference (qualified name).
-	path     string                 // a path to the stack's checkpoint file on disk.
-	snapshot *deploy.Snapshot       // a snapshot representing the latest deployment state.
-	b        *localBackend          // a pointer to the backend this stack belongs to.
+	ref       backend.StackReference // the stack's reference (qualified name).
+	path      string                 // a path to the stack's checkpoint file on disk.
+	snapshot  *deploy.Snapshot       // a snapshot representing the latest deployment state.
+	b         *localBackend          // a pointer to the backend this stack belongs to.
+	encrypted bool                   // true if the stack's state is encrypted at rest, and not yet decrypted.
 }
 
 func newStack(ref backend.StackReference, path string, snapshot *deploy.Snapshot, b *localBackend) Stack {
@@ -51,10 +54,31 @@ func newStack(ref backend.StackReference, path string, snapshot *deploy.Snapshot
 	}
 }
 
-func (s *localStack) Ref() backend.StackReference                            { return s.ref }
-func (s *localStack) Snapshot(ctx context.Context) (*deploy.Snapshot, error) { return s.snapshot, nil }
-func (s *localStack) Backend() backend.Backend                               { return s.b }
-func (s *localStack) Path() string                                           { return s.path }
+// newEncryptedStack returns a stack whose state is encrypted at rest. The state is only decrypted, with the key of the
+// stack's secrets provider, once the stack's snapshot is needed.
+func newEncryptedStack(ref backend.StackReference, path string, b *localBackend) Stack {
+	return &localStack{
+		ref:       ref,
+		path:      path,
+		b:         b,
+		encrypted: true,
+	}
+}
+
+func (s *localStack) Ref() backend.StackReference { return s.ref }
+func (s *localStack) Backend() backend.Backend    { return s.b }
+func (s *localStack) Path() string                { return s.path }
+
+func (s *localStack) Snapshot(ctx context.Context) (*deploy.Snapshot, error) {
+	if s.encrypted {
+		snapshot, _, err := s.b.getStack(s.ref.Name())
+		if err != nil {
+			return nil, errors.Wrapf(err, "decrypting the state of stack %s", s.ref)
+		}
+		s.snapshot, s.encrypted = snapshot, false
+	}
+	return s.snapshot, nil
+}
 
 func (s *localStack) Query(ctx context.Context, op backend.UpdateOperation) result.Result {
 	return backend.Query(ctx, s, op)
@@ -21,6 +21,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -30,7 +31,6 @@ import (
 	"github.com/pulumi/pulumi/pkg/apitype"
 	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/encoding"
-	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/resource/deploy"
 	"github.com/pulumi/pulumi/pkg/resource/stack"
 	"github.com/pulumi/pulumi/pkg/secrets"
@@ -44,6 +44,14 @@ import (
 
 const DisableCheckpointBackupsEnvVar = "PULUMI_DISABLE_CHECKPOINT_BACKUPS"
 
+// EncryptStateEnvVar, when set, causes the checkpoints of every stack with a secrets provider to be encrypted at rest
+// with its key, rather than only those of stacks whose state has been encrypted with `pulumi state encrypt`.
+const EncryptStateEnvVar = backend.EncryptStateEnvVar
+
+// HistoryRetentionEnvVar, when set to a positive number, is the number of each stack's most recent updates whose
+// records and checkpoints are kept in its history; older ones are removed. By default, all of them are kept.
+const HistoryRetentionEnvVar = "PULUMI_HISTORY_RETENTION"
+
 // DisableIntegrityChecking can be set to true to disable checkpoint state integrity verification.  This is not
 // recommended, because it could mean proceeding even in the face of a corrupted checkpoint state file, but can
 // be used as a last resort when a command absolutely must be run.
@@ -73,7 +81,7 @@ func (b *localBackend) newUpdate(stackName tokens.QName, op backend.UpdateOperat
 	contract.Require(stackName != "", "stackName")
 
 	// Construct the deployment target.
-	target, err := b.getTarget(stackName, op.StackConfiguration.Config, op.StackConfiguration.Decrypter)
+	target, err := b.getTarget(stackName, op.StackConfiguration)
 	if err != nil {
 		return nil, err
 	}
@@ -87,60 +95,129 @@ func (b *localBackend) newUpdate(stackName tokens.QName, op backend.UpdateOperat
 	}, nil
 }
 
-func (b *localBackend) getTarget(stackName tokens.QName, cfg config.Map, dec config.Decrypter) (*deploy.Target, error) {
+func (b *localBackend) getTarget(stackName tokens.QName, cfg backend.StackConfiguration) (*deploy.Target, error) {
 	snapshot, _, err := b.getStack(stackName)
 	if err != nil {
 		return nil, err
 	}
 	return &deploy.Target{
-		Name:      stackName,
-		Config:    cfg,
-		Decrypter: dec,
-		Snapshot:  snapshot,
+		Name:            stackName,
+		Config:          cfg.Config,
+		Decrypter:       cfg.Decrypter,
+		Snapshot:        snapshot,
+		Transformations: cfg.Transformations,
+		NamePrefix:      cfg.NamePrefix,
+		NameSuffix:      cfg.NameSuffix,
+		Guardrails:      cfg.Guardrails,
 	}, nil
 }
 
 func (b *localBackend) getStack(name tokens.QName) (*deploy.Snapshot, string, error) {
+	snapshot, file, _, err := b.loadStack(name, true /*decrypt*/)
+	return snapshot, file, err
+}
+
+// loadStack loads the given stack's snapshot, and reports whether the stack's state is encrypted at rest. If it is
+// encrypted and decrypt is false, the state is not decrypted, and no snapshot is returned.
+func (b *localBackend) loadStack(name tokens.QName, decrypt bool) (*deploy.Snapshot, string, bool, error) {
 	if name == "" {
-		return nil, "", errors.New("invalid empty stack name")
+		return nil, "", false, errors.New("invalid empty stack name")
 	}
 
 	file := b.stackPath(name)
 
-	chk, err := b.getCheckpoint(name)
+	chk, encrypted, err := b.loadCheckpoint(name, decrypt)
 	if err != nil {
-		return nil, file, errors.Wrap(err, "failed to load checkpoint")
+		return nil, file, encrypted, errors.Wrap(err, "failed to load checkpoint")
+	}
+	if chk == nil {
+		return nil, file, encrypted, nil
 	}
 
 	// Materialize an actual snapshot object.
 	snapshot, err := stack.DeserializeCheckpoint(chk)
 	if err != nil {
-		return nil, "", err
+		return nil, "", encrypted, err
 	}
 
 	// Ensure the snapshot passes verification before returning it, to catch bugs early.
 	if !DisableIntegrityChecking {
 		if verifyerr := snapshot.VerifyIntegrity(); verifyerr != nil {
-			return nil, file,
+			return nil, file, encrypted,
 				errors.Wrapf(verifyerr, "%s: snapshot integrity failure; refusing to use it", file)
 		}
 	}
 
-	return snapshot, file, nil
+	return snapshot, file, encrypted, nil
 }
 
 // GetCheckpoint loads a checkpoint file for the given stack in this project, from the current project workspace.
 func (b *localBackend) getCheckpoint(stackName tokens.QName) (*apitype.CheckpointV3, error) {
+	chk, _, err := b.loadCheckpoint(stackName, true /*decrypt*/)
+	return chk, err
+}
+
+// loadCheckpoint loads the given stack's checkpoint, and reports whether it is encrypted at rest. An encrypted
+// checkpoint is decrypted with the key of the stack's secrets provider or, if decrypt is false, not returned at all.
+func (b *localBackend) loadCheckpoint(stackName tokens.QName, decrypt bool) (*apitype.CheckpointV3, bool, error) {
 	chkpath := b.stackPath(stackName)
 	bytes, err := b.bucket.ReadAll(context.TODO(), chkpath)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
-	return stack.UnmarshalVersionedCheckpointToLatestCheckpoint(bytes)
+	var secretsProv stack.SecretsProvider
+	if decrypt {
+		secretsProv = b.stateSecretsProvider(stackName)
+	}
+	chk, encrypted, err := stack.UnmarshalVersionedCheckpoint(bytes, secretsProv)
+	if encrypted {
+		b.setStateEncrypted(stackName, true)
+	}
+	if err != nil || chk == nil {
+		return nil, encrypted, err
+	}
+
+	// Bring the checkpoint up to date with the journal of the update that last wrote it, if any.
+	if err = b.replayJournal(stackName, chk); err != nil {
+		return nil, encrypted, err
+	}
+	return chk, encrypted, nil
 }
 
+// stateSecretsProvider returns the provider of the secrets manager that decrypts the given stack's state, if it is
+// encrypted at rest.
+func (b *localBackend) stateSecretsProvider(stackName tokens.QName) stack.SecretsProvider {
+	ref := localBackendReference{name: stackName}
+	return b.encryption.SecretsProvider(newEncryptedStack(ref, b.stackPath(stackName), b))
+}
+
+// isStateEncrypted returns true if the given stack's checkpoints are known to be encrypted at rest.
+func (b *localBackend) isStateEncrypted(stackName tokens.QName) bool {
+	return b.encryption.IsEncrypted(localBackendReference{name: stackName})
+}
+
+// setStateEncrypted records whether the given stack's checkpoints are encrypted at rest.
+func (b *localBackend) setStateEncrypted(stackName tokens.QName, encrypted bool) {
+	b.encryption.SetEncrypted(localBackendReference{name: stackName}, encrypted)
+}
+
+// saveStack saves the given snapshot as the stack's checkpoint, replacing any journal of changes to its last one.
 func (b *localBackend) saveStack(name tokens.QName, snap *deploy.Snapshot, sm secrets.Manager) (string, error) {
+	file, err := b.saveStackCheckpoint(name, snap, sm, false)
+	if err != nil {
+		return "", err
+	}
+	if err = b.clearJournal(name); err != nil {
+		return "", err
+	}
+	return file, nil
+}
+
+// saveStackCheckpoint saves the given snapshot as the stack's checkpoint. If stream is true and the checkpoint is
+// JSON, it is written without first being formatted for readability, so that no second copy of it is held in memory.
+func (b *localBackend) saveStackCheckpoint(name tokens.QName, snap *deploy.Snapshot, sm secrets.Manager,
+	stream bool) (string, error) {
 	// Make a serializable stack and then use the encoder to encode it.
 	file := b.stackPath(name)
 	m, ext := encoding.Detect(file)
@@ -154,16 +231,41 @@ func (b *localBackend) saveStack(name tokens.QName, snap *deploy.Snapshot, sm se
 	if err != nil {
 		return "", errors.Wrap(err, "serializaing checkpoint")
 	}
-	byts, err := m.Marshal(chk)
-	if err != nil {
-		return "", errors.Wrap(err, "An IO error occurred during the current operation")
+
+	// Encrypt the checkpoint at rest if the stack's state is encrypted. A stack without a secrets manager has no key
+	// to encrypt with, which is only an error if its state was explicitly encrypted and there is state to protect.
+	if sm == nil && snap != nil {
+		sm = snap.SecretsManager
+	}
+	if b.encryptsState(name, snap, sm) {
+		enc, err := stack.NewStateEncrypter(sm)
+		if err != nil {
+			return "", errors.Wrapf(err, "encrypting the state of stack %s", name)
+		}
+		if chk, err = stack.EncryptCheckpoint(chk, enc); err != nil {
+			return "", err
+		}
+		stream = false
+	}
+
+	var byts []byte
+	stream = stream && m.IsJSONLike()
+	if !stream {
+		if byts, err = m.Marshal(chk); err != nil {
+			return "", errors.Wrap(err, "An IO error occurred during the current operation")
+		}
 	}
 
 	// Back up the existing file if it already exists.
 	bck := backupTarget(b.bucket, file)
 
 	// And now write out the new snapshot file, overwriting that location.
-	if err = b.bucket.WriteAll(context.TODO(), file, byts, nil); err != nil {
+	if stream {
+		err = writeCheckpointStream(b.bucket, file, chk)
+	} else {
+		err = b.bucket.WriteAll(context.TODO(), file, byts, nil)
+	}
+	if err != nil {
 		return "", errors.Wrap(err, "An IO error occurred during the current operation")
 	}
 
@@ -171,7 +273,13 @@ func (b *localBackend) saveStack(name tokens.QName, snap *deploy.Snapshot, sm se
 
 	// And if we are retaining historical checkpoint information, write it out again
 	if cmdutil.IsTruthy(os.Getenv("PULUMI_RETAIN_CHECKPOINTS")) {
-		if err = b.bucket.WriteAll(context.TODO(), fmt.Sprintf("%v.%v", file, time.Now().UnixNano()), byts, nil); err != nil {
+		retained := fmt.Sprintf("%v.%v", file, time.Now().UnixNano())
+		if stream {
+			err = b.bucket.Copy(context.TODO(), retained, file, nil)
+		} else {
+			err = b.bucket.WriteAll(context.TODO(), retained, byts, nil)
+		}
+		if err != nil {
 			return "", errors.Wrap(err, "An IO error occurred during the current operation")
 		}
 	}
@@ -190,6 +298,34 @@ func (b *localBackend) saveStack(name tokens.QName, snap *deploy.Snapshot, sm se
 	return file, nil
 }
 
+// encryptsState returns true if the checkpoint of the given stack with the given snapshot and secrets manager is
+// encrypted at rest.
+func (b *localBackend) encryptsState(name tokens.QName, snap *deploy.Snapshot, sm secrets.Manager) bool {
+	return b.encryption.Encrypts(localBackendReference{name: name}, sm) && (sm != nil || snap != nil)
+}
+
+// writeCheckpointStream writes the given checkpoint to the object with the given key as compact JSON, directly from
+// its serialized form. If the write fails, any existing object is left as it was.
+func writeCheckpointStream(bucket Bucket, key string, chk *apitype.VersionedCheckpoint) error {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	w, err := bucket.NewWriter(ctx, key, nil)
+	if err != nil {
+		return err
+	}
+	for _, part := range [][]byte{[]byte(fmt.Sprintf(`{"version":%d,"checkpoint":`, chk.Version)), chk.Checkpoint,
+		[]byte("}\n")} {
+		if _, err = w.Write(part); err != nil {
+			// Canceling the write before closing the writer discards what was written so far.
+			cancel()
+			contract.IgnoreClose(w)
+			return err
+		}
+	}
+	return w.Close()
+}
+
 // removeStack removes information about a stack from the current workspace.
 func (b *localBackend) removeStack(name tokens.QName) error {
 	contract.Require(name != "", "name")
@@ -198,6 +334,10 @@ func (b *localBackend) removeStack(name tokens.QName) error {
 	file := b.stackPath(name)
 	backupTarget(b.bucket, file)
 
+	if err := b.clearJournal(name); err != nil {
+		return err
+	}
+
 	historyDir := b.historyDirectory(name)
 	return removeAllByPrefix(b.bucket, historyDir)
 }
@@ -240,6 +380,16 @@ func (b *localBackend) backupStack(name tokens.QName) error {
 	return b.bucket.WriteAll(context.TODO(), filepath.Join(backupDir, backupFile), byts, nil)
 }
 
+// checkWritable verifies that the given stack's checkpoint can be written, by writing and then removing a probe file
+// alongside it.
+func (b *localBackend) checkWritable(stack tokens.QName) error {
+	probe := b.stackPath(stack) + ".preflight"
+	if err := b.bucket.WriteAll(context.TODO(), probe, []byte{}, nil); err != nil {
+		return errors.Wrapf(err, "stack state at %s is not writable", filepath.Dir(probe))
+	}
+	return b.bucket.Delete(context.TODO(), probe)
+}
+
 func (b *localBackend) stackPath(stack tokens.QName) string {
 	path := filepath.Join(b.StateDir(), workspace.StackDir)
 	if stack != "" {
@@ -259,9 +409,16 @@ func (b *localBackend) backupDirectory(stack tokens.QName) string {
 	return filepath.Join(b.StateDir(), workspace.BackupDir, fsutil.QnamePath(stack))
 }
 
-// getHistory returns locally stored update history. The first element of the result will be
-// the most recent update record.
-func (b *localBackend) getHistory(name tokens.QName) ([]backend.UpdateInfo, error) {
+// historyEntry is a record of one of a stack's updates, which is kept along with a copy of the checkpoint it left.
+type historyEntry struct {
+	// prefix is the path of the entry's files, without their .history.json or .checkpoint.json suffixes.
+	prefix string
+	update backend.UpdateInfo
+}
+
+// listHistory returns the records of the given stack's updates, oldest first. Records written before updates were
+// versioned are numbered by their position in the history.
+func (b *localBackend) listHistory(name tokens.QName) ([]historyEntry, error) {
 	contract.Require(name != "", "name")
 
 	dir := b.historyDirectory(name)
@@ -274,12 +431,10 @@ func (b *localBackend) getHistory(name tokens.QName) ([]backend.UpdateInfo, erro
 		return nil, err
 	}
 
-	var updates []backend.UpdateInfo
-
-	// listBucket returns the array sorted by file name, but because of how we name files, older updates come before
-	// newer ones. Loop backwards so we added the newest updates to the array we will return first.
-	for i := len(allFiles) - 1; i >= 0; i-- {
-		file := allFiles[i]
+	// listBucket returns the array sorted by file name, and because of how we name files, older updates come before
+	// newer ones.
+	var entries []historyEntry
+	for _, file := range allFiles {
 		filepath := file.Key
 
 		// Open all of the history files, ignoring the checkpoints.
@@ -296,13 +451,57 @@ func (b *localBackend) getHistory(name tokens.QName) ([]backend.UpdateInfo, erro
 		if err != nil {
 			return nil, errors.Wrapf(err, "reading history file %s", filepath)
 		}
+		if update.Version == 0 {
+			update.Version = 1
+			if len(entries) > 0 {
+				update.Version = entries[len(entries)-1].update.Version + 1
+			}
+		}
 
-		updates = append(updates, update)
+		entries = append(entries, historyEntry{
+			prefix: strings.TrimSuffix(filepath, ".history.json"),
+			update: update,
+		})
 	}
 
+	return entries, nil
+}
+
+// getHistory returns locally stored update history. The first element of the result will be
+// the most recent update record.
+func (b *localBackend) getHistory(name tokens.QName) ([]backend.UpdateInfo, error) {
+	entries, err := b.listHistory(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var updates []backend.UpdateInfo
+	for i := len(entries) - 1; i >= 0; i-- {
+		updates = append(updates, entries[i].update)
+	}
 	return updates, nil
 }
 
+// getHistoryCheckpoint returns the checkpoint that the given version of the stack's updates left.
+func (b *localBackend) getHistoryCheckpoint(name tokens.QName, version int) (*apitype.CheckpointV3, error) {
+	entries, err := b.listHistory(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.update.Version != version {
+			continue
+		}
+		bytes, err := b.bucket.ReadAll(context.TODO(), entry.prefix+".checkpoint.json")
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading the checkpoint of version %d", version)
+		}
+		chk, _, err := stack.UnmarshalVersionedCheckpoint(bytes, b.stateSecretsProvider(name))
+		return chk, err
+	}
+	return nil, errors.Errorf("stack %s has no update with version %d", name, version)
+}
+
 func (b *localBackend) renameHistory(oldName tokens.QName, newName tokens.QName) error {
 	contract.Require(oldName != "", "oldName")
 	contract.Require(newName != "", "newName")
@@ -339,10 +538,20 @@ func (b *localBackend) renameHistory(oldName tokens.QName, newName tokens.QName)
 	return nil
 }
 
-// addToHistory saves the UpdateInfo and makes a copy of the current Checkpoint file.
+// addToHistory saves the UpdateInfo and makes a copy of the current Checkpoint file. The update is given the next
+// version of the stack's updates, and the records of updates older than the retention limit are removed.
 func (b *localBackend) addToHistory(name tokens.QName, update backend.UpdateInfo) error {
 	contract.Require(name != "", "name")
 
+	entries, err := b.listHistory(name)
+	if err != nil {
+		return err
+	}
+	update.Version = 1
+	if len(entries) > 0 {
+		update.Version = entries[len(entries)-1].update.Version + 1
+	}
+
 	dir := b.historyDirectory(name)
 
 	// Prefix for the update and checkpoint files.
@@ -361,5 +570,38 @@ func (b *localBackend) addToHistory(name tokens.QName, update backend.UpdateInfo
 
 	// Make a copy of the checkpoint file. (Assuming it already exists.)
 	checkpointFile := fmt.Sprintf("%s.checkpoint.json", pathPrefix)
-	return b.bucket.Copy(context.TODO(), checkpointFile, b.stackPath(name), nil)
+	if err = b.bucket.Copy(context.TODO(), checkpointFile, b.stackPath(name), nil); err != nil {
+		return err
+	}
+
+	retention, err := historyRetention()
+	if err != nil {
+		return err
+	}
+	if retention == 0 || len(entries) < retention {
+		return nil
+	}
+	for _, entry := range entries[:len(entries)+1-retention] {
+		for _, file := range []string{entry.prefix + ".history.json", entry.prefix + ".checkpoint.json"} {
+			if err = b.bucket.Delete(context.TODO(), file); err != nil &&
+				gcerrors.Code(errors.Cause(err)) != gcerrors.NotFound {
+				return errors.Wrapf(err, "removing history file %s", file)
+			}
+		}
+	}
+	return nil
+}
+
+// historyRetention returns the number of each stack's most recent updates whose records are kept, or 0 if all of them
+// are.
+func historyRetention() (int, error) {
+	v := os.Getenv(HistoryRetentionEnvVar)
+	if v == "" {
+		return 0, nil
+	}
+	retention, err := strconv.Atoi(v)
+	if err != nil || retention < 0 {
+		return 0, errors.Errorf("%s must be a non-negative number of updates, not '%s'", HistoryRetentionEnvVar, v)
+	}
+	return retention, nil
 }
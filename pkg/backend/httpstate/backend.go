@@ -18,6 +18,7 @@ import (
 	"context"
 	cryptorand "crypto/rand"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net"
@@ -46,12 +47,15 @@ import (
 	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/secrets"
 	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 	"github.com/pulumi/pulumi/pkg/util/contract"
 	"github.com/pulumi/pulumi/pkg/util/logging"
 	"github.com/pulumi/pulumi/pkg/util/result"
 	"github.com/pulumi/pulumi/pkg/util/retry"
+	"github.com/pulumi/pulumi/pkg/version"
 	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
@@ -67,6 +71,11 @@ const (
 	defaultURLEnvVar = "PULUMI_API"
 	// AccessTokenEnvVar is the environment variable used to bypass a prompt on login.
 	AccessTokenEnvVar = "PULUMI_ACCESS_TOKEN"
+	// LowBandwidthEnvVar can be set to summarize verbose events when displaying updates over slow links.
+	LowBandwidthEnvVar = "PULUMI_LOW_BANDWIDTH"
+	// DisableEventStreamingEnvVar can be set to send an update's engine events to the service in separate requests
+	// rather than over a single stream.
+	DisableEventStreamingEnvVar = "PULUMI_DISABLE_EVENT_STREAMING"
 )
 
 // DefaultURL returns the default cloud URL.  This may be overridden using the PULUMI_API environment
@@ -109,6 +118,19 @@ type Backend interface {
 	CancelCurrentUpdate(ctx context.Context, stackRef backend.StackReference) error
 	StackConsoleURL(stackRef backend.StackReference) (string, error)
 	Client() *client.Client
+
+	// ExportDeploymentVersion exports the stack's deployment as of the given update version.
+	ExportDeploymentVersion(ctx context.Context, stackRef backend.StackReference,
+		version int) (*apitype.UntypedDeployment, error)
+
+	// RecordSecretsRevealed records in the stack's audit log that the given command revealed its secrets.
+	RecordSecretsRevealed(ctx context.Context, stackRef backend.StackReference, command string, version int,
+		paths []string) error
+
+	// GetStackSchema returns the schema last published by the stack, or nil if it has not published one.
+	GetStackSchema(ctx context.Context, stackRef backend.StackReference) (*apitype.StackSchema, error)
+	// PublishStackSchema replaces the schema of the stack's configuration and outputs.
+	PublishStackSchema(ctx context.Context, stackRef backend.StackReference, schema apitype.StackSchema) error
 }
 
 type cloudBackend struct {
@@ -116,6 +138,7 @@ type cloudBackend struct {
 	url            string
 	client         *client.Client
 	currentProject *workspace.Project
+	encryption     backend.StateEncryption // the stacks whose state is encrypted at rest.
 }
 
 // New creates a new Pulumi backend for the given cloud API URL and token.
@@ -245,8 +268,7 @@ func Login(ctx context.Context, d diag.Sink, cloudURL string, opts display.Optio
 	} else if !cmdutil.Interactive() {
 		// If interactive mode isn't enabled, the only way to specify a token is through the environment variable.
 		// Fail the attempt to login.
-		return nil, errors.Errorf(
-			"%s must be set for login during non-interactive CLI sessions", AccessTokenEnvVar)
+		return nil, cmdutil.NonInteractiveError("an access token is required to log in", "set "+AccessTokenEnvVar)
 	} else {
 		// If no access token is available from the environment, and we are interactive, prompt and offer to
 		// open a browser to make it easy to generate and use a fresh token.
@@ -541,6 +563,9 @@ func (b *cloudBackend) ListStacks(
 		TagName:      filter.TagName,
 		TagValue:     filter.TagValue,
 	}
+	if filter.NamesOnly {
+		clientFilter.Fields = client.StackNameFields
+	}
 
 	apiSummaries, err := b.client.ListStacks(ctx, clientFilter)
 	if err != nil {
@@ -680,13 +705,16 @@ func (b *cloudBackend) createAndStartUpdate(
 			op.Opts.Engine.RequiredPolicies, newCloudRequiredPolicy(b.client, policy))
 	}
 
+	// An update that must be approved may not start until it is.
+	if !dryRun && op.Opts.RequireApproval {
+		if err = b.waitForApproval(ctx, update, *op); err != nil {
+			return client.UpdateIdentifier{}, 0, "", err
+		}
+	}
+
 	// Start the update. We use this opportunity to pass new tags to the service, to pick up any
 	// metadata changes.
-	tags, err := backend.GetMergedStackTags(ctx, stack)
-	if err != nil {
-		return client.UpdateIdentifier{}, 0, "", errors.Wrap(err, "getting stack tags")
-	}
-	version, token, err := b.client.StartUpdate(ctx, update, tags)
+	version, token, err := b.startUpdate(ctx, update, stackID)
 	if err != nil {
 		return client.UpdateIdentifier{}, 0, "", err
 	}
@@ -698,6 +726,132 @@ func (b *cloudBackend) createAndStartUpdate(
 	return update, version, token, nil
 }
 
+// maxStartUpdateAttempts is how many times starting an update is attempted while the stack's tags keep changing.
+const maxStartUpdateAttempts = 3
+
+// startUpdate starts the given update, merging the tags derived from the environment and the project into the stack's
+// tags, so that tags that others have set on the stack are kept. The stack's existing tags are sent too, so that
+// services that predate merging, and replace the tags instead, keep them as well; they are sent along with their
+// entity tag, and are read afresh if they change before the update starts.
+func (b *cloudBackend) startUpdate(ctx context.Context, update client.UpdateIdentifier,
+	stackID client.StackIdentifier) (int, string, error) {
+
+	envTags, err := backend.GetEnvironmentTagsForCurrentStack()
+	if err != nil {
+		return 0, "", errors.Wrap(err, "getting stack tags")
+	}
+
+	for attempt := 1; ; attempt++ {
+		stack, err := b.client.GetStack(ctx, stackID)
+		if err != nil {
+			return 0, "", errors.Wrap(err, "getting stack tags")
+		}
+		tags := make(map[apitype.StackTagName]string)
+		for k, v := range stack.Tags {
+			tags[k] = v
+		}
+		for k, v := range envTags {
+			tags[k] = v
+		}
+
+		version, token, err := b.client.StartUpdate(ctx, update, client.TagsUpdate{
+			Tags:    tags,
+			Merge:   true,
+			IfMatch: stack.TagsETag,
+		})
+		if err != client.ErrStackTagsChanged || attempt == maxStartUpdateAttempts {
+			return version, token, err
+		}
+		logging.V(7).Infof("Stack tags changed while starting update %s; retrying", update.UpdateID)
+	}
+}
+
+// approvalPollInterval is how often the status of an update's request for approval is polled.
+var approvalPollInterval = 10 * time.Second
+
+// waitForApproval submits the given update's plan for approval, then waits until an approver approves or rejects it.
+func (b *cloudBackend) waitForApproval(ctx context.Context, update client.UpdateIdentifier,
+	op backend.UpdateOperation) error {
+
+	if op.Opts.Engine.Plan == nil {
+		return errors.New("an update must have a plan to be approved")
+	}
+	plan, err := engine.SerializeUpdatePlan(op.Opts.Engine.Plan)
+	if err != nil {
+		return err
+	}
+	if err = b.client.RequestApproval(ctx, update, *plan); err != nil {
+		return errors.Wrap(err, "requesting approval")
+	}
+
+	colorize := op.Opts.Display.Color.Colorize
+	if !op.Opts.Display.JSONDisplay {
+		fmt.Print(colorize(colors.SpecHeadline + "Waiting for the update to be approved..." + colors.Reset + "\n"))
+	}
+
+	// Stop waiting once the approval timeout, if any, has passed.
+	pollCtx := ctx
+	if op.Opts.ApprovalTimeout > 0 {
+		var cancel context.CancelFunc
+		pollCtx, cancel = context.WithTimeout(ctx, op.Opts.ApprovalTimeout)
+		defer cancel()
+	}
+	timedOut := func() bool {
+		return ctx.Err() == nil && pollCtx.Err() == context.DeadlineExceeded
+	}
+	timeoutErr := errors.Errorf("the update was not approved within %v", op.Opts.ApprovalTimeout)
+
+	for {
+		status, err := b.client.GetApprovalStatus(pollCtx, update)
+		if err != nil {
+			if timedOut() {
+				return timeoutErr
+			}
+			return errors.Wrap(err, "getting approval status")
+		}
+
+		switch status.Status {
+		case apitype.ApprovalApproved:
+			if !op.Opts.Display.JSONDisplay {
+				fmt.Printf("Approved by %s\n", status.Approver)
+			}
+			return nil
+		case apitype.ApprovalRejected:
+			msg := fmt.Sprintf("the update was rejected by %s", status.Approver)
+			if status.Comment != "" {
+				msg += ": " + status.Comment
+			}
+			return errors.New(msg)
+		}
+
+		select {
+		case <-pollCtx.Done():
+			if timedOut() {
+				return timeoutErr
+			}
+			return ctx.Err()
+		case <-time.After(approvalPollInterval):
+		}
+	}
+}
+
+// checkWritable verifies that the current user may update the given stack. Services that do not report the user's
+// permission on the stack are assumed to allow the update.
+func (b *cloudBackend) checkWritable(ctx context.Context, stackRef backend.StackReference) error {
+	stackID, err := b.getCloudStackIdentifier(stackRef)
+	if err != nil {
+		return err
+	}
+	stack, err := b.client.GetStack(ctx, stackID)
+	if err != nil {
+		return err
+	}
+	if stack.Permission != nil && *stack.Permission < apitype.StackPermissionWrite {
+		return errors.Errorf("you do not have permission to update stack '%s'", stackRef)
+	}
+	return nil
+}
+
 // apply actually performs the provided type of update on a stack hosted in the Pulumi Cloud.
 func (b *cloudBackend) apply(
 	ctx context.Context, kind apitype.UpdateKind, stack backend.Stack,
@@ -712,6 +866,27 @@ func (b *cloudBackend) apply(
 			colors.SpecHeadline+"%s (%s):"+colors.Reset+"\n"), actionLabel, stack.Ref())
 	}
 
+	// Have the engine's pre-flight checks verify that the user may update the stack before the program runs.
+	if kind == apitype.UpdateUpdate {
+		op.Opts.Engine.CheckWritable = func() error { return b.checkWritable(ctx, stack.Ref()) }
+	}
+
+	// Run the pre-flight checks before the update is started, so that the service never records an update that cannot
+	// succeed. The default providers they configure are handed over to the update.
+	runsProgram := kind == apitype.UpdateUpdate || kind == apitype.PreviewUpdate
+	if runsProgram && !op.Opts.Engine.SkipPreflight && len(op.Opts.Engine.Imports) == 0 {
+		q, err := b.newQuery(ctx, stack.Ref(), op)
+		if err != nil {
+			return nil, result.FromError(err)
+		}
+		providers, err := engine.Preflight(q, op.Opts.Engine)
+		if err != nil {
+			return nil, result.FromError(err)
+		}
+		defer contract.IgnoreClose(providers)
+		op.Opts.Engine.Preflight = providers
+	}
+
 	// Create an update object to persist results.
 	update, version, token, err :=
 		b.createAndStartUpdate(ctx, kind, stack, &op, opts.DryRun)
@@ -729,6 +904,9 @@ func (b *cloudBackend) apply(
 			link = b.CloudConsoleURL(base, "previews", update.UpdateID)
 		}
 		if link != "" {
+			if op.Permalink != nil {
+				*op.Permalink = link
+			}
 			defer func() {
 				fmt.Printf(op.Opts.Display.Color.Colorize(
 					colors.SpecHeadline+"Permalink: "+
@@ -829,6 +1007,9 @@ func (b *cloudBackend) runEngineAction(
 			if callerEventsOpt != nil {
 				callerEventsOpt <- e
 			}
+			if op.Events != nil {
+				op.Events <- e
+			}
 		}
 
 		close(eventsDone)
@@ -836,7 +1017,7 @@ func (b *cloudBackend) runEngineAction(
 
 	// The backend.SnapshotManager and backend.SnapshotPersister will keep track of any changes to
 	// the Snapshot (checkpoint file) in the HTTP backend.
-	persister := b.newSnapshotPersister(ctx, u.update, u.tokenSource, op.SecretsManager)
+	persister := b.newSnapshotPersister(ctx, stackRef, u.update, u.tokenSource, op.SecretsManager)
 	snapshotManager := backend.NewSnapshotManager(persister, u.GetTarget().Snapshot)
 
 	// Depending on the action, kick off the relevant engine activity.  Note that we don't immediately check and
@@ -888,6 +1069,19 @@ func (b *cloudBackend) runEngineAction(
 		res = result.Merge(res, result.FromError(errors.Wrap(completeErr, "failed to complete update")))
 	}
 
+	// Once the stack has been updated, publish the schema of its configuration and outputs, so that the stacks that
+	// reference it can check the outputs they read.
+	if kind == apitype.UpdateUpdate && !dryRun && res == nil {
+		schema, err := newStackSchema(op.Proj)
+		if err == nil && schema != nil {
+			err = b.PublishStackSchema(ctx, stackRef, *schema)
+		}
+		if err != nil {
+			// The update itself succeeded, so only warn that the stacks that reference this one can't check it.
+			b.d.Warningf(diag.Message("", "failed to publish stack schema: %v"), err)
+		}
+	}
+
 	return changes, res
 }
 
@@ -916,6 +1110,16 @@ func (b *cloudBackend) CancelCurrentUpdate(ctx context.Context, stackRef backend
 	return b.client.CancelUpdate(ctx, updateID)
 }
 
+func (b *cloudBackend) WaitForStackReady(ctx context.Context, stackRef backend.StackReference,
+	pollInterval time.Duration) error {
+
+	stackID, err := b.getCloudStackIdentifier(stackRef)
+	if err != nil {
+		return err
+	}
+	return b.client.WaitForStackReady(ctx, stackID, pollInterval)
+}
+
 func (b *cloudBackend) GetHistory(ctx context.Context, stackRef backend.StackReference) ([]backend.UpdateInfo, error) {
 	stack, err := b.getCloudStackIdentifier(stackRef)
 	if err != nil {
@@ -944,6 +1148,7 @@ func (b *cloudBackend) GetHistory(ctx context.Context, stackRef backend.StackRef
 			Result:          backend.UpdateResult(update.Result),
 			StartTime:       update.StartTime,
 			EndTime:         update.EndTime,
+			Version:         update.Version,
 			ResourceChanges: convertResourceChanges(update.ResourceChanges),
 		})
 	}
@@ -987,9 +1192,12 @@ func convertConfig(apiConfig map[string]apitype.ConfigValue) (config.Map, error)
 		if err != nil {
 			return nil, err
 		}
-		if rawV.Secret {
+		switch {
+		case rawV.Object:
+			c[k] = config.NewObjectValue(rawV.String)
+		case rawV.Secret:
 			c[k] = config.NewSecureValue(rawV.String)
-		} else {
+		default:
 			c[k] = config.NewValue(rawV.String)
 		}
 	}
@@ -1007,38 +1215,156 @@ func (b *cloudBackend) GetLogs(ctx context.Context, stackRef backend.StackRefere
 		return nil, errors.New("stack not found")
 	}
 
-	target, targetErr := b.getTarget(ctx, stackRef, cfg.Config, cfg.Decrypter)
+	target, targetErr := b.getTarget(ctx, stackRef, cfg, nil)
 	if targetErr != nil {
 		return nil, targetErr
 	}
-	return filestate.GetLogsForTarget(target, logQuery)
+	return filestate.GetLogsForTarget(b.d, target, logQuery)
 }
 
 func (b *cloudBackend) ExportDeployment(ctx context.Context,
 	stackRef backend.StackReference) (*apitype.UntypedDeployment, error) {
 
-	stack, err := b.getCloudStackIdentifier(stackRef)
+	return b.exportDeployment(ctx, stackRef, nil)
+}
+
+func (b *cloudBackend) ExportDeploymentVersion(ctx context.Context, stackRef backend.StackReference,
+	version int) (*apitype.UntypedDeployment, error) {
+
+	return b.exportDeployment(ctx, stackRef, &version)
+}
+
+// exportDeployment exports the stack's deployment as of the given update version, or the latest one if version is nil.
+func (b *cloudBackend) exportDeployment(ctx context.Context, stackRef backend.StackReference,
+	version *int) (*apitype.UntypedDeployment, error) {
+
+	stackID, err := b.getCloudStackIdentifier(stackRef)
+	if err != nil {
+		return nil, err
+	}
+
+	deployment, err := b.client.ExportStackDeployment(ctx, stackID, version)
 	if err != nil {
 		return nil, err
 	}
+	if deployment.Encrypted == nil {
+		return &deployment, nil
+	}
+
+	// The deployment is encrypted at rest, so decrypt it with the key of the stack's secrets provider, and remember
+	// to encrypt the stack's later checkpoints in the same way.
+	b.encryption.SetEncrypted(stackRef, true)
+	s, err := b.GetStack(ctx, stackRef)
+	if err != nil {
+		return nil, err
+	}
+	return stack.DecryptDeployment(&deployment, b.encryption.SecretsProvider(s))
+}
+
+func (b *cloudBackend) RecordSecretsRevealed(ctx context.Context, stackRef backend.StackReference, command string,
+	version int, paths []string) error {
+
+	stack, err := b.getCloudStackIdentifier(stackRef)
+	if err != nil {
+		return err
+	}
 
-	deployment, err := b.client.ExportStackDeployment(ctx, stack)
+	return b.client.RecordSecretsRevealed(ctx, stack, apitype.RecordSecretsRevealedRequest{
+		Command: command,
+		Version: version,
+		Paths:   paths,
+	})
+}
+
+func (b *cloudBackend) GetStackSchema(ctx context.Context,
+	stackRef backend.StackReference) (*apitype.StackSchema, error) {
+
+	stack, err := b.getCloudStackIdentifier(stackRef)
 	if err != nil {
 		return nil, err
 	}
 
-	return &deployment, nil
+	return b.client.GetStackSchema(ctx, stack)
+}
+
+func (b *cloudBackend) PublishStackSchema(ctx context.Context, stackRef backend.StackReference,
+	schema apitype.StackSchema) error {
+
+	stack, err := b.getCloudStackIdentifier(stackRef)
+	if err != nil {
+		return err
+	}
+
+	return b.client.PublishStackSchema(ctx, stack, schema)
+}
+
+// newStackSchema returns the schema of the configuration and outputs of the given project's stacks, or nil if the
+// project declares neither.
+func newStackSchema(proj *workspace.Project) (*apitype.StackSchema, error) {
+	if proj == nil || len(proj.ConfigSchema) == 0 && len(proj.OutputSchema) == 0 {
+		return nil, nil
+	}
+
+	schema := &apitype.StackSchema{
+		Config:  make(map[string]apitype.StackSchemaType),
+		Outputs: make(map[string]apitype.StackSchemaType),
+	}
+	for k, t := range proj.ConfigSchema {
+		key, err := proj.ConfigSchemaKey(k)
+		if err != nil {
+			return nil, errors.Wrapf(err, "config schema for '%s'", k)
+		}
+		typ := t.Type
+		if typ == "" {
+			typ = workspace.ConfigTypeString
+		}
+		schema.Config[key.String()] = apitype.StackSchemaType{Type: typ, Description: t.Description, Secret: t.Secret}
+	}
+	for k, t := range proj.OutputSchema {
+		schema.Outputs[k] = apitype.StackSchemaType{Type: t.Type, Description: t.Description, Secret: t.Secret}
+	}
+	return schema, nil
 }
 
 func (b *cloudBackend) ImportDeployment(ctx context.Context, stackRef backend.StackReference,
 	deployment *apitype.UntypedDeployment) error {
 
-	stack, err := b.getCloudStackIdentifier(stackRef)
+	// Only resolve the stack's secrets manager, which may prompt for its passphrase, if the deployment may need to be
+	// encrypted with it.
+	var sm secrets.Manager
+	if b.encryption.IsEncrypted(stackRef) || cmdutil.IsTruthy(os.Getenv(backend.EncryptStateEnvVar)) {
+		s, err := b.GetStack(ctx, stackRef)
+		if err != nil {
+			return err
+		}
+		if sm, err = b.encryption.SecretsManager(s); err != nil {
+			return errors.Wrap(err, "getting secrets manager")
+		}
+	}
+	return b.importDeployment(ctx, stackRef, deployment, sm)
+}
+
+// importDeployment imports the given deployment into the stack, encrypted at rest with the key of the given secrets
+// manager if the stack's state is encrypted.
+func (b *cloudBackend) importDeployment(ctx context.Context, stackRef backend.StackReference,
+	deployment *apitype.UntypedDeployment, sm secrets.Manager) error {
+
+	stackID, err := b.getCloudStackIdentifier(stackRef)
 	if err != nil {
 		return err
 	}
 
-	update, err := b.client.ImportStackDeployment(ctx, stack, deployment)
+	if deployment.Encrypted == nil && b.encryption.Encrypts(stackRef, sm) {
+		enc, err := stack.NewStateEncrypter(sm)
+		if err != nil {
+			return err
+		}
+		if deployment, err = stack.EncryptDeployment(deployment, enc); err != nil {
+			return err
+		}
+	}
+
+	update, err := b.client.ImportStackDeployment(ctx, stackID, deployment)
 	if err != nil {
 		return err
 	}
@@ -1046,7 +1372,7 @@ func (b *cloudBackend) ImportDeployment(ctx context.Context, stackRef backend.St
 	// Wait for the import to complete, which also polls and renders event output to STDOUT.
 	status, err := b.waitForUpdate(
 		ctx, backend.ActionLabel(apitype.ImportUpdate, false /*dryRun*/), update,
-		display.Options{Color: colors.Always})
+		display.Options{Color: colors.Always, LowBandwidth: cmdutil.IsTruthy(os.Getenv(LowBandwidthEnvVar))})
 	if err != nil {
 		return errors.Wrap(err, "waiting for import")
 	} else if status != apitype.StatusSucceeded {
@@ -1055,6 +1381,42 @@ func (b *cloudBackend) ImportDeployment(ctx context.Context, stackRef backend.St
 	return nil
 }
 
+func (b *cloudBackend) SetStateEncryption(ctx context.Context, stackRef backend.StackReference, sm secrets.Manager,
+	encrypt bool) error {
+
+	deployment, err := b.exportDeployment(ctx, stackRef, nil)
+	if err != nil {
+		return err
+	}
+	if len(deployment.Deployment) == 0 {
+		// Import an empty deployment, so that the stack's state records the secrets provider it is encrypted with.
+		empty, err := stack.SerializeDeployment(deploy.NewSnapshot(deploy.Manifest{
+			Time:    time.Now(),
+			Version: version.Version,
+		}, sm, nil, nil), sm)
+		if err != nil {
+			return errors.Wrap(err, "serializing deployment")
+		}
+		raw, err := json.Marshal(empty)
+		if err != nil {
+			return err
+		}
+		deployment = &apitype.UntypedDeployment{Version: 3, Deployment: raw}
+	}
+
+	wasEncrypted := b.encryption.IsEncrypted(stackRef)
+	b.encryption.SetEncrypted(stackRef, encrypt)
+	if err = b.importDeployment(ctx, stackRef, deployment, sm); err != nil {
+		b.encryption.SetEncrypted(stackRef, wasEncrypted)
+		return err
+	}
+	return nil
+}
+
+func (b *cloudBackend) SetStateSecretsManagerSource(source backend.StateSecretsManagerSource) {
+	b.encryption.SetSecretsManagerSource(source)
+}
+
 var (
 	projectNameCleanRegexp = regexp.MustCompile("[^a-zA-Z0-9-_.]")
 )
@@ -1109,7 +1471,10 @@ func (b *cloudBackend) waitForUpdate(ctx context.Context, actionLabel string, up
 		close(events)
 		close(done)
 	}()
-	go displayEvents(strings.ToLower(actionLabel), events, done, displayOpts)
+	fetch := func(index string) (apitype.UpdateEvent, error) {
+		return b.client.GetUpdateEvent(ctx, update, index)
+	}
+	go displayEvents(strings.ToLower(actionLabel), events, done, displayOpts, fetch)
 
 	// The UpdateEvents API returns a continuation token to only get events after the previous call.
 	var continuationToken *string
@@ -1117,7 +1482,7 @@ func (b *cloudBackend) waitForUpdate(ctx context.Context, actionLabel string, up
 		// Query for the latest update results, including log entries so we can provide active status updates.
 		_, results, err := retry.Until(context.Background(), retry.Acceptor{
 			Accept: func(try int, nextRetryTime time.Duration) (bool, interface{}, error) {
-				return b.tryNextUpdate(ctx, update, continuationToken, try, nextRetryTime)
+				return b.tryNextUpdate(ctx, update, continuationToken, displayOpts.LowBandwidth, try, nextRetryTime)
 			},
 		})
 		if err != nil {
@@ -1138,7 +1503,9 @@ func (b *cloudBackend) waitForUpdate(ctx context.Context, actionLabel string, up
 	}
 }
 
-func displayEvents(action string, events <-chan displayEvent, done chan<- bool, opts display.Options) {
+func displayEvents(action string, events <-chan displayEvent, done chan<- bool, opts display.Options,
+	fetch func(index string) (apitype.UpdateEvent, error)) {
+
 	prefix := fmt.Sprintf("%s%s...", cmdutil.EmojiOr("✨ ", "@ "), action)
 	spinner, ticker := cmdutil.NewSpinnerAndTicker(prefix, nil, 8 /*timesPerSecond*/)
 
@@ -1158,9 +1525,12 @@ func displayEvents(action string, events <-chan displayEvent, done chan<- bool,
 			}
 
 			// Pluck out the string.
-			payload := event.Payload.(apitype.UpdateEvent)
+			payload := expandUpdateEvent(event.Payload.(apitype.UpdateEvent), opts, fetch)
 			if raw, ok := payload.Fields["text"]; ok && raw != nil {
 				if text, ok := raw.(string); ok {
+					if opts.LowBandwidth && !expandsUpdateEvent(payload, opts) {
+						text = display.SummarizeText(text, display.LowBandwidthMaxLines)
+					}
 					text = opts.Color.Colorize(text)
 
 					// Choose the stream to write to (by default stdout).
@@ -1181,13 +1551,39 @@ func displayEvents(action string, events <-chan displayEvent, done chan<- bool,
 	}
 }
 
+// expandsUpdateEvent returns true if the given event is shown in full in low-bandwidth mode, following the same rule
+// as the display of local updates: errors are, and so is everything else if debug output was requested.
+func expandsUpdateEvent(event apitype.UpdateEvent, opts display.Options) bool {
+	severity := diag.Info
+	if event.Kind == apitype.StderrEvent {
+		severity = diag.Error
+	}
+	return display.ExpandsDiagnostic(severity, opts)
+}
+
+// expandUpdateEvent returns the event to display in place of the given one. The service summarizes events when
+// low-bandwidth mode requests it; the full payload of a summarized event is fetched only if the event is shown in full.
+func expandUpdateEvent(event apitype.UpdateEvent, opts display.Options,
+	fetch func(index string) (apitype.UpdateEvent, error)) apitype.UpdateEvent {
+
+	if !event.Truncated || !expandsUpdateEvent(event, opts) {
+		return event
+	}
+	full, err := fetch(event.Index)
+	if err != nil {
+		logging.V(3).Infof("Could not fetch the full payload of event %s: %v", event.Index, err)
+		return event
+	}
+	return full
+}
+
 // tryNextUpdate tries to get the next update for a Pulumi program.  This may time or error out, which results in a
 // false returned in the first return value.  If a non-nil error is returned, this operation should fail.
 func (b *cloudBackend) tryNextUpdate(ctx context.Context, update client.UpdateIdentifier, continuationToken *string,
-	try int, nextRetryTime time.Duration) (bool, interface{}, error) {
+	summarize bool, try int, nextRetryTime time.Duration) (bool, interface{}, error) {
 
 	// If there is no error, we're done.
-	results, err := b.client.GetUpdateEvents(ctx, update, continuationToken)
+	results, err := b.client.GetUpdateEvents(ctx, update, continuationToken, summarize)
 	if err == nil {
 		return true, results, nil
 	}
@@ -1287,6 +1683,24 @@ func (c httpstateBackendClient) GetStackOutputs(ctx context.Context, name string
 	return backend.NewBackendClient(c.backend).GetStackOutputs(ctx, name)
 }
 
+// GetStackSchema returns the schema last published by the named stack, or nil if it has not published one.
+func (c httpstateBackendClient) GetStackSchema(ctx context.Context, name string) (*apitype.StackSchema, error) {
+	ref, err := c.backend.ParseStackReference(name)
+	if err != nil {
+		return nil, err
+	}
+	return c.backend.GetStackSchema(ctx, ref)
+}
+
+// NormalizeStackReference returns the canonical name of the stack with the given name.
+func (c httpstateBackendClient) NormalizeStackReference(name string) (string, error) {
+	ref, err := c.backend.ParseStackReference(name)
+	if err != nil {
+		return "", err
+	}
+	return ref.String(), nil
+}
+
 func (c httpstateBackendClient) GetStackResourceOutputs(
 	ctx context.Context, name string) (resource.PropertyMap, error) {
 	return backend.NewBackendClient(c.backend).GetStackResourceOutputs(ctx, name)
@@ -0,0 +1,424 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpstate
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	_ "gocloud.dev/secrets/localsecrets" // support for base64key://
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/backend/httpstate/client"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/secrets"
+	"github.com/pulumi/pulumi/pkg/secrets/cloud"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestExpandUpdateEvent(t *testing.T) {
+	var fetched []string
+	fetch := func(index string) (apitype.UpdateEvent, error) {
+		fetched = append(fetched, index)
+		return apitype.UpdateEvent{Index: index, Kind: apitype.StderrEvent, Fields: map[string]interface{}{
+			"text": "full",
+		}}, nil
+	}
+	opts := display.Options{LowBandwidth: true}
+
+	// Events that were not summarized are never fetched.
+	whole := apitype.UpdateEvent{Index: "1", Kind: apitype.StderrEvent}
+	assert.Equal(t, whole, expandUpdateEvent(whole, opts, fetch))
+
+	// Summarized output is left summarized, and its payload is not fetched.
+	stdout := apitype.UpdateEvent{Index: "2", Kind: apitype.StdoutEvent, Truncated: true}
+	assert.Equal(t, stdout, expandUpdateEvent(stdout, opts, fetch))
+	assert.Empty(t, fetched)
+
+	// Summarized errors are shown in full, so their payloads are fetched.
+	stderr := apitype.UpdateEvent{Index: "3", Kind: apitype.StderrEvent, Truncated: true}
+	assert.Equal(t, "full", expandUpdateEvent(stderr, opts, fetch).Fields["text"])
+	assert.Equal(t, []string{"3"}, fetched)
+
+	// With debug output, everything is shown in full.
+	opts.Debug = true
+	assert.Equal(t, "full", expandUpdateEvent(stdout, opts, fetch).Fields["text"])
+	assert.Equal(t, []string{"3", "2"}, fetched)
+}
+
+func TestWaitForApproval(t *testing.T) {
+	defer func(interval time.Duration) { approvalPollInterval = interval }(approvalPollInterval)
+	approvalPollInterval = time.Millisecond
+
+	var requested bool
+	var polls int
+	var final string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/stacks/acme/web/prod/update/abc/approval", r.URL.Path)
+		if r.Method == "POST" {
+			requested = true
+			return
+		}
+		polls++
+		status := `{"status":"pending"}`
+		if polls == 3 {
+			status = final
+		}
+		_, err := w.Write([]byte(status))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	b := &cloudBackend{client: client.NewClient(server.URL, "", nil)}
+	update := client.UpdateIdentifier{
+		StackIdentifier: client.StackIdentifier{Owner: "acme", Project: "web", Stack: "prod"},
+		UpdateKind:      apitype.UpdateUpdate,
+		UpdateID:        "abc",
+	}
+	op := backend.UpdateOperation{
+		Opts: backend.UpdateOptions{
+			Engine:  engine.UpdateOptions{Plan: engine.NewUpdatePlan()},
+			Display: display.Options{JSONDisplay: true},
+		},
+	}
+
+	// Updates wait until they are approved.
+	final = `{"status":"approved","approver":"alice"}`
+	assert.NoError(t, b.waitForApproval(context.Background(), update, op))
+	assert.True(t, requested)
+	assert.Equal(t, 3, polls)
+
+	// Rejected updates fail with the approver's comment.
+	polls, final = 0, `{"status":"rejected","approver":"bob","comment":"not during the launch"}`
+	assert.EqualError(t, b.waitForApproval(context.Background(), update, op),
+		"the update was rejected by bob: not during the launch")
+
+	// Updates that are not approved in time fail.
+	polls, final = -1000, `{"status":"approved","approver":"alice"}`
+	op.Opts.ApprovalTimeout = 20 * time.Millisecond
+	assert.EqualError(t, b.waitForApproval(context.Background(), update, op),
+		"the update was not approved within 20ms")
+
+	// Only updates with plans can be approved.
+	op.Opts.Engine.Plan = nil
+	assert.Error(t, b.waitForApproval(context.Background(), update, op))
+}
+
+func TestNewStackSchema(t *testing.T) {
+	schema, err := newStackSchema(&workspace.Project{Name: "network"})
+	assert.NoError(t, err)
+	assert.Nil(t, schema)
+
+	schema, err = newStackSchema(&workspace.Project{
+		Name: "network",
+		ConfigSchema: map[string]workspace.ProjectConfigType{
+			"cidr":       {Description: "the VPC's CIDR block"},
+			"aws:region": {Type: workspace.ConfigTypeString},
+		},
+		OutputSchema: map[string]workspace.ProjectOutputType{
+			"vpcId":   {Type: workspace.ConfigTypeString},
+			"subnets": {Type: workspace.ConfigTypeArray, Secret: true},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &apitype.StackSchema{
+		Config: map[string]apitype.StackSchemaType{
+			"network:cidr": {Type: "string", Description: "the VPC's CIDR block"},
+			"aws:region":   {Type: "string"},
+		},
+		Outputs: map[string]apitype.StackSchemaType{
+			"vpcId":   {Type: "string"},
+			"subnets": {Type: "array", Secret: true},
+		},
+	}, schema)
+}
+
+func TestStartUpdateRetriesChangedTags(t *testing.T) {
+	var reads int
+	var ifMatches []string
+	var started apitype.StartUpdateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/stacks/acme/web/prod":
+			reads++
+			stack := apitype.Stack{
+				Tags:     map[apitype.StackTagName]string{"owner": "alice"},
+				TagsETag: fmt.Sprintf("v%d", reads),
+			}
+			assert.NoError(t, json.NewEncoder(w).Encode(stack))
+		case "/api/stacks/acme/web/prod/update/abc":
+			ifMatches = append(ifMatches, r.Header.Get("If-Match"))
+			if r.Header.Get("If-Match") == "v1" {
+				// The tags were changed by someone else after they were first read.
+				w.WriteHeader(http.StatusPreconditionFailed)
+				_, err := w.Write([]byte(`{"code":412,"message":"Precondition Failed"}`))
+				assert.NoError(t, err)
+				return
+			}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&started))
+			_, err := w.Write([]byte(`{"version":7,"token":"tok"}`))
+			assert.NoError(t, err)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	b := &cloudBackend{client: client.NewClient(server.URL, "", nil)}
+	stackID := client.StackIdentifier{Owner: "acme", Project: "web", Stack: "prod"}
+	update := client.UpdateIdentifier{StackIdentifier: stackID, UpdateKind: apitype.UpdateUpdate, UpdateID: "abc"}
+
+	version, token, err := b.startUpdate(context.Background(), update, stackID)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, version)
+	assert.Equal(t, "tok", token)
+	assert.Equal(t, []string{"v1", "v2"}, ifMatches)
+	assert.True(t, started.MergeTags)
+	assert.Equal(t, "alice", started.Tags["owner"])
+}
+
+func TestCheckWritable(t *testing.T) {
+	var permission string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/stacks/acme/web/prod":
+			_, err := w.Write([]byte(`{"orgName":"acme","projectName":"web","stackName":"prod"` + permission + `}`))
+			assert.NoError(t, err)
+		case "/api/user":
+			_, err := w.Write([]byte(`{"githubLogin":"alice"}`))
+			assert.NoError(t, err)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	b := &cloudBackend{client: client.NewClient(server.URL, "", nil)}
+	ref := cloudBackendReference{name: "prod", project: "web", owner: "acme", b: b}
+
+	// A service that does not report the permission allows the update.
+	assert.NoError(t, b.checkWritable(context.Background(), ref))
+
+	permission = fmt.Sprintf(`,"permission":%d`, apitype.StackPermissionWrite)
+	assert.NoError(t, b.checkWritable(context.Background(), ref))
+
+	permission = fmt.Sprintf(`,"permission":%d`, apitype.StackPermissionRead)
+	assert.Error(t, b.checkWritable(context.Background(), ref))
+
+	// An explicit lack of access is not mistaken for an unreported permission.
+	permission = fmt.Sprintf(`,"permission":%d`, apitype.StackPermissionNone)
+	assert.Error(t, b.checkWritable(context.Background(), ref))
+}
+
+func TestEncryptedCheckpoint(t *testing.T) {
+	var checkpoint apitype.PatchUpdateCheckpointRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/user":
+			_, err := w.Write([]byte(`{"githubLogin":"acme"}`))
+			assert.NoError(t, err)
+		case "/api/stacks/acme/web/prod":
+			assert.NoError(t, json.NewEncoder(w).Encode(apitype.Stack{OrgName: "acme", StackName: "prod"}))
+		case "/api/stacks/acme/web/prod/update/abc/renew_lease":
+			_, err := w.Write([]byte(`{"token":"tok"}`))
+			assert.NoError(t, err)
+		case "/api/stacks/acme/web/prod/update/abc/checkpoint":
+			body := io.Reader(r.Body)
+			if r.Header.Get("Content-Encoding") == "gzip" {
+				reader, err := gzip.NewReader(r.Body)
+				if !assert.NoError(t, err) {
+					return
+				}
+				body = reader
+			}
+			// The client flushes its gzip stream rather than closing it, so the stream has no trailer.
+			contents, err := ioutil.ReadAll(body)
+			if err != io.ErrUnexpectedEOF {
+				assert.NoError(t, err)
+			}
+			assert.NotContains(t, string(contents), "hunter2")
+			assert.NoError(t, json.Unmarshal(contents, &checkpoint))
+		case "/api/stacks/acme/web/prod/export":
+			deployment := apitype.ExportStackResponse{Version: 3, Encrypted: checkpoint.Encrypted}
+			assert.NoError(t, json.NewEncoder(w).Encode(deployment))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	url := "base64key://" + base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))
+	dataKey, err := cloud.GenerateNewDataKey(url)
+	if !assert.NoError(t, err) {
+		return
+	}
+	sm, err := cloud.NewCloudSecretsManager(url, dataKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	b := &cloudBackend{client: client.NewClient(server.URL, "", nil)}
+	b.SetStateSecretsManagerSource(func(s backend.Stack) (secrets.Manager, error) { return sm, nil })
+	ref := cloudBackendReference{name: "prod", project: "web", owner: "acme", b: b}
+	b.encryption.SetEncrypted(ref, true)
+
+	update := client.UpdateIdentifier{
+		StackIdentifier: client.StackIdentifier{Owner: "acme", Project: "web", Stack: "prod"},
+		UpdateKind:      apitype.UpdateUpdate,
+		UpdateID:        "abc",
+	}
+	tokens, err := newTokenSource(context.Background(), "tok", b, update, time.Minute)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer tokens.Close()
+
+	// The checkpoints of a stack whose state is encrypted never reach the service in plaintext.
+	urn := resource.NewURN("prod", "web", "", "test:index:Resource", "a")
+	res := &resource.State{
+		URN:     urn,
+		Type:    urn.Type(),
+		Custom:  true,
+		ID:      "a",
+		Outputs: resource.PropertyMap{"password": resource.NewStringProperty("hunter2")},
+	}
+	persister := b.newSnapshotPersister(context.Background(), ref, update, tokens, sm)
+	assert.NoError(t, persister.Save(deploy.NewSnapshot(deploy.Manifest{}, sm, []*resource.State{res}, nil)))
+	if !assert.NotNil(t, checkpoint.Encrypted) {
+		return
+	}
+	assert.Empty(t, checkpoint.Deployment)
+
+	// Exporting the stack's deployment decrypts it with the key of the stack's secrets manager.
+	deployment, err := b.ExportDeployment(context.Background(), ref)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Nil(t, deployment.Encrypted)
+	assert.Contains(t, string(deployment.Deployment), "hunter2")
+}
+
+func TestPersistEngineEventsResendsAfterStreamFailure(t *testing.T) {
+	var lock sync.Mutex
+	var recorded []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/stacks/acme/web/prod/update/abc/renew_lease":
+			_, err := w.Write([]byte(`{"token":"tok"}`))
+			assert.NoError(t, err)
+		case "/api/stacks/acme/web/prod/update/abc/events/stream":
+			// Read the first batch, then drop the connection midway through the update.
+			body, err := gzip.NewReader(r.Body)
+			if !assert.NoError(t, err) {
+				return
+			}
+			var batch apitype.EngineEventBatch
+			assert.NoError(t, json.NewDecoder(body).Decode(&batch))
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if assert.NoError(t, err) {
+				assert.NoError(t, conn.Close())
+			}
+		case "/api/stacks/acme/web/prod/update/abc/events/batch":
+			body, err := gzip.NewReader(r.Body)
+			if !assert.NoError(t, err) {
+				return
+			}
+			var batch apitype.EngineEventBatch
+			if !assert.NoError(t, json.NewDecoder(body).Decode(&batch)) {
+				return
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			for _, e := range batch.Events {
+				recorded = append(recorded, e.Sequence)
+			}
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	b := &cloudBackend{client: client.NewClient(server.URL, "", nil)}
+	update := client.UpdateIdentifier{
+		StackIdentifier: client.StackIdentifier{Owner: "acme", Project: "web", Stack: "prod"},
+		UpdateKind:      apitype.UpdateUpdate,
+		UpdateID:        "abc",
+	}
+	tokens, err := newTokenSource(context.Background(), "tok", b, update, time.Minute)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer tokens.Close()
+	u := &cloudUpdate{context: context.Background(), backend: b, update: update, tokenSource: tokens}
+
+	events, done := make(chan engine.Event), make(chan bool)
+	go persistEngineEvents(u, false, events, done)
+
+	// Every event reaches the service once the stream fails, including those the stream had sent but the service had
+	// not acknowledged.
+	const count = 120
+	for i := 0; i < count; i++ {
+		events <- engine.Event{Type: engine.StdoutColorEvent, Payload: engine.StdoutEventPayload{
+			Message: fmt.Sprintf("event %d", i),
+		}}
+	}
+	events <- engine.Event{Type: engine.CancelEvent}
+	<-done
+
+	expected := make([]int, count)
+	for i := range expected {
+		expected[i] = i
+	}
+	assert.Equal(t, expected, recorded)
+}
+
+func TestGetTargetAgainstVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/stacks/acme/web/prod/export/3":
+			deployment := apitype.ExportStackResponse{Version: apitype.DeploymentSchemaVersionCurrent + 1}
+			assert.NoError(t, json.NewEncoder(w).Encode(deployment))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	b := &cloudBackend{client: client.NewClient(server.URL, "", nil)}
+	ref := cloudBackendReference{name: "prod", project: "web", owner: "acme", b: b}
+
+	// Errors loading a prior version's state are reported as they are for the current state.
+	version := 3
+	_, err := b.getTarget(context.Background(), ref, backend.StackConfiguration{}, &version)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "is newer than what this version of the Pulumi CLI understands")
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"reflect"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/pulumi/pulumi/pkg/diag"
 
@@ -35,7 +36,6 @@ import (
 
 	"github.com/pulumi/pulumi/pkg/apitype"
 	"github.com/pulumi/pulumi/pkg/util/contract"
-	"github.com/pulumi/pulumi/pkg/util/httputil"
 	"github.com/pulumi/pulumi/pkg/util/logging"
 	"github.com/pulumi/pulumi/pkg/util/tracing"
 	"github.com/pulumi/pulumi/pkg/version"
@@ -84,6 +84,24 @@ type httpCallOptions struct {
 
 	// GzipCompress compresses the request using gzip before sending it.
 	GzipCompress bool
+
+	// RetryPolicy controls how the call is retried. If nil, the default policy is used.
+	RetryPolicy *RetryPolicy
+
+	// Hooks are invoked around the call.
+	Hooks Hooks
+
+	// Stats, if non-nil, records the call's outcome.
+	Stats *callStats
+
+	// Faults, if non-nil, injects faults into the call.
+	Faults *FaultInjector
+
+	// Transport, if non-nil, sends the call. If nil, Go's default transport is used.
+	Transport http.RoundTripper
+
+	// Header holds additional headers to send with the call.
+	Header http.Header
 }
 
 // apiAccessToken is an implementation of accessToken for Pulumi API tokens (i.e. tokens of kind
@@ -110,6 +128,66 @@ func (t updateAccessToken) String() string {
 	return string(t)
 }
 
+// setAPIHeaders sets the headers common to all requests to the Pulumi API, including credentials if the given token
+// is non-empty and, if requested, headers that propagate the given tracing span.
+func setAPIHeaders(req *http.Request, requestSpan opentracing.Span, tok accessToken) {
+	req.Header.Set("Content-Type", "application/json")
+
+	// Add a User-Agent header to allow for the backend to make breaking API changes while preserving
+	// backwards compatibility.
+	userAgent := fmt.Sprintf("pulumi-cli/1 (%s; %s)", version.Version, runtime.GOOS)
+	req.Header.Set("User-Agent", userAgent)
+	// Specify the specific API version we accept.
+	req.Header.Set("Accept", "application/vnd.pulumi+3")
+
+	// Apply credentials if provided.
+	if tok.String() != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("%s %s", tok.Kind(), tok.String()))
+	}
+
+	tracingOptions := tracing.OptionsFromContext(req.Context())
+	if tracingOptions.PropagateSpans {
+		carrier := opentracing.HTTPHeadersCarrier(req.Header)
+		if err := requestSpan.Tracer().Inject(requestSpan.Context(), opentracing.HTTPHeaders, carrier); err != nil {
+			logging.Errorf("injecting tracing headers: %v", err)
+		}
+	}
+	if tracingOptions.TracingHeader != "" {
+		req.Header.Set("X-Pulumi-Tracing", tracingOptions.TracingHeader)
+	}
+
+	// Opt-in to accepting gzip-encoded responses from the service.
+	req.Header.Set("Accept-Encoding", "gzip")
+}
+
+// apiResponseError returns the error described by a 4xx or 5xx response from the Pulumi API, reading and closing the
+// response's body. It returns nil for any other response.
+func apiResponseError(resp *http.Response, tok accessToken) error {
+	// For 4xx and 5xx failures, attempt to provide better diagnostics about what may have gone wrong.
+	if resp.StatusCode < 400 || resp.StatusCode > 599 {
+		return nil
+	}
+
+	// 4xx and 5xx responses should be of type ErrorResponse. See if we can unmarshal as that
+	// type, and if not just return the raw response text.
+	respBody, err := readBody(resp)
+	if err != nil {
+		return errors.Wrapf(err, "API call failed (%s), could not read response", resp.Status)
+	}
+
+	// Provide a better error if using an authenticated call without having logged in first.
+	if resp.StatusCode == 401 && tok.Kind() == accessTokenKindAPIToken && tok.String() == "" {
+		return errors.New("this command requires logging in; try running 'pulumi login' first")
+	}
+
+	var errResp apitype.ErrorResponse
+	if err = json.Unmarshal(respBody, &errResp); err != nil {
+		errResp.Code = resp.StatusCode
+		errResp.Message = strings.TrimSpace(string(respBody))
+	}
+	return &errResp
+}
+
 // pulumiAPICall makes an HTTP request to the Pulumi API.
 func pulumiAPICall(ctx context.Context, d diag.Sink, cloudAPI, method, path string, body []byte, tok accessToken,
 	opts httpCallOptions) (string, *http.Response, error) {
@@ -158,33 +236,10 @@ func pulumiAPICall(ctx context.Context, d diag.Sink, cloudAPI, method, path stri
 	defer requestSpan.Finish()
 
 	req = req.WithContext(requestContext)
-	req.Header.Set("Content-Type", "application/json")
-
-	// Add a User-Agent header to allow for the backend to make breaking API changes while preserving
-	// backwards compatibility.
-	userAgent := fmt.Sprintf("pulumi-cli/1 (%s; %s)", version.Version, runtime.GOOS)
-	req.Header.Set("User-Agent", userAgent)
-	// Specify the specific API version we accept.
-	req.Header.Set("Accept", "application/vnd.pulumi+3")
-
-	// Apply credentials if provided.
-	if tok.String() != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("%s %s", tok.Kind(), tok.String()))
+	setAPIHeaders(req, requestSpan, tok)
+	for k, v := range opts.Header {
+		req.Header[k] = v
 	}
-
-	tracingOptions := tracing.OptionsFromContext(requestContext)
-	if tracingOptions.PropagateSpans {
-		carrier := opentracing.HTTPHeadersCarrier(req.Header)
-		if err = requestSpan.Tracer().Inject(requestSpan.Context(), opentracing.HTTPHeaders, carrier); err != nil {
-			logging.Errorf("injecting tracing headers: %v", err)
-		}
-	}
-	if tracingOptions.TracingHeader != "" {
-		req.Header.Set("X-Pulumi-Tracing", tracingOptions.TracingHeader)
-	}
-
-	// Opt-in to accepting gzip-encoded responses from the service.
-	req.Header.Set("Accept-Encoding", "gzip")
 	if opts.GzipCompress {
 		// If we're sending something that's gzipped, set that header too.
 		req.Header.Set("Content-Encoding", "gzip")
@@ -196,13 +251,14 @@ func pulumiAPICall(ctx context.Context, d diag.Sink, cloudAPI, method, path stri
 			"Pulumi API call details (%s): headers=%v; body=%v", url, req.Header, string(body))
 	}
 
-	var resp *http.Response
-	if req.Method == "GET" || opts.RetryAllMethods {
-		resp, err = httputil.DoWithRetry(req, http.DefaultClient)
-	} else {
-		resp, err = http.DefaultClient.Do(req)
+	policy := DefaultRetryPolicy()
+	if opts.RetryPolicy != nil {
+		policy = *opts.RetryPolicy
 	}
-
+	opts.beforeCall(req)
+	start := time.Now()
+	resp, err := doWithRetryPolicy(req, opts.httpClient(), policy, req.Method == "GET" || opts.RetryAllMethods)
+	opts.afterCall(req, resp, time.Since(start), err)
 	if err != nil {
 		return "", nil, errors.Wrapf(err, "performing HTTP request")
 	}
@@ -216,27 +272,8 @@ func pulumiAPICall(ctx context.Context, d diag.Sink, cloudAPI, method, path stri
 		}
 	}
 
-	// For 4xx and 5xx failures, attempt to provide better diagnostics about what may have gone wrong.
-	if resp.StatusCode >= 400 && resp.StatusCode <= 599 {
-		// 4xx and 5xx responses should be of type ErrorResponse. See if we can unmarshal as that
-		// type, and if not just return the raw response text.
-		respBody, err := readBody(resp)
-		if err != nil {
-			return "", nil, errors.Wrapf(
-				err, "API call failed (%s), could not read response", resp.Status)
-		}
-
-		// Provide a better error if using an authenticated call without having logged in first.
-		if resp.StatusCode == 401 && tok.Kind() == accessTokenKindAPIToken && tok.String() == "" {
-			return "", nil, errors.New("this command requires logging in; try running 'pulumi login' first")
-		}
-
-		var errResp apitype.ErrorResponse
-		if err = json.Unmarshal(respBody, &errResp); err != nil {
-			errResp.Code = resp.StatusCode
-			errResp.Message = strings.TrimSpace(string(respBody))
-		}
-		return "", nil, &errResp
+	if err = apiResponseError(resp, tok); err != nil {
+		return "", nil, err
 	}
 
 	return url, resp, nil
@@ -80,6 +80,7 @@ func init() {
 
 	addEndpoint("GET", "/api/user", "getCurrentUser")
 	addEndpoint("GET", "/api/user/stacks", "listUserStacks")
+	addEndpoint("GET", "/api/user/organizations", "listUserOrganizations")
 	addEndpoint("GET", "/api/stacks/{orgName}", "listOrganizationStacks")
 	addEndpoint("POST", "/api/stacks/{orgName}", "createStack")
 	addEndpoint("DELETE", "/api/stacks/{orgName}/{projectName}/{stackName}", "deleteStack")
@@ -89,6 +90,15 @@ func init() {
 	addEndpoint("POST", "/api/stacks/{orgName}/{projectName}/{stackName}/encrypt", "encryptValue")
 	addEndpoint("POST", "/api/stacks/{orgName}/{projectName}/{stackName}/decrypt", "decryptValue")
 	addEndpoint("GET", "/api/stacks/{orgName}/{projectName}/{stackName}/logs", "getStackLogs")
+	addEndpoint("GET", "/api/stacks/{orgName}/{projectName}/{stackName}/collaborators", "listStackPermissions")
+	addEndpoint("PUT", "/api/stacks/{orgName}/{projectName}/{stackName}/collaborators/{kind}/{name}", "grantStackPermission")
+	addEndpoint("DELETE", "/api/stacks/{orgName}/{projectName}/{stackName}/collaborators/{kind}/{name}", "revokeStackPermission")
+	addEndpoint("GET", "/api/stacks/{orgName}/{projectName}/{stackName}/activity", "listStackActivity")
+	addEndpoint("GET", "/api/stacks/{orgName}/{projectName}/{stackName}/freeze-windows", "listFreezeWindows")
+	addEndpoint("GET", "/api/stacks/{orgName}/{projectName}/{stackName}/schema", "getStackSchema")
+	addEndpoint("PUT", "/api/stacks/{orgName}/{projectName}/{stackName}/schema", "publishStackSchema")
+	addEndpoint("POST", "/api/stacks/{orgName}/{projectName}/{stackName}/hooks", "createStackWebhook")
+	addEndpoint("DELETE", "/api/stacks/{orgName}/{projectName}/{stackName}/hooks/{hookName}", "deleteStackWebhook")
 	addEndpoint("GET", "/api/stacks/{orgName}/{projectName}/{stackName}/updates", "getStackUpdates")
 	addEndpoint("GET", "/api/stacks/{orgName}/{projectName}/{stackName}/updates/latest", "getLatestStackUpdate")
 	addEndpoint("GET", "/api/stacks/{orgName}/{projectName}/{stackName}/updates/{version}", "getStackUpdate")
@@ -105,8 +115,29 @@ func init() {
 	addEndpoint("PATCH", "/api/stacks/{orgName}/{projectName}/{stackName}/{updateKind}/{updateID}/checkpoint", "patchCheckpoint")
 	addEndpoint("POST", "/api/stacks/{orgName}/{projectName}/{stackName}/{updateKind}/{updateID}/complete", "completeUpdate")
 	addEndpoint("POST", "/api/stacks/{orgName}/{projectName}/{stackName}/{updateKind}/{updateID}/events", "postEngineEvent")
+	addEndpoint("POST", "/api/stacks/{orgName}/{projectName}/{stackName}/{updateKind}/{updateID}/events/stream", "streamEngineEvents")
+	addEndpoint("GET", "/api/stacks/{orgName}/{projectName}/{stackName}/{updateKind}/{updateID}/events/{index}", "getUpdateEvent")
 	addEndpoint("POST", "/api/stacks/{orgName}/{projectName}/{stackName}/{updateKind}/{updateID}/renew_lease", "renewLease")
+	addEndpoint("POST", "/api/stacks/{orgName}/{projectName}/{stackName}/{updateKind}/{updateID}/approval", "requestApproval")
+	addEndpoint("GET", "/api/stacks/{orgName}/{projectName}/{stackName}/{updateKind}/{updateID}/approval", "getApprovalStatus")
 
 	// APIs for managing `PolicyPack`s.
+	addEndpoint("GET", "/api/orgs/{orgName}/policypacks", "listPolicyPacks")
 	addEndpoint("POST", "/api/orgs/{orgName}/policypacks", "publishPolicyPack")
+	addEndpoint("GET", "/api/orgs/{orgName}/policypacks/{policyPackName}", "listPolicyPackVersions")
+	addEndpoint("DELETE", "/api/orgs/{orgName}/policypacks/{policyPackName}", "removePolicyPack")
+	addEndpoint("POST", "/api/orgs/{orgName}/policypacks/{policyPackName}/disable", "disablePolicyPack")
+	addEndpoint("DELETE", "/api/orgs/{orgName}/policypacks/{policyPackName}/versions/{version}", "removePolicyPackVersion")
+	addEndpoint("GET", "/api/orgs/{orgName}/policypacks/{policyPackName}/versions/{version}", "getPolicyPack")
+	addEndpoint("POST", "/api/orgs/{orgName}/hooks", "createOrganizationWebhook")
+	addEndpoint("DELETE", "/api/orgs/{orgName}/hooks/{hookName}", "deleteOrganizationWebhook")
+
+	// APIs for managing organizations and their members.
+	addEndpoint("GET", "/api/orgs/{orgName}", "getOrganization")
+	addEndpoint("GET", "/api/orgs/{orgName}/members", "listOrganizationMembers")
+	addEndpoint("PATCH", "/api/orgs/{orgName}/members/{userName}", "updateOrganizationMember")
+	addEndpoint("DELETE", "/api/orgs/{orgName}/members/{userName}", "removeOrganizationMember")
+	addEndpoint("GET", "/api/orgs/{orgName}/update-message-policy", "getUpdateMessagePolicy")
+	addEndpoint("GET", "/api/orgs/{orgName}/secrets/escrow", "getSecretsEscrow")
+	addEndpoint("POST", "/api/orgs/{orgName}/invites", "inviteOrganizationMember")
 }
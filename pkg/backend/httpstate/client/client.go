@@ -22,6 +22,9 @@ import (
 	"io/ioutil"
 	"net/http"
 	"path"
+	"sort"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/pulumi/pulumi/pkg/resource/plugin"
@@ -36,27 +39,132 @@ import (
 	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/util/retry"
 	"github.com/pulumi/pulumi/pkg/util/validation"
 	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
-// Client provides a slim wrapper around the Pulumi HTTP/REST API.
+// Client provides a slim wrapper around the Pulumi HTTP/REST API. A Client is safe for concurrent use.
 type Client struct {
 	apiURL   string
 	apiToken apiAccessToken
-	apiUser  string
 	diag     diag.Sink
+	stats    *callStats
+
+	lock        sync.RWMutex // protects the fields below.
+	retryPolicy RetryPolicy
+	hooks       Hooks
+	faults      *FaultInjector
+	transport   http.RoundTripper
+
+	userLock sync.Mutex // protects apiUser, and is held while it is fetched.
+	apiUser  string
 }
 
-// NewClient creates a new Pulumi API client with the given URL and API token.
+// NewClient creates a new Pulumi API client with the given URL and API token. The client's retry policy is read from
+// the PULUMI_API_RETRY_* environment variables; if they are invalid, a warning is issued and the default is used.
+// Likewise, any faults in the PULUMI_API_FAULT_INJECTION environment variable are injected into the client's calls,
+// and the client connects to the API as the PULUMI_CA_BUNDLE, PULUMI_CLIENT_CERT, and PULUMI_CLIENT_KEY environment
+// variables describe.
 func NewClient(apiURL, apiToken string, d diag.Sink) *Client {
+	policy, err := RetryPolicyFromEnv()
+	if err != nil {
+		if d != nil {
+			d.Warningf(diag.Message("", "ignoring invalid API retry settings: %v"), err)
+		}
+		policy = DefaultRetryPolicy()
+	}
+	faults, err := FaultInjectorFromEnv()
+	if err != nil && d != nil {
+		d.Warningf(diag.Message("", "ignoring invalid API fault injection settings: %v"), err)
+	}
+	var transport http.RoundTripper
+	if opts := TransportOptionsFromEnv(); !opts.IsDefault() {
+		if t, err := NewTransport(opts); err != nil {
+			if d != nil {
+				d.Warningf(diag.Message("", "ignoring invalid API TLS settings: %v"), err)
+			}
+		} else {
+			transport = t
+		}
+	}
+
 	return &Client{
-		apiURL:   apiURL,
-		apiToken: apiAccessToken(apiToken),
-		diag:     d,
+		apiURL:      apiURL,
+		apiToken:    apiAccessToken(apiToken),
+		diag:        d,
+		stats:       newCallStats(),
+		retryPolicy: policy,
+		faults:      faults,
+		transport:   transport,
 	}
 }
 
+// RetryPolicy returns the policy this client uses to retry failed API calls.
+func (pc *Client) RetryPolicy() RetryPolicy {
+	pc.lock.RLock()
+	defer pc.lock.RUnlock()
+	return pc.retryPolicy
+}
+
+// SetRetryPolicy changes the policy this client uses to retry failed API calls.
+func (pc *Client) SetRetryPolicy(policy RetryPolicy) {
+	pc.lock.Lock()
+	defer pc.lock.Unlock()
+	pc.retryPolicy = policy
+}
+
+// SetHooks changes the hooks this client invokes around each API call. Calls already in flight are unaffected.
+func (pc *Client) SetHooks(hooks Hooks) {
+	pc.lock.Lock()
+	defer pc.lock.Unlock()
+	pc.hooks = hooks
+}
+
+// SetFaultInjector changes the fault injector whose faults are injected into this client's API calls. If it is nil,
+// no faults are injected.
+func (pc *Client) SetFaultInjector(faults *FaultInjector) {
+	pc.lock.Lock()
+	defer pc.lock.Unlock()
+	pc.faults = faults
+}
+
+// SetTransport changes the transport with which this client sends its calls. If nil, Go's default transport is used.
+func (pc *Client) SetTransport(transport http.RoundTripper) {
+	pc.lock.Lock()
+	defer pc.lock.Unlock()
+	pc.transport = transport
+}
+
+// Stats returns statistics about the API calls this client has made so far, by endpoint, sorted by endpoint name.
+func (pc *Client) Stats() []EndpointStats {
+	return pc.stats.snapshot()
+}
+
+// callOptions returns the given call options, updated with this client's retry policy, hooks, statistics, and fault
+// injector.
+func (pc *Client) callOptions(opts httpCallOptions) httpCallOptions {
+	pc.lock.RLock()
+	defer pc.lock.RUnlock()
+
+	policy := pc.retryPolicy
+	opts.RetryPolicy = &policy
+	opts.Hooks = pc.hooks
+	opts.Stats = pc.stats
+	opts.Faults = pc.faults
+	opts.Transport = pc.transport
+	return opts
+}
+
+// httpClient returns the HTTP client with which to make requests that are not API calls, such as those to the
+// storage the service hands out URLs for.
+func (pc *Client) httpClient() *http.Client {
+	pc.lock.RLock()
+	defer pc.lock.RUnlock()
+	return httpCallOptions{Transport: pc.transport}.httpClient()
+}
+
 // URL returns the URL of the API endpoint this client interacts with
 func (pc *Client) URL() string {
 	return pc.apiURL
@@ -65,13 +173,14 @@ func (pc *Client) URL() string {
 // restCall makes a REST-style request to the Pulumi API using the given method, path, query object, and request
 // object. If a response object is provided, the server's response is deserialized into that object.
 func (pc *Client) restCall(ctx context.Context, method, path string, queryObj, reqObj, respObj interface{}) error {
-	return pulumiRESTCall(ctx, pc.diag, pc.apiURL, method, path, queryObj, reqObj, respObj, pc.apiToken, httpCallOptions{})
+	return pc.restCallWithOptions(ctx, method, path, queryObj, reqObj, respObj, httpCallOptions{})
 }
 
 // restCall makes a REST-style request to the Pulumi API using the given method, path, query object, and request
 // object. If a response object is provided, the server's response is deserialized into that object.
 func (pc *Client) restCallWithOptions(ctx context.Context, method, path string, queryObj, reqObj,
 	respObj interface{}, opts httpCallOptions) error {
+	opts = pc.callOptions(opts)
 	return pulumiRESTCall(ctx, pc.diag, pc.apiURL, method, path, queryObj, reqObj, respObj, pc.apiToken, opts)
 }
 
@@ -81,6 +190,7 @@ func (pc *Client) restCallWithOptions(ctx context.Context, method, path string,
 func (pc *Client) updateRESTCall(ctx context.Context, method, path string, queryObj, reqObj, respObj interface{},
 	token updateAccessToken, httpOptions httpCallOptions) error {
 
+	httpOptions = pc.callOptions(httpOptions)
 	return pulumiRESTCall(ctx, pc.diag, pc.apiURL, method, path, queryObj, reqObj, respObj, token, httpOptions)
 }
 
@@ -91,6 +201,13 @@ func getStackPath(stack StackIdentifier, components ...string) string {
 	return path.Join(append([]string{prefix}, components...)...)
 }
 
+// getOrgPath returns the API path for the given organization with the given components joined with path separators
+// and appended to the organization root.
+func getOrgPath(orgName string, components ...string) string {
+	prefix := fmt.Sprintf("/api/orgs/%s", orgName)
+	return path.Join(append([]string{prefix}, components...)...)
+}
+
 // publishPolicyPackPath returns the API path to for the given organization with the given
 // components joined with path separators and appended to the organization root.
 func publishPolicyPackPath(orgName string) string {
@@ -104,6 +221,12 @@ func applyPolicyPackPath(orgName, policyPackName string, version int) string {
 		"/api/orgs/%s/policypacks/%s/versions/%d/apply", orgName, policyPackName, version)
 }
 
+// getPolicyPackPath returns the API path to get the given version of a PolicyPack.
+func getPolicyPackPath(orgName, policyPackName string, version int) string {
+	return fmt.Sprintf(
+		"/api/orgs/%s/policypacks/%s/versions/%d", orgName, policyPackName, version)
+}
+
 // publishPolicyPackPublishComplete returns the path for an API call to signal to the Pulumi service
 // that a PolicyPack to a Pulumi organization.
 func publishPolicyPackPublishComplete(orgName, policyPackName string, version int) string {
@@ -120,6 +243,9 @@ func getUpdatePath(update UpdateIdentifier, components ...string) string {
 
 // GetPulumiAccountName returns the user implied by the API token associated with this client.
 func (pc *Client) GetPulumiAccountName(ctx context.Context) (string, error) {
+	pc.userLock.Lock()
+	defer pc.userLock.Unlock()
+
 	if pc.apiUser == "" {
 		resp := struct {
 			GitHubLogin string `json:"githubLogin"`
@@ -138,6 +264,11 @@ func (pc *Client) GetPulumiAccountName(ctx context.Context) (string, error) {
 	return pc.apiUser, nil
 }
 
+// SubmitCrashReport uploads a report of a crash of the CLI.
+func (pc *Client) SubmitCrashReport(ctx context.Context, report apitype.CrashReport) error {
+	return pc.restCall(ctx, "POST", "/api/cli/crash-reports", nil, report, nil)
+}
+
 // GetCLIVersionInfo asks the service for information about versions of the CLI (the newest version as well as the
 // oldest version before the CLI should warn about an upgrade).
 func (pc *Client) GetCLIVersionInfo(ctx context.Context) (semver.Version, semver.Version, error) {
@@ -166,29 +297,111 @@ type ListStacksFilter struct {
 	Organization *string
 	TagName      *string
 	TagValue     *string
+
+	// ContinuationToken resumes a listing after the page of stacks that returned it.
+	ContinuationToken *string
+	// PageSize is the maximum number of stacks to return per page. If nil, the service picks a page size.
+	PageSize *int
+	// Fields limits each returned summary to the given fields, named as in the JSON form of apitype.StackSummary. If
+	// empty, complete summaries are returned.
+	Fields []string
 }
 
-// ListStacks lists all stacks the current user has access to, optionally filtered by project.
+// StackNameFields are the fields of a stack summary that identify the stack. Listing only these fields keeps the
+// response small for organizations with many stacks when only the stacks' names are needed.
+var StackNameFields = []string{"orgName", "projectName", "stackName"}
+
+// ListStacks lists all stacks the current user has access to, optionally filtered by project. The stacks are fetched
+// a page at a time, starting from the filter's continuation token, if any.
 func (pc *Client) ListStacks(
 	ctx context.Context, filter ListStacksFilter) ([]apitype.StackSummary, error) {
+	var stacks []apitype.StackSummary
+	it := pc.IterateStacks(ctx, filter)
+	for it.Next() {
+		stacks = append(stacks, it.Stack())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return stacks, nil
+}
+
+// ListStacksPage returns a single page of the stacks the current user has access to. The response's continuation
+// token, if any, may be passed back in the filter to fetch the next page.
+func (pc *Client) ListStacksPage(
+	ctx context.Context, filter ListStacksFilter) (apitype.ListStacksResponse, error) {
 	queryFilter := struct {
-		Project      *string `url:"project,omitempty"`
-		Organization *string `url:"organization,omitempty"`
-		TagName      *string `url:"tagName,omitempty"`
-		TagValue     *string `url:"tagValue,omitempty"`
+		Project           *string  `url:"project,omitempty"`
+		Organization      *string  `url:"organization,omitempty"`
+		TagName           *string  `url:"tagName,omitempty"`
+		TagValue          *string  `url:"tagValue,omitempty"`
+		ContinuationToken *string  `url:"continuationToken,omitempty"`
+		PageSize          *int     `url:"pageSize,omitempty"`
+		Fields            []string `url:"fields,comma,omitempty"`
 	}{
-		Project:      filter.Project,
-		Organization: filter.Organization,
-		TagName:      filter.TagName,
-		TagValue:     filter.TagValue,
+		Project:           filter.Project,
+		Organization:      filter.Organization,
+		TagName:           filter.TagName,
+		TagValue:          filter.TagValue,
+		ContinuationToken: filter.ContinuationToken,
+		PageSize:          filter.PageSize,
+		Fields:            filter.Fields,
 	}
 
 	var resp apitype.ListStacksResponse
 	if err := pc.restCall(ctx, "GET", "/api/user/stacks", queryFilter, nil, &resp); err != nil {
-		return nil, err
+		return apitype.ListStacksResponse{}, err
 	}
+	return resp, nil
+}
 
-	return resp.Stacks, nil
+// StackIterator streams the stacks the current user has access to, fetching a page of stacks whenever it runs out. Call
+// Next to advance to each stack in turn, and Err once Next returns false to check whether the listing failed.
+type StackIterator struct {
+	ctx    context.Context
+	client *Client
+	filter ListStacksFilter
+	page   []apitype.StackSummary
+	stack  apitype.StackSummary
+	done   bool
+	err    error
+}
+
+// IterateStacks returns an iterator over the stacks the current user has access to that match the given filter.
+func (pc *Client) IterateStacks(ctx context.Context, filter ListStacksFilter) *StackIterator {
+	return &StackIterator{ctx: ctx, client: pc, filter: filter}
+}
+
+// Next advances the iterator to the next stack, fetching the next page of stacks if needed. It returns false once
+// there are no more stacks or an error occurs.
+func (it *StackIterator) Next() bool {
+	for len(it.page) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+
+		resp, err := it.client.ListStacksPage(it.ctx, it.filter)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.page = resp.Stacks
+		it.filter.ContinuationToken = resp.ContinuationToken
+		it.done = resp.ContinuationToken == nil
+	}
+
+	it.stack, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Stack returns the stack the iterator is positioned at.
+func (it *StackIterator) Stack() apitype.StackSummary {
+	return it.stack
+}
+
+// Err returns the error, if any, that stopped the iteration.
+func (it *StackIterator) Err() error {
+	return it.err
 }
 
 var (
@@ -218,9 +431,12 @@ func (pc *Client) GetLatestConfiguration(ctx context.Context, stackID StackIdent
 		if err != nil {
 			return nil, err
 		}
-		if v.Secret {
+		switch {
+		case v.Object:
+			cfg[newKey] = config.NewObjectValue(v.String)
+		case v.Secret:
 			cfg[newKey] = config.NewSecureValue(v.String)
-		} else {
+		default:
 			cfg[newKey] = config.NewValue(v.String)
 		}
 	}
@@ -313,6 +529,17 @@ func (pc *Client) DecryptValue(ctx context.Context, stack StackIdentifier, ciphe
 	return resp.Plaintext, nil
 }
 
+// GetSecretsEscrowEnabled returns true if the indicated organization allows secrets to be encrypted under a locally
+// configured escrow key when the service is unable to encrypt them.
+func (pc *Client) GetSecretsEscrowEnabled(ctx context.Context, orgName string) (bool, error) {
+	var resp apitype.GetSecretsEscrowResponse
+	path := fmt.Sprintf("/api/orgs/%s/secrets/escrow", orgName)
+	if err := pc.restCall(ctx, "GET", path, nil, nil, &resp); err != nil {
+		return false, err
+	}
+	return resp.Enabled, nil
+}
+
 // GetStackUpdates returns all updates to the indicated stack.
 func (pc *Client) GetStackUpdates(ctx context.Context, stack StackIdentifier) ([]apitype.UpdateInfo, error) {
 	var response apitype.GetHistoryResponse
@@ -323,18 +550,31 @@ func (pc *Client) GetStackUpdates(ctx context.Context, stack StackIdentifier) ([
 	return response.Updates, nil
 }
 
-// ExportStackDeployment exports the indicated stack's deployment as a raw JSON message.
+// ExportStackDeployment exports the indicated stack's deployment as a raw JSON message. If version is non-nil, the
+// deployment as of that update version is exported rather than the latest one.
 func (pc *Client) ExportStackDeployment(ctx context.Context,
-	stack StackIdentifier) (apitype.UntypedDeployment, error) {
+	stack StackIdentifier, version *int) (apitype.UntypedDeployment, error) {
+
+	stackPath := getStackPath(stack, "export")
+	if version != nil {
+		stackPath = getStackPath(stack, "export", strconv.Itoa(*version))
+	}
 
 	var resp apitype.ExportStackResponse
-	if err := pc.restCall(ctx, "GET", getStackPath(stack, "export"), nil, nil, &resp); err != nil {
+	if err := pc.restCall(ctx, "GET", stackPath, nil, nil, &resp); err != nil {
 		return apitype.UntypedDeployment{}, err
 	}
 
 	return apitype.UntypedDeployment(resp), nil
 }
 
+// RecordSecretsRevealed records in the indicated stack's audit log that some of its secrets were revealed.
+func (pc *Client) RecordSecretsRevealed(ctx context.Context, stack StackIdentifier,
+	req apitype.RecordSecretsRevealedRequest) error {
+
+	return pc.restCall(ctx, "POST", getStackPath(stack, "audit", "secrets-revealed"), nil, req, nil)
+}
+
 // ImportStackDeployment imports a new deployment into the indicated stack.
 func (pc *Client) ImportStackDeployment(ctx context.Context, stack StackIdentifier,
 	deployment *apitype.UntypedDeployment) (UpdateIdentifier, error) {
@@ -362,6 +602,14 @@ func (pc *Client) CreateUpdate(
 	// First create the update program request.
 	wireConfig := make(map[string]apitype.ConfigValue)
 	for k, cv := range cfg {
+		if cv.Object() {
+			// Objects are sent as JSON with their secrets still encrypted.
+			v, err := json.Marshal(cv)
+			contract.AssertNoError(err)
+			wireConfig[k.String()] = apitype.ConfigValue{String: string(v), Object: true}
+			continue
+		}
+
 		v, err := cv.Value(config.NopDecrypter)
 		contract.AssertNoError(err)
 
@@ -431,28 +679,426 @@ func (pc *Client) RenameStack(ctx context.Context, stack StackIdentifier, newNam
 	return pc.restCall(ctx, "POST", getStackPath(stack, "rename"), nil, &req, &resp)
 }
 
-// StartUpdate starts the indicated update. It returns the new version of the update's target stack and the token used
-// to authenticate operations on the update if any. Replaces the stack's tags with the updated set.
-func (pc *Client) StartUpdate(ctx context.Context, update UpdateIdentifier,
-	tags map[apitype.StackTagName]string) (int, string, error) {
+// ListStackPermissions returns the users and teams that have been granted access to the indicated stack.
+func (pc *Client) ListStackPermissions(ctx context.Context,
+	stack StackIdentifier) ([]apitype.StackCollaborator, error) {
+
+	var resp apitype.ListStackPermissionsResponse
+	if err := pc.restCall(ctx, "GET", getStackPath(stack, "collaborators"), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Collaborators, nil
+}
+
+// GrantStackPermission grants the indicated user or team the given level of access to the indicated stack, replacing
+// any access they had previously been granted.
+func (pc *Client) GrantStackPermission(ctx context.Context, stack StackIdentifier,
+	kind apitype.StackCollaboratorKind, name string, permission apitype.StackPermission) error {
+
+	req := apitype.GrantStackPermissionRequest{Permission: permission}
+	return pc.restCall(ctx, "PUT", getStackPath(stack, "collaborators", string(kind), name), nil, req, nil)
+}
+
+// RevokeStackPermission revokes all access the indicated user or team has been granted to the indicated stack.
+func (pc *Client) RevokeStackPermission(ctx context.Context, stack StackIdentifier,
+	kind apitype.StackCollaboratorKind, name string) error {
+
+	return pc.restCall(ctx, "DELETE", getStackPath(stack, "collaborators", string(kind), name), nil, nil, nil)
+}
+
+// GetStackPermissions returns the permissions that have been granted to each of the users and teams with access to the
+// indicated stack.
+func (pc *Client) GetStackPermissions(ctx context.Context, stack StackIdentifier) (apitype.StackPermissions, error) {
+	collaborators, err := pc.ListStackPermissions(ctx, stack)
+	if err != nil {
+		return apitype.StackPermissions{}, err
+	}
+
+	perms := apitype.StackPermissions{
+		Users: make(map[string]apitype.StackPermission),
+		Teams: make(map[string]apitype.StackPermission),
+	}
+	for _, c := range collaborators {
+		switch c.Kind {
+		case apitype.StackCollaboratorUser:
+			perms.Users[c.Name] = c.Permission
+		case apitype.StackCollaboratorTeam:
+			perms.Teams[c.Name] = c.Permission
+		}
+	}
+	return perms, nil
+}
+
+// DiffStackPermissions returns the changes needed to grant each of the given users and teams its permission on the
+// indicated stack. A permission of StackPermissionNone revokes access. If replace is true, the given permissions are to
+// be the only ones granted, so the access of any other user or team is revoked too. In the changes, revocations have
+// StackPermissionNone.
+func (pc *Client) DiffStackPermissions(ctx context.Context, stack StackIdentifier, perms apitype.StackPermissions,
+	replace bool) ([]apitype.StackCollaborator, error) {
+
+	current, err := pc.GetStackPermissions(ctx, stack)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []apitype.StackCollaborator
+	diff := func(kind apitype.StackCollaboratorKind, current, desired map[string]apitype.StackPermission) {
+		var names []string
+		for name := range desired {
+			names = append(names, name)
+		}
+		if replace {
+			for name := range current {
+				if _, has := desired[name]; !has {
+					names = append(names, name)
+				}
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if desired[name] != current[name] {
+				changes = append(changes, apitype.StackCollaborator{Name: name, Kind: kind, Permission: desired[name]})
+			}
+		}
+	}
+	diff(apitype.StackCollaboratorUser, current.Users, perms.Users)
+	diff(apitype.StackCollaboratorTeam, current.Teams, perms.Teams)
+	return changes, nil
+}
+
+// ApplyStackPermissionChanges grants or, for StackPermissionNone, revokes each of the given permissions on the
+// indicated stack. It returns the changes that were made before any error.
+func (pc *Client) ApplyStackPermissionChanges(ctx context.Context, stack StackIdentifier,
+	changes []apitype.StackCollaborator) ([]apitype.StackCollaborator, error) {
+
+	for i, c := range changes {
+		var err error
+		if c.Permission == apitype.StackPermissionNone {
+			err = pc.RevokeStackPermission(ctx, stack, c.Kind, c.Name)
+		} else {
+			err = pc.GrantStackPermission(ctx, stack, c.Kind, c.Name, c.Permission)
+		}
+		if err != nil {
+			return changes[:i], errors.Wrapf(err, "changing the permission of %s %s", c.Kind, c.Name)
+		}
+	}
+	return changes, nil
+}
 
+// SetStackPermissions grants each of the given users and teams its permission on the indicated stack, changing only
+// the permissions that differ. If replace is true, the access of any other user or team is revoked. It returns the
+// changes made, in which revocations have StackPermissionNone.
+func (pc *Client) SetStackPermissions(ctx context.Context, stack StackIdentifier, perms apitype.StackPermissions,
+	replace bool) ([]apitype.StackCollaborator, error) {
+
+	changes, err := pc.DiffStackPermissions(ctx, stack, perms, replace)
+	if err != nil {
+		return nil, err
+	}
+	return pc.ApplyStackPermissionChanges(ctx, stack, changes)
+}
+
+// ListOrganizations returns the organizations the current user belongs to.
+func (pc *Client) ListOrganizations(ctx context.Context) ([]apitype.Organization, error) {
+	var resp apitype.ListOrganizationsResponse
+	if err := pc.restCall(ctx, "GET", "/api/user/organizations", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Organizations, nil
+}
+
+// GetOrganization returns the indicated organization.
+func (pc *Client) GetOrganization(ctx context.Context, orgName string) (apitype.Organization, error) {
+	var org apitype.Organization
+	if err := pc.restCall(ctx, "GET", getOrgPath(orgName), nil, nil, &org); err != nil {
+		return apitype.Organization{}, err
+	}
+	return org, nil
+}
+
+// GetUpdateMessagePolicy returns the policy that the messages of updates to the indicated organization's stacks must
+// comply with, or nil if the organization has none.
+func (pc *Client) GetUpdateMessagePolicy(ctx context.Context, orgName string) (*apitype.UpdateMessagePolicy, error) {
+	var policy apitype.UpdateMessagePolicy
+	if err := pc.restCall(ctx, "GET", getOrgPath(orgName, "update-message-policy"), nil, nil, &policy); err != nil {
+		// Services that predate update message policies have none to enforce.
+		if restErr, ok := err.(*apitype.ErrorResponse); ok && restErr.Code == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if policy.Pattern == "" {
+		return nil, nil
+	}
+	return &policy, nil
+}
+
+// InviteOrganizationMember invites the user with the given email address to join the indicated organization with the
+// given role. The user becomes a member once they accept the invitation.
+func (pc *Client) InviteOrganizationMember(ctx context.Context, orgName, email string,
+	role apitype.OrganizationRole) error {
+
+	req := apitype.InviteOrganizationMemberRequest{Email: email, Role: role}
+	return pc.restCall(ctx, "POST", getOrgPath(orgName, "invites"), nil, &req, nil)
+}
+
+// UpdateOrganizationMember changes the role of the indicated member of an organization.
+func (pc *Client) UpdateOrganizationMember(ctx context.Context, orgName, userName string,
+	role apitype.OrganizationRole) error {
+
+	req := apitype.UpdateOrganizationMemberRequest{Role: role}
+	return pc.restCall(ctx, "PATCH", getOrgPath(orgName, "members", userName), nil, &req, nil)
+}
+
+// RemoveOrganizationMember removes the indicated user from an organization and from all of its teams.
+func (pc *Client) RemoveOrganizationMember(ctx context.Context, orgName, userName string) error {
+	return pc.restCall(ctx, "DELETE", getOrgPath(orgName, "members", userName), nil, nil, nil)
+}
+
+// ListOrganizationMembers returns the users who belong to the indicated organization.
+func (pc *Client) ListOrganizationMembers(ctx context.Context,
+	orgName string) ([]apitype.OrganizationMember, error) {
+
+	var resp apitype.ListOrganizationMembersResponse
+	if err := pc.restCall(ctx, "GET", getOrgPath(orgName, "members"), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Members, nil
+}
+
+// ListOrganizationTeams returns the teams within the indicated organization. The teams' members are not included; use
+// GetTeam to fetch them.
+func (pc *Client) ListOrganizationTeams(ctx context.Context, orgName string) ([]apitype.Team, error) {
+	var resp apitype.ListOrganizationTeamsResponse
+	if err := pc.restCall(ctx, "GET", getOrgPath(orgName, "teams"), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Teams, nil
+}
+
+// GetTeam returns the indicated team, including its members.
+func (pc *Client) GetTeam(ctx context.Context, orgName, teamName string) (apitype.Team, error) {
+	var team apitype.Team
+	if err := pc.restCall(ctx, "GET", getOrgPath(orgName, "teams", teamName), nil, nil, &team); err != nil {
+		return apitype.Team{}, err
+	}
+	return team, nil
+}
+
+// CreateTeam creates a team with no members within the indicated organization.
+func (pc *Client) CreateTeam(ctx context.Context, orgName string, req apitype.CreateTeamRequest) (apitype.Team, error) {
+	var team apitype.Team
+	if err := pc.restCall(ctx, "POST", getOrgPath(orgName, "teams"), nil, &req, &team); err != nil {
+		return apitype.Team{}, err
+	}
+	return team, nil
+}
+
+// DeleteTeam deletes the indicated team, revoking any access to stacks that was granted to it.
+func (pc *Client) DeleteTeam(ctx context.Context, orgName, teamName string) error {
+	return pc.restCall(ctx, "DELETE", getOrgPath(orgName, "teams", teamName), nil, nil, nil)
+}
+
+// AddTeamMember adds the indicated member of an organization to one of its teams.
+func (pc *Client) AddTeamMember(ctx context.Context, orgName, teamName, userName string) error {
+	return pc.restCall(ctx, "PUT", getOrgPath(orgName, "teams", teamName, "members", userName), nil, nil, nil)
+}
+
+// RemoveTeamMember removes the indicated user from a team, without removing them from the organization.
+func (pc *Client) RemoveTeamMember(ctx context.Context, orgName, teamName, userName string) error {
+	return pc.restCall(ctx, "DELETE", getOrgPath(orgName, "teams", teamName, "members", userName), nil, nil, nil)
+}
+
+// GetTeamPermissions returns the stacks that the indicated team has been granted access to.
+func (pc *Client) GetTeamPermissions(ctx context.Context,
+	orgName, teamName string) ([]apitype.TeamStackPermission, error) {
+
+	var resp apitype.GetTeamPermissionsResponse
+	if err := pc.restCall(ctx, "GET", getOrgPath(orgName, "teams", teamName, "stacks"), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Stacks, nil
+}
+
+// AddStackToTeam grants the indicated team of the stack's organization the given level of access to the stack,
+// replacing any access it had previously been granted.
+func (pc *Client) AddStackToTeam(ctx context.Context, stack StackIdentifier, teamName string,
+	permission apitype.StackPermission) error {
+
+	return pc.GrantStackPermission(ctx, stack, apitype.StackCollaboratorTeam, teamName, permission)
+}
+
+// RemoveStackFromTeam revokes all access the indicated team has been granted to the stack.
+func (pc *Client) RemoveStackFromTeam(ctx context.Context, stack StackIdentifier, teamName string) error {
+	return pc.RevokeStackPermission(ctx, stack, apitype.StackCollaboratorTeam, teamName)
+}
+
+// GetOrgUsageSummary returns the indicated organization's usage of the service between the given times. If either time
+// is zero, the period starts or ends with the organization's current billing period.
+func (pc *Client) GetOrgUsageSummary(ctx context.Context, orgName string,
+	since, until time.Time) (apitype.OrgUsageSummary, error) {
+
+	var queryObj struct {
+		Since *int64 `url:"since,omitempty"`
+		Until *int64 `url:"until,omitempty"`
+	}
+	if !since.IsZero() {
+		s := since.Unix()
+		queryObj.Since = &s
+	}
+	if !until.IsZero() {
+		u := until.Unix()
+		queryObj.Until = &u
+	}
+
+	var resp apitype.OrgUsageSummary
+	if err := pc.restCall(ctx, "GET", getOrgPath(orgName, "usage"), queryObj, nil, &resp); err != nil {
+		return apitype.OrgUsageSummary{}, err
+	}
+	return resp, nil
+}
+
+// ListStackActivity returns a page of the indicated stack's activity feed, which records its updates, tag changes, and
+// permission changes. The feed starts from its oldest event, or after the events that returned the given continuation
+// token, if any.
+func (pc *Client) ListStackActivity(ctx context.Context, stack StackIdentifier,
+	continuationToken *string) (apitype.ListStackActivityResponse, error) {
+
+	queryObj := struct {
+		ContinuationToken *string `url:"continuationToken,omitempty"`
+	}{
+		ContinuationToken: continuationToken,
+	}
+
+	var resp apitype.ListStackActivityResponse
+	if err := pc.restCall(ctx, "GET", getStackPath(stack, "activity"), queryObj, nil, &resp); err != nil {
+		return apitype.ListStackActivityResponse{}, err
+	}
+	return resp, nil
+}
+
+// ListFreezeWindows returns the freeze windows that apply to the indicated stack, including those of its organization.
+func (pc *Client) ListFreezeWindows(ctx context.Context, stack StackIdentifier) ([]apitype.FreezeWindow, error) {
+	var resp apitype.ListFreezeWindowsResponse
+	if err := pc.restCall(ctx, "GET", getStackPath(stack, "freeze-windows"), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.FreezeWindows, nil
+}
+
+// GetStackSchema returns the schema last published by the indicated stack, or nil if it has not published one.
+func (pc *Client) GetStackSchema(ctx context.Context, stack StackIdentifier) (*apitype.StackSchema, error) {
+	var schema apitype.StackSchema
+	if err := pc.restCall(ctx, "GET", getStackPath(stack, "schema"), nil, nil, &schema); err != nil {
+		if restErr, ok := err.(*apitype.ErrorResponse); ok && restErr.Code == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// PublishStackSchema replaces the schema of the indicated stack's configuration and outputs.
+func (pc *Client) PublishStackSchema(ctx context.Context, stack StackIdentifier, schema apitype.StackSchema) error {
+	return pc.restCall(ctx, "PUT", getStackPath(stack, "schema"), nil, &schema, nil)
+}
+
+// CreateOrganizationWebhook creates a webhook that is notified of the events of all of the indicated organization's
+// stacks.
+func (pc *Client) CreateOrganizationWebhook(ctx context.Context, orgName string,
+	req apitype.CreateWebhookRequest) (apitype.Webhook, error) {
+
+	var hook apitype.Webhook
+	if err := pc.restCall(ctx, "POST", getOrgPath(orgName, "hooks"), nil, &req, &hook); err != nil {
+		return apitype.Webhook{}, err
+	}
+	return hook, nil
+}
+
+// DeleteOrganizationWebhook deletes the indicated webhook of an organization.
+func (pc *Client) DeleteOrganizationWebhook(ctx context.Context, orgName, hookName string) error {
+	return pc.restCall(ctx, "DELETE", getOrgPath(orgName, "hooks", hookName), nil, nil, nil)
+}
+
+// CreateStackWebhook creates a webhook that is notified of the indicated stack's events.
+func (pc *Client) CreateStackWebhook(ctx context.Context, stack StackIdentifier,
+	req apitype.CreateWebhookRequest) (apitype.Webhook, error) {
+
+	var hook apitype.Webhook
+	if err := pc.restCall(ctx, "POST", getStackPath(stack, "hooks"), nil, &req, &hook); err != nil {
+		return apitype.Webhook{}, err
+	}
+	return hook, nil
+}
+
+// DeleteStackWebhook deletes the indicated webhook of a stack.
+func (pc *Client) DeleteStackWebhook(ctx context.Context, stack StackIdentifier, hookName string) error {
+	return pc.restCall(ctx, "DELETE", getStackPath(stack, "hooks", hookName), nil, nil, nil)
+}
+
+// TagsUpdate describes how StartUpdate changes the tags of the update's stack.
+type TagsUpdate struct {
+	// Tags are the stack's new tags.
+	Tags map[apitype.StackTagName]string
+	// Merge, if true, sets each of Tags and leaves the stack's other tags as they are, rather than replacing them.
+	Merge bool
+	// IfMatch, if non-empty, is the entity tag of the stack's tags as they were read, from apitype.Stack.TagsETag. If
+	// the tags have changed since, they are left as they are and StartUpdate returns ErrStackTagsChanged.
+	IfMatch string
+}
+
+// ErrStackTagsChanged is returned by StartUpdate if the stack's tags have changed since they were read.
+var ErrStackTagsChanged = errors.New("the stack's tags have changed since they were read")
+
+// StartUpdate starts the indicated update. It returns the new version of the update's target stack and the token used
+// to authenticate operations on the update if any. Updates the stack's tags as described by tags.
+func (pc *Client) StartUpdate(ctx context.Context, update UpdateIdentifier, tags TagsUpdate) (int, string, error) {
 	// Validate names and tags.
-	if err := validation.ValidateStackProperties(update.StackIdentifier.Stack, tags); err != nil {
+	if err := validation.ValidateStackProperties(update.StackIdentifier.Stack, tags.Tags); err != nil {
 		return 0, "", errors.Wrap(err, "validating stack properties")
 	}
 
 	req := apitype.StartUpdateRequest{
-		Tags: tags,
+		Tags:      tags.Tags,
+		MergeTags: tags.Merge,
+	}
+
+	var opts httpCallOptions
+	if tags.IfMatch != "" {
+		opts.Header = http.Header{"If-Match": []string{tags.IfMatch}}
 	}
 
 	var resp apitype.StartUpdateResponse
-	if err := pc.restCall(ctx, "POST", getUpdatePath(update), nil, req, &resp); err != nil {
+	if err := pc.restCallWithOptions(ctx, "POST", getUpdatePath(update), nil, req, &resp, opts); err != nil {
+		if restErr, ok := err.(*apitype.ErrorResponse); ok && restErr.Code == http.StatusPreconditionFailed {
+			return 0, "", ErrStackTagsChanged
+		}
 		return 0, "", err
 	}
 
 	return resp.Version, resp.Token, nil
 }
 
+// RequestApproval submits the indicated update's plan for approval by the stack's organization. The update may not be
+// started until an approver approves it; use GetApprovalStatus to learn whether one has.
+func (pc *Client) RequestApproval(ctx context.Context, update UpdateIdentifier,
+	plan apitype.VersionedUpdatePlan) error {
+
+	req := apitype.RequestApprovalRequest{Plan: plan}
+	return pc.restCall(ctx, "POST", getUpdatePath(update, "approval"), nil, req, nil)
+}
+
+// GetApprovalStatus returns the state of the indicated update's request for approval.
+func (pc *Client) GetApprovalStatus(ctx context.Context,
+	update UpdateIdentifier) (apitype.GetApprovalStatusResponse, error) {
+
+	var resp apitype.GetApprovalStatusResponse
+	if err := pc.restCall(ctx, "GET", getUpdatePath(update, "approval"), nil, nil, &resp); err != nil {
+		return apitype.GetApprovalStatusResponse{}, err
+	}
+	return resp, nil
+}
+
 // PublishPolicyPack publishes a `PolicyPack` to the Pulumi service.
 func (pc *Client) PublishPolicyPack(ctx context.Context, orgName string,
 	analyzerInfo plugin.AnalyzerInfo, dirArchive io.Reader) error {
@@ -488,7 +1134,7 @@ func (pc *Client) PublishPolicyPack(ctx context.Context, orgName string,
 		return errors.Wrapf(err, "Failed to upload compressed PolicyPack")
 	}
 
-	_, err = http.DefaultClient.Do(putS3Req)
+	_, err = pc.httpClient().Do(putS3Req)
 	if err != nil {
 		return errors.Wrapf(err, "Failed to upload compressed PolicyPack")
 	}
@@ -521,6 +1167,138 @@ func (pc *Client) ApplyPolicyPack(ctx context.Context, orgName string, policyPac
 	return nil
 }
 
+// GetPolicyPack gets the metadata for the given version of a `PolicyPack`, including the location from
+// which it can be downloaded.
+func (pc *Client) GetPolicyPack(ctx context.Context, orgName string, policyPackName string,
+	version int) (apitype.GetPolicyPackResponse, error) {
+
+	var resp apitype.GetPolicyPackResponse
+	err := pc.restCall(ctx, "GET", getPolicyPackPath(orgName, policyPackName, version), nil, nil, &resp)
+	if err != nil {
+		return apitype.GetPolicyPackResponse{}, errors.Wrapf(err, "HTTP GET of policy pack failed")
+	}
+	return resp, nil
+}
+
+// ListPolicyPacks returns the Policy Packs that have been published to the indicated organization.
+func (pc *Client) ListPolicyPacks(ctx context.Context, orgName string) ([]apitype.PolicyPackSummary, error) {
+	var resp apitype.ListPolicyPacksResponse
+	if err := pc.restCall(ctx, "GET", getOrgPath(orgName, "policypacks"), nil, nil, &resp); err != nil {
+		return nil, errors.Wrapf(err, "HTTP GET of policy packs failed")
+	}
+	return resp.PolicyPacks, nil
+}
+
+// ListPolicyPackVersions returns the published versions of the indicated Policy Pack, in ascending order.
+func (pc *Client) ListPolicyPackVersions(ctx context.Context, orgName string,
+	policyPackName string) ([]apitype.PolicyPackVersion, error) {
+
+	var resp apitype.ListPolicyPackVersionsResponse
+	err := pc.restCall(ctx, "GET", getOrgPath(orgName, "policypacks", policyPackName), nil, nil, &resp)
+	if err != nil {
+		return nil, errors.Wrapf(err, "HTTP GET of policy pack versions failed")
+	}
+	return resp.Versions, nil
+}
+
+// DisablePolicyPack stops enforcing the indicated Policy Pack in the organization, whichever version of it is applied.
+func (pc *Client) DisablePolicyPack(ctx context.Context, orgName string, policyPackName string) error {
+	err := pc.restCall(ctx, "POST", getOrgPath(orgName, "policypacks", policyPackName, "disable"), nil, nil, nil)
+	if err != nil {
+		return errors.Wrapf(err, "HTTP POST to disable policy pack failed")
+	}
+	return nil
+}
+
+// RemovePolicyPack removes the given version of the indicated Policy Pack from the organization, or every version of
+// it if the version is 0. A version that is applied to the organization cannot be removed.
+func (pc *Client) RemovePolicyPack(ctx context.Context, orgName string, policyPackName string, version int) error {
+	path := getOrgPath(orgName, "policypacks", policyPackName)
+	if version != 0 {
+		path = getPolicyPackPath(orgName, policyPackName, version)
+	}
+	if err := pc.restCall(ctx, "DELETE", path, nil, nil, nil); err != nil {
+		return errors.Wrapf(err, "HTTP DELETE of policy pack failed")
+	}
+	return nil
+}
+
+// PolicyViolationsFilter describes optional filters when fetching policy violations.
+type PolicyViolationsFilter struct {
+	Project          *string
+	Stack            *string
+	PolicyPack       *string
+	Policy           *string
+	EnforcementLevel *apitype.EnforcementLevel
+
+	// Since and Until, if not zero, bound the times at which the violations occurred.
+	Since time.Time
+	Until time.Time
+
+	// ContinuationToken resumes a listing after the page of violations that returned it.
+	ContinuationToken *string
+}
+
+// GetPolicyViolations returns the policy violations that have occurred in the indicated organization's stacks and that
+// match the given filter, newest first. The violations are fetched a page at a time, starting from the filter's
+// continuation token, if any.
+func (pc *Client) GetPolicyViolations(ctx context.Context, orgName string,
+	filter PolicyViolationsFilter) ([]apitype.PolicyViolation, error) {
+
+	var violations []apitype.PolicyViolation
+	for {
+		resp, err := pc.GetPolicyViolationsPage(ctx, orgName, filter)
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, resp.Violations...)
+		if resp.ContinuationToken == nil || len(resp.Violations) == 0 {
+			return violations, nil
+		}
+		filter.ContinuationToken = resp.ContinuationToken
+	}
+}
+
+// GetPolicyViolationsPage returns a single page of the policy violations that have occurred in the indicated
+// organization's stacks and that match the given filter. The response's continuation token, if any, may be passed back
+// in the filter to fetch the next page.
+func (pc *Client) GetPolicyViolationsPage(ctx context.Context, orgName string,
+	filter PolicyViolationsFilter) (apitype.GetPolicyViolationsResponse, error) {
+
+	queryFilter := struct {
+		Project           *string                   `url:"project,omitempty"`
+		Stack             *string                   `url:"stack,omitempty"`
+		PolicyPack        *string                   `url:"policyPack,omitempty"`
+		Policy            *string                   `url:"policy,omitempty"`
+		EnforcementLevel  *apitype.EnforcementLevel `url:"enforcementLevel,omitempty"`
+		Since             *int64                    `url:"since,omitempty"`
+		Until             *int64                    `url:"until,omitempty"`
+		ContinuationToken *string                   `url:"continuationToken,omitempty"`
+	}{
+		Project:           filter.Project,
+		Stack:             filter.Stack,
+		PolicyPack:        filter.PolicyPack,
+		Policy:            filter.Policy,
+		EnforcementLevel:  filter.EnforcementLevel,
+		ContinuationToken: filter.ContinuationToken,
+	}
+	if !filter.Since.IsZero() {
+		s := filter.Since.Unix()
+		queryFilter.Since = &s
+	}
+	if !filter.Until.IsZero() {
+		u := filter.Until.Unix()
+		queryFilter.Until = &u
+	}
+
+	var resp apitype.GetPolicyViolationsResponse
+	err := pc.restCall(ctx, "GET", getOrgPath(orgName, "policyviolations"), queryFilter, nil, &resp)
+	if err != nil {
+		return apitype.GetPolicyViolationsResponse{}, err
+	}
+	return resp, nil
+}
+
 // DownloadPolicyPack applies a `PolicyPack` to the Pulumi organization.
 func (pc *Client) DownloadPolicyPack(ctx context.Context, url string) ([]byte, error) {
 	fmt.Println("Downloading policy pack")
@@ -530,7 +1308,7 @@ func (pc *Client) DownloadPolicyPack(ctx context.Context, url string) ([]byte, e
 		return nil, errors.Wrapf(err, "Failed to download compressed PolicyPack")
 	}
 
-	resp, err := http.DefaultClient.Do(getS3Req)
+	resp, err := pc.httpClient().Do(getS3Req)
 	if err != nil {
 		return nil, errors.Wrapf(err, "Failed to download compressed PolicyPack")
 	}
@@ -544,23 +1322,39 @@ func (pc *Client) DownloadPolicyPack(ctx context.Context, url string) ([]byte, e
 	return tarball, nil
 }
 
-// GetUpdateEvents returns all events, taking an optional continuation token from a previous call.
+// GetUpdateEvents returns all events, taking an optional continuation token from a previous call. If summarize is
+// true, the service is asked to elide the bulky fields of verbose events, which are then marked as truncated; their
+// full payloads can be fetched individually with GetUpdateEvent.
 func (pc *Client) GetUpdateEvents(ctx context.Context, update UpdateIdentifier,
-	continuationToken *string) (apitype.UpdateResults, error) {
+	continuationToken *string, summarize bool) (apitype.UpdateResults, error) {
 
-	path := getUpdatePath(update)
-	if continuationToken != nil {
-		path += fmt.Sprintf("?continuationToken=%s", *continuationToken)
+	queryObj := struct {
+		ContinuationToken *string `url:"continuationToken,omitempty"`
+		Summarize         bool    `url:"summarize,omitempty"`
+	}{
+		ContinuationToken: continuationToken,
+		Summarize:         summarize,
 	}
 
 	var results apitype.UpdateResults
-	if err := pc.restCall(ctx, "GET", path, nil, nil, &results); err != nil {
+	if err := pc.restCall(ctx, "GET", getUpdatePath(update), queryObj, nil, &results); err != nil {
 		return apitype.UpdateResults{}, err
 	}
 
 	return results, nil
 }
 
+// GetUpdateEvent returns the full payload of the update event with the given index.
+func (pc *Client) GetUpdateEvent(ctx context.Context, update UpdateIdentifier,
+	index string) (apitype.UpdateEvent, error) {
+
+	var event apitype.UpdateEvent
+	if err := pc.restCall(ctx, "GET", getUpdatePath(update, "events", index), nil, nil, &event); err != nil {
+		return apitype.UpdateEvent{}, err
+	}
+	return event, nil
+}
+
 // RenewUpdateLease renews the indicated update lease for the given duration.
 func (pc *Client) RenewUpdateLease(ctx context.Context, update UpdateIdentifier, token string,
 	duration time.Duration) (string, error) {
@@ -612,6 +1406,22 @@ func (pc *Client) PatchUpdateCheckpoint(ctx context.Context, update UpdateIdenti
 		updateAccessToken(token), httpCallOptions{RetryAllMethods: true, GzipCompress: true})
 }
 
+// PatchUpdateEncryptedCheckpoint patches the checkpoint for the indicated update with the given deployment, which is
+// encrypted at rest.
+func (pc *Client) PatchUpdateEncryptedCheckpoint(ctx context.Context, update UpdateIdentifier,
+	encrypted *apitype.EncryptedCheckpointV1, token string) error {
+
+	req := apitype.PatchUpdateCheckpointRequest{
+		Version:   3,
+		Encrypted: encrypted,
+	}
+
+	// It is safe to retry this PATCH operation, because it is logically idempotent, since we send the entire
+	// deployment instead of a set of changes to apply.
+	return pc.updateRESTCall(ctx, "PATCH", getUpdatePath(update, "checkpoint"), nil, req, nil,
+		updateAccessToken(token), httpCallOptions{RetryAllMethods: true, GzipCompress: true})
+}
+
 // CancelUpdate cancels the indicated update.
 func (pc *Client) CancelUpdate(ctx context.Context, update UpdateIdentifier) error {
 
@@ -620,6 +1430,59 @@ func (pc *Client) CancelUpdate(ctx context.Context, update UpdateIdentifier) err
 		httpCallOptions{RetryAllMethods: true})
 }
 
+// WaitForStackReady waits until the indicated stack has no update in progress, polling the status of its latest update
+// at the given interval. If the context ends first, an error wrapping the context's error is returned.
+func (pc *Client) WaitForStackReady(ctx context.Context, stackID StackIdentifier, interval time.Duration) error {
+	// Only fetch the events of the update that are new since the last poll; we need nothing but its status.
+	var update UpdateIdentifier
+	var continuationToken *string
+	backoff := 1.0
+	ready, _, err := retry.Until(ctx, retry.Acceptor{
+		Delay:    &interval,
+		Backoff:  &backoff,
+		MaxDelay: &interval,
+		Accept: func(try int, nextRetryTime time.Duration) (bool, interface{}, error) {
+			stack, err := pc.GetStack(ctx, stackID)
+			if err != nil {
+				return false, nil, err
+			}
+			if stack.ActiveUpdate == "" {
+				return true, nil, nil
+			}
+			if stack.ActiveUpdate != update.UpdateID {
+				update = UpdateIdentifier{
+					StackIdentifier: stackID,
+					UpdateKind:      apitype.UpdateUpdate,
+					UpdateID:        stack.ActiveUpdate,
+				}
+				continuationToken = nil
+			}
+
+			results, err := pc.GetUpdateEvents(ctx, update, continuationToken, true)
+			if err != nil {
+				return false, nil, err
+			}
+			continuationToken = results.ContinuationToken
+			switch results.Status {
+			case apitype.StatusRequested, apitype.StatusRunning:
+				logging.V(7).Infof("update %s is %s; checking again in %v", update.UpdateID, results.Status,
+					nextRetryTime)
+				return false, nil, nil
+			default:
+				return true, nil, nil
+			}
+		},
+	})
+	if ready || ctx.Err() == nil {
+		return err
+	}
+	stackName := fmt.Sprintf("%s/%s/%s", stackID.Owner, stackID.Project, stackID.Stack)
+	if update.UpdateID == "" {
+		return errors.Wrapf(ctx.Err(), "waiting for stack %s", stackName)
+	}
+	return errors.Wrapf(ctx.Err(), "update %s of stack %s is still in progress", update.UpdateID, stackName)
+}
+
 // CompleteUpdate completes the indicated update with the given status.
 func (pc *Client) CompleteUpdate(ctx context.Context, update UpdateIdentifier, status apitype.UpdateStatus,
 	token string) error {
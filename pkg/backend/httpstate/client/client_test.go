@@ -0,0 +1,731 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+)
+
+func TestExportStackDeploymentVersion(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		_, err := w.Write([]byte(`{"version":3,"deployment":{}}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", nil)
+	stack := StackIdentifier{Owner: "owner", Project: "project", Stack: "stack"}
+
+	_, err := client.ExportStackDeployment(context.Background(), stack, nil)
+	assert.NoError(t, err)
+
+	version := 7
+	_, err = client.ExportStackDeployment(context.Background(), stack, &version)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"/api/stacks/owner/project/stack/export",
+		"/api/stacks/owner/project/stack/export/7",
+	}, paths)
+}
+
+func TestListStackActivity(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/stacks/owner/project/stack/activity", r.URL.Path)
+		queries = append(queries, r.URL.RawQuery)
+		_, err := w.Write([]byte(`{"events":[{"kind":"update","user":"alice","version":2}],"continuationToken":"next"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", nil)
+	stack := StackIdentifier{Owner: "owner", Project: "project", Stack: "stack"}
+
+	resp, err := client.ListStackActivity(context.Background(), stack, nil)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Events, 1)
+	assert.Equal(t, 2, resp.Events[0].Version)
+
+	_, err = client.ListStackActivity(context.Background(), stack, resp.ContinuationToken)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "continuationToken=next"}, queries)
+}
+
+func TestListStacksPagination(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/user/stacks", r.URL.Path)
+		queries = append(queries, r.URL.RawQuery)
+		var body string
+		switch r.URL.Query().Get("continuationToken") {
+		case "":
+			body = `{"stacks":[{"orgName":"owner","projectName":"project","stackName":"a"},` +
+				`{"orgName":"owner","projectName":"project","stackName":"b"}],"continuationToken":"page2"}`
+		case "page2":
+			body = `{"stacks":[],"continuationToken":"page3"}`
+		case "page3":
+			body = `{"stacks":[{"orgName":"owner","projectName":"project","stackName":"c"}]}`
+		}
+		_, err := w.Write([]byte(body))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", nil)
+	project, pageSize := "project", 2
+	filter := ListStacksFilter{Project: &project, PageSize: &pageSize}
+
+	// A single page returns the token for the next.
+	page, err := client.ListStacksPage(context.Background(), filter)
+	assert.NoError(t, err)
+	assert.Len(t, page.Stacks, 2)
+	if assert.NotNil(t, page.ContinuationToken) {
+		assert.Equal(t, "page2", *page.ContinuationToken)
+	}
+
+	// Listing follows the tokens, including past empty pages, until there are none left.
+	queries = nil
+	stacks, err := client.ListStacks(context.Background(), filter)
+	assert.NoError(t, err)
+	var names []string
+	for _, s := range stacks {
+		names = append(names, s.StackName)
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+	assert.Equal(t, []string{
+		"pageSize=2&project=project",
+		"continuationToken=page2&pageSize=2&project=project",
+		"continuationToken=page3&pageSize=2&project=project",
+	}, queries)
+}
+
+func TestListStacksFields(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		_, err := w.Write([]byte(`{"stacks":[{"orgName":"owner","projectName":"project","stackName":"a"}]}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", nil)
+	stacks, err := client.ListStacks(context.Background(), ListStacksFilter{Fields: StackNameFields})
+	assert.NoError(t, err)
+	assert.Len(t, stacks, 1)
+	assert.Nil(t, stacks[0].LastUpdate)
+
+	_, err = client.ListStacks(context.Background(), ListStacksFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"fields=orgName%2CprojectName%2CstackName", ""}, queries)
+}
+
+func TestTeamManagement(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		var body string
+		switch r.URL.Path {
+		case "/api/orgs/acme/teams":
+			body = `{"teams":[{"name":"ops","displayName":"Operations"}]}`
+		case "/api/orgs/acme/teams/ops":
+			body = `{"name":"ops","members":["alice","bob"]}`
+		case "/api/orgs/acme/teams/ops/stacks":
+			body = `{"stacks":[{"projectName":"web","stackName":"prod","permission":102}]}`
+		}
+		_, err := w.Write([]byte(body))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", nil)
+	ctx := context.Background()
+
+	teams, err := client.ListOrganizationTeams(ctx, "acme")
+	assert.NoError(t, err)
+	assert.Equal(t, []apitype.Team{{Name: "ops", DisplayName: "Operations"}}, teams)
+
+	team, err := client.GetTeam(ctx, "acme", "ops")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, team.Members)
+
+	assert.NoError(t, client.AddTeamMember(ctx, "acme", "ops", "carol"))
+
+	stack := StackIdentifier{Owner: "acme", Project: "web", Stack: "staging"}
+	assert.NoError(t, client.AddStackToTeam(ctx, stack, "ops", apitype.StackPermissionRead))
+	assert.NoError(t, client.RemoveStackFromTeam(ctx, stack, "ops"))
+
+	permissions, err := client.GetTeamPermissions(ctx, "acme", "ops")
+	assert.NoError(t, err)
+	assert.Equal(t, []apitype.TeamStackPermission{
+		{ProjectName: "web", StackName: "prod", Permission: apitype.StackPermissionWrite},
+	}, permissions)
+
+	assert.Equal(t, []string{
+		"GET /api/orgs/acme/teams",
+		"GET /api/orgs/acme/teams/ops",
+		"PUT /api/orgs/acme/teams/ops/members/carol",
+		"PUT /api/stacks/acme/web/staging/collaborators/team/ops",
+		"DELETE /api/stacks/acme/web/staging/collaborators/team/ops",
+		"GET /api/orgs/acme/teams/ops/stacks",
+	}, requests)
+}
+
+func TestOrganizationManagement(t *testing.T) {
+	var requests []string
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Body != nil {
+			b, err := ioutil.ReadAll(r.Body)
+			assert.NoError(t, err)
+			if len(b) > 0 {
+				bodies = append(bodies, string(b))
+			}
+		}
+		var body string
+		switch r.URL.Path {
+		case "/api/user/organizations":
+			body = `{"organizations":[{"name":"acme","displayName":"ACME","role":"admin","members":3}]}`
+		case "/api/orgs/acme":
+			body = `{"name":"acme","displayName":"ACME","role":"admin","members":3}`
+		case "/api/orgs/acme/members":
+			body = `{"members":[{"name":"alice","role":"admin"},{"name":"bob","role":"member"}]}`
+		case "/api/orgs/acme/update-message-policy":
+			body = `{"pattern":"^OPS-[0-9]+: ","description":"a ticket ID"}`
+		case "/api/orgs/other/update-message-policy":
+			w.WriteHeader(http.StatusNotFound)
+			body = `{"code":404,"message":"not found"}`
+		}
+		_, err := w.Write([]byte(body))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", nil)
+	ctx := context.Background()
+
+	acme := apitype.Organization{Name: "acme", DisplayName: "ACME", Role: apitype.OrganizationRoleAdmin, Members: 3}
+	orgs, err := client.ListOrganizations(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []apitype.Organization{acme}, orgs)
+
+	org, err := client.GetOrganization(ctx, "acme")
+	assert.NoError(t, err)
+	assert.Equal(t, acme, org)
+
+	members, err := client.ListOrganizationMembers(ctx, "acme")
+	assert.NoError(t, err)
+	assert.Equal(t, []apitype.OrganizationMember{
+		{Name: "alice", Role: apitype.OrganizationRoleAdmin},
+		{Name: "bob", Role: apitype.OrganizationRoleMember},
+	}, members)
+
+	assert.NoError(t, client.InviteOrganizationMember(ctx, "acme", "carol@example.com",
+		apitype.OrganizationRoleMember))
+	assert.NoError(t, client.UpdateOrganizationMember(ctx, "acme", "bob", apitype.OrganizationRoleAdmin))
+	assert.NoError(t, client.RemoveOrganizationMember(ctx, "acme", "alice"))
+
+	policy, err := client.GetUpdateMessagePolicy(ctx, "acme")
+	assert.NoError(t, err)
+	assert.Equal(t, &apitype.UpdateMessagePolicy{Pattern: "^OPS-[0-9]+: ", Description: "a ticket ID"}, policy)
+	policy, err = client.GetUpdateMessagePolicy(ctx, "other")
+	assert.NoError(t, err)
+	assert.Nil(t, policy)
+
+	assert.Equal(t, []string{
+		"GET /api/user/organizations",
+		"GET /api/orgs/acme",
+		"GET /api/orgs/acme/members",
+		"POST /api/orgs/acme/invites",
+		"PATCH /api/orgs/acme/members/bob",
+		"DELETE /api/orgs/acme/members/alice",
+		"GET /api/orgs/acme/update-message-policy",
+		"GET /api/orgs/other/update-message-policy",
+	}, requests)
+	assert.Equal(t, []string{
+		`{"email":"carol@example.com","role":"member"}`,
+		`{"role":"admin"}`,
+	}, bodies)
+}
+
+func TestSetStackPermissions(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Method == "GET" {
+			_, err := w.Write([]byte(`{"collaborators":[` +
+				`{"name":"alice","kind":"user","permission":103},` +
+				`{"name":"bob","kind":"user","permission":101},` +
+				`{"name":"ops","kind":"team","permission":102}]}`))
+			assert.NoError(t, err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", nil)
+	stack := StackIdentifier{Owner: "acme", Project: "web", Stack: "prod"}
+
+	perms, err := client.GetStackPermissions(context.Background(), stack)
+	assert.NoError(t, err)
+	assert.Equal(t, apitype.StackPermissions{
+		Users: map[string]apitype.StackPermission{
+			"alice": apitype.StackPermissionAdmin,
+			"bob":   apitype.StackPermissionRead,
+		},
+		Teams: map[string]apitype.StackPermission{"ops": apitype.StackPermissionWrite},
+	}, perms)
+
+	// Without replacement, only the given permissions are changed, and only where they differ.
+	requests = nil
+	desired := apitype.StackPermissions{
+		Users: map[string]apitype.StackPermission{
+			"alice": apitype.StackPermissionAdmin,
+			"carol": apitype.StackPermissionWrite,
+		},
+		Teams: map[string]apitype.StackPermission{"ops": apitype.StackPermissionRead},
+	}
+	changes, err := client.DiffStackPermissions(context.Background(), stack, desired, false /*replace*/)
+	assert.NoError(t, err)
+	assert.Equal(t, []apitype.StackCollaborator{
+		{Name: "carol", Kind: apitype.StackCollaboratorUser, Permission: apitype.StackPermissionWrite},
+		{Name: "ops", Kind: apitype.StackCollaboratorTeam, Permission: apitype.StackPermissionRead},
+	}, changes)
+	assert.Equal(t, []string{"GET /api/stacks/acme/web/prod/collaborators"}, requests)
+
+	// With replacement, anyone not given a permission loses their access too.
+	requests = nil
+	changes, err = client.SetStackPermissions(context.Background(), stack, desired, true /*replace*/)
+	assert.NoError(t, err)
+	assert.Equal(t, []apitype.StackCollaborator{
+		{Name: "bob", Kind: apitype.StackCollaboratorUser, Permission: apitype.StackPermissionNone},
+		{Name: "carol", Kind: apitype.StackCollaboratorUser, Permission: apitype.StackPermissionWrite},
+		{Name: "ops", Kind: apitype.StackCollaboratorTeam, Permission: apitype.StackPermissionRead},
+	}, changes)
+	assert.Equal(t, []string{
+		"GET /api/stacks/acme/web/prod/collaborators",
+		"DELETE /api/stacks/acme/web/prod/collaborators/user/bob",
+		"PUT /api/stacks/acme/web/prod/collaborators/user/carol",
+		"PUT /api/stacks/acme/web/prod/collaborators/team/ops",
+	}, requests)
+}
+
+func TestStackPermissionEndpoints(t *testing.T) {
+	var requests, bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		bodies = append(bodies, string(body))
+
+		switch {
+		case r.Method == "GET":
+			_, err = w.Write([]byte(`{"collaborators":[` +
+				`{"name":"alice","kind":"user","permission":103},` +
+				`{"name":"ops","kind":"team","permission":101},` +
+				`{"name":"bob","kind":"user"}]}`))
+			assert.NoError(t, err)
+		case r.URL.Path == "/api/stacks/acme/web/prod/collaborators/user/mallory":
+			w.WriteHeader(http.StatusForbidden)
+			_, err = w.Write([]byte(`{"code":403,"message":"Forbidden"}`))
+			assert.NoError(t, err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", nil)
+	ctx := context.Background()
+	stack := StackIdentifier{Owner: "acme", Project: "web", Stack: "prod"}
+
+	// A collaborator listed without a permission has none.
+	collaborators, err := client.ListStackPermissions(ctx, stack)
+	assert.NoError(t, err)
+	assert.Equal(t, []apitype.StackCollaborator{
+		{Name: "alice", Kind: apitype.StackCollaboratorUser, Permission: apitype.StackPermissionAdmin},
+		{Name: "ops", Kind: apitype.StackCollaboratorTeam, Permission: apitype.StackPermissionRead},
+		{Name: "bob", Kind: apitype.StackCollaboratorUser, Permission: apitype.StackPermissionNone},
+	}, collaborators)
+
+	assert.NoError(t, client.GrantStackPermission(ctx, stack, apitype.StackCollaboratorTeam, "ops",
+		apitype.StackPermissionWrite))
+	assert.NoError(t, client.RevokeStackPermission(ctx, stack, apitype.StackCollaboratorUser, "alice"))
+
+	assert.Equal(t, []string{
+		"GET /api/stacks/acme/web/prod/collaborators",
+		"PUT /api/stacks/acme/web/prod/collaborators/team/ops",
+		"DELETE /api/stacks/acme/web/prod/collaborators/user/alice",
+	}, requests)
+	assert.Equal(t, []string{"", `{"permission":102}`, ""}, bodies)
+
+	// StackPermissionNone is applied as a revocation, never granted; the changes made before a failure are returned.
+	requests, bodies = nil, nil
+	applied, err := client.ApplyStackPermissionChanges(ctx, stack, []apitype.StackCollaborator{
+		{Name: "bob", Kind: apitype.StackCollaboratorUser, Permission: apitype.StackPermissionNone},
+		{Name: "carol", Kind: apitype.StackCollaboratorUser, Permission: apitype.StackPermissionRead},
+		{Name: "mallory", Kind: apitype.StackCollaboratorUser, Permission: apitype.StackPermissionAdmin},
+		{Name: "ops", Kind: apitype.StackCollaboratorTeam, Permission: apitype.StackPermissionNone},
+	})
+	assert.Error(t, err)
+	assert.Equal(t, []apitype.StackCollaborator{
+		{Name: "bob", Kind: apitype.StackCollaboratorUser, Permission: apitype.StackPermissionNone},
+		{Name: "carol", Kind: apitype.StackCollaboratorUser, Permission: apitype.StackPermissionRead},
+	}, applied)
+	assert.Equal(t, []string{
+		"DELETE /api/stacks/acme/web/prod/collaborators/user/bob",
+		"PUT /api/stacks/acme/web/prod/collaborators/user/carol",
+		"PUT /api/stacks/acme/web/prod/collaborators/user/mallory",
+	}, requests)
+	assert.Equal(t, []string{"", `{"permission":101}`, `{"permission":103}`}, bodies)
+}
+
+func TestListFreezeWindows(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/stacks/acme/web/prod/freeze-windows", r.URL.Path)
+		_, err := w.Write([]byte(`{"freezeWindows":[` +
+			`{"start":1577836800,"end":1578441600,"reason":"holidays","orgWide":true},` +
+			`{"start":1580515200,"end":1580601600}]}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", nil)
+	windows, err := client.ListFreezeWindows(context.Background(),
+		StackIdentifier{Owner: "acme", Project: "web", Stack: "prod"})
+	assert.NoError(t, err)
+	assert.Equal(t, []apitype.FreezeWindow{
+		{Start: 1577836800, End: 1578441600, Reason: "holidays", OrgWide: true},
+		{Start: 1580515200, End: 1580601600},
+	}, windows)
+}
+
+func TestGetOrgUsageSummary(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/orgs/acme/usage", r.URL.Path)
+		queries = append(queries, r.URL.RawQuery)
+		_, err := w.Write([]byte(`{"periodStart":1564617600,"periodEnd":1567296000,"stacks":2,` +
+			`"resourcesUnderManagement":40,"deploymentMinutes":95,"stackUsage":[` +
+			`{"projectName":"web","stackName":"prod","resourcesUnderManagement":30,"deploymentMinutes":60}]}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", nil)
+
+	summary, err := client.GetOrgUsageSummary(context.Background(), "acme", time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, 40, summary.ResourcesUnderManagement)
+	assert.Equal(t, []apitype.StackUsage{
+		{ProjectName: "web", StackName: "prod", ResourcesUnderManagement: 30, DeploymentMinutes: 60},
+	}, summary.StackUsage)
+
+	since := time.Unix(1564617600, 0)
+	_, err = client.GetOrgUsageSummary(context.Background(), "acme", since, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "since=1564617600"}, queries)
+}
+
+func TestGetUpdateEventsSummarized(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path+"?"+r.URL.RawQuery)
+		var body string
+		if r.URL.Path == "/api/stacks/owner/project/stack/update/id/events/7" {
+			body = `{"index":"7","kind":"stderr","fields":{"text":"full"}}`
+		} else {
+			body = `{"status":"running","events":[{"index":"7","kind":"stderr","truncated":true}]}`
+		}
+		_, err := w.Write([]byte(body))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", nil)
+	update := UpdateIdentifier{
+		StackIdentifier: StackIdentifier{Owner: "owner", Project: "project", Stack: "stack"},
+		UpdateKind:      apitype.UpdateUpdate,
+		UpdateID:        "id",
+	}
+
+	results, err := client.GetUpdateEvents(context.Background(), update, nil, true)
+	assert.NoError(t, err)
+	assert.Len(t, results.Events, 1)
+	assert.True(t, results.Events[0].Truncated)
+
+	event, err := client.GetUpdateEvent(context.Background(), update, results.Events[0].Index)
+	assert.NoError(t, err)
+	assert.False(t, event.Truncated)
+	assert.Equal(t, "full", event.Fields["text"])
+
+	assert.Equal(t, []string{
+		"/api/stacks/owner/project/stack/update/id?summarize=true",
+		"/api/stacks/owner/project/stack/update/id/events/7?",
+	}, requests)
+}
+
+func TestWaitForStackReady(t *testing.T) {
+	var requests []string
+	statuses := []string{
+		`{"status":"running","events":[],"continuationToken":"1"}`,
+		`{"status":"running","events":[],"continuationToken":"2"}`,
+		`{"status":"succeeded","events":[]}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path+"?"+r.URL.RawQuery)
+		var body string
+		switch r.URL.Path {
+		case "/api/stacks/owner/project/stack":
+			body = `{"orgName":"owner","projectName":"project","stackName":"stack","activeUpdate":"id"}`
+		case "/api/stacks/owner/project/other":
+			body = `{"orgName":"owner","projectName":"project","stackName":"other","activeUpdate":""}`
+		default:
+			body, statuses = statuses[0], statuses[1:]
+		}
+		_, err := w.Write([]byte(body))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", nil)
+	stackID := StackIdentifier{Owner: "owner", Project: "project", Stack: "stack"}
+
+	// The update's events are skipped over on each poll until it finishes.
+	assert.NoError(t, client.WaitForStackReady(context.Background(), stackID, time.Millisecond))
+	assert.Equal(t, []string{
+		"/api/stacks/owner/project/stack?",
+		"/api/stacks/owner/project/stack/update/id?summarize=true",
+		"/api/stacks/owner/project/stack?",
+		"/api/stacks/owner/project/stack/update/id?continuationToken=1&summarize=true",
+		"/api/stacks/owner/project/stack?",
+		"/api/stacks/owner/project/stack/update/id?continuationToken=2&summarize=true",
+	}, requests)
+
+	// A stack that has never been updated is ready straight away.
+	requests = nil
+	other := StackIdentifier{Owner: "owner", Project: "project", Stack: "other"}
+	assert.NoError(t, client.WaitForStackReady(context.Background(), other, time.Millisecond))
+	assert.Equal(t, []string{"/api/stacks/owner/project/other?"}, requests)
+
+	// Waiting for an update that doesn't finish times out.
+	statuses = []string{
+		`{"status":"running","events":[],"continuationToken":"1"}`,
+		`{"status":"running","events":[],"continuationToken":"1"}`,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := client.WaitForStackReady(ctx, stackID, time.Second)
+	if assert.Error(t, err) {
+		assert.Equal(t, context.DeadlineExceeded, errors.Cause(err))
+		assert.Contains(t, err.Error(), "update id of stack owner/project/stack is still in progress")
+	}
+}
+
+func TestRecordSecretsRevealed(t *testing.T) {
+	var path string
+	var body apitype.RecordSecretsRevealedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		assert.Equal(t, "POST", r.Method)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", nil)
+	stack := StackIdentifier{Owner: "owner", Project: "project", Stack: "stack"}
+	err := client.RecordSecretsRevealed(context.Background(), stack, apitype.RecordSecretsRevealedRequest{
+		Command: "stack export",
+		Paths:   []string{"outputs.dbPassword"},
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "/api/stacks/owner/project/stack/audit/secrets-revealed", path)
+	assert.Equal(t, "stack export", body.Command)
+	assert.Equal(t, []string{"outputs.dbPassword"}, body.Paths)
+}
+
+func TestStackSchema(t *testing.T) {
+	var published *apitype.StackSchema
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/stacks/owner/project/stack/schema", r.URL.Path)
+		switch r.Method {
+		case "PUT":
+			published = &apitype.StackSchema{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(published))
+		case "GET":
+			if published == nil {
+				w.WriteHeader(http.StatusNotFound)
+				_, err := w.Write([]byte(`{"code":404,"message":"Not Found"}`))
+				assert.NoError(t, err)
+				return
+			}
+			assert.NoError(t, json.NewEncoder(w).Encode(published))
+		default:
+			t.Errorf("unexpected method %s", r.Method)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", nil)
+	stack := StackIdentifier{Owner: "owner", Project: "project", Stack: "stack"}
+
+	schema, err := client.GetStackSchema(context.Background(), stack)
+	assert.NoError(t, err)
+	assert.Nil(t, schema)
+
+	err = client.PublishStackSchema(context.Background(), stack, apitype.StackSchema{
+		Config:  map[string]apitype.StackSchemaType{"project:region": {Type: "string"}},
+		Outputs: map[string]apitype.StackSchemaType{"vpcId": {Type: "string", Description: "the VPC"}},
+	})
+	assert.NoError(t, err)
+
+	schema, err = client.GetStackSchema(context.Background(), stack)
+	assert.NoError(t, err)
+	if assert.NotNil(t, schema) {
+		assert.Equal(t, "string", schema.Config["project:region"].Type)
+		assert.Equal(t, apitype.StackSchemaType{Type: "string", Description: "the VPC"}, schema.Outputs["vpcId"])
+	}
+}
+
+func TestPolicyPackManagement(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		var body string
+		switch r.URL.Path {
+		case "/api/orgs/acme/policypacks":
+			body = `{"policyPacks":[{"name":"security","displayName":"Security","versions":[1,2],"appliedVersion":2}]}`
+		case "/api/orgs/acme/policypacks/security":
+			if r.Method == "GET" {
+				body = `{"name":"security","versions":[{"version":1,"timestamp":10},` +
+					`{"version":2,"applied":true,"timestamp":20}]}`
+			}
+		}
+		_, err := w.Write([]byte(body))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", nil)
+	ctx := context.Background()
+
+	packs, err := client.ListPolicyPacks(ctx, "acme")
+	assert.NoError(t, err)
+	assert.Equal(t, []apitype.PolicyPackSummary{
+		{Name: "security", DisplayName: "Security", Versions: []int{1, 2}, AppliedVersion: 2},
+	}, packs)
+
+	versions, err := client.ListPolicyPackVersions(ctx, "acme", "security")
+	assert.NoError(t, err)
+	assert.Equal(t, []apitype.PolicyPackVersion{
+		{Version: 1, Timestamp: 10},
+		{Version: 2, Applied: true, Timestamp: 20},
+	}, versions)
+
+	assert.NoError(t, client.DisablePolicyPack(ctx, "acme", "security"))
+	assert.NoError(t, client.RemovePolicyPack(ctx, "acme", "security", 1))
+	assert.NoError(t, client.RemovePolicyPack(ctx, "acme", "security", 0))
+
+	assert.Equal(t, []string{
+		"GET /api/orgs/acme/policypacks",
+		"GET /api/orgs/acme/policypacks/security",
+		"POST /api/orgs/acme/policypacks/security/disable",
+		"DELETE /api/orgs/acme/policypacks/security/versions/1",
+		"DELETE /api/orgs/acme/policypacks/security",
+	}, requests)
+}
+
+func TestGetPolicyPack(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path != "/api/orgs/acme/policypacks/security/versions/2" {
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte(`{"code":404,"message":"Not Found"}`))
+			assert.NoError(t, err)
+			return
+		}
+		_, err := w.Write([]byte(`{"name":"security","displayName":"Security","version":2,` +
+			`"policies":[{"name":"no-public-buckets"}],"packLocation":"https://example.com/security-2.tgz"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", nil)
+
+	pack, err := client.GetPolicyPack(context.Background(), "acme", "security", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "security", pack.Name)
+	assert.Equal(t, "Security", pack.DisplayName)
+	assert.Equal(t, 2, pack.Version)
+	assert.Equal(t, "https://example.com/security-2.tgz", pack.PackLocation)
+	if assert.Len(t, pack.Policies, 1) {
+		assert.Equal(t, "no-public-buckets", pack.Policies[0].Name)
+	}
+
+	_, err = client.GetPolicyPack(context.Background(), "acme", "security", 3)
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{
+		"/api/orgs/acme/policypacks/security/versions/2",
+		"/api/orgs/acme/policypacks/security/versions/3",
+	}, paths)
+}
+
+func TestGetPolicyViolations(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/orgs/acme/policyviolations", r.URL.Path)
+		queries = append(queries, r.URL.RawQuery)
+
+		resp := `{"violations":[{"stackName":"prod","policyName":"no-public-buckets"}],"continuationToken":"next"}`
+		if r.URL.Query().Get("continuationToken") == "next" {
+			resp = `{"violations":[{"stackName":"dev","policyName":"no-public-buckets"}]}`
+		}
+		_, err := w.Write([]byte(resp))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", nil)
+	project, level := "webshop", apitype.Mandatory
+	violations, err := client.GetPolicyViolations(context.Background(), "acme", PolicyViolationsFilter{
+		Project:          &project,
+		EnforcementLevel: &level,
+		Since:            time.Unix(1567296000, 0),
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, violations, 2) {
+		assert.Equal(t, "prod", violations[0].StackName)
+		assert.Equal(t, "dev", violations[1].StackName)
+	}
+	assert.Equal(t, []string{
+		"enforcementLevel=mandatory&project=webshop&since=1567296000",
+		"continuationToken=next&enforcementLevel=mandatory&project=webshop&since=1567296000",
+	}, queries)
+}
@@ -0,0 +1,161 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+)
+
+// EngineEventStream sends an update's engine events to the Pulumi service over a single long-lived request, rather
+// than posting each batch of events separately. Batches are written as newline-delimited JSON to the gzip-compressed,
+// chunked body of the request, which ends when the stream is closed. The service acknowledges the events only when it
+// responds to the request, so the stream keeps every batch it sends until then, to be resent by other means should the
+// stream fail.
+//
+// An EngineEventStream is not safe for concurrent use.
+type EngineEventStream struct {
+	body *io.PipeWriter
+	gzip *gzip.Writer
+	enc  *json.Encoder
+	sent []apitype.EngineEventBatch // the batches written to the stream, which the service has not yet acknowledged.
+
+	done chan struct{} // closed once the request has finished.
+	err  error         // the request's error, if any; only read after done is closed.
+}
+
+// OpenEngineEventStream opens a stream over which the given update's engine events can be sent to the Pulumi service.
+// The call is authorized with the indicated update token when the stream is opened, so the stream outlives any
+// renewals of the token.
+func (pc *Client) OpenEngineEventStream(ctx context.Context, update UpdateIdentifier,
+	token string) (*EngineEventStream, error) {
+
+	path := getUpdatePath(update, "events/stream")
+	url := fmt.Sprintf("%s%s", strings.TrimSuffix(pc.apiURL, "/"), cleanPath(path))
+
+	reader, writer := io.Pipe()
+	req, err := http.NewRequest("POST", url, reader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating new HTTP request")
+	}
+
+	requestSpan, requestContext := opentracing.StartSpanFromContext(ctx, getEndpointName("POST", path),
+		opentracing.Tag{Key: "method", Value: "POST"},
+		opentracing.Tag{Key: "path", Value: path},
+		opentracing.Tag{Key: "api", Value: pc.apiURL})
+
+	tok := updateAccessToken(token)
+	opts := pc.callOptions(httpCallOptions{})
+	req = req.WithContext(requestContext)
+	setAPIHeaders(req, requestSpan, tok)
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Content-Encoding", "gzip")
+
+	logging.V(apiRequestLogLevel).Infof("Opening Pulumi API event stream: %s", url)
+
+	gz := gzip.NewWriter(writer)
+	stream := &EngineEventStream{
+		body: writer,
+		gzip: gz,
+		enc:  json.NewEncoder(gz),
+		done: make(chan struct{}),
+	}
+	go func() {
+		defer close(stream.done)
+		defer requestSpan.Finish()
+
+		opts.beforeCall(req)
+		start := time.Now()
+		resp, err := opts.httpClient().Do(req)
+		opts.afterCall(req, resp, time.Since(start), err)
+		if err != nil {
+			err = errors.Wrapf(err, "performing HTTP request")
+		} else {
+			logging.V(apiRequestLogLevel).Infof("Pulumi API event stream response code (%s): %v", url, resp.Status)
+			requestSpan.SetTag("responseCode", resp.Status)
+			if err = apiResponseError(resp, tok); err == nil {
+				contract.IgnoreClose(resp.Body)
+			}
+		}
+		stream.err = err
+
+		// Unblock any writer still waiting on the request body, since nothing will read it now.
+		if err == nil {
+			err = errors.New("the service closed the event stream")
+		}
+		contract.IgnoreError(reader.CloseWithError(err))
+	}()
+
+	return stream, nil
+}
+
+// Send writes a batch of engine events to the stream. It blocks until the connection has accepted the batch, so a
+// caller producing events faster than they can be sent is slowed to the speed of the connection.
+func (s *EngineEventStream) Send(batch apitype.EngineEventBatch) error {
+	s.sent = append(s.sent, batch)
+	if err := s.enc.Encode(batch); err != nil {
+		return s.failed(err)
+	}
+	// gzip.Writer buffers its output; flush it so that the batch is sent now.
+	if err := s.gzip.Flush(); err != nil {
+		return s.failed(err)
+	}
+	return nil
+}
+
+// Close ends the stream and waits for the service to acknowledge the events that were sent.
+func (s *EngineEventStream) Close() error {
+	gzErr := s.gzip.Close()
+	contract.IgnoreError(s.body.Close())
+	<-s.done
+
+	if s.err != nil {
+		return s.err
+	}
+	if gzErr != nil {
+		return gzErr
+	}
+	s.sent = nil
+	return nil
+}
+
+// Unacknowledged returns the batches sent over the stream that the service has not acknowledged. Until the stream has
+// been closed successfully, this is every batch sent, including any batch whose Send failed.
+func (s *EngineEventStream) Unacknowledged() []apitype.EngineEventBatch {
+	return s.sent
+}
+
+// failed returns the error that caused a write to the stream to fail. Writes only fail once the request has ended, so
+// this waits for the request and prefers its error.
+func (s *EngineEventStream) failed(err error) error {
+	<-s.done
+	if s.err != nil {
+		return s.err
+	}
+	return err
+}
@@ -0,0 +1,139 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+)
+
+var testUpdate = UpdateIdentifier{
+	StackIdentifier: StackIdentifier{Owner: "owner", Project: "project", Stack: "stack"},
+	UpdateKind:      apitype.UpdateUpdate,
+	UpdateID:        "update",
+}
+
+func TestEngineEventStream(t *testing.T) {
+	var sequences []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/stacks/owner/project/stack/update/update/events/stream", r.URL.Path)
+		assert.Equal(t, "update-token token", r.Header.Get("Authorization"))
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+
+		body, err := gzip.NewReader(r.Body)
+		if !assert.NoError(t, err) {
+			return
+		}
+		dec := json.NewDecoder(body)
+		for dec.More() {
+			var batch apitype.EngineEventBatch
+			if !assert.NoError(t, dec.Decode(&batch)) {
+				return
+			}
+			for _, e := range batch.Events {
+				sequences = append(sequences, e.Sequence)
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", nil)
+	stream, err := client.OpenEngineEventStream(context.Background(), testUpdate, "token")
+	assert.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		batch := apitype.EngineEventBatch{Events: []apitype.EngineEvent{{Sequence: 2 * i}, {Sequence: 2*i + 1}}}
+		assert.NoError(t, stream.Send(batch))
+	}
+	assert.NoError(t, stream.Close())
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5}, sequences)
+	assert.Empty(t, stream.Unacknowledged())
+}
+
+func TestEngineEventStreamUnsupported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte(`{"code":404,"message":"Not Found"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", nil)
+	stream, err := client.OpenEngineEventStream(context.Background(), testUpdate, "token")
+	assert.NoError(t, err)
+
+	// The service may reject the stream before or after the first batch is written, but the rejection must surface
+	// by the time the stream is closed.
+	batch := apitype.EngineEventBatch{Events: []apitype.EngineEvent{{Sequence: 0}}}
+	if err = stream.Send(batch); err == nil {
+		err = stream.Close()
+	}
+	if assert.Error(t, err) {
+		errResp, ok := err.(*apitype.ErrorResponse)
+		if assert.True(t, ok) {
+			assert.Equal(t, 404, errResp.Code)
+		}
+	}
+}
+
+func TestEngineEventStreamKilled(t *testing.T) {
+	received := make(chan int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := gzip.NewReader(r.Body)
+		if !assert.NoError(t, err) {
+			return
+		}
+		var batch apitype.EngineEventBatch
+		if !assert.NoError(t, json.NewDecoder(body).Decode(&batch)) {
+			return
+		}
+		received <- batch.Events[0].Sequence
+
+		// Drop the connection without responding, as a failing proxy might.
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if assert.NoError(t, err) {
+			assert.NoError(t, conn.Close())
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", nil)
+	stream, err := client.OpenEngineEventStream(context.Background(), testUpdate, "token")
+	assert.NoError(t, err)
+
+	first := apitype.EngineEventBatch{Events: []apitype.EngineEvent{{Sequence: 0}}}
+	assert.NoError(t, stream.Send(first))
+	assert.Equal(t, 0, <-received)
+
+	// Once the connection is lost, writes fail, and every batch sent remains unacknowledged, including the one the
+	// service read before the connection was lost.
+	sent := []apitype.EngineEventBatch{first}
+	for i := 1; err == nil; i++ {
+		batch := apitype.EngineEventBatch{Events: []apitype.EngineEvent{{Sequence: i}}}
+		sent = append(sent, batch)
+		err = stream.Send(batch)
+	}
+	assert.Error(t, stream.Close())
+	assert.Equal(t, sent, stream.Unacknowledged())
+}
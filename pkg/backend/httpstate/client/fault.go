@@ -0,0 +1,242 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/util/logging"
+)
+
+// FaultInjectionEnvVar, if set, injects faults into the calls clients make to the Pulumi API. It is intended for
+// testing how the CLI copes with an unreliable service, and its format is described by ParseFaults.
+const FaultInjectionEnvVar = "PULUMI_API_FAULT_INJECTION"
+
+// Fault is a failure injected into the calls made to an API endpoint. Each attempt of a call, including each retry,
+// is subject to the fault independently.
+type Fault struct {
+	// Endpoint is the friendly name of the endpoint whose calls are affected, e.g. "api/patchCheckpoint", or "*" for
+	// all endpoints.
+	Endpoint string
+	// Latency delays affected calls by the given duration before they are sent.
+	Latency time.Duration
+	// StatusCode, if non-zero, fails affected calls with the given HTTP status code without sending them.
+	StatusCode int
+	// Reset, if true, fails affected calls as if the connection had been reset, without sending them.
+	Reset bool
+	// Probability is the chance, between 0 and 1, that the fault affects each call.
+	Probability float64
+	// Limit is the number of calls the fault affects, after which it has no effect. Zero means no limit.
+	Limit int
+}
+
+// String returns the fault in the format accepted by ParseFaults.
+func (f Fault) String() string {
+	var kind string
+	switch {
+	case f.StatusCode != 0:
+		kind = fmt.Sprintf("status=%d", f.StatusCode)
+	case f.Reset:
+		kind = "reset"
+	default:
+		kind = fmt.Sprintf("latency=%v", f.Latency)
+	}
+	s := f.Endpoint + ":" + kind
+	if f.Probability != 1 {
+		s += fmt.Sprintf(",p=%v", f.Probability)
+	}
+	if f.Limit != 0 {
+		s += fmt.Sprintf(",n=%d", f.Limit)
+	}
+	return s
+}
+
+// ParseFaults parses a semicolon-separated list of faults, each of the form `<endpoint>:<kind>[,<option>...]`.
+//
+// The endpoint is the friendly name of an API endpoint, as used by `pulumi` to log calls (e.g. "api/renewLease"), or
+// "*" for every endpoint. The kind is one of `latency=<duration>`, which delays calls; `status=<code>`, which fails
+// them with the given HTTP status code; or `reset`, which fails them as if the connection had been reset. The options
+// are `p=<probability>`, the chance that each call is affected, which defaults to 1; and `n=<count>`, the number of
+// calls that are affected before the fault stops. For example:
+//
+//     api/patchCheckpoint:status=503,p=0.5;api/renewLease:reset,n=2;*:latency=200ms
+func ParseFaults(spec string) ([]Fault, error) {
+	var faults []Fault
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		colon := strings.LastIndex(entry, ":")
+		if colon <= 0 {
+			return nil, errors.Errorf("fault '%s' must be of the form <endpoint>:<kind>", entry)
+		}
+		fault := Fault{Endpoint: entry[:colon], Probability: 1}
+
+		for i, part := range strings.Split(entry[colon+1:], ",") {
+			key, value := strings.TrimSpace(part), ""
+			if eq := strings.Index(key, "="); eq != -1 {
+				key, value = key[:eq], key[eq+1:]
+			}
+
+			var err error
+			switch {
+			case i == 0 && key == "latency":
+				fault.Latency, err = time.ParseDuration(value)
+				if err == nil && fault.Latency <= 0 {
+					err = errors.New("must be positive")
+				}
+			case i == 0 && key == "status":
+				fault.StatusCode, err = strconv.Atoi(value)
+				if err == nil && (fault.StatusCode < 400 || fault.StatusCode > 599) {
+					err = errors.New("must be a 4xx or 5xx HTTP status code")
+				}
+			case i == 0 && key == "reset" && value == "":
+				fault.Reset = true
+			case i == 0:
+				return nil, errors.Errorf("fault '%s' has unknown kind '%s'; must be one of latency, status, or reset",
+					entry, part)
+			case key == "p":
+				fault.Probability, err = strconv.ParseFloat(value, 64)
+				if err == nil && (fault.Probability <= 0 || fault.Probability > 1) {
+					err = errors.New("must be greater than 0 and at most 1")
+				}
+			case key == "n":
+				fault.Limit, err = strconv.Atoi(value)
+				if err == nil && fault.Limit < 1 {
+					err = errors.New("must be a positive integer")
+				}
+			default:
+				return nil, errors.Errorf("fault '%s' has unknown option '%s'; must be one of p or n", entry, part)
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "fault '%s' has invalid %s '%s'", entry, key, value)
+			}
+		}
+
+		faults = append(faults, fault)
+	}
+	return faults, nil
+}
+
+// FaultInjector injects faults into the calls a Client makes to the Pulumi API. A FaultInjector is safe for
+// concurrent use.
+type FaultInjector struct {
+	lock   sync.Mutex
+	faults []Fault
+	counts []int // the number of calls each fault has affected.
+}
+
+// NewFaultInjector returns a fault injector that injects the given faults.
+func NewFaultInjector(faults []Fault) *FaultInjector {
+	return &FaultInjector{faults: faults, counts: make([]int, len(faults))}
+}
+
+// FaultInjectorFromEnv returns a fault injector for the faults in the PULUMI_API_FAULT_INJECTION environment
+// variable, or nil if it is not set.
+func FaultInjectorFromEnv() (*FaultInjector, error) {
+	spec := os.Getenv(FaultInjectionEnvVar)
+	if spec == "" {
+		return nil, nil
+	}
+	faults, err := ParseFaults(spec)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", FaultInjectionEnvVar)
+	}
+	return NewFaultInjector(faults), nil
+}
+
+// affecting returns the faults that affect the next attempt of a call to the given endpoint, counting them against
+// their limits.
+func (fi *FaultInjector) affecting(endpoint string) []Fault {
+	fi.lock.Lock()
+	defer fi.lock.Unlock()
+
+	var result []Fault
+	for i, f := range fi.faults {
+		if f.Endpoint != "*" && f.Endpoint != endpoint {
+			continue
+		}
+		if f.Limit != 0 && fi.counts[i] >= f.Limit {
+			continue
+		}
+		if f.Probability < 1 && rand.Float64() >= f.Probability { // nolint: gosec
+			continue
+		}
+		fi.counts[i]++
+		result = append(result, f)
+	}
+	return result
+}
+
+// faultTransport is an http.RoundTripper that injects faults into the requests it sends.
+type faultTransport struct {
+	injector *FaultInjector
+	base     http.RoundTripper
+}
+
+func (t *faultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := getEndpointName(req.Method, req.URL.Path)
+	for _, f := range t.injector.affecting(endpoint) {
+		logging.V(apiRequestLogLevel).Infof("Injecting fault into %s %s: %v", req.Method, req.URL, f)
+
+		if f.Latency > 0 {
+			select {
+			case <-time.After(f.Latency):
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			}
+		}
+
+		switch {
+		case f.StatusCode != 0:
+			if req.Body != nil {
+				_ = req.Body.Close()
+			}
+			body := fmt.Sprintf(`{"code":%d,"message":"fault injected by %s"}`, f.StatusCode, FaultInjectionEnvVar)
+			return &http.Response{
+				Status:        fmt.Sprintf("%d %s", f.StatusCode, http.StatusText(f.StatusCode)),
+				StatusCode:    f.StatusCode,
+				Proto:         "HTTP/1.1",
+				ProtoMajor:    1,
+				ProtoMinor:    1,
+				Header:        http.Header{"Content-Type": []string{"application/json"}},
+				Body:          ioutil.NopCloser(bytes.NewReader([]byte(body))),
+				ContentLength: int64(len(body)),
+				Request:       req,
+			}, nil
+		case f.Reset:
+			if req.Body != nil {
+				_ = req.Body.Close()
+			}
+			return nil, &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+		}
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,95 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFaults(t *testing.T) {
+	faults, err := ParseFaults("api/patchCheckpoint:status=503,p=0.5; api/renewLease:reset,n=2;*:latency=200ms")
+	assert.NoError(t, err)
+	assert.Equal(t, []Fault{
+		{Endpoint: "api/patchCheckpoint", StatusCode: 503, Probability: 0.5},
+		{Endpoint: "api/renewLease", Reset: true, Probability: 1, Limit: 2},
+		{Endpoint: "*", Latency: 200 * time.Millisecond, Probability: 1},
+	}, faults)
+	for _, f := range faults {
+		reparsed, err := ParseFaults(f.String())
+		assert.NoError(t, err)
+		assert.Equal(t, []Fault{f}, reparsed)
+	}
+
+	for _, spec := range []string{
+		"api/getStack",
+		"api/getStack:crash",
+		"api/getStack:status=200",
+		"api/getStack:latency=soon",
+		"api/getStack:reset,p=2",
+		"api/getStack:reset,n=0",
+		"api/getStack:reset,q=1",
+	} {
+		_, err := ParseFaults(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestFaultInjection(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, err := w.Write([]byte(`{"githubLogin":"user"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	newClient := func(spec string) *Client {
+		faults, err := ParseFaults(spec)
+		assert.NoError(t, err)
+		client := NewClient(server.URL, "", nil)
+		client.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, Delay: time.Millisecond, Backoff: 1,
+			RetryableStatusCodes: []int{http.StatusServiceUnavailable}})
+		client.SetFaultInjector(NewFaultInjector(faults))
+		return client
+	}
+
+	// Faults that stop before the retries are exhausted are recovered from without reaching the service.
+	client := newClient("api/getCurrentUser:status=503,n=1;*:reset,n=2")
+	name, err := client.GetPulumiAccountName(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "user", name)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// Persistent faults surface as errors once the retries are exhausted.
+	client = newClient("*:status=503")
+	_, err = client.GetPulumiAccountName(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// Faults only affect the endpoints they name.
+	client = newClient("api/getStack:reset;api/getCurrentUser:latency=10ms")
+	start := time.Now()
+	_, err = client.GetPulumiAccountName(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, time.Since(start) >= 10*time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,230 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+)
+
+const (
+	// RetryMaxAttemptsEnvVar overrides the maximum number of attempts made for each API call.
+	RetryMaxAttemptsEnvVar = "PULUMI_API_RETRY_MAX_ATTEMPTS"
+	// RetryDelayEnvVar overrides the delay before the first retry of an API call, e.g. "500ms".
+	RetryDelayEnvVar = "PULUMI_API_RETRY_DELAY"
+	// RetryBackoffEnvVar overrides the multiplier applied to the delay after each retry.
+	RetryBackoffEnvVar = "PULUMI_API_RETRY_BACKOFF"
+	// RetryMaxDelayEnvVar overrides the longest delay between two attempts of an API call, e.g. "30s".
+	RetryMaxDelayEnvVar = "PULUMI_API_RETRY_MAX_DELAY"
+	// RetryJitterEnvVar overrides the fraction of each delay that is randomized, between 0 and 1.
+	RetryJitterEnvVar = "PULUMI_API_RETRY_JITTER"
+	// RetryStatusCodesEnvVar overrides the comma-separated list of HTTP status codes that are retried.
+	RetryStatusCodesEnvVar = "PULUMI_API_RETRY_STATUS_CODES"
+)
+
+// RetryPolicy controls how calls to the Pulumi API are retried when the service cannot be reached or responds with a
+// retryable status code.
+//
+// GET requests, and calls that are known to be safe to repeat, are retried after transport errors and any of the
+// retryable status codes. Other calls are only retried after a 429, since the service rejected them without acting on
+// them.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts made for each call, including the first. Values less than two
+	// disable retries.
+	MaxAttempts int
+	// Delay is the delay before the first retry.
+	Delay time.Duration
+	// Backoff is the multiplier applied to the delay after each retry.
+	Backoff float64
+	// MaxDelay is the longest delay between two attempts.
+	MaxDelay time.Duration
+	// Jitter is the fraction of each delay, between 0 and 1, that is randomized so that many clients retrying at once
+	// do not do so in lockstep.
+	Jitter float64
+	// RetryableStatusCodes are the HTTP status codes that cause a call to be retried.
+	RetryableStatusCodes []int
+}
+
+// DefaultRetryPolicy returns the retry policy used by clients unless it is overridden.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 5,
+		Delay:       150 * time.Millisecond,
+		Backoff:     2,
+		MaxDelay:    10 * time.Second,
+		Jitter:      0.2,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// RetryPolicyFromEnv returns the default retry policy, overridden by any of the PULUMI_API_RETRY_* environment
+// variables that are set.
+func RetryPolicyFromEnv() (RetryPolicy, error) {
+	policy := DefaultRetryPolicy()
+
+	if v := os.Getenv(RetryMaxAttemptsEnvVar); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return RetryPolicy{}, errors.Errorf("%s must be a positive integer, not '%s'", RetryMaxAttemptsEnvVar, v)
+		}
+		policy.MaxAttempts = n
+	}
+	if v := os.Getenv(RetryDelayEnvVar); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return RetryPolicy{}, errors.Errorf("%s must be a duration such as '500ms', not '%s'", RetryDelayEnvVar, v)
+		}
+		policy.Delay = d
+	}
+	if v := os.Getenv(RetryBackoffEnvVar); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 1 {
+			return RetryPolicy{}, errors.Errorf("%s must be a number no less than 1, not '%s'", RetryBackoffEnvVar, v)
+		}
+		policy.Backoff = f
+	}
+	if v := os.Getenv(RetryMaxDelayEnvVar); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return RetryPolicy{}, errors.Errorf("%s must be a duration such as '30s', not '%s'", RetryMaxDelayEnvVar, v)
+		}
+		policy.MaxDelay = d
+	}
+	if v := os.Getenv(RetryJitterEnvVar); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 || f > 1 {
+			return RetryPolicy{}, errors.Errorf("%s must be a number between 0 and 1, not '%s'", RetryJitterEnvVar, v)
+		}
+		policy.Jitter = f
+	}
+	if v := os.Getenv(RetryStatusCodesEnvVar); v != "" {
+		var codes []int
+		for _, s := range strings.Split(v, ",") {
+			code, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil || code < 100 || code > 599 {
+				return RetryPolicy{}, errors.Errorf("%s must be a comma-separated list of HTTP status codes, not '%s'",
+					RetryStatusCodesEnvVar, v)
+			}
+			codes = append(codes, code)
+		}
+		policy.RetryableStatusCodes = codes
+	}
+
+	return policy, nil
+}
+
+// isRetryableStatus returns true if the policy retries responses with the given status code.
+func (p RetryPolicy) isRetryableStatus(code int) bool {
+	for _, c := range p.RetryableStatusCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// delay returns the delay before the given retry, counting from zero, with jitter applied.
+func (p RetryPolicy) delay(retry int) time.Duration {
+	d := float64(p.Delay)
+	for i := 0; i < retry && (p.MaxDelay <= 0 || d < float64(p.MaxDelay)); i++ {
+		d *= p.Backoff
+	}
+	if p.MaxDelay > 0 && d > float64(p.MaxDelay) {
+		d = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1) // nolint: gosec
+	}
+	return time.Duration(d)
+}
+
+// retryAfter returns the delay requested by a response's Retry-After header, if it has one in seconds.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// doWithRetryPolicy calls client.Do, retrying as directed by the given policy. If retryAll is false, only 429
+// responses are retried; otherwise transport errors and all of the policy's retryable status codes are. Retries stop
+// early if the request's context is canceled.
+func doWithRetryPolicy(req *http.Request, client *http.Client, policy RetryPolicy,
+	retryAll bool) (*http.Response, error) {
+
+	contract.Assertf(req.ContentLength == 0 || req.GetBody != nil,
+		"Retryable request must have no body or rewindable body")
+
+	for try := 0; ; try++ {
+		if try > 0 && req.GetBody != nil {
+			// Reset request body, if present, for retries.
+			rc, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = rc
+		}
+
+		resp, err := client.Do(req)
+		if try >= policy.MaxAttempts-1 {
+			return resp, err
+		}
+
+		switch {
+		case err != nil:
+			if !retryAll {
+				return nil, err
+			}
+		case resp.StatusCode == http.StatusTooManyRequests && policy.isRetryableStatus(resp.StatusCode):
+		case retryAll && policy.isRetryableStatus(resp.StatusCode):
+		default:
+			return resp, nil
+		}
+
+		delay := policy.delay(try)
+		if err == nil {
+			if after, ok := retryAfter(resp); ok && after > delay {
+				delay = after
+			}
+			// Close the response body, since our caller can't.
+			contract.IgnoreError(resp.Body.Close())
+			logging.V(apiRequestLogLevel).Infof("Retrying %s %s after %v: %s", req.Method, req.URL, delay, resp.Status)
+		} else {
+			logging.V(apiRequestLogLevel).Infof("Retrying %s %s after %v: %v", req.Method, req.URL, delay, err)
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
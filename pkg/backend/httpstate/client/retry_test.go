@@ -0,0 +1,149 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+)
+
+func testRetryPolicy() RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.MaxAttempts = 3
+	policy.Delay = time.Millisecond
+	policy.MaxDelay = time.Millisecond
+	return policy
+}
+
+func TestRetryPolicyRetriesGets(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, err := w.Write([]byte(`{"name":"devs"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", nil)
+	client.SetRetryPolicy(testRetryPolicy())
+
+	team, err := client.GetTeam(context.Background(), "acme", "devs")
+	assert.NoError(t, err)
+	assert.Equal(t, "devs", team.Name)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestRetryPolicyGivesUp(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", nil)
+	client.SetRetryPolicy(testRetryPolicy())
+
+	_, err := client.GetTeam(context.Background(), "acme", "devs")
+	assert.Error(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestRetryPolicyOnlyRetriesOtherMethodsAfter429(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		bodies = append(bodies, string(body))
+
+		switch len(bodies) {
+		case 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			_, err = w.Write([]byte(`{"name":"devs"}`))
+			assert.NoError(t, err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", nil)
+	client.SetRetryPolicy(testRetryPolicy())
+
+	// The 429 is retried with the same body, but the 500 is not, since the service may have acted on the request.
+	_, err := client.CreateTeam(context.Background(), "acme", apitype.CreateTeamRequest{Name: "devs"})
+	assert.Error(t, err)
+	assert.Len(t, bodies, 2)
+	assert.Equal(t, bodies[0], bodies[1])
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{Delay: time.Second, Backoff: 2, MaxDelay: 5 * time.Second}
+	assert.Equal(t, time.Second, policy.delay(0))
+	assert.Equal(t, 2*time.Second, policy.delay(1))
+	assert.Equal(t, 4*time.Second, policy.delay(2))
+	assert.Equal(t, 5*time.Second, policy.delay(3))
+	assert.Equal(t, 5*time.Second, policy.delay(100))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := policy.delay(0)
+		assert.True(t, d >= 500*time.Millisecond && d <= 1500*time.Millisecond, "delay %v out of range", d)
+	}
+}
+
+func TestRetryPolicyFromEnv(t *testing.T) {
+	vars := map[string]string{
+		RetryMaxAttemptsEnvVar: "8",
+		RetryDelayEnvVar:       "1s",
+		RetryBackoffEnvVar:     "3",
+		RetryMaxDelayEnvVar:    "1m",
+		RetryJitterEnvVar:      "0",
+		RetryStatusCodesEnvVar: "429, 503",
+	}
+	for k, v := range vars {
+		assert.NoError(t, os.Setenv(k, v))
+		defer func(k string) { assert.NoError(t, os.Unsetenv(k)) }(k)
+	}
+
+	policy, err := RetryPolicyFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, RetryPolicy{
+		MaxAttempts:          8,
+		Delay:                time.Second,
+		Backoff:              3,
+		MaxDelay:             time.Minute,
+		Jitter:               0,
+		RetryableStatusCodes: []int{429, 503},
+	}, policy)
+
+	assert.NoError(t, os.Setenv(RetryStatusCodesEnvVar, "429,teapot"))
+	_, err = RetryPolicyFromEnv()
+	assert.Error(t, err)
+}
@@ -0,0 +1,113 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Hooks are callbacks that a Client invokes around each call it makes to the Pulumi API, e.g. for instrumentation.
+// Calls may be made concurrently, so hooks must be safe for concurrent use.
+type Hooks struct {
+	// OnRequest, if non-nil, is called before a request is sent.
+	OnRequest func(req *http.Request)
+	// OnResponse, if non-nil, is called once a request has completed, after any retries. The latency includes the
+	// time spent retrying. If err is non-nil, resp is nil.
+	OnResponse func(req *http.Request, resp *http.Response, latency time.Duration, err error)
+}
+
+// EndpointStats summarizes the calls a Client has made to a single API endpoint.
+type EndpointStats struct {
+	Endpoint     string        // the friendly name of the endpoint, e.g. "api/getStack".
+	Calls        int           // the number of calls made.
+	Failures     int           // the number of calls that failed or returned a 4xx or 5xx response.
+	TotalLatency time.Duration // the sum of the calls' latencies.
+	MaxLatency   time.Duration // the latency of the slowest call.
+}
+
+// MeanLatency returns the average latency of the calls to the endpoint.
+func (s EndpointStats) MeanLatency() time.Duration {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.TotalLatency / time.Duration(s.Calls)
+}
+
+// callStats accumulates statistics about the calls made by a Client, by endpoint.
+type callStats struct {
+	lock      sync.Mutex
+	endpoints map[string]*EndpointStats
+}
+
+func newCallStats() *callStats {
+	return &callStats{endpoints: make(map[string]*EndpointStats)}
+}
+
+// record adds a call to the given endpoint to the statistics.
+func (s *callStats) record(endpoint string, latency time.Duration, failed bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	stats, ok := s.endpoints[endpoint]
+	if !ok {
+		stats = &EndpointStats{Endpoint: endpoint}
+		s.endpoints[endpoint] = stats
+	}
+	stats.Calls++
+	if failed {
+		stats.Failures++
+	}
+	stats.TotalLatency += latency
+	if latency > stats.MaxLatency {
+		stats.MaxLatency = latency
+	}
+}
+
+// snapshot returns a copy of the statistics, sorted by endpoint.
+func (s *callStats) snapshot() []EndpointStats {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	result := make([]EndpointStats, 0, len(s.endpoints))
+	for _, stats := range s.endpoints {
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Endpoint < result[j].Endpoint
+	})
+	return result
+}
+
+// beforeCall invokes the OnRequest hook, if any, for the given request.
+func (opts httpCallOptions) beforeCall(req *http.Request) {
+	if opts.Hooks.OnRequest != nil {
+		opts.Hooks.OnRequest(req)
+	}
+}
+
+// afterCall records the outcome of the given request in the call statistics, if any, and invokes the OnResponse
+// hook, if any.
+func (opts httpCallOptions) afterCall(req *http.Request, resp *http.Response, latency time.Duration, err error) {
+	if opts.Stats != nil {
+		failed := err != nil || resp.StatusCode >= 400
+		opts.Stats.record(getEndpointName(req.Method, req.URL.Path), latency, failed)
+	}
+	if opts.Hooks.OnResponse != nil {
+		opts.Hooks.OnResponse(req, resp, latency, err)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientHooksAndStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/user" {
+			_, err := w.Write([]byte(`{"githubLogin":"user"}`))
+			assert.NoError(t, err)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	var requests, responses, failures int32
+	client := NewClient(server.URL, "", nil)
+	client.SetHooks(Hooks{
+		OnRequest: func(req *http.Request) {
+			atomic.AddInt32(&requests, 1)
+		},
+		OnResponse: func(req *http.Request, resp *http.Response, latency time.Duration, err error) {
+			atomic.AddInt32(&responses, 1)
+			if err != nil || resp.StatusCode >= 400 {
+				atomic.AddInt32(&failures, 1)
+			}
+		},
+	})
+
+	// The account name is only fetched once, however many callers ask for it at once.
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name, err := client.GetPulumiAccountName(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, "user", name)
+		}()
+	}
+	wg.Wait()
+
+	_, err := client.GetStack(context.Background(), StackIdentifier{Owner: "owner", Project: "project", Stack: "a"})
+	assert.Error(t, err)
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&responses))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&failures))
+
+	stats := client.Stats()
+	if assert.Len(t, stats, 2) {
+		assert.Equal(t, "api/getCurrentUser", stats[0].Endpoint)
+		assert.Equal(t, 1, stats[0].Calls)
+		assert.Equal(t, 0, stats[0].Failures)
+		assert.True(t, stats[0].MaxLatency >= stats[0].MeanLatency())
+		assert.Equal(t, "api/getStack", stats[1].Endpoint)
+		assert.Equal(t, 1, stats[1].Calls)
+		assert.Equal(t, 1, stats[1].Failures)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// CABundleEnvVar is the path of a PEM file of the certificates of certificate authorities to trust, in addition to
+	// the system's, when calling the Pulumi API. It is needed by self-hosted services whose certificates are issued by
+	// a private PKI.
+	CABundleEnvVar = "PULUMI_CA_BUNDLE"
+	// ClientCertEnvVar and ClientKeyEnvVar are the paths of PEM files of a certificate and its private key, with which
+	// the CLI authenticates itself to services that require mutual TLS.
+	ClientCertEnvVar = "PULUMI_CLIENT_CERT"
+	ClientKeyEnvVar  = "PULUMI_CLIENT_KEY"
+)
+
+// TransportOptions controls how a client connects to the Pulumi API. Proxies are always taken from the HTTPS_PROXY,
+// HTTP_PROXY, and NO_PROXY environment variables.
+type TransportOptions struct {
+	// CABundle is the path of a PEM file of additional certificate authorities to trust.
+	CABundle string
+	// ClientCert and ClientKey are the paths of the PEM files of a client certificate and its key, if any.
+	ClientCert string
+	ClientKey  string
+}
+
+// TransportOptionsFromEnv returns the transport options set by the PULUMI_CA_BUNDLE, PULUMI_CLIENT_CERT, and
+// PULUMI_CLIENT_KEY environment variables.
+func TransportOptionsFromEnv() TransportOptions {
+	return TransportOptions{
+		CABundle:   os.Getenv(CABundleEnvVar),
+		ClientCert: os.Getenv(ClientCertEnvVar),
+		ClientKey:  os.Getenv(ClientKeyEnvVar),
+	}
+}
+
+// IsDefault returns true if the options do not change how connections are made.
+func (opts TransportOptions) IsDefault() bool {
+	return opts == TransportOptions{}
+}
+
+// NewTransport returns an HTTP transport that connects to the Pulumi API with the given options. Like Go's default
+// transport, it sends requests through the proxy given by the environment, if any.
+func NewTransport(opts TransportOptions) (*http.Transport, error) {
+	tlsConfig := &tls.Config{}
+
+	if opts.CABundle != "" {
+		pem, err := ioutil.ReadFile(opts.CABundle)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading CA bundle")
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("CA bundle %s contains no PEM certificates", opts.CABundle)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	switch {
+	case opts.ClientCert != "" && opts.ClientKey != "":
+		cert, err := tls.LoadX509KeyPair(opts.ClientCert, opts.ClientKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "loading client certificate")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	case opts.ClientCert != "" || opts.ClientKey != "":
+		return nil, errors.Errorf("a client certificate requires both %s and %s", ClientCertEnvVar, ClientKeyEnvVar)
+	}
+
+	// These settings mirror those of http.DefaultTransport.
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:       tlsConfig,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}, nil
+}
+
+// httpClient returns the HTTP client with which to make the call, which uses the call's transport and injects its
+// faults, if any.
+func (opts httpCallOptions) httpClient() *http.Client {
+	transport := opts.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if opts.Faults != nil {
+		transport = &faultTransport{injector: opts.Faults, base: transport}
+	}
+	if transport == http.DefaultTransport {
+		return http.DefaultClient
+	}
+	return &http.Client{Transport: transport}
+}
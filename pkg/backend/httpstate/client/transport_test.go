@@ -0,0 +1,73 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransportCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"githubLogin":"user"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "transport")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	bundle := filepath.Join(dir, "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	assert.NoError(t, ioutil.WriteFile(bundle, cert, 0600))
+
+	// Without the server's certificate authority, calls fail; with it, they succeed.
+	client := NewClient(server.URL, "", nil)
+	client.SetRetryPolicy(RetryPolicy{MaxAttempts: 1})
+	_, err = client.GetPulumiAccountName(context.Background())
+	assert.Error(t, err)
+
+	transport, err := NewTransport(TransportOptions{CABundle: bundle})
+	if !assert.NoError(t, err) {
+		return
+	}
+	client.SetTransport(transport)
+	name, err := client.GetPulumiAccountName(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "user", name)
+
+	// Bundles must exist and hold certificates, and client certificates need both halves.
+	notPEM := filepath.Join(dir, "not.pem")
+	assert.NoError(t, ioutil.WriteFile(notPEM, []byte("hello"), 0600))
+	for _, opts := range []TransportOptions{
+		{CABundle: filepath.Join(dir, "missing.pem")},
+		{CABundle: notPEM},
+		{ClientCert: bundle},
+		{ClientCert: bundle, ClientKey: notPEM},
+	} {
+		_, err = NewTransport(opts)
+		assert.Error(t, err, "%+v", opts)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpstate
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+)
+
+// FreezeError is returned by CheckFreezeWindows when a stack is updated during one of its freeze windows without a
+// justification for breaking the freeze.
+type FreezeError struct {
+	Stack  backend.StackReference
+	Window apitype.FreezeWindow
+}
+
+func (e FreezeError) Error() string {
+	msg := fmt.Sprintf("updates to stack %s are frozen until %s", e.Stack,
+		time.Unix(e.Window.End, 0).Format(time.RFC1123))
+	if e.Window.Reason != "" {
+		msg += ": " + e.Window.Reason
+	}
+	return msg
+}
+
+// CheckFreezeWindows refuses to update the stack during any of its freeze windows, unless the freeze is broken with a
+// justification, in which case the justification is recorded in the update's metadata. Only stacks managed by the
+// Pulumi service have freeze windows.
+func CheckFreezeWindows(ctx context.Context, s backend.Stack, m *backend.UpdateMetadata, justification string) error {
+	cloudStack, ok := s.(Stack)
+	if !ok {
+		return nil
+	}
+
+	client := cloudStack.Backend().(Backend).Client()
+	windows, err := client.ListFreezeWindows(ctx, cloudStack.StackIdentifier())
+	if err != nil {
+		// Services that predate freeze windows have none to enforce.
+		if errResp, ok := err.(*apitype.ErrorResponse); ok && errResp.Code == http.StatusNotFound {
+			return nil
+		}
+		return errors.Wrap(err, "getting freeze windows")
+	}
+
+	window, ok := activeFreezeWindow(windows, time.Now())
+	if !ok {
+		return nil
+	}
+	if justification == "" {
+		return FreezeError{Stack: s.Ref(), Window: window}
+	}
+
+	m.Environment[backend.FreezeBroken] = "true"
+	m.Environment[backend.FreezeJustification] = justification
+	return nil
+}
+
+// activeFreezeWindow returns the freeze window, if any, that is in effect at the given time. If several are, the one
+// that ends last is returned.
+func activeFreezeWindow(windows []apitype.FreezeWindow, now time.Time) (apitype.FreezeWindow, bool) {
+	var active apitype.FreezeWindow
+	var found bool
+	for _, w := range windows {
+		if now.Unix() < w.Start || now.Unix() >= w.End {
+			continue
+		}
+		if !found || w.End > active.End {
+			active, found = w, true
+		}
+	}
+	return active, found
+}
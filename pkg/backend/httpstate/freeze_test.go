@@ -0,0 +1,91 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpstate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/httpstate/client"
+)
+
+func TestActiveFreezeWindow(t *testing.T) {
+	windows := []apitype.FreezeWindow{
+		{Start: 100, End: 200, Reason: "release"},
+		{Start: 150, End: 300, Reason: "holidays", OrgWide: true},
+	}
+
+	_, ok := activeFreezeWindow(windows, time.Unix(99, 0))
+	assert.False(t, ok)
+
+	w, ok := activeFreezeWindow(windows, time.Unix(100, 0))
+	assert.True(t, ok)
+	assert.Equal(t, "release", w.Reason)
+
+	// When windows overlap, the one that ends last is in effect.
+	w, ok = activeFreezeWindow(windows, time.Unix(199, 0))
+	assert.True(t, ok)
+	assert.Equal(t, "holidays", w.Reason)
+
+	// Windows end at their end time.
+	_, ok = activeFreezeWindow(windows, time.Unix(300, 0))
+	assert.False(t, ok)
+
+	_, ok = activeFreezeWindow(nil, time.Unix(150, 0))
+	assert.False(t, ok)
+}
+
+func TestCheckFreezeWindows(t *testing.T) {
+	now := time.Now().Unix()
+	windows := []apitype.FreezeWindow{{Start: now - 60, End: now + 60, Reason: "release"}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/stacks/acme/web/prod/freeze-windows" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+			return
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(apitype.ListFreezeWindowsResponse{FreezeWindows: windows}))
+	}))
+	defer server.Close()
+
+	b := &cloudBackend{client: client.NewClient(server.URL, "", nil), url: server.URL}
+	s := newStack(apitype.Stack{OrgName: "acme", ProjectName: "web", StackName: "prod"}, b)
+
+	// Without a justification, the stack may not be updated during a freeze.
+	m := &backend.UpdateMetadata{Environment: map[string]string{}}
+	err := CheckFreezeWindows(context.Background(), s, m, "")
+	if assert.IsType(t, FreezeError{}, err) {
+		assert.Equal(t, "release", err.(FreezeError).Window.Reason)
+	}
+	assert.Empty(t, m.Environment)
+
+	// A justification breaks the freeze, and is recorded with the update.
+	assert.NoError(t, CheckFreezeWindows(context.Background(), s, m, "hotfix"))
+	assert.Equal(t, "true", m.Environment[backend.FreezeBroken])
+	assert.Equal(t, "hotfix", m.Environment[backend.FreezeJustification])
+
+	// Outside of a freeze, no justification is needed.
+	windows = nil
+	m = &backend.UpdateMetadata{Environment: map[string]string{}}
+	assert.NoError(t, CheckFreezeWindows(context.Background(), s, m, ""))
+	assert.Empty(t, m.Environment)
+}
@@ -164,6 +164,35 @@ func (pack *cloudPolicyPack) Apply(ctx context.Context, op backend.ApplyOperatio
 	return pack.cl.ApplyPolicyPack(ctx, pack.ref.orgName, string(pack.ref.name), op.Version)
 }
 
+func (pack *cloudPolicyPack) Disable(ctx context.Context) error {
+	return pack.cl.DisablePolicyPack(ctx, pack.ref.orgName, string(pack.ref.name))
+}
+
+func (pack *cloudPolicyPack) Remove(ctx context.Context, version int) error {
+	return pack.cl.RemovePolicyPack(ctx, pack.ref.orgName, string(pack.ref.name), version)
+}
+
+func (pack *cloudPolicyPack) Versions(ctx context.Context) ([]apitype.PolicyPackVersion, error) {
+	return pack.cl.ListPolicyPackVersions(ctx, pack.ref.orgName, string(pack.ref.name))
+}
+
+func (pack *cloudPolicyPack) Required(ctx context.Context, version int) (engine.RequiredPolicy, error) {
+	resp, err := pack.cl.GetPolicyPack(ctx, pack.ref.orgName, string(pack.ref.name), version)
+	if err != nil {
+		return nil, err
+	}
+	if resp.PackLocation == "" {
+		return nil, errors.Errorf("policy pack %s version %d has no download location", pack.ref, version)
+	}
+
+	return newCloudRequiredPolicy(pack.cl, apitype.RequiredPolicy{
+		Name:         resp.Name,
+		Version:      resp.Version,
+		DisplayName:  resp.DisplayName,
+		PackLocation: resp.PackLocation,
+	}), nil
+}
+
 const npmPackageDir = "package"
 
 func installRequiredPolicy(finalDir string, tarball []byte) error {
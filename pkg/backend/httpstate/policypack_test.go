@@ -0,0 +1,68 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpstate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/backend/httpstate/client"
+)
+
+func TestCloudPolicyPackRequired(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body string
+		switch r.URL.Path {
+		case "/api/orgs/acme/policypacks/security/versions/2":
+			body = `{"name":"security","displayName":"Security","version":2,` +
+				`"packLocation":"https://example.com/security-2.tgz"}`
+		case "/api/orgs/acme/policypacks/security/versions/3":
+			// A version without a download location cannot be installed.
+			body = `{"name":"security","displayName":"Security","version":3}`
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			body = `{"code":404,"message":"Not Found"}`
+		}
+		_, err := w.Write([]byte(body))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	pack := &cloudPolicyPack{
+		ref: newCloudBackendPolicyPackReference("acme", "security"),
+		cl:  client.NewClient(server.URL, "", nil),
+	}
+
+	policy, err := pack.Required(context.Background(), 2)
+	assert.NoError(t, err)
+	if assert.NotNil(t, policy) {
+		assert.Equal(t, "security", policy.Name())
+		assert.Equal(t, "2", policy.Version())
+		assert.Equal(t, "https://example.com/security-2.tgz",
+			policy.(*cloudRequiredPolicy).RequiredPolicy.PackLocation)
+	}
+
+	_, err = pack.Required(context.Background(), 3)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "policy pack acme/security version 3 has no download location")
+	}
+
+	_, err = pack.Required(context.Background(), 4)
+	assert.Error(t, err)
+}
@@ -16,8 +16,10 @@ package httpstate
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/pkg/errors"
+	"github.com/pulumi/pulumi/pkg/apitype"
 	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/backend/httpstate/client"
 	"github.com/pulumi/pulumi/pkg/resource/deploy"
@@ -28,6 +30,7 @@ import (
 // cloudSnapshotPersister persists snapshots to the Pulumi service.
 type cloudSnapshotPersister struct {
 	context     context.Context         // The context to use for client requests.
+	stackRef    backend.StackReference  // The stack whose snapshots are persisted.
 	update      client.UpdateIdentifier // The UpdateIdentifier for this update sequence.
 	tokenSource *tokenSource            // A token source for interacting with the service.
 	backend     *cloudBackend           // A backend for communicating with the service
@@ -47,15 +50,34 @@ func (persister *cloudSnapshotPersister) Save(snapshot *deploy.Snapshot) error {
 	if err != nil {
 		return errors.Wrap(err, "serializing deployment")
 	}
-	return persister.backend.client.PatchUpdateCheckpoint(persister.context, persister.update, deployment, token)
+	if !persister.backend.encryption.Encrypts(persister.stackRef, persister.sm) {
+		return persister.backend.client.PatchUpdateCheckpoint(persister.context, persister.update, deployment, token)
+	}
+
+	// The stack's state is encrypted at rest, so encrypt the deployment with the key of its secrets manager.
+	rawDeployment, err := json.Marshal(deployment)
+	if err != nil {
+		return err
+	}
+	enc, err := stack.NewStateEncrypter(persister.sm)
+	if err != nil {
+		return err
+	}
+	encrypted, err := stack.EncryptDeployment(&apitype.UntypedDeployment{Version: 3, Deployment: rawDeployment}, enc)
+	if err != nil {
+		return err
+	}
+	return persister.backend.client.PatchUpdateEncryptedCheckpoint(
+		persister.context, persister.update, encrypted.Encrypted, token)
 }
 
 var _ backend.SnapshotPersister = (*cloudSnapshotPersister)(nil)
 
-func (cb *cloudBackend) newSnapshotPersister(ctx context.Context, update client.UpdateIdentifier,
-	tokenSource *tokenSource, sm secrets.Manager) *cloudSnapshotPersister {
+func (cb *cloudBackend) newSnapshotPersister(ctx context.Context, stackRef backend.StackReference,
+	update client.UpdateIdentifier, tokenSource *tokenSource, sm secrets.Manager) *cloudSnapshotPersister {
 	return &cloudSnapshotPersister{
 		context:     ctx,
+		stackRef:    stackRef,
 		update:      update,
 		tokenSource: tokenSource,
 		backend:     cb,
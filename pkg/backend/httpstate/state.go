@@ -17,10 +17,12 @@ package httpstate
 import (
 	"context"
 	"fmt"
+	"os"
 	"sync"
 	"time"
 
 	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 	"github.com/pulumi/pulumi/pkg/util/contract"
 	"github.com/pulumi/pulumi/pkg/util/logging"
 
@@ -30,9 +32,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/backend/display"
 	"github.com/pulumi/pulumi/pkg/backend/httpstate/client"
 	"github.com/pulumi/pulumi/pkg/engine"
-	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/resource/deploy"
-	"github.com/pulumi/pulumi/pkg/resource/plugin"
 	"github.com/pulumi/pulumi/pkg/resource/stack"
 	"github.com/pulumi/pulumi/pkg/workspace"
 )
@@ -158,20 +158,14 @@ func (u *cloudUpdate) Complete(status apitype.UpdateStatus) error {
 	return u.backend.client.CompleteUpdate(u.context, u.update, status, token)
 }
 
-// recordEngineEvents will record the events with the Pulumi Service, enabling things like viewing
-// the update logs or drilling into the timeline of an update.
-func (u *cloudUpdate) recordEngineEvents(startingSeqNumber int, events []engine.Event) error {
-	contract.Assert(u.tokenSource != nil)
-	token, err := u.tokenSource.GetToken()
-	if err != nil {
-		return err
-	}
-
+// convertEngineEvents converts a batch of engine events, numbered from the given sequence number, to the form used by
+// the Pulumi Service.
+func convertEngineEvents(startingSeqNumber int, events []engine.Event) (apitype.EngineEventBatch, error) {
 	var apiEvents apitype.EngineEventBatch
 	for idx, event := range events {
-		apiEvent, convErr := convertEngineEvent(event)
+		apiEvent, convErr := display.ConvertEngineEvent(event)
 		if convErr != nil {
-			return errors.Wrap(convErr, "converting engine event")
+			return apitype.EngineEventBatch{}, errors.Wrap(convErr, "converting engine event")
 		}
 
 		// Each event within an update must have a unique sequence number. Any request to
@@ -182,8 +176,52 @@ func (u *cloudUpdate) recordEngineEvents(startingSeqNumber int, events []engine.
 
 		apiEvents.Events = append(apiEvents.Events, apiEvent)
 	}
+	return apiEvents, nil
+}
+
+// recordEngineEvents will record the events with the Pulumi Service, enabling things like viewing
+// the update logs or drilling into the timeline of an update.
+func (u *cloudUpdate) recordEngineEvents(batch apitype.EngineEventBatch) error {
+	contract.Assert(u.tokenSource != nil)
+	token, err := u.tokenSource.GetToken()
+	if err != nil {
+		return err
+	}
+
+	return u.backend.client.RecordEngineEvents(u.context, u.update, batch, token)
+}
+
+// openEngineEventStream opens a stream over which the update's engine events can be sent to the Pulumi Service. It
+// returns nil if streaming has been disabled or the stream cannot be opened.
+func (u *cloudUpdate) openEngineEventStream() *client.EngineEventStream {
+	if cmdutil.IsTruthy(os.Getenv(DisableEventStreamingEnvVar)) {
+		return nil
+	}
+
+	contract.Assert(u.tokenSource != nil)
+	token, err := u.tokenSource.GetToken()
+	if err != nil {
+		logging.V(3).Infof("error opening engine event stream: %s", err)
+		return nil
+	}
+	stream, err := u.backend.client.OpenEngineEventStream(u.context, u.update, token)
+	if err != nil {
+		logging.V(3).Infof("error opening engine event stream: %s", err)
+		return nil
+	}
+	return stream
+}
 
-	return u.backend.client.RecordEngineEvents(u.context, u.update, apiEvents, token)
+// resendEngineEvents records the events that were sent over the given stream but never acknowledged by the Pulumi
+// Service, so that none are lost when a failed stream falls back to separate requests. The service may have received
+// some of them before the stream failed; any batch it has already recorded is rejected, as its sequence numbers are
+// taken.
+func (u *cloudUpdate) resendEngineEvents(stream *client.EngineEventStream) {
+	for _, batch := range stream.Unacknowledged() {
+		if err := u.recordEngineEvents(batch); err != nil {
+			logging.V(3).Infof("error recording engine events: %s", err)
+		}
+	}
 }
 
 // RecordAndDisplayEvents inspects engine events from the given channel, and prints them to the CLI as well as
@@ -233,7 +271,7 @@ func (u *cloudUpdate) RecordAndDisplayEvents(
 func (b *cloudBackend) newQuery(ctx context.Context, stackRef backend.StackReference,
 	op backend.UpdateOperation) (*cloudQuery, error) {
 	// Construct the query target.
-	target, err := b.getTarget(ctx, stackRef, op.StackConfiguration.Config, op.StackConfiguration.Decrypter)
+	target, err := b.getTarget(ctx, stackRef, op.StackConfiguration, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -254,8 +292,12 @@ func (b *cloudBackend) newUpdate(ctx context.Context, stackRef backend.StackRefe
 		tokenSource = ts
 	}
 
-	// Construct the deployment target.
-	target, err := b.getTarget(ctx, stackRef, op.StackConfiguration.Config, op.StackConfiguration.Decrypter)
+	// Construct the deployment target. Previews may be run against the state left by a prior update.
+	var version *int
+	if op.Opts.AgainstVersion != 0 {
+		version = &op.Opts.AgainstVersion
+	}
+	target, err := b.getTarget(ctx, stackRef, op.StackConfiguration, version)
 	if err != nil {
 		return nil, err
 	}
@@ -272,25 +314,26 @@ func (b *cloudBackend) newUpdate(ctx context.Context, stackRef backend.StackRefe
 	}, nil
 }
 
-func (b *cloudBackend) getSnapshot(ctx context.Context, stackRef backend.StackReference) (*deploy.Snapshot, error) {
-	untypedDeployment, err := b.ExportDeployment(ctx, stackRef)
-	if err != nil {
-		return nil, err
-	}
+// getSnapshot returns the stack's snapshot as of the given update version, or the latest one if version is nil.
+func (b *cloudBackend) getSnapshot(ctx context.Context, stackRef backend.StackReference,
+	version *int) (*deploy.Snapshot, error) {
 
-	snapshot, err := stack.DeserializeUntypedDeployment(untypedDeployment, stack.DefaultSecretsProvider)
-	if err != nil {
-		return nil, err
+	var snapshot *deploy.Snapshot
+	untypedDeployment, err := b.exportDeployment(ctx, stackRef, version)
+	if err == nil {
+		snapshot, err = stack.DeserializeUntypedDeployment(untypedDeployment, stack.DefaultSecretsProvider)
 	}
-
-	return snapshot, nil
+	if err != nil && version != nil {
+		return nil, errors.Wrapf(err, "loading the state of version %d", *version)
+	}
+	return snapshot, err
 }
 
 func (b *cloudBackend) getTarget(ctx context.Context, stackRef backend.StackReference,
-	cfg config.Map, dec config.Decrypter) (*deploy.Target, error) {
-	snapshot, err := b.getSnapshot(ctx, stackRef)
+	cfg backend.StackConfiguration, version *int) (*deploy.Target, error) {
+	snapshot, err := b.getSnapshot(ctx, stackRef, version)
 	if err != nil {
-		switch err {
+		switch errors.Cause(err) {
 		case stack.ErrDeploymentSchemaVersionTooOld:
 			return nil, fmt.Errorf("the stack '%s' is too old to be used by this version of the Pulumi CLI",
 				stackRef.Name())
@@ -303,215 +346,17 @@ func (b *cloudBackend) getTarget(ctx context.Context, stackRef backend.StackRefe
 	}
 
 	return &deploy.Target{
-		Name:      stackRef.Name(),
-		Config:    cfg,
-		Decrypter: dec,
-		Snapshot:  snapshot,
+		Name:            stackRef.Name(),
+		Config:          cfg.Config,
+		Decrypter:       cfg.Decrypter,
+		Snapshot:        snapshot,
+		Transformations: cfg.Transformations,
+		NamePrefix:      cfg.NamePrefix,
+		NameSuffix:      cfg.NameSuffix,
+		Guardrails:      cfg.Guardrails,
 	}, nil
 }
 
-func convertStepEventMetadata(md engine.StepEventMetadata) apitype.StepEventMetadata {
-	keys := make([]string, len(md.Keys))
-	for i, v := range md.Keys {
-		keys[i] = string(v)
-	}
-	var diffs []string
-	for _, v := range md.Diffs {
-		diffs = append(diffs, string(v))
-	}
-	var detailedDiff map[string]apitype.PropertyDiff
-	if md.DetailedDiff != nil {
-		detailedDiff = make(map[string]apitype.PropertyDiff)
-		for k, v := range md.DetailedDiff {
-			var d apitype.DiffKind
-			switch v.Kind {
-			case plugin.DiffAdd:
-				d = apitype.DiffAdd
-			case plugin.DiffAddReplace:
-				d = apitype.DiffAddReplace
-			case plugin.DiffDelete:
-				d = apitype.DiffDelete
-			case plugin.DiffDeleteReplace:
-				d = apitype.DiffDeleteReplace
-			case plugin.DiffUpdate:
-				d = apitype.DiffUpdate
-			case plugin.DiffUpdateReplace:
-				d = apitype.DiffUpdateReplace
-			default:
-				contract.Failf("unrecognized diff kind %v", v)
-			}
-			detailedDiff[k] = apitype.PropertyDiff{
-				Kind:      d,
-				InputDiff: v.InputDiff,
-			}
-		}
-	}
-
-	return apitype.StepEventMetadata{
-		Op:   string(md.Op),
-		URN:  string(md.URN),
-		Type: string(md.Type),
-
-		Old: convertStepEventStateMetadata(md.Old),
-		New: convertStepEventStateMetadata(md.New),
-
-		Keys:         keys,
-		Diffs:        diffs,
-		DetailedDiff: detailedDiff,
-		Logical:      md.Logical,
-		Provider:     md.Provider,
-	}
-}
-
-func convertStepEventStateMetadata(md *engine.StepEventStateMetadata) *apitype.StepEventStateMetadata {
-	if md == nil {
-		return nil
-	}
-
-	inputs := make(map[string]interface{})
-	for k, v := range md.Inputs {
-		inputs[string(k)] = v
-	}
-	outputs := make(map[string]interface{})
-	for k, v := range md.Outputs {
-		outputs[string(k)] = v
-	}
-
-	return &apitype.StepEventStateMetadata{
-		Type: string(md.Type),
-		URN:  string(md.URN),
-
-		Custom:     md.Custom,
-		Delete:     md.Delete,
-		ID:         string(md.ID),
-		Parent:     string(md.Parent),
-		Protect:    md.Protect,
-		Inputs:     inputs,
-		Outputs:    outputs,
-		InitErrors: md.InitErrors,
-	}
-}
-
-// convertEngineEvent converts a raw engine.Event into an apitype.EngineEvent used in the Pulumi
-// REST API. Returns an error if the engine event is unknown or not in an expected format.
-// EngineEvent.{ Sequence, Timestamp } are expected to be set by the caller.
-func convertEngineEvent(e engine.Event) (apitype.EngineEvent, error) {
-	var apiEvent apitype.EngineEvent
-
-	// Error to return if the payload doesn't match expected.
-	eventTypePayloadMismatch := errors.Errorf("unexpected payload for event type %v", e.Type)
-
-	switch e.Type {
-	case engine.CancelEvent:
-		apiEvent.CancelEvent = &apitype.CancelEvent{}
-
-	case engine.StdoutColorEvent:
-		p, ok := e.Payload.(engine.StdoutEventPayload)
-		if !ok {
-			return apiEvent, eventTypePayloadMismatch
-		}
-		apiEvent.StdoutEvent = &apitype.StdoutEngineEvent{
-			Message: p.Message,
-			Color:   string(p.Color),
-		}
-
-	case engine.DiagEvent:
-		p, ok := e.Payload.(engine.DiagEventPayload)
-		if !ok {
-			return apiEvent, eventTypePayloadMismatch
-		}
-		apiEvent.DiagnosticEvent = &apitype.DiagnosticEvent{
-			URN:       string(p.URN),
-			Prefix:    p.Prefix,
-			Message:   p.Message,
-			Color:     string(p.Color),
-			Severity:  string(p.Severity),
-			Ephemeral: p.Ephemeral,
-		}
-
-	case engine.PolicyViolationEvent:
-		p, ok := e.Payload.(engine.PolicyViolationEventPayload)
-		if !ok {
-			return apiEvent, eventTypePayloadMismatch
-		}
-		apiEvent.PolicyEvent = &apitype.PolicyEvent{
-			ResourceURN:       string(p.ResourceURN),
-			Message:           p.Message,
-			Color:             string(p.Color),
-			PolicyName:        p.PolicyName,
-			PolicyPackName:    p.PolicyPackName,
-			PolicyPackVersion: p.PolicyPackVersion,
-			EnforcementLevel:  string(p.EnforcementLevel),
-		}
-
-	case engine.PreludeEvent:
-		p, ok := e.Payload.(engine.PreludeEventPayload)
-		if !ok {
-			return apiEvent, eventTypePayloadMismatch
-		}
-		// Convert the config bag.
-		cfg := make(map[string]string)
-		for k, v := range p.Config {
-			cfg[k] = v
-		}
-		apiEvent.PreludeEvent = &apitype.PreludeEvent{
-			Config: cfg,
-		}
-
-	case engine.SummaryEvent:
-		p, ok := e.Payload.(engine.SummaryEventPayload)
-		if !ok {
-			return apiEvent, eventTypePayloadMismatch
-		}
-		// Convert the resource changes.
-		changes := make(map[string]int)
-		for op, count := range p.ResourceChanges {
-			changes[string(op)] = count
-		}
-		apiEvent.SummaryEvent = &apitype.SummaryEvent{
-			MaybeCorrupt:    p.MaybeCorrupt,
-			DurationSeconds: int(p.Duration.Seconds()),
-			ResourceChanges: changes,
-		}
-
-	case engine.ResourcePreEvent:
-		p, ok := e.Payload.(engine.ResourcePreEventPayload)
-		if !ok {
-			return apiEvent, eventTypePayloadMismatch
-		}
-		apiEvent.ResourcePreEvent = &apitype.ResourcePreEvent{
-			Metadata: convertStepEventMetadata(p.Metadata),
-			Planning: p.Planning,
-		}
-
-	case engine.ResourceOutputsEvent:
-		p, ok := e.Payload.(engine.ResourceOutputsEventPayload)
-		if !ok {
-			return apiEvent, eventTypePayloadMismatch
-		}
-		apiEvent.ResOutputsEvent = &apitype.ResOutputsEvent{
-			Metadata: convertStepEventMetadata(p.Metadata),
-			Planning: p.Planning,
-		}
-
-	case engine.ResourceOperationFailed:
-		p, ok := e.Payload.(engine.ResourceOperationFailedPayload)
-		if !ok {
-			return apiEvent, eventTypePayloadMismatch
-		}
-		apiEvent.ResOpFailedEvent = &apitype.ResOpFailedEvent{
-			Metadata: convertStepEventMetadata(p.Metadata),
-			Status:   int(p.Status),
-			Steps:    p.Steps,
-		}
-
-	default:
-		return apiEvent, errors.Errorf("unknown event type %q", e.Type)
-	}
-
-	return apiEvent, nil
-}
-
 func isDebugDiagEvent(e engine.Event) bool {
 	return e.Type == engine.DiagEvent && (e.Payload.(engine.DiagEventPayload)).Severity == diag.Debug
 }
@@ -527,17 +372,27 @@ func persistEngineEvents(
 	update *cloudUpdate, persistDebugEvents bool,
 	events <-chan engine.Event, done chan<- bool) {
 	// A single update can emit hundreds, if not thousands, or tens of thousands of
-	// engine events. We transmit engine events in large batches to reduce the overhead
-	// associated with each HTTP request to the service. We also send multiple HTTP
-	// requests concurrently, as to not block processing subsequent engine events.
+	// engine events. Where the Pulumi Service supports it, we stream them over a single
+	// long-lived request, which applies backpressure when we produce events faster than
+	// they can be sent. Otherwise, we transmit engine events in large batches to reduce
+	// the overhead associated with each HTTP request to the service. We also send
+	// multiple HTTP requests concurrently, as to not block processing subsequent engine
+	// events.
+	stream := update.openEngineEventStream()
 
 	// Maximum number of events to batch up before transmitting.
 	const maxEventsToTransmit = 50
-	// Maximum wait time before sending all batched events.
-	const maxTransmissionDelay = 4 * time.Second
+	// Maximum wait time before sending all batched events. Events sent over a stream are
+	// cheap to send, so they are flushed sooner.
+	maxTransmissionDelay := 4 * time.Second
 	// Maximum number of concurrent requests to the Pulumi Service to persist
-	// engine events.
-	const maxConcurrentRequests = 3
+	// engine events. A stream must be written by a single go-routine so that its
+	// batches arrive in order.
+	maxConcurrentRequests := 3
+	if stream != nil {
+		maxTransmissionDelay = 250 * time.Millisecond
+		maxConcurrentRequests = 1
+	}
 
 	// We don't want to indicate that we are done processing every engine event in the
 	// provided channel until every HTTP request has completed. We use a wait group to
@@ -551,6 +406,7 @@ func persistEngineEvents(
 
 	var eventBatch []engine.Event
 	maxDelayTicker := time.NewTicker(maxTransmissionDelay)
+	defer maxDelayTicker.Stop()
 
 	// We maintain a sequence counter for each event to ensure that the Pulumi Service can
 	// ensure events can be reconstructured in the same order they were emitted. (And not
@@ -566,18 +422,45 @@ func persistEngineEvents(
 	batchesToTransmit := make(chan engineEventBatch)
 
 	transmitBatchLoop := func() {
-		wg.Add(1)
 		defer wg.Done()
 
 		for eventBatch := range batchesToTransmit {
-			err := update.recordEngineEvents(eventBatch.sequenceStart, eventBatch.events)
+			batch, err := convertEngineEvents(eventBatch.sequenceStart, eventBatch.events)
 			if err != nil {
 				logging.V(3).Infof("error recording engine events: %s", err)
+				continue
+			}
+
+			if stream != nil {
+				err = stream.Send(batch)
+				if err == nil {
+					continue
+				}
+
+				// If the stream fails, e.g. because the service does not support it or the connection
+				// was lost, resend the events it never delivered and fall back to sending any remaining
+				// batches in separate requests.
+				logging.V(3).Infof("error streaming engine events; falling back to batched requests: %s", err)
+				update.resendEngineEvents(stream)
+				stream = nil
+				continue
+			}
+
+			if err = update.recordEngineEvents(batch); err != nil {
+				logging.V(3).Infof("error recording engine events: %s", err)
+			}
+		}
+
+		if stream != nil {
+			if err := stream.Close(); err != nil {
+				logging.V(3).Infof("error closing engine event stream; resending its events: %s", err)
+				update.resendEngineEvents(stream)
 			}
 		}
 	}
 	// Start N different go-routines which will all pull from the batchesToTransmit channel
 	// and persist those engine events until the channel is closed.
+	wg.Add(maxConcurrentRequests)
 	for i := 0; i < maxConcurrentRequests; i++ {
 		go transmitBatchLoop()
 	}
@@ -17,6 +17,8 @@ package backend
 import (
 	"context"
 
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/engine"
 	"github.com/pulumi/pulumi/pkg/resource/plugin"
 	"github.com/pulumi/pulumi/pkg/util/result"
 )
@@ -44,4 +46,13 @@ type PolicyPack interface {
 	Publish(ctx context.Context, op PublishOperation) result.Result
 	// Apply the PolicyPack to an organization.
 	Apply(ctx context.Context, op ApplyOperation) error
+	// Disable stops enforcing the PolicyPack in its organization.
+	Disable(ctx context.Context) error
+	// Remove the given version of the PolicyPack from its organization, or every version of it if the version is 0.
+	Remove(ctx context.Context, version int) error
+	// Versions returns the published versions of the PolicyPack, in ascending order.
+	Versions(ctx context.Context) ([]apitype.PolicyPackVersion, error)
+	// Required returns the given version of the PolicyPack as a policy that can be run locally during an
+	// update, without applying it to the organization.
+	Required(ctx context.Context, version int) (engine.RequiredPolicy, error)
 }
@@ -62,6 +62,7 @@ type SnapshotManager struct {
 	dones            map[*resource.State]bool // The set of resources that have been operated upon already by this plan
 	completeOps      map[*resource.State]bool // The set of resources that have completed their operation
 	doVerify         bool                     // If true, verify the snapshot before persisting it
+	verifyEveryStep  bool                     // If true, verify the snapshot after every mutation
 	mutationRequests chan<- mutationRequest   // The queue of mutation requests, to be retired serially by the manager
 	cancel           chan bool                // A channel used to request cancellation of any new mutation requests.
 	done             <-chan error             // A channel that sends a single result when the manager has shut down.
@@ -69,7 +70,13 @@ type SnapshotManager struct {
 
 var _ engine.SnapshotManager = (*SnapshotManager)(nil)
 
+// ContinuousIntegrityChecking can be set to true to verify the integrity of the snapshot after every step of an
+// update, including those whose checkpoint writes are elided. The first violation fails the update with an error that
+// names the step that caused it, rather than surfacing the next time the checkpoint is loaded.
+var ContinuousIntegrityChecking bool
+
 type mutationRequest struct {
+	step    deploy.Step
 	mutator func() bool
 	result  chan<- error
 }
@@ -87,7 +94,8 @@ func (sm *SnapshotManager) Close() error {
 //
 // Serialization is performed by pushing the mutator function onto a channel, where another
 // goroutine is polling the channel and executing the mutation functions as they come.
-// This function optionally verifies the integrity of the snapshot before and after mutation.
+// This function optionally verifies the integrity of the snapshot after each mutation, attributing any violation
+// to the step that caused it (see ContinuousIntegrityChecking).
 //
 // The mutator may indicate that its corresponding checkpoint write may be safely elided by
 // returning `false`. As of this writing, we only elide writes after same steps with no
@@ -96,10 +104,10 @@ func (sm *SnapshotManager) Close() error {
 //
 // You should never observe or mutate the global snapshot without using this function unless
 // you have a very good justification.
-func (sm *SnapshotManager) mutate(mutator func() bool) error {
+func (sm *SnapshotManager) mutate(step deploy.Step, mutator func() bool) error {
 	result := make(chan error)
 	select {
-	case sm.mutationRequests <- mutationRequest{mutator: mutator, result: result}:
+	case sm.mutationRequests <- mutationRequest{step: step, mutator: mutator, result: result}:
 		return <-result
 	case <-sm.cancel:
 		return errors.New("snapshot manager closed")
@@ -118,7 +126,7 @@ func (sm *SnapshotManager) mutate(mutator func() bool) error {
 // Note that this is completely not thread-safe and defeats the purpose of having a `mutate` callback
 // entirely, but the hope is that this state of things will not be permament.
 func (sm *SnapshotManager) RegisterResourceOutputs(step deploy.Step) error {
-	return sm.mutate(func() bool { return true })
+	return sm.mutate(step, func() bool { return true })
 }
 
 // BeginMutation signals to the SnapshotManager that the engine intends to mutate the global snapshot
@@ -237,7 +245,7 @@ func (ssm *sameSnapshotMutation) End(step deploy.Step, successful bool) error {
 	contract.Require(step.Op() == deploy.OpSame, "step.Op() == deploy.OpSame")
 	contract.Assert(successful)
 	logging.V(9).Infof("SnapshotManager: sameSnapshotMutation.End(..., %v)", successful)
-	return ssm.manager.mutate(func() bool {
+	return ssm.manager.mutate(step, func() bool {
 		ssm.manager.markDone(step.Old())
 		ssm.manager.markNew(step.New())
 
@@ -256,7 +264,7 @@ func (ssm *sameSnapshotMutation) End(step deploy.Step, successful bool) error {
 
 func (sm *SnapshotManager) doCreate(step deploy.Step) (engine.SnapshotMutation, error) {
 	logging.V(9).Infof("SnapshotManager.doCreate(%s)", step.URN())
-	err := sm.mutate(func() bool {
+	err := sm.mutate(step, func() bool {
 		sm.markOperationPending(step.New(), resource.OperationTypeCreating)
 		return true
 	})
@@ -274,7 +282,7 @@ type createSnapshotMutation struct {
 func (csm *createSnapshotMutation) End(step deploy.Step, successful bool) error {
 	contract.Require(step != nil, "step != nil")
 	logging.V(9).Infof("SnapshotManager: createSnapshotMutation.End(..., %v)", successful)
-	return csm.manager.mutate(func() bool {
+	return csm.manager.mutate(step, func() bool {
 		csm.manager.markOperationComplete(step.New())
 		if successful {
 			// There is some very subtle behind-the-scenes magic here that
@@ -300,7 +308,7 @@ func (csm *createSnapshotMutation) End(step deploy.Step, successful bool) error
 
 func (sm *SnapshotManager) doUpdate(step deploy.Step) (engine.SnapshotMutation, error) {
 	logging.V(9).Infof("SnapshotManager.doUpdate(%s)", step.URN())
-	err := sm.mutate(func() bool {
+	err := sm.mutate(step, func() bool {
 		sm.markOperationPending(step.New(), resource.OperationTypeUpdating)
 		return true
 	})
@@ -318,7 +326,7 @@ type updateSnapshotMutation struct {
 func (usm *updateSnapshotMutation) End(step deploy.Step, successful bool) error {
 	contract.Require(step != nil, "step != nil")
 	logging.V(9).Infof("SnapshotManager: updateSnapshotMutation.End(..., %v)", successful)
-	return usm.manager.mutate(func() bool {
+	return usm.manager.mutate(step, func() bool {
 		usm.manager.markOperationComplete(step.New())
 		if successful {
 			usm.manager.markDone(step.Old())
@@ -330,7 +338,7 @@ func (usm *updateSnapshotMutation) End(step deploy.Step, successful bool) error
 
 func (sm *SnapshotManager) doDelete(step deploy.Step) (engine.SnapshotMutation, error) {
 	logging.V(9).Infof("SnapshotManager.doDelete(%s)", step.URN())
-	err := sm.mutate(func() bool {
+	err := sm.mutate(step, func() bool {
 		sm.markOperationPending(step.Old(), resource.OperationTypeDeleting)
 		return true
 	})
@@ -348,7 +356,7 @@ type deleteSnapshotMutation struct {
 func (dsm *deleteSnapshotMutation) End(step deploy.Step, successful bool) error {
 	contract.Require(step != nil, "step != nil")
 	logging.V(9).Infof("SnapshotManager: deleteSnapshotMutation.End(..., %v)", successful)
-	return dsm.manager.mutate(func() bool {
+	return dsm.manager.mutate(step, func() bool {
 		dsm.manager.markOperationComplete(step.Old())
 		if successful {
 			contract.Assert(!step.Old().Protect)
@@ -371,7 +379,7 @@ func (rsm *replaceSnapshotMutation) End(step deploy.Step, successful bool) error
 
 func (sm *SnapshotManager) doRead(step deploy.Step) (engine.SnapshotMutation, error) {
 	logging.V(9).Infof("SnapshotManager.doRead(%s)", step.URN())
-	err := sm.mutate(func() bool {
+	err := sm.mutate(step, func() bool {
 		sm.markOperationPending(step.New(), resource.OperationTypeReading)
 		return true
 	})
@@ -389,7 +397,7 @@ type readSnapshotMutation struct {
 func (rsm *readSnapshotMutation) End(step deploy.Step, successful bool) error {
 	contract.Require(step != nil, "step != nil")
 	logging.V(9).Infof("SnapshotManager: readSnapshotMutation.End(..., %v)", successful)
-	return rsm.manager.mutate(func() bool {
+	return rsm.manager.mutate(step, func() bool {
 		rsm.manager.markOperationComplete(step.New())
 		if successful {
 			if step.Old() != nil {
@@ -410,7 +418,7 @@ func (rsm *refreshSnapshotMutation) End(step deploy.Step, successful bool) error
 	contract.Require(step != nil, "step != nil")
 	contract.Require(step.Op() == deploy.OpRefresh, "step.Op() == deploy.OpRefresh")
 	logging.V(9).Infof("SnapshotManager: refreshSnapshotMutation.End(..., %v)", successful)
-	return rsm.manager.mutate(func() bool {
+	return rsm.manager.mutate(step, func() bool {
 		// We always elide refreshes. The expectation is that all of these run before any actual mutations and that
 		// some other component will rewrite the base snapshot in-memory, so there's no action the snapshot
 		// manager needs to take other than to remember that the base snapshot--and therefore the actual snapshot--may
@@ -426,7 +434,7 @@ type removePendingReplaceSnapshotMutation struct {
 func (rsm *removePendingReplaceSnapshotMutation) End(step deploy.Step, successful bool) error {
 	contract.Require(step != nil, "step != nil")
 	contract.Require(step.Op() == deploy.OpRemovePendingReplace, "step.Op() == deploy.OpRemovePendingReplace")
-	return rsm.manager.mutate(func() bool {
+	return rsm.manager.mutate(step, func() bool {
 		res := step.Old()
 		contract.Assert(res.PendingReplacement)
 		rsm.manager.markDone(res)
@@ -436,7 +444,7 @@ func (rsm *removePendingReplaceSnapshotMutation) End(step deploy.Step, successfu
 
 func (sm *SnapshotManager) doImport(step deploy.Step) (engine.SnapshotMutation, error) {
 	logging.V(9).Infof("SnapshotManager.doImport(%s)", step.URN())
-	err := sm.mutate(func() bool {
+	err := sm.mutate(step, func() bool {
 		sm.markOperationPending(step.New(), resource.OperationTypeImporting)
 		return true
 	})
@@ -456,7 +464,7 @@ func (ism *importSnapshotMutation) End(step deploy.Step, successful bool) error
 	contract.Require(step.Op() == deploy.OpImport || step.Op() == deploy.OpImportReplacement,
 		"step.Op() == deploy.OpImport || step.Op() == deploy.OpImportReplacement")
 
-	return ism.manager.mutate(func() bool {
+	return ism.manager.mutate(step, func() bool {
 		ism.manager.markOperationComplete(step.New())
 		if successful {
 			ism.manager.markNew(step.New())
@@ -574,6 +582,16 @@ func (sm *SnapshotManager) saveSnapshot() error {
 	return nil
 }
 
+// verifyStep verifies the integrity of the snapshot as it stands after the mutation for the given step.
+func (sm *SnapshotManager) verifyStep(step deploy.Step) error {
+	snap := sm.snap()
+	snap.NormalizeURNReferences()
+	if err := snap.VerifyIntegrity(); err != nil {
+		return errors.Wrapf(err, "snapshot integrity violated after %s step for %s", step.Op(), step.URN())
+	}
+	return nil
+}
+
 // NewSnapshotManager creates a new SnapshotManager for the given stack name, using the given persister
 // and base snapshot.
 //
@@ -589,6 +607,7 @@ func NewSnapshotManager(persister SnapshotPersister, baseSnap *deploy.Snapshot)
 		dones:            make(map[*resource.State]bool),
 		completeOps:      make(map[*resource.State]bool),
 		doVerify:         true,
+		verifyEveryStep:  ContinuousIntegrityChecking,
 		mutationRequests: mutationRequests,
 		cancel:           cancel,
 		done:             done,
@@ -597,14 +616,27 @@ func NewSnapshotManager(persister SnapshotPersister, baseSnap *deploy.Snapshot)
 	go func() {
 		// True if we have elided writes since the last actual write.
 		hasElidedWrites := false
+		// True if continuous integrity checking has found a violation, after which nothing more is written.
+		corrupt := false
 
 		// Service each mutation request in turn.
 	serviceLoop:
 		for {
 			select {
 			case request := <-mutationRequests:
+				mustWrite := request.mutator()
 				var err error
-				if request.mutator() {
+				if manager.verifyEveryStep && !corrupt {
+					err = manager.verifyStep(request.step)
+					corrupt = err != nil
+				}
+				if corrupt {
+					// Leave the last good checkpoint in place rather than persisting a corrupt one.
+					if err == nil {
+						err = errors.New("snapshot integrity previously violated")
+					}
+					hasElidedWrites = false
+				} else if mustWrite {
 					err = manager.saveSnapshot()
 					hasElidedWrites = false
 				} else {
@@ -881,3 +881,31 @@ func TestRegisterOutputs(t *testing.T) {
 	assert.Len(t, lastSnap.Resources, 1)
 	assert.Equal(t, resourceA.URN, lastSnap.Resources[0].URN)
 }
+
+func TestContinuousIntegrityChecking(t *testing.T) {
+	resourceA := NewResource("a")
+	snap := NewSnapshot([]*resource.State{resourceA})
+	manager, sp := MockSetup(t, snap)
+	manager.verifyEveryStep = true
+
+	// Creating a second resource with the same URN corrupts the snapshot; the violation should be attributed to the
+	// create step, and the corrupt snapshot should not be persisted.
+	step := deploy.NewCreateStep(nil, &MockRegisterResourceEvent{}, NewResource("a"))
+	mutation, err := manager.BeginMutation(step)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	saved := len(sp.SavedSnapshots)
+
+	err = mutation.End(step, true /* successful */)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "snapshot integrity violated after create step for a")
+		assert.Contains(t, err.Error(), "duplicate resource a")
+	}
+	assert.Len(t, sp.SavedSnapshots, saved)
+
+	// Once violated, nothing more is written.
+	assert.Error(t, manager.RegisterResourceOutputs(step))
+	assert.NoError(t, manager.Close())
+	assert.Len(t, sp.SavedSnapshots, saved)
+}
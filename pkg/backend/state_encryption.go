@@ -0,0 +1,120 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/secrets"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+// EncryptStateEnvVar, when set, causes the state of every stack with a secrets provider to be encrypted at rest with
+// its key, rather than only that of stacks whose state has been encrypted with `pulumi state encrypt`.
+const EncryptStateEnvVar = "PULUMI_ENCRYPT_STATE"
+
+// StateSecretsManagerSource returns the secrets manager configured for the given stack. The state of a stack that is
+// encrypted at rest is decrypted with the key of this secrets manager.
+type StateSecretsManagerSource func(s Stack) (secrets.Manager, error)
+
+// StateEncryption records which stacks' state is encrypted at rest, and resolves the secrets managers whose keys
+// decrypt it. Backends that implement StateEncryptionBackend use it to decide when to apply a stack.StateEncrypter to
+// the state they persist. The zero value is ready to use.
+type StateEncryption struct {
+	lock      sync.Mutex
+	encrypted map[string]bool           // the stacks whose state is known to be encrypted, by reference.
+	source    StateSecretsManagerSource // resolves each stack's secrets manager, if set.
+}
+
+// SetSecretsManagerSource sets the function that resolves the secrets manager of each stack whose state is decrypted.
+func (e *StateEncryption) SetSecretsManagerSource(source StateSecretsManagerSource) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.source = source
+}
+
+// IsEncrypted returns true if the given stack's state is known to be encrypted at rest.
+func (e *StateEncryption) IsEncrypted(stackRef StackReference) bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.encrypted[stackRef.String()]
+}
+
+// SetEncrypted records whether the given stack's state is encrypted at rest.
+func (e *StateEncryption) SetEncrypted(stackRef StackReference, encrypted bool) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if encrypted {
+		if e.encrypted == nil {
+			e.encrypted = make(map[string]bool)
+		}
+		e.encrypted[stackRef.String()] = true
+	} else {
+		delete(e.encrypted, stackRef.String())
+	}
+}
+
+// Encrypts returns true if state of the given stack that is persisted with the given secrets manager is encrypted at
+// rest, either because the stack's state is already encrypted or because EncryptStateEnvVar is set.
+func (e *StateEncryption) Encrypts(stackRef StackReference, sm secrets.Manager) bool {
+	return e.IsEncrypted(stackRef) || sm != nil && cmdutil.IsTruthy(os.Getenv(EncryptStateEnvVar))
+}
+
+// SecretsManager returns the secrets manager configured for the given stack.
+func (e *StateEncryption) SecretsManager(s Stack) (secrets.Manager, error) {
+	e.lock.Lock()
+	source := e.source
+	e.lock.Unlock()
+
+	if source == nil {
+		return nil, errors.Errorf("the secrets provider of stack %s is unknown", s.Ref())
+	}
+	return source(s)
+}
+
+// SecretsProvider returns the provider of the secrets manager that decrypts the given stack's state. If the stack's
+// own secrets manager is of the type that encrypted the state, it is used, so that the key is found the same way as
+// that of the stack's configuration; otherwise, the secrets manager that encrypted the state is reconstructed from
+// the state itself, as stack.DefaultSecretsProvider does.
+func (e *StateEncryption) SecretsProvider(s Stack) stack.SecretsProvider {
+	return stateSecretsProvider{e: e, s: s}
+}
+
+type stateSecretsProvider struct {
+	e *StateEncryption
+	s Stack
+}
+
+func (p stateSecretsProvider) OfType(ty string, state json.RawMessage) (secrets.Manager, error) {
+	p.e.lock.Lock()
+	source := p.e.source
+	p.e.lock.Unlock()
+
+	if source != nil {
+		sm, err := source(p.s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting the secrets manager of stack %s", p.s.Ref())
+		}
+		if sm != nil && sm.Type() == ty {
+			return sm, nil
+		}
+	}
+	return stack.DefaultSecretsProvider.OfType(ty, state)
+}
@@ -81,6 +81,14 @@ const (
 	// CIPRNumber is the PR number, for which the current CI job may be executing.
 	// Combining this information with the `VCSRepoKind` will give us the PR URL.
 	CIPRNumber = "ci.pr.number"
+
+	// GuardrailsOverridden ("true") indicates that the stack's guardrails were overridden for the update.
+	GuardrailsOverridden = "pulumi.guardrails.overridden"
+
+	// FreezeBroken ("true") indicates that the update ran during one of the stack's freeze windows.
+	FreezeBroken = "pulumi.freeze.broken"
+	// FreezeJustification is the reason given for running the update during one of the stack's freeze windows.
+	FreezeJustification = "pulumi.freeze.justification"
 )
 
 // UpdateInfo describes a previous update.
@@ -102,5 +110,6 @@ type UpdateInfo struct {
 	// Information obtained from an update completing.
 	Result          UpdateResult           `json:"result"`
 	EndTime         int64                  `json:"endTime"`
+	Version         int                    `json:"version,omitempty"`
 	ResourceChanges engine.ResourceChanges `json:"resourceChanges,omitempty"`
 }
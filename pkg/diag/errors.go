@@ -58,3 +58,21 @@ func GetDuplicateResourceAliasError(urn resource.URN) *Diag {
 		"Duplicate resource alias '%v' applied to resource with URN '%v' conflicting with resource with URN '%v'",
 	)
 }
+
+func GetStrictPreviewInvokeError(urn resource.URN) *Diag {
+	return newError(urn, 2009, "%v: '%v' is not permitted by --strict-allow during a strict preview; "+
+		"the call was rejected")
+}
+
+func GetUntargetedCreateError(urn resource.URN) *Diag {
+	return newError(urn, 2010, "Resource '%v' would be created but is not targeted; target it as well or target "+
+		"the dependents of the targets")
+}
+
+func GetUpdateRefusedError(urn resource.URN) *Diag {
+	return newError(urn, 2011, "Update refused before any changes were made: %v")
+}
+
+func GetStrictPreviewWriteError(urn resource.URN) *Diag {
+	return newError(urn, 2012, "%v: '%v' is not permitted during a strict preview; the call was rejected")
+}
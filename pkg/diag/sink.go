@@ -22,6 +22,7 @@ import (
 
 	"github.com/pulumi/pulumi/pkg/diag/colors"
 	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/i18n"
 	"github.com/pulumi/pulumi/pkg/util/logging"
 )
 
@@ -179,7 +180,7 @@ func (d *defaultSink) Stringify(sev Severity, diag *Diag, args ...interface{}) (
 			contract.Failf("Unrecognized diagnostic severity: %v", sev)
 		}
 
-		prefix.WriteString(string(sev))
+		prefix.WriteString(i18n.T(string(sev)))
 		prefix.WriteString(": ")
 		prefix.WriteString(colors.Reset)
 	}
@@ -191,7 +192,7 @@ func (d *defaultSink) Stringify(sev Severity, diag *Diag, args ...interface{}) (
 	if diag.Raw {
 		buffer.WriteString(diag.Message)
 	} else {
-		buffer.WriteString(fmt.Sprintf(diag.Message, args...))
+		buffer.WriteString(i18n.Sprintf(diag.Message, args...))
 	}
 
 	buffer.WriteString(colors.Reset)
@@ -0,0 +1,152 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+)
+
+const (
+	// DefaultCheckpointLagThreshold is the latency above which a checkpoint write is considered to be lagging, unless
+	// UpdateOptions.CheckpointLagThreshold says otherwise.
+	DefaultCheckpointLagThreshold = 10 * time.Second
+
+	// checkpointLagPauseCount is the number of consecutive lagging checkpoint writes after which no new steps are
+	// started until a write completes in time.
+	checkpointLagPauseCount = 3
+)
+
+// checkpointValve is a safety valve that keeps an update from racing ahead of the persistence of its checkpoints.
+//
+// The engine reports the latency and result of each checkpoint write to the valve. Each write that is slower than the
+// threshold, or that fails, halves the number of steps that may execute at once; after several such writes in a row,
+// the valve stops admitting new steps altogether. Each timely write restores one step of parallelism, up to the
+// update's degree of parallelism. While paused, the valve admits a single step once no steps are executing and the
+// threshold has elapsed, so that the update can observe whether persistence has caught up.
+type checkpointValve struct {
+	threshold time.Duration // the latency above which a checkpoint write is lagging.
+	max       int           // the degree of parallelism of the update.
+
+	lock     sync.Mutex
+	limit    int           // the number of steps that may currently execute at once; zero if paused.
+	active   int           // the number of steps currently executing.
+	lagging  int           // the number of consecutive lagging writes.
+	resumeAt time.Time     // while paused, the time after which a single step may be admitted.
+	changed  chan struct{} // closed and replaced whenever the valve's state changes.
+}
+
+var _ deploy.StepThrottle = (*checkpointValve)(nil)
+
+// newCheckpointValve creates a valve for an update with the given degree of parallelism that considers checkpoint
+// writes slower than the given threshold to be lagging.
+func newCheckpointValve(parallelism int, threshold time.Duration) *checkpointValve {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	return &checkpointValve{
+		threshold: threshold,
+		max:       parallelism,
+		limit:     parallelism,
+		changed:   make(chan struct{}),
+	}
+}
+
+// Acquire blocks until the valve admits a new step.
+func (v *checkpointValve) Acquire(ctx context.Context) error {
+	for {
+		v.lock.Lock()
+		var wait <-chan time.Time
+		switch {
+		case v.limit > 0 && v.active < v.limit:
+			v.active++
+			v.lock.Unlock()
+			return nil
+		case v.limit == 0 && v.active == 0:
+			if delay := time.Until(v.resumeAt); delay > 0 {
+				wait = time.After(delay)
+			} else {
+				logging.V(4).Infof("checkpointValve: admitting a step to probe checkpoint latency")
+				v.active++
+				v.lock.Unlock()
+				return nil
+			}
+		}
+		changed := v.changed
+		v.lock.Unlock()
+
+		select {
+		case <-changed:
+		case <-wait:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Release records that a step admitted by Acquire has finished.
+func (v *checkpointValve) Release() {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	v.active--
+	v.notify()
+}
+
+// observe records the latency and result of a checkpoint write, adjusting the number of steps that may execute at
+// once.
+func (v *checkpointValve) observe(latency time.Duration, err error) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if err == nil && latency <= v.threshold {
+		v.lagging = 0
+		if v.limit < v.max {
+			v.limit++
+			logging.V(4).Infof("checkpointValve: checkpoint write took %v; raising parallelism to %d", latency, v.limit)
+			v.notify()
+		}
+		return
+	}
+
+	v.lagging++
+	if v.lagging >= checkpointLagPauseCount {
+		v.limit, v.resumeAt = 0, time.Now().Add(v.threshold)
+		logging.V(4).Infof("checkpointValve: %d lagging checkpoint writes; pausing new steps", v.lagging)
+	} else {
+		// Halve the number of steps that are actually executing, rather than the limit, which may be far above it.
+		limit := v.limit
+		if v.active < limit {
+			limit = v.active
+		}
+		if limit /= 2; limit < 1 {
+			limit = 1
+		}
+		v.limit = limit
+		logging.V(4).Infof("checkpointValve: checkpoint write took %v (err: %v); lowering parallelism to %d",
+			latency, err, v.limit)
+	}
+	v.notify()
+}
+
+// notify wakes any callers waiting on the valve's state. The valve's lock must be held.
+func (v *checkpointValve) notify() {
+	close(v.changed)
+	v.changed = make(chan struct{})
+}
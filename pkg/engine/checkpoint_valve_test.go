@@ -0,0 +1,109 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// tryAcquire returns true if the valve admits a step within a short time.
+func tryAcquire(v *checkpointValve) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	return v.Acquire(ctx) == nil
+}
+
+func TestCheckpointValveLimitsParallelism(t *testing.T) {
+	v := newCheckpointValve(4, time.Second)
+
+	for i := 0; i < 4; i++ {
+		assert.True(t, tryAcquire(v))
+	}
+	assert.False(t, tryAcquire(v))
+
+	// A lagging write halves the number of steps that may execute.
+	v.observe(2*time.Second, nil)
+	assert.Equal(t, 2, v.limit)
+	v.Release()
+	v.Release()
+	assert.False(t, tryAcquire(v))
+
+	// A failed write counts as lagging, too.
+	v.observe(time.Millisecond, errors.New("failed"))
+	assert.Equal(t, 1, v.limit)
+	v.Release()
+	v.Release()
+
+	// Timely writes restore parallelism one step at a time.
+	v.observe(time.Millisecond, nil)
+	assert.Equal(t, 2, v.limit)
+	assert.True(t, tryAcquire(v))
+	assert.True(t, tryAcquire(v))
+	assert.False(t, tryAcquire(v))
+	v.observe(time.Millisecond, nil)
+	v.observe(time.Millisecond, nil)
+	v.observe(time.Millisecond, nil)
+	assert.Equal(t, 4, v.limit)
+}
+
+func TestCheckpointValvePauses(t *testing.T) {
+	v := newCheckpointValve(8, 20*time.Millisecond)
+
+	assert.True(t, tryAcquire(v))
+	for i := 0; i < checkpointLagPauseCount; i++ {
+		v.observe(time.Second, nil)
+	}
+	assert.Equal(t, 0, v.limit)
+
+	// While a step is executing, no new steps are admitted.
+	assert.False(t, tryAcquire(v))
+
+	// Once no steps are executing, a single step is admitted after the threshold to probe whether persistence has
+	// caught up.
+	v.Release()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, v.Acquire(ctx))
+	assert.False(t, tryAcquire(v))
+
+	// A timely write resumes the update.
+	v.observe(time.Millisecond, nil)
+	assert.Equal(t, 1, v.limit)
+	v.Release()
+	assert.True(t, tryAcquire(v))
+}
+
+func TestCheckpointValveWakesWaiters(t *testing.T) {
+	v := newCheckpointValve(1, time.Second)
+	assert.True(t, tryAcquire(v))
+
+	acquired := make(chan error)
+	go func() {
+		acquired <- v.Acquire(context.Background())
+	}()
+
+	v.Release()
+	select {
+	case err := <-acquired:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		assert.Fail(t, "waiting step was not admitted")
+	}
+}
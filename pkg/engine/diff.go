@@ -66,9 +66,22 @@ func printStepHeader(b io.StringWriter, step StepEventMetadata) {
 		// show a locked symbol, since we are either newly protecting this resource, or retaining protection.
 		extra = " 🔒"
 	}
+	if IsRetainedDelete(step) {
+		// note that the resource is only removed from the state, since its lifecycle is handed off elsewhere.
+		extra += " [retain]"
+	}
 	writeString(b, fmt.Sprintf("%s: (%s)%s\n", string(step.Type), step.Op, extra))
 }
 
+// IsRetainedDelete returns true if the step deletes a resource that is retained on deletion, and so only removes the
+// resource from the state.
+func IsRetainedDelete(step StepEventMetadata) bool {
+	if step.Op != deploy.OpDelete && step.Op != deploy.OpDeleteReplaced {
+		return false
+	}
+	return step.Old != nil && step.Old.RetainOnDelete
+}
+
 func GetIndentationString(indent int) string {
 	var result string
 	for i := 0; i < indent; i++ {
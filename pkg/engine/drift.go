@@ -0,0 +1,91 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+// DriftReport records the resources whose live state a refresh found differs from the state in the stack's
+// checkpoint. Drift is recorded by the refresh's preview, which leaves the checkpoint as it is, so a refresh that
+// reports drift must not skip its preview.
+type DriftReport struct {
+	lock      sync.Mutex
+	resources []DriftedResource
+}
+
+// DriftedResource describes a resource whose live state differs from the state in the stack's checkpoint.
+type DriftedResource struct {
+	// URN is the resource's URN.
+	URN resource.URN `json:"urn"`
+	// Type is the resource's type.
+	Type tokens.Type `json:"type"`
+	// Op is OpUpdate if the resource's outputs have changed, or OpDelete if the resource no longer exists.
+	Op deploy.StepOp `json:"op"`
+	// Properties are the names of the outputs whose values have changed, if the resource still exists.
+	Properties []resource.PropertyKey `json:"properties,omitempty"`
+}
+
+// NewDriftReport returns a drift report, which refreshes record the resources that have drifted in, that starts out
+// holding the given resources.
+func NewDriftReport(resources ...DriftedResource) *DriftReport {
+	return &DriftReport{resources: resources}
+}
+
+// Resources returns the resources that have drifted, in order of their URNs.
+func (r *DriftReport) Resources() []DriftedResource {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	resources := make([]DriftedResource, len(r.resources))
+	copy(resources, r.resources)
+	sort.Slice(resources, func(i, j int) bool { return resources[i].URN < resources[j].URN })
+	return resources
+}
+
+// HasDrift returns true if any resource has drifted.
+func (r *DriftReport) HasDrift() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return len(r.resources) > 0
+}
+
+// record adds the resource refreshed by the given step to the report if the refresh changed it, as described by the
+// given operation. A nil report records nothing.
+func (r *DriftReport) record(step *deploy.RefreshStep, op deploy.StepOp) {
+	if r == nil || (op != deploy.OpUpdate && op != deploy.OpDelete) {
+		return
+	}
+
+	drifted := DriftedResource{URN: step.URN(), Type: step.Type(), Op: op}
+	if op == deploy.OpUpdate {
+		if diff := step.Old().Outputs.Diff(step.New().Outputs); diff != nil {
+			for _, k := range diff.Keys() {
+				if diff.Changed(k) {
+					drifted.Properties = append(drifted.Properties, k)
+				}
+			}
+		}
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.resources = append(r.resources, drifted)
+}
@@ -0,0 +1,118 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/deploytest"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+func TestDriftReport(t *testing.T) {
+	state := func(name string) *resource.State {
+		urn := resource.NewURN("dev", "proj", "", "test:index:Resource", tokens.QName(name))
+		return &resource.State{URN: urn, Type: urn.Type(), Custom: true}
+	}
+	a, b := state("a"), state("b")
+
+	// A nil report records nothing.
+	var none *DriftReport
+	none.record(deploy.NewRefreshStep(nil, a, nil).(*deploy.RefreshStep), deploy.OpDelete)
+
+	// Resources that are the same are not drifted; those that have been updated or deleted are.
+	report := NewDriftReport()
+	report.record(deploy.NewRefreshStep(nil, b, nil).(*deploy.RefreshStep), deploy.OpDelete)
+	report.record(deploy.NewRefreshStep(nil, a, nil).(*deploy.RefreshStep), deploy.OpSame)
+	assert.True(t, report.HasDrift())
+	assert.Equal(t, []DriftedResource{{URN: b.URN, Type: b.Type, Op: deploy.OpDelete}}, report.Resources())
+
+	assert.False(t, NewDriftReport().HasDrift())
+	assert.Empty(t, NewDriftReport().Resources())
+}
+
+func TestRefreshDriftReport(t *testing.T) {
+	drifted := false
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				CreateF: func(urn resource.URN, news resource.PropertyMap,
+					timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
+					return resource.ID(urn.Name()), resource.PropertyMap{"size": resource.NewNumberProperty(1)},
+						resource.StatusOK, nil
+				},
+				ReadF: func(urn resource.URN, id resource.ID,
+					inputs, state resource.PropertyMap) (plugin.ReadResult, resource.Status, error) {
+					if !drifted {
+						return plugin.ReadResult{Inputs: inputs, Outputs: state}, resource.StatusOK, nil
+					}
+					switch id {
+					case "resA":
+						// resA has been resized.
+						outputs := resource.PropertyMap{"size": resource.NewNumberProperty(2)}
+						return plugin.ReadResult{Inputs: inputs, Outputs: outputs}, resource.StatusOK, nil
+					case "resB":
+						// resB has been deleted.
+						return plugin.ReadResult{}, resource.StatusOK, nil
+					default:
+						return plugin.ReadResult{Inputs: inputs, Outputs: state}, resource.StatusOK, nil
+					}
+				},
+			}, nil
+		}),
+	}
+
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		for _, name := range []string{"resA", "resB", "resC"} {
+			_, _, _, err := monitor.RegisterResource("pkgA:m:typA", name, true)
+			assert.NoError(t, err)
+		}
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &TestPlan{Options: UpdateOptions{host: host}}
+	p.Steps = []TestStep{{Op: Update}}
+	snap := p.Run(t, nil)
+
+	// The refresh is previewed and then run with the same report. Each drifted resource is reported once.
+	drifted = true
+	report := NewDriftReport()
+	p.Options.DriftReport = report
+	p.Steps = []TestStep{{Op: Refresh}}
+	snap = p.Run(t, snap)
+
+	project := p.GetProject()
+	urn := func(name string) resource.URN {
+		return resource.NewURN(p.GetTarget(nil).Name, project.Name, "", "pkgA:m:typA", tokens.QName(name))
+	}
+	assert.True(t, report.HasDrift())
+	assert.Equal(t, []DriftedResource{
+		{URN: urn("resA"), Type: "pkgA:m:typA", Op: deploy.OpUpdate, Properties: []resource.PropertyKey{"size"}},
+		{URN: urn("resB"), Type: "pkgA:m:typA", Op: deploy.OpDelete},
+	}, report.Resources())
+
+	// The refresh adopted the drift, so refreshing again finds none.
+	report = NewDriftReport()
+	p.Options.DriftReport = report
+	p.Run(t, snap)
+	assert.False(t, report.HasDrift())
+}
@@ -16,7 +16,10 @@ package engine
 
 import (
 	"bytes"
+	"fmt"
 	"reflect"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/pulumi/pulumi/pkg/apitype"
@@ -28,6 +31,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/resource/plugin"
 	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/crash"
 	"github.com/pulumi/pulumi/pkg/util/logging"
 )
 
@@ -51,6 +55,7 @@ const (
 	ResourceOutputsEvent    EventType = "resource-outputs"
 	ResourceOperationFailed EventType = "resource-operationfailed"
 	PolicyViolationEvent    EventType = "policy-violation"
+	PolicyRemediationEvent  EventType = "policy-remediation"
 )
 
 func cancelEvent() Event {
@@ -80,6 +85,19 @@ type PolicyViolationEventPayload struct {
 	Prefix            string
 }
 
+// PolicyRemediationEventPayload is the payload for an event with type `policy-remediation`.
+type PolicyRemediationEventPayload struct {
+	ResourceURN       resource.URN
+	Message           string
+	Color             colors.Colorization
+	PolicyName        string
+	PolicyPackName    string
+	PolicyPackVersion string
+	Before            resource.PropertyMap // the resource's inputs before the remediation.
+	After             resource.PropertyMap // the resource's inputs after the remediation.
+	Prefix            string
+}
+
 type StdoutEventPayload struct {
 	Message string
 	Color   colors.Colorization
@@ -95,6 +113,14 @@ type SummaryEventPayload struct {
 	MaybeCorrupt    bool            // true if one or more resources may be corrupt
 	Duration        time.Duration   // the duration of the entire update operation (zero values for previews)
 	ResourceChanges ResourceChanges // count of changed resources, useful for reporting
+	Deprecations    map[string]int  // count of uses of each deprecated resource type and property
+
+	// ProviderChanges breaks a preview's changes down by provider package.
+	ProviderChanges map[string]ResourceChanges
+	// CriticalPath is the chain of dependent changes in a preview that is expected to take the longest.
+	CriticalPath []CriticalPathStep
+	// PeakMemory is the peak heap usage of the operation in bytes, or zero if it wasn't measured.
+	PeakMemory uint64
 }
 
 type ResourceOperationFailedPayload struct {
@@ -148,6 +174,8 @@ type StepEventStateMetadata struct {
 	Parent resource.URN
 	// true to "protect" this resource (protected resources cannot be deleted).
 	Protect bool
+	// true if deleting this resource only removes it from the state.
+	RetainOnDelete bool
 	// the resource's input properties (as specified by the program). Note: because this will cross
 	// over rpc boundaries it will be slightly different than the Inputs found in resource_state.
 	// Specifically, secrets will have been filtered out, and large values (like assets) will be
@@ -187,13 +215,97 @@ func makeEventEmitter(events chan<- Event, update UpdateInfo) (eventEmitter, err
 
 	logging.AddGlobalFilter(logging.CreateFilter(secrets, "[secret]"))
 
+	redactions, err := applyRedactionRules(update.GetProject())
+	if err != nil {
+		return eventEmitter{}, err
+	}
+
 	return eventEmitter{
-		Chan: events,
+		Chan:       events,
+		redactions: redactions,
 	}, nil
 }
 
 type eventEmitter struct {
-	Chan chan<- Event
+	Chan       chan<- Event
+	redactions []propertyRedaction // the project's property redaction rules.
+	details    *detailLimit        // if non-nil, limits the resources whose step events carry property details.
+}
+
+// detailLimit tracks the resources whose step events carry property details, up to a maximum number of resources.
+type detailLimit struct {
+	lock sync.Mutex
+	max  int
+	urns map[resource.URN]bool
+}
+
+// allows returns true if the given resource's step events may carry property details. Every event for a resource that
+// is allowed details carries them, so that a resource's display is consistent from its first event to its last.
+func (l *detailLimit) allows(urn resource.URN) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.urns[urn] {
+		return true
+	}
+	if len(l.urns) >= l.max {
+		return false
+	}
+	l.urns[urn] = true
+	return true
+}
+
+// limitDetails limits the number of distinct resources whose step events carry their properties and detailed diffs to
+// the given number. Zero means no limit.
+func (e *eventEmitter) limitDetails(max int) {
+	if max <= 0 {
+		e.details = nil
+		return
+	}
+	e.details = &detailLimit{max: max, urns: make(map[resource.URN]bool)}
+}
+
+// stepEventMetadata returns the event metadata for a step, with the project's property redactions applied. Once the
+// emitter's limit on property details is reached, the metadata leaves them out. The root stack always carries its
+// properties, as they hold the stack's outputs.
+func (e *eventEmitter) stepEventMetadata(op deploy.StepOp, step deploy.Step, debug bool) StepEventMetadata {
+	md := makeStepEventMetadata(op, step, debug)
+	if e.details != nil && md.Type != resource.RootStackType && !e.details.allows(md.URN) {
+		md.DetailedDiff = nil
+		for _, state := range []*StepEventStateMetadata{md.Old, md.New, md.Res} {
+			if state != nil {
+				state.Inputs, state.Outputs = nil, nil
+			}
+		}
+	}
+	return redactStepEventMetadata(md, e.redactions)
+}
+
+// emit sends an event to the event channel, recording a description of it for inclusion in any crash report.
+func (e *eventEmitter) emit(event Event) {
+	crash.RecordEvent(describeEvent(event))
+	e.Chan <- event
+}
+
+// describeEvent returns a one-line description of an event. Messages are left out, as they may contain sensitive
+// information.
+func describeEvent(event Event) string {
+	switch p := event.Payload.(type) {
+	case ResourcePreEventPayload:
+		return fmt.Sprintf("%s %s %s", event.Type, p.Metadata.Op, p.Metadata.URN)
+	case ResourceOutputsEventPayload:
+		return fmt.Sprintf("%s %s %s", event.Type, p.Metadata.Op, p.Metadata.URN)
+	case ResourceOperationFailedPayload:
+		return fmt.Sprintf("%s %s %s", event.Type, p.Metadata.Op, p.Metadata.URN)
+	case DiagEventPayload:
+		return fmt.Sprintf("%s %s %s", event.Type, p.Severity, p.URN)
+	case PolicyViolationEventPayload:
+		return fmt.Sprintf("%s %s %s", event.Type, p.PolicyName, p.ResourceURN)
+	case PolicyRemediationEventPayload:
+		return fmt.Sprintf("%s %s %s", event.Type, p.PolicyName, p.ResourceURN)
+	default:
+		return string(event.Type)
+	}
 }
 
 func makeStepEventMetadata(op deploy.StepOp, step deploy.Step, debug bool) StepEventMetadata {
@@ -235,18 +347,19 @@ func makeStepEventStateMetadata(state *resource.State, debug bool) *StepEventSta
 	}
 
 	return &StepEventStateMetadata{
-		State:      state,
-		Type:       state.Type,
-		URN:        state.URN,
-		Custom:     state.Custom,
-		Delete:     state.Delete,
-		ID:         state.ID,
-		Parent:     state.Parent,
-		Protect:    state.Protect,
-		Inputs:     filterPropertyMap(state.Inputs, debug),
-		Outputs:    filterPropertyMap(state.Outputs, debug),
-		Provider:   state.Provider,
-		InitErrors: state.InitErrors,
+		State:          state,
+		Type:           state.Type,
+		URN:            state.URN,
+		Custom:         state.Custom,
+		Delete:         state.Delete,
+		ID:             state.ID,
+		Parent:         state.Parent,
+		Protect:        state.Protect,
+		RetainOnDelete: state.RetainOnDelete,
+		Inputs:         filterPropertyMap(state.Inputs, debug),
+		Outputs:        filterPropertyMap(state.Outputs, debug),
+		Provider:       state.Provider,
+		InitErrors:     state.InitErrors,
 	}
 }
 
@@ -366,27 +479,28 @@ func (e *eventEmitter) resourceOperationFailedEvent(
 
 	contract.Requiref(e != nil, "e", "!= nil")
 
-	e.Chan <- Event{
+	e.emit(Event{
 		Type: ResourceOperationFailed,
 		Payload: ResourceOperationFailedPayload{
-			Metadata: makeStepEventMetadata(step.Op(), step, debug),
+			Metadata: e.stepEventMetadata(step.Op(), step, debug),
 			Status:   status,
 			Steps:    steps,
 		},
-	}
+	})
 }
 
 func (e *eventEmitter) resourceOutputsEvent(op deploy.StepOp, step deploy.Step, planning bool, debug bool) {
 	contract.Requiref(e != nil, "e", "!= nil")
 
-	e.Chan <- Event{
+	e.emit(Event{
 		Type: ResourceOutputsEvent,
 		Payload: ResourceOutputsEventPayload{
-			Metadata: makeStepEventMetadata(op, step, debug),
+			// Outputs events always carry the resource's properties, as they are the only record of its outputs.
+			Metadata: redactStepEventMetadata(makeStepEventMetadata(op, step, debug), e.redactions),
 			Planning: planning,
 			Debug:    debug,
 		},
-	}
+	})
 }
 
 func (e *eventEmitter) resourcePreEvent(
@@ -394,14 +508,14 @@ func (e *eventEmitter) resourcePreEvent(
 
 	contract.Requiref(e != nil, "e", "!= nil")
 
-	e.Chan <- Event{
+	e.emit(Event{
 		Type: ResourcePreEvent,
 		Payload: ResourcePreEventPayload{
-			Metadata: makeStepEventMetadata(step.Op(), step, debug),
+			Metadata: e.stepEventMetadata(step.Op(), step, debug),
 			Planning: planning,
 			Debug:    debug,
 		},
-	}
+	})
 }
 
 func (e *eventEmitter) preludeEvent(isPreview bool, cfg config.Map) {
@@ -415,42 +529,53 @@ func (e *eventEmitter) preludeEvent(isPreview bool, cfg config.Map) {
 		configStringMap[keyString] = valueString
 	}
 
-	e.Chan <- Event{
+	e.emit(Event{
 		Type: PreludeEvent,
 		Payload: PreludeEventPayload{
 			IsPreview: isPreview,
 			Config:    configStringMap,
 		},
-	}
+	})
 }
 
-func (e *eventEmitter) previewSummaryEvent(resourceChanges ResourceChanges) {
+func (e *eventEmitter) previewSummaryEvent(resourceChanges ResourceChanges, deprecations map[string]int,
+	analysis *planAnalyzer, peakMemory uint64) {
 	contract.Requiref(e != nil, "e", "!= nil")
 
-	e.Chan <- Event{
-		Type: SummaryEvent,
-		Payload: SummaryEventPayload{
-			IsPreview:       true,
-			MaybeCorrupt:    false,
-			Duration:        0,
-			ResourceChanges: resourceChanges,
-		},
+	payload := SummaryEventPayload{
+		IsPreview:       true,
+		MaybeCorrupt:    false,
+		Duration:        0,
+		ResourceChanges: resourceChanges,
+		Deprecations:    deprecations,
+		PeakMemory:      peakMemory,
+	}
+	if analysis != nil {
+		payload.ProviderChanges = analysis.ProviderChanges()
+		payload.CriticalPath = analysis.CriticalPath()
 	}
+
+	e.emit(Event{
+		Type:    SummaryEvent,
+		Payload: payload,
+	})
 }
 
 func (e *eventEmitter) updateSummaryEvent(maybeCorrupt bool,
-	duration time.Duration, resourceChanges ResourceChanges) {
+	duration time.Duration, resourceChanges ResourceChanges, deprecations map[string]int, peakMemory uint64) {
 	contract.Requiref(e != nil, "e", "!= nil")
 
-	e.Chan <- Event{
+	e.emit(Event{
 		Type: SummaryEvent,
 		Payload: SummaryEventPayload{
 			IsPreview:       false,
 			MaybeCorrupt:    maybeCorrupt,
 			Duration:        duration,
 			ResourceChanges: resourceChanges,
+			Deprecations:    deprecations,
+			PeakMemory:      peakMemory,
 		},
-	}
+	})
 }
 
 func (e *eventEmitter) policyViolationEvent(urn resource.URN, d plugin.AnalyzeDiagnostic) {
@@ -481,7 +606,7 @@ func (e *eventEmitter) policyViolationEvent(urn resource.URN, d plugin.AnalyzeDi
 	buffer.WriteString(colors.Reset)
 	buffer.WriteRune('\n')
 
-	e.Chan <- Event{
+	e.emit(Event{
 		Type: PolicyViolationEvent,
 		Payload: PolicyViolationEventPayload{
 			ResourceURN:       urn,
@@ -493,14 +618,66 @@ func (e *eventEmitter) policyViolationEvent(urn resource.URN, d plugin.AnalyzeDi
 			EnforcementLevel:  d.EnforcementLevel,
 			Prefix:            logging.FilterString(prefix.String()),
 		},
+	})
+}
+
+func (e *eventEmitter) policyRemediationEvent(urn resource.URN, r plugin.Remediation,
+	before, after resource.PropertyMap) {
+
+	contract.Requiref(e != nil, "e", "!= nil")
+
+	// Write prefix.
+	var prefix bytes.Buffer
+	prefix.WriteString(colors.SpecInfo)
+	prefix.WriteString("remediated: ")
+	prefix.WriteString(colors.Reset)
+
+	// Write the message itself, naming the policy and the properties it changed.
+	var changed []string
+	if diff := before.Diff(after); diff != nil {
+		for _, k := range diff.Keys() {
+			if diff.Changed(k) {
+				changed = append(changed, string(k))
+			}
+		}
 	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString(colors.SpecNote)
+	buffer.WriteString(fmt.Sprintf("%s/%s", r.PolicyPackName, r.PolicyName))
+	if r.Description != "" {
+		buffer.WriteString(": ")
+		buffer.WriteString(r.Description)
+	}
+	if len(changed) > 0 {
+		buffer.WriteString(fmt.Sprintf(" (changed %s)", strings.Join(changed, ", ")))
+	} else {
+		buffer.WriteString(" (no changes)")
+	}
+	buffer.WriteString(colors.Reset)
+	buffer.WriteRune('\n')
+
+	e.emit(Event{
+		Type: PolicyRemediationEvent,
+		Payload: PolicyRemediationEventPayload{
+			ResourceURN:       urn,
+			Message:           logging.FilterString(buffer.String()),
+			Color:             colors.Raw,
+			PolicyName:        r.PolicyName,
+			PolicyPackName:    r.PolicyPackName,
+			PolicyPackVersion: r.PolicyPackVersion,
+			Before:            filterPropertyMap(before, false),
+			After:             filterPropertyMap(after, false),
+			Prefix:            logging.FilterString(prefix.String()),
+		},
+	})
 }
 
 func diagEvent(e *eventEmitter, d *diag.Diag, prefix, msg string, sev diag.Severity,
 	ephemeral bool) {
 	contract.Requiref(e != nil, "e", "!= nil")
 
-	e.Chan <- Event{
+	e.emit(Event{
 		Type: DiagEvent,
 		Payload: DiagEventPayload{
 			URN:       d.URN,
@@ -511,7 +688,7 @@ func diagEvent(e *eventEmitter, d *diag.Diag, prefix, msg string, sev diag.Sever
 			StreamID:  d.StreamID,
 			Ephemeral: ephemeral,
 		},
-	}
+	})
 }
 
 func (e *eventEmitter) diagDebugEvent(d *diag.Diag, prefix, msg string, ephemeral bool) {
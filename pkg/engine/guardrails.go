@@ -0,0 +1,114 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// guardrailChecker enforces a stack's guardrails. Each step of a preview is checked, so that `pulumi up` stops before
+// an update that would violate them starts. An update constrained to a saved plan has the plan's changes checked at
+// once, before any resource is changed (see validateUpdate). Each step is also checked as it is about to be applied.
+type guardrailChecker struct {
+	guardrails   *workspace.StackGuardrails
+	deniedTypes  map[tokens.Type]bool
+	lock         sync.Mutex
+	deletes      int
+	replacements int
+}
+
+// newGuardrailChecker returns a checker for the given guardrails, or nil if there are none or they are overridden.
+func newGuardrailChecker(guardrails *workspace.StackGuardrails, override bool) *guardrailChecker {
+	if guardrails == nil || override {
+		return nil
+	}
+
+	denied := make(map[tokens.Type]bool)
+	for _, t := range guardrails.DenyDelete {
+		denied[tokens.Type(t)] = true
+	}
+	return &guardrailChecker{guardrails: guardrails, deniedTypes: denied}
+}
+
+// verify checks all of the changes the given plan records, and returns an error for each distinct way in which they
+// violate the guardrails. A nil checker allows every change.
+func (g *guardrailChecker) verify(plan *UpdatePlan) []error {
+	var errs []error
+	seen := make(map[string]bool)
+	for _, urn := range plan.Resources() {
+		for _, op := range plan.Ops(urn) {
+			// Once a limit is exceeded, every further change exceeds it in the same way; report that only once.
+			if err := g.checkOp(op, urn.Type()); err != nil && !seen[err.Error()] {
+				seen[err.Error()] = true
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// check records the given step and returns an error if it violates the guardrails. A nil checker allows every step.
+func (g *guardrailChecker) check(step deploy.Step) error {
+	return g.checkOp(step.Op(), step.Type())
+}
+
+// checkOp records an operation on a resource of the given type, and returns an error if it violates the guardrails.
+func (g *guardrailChecker) checkOp(op deploy.StepOp, typ tokens.Type) error {
+	if g == nil || providers.IsProviderType(typ) {
+		return nil
+	}
+
+	var replace bool
+	switch op {
+	case deploy.OpDelete:
+	case deploy.OpReplace:
+		replace = true
+	default:
+		// Only deletions and replacements are limited. The OpDeleteReplaced and OpCreateReplacement halves of a
+		// replacement are accounted for by its OpReplace step.
+		return nil
+	}
+
+	if g.deniedTypes[typ] {
+		return errors.Errorf("guardrails deny deleting or replacing resources of type %s; "+
+			"rerun with --override-guardrails to proceed anyway", typ)
+	}
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if replace {
+		g.replacements++
+		if max := g.guardrails.MaxReplacements; max > 0 && g.replacements > max {
+			return errors.Errorf("this update would replace more than %d resources, the most allowed by the stack's "+
+				"guardrails; rerun with --override-guardrails to proceed anyway", max)
+		}
+		return nil
+	}
+
+	g.deletes++
+	if max := g.guardrails.MaxDeletes; max > 0 && g.deletes > max {
+		return errors.Errorf("this update would delete more than %d resources, the most allowed by the stack's "+
+			"guardrails; rerun with --override-guardrails to proceed anyway", max)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestGuardrailChecker(t *testing.T) {
+	state := func(typ tokens.Type, name string) *resource.State {
+		urn := resource.NewURN("dev", "proj", "", typ, tokens.QName(name))
+		return &resource.State{URN: urn, Type: typ}
+	}
+	del := func(typ tokens.Type, name string) deploy.Step {
+		return deploy.NewDeleteStep(nil, state(typ, name))
+	}
+	replace := func(typ tokens.Type, name string) deploy.Step {
+		return deploy.NewReplaceStep(nil, state(typ, name), state(typ, name), nil, nil, nil, false)
+	}
+
+	guardrails := &workspace.StackGuardrails{
+		MaxDeletes:      1,
+		MaxReplacements: 1,
+		DenyDelete:      []string{"aws:rds/instance:Instance"},
+	}
+
+	// Without guardrails, or when they are overridden, every step is allowed.
+	for _, g := range []*guardrailChecker{newGuardrailChecker(nil, false), newGuardrailChecker(guardrails, true)} {
+		assert.Nil(t, g)
+		assert.NoError(t, g.check(del("aws:rds/instance:Instance", "db")))
+	}
+
+	g := newGuardrailChecker(guardrails, false)
+	assert.NoError(t, g.check(del("aws:s3/bucket:Bucket", "a")))
+	assert.Error(t, g.check(del("aws:s3/bucket:Bucket", "b")))
+	assert.NoError(t, g.check(replace("aws:s3/bucket:Bucket", "c")))
+	assert.Error(t, g.check(replace("aws:s3/bucket:Bucket", "d")))
+
+	// Denied types may be neither deleted nor replaced, regardless of the limits.
+	g = newGuardrailChecker(guardrails, false)
+	assert.Error(t, g.check(replace("aws:rds/instance:Instance", "db")))
+	assert.Error(t, g.check(del("aws:rds/instance:Instance", "db")))
+
+	// Providers are not counted.
+	g = newGuardrailChecker(guardrails, false)
+	assert.NoError(t, g.check(del("pulumi:providers:aws", "p1")))
+	assert.NoError(t, g.check(del("pulumi:providers:aws", "p2")))
+	assert.NoError(t, g.check(del("aws:s3/bucket:Bucket", "a")))
+
+	// Verifying the changes a plan records reports each violation once.
+	plan := NewUpdatePlan()
+	for _, step := range []deploy.Step{
+		del("aws:s3/bucket:Bucket", "a"),
+		del("aws:s3/bucket:Bucket", "b"),
+		del("aws:s3/bucket:Bucket", "c"),
+		del("aws:rds/instance:Instance", "db"),
+	} {
+		assert.NoError(t, plan.record(step))
+	}
+	assert.Len(t, newGuardrailChecker(guardrails, false).verify(plan), 2)
+	assert.Nil(t, newGuardrailChecker(nil, false).verify(plan))
+}
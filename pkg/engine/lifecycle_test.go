@@ -349,6 +349,7 @@ type TestPlan struct {
 	Config        config.Map
 	Decrypter     config.Decrypter
 	BackendClient deploy.BackendClient
+	Guardrails    *workspace.StackGuardrails
 	Options       UpdateOptions
 	Steps         []TestStep
 }
@@ -401,10 +402,11 @@ func (p *TestPlan) GetTarget(snapshot *deploy.Snapshot) deploy.Target {
 	}
 
 	return deploy.Target{
-		Name:      stack,
-		Config:    cfg,
-		Decrypter: p.Decrypter,
-		Snapshot:  snapshot,
+		Name:       stack,
+		Config:     cfg,
+		Decrypter:  p.Decrypter,
+		Snapshot:   snapshot,
+		Guardrails: p.Guardrails,
 	}
 }
 
@@ -1512,6 +1514,69 @@ func TestCheckFailureInvalidPropertyRecord(t *testing.T) {
 
 }
 
+// Test that check-only previews carry on past resources that fail their checks, so that every resource is checked,
+// and fail once all resources have been checked.
+func TestCheckOnlyChecksAllResources(t *testing.T) {
+	var checked []string
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				CheckF: func(urn resource.URN,
+					olds, news resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error) {
+					checked = append(checked, string(urn.Name()))
+					if urn.Name() == "resC" {
+						return news, nil, nil
+					}
+					return nil, []plugin.CheckFailure{{
+						Property: "someprop",
+						Reason:   "field is not valid",
+					}}, nil
+				},
+			}, nil
+		}),
+	}
+
+	var registered []string
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		for _, name := range []string{"resA", "resB", "resC"} {
+			if _, _, _, err := monitor.RegisterResource("pkgA:m:typA", name, true); err != nil {
+				return err
+			}
+			registered = append(registered, name)
+		}
+		return nil
+	})
+
+	p := &TestPlan{}
+	p.Options.host = deploytest.NewPluginHost(nil, nil, program, loaders...)
+	project := p.GetProject()
+
+	// A normal preview stops at the first resource that fails its checks.
+	_, res := TestOp(Update).Run(project, p.GetTarget(nil), p.Options, true, p.BackendClient, nil)
+	assert.NotNil(t, res)
+	assert.Equal(t, []string{"resA"}, checked)
+	assert.Empty(t, registered)
+
+	// A check-only preview checks every resource, and still fails.
+	checked, registered = nil, nil
+	p.Options.CheckOnly = true
+	_, res = TestOp(Update).Run(project, p.GetTarget(nil), p.Options, true, p.BackendClient, nil)
+	assert.NotNil(t, res)
+	assert.Equal(t, []string{"resA", "resB", "resC"}, checked)
+	assert.Equal(t, []string{"resA", "resB", "resC"}, registered)
+
+	// A check-only preview whose resources all pass their checks succeeds.
+	checked, registered = nil, nil
+	program = deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resC", true)
+		return err
+	})
+	p.Options.host = deploytest.NewPluginHost(nil, nil, program, loaders...)
+	_, res = TestOp(Update).Run(project, p.GetTarget(nil), p.Options, true, p.BackendClient, nil)
+	assert.Nil(t, res)
+	assert.Equal(t, []string{"resC"}, checked)
+}
+
 // Test that tests that Refresh can detect that resources have been deleted and removes them
 // from the snapshot.
 func TestRefreshWithDelete(t *testing.T) {
@@ -3963,6 +4028,140 @@ func TestImport(t *testing.T) {
 	assert.Nil(t, res)
 }
 
+func TestImportSource(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				DiffF: func(urn resource.URN, id resource.ID,
+					olds, news resource.PropertyMap, ignoreChanges []string) (plugin.DiffResult, error) {
+
+					if olds["foo"].DeepEquals(news["foo"]) {
+						return plugin.DiffResult{Changes: plugin.DiffNone}, nil
+					}
+					return plugin.DiffResult{Changes: plugin.DiffSome, ChangedKeys: []resource.PropertyKey{"foo"}}, nil
+				},
+				CreateF: func(urn resource.URN,
+					news resource.PropertyMap, timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
+
+					return "created-id", news, resource.StatusOK, nil
+				},
+				ReadF: func(urn resource.URN, id resource.ID,
+					inputs, state resource.PropertyMap) (plugin.ReadResult, resource.Status, error) {
+
+					if id != "existing-id" {
+						return plugin.ReadResult{}, resource.StatusOK, nil
+					}
+					return plugin.ReadResult{
+						Inputs:  resource.PropertyMap{"foo": resource.NewStringProperty("bar")},
+						Outputs: resource.PropertyMap{"foo": resource.NewStringProperty("bar")},
+					}, resource.StatusOK, nil
+				},
+			}, nil
+		}),
+	}
+
+	p := &TestPlan{}
+	stackName, projectName, _ := p.getNames()
+	rootURN := resource.DefaultRootStackURN(stackName, projectName)
+	provURN := p.NewProviderURN("pkgA", "default", "")
+	resAURN := p.NewURN("pkgA:m:typA", "resA", "")
+	resBURN := p.NewURN("pkgA:m:typA", "resB", "")
+
+	// The program registers the stack's root resource and resB, and once resA has been imported, resA too.
+	var adopted bool
+	inputs := resource.PropertyMap{"foo": resource.NewStringProperty("bar")}
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		root, _, _, err := monitor.RegisterResource(resource.RootStackType, string(rootURN.Name()), false)
+		assert.NoError(t, err)
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resB", true, deploytest.ResourceOptions{
+			Parent: root,
+		})
+		assert.NoError(t, err)
+		if adopted {
+			_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resA", true, deploytest.ResourceOptions{
+				Parent: root,
+				Inputs: inputs,
+			})
+			assert.NoError(t, err)
+		}
+		return nil
+	})
+	p.Options.host = deploytest.NewPluginHost(nil, nil, program, loaders...)
+	project := p.GetProject()
+
+	// expectOps returns a validation function that checks that the update performed the given operation on each
+	// resource, and on no others.
+	expectOps := func(ops map[resource.URN]deploy.StepOp) ValidateFunc {
+		return func(_ workspace.Project, _ deploy.Target, j *Journal, _ []Event, res result.Result) result.Result {
+			seen := make(map[resource.URN]deploy.StepOp)
+			for _, entry := range j.Entries {
+				seen[entry.Step.URN()] = entry.Step.Op()
+			}
+			assert.Equal(t, ops, seen)
+			return res
+		}
+	}
+
+	// Create the stack's root resource and resB.
+	snap, res := TestOp(Update).Run(project, p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+	assert.Nil(t, res)
+	assert.Len(t, snap.Resources, 3)
+
+	// Importing a resource that does not exist fails.
+	importOpts := p.Options
+	importOpts.Imports = []deploy.Import{{Type: "pkgA:m:typA", Name: "resA", ID: "missing-id"}}
+	_, res = TestOp(Update).Run(project, p.GetTarget(snap), importOpts, false, p.BackendClient, nil)
+	assert.NotNil(t, res)
+
+	// Import resA without running the program. The stack's root resource and default provider are reused, and resB
+	// is left as it is.
+	importOpts.Imports = []deploy.Import{{Type: "pkgA:m:typA", Name: "resA", ID: "existing-id"}}
+	snap, res = TestOp(Update).Run(project, p.GetTarget(snap), importOpts, false, p.BackendClient,
+		expectOps(map[resource.URN]deploy.StepOp{
+			rootURN: deploy.OpSame,
+			provURN: deploy.OpSame,
+			resAURN: deploy.OpImport,
+		}))
+	assert.Nil(t, res)
+	assert.Len(t, snap.Resources, 4)
+	for _, r := range snap.Resources {
+		if r.URN == resAURN {
+			assert.Equal(t, resource.ID("existing-id"), r.ID)
+			assert.Equal(t, rootURN, r.Parent)
+			assert.Equal(t, inputs, r.Inputs)
+		}
+	}
+
+	// Adopt resA into the program with the inputs it was imported with. Nothing changes.
+	adopted = true
+	snap, res = TestOp(Update).Run(project, p.GetTarget(snap), p.Options, false, p.BackendClient,
+		expectOps(map[resource.URN]deploy.StepOp{
+			rootURN: deploy.OpSame,
+			provURN: deploy.OpSame,
+			resAURN: deploy.OpSame,
+			resBURN: deploy.OpSame,
+		}))
+	assert.Nil(t, res)
+	assert.Len(t, snap.Resources, 4)
+
+	// Change resA's inputs. It is updated in place, keeping the ID it was imported with.
+	inputs = resource.PropertyMap{"foo": resource.NewStringProperty("baz")}
+	snap, res = TestOp(Update).Run(project, p.GetTarget(snap), p.Options, false, p.BackendClient,
+		expectOps(map[resource.URN]deploy.StepOp{
+			rootURN: deploy.OpSame,
+			provURN: deploy.OpSame,
+			resAURN: deploy.OpUpdate,
+			resBURN: deploy.OpSame,
+		}))
+	assert.Nil(t, res)
+	for _, r := range snap.Resources {
+		if r.URN == resAURN {
+			assert.Equal(t, resource.ID("existing-id"), r.ID)
+			assert.Equal(t, inputs, r.Inputs)
+		}
+	}
+}
+
 func TestCustomTimeouts(t *testing.T) {
 	loaders := []*deploytest.ProviderLoader{
 		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
@@ -4173,3 +4372,438 @@ func TestMissingRead(t *testing.T) {
 	}
 	p.Run(t, nil)
 }
+
+func TestRetainOnDelete(t *testing.T) {
+	deletes := 0
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				CreateF: func(urn resource.URN, inputs resource.PropertyMap,
+					timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
+					return "created-id", inputs, resource.StatusOK, nil
+				},
+				DeleteF: func(urn resource.URN, id resource.ID, olds resource.PropertyMap,
+					timeout float64) (resource.Status, error) {
+					deletes++
+					return resource.StatusOK, nil
+				},
+			}, nil
+		}),
+	}
+
+	register := true
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		if register {
+			_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, deploytest.ResourceOptions{
+				RetainOnDelete: true,
+			})
+			assert.NoError(t, err)
+			_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resB", true)
+			assert.NoError(t, err)
+		}
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+	p := &TestPlan{
+		Options: UpdateOptions{host: host},
+		Steps:   []TestStep{{Op: Update}},
+	}
+
+	// The retainOnDelete option is recorded in the state.
+	snap := p.Run(t, nil)
+	assert.Len(t, snap.Resources, 3)
+	assert.True(t, snap.Resources[1].RetainOnDelete)
+	assert.False(t, snap.Resources[2].RetainOnDelete)
+
+	// Removing the resources from the program deletes "resB", but only drops "resA" from the state.
+	register = false
+	snap = p.Run(t, snap)
+	assert.Len(t, snap.Resources, 0)
+	assert.Equal(t, 1, deletes)
+
+	// The same is true of a destroy.
+	register, deletes = true, 0
+	snap = p.Run(t, nil)
+	assert.Len(t, snap.Resources, 3)
+	p.Steps = []TestStep{{Op: Destroy}}
+	snap = p.Run(t, snap)
+	assert.Len(t, snap.Resources, 0)
+	assert.Equal(t, 1, deletes)
+}
+
+func TestLockedResource(t *testing.T) {
+	updates := make(map[string]int)
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				CreateF: func(urn resource.URN, inputs resource.PropertyMap,
+					timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
+					return "created-id", inputs, resource.StatusOK, nil
+				},
+				UpdateF: func(urn resource.URN, id resource.ID, olds, news resource.PropertyMap, timeout float64,
+					ignoreChanges []string) (resource.PropertyMap, resource.Status, error) {
+					updates[string(urn.Name())]++
+					return news, resource.StatusOK, nil
+				},
+			}, nil
+		}),
+	}
+
+	value, locked := "foo", true
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		inputs := resource.PropertyMap{"foo": resource.NewStringProperty(value)}
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, deploytest.ResourceOptions{
+			Inputs: inputs,
+		})
+		if err != nil {
+			return err
+		}
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resB", true, deploytest.ResourceOptions{
+			Inputs: inputs,
+			Locked: locked,
+		})
+		return err
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+	p := &TestPlan{
+		Options: UpdateOptions{host: host},
+		Steps:   []TestStep{{Op: Update}},
+	}
+
+	// The locked option is recorded in the state.
+	snap := p.Run(t, nil)
+	assert.Len(t, snap.Resources, 3)
+	assert.False(t, snap.Resources[1].Locked)
+	assert.True(t, snap.Resources[2].Locked)
+
+	// Changing both resources would update the locked "resB", so the update's preview fails, and the update is never
+	// run.
+	value = "bar"
+	p.Steps = []TestStep{{Op: Update, ExpectFailure: true}}
+	p.Run(t, CloneSnapshot(t, snap))
+	assert.Empty(t, updates)
+
+	// An update constrained to a saved plan that would update "resB" is refused before any resource is updated.
+	unlocked := CloneSnapshot(t, snap)
+	unlocked.Resources[2].Locked = false
+	p.Options.SavePlan = NewUpdatePlan()
+	_, res := TestOp(Update).Run(p.GetProject(), p.GetTarget(unlocked), p.Options, true, nil, nil)
+	if !assert.Nil(t, res) {
+		return
+	}
+	p.Options.Plan, p.Options.SavePlan = p.Options.SavePlan, nil
+	p.Steps = []TestStep{{Op: Update, SkipPreview: true, ExpectFailure: true}}
+	p.Run(t, CloneSnapshot(t, snap))
+	assert.Empty(t, updates)
+
+	// With neither a preview nor a plan, the update is refused before "resB" is updated.
+	p.Options.Plan = nil
+	p.Run(t, CloneSnapshot(t, snap))
+	assert.Equal(t, 0, updates["resB"])
+
+	// Once the resource is unlocked and the option removed, the update goes ahead.
+	updates = make(map[string]int)
+	snap.Resources[2].Locked, locked = false, false
+	p.Steps = []TestStep{{Op: Update, SkipPreview: true}}
+	snap = p.Run(t, snap)
+	assert.Equal(t, map[string]int{"resA": 1, "resB": 1}, updates)
+	assert.False(t, snap.Resources[2].Locked)
+}
+
+// remediatingAnalyzer is a policy pack that makes every bucket private.
+type remediatingAnalyzer struct{}
+
+func (a *remediatingAnalyzer) Close() error       { return nil }
+func (a *remediatingAnalyzer) Name() tokens.QName { return "remediator" }
+
+func (a *remediatingAnalyzer) Analyze(t tokens.Type,
+	props resource.PropertyMap) ([]plugin.AnalyzeDiagnostic, []plugin.Remediation, error) {
+
+	if acl, has := props["acl"]; has && acl.StringValue() != "private" {
+		remediated := props.Copy()
+		remediated["acl"] = resource.NewStringProperty("private")
+		return nil, []plugin.Remediation{{
+			PolicyName:     "private-buckets",
+			PolicyPackName: "remediator",
+			Description:    "made the bucket private",
+			Properties:     remediated,
+		}}, nil
+	}
+	return nil, nil, nil
+}
+
+func (a *remediatingAnalyzer) GetAnalyzerInfo() (plugin.AnalyzerInfo, error) {
+	return plugin.AnalyzerInfo{Name: "remediator"}, nil
+}
+
+func (a *remediatingAnalyzer) GetPluginInfo() (workspace.PluginInfo, error) {
+	return workspace.PluginInfo{Name: "remediator", Kind: workspace.AnalyzerPlugin}, nil
+}
+
+// analyzerHost is a plugin host that runs the given policy packs.
+type analyzerHost struct {
+	plugin.Host
+	analyzers []plugin.Analyzer
+}
+
+func (host *analyzerHost) ListAnalyzers() []plugin.Analyzer {
+	return host.analyzers
+}
+
+func TestPolicyRemediation(t *testing.T) {
+	var created resource.PropertyMap
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				CreateF: func(urn resource.URN, inputs resource.PropertyMap,
+					timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
+					created = inputs
+					return "created-id", inputs, resource.StatusOK, nil
+				},
+			}, nil
+		}),
+	}
+
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, deploytest.ResourceOptions{
+			Inputs: resource.PropertyMap{
+				"acl":  resource.NewStringProperty("public-read"),
+				"name": resource.NewStringProperty("bucket"),
+			},
+		})
+		assert.NoError(t, err)
+		return nil
+	})
+	host := &analyzerHost{
+		Host:      deploytest.NewPluginHost(nil, nil, program, loaders...),
+		analyzers: []plugin.Analyzer{&remediatingAnalyzer{}},
+	}
+
+	p := &TestPlan{
+		Options: UpdateOptions{host: host},
+		Steps: []TestStep{{
+			Op: Update,
+			Validate: func(project workspace.Project, target deploy.Target, j *Journal,
+				events []Event, res result.Result) result.Result {
+
+				var remediations []PolicyRemediationEventPayload
+				for _, e := range events {
+					if e.Type == PolicyRemediationEvent {
+						remediations = append(remediations, e.Payload.(PolicyRemediationEventPayload))
+					}
+				}
+				if assert.Len(t, remediations, 1) {
+					r := remediations[0]
+					assert.Equal(t, "private-buckets", r.PolicyName)
+					assert.Equal(t, "public-read", r.Before["acl"].StringValue())
+					assert.Equal(t, "private", r.After["acl"].StringValue())
+					assert.Contains(t, r.Message, "remediator/private-buckets: made the bucket private (changed acl)")
+				}
+				return res
+			},
+		}},
+	}
+
+	// The provider creates the resource with the remediated inputs, and those are what the state records.
+	snap := p.Run(t, nil)
+	assert.Equal(t, "private", created["acl"].StringValue())
+	assert.Equal(t, "bucket", created["name"].StringValue())
+	if assert.Len(t, snap.Resources, 2) {
+		assert.Equal(t, "private", snap.Resources[1].Inputs["acl"].StringValue())
+	}
+}
+
+func TestGuardrailsChecked(t *testing.T) {
+	deletes := 0
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				DeleteF: func(urn resource.URN, id resource.ID, olds resource.PropertyMap,
+					timeout float64) (resource.Status, error) {
+					deletes++
+					return resource.StatusOK, nil
+				},
+			}, nil
+		}),
+	}
+
+	names := []string{"resA", "resB", "resC"}
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		for _, name := range names {
+			_, _, _, err := monitor.RegisterResource("pkgA:m:typA", name, true)
+			assert.NoError(t, err)
+		}
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &TestPlan{Options: UpdateOptions{host: host}}
+	p.Steps = []TestStep{{Op: Update}}
+	snap := p.Run(t, nil)
+	assert.Len(t, snap.Resources, 4)
+
+	// Removing every resource from the program would delete more resources than the guardrails allow, so the update's
+	// preview fails, and the update is never run.
+	names = nil
+	p.Guardrails = &workspace.StackGuardrails{MaxDeletes: 2}
+	p.Steps = []TestStep{{Op: Update, ExpectFailure: true}}
+	p.Run(t, CloneSnapshot(t, snap))
+	assert.Equal(t, 0, deletes)
+
+	// An update constrained to a saved plan that violates the guardrails is refused before any resource is deleted.
+	p.Options = UpdateOptions{host: host, SavePlan: NewUpdatePlan(), OverrideGuardrails: true}
+	_, res := TestOp(Update).Run(p.GetProject(), p.GetTarget(CloneSnapshot(t, snap)), p.Options, true, nil, nil)
+	if !assert.Nil(t, res) {
+		return
+	}
+	p.Options = UpdateOptions{host: host, Plan: p.Options.SavePlan}
+	p.Steps = []TestStep{{Op: Update, SkipPreview: true, ExpectFailure: true}}
+	p.Run(t, CloneSnapshot(t, snap))
+	assert.Equal(t, 0, deletes)
+
+	// An update with neither a preview nor a plan is refused before any resource is deleted, even those within the
+	// guardrails.
+	p.Options = UpdateOptions{host: host}
+	p.Run(t, CloneSnapshot(t, snap))
+	assert.Equal(t, 0, deletes)
+
+	// An update constrained to the plan of its preview is allowed if the plan stays within the guardrails.
+	names = []string{"resA", "resB"}
+	p.Options = UpdateOptions{host: host, SavePlan: NewUpdatePlan()}
+	_, res = TestOp(Update).Run(p.GetProject(), p.GetTarget(CloneSnapshot(t, snap)), p.Options, true, nil, nil)
+	if !assert.Nil(t, res) {
+		return
+	}
+	p.Options = UpdateOptions{host: host, Plan: p.Options.SavePlan}
+	p.Steps = []TestStep{{Op: Update, SkipPreview: true}}
+	p.Run(t, CloneSnapshot(t, snap))
+	assert.Equal(t, 1, deletes)
+
+	// Overriding the guardrails allows the update, with neither a preview nor a plan.
+	deletes = 0
+	names = nil
+	p.Options = UpdateOptions{host: host, OverrideGuardrails: true}
+	p.Steps = []TestStep{{Op: Update, SkipPreview: true}}
+	snap = p.Run(t, snap)
+	assert.Equal(t, 3, deletes)
+	assert.Len(t, snap.Resources, 0)
+}
+
+func TestPolicyRemediationChecked(t *testing.T) {
+	creates := 0
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				// Private buckets must name an owner, which the program doesn't.
+				CheckF: func(urn resource.URN,
+					olds, news resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error) {
+					if news["acl"].StringValue() == "private" && !news.HasValue("owner") {
+						return nil, []plugin.CheckFailure{{Property: "owner", Reason: "private buckets need an owner"}}, nil
+					}
+					return news, nil, nil
+				},
+				CreateF: func(urn resource.URN, inputs resource.PropertyMap,
+					timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
+					creates++
+					return "created-id", inputs, resource.StatusOK, nil
+				},
+			}, nil
+		}),
+	}
+
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, deploytest.ResourceOptions{
+			Inputs: resource.PropertyMap{
+				"acl": resource.NewStringProperty("public-read"),
+			},
+		})
+		assert.Error(t, err)
+		return err
+	})
+	host := &analyzerHost{
+		Host:      deploytest.NewPluginHost(nil, nil, program, loaders...),
+		analyzers: []plugin.Analyzer{&remediatingAnalyzer{}},
+	}
+
+	// The program's inputs pass the provider's checks, but the remediated inputs don't, so the update fails.
+	p := &TestPlan{
+		Options: UpdateOptions{host: host},
+		Steps:   []TestStep{{Op: Update, ExpectFailure: true, SkipPreview: true}},
+	}
+	p.Run(t, nil)
+	assert.Equal(t, 0, creates)
+}
+
+func TestUpdatePlanChecked(t *testing.T) {
+	creates := make(map[string]int)
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				CreateF: func(urn resource.URN, inputs resource.PropertyMap,
+					timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
+					creates[string(urn.Name())]++
+					return "created-id", inputs, resource.StatusOK, nil
+				},
+			}, nil
+		}),
+	}
+
+	size := 1.0
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, deploytest.ResourceOptions{
+			Inputs: resource.PropertyMap{"name": resource.NewStringProperty("a")},
+		})
+		assert.NoError(t, err)
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resB", true, deploytest.ResourceOptions{
+			Inputs: resource.PropertyMap{"size": resource.NewNumberProperty(size)},
+		})
+		assert.NoError(t, err)
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	// Save a plan with a preview.
+	p := &TestPlan{Options: UpdateOptions{host: host, SavePlan: NewUpdatePlan()}}
+	_, res := TestOp(Update).Run(p.GetProject(), p.GetTarget(nil), p.Options, true, nil, nil)
+	if !assert.Nil(t, res) {
+		return
+	}
+	plan := p.Options.SavePlan
+	assert.Len(t, plan.Resources(), 3)
+
+	// An update that would give the last resource different inputs fails its preview, before any resource is created.
+	size = 2
+	p.Options = UpdateOptions{host: host, Plan: plan}
+	p.Steps = []TestStep{{
+		Op:            Update,
+		ExpectFailure: true,
+		Validate: func(project workspace.Project, target deploy.Target, j *Journal,
+			evts []Event, res result.Result) result.Result {
+
+			var refused bool
+			for _, evt := range evts {
+				if evt.Type == DiagEvent {
+					e := evt.Payload.(DiagEventPayload)
+					refused = refused || strings.Contains(e.Message, "input 'size'") && e.Severity == diag.Error
+				}
+			}
+			assert.True(t, refused)
+			return res
+		},
+	}}
+	p.Run(t, nil)
+	assert.Empty(t, creates)
+
+	// With the preview skipped, the update fails before that resource is created.
+	p.Steps = []TestStep{{Op: Update, SkipPreview: true, ExpectFailure: true}}
+	p.Run(t, nil)
+	assert.Equal(t, 0, creates["resB"])
+
+	// The update the plan was made for succeeds.
+	creates = make(map[string]int)
+	size = 1
+	p.Steps = []TestStep{{Op: Update, SkipPreview: true}}
+	snap := p.Run(t, nil)
+	assert.Len(t, snap.Resources, 3)
+	assert.Equal(t, map[string]int{"resA": 1, "resB": 1}, creates)
+}
@@ -0,0 +1,190 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+)
+
+// MemoryOptions bounds the memory an operation uses, so that stacks with very many resources can be updated within
+// tight memory limits, at some cost in speed and in the detail the operation reports.
+type MemoryOptions struct {
+	// true if equal strings in resources' properties should share storage. Large stacks tend to repeat the same
+	// values, such as regions, account IDs, and tags, across thousands of resources.
+	InternProperties bool
+
+	// the number of resources whose events carry their properties and detailed diffs. Other resources' events carry
+	// only their identities and operations, so their changes are displayed in less detail. The root stack and outputs
+	// events are not limited. Zero means no limit.
+	MaxDiffDetails int
+
+	// true if self-managed backends should write snapshots as they were serialized, without first formatting them for
+	// readability, so that no second copy of each snapshot is held in memory.
+	StreamSnapshots bool
+
+	// true if the operation's peak heap usage should be measured and reported in its summary.
+	ReportPeakUsage bool
+}
+
+// memorySampleInterval is how often a memoryMonitor samples the heap usage of the process.
+var memorySampleInterval = 500 * time.Millisecond
+
+// memoryMonitor samples the heap usage of the process while an operation runs, and records the peak.
+type memoryMonitor struct {
+	peak uint64
+	stop chan bool
+	done chan bool
+}
+
+// startMemoryMonitor starts sampling the heap usage of the process, if the given options ask for it to be reported.
+// Otherwise, it returns nil, which measures nothing.
+func startMemoryMonitor(opts MemoryOptions) *memoryMonitor {
+	if !opts.ReportPeakUsage {
+		return nil
+	}
+
+	m := &memoryMonitor{stop: make(chan bool), done: make(chan bool)}
+	m.sample()
+	go func() {
+		defer close(m.done)
+
+		ticker := time.NewTicker(memorySampleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.sample()
+			case <-m.stop:
+				return
+			}
+		}
+	}()
+	return m
+}
+
+func (m *memoryMonitor) sample() {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	if stats.HeapAlloc > atomic.LoadUint64(&m.peak) {
+		atomic.StoreUint64(&m.peak, stats.HeapAlloc)
+	}
+}
+
+// Peak returns the peak heap usage observed so far, in bytes. A nil monitor observes nothing.
+func (m *memoryMonitor) Peak() uint64 {
+	if m == nil {
+		return 0
+	}
+
+	m.sample()
+	return atomic.LoadUint64(&m.peak)
+}
+
+// Stop stops sampling. It does nothing to a nil monitor.
+func (m *memoryMonitor) Stop() {
+	if m == nil {
+		return
+	}
+
+	close(m.stop)
+	<-m.done
+}
+
+// propertyInterner makes equal strings in resources' properties share storage. A nil interner leaves properties
+// as they are.
+type propertyInterner struct {
+	lock    sync.Mutex
+	strings map[string]string
+}
+
+// newPropertyInterner returns an interner if the given options ask for properties to be interned, and nil otherwise.
+func newPropertyInterner(opts MemoryOptions) *propertyInterner {
+	if !opts.InternProperties {
+		return nil
+	}
+	return &propertyInterner{strings: make(map[string]string)}
+}
+
+func (i *propertyInterner) intern(s string) string {
+	if interned, ok := i.strings[s]; ok {
+		return interned
+	}
+	i.strings[s] = s
+	return s
+}
+
+// internSnapshot interns the properties of each of the resources in the given snapshot.
+func (i *propertyInterner) internSnapshot(snap *deploy.Snapshot) {
+	if i == nil || snap == nil {
+		return
+	}
+	for _, res := range snap.Resources {
+		i.internState(res)
+	}
+}
+
+// internState interns the properties of the given resource, replacing them in place. The resource must not be in use
+// elsewhere while its properties are interned.
+func (i *propertyInterner) internState(state *resource.State) {
+	if i == nil || state == nil {
+		return
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	state.Inputs = i.internMap(state.Inputs)
+	state.Outputs = i.internMap(state.Outputs)
+}
+
+func (i *propertyInterner) internMap(m resource.PropertyMap) resource.PropertyMap {
+	if m == nil {
+		return nil
+	}
+
+	interned := make(resource.PropertyMap, len(m))
+	for k, v := range m {
+		interned[resource.PropertyKey(i.intern(string(k)))] = i.internValue(v)
+	}
+	return interned
+}
+
+func (i *propertyInterner) internValue(v resource.PropertyValue) resource.PropertyValue {
+	switch {
+	case v.IsString():
+		return resource.NewStringProperty(i.intern(v.StringValue()))
+	case v.IsArray():
+		arr := make([]resource.PropertyValue, len(v.ArrayValue()))
+		for j, elem := range v.ArrayValue() {
+			arr[j] = i.internValue(elem)
+		}
+		return resource.NewArrayProperty(arr)
+	case v.IsObject():
+		return resource.NewObjectProperty(i.internMap(v.ObjectValue()))
+	case v.IsSecret():
+		return resource.MakeSecret(i.internValue(v.SecretValue().Element))
+	case v.IsComputed():
+		return resource.MakeComputed(i.internValue(v.Input().Element))
+	case v.IsOutput():
+		return resource.MakeOutput(i.internValue(v.OutputValue().Element))
+	default:
+		return v
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+// stringData returns the address of the given string's bytes.
+func stringData(s string) uintptr {
+	return (*reflect.StringHeader)(unsafe.Pointer(&s)).Data
+}
+
+func TestPropertyInterner(t *testing.T) {
+	region := func() string { return string([]byte("us-west-2")) }
+	state := func(name string) *resource.State {
+		urn := resource.NewURN("dev", "proj", "", "test:index:Resource", "a")
+		return &resource.State{
+			URN:  urn,
+			Type: urn.Type(),
+			Inputs: resource.PropertyMap{
+				"region": resource.NewStringProperty(region()),
+				"tags": resource.NewObjectProperty(resource.PropertyMap{
+					"name": resource.NewStringProperty(name),
+				}),
+			},
+			Outputs: resource.PropertyMap{
+				"regions": resource.NewArrayProperty([]resource.PropertyValue{
+					resource.MakeSecret(resource.NewStringProperty(region())),
+				}),
+			},
+		}
+	}
+	a, b := state("a"), state("b")
+
+	// A nil interner leaves properties as they are.
+	var none *propertyInterner
+	none.internSnapshot(deploy.NewSnapshot(deploy.Manifest{}, nil, []*resource.State{a, b}, nil))
+	assert.NotEqual(t, stringData(a.Inputs["region"].StringValue()), stringData(b.Inputs["region"].StringValue()))
+
+	interner := newPropertyInterner(MemoryOptions{InternProperties: true})
+	interner.internSnapshot(deploy.NewSnapshot(deploy.Manifest{}, nil, []*resource.State{a}, nil))
+	interner.internState(b)
+
+	// Equal strings share storage, however deeply they are nested, and the properties are otherwise unchanged.
+	shared := stringData(a.Inputs["region"].StringValue())
+	assert.Equal(t, shared, stringData(b.Inputs["region"].StringValue()))
+	for _, res := range []*resource.State{a, b} {
+		secret := res.Outputs["regions"].ArrayValue()[0]
+		assert.True(t, secret.IsSecret())
+		assert.Equal(t, shared, stringData(secret.SecretValue().Element.StringValue()))
+	}
+	assert.Equal(t, "a", a.Inputs["tags"].ObjectValue()["name"].StringValue())
+	assert.Equal(t, "b", b.Inputs["tags"].ObjectValue()["name"].StringValue())
+}
+
+func TestEventDetailLimit(t *testing.T) {
+	state := func(name string, size float64) *resource.State {
+		urn := resource.NewURN("dev", "proj", "", "test:index:Resource", tokens.QName(name))
+		if name == "stack" {
+			urn = resource.DefaultRootStackURN("dev", "proj")
+		}
+		return &resource.State{URN: urn, Type: urn.Type(), Inputs: resource.PropertyMap{
+			"size": resource.NewNumberProperty(size),
+		}}
+	}
+	a := deploy.NewUpdateStep(nil, nil, state("a", 1), state("a", 2), nil, nil, nil, nil)
+	b := deploy.NewUpdateStep(nil, nil, state("b", 3), state("b", 4), nil, nil, nil, nil)
+	stack := deploy.NewUpdateStep(nil, nil, state("stack", 5), state("stack", 6), nil, nil, nil, nil)
+
+	// Only the first resources carry their properties, in every one of their events.
+	emitter := eventEmitter{}
+	emitter.limitDetails(1)
+	md := emitter.stepEventMetadata(a.Op(), a, false)
+	assert.Equal(t, resource.NewNumberProperty(2), md.New.Inputs["size"])
+	md = emitter.stepEventMetadata(a.Op(), a, false)
+	assert.Equal(t, resource.NewNumberProperty(2), md.New.Inputs["size"])
+	md = emitter.stepEventMetadata(b.Op(), b, false)
+	assert.Equal(t, b.URN(), md.URN)
+	assert.Nil(t, md.New.Inputs)
+
+	// The root stack's events and outputs events are not limited.
+	md = emitter.stepEventMetadata(stack.Op(), stack, false)
+	assert.NotNil(t, md.New.Inputs)
+
+	events := make(chan Event, 1)
+	emitter.Chan = events
+	emitter.resourceOutputsEvent(b.Op(), b, false, false)
+	outputs := (<-events).Payload.(ResourceOutputsEventPayload)
+	assert.NotNil(t, outputs.Metadata.New.Inputs)
+
+	// No limit is the default.
+	emitter.limitDetails(0)
+	md = emitter.stepEventMetadata(b.Op(), b, false)
+	assert.NotNil(t, md.New.Inputs)
+}
+
+func TestMemoryMonitor(t *testing.T) {
+	var none *memoryMonitor
+	assert.Equal(t, uint64(0), none.Peak())
+	none.Stop()
+	assert.Nil(t, startMemoryMonitor(MemoryOptions{}))
+
+	m := startMemoryMonitor(MemoryOptions{ReportPeakUsage: true})
+	defer m.Stop()
+	assert.True(t, m.Peak() > 0)
+}
@@ -24,7 +24,9 @@ import (
 	"github.com/pulumi/pulumi/pkg/resource/deploy"
 	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
 	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/crash"
 	"github.com/pulumi/pulumi/pkg/util/fsutil"
 	"github.com/pulumi/pulumi/pkg/util/result"
 	"github.com/pulumi/pulumi/pkg/workspace"
@@ -100,6 +102,9 @@ type planOptions struct {
 	// true if we should trust the dependency graph reported by the language host. Not all Pulumi-supported languages
 	// correctly report their dependencies, in which case this will be false.
 	trustDependencies bool
+
+	memory   *memoryMonitor    // the monitor of the operation's heap usage, if it is reported.
+	interner *propertyInterner // the interner of resources' properties, if they are interned.
 }
 
 // planSourceFunc is a callback that will be used to prepare for, and evaluate, the "new" state for a stack.
@@ -124,11 +129,24 @@ func plan(ctx *Context, info *planContext, opts planOptions, dryRun bool) (*plan
 	if err != nil {
 		return nil, err
 	}
+	plugctx.Sandbox = opts.Sandbox
+	plugctx.StrictPreview = dryRun && opts.StrictPreview
+	if plugctx.StrictPreview {
+		plugctx.StrictPreviewInvokes = make(map[tokens.ModuleMember]bool, len(opts.StrictPreviewInvokes))
+		for _, tok := range opts.StrictPreviewInvokes {
+			plugctx.StrictPreviewInvokes[tokens.ModuleMember(tok)] = true
+		}
+	}
+	plugctx.Mock = opts.Mock
 
 	opts.trustDependencies = proj.TrustResourceDependencies()
+
+	// Outputs read through stack references are checked against those the project declares it reads.
+	client := newStackReferenceChecker(ctx.BackendClient, proj.StackReferences)
+
 	// Now create the state source.  This may issue an error if it can't create the source.  This entails,
 	// for example, loading any plugins which will be required to execute a program, among other things.
-	source, err := opts.SourceFunc(ctx.BackendClient, opts, proj, pwd, main, target, plugctx, dryRun)
+	source, err := opts.SourceFunc(client, opts, proj, pwd, main, target, plugctx, dryRun)
 	if err != nil {
 		contract.IgnoreClose(plugctx)
 		return nil, err
@@ -136,7 +154,7 @@ func plan(ctx *Context, info *planContext, opts planOptions, dryRun bool) (*plan
 
 	// Generate a plan; this API handles all interesting cases (create, update, delete).
 	plan, err := deploy.NewPlan(
-		plugctx, target, target.Snapshot, source, opts.LocalPolicyPackPaths, dryRun, ctx.BackendClient)
+		plugctx, target, target.Snapshot, source, opts.LocalPolicyPackPaths, dryRun, client)
 	if err != nil {
 		contract.IgnoreClose(plugctx)
 		return nil, err
@@ -164,13 +182,19 @@ func (planResult *planResult) Chdir() (func(), error) {
 
 // Walk enumerates all steps in the plan, calling out to the provided action at each step.  It returns four things: the
 // resulting Snapshot, no matter whether an error occurs or not; an error, if something went wrong; the step that
-// failed, if the error is non-nil; and finally the state of the resource modified in the failing step.
-func (planResult *planResult) Walk(cancelCtx *Context, events deploy.Events, preview bool) result.Result {
+// failed, if the error is non-nil; and finally the state of the resource modified in the failing step. If a throttle
+// is given, it may further limit how many steps execute at once.
+func (planResult *planResult) Walk(cancelCtx *Context, events deploy.Events, throttle deploy.StepThrottle,
+	preview bool) result.Result {
 	ctx, cancelFunc := context.WithCancel(context.Background())
 
+	// Intern the properties of the existing resources before the plan starts to use them.
+	planResult.Options.interner.internSnapshot(planResult.Plan.Prev())
+
 	done := make(chan bool)
 	var walkResult result.Result
 	go func() {
+		defer crash.Recover()
 		opts := deploy.Options{
 			Events:            events,
 			Parallel:          planResult.Options.Parallel,
@@ -178,6 +202,15 @@ func (planResult *planResult) Walk(cancelCtx *Context, events deploy.Events, pre
 			RefreshOnly:       planResult.Options.isRefresh,
 			TrustDependencies: planResult.Options.trustDependencies,
 			UseLegacyDiff:     planResult.Options.UseLegacyDiff,
+			AutoNaming:        planResult.Ctx.Update.GetProject().AutoNaming,
+
+			StrictDeprecations: planResult.Options.StrictDeprecations,
+			CheckOnly:          planResult.Options.CheckOnly && preview,
+
+			UpdateTargets:    planResult.Options.UpdateTargets,
+			TargetDependents: planResult.Options.TargetDependents,
+
+			Throttle: throttle,
 		}
 		walkResult = planResult.Plan.Execute(ctx, opts, preview)
 		close(done)
@@ -212,8 +245,8 @@ func printPlan(ctx *Context, planResult *planResult, dryRun bool) (ResourceChang
 	planResult.Options.Events.preludeEvent(dryRun, planResult.Ctx.Update.GetTarget().Config)
 
 	// Walk the plan's steps and and pretty-print them out.
-	actions := newPlanActions(planResult.Options)
-	if res := planResult.Walk(ctx, actions, true); res != nil {
+	actions := newPlanActions(planResult.Options, planResult.Ctx.Update.GetTarget())
+	if res := planResult.Walk(ctx, actions, nil, true); res != nil {
 		if res.IsBail() {
 			return nil, res
 		}
@@ -223,15 +256,23 @@ func printPlan(ctx *Context, planResult *planResult, dryRun bool) (ResourceChang
 
 	// Emit an event with a summary of operation counts.
 	changes := ResourceChanges(actions.Ops)
-	planResult.Options.Events.previewSummaryEvent(changes)
+	var analysis *planAnalyzer
+	if !planResult.Options.isRefresh {
+		analysis = actions.Analysis
+	}
+	planResult.Options.Events.previewSummaryEvent(changes, actions.Deprecations, analysis,
+		planResult.Options.memory.Peak())
 	return changes, nil
 }
 
 type planActions struct {
-	Ops     map[deploy.StepOp]int
-	Opts    planOptions
-	Seen    map[resource.URN]deploy.Step
-	MapLock sync.Mutex
+	Ops          map[deploy.StepOp]int
+	Opts         planOptions
+	Seen         map[resource.URN]deploy.Step
+	MapLock      sync.Mutex
+	Guardrails   *guardrailChecker
+	Deprecations map[string]int
+	Analysis     *planAnalyzer
 }
 
 func shouldReportStep(step deploy.Step, opts planOptions) bool {
@@ -239,19 +280,37 @@ func shouldReportStep(step deploy.Step, opts planOptions) bool {
 		(opts.reportDefaultProviderSteps || !isDefaultProviderStep(step))
 }
 
-func newPlanActions(opts planOptions) *planActions {
+func newPlanActions(opts planOptions, target *deploy.Target) *planActions {
 	return &planActions{
-		Ops:  make(map[deploy.StepOp]int),
-		Opts: opts,
-		Seen: make(map[resource.URN]deploy.Step),
+		Ops:          make(map[deploy.StepOp]int),
+		Opts:         opts,
+		Seen:         make(map[resource.URN]deploy.Step),
+		Guardrails:   newGuardrailChecker(target.Guardrails, opts.OverrideGuardrails),
+		Deprecations: make(map[string]int),
+		Analysis:     newPlanAnalyzer(opts.Timings),
 	}
 }
 
 func (acts *planActions) OnResourceStepPre(step deploy.Step) (interface{}, error) {
 	acts.MapLock.Lock()
+	_, seen := acts.Seen[step.URN()]
 	acts.Seen[step.URN()] = step
 	acts.MapLock.Unlock()
 
+	if err := acts.Guardrails.check(step); err != nil {
+		return nil, err
+	}
+	if err := acts.Opts.Plan.check(step); err != nil {
+		return nil, err
+	}
+	if !seen {
+		// Show any notes on the resource's planned changes once, before the first of its steps.
+		acts.Opts.Plan.reportNotes(step, acts.Opts.Events)
+	}
+	if err := acts.Opts.SavePlan.record(step); err != nil {
+		return nil, err
+	}
+
 	// Skip reporting if necessary.
 	if !shouldReportStep(step, acts.Opts) {
 		return nil, nil
@@ -268,6 +327,10 @@ func (acts *planActions) OnResourceStepPost(ctx interface{},
 	assertSeen(acts.Seen, step)
 	acts.MapLock.Unlock()
 
+	if err == nil {
+		acts.Opts.interner.internState(step.New())
+	}
+
 	reportStep := shouldReportStep(step, acts.Opts)
 
 	if err != nil {
@@ -284,6 +347,10 @@ func (acts *planActions) OnResourceStepPost(ctx interface{},
 		if acts.Opts.isRefresh && op == deploy.OpRefresh {
 			// Refreshes are handled specially.
 			op, record = step.(*deploy.RefreshStep).ResultOp(), true
+
+			// Drift is what the refresh's preview finds, before the refresh adopts it: by the time the refresh itself
+			// runs, the preview has already recorded the same resources.
+			acts.Opts.DriftReport.record(step.(*deploy.RefreshStep), op)
 		}
 
 		if step.Op() == deploy.OpRead {
@@ -291,11 +358,14 @@ func (acts *planActions) OnResourceStepPost(ctx interface{},
 		}
 
 		// Track the operation if shown and/or if it is a logically meaningful operation.
+		acts.MapLock.Lock()
 		if record {
-			acts.MapLock.Lock()
 			acts.Ops[op]++
-			acts.MapLock.Unlock()
 		}
+		if !acts.Opts.isRefresh {
+			acts.Analysis.add(step, op, record)
+		}
+		acts.MapLock.Unlock()
 
 		acts.Opts.Events.resourceOutputsEvent(op, step, true /*planning*/, acts.Opts.Debug)
 	}
@@ -321,6 +391,8 @@ func (acts *planActions) OnResourceOutputs(step deploy.Step) error {
 	assertSeen(acts.Seen, step)
 	acts.MapLock.Unlock()
 
+	acts.Opts.interner.internState(step.New())
+
 	// Skip reporting if necessary.
 	if !shouldReportStep(step, acts.Opts) {
 		return nil
@@ -336,6 +408,18 @@ func (acts *planActions) OnPolicyViolation(urn resource.URN, d plugin.AnalyzeDia
 	acts.Opts.Events.policyViolationEvent(urn, d)
 }
 
+func (acts *planActions) OnPolicyRemediation(urn resource.URN, r plugin.Remediation,
+	before, after resource.PropertyMap) {
+
+	acts.Opts.Events.policyRemediationEvent(urn, r, before, after)
+}
+
+func (acts *planActions) OnDeprecation(urn resource.URN, d deploy.Deprecation) {
+	acts.MapLock.Lock()
+	acts.Deprecations[d.Item]++
+	acts.MapLock.Unlock()
+}
+
 func assertSeen(seen map[resource.URN]deploy.Step, step deploy.Step) {
 	_, has := seen[step.URN()]
 	contract.Assertf(has, "URN '%v' had not been marked as seen", step.URN())
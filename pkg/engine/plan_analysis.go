@@ -0,0 +1,189 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"time"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// CriticalPathStep is a change on the longest chain of dependent changes in a preview.
+type CriticalPathStep struct {
+	URN      resource.URN  // the resource being changed.
+	Op       deploy.StepOp // the change to the resource.
+	Expected time.Duration // the expected duration of the change, if known.
+	Known    bool          // true if there is a history of how long this change takes.
+}
+
+// planAnalyzer builds a picture of a preview's changes as its steps complete, so that the preview summary can break
+// them down by provider and point out the chain of changes that will take longest to apply.
+type planAnalyzer struct {
+	timings         *workspace.OperationTimings
+	nodes           map[resource.URN]*planNode
+	providerChanges map[string]ResourceChanges
+}
+
+type planNode struct {
+	op       deploy.StepOp
+	changed  bool
+	deps     []resource.URN
+	expected time.Duration
+	known    bool
+}
+
+func newPlanAnalyzer(timings *workspace.OperationTimings) *planAnalyzer {
+	return &planAnalyzer{
+		timings:         timings,
+		nodes:           make(map[resource.URN]*planNode),
+		providerChanges: make(map[string]ResourceChanges),
+	}
+}
+
+// isChange returns true if the given operation changes a resource.
+func isChange(op deploy.StepOp) bool {
+	switch op {
+	case deploy.OpSame, deploy.OpRead, deploy.OpReadDiscard, deploy.OpReadReplacement, deploy.OpRefresh,
+		deploy.OpRemovePendingReplace:
+		return false
+	default:
+		return true
+	}
+}
+
+// timedOp returns the operation whose recorded duration estimates how long the given operation takes. Replacements
+// are logical operations whose time is spent creating the replacement resource.
+func timedOp(op deploy.StepOp) deploy.StepOp {
+	if op == deploy.OpReplace {
+		return deploy.OpCreateReplacement
+	}
+	return op
+}
+
+// providerName returns the name of the provider package that manages resources of the given type.
+func providerName(t tokens.Type) string {
+	if providers.IsProviderType(t) {
+		return string(providers.GetProviderPackage(t))
+	}
+	return string(t.Package())
+}
+
+// add records a completed step. Deletions are counted, but are not part of any chain of dependent changes, as they
+// are performed once all other changes are complete.
+func (a *planAnalyzer) add(step deploy.Step, op deploy.StepOp, record bool) {
+	changed := record && isChange(op)
+	if changed {
+		p := providerName(step.Type())
+		if a.providerChanges[p] == nil {
+			a.providerChanges[p] = make(ResourceChanges)
+		}
+		a.providerChanges[p][op]++
+	}
+
+	new := step.New()
+	if new == nil {
+		return
+	}
+	node := &planNode{op: op, changed: changed, deps: new.Dependencies}
+	if changed && a.timings != nil {
+		node.expected, node.known = a.timings.Expected(step.Type(), string(timedOp(op)))
+	}
+	if existing, has := a.nodes[step.URN()]; has && existing.changed && !changed {
+		// Keep the logical change for resources with several steps, e.g. replacements.
+		return
+	}
+	a.nodes[step.URN()] = node
+}
+
+// ProviderChanges returns the changes in the preview broken down by provider package.
+func (a *planAnalyzer) ProviderChanges() map[string]ResourceChanges {
+	return a.providerChanges
+}
+
+// CriticalPath returns the chain of dependent changes with the longest expected duration. Changes without a timing
+// history count as taking no time, so with no history at all this is the longest chain of dependent changes.
+func (a *planAnalyzer) CriticalPath() []CriticalPathStep {
+	type pathCost struct {
+		duration time.Duration
+		changes  int
+		next     resource.URN // the most expensive dependency, or "" if there is none.
+	}
+	less := func(x, y pathCost) bool {
+		return x.duration < y.duration || x.duration == y.duration && x.changes < y.changes
+	}
+
+	costs := make(map[resource.URN]pathCost)
+	var cost func(urn resource.URN) pathCost
+	cost = func(urn resource.URN) pathCost {
+		if c, has := costs[urn]; has {
+			return c
+		}
+		node := a.nodes[urn]
+
+		var best pathCost
+		for _, dep := range node.deps {
+			if _, has := a.nodes[dep]; !has {
+				continue
+			}
+			if c := cost(dep); best.next == "" || less(best, c) {
+				best = pathCost{duration: c.duration, changes: c.changes, next: dep}
+			}
+		}
+		if node.changed {
+			best.duration += node.expected
+			best.changes++
+		}
+		costs[urn] = best
+		return best
+	}
+
+	var start resource.URN
+	var longest pathCost
+	for urn := range a.nodes {
+		if c := cost(urn); start == "" || less(longest, c) || !less(c, longest) && urn < start {
+			start, longest = urn, c
+		}
+	}
+
+	// Walk the chain from its last change back to its first, then reverse it to put it in order of application.
+	var path []CriticalPathStep
+	for urn := start; urn != ""; urn = costs[urn].next {
+		if node := a.nodes[urn]; node.changed {
+			path = append(path, CriticalPathStep{URN: urn, Op: node.op, Expected: node.expected, Known: node.known})
+		}
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// isTimedStep returns true if the given step performs a provider operation whose duration is worth recording.
+func isTimedStep(step deploy.Step) bool {
+	if !step.Res().Custom {
+		return false
+	}
+	switch step.Op() {
+	case deploy.OpCreate, deploy.OpUpdate, deploy.OpDelete, deploy.OpCreateReplacement, deploy.OpDeleteReplaced,
+		deploy.OpImport, deploy.OpImportReplacement:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestPlanAnalysis(t *testing.T) {
+	dir, err := ioutil.TempDir("", "timings")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Record a history for slow updates, and check that it survives a round trip through the timings file.
+	path := filepath.Join(dir, "timings.json")
+	timings, err := workspace.GetOperationTimings(path)
+	assert.NoError(t, err)
+	timings.Record("test:index:Slow", string(deploy.OpUpdate), 90*time.Second)
+	timings.Record("test:index:Slow", string(deploy.OpUpdate), 150*time.Second)
+	assert.NoError(t, timings.Save())
+	timings, err = workspace.GetOperationTimings(path)
+	assert.NoError(t, err)
+	expected, known := timings.Expected("test:index:Slow", string(deploy.OpUpdate))
+	assert.True(t, known)
+	assert.Equal(t, 2*time.Minute, expected)
+
+	urn := func(t tokens.Type, name string) resource.URN {
+		return resource.NewURN("stack", "proj", "", t, tokens.QName(name))
+	}
+	state := func(urn resource.URN, deps ...resource.URN) *resource.State {
+		return resource.NewState(urn.Type(), urn, false, false, "", resource.PropertyMap{}, nil, "", false, false,
+			deps, nil, "", nil, false, nil, nil, nil)
+	}
+	update := func(urn resource.URN, deps ...resource.URN) deploy.Step {
+		return deploy.NewUpdateStep(nil, nil, state(urn), state(urn, deps...), nil, nil, nil, nil)
+	}
+	same := func(urn resource.URN, deps ...resource.URN) deploy.Step {
+		return deploy.NewSameStep(nil, nil, state(urn), state(urn, deps...))
+	}
+
+	a := urn("test:index:Slow", "a")
+	b := urn("test:index:Fast", "b")
+	c := urn("test:index:Fast", "c")
+	d := urn("test:index:Fast", "d")
+	e := urn("other:index:Thing", "e")
+
+	analyzer := newPlanAnalyzer(timings)
+	for _, step := range []deploy.Step{update(a), update(b, a), same(c, b), update(d, c), update(e)} {
+		analyzer.add(step, step.Op(), true)
+	}
+
+	assert.Equal(t, map[string]ResourceChanges{
+		"test":  {deploy.OpUpdate: 3},
+		"other": {deploy.OpUpdate: 1},
+	}, analyzer.ProviderChanges())
+
+	// The chain runs through the unchanged resource, but only lists the changes along it.
+	assert.Equal(t, []CriticalPathStep{
+		{URN: a, Op: deploy.OpUpdate, Expected: 2 * time.Minute, Known: true},
+		{URN: b, Op: deploy.OpUpdate},
+		{URN: d, Op: deploy.OpUpdate},
+	}, analyzer.CriticalPath())
+}
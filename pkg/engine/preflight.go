@@ -0,0 +1,454 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// PreflightProblem is a single problem found by the pre-flight checks that run before an update starts.
+type PreflightProblem struct {
+	Check   string // the check that found the problem, e.g. "plugins" or "credentials".
+	Message string // a description of the problem.
+}
+
+// PreflightError reports all of the problems found by the pre-flight checks at once.
+type PreflightError struct {
+	Problems []PreflightProblem
+}
+
+func (e *PreflightError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "pre-flight checks found %d problem(s):", len(e.Problems))
+	for _, p := range e.Problems {
+		fmt.Fprintf(&sb, "\n    %s: %s", p.Check, p.Message)
+	}
+	return sb.String()
+}
+
+// NewPreflightError returns a PreflightError for the given problems, or nil if there are none.
+func NewPreflightError(problems []PreflightProblem) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	return &PreflightError{Problems: problems}
+}
+
+// PreflightProviders holds the default providers configured by pre-flight checks that ran before an update started,
+// until the update is given them through UpdateOptions.Preflight. It must be closed once the update is done.
+type PreflightProviders struct {
+	plugctx    *plugin.Context
+	ownsHost   bool           // true if plugctx's host was created for the checks, and so is closed with them.
+	sink       *preflightSink // reports the providers' diagnostics to the update they are handed over to.
+	statusSink *preflightSink // reports the providers' status messages to the update they are handed over to.
+	host       *preflightHost
+}
+
+// Preflight runs the pre-flight checks for an update of the given program and target before the update itself
+// starts, so that an update that cannot succeed is never started; see preflight for the checks. All problems are
+// reported at once in a PreflightError. If the checks pass, the default providers they configured are returned, to be
+// given to the update so that none is launched twice.
+func Preflight(u UpdateInfo, opts UpdateOptions) (*PreflightProviders, error) {
+	contract.Require(u != nil, "u")
+
+	proj, target := u.GetProject(), u.GetTarget()
+	contract.Assert(proj != nil)
+	contract.Assert(target != nil)
+
+	// Until the providers are handed over, there is no update to report their output to.
+	sink, statusSink := newPreflightSink(), newPreflightSink()
+	projinfo := &Projinfo{Proj: proj, Root: u.GetRoot()}
+	pwd, main, plugctx, err := ProjectInfoContext(projinfo, opts.host, target, sink, statusSink, nil)
+	if err != nil {
+		return nil, err
+	}
+	plugctx.Sandbox = opts.Sandbox
+	plugctx.Mock = opts.Mock
+	providers := &PreflightProviders{
+		plugctx:    plugctx,
+		ownsHost:   opts.host == nil,
+		sink:       sink,
+		statusSink: statusSink,
+	}
+
+	allPlugins, defaultProviderVersions, err := installPlugins(proj, pwd, main, target, plugctx)
+	if err == nil {
+		providers.host, err = preflight(plugctx, proj, target, allPlugins, defaultProviderVersions, opts)
+	}
+	if err != nil {
+		contract.IgnoreClose(providers)
+		return nil, err
+	}
+	return providers, nil
+}
+
+// Close closes the plugin host of the pre-flight checks, along with the providers it loaded.
+func (p *PreflightProviders) Close() error {
+	if p == nil || !p.ownsHost {
+		return nil
+	}
+	return p.plugctx.Close()
+}
+
+// handOver returns a host for the update whose plugin context is given, which hands the configured providers over to
+// the update. From then on, the providers' output is reported to the update.
+func (p *PreflightProviders) handOver(plugctx *plugin.Context) plugin.Host {
+	p.sink.setTarget(plugctx.Diag)
+	p.statusSink.setTarget(plugctx.StatusDiag)
+
+	host := newPreflightHost(plugctx.Host)
+	p.host.lock.Lock()
+	defer p.host.lock.Unlock()
+	for key, provider := range p.host.configured {
+		provider.host = plugctx.Host
+		host.configured[key] = provider
+	}
+	p.host.configured = make(map[string]*preflightProvider)
+	return host
+}
+
+// preflight validates that an update of the given program and target can proceed, before the program starts. It
+// checks that every plugin the update requires is available, that each default provider the stack already has accepts
+// the target's configuration (which is typically where credentials are validated), that the target's configuration
+// conforms to the project's config schema, and, if the options carry a check for it, that the stack may be written.
+// All problems are reported at once in a PreflightError, rather than failing partway through the deployment.
+//
+// The default providers are configured using the given plugin context's host. The returned host hands those that pass
+// over to the update when it loads them, rather than launching them a second time.
+func preflight(plugctx *plugin.Context, proj *workspace.Project, target *deploy.Target, allPlugins pluginSet,
+	defaultProviderVersions map[tokens.Package]*semver.Version, opts UpdateOptions) (*preflightHost, error) {
+
+	problems := checkConfigSchema(proj, target)
+
+	// installPlugins only makes a best effort to install missing plugins, so check for any that are still missing. A
+	// host supplied with the options does not load its plugins from the workspace, so there is nothing to check.
+	for _, plug := range allPlugins.Values() {
+		if opts.host != nil {
+			break
+		}
+		if plug.Kind == workspace.LanguagePlugin || plug.Kind == workspace.ResourcePlugin && opts.Mock != nil {
+			continue
+		}
+		if _, path, err := workspace.GetPluginPath(plug.Kind, plug.Name, plug.Version); err != nil || path == "" {
+			problems = append(problems, PreflightProblem{
+				Check:   "plugins",
+				Message: fmt.Sprintf("%s plugin %s is not installed and could not be installed", plug.Kind, plug),
+			})
+		}
+	}
+
+	// Configure each default provider, so that invalid configuration and credentials are reported up front. Which
+	// default providers the program uses is only known once it runs, and the plugins the language host reports are
+	// not all used by default providers, so only the default providers that the stack already has are checked.
+	host := newPreflightHost(plugctx.Host)
+	used := defaultProviderPackages(target.Snapshot)
+	var pkgs []string
+	for pkg := range defaultProviderVersions {
+		if used[pkg] {
+			pkgs = append(pkgs, string(pkg))
+		}
+	}
+	sort.Strings(pkgs)
+	for _, name := range pkgs {
+		pkg := tokens.Package(name)
+		problems = append(problems, host.checkProviderConfig(proj, target, pkg, defaultProviderVersions[pkg])...)
+	}
+
+	if opts.CheckWritable != nil {
+		if err := opts.CheckWritable(); err != nil {
+			problems = append(problems, PreflightProblem{Check: "permissions", Message: err.Error()})
+		}
+	}
+
+	if err := NewPreflightError(problems); err != nil {
+		return nil, err
+	}
+	return host, nil
+}
+
+// defaultProviderPackages returns the packages of the default providers in the given snapshot, which may be nil.
+func defaultProviderPackages(snap *deploy.Snapshot) map[tokens.Package]bool {
+	pkgs := make(map[tokens.Package]bool)
+	if snap == nil {
+		return pkgs
+	}
+	for _, res := range snap.Resources {
+		if !res.Delete && providers.IsDefaultProvider(res.URN) {
+			pkgs[providers.GetProviderPackage(res.Type)] = true
+		}
+	}
+	return pkgs
+}
+
+// checkConfigSchema checks the target's configuration against the types declared by the project's config schema.
+func checkConfigSchema(proj *workspace.Project, target *deploy.Target) []PreflightProblem {
+	var problems []PreflightProblem
+	report := func(k string, format string, args ...interface{}) {
+		problems = append(problems, PreflightProblem{
+			Check:   "config",
+			Message: fmt.Sprintf("%s: %s", k, fmt.Sprintf(format, args...)),
+		})
+	}
+
+	var keys []string
+	for k := range proj.ConfigSchema {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		t := proj.ConfigSchema[k]
+		key, err := proj.ConfigSchemaKey(k)
+		if err != nil {
+			report(k, "not a valid configuration key: %v", err)
+			continue
+		}
+		v, has := target.Config[key]
+		if !has {
+			continue
+		}
+		if t.Secret && !v.Secure() {
+			report(k, "declared secret but stored in plaintext")
+		}
+		if v.Secure() && target.Decrypter == nil {
+			continue
+		}
+		value, err := v.Value(target.Decrypter)
+		if err != nil {
+			report(k, "could not be decrypted: %v", err)
+			continue
+		}
+		if err = t.Check(value); err != nil {
+			report(k, "%v", err)
+		}
+	}
+	return problems
+}
+
+// preflightSink is a diagnostics sink that forwards to another, which may be changed. Providers report their output
+// to the sink of the plugin context they are loaded with, so the pre-flight checks load them with one of these, which
+// is pointed at the update's sink when they are handed over to it.
+type preflightSink struct {
+	lock   sync.RWMutex
+	target diag.Sink
+}
+
+func newPreflightSink() *preflightSink {
+	return &preflightSink{target: diag.DefaultSink(ioutil.Discard, ioutil.Discard, diag.FormatOptions{})}
+}
+
+func (s *preflightSink) setTarget(target diag.Sink) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.target = target
+}
+
+func (s *preflightSink) sink() diag.Sink {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.target
+}
+
+func (s *preflightSink) Logf(sev diag.Severity, d *diag.Diag, args ...interface{}) {
+	s.sink().Logf(sev, d, args...)
+}
+
+func (s *preflightSink) Debugf(d *diag.Diag, args ...interface{})   { s.sink().Debugf(d, args...) }
+func (s *preflightSink) Infof(d *diag.Diag, args ...interface{})    { s.sink().Infof(d, args...) }
+func (s *preflightSink) Infoerrf(d *diag.Diag, args ...interface{}) { s.sink().Infoerrf(d, args...) }
+func (s *preflightSink) Errorf(d *diag.Diag, args ...interface{})   { s.sink().Errorf(d, args...) }
+func (s *preflightSink) Warningf(d *diag.Diag, args ...interface{}) { s.sink().Warningf(d, args...) }
+
+func (s *preflightSink) Stringify(sev diag.Severity, d *diag.Diag, args ...interface{}) (string, string) {
+	return s.sink().Stringify(sev, d, args...)
+}
+
+// preflightHost is a plugin host that hands the default providers configured by the pre-flight checks over to the
+// update that follows them.
+type preflightHost struct {
+	plugin.Host
+
+	lock       sync.Mutex
+	configured map[string]*preflightProvider // the configured providers that have not been handed over, by package.
+}
+
+func newPreflightHost(host plugin.Host) *preflightHost {
+	return &preflightHost{Host: host, configured: make(map[string]*preflightProvider)}
+}
+
+func preflightProviderKey(pkg tokens.Package, version *semver.Version) string {
+	if version == nil {
+		return string(pkg)
+	}
+	return fmt.Sprintf("%s-%s", pkg, version)
+}
+
+// Provider returns the provider configured by the pre-flight checks for the given package and version, the first time
+// it is asked for, and otherwise loads a new one.
+func (host *preflightHost) Provider(pkg tokens.Package, version *semver.Version) (plugin.Provider, error) {
+	host.lock.Lock()
+	key := preflightProviderKey(pkg, version)
+	provider, has := host.configured[key]
+	delete(host.configured, key)
+	host.lock.Unlock()
+
+	if has {
+		return provider, nil
+	}
+	return host.Host.Provider(pkg, version)
+}
+
+func (host *preflightHost) CloseProvider(provider plugin.Provider) error {
+	if p, ok := provider.(*preflightProvider); ok {
+		provider = p.Provider
+	}
+	return host.Host.CloseProvider(provider)
+}
+
+// preflightProvider is a provider that the pre-flight checks have already configured. A provider can only be
+// configured once, so if the update configures it differently, a new provider is loaded in its place.
+type preflightProvider struct {
+	plugin.Provider
+
+	host    plugin.Host
+	pkg     tokens.Package
+	version *semver.Version
+	config  map[string]string // the configuration applied by the pre-flight checks, or nil once it has been used.
+}
+
+func (p *preflightProvider) Configure(inputs resource.PropertyMap) error {
+	if p.config != nil && reflect.DeepEqual(p.config, providerConfigStrings(inputs)) {
+		p.config = nil
+		return nil
+	}
+
+	fresh, err := p.host.Provider(p.pkg, p.version)
+	if err != nil {
+		return err
+	} else if fresh == nil {
+		return errors.Errorf("could not find plugin for provider %s", p.pkg)
+	}
+	if closeErr := p.host.CloseProvider(p.Provider); closeErr != nil {
+		logging.V(7).Infof("preflightProvider.Configure(): closing provider %s: %v", p.pkg, closeErr)
+	}
+	p.Provider, p.config = fresh, nil
+	return fresh.Configure(inputs)
+}
+
+// providerConfigStrings returns the string values of the given provider configuration, as they would be passed to
+// the provider, or nil if any of them is not known.
+func providerConfigStrings(inputs resource.PropertyMap) map[string]string {
+	config := make(map[string]string)
+	for k, v := range inputs {
+		if k == "version" {
+			continue
+		}
+		for v.IsSecret() {
+			v = v.SecretValue().Element
+		}
+		if !v.IsString() {
+			return nil
+		}
+		config[string(k)] = v.StringValue()
+	}
+	return config
+}
+
+// checkProviderConfig loads the given provider and configures it with the target's configuration for its package, as
+// would happen for the package's default provider during the update. If it succeeds, the configured provider is kept
+// for the update.
+func (host *preflightHost) checkProviderConfig(proj *workspace.Project, target *deploy.Target, pkg tokens.Package,
+	version *semver.Version) []PreflightProblem {
+
+	report := func(format string, args ...interface{}) []PreflightProblem {
+		return []PreflightProblem{{Check: "credentials", Message: fmt.Sprintf(format, args...)}}
+	}
+
+	cfg, err := target.GetPackageConfig(pkg)
+	if err != nil {
+		return report("reading configuration for provider %s: %v", pkg, err)
+	}
+	inputs := make(resource.PropertyMap)
+	for k, v := range cfg {
+		inputs[resource.PropertyKey(k.Name())] = resource.NewStringProperty(v)
+	}
+
+	provider, err := host.Host.Provider(pkg, version)
+	if err != nil {
+		return report("loading provider %s: %v", pkg, err)
+	} else if provider == nil {
+		// The missing plugin has already been reported.
+		return nil
+	}
+	keep := false
+	defer func() {
+		if keep {
+			return
+		}
+		if closeErr := host.Host.CloseProvider(provider); closeErr != nil {
+			logging.V(7).Infof("preflight(): closing provider %s: %v", pkg, closeErr)
+		}
+	}()
+
+	urn := resource.NewURN(target.Name, proj.Name, "", providers.MakeProviderType(pkg), "default")
+	checked, failures, err := provider.CheckConfig(urn, nil, inputs, false)
+	if err != nil {
+		return report("checking configuration for provider %s: %v", pkg, err)
+	}
+	if len(failures) > 0 {
+		var problems []PreflightProblem
+		for _, f := range failures {
+			key := config.MustMakeKey(string(pkg), string(f.Property))
+			problems = append(problems, PreflightProblem{
+				Check:   "credentials",
+				Message: fmt.Sprintf("provider %s: %s: %s", pkg, key, f.Reason),
+			})
+		}
+		return problems
+	}
+	if err = provider.Configure(checked); err != nil {
+		return report("configuring provider %s (are its credentials valid?): %v", pkg, err)
+	}
+
+	keep = true
+	host.lock.Lock()
+	defer host.lock.Unlock()
+	host.configured[preflightProviderKey(pkg, version)] = &preflightProvider{
+		Provider: provider,
+		host:     host.Host,
+		pkg:      pkg,
+		version:  version,
+		config:   providerConfigStrings(checked),
+	}
+	return nil
+}
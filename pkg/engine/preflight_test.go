@@ -0,0 +1,224 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/deploytest"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/util/result"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestCheckConfigSchema(t *testing.T) {
+	proj := &workspace.Project{
+		Name: "proj",
+		ConfigSchema: map[string]workspace.ProjectConfigType{
+			"port":      {Type: workspace.ConfigTypeInteger},
+			"enabled":   {Type: workspace.ConfigTypeBoolean},
+			"password":  {Secret: true},
+			"aws:tries": {Type: workspace.ConfigTypeNumber},
+			"missing":   {Type: workspace.ConfigTypeInteger},
+		},
+	}
+	target := &deploy.Target{
+		Name: "stack",
+		Config: config.Map{
+			config.MustMakeKey("proj", "port"):     config.NewValue("eighty"),
+			config.MustMakeKey("proj", "enabled"):  config.NewValue("true"),
+			config.MustMakeKey("proj", "password"): config.NewValue("hunter2"),
+			config.MustMakeKey("aws", "tries"):     config.NewValue("3.5"),
+		},
+	}
+
+	assert.Equal(t, []PreflightProblem{
+		{Check: "config", Message: "password: declared secret but stored in plaintext"},
+		{Check: "config", Message: "port: \"eighty\" is not a valid integer"},
+	}, checkConfigSchema(proj, target))
+}
+
+func TestPreflightError(t *testing.T) {
+	assert.NoError(t, NewPreflightError(nil))
+
+	err := NewPreflightError([]PreflightProblem{
+		{Check: "plugins", Message: "resource plugin aws-1.0.0 is not installed and could not be installed"},
+		{Check: "permissions", Message: "you do not have permission to update stack 'dev'"},
+	})
+	assert.EqualError(t, err, "pre-flight checks found 2 problem(s):\n"+
+		"    plugins: resource plugin aws-1.0.0 is not installed and could not be installed\n"+
+		"    permissions: you do not have permission to update stack 'dev'")
+}
+
+func TestPreflightReusesProviders(t *testing.T) {
+	var loads, configures int
+	var configureErr error
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			loads++
+			return &deploytest.Provider{
+				ConfigureF: func(news resource.PropertyMap) error {
+					configures++
+					return configureErr
+				},
+			}, nil
+		}),
+	}
+
+	ran := false
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		ran = true
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true)
+		return err
+	}, workspace.PluginInfo{Name: "pkgA", Kind: workspace.ResourcePlugin, Version: &semver.Version{Major: 1}})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	// Before the stack has a default provider, the checks do not know that the program uses one.
+	p := &TestPlan{
+		Options: UpdateOptions{host: host},
+		Steps:   []TestStep{{Op: Update, SkipPreview: true}},
+	}
+	snap := p.Run(t, nil)
+	assert.True(t, ran)
+	assert.Equal(t, 1, loads)
+	assert.Equal(t, 1, configures)
+
+	// Once it has one, the default provider configured by the pre-flight checks is the one the update loads for the
+	// stack's existing default provider, rather than configuring another. The program's default provider is loaded
+	// again to check its configuration.
+	loads, configures = 0, 0
+	snap = p.Run(t, snap)
+	assert.Equal(t, 2, loads)
+	assert.Equal(t, 1, configures)
+
+	// A provider that cannot be configured fails the update before the program runs, and so does a stack that may not
+	// be written.
+	loads, configures, ran = 0, 0, false
+	configureErr = errors.New("bad credentials")
+	p.Options.CheckWritable = func() error { return errors.New("read-only") }
+	p.Steps = []TestStep{{
+		Op:            Update,
+		SkipPreview:   true,
+		ExpectFailure: true,
+		Validate: func(_ workspace.Project, _ deploy.Target, _ *Journal, _ []Event, res result.Result) result.Result {
+			if assert.NotNil(t, res) && assert.Error(t, res.Error()) {
+				assert.Contains(t, res.Error().Error(), "bad credentials")
+				assert.Contains(t, res.Error().Error(), "read-only")
+			}
+			return res
+		},
+	}}
+	p.Run(t, snap)
+	assert.False(t, ran)
+	assert.Equal(t, 1, loads)
+}
+
+func TestPreflightBeforeUpdate(t *testing.T) {
+	var loads, configures int
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			loads++
+			return &deploytest.Provider{
+				ConfigureF: func(news resource.PropertyMap) error {
+					configures++
+					return nil
+				},
+			}, nil
+		}),
+	}
+
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true)
+		return err
+	}, workspace.PluginInfo{Name: "pkgA", Kind: workspace.ResourcePlugin, Version: &semver.Version{Major: 1}})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &TestPlan{
+		Options: UpdateOptions{host: host, SkipPreflight: true},
+		Steps:   []TestStep{{Op: Update, SkipPreview: true}},
+	}
+	snap := p.Run(t, nil)
+	loads, configures = 0, 0
+
+	p.Options.SkipPreflight = false
+	u := &updateInfo{project: p.GetProject(), target: p.GetTarget(snap)}
+	providers, err := Preflight(u, p.Options)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() { assert.NoError(t, providers.Close()) }()
+	assert.Equal(t, 1, loads)
+	assert.Equal(t, 1, configures)
+
+	// The update is given the default provider the checks configured, rather than running the checks again.
+	p.Options.Preflight = providers
+	p.Run(t, snap)
+	assert.Equal(t, 2, loads)
+	assert.Equal(t, 1, configures)
+}
+
+func TestPreflightExplicitProviders(t *testing.T) {
+	// A package the program never uses by default cannot be configured from the stack's configuration, e.g. because
+	// its credentials are only given to explicit providers.
+	configureErr := errors.New("missing region")
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				ConfigureF: func(news resource.PropertyMap) error {
+					if !news.HasValue("region") {
+						return configureErr
+					}
+					return nil
+				},
+			}, nil
+		}),
+	}
+
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		provURN, provID, _, err := monitor.RegisterResource(providers.MakeProviderType("pkgA"), "explicit", true,
+			deploytest.ResourceOptions{
+				Inputs: resource.PropertyMap{"region": resource.NewStringProperty("us-west-2")},
+			})
+		if err != nil {
+			return err
+		}
+		provRef, err := providers.NewReference(provURN, provID)
+		if err != nil {
+			return err
+		}
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resA", true, deploytest.ResourceOptions{
+			Provider: provRef.String(),
+		})
+		return err
+	}, workspace.PluginInfo{Name: "pkgA", Kind: workspace.ResourcePlugin, Version: &semver.Version{Major: 1}})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	// Neither the first update nor later ones check the package's default provider, which the stack never has.
+	p := &TestPlan{
+		Options: UpdateOptions{host: host},
+		Steps:   []TestStep{{Op: Update}},
+	}
+	snap := p.Run(t, nil)
+	snap = p.Run(t, snap)
+	assert.Len(t, snap.Resources, 2)
+}
@@ -0,0 +1,92 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// propertyRedaction redacts the value at a property path in the inputs and outputs of matching resources.
+type propertyRedaction struct {
+	typ         *regexp.Regexp // nil if the redaction applies to all resources.
+	path        resource.PropertyPath
+	replacement string
+}
+
+// applyRedactionRules installs a project's pattern redaction rules as global log filters, which also covers
+// diagnostics and displayed property values, and returns its property redaction rules.
+func applyRedactionRules(proj *workspace.Project) ([]propertyRedaction, error) {
+	if proj == nil {
+		return nil, nil
+	}
+
+	var redactions []propertyRedaction
+	for i, rule := range proj.Redact {
+		if err := rule.Validate(); err != nil {
+			return nil, errors.Wrapf(err, "redaction rule #%d", i)
+		}
+
+		if rule.Pattern != "" {
+			re := regexp.MustCompile(rule.Pattern)
+			logging.AddGlobalFilter(logging.CreateRegexpFilter(re, rule.GetReplacement()))
+			continue
+		}
+
+		path, err := resource.ParsePropertyPath(rule.Property)
+		if err != nil {
+			return nil, errors.Wrapf(err, "redaction rule #%d: invalid 'property'", i)
+		}
+		r := propertyRedaction{path: path, replacement: rule.GetReplacement()}
+		if rule.Type != "" {
+			r.typ = regexp.MustCompile(rule.Type)
+		}
+		redactions = append(redactions, r)
+	}
+	return redactions, nil
+}
+
+// redactStepEventMetadata applies property redactions to the states in a step's event metadata. The states' property
+// maps must not be shared with the engine, as they are modified in place.
+func redactStepEventMetadata(metadata StepEventMetadata, redactions []propertyRedaction) StepEventMetadata {
+	for _, state := range []*StepEventStateMetadata{metadata.Old, metadata.New, metadata.Res} {
+		if state == nil {
+			continue
+		}
+		for _, r := range redactions {
+			if r.typ != nil && !r.typ.MatchString(string(state.Type)) {
+				continue
+			}
+			r.apply(state.Inputs)
+			r.apply(state.Outputs)
+		}
+	}
+	return metadata
+}
+
+func (r propertyRedaction) apply(props resource.PropertyMap) {
+	if props == nil {
+		return
+	}
+	root := resource.NewObjectProperty(props)
+	if _, has := r.path.Get(root); has {
+		r.path.Set(root, resource.NewStringProperty(r.replacement))
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestApplyRedactionRules(t *testing.T) {
+	proj := &workspace.Project{Redact: []workspace.RedactionRule{
+		{Pattern: `db-[0-9]+\.corp\.example\.com`},
+		{Property: "endpoint.host", Type: "^aws:rds", Replacement: "[host]"},
+		{Property: "servers[0]"},
+	}}
+
+	redactions, err := applyRedactionRules(proj)
+	assert.NoError(t, err)
+	assert.Len(t, redactions, 2)
+
+	// Pattern rules filter all displayed and logged text.
+	assert.Equal(t, "connecting to [redacted]:5432", logging.FilterString("connecting to db-12.corp.example.com:5432"))
+
+	props := func() resource.PropertyMap {
+		return resource.NewPropertyMapFromMap(map[string]interface{}{
+			"endpoint": map[string]interface{}{"host": "10.0.0.1", "port": 5432},
+			"servers":  []interface{}{"a.internal", "b.internal"},
+		})
+	}
+	state := func(typ tokens.Type) *StepEventStateMetadata {
+		return &StepEventStateMetadata{Type: typ, Inputs: props(), Outputs: props()}
+	}
+
+	metadata := redactStepEventMetadata(StepEventMetadata{
+		Old: state("aws:rds/instance:Instance"),
+		New: state("aws:ec2/instance:Instance"),
+	}, redactions)
+
+	// Type-restricted rules only apply to matching resources.
+	for _, props := range []resource.PropertyMap{metadata.Old.Inputs, metadata.Old.Outputs} {
+		assert.Equal(t, "[host]", props["endpoint"].ObjectValue()["host"].StringValue())
+		assert.Equal(t, float64(5432), props["endpoint"].ObjectValue()["port"].NumberValue())
+		assert.Equal(t, "[redacted]", props["servers"].ArrayValue()[0].StringValue())
+		assert.Equal(t, "b.internal", props["servers"].ArrayValue()[1].StringValue())
+	}
+	assert.Equal(t, "10.0.0.1", metadata.New.Inputs["endpoint"].ObjectValue()["host"].StringValue())
+	assert.Equal(t, "[redacted]", metadata.New.Inputs["servers"].ArrayValue()[0].StringValue())
+
+	// Missing properties are left alone, rather than being added.
+	empty := redactStepEventMetadata(StepEventMetadata{
+		New: &StepEventStateMetadata{Type: "aws:rds/instance:Instance", Inputs: resource.PropertyMap{}},
+	}, redactions)
+	assert.Empty(t, empty.New.Inputs)
+}
+
+func TestApplyRedactionRulesErrors(t *testing.T) {
+	for _, rule := range []workspace.RedactionRule{
+		{},
+		{Pattern: "a", Property: "b"},
+		{Pattern: "a", Type: "b"},
+		{Pattern: "("},
+		{Property: "a", Type: "("},
+		{Property: "a[x"},
+	} {
+		_, err := applyRedactionRules(&workspace.Project{Redact: []workspace.RedactionRule{rule}})
+		assert.Error(t, err, "%+v", rule)
+	}
+}
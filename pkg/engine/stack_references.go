@@ -0,0 +1,152 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// stackReferenceChecker checks the outputs read through stack references against the outputs that the project
+// declares it reads from the referenced stacks and, if the backend supports it, against the output schemas that the
+// referenced stacks have published. A reference that breaks its contract fails as it is read.
+type stackReferenceChecker struct {
+	deploy.BackendClient
+	refs map[string]workspace.ProjectStackReference
+}
+
+// newStackReferenceChecker wraps the given backend client so that it checks the given stack references, if there are
+// any.
+func newStackReferenceChecker(client deploy.BackendClient,
+	refs map[string]workspace.ProjectStackReference) deploy.BackendClient {
+
+	if client == nil || len(refs) == 0 {
+		return client
+	}
+	return &stackReferenceChecker{BackendClient: client, refs: refs}
+}
+
+func (c *stackReferenceChecker) GetStackOutputs(ctx context.Context, name string) (resource.PropertyMap, error) {
+	outputs, err := c.BackendClient.GetStackOutputs(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	ref, has := c.lookup(name)
+	if !has || len(ref.Outputs) == 0 {
+		return outputs, nil
+	}
+
+	var schema *apitype.StackSchema
+	if sc, ok := c.BackendClient.(deploy.StackSchemaClient); ok {
+		if schema, err = sc.GetStackSchema(ctx, name); err != nil {
+			return nil, errors.Wrapf(err, "getting the schema of stack %s", name)
+		}
+	}
+
+	var keys []string
+	for k := range ref.Outputs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var problems []string
+	for _, k := range keys {
+		expected := ref.Outputs[k]
+		if schema != nil {
+			declared, has := schema.Outputs[k]
+			switch {
+			case !has:
+				problems = append(problems, fmt.Sprintf("the stack's schema does not declare output '%s'", k))
+			case expected.Type != "" && declared.Type != "" && declared.Type != expected.Type:
+				problems = append(problems, fmt.Sprintf("the stack's schema declares output '%s' as %s, not %s",
+					k, declared.Type, expected.Type))
+			}
+		}
+
+		v, has := outputs[resource.PropertyKey(k)]
+		switch {
+		case !has:
+			problems = append(problems, fmt.Sprintf("the stack has no output '%s'", k))
+		case !outputHasType(v, expected.Type):
+			problems = append(problems, fmt.Sprintf("output '%s' is not a valid %s", k, expected.Type))
+		}
+	}
+	if len(problems) != 0 {
+		return nil, errors.Errorf("stack reference %s does not provide the outputs this project reads from it: %s",
+			name, strings.Join(problems, "; "))
+	}
+	return outputs, nil
+}
+
+// lookup returns the project's declaration of the named stack reference. If the backend accepts several names for the
+// same stack, names are compared in their canonical form, so that the declaration is found however the stack is named.
+func (c *stackReferenceChecker) lookup(name string) (workspace.ProjectStackReference, bool) {
+	if ref, has := c.refs[name]; has {
+		return ref, true
+	}
+
+	normalizer, ok := c.BackendClient.(deploy.StackReferenceNormalizer)
+	if !ok {
+		return workspace.ProjectStackReference{}, false
+	}
+	canonical, err := normalizer.NormalizeStackReference(name)
+	if err != nil {
+		return workspace.ProjectStackReference{}, false
+	}
+	for declared, ref := range c.refs {
+		if n, err := normalizer.NormalizeStackReference(declared); err == nil && n == canonical {
+			return ref, true
+		}
+	}
+	return workspace.ProjectStackReference{}, false
+}
+
+// outputHasType returns true if the given output value has the given type. Every value has the empty type, and
+// unknown values have every type.
+func outputHasType(v resource.PropertyValue, typ string) bool {
+	if v.IsSecret() {
+		v = v.SecretValue().Element
+	}
+	if v.IsComputed() || v.IsOutput() {
+		return true
+	}
+
+	switch typ {
+	case workspace.ConfigTypeString:
+		return v.IsString()
+	case workspace.ConfigTypeInteger:
+		return v.IsNumber() && v.NumberValue() == math.Trunc(v.NumberValue())
+	case workspace.ConfigTypeNumber:
+		return v.IsNumber()
+	case workspace.ConfigTypeBoolean:
+		return v.IsBool()
+	case workspace.ConfigTypeArray:
+		return v.IsArray()
+	case workspace.ConfigTypeObject:
+		return v.IsObject()
+	default:
+		return true
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/deploytest"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+type schemaBackendClient struct {
+	deploytest.BackendClient
+	schema *apitype.StackSchema
+}
+
+func (c *schemaBackendClient) GetStackSchema(ctx context.Context, name string) (*apitype.StackSchema, error) {
+	return c.schema, nil
+}
+
+func TestStackReferenceChecker(t *testing.T) {
+	outputs := resource.NewPropertyMapFromMap(map[string]interface{}{
+		"vpcId":    "vpc-123",
+		"subnets":  []interface{}{"a", "b"},
+		"replicas": 3,
+	})
+	outputs["password"] = resource.MakeSecret(resource.NewStringProperty("hunter2"))
+
+	inner := &schemaBackendClient{BackendClient: deploytest.BackendClient{
+		GetStackOutputsF: func(ctx context.Context, name string) (resource.PropertyMap, error) {
+			return outputs, nil
+		},
+	}}
+
+	client := newStackReferenceChecker(inner, map[string]workspace.ProjectStackReference{
+		"acme/network/prod": {Outputs: map[string]workspace.ProjectOutputType{
+			"vpcId":    {Type: workspace.ConfigTypeString},
+			"subnets":  {Type: workspace.ConfigTypeArray},
+			"replicas": {Type: workspace.ConfigTypeInteger},
+			"password": {Type: workspace.ConfigTypeString, Secret: true},
+		}},
+		"acme/dns/prod": {Outputs: map[string]workspace.ProjectOutputType{
+			"vpcId":  {Type: workspace.ConfigTypeBoolean},
+			"zoneId": {},
+		}},
+	})
+
+	// Stacks without declared outputs are not checked.
+	_, err := client.GetStackOutputs(context.Background(), "acme/other/prod")
+	assert.NoError(t, err)
+
+	// Without a published schema, only the outputs themselves are checked.
+	actual, err := client.GetStackOutputs(context.Background(), "acme/network/prod")
+	assert.NoError(t, err)
+	assert.Equal(t, outputs, actual)
+
+	_, err = client.GetStackOutputs(context.Background(), "acme/dns/prod")
+	assert.EqualError(t, err, "stack reference acme/dns/prod does not provide the outputs this project reads from it: "+
+		"output 'vpcId' is not a valid boolean; the stack has no output 'zoneId'")
+
+	inner.schema = &apitype.StackSchema{Outputs: map[string]apitype.StackSchemaType{
+		"vpcId":    {Type: "string"},
+		"subnets":  {Type: "array"},
+		"replicas": {Type: "number"},
+	}}
+	_, err = client.GetStackOutputs(context.Background(), "acme/network/prod")
+	assert.EqualError(t, err, "stack reference acme/network/prod does not provide the outputs this project reads from "+
+		"it: the stack's schema does not declare output 'password'; "+
+		"the stack's schema declares output 'replicas' as number, not integer")
+
+	// A client without a checker is returned as is.
+	assert.Equal(t, inner, newStackReferenceChecker(inner, nil))
+}
+
+func TestOutputHasType(t *testing.T) {
+	assert.True(t, outputHasType(resource.NewNumberProperty(3), workspace.ConfigTypeInteger))
+	assert.False(t, outputHasType(resource.NewNumberProperty(3.5), workspace.ConfigTypeInteger))
+	assert.True(t, outputHasType(resource.NewNumberProperty(3.5), workspace.ConfigTypeNumber))
+	assert.True(t, outputHasType(resource.NewBoolProperty(true), workspace.ConfigTypeBoolean))
+	assert.True(t, outputHasType(resource.NewObjectProperty(resource.PropertyMap{}), workspace.ConfigTypeObject))
+	assert.False(t, outputHasType(resource.NewStringProperty("x"), workspace.ConfigTypeObject))
+	assert.True(t, outputHasType(resource.NewStringProperty("x"), ""))
+	assert.True(t, outputHasType(resource.MakeComputed(resource.NewStringProperty("")), workspace.ConfigTypeArray))
+}
+
+type normalizingBackendClient struct {
+	deploytest.BackendClient
+}
+
+func (c *normalizingBackendClient) NormalizeStackReference(name string) (string, error) {
+	if strings.Count(name, "/") == 1 {
+		return "acme/" + name, nil
+	}
+	return name, nil
+}
+
+func TestStackReferenceCheckerNormalizesNames(t *testing.T) {
+	inner := &normalizingBackendClient{BackendClient: deploytest.BackendClient{
+		GetStackOutputsF: func(ctx context.Context, name string) (resource.PropertyMap, error) {
+			return resource.NewPropertyMapFromMap(map[string]interface{}{"vpcId": 42}), nil
+		},
+	}}
+
+	client := newStackReferenceChecker(inner, map[string]workspace.ProjectStackReference{
+		"network/prod": {Outputs: map[string]workspace.ProjectOutputType{
+			"vpcId": {Type: workspace.ConfigTypeString},
+		}},
+	})
+
+	// The declaration applies however the program names the stack.
+	for _, name := range []string{"network/prod", "acme/network/prod"} {
+		_, err := client.GetStackOutputs(context.Background(), name)
+		assert.EqualError(t, err, "stack reference "+name+" does not provide the outputs this project reads from it: "+
+			"output 'vpcId' is not a valid string")
+	}
+
+	_, err := client.GetStackOutputs(context.Background(), "acme/network/dev")
+	assert.NoError(t, err)
+}
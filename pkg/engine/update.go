@@ -26,6 +26,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/resource/plugin"
 	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/gitutil"
 	"github.com/pulumi/pulumi/pkg/util/logging"
 	"github.com/pulumi/pulumi/pkg/util/result"
 	"github.com/pulumi/pulumi/pkg/workspace"
@@ -65,6 +66,74 @@ type UpdateOptions struct {
 	// true if the engine should use legacy diffing behavior during an update.
 	UseLegacyDiff bool
 
+	// an optional set of restrictions to run the program's language host under.
+	Sandbox *plugin.SandboxOptions
+
+	// true if previews should reject and report any provider operation that is not a read.
+	StrictPreview bool
+
+	// the provider functions, e.g. `aws:index/getAmi:getAmi`, that a strict preview may invoke.
+	StrictPreviewInvokes []string
+
+	// an optional configuration of synthetic providers to use in place of every resource provider, which simulate
+	// resource lifecycles without contacting any cloud.
+	Mock *plugin.MockOptions
+
+	// true if the pre-flight checks that normally run before an update or preview starts should be skipped.
+	SkipPreflight bool
+
+	// an optional check, run with the pre-flight checks, that the stack being updated may be written.
+	CheckWritable func() error
+
+	// the providers configured by pre-flight checks that ran before the update started, if any. The update uses them
+	// rather than running the checks itself.
+	Preflight *PreflightProviders
+
+	// true if the stack's guardrails should not be enforced.
+	OverrideGuardrails bool
+
+	// true if uses of deprecated resource types and properties should fail the operation, rather than warn.
+	StrictDeprecations bool
+
+	// true if previews should only validate resources with their providers' Check and Diff methods, skipping
+	// invokes, reads, and policy packs, and reporting every resource that fails its checks. Updates ignore it.
+	CheckOnly bool
+
+	// an optional set of URNs of the resources to update. If non-empty, only these resources are created, updated,
+	// or deleted; all other resources are left as they are.
+	UpdateTargets []resource.URN
+
+	// true if resources that depend on an update target, directly or transitively, should be targeted as well.
+	TargetDependents bool
+
+	// an optional set of existing resources to import into the stack. If non-empty, the stack's program is not run:
+	// the update imports these resources, along with any default providers they need, and leaves the stack's other
+	// resources as they are.
+	Imports []deploy.Import
+
+	// an optional plan saved by an earlier preview. If set, the operation fails rather than perform any step whose
+	// operation the plan doesn't record.
+	Plan *UpdatePlan
+
+	// an optional plan in which previews record the operations they find the update would perform.
+	SavePlan *UpdatePlan
+
+	// an optional report in which the previews of refreshes record the resources whose live state differs from their
+	// checkpointed state.
+	DriftReport *DriftReport
+
+	// an optional history of how long resource operations take. Updates record their operations' durations in it,
+	// and previews use it to estimate how long their changes will take.
+	Timings *workspace.OperationTimings
+
+	// the latency above which a checkpoint write is considered to be lagging, after which the update reduces its
+	// parallelism until checkpoints are persisted in time. Zero uses DefaultCheckpointLagThreshold; a negative value
+	// disables the safety valve.
+	CheckpointLagThreshold time.Duration
+
+	// bounds on the memory the operation uses, and whether to report its peak usage.
+	Memory MemoryOptions
+
 	// true if we should report events for steps that involve default providers.
 	reportDefaultProviderSteps bool
 
@@ -102,9 +171,18 @@ func Update(u UpdateInfo, ctx *Context, opts UpdateOptions, dryRun bool) (Resour
 	if err != nil {
 		return nil, result.FromError(err)
 	}
+
+	// An import targets only the resources it imports, so that the stack's other resources are left as they are.
+	sourceFunc := newUpdateSource
+	if len(opts.Imports) > 0 {
+		sourceFunc = newImportSource
+		opts.UpdateTargets = deploy.ImportTargets(u.GetProject(), u.GetTarget(), opts.Imports)
+		opts.TargetDependents = false
+	}
+
 	return update(ctx, info, planOptions{
 		UpdateOptions: opts,
-		SourceFunc:    newUpdateSource,
+		SourceFunc:    sourceFunc,
 		Events:        emitter,
 		Diag:          newEventSink(emitter, false),
 		StatusDiag:    newEventSink(emitter, true),
@@ -145,9 +223,11 @@ func installPlugins(
 	// If there are any plugins that are not available, we can attempt to install them here.
 	//
 	// Note that this is purely a best-effort thing. If we can't install missing plugins, just proceed; we'll fail later
-	// with an error message indicating exactly what plugins are missing.
-	if err := ensurePluginsAreInstalled(allPlugins); err != nil {
-		logging.V(7).Infof("newUpdateSource(): failed to install missing plugins: %v", err)
+	// with an error message indicating exactly what plugins are missing. Synthetic providers need no plugins.
+	if plugctx.Mock == nil {
+		if err := ensurePluginsAreInstalled(allPlugins); err != nil {
+			logging.V(7).Infof("newUpdateSource(): failed to install missing plugins: %v", err)
+		}
 	}
 
 	// Collect the version information for default providers.
@@ -186,6 +266,18 @@ func newUpdateSource(
 		return nil, err
 	}
 
+	// Check that the update can proceed before the program starts, unless the checks ran before the update started.
+	// The default providers configured by the checks are handed over to the update, so that none is launched twice.
+	if opts.Preflight != nil {
+		plugctx.Host = opts.Preflight.handOver(plugctx)
+	} else if !opts.SkipPreflight {
+		host, err := preflight(plugctx, proj, target, allPlugins, defaultProviderVersions, opts.UpdateOptions)
+		if err != nil {
+			return nil, err
+		}
+		plugctx.Host = host
+	}
+
 	// Once we've installed all of the plugins we need, make sure that all analyzers and language plugins are
 	// loaded up and ready to go. Provider plugins are loaded lazily by the provider registry and thus don't
 	// need to be loaded here.
@@ -202,6 +294,12 @@ func newUpdateSource(
 		return nil, err
 	}
 
+	// Tag propagation may refer to the commit the program is being run from, so look it up if necessary.
+	var commit string
+	if proj.TagPropagation != nil {
+		commit = getCurrentCommit(pwd)
+	}
+
 	// If that succeeded, create a new source that will perform interpretation of the compiled program.
 	// TODO[pulumi/pulumi#88]: we are passing `nil` as the arguments map; we need to allow a way to pass these.
 	return deploy.NewEvalSource(plugctx, &deploy.EvalRunInfo{
@@ -209,10 +307,56 @@ func newUpdateSource(
 		Pwd:     pwd,
 		Program: main,
 		Target:  target,
+		Commit:  commit,
 	}, defaultProviderVersions, dryRun), nil
 }
 
+func newImportSource(
+	client deploy.BackendClient, opts planOptions, proj *workspace.Project, pwd, main string,
+	target *deploy.Target, plugctx *plugin.Context, dryRun bool) (deploy.Source, error) {
+
+	// Install the plugins the program and the snapshot need, so that imported resources use the same default provider
+	// versions that the program would.
+	_, defaultProviderVersions, err := installPlugins(proj, pwd, main, target, plugctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := installAndLoadPolicyPlugins(plugctx, opts.RequiredPolicies); err != nil {
+		return nil, err
+	}
+
+	return deploy.NewImportSource(proj, target, defaultProviderVersions, opts.Imports), nil
+}
+
+// getCurrentCommit returns the hash of the HEAD commit of the Git repository containing the given directory, or the
+// empty string if there is no such repository.
+func getCurrentCommit(dir string) string {
+	repo, err := gitutil.GetGitRepository(dir)
+	if err != nil || repo == nil {
+		logging.V(7).Infof("no Git repository found for %s: %v", dir, err)
+		return ""
+	}
+	head, err := repo.Head()
+	if err != nil {
+		logging.V(7).Infof("could not read Git HEAD for %s: %v", dir, err)
+		return ""
+	}
+	return head.Hash().String()
+}
+
 func update(ctx *Context, info *planContext, opts planOptions, dryRun bool) (ResourceChanges, result.Result) {
+	opts.memory = startMemoryMonitor(opts.Memory)
+	defer opts.memory.Stop()
+	opts.interner = newPropertyInterner(opts.Memory)
+	opts.Events.limitDetails(opts.Memory.MaxDiffDetails)
+
+	// Before an update changes anything, check the changes its saved plan allows it to make.
+	if !dryRun {
+		if res := validateUpdate(info, opts); res != nil {
+			return nil, res
+		}
+	}
+
 	planResult, err := plan(ctx, info, opts, dryRun)
 	if err != nil {
 		return nil, result.FromError(err)
@@ -241,12 +385,17 @@ func update(ctx *Context, info *planContext, opts planOptions, dryRun bool) (Res
 			start := time.Now()
 			actions := newUpdateActions(ctx, info.Update, opts)
 
-			res = planResult.Walk(ctx, actions, false)
+			var throttle deploy.StepThrottle
+			if actions.Valve != nil {
+				throttle = actions.Valve
+			}
+			res = planResult.Walk(ctx, actions, throttle, false)
 			resourceChanges = ResourceChanges(actions.Ops)
 
 			if len(resourceChanges) != 0 {
 				// Print out the total number of steps performed (and their kinds), the duration, and any summary info.
-				opts.Events.updateSummaryEvent(actions.MaybeCorrupt, time.Since(start), resourceChanges)
+				opts.Events.updateSummaryEvent(actions.MaybeCorrupt, time.Since(start), resourceChanges,
+					actions.Deprecations, opts.memory.Peak())
 			}
 		}
 	}
@@ -263,31 +412,79 @@ type updateActions struct {
 	MaybeCorrupt bool
 	Update       UpdateInfo
 	Opts         planOptions
+	Guardrails   *guardrailChecker
+	Deprecations map[string]int
+	Started      map[deploy.Step]time.Time
+	Valve        *checkpointValve
 }
 
 func newUpdateActions(context *Context, u UpdateInfo, opts planOptions) *updateActions {
+	var valve *checkpointValve
+	if threshold := opts.CheckpointLagThreshold; threshold >= 0 {
+		if threshold == 0 {
+			threshold = DefaultCheckpointLagThreshold
+		}
+		valve = newCheckpointValve(opts.Parallel, threshold)
+	}
+
 	return &updateActions{
-		Context: context,
-		Ops:     make(map[deploy.StepOp]int),
-		Seen:    make(map[resource.URN]deploy.Step),
-		Update:  u,
-		Opts:    opts,
+		Context:      context,
+		Ops:          make(map[deploy.StepOp]int),
+		Seen:         make(map[resource.URN]deploy.Step),
+		Update:       u,
+		Opts:         opts,
+		Guardrails:   newGuardrailChecker(u.GetTarget().Guardrails, opts.OverrideGuardrails),
+		Deprecations: make(map[string]int),
+		Started:      make(map[deploy.Step]time.Time),
+		Valve:        valve,
+	}
+}
+
+// checkpoint performs a checkpoint write, reporting its latency and result to the update's checkpoint valve.
+func (acts *updateActions) checkpoint(write func() error) error {
+	start := time.Now()
+	err := write()
+	if acts.Valve != nil {
+		acts.Valve.observe(time.Since(start), err)
 	}
+	return err
 }
 
 func (acts *updateActions) OnResourceStepPre(step deploy.Step) (interface{}, error) {
 	// Ensure we've marked this step as observed.
 	acts.MapLock.Lock()
+	_, seen := acts.Seen[step.URN()]
 	acts.Seen[step.URN()] = step
 	acts.MapLock.Unlock()
 
+	if err := acts.Guardrails.check(step); err != nil {
+		return nil, err
+	}
+	if err := acts.Opts.Plan.check(step); err != nil {
+		return nil, err
+	}
+	if !seen {
+		// Show any notes on the resource's planned changes once, before the first of its steps.
+		acts.Opts.Plan.reportNotes(step, acts.Opts.Events)
+	}
+
 	// Skip reporting if necessary.
 	if shouldReportStep(step, acts.Opts) {
 		acts.Opts.Events.resourcePreEvent(step, false /*planning*/, acts.Opts.Debug)
 	}
 
+	acts.MapLock.Lock()
+	acts.Started[step] = time.Now()
+	acts.MapLock.Unlock()
+
 	// Inform the snapshot service that we are about to perform a step.
-	return acts.Context.SnapshotManager.BeginMutation(step)
+	var mutation SnapshotMutation
+	err := acts.checkpoint(func() error {
+		var err error
+		mutation, err = acts.Context.SnapshotManager.BeginMutation(step)
+		return err
+	})
+	return mutation, err
 }
 
 func (acts *updateActions) OnResourceStepPost(
@@ -296,8 +493,19 @@ func (acts *updateActions) OnResourceStepPost(
 
 	acts.MapLock.Lock()
 	assertSeen(acts.Seen, step)
+	started, hasStarted := acts.Started[step]
+	delete(acts.Started, step)
 	acts.MapLock.Unlock()
 
+	if err == nil {
+		acts.Opts.interner.internState(step.New())
+	}
+
+	// Record how long the operation took, so that future previews can estimate how long their changes will take.
+	if err == nil && hasStarted && acts.Opts.Timings != nil && isTimedStep(step) {
+		acts.Opts.Timings.Record(step.Type(), string(step.Op()), time.Since(started))
+	}
+
 	// If we've already been terminated, exit without writing the checkpoint. We explicitly want to leave the
 	// checkpoint in an inconsistent state in this event.
 	if acts.Context.Cancel.TerminateErr() != nil {
@@ -377,7 +585,9 @@ func (acts *updateActions) OnResourceStepPost(
 	// Write out the current snapshot. Note that even if a failure has occurred, we should still have a
 	// safe checkpoint.  Note that any error that occurs when writing the checkpoint trumps the error
 	// reported above.
-	return ctx.(SnapshotMutation).End(step, err == nil || status == resource.StatusPartialFailure)
+	return acts.checkpoint(func() error {
+		return ctx.(SnapshotMutation).End(step, err == nil || status == resource.StatusPartialFailure)
+	})
 }
 
 func (acts *updateActions) OnResourceOutputs(step deploy.Step) error {
@@ -385,6 +595,8 @@ func (acts *updateActions) OnResourceOutputs(step deploy.Step) error {
 	assertSeen(acts.Seen, step)
 	acts.MapLock.Unlock()
 
+	acts.Opts.interner.internState(step.New())
+
 	// Skip reporting if necessary.
 	if shouldReportStep(step, acts.Opts) {
 		acts.Opts.Events.resourceOutputsEvent(step.Op(), step, false /*planning*/, acts.Opts.Debug)
@@ -392,9 +604,23 @@ func (acts *updateActions) OnResourceOutputs(step deploy.Step) error {
 
 	// There's a chance there are new outputs that weren't written out last time.
 	// We need to perform another snapshot write to ensure they get written out.
-	return acts.Context.SnapshotManager.RegisterResourceOutputs(step)
+	return acts.checkpoint(func() error {
+		return acts.Context.SnapshotManager.RegisterResourceOutputs(step)
+	})
 }
 
 func (acts *updateActions) OnPolicyViolation(urn resource.URN, d plugin.AnalyzeDiagnostic) {
 	acts.Opts.Events.policyViolationEvent(urn, d)
 }
+
+func (acts *updateActions) OnPolicyRemediation(urn resource.URN, r plugin.Remediation,
+	before, after resource.PropertyMap) {
+
+	acts.Opts.Events.policyRemediationEvent(urn, r, before, after)
+}
+
+func (acts *updateActions) OnDeprecation(urn resource.URN, d deploy.Deprecation) {
+	acts.MapLock.Lock()
+	acts.Deprecations[d.Item]++
+	acts.MapLock.Unlock()
+}
@@ -0,0 +1,432 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+)
+
+// UpdatePlan records the changes that a preview found an update would make to each resource: the operations it would
+// perform, and the inputs and changed properties of the resources it would create, update, or replace. A plan saved by
+// a preview may be given to a later update, which then fails rather than make any change the plan doesn't record.
+//
+// Reviewers may attach notes to the changes planned for a resource, which are shown when the plan is applied.
+type UpdatePlan struct {
+	lock      sync.Mutex
+	resources map[resource.URN]*resourcePlan
+	notes     map[resource.URN][]PlanNote
+}
+
+// resourcePlan records the changes planned for a single resource.
+type resourcePlan struct {
+	ops      []deploy.StepOp
+	inputs   map[string]interface{} // the resource's planned inputs, serialized, or nil if none were recorded.
+	unknowns map[string]bool        // the planned inputs whose values were unknown.
+	diffs    map[string]bool        // the properties expected to change, or nil if none were recorded.
+}
+
+// PlanNote is a reviewer's note on the changes planned for a resource.
+type PlanNote struct {
+	Author  string // the reviewer who wrote the note, if known.
+	Message string // the text of the note.
+}
+
+// NewUpdatePlan returns an empty plan, which previews record their operations in.
+func NewUpdatePlan() *UpdatePlan {
+	return &UpdatePlan{
+		resources: make(map[resource.URN]*resourcePlan),
+		notes:     make(map[resource.URN][]PlanNote),
+	}
+}
+
+// Resources returns the URNs of the resources the plan records operations for, in sorted order.
+func (p *UpdatePlan) Resources() []resource.URN {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	urns := make([]resource.URN, 0, len(p.resources))
+	for urn := range p.resources {
+		urns = append(urns, urn)
+	}
+	sort.Slice(urns, func(i, j int) bool { return urns[i] < urns[j] })
+	return urns
+}
+
+// Ops returns the operations the plan records for the resource with the given URN.
+func (p *UpdatePlan) Ops(urn resource.URN) []deploy.StepOp {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if rp, ok := p.resources[urn]; ok {
+		return append([]deploy.StepOp(nil), rp.ops...)
+	}
+	return nil
+}
+
+// Notes returns the notes attached to the changes planned for the resource with the given URN.
+func (p *UpdatePlan) Notes(urn resource.URN) []PlanNote {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return append([]PlanNote(nil), p.notes[urn]...)
+}
+
+// AddNote attaches the given note to the changes planned for the resource with the given URN, which must be in the
+// plan.
+func (p *UpdatePlan) AddNote(urn resource.URN, note PlanNote) error {
+	if note.Message == "" {
+		return errors.New("a note must have a message")
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.resources[urn]; !ok {
+		return errors.Errorf("resource '%s' is not in the plan", urn)
+	}
+	p.notes[urn] = append(p.notes[urn], note)
+	return nil
+}
+
+// reportNotes emits the notes attached to the changes planned for the given step's resource. A nil plan has no notes.
+func (p *UpdatePlan) reportNotes(step deploy.Step, events eventEmitter) {
+	if p == nil || step.Op() == deploy.OpSame {
+		return
+	}
+
+	for _, note := range p.Notes(step.URN()) {
+		msg := "note: " + note.Message
+		if note.Author != "" {
+			msg = fmt.Sprintf("note from %s: %s", note.Author, note.Message)
+		}
+		events.diagInfoEvent(&diag.Diag{URN: step.URN()}, "", msg+"\n", false)
+	}
+}
+
+// record adds the given step's operation to the plan, along with the inputs and changed properties of the resource it
+// creates, updates, or replaces. A nil plan records nothing.
+func (p *UpdatePlan) record(step deploy.Step) error {
+	if p == nil {
+		return nil
+	}
+
+	var inputs map[string]interface{}
+	var unknowns map[string]bool
+	if plansInputs(step) {
+		var err error
+		if inputs, unknowns, err = planInputs(step.New().Inputs); err != nil {
+			return errors.Wrapf(err, "recording the planned inputs of resource '%s'", step.URN())
+		}
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	urn, op := step.URN(), step.Op()
+	rp, ok := p.resources[urn]
+	if !ok {
+		rp = &resourcePlan{}
+		p.resources[urn] = rp
+	}
+	if inputs != nil && rp.inputs == nil {
+		rp.inputs, rp.unknowns = inputs, unknowns
+	}
+	if diffs, ok := stepDiffs(step); ok && len(diffs) > 0 {
+		if rp.diffs == nil {
+			rp.diffs = make(map[string]bool)
+		}
+		for _, k := range diffs {
+			rp.diffs[string(k)] = true
+		}
+	}
+	for _, planned := range rp.ops {
+		if planned == op {
+			return nil
+		}
+	}
+	rp.ops = append(rp.ops, op)
+	return nil
+}
+
+// check returns an error if the plan doesn't record the given step's operation, or if the step would give its resource
+// inputs or change properties of it that the plan doesn't. Leaving a resource as it is, or refreshing its state, never
+// violates the plan. A nil plan allows every step.
+func (p *UpdatePlan) check(step deploy.Step) error {
+	if p == nil || step.Op() == deploy.OpSame || step.Op() == deploy.OpRefresh {
+		return nil
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	urn, op := step.URN(), step.Op()
+	rp, ok := p.resources[urn]
+	if !ok {
+		return errors.Errorf("resource '%s' is not in the plan, but the update would %s it; "+
+			"preview the update again to save a new plan", urn, op)
+	}
+	for _, planOp := range rp.ops {
+		if planOp == op {
+			return rp.checkChanges(step)
+		}
+	}
+	return errors.Errorf("the update would %s resource '%s', which the plan doesn't allow; "+
+		"preview the update again to save a new plan", op, urn)
+}
+
+// verify checks every one of the given steps against the plan, and returns an error for each step that violates it.
+// A nil plan allows every step.
+func (p *UpdatePlan) verify(steps []deploy.Step) []error {
+	var errs []error
+	seen := make(map[string]bool)
+	for _, step := range steps {
+		if err := p.check(step); err != nil && !seen[err.Error()] {
+			// The steps that make up a replacement may violate the plan in the same way; report that only once.
+			seen[err.Error()] = true
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// verifyLocks returns an error for each locked resource in the given snapshot that the plan would update, replace, or
+// delete. A nil plan changes no resource.
+func (p *UpdatePlan) verifyLocks(snap *deploy.Snapshot) []error {
+	if p == nil || snap == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, res := range snap.Resources {
+		if !res.Locked || res.Delete {
+			continue
+		}
+		for _, op := range p.Ops(res.URN) {
+			if verb, ok := lockedVerbs[op]; ok {
+				errs = append(errs, errors.Errorf("the plan would %s locked resource '%s'; "+
+					"run `pulumi state unlock` to unlock it first", verb, res.URN))
+				break
+			}
+		}
+	}
+	return errs
+}
+
+// lockedVerbs describes the operations that a locked resource refuses.
+var lockedVerbs = map[deploy.StepOp]string{
+	deploy.OpUpdate:            "update",
+	deploy.OpDelete:            "delete",
+	deploy.OpReplace:           "replace",
+	deploy.OpCreateReplacement: "replace",
+	deploy.OpDeleteReplaced:    "replace",
+	deploy.OpReadReplacement:   "replace",
+	deploy.OpImportReplacement: "replace",
+}
+
+// checkChanges returns an error if the given step would give its resource inputs other than those planned for it, or
+// change properties of it that the plan doesn't expect to change. Inputs whose values were unknown when the plan was
+// made, or are unknown now, may take any value.
+func (rp *resourcePlan) checkChanges(step deploy.Step) error {
+	if rp.inputs == nil || !plansInputs(step) {
+		return nil
+	}
+
+	urn := step.URN()
+	inputs, unknowns, err := planInputs(step.New().Inputs)
+	if err != nil {
+		return errors.Wrapf(err, "checking the inputs of resource '%s' against the plan", urn)
+	}
+	for _, k := range sortedKeys(inputs) {
+		if planned, ok := rp.inputs[k]; !rp.unknowns[k] && (!ok || !reflect.DeepEqual(planned, inputs[k])) {
+			return errors.Errorf("the update would set input '%s' of resource '%s' to a value the plan doesn't "+
+				"allow; preview the update again to save a new plan", k, urn)
+		}
+	}
+	for _, k := range sortedKeys(rp.inputs) {
+		if _, ok := inputs[k]; !ok && !unknowns[k] && !rp.unknowns[k] {
+			return errors.Errorf("the update would remove input '%s' of resource '%s', which the plan doesn't "+
+				"allow; preview the update again to save a new plan", k, urn)
+		}
+	}
+
+	if diffs, ok := stepDiffs(step); ok && rp.diffs != nil {
+		for _, k := range diffs {
+			if !rp.diffs[string(k)] && !rp.unknowns[string(k)] {
+				return errors.Errorf("the update would change property '%s' of resource '%s', which the plan "+
+					"doesn't expect to change; preview the update again to save a new plan", k, urn)
+			}
+		}
+	}
+	return nil
+}
+
+// plansInputs returns true if the plan records the inputs that the given step gives its resource.
+func plansInputs(step deploy.Step) bool {
+	switch step.Op() {
+	case deploy.OpCreate, deploy.OpUpdate, deploy.OpReplace, deploy.OpCreateReplacement,
+		deploy.OpImport, deploy.OpImportReplacement:
+		return step.New() != nil
+	default:
+		return false
+	}
+}
+
+// stepDiffs returns the properties that the given step changes, if it reports them.
+func stepDiffs(step deploy.Step) ([]resource.PropertyKey, bool) {
+	switch step.Op() {
+	case deploy.OpUpdate, deploy.OpReplace, deploy.OpCreateReplacement:
+		if d, ok := step.(interface{ Diffs() []resource.PropertyKey }); ok {
+			return d.Diffs(), true
+		}
+	}
+	return nil, false
+}
+
+// planInputs serializes the given inputs as a plan records them, along with the names of those whose values are
+// unknown. Values are normalized as they would be read back from a saved plan, so that they compare equal.
+func planInputs(props resource.PropertyMap) (map[string]interface{}, map[string]bool, error) {
+	inputs, unknowns := make(map[string]interface{}), make(map[string]bool)
+	for k, v := range props {
+		if v.ContainsUnknowns() {
+			unknowns[string(k)] = true
+			continue
+		}
+
+		bytes, err := json.Marshal(v.MapRepl(nil, planValue))
+		if err != nil {
+			return nil, nil, err
+		}
+		var normalized interface{}
+		if err = json.Unmarshal(bytes, &normalized); err != nil {
+			return nil, nil, err
+		}
+		inputs[string(k)] = normalized
+	}
+	return inputs, unknowns, nil
+}
+
+// planValue replaces the values of secrets with their signature alone, so that plans never contain them, and assets
+// and archives with their serialized forms.
+func planValue(v resource.PropertyValue) (interface{}, bool) {
+	switch {
+	case v.IsSecret():
+		return map[string]interface{}{resource.SigKey: resource.SecretSig}, true
+	case v.IsAsset():
+		return v.AssetValue().Serialize(), true
+	case v.IsArchive():
+		return v.ArchiveValue().Serialize(), true
+	default:
+		return nil, false
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SerializeUpdatePlan serializes the given plan into its versioned file format.
+func SerializeUpdatePlan(p *UpdatePlan) (*apitype.VersionedUpdatePlan, error) {
+	p.lock.Lock()
+	plan := apitype.UpdatePlanV1{Resources: make(map[resource.URN]apitype.ResourcePlanV1)}
+	for urn, rp := range p.resources {
+		saved := apitype.ResourcePlanV1{Ops: make([]string, len(rp.ops)), Inputs: rp.inputs}
+		for i, op := range rp.ops {
+			saved.Ops[i] = string(op)
+		}
+		for k := range rp.unknowns {
+			saved.Unknowns = append(saved.Unknowns, k)
+		}
+		sort.Strings(saved.Unknowns)
+		for k := range rp.diffs {
+			saved.Diffs = append(saved.Diffs, k)
+		}
+		sort.Strings(saved.Diffs)
+		for _, note := range p.notes[urn] {
+			saved.Notes = append(saved.Notes, apitype.PlanNoteV1{Author: note.Author, Message: note.Message})
+		}
+		plan.Resources[urn] = saved
+	}
+	p.lock.Unlock()
+
+	bytes, err := json.Marshal(plan)
+	if err != nil {
+		return nil, err
+	}
+	return &apitype.VersionedUpdatePlan{Version: apitype.UpdatePlanSchemaVersionCurrent, Plan: bytes}, nil
+}
+
+// DeserializeUpdatePlan deserializes a plan from its versioned file format.
+func DeserializeUpdatePlan(versioned *apitype.VersionedUpdatePlan) (*UpdatePlan, error) {
+	switch {
+	case versioned.Version < 1:
+		return nil, errors.Errorf("unsupported plan version %d", versioned.Version)
+	case versioned.Version > apitype.UpdatePlanSchemaVersionCurrent:
+		return nil, errors.Errorf("the plan was saved by a newer version of Pulumi (plan version %d); "+
+			"upgrade Pulumi to use it", versioned.Version)
+	}
+
+	var plan apitype.UpdatePlanV1
+	if err := json.Unmarshal(versioned.Plan, &plan); err != nil {
+		return nil, errors.Wrap(err, "could not read the plan")
+	}
+
+	knownOps := make(map[deploy.StepOp]bool)
+	for _, op := range deploy.StepOps {
+		knownOps[op] = true
+	}
+
+	p := NewUpdatePlan()
+	for urn, saved := range plan.Resources {
+		ops := make([]deploy.StepOp, len(saved.Ops))
+		for i, op := range saved.Ops {
+			if !knownOps[deploy.StepOp(op)] {
+				return nil, errors.Errorf("the plan for resource '%s' has an unknown operation '%s'", urn, op)
+			}
+			ops[i] = deploy.StepOp(op)
+		}
+		rp := &resourcePlan{ops: ops, inputs: saved.Inputs}
+		if rp.inputs != nil {
+			rp.unknowns = make(map[string]bool)
+			for _, k := range saved.Unknowns {
+				rp.unknowns[k] = true
+			}
+		}
+		if len(saved.Diffs) > 0 {
+			rp.diffs = make(map[string]bool)
+			for _, k := range saved.Diffs {
+				rp.diffs[k] = true
+			}
+		}
+		p.resources[urn] = rp
+		for _, note := range saved.Notes {
+			p.notes[urn] = append(p.notes[urn], PlanNote{Author: note.Author, Message: note.Message})
+		}
+	}
+	return p, nil
+}
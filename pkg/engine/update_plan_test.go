@@ -0,0 +1,178 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+func TestUpdatePlan(t *testing.T) {
+	state := func(name string) *resource.State {
+		urn := resource.NewURN("dev", "proj", "", "test:index:Resource", tokens.QName(name))
+		return &resource.State{URN: urn, Type: urn.Type()}
+	}
+	a, b, c := state("a"), state("b"), state("c")
+
+	plan := NewUpdatePlan()
+	plan.record(deploy.NewReplaceStep(nil, a, a, nil, nil, nil, false))
+	plan.record(deploy.NewSameStep(nil, nil, b, b))
+	assert.Equal(t, []resource.URN{a.URN, b.URN}, plan.Resources())
+
+	// Saving and loading the plan preserves its operations.
+	versioned, err := SerializeUpdatePlan(plan)
+	if !assert.NoError(t, err) {
+		return
+	}
+	loaded, err := DeserializeUpdatePlan(versioned)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []deploy.StepOp{deploy.OpReplace}, loaded.Ops(a.URN))
+	assert.Equal(t, []deploy.StepOp{deploy.OpSame}, loaded.Ops(b.URN))
+
+	// Planned operations are allowed, and leaving a resource as it is always is.
+	assert.NoError(t, loaded.check(deploy.NewReplaceStep(nil, a, a, nil, nil, nil, false)))
+	assert.NoError(t, loaded.check(deploy.NewSameStep(nil, nil, c, c)))
+
+	// Operations that weren't planned, including those on resources the plan doesn't know, are not.
+	assert.Error(t, loaded.check(deploy.NewDeleteStep(nil, a)))
+	assert.Error(t, loaded.check(deploy.NewDeleteStep(nil, c)))
+
+	// A nil plan allows everything.
+	var none *UpdatePlan
+	assert.NoError(t, none.check(deploy.NewDeleteStep(nil, a)))
+}
+
+func TestUpdatePlanInputs(t *testing.T) {
+	urn := resource.NewURN("dev", "proj", "", "test:index:Resource", "a")
+	state := func(inputs resource.PropertyMap) *resource.State {
+		return &resource.State{URN: urn, Type: urn.Type(), Inputs: inputs}
+	}
+	old := state(resource.PropertyMap{"size": resource.NewNumberProperty(1)})
+	planned := state(resource.PropertyMap{
+		"size":     resource.NewNumberProperty(2),
+		"password": resource.MakeSecret(resource.NewStringProperty("hunter2")),
+		"arn":      resource.MakeComputed(resource.NewStringProperty("")),
+	})
+	update := func(new *resource.State, diffs ...resource.PropertyKey) deploy.Step {
+		return deploy.NewUpdateStep(nil, nil, old, new, nil, diffs, nil, nil)
+	}
+
+	plan := NewUpdatePlan()
+	assert.NoError(t, plan.record(update(planned, "size", "password")))
+
+	// Saving and loading the plan preserves its inputs, but not the values of secrets.
+	versioned, err := SerializeUpdatePlan(plan)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotContains(t, string(versioned.Plan), "hunter2")
+	loaded, err := DeserializeUpdatePlan(versioned)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// The planned inputs are allowed, with any value for those that were unknown.
+	assert.NoError(t, loaded.check(update(planned, "size", "password")))
+	assert.NoError(t, loaded.check(update(state(resource.PropertyMap{
+		"size":     resource.NewNumberProperty(2),
+		"password": resource.MakeSecret(resource.NewStringProperty("hunter2")),
+		"arn":      resource.NewStringProperty("arn:aws:s3:::bucket"),
+	}), "size", "password", "arn")))
+
+	// Other values, added or removed inputs, and unexpected changes are not.
+	assert.Error(t, loaded.check(update(state(resource.PropertyMap{
+		"size":     resource.NewNumberProperty(3),
+		"password": resource.MakeSecret(resource.NewStringProperty("hunter2")),
+	}), "size", "password")))
+	assert.Error(t, loaded.check(update(state(resource.PropertyMap{
+		"size":     resource.NewNumberProperty(2),
+		"password": resource.MakeSecret(resource.NewStringProperty("hunter2")),
+		"tags":     resource.NewStringProperty("extra"),
+	}), "size", "password", "tags")))
+	assert.Error(t, loaded.check(update(state(resource.PropertyMap{
+		"size": resource.NewNumberProperty(2),
+	}), "size", "password")))
+	assert.Error(t, loaded.check(update(planned, "size", "password", "name")))
+
+	// Every violation of the plan is found at once.
+	other := resource.NewURN("dev", "proj", "", "test:index:Resource", "b")
+	errs := loaded.verify([]deploy.Step{
+		update(state(resource.PropertyMap{"size": resource.NewNumberProperty(3)})),
+		deploy.NewDeleteStep(nil, &resource.State{URN: other, Type: other.Type()}),
+	})
+	assert.Len(t, errs, 2)
+}
+
+func TestDeserializeUpdatePlan(t *testing.T) {
+	_, err := DeserializeUpdatePlan(&apitype.VersionedUpdatePlan{
+		Version: apitype.UpdatePlanSchemaVersionCurrent + 1,
+		Plan:    json.RawMessage(`{}`),
+	})
+	assert.Error(t, err)
+
+	_, err = DeserializeUpdatePlan(&apitype.VersionedUpdatePlan{
+		Version: apitype.UpdatePlanSchemaVersionCurrent,
+		Plan:    json.RawMessage(`{"resources":{"urn:pulumi:dev::proj::test:index:Resource::a":{"ops":["explode"]}}}`),
+	})
+	assert.Error(t, err)
+}
+
+func TestUpdatePlanNotes(t *testing.T) {
+	urn := resource.NewURN("dev", "proj", "", "test:index:Resource", "a")
+	a := &resource.State{URN: urn, Type: urn.Type()}
+
+	plan := NewUpdatePlan()
+	plan.record(deploy.NewUpdateStep(nil, nil, a, a, nil, nil, nil, nil))
+
+	// Notes need a message, and can only be attached to resources in the plan.
+	assert.Error(t, plan.AddNote(urn, PlanNote{Author: "alice"}))
+	other := resource.NewURN("dev", "proj", "", "test:index:Resource", "b")
+	assert.Error(t, plan.AddNote(other, PlanNote{Message: "hello"}))
+	assert.NoError(t, plan.AddNote(urn, PlanNote{Author: "alice", Message: "check the new size"}))
+	assert.NoError(t, plan.AddNote(urn, PlanNote{Message: "approved"}))
+
+	// Saving and loading the plan preserves its notes.
+	versioned, err := SerializeUpdatePlan(plan)
+	if !assert.NoError(t, err) {
+		return
+	}
+	loaded, err := DeserializeUpdatePlan(versioned)
+	if !assert.NoError(t, err) {
+		return
+	}
+	notes := loaded.Notes(urn)
+	assert.Equal(t, []PlanNote{{Author: "alice", Message: "check the new size"}, {Message: "approved"}}, notes)
+
+	// The notes are reported against the resource when its changes are made.
+	events := make(chan Event, 2)
+	loaded.reportNotes(deploy.NewUpdateStep(nil, nil, a, a, nil, nil, nil, nil), eventEmitter{Chan: events})
+	close(events)
+	var messages []string
+	for e := range events {
+		payload := e.Payload.(DiagEventPayload)
+		assert.Equal(t, urn, payload.URN)
+		messages = append(messages, payload.Message)
+	}
+	assert.Equal(t, []string{"note from alice: check the new size\n", "note: approved\n"}, messages)
+}
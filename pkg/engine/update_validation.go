@@ -0,0 +1,49 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/util/result"
+)
+
+// validateUpdate checks the changes recorded by the saved plan an update is constrained to against the stack's
+// guardrails and locked resources, before any of them is made. Each violation is reported as an error. An update to a
+// stack with guardrails must be constrained to a plan, such as the one recorded by its preview, so that no resource is
+// deleted or replaced before a violation is found; refreshes and imports, which do neither, are exempt. Each step is
+// also checked again as it is about to be applied.
+func validateUpdate(info *planContext, opts planOptions) result.Result {
+	target := info.Update.GetTarget()
+	guardrails := newGuardrailChecker(target.Guardrails, opts.OverrideGuardrails)
+	if opts.Plan == nil {
+		if guardrails != nil && !opts.isRefresh && len(opts.Imports) == 0 {
+			opts.Diag.Errorf(diag.GetUpdateRefusedError(""), errors.New("the stack has guardrails, so the update "+
+				"must be previewed or constrained to a saved plan; rerun with --override-guardrails to proceed anyway"))
+			return result.Bail()
+		}
+		return nil
+	}
+
+	errs := append(guardrails.verify(opts.Plan), opts.Plan.verifyLocks(target.Snapshot)...)
+	for _, err := range errs {
+		opts.Diag.Errorf(diag.GetUpdateRefusedError(""), err)
+	}
+	if len(errs) > 0 {
+		return result.Bail()
+	}
+	return nil
+}
@@ -16,6 +16,8 @@ package operations
 
 import (
 	"time"
+
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
 )
 
 // LogEntry is a row in the logs for a running compute service
@@ -45,6 +47,10 @@ type LogQuery struct {
 	EndTime *time.Time `url:"endTime,unix"`
 	// ResourceFilter is a string indicating that logs should be limited to a resource or resources
 	ResourceFilter *ResourceFilter `url:"resourceFilter"`
+	// Host is an optional plugin host from which to load the provider plugins that supply resources' logs. Callers
+	// that query logs repeatedly, such as when following them, should share a host between queries so that each plugin
+	// is loaded only once. If nil, a host is created for the query and closed when it completes.
+	Host plugin.Host `url:"-"`
 }
 
 // Provider is the interface for making operational requests about the
@@ -0,0 +1,144 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+)
+
+// ProviderSource supplies the provider plugins that manage a stack's resources.
+type ProviderSource interface {
+	// Provider returns the configured provider plugin that manages the given resource, or nil if it has none.
+	Provider(state *resource.State) (plugin.Provider, error)
+}
+
+// NewProviderSource returns a ProviderSource for the given resources, which loads and configures each of their
+// providers using the given host the first time it is needed. The providers are owned by the host, and are closed
+// with it.
+func NewProviderSource(host plugin.Host, resources []*resource.State) ProviderSource {
+	states := make(map[resource.URN]*resource.State)
+	for _, state := range resources {
+		if providers.IsProviderType(state.Type) {
+			states[state.URN] = state
+		}
+	}
+	return &providerSource{
+		host:   host,
+		states: states,
+		loaded: make(map[providers.Reference]plugin.Provider),
+	}
+}
+
+type providerSource struct {
+	host   plugin.Host
+	states map[resource.URN]*resource.State // the provider resources, by URN.
+
+	lock   sync.Mutex
+	loaded map[providers.Reference]plugin.Provider
+}
+
+func (s *providerSource) Provider(state *resource.State) (plugin.Provider, error) {
+	if !state.Custom || state.Provider == "" {
+		return nil, nil
+	}
+	ref, err := providers.ParseReference(state.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if prov, ok := s.loaded[ref]; ok {
+		return prov, nil
+	}
+
+	provState, ok := s.states[ref.URN()]
+	if !ok {
+		return nil, errors.Errorf("unknown provider '%v' for resource '%v'", ref, state.URN)
+	}
+	pkg := providers.GetProviderPackage(provState.Type)
+	version, err := providers.GetProviderVersion(provState.Inputs)
+	if err != nil {
+		return nil, errors.Errorf("could not parse version for %v provider '%v': %v", pkg, provState.URN, err)
+	}
+	prov, err := s.host.Provider(pkg, version)
+	if err != nil {
+		return nil, errors.Errorf("could not load plugin for %v provider '%v': %v", pkg, provState.URN, err)
+	}
+	if prov == nil {
+		return nil, errors.Errorf("could not find plugin for %v provider '%v' at version %v", pkg, provState.URN, version)
+	}
+	if err = prov.Configure(provState.Inputs); err != nil {
+		return nil, errors.Errorf("could not configure provider '%v': %v", provState.URN, err)
+	}
+
+	s.loaded[ref] = prov
+	return prov, nil
+}
+
+// PluginOperationsProvider creates an OperationsProvider that answers operational queries about a resource by asking
+// the provider plugin that manages it. If the plugin cannot be loaded, the resource is treated as having no logs.
+func PluginOperationsProvider(source ProviderSource, component *Resource) (Provider, error) {
+	prov, err := source.Provider(component.State)
+	if err != nil {
+		// Not every provider of a stack is needed to query its logs, so a missing or misconfigured plugin is not
+		// fatal; it just means that its resources' logs are unavailable.
+		logging.V(5).Infof("not querying logs of %v: %v", component.State.URN, err)
+		return nil, nil
+	}
+	if prov == nil {
+		return nil, nil
+	}
+	return &pluginOpsProvider{provider: prov, component: component}, nil
+}
+
+type pluginOpsProvider struct {
+	provider  plugin.Provider
+	component *Resource
+}
+
+var _ Provider = (*pluginOpsProvider)(nil)
+
+func (ops *pluginOpsProvider) GetLogs(query LogQuery) (*[]LogEntry, error) {
+	state := ops.component.State
+	logging.V(6).Infof("GetLogs[%v]", state.URN)
+
+	entries, err := ops.provider.GetLogs(plugin.LogQuery{
+		Resources: []plugin.LogResource{{URN: state.URN, ID: state.ID, Outputs: state.Outputs}},
+		StartTime: query.StartTime,
+		EndTime:   query.EndTime,
+	})
+	if err != nil || entries == nil {
+		return nil, err
+	}
+
+	logs := make([]LogEntry, 0, len(*entries))
+	for _, entry := range *entries {
+		id := entry.ID
+		if id == "" {
+			id = string(state.URN.Name())
+		}
+		logs = append(logs, LogEntry{ID: id, Timestamp: entry.Timestamp, Message: entry.Message})
+	}
+	return &logs, nil
+}
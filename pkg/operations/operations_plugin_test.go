@@ -0,0 +1,121 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/deploytest"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+func TestPluginLogs(t *testing.T) {
+	urn := func(parentType, typ tokens.Type, name tokens.QName) resource.URN {
+		return resource.NewURN("stack", "proj", parentType, typ, name)
+	}
+	newProvider := func(pkg tokens.Package) (*resource.State, string) {
+		state := &resource.State{
+			Type:   providers.MakeProviderType(pkg),
+			URN:    urn("", providers.MakeProviderType(pkg), "default"),
+			Custom: true,
+			ID:     "id",
+		}
+		ref, err := providers.NewReference(state.URN, state.ID)
+		assert.NoError(t, err)
+		return state, ref.String()
+	}
+	testProv, testRef := newProvider("test")
+	nologsProv, nologsRef := newProvider("nologs")
+	missingProv, missingRef := newProvider("missing")
+
+	comp := &resource.State{Type: "my:index:Comp", URN: urn("", "my:index:Comp", "app")}
+	newResource := func(typ tokens.Type, name tokens.QName, provider string) *resource.State {
+		return &resource.State{
+			Type:     typ,
+			URN:      urn(comp.Type, typ, name),
+			Custom:   true,
+			ID:       resource.ID(name + "-id"),
+			Outputs:  resource.PropertyMap{"name": resource.NewStringProperty(string(name))},
+			Parent:   comp.URN,
+			Provider: provider,
+		}
+	}
+	resources := []*resource.State{
+		testProv, nologsProv, missingProv, comp,
+		newResource("test:index:Fn", "fn", testRef),
+		newResource("test:index:Fn", "fn2", testRef),
+		newResource("nologs:index:Thing", "thing", nologsRef),
+		newResource("missing:index:Thing", "other", missingRef),
+	}
+
+	start := time.Unix(100, 0)
+	var loads int
+	host := deploytest.NewPluginHost(
+		diag.DefaultSink(ioutil.Discard, ioutil.Discard, diag.FormatOptions{Color: colors.Never}), nil, nil,
+		deploytest.NewProviderLoader("test", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			loads++
+			return &deploytest.Provider{
+				GetLogsF: func(query plugin.LogQuery) (*[]plugin.LogEntry, error) {
+					assert.Equal(t, &start, query.StartTime)
+					if !assert.Len(t, query.Resources, 1) {
+						return nil, nil
+					}
+					res := query.Resources[0]
+					assert.Equal(t, res.URN.Name(), tokens.QName(res.Outputs["name"].StringValue()))
+					if res.URN.Name() == "fn" {
+						return &[]plugin.LogEntry{
+							{URN: res.URN, Timestamp: 2000, Message: "second"},
+							{URN: res.URN, ID: "worker", Timestamp: 1000, Message: "first"},
+						}, nil
+					}
+					return &[]plugin.LogEntry{{URN: res.URN, Timestamp: 3000, Message: "third"}}, nil
+				},
+			}, nil
+		}),
+		deploytest.NewProviderLoader("nologs", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}))
+
+	// Logs from the resources of every provider that supports them are merged in order; resources whose providers do
+	// not support logs, or cannot be loaded, have none.
+	tree := NewResourceTree(resources)
+	logs, err := tree.PluginOperationsProvider(nil, NewProviderSource(host, resources)).GetLogs(LogQuery{
+		StartTime: &start,
+	})
+	assert.NoError(t, err)
+	if assert.NotNil(t, logs) {
+		assert.Equal(t, []LogEntry{
+			{ID: "worker", Timestamp: 1000, Message: "first"},
+			{ID: "fn", Timestamp: 2000, Message: "second"},
+			{ID: "fn2", Timestamp: 3000, Message: "third"},
+		}, *logs)
+	}
+	assert.Equal(t, 1, loads)
+
+	// Without a provider source, there are no logs to be had.
+	logs, err = tree.OperationsProvider(nil).GetLogs(LogQuery{StartTime: &start})
+	assert.NoError(t, err)
+	assert.Empty(t, *logs)
+}
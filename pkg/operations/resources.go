@@ -120,10 +120,22 @@ func (r *Resource) OperationsProvider(config map[config.Key]string) Provider {
 	}
 }
 
+// PluginOperationsProvider gets an OperationsProvider for this resource that, in addition to the operations built in
+// for the `cloud`, `aws`, and `gcp` packages, answers queries about the resources of other packages by asking the
+// provider plugins that manage them.
+func (r *Resource) PluginOperationsProvider(config map[config.Key]string, providers ProviderSource) Provider {
+	return &resourceOperations{
+		resource:  r,
+		config:    config,
+		providers: providers,
+	}
+}
+
 // ResourceOperations is an OperationsProvider for Resources
 type resourceOperations struct {
-	resource *Resource
-	config   map[config.Key]string
+	resource  *Resource
+	config    map[config.Key]string
+	providers ProviderSource
 }
 
 var _ Provider = (*resourceOperations)(nil)
@@ -168,8 +180,9 @@ func (ops *resourceOperations) GetLogs(query LogQuery) (*[]LogEntry, error) {
 	errch := make(chan error)
 	for _, child := range ops.resource.Children {
 		childOps := &resourceOperations{
-			resource: child,
-			config:   ops.config,
+			resource:  child,
+			config:    ops.config,
+			providers: ops.providers,
 		}
 		go func() {
 			childLogs, err := childOps.GetLogs(query)
@@ -257,6 +270,9 @@ func (ops *resourceOperations) getOperationsProvider() (Provider, error) {
 	case "gcp":
 		return GCPOperationsProvider(ops.config, ops.resource)
 	default:
-		return nil, nil
+		if ops.providers == nil {
+			return nil, nil
+		}
+		return PluginOperationsProvider(ops.providers, ops.resource)
 	}
 }
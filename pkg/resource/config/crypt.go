@@ -44,6 +44,30 @@ type Crypter interface {
 	Decrypter
 }
 
+// ValueLocation identifies where an encrypted value is stored: a configuration key, or a property of a resource in a
+// stack's state.
+type ValueLocation struct {
+	ConfigKey string `json:"configKey,omitempty"` // the configuration key holding the value, if any.
+	Resource  string `json:"resource,omitempty"`  // the URN of the resource holding the value, if any.
+	Property  string `json:"property,omitempty"`  // the path of the resource property holding the value, if any.
+}
+
+// LocatingEncrypter is an Encrypter that is told where each value it encrypts is stored.
+type LocatingEncrypter interface {
+	Encrypter
+	// EncryptValueAt encrypts a value stored at the given location.
+	EncryptValueAt(plaintext string, loc ValueLocation) (string, error)
+}
+
+// EncryptValueAt encrypts the given value, which is stored at the given location, with the given encrypter. Only
+// encrypters that implement LocatingEncrypter are told the location.
+func EncryptValueAt(enc Encrypter, plaintext string, loc ValueLocation) (string, error) {
+	if l, ok := enc.(LocatingEncrypter); ok {
+		return l.EncryptValueAt(plaintext, loc)
+	}
+	return enc.EncryptValue(plaintext)
+}
+
 // A nopCrypter simply returns the ciphertext as-is.
 type nopCrypter struct{}
 
@@ -0,0 +1,49 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// Layer is a named source of configuration values, such as a configuration file.
+type Layer struct {
+	// Name describes where the layer's values come from, e.g. the file they were read from.
+	Name string
+	// Config holds the layer's values.
+	Config Map
+}
+
+// Layers are sources of configuration in order of increasing precedence: a value in one layer overrides the values of
+// the same key in all of the layers before it.
+type Layers []Layer
+
+// Merge returns the effective configuration: for each key, the value from the last layer that sets it.
+func (ls Layers) Merge() Map {
+	result := make(Map)
+	for _, l := range ls {
+		for k, v := range l.Config {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// Source returns the name of the layer that the effective value of the given key comes from, and false if no layer
+// sets the key.
+func (ls Layers) Source(k Key) (string, bool) {
+	for i := len(ls) - 1; i >= 0; i-- {
+		if _, ok := ls[i].Config[k]; ok {
+			return ls[i].Name, true
+		}
+	}
+	return "", false
+}
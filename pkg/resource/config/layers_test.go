@@ -0,0 +1,41 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLayers(t *testing.T) {
+	a, b, c := MustMakeKey("proj", "a"), MustMakeKey("proj", "b"), MustMakeKey("proj", "c")
+	layers := Layers{
+		{Name: "defaults", Config: Map{a: NewValue("default-a"), b: NewValue("default-b")}},
+		{Name: "env", Config: Map{b: NewValue("env-b")}},
+		{Name: "stack", Config: Map{a: NewValue("stack-a")}},
+	}
+
+	assert.Equal(t, Map{a: NewValue("stack-a"), b: NewValue("env-b")}, layers.Merge())
+
+	source, ok := layers.Source(a)
+	assert.True(t, ok)
+	assert.Equal(t, "stack", source)
+	source, ok = layers.Source(b)
+	assert.True(t, ok)
+	assert.Equal(t, "env", source)
+	_, ok = layers.Source(c)
+	assert.False(t, ok)
+}
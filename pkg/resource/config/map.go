@@ -16,6 +16,7 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/pkg/errors"
 )
@@ -47,6 +48,204 @@ func (m Map) HasSecureValue() bool {
 	return false
 }
 
+// Get returns the value at the given path within the value of the given key. The path's elements are map keys
+// (strings) and list indices (ints); an empty path refers to the value of the key itself. Values within objects are
+// returned as strings, secrets, or nested objects.
+func (m Map) Get(k Key, path []interface{}) (Value, bool, error) {
+	v, has := m[k]
+	if !has || len(path) == 0 {
+		return v, has, nil
+	}
+	if !v.Object() {
+		return Value{}, false, errors.Errorf("%s is not a map or list", k)
+	}
+
+	obj, err := v.ToObject()
+	if err != nil {
+		return Value{}, false, err
+	}
+	for _, elem := range path {
+		switch elem := elem.(type) {
+		case string:
+			fields, ok := obj.(map[string]interface{})
+			if !ok {
+				return Value{}, false, nil
+			}
+			if obj, ok = fields[elem]; !ok {
+				return Value{}, false, nil
+			}
+		case int:
+			a, ok := obj.([]interface{})
+			if !ok || elem < 0 || elem >= len(a) {
+				return Value{}, false, nil
+			}
+			obj = a[elem]
+		default:
+			return Value{}, false, errors.Errorf("invalid path element %v", elem)
+		}
+	}
+
+	switch obj := obj.(type) {
+	case string:
+		return NewValue(obj), true, nil
+	case nil:
+		return NewValue(""), true, nil
+	case map[string]interface{}:
+		if ciphertext, ok := secureLeaf(obj); ok {
+			return NewSecureValue(ciphertext), true, nil
+		}
+	case []interface{}:
+	default:
+		return NewValue(fmt.Sprintf("%v", obj)), true, nil
+	}
+	v, err = newObjectValue(obj)
+	return v, err == nil, err
+}
+
+// Set sets the value at the given path within the value of the given key, creating the maps and lists along the path
+// that do not exist yet. An index may refer to an existing element of a list, or to the end of the list to append to
+// it. An empty path sets the value of the key itself.
+func (m Map) Set(k Key, path []interface{}, v Value) error {
+	if len(path) == 0 {
+		m[k] = v
+		return nil
+	}
+
+	var root interface{}
+	if existing, has := m[k]; has {
+		if !existing.Object() {
+			return errors.Errorf("%s is not a map or list", k)
+		}
+		obj, err := existing.ToObject()
+		if err != nil {
+			return err
+		}
+		root = obj
+	}
+
+	var leaf interface{}
+	switch {
+	case v.Object():
+		obj, err := v.ToObject()
+		if err != nil {
+			return err
+		}
+		leaf = obj
+	case v.Secure():
+		leaf = map[string]interface{}{"secure": v.value}
+	default:
+		leaf = v.value
+	}
+
+	root, err := setPath(root, path, leaf)
+	if err != nil {
+		return errors.Wrapf(err, "setting %s", k)
+	}
+	newV, err := newObjectValue(root)
+	if err != nil {
+		return err
+	}
+	m[k] = newV
+	return nil
+}
+
+func setPath(obj interface{}, path []interface{}, leaf interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return leaf, nil
+	}
+
+	switch elem := path[0].(type) {
+	case string:
+		m, ok := obj.(map[string]interface{})
+		if obj == nil {
+			m = make(map[string]interface{})
+		} else if !ok {
+			return nil, errors.Errorf("cannot set key %q of a value that is not a map", elem)
+		}
+		child, err := setPath(m[elem], path[1:], leaf)
+		if err != nil {
+			return nil, err
+		}
+		m[elem] = child
+		return m, nil
+	case int:
+		a, ok := obj.([]interface{})
+		if obj != nil && !ok {
+			return nil, errors.Errorf("cannot set index %d of a value that is not a list", elem)
+		}
+		switch {
+		case elem >= 0 && elem < len(a):
+		case elem == len(a):
+			a = append(a, nil)
+		default:
+			return nil, errors.Errorf("index %d is out of range for a list of length %d", elem, len(a))
+		}
+		child, err := setPath(a[elem], path[1:], leaf)
+		if err != nil {
+			return nil, err
+		}
+		a[elem] = child
+		return a, nil
+	default:
+		return nil, errors.Errorf("invalid path element %v", elem)
+	}
+}
+
+// Remove removes the value at the given path within the value of the given key; an empty path removes the key
+// itself. Removing an element of a list shifts the elements after it. Removing a value that does not exist is not an
+// error.
+func (m Map) Remove(k Key, path []interface{}) error {
+	v, has := m[k]
+	if !has || len(path) == 0 {
+		delete(m, k)
+		return nil
+	}
+	if !v.Object() {
+		return errors.Errorf("%s is not a map or list", k)
+	}
+
+	obj, err := v.ToObject()
+	if err != nil {
+		return err
+	}
+	newV, err := newObjectValue(removePath(obj, path))
+	if err != nil {
+		return err
+	}
+	m[k] = newV
+	return nil
+}
+
+func removePath(obj interface{}, path []interface{}) interface{} {
+	switch elem := path[0].(type) {
+	case string:
+		m, ok := obj.(map[string]interface{})
+		if !ok {
+			return obj
+		}
+		if child, has := m[elem]; has {
+			if len(path) == 1 {
+				delete(m, elem)
+			} else {
+				m[elem] = removePath(child, path[1:])
+			}
+		}
+		return m
+	case int:
+		a, ok := obj.([]interface{})
+		if !ok || elem < 0 || elem >= len(a) {
+			return obj
+		}
+		if len(path) == 1 {
+			return append(a[:elem], a[elem+1:]...)
+		}
+		a[elem] = removePath(a[elem], path[1:])
+		return a
+	default:
+		return obj
+	}
+}
+
 func (m Map) MarshalJSON() ([]byte, error) {
 	rawMap := make(map[string]Value, len(m))
 	for k, v := range m {
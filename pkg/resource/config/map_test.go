@@ -78,3 +78,52 @@ func roundtripMap(m Map, marshal func(v interface{}) ([]byte, error),
 	err = unmarshal(b, &newM)
 	return newM, err
 }
+
+func TestMapPaths(t *testing.T) {
+	vpc, region := MustMakeKey("proj", "vpc"), MustMakeKey("proj", "region")
+	m := Map{region: NewValue("us-west-2")}
+
+	// Setting a path creates the maps and lists along it, and indices may append to lists.
+	assert.NoError(t, m.Set(vpc, []interface{}{"subnets", 0}, NewValue("10.0.0.0/24")))
+	assert.NoError(t, m.Set(vpc, []interface{}{"subnets", 1}, NewValue("10.0.1.0/24")))
+	assert.NoError(t, m.Set(vpc, []interface{}{"auth", "token"}, NewSecureValue("ciphertext")))
+	assert.Equal(t, NewObjectValue(`{"auth":{"token":{"secure":"ciphertext"}},"subnets":["10.0.0.0/24","10.0.1.0/24"]}`),
+		m[vpc])
+	assert.Error(t, m.Set(vpc, []interface{}{"subnets", 3}, NewValue("10.0.3.0/24")))
+	assert.Error(t, m.Set(vpc, []interface{}{"subnets", "first"}, NewValue("10.0.3.0/24")))
+	assert.Error(t, m.Set(region, []interface{}{"name"}, NewValue("us-west-2")))
+
+	v, ok, err := m.Get(vpc, []interface{}{"subnets", 1})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, NewValue("10.0.1.0/24"), v)
+
+	v, ok, err = m.Get(vpc, []interface{}{"auth", "token"})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, NewSecureValue("ciphertext"), v)
+
+	v, ok, err = m.Get(vpc, []interface{}{"subnets"})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, NewObjectValue(`["10.0.0.0/24","10.0.1.0/24"]`), v)
+
+	_, ok, err = m.Get(vpc, []interface{}{"subnets", 2})
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	v, ok, err = m.Get(region, nil)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, NewValue("us-west-2"), v)
+
+	// Removing an element of a list shifts the rest, and removing values that don't exist is not an error.
+	assert.NoError(t, m.Remove(vpc, []interface{}{"subnets", 0}))
+	assert.NoError(t, m.Remove(vpc, []interface{}{"auth", "missing", "nested"}))
+	assert.NoError(t, m.Remove(vpc, []interface{}{"auth"}))
+	assert.Equal(t, NewObjectValue(`{"subnets":["10.0.1.0/24"]}`), m[vpc])
+
+	assert.NoError(t, m.Remove(vpc, nil))
+	_, has := m[vpc]
+	assert.False(t, has)
+}
@@ -15,14 +15,18 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 )
 
-// Value is a single config value.
+// Value is a single config value. A value is either a string, which may be encrypted, or an object: a structured value
+// of nested maps and lists whose string leaves may themselves be encrypted.
 type Value struct {
 	value  string
 	secure bool
+	object bool
 }
 
 func NewSecureValue(v string) Value {
@@ -33,9 +37,40 @@ func NewValue(v string) Value {
 	return Value{value: v, secure: false}
 }
 
+// NewObjectValue returns a structured value from its JSON representation, in which each encrypted string is
+// represented by an object of the form {"secure": <ciphertext>}.
+func NewObjectValue(v string) Value {
+	return Value{value: v, object: true}
+}
+
 // Value fetches the value of this configuration entry, using decrypter to decrypt if necessary.  If the value
-// is a secret and decrypter is nil, or if decryption fails for any reason, a non-nil error is returned.
+// is a secret and decrypter is nil, or if decryption fails for any reason, a non-nil error is returned. The value of
+// an object is its JSON representation, with each of its secrets decrypted.
 func (c Value) Value(decrypter Decrypter) (string, error) {
+	if c.object {
+		if !c.Secure() {
+			return c.value, nil
+		}
+		obj, err := c.ToObject()
+		if err != nil {
+			return "", err
+		}
+		decrypted, err := mapSecureLeaves(obj, func(ciphertext string) (interface{}, error) {
+			if decrypter == nil {
+				return nil, errors.New("non-nil decrypter required for secret")
+			}
+			return decrypter.DecryptValue(ciphertext)
+		})
+		if err != nil {
+			return "", err
+		}
+		b, err := json.Marshal(decrypted)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+
 	if !c.secure {
 		return c.value, nil
 	}
@@ -46,11 +81,80 @@ func (c Value) Value(decrypter Decrypter) (string, error) {
 	return decrypter.DecryptValue(c.value)
 }
 
+// Secure returns true if the value is encrypted, or is an object that contains encrypted values.
 func (c Value) Secure() bool {
+	if c.object {
+		obj, err := c.ToObject()
+		return err == nil && hasSecureLeaf(obj)
+	}
 	return c.secure
 }
 
+// Object returns true if the value is a structured value rather than a string.
+func (c Value) Object() bool {
+	return c.object
+}
+
+// ToObject returns the structured value of an object, as maps and lists, in which each encrypted string is
+// represented by a map of the form {"secure": <ciphertext>}. The value of a string is the string itself.
+func (c Value) ToObject() (interface{}, error) {
+	if !c.object {
+		return c.value, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader([]byte(c.value)))
+	dec.UseNumber()
+	var obj interface{}
+	if err := dec.Decode(&obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// Copy returns a copy of the value in which each secret has been decrypted by decrypter and re-encrypted by encrypter.
+func (c Value) Copy(decrypter Decrypter, encrypter Encrypter) (Value, error) {
+	if c.object {
+		if !c.Secure() {
+			return c, nil
+		}
+		obj, err := c.ToObject()
+		if err != nil {
+			return Value{}, err
+		}
+		copied, err := mapSecureLeaves(obj, func(ciphertext string) (interface{}, error) {
+			plaintext, err := decrypter.DecryptValue(ciphertext)
+			if err != nil {
+				return nil, err
+			}
+			ciphertext, err = encrypter.EncryptValue(plaintext)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"secure": ciphertext}, nil
+		})
+		if err != nil {
+			return Value{}, err
+		}
+		return newObjectValue(copied)
+	}
+
+	if !c.secure {
+		return c, nil
+	}
+	plaintext, err := decrypter.DecryptValue(c.value)
+	if err != nil {
+		return Value{}, err
+	}
+	ciphertext, err := encrypter.EncryptValue(plaintext)
+	if err != nil {
+		return Value{}, err
+	}
+	return NewSecureValue(ciphertext), nil
+}
+
 func (c Value) MarshalJSON() ([]byte, error) {
+	if c.object {
+		return []byte(c.value), nil
+	}
 	if !c.secure {
 		return json.Marshal(c.value)
 	}
@@ -62,27 +166,34 @@ func (c Value) MarshalJSON() ([]byte, error) {
 }
 
 func (c *Value) UnmarshalJSON(b []byte) error {
-	var m map[string]string
-	err := json.Unmarshal(b, &m)
-	if err == nil {
-		if len(m) != 1 {
-			return errors.New("malformed secure data")
-		}
+	var raw interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
 
-		val, has := m["secure"]
-		if !has {
-			return errors.New("malformed secure data")
+	switch raw := raw.(type) {
+	case map[string]interface{}:
+		if ciphertext, ok := secureLeaf(raw); ok {
+			*c = NewSecureValue(ciphertext)
+			return nil
 		}
-
-		c.value = val
-		c.secure = true
-		return nil
+	case []interface{}:
+	default:
+		return json.Unmarshal(b, &c.value)
 	}
 
-	return json.Unmarshal(b, &c.value)
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, b); err != nil {
+		return err
+	}
+	*c = NewObjectValue(buf.String())
+	return nil
 }
 
 func (c Value) MarshalYAML() (interface{}, error) {
+	if c.object {
+		return c.ToObject()
+	}
 	if !c.secure {
 		return c.value, nil
 	}
@@ -94,23 +205,122 @@ func (c Value) MarshalYAML() (interface{}, error) {
 }
 
 func (c *Value) UnmarshalYAML(unmarshal func(interface{}) error) error {
-	var m map[string]string
-	err := unmarshal(&m)
-	if err == nil {
-		if len(m) != 1 {
-			return errors.New("malformed secure data")
-		}
+	var raw interface{}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
 
-		val, has := m["secure"]
-		if !has {
-			return errors.New("malformed secure data")
+	switch raw.(type) {
+	case map[interface{}]interface{}, []interface{}:
+		obj := yamlToJSONObject(raw)
+		if m, ok := obj.(map[string]interface{}); ok {
+			if ciphertext, ok := secureLeaf(m); ok {
+				*c = NewSecureValue(ciphertext)
+				return nil
+			}
 		}
-
-		c.value = val
-		c.secure = true
+		v, err := newObjectValue(obj)
+		if err != nil {
+			return err
+		}
+		*c = v
 		return nil
 	}
 
-	c.secure = false
+	c.secure, c.object = false, false
 	return unmarshal(&c.value)
 }
+
+// newObjectValue returns a structured value from its maps and lists.
+func newObjectValue(obj interface{}) (Value, error) {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return Value{}, err
+	}
+	return NewObjectValue(string(b)), nil
+}
+
+// secureLeaf returns the ciphertext of an encrypted string within an object, which is represented by a map of the
+// form {"secure": <ciphertext>}.
+func secureLeaf(m map[string]interface{}) (string, bool) {
+	if len(m) != 1 {
+		return "", false
+	}
+	ciphertext, ok := m["secure"].(string)
+	return ciphertext, ok
+}
+
+// hasSecureLeaf returns true if the given structured value contains an encrypted string.
+func hasSecureLeaf(obj interface{}) bool {
+	switch obj := obj.(type) {
+	case map[string]interface{}:
+		if _, ok := secureLeaf(obj); ok {
+			return true
+		}
+		for _, v := range obj {
+			if hasSecureLeaf(v) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, v := range obj {
+			if hasSecureLeaf(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mapSecureLeaves returns a copy of the given structured value in which each encrypted string has been replaced by
+// the result of f applied to its ciphertext.
+func mapSecureLeaves(obj interface{}, f func(ciphertext string) (interface{}, error)) (interface{}, error) {
+	switch obj := obj.(type) {
+	case map[string]interface{}:
+		if ciphertext, ok := secureLeaf(obj); ok {
+			return f(ciphertext)
+		}
+		m := make(map[string]interface{}, len(obj))
+		for k, v := range obj {
+			mapped, err := mapSecureLeaves(v, f)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = mapped
+		}
+		return m, nil
+	case []interface{}:
+		a := make([]interface{}, len(obj))
+		for i, v := range obj {
+			mapped, err := mapSecureLeaves(v, f)
+			if err != nil {
+				return nil, err
+			}
+			a[i] = mapped
+		}
+		return a, nil
+	default:
+		return obj, nil
+	}
+}
+
+// yamlToJSONObject converts a structured value decoded from YAML, whose maps may have keys of any type, into one that
+// can be encoded as JSON.
+func yamlToJSONObject(obj interface{}) interface{} {
+	switch obj := obj.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(obj))
+		for k, v := range obj {
+			m[fmt.Sprintf("%v", k)] = yamlToJSONObject(v)
+		}
+		return m
+	case []interface{}:
+		a := make([]interface{}, len(obj))
+		for i, v := range obj {
+			a[i] = yamlToJSONObject(v)
+		}
+		return a
+	default:
+		return obj
+	}
+}
@@ -70,6 +70,68 @@ func TestMarshallSecureValueJSON(t *testing.T) {
 	assert.Equal(t, v, newV)
 }
 
+func TestMarshallObjectValue(t *testing.T) {
+	v := NewObjectValue(`{"subnets":["10.0.0.0/24",{"secure":"ciphertext"}],"zones":2}`)
+	assert.True(t, v.Object())
+	assert.True(t, v.Secure())
+
+	b, err := yaml.Marshal(v)
+	assert.NoError(t, err)
+	assert.Equal(t, "subnets:\n- 10.0.0.0/24\n- secure: ciphertext\nzones: 2\n", string(b))
+
+	newV, err := roundtripValueYAML(v)
+	assert.NoError(t, err)
+	assert.Equal(t, v, newV)
+
+	b, err = json.Marshal(v)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"subnets":["10.0.0.0/24",{"secure":"ciphertext"}],"zones":2}`, string(b))
+
+	newV, err = roundtripValueJSON(v)
+	assert.NoError(t, err)
+	assert.Equal(t, v, newV)
+
+	// Values that are objects without secrets are not secure.
+	assert.False(t, NewObjectValue(`["a","b"]`).Secure())
+}
+
+func TestObjectValueSecrets(t *testing.T) {
+	source := NewSymmetricCrypter(make([]byte, 32))
+	target := NewSymmetricCrypter([]byte("0123456789abcdef0123456789abcdef"))
+	ciphertext, err := source.EncryptValue("hunter2")
+	assert.NoError(t, err)
+
+	obj, err := newObjectValue(map[string]interface{}{
+		"user":     "admin",
+		"password": map[string]interface{}{"secure": ciphertext},
+	})
+	assert.NoError(t, err)
+
+	// The value of an object is its JSON, with its secrets decrypted.
+	plaintext, err := obj.Value(source)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"password":"hunter2","user":"admin"}`, plaintext)
+	blinded, err := obj.Value(NewBlindingDecrypter())
+	assert.NoError(t, err)
+	assert.Equal(t, `{"password":"[secret]","user":"admin"}`, blinded)
+	_, err = obj.Value(nil)
+	assert.Error(t, err)
+
+	// Copying an object re-encrypts its secrets and leaves the rest alone.
+	copied, err := obj.Copy(source, target)
+	assert.NoError(t, err)
+	assert.True(t, copied.Object())
+	plaintext, err = copied.Value(target)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"password":"hunter2","user":"admin"}`, plaintext)
+
+	secret, err := NewSecureValue(ciphertext).Copy(source, target)
+	assert.NoError(t, err)
+	plaintext, err = secret.Value(target)
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter2", plaintext)
+}
+
 func roundtripValueYAML(v Value) (Value, error) {
 	return roundtripValue(v, yaml.Marshal, yaml.Unmarshal)
 }
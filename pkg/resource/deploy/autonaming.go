@@ -0,0 +1,199 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// defaultAutoNameSuffixLength matches the suffix length used by most providers' own auto-naming.
+const defaultAutoNameSuffixLength = 7
+
+// autoNamer fills in the physical names of custom resources according to the project's auto-naming configuration.
+type autoNamer struct {
+	cfg     *workspace.AutoNamingConfig
+	target  *Target
+	load    schemaLoader
+	schemas map[tokens.Package]*workspace.PackageSchema
+	warned  map[tokens.Type]bool // the types for which undecorated names have been warned about.
+}
+
+// newAutoNamer returns an auto-namer for the given configuration and target. load loads the schemas that say which
+// types have a name property; it may be nil, in which case only types with their own strategy are auto-named.
+func newAutoNamer(cfg *workspace.AutoNamingConfig, target *Target, load schemaLoader) *autoNamer {
+	return &autoNamer{
+		cfg:     cfg,
+		target:  target,
+		load:    load,
+		schemas: make(map[tokens.Package]*workspace.PackageSchema),
+		warned:  make(map[tokens.Type]bool),
+	}
+}
+
+// strategyFor returns the auto-naming strategy that applies to the given resource type, if any, and whether the
+// strategy was configured for that type specifically. If the project configures no strategy but the target decorates
+// names, the default strategy applies, so that the decoration is applied to every name the engine can generate.
+func (n *autoNamer) strategyFor(urn resource.URN) (workspace.AutoNamingStrategy, bool, bool) {
+	if n.cfg != nil {
+		if s, has := n.cfg.Types[string(urn.Type())]; has {
+			return s, true, true
+		}
+		if n.cfg.Default != nil {
+			return *n.cfg.Default, false, true
+		}
+	}
+	if n.decorates() {
+		return workspace.AutoNamingStrategy{}, false, true
+	}
+	return workspace.AutoNamingStrategy{}, false, false
+}
+
+// decorates returns true if the target decorates generated names with a prefix or suffix.
+func (n *autoNamer) decorates() bool {
+	return n.target != nil && (n.target.NamePrefix != "" || n.target.NameSuffix != "")
+}
+
+// nameable returns true if the schema of the given type declares the given input property. Providers reject
+// properties their types do not declare, so the default strategy only names types whose schemas declare the property.
+func (n *autoNamer) nameable(t tokens.Type, key resource.PropertyKey) bool {
+	if n.load == nil {
+		return false
+	}
+	pkg := t.Package()
+	schema, has := n.schemas[pkg]
+	if !has {
+		var err error
+		if schema, err = n.load(pkg); err != nil {
+			logging.V(7).Infof("could not load the schema for package %s: %v", pkg, err)
+		}
+		n.schemas[pkg] = schema
+	}
+	if schema == nil {
+		return false
+	}
+	_, has = schema.Resources[string(t)].InputProperties[string(key)]
+	return has
+}
+
+// name fills in the name property of a custom resource's inputs according to the configured auto-naming strategy, if
+// the program did not specify a name itself. A strategy configured for the resource's type always applies; the
+// default strategy applies only if the schema of the type declares the name property. Generated names are decorated
+// with the target's name prefix and suffix, if any. A name generated for the same resource in a prior update is
+// reused, so that random names remain stable across updates. The input property map is not modified.
+//
+// If the target decorates names but the resource's name is left to its provider, which knows nothing of the
+// decoration, a warning to that effect is returned, once for each resource type.
+func (n *autoNamer) name(urn resource.URN, custom bool,
+	inputs, oldInputs resource.PropertyMap) (resource.PropertyMap, string, error) {
+
+	if !custom || providers.IsProviderType(urn.Type()) {
+		return inputs, "", nil
+	}
+	strategy, perType, ok := n.strategyFor(urn)
+	if !ok {
+		return inputs, "", nil
+	}
+
+	key := resource.PropertyKey(strategy.Property)
+	if key == "" {
+		key = "name"
+	}
+	if v, has := inputs[key]; has && !v.IsNull() {
+		return inputs, "", nil
+	}
+	if !perType && !n.nameable(urn.Type(), key) {
+		if !n.decorates() || n.warned[urn.Type()] {
+			return inputs, "", nil
+		}
+		n.warned[urn.Type()] = true
+		return inputs, fmt.Sprintf("the stack's name prefix and suffix are not applied to resources of type %s, "+
+			"whose provider's schema does not declare the '%s' property; list the type under `autonaming` "+
+			"to name these resources with the engine", urn.Type(), key), nil
+	}
+
+	var name resource.PropertyValue
+	if old, has := oldInputs[key]; has && old.IsString() {
+		name = old
+	} else {
+		prefix, suffix := nameDecoration(n.target, urn)
+		generated, err := generateName(strategy, prefix, suffix, urn)
+		if err != nil {
+			return nil, "", err
+		}
+		name = resource.NewStringProperty(generated)
+	}
+
+	result := make(resource.PropertyMap, len(inputs)+1)
+	for k, v := range inputs {
+		result[k] = v
+	}
+	result[key] = name
+	return result, "", nil
+}
+
+// nameDecoration returns the prefix and suffix with which the target decorates the names generated for its resources.
+// Both may refer to the stack and project names.
+func nameDecoration(target *Target, urn resource.URN) (string, string) {
+	if target == nil {
+		return "", ""
+	}
+	r := strings.NewReplacer("${stack}", string(urn.Stack()), "${project}", string(urn.Project()))
+	return r.Replace(target.NamePrefix), r.Replace(target.NameSuffix)
+}
+
+// generateName generates a physical name for the resource with the given URN, between the given prefix and suffix.
+func generateName(strategy workspace.AutoNamingStrategy, prefix, suffix string, urn resource.URN) (string, error) {
+	suffixLength := defaultAutoNameSuffixLength
+	if strategy.SuffixLength != nil {
+		suffixLength = *strategy.SuffixLength
+	}
+	delimiter := "-"
+	if strategy.Delimiter != nil {
+		delimiter = *strategy.Delimiter
+	}
+
+	name := prefix + string(urn.Name()) + suffix
+	if suffixLength > 0 {
+		var suffix string
+		if strategy.Mode == workspace.AutoNamingModeStable {
+			sum := sha256.Sum256([]byte(urn))
+			suffix = hex.EncodeToString(sum[:])[:suffixLength]
+		} else {
+			random, err := resource.NewUniqueHex("", suffixLength, suffixLength)
+			if err != nil {
+				return "", err
+			}
+			suffix = random
+		}
+		name += delimiter + suffix
+	}
+
+	switch strategy.Casing {
+	case "lower":
+		name = strings.ToLower(name)
+	case "upper":
+		name = strings.ToUpper(name)
+	}
+	return name, nil
+}
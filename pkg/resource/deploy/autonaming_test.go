@@ -0,0 +1,139 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// loadNameSchemas returns a schema loader whose schemas declare a `name` input property for the given types only.
+func loadNameSchemas(types ...string) schemaLoader {
+	return func(pkg tokens.Package) (*workspace.PackageSchema, error) {
+		schema := &workspace.PackageSchema{Name: string(pkg), Resources: map[string]workspace.ResourceSchema{}}
+		for _, t := range types {
+			schema.Resources[t] = workspace.ResourceSchema{
+				InputProperties: map[string]workspace.PropertySchema{"name": {}},
+			}
+		}
+		return schema, nil
+	}
+}
+
+func TestAutoName(t *testing.T) {
+	urn := resource.NewURN("stack", "proj", "", "aws:s3/bucket:Bucket", "Logs")
+	underscore, four := "_", 4
+	cfg := &workspace.AutoNamingConfig{
+		Default: &workspace.AutoNamingStrategy{},
+		Types: map[string]workspace.AutoNamingStrategy{
+			"aws:s3/bucket:Bucket": {
+				Property:     "bucket",
+				Mode:         workspace.AutoNamingModeStable,
+				SuffixLength: &four,
+				Delimiter:    &underscore,
+				Casing:       "lower",
+			},
+		},
+	}
+
+	namer := newAutoNamer(cfg, nil, loadNameSchemas("aws:sqs/queue:Queue"))
+
+	// Stable names are derived from the URN, so they are identical from one run to the next.
+	props, _, err := namer.name(urn, true, resource.PropertyMap{}, nil)
+	assert.NoError(t, err)
+	name := props["bucket"].StringValue()
+	assert.Regexp(t, "^logs_[0-9a-f]{4}$", name)
+	again, _, err := namer.name(urn, true, resource.PropertyMap{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, name, again["bucket"].StringValue())
+
+	// Explicit names are left alone.
+	explicit := resource.PropertyMap{"bucket": resource.NewStringProperty("mine")}
+	props, _, err = namer.name(urn, true, explicit, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "mine", props["bucket"].StringValue())
+
+	// Random names use the default strategy, and are reused from the prior inputs if present.
+	other := resource.NewURN("stack", "proj", "", "aws:sqs/queue:Queue", "q")
+	props, _, err = namer.name(other, true, resource.PropertyMap{}, nil)
+	assert.NoError(t, err)
+	assert.Regexp(t, "^q-[0-9a-f]{7}$", props["name"].StringValue())
+	olds := resource.PropertyMap{"name": resource.NewStringProperty("q-1234567")}
+	props, _, err = namer.name(other, true, resource.PropertyMap{}, olds)
+	assert.NoError(t, err)
+	assert.Equal(t, "q-1234567", props["name"].StringValue())
+
+	// The default strategy only names types whose schemas declare the name property, and none without schemas.
+	undeclared := resource.NewURN("stack", "proj", "", "aws:sns/topic:Topic", "t")
+	props, _, err = namer.name(undeclared, true, resource.PropertyMap{}, nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, props, resource.PropertyKey("name"))
+	props, _, err = newAutoNamer(cfg, nil, nil).name(other, true, resource.PropertyMap{}, nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, props, resource.PropertyKey("name"))
+
+	// Components and unconfigured projects are not auto-named.
+	props, _, err = namer.name(other, false, resource.PropertyMap{}, nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, props, resource.PropertyKey("name"))
+	props, _, err = newAutoNamer(nil, nil, loadNameSchemas("aws:sqs/queue:Queue")).name(other, true,
+		resource.PropertyMap{}, nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, props, resource.PropertyKey("name"))
+
+	// Generated names are decorated with the stack's name prefix and suffix, which leave the URN alone.
+	target := &Target{Name: "stack", NamePrefix: "${project}-", NameSuffix: "-${stack}"}
+	namer = newAutoNamer(cfg, target, loadNameSchemas("aws:sqs/queue:Queue"))
+	props, _, err = namer.name(other, true, resource.PropertyMap{}, nil)
+	assert.NoError(t, err)
+	assert.Regexp(t, "^proj-q-stack-[0-9a-f]{7}$", props["name"].StringValue())
+	props, _, err = namer.name(other, true, resource.PropertyMap{"name": resource.NewStringProperty("mine")}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "mine", props["name"].StringValue())
+}
+
+func TestAutoNamePrefixWithoutAutoNaming(t *testing.T) {
+	queue := resource.NewURN("stack", "proj", "", "aws:sqs/queue:Queue", "q")
+	topic := resource.NewURN("stack", "proj", "", "aws:sns/topic:Topic", "t")
+	target := &Target{Name: "stack", NamePrefix: "${stack}-"}
+	namer := newAutoNamer(nil, target, loadNameSchemas("aws:sqs/queue:Queue"))
+
+	// Without an `autonaming` section, a name prefix on its own still decorates names, using the default strategy.
+	props, warning, err := namer.name(queue, true, resource.PropertyMap{}, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, warning)
+	assert.Regexp(t, "^stack-q-[0-9a-f]{7}$", props["name"].StringValue())
+
+	// Types the engine cannot name are left to their providers, with a warning for the first resource of each type.
+	props, warning, err = namer.name(topic, true, resource.PropertyMap{}, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, warning, "aws:sns/topic:Topic")
+	assert.NotContains(t, props, resource.PropertyKey("name"))
+	_, warning, err = namer.name(topic, true, resource.PropertyMap{}, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, warning)
+
+	// Undecorated stacks leave naming to the providers, without warnings.
+	props, warning, err = newAutoNamer(nil, &Target{Name: "stack"}, loadNameSchemas("aws:sqs/queue:Queue")).name(
+		queue, true, resource.PropertyMap{}, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, warning)
+	assert.NotContains(t, props, resource.PropertyKey("name"))
+}
@@ -158,6 +158,11 @@ func (p *builtinProvider) Invoke(tok tokens.ModuleMember,
 	return outs, nil, nil
 }
 
+func (p *builtinProvider) GetLogs(query plugin.LogQuery) (*[]plugin.LogEntry, error) {
+	// The builtin provider's resources do not write logs.
+	return nil, nil
+}
+
 func (p *builtinProvider) GetPluginInfo() (workspace.PluginInfo, error) {
 	// return an error: this should not be called for the builtin provider
 	return workspace.PluginInfo{}, errors.New("the builtin provider does not report plugin info")
@@ -177,7 +177,10 @@ func (host *pluginHost) EnsurePlugins(plugins []workspace.PluginInfo, kinds plug
 }
 func (host *pluginHost) GetRequiredPlugins(info plugin.ProgInfo,
 	kinds plugin.Flags) ([]workspace.PluginInfo, error) {
-	return nil, nil
+	if host.languageRuntime == nil || kinds&plugin.LanguagePlugins == 0 || kinds&plugin.ResourcePlugins == 0 {
+		return nil, nil
+	}
+	return host.languageRuntime.GetRequiredPlugins(info)
 }
 
 func (host *pluginHost) PolicyAnalyzer(name tokens.QName, path string) (plugin.Analyzer, error) {
@@ -52,6 +52,7 @@ type Provider struct {
 		inputs, state resource.PropertyMap) (plugin.ReadResult, resource.Status, error)
 	InvokeF func(tok tokens.ModuleMember,
 		inputs resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error)
+	GetLogsF func(query plugin.LogQuery) (*[]plugin.LogEntry, error)
 
 	CancelF func() error
 }
@@ -155,3 +156,10 @@ func (prov *Provider) Invoke(tok tokens.ModuleMember,
 	}
 	return prov.InvokeF(tok, args)
 }
+
+func (prov *Provider) GetLogs(query plugin.LogQuery) (*[]plugin.LogEntry, error) {
+	if prov.GetLogsF == nil {
+		return nil, nil
+	}
+	return prov.GetLogsF(query)
+}
@@ -41,6 +41,8 @@ type ResourceOptions struct {
 	Aliases             []resource.URN
 	ImportID            resource.ID
 	CustomTimeouts      *resource.CustomTimeouts
+	RetainOnDelete      bool
+	Locked              bool
 }
 
 func (rm *ResourceMonitor) RegisterResource(t tokens.Type, name string, custom bool,
@@ -111,6 +113,8 @@ func (rm *ResourceMonitor) RegisterResource(t tokens.Type, name string, custom b
 		Aliases:                    aliasStrings,
 		ImportId:                   string(opts.ImportID),
 		CustomTimeouts:             &timeouts,
+		RetainOnDelete:             opts.RetainOnDelete,
+		Locked:                     opts.Locked,
 	}
 
 	// submit request
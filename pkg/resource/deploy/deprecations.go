@@ -0,0 +1,92 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// Deprecation describes a program's use of a deprecated resource type or property.
+type Deprecation struct {
+	// Item is the deprecated type token, e.g. `aws:s3/bucket:Bucket`, or property, e.g. `aws:s3/bucket:Bucket.acl`.
+	Item string
+	// Message is the provider's explanation of the deprecation, which usually names a replacement.
+	Message string
+}
+
+func (d Deprecation) String() string {
+	return fmt.Sprintf("%s is deprecated: %s", d.Item, d.Message)
+}
+
+// schemaLoader loads the schema of a package, returning nil if none is available.
+type schemaLoader func(pkg tokens.Package) (*workspace.PackageSchema, error)
+
+// deprecationChecker finds uses of deprecated resource types and properties, using the deprecation metadata in the
+// schemas installed alongside resource plugins. Packages without a schema are not checked.
+type deprecationChecker struct {
+	load    schemaLoader
+	schemas map[tokens.Package]*workspace.PackageSchema
+}
+
+func newDeprecationChecker(load schemaLoader) *deprecationChecker {
+	return &deprecationChecker{
+		load:    load,
+		schemas: make(map[tokens.Package]*workspace.PackageSchema),
+	}
+}
+
+// check returns the deprecated type and input properties used by a resource, in a stable order.
+func (c *deprecationChecker) check(t tokens.Type, inputs resource.PropertyMap) []Deprecation {
+	pkg := t.Package()
+	schema, has := c.schemas[pkg]
+	if !has {
+		var err error
+		if schema, err = c.load(pkg); err != nil {
+			// A broken schema should not prevent a deployment; it only means we can't check for deprecations.
+			logging.V(7).Infof("could not load the schema for package %s: %v", pkg, err)
+		}
+		c.schemas[pkg] = schema
+	}
+	if schema == nil {
+		return nil
+	}
+
+	res, has := schema.Resources[string(t)]
+	if !has {
+		return nil
+	}
+
+	var deprecations []Deprecation
+	if res.DeprecationMessage != "" {
+		deprecations = append(deprecations, Deprecation{Item: string(t), Message: res.DeprecationMessage})
+	}
+	var props []Deprecation
+	for k, v := range inputs {
+		if v.IsNull() {
+			continue
+		}
+		if prop, has := res.InputProperties[string(k)]; has && prop.DeprecationMessage != "" {
+			props = append(props, Deprecation{Item: fmt.Sprintf("%s.%s", t, k), Message: prop.DeprecationMessage})
+		}
+	}
+	sort.Slice(props, func(i, j int) bool { return props[i].Item < props[j].Item })
+	return append(deprecations, props...)
+}
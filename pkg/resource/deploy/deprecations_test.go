@@ -0,0 +1,71 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestDeprecationChecker(t *testing.T) {
+	loads := make(map[tokens.Package]int)
+	checker := newDeprecationChecker(func(pkg tokens.Package) (*workspace.PackageSchema, error) {
+		loads[pkg]++
+		switch pkg {
+		case "aws":
+			return &workspace.PackageSchema{Name: "aws", Resources: map[string]workspace.ResourceSchema{
+				"aws:s3/bucket:Bucket": {
+					InputProperties: map[string]workspace.PropertySchema{
+						"acl":    {Type: "string", DeprecationMessage: "Use BucketAcl instead."},
+						"region": {Type: "string", DeprecationMessage: "Set the provider's region instead."},
+						"bucket": {Type: "string"},
+					},
+				},
+				"aws:elasticsearch/domain:Domain": {DeprecationMessage: "Use aws.opensearch.Domain instead."},
+			}}, nil
+		case "broken":
+			return nil, errors.New("bad schema")
+		default:
+			return nil, nil
+		}
+	})
+
+	inputs := resource.NewPropertyMapFromMap(map[string]interface{}{
+		"acl":    "private",
+		"region": nil,
+		"bucket": "my-bucket",
+	})
+	assert.Equal(t, []Deprecation{
+		{Item: "aws:s3/bucket:Bucket.acl", Message: "Use BucketAcl instead."},
+	}, checker.check("aws:s3/bucket:Bucket", inputs))
+	assert.Equal(t, []Deprecation{
+		{Item: "aws:elasticsearch/domain:Domain", Message: "Use aws.opensearch.Domain instead."},
+	}, checker.check("aws:elasticsearch/domain:Domain", resource.PropertyMap{}))
+
+	// Unknown types, packages without schemas, and broken schemas are not checked.
+	assert.Empty(t, checker.check("aws:s3/bucketPolicy:BucketPolicy", inputs))
+	assert.Empty(t, checker.check("gcp:storage/bucket:Bucket", inputs))
+	assert.Empty(t, checker.check("broken:index:Thing", inputs))
+	assert.Empty(t, checker.check("broken:index:Thing", inputs))
+
+	// Schemas are loaded at most once per package.
+	assert.Equal(t, map[tokens.Package]int{"aws": 1, "gcp": 1, "broken": 1}, loads)
+}
@@ -0,0 +1,167 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// SnapshotJournal records successive versions of a serialized snapshot as a sequence of entries, each of which holds
+// only the resources that changed since the version before it. Appending an entry for every step of a deployment is
+// far cheaper than rewriting the whole snapshot when a stack has many resources; a persister that uses a journal
+// periodically compacts it by writing a full snapshot and starting a new journal based on it.
+//
+// The journal is independent of how snapshots are serialized: the resources of a version are opaque records, and the
+// rest of the snapshot (its manifest, pending operations, and so on) is an opaque header.
+type SnapshotJournal struct {
+	base     string   // identifies the full snapshot on which the journal is based.
+	keys     []string // the keys of the resource records of the most recently recorded version, in order.
+	index    map[string]int
+	sequence int
+}
+
+// JournalEntry is one version of a snapshot in a journal.
+type JournalEntry struct {
+	// Base identifies the full snapshot on which the journal is based. Entries that are based on a different snapshot
+	// than the one they are replayed onto, which happens if a compaction is interrupted, are ignored.
+	Base string `json:"base"`
+	// Sequence is the position of the entry in its journal, starting at one.
+	Sequence int `json:"sequence"`
+	// Header is the serialized snapshot, without its resources.
+	Header json.RawMessage `json:"header"`
+	// Resources are the resources of this version, as runs of resources of the previous version and new records.
+	Resources []JournalSpan `json:"resources,omitempty"`
+	// Records are the resource records that did not appear in the previous version, by key.
+	Records map[string]json.RawMessage `json:"records,omitempty"`
+}
+
+// JournalSpan is a run of resources in a version of a snapshot. It is either the Length resources starting at
+// position Start of the previous version or, if Key is set, the single new record with that key.
+type JournalSpan struct {
+	Start  int    `json:"start,omitempty"`
+	Length int    `json:"length,omitempty"`
+	Key    string `json:"key,omitempty"`
+}
+
+// NewSnapshotJournal creates a journal based on the full snapshot with the given identity and resource records.
+func NewSnapshotJournal(base string, resources []json.RawMessage) *SnapshotJournal {
+	j := &SnapshotJournal{base: base}
+	j.setKeys(journalKeys(resources))
+	return j
+}
+
+// Base returns the identity of the full snapshot on which the journal is based.
+func (j *SnapshotJournal) Base() string {
+	return j.base
+}
+
+// Len returns the number of entries that have been recorded in the journal.
+func (j *SnapshotJournal) Len() int {
+	return j.sequence
+}
+
+// Record returns the entry that describes a new version of the snapshot, with the given header and resource records,
+// in terms of the version before it.
+func (j *SnapshotJournal) Record(header json.RawMessage, resources []json.RawMessage) JournalEntry {
+	prev := j.keys
+	j.sequence++
+	entry := JournalEntry{Base: j.base, Sequence: j.sequence, Header: header}
+
+	keys := journalKeys(resources)
+	for i := 0; i < len(keys); {
+		start, ok := j.index[keys[i]]
+		if !ok {
+			if entry.Records == nil {
+				entry.Records = make(map[string]json.RawMessage)
+			}
+			entry.Records[keys[i]] = resources[i]
+			entry.Resources = append(entry.Resources, JournalSpan{Key: keys[i]})
+			i++
+			continue
+		}
+
+		// Extend the run for as long as the new version follows the previous one.
+		length := 1
+		for i+length < len(keys) && start+length < len(prev) && keys[i+length] == prev[start+length] {
+			length++
+		}
+		entry.Resources = append(entry.Resources, JournalSpan{Start: start, Length: length})
+		i += length
+	}
+
+	j.setKeys(keys)
+	return entry
+}
+
+// setKeys makes the resource records with the given keys the most recently recorded version.
+func (j *SnapshotJournal) setKeys(keys []string) {
+	j.keys = keys
+	j.index = make(map[string]int, len(keys))
+	for i := len(keys) - 1; i >= 0; i-- {
+		j.index[keys[i]] = i
+	}
+}
+
+// journalKeys returns the keys of the given resource records, which are digests of their contents.
+func journalKeys(resources []json.RawMessage) []string {
+	keys := make([]string, len(resources))
+	for i, res := range resources {
+		sum := sha256.Sum256(res)
+		keys[i] = hex.EncodeToString(sum[:16])
+	}
+	return keys
+}
+
+// ReplaySnapshotJournal applies the given entries, in order, to the resource records of the full snapshot with the
+// given identity, and returns the header and resource records of the last version they describe. Entries that are
+// based on a different snapshot are skipped, and replay stops at the first gap in the sequence, since the entries
+// after it cannot be applied. If no entry applies, the returned header is nil.
+func ReplaySnapshotJournal(base string, resources []json.RawMessage,
+	entries []JournalEntry) (json.RawMessage, []json.RawMessage, error) {
+	var header json.RawMessage
+	sequence := 0
+	for _, entry := range entries {
+		if entry.Base != base {
+			continue
+		}
+		if entry.Sequence != sequence+1 {
+			break
+		}
+
+		var next []json.RawMessage
+		for _, span := range entry.Resources {
+			if span.Key != "" {
+				record, ok := entry.Records[span.Key]
+				if !ok {
+					return nil, nil, errors.Errorf("journal entry %d is missing a resource record", entry.Sequence)
+				}
+				next = append(next, record)
+				continue
+			}
+			if span.Start < 0 || span.Length < 0 || span.Start+span.Length > len(resources) {
+				return nil, nil, errors.Errorf("journal entry %d refers to resources that do not exist",
+					entry.Sequence)
+			}
+			next = append(next, resources[span.Start:span.Start+span.Length]...)
+		}
+
+		header, resources, sequence = entry.Header, next, entry.Sequence
+	}
+	return header, resources, nil
+}
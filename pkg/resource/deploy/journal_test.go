@@ -0,0 +1,77 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func journalRecords(names ...string) []json.RawMessage {
+	records := make([]json.RawMessage, len(names))
+	for i, name := range names {
+		records[i] = json.RawMessage(`{"name":"` + name + `"}`)
+	}
+	return records
+}
+
+func TestSnapshotJournal(t *testing.T) {
+	base := journalRecords("a", "b", "c", "d")
+	journal := NewSnapshotJournal("base", base)
+
+	// Unchanged runs of resources are copied from the previous version; only new records are written.
+	first := journal.Record(json.RawMessage(`{"v":1}`), journalRecords("a", "b", "x", "c", "d"))
+	assert.Equal(t, 1, first.Sequence)
+	assert.Equal(t, []JournalSpan{{Start: 0, Length: 2}, {Key: first.Resources[1].Key}, {Start: 2, Length: 2}},
+		first.Resources)
+	assert.Len(t, first.Records, 1)
+
+	second := journal.Record(json.RawMessage(`{"v":2}`), journalRecords("d", "a", "b", "x"))
+	assert.Equal(t, 2, second.Sequence)
+	assert.Equal(t, []JournalSpan{{Start: 4, Length: 1}, {Start: 0, Length: 3}}, second.Resources)
+	assert.Empty(t, second.Records)
+	assert.Equal(t, 2, journal.Len())
+
+	// Entries survive a round trip through JSON.
+	var entries []JournalEntry
+	for _, entry := range []JournalEntry{first, second} {
+		byts, err := json.Marshal(entry)
+		assert.NoError(t, err)
+		var decoded JournalEntry
+		assert.NoError(t, json.Unmarshal(byts, &decoded))
+		entries = append(entries, decoded)
+	}
+
+	header, resources, err := ReplaySnapshotJournal("base", base, entries)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"v":2}`, string(header))
+	assert.Equal(t, journalRecords("d", "a", "b", "x"), resources)
+
+	// Replay stops at a gap in the sequence, and ignores entries that are based on another snapshot.
+	header, resources, err = ReplaySnapshotJournal("base", base, entries[1:])
+	assert.NoError(t, err)
+	assert.Nil(t, header)
+	assert.Equal(t, base, resources)
+
+	header, _, err = ReplaySnapshotJournal("other", base, entries)
+	assert.NoError(t, err)
+	assert.Nil(t, header)
+
+	// An entry that refers to resources that do not exist is an error.
+	_, _, err = ReplaySnapshotJournal("base", base[:1], entries)
+	assert.Error(t, err)
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
 
+	"github.com/pulumi/pulumi/pkg/apitype"
 	"github.com/pulumi/pulumi/pkg/diag"
 	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
@@ -30,6 +31,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/contract"
 	"github.com/pulumi/pulumi/pkg/util/result"
+	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
 // BackendClient provides an interface for retrieving information about other stacks.
@@ -45,6 +47,20 @@ type BackendClient interface {
 	GetStackResourceOutputs(ctx context.Context, stackName string) (resource.PropertyMap, error)
 }
 
+// StackSchemaClient may be implemented by a BackendClient whose stacks publish the schemas of their configuration and
+// outputs.
+type StackSchemaClient interface {
+	// GetStackSchema returns the schema last published by the named stack, or nil if it has not published one.
+	GetStackSchema(ctx context.Context, name string) (*apitype.StackSchema, error)
+}
+
+// StackReferenceNormalizer may be implemented by a BackendClient that accepts several names for the same stack, such
+// as names with and without the stack's organization or project.
+type StackReferenceNormalizer interface {
+	// NormalizeStackReference returns the canonical name of the named stack.
+	NormalizeStackReference(name string) (string, error)
+}
+
 // Options controls the planning and deployment process.
 type Options struct {
 	Events            Events // an optional events callback interface.
@@ -53,6 +69,20 @@ type Options struct {
 	RefreshOnly       bool   // whether or not to exit after refreshing.
 	TrustDependencies bool   // whether or not to trust the resource dependency graph.
 	UseLegacyDiff     bool   // whether or not to use legacy diffing behavior.
+
+	StrictDeprecations bool // whether or not uses of deprecated resource types and properties are errors.
+
+	UpdateTargets    []resource.URN // if non-empty, the only resources that may be created, updated, or deleted.
+	TargetDependents bool           // whether or not resources that depend on a target are also targeted.
+
+	AutoNaming *workspace.AutoNamingConfig // optional configuration for engine-generated physical names.
+
+	Throttle StepThrottle // an optional throttle that may further limit how many steps execute at once.
+
+	// CheckOnly restricts a preview to validating resources with their providers' Check and Diff methods. Invokes
+	// and reads are not sent to providers, policy packs are not run, and check failures are aggregated rather than
+	// ending the plan at the first invalid resource.
+	CheckOnly bool
 }
 
 // DegreeOfParallelism returns the degree of parallelism that should be used during the
@@ -76,15 +106,32 @@ type StepExecutorEvents interface {
 	OnResourceOutputs(step Step) error
 }
 
-// PolicyEvents is an interface that can be used to hook policy violation events.
+// StepThrottle is an interface that can be used to limit how many steps execute at once, below the degree of
+// parallelism, e.g. while the results of earlier steps are yet to be persisted.
+type StepThrottle interface {
+	// Acquire blocks until a step may begin executing. It returns an error if the context is canceled first.
+	Acquire(ctx context.Context) error
+	// Release signals that a step admitted by Acquire has finished executing.
+	Release()
+}
+
+// PolicyEvents is an interface that can be used to hook policy violation and remediation events.
 type PolicyEvents interface {
 	OnPolicyViolation(resource.URN, plugin.AnalyzeDiagnostic)
+	// OnPolicyRemediation is called when a policy remediates a resource, with its properties before and after.
+	OnPolicyRemediation(urn resource.URN, r plugin.Remediation, before, after resource.PropertyMap)
+}
+
+// DeprecationEvents is an interface that can be used to hook uses of deprecated resource types and properties.
+type DeprecationEvents interface {
+	OnDeprecation(resource.URN, Deprecation)
 }
 
 // Events is an interface that can be used to hook interesting engine/planning events.
 type Events interface {
 	StepExecutorEvents
 	PolicyEvents
+	DeprecationEvents
 }
 
 // PlanPendingOperationsError is an error returned from `NewPlan` if there exist pending operations in the
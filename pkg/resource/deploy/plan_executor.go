@@ -23,6 +23,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
 	"github.com/pulumi/pulumi/pkg/resource/graph"
 	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/crash"
 	"github.com/pulumi/pulumi/pkg/util/logging"
 	"github.com/pulumi/pulumi/pkg/util/result"
 )
@@ -73,8 +74,9 @@ func (pe *planExecutor) Execute(callerCtx context.Context, opts Options, preview
 		}
 	}()
 
-	// Before doing anything else, optionally refresh each resource in the base checkpoint.
-	if opts.Refresh {
+	// Before doing anything else, optionally refresh each resource in the base checkpoint. Check-only plans never
+	// read resources, so they never refresh.
+	if opts.Refresh && !opts.CheckOnly {
 		if res := pe.refresh(callerCtx, opts, preview); res != nil {
 			return res
 		}
@@ -112,6 +114,7 @@ func (pe *planExecutor) Execute(callerCtx context.Context, opts Options, preview
 	}
 	incomingEvents := make(chan nextEvent)
 	go func() {
+		defer crash.Recover()
 		for {
 			event, sourceErr := src.Next()
 			select {
@@ -153,6 +156,16 @@ func (pe *planExecutor) Execute(callerCtx context.Context, opts Options, preview
 
 				if event.Event == nil {
 					deleteSteps := pe.stepGen.GenerateDeletes()
+					if err := checkLockedSteps(deleteSteps); err != nil {
+						pe.reportError("", err)
+						cancel()
+						return false, result.Bail()
+					}
+					if err := pe.stepGen.CheckTargets(deleteSteps); err != nil {
+						pe.reportError("", err)
+						cancel()
+						return false, result.Bail()
+					}
 					deletes := pe.stepGen.ScheduleDeletes(deleteSteps)
 
 					// ScheduleDeletes gives us a list of lists of steps. Each list of steps can safely be executed in
@@ -199,7 +212,8 @@ func (pe *planExecutor) Execute(callerCtx context.Context, opts Options, preview
 	}
 
 	// Figure out if execution failed and why. Step generation and execution errors trump cancellation.
-	if res != nil || pe.stepExec.Errored() || pe.stepGen.hasPolicyViolations {
+	if res != nil || pe.stepExec.Errored() || pe.stepGen.hasPolicyViolations || pe.stepGen.hasDeprecationErrors ||
+		pe.stepGen.hasCheckFailures {
 		// TODO(cyrusn): We seem to be losing any information about the original 'res's errors.  Should
 		// we be doing a merge here?
 		pe.reportExecResult("failed", preview)
@@ -235,6 +249,10 @@ func (pe *planExecutor) handleSingleEvent(event SourceEvent) result.Result {
 	if res != nil {
 		return res
 	}
+	if err := checkLockedSteps(steps); err != nil {
+		pe.reportError(pe.plan.generateEventURN(event), err)
+		return result.Bail()
+	}
 
 	pe.stepExec.ExecuteSerial(steps)
 	return nil
@@ -375,3 +393,29 @@ func (pe *planExecutor) refresh(callerCtx context.Context, opts Options, preview
 	}
 	return nil
 }
+
+// checkLockedSteps returns an error if any of the given steps would modify or delete a locked resource. Steps are
+// checked before any of them are executed, so that no part of an update to a locked resource is performed.
+func checkLockedSteps(steps []Step) error {
+	for _, step := range steps {
+		old := step.Old()
+		if old == nil || !old.Locked {
+			continue
+		}
+
+		var verb string
+		switch step.Op() {
+		case OpUpdate:
+			verb = "update"
+		case OpDelete:
+			verb = "delete"
+		case OpReplace, OpCreateReplacement, OpDeleteReplaced, OpReadReplacement, OpImportReplacement:
+			verb = "replace"
+		default:
+			continue
+		}
+		return errors.Errorf("refusing to %s locked resource '%s'; run `pulumi state unlock` to unlock it first",
+			verb, old.URN)
+	}
+	return nil
+}
@@ -384,6 +384,11 @@ func (r *Registry) Invoke(tok tokens.ModuleMember,
 	return nil, nil, errors.New("the provider registry is not invokable")
 }
 
+func (r *Registry) GetLogs(query plugin.LogQuery) (*[]plugin.LogEntry, error) {
+	// return an error: logs should be requested from the provider that manages the resources, not the registry
+	return nil, errors.New("the provider registry does not have logs")
+}
+
 func (r *Registry) GetPluginInfo() (workspace.PluginInfo, error) {
 	// return an error: this should not be called for the provider registry
 	return workspace.PluginInfo{}, errors.New("the provider registry does not report plugin info")
@@ -142,6 +142,9 @@ func (prov *testProvider) Invoke(tok tokens.ModuleMember,
 	args resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error) {
 	return nil, nil, errors.New("unsupported")
 }
+func (prov *testProvider) GetLogs(query plugin.LogQuery) (*[]plugin.LogEntry, error) {
+	return nil, errors.New("unsupported")
+}
 func (prov *testProvider) GetPluginInfo() (workspace.PluginInfo, error) {
 	return workspace.PluginInfo{
 		Name:    "testProvider",
@@ -17,18 +17,21 @@ package deploy
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
+
 	"github.com/blang/semver"
 	pbempty "github.com/golang/protobuf/ptypes/empty"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
-	"time"
 
 	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
 	"github.com/pulumi/pulumi/pkg/resource/plugin"
 	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/crash"
 	"github.com/pulumi/pulumi/pkg/util/logging"
 	"github.com/pulumi/pulumi/pkg/util/result"
 	"github.com/pulumi/pulumi/pkg/util/rpcutil"
@@ -44,6 +47,7 @@ type EvalRunInfo struct {
 	Program string             `json:"program" yaml:"program"`                   // the path to the program.
 	Args    []string           `json:"args,omitempty" yaml:"args,omitempty"`     // any arguments to pass to the package.
 	Target  *Target            `json:"target,omitempty" yaml:"target,omitempty"` // the target being deployed into.
+	Commit  string             `json:"commit,omitempty" yaml:"commit,omitempty"` // the program's source commit, if known.
 }
 
 // NewEvalSource returns a planning source that fetches resources by evaluating a package with a set of args and
@@ -93,7 +97,7 @@ func (src *evalSource) Iterate(
 	regChan := make(chan *registerResourceEvent)
 	regOutChan := make(chan *registerResourceOutputsEvent)
 	regReadChan := make(chan *readResourceEvent)
-	mon, err := newResourceMonitor(src, providers, regChan, regOutChan, regReadChan)
+	mon, err := newResourceMonitor(src, providers, regChan, regOutChan, regReadChan, opts.CheckOnly)
 	if err != nil {
 		return nil, result.FromError(errors.Wrap(err, "failed to start resource monitor"))
 	}
@@ -174,6 +178,7 @@ func (iter *evalSourceIterator) forkRun(opts Options) {
 	// Fire up the goroutine to make the RPC invocation against the language runtime.  As this executes, calls
 	// to queue things up in the resource channel will occur, and we will serve them concurrently.
 	go func() {
+		defer crash.Recover()
 		// Next, launch the language plugin.
 		run := func() result.Result {
 			rt := iter.src.runinfo.Proj.Runtime.Name()
@@ -298,7 +303,7 @@ func (d *defaultProviders) newRegisterDefaultProviderEvent(
 	event := &registerResourceEvent{
 		goal: resource.NewGoal(
 			providers.MakeProviderType(req.Package()),
-			req.Name(), true, inputs, "", false, nil, "", nil, nil, nil, nil, nil, nil, "", nil),
+			req.Name(), true, inputs, "", false, nil, "", nil, nil, nil, nil, nil, nil, "", nil, false, false),
 		done: done,
 	}
 	return event, done, nil
@@ -394,16 +399,42 @@ type resmon struct {
 	regChan          chan *registerResourceEvent        // the channel to send resource registrations to.
 	regOutChan       chan *registerResourceOutputsEvent // the channel to send resource output registrations to.
 	regReadChan      chan *readResourceEvent            // the channel to send resource reads to.
+	transformer      *transformer                       // the declarative transformations to apply to resources.
+	stack            tokens.QName                       // the name of the stack, for the URNs of renamed resources.
+	project          tokens.PackageName                 // the name of the project, for the URNs of renamed resources.
 	addr             string                             // the address the host is listening on.
 	cancel           chan bool                          // a channel that can cancel the server.
 	done             chan error                         // a channel that resolves when the server completes.
+	checkOnly        bool                               // true if invokes are not to be sent to providers.
 }
 
 var _ SourceResourceMonitor = (*resmon)(nil)
 
 // newResourceMonitor creates a new resource monitor RPC server.
 func newResourceMonitor(src *evalSource, provs ProviderSource, regChan chan *registerResourceEvent,
-	regOutChan chan *registerResourceOutputsEvent, regReadChan chan *readResourceEvent) (*resmon, error) {
+	regOutChan chan *registerResourceOutputsEvent, regReadChan chan *readResourceEvent, checkOnly bool) (*resmon, error) {
+
+	// Compile the project's and stack's declarative transformations, in that order.
+	var transformations []workspace.ResourceTransformation
+	if src.runinfo.Proj != nil {
+		transformations = append(transformations, src.runinfo.Proj.Transformations...)
+	}
+	if src.runinfo.Target != nil {
+		transformations = append(transformations, src.runinfo.Target.Transformations...)
+	}
+	xf, err := newTransformer(transformations, workspace.LoadInstalledPackageSchema)
+	if err != nil {
+		return nil, err
+	}
+	var stack tokens.QName
+	var project tokens.PackageName
+	if src.runinfo.Proj != nil && src.runinfo.Target != nil {
+		stack, project = src.runinfo.Target.Name, src.runinfo.Proj.Name
+		xf.setTagPropagation(src.runinfo.Proj.TagPropagation, strings.NewReplacer(
+			"${project}", string(project),
+			"${stack}", string(stack),
+			"${commit}", src.runinfo.Commit))
+	}
 
 	// Create our cancellation channel.
 	cancel := make(chan bool)
@@ -425,7 +456,11 @@ func newResourceMonitor(src *evalSource, provs ProviderSource, regChan chan *reg
 		regChan:          regChan,
 		regOutChan:       regOutChan,
 		regReadChan:      regReadChan,
+		transformer:      xf,
+		stack:            stack,
+		project:          project,
 		cancel:           cancel,
+		checkOnly:        checkOnly,
 	}
 
 	// Fire up a gRPC server and start listening for incomings.
@@ -550,11 +585,17 @@ func (rm *resmon) Invoke(ctx context.Context, req *pulumirpc.InvokeRequest) (*pu
 		return nil, errors.Wrapf(err, "failed to unmarshal %v args", tok)
 	}
 
-	// Do the invoke and then return the arguments.
+	// Do the invoke and then return the arguments. Check-only plans skip the invoke, and return an empty result.
 	logging.V(5).Infof("ResourceMonitor.Invoke received: tok=%v #args=%v", tok, len(args))
-	ret, failures, err := prov.Invoke(tok, args)
-	if err != nil {
-		return nil, errors.Wrapf(err, "invocation of %v returned an error", tok)
+	var ret resource.PropertyMap
+	var failures []plugin.CheckFailure
+	if rm.checkOnly {
+		logging.V(5).Infof("ResourceMonitor.Invoke skipped: tok=%v (check-only)", tok)
+	} else {
+		ret, failures, err = prov.Invoke(tok, args)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invocation of %v returned an error", tok)
+		}
 	}
 	mret, err := plugin.MarshalProperties(ret, plugin.MarshalOptions{
 		Label:        label,
@@ -671,6 +712,8 @@ func (rm *resmon) RegisterResource(ctx context.Context,
 	custom := req.GetCustom()
 	parent := resource.URN(req.GetParent())
 	protect := req.GetProtect()
+	retainOnDelete := req.GetRetainOnDelete()
+	locked := req.GetLocked()
 	deleteBeforeReplaceValue := req.GetDeleteBeforeReplace()
 	ignoreChanges := req.GetIgnoreChanges()
 	id := resource.ID(req.GetImportId())
@@ -725,6 +768,18 @@ func (rm *resmon) RegisterResource(ctx context.Context,
 		return nil, err
 	}
 
+	// Apply any declarative transformations from the project and stack. A resource that they rename is aliased to its
+	// URN from before the rename, so that renaming an existing resource does not replace it.
+	renamed, props, protect := rm.transformer.apply(t, name, custom, props, protect)
+	if renamed != name && rm.stack != "" {
+		parentType := tokens.Type("")
+		if parent != "" && parent.Type() != resource.RootStackType {
+			parentType = parent.QualifiedType()
+		}
+		aliases = append(aliases, resource.NewURN(rm.stack, rm.project, parentType, t, name))
+	}
+	name = renamed
+
 	propertyDependencies := make(map[resource.PropertyKey][]resource.URN)
 	if len(req.GetPropertyDependencies()) == 0 {
 		// If this request did not specify property dependencies, treat each property as depending on every resource
@@ -780,14 +835,16 @@ func (rm *resmon) RegisterResource(ctx context.Context,
 
 	logging.V(5).Infof(
 		"ResourceMonitor.RegisterResource received: t=%v, name=%v, custom=%v, #props=%v, parent=%v, protect=%v, "+
-			"provider=%v, deps=%v, deleteBeforeReplace=%v, ignoreChanges=%v, aliases=%v, customTimeouts=%v",
+			"provider=%v, deps=%v, deleteBeforeReplace=%v, ignoreChanges=%v, aliases=%v, customTimeouts=%v, "+
+			"retainOnDelete=%v, locked=%v",
 		t, name, custom, len(props), parent, protect, provider, dependencies, deleteBeforeReplace, ignoreChanges,
-		aliases, timeouts)
+		aliases, timeouts, retainOnDelete, locked)
 
 	// Send the goal state to the engine.
 	step := &registerResourceEvent{
 		goal: resource.NewGoal(t, name, custom, props, parent, protect, dependencies, provider, nil,
-			propertyDependencies, deleteBeforeReplace, ignoreChanges, additionalSecretOutputs, aliases, id, &timeouts),
+			propertyDependencies, deleteBeforeReplace, ignoreChanges, additionalSecretOutputs, aliases, id, &timeouts,
+			retainOnDelete, locked),
 		done: make(chan *RegisterResult),
 	}
 
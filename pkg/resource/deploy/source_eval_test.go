@@ -149,16 +149,16 @@ func TestRegisterNoDefaultProviders(t *testing.T) {
 		// Register a component resource.
 		&testRegEvent{
 			goal: resource.NewGoal(componentURN.Type(), componentURN.Name(), false, resource.PropertyMap{}, "", false,
-				nil, "", []string{}, nil, nil, nil, nil, nil, "", nil),
+				nil, "", []string{}, nil, nil, nil, nil, nil, "", nil, false, false),
 		},
 		// Register a couple resources using provider A.
 		&testRegEvent{
 			goal: resource.NewGoal("pkgA:index:typA", "res1", true, resource.PropertyMap{}, componentURN, false, nil,
-				providerARef.String(), []string{}, nil, nil, nil, nil, nil, "", nil),
+				providerARef.String(), []string{}, nil, nil, nil, nil, nil, "", nil, false, false),
 		},
 		&testRegEvent{
 			goal: resource.NewGoal("pkgA:index:typA", "res2", true, resource.PropertyMap{}, componentURN, false, nil,
-				providerARef.String(), []string{}, nil, nil, nil, nil, nil, "", nil),
+				providerARef.String(), []string{}, nil, nil, nil, nil, nil, "", nil, false, false),
 		},
 		// Register two more providers.
 		newProviderEvent("pkgA", "providerB", nil, ""),
@@ -166,11 +166,11 @@ func TestRegisterNoDefaultProviders(t *testing.T) {
 		// Register a few resources that use the new providers.
 		&testRegEvent{
 			goal: resource.NewGoal("pkgB:index:typB", "res3", true, resource.PropertyMap{}, "", false, nil,
-				providerBRef.String(), []string{}, nil, nil, nil, nil, nil, "", nil),
+				providerBRef.String(), []string{}, nil, nil, nil, nil, nil, "", nil, false, false),
 		},
 		&testRegEvent{
 			goal: resource.NewGoal("pkgB:index:typC", "res4", true, resource.PropertyMap{}, "", false, nil,
-				providerCRef.String(), []string{}, nil, nil, nil, nil, nil, "", nil),
+				providerCRef.String(), []string{}, nil, nil, nil, nil, nil, "", nil, false, false),
 		},
 	}
 
@@ -233,25 +233,25 @@ func TestRegisterDefaultProviders(t *testing.T) {
 		// Register a component resource.
 		&testRegEvent{
 			goal: resource.NewGoal(componentURN.Type(), componentURN.Name(), false, resource.PropertyMap{}, "", false,
-				nil, "", []string{}, nil, nil, nil, nil, nil, "", nil),
+				nil, "", []string{}, nil, nil, nil, nil, nil, "", nil, false, false),
 		},
 		// Register a couple resources from package A.
 		&testRegEvent{
 			goal: resource.NewGoal("pkgA:m:typA", "res1", true, resource.PropertyMap{},
-				componentURN, false, nil, "", []string{}, nil, nil, nil, nil, nil, "", nil),
+				componentURN, false, nil, "", []string{}, nil, nil, nil, nil, nil, "", nil, false, false),
 		},
 		&testRegEvent{
 			goal: resource.NewGoal("pkgA:m:typA", "res2", true, resource.PropertyMap{},
-				componentURN, false, nil, "", []string{}, nil, nil, nil, nil, nil, "", nil),
+				componentURN, false, nil, "", []string{}, nil, nil, nil, nil, nil, "", nil, false, false),
 		},
 		// Register a few resources from other packages.
 		&testRegEvent{
 			goal: resource.NewGoal("pkgB:m:typB", "res3", true, resource.PropertyMap{}, "", false,
-				nil, "", []string{}, nil, nil, nil, nil, nil, "", nil),
+				nil, "", []string{}, nil, nil, nil, nil, nil, "", nil, false, false),
 		},
 		&testRegEvent{
 			goal: resource.NewGoal("pkgB:m:typC", "res4", true, resource.PropertyMap{}, "", false,
-				nil, "", []string{}, nil, nil, nil, nil, nil, "", nil),
+				nil, "", []string{}, nil, nil, nil, nil, nil, "", nil, false, false),
 		},
 	}
 
@@ -491,6 +491,115 @@ func TestReadInvokeDefaultProviders(t *testing.T) {
 	assert.Equal(t, expectedInvokes, int(invokes))
 }
 
+func TestCheckOnlySkipsInvokes(t *testing.T) {
+	runInfo := &EvalRunInfo{
+		Proj:   &workspace.Project{Name: "test"},
+		Target: &Target{Name: "test"},
+	}
+
+	invokes := int32(0)
+	noopProvider := &deploytest.Provider{
+		InvokeF: func(tokens.ModuleMember, resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error) {
+			atomic.AddInt32(&invokes, 1)
+			return resource.PropertyMap{"result": resource.NewStringProperty("value")}, nil, nil
+		},
+	}
+
+	program := func(_ plugin.RunInfo, resmon *deploytest.ResourceMonitor) error {
+		// Invokes succeed, but return nothing.
+		ret, failures, err := resmon.Invoke("pkgA:m:funcA", nil, "", "")
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+		assert.Empty(t, failures)
+		return nil
+	}
+
+	ctx, err := newTestPluginContext(program)
+	assert.NoError(t, err)
+
+	providerSource := &testProviderSource{providers: make(map[providers.Reference]plugin.Provider)}
+
+	iter, res := NewEvalSource(ctx, runInfo, nil, true).Iterate(
+		context.Background(), Options{CheckOnly: true}, providerSource)
+	assert.Nil(t, res)
+
+	for {
+		event, res := iter.Next()
+		assert.Nil(t, res)
+		if event == nil {
+			break
+		}
+
+		// The default provider is still registered, so that resources can be checked.
+		e, ok := event.(RegisterResourceEvent)
+		if !assert.True(t, ok) {
+			continue
+		}
+		goal := e.Goal()
+		assert.True(t, providers.IsProviderType(goal.Type))
+		urn := resource.NewURN(runInfo.Target.Name, runInfo.Proj.Name, "", goal.Type, goal.Name)
+		ref, err := providers.NewReference(urn, "id")
+		assert.NoError(t, err)
+		providerSource.registerProvider(ref, noopProvider)
+
+		e.Done(&RegisterResult{
+			State: resource.NewState(goal.Type, urn, goal.Custom, false, "id", goal.Properties, resource.PropertyMap{},
+				goal.Parent, goal.Protect, false, goal.Dependencies, nil, goal.Provider, goal.PropertyDependencies,
+				false, nil, nil, nil),
+		})
+	}
+
+	assert.Equal(t, int32(0), atomic.LoadInt32(&invokes))
+}
+
+func TestRenameTransformationAliases(t *testing.T) {
+	runInfo := &EvalRunInfo{
+		Proj: &workspace.Project{Name: "test"},
+		Target: &Target{Name: "test", Transformations: []workspace.ResourceTransformation{
+			{Rename: &workspace.ResourceRename{Pattern: "^(.*)$", Replacement: "acme-$1"}},
+		}},
+	}
+
+	program := func(_ plugin.RunInfo, resmon *deploytest.ResourceMonitor) error {
+		_, _, _, err := resmon.RegisterResource("my:component:Thing", "c", false)
+		return err
+	}
+
+	ctx, err := newTestPluginContext(program)
+	assert.NoError(t, err)
+
+	providerSource := &testProviderSource{providers: make(map[providers.Reference]plugin.Provider)}
+	iter, res := NewEvalSource(ctx, runInfo, nil, false).Iterate(context.Background(), Options{}, providerSource)
+	assert.Nil(t, res)
+
+	registered := 0
+	for {
+		event, res := iter.Next()
+		assert.Nil(t, res)
+		if event == nil {
+			break
+		}
+		e, ok := event.(RegisterResourceEvent)
+		if !assert.True(t, ok) {
+			continue
+		}
+
+		// The renamed resource is aliased to its URN from before the rename, so that it is not replaced.
+		goal := e.Goal()
+		assert.Equal(t, "acme-c", string(goal.Name))
+		assert.Equal(t, []resource.URN{resource.NewURN("test", "test", "", "my:component:Thing", "c")}, goal.Aliases)
+		registered++
+
+		urn := resource.NewURN(runInfo.Target.Name, runInfo.Proj.Name, "", goal.Type, goal.Name)
+		e.Done(&RegisterResult{
+			State: resource.NewState(goal.Type, urn, goal.Custom, false, "", goal.Properties, resource.PropertyMap{},
+				goal.Parent, goal.Protect, false, goal.Dependencies, nil, goal.Provider, goal.PropertyDependencies,
+				false, nil, nil, nil),
+		})
+	}
+	assert.Equal(t, 1, registered)
+}
+
 // TODO[pulumi/pulumi#2753]: We should re-enable these tests (and fix them up as needed) once we have a solution
 // for #2753.
 // func TestReadResourceAndInvokeVersion(t *testing.T) {
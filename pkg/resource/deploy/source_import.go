@@ -0,0 +1,260 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"context"
+	"sync"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/crash"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/util/result"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// Import describes an existing resource to adopt into a stack.
+type Import struct {
+	Type    tokens.Type  // the resource's type.
+	Name    tokens.QName // the resource's name.
+	ID      resource.ID  // the ID of the existing resource.
+	Protect bool         // true if the resource is to be protected once it has been imported.
+}
+
+// NewImportSource returns a planning source that imports existing resources into a stack rather than running its
+// program. Each resource is read from the default provider for its package, and registered as a child of the stack's
+// root resource with the ID to import and the inputs its provider reports. The root resource and default providers
+// are registered first; those already in the stack's snapshot are reused.
+func NewImportSource(proj *workspace.Project, target *Target,
+	defaultProviderVersions map[tokens.Package]*semver.Version, imports []Import) Source {
+
+	return &importSource{
+		proj:                    proj,
+		target:                  target,
+		defaultProviderVersions: defaultProviderVersions,
+		imports:                 imports,
+	}
+}
+
+// ImportTargets returns the URNs of the resources that importing the given resources may create: the resources
+// themselves, along with the root resource and default providers they need if the target's snapshot lacks them. An
+// import targets these so that it leaves the stack's other resources as they are.
+func ImportTargets(proj *workspace.Project, target *Target, imports []Import) []resource.URN {
+	olds := make(map[resource.URN]bool)
+	if target.Snapshot != nil {
+		for _, res := range target.Snapshot.Resources {
+			if !res.Delete {
+				olds[res.URN] = true
+			}
+		}
+	}
+
+	root := importRootURN(proj, target)
+	var targets []resource.URN
+	if !olds[root] {
+		targets = append(targets, root)
+	}
+	seen := make(map[tokens.Package]bool)
+	for _, imp := range imports {
+		if pkg := imp.Type.Package(); !seen[pkg] {
+			seen[pkg] = true
+			req := providers.NewProviderRequest(nil, pkg)
+			urn := resource.NewURN(target.Name, proj.Name, "", providers.MakeProviderType(pkg), req.Name())
+			if !olds[urn] {
+				targets = append(targets, urn)
+			}
+		}
+		targets = append(targets, importURN(proj, target, imp))
+	}
+	return targets
+}
+
+// importURN returns the URN of the given resource to import. Imported resources are children of the stack's root
+// resource, whose type, like that of any root stack resource, is not part of its children's URNs.
+func importURN(proj *workspace.Project, target *Target, imp Import) resource.URN {
+	return resource.NewURN(target.Name, proj.Name, "", imp.Type, imp.Name)
+}
+
+// importRootURN returns the URN of the root resource of the target stack: that of the root resource in its snapshot,
+// if there is one, and otherwise the one that programs register by default.
+func importRootURN(proj *workspace.Project, target *Target) resource.URN {
+	if target.Snapshot != nil {
+		for _, res := range target.Snapshot.Resources {
+			if res.Type == resource.RootStackType && res.Parent == "" && !res.Delete {
+				return res.URN
+			}
+		}
+	}
+	return resource.DefaultRootStackURN(target.Name, proj.Name)
+}
+
+type importSource struct {
+	proj                    *workspace.Project                 // the project of the stack being imported into.
+	target                  *Target                            // the stack being imported into.
+	defaultProviderVersions map[tokens.Package]*semver.Version // the default provider versions for this source.
+	imports                 []Import                           // the resources to import.
+}
+
+func (src *importSource) Close() error                { return nil }
+func (src *importSource) Project() tokens.PackageName { return src.proj.Name }
+func (src *importSource) Info() interface{}           { return nil }
+
+func (src *importSource) Iterate(
+	ctx context.Context, opts Options, providers ProviderSource) (SourceIterator, result.Result) {
+
+	iter := &importSourceIterator{
+		ctx:       ctx,
+		src:       src,
+		providers: providers,
+		regChan:   make(chan *registerResourceEvent),
+		finChan:   make(chan result.Result),
+		cancel:    make(chan bool),
+	}
+	go iter.run()
+	return iter, nil
+}
+
+type importSourceIterator struct {
+	ctx       context.Context             // the context of the plan, which cancels the import.
+	src       *importSource               // the owning import source.
+	providers ProviderSource              // the source of the providers that read the resources to import.
+	regChan   chan *registerResourceEvent // the channel that contains resource registrations.
+	finChan   chan result.Result          // the channel that communicates completion.
+	cancel    chan bool                   // closed when the iterator is closed.
+	closeOnce sync.Once                   // ensures that cancel is closed only once.
+	done      bool                        // set to true when the import is done.
+}
+
+func (iter *importSourceIterator) Close() error {
+	iter.closeOnce.Do(func() { close(iter.cancel) })
+	return nil
+}
+
+func (iter *importSourceIterator) Next() (SourceEvent, result.Result) {
+	if iter.done {
+		return nil, nil
+	}
+
+	select {
+	case reg := <-iter.regChan:
+		return reg, nil
+	case res := <-iter.finChan:
+		iter.done = true
+		return nil, res
+	}
+}
+
+// run registers the root resource, the default providers, and the resources to import, in that order, waiting for
+// each registration to complete before making the next.
+func (iter *importSourceIterator) run() {
+	defer crash.Recover()
+
+	err := iter.importResources()
+	var res result.Result
+	if err != nil {
+		res = result.FromError(err)
+	}
+	select {
+	case iter.finChan <- res:
+	case <-iter.cancel:
+	case <-iter.ctx.Done():
+	}
+}
+
+func (iter *importSourceIterator) importResources() error {
+	src := iter.src
+	root := importRootURN(src.proj, src.target)
+	if _, err := iter.register(resource.NewGoal(root.Type(), root.Name(), false, resource.PropertyMap{},
+		"", false, nil, "", nil, nil, nil, nil, nil, nil, "", nil, false, false)); err != nil {
+		return err
+	}
+
+	defaults := &defaultProviders{defaultVersions: src.defaultProviderVersions, config: src.target}
+	refs := make(map[tokens.Package]providers.Reference)
+	for _, imp := range src.imports {
+		pkg := imp.Type.Package()
+		ref, has := refs[pkg]
+		if !has {
+			event, _, err := defaults.newRegisterDefaultProviderEvent(providers.NewProviderRequest(nil, pkg))
+			if err != nil {
+				return err
+			}
+			state, err := iter.register(event.goal)
+			if err != nil {
+				return err
+			}
+			id := state.ID
+			if id == "" {
+				id = providers.UnknownID
+			}
+			if ref, err = providers.NewReference(state.URN, id); err != nil {
+				return err
+			}
+			refs[pkg] = ref
+		}
+
+		// Read the resource so that it can be registered with the inputs its provider reports. If the provider reports
+		// none, the import step will fail with a suitable error.
+		prov, ok := iter.providers.GetProvider(ref)
+		if !ok {
+			return errors.Errorf("unknown provider '%v'", ref)
+		}
+		urn := importURN(src.proj, src.target, imp)
+		read, _, err := prov.Read(urn, imp.ID, nil, nil)
+		if err != nil {
+			return errors.Wrapf(err, "reading %s", urn)
+		}
+		if read.Outputs == nil {
+			return errors.Errorf("resource '%v' does not exist", imp.ID)
+		}
+		inputs := read.Inputs
+		if inputs == nil {
+			inputs = resource.PropertyMap{}
+		}
+		logging.V(5).Infof("ImportSourceIterator read %v (id=%v, #inputs=%v)", urn, imp.ID, len(inputs))
+
+		if _, err = iter.register(resource.NewGoal(imp.Type, imp.Name, true, inputs, root, imp.Protect, nil,
+			ref.String(), nil, nil, nil, nil, nil, nil, imp.ID, nil, false, false)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// register registers a resource with the given goal, and returns its state once the registration completes.
+func (iter *importSourceIterator) register(goal *resource.Goal) (*resource.State, error) {
+	event := &registerResourceEvent{goal: goal, done: make(chan *RegisterResult)}
+	select {
+	case iter.regChan <- event:
+	case <-iter.cancel:
+		return nil, context.Canceled
+	case <-iter.ctx.Done():
+		return nil, iter.ctx.Err()
+	}
+
+	select {
+	case res := <-event.done:
+		return res.State, nil
+	case <-iter.cancel:
+		return nil, context.Canceled
+	case <-iter.ctx.Done():
+		return nil, iter.ctx.Err()
+	}
+}
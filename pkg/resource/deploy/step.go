@@ -299,8 +299,9 @@ func (s *DeleteStep) Apply(preview bool) (resource.Status, StepCompleteFunc, err
 			errors.Errorf("refusing to delete protected resource '%s'", s.old.URN)
 	}
 
-	// Deleting an External resource is a no-op, since Pulumi does not own the lifecycle.
-	if !preview && !s.old.External {
+	// Deleting an External resource is a no-op, since Pulumi does not own the lifecycle. Neither is deleting a
+	// resource that is retained on deletion, whose lifecycle is handed off to whatever manages it next.
+	if !preview && !s.old.External && !s.old.RetainOnDelete {
 		if s.old.Custom {
 			// Invoke the Delete RPC function for this provider:
 			prov, err := getProvider(s)
@@ -571,6 +572,12 @@ func (s *ReadStep) Res() *resource.State { return s.new }
 func (s *ReadStep) Logical() bool        { return !s.replacing }
 
 func (s *ReadStep) Apply(preview bool) (resource.Status, StepCompleteFunc, error) {
+	return s.apply(true)
+}
+
+// apply applies the step. If read is false, the provider is not asked to read the resource, and its outputs are
+// left empty, just as they are when its ID is unknown.
+func (s *ReadStep) apply(read bool) (resource.Status, StepCompleteFunc, error) {
 	urn := s.new.URN
 	id := s.new.ID
 
@@ -578,7 +585,7 @@ func (s *ReadStep) Apply(preview bool) (resource.Status, StepCompleteFunc, error
 	resourceStatus := resource.StatusOK
 	// Unlike most steps, Read steps run during previews. The only time
 	// we can't run is if the ID we are given is unknown.
-	if id == plugin.UnknownStringValue {
+	if !read || id == plugin.UnknownStringValue {
 		s.new.Outputs = resource.PropertyMap{}
 	} else {
 		prov, err := getProvider(s)
@@ -728,6 +735,8 @@ func (s *RefreshStep) Apply(preview bool) (resource.Status, StepCompleteFunc, er
 			s.old.Parent, s.old.Protect, s.old.External, s.old.Dependencies, initErrors, s.old.Provider,
 			s.old.PropertyDependencies, s.old.PendingReplacement, s.old.AdditionalSecretOutputs, s.old.Aliases,
 			&s.old.CustomTimeouts)
+		s.new.Locked = s.old.Locked
+		s.new.RetainOnDelete = s.old.RetainOnDelete
 	} else {
 		s.new = nil
 	}
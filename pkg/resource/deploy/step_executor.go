@@ -24,6 +24,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/diag"
 	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/crash"
 	"github.com/pulumi/pulumi/pkg/util/logging"
 )
 
@@ -218,7 +219,18 @@ func (se *stepExecutor) executeChain(workerID int, chain chain) {
 		default:
 		}
 
-		if err := se.executeStep(workerID, step); err != nil {
+		if throttle := se.opts.Throttle; throttle != nil {
+			if err := throttle.Acquire(se.ctx); err != nil {
+				se.log(workerID, "step %v on %v canceled while throttled", step.Op(), step.URN())
+				return
+			}
+		}
+		err := se.executeStep(workerID, step)
+		if throttle := se.opts.Throttle; throttle != nil {
+			throttle.Release()
+		}
+
+		if err != nil {
 			se.log(workerID, "step %v on %v failed, signalling cancellation", step.Op(), step.URN())
 			se.cancelDueToError()
 			if err != errStepApplyFailed {
@@ -268,7 +280,15 @@ func (se *stepExecutor) executeStep(workerID int, step Step) error {
 	}
 
 	se.log(workerID, "applying step %v on %v (preview %v)", step.Op(), step.URN(), se.preview)
-	status, stepComplete, err := step.Apply(se.preview)
+	var status resource.Status
+	var stepComplete StepCompleteFunc
+	var err error
+	if read, isRead := step.(*ReadStep); isRead && se.opts.CheckOnly {
+		// Check-only plans never ask providers to read resources.
+		status, stepComplete, err = read.apply(false)
+	} else {
+		status, stepComplete, err = step.Apply(se.preview)
+	}
 
 	if err == nil {
 		// If we have a state object, and this is a create or update, remember it, as we may need to update it later.
@@ -341,6 +361,7 @@ func (se *stepExecutor) log(workerID int, msg string, args ...interface{}) {
 // that will execute the chain so that the execution continues asynchronously and this worker can proceed to
 // the next chain.
 func (se *stepExecutor) worker(workerID int, launchAsync bool) {
+	defer crash.Recover()
 	se.log(workerID, "worker coming online")
 	defer se.workers.Done()
 
@@ -366,6 +387,7 @@ func (se *stepExecutor) worker(workerID int, launchAsync bool) {
 			se.workers.Add(1)
 			newWorkerID := oneshotWorkerID
 			go func() {
+				defer crash.Recover()
 				defer se.workers.Done()
 				se.log(newWorkerID, "launching oneshot worker")
 				se.executeChain(newWorkerID, request.Chain)
@@ -30,6 +30,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/util/contract"
 	"github.com/pulumi/pulumi/pkg/util/logging"
 	"github.com/pulumi/pulumi/pkg/util/result"
+	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
 // stepGenerator is responsible for turning resource events into steps that
@@ -44,6 +45,15 @@ type stepGenerator struct {
 	// should terminate in error. This primarily allows `preview` to aggregate many policy violation
 	// events and report them all at once.
 	hasPolicyViolations bool
+	// signals that one or more deprecated resource types or properties were used while deprecations are strict, and
+	// the plan should terminate in error.
+	hasDeprecationErrors bool
+	// signals that one or more resources failed their providers' checks during a check-only plan, and the plan
+	// should terminate in error once all resources have been checked.
+	hasCheckFailures bool
+
+	deprecations *deprecationChecker // finds uses of deprecated resource types and properties.
+	autoNamer    *autoNamer          // fills in the physical names of resources that do not specify one.
 
 	urns           map[resource.URN]bool            // set of URNs discovered for this plan
 	reads          map[resource.URN]bool            // set of URNs read for this plan
@@ -59,6 +69,9 @@ type stepGenerator struct {
 	dependentReplaceKeys map[resource.URN][]resource.PropertyKey
 	// a map from old names (aliased URNs) to the new URN that aliased to them.
 	aliased map[resource.URN]resource.URN
+	// set of URNs that may be created, updated, or deleted in this plan, or nil if every resource may be. If targets'
+	// dependents are targeted as well, they are added to this set as they are discovered.
+	targets map[resource.URN]bool
 }
 
 // GenerateReadSteps is responsible for producing one or more steps required to service
@@ -126,7 +139,8 @@ func (sg *stepGenerator) GenerateReadSteps(event ReadResourceEvent) ([]Step, res
 // and Check on the provider associated with that resource. If those fail, an error
 // is returned.
 func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, result.Result) {
-	var invalid bool // will be set to true if this object fails validation.
+	var invalid bool     // will be set to true if this object fails validation.
+	var checkFailed bool // will be set to true if this object fails its provider's checks.
 
 	goal := event.Goal()
 	// generate an URN for this new resource.
@@ -161,8 +175,17 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, res
 		}
 	}
 
+	// Fill in the resource's physical name if the project or stack configures the engine to auto-name resources.
+	goalProps, nameWarning, nameErr := sg.autoNamer.name(urn, goal.Custom, goal.Properties, oldInputs)
+	if nameErr != nil {
+		return nil, result.FromError(nameErr)
+	}
+	if nameWarning != "" {
+		sg.plan.Diag().Warningf(diag.RawMessage(urn, nameWarning))
+	}
+
 	// Create the desired inputs from the goal state
-	inputs := goal.Properties
+	inputs := goalProps
 	if hasOld {
 		// Set inputs back to their old values (if any) for any "ignored" properties
 		processedInputs, res := processIgnoreChanges(inputs, oldInputs, goal.IgnoreChanges)
@@ -177,6 +200,13 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, res
 	new := resource.NewState(goal.Type, urn, goal.Custom, false, "", inputs, nil, goal.Parent, goal.Protect, false,
 		goal.Dependencies, goal.InitErrors, goal.Provider, goal.PropertyDependencies, false,
 		goal.AdditionalSecretOutputs, goal.Aliases, &goal.CustomTimeouts)
+	new.RetainOnDelete = goal.RetainOnDelete
+	new.Locked = goal.Locked
+	if hasOld && old.Locked {
+		// A lock may also have been set by `pulumi state lock`, so it carries over from the old state until it is
+		// removed with `pulumi state unlock`.
+		new.Locked = true
+	}
 
 	// Is this thing a provider resource? If so, stash it - we might need it later when calculating replacement
 	// of resources that use this provider.
@@ -199,6 +229,21 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, res
 	// We may be creating this resource if it previously existed in the snapshot as an External resource
 	wasExternal := hasOld && old.External
 
+	// If this is a targeted update and this resource is not a target, leave it as it is by carrying its old state
+	// over. We can't do the same for a resource that doesn't exist yet, as the resources that depend on it would
+	// refer to a resource that isn't there.
+	if !recreating && !sg.isTargetedForUpdate(new) {
+		if !hasOld || wasExternal {
+			sg.plan.Diag().Errorf(diag.GetUntargetedCreateError(urn), urn)
+			return nil, result.Bail()
+		}
+
+		logging.V(7).Infof("Planner decided not to update '%v' as it is not targeted (same)", urn)
+		new.Inputs = oldInputs
+		sg.sames[urn] = true
+		return []Step{NewSameStep(sg.plan, event, old, new)}, nil
+	}
+
 	// If the goal contains an ID, this may be an import. An import occurs if there is no old resource or if the old
 	// resource's ID does not match the ID in the goal state.
 	isImport := goal.Custom && goal.ID != "" && (!hasOld || old.External || old.ID != goal.ID)
@@ -224,7 +269,7 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, res
 		// invalid (they got deleted) so don't consider them. Similarly, if the old resource was External,
 		// don't consider those inputs since Pulumi does not own them.
 		if recreating || wasExternal {
-			inputs, failures, err = prov.Check(urn, nil, goal.Properties, allowUnknowns)
+			inputs, failures, err = prov.Check(urn, nil, goalProps, allowUnknowns)
 		} else {
 			inputs, failures, err = prov.Check(urn, oldInputs, inputs, allowUnknowns)
 		}
@@ -232,13 +277,19 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, res
 		if err != nil {
 			return nil, result.FromError(err)
 		} else if issueCheckErrors(sg.plan, new, urn, failures) {
-			invalid = true
+			checkFailed = true
 		}
 		new.Inputs = inputs
 	}
 
+	// Check-only plans don't run policy packs.
+	var policyPackPaths []string
+	if !sg.opts.CheckOnly {
+		policyPackPaths = sg.plan.localPolicyPackPaths
+	}
+
 	// Load all policy packs into the plugin host.
-	for _, path := range sg.plan.localPolicyPackPaths {
+	for _, path := range policyPackPaths {
 		abs, err := filepath.Abs(path)
 		if err != nil {
 			return nil, result.FromError(err)
@@ -254,14 +305,29 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, res
 	}
 
 	// Get the final list of policy packs.
-	analyzers := sg.plan.ctx.Host.ListAnalyzers()
+	var analyzers []plugin.Analyzer
+	if !sg.opts.CheckOnly {
+		analyzers = sg.plan.ctx.Host.ListAnalyzers()
+	}
 
+	remediated := false
 	for _, analyzer := range analyzers {
 		var diagnostics []plugin.AnalyzeDiagnostic
-		diagnostics, err = analyzer.Analyze(new.Type, inputs)
+		var remediations []plugin.Remediation
+		diagnostics, remediations, err = analyzer.Analyze(new.Type, inputs)
 		if err != nil {
 			return nil, result.FromError(err)
 		}
+
+		// Apply any remediations before the resource is registered, so that its provider sees the remediated inputs.
+		// Later policy packs analyze the remediated inputs, too.
+		for _, r := range remediations {
+			after := applyRemediation(inputs, r)
+			sg.opts.Events.OnPolicyRemediation(new.URN, r, inputs, after)
+			inputs = after
+			new.Inputs = inputs
+			remediated = true
+		}
 		for _, d := range diagnostics {
 			// TODO(hausdorff): Batch up failures and report them all at once during preview. This
 			// will cause them to fail eagerly.
@@ -275,11 +341,60 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, res
 		}
 	}
 
+	// Remediations may leave inputs that the provider would reject, so the provider checks the remediated inputs
+	// again, exactly as it checked the program's.
+	if remediated && prov != nil {
+		var failures []plugin.CheckFailure
+		if recreating || wasExternal {
+			inputs, failures, err = prov.Check(urn, nil, inputs, allowUnknowns)
+		} else {
+			inputs, failures, err = prov.Check(urn, oldInputs, inputs, allowUnknowns)
+		}
+
+		if err != nil {
+			return nil, result.FromError(err)
+		} else if issueCheckErrors(sg.plan, new, urn, failures) {
+			checkFailed = true
+		}
+		new.Inputs = inputs
+	}
+
+	// Report any deprecated resource types or properties the program uses. Like policy violations, these are
+	// aggregated during previews so that they can all be reported at once.
+	if goal.Custom {
+		for _, d := range sg.deprecations.check(goal.Type, goalProps) {
+			if sg.opts.StrictDeprecations {
+				if !sg.plan.preview {
+					invalid = true
+				}
+				sg.hasDeprecationErrors = true
+				sg.plan.Diag().Errorf(diag.RawMessage(urn, d.String()))
+			} else {
+				sg.plan.Diag().Warningf(diag.RawMessage(urn, d.String()))
+			}
+			if sg.opts.Events != nil {
+				sg.opts.Events.OnDeprecation(urn, d)
+			}
+		}
+	}
+
 	// If the resource isn't valid, don't proceed any further.
-	if invalid {
+	if invalid || checkFailed && !sg.opts.CheckOnly {
 		return nil, result.Bail()
 	}
 
+	// Check-only plans carry on past resources that fail their checks so that every resource is checked, but don't
+	// diff them: their inputs are known to be invalid. The program sees the old resource's outputs, if any.
+	if checkFailed {
+		sg.hasCheckFailures = true
+		if hasOld {
+			sg.sames[urn] = true
+			return []Step{NewSameStep(sg.plan, event, old, new)}, nil
+		}
+		sg.creates[urn] = true
+		return []Step{NewCreateStep(sg.plan, event, new)}, nil
+	}
+
 	// There are four cases we need to consider when figuring out what to do with this resource.
 	//
 	// Case 1: recreating
@@ -379,7 +494,7 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, res
 				// had assumed that we were going to carry them over from the old resource, which is no longer true.
 				if prov != nil {
 					var failures []plugin.CheckFailure
-					inputs, failures, err = prov.Check(urn, nil, goal.Properties, allowUnknowns)
+					inputs, failures, err = prov.Check(urn, nil, goalProps, allowUnknowns)
 					if err != nil {
 						return nil, result.FromError(err)
 					} else if issueCheckErrors(sg.plan, new, urn, failures) {
@@ -507,6 +622,16 @@ func (sg *stepGenerator) GenerateDeletes() []Step {
 	// dependencies prior to their dependent nodes.
 	var dels []Step
 	if prev := sg.plan.prev; prev != nil {
+		// If the targets' dependents are targeted as well, find those that the program didn't register. These must be
+		// found before we walk the list backwards, as a resource's dependencies precede it in the list.
+		if sg.targets != nil && sg.opts.TargetDependents {
+			for _, res := range prev.Resources {
+				if !res.Delete && !sg.urns[res.URN] {
+					sg.isTargetedForUpdate(res)
+				}
+			}
+		}
+
 		for i := len(prev.Resources) - 1; i >= 0; i-- {
 			// If this resource is explicitly marked for deletion or wasn't seen at all, delete it.
 			res := prev.Resources[i]
@@ -543,6 +668,12 @@ func (sg *stepGenerator) GenerateDeletes() []Step {
 				dels = append(dels, NewDeleteReplacementStep(sg.plan, res, false))
 			} else if _, aliased := sg.aliased[res.URN]; !sg.sames[res.URN] && !sg.updates[res.URN] && !sg.replaces[res.URN] &&
 				!sg.reads[res.URN] && !aliased {
+				// If this is a targeted update and the resource is not a target, leave it in the snapshot.
+				if !sg.isTargetedForUpdate(res) {
+					logging.V(7).Infof("Planner decided not to delete '%v' as it is not targeted", res.URN)
+					continue
+				}
+
 				// NOTE: we deliberately do not check sg.deletes here, as it is possible for us to issue multiple
 				// delete steps for the same URN if the old checkpoint contained pending deletes.
 				logging.V(7).Infof("Planner decided to delete '%v'", res.URN)
@@ -558,6 +689,81 @@ func (sg *stepGenerator) GenerateDeletes() []Step {
 	return dels
 }
 
+// isTargetedForUpdate returns true if the given resource may be created, updated, or deleted by this plan. If the
+// targets' dependents are targeted as well and the resource depends on a target, it is added to the targets.
+func (sg *stepGenerator) isTargetedForUpdate(res *resource.State) bool {
+	if sg.targets == nil || sg.targets[res.URN] {
+		return true
+	}
+	if !sg.opts.TargetDependents {
+		return false
+	}
+
+	for _, dep := range resourceDependencies(res) {
+		if sg.targets[dep] {
+			sg.targets[res.URN] = true
+			return true
+		}
+	}
+	return false
+}
+
+// CheckTargets ensures that a targeted update names only resources that exist and leaves the snapshot intact after the
+// given deletes: a resource that is not targeted must not depend on a resource that the update deletes.
+func (sg *stepGenerator) CheckTargets(deleteSteps []Step) error {
+	if sg.targets == nil {
+		return nil
+	}
+
+	olds := sg.plan.Olds()
+	for _, target := range sg.opts.UpdateTargets {
+		if _, hasOld := olds[target]; !hasOld && !sg.urns[target] {
+			return errors.Errorf("target '%s' does not name a resource in the stack or the program", target)
+		}
+	}
+
+	deleted := make(map[resource.URN]bool)
+	for _, step := range deleteSteps {
+		if !step.Old().Delete {
+			deleted[step.URN()] = true
+		}
+	}
+	if len(deleted) == 0 {
+		return nil
+	}
+
+	// Any old resource that was neither registered by the program nor deleted stays in the snapshot as it is.
+	if prev := sg.plan.prev; prev != nil {
+		for _, res := range prev.Resources {
+			if _, aliased := sg.aliased[res.URN]; res.Delete || sg.urns[res.URN] || deleted[res.URN] || aliased {
+				continue
+			}
+			for _, dep := range resourceDependencies(res) {
+				if deleted[dep] {
+					return errors.Errorf("refusing to delete '%s', which '%s' depends on but is not targeted; "+
+						"target it as well or target the dependents of the targets", dep, res.URN)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// resourceDependencies returns the URNs of the resources the given resource depends on: its parent, its provider, and
+// its explicit dependencies.
+func resourceDependencies(res *resource.State) []resource.URN {
+	var deps []resource.URN
+	if res.Parent != "" {
+		deps = append(deps, res.Parent)
+	}
+	if res.Provider != "" {
+		if ref, err := providers.ParseReference(res.Provider); err == nil {
+			deps = append(deps, ref.URN())
+		}
+	}
+	return append(deps, res.Dependencies...)
+}
+
 // GeneratePendingDeletes generates delete steps for all resources that are pending deletion. This function should be
 // called at the start of a plan in order to find all resources that are pending deletion from the prevous plan.
 func (sg *stepGenerator) GeneratePendingDeletes() []Step {
@@ -814,6 +1020,18 @@ func issueCheckErrors(plan *Plan, new *resource.State, urn resource.URN, failure
 	return true
 }
 
+// applyRemediation returns the inputs of a resource after the given remediation. Analyzers see secrets as their
+// underlying values, so each property that was secret before the remediation remains secret after it.
+func applyRemediation(inputs resource.PropertyMap, r plugin.Remediation) resource.PropertyMap {
+	remediated := r.Properties.Copy()
+	for k, v := range remediated {
+		if old, has := inputs[k]; has && old.ContainsSecrets() && !v.ContainsSecrets() {
+			remediated[k] = resource.MakeSecret(v)
+		}
+	}
+	return remediated
+}
+
 // processIgnoreChanges sets the value for each ignoreChanges property in inputs to the value from oldInputs.  This has
 // the effect of ensuring that no changes will be made for the corresponding property.
 func processIgnoreChanges(inputs, oldInputs resource.PropertyMap,
@@ -979,6 +1197,14 @@ func (sg *stepGenerator) calculateDependentReplacements(root *resource.State) ([
 
 // newStepGenerator creates a new step generator that operates on the given plan.
 func newStepGenerator(plan *Plan, opts Options) *stepGenerator {
+	var targets map[resource.URN]bool
+	if len(opts.UpdateTargets) != 0 {
+		targets = make(map[resource.URN]bool)
+		for _, urn := range opts.UpdateTargets {
+			targets[urn] = true
+		}
+	}
+
 	return &stepGenerator{
 		plan:                 plan,
 		opts:                 opts,
@@ -993,5 +1219,8 @@ func newStepGenerator(plan *Plan, opts Options) *stepGenerator {
 		providers:            make(map[resource.URN]*resource.State),
 		dependentReplaceKeys: make(map[resource.URN][]resource.PropertyKey),
 		aliased:              make(map[resource.URN]resource.URN),
+		deprecations:         newDeprecationChecker(workspace.LoadInstalledPackageSchema),
+		autoNamer:            newAutoNamer(opts.AutoNaming, plan.Target(), workspace.LoadInstalledPackageSchema),
+		targets:              targets,
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -113,3 +114,91 @@ func TestIgnoreChanges(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckLockedSteps(t *testing.T) {
+	urn := resource.NewURN("dev", "proj", "", "test:index:Resource", "a")
+	old := &resource.State{URN: urn, Type: urn.Type(), Inputs: resource.PropertyMap{}}
+	new := &resource.State{URN: urn, Type: urn.Type(), Inputs: resource.PropertyMap{}}
+
+	update := NewUpdateStep(nil, &testRegEvent{}, old, new, nil, nil, nil, nil)
+	del := NewDeleteStep(nil, old)
+	same := NewSameStep(nil, &testRegEvent{}, old, new)
+
+	// Unlocked resources may be changed.
+	assert.NoError(t, checkLockedSteps([]Step{update, del}))
+
+	// Locked resources may stay the same, but may be neither updated nor deleted.
+	old.Locked = true
+	assert.NoError(t, checkLockedSteps([]Step{same}))
+	assert.EqualError(t, checkLockedSteps([]Step{same, update}), "refusing to update locked resource '"+
+		string(urn)+"'; run `pulumi state unlock` to unlock it first")
+	assert.Error(t, checkLockedSteps([]Step{del}))
+}
+
+func TestTargetedDeletes(t *testing.T) {
+	a, b, c := newResource("a"), newResource("b"), newResource("c")
+	b.Dependencies = []resource.URN{a.URN}
+	snap := newSnapshot([]*resource.State{a, b, c}, nil)
+
+	deletes := func(opts Options) ([]resource.URN, error) {
+		plan, err := NewPlan(&plugin.Context{}, &Target{}, snap, &fixedSource{}, nil, false, nil)
+		if err != nil {
+			return nil, err
+		}
+		sg := newStepGenerator(plan, opts)
+		steps := sg.GenerateDeletes()
+		var urns []resource.URN
+		for _, step := range steps {
+			urns = append(urns, step.URN())
+		}
+		return urns, sg.CheckTargets(steps)
+	}
+
+	// Without targets, every resource the program didn't register is deleted.
+	urns, err := deletes(Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, []resource.URN{c.URN, b.URN, a.URN}, urns)
+
+	// Only targets are deleted.
+	urns, err = deletes(Options{UpdateTargets: []resource.URN{c.URN}})
+	assert.NoError(t, err)
+	assert.Equal(t, []resource.URN{c.URN}, urns)
+
+	// A target may not be deleted while a resource that is not targeted depends on it...
+	_, err = deletes(Options{UpdateTargets: []resource.URN{a.URN}})
+	assert.Error(t, err)
+
+	// ...unless the dependents of targets are targeted as well.
+	urns, err = deletes(Options{UpdateTargets: []resource.URN{a.URN}, TargetDependents: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []resource.URN{b.URN, a.URN}, urns)
+
+	// Targets must name resources that exist.
+	_, err = deletes(Options{UpdateTargets: []resource.URN{newResource("d").URN}})
+	assert.Error(t, err)
+}
+
+func TestApplyRemediation(t *testing.T) {
+	inputs := resource.PropertyMap{
+		"acl":      resource.NewStringProperty("public-read"),
+		"password": resource.MakeSecret(resource.NewStringProperty("hunter2")),
+	}
+
+	// Analyzers see secrets as plain values, so a remediation returns them that way.
+	remediated := applyRemediation(inputs, plugin.Remediation{
+		PolicyName: "no-public-buckets",
+		Properties: resource.PropertyMap{
+			"acl":      resource.NewStringProperty("private"),
+			"password": resource.NewStringProperty("hunter2"),
+			"tags":     resource.NewObjectProperty(resource.PropertyMap{}),
+		},
+	})
+	assert.Equal(t, resource.PropertyMap{
+		"acl":      resource.NewStringProperty("private"),
+		"password": resource.MakeSecret(resource.NewStringProperty("hunter2")),
+		"tags":     resource.NewObjectProperty(resource.PropertyMap{}),
+	}, remediated)
+
+	// The original inputs are left alone.
+	assert.Equal(t, "public-read", inputs["acl"].StringValue())
+}
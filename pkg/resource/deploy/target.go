@@ -17,6 +17,7 @@ package deploy
 import (
 	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
 // Target represents information about a deployment target.
@@ -25,6 +26,11 @@ type Target struct {
 	Config    config.Map       // optional configuration key/value pairs.
 	Decrypter config.Decrypter // decrypter for secret configuration values.
 	Snapshot  *Snapshot        // the last snapshot deployed to the target.
+
+	Transformations []workspace.ResourceTransformation // stack-specific transformations to apply to resources.
+	NamePrefix      string                             // an optional prefix for engine-generated physical names.
+	NameSuffix      string                             // an optional suffix for engine-generated physical names.
+	Guardrails      *workspace.StackGuardrails         // optional limits on the impact of an update.
 }
 
 // GetPackageConfig returns the set of configuration parameters for the indicated package, if any.
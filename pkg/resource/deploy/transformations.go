@@ -0,0 +1,252 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// compiledTransformation is a resource transformation whose regular expressions have been compiled.
+type compiledTransformation struct {
+	spec          workspace.ResourceTransformation
+	typePattern   *regexp.Regexp
+	renamePattern *regexp.Regexp
+}
+
+// transformer applies a list of declarative resource transformations, in order, to resources as they are registered.
+type transformer struct {
+	transformations []compiledTransformation
+
+	propagatedTags map[string]string                       // tags to apply to all taggable resources.
+	tagProperties  map[tokens.Package]resource.PropertyKey // the tag property for each taggable package.
+	taggableTypes  map[tokens.Type]bool                    // the types to tag, if the project lists them.
+
+	loadSchema schemaLoader                                // loads the schemas that say which types are taggable.
+	schemaLock sync.Mutex                                  // guards schemas, since resources register concurrently.
+	schemas    map[tokens.Package]*workspace.PackageSchema // the schemas loaded so far, by package.
+}
+
+// newTransformer compiles the given transformations. Tags, whether from the transformations or propagated, are only
+// applied to taggable resources; load loads the schemas that say which types are.
+func newTransformer(specs []workspace.ResourceTransformation, load schemaLoader) (*transformer, error) {
+	var result []compiledTransformation
+	for i, spec := range specs {
+		if err := spec.Validate(); err != nil {
+			return nil, errors.Wrapf(err, "transformation #%d", i)
+		}
+
+		t := compiledTransformation{spec: spec}
+		if spec.Type != "" {
+			t.typePattern = regexp.MustCompile(spec.Type)
+		}
+		if spec.Rename != nil {
+			t.renamePattern = regexp.MustCompile(spec.Rename.Pattern)
+		}
+		result = append(result, t)
+	}
+	return &transformer{
+		transformations: result,
+		tagProperties:   tagProperties(workspace.DefaultTagProperties),
+		loadSchema:      load,
+		schemas:         make(map[tokens.Package]*workspace.PackageSchema),
+	}, nil
+}
+
+// tagProperties returns the given map of package names to tag properties, keyed by package.
+func tagProperties(properties map[string]string) map[tokens.Package]resource.PropertyKey {
+	result := make(map[tokens.Package]resource.PropertyKey, len(properties))
+	for pkg, prop := range properties {
+		result[tokens.Package(pkg)] = resource.PropertyKey(prop)
+	}
+	return result
+}
+
+// setTagPropagation configures the transformer to apply the given tags to every taggable resource. The tag values are
+// expanded using the given replacer. A resource is taggable if its type is one the project lists or, if the project
+// lists none, if the schema its provider installs declares the tag property for its type.
+func (tr *transformer) setTagPropagation(cfg *workspace.TagPropagationConfig, r *strings.Replacer) {
+	if cfg == nil {
+		return
+	}
+
+	tags := cfg.Tags
+	if len(tags) == 0 {
+		tags = workspace.DefaultPropagatedTags
+	}
+	tr.propagatedTags = make(map[string]string, len(tags))
+	for k, v := range tags {
+		tr.propagatedTags[k] = r.Replace(v)
+	}
+	if len(cfg.Types) > 0 {
+		tr.taggableTypes = make(map[tokens.Type]bool, len(cfg.Types))
+		for _, t := range cfg.Types {
+			tr.taggableTypes[tokens.Type(t)] = true
+		}
+	}
+	if len(cfg.Properties) > 0 {
+		tr.tagProperties = tagProperties(cfg.Properties)
+	}
+}
+
+// apply applies all matching transformations to the given resource registration, returning its new name, properties,
+// and protect bit. The input property map is not modified. A renamed resource has a new URN, so the caller must alias
+// the resource's URN from before the rename for it not to be replaced.
+func (tr *transformer) apply(t tokens.Type, name tokens.QName, custom bool, props resource.PropertyMap,
+	protect bool) (tokens.QName, resource.PropertyMap, bool) {
+
+	if tr == nil {
+		return name, props, protect
+	}
+
+	// Provider resources are never transformed, since their properties are configuration rather than resource state,
+	// and neither is the root stack resource.
+	if providers.IsProviderType(t) || t == resource.RootStackType {
+		return name, props, protect
+	}
+
+	for _, xf := range tr.transformations {
+		if xf.typePattern != nil && !xf.typePattern.MatchString(string(t)) {
+			continue
+		}
+		if xf.renamePattern != nil {
+			name = tokens.QName(xf.renamePattern.ReplaceAllString(string(name), xf.spec.Rename.Replacement))
+		}
+		if xf.spec.Protect != nil {
+			protect = *xf.spec.Protect
+		}
+		if custom && len(xf.spec.Tags) > 0 {
+			props = tr.tag(t, props, xf.spec.Tags)
+		}
+	}
+
+	// Propagated tags are applied last, so that they are uniform across all resources.
+	if custom && len(tr.propagatedTags) > 0 {
+		props = tr.tag(t, props, tr.propagatedTags)
+	}
+	return name, props, protect
+}
+
+// tag merges the given tags into the tag property of a resource of the given type, if the resource is taggable.
+func (tr *transformer) tag(t tokens.Type, props resource.PropertyMap, tags map[string]string) resource.PropertyMap {
+	key, ok := tr.tagProperties[t.Package()]
+	if !ok || !tr.taggable(t, key) {
+		return props
+	}
+	if t.Package() == "gcp" {
+		tags = gcpLabels(tags)
+	}
+	return mergeTags(props, key, tags)
+}
+
+// taggable returns true if tags may be applied to resources of the given type, in the given property.
+// Providers reject properties their types do not declare, so without a list of types from the project, only types
+// whose schemas declare the property are tagged.
+func (tr *transformer) taggable(t tokens.Type, key resource.PropertyKey) bool {
+	if tr.taggableTypes != nil {
+		return tr.taggableTypes[t]
+	}
+	if tr.loadSchema == nil {
+		return false
+	}
+
+	tr.schemaLock.Lock()
+	defer tr.schemaLock.Unlock()
+	pkg := t.Package()
+	schema, has := tr.schemas[pkg]
+	if !has {
+		var err error
+		if schema, err = tr.loadSchema(pkg); err != nil {
+			logging.V(7).Infof("could not load the schema for package %s: %v", pkg, err)
+		}
+		tr.schemas[pkg] = schema
+	}
+	if schema == nil {
+		return false
+	}
+	_, has = schema.Resources[string(t)].InputProperties[string(key)]
+	return has
+}
+
+// gcpLabelLength is the maximum length of the keys and values of GCP labels.
+const gcpLabelLength = 63
+
+// gcpLabels returns the given tags as valid GCP labels. Label keys and values may only contain lowercase letters,
+// digits, underscores and dashes, and keys must start with a letter, so other characters are replaced with
+// underscores, e.g. `pulumi:stack` becomes `pulumi_stack`.
+func gcpLabels(tags map[string]string) map[string]string {
+	sanitize := func(s string) string {
+		s = strings.Map(func(r rune) rune {
+			switch {
+			case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '-':
+				return r
+			case r >= 'A' && r <= 'Z':
+				return r - 'A' + 'a'
+			default:
+				return '_'
+			}
+		}, s)
+		if len(s) > gcpLabelLength {
+			s = s[:gcpLabelLength]
+		}
+		return s
+	}
+
+	labels := make(map[string]string, len(tags))
+	for k, v := range tags {
+		key := sanitize(k)
+		if key == "" || key[0] < 'a' || key[0] > 'z' {
+			key = sanitize("l" + key)
+		}
+		labels[key] = sanitize(v)
+	}
+	return labels
+}
+
+// mergeTags returns a copy of the given properties with the given tags merged into the given tags property. If the
+// existing tags are not a known object, they are left untouched.
+func mergeTags(props resource.PropertyMap, key resource.PropertyKey, tags map[string]string) resource.PropertyMap {
+	var existing resource.PropertyMap
+	if v, has := props[key]; has && !v.IsNull() {
+		if !v.IsObject() {
+			return props
+		}
+		existing = v.ObjectValue()
+	}
+
+	merged := make(resource.PropertyMap, len(existing)+len(tags))
+	for k, v := range existing {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[resource.PropertyKey(k)] = resource.NewStringProperty(v)
+	}
+
+	result := make(resource.PropertyMap, len(props)+1)
+	for k, v := range props {
+		result[k] = v
+	}
+	result[key] = resource.NewObjectProperty(merged)
+	return result
+}
@@ -0,0 +1,142 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// loadTagSchemas returns a schema loader for installed schemas that declare tag properties for some types and not
+// others. There is no schema for azure.
+func loadTagSchemas() schemaLoader {
+	schemas := map[tokens.Package]*workspace.PackageSchema{
+		"aws": {Resources: map[string]workspace.ResourceSchema{
+			"aws:s3/bucket:Bucket": {InputProperties: map[string]workspace.PropertySchema{"tags": {Type: "object"}}},
+			"aws:s3/bucketPolicy:BucketPolicy": {InputProperties: map[string]workspace.PropertySchema{
+				"policy": {Type: "string"},
+			}},
+		}},
+		"gcp": {Resources: map[string]workspace.ResourceSchema{
+			"gcp:storage/bucket:Bucket": {InputProperties: map[string]workspace.PropertySchema{"labels": {Type: "object"}}},
+		}},
+	}
+	return func(pkg tokens.Package) (*workspace.PackageSchema, error) {
+		return schemas[pkg], nil
+	}
+}
+
+func TestTransformations(t *testing.T) {
+	protect := true
+	tr, err := newTransformer([]workspace.ResourceTransformation{
+		{Tags: map[string]string{"owner": "platform", "Cost Center": "R&D"}},
+		{Type: "^aws:s3/", Protect: &protect},
+		{Rename: &workspace.ResourceRename{Pattern: "^(.*)$", Replacement: "acme-$1"}},
+	}, loadTagSchemas())
+	assert.NoError(t, err)
+
+	props := resource.PropertyMap{
+		"tags": resource.NewObjectProperty(resource.PropertyMap{"env": resource.NewStringProperty("dev")}),
+	}
+	name, result, isProtected := tr.apply("aws:s3/bucket:Bucket", "logs", true, props, false)
+	assert.Equal(t, "acme-logs", string(name))
+	assert.True(t, isProtected)
+	tags := result["tags"].ObjectValue()
+	assert.Equal(t, "dev", tags["env"].StringValue())
+	assert.Equal(t, "platform", tags["owner"].StringValue())
+
+	// The original properties must not have been modified.
+	assert.Len(t, props["tags"].ObjectValue(), 1)
+
+	// Type filters are respected, components are not tagged, and providers are left alone.
+	name, result, isProtected = tr.apply("my:component:Thing", "c", false, resource.PropertyMap{}, false)
+	assert.Equal(t, "acme-c", string(name))
+	assert.False(t, isProtected)
+	assert.NotContains(t, result, resource.PropertyKey("tags"))
+
+	name, _, _ = tr.apply("pulumi:providers:aws", "default", true, resource.PropertyMap{}, false)
+	assert.Equal(t, "default", string(name))
+
+	// Tags are only applied to taggable types, in their package's tag property, and are made valid GCP labels.
+	_, result, _ = tr.apply("aws:s3/bucketPolicy:BucketPolicy", "p", true, resource.PropertyMap{}, false)
+	assert.Empty(t, result)
+	_, result, _ = tr.apply("gcp:storage/bucket:Bucket", "b", true, resource.PropertyMap{}, false)
+	assert.Equal(t, resource.PropertyMap{
+		"owner":       resource.NewStringProperty("platform"),
+		"cost_center": resource.NewStringProperty("r_d"),
+	}, result["labels"].ObjectValue())
+	assert.NotContains(t, result, resource.PropertyKey("tags"))
+
+	_, err = newTransformer([]workspace.ResourceTransformation{{Type: "("}}, nil)
+	assert.Error(t, err)
+}
+
+func TestTagPropagation(t *testing.T) {
+	tr, err := newTransformer(nil, loadTagSchemas())
+	assert.NoError(t, err)
+	tr.setTagPropagation(&workspace.TagPropagationConfig{
+		Tags: map[string]string{"pulumi:stack": "${project}/${stack}", "Pulumi:Commit": "${commit}"},
+	}, strings.NewReplacer("${project}", "proj", "${stack}", "Dev", "${commit}", "abc123"))
+
+	// Tags are merged into the provider-specific tag property.
+	props := resource.PropertyMap{
+		"tags": resource.NewObjectProperty(resource.PropertyMap{"env": resource.NewStringProperty("dev")}),
+	}
+	_, result, _ := tr.apply("aws:s3/bucket:Bucket", "logs", true, props, false)
+	tags := result["tags"].ObjectValue()
+	assert.Equal(t, "dev", tags["env"].StringValue())
+	assert.Equal(t, "proj/Dev", tags["pulumi:stack"].StringValue())
+	assert.Equal(t, "abc123", tags["Pulumi:Commit"].StringValue())
+
+	// GCP labels are made valid.
+	_, result, _ = tr.apply("gcp:storage/bucket:Bucket", "logs", true, resource.PropertyMap{}, false)
+	assert.Equal(t, resource.PropertyMap{
+		"pulumi_stack":  resource.NewStringProperty("proj_dev"),
+		"pulumi_commit": resource.NewStringProperty("abc123"),
+	}, result["labels"].ObjectValue())
+
+	// Types whose schemas do not declare the tag property, or that have no schema, are left alone, as are packages
+	// without a tag property, components, and providers.
+	for _, typ := range []tokens.Type{
+		"aws:s3/bucketPolicy:BucketPolicy", "aws:sqs/queue:Queue", "azure:core/resourceGroup:ResourceGroup",
+		"random:index/randomId:RandomId",
+	} {
+		_, result, _ = tr.apply(typ, "r", true, resource.PropertyMap{}, false)
+		assert.Empty(t, result, string(typ))
+	}
+	_, result, _ = tr.apply("aws:my:Component", "c", false, resource.PropertyMap{}, false)
+	assert.Empty(t, result)
+	_, result, _ = tr.apply("pulumi:providers:aws", "default", true, resource.PropertyMap{}, false)
+	assert.Empty(t, result)
+
+	// A project's list of types takes the place of the schemas, and by default the commit is not propagated.
+	tr, err = newTransformer(nil, loadTagSchemas())
+	assert.NoError(t, err)
+	tr.setTagPropagation(&workspace.TagPropagationConfig{Types: []string{"azure:core/resourceGroup:ResourceGroup"}},
+		strings.NewReplacer("${project}", "proj", "${stack}", "dev", "${commit}", "abc123"))
+	_, result, _ = tr.apply("azure:core/resourceGroup:ResourceGroup", "rg", true, resource.PropertyMap{}, false)
+	assert.Equal(t, resource.PropertyMap{
+		"pulumi:project": resource.NewStringProperty("proj"),
+		"pulumi:stack":   resource.NewStringProperty("dev"),
+	}, result["tags"].ObjectValue())
+	_, result, _ = tr.apply("aws:s3/bucket:Bucket", "logs", true, resource.PropertyMap{}, false)
+	assert.Empty(t, result)
+}
@@ -39,3 +39,45 @@ type ResourceProtectedError struct {
 func (ResourceProtectedError) Error() string {
 	return "Can't delete protected resource"
 }
+
+// ResourceLockedError is returned by DeleteResource if a resource is locked.
+type ResourceLockedError struct {
+	Condemned *resource.State
+}
+
+func (ResourceLockedError) Error() string {
+	return "Can't delete locked resource"
+}
+
+// ResourceDependencyNotMovedError is returned by MoveResources if a resource being moved depends on, or is parented
+// to, a resource that is neither being moved nor already present in the destination.
+type ResourceDependencyNotMovedError struct {
+	Moved      *resource.State
+	Dependency resource.URN
+}
+
+func (r ResourceDependencyNotMovedError) Error() string {
+	return fmt.Sprintf("Can't move resource %q because it depends on %q, which is not being moved",
+		r.Moved.URN, r.Dependency)
+}
+
+// ResourceAlreadyExistsError is returned by MoveResources if the destination already contains a resource with the
+// URN that a moved resource would have, and by ImportResource if the snapshot already contains the imported resource.
+type ResourceAlreadyExistsError struct {
+	URN resource.URN
+}
+
+func (r ResourceAlreadyExistsError) Error() string {
+	return fmt.Sprintf("Can't add resource: the state already contains %q", r.URN)
+}
+
+// ResourceReferenceNotFoundError is returned by ImportResource if the imported resource's parent, provider, or one of
+// its dependencies does not exist in the snapshot.
+type ResourceReferenceNotFoundError struct {
+	Imported  *resource.State
+	Reference resource.URN
+}
+
+func (r ResourceReferenceNotFoundError) Error() string {
+	return fmt.Sprintf("Can't import resource %q because %q does not exist in the state", r.Imported.URN, r.Reference)
+}
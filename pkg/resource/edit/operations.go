@@ -39,6 +39,9 @@ func DeleteResource(snapshot *deploy.Snapshot, condemnedRes *resource.State) err
 	if condemnedRes.Protect {
 		return ResourceProtectedError{condemnedRes}
 	}
+	if condemnedRes.Locked {
+		return ResourceLockedError{condemnedRes}
+	}
 
 	dg := graph.NewDependencyGraph(snapshot.Resources)
 	dependencies := dg.DependingOn(condemnedRes)
@@ -79,6 +82,62 @@ func UnprotectResource(_ *deploy.Snapshot, res *resource.State) error {
 	return nil
 }
 
+// LockResource locks a resource, so that updates may neither modify nor delete it.
+func LockResource(_ *deploy.Snapshot, res *resource.State) error {
+	res.Locked = true
+	return nil
+}
+
+// UnlockResource unlocks a resource.
+func UnlockResource(_ *deploy.Snapshot, res *resource.State) error {
+	res.Locked = false
+	return nil
+}
+
+// ImportResource adds a resource to the end of the snapshot. Its parent, provider, and dependencies must already exist
+// in the snapshot, and no resource with its URN may; otherwise, ImportResource returns an error instance of
+// `ResourceReferenceNotFoundError` or `ResourceAlreadyExistsError`, and the snapshot is left as it was.
+func ImportResource(snapshot *deploy.Snapshot, res *resource.State) error {
+	contract.Require(snapshot != nil, "snapshot")
+	contract.Require(res != nil, "res")
+
+	urns := make(map[resource.URN]bool)
+	providerRefs := make(map[string]bool)
+	for _, r := range snapshot.Resources {
+		if r.Delete {
+			continue
+		}
+		urns[r.URN] = true
+		if providers.IsProviderType(r.Type) {
+			ref, err := providers.NewReference(r.URN, r.ID)
+			contract.AssertNoErrorf(err, "failed to create provider reference from validated checkpoint")
+			providerRefs[ref.String()] = true
+		}
+	}
+
+	if urns[res.URN] {
+		return ResourceAlreadyExistsError{URN: res.URN}
+	}
+	if res.Parent != "" && !urns[res.Parent] {
+		return ResourceReferenceNotFoundError{Imported: res, Reference: res.Parent}
+	}
+	for _, dep := range res.Dependencies {
+		if !urns[dep] {
+			return ResourceReferenceNotFoundError{Imported: res, Reference: dep}
+		}
+	}
+	if res.Provider != "" && !providerRefs[res.Provider] {
+		ref, err := providers.ParseReference(res.Provider)
+		if err != nil {
+			return err
+		}
+		return ResourceReferenceNotFoundError{Imported: res, Reference: ref.URN()}
+	}
+
+	snapshot.Resources = append(snapshot.Resources, res)
+	return nil
+}
+
 // LocateResource returns all resources in the given shapshot that have the given URN.
 func LocateResource(snap *deploy.Snapshot, urn resource.URN) []*resource.State {
 	contract.Require(snap != nil, "snap")
@@ -152,3 +211,268 @@ func RenameStack(snap *deploy.Snapshot, newName tokens.QName) error {
 
 	return nil
 }
+
+// MoveResources moves a resource, along with every resource that descends from it, from one snapshot to another. The
+// moved resources' URNs, and their references to one another, are rewritten to name the destination stack.
+//
+// If includeDependents is true, every resource that depends on a moved resource is moved too; otherwise, the
+// existence of such a resource causes MoveResources to return an error instance of `ResourceHasDependenciesError`.
+// Every other resource that a moved resource depends on or is parented to must already exist in the destination, with
+// the exceptions of the source's root stack resource, which is replaced by the destination's, and of providers, which
+// are copied to the destination if it does not have them. Neither snapshot is modified if an error is returned.
+//
+// MoveResources returns the moved resources as they now appear in the destination.
+func MoveResources(source, dest *deploy.Snapshot, res *resource.State, destStack tokens.QName,
+	includeDependents bool) ([]*resource.State, error) {
+
+	contract.Require(source != nil, "source")
+	contract.Require(dest != nil, "dest")
+	contract.Require(res != nil, "res")
+
+	if res.Type == resource.RootStackType {
+		return nil, errors.New("Can't move a stack's root resource")
+	}
+
+	// Find the resources to move: the given resource, its descendants and, if requested, its dependents, in the order
+	// in which they appear in the source.
+	movedURNs := map[resource.URN]bool{res.URN: true}
+	dependsOnMoved := func(r *resource.State) bool {
+		for _, dep := range r.Dependencies {
+			if movedURNs[dep] {
+				return true
+			}
+		}
+		if r.Provider != "" {
+			ref, err := providers.ParseReference(r.Provider)
+			contract.AssertNoErrorf(err, "failed to parse provider reference from validated checkpoint")
+			return movedURNs[ref.URN()]
+		}
+		return false
+	}
+	var dependents []*resource.State
+	for changed := true; changed; {
+		changed, dependents = false, nil
+		for _, r := range source.Resources {
+			switch {
+			case movedURNs[r.URN]:
+			case r.Parent != "" && movedURNs[r.Parent]:
+				movedURNs[r.URN], changed = true, true
+			case dependsOnMoved(r):
+				if includeDependents {
+					movedURNs[r.URN], changed = true, true
+				} else {
+					dependents = append(dependents, r)
+				}
+			}
+		}
+	}
+	if len(dependents) != 0 {
+		return nil, ResourceHasDependenciesError{Condemned: res, Dependencies: dependents}
+	}
+
+	// Work out how the moved resources' URNs map into the destination. The destination's project is that of its
+	// existing resources, if it has any.
+	destProject := res.URN.Project()
+	destURNs := make(map[resource.URN]*resource.State)
+	var destRoot *resource.State
+	for _, r := range dest.Resources {
+		destProject = r.URN.Project()
+		destURNs[r.URN] = r
+		if r.Type == resource.RootStackType {
+			destRoot = r
+		}
+	}
+	destRootURN := resource.NewURN(destStack, destProject, "", resource.RootStackType,
+		tokens.QName(destProject)+"-"+destStack)
+	if destRoot != nil {
+		destRootURN = destRoot.URN
+	}
+	rewriteURN := func(u resource.URN) resource.URN {
+		if u.Type() == resource.RootStackType {
+			return destRootURN
+		}
+		return resource.NewURN(destStack, destProject, "", u.QualifiedType(), u.Name())
+	}
+
+	// Validate the move, and work out which providers must be copied, before making any changes.
+	var moved, remaining []*resource.State
+	copiedProviders := make(map[resource.URN]*resource.State)
+	var copiedProviderOrder []resource.URN
+	needsRoot := false
+	checkReference := func(r *resource.State, ref resource.URN) error {
+		switch {
+		case movedURNs[ref]:
+			return nil
+		case ref.Type() == resource.RootStackType:
+			needsRoot = needsRoot || destRoot == nil
+			return nil
+		case destURNs[rewriteURN(ref)] != nil:
+			return nil
+		default:
+			return ResourceDependencyNotMovedError{Moved: r, Dependency: ref}
+		}
+	}
+	for _, r := range source.Resources {
+		if !movedURNs[r.URN] {
+			remaining = append(remaining, r)
+			continue
+		}
+		moved = append(moved, r)
+
+		if destURNs[rewriteURN(r.URN)] != nil {
+			return nil, ResourceAlreadyExistsError{URN: rewriteURN(r.URN)}
+		}
+		if r.Parent != "" {
+			if err := checkReference(r, r.Parent); err != nil {
+				return nil, err
+			}
+		}
+		for _, dep := range r.Dependencies {
+			if err := checkReference(r, dep); err != nil {
+				return nil, err
+			}
+		}
+		if r.Provider != "" {
+			ref, err := providers.ParseReference(r.Provider)
+			contract.AssertNoErrorf(err, "failed to parse provider reference from validated checkpoint")
+			provURN := ref.URN()
+			if !movedURNs[provURN] && destURNs[rewriteURN(provURN)] == nil && copiedProviders[provURN] == nil {
+				for _, p := range source.Resources {
+					if p.URN == provURN && p.ID == ref.ID() {
+						copiedProviders[provURN] = p
+						copiedProviderOrder = append(copiedProviderOrder, provURN)
+						break
+					}
+				}
+				contract.Assertf(copiedProviders[provURN] != nil, "provider %v not found in validated checkpoint", ref)
+				if p := copiedProviders[provURN]; p.Parent != "" {
+					if err := checkReference(p, p.Parent); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+
+	rewriteState := func(r *resource.State) {
+		r.URN = rewriteURN(r.URN)
+		if r.Parent != "" {
+			r.Parent = rewriteURN(r.Parent)
+		}
+		for i, dep := range r.Dependencies {
+			r.Dependencies[i] = rewriteURN(dep)
+		}
+		for _, propDeps := range r.PropertyDependencies {
+			for i, dep := range propDeps {
+				propDeps[i] = rewriteURN(dep)
+			}
+		}
+		if r.Provider != "" {
+			ref, err := providers.ParseReference(r.Provider)
+			contract.AssertNoErrorf(err, "failed to parse provider reference from validated checkpoint")
+
+			// Prefer a provider that the destination already has.
+			provURN, provID := rewriteURN(ref.URN()), ref.ID()
+			if existing := destURNs[provURN]; existing != nil {
+				provID = existing.ID
+			}
+			ref, err = providers.NewReference(provURN, provID)
+			contract.AssertNoErrorf(err, "failed to generate provider reference from valid reference")
+			r.Provider = ref.String()
+		}
+	}
+
+	// The moves are valid: build the destination's new resource list. Any new root resource comes first, followed by
+	// the destination's existing resources, copies of the providers that the moved resources use, and the moved
+	// resources themselves.
+	var destResources []*resource.State
+	if needsRoot {
+		destResources = append(destResources, &resource.State{
+			Type:    resource.RootStackType,
+			URN:     destRootURN,
+			Inputs:  resource.PropertyMap{},
+			Outputs: resource.PropertyMap{},
+		})
+	}
+	destResources = append(destResources, dest.Resources...)
+	for _, provURN := range copiedProviderOrder {
+		// The copied provider's dependencies remain in the source, so they are not carried over; the next update of
+		// the destination records them afresh.
+		p := *copiedProviders[provURN]
+		p.Dependencies, p.PropertyDependencies = nil, nil
+		rewriteState(&p)
+		destResources = append(destResources, &p)
+	}
+	for _, r := range moved {
+		rewriteState(r)
+		destResources = append(destResources, r)
+	}
+
+	source.Resources = remaining
+	dest.Resources = destResources
+	return moved, nil
+}
+
+// RenameResource changes the name in the URN of the given resource, along with that of every other resource in the
+// snapshot with the same URN, such as one pending deletion after having been replaced. Every reference to the URN --
+// from children, dependents and, if the resource is a provider, the resources that use it -- is rewritten to match,
+// so that the resource can be given a new name in the program without being replaced. The URNs of the resource's
+// children do not include its name, and are not changed. The snapshot is not modified if an error is returned.
+func RenameResource(snap *deploy.Snapshot, res *resource.State, newName tokens.QName) error {
+	contract.Require(snap != nil, "snap")
+	contract.Require(res != nil, "res")
+
+	if res.Type == resource.RootStackType {
+		return errors.New("Can't rename a stack's root resource")
+	}
+	if newName == "" {
+		return errors.New("Can't rename a resource to an empty name")
+	}
+
+	oldURN := res.URN
+	newURN := resource.NewURN(oldURN.Stack(), oldURN.Project(), "", oldURN.QualifiedType(), newName)
+	if newURN == oldURN {
+		return nil
+	}
+	for _, r := range snap.Resources {
+		if r.URN == newURN {
+			return ResourceAlreadyExistsError{URN: newURN}
+		}
+	}
+
+	rewriteURN := func(u resource.URN) resource.URN {
+		if u == oldURN {
+			return newURN
+		}
+		return u
+	}
+	rewriteState := func(r *resource.State) {
+		r.URN = rewriteURN(r.URN)
+		r.Parent = rewriteURN(r.Parent)
+		for i, dep := range r.Dependencies {
+			r.Dependencies[i] = rewriteURN(dep)
+		}
+		for _, propDeps := range r.PropertyDependencies {
+			for i, dep := range propDeps {
+				propDeps[i] = rewriteURN(dep)
+			}
+		}
+		if r.Provider != "" {
+			ref, err := providers.ParseReference(r.Provider)
+			contract.AssertNoErrorf(err, "failed to parse provider reference from validated checkpoint")
+			if ref.URN() == oldURN {
+				ref, err = providers.NewReference(newURN, ref.ID())
+				contract.AssertNoErrorf(err, "failed to generate provider reference from valid reference")
+				r.Provider = ref.String()
+			}
+		}
+	}
+
+	for _, r := range snap.Resources {
+		rewriteState(r)
+	}
+	for _, op := range snap.PendingOperations {
+		rewriteState(op.Resource)
+	}
+	return nil
+}
@@ -205,6 +205,66 @@ func TestUnprotectResource(t *testing.T) {
 	assert.False(t, a.Protect)
 }
 
+func TestLockResource(t *testing.T) {
+	pA := NewProviderResource("a", "p1", "0")
+	a := NewResource("a", pA)
+	snap := NewSnapshot([]*resource.State{
+		pA,
+		a,
+	})
+
+	err := LockResource(snap, a)
+	assert.NoError(t, err)
+	assert.True(t, a.Locked)
+
+	err = DeleteResource(snap, a)
+	_, ok := err.(ResourceLockedError)
+	assert.True(t, ok)
+	assert.Len(t, snap.Resources, 2)
+
+	err = UnlockResource(snap, a)
+	assert.NoError(t, err)
+	assert.False(t, a.Locked)
+	assert.NoError(t, DeleteResource(snap, a))
+}
+
+func TestImportResource(t *testing.T) {
+	pA := NewProviderResource("a", "p1", "0")
+	a := NewResource("a", pA)
+	snap := NewSnapshot([]*resource.State{
+		pA,
+		a,
+	})
+
+	b := NewResource("b", pA, a.URN)
+	b.Parent = a.URN
+	b.External = true
+	assert.NoError(t, ImportResource(snap, b))
+	assert.Equal(t, []*resource.State{pA, a, b}, snap.Resources)
+	assert.NoError(t, snap.VerifyIntegrity())
+
+	// A resource that is already in the state can't be imported again.
+	err := ImportResource(snap, NewResource("b", pA))
+	_, ok := err.(ResourceAlreadyExistsError)
+	assert.True(t, ok)
+
+	// Nor can one whose parent, dependencies, or provider aren't in the state.
+	c := NewResource("c", pA)
+	c.Parent = NewResource("missing", nil).URN
+	err = ImportResource(snap, c)
+	_, ok = err.(ResourceReferenceNotFoundError)
+	assert.True(t, ok)
+
+	err = ImportResource(snap, NewResource("c", pA, NewResource("missing", nil).URN))
+	_, ok = err.(ResourceReferenceNotFoundError)
+	assert.True(t, ok)
+
+	err = ImportResource(snap, NewResource("c", NewProviderResource("a", "p1", "1")))
+	_, ok = err.(ResourceReferenceNotFoundError)
+	assert.True(t, ok)
+	assert.Len(t, snap.Resources, 3)
+}
+
 func TestLocateResourceNotFound(t *testing.T) {
 	pA := NewProviderResource("a", "p1", "0")
 	a := NewResource("a", pA)
@@ -260,3 +320,190 @@ func TestLocateResourceExact(t *testing.T) {
 	assert.Len(t, resList, 1)
 	assert.Contains(t, resList, a)
 }
+
+func TestMoveResource(t *testing.T) {
+	pA := NewProviderResource("a", "p1", "0")
+	a := NewResource("a", pA)
+	b := NewResource("b", pA, a.URN)
+	c := NewResource("c", pA)
+	source := NewSnapshot([]*resource.State{pA, a, b, c})
+	dest := NewSnapshot(nil)
+
+	moved, err := MoveResources(source, dest, c, "dest", false)
+	assert.NoError(t, err)
+	assert.Equal(t, []*resource.State{c}, moved)
+	assert.Equal(t, []*resource.State{pA, a, b}, source.Resources)
+	assert.NoError(t, source.VerifyIntegrity())
+
+	// The provider is copied to the destination, and the moved resource refers to the copy.
+	assert.Len(t, dest.Resources, 2)
+	assert.NoError(t, dest.VerifyIntegrity())
+	assert.Equal(t, resource.NewURN("dest", "test", "", pA.Type, "p1"), dest.Resources[0].URN)
+	assert.Equal(t, pA.ID, dest.Resources[0].ID)
+	assert.Equal(t, resource.NewURN("test", "test", "", pA.Type, "p1"), pA.URN)
+	assert.Equal(t, c, dest.Resources[1])
+	assert.Equal(t, resource.NewURN("dest", "test", "", c.Type, "c"), c.URN)
+	ref, err := providers.ParseReference(c.Provider)
+	assert.NoError(t, err)
+	assert.Equal(t, dest.Resources[0].URN, ref.URN())
+}
+
+func TestMoveResourceWithDependents(t *testing.T) {
+	pA := NewProviderResource("a", "p1", "0")
+	a := NewResource("a", pA)
+	b := NewResource("b", pA, a.URN)
+	c := NewResource("c", pA, b.URN)
+	d := NewResource("d", pA)
+	source := NewSnapshot([]*resource.State{pA, a, b, c, d})
+	dest := NewSnapshot(nil)
+
+	_, err := MoveResources(source, dest, a, "dest", false)
+	depErr, ok := err.(ResourceHasDependenciesError)
+	assert.True(t, ok)
+	assert.Equal(t, []*resource.State{b}, depErr.Dependencies)
+	assert.Equal(t, []*resource.State{pA, a, b, c, d}, source.Resources)
+	assert.Len(t, dest.Resources, 0)
+
+	moved, err := MoveResources(source, dest, a, "dest", true)
+	assert.NoError(t, err)
+	assert.Equal(t, []*resource.State{a, b, c}, moved)
+	assert.Equal(t, []*resource.State{pA, d}, source.Resources)
+	assert.NoError(t, dest.VerifyIntegrity())
+	assert.Equal(t, []resource.URN{a.URN}, b.Dependencies)
+	assert.Equal(t, []resource.URN{b.URN}, c.Dependencies)
+}
+
+func TestMoveResourceChildren(t *testing.T) {
+	rootType := resource.RootStackType
+	root := &resource.State{
+		Type: rootType,
+		URN:  resource.NewURN("test", "test", "", rootType, "test-test"),
+	}
+	compType := tokens.Type("a:b:Component")
+	comp := &resource.State{
+		Type:   compType,
+		URN:    resource.NewURN("test", "test", "", compType, "comp"),
+		Parent: root.URN,
+	}
+	child := &resource.State{
+		Type:   "a:b:c",
+		URN:    resource.NewURN("test", "test", compType, "a:b:c", "child"),
+		Parent: comp.URN,
+	}
+	other := &resource.State{
+		Type:   "a:b:c",
+		URN:    resource.NewURN("test", "test", "", "a:b:c", "other"),
+		Parent: root.URN,
+	}
+	source := NewSnapshot([]*resource.State{root, comp, child, other})
+	dest := NewSnapshot(nil)
+
+	moved, err := MoveResources(source, dest, comp, "dest", false)
+	assert.NoError(t, err)
+	assert.Equal(t, []*resource.State{comp, child}, moved)
+	assert.Equal(t, []*resource.State{root, other}, source.Resources)
+
+	// The destination gains a root stack resource to parent the moved component.
+	assert.NoError(t, dest.VerifyIntegrity())
+	assert.Len(t, dest.Resources, 3)
+	destRoot := dest.Resources[0]
+	assert.Equal(t, resource.NewURN("dest", "test", "", rootType, "test-dest"), destRoot.URN)
+	assert.Equal(t, destRoot.URN, comp.Parent)
+	assert.Equal(t, comp.URN, child.Parent)
+	assert.Equal(t, resource.NewURN("dest", "test", compType, "a:b:c", "child"), child.URN)
+}
+
+func TestMoveResourceMissingDependency(t *testing.T) {
+	pA := NewProviderResource("a", "p1", "0")
+	a := NewResource("a", pA)
+	b := NewResource("b", pA, a.URN)
+	source := NewSnapshot([]*resource.State{pA, a, b})
+	dest := NewSnapshot(nil)
+
+	_, err := MoveResources(source, dest, b, "dest", false)
+	depErr, ok := err.(ResourceDependencyNotMovedError)
+	assert.True(t, ok)
+	assert.Equal(t, a.URN, depErr.Dependency)
+	assert.Equal(t, []*resource.State{pA, a, b}, source.Resources)
+	assert.Equal(t, resource.NewURN("test", "test", "", b.Type, "b"), b.URN)
+	assert.Len(t, dest.Resources, 0)
+
+	// Once the dependency exists in the destination, the move succeeds and uses the destination's provider.
+	destProvider := NewProviderResource("a", "p1", "1")
+	destProvider.URN = resource.NewURN("dest", "test", "", destProvider.Type, "p1")
+	destA := NewResource("a", destProvider)
+	destA.URN = resource.NewURN("dest", "test", "", destA.Type, "a")
+	dest = NewSnapshot([]*resource.State{destProvider, destA})
+
+	_, err = MoveResources(source, dest, b, "dest", false)
+	assert.NoError(t, err)
+	assert.Equal(t, []*resource.State{destProvider, destA, b}, dest.Resources)
+	assert.NoError(t, dest.VerifyIntegrity())
+	ref, err := providers.ParseReference(b.Provider)
+	assert.NoError(t, err)
+	assert.Equal(t, resource.ID("1"), ref.ID())
+}
+
+func TestMoveResourceAlreadyExists(t *testing.T) {
+	pA := NewProviderResource("a", "p1", "0")
+	a := NewResource("a", pA)
+	source := NewSnapshot([]*resource.State{pA, a})
+
+	destA := NewResource("a", nil)
+	destA.URN = resource.NewURN("dest", "test", "", destA.Type, "a")
+	dest := NewSnapshot([]*resource.State{destA})
+
+	_, err := MoveResources(source, dest, a, "dest", false)
+	_, ok := err.(ResourceAlreadyExistsError)
+	assert.True(t, ok)
+	assert.Equal(t, []*resource.State{pA, a}, source.Resources)
+	assert.Equal(t, []*resource.State{destA}, dest.Resources)
+}
+
+func TestRenameResource(t *testing.T) {
+	pA := NewProviderResource("a", "p1", "0")
+	a := NewResource("a", pA)
+	b := NewResource("b", pA, a.URN)
+	b.PropertyDependencies = map[resource.PropertyKey][]resource.URN{"input": {a.URN}}
+	c := NewResource("c", pA)
+	c.Parent = a.URN
+	snap := NewSnapshot([]*resource.State{pA, a, b, c})
+
+	err := RenameResource(snap, a, "renamed")
+	assert.NoError(t, err)
+	assert.NoError(t, snap.VerifyIntegrity())
+	renamed := resource.NewURN("test", "test", "", a.Type, "renamed")
+	assert.Equal(t, renamed, a.URN)
+	assert.Equal(t, []resource.URN{renamed}, b.Dependencies)
+	assert.Equal(t, []resource.URN{renamed}, b.PropertyDependencies["input"])
+	assert.Equal(t, renamed, c.Parent)
+
+	// Renaming a provider rewrites the references of the resources that use it.
+	err = RenameResource(snap, pA, "p2")
+	assert.NoError(t, err)
+	assert.NoError(t, snap.VerifyIntegrity())
+	for _, r := range []*resource.State{a, b, c} {
+		ref, err := providers.ParseReference(r.Provider)
+		assert.NoError(t, err)
+		assert.Equal(t, pA.URN, ref.URN())
+		assert.Equal(t, resource.ID("0"), ref.ID())
+	}
+}
+
+func TestRenameResourceErrors(t *testing.T) {
+	pA := NewProviderResource("a", "p1", "0")
+	a := NewResource("a", pA)
+	b := NewResource("b", pA)
+	root := &resource.State{
+		Type: resource.RootStackType,
+		URN:  resource.NewURN("test", "test", "", resource.RootStackType, "test-test"),
+	}
+	snap := NewSnapshot([]*resource.State{root, pA, a, b})
+
+	err := RenameResource(snap, a, "b")
+	assert.Equal(t, ResourceAlreadyExistsError{URN: b.URN}, err)
+	assert.Equal(t, resource.NewURN("test", "test", "", a.Type, "a"), a.URN)
+
+	assert.Error(t, RenameResource(snap, root, "other"))
+	assert.Error(t, RenameResource(snap, a, ""))
+}
@@ -31,8 +31,9 @@ type Analyzer interface {
 	io.Closer
 	// Name fetches an analyzer's qualified name.
 	Name() tokens.QName
-	// Analyze analyzes a single resource object, and returns any errors that it finds.
-	Analyze(t tokens.Type, props resource.PropertyMap) ([]AnalyzeDiagnostic, error)
+	// Analyze analyzes a single resource object, and returns any errors that it finds, along with any remediations
+	// its policies made to the resource's properties.
+	Analyze(t tokens.Type, props resource.PropertyMap) ([]AnalyzeDiagnostic, []Remediation, error)
 	// GetAnalyzerInfo returns metadata about the analyzer (e.g., list of policies contained).
 	GetAnalyzerInfo() (AnalyzerInfo, error)
 	// GetPluginInfo returns this plugin's information.
@@ -51,6 +52,15 @@ type AnalyzeDiagnostic struct {
 	EnforcementLevel  apitype.EnforcementLevel
 }
 
+// Remediation is a modification of a resource's properties, made by a policy so that the resource complies with it.
+type Remediation struct {
+	PolicyName        string
+	PolicyPackName    string
+	PolicyPackVersion string
+	Description       string
+	Properties        resource.PropertyMap // the resource's full properties after the remediation.
+}
+
 // AnalyzerInfo provides metadata about a PolicyPack inside an analyzer.
 type AnalyzerInfo struct {
 	Name        string
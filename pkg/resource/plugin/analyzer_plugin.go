@@ -57,7 +57,7 @@ func NewAnalyzer(host Host, ctx *Context, name tokens.QName) (Analyzer, error) {
 	}
 
 	plug, err := newPlugin(ctx, path, fmt.Sprintf("%v (analyzer)", name),
-		[]string{host.ServerAddr(), ctx.Pwd})
+		[]string{host.ServerAddr(), ctx.Pwd}, nil /*sandbox*/, nil /*network*/)
 	if err != nil {
 		return nil, err
 	}
@@ -90,7 +90,7 @@ func NewPolicyAnalyzer(
 	}
 
 	plug, err := newPlugin(ctx, pluginPath, fmt.Sprintf("%v (analyzer)", name),
-		[]string{host.ServerAddr(), policyPackPath})
+		[]string{host.ServerAddr(), policyPackPath}, nil /*sandbox*/, nil /*network*/)
 	if err != nil {
 		return nil, errors.Wrapf(err,
 			"policy pack %s failed to start because of an internal error", string(name))
@@ -112,15 +112,16 @@ func (a *analyzer) label() string {
 	return fmt.Sprintf("Analyzer[%s]", a.name)
 }
 
-// Analyze analyzes a single resource object, and returns any errors that it finds.
+// Analyze analyzes a single resource object, and returns any errors that it finds, along with any remediations its
+// policies made to the resource's properties.
 func (a *analyzer) Analyze(
-	t tokens.Type, props resource.PropertyMap) ([]AnalyzeDiagnostic, error) {
+	t tokens.Type, props resource.PropertyMap) ([]AnalyzeDiagnostic, []Remediation, error) {
 
 	label := fmt.Sprintf("%s.Analyze(%s)", a.label(), t)
 	logging.V(7).Infof("%s executing (#props=%d)", label, len(props))
 	mprops, err := MarshalProperties(props, MarshalOptions{KeepUnknowns: true})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	resp, err := a.client.Analyze(a.ctx.Request(), &pulumirpc.AnalyzeRequest{
@@ -130,17 +131,17 @@ func (a *analyzer) Analyze(
 	if err != nil {
 		rpcError := rpcerror.Convert(err)
 		logging.V(7).Infof("%s failed: err=%v", label, rpcError)
-		return nil, rpcError
+		return nil, nil, rpcError
 	}
 
-	failures := resp.GetDiagnostics()
-	logging.V(7).Infof("%s success: failures=#%d", label, len(failures))
+	failures, remediations := resp.GetDiagnostics(), resp.GetRemediations()
+	logging.V(7).Infof("%s success: failures=#%d remediations=#%d", label, len(failures), len(remediations))
 
 	diags := []AnalyzeDiagnostic{}
 	for _, failure := range failures {
 		enforcementLevel, err := convertEnforcementLevel(failure.EnforcementLevel)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		diags = append(diags, AnalyzeDiagnostic{
@@ -154,7 +155,23 @@ func (a *analyzer) Analyze(
 		})
 	}
 
-	return diags, nil
+	var rems []Remediation
+	for _, r := range remediations {
+		rprops, err := UnmarshalProperties(r.GetProperties(), MarshalOptions{KeepUnknowns: true})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		rems = append(rems, Remediation{
+			PolicyName:        r.PolicyName,
+			PolicyPackName:    r.PolicyPackName,
+			PolicyPackVersion: r.PolicyPackVersion,
+			Description:       r.Description,
+			Properties:        rprops,
+		})
+	}
+
+	return diags, rems, nil
 }
 
 // GetAnalyzerInfo returns metadata about the policies contained in this analyzer plugin.
@@ -20,6 +20,7 @@ import (
 	"github.com/opentracing/opentracing-go"
 
 	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/rpcutil"
 )
 
@@ -31,6 +32,11 @@ type Context struct {
 	Host       Host      // the host that can be used to fetch providers.
 	Pwd        string    // the working directory to spawn all plugins in.
 
+	Sandbox              *SandboxOptions              // optional restrictions to apply to the language host.
+	StrictPreview        bool                         // true if providers must reject all non-read operations.
+	StrictPreviewInvokes map[tokens.ModuleMember]bool // the provider functions a strict preview permits.
+	Mock                 *MockOptions                 // if non-nil, synthetic providers replace every resource provider.
+
 	tracingSpan opentracing.Span // the OpenTracing span to parent requests within.
 }
 
@@ -248,8 +248,14 @@ func (host *defaultHost) ListAnalyzers() []Analyzer {
 
 func (host *defaultHost) Provider(pkg tokens.Package, version *semver.Version) (Provider, error) {
 	plugin, err := host.loadPlugin(func() (interface{}, error) {
-		// Try to load and bind to a plugin.
-		plug, err := NewProvider(host, host.ctx, pkg, version)
+		// Try to load and bind to a plugin, unless synthetic providers are to stand in for all of them.
+		var plug Provider
+		var err error
+		if host.ctx.Mock != nil {
+			plug = NewMockProvider(pkg, *host.ctx.Mock)
+		} else {
+			plug, err = NewProvider(host, host.ctx, pkg, version)
+		}
 		if err == nil && plug != nil {
 			info, infoerr := plug.GetPluginInfo()
 			if infoerr != nil {
@@ -55,14 +55,30 @@ func NewLanguageRuntime(host Host, ctx *Context, runtime string,
 		})
 	}
 
+	// A language host without network access reaches the engine through a sandbox network.
+	network, err := newSandboxNetwork(ctx.Sandbox)
+	if err != nil {
+		return nil, err
+	}
+	engineAddr := host.ServerAddr()
+	if network != nil {
+		if engineAddr, err = network.forward(engineAddr); err != nil {
+			contract.IgnoreClose(network)
+			return nil, err
+		}
+	}
+
 	var args []string
 	for k, v := range options {
 		args = append(args, fmt.Sprintf("-%s=%t", k, v))
 	}
-	args = append(args, host.ServerAddr())
+	args = append(args, engineAddr)
 
-	plug, err := newPlugin(ctx, path, runtime, args)
+	plug, err := newPlugin(ctx, path, runtime, args, ctx.Sandbox, network)
 	if err != nil {
+		if network != nil {
+			contract.IgnoreClose(network)
+		}
 		return nil, err
 	}
 	contract.Assertf(plug != nil, "unexpected nil language plugin for %s", runtime)
@@ -139,8 +155,16 @@ func (h *langhost) Run(info RunInfo) (string, bool, error) {
 	for k, v := range info.Config {
 		config[k.String()] = v
 	}
+	monitorAddr := info.MonitorAddress
+	if network := h.plug.network; network != nil {
+		forwarded, err := network.forward(monitorAddr)
+		if err != nil {
+			return "", false, err
+		}
+		monitorAddr = forwarded
+	}
 	resp, err := h.client.Run(h.ctx.Request(), &pulumirpc.RunRequest{
-		MonitorAddress: info.MonitorAddress,
+		MonitorAddress: monitorAddr,
 		Pwd:            info.Pwd,
 		Program:        info.Program,
 		Args:           info.Args,
@@ -0,0 +1,206 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// MockOptions configures the synthetic providers that stand in for every resource provider in mock mode, which
+// simulates the lifecycle of resources without contacting any cloud.
+type MockOptions struct {
+	// Latency is the average duration of a simulated create, update, or delete. Each operation takes between half and
+	// one and a half times as long.
+	Latency time.Duration
+	// FailureRate is the probability, between 0 and 1, that a simulated create, update, or delete fails.
+	FailureRate float64
+	// Fail lists the names and types of resources whose creates, updates, and deletes always fail.
+	Fail []string
+}
+
+// mockProvider is a synthetic provider that accepts any configuration and inputs, and simulates operations on its
+// resources by echoing their inputs back as their outputs after a delay.
+type mockProvider struct {
+	pkg    tokens.Package
+	opts   MockOptions
+	cancel chan struct{}
+
+	lock   sync.Mutex
+	random *rand.Rand
+}
+
+// NewMockProvider returns a synthetic provider for the given package, which simulates operations on its resources as
+// configured by the given options.
+func NewMockProvider(pkg tokens.Package, opts MockOptions) Provider {
+	return &mockProvider{
+		pkg:    pkg,
+		opts:   opts,
+		cancel: make(chan struct{}),
+		random: rand.New(rand.NewSource(time.Now().UnixNano())), // nolint: gosec
+	}
+}
+
+func (p *mockProvider) Close() error {
+	return nil
+}
+
+func (p *mockProvider) Pkg() tokens.Package {
+	return p.pkg
+}
+
+func (p *mockProvider) CheckConfig(urn resource.URN, olds, news resource.PropertyMap,
+	allowUnknowns bool) (resource.PropertyMap, []CheckFailure, error) {
+	return news, nil, nil
+}
+
+func (p *mockProvider) DiffConfig(urn resource.URN, olds, news resource.PropertyMap, allowUnknowns bool,
+	ignoreChanges []string) (DiffResult, error) {
+	return DiffResult{Changes: DiffNone}, nil
+}
+
+func (p *mockProvider) Configure(inputs resource.PropertyMap) error {
+	return nil
+}
+
+func (p *mockProvider) Check(urn resource.URN, olds, news resource.PropertyMap,
+	allowUnknowns bool) (resource.PropertyMap, []CheckFailure, error) {
+	return news, nil, nil
+}
+
+// Diff reports the inputs that changed, which are updated in place.
+func (p *mockProvider) Diff(urn resource.URN, id resource.ID, olds resource.PropertyMap, news resource.PropertyMap,
+	allowUnknowns bool, ignoreChanges []string) (DiffResult, error) {
+
+	diff := olds.Diff(news)
+	if diff == nil {
+		return DiffResult{Changes: DiffNone}, nil
+	}
+	var changed []resource.PropertyKey
+	for _, k := range diff.Keys() {
+		if diff.Changed(k) {
+			changed = append(changed, k)
+		}
+	}
+	if len(changed) == 0 {
+		return DiffResult{Changes: DiffNone}, nil
+	}
+	return DiffResult{Changes: DiffSome, ChangedKeys: changed}, nil
+}
+
+func (p *mockProvider) Create(urn resource.URN, news resource.PropertyMap, timeout float64) (resource.ID,
+	resource.PropertyMap, resource.Status, error) {
+
+	if err := p.simulate(urn, "create"); err != nil {
+		return "", nil, resource.StatusOK, err
+	}
+	p.lock.Lock()
+	id := resource.ID(fmt.Sprintf("%s-%08x", urn.Name(), p.random.Uint32()))
+	p.lock.Unlock()
+	return id, news, resource.StatusOK, nil
+}
+
+// Read reports that resources are exactly as they were last left, so that refreshes find no drift.
+func (p *mockProvider) Read(urn resource.URN, id resource.ID,
+	inputs, state resource.PropertyMap) (ReadResult, resource.Status, error) {
+
+	if inputs == nil {
+		inputs = resource.PropertyMap{}
+	}
+	if state == nil {
+		state = inputs
+	}
+	return ReadResult{ID: id, Inputs: inputs, Outputs: state}, resource.StatusOK, nil
+}
+
+func (p *mockProvider) Update(urn resource.URN, id resource.ID,
+	olds resource.PropertyMap, news resource.PropertyMap, timeout float64,
+	ignoreChanges []string) (resource.PropertyMap, resource.Status, error) {
+
+	if err := p.simulate(urn, "update"); err != nil {
+		return nil, resource.StatusOK, err
+	}
+	return news, resource.StatusOK, nil
+}
+
+func (p *mockProvider) Delete(urn resource.URN, id resource.ID, props resource.PropertyMap,
+	timeout float64) (resource.Status, error) {
+
+	if err := p.simulate(urn, "delete"); err != nil {
+		return resource.StatusOK, err
+	}
+	return resource.StatusOK, nil
+}
+
+// Invoke returns no results for any function, since there is no cloud to query.
+func (p *mockProvider) Invoke(tok tokens.ModuleMember,
+	args resource.PropertyMap) (resource.PropertyMap, []CheckFailure, error) {
+	return resource.PropertyMap{}, nil, nil
+}
+
+// GetLogs returns no logs, since simulated resources do not write any.
+func (p *mockProvider) GetLogs(query LogQuery) (*[]LogEntry, error) {
+	return nil, nil
+}
+
+func (p *mockProvider) GetPluginInfo() (workspace.PluginInfo, error) {
+	return workspace.PluginInfo{
+		Name: string(p.pkg),
+		Kind: workspace.ResourcePlugin,
+	}, nil
+}
+
+func (p *mockProvider) SignalCancellation() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	select {
+	case <-p.cancel:
+	default:
+		close(p.cancel)
+	}
+	return nil
+}
+
+// simulate waits for the duration of a simulated operation on the given resource, and returns an error if the
+// operation is to fail.
+func (p *mockProvider) simulate(urn resource.URN, op string) error {
+	p.lock.Lock()
+	latency := time.Duration(float64(p.opts.Latency) * (0.5 + p.random.Float64()))
+	fail := p.opts.FailureRate > 0 && p.random.Float64() < p.opts.FailureRate
+	p.lock.Unlock()
+	for _, f := range p.opts.Fail {
+		if f == string(urn.Name()) || f == string(urn.Type()) {
+			fail = true
+		}
+	}
+
+	select {
+	case <-time.After(latency):
+	case <-p.cancel:
+		return errors.Errorf("simulated %s of %s was canceled", op, urn.Name())
+	}
+	if fail {
+		return errors.Errorf("simulated failure to %s %s", op, urn.Name())
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+)
+
+func TestMockProvider(t *testing.T) {
+	urn := resource.NewURN("dev", "proj", "", "aws:s3/bucket:Bucket", "logs")
+	inputs := resource.PropertyMap{"acl": resource.NewStringProperty("private")}
+
+	// Resources are created with their inputs as their outputs, and changes to their inputs are updated in place.
+	p := NewMockProvider("aws", MockOptions{})
+	id, outs, _, err := p.Create(urn, inputs, 0)
+	assert.NoError(t, err)
+	assert.Contains(t, string(id), "logs-")
+	assert.Equal(t, inputs, outs)
+
+	news := resource.PropertyMap{"acl": resource.NewStringProperty("public-read")}
+	diff, err := p.Diff(urn, id, inputs, news, false, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, DiffSome, diff.Changes)
+	assert.Equal(t, []resource.PropertyKey{"acl"}, diff.ChangedKeys)
+	assert.False(t, diff.Replace())
+	diff, err = p.Diff(urn, id, inputs, inputs, false, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, DiffNone, diff.Changes)
+
+	read, _, err := p.Read(urn, id, inputs, outs)
+	assert.NoError(t, err)
+	assert.Equal(t, outs, read.Outputs)
+
+	// Operations on the named resources or types always fail, as do all of them at a failure rate of 1.
+	p = NewMockProvider("aws", MockOptions{Fail: []string{"aws:s3/bucket:Bucket"}})
+	_, _, _, err = p.Create(urn, inputs, 0)
+	assert.Error(t, err)
+	p = NewMockProvider("aws", MockOptions{FailureRate: 1})
+	_, err = p.Delete(urn, id, inputs, 0)
+	assert.Error(t, err)
+
+	// Simulated operations take time, unless they are canceled.
+	p = NewMockProvider("aws", MockOptions{Latency: time.Hour})
+	assert.NoError(t, p.SignalCancellation())
+	_, _, err = p.Update(urn, id, inputs, news, 0, nil)
+	assert.Error(t, err)
+}
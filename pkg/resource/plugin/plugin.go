@@ -35,6 +35,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/diag"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/fsutil"
 	"github.com/pulumi/pulumi/pkg/util/logging"
 	"github.com/pulumi/pulumi/pkg/util/rpcutil"
 )
@@ -50,6 +51,8 @@ type plugin struct {
 	Stdin  io.WriteCloser
 	Stdout io.ReadCloser
 	Stderr io.ReadCloser
+
+	network *sandboxNetwork // the network over which a sandboxed plugin reaches the engine, if any.
 }
 
 // pluginRPCConnectionTimeout dictates how long we wait for the plugin's RPC to become available.
@@ -60,7 +63,13 @@ var pluginRPCConnectionTimeout = time.Second * 10
 // time.
 var nextStreamID int32
 
-func newPlugin(ctx *Context, bin string, prefix string, args []string) (*plugin, error) {
+// StrictPreviewEnvVar is set in the environment of every plugin launched for a strict preview, so that plugins which
+// run user code, such as dynamic providers, know to refuse anything but reads.
+const StrictPreviewEnvVar = "PULUMI_STRICT_PREVIEW"
+
+func newPlugin(ctx *Context, bin string, prefix string, args []string, sandbox *SandboxOptions,
+	network *sandboxNetwork) (*plugin, error) {
+
 	if logging.V(9) {
 		var argstr string
 		for i, arg := range args {
@@ -72,8 +81,13 @@ func newPlugin(ctx *Context, bin string, prefix string, args []string) (*plugin,
 		logging.V(9).Infof("Launching plugin '%v' from '%v' with args: %v", prefix, bin, argstr)
 	}
 
+	var env []string
+	if ctx.StrictPreview {
+		env = append(env, StrictPreviewEnvVar+"=true")
+	}
+
 	// Try to execute the binary.
-	plug, err := execPlugin(bin, args, ctx.Pwd)
+	plug, err := execPlugin(bin, args, ctx.Pwd, env, sandbox, network)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to load plugin %s", bin)
 	}
@@ -137,20 +151,34 @@ func newPlugin(ctx *Context, bin string, prefix string, args []string) (*plugin,
 	}
 
 	// Parse the output line (minus the '\n') to ensure it's a numeric port.
-	if _, err = strconv.Atoi(port); err != nil {
+	portNum, err := strconv.Atoi(port)
+	if err != nil {
 		killerr := plug.Proc.Kill()
 		contract.IgnoreError(killerr) // ignoring the error because the existing one trumps it.
 		return nil, errors.Wrapf(
 			err, "%v plugin [%v] wrote a non-numeric port to stdout ('%v')", prefix, bin, port)
 	}
 
+	// A plugin in a sandbox network serves on the Unix socket it was asked to, and reports a port of 0. Older plugins
+	// that listen on a TCP port regardless cannot be reached from outside their network namespace.
+	addr := "127.0.0.1:" + port
+	if network != nil {
+		if portNum != 0 {
+			killerr := plug.Proc.Kill()
+			contract.IgnoreError(killerr) // ignoring the error because the existing one trumps it.
+			return nil, errors.Errorf("%v plugin [%v] does not support running without network access; "+
+				"it must serve on the Unix socket named by %s", prefix, bin, rpcutil.SocketEnvVar)
+		}
+		addr = rpcutil.UnixSocketScheme + network.hostSocket()
+	}
+
 	// After reading the port number, set up a tracer on stdout just so other output doesn't disappear.
 	stdoutDone := make(chan bool)
 	plug.stdoutDone = stdoutDone
 	go runtrace(plug.Stdout, false, stdoutDone)
 
 	// Now that we have the port, go ahead and create a gRPC client connection to it.
-	conn, err := grpc.Dial("127.0.0.1:"+port, grpc.WithInsecure(), grpc.WithUnaryInterceptor(
+	conn, err := grpc.Dial(addr, grpc.WithInsecure(), rpcutil.WithUnixSockets(), grpc.WithUnaryInterceptor(
 		rpcutil.OpenTracingClientInterceptor(),
 	))
 	if err != nil {
@@ -204,15 +232,16 @@ func newPlugin(ctx *Context, bin string, prefix string, args []string) (*plugin,
 	return plug, nil
 }
 
-func execPlugin(bin string, pluginArgs []string, pwd string) (*plugin, error) {
+func execPlugin(bin string, pluginArgs []string, pwd string, env []string,
+	sandbox *SandboxOptions, network *sandboxNetwork) (*plugin, error) {
 	var args []string
 	// Flow the logging information if set.
 	if logging.LogFlow {
 		if logging.LogToStderr {
 			args = append(args, "-logtostderr")
 		}
-		if logging.Verbose > 0 {
-			args = append(args, "-v="+strconv.Itoa(logging.Verbose))
+		if verbose := logging.Verbosity(); verbose > 0 {
+			args = append(args, "-v="+strconv.Itoa(verbose))
 		}
 	}
 	// Always flow tracing settings.
@@ -221,9 +250,18 @@ func execPlugin(bin string, pluginArgs []string, pwd string) (*plugin, error) {
 	}
 	args = append(args, pluginArgs...)
 
-	cmd := exec.Command(bin, args...)
+	cmd := exec.Command(fsutil.LongPath(bin), args...)
 	cmdutil.RegisterProcessGroup(cmd)
 	cmd.Dir = pwd
+	if err := sandbox.apply(cmd, network); err != nil {
+		return nil, errors.Wrap(err, "configuring sandbox")
+	}
+	if len(env) > 0 {
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, env...)
+	}
 	in, _ := cmd.StdinPipe()
 	out, _ := cmd.StdoutPipe()
 	err, _ := cmd.StderrPipe()
@@ -232,12 +270,13 @@ func execPlugin(bin string, pluginArgs []string, pwd string) (*plugin, error) {
 	}
 
 	return &plugin{
-		Bin:    bin,
-		Args:   args,
-		Proc:   cmd.Process,
-		Stdin:  in,
-		Stdout: out,
-		Stderr: err,
+		Bin:     bin,
+		Args:    args,
+		Proc:    cmd.Process,
+		Stdin:   in,
+		Stdout:  out,
+		Stderr:  err,
+		network: network,
 	}, nil
 }
 
@@ -269,5 +308,11 @@ func (p *plugin) Close() error {
 		<-p.stderrDone
 	}
 
+	if p.network != nil {
+		if err := p.network.Close(); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
 	return result
 }
@@ -16,6 +16,7 @@ package plugin
 
 import (
 	"io"
+	"time"
 
 	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/tokens"
@@ -71,6 +72,9 @@ type Provider interface {
 	Delete(urn resource.URN, id resource.ID, props resource.PropertyMap, timeout float64) (resource.Status, error)
 	// Invoke dynamically executes a built-in function in the provider.
 	Invoke(tok tokens.ModuleMember, args resource.PropertyMap) (resource.PropertyMap, []CheckFailure, error)
+	// GetLogs returns the log entries written by the resources in the query. If the provider does not support logs,
+	// the result is nil.
+	GetLogs(query LogQuery) (*[]LogEntry, error)
 	// GetPluginInfo returns this plugin's information.
 	GetPluginInfo() (workspace.PluginInfo, error)
 
@@ -88,6 +92,28 @@ type CheckFailure struct {
 	Reason   string               // the reason the property failed to check.
 }
 
+// LogQuery asks a provider for the log entries written by some of its resources.
+type LogQuery struct {
+	Resources []LogResource // the resources whose log entries to return.
+	StartTime *time.Time    // if non-nil, only entries written at or after this time are returned.
+	EndTime   *time.Time    // if non-nil, only entries written before this time are returned.
+}
+
+// LogResource identifies a resource whose log entries are requested.
+type LogResource struct {
+	URN     resource.URN         // the resource's URN.
+	ID      resource.ID          // the resource's ID.
+	Outputs resource.PropertyMap // the resource's current properties.
+}
+
+// LogEntry is a single entry in the logs of a resource.
+type LogEntry struct {
+	URN       resource.URN // the resource that wrote the entry.
+	ID        string       // the source of the entry within the resource, such as a function or container.
+	Timestamp int64        // the Unix time at which the entry was written, in milliseconds.
+	Message   string       // the text of the entry.
+}
+
 // DiffChanges represents the kind of changes detected by a diff operation.
 type DiffChanges int
 
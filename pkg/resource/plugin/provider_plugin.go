@@ -17,6 +17,7 @@ package plugin
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/blang/semver"
 	pbempty "github.com/golang/protobuf/ptypes/empty"
@@ -25,6 +26,7 @@ import (
 	"github.com/pkg/errors"
 	"google.golang.org/grpc/codes"
 
+	"github.com/pulumi/pulumi/pkg/diag"
 	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/contract"
@@ -76,7 +78,8 @@ func NewProvider(host Host, ctx *Context, pkg tokens.Package, version *semver.Ve
 		})
 	}
 
-	plug, err := newPlugin(ctx, path, fmt.Sprintf("%v (resource)", pkg), []string{host.ServerAddr()})
+	plug, err := newPlugin(ctx, path, fmt.Sprintf("%v (resource)", pkg),
+		[]string{host.ServerAddr()}, nil /*sandbox*/, nil /*network*/)
 	if err != nil {
 		return nil, err
 	}
@@ -325,6 +328,36 @@ func (p *provider) getClient() (pulumirpc.ResourceProviderClient, error) {
 	return p.clientRaw, nil
 }
 
+// checkStrictPreview rejects and reports the given provider operation, which may change resources, if the engine is
+// running a strict preview. Providers are told about a strict preview by StrictPreviewEnvVar, but the engine does not
+// rely on them honoring it: operations that could change resources are never forwarded to the plugin during a strict
+// preview.
+func (p *provider) checkStrictPreview(urn resource.URN, op string) error {
+	if !p.ctx.StrictPreview {
+		return nil
+	}
+	p.ctx.Diag.Errorf(diag.GetStrictPreviewWriteError(urn), p.label(), op)
+	return errors.Errorf("%s: %s is not permitted during a strict preview", p.label(), op)
+}
+
+// checkStrictInvoke rejects and reports the given provider function if the engine is running a strict preview that
+// does not permit it.
+func (p *provider) checkStrictInvoke(tok tokens.ModuleMember) error {
+	if !p.ctx.StrictPreview || p.ctx.StrictPreviewInvokes[tok] {
+		return nil
+	}
+	op := fmt.Sprintf("Invoke(%s)", tok)
+	p.ctx.Diag.Errorf(diag.GetStrictPreviewInvokeError(""), p.label(), op)
+	return errors.Errorf("%s: %s is not permitted during a strict preview", p.label(), op)
+}
+
+// strictConfigure returns true if the provider may be configured during a strict preview. Configuring a provider hands
+// it credentials, so it is only done if the preview permits invokes, which need a configured provider; otherwise the
+// configuration is treated as unknown, and Check, Diff, Read and Invoke do not call into the plugin.
+func (p *provider) strictConfigure() bool {
+	return !p.ctx.StrictPreview || len(p.ctx.StrictPreviewInvokes) > 0
+}
+
 // ensureConfigured blocks waiting for the plugin to be configured.  To improve parallelism, all Configure RPCs
 // occur in parallel, and we await the completion of them at the last possible moment.  This does mean, however, that
 // we might discover failures later than we would have otherwise, but the caller of ensureConfigured will get them.
@@ -369,6 +402,15 @@ func (p *provider) Configure(inputs resource.PropertyMap) error {
 	label := fmt.Sprintf("%s.Configure()", p.label())
 	logging.V(7).Infof("%s executing (#vars=%d)", label, len(inputs))
 
+	// A strict preview does not configure the underlying plugin unless it must: leave the cfgknown bit unset, as for
+	// unknown configuration, and carry on.
+	if !p.strictConfigure() {
+		logging.V(7).Infof("%s skipped during a strict preview", label)
+		p.cfgknown, p.acceptSecrets = false, false
+		close(p.cfgdone)
+		return nil
+	}
+
 	// Convert the inputs to a config map. If any are unknown, do not configure the underlying plugin: instead, leave
 	// the cfgknown bit unset and carry on.
 	config := make(map[string]string)
@@ -591,6 +633,11 @@ func (p *provider) Create(urn resource.URN, props resource.PropertyMap, timeout
 	label := fmt.Sprintf("%s.Create(%s)", p.label(), urn)
 	logging.V(7).Infof("%s executing (#props=%v)", label, len(props))
 
+	// A strict preview never changes resources, whether or not the provider honors it.
+	if err := p.checkStrictPreview(urn, "Create"); err != nil {
+		return "", nil, resource.StatusOK, err
+	}
+
 	mprops, err := MarshalProperties(props, MarshalOptions{
 		Label:       fmt.Sprintf("%s.inputs", label),
 		KeepSecrets: p.acceptSecrets,
@@ -787,6 +834,11 @@ func (p *provider) Update(urn resource.URN, id resource.ID,
 	label := fmt.Sprintf("%s.Update(%s,%s)", p.label(), id, urn)
 	logging.V(7).Infof("%s executing (#olds=%v,#news=%v)", label, len(olds), len(news))
 
+	// A strict preview never changes resources, whether or not the provider honors it.
+	if err := p.checkStrictPreview(urn, "Update"); err != nil {
+		return nil, resource.StatusOK, err
+	}
+
 	molds, err := MarshalProperties(olds, MarshalOptions{
 		Label:              fmt.Sprintf("%s.olds", label),
 		ElideAssetContents: true,
@@ -867,6 +919,11 @@ func (p *provider) Delete(urn resource.URN, id resource.ID, props resource.Prope
 	label := fmt.Sprintf("%s.Delete(%s,%s)", p.label(), urn, id)
 	logging.V(7).Infof("%s executing (#props=%d)", label, len(props))
 
+	// A strict preview never changes resources, whether or not the provider honors it.
+	if err := p.checkStrictPreview(urn, "Delete"); err != nil {
+		return resource.StatusOK, err
+	}
+
 	mprops, err := MarshalProperties(props, MarshalOptions{
 		Label:              label,
 		ElideAssetContents: true,
@@ -908,6 +965,11 @@ func (p *provider) Invoke(tok tokens.ModuleMember, args resource.PropertyMap) (r
 	label := fmt.Sprintf("%s.Invoke(%s)", p.label(), tok)
 	logging.V(7).Infof("%s executing (#args=%d)", label, len(args))
 
+	// Invokes run arbitrary provider code, so a strict preview only permits those it is told are reads.
+	if err := p.checkStrictInvoke(tok); err != nil {
+		return nil, nil, err
+	}
+
 	// Get the RPC client and ensure it's configured.
 	client, err := p.getClient()
 	if err != nil {
@@ -954,6 +1016,71 @@ func (p *provider) Invoke(tok tokens.ModuleMember, args resource.PropertyMap) (r
 	return ret, failures, nil
 }
 
+// GetLogs returns the log entries written by the resources in the query.
+func (p *provider) GetLogs(query LogQuery) (*[]LogEntry, error) {
+	label := fmt.Sprintf("%s.GetLogs()", p.label())
+	logging.V(7).Infof("%s executing (#resources=%d)", label, len(query.Resources))
+
+	// Get the RPC client and ensure it's configured.
+	client, err := p.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	// If the provider is not fully configured, it cannot reach the resources' logs.
+	if !p.cfgknown {
+		return nil, nil
+	}
+
+	req := &pulumirpc.GetLogsRequest{}
+	for _, res := range query.Resources {
+		mprops, err := MarshalProperties(res.Outputs, MarshalOptions{
+			Label:              fmt.Sprintf("%s.properties(%s)", label, res.URN),
+			ElideAssetContents: true,
+			KeepSecrets:        p.acceptSecrets,
+		})
+		if err != nil {
+			return nil, err
+		}
+		req.Resources = append(req.Resources, &pulumirpc.LogResource{
+			Id:         string(res.ID),
+			Urn:        string(res.URN),
+			Properties: mprops,
+		})
+	}
+	if query.StartTime != nil {
+		req.StartTime = query.StartTime.UnixNano() / int64(time.Millisecond)
+	}
+	if query.EndTime != nil {
+		req.EndTime = query.EndTime.UnixNano() / int64(time.Millisecond)
+	}
+
+	resp, err := client.GetLogs(p.ctx.Request(), req)
+	if err != nil {
+		rpcError := rpcerror.Convert(err)
+		if rpcError.Code() == codes.Unimplemented {
+			// Providers whose resources do not write logs need not implement GetLogs.
+			logging.V(7).Infof("%s unimplemented rpc: returning no logs", label)
+			return nil, nil
+		}
+		logging.V(7).Infof("%s failed: %v", label, rpcError.Message())
+		return nil, rpcError
+	}
+
+	logs := make([]LogEntry, 0, len(resp.GetEntries()))
+	for _, entry := range resp.GetEntries() {
+		logs = append(logs, LogEntry{
+			URN:       resource.URN(entry.GetUrn()),
+			ID:        entry.GetId(),
+			Timestamp: entry.GetTimestamp(),
+			Message:   entry.GetMessage(),
+		})
+	}
+
+	logging.V(7).Infof("%s success (#entries=%d)", label, len(logs))
+	return &logs, nil
+}
+
 // GetPluginInfo returns this plugin's information.
 func (p *provider) GetPluginInfo() (workspace.PluginInfo, error) {
 	label := fmt.Sprintf("%s.GetPluginInfo()", p.label())
@@ -1,12 +1,20 @@
 package plugin
 
 import (
+	"bytes"
+	"context"
 	"reflect"
 	"testing"
 
+	pbempty "github.com/golang/protobuf/ptypes/empty"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
 
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
 	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	pulumirpc "github.com/pulumi/pulumi/sdk/proto/go"
 )
 
 func TestAnnotateSecrets(t *testing.T) {
@@ -217,3 +225,96 @@ func TestNestedSecret(t *testing.T) {
 
 	assert.Truef(t, reflect.DeepEqual(to, expected), "did not match expected after annotation")
 }
+
+func TestInvokeStrictPreview(t *testing.T) {
+	var stderr bytes.Buffer
+	sink := diag.DefaultSink(&stderr, &stderr, diag.FormatOptions{Color: colors.Never})
+	p := &provider{ctx: &Context{
+		Diag:                 sink,
+		StrictPreview:        true,
+		StrictPreviewInvokes: map[tokens.ModuleMember]bool{"test:index:getBucket": true},
+	}, pkg: "test"}
+
+	// A function that is not permitted is rejected before the plugin is ever called, even if it is named like a read.
+	_, _, err := p.Invoke(tokens.ModuleMember("test:index:getOrCreateBucket"), nil)
+	assert.Error(t, err)
+	assert.Contains(t, stderr.String(), "Invoke(test:index:getOrCreateBucket)")
+	assert.Contains(t, stderr.String(), "is not permitted by --strict-allow")
+	_, _, err = p.Invoke(tokens.ModuleMember("test:index:deleteEverything"), nil)
+	assert.Error(t, err)
+
+	// Permitted functions, and any function outside of a strict preview, are not.
+	assert.NoError(t, p.checkStrictInvoke("test:index:getBucket"))
+	p.ctx.StrictPreview = false
+	assert.NoError(t, p.checkStrictInvoke("test:index:deleteEverything"))
+}
+
+// permissiveProviderClient is a provider that ignores strict previews, and records every call that changes resources.
+type permissiveProviderClient struct {
+	pulumirpc.ResourceProviderClient
+	calls []string
+}
+
+func (c *permissiveProviderClient) Configure(ctx context.Context, in *pulumirpc.ConfigureRequest,
+	opts ...grpc.CallOption) (*pulumirpc.ConfigureResponse, error) {
+	c.calls = append(c.calls, "Configure")
+	return &pulumirpc.ConfigureResponse{}, nil
+}
+
+func (c *permissiveProviderClient) Create(ctx context.Context, in *pulumirpc.CreateRequest,
+	opts ...grpc.CallOption) (*pulumirpc.CreateResponse, error) {
+	c.calls = append(c.calls, "Create")
+	return &pulumirpc.CreateResponse{Id: "id"}, nil
+}
+
+func (c *permissiveProviderClient) Update(ctx context.Context, in *pulumirpc.UpdateRequest,
+	opts ...grpc.CallOption) (*pulumirpc.UpdateResponse, error) {
+	c.calls = append(c.calls, "Update")
+	return &pulumirpc.UpdateResponse{}, nil
+}
+
+func (c *permissiveProviderClient) Delete(ctx context.Context, in *pulumirpc.DeleteRequest,
+	opts ...grpc.CallOption) (*pbempty.Empty, error) {
+	c.calls = append(c.calls, "Delete")
+	return &pbempty.Empty{}, nil
+}
+
+func TestStrictPreviewRejectsWrites(t *testing.T) {
+	var stderr bytes.Buffer
+	sink := diag.DefaultSink(&stderr, &stderr, diag.FormatOptions{Color: colors.Never})
+	client := &permissiveProviderClient{}
+	p := &provider{
+		ctx:       &Context{Diag: sink, StrictPreview: true},
+		pkg:       "test",
+		clientRaw: client,
+		cfgdone:   make(chan bool),
+	}
+
+	// Without any permitted invokes, the provider is never configured, so it never receives credentials.
+	assert.NoError(t, p.Configure(resource.PropertyMap{"region": resource.NewStringProperty("us-west-2")}))
+	assert.NoError(t, p.ensureConfigured())
+	assert.False(t, p.cfgknown)
+
+	// Even a provider that would carry them out is never asked to create, update or delete resources.
+	p.cfgknown = true
+	urn := resource.NewURN("stack", "project", "", "test:index:Bucket", "bucket")
+	props := resource.PropertyMap{"name": resource.NewStringProperty("bucket")}
+	_, _, _, err := p.Create(urn, props, 0)
+	assert.Error(t, err)
+	_, _, err = p.Update(urn, "id", props, props, 0, nil)
+	assert.Error(t, err)
+	_, err = p.Delete(urn, "id", props, 0)
+	assert.Error(t, err)
+	assert.Empty(t, client.calls)
+	assert.Contains(t, stderr.String(), "'Create' is not permitted")
+	assert.Contains(t, stderr.String(), "'Update' is not permitted")
+	assert.Contains(t, stderr.String(), "'Delete' is not permitted")
+	assert.NotContains(t, stderr.String(), "--strict-allow")
+
+	// A preview that permits invokes configures the provider, since they need it to be.
+	p.ctx.StrictPreviewInvokes = map[tokens.ModuleMember]bool{"test:index:getBucket": true}
+	p.cfgdone = make(chan bool)
+	assert.NoError(t, p.Configure(resource.PropertyMap{"region": resource.NewStringProperty("us-west-2")}))
+	assert.NoError(t, p.ensureConfigured())
+	assert.Equal(t, []string{"Configure"}, client.calls)
+}
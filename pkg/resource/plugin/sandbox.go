@@ -0,0 +1,189 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/util/rpcutil"
+)
+
+// SandboxOptions describes a set of restrictions to apply to a language host process when it is launched. These are
+// useful when running programs that pull in untrusted third-party components, e.g. during a preview of a pull request.
+type SandboxOptions struct {
+	// User, if non-empty, is the name of the OS user the language host should run as.
+	User string
+	// NoNetwork cuts the language host, and the program it runs, off from the network. On Linux, the language host runs
+	// in its own network namespace under bubblewrap, and reaches the engine over Unix sockets in a directory that is
+	// visible inside the sandbox. Elsewhere, or if bubblewrap is not installed, launching the language host fails.
+	NoNetwork bool
+	// ReadOnlyProjectDir mounts the project directory as read-only for the language host.
+	ReadOnlyProjectDir bool
+}
+
+// IsEmpty returns true if no restrictions have been requested.
+func (opts *SandboxOptions) IsEmpty() bool {
+	return opts == nil || (opts.User == "" && !opts.NoNetwork && !opts.ReadOnlyProjectDir)
+}
+
+// apply configures the given command so that, once started, it runs under the requested restrictions. If the sandbox
+// denies network access, network is the sandbox network over which the command reaches the engine.
+func (opts *SandboxOptions) apply(cmd *exec.Cmd, network *sandboxNetwork) error {
+	if opts.IsEmpty() {
+		return nil
+	}
+
+	if opts.NoNetwork {
+		contract.Assertf(network != nil, "a sandbox without network access requires a sandbox network")
+		// Ask the language host to serve on a Unix socket, since the engine cannot reach its loopback interface.
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, rpcutil.SocketEnvVar+"="+network.hostSocket())
+	}
+	if opts.User != "" {
+		if err := sandboxUser(cmd, opts.User); err != nil {
+			return err
+		}
+	}
+	if opts.NoNetwork || opts.ReadOnlyProjectDir {
+		var readOnlyDir string
+		if opts.ReadOnlyProjectDir {
+			readOnlyDir = cmd.Dir
+		}
+		if err := sandboxWrap(cmd, readOnlyDir, opts.NoNetwork); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sandboxNetwork connects a language host that has no network access to the engine. The engine's gRPC servers listen
+// on loopback TCP ports, which are not reachable from the language host's network namespace, so each is forwarded
+// from a Unix socket in a directory that the language host can see; the language host serves on a Unix socket there
+// in turn.
+type sandboxNetwork struct {
+	dir string
+
+	lock      sync.Mutex
+	listeners []net.Listener
+}
+
+// newSandboxNetwork creates the directory of the sandbox network for a language host running under the given
+// restrictions, or returns nil if they do not deny network access.
+func newSandboxNetwork(opts *SandboxOptions) (*sandboxNetwork, error) {
+	if opts.IsEmpty() || !opts.NoNetwork {
+		return nil, nil
+	}
+
+	dir, err := ioutil.TempDir("", "pulumi-sandbox")
+	if err != nil {
+		return nil, errors.Wrap(err, "creating sandbox network directory")
+	}
+	// A language host running as another user must be able to create its socket, and to connect to the engine's.
+	if opts.User != "" {
+		if err = sandboxChown(dir, opts.User); err != nil {
+			contract.IgnoreError(os.RemoveAll(dir))
+			return nil, err
+		}
+	}
+	return &sandboxNetwork{dir: dir}, nil
+}
+
+// hostSocket returns the path of the Unix socket on which the language host serves.
+func (n *sandboxNetwork) hostSocket() string {
+	return filepath.Join(n.dir, "host.sock")
+}
+
+// forward listens on a new Unix socket in the sandbox network's directory and forwards each connection to it to the
+// given TCP address. It returns the address of the socket, for the language host to connect to.
+func (n *sandboxNetwork) forward(addr string) (string, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	path := filepath.Join(n.dir, fmt.Sprintf("engine-%d.sock", len(n.listeners)))
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		return "", errors.Wrapf(err, "forwarding %s into the sandbox", addr)
+	}
+	n.listeners = append(n.listeners, lis)
+
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go forwardConn(conn, addr)
+		}
+	}()
+	return rpcutil.UnixSocketScheme + path, nil
+}
+
+// forwardConn copies data in both directions between the given connection and a new connection to the given TCP
+// address, until either side closes its connection.
+func forwardConn(conn net.Conn, addr string) {
+	defer contract.IgnoreClose(conn)
+
+	upstream, err := net.Dial("tcp", addr)
+	if err != nil {
+		logging.V(5).Infof("sandbox network: could not connect to %s: %v", addr, err)
+		return
+	}
+	defer contract.IgnoreClose(upstream)
+
+	done := make(chan bool, 2)
+	go func() {
+		_, err := io.Copy(upstream, conn)
+		contract.IgnoreError(err)
+		done <- true
+	}()
+	go func() {
+		_, err := io.Copy(conn, upstream)
+		contract.IgnoreError(err)
+		done <- true
+	}()
+	<-done
+}
+
+// Close stops forwarding connections and removes the sandbox network's directory.
+func (n *sandboxNetwork) Close() error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	var result error
+	for _, lis := range n.listeners {
+		if err := lis.Close(); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+	n.listeners = nil
+	if err := os.RemoveAll(n.dir); err != nil {
+		result = multierror.Append(result, err)
+	}
+	return result
+}
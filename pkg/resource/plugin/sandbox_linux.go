@@ -0,0 +1,48 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"os/exec"
+
+	"github.com/pkg/errors"
+)
+
+// sandboxWrap re-parents the command under bubblewrap, which bind mounts the whole filesystem as-is except for the
+// given directory, if any, which is re-mounted as read-only. If unshareNet is true, the command runs in a network
+// namespace of its own, which has nothing but a loopback interface; the engine is then reached over the Unix sockets
+// of a sandboxNetwork, which the bind mount of the filesystem makes visible. Otherwise the network namespace is
+// shared, so that the language host can reach the engine over loopback TCP.
+func sandboxWrap(cmd *exec.Cmd, readOnlyDir string, unshareNet bool) error {
+	bwrap, err := exec.LookPath("bwrap")
+	if err != nil {
+		return errors.New("sandboxing the language host requires bubblewrap ('bwrap') to be installed")
+	}
+
+	args := []string{"bwrap", "--dev-bind", "/", "/"}
+	if readOnlyDir != "" {
+		args = append(args, "--ro-bind", readOnlyDir, readOnlyDir)
+	}
+	if unshareNet {
+		args = append(args, "--unshare-net")
+	}
+	if cmd.Dir != "" {
+		args = append(args, "--chdir", cmd.Dir)
+	}
+	args = append(args, "--", cmd.Path)
+	cmd.Args = append(args, cmd.Args[1:]...)
+	cmd.Path = bwrap
+	return nil
+}
@@ -0,0 +1,32 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !linux
+
+package plugin
+
+import (
+	"os/exec"
+
+	"github.com/pkg/errors"
+)
+
+// sandboxWrap is only supported on Linux. Since the restrictions it enforces cannot be enforced elsewhere, launching
+// a language host that requests them fails.
+func sandboxWrap(cmd *exec.Cmd, readOnlyDir string, unshareNet bool) error {
+	if unshareNet {
+		return errors.New("running the language host without network access is only supported on Linux")
+	}
+	return errors.New("a read-only project directory is only supported on Linux")
+}
@@ -0,0 +1,102 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/rpcutil"
+)
+
+func TestSandboxIsEmpty(t *testing.T) {
+	var opts *SandboxOptions
+	assert.True(t, opts.IsEmpty())
+	assert.True(t, (&SandboxOptions{}).IsEmpty())
+	assert.False(t, (&SandboxOptions{NoNetwork: true}).IsEmpty())
+	assert.False(t, (&SandboxOptions{User: "nobody"}).IsEmpty())
+}
+
+func TestSandboxNoNetwork(t *testing.T) {
+	opts := &SandboxOptions{NoNetwork: true}
+	network, err := newSandboxNetwork(opts)
+	assert.NoError(t, err)
+	defer contract.IgnoreClose(network)
+
+	// The language host is asked to serve on a socket in the sandbox network, in addition to its given environment.
+	cmd := exec.Command("true")
+	cmd.Env = []string{"FOO=bar"}
+	err = opts.apply(cmd, network)
+	assert.Equal(t, []string{"FOO=bar", rpcutil.SocketEnvVar + "=" + network.hostSocket()}, cmd.Env)
+	if _, lookErr := exec.LookPath("bwrap"); lookErr != nil || runtime.GOOS != "linux" {
+		// Without bubblewrap, the network cannot be denied, so the language host must not be launched at all.
+		assert.Error(t, err)
+	} else {
+		assert.NoError(t, err)
+		assert.Contains(t, cmd.Args, "--unshare-net")
+	}
+
+	cmd = exec.Command("true")
+	assert.NoError(t, (*SandboxOptions)(nil).apply(cmd, nil))
+	assert.Nil(t, cmd.Env)
+	network, err = newSandboxNetwork(&SandboxOptions{User: "nobody"})
+	assert.NoError(t, err)
+	assert.Nil(t, network)
+}
+
+func TestSandboxNetworkForward(t *testing.T) {
+	// Stand in for one of the engine's servers with one that echoes what it is sent.
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer contract.IgnoreClose(lis)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer contract.IgnoreClose(conn)
+		_, err = io.Copy(conn, conn)
+		contract.IgnoreError(err)
+	}()
+
+	network, err := newSandboxNetwork(&SandboxOptions{NoNetwork: true})
+	assert.NoError(t, err)
+	addr, err := network.forward(lis.Addr().String())
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(addr, rpcutil.UnixSocketScheme))
+
+	conn, err := net.Dial("unix", strings.TrimPrefix(addr, rpcutil.UnixSocketScheme))
+	assert.NoError(t, err)
+	_, err = conn.Write([]byte("ping"))
+	assert.NoError(t, err)
+	reply := make([]byte, 4)
+	_, err = io.ReadFull(conn, reply)
+	assert.NoError(t, err)
+	assert.Equal(t, "ping", string(reply))
+	assert.NoError(t, conn.Close())
+
+	// Closing the network removes its sockets.
+	assert.NoError(t, network.Close())
+	_, err = os.Stat(network.dir)
+	assert.True(t, os.IsNotExist(err))
+}
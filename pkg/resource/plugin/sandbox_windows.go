@@ -0,0 +1,31 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"os/exec"
+
+	"github.com/pkg/errors"
+)
+
+// sandboxUser is not supported on Windows.
+func sandboxUser(cmd *exec.Cmd, name string) error {
+	return errors.New("running the language host as a different user is not supported on Windows")
+}
+
+// sandboxChown is not supported on Windows.
+func sandboxChown(path, name string) error {
+	return errors.New("running the language host as a different user is not supported on Windows")
+}
@@ -33,7 +33,9 @@ type State struct {
 	Outputs                 PropertyMap           // the resource's complete output state (as returned by the resource provider).
 	Parent                  URN                   // an optional parent URN that this resource belongs to.
 	Protect                 bool                  // true to "protect" this resource (protected resources cannot be deleted).
+	Locked                  bool                  // true to "lock" this resource (locked resources can't be changed).
 	External                bool                  // true if this resource is "external" to Pulumi and we don't control the lifecycle
+	RetainOnDelete          bool                  // true if deleting this resource only removes it from the state.
 	Dependencies            []URN                 // the resource's dependencies
 	InitErrors              []string              // the set of errors encountered in the process of initializing resource.
 	Provider                string                // the provider to use for this resource.
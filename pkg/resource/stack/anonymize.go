@@ -0,0 +1,337 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/secrets/b64"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+// AnonymizeDeployment returns a copy of the given deployment in which the values that identify infrastructure are
+// replaced with pseudonyms, so that the deployment can be shared, e.g. to reproduce a bug, without revealing them:
+//
+//     - the stack and project names, and the names of resources other than default providers;
+//     - the IDs of resources, and the provider references that contain them;
+//     - IPv4 addresses;
+//     - the values of properties whose names suggest they hold credentials, such as passwords, tokens, and keys;
+//     - the paths of plugins.
+//
+// The same value is always replaced with the same pseudonym, wherever it appears, including within other strings. The
+// structure of the deployment, its resources' types, and the dependencies between them are preserved. Secrets are
+// replaced with a placeholder that is "encrypted" by the base64 secrets provider, so that the deployment can still be
+// imported into a stack.
+func AnonymizeDeployment(deployment *apitype.DeploymentV3) *apitype.DeploymentV3 {
+	contract.Require(deployment != nil, "deployment")
+
+	a := newAnonymizer(deployment)
+
+	result := &apitype.DeploymentV3{Manifest: deployment.Manifest}
+	result.Manifest.Plugins = make([]apitype.PluginInfoV1, len(deployment.Manifest.Plugins))
+	for i, plugin := range deployment.Manifest.Plugins {
+		plugin.Path = ""
+		result.Manifest.Plugins[i] = plugin
+	}
+	if deployment.SecretsProviders != nil {
+		result.SecretsProviders = &apitype.SecretsProvidersV1{Type: b64.Type}
+	}
+	for _, res := range deployment.Resources {
+		result.Resources = append(result.Resources, a.resource(res))
+	}
+	for _, op := range deployment.PendingOperations {
+		result.PendingOperations = append(result.PendingOperations, apitype.OperationV2{
+			Resource: a.resource(op.Resource),
+			Type:     op.Type,
+		})
+	}
+	return result
+}
+
+// anonymizedSecret is the value that every secret is replaced with.
+const anonymizedSecret = "[secret]"
+
+// ipv4Regexp matches the IPv4 addresses within a string.
+var ipv4Regexp = regexp.MustCompile(`\b(?:[0-9]{1,3}\.){3}[0-9]{1,3}\b`)
+
+// credentialKeys are the parts of property names that suggest a property holds a credential.
+var credentialKeys = []string{"password", "passwd", "secret", "token", "credential", "privatekey", "private_key",
+	"accesskey", "access_key", "apikey", "api_key", "connectionstring", "connection_string"}
+
+// anonymizer replaces the identifying values of a deployment with pseudonyms.
+type anonymizer struct {
+	pseudonyms map[string]string // the pseudonyms of the names and IDs found in the deployment's resources.
+	replacer   *strings.Replacer // replaces the longer of those names and IDs within other strings.
+	ips        map[string]string // the pseudonyms of the IPv4 addresses found so far.
+	values     map[string]string // the pseudonyms of the credentials found so far.
+}
+
+func newAnonymizer(deployment *apitype.DeploymentV3) *anonymizer {
+	a := &anonymizer{
+		pseudonyms: make(map[string]string),
+		ips:        make(map[string]string),
+		values:     make(map[string]string),
+	}
+
+	// Assign pseudonyms to every name and ID up front, in the order their resources appear, so that references to
+	// resources that appear later in the deployment are replaced consistently too.
+	add := func(value, pseudonym string) {
+		if _, has := a.pseudonyms[value]; value != "" && !has {
+			a.pseudonyms[value] = pseudonym
+		}
+	}
+	var names, ids int
+	resources := deployment.Resources
+	for _, op := range deployment.PendingOperations {
+		resources = append(resources, op.Resource)
+	}
+	for _, res := range resources {
+		if !isURN(res.URN) {
+			continue
+		}
+		add(string(res.URN.Stack()), "stack")
+		add(string(res.URN.Project()), "project")
+		if !providers.IsDefaultProvider(res.URN) {
+			if _, has := a.pseudonyms[string(res.URN.Name())]; !has {
+				names++
+				add(string(res.URN.Name()), fmt.Sprintf("resource-%d", names))
+			}
+		}
+		if _, has := a.pseudonyms[string(res.ID)]; res.ID != "" && !has {
+			ids++
+			add(string(res.ID), fmt.Sprintf("id-%d", ids))
+		}
+	}
+
+	// Replace the longest values first within other strings, and don't replace very short ones, which are likely to
+	// appear by coincidence.
+	var known []string
+	for value := range a.pseudonyms {
+		if len(value) >= 4 {
+			known = append(known, value)
+		}
+	}
+	sort.Slice(known, func(i, j int) bool {
+		if len(known[i]) != len(known[j]) {
+			return len(known[i]) > len(known[j])
+		}
+		return known[i] < known[j]
+	})
+	var pairs []string
+	for _, value := range known {
+		pairs = append(pairs, value, a.pseudonyms[value])
+	}
+	a.replacer = strings.NewReplacer(pairs...)
+	return a
+}
+
+func isURN(urn resource.URN) bool {
+	return strings.HasPrefix(string(urn), resource.URNPrefix) &&
+		strings.Count(string(urn), resource.URNNameDelimiter) >= 3
+}
+
+func (a *anonymizer) resource(res apitype.ResourceV3) apitype.ResourceV3 {
+	res.URN = a.urn(res.URN)
+	res.ID = resource.ID(a.string(string(res.ID)))
+	res.Inputs = a.object(res.Inputs)
+	res.Outputs = a.object(res.Outputs)
+	res.Parent = a.urn(res.Parent)
+	res.Dependencies = a.urns(res.Dependencies)
+	res.Aliases = a.urns(res.Aliases)
+	if res.Provider != "" {
+		res.Provider = a.providerReference(res.Provider)
+	}
+	if res.PropertyDependencies != nil {
+		deps := make(map[resource.PropertyKey][]resource.URN, len(res.PropertyDependencies))
+		for k, urns := range res.PropertyDependencies {
+			deps[k] = a.urns(urns)
+		}
+		res.PropertyDependencies = deps
+	}
+	if res.InitErrors != nil {
+		errs := make([]string, len(res.InitErrors))
+		for i, err := range res.InitErrors {
+			errs[i] = a.string(err)
+		}
+		res.InitErrors = errs
+	}
+	return res
+}
+
+func (a *anonymizer) urn(urn resource.URN) resource.URN {
+	if !isURN(urn) {
+		return resource.URN(a.string(string(urn)))
+	}
+	name := string(urn.Name())
+	if pseudonym, has := a.pseudonyms[name]; has {
+		name = pseudonym
+	}
+	return resource.URN(resource.URNPrefix + a.pseudonyms[string(urn.Stack())] + resource.URNNameDelimiter +
+		a.pseudonyms[string(urn.Project())] + resource.URNNameDelimiter + string(urn.QualifiedType()) +
+		resource.URNNameDelimiter + name)
+}
+
+func (a *anonymizer) urns(urns []resource.URN) []resource.URN {
+	if urns == nil {
+		return nil
+	}
+	result := make([]resource.URN, len(urns))
+	for i, urn := range urns {
+		result[i] = a.urn(urn)
+	}
+	return result
+}
+
+func (a *anonymizer) providerReference(s string) string {
+	ref, err := providers.ParseReference(s)
+	if err != nil {
+		return a.string(s)
+	}
+	anonymized, err := providers.NewReference(a.urn(ref.URN()), resource.ID(a.string(string(ref.ID()))))
+	if err != nil {
+		return a.string(s)
+	}
+	return anonymized.String()
+}
+
+// string replaces the names, IDs, and IPv4 addresses within the given string.
+func (a *anonymizer) string(s string) string {
+	if pseudonym, has := a.pseudonyms[s]; has {
+		return pseudonym
+	}
+	s = a.replacer.Replace(s)
+	return ipv4Regexp.ReplaceAllStringFunc(s, func(ip string) string {
+		if net.ParseIP(ip) == nil {
+			return ip
+		}
+		pseudonym, has := a.ips[ip]
+		if !has {
+			n := len(a.ips) + 1
+			pseudonym = fmt.Sprintf("10.%d.%d.%d", (n>>16)&0xff, (n>>8)&0xff, n&0xff)
+			a.ips[ip] = pseudonym
+		}
+		return pseudonym
+	})
+}
+
+func (a *anonymizer) object(obj map[string]interface{}) map[string]interface{} {
+	if obj == nil {
+		return nil
+	}
+	result := make(map[string]interface{}, len(obj))
+	for _, k := range sortedKeys(obj) {
+		v := obj[k]
+		if isCredentialKey(k) {
+			result[k] = a.credential(v)
+		} else {
+			result[k] = a.value(v)
+		}
+	}
+	return result
+}
+
+func (a *anonymizer) value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return a.string(v)
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, e := range v {
+			result[i] = a.value(e)
+		}
+		return result
+	case map[string]interface{}:
+		if sig, has := v[resource.SigKey]; has && sig == resource.SecretSig {
+			return anonymizedSecretValue()
+		}
+		return a.object(v)
+	default:
+		return v
+	}
+}
+
+// credential replaces every string within the value of a property that holds a credential.
+func (a *anonymizer) credential(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		if v == "" {
+			return v
+		}
+		pseudonym, has := a.values[v]
+		if !has {
+			pseudonym = fmt.Sprintf("value-%d", len(a.values)+1)
+			a.values[v] = pseudonym
+		}
+		return pseudonym
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, e := range v {
+			result[i] = a.credential(e)
+		}
+		return result
+	case map[string]interface{}:
+		if sig, has := v[resource.SigKey]; has && sig == resource.SecretSig {
+			return anonymizedSecretValue()
+		}
+		result := make(map[string]interface{}, len(v))
+		for _, k := range sortedKeys(v) {
+			result[k] = a.credential(v[k])
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// sortedKeys returns the keys of the given object in sorted order, so that pseudonyms are assigned in the same order
+// every time a deployment is anonymized.
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isCredentialKey(k string) bool {
+	k = strings.ToLower(k)
+	for _, credential := range credentialKeys {
+		if strings.Contains(k, credential) {
+			return true
+		}
+	}
+	return false
+}
+
+// anonymizedSecretValue returns a serialized secret whose plaintext is anonymizedSecret, "encrypted" by the base64
+// secrets provider.
+func anonymizedSecretValue() map[string]interface{} {
+	plaintext, err := json.Marshal(anonymizedSecret)
+	contract.AssertNoError(err)
+	return map[string]interface{}{
+		resource.SigKey: resource.SecretSig,
+		"ciphertext":    base64.StdEncoding.EncodeToString(plaintext),
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/resource"
+)
+
+func TestAnonymizeDeployment(t *testing.T) {
+	provURN := resource.URN("urn:pulumi:prod::webshop::pulumi:providers:aws::default_1_2_3")
+	vpcURN := resource.URN("urn:pulumi:prod::webshop::aws:ec2/vpc:Vpc::webshop-vpc")
+	dbURN := resource.URN("urn:pulumi:prod::webshop::aws:rds/instance:Instance::orders-db")
+	provRef := string(provURN) + "::0b8f7a3e-provider-id"
+
+	deployment := &apitype.DeploymentV3{
+		Manifest: apitype.ManifestV1{
+			Version: "1.0.0",
+			Plugins: []apitype.PluginInfoV1{{Name: "aws", Path: "/home/alice/.pulumi/plugins/aws", Version: "1.2.3"}},
+		},
+		SecretsProviders: &apitype.SecretsProvidersV1{Type: "passphrase", State: json.RawMessage(`{"salt":"x"}`)},
+		Resources: []apitype.ResourceV3{
+			{URN: provURN, Custom: true, ID: "0b8f7a3e-provider-id", Type: provURN.Type()},
+			{
+				URN:      vpcURN,
+				Custom:   true,
+				ID:       "vpc-0123abcd",
+				Type:     vpcURN.Type(),
+				Provider: provRef,
+				Outputs: map[string]interface{}{
+					"cidrBlock": "172.16.0.0/16",
+					"arn":       "arn:aws:ec2:us-west-2:123456789012:vpc/vpc-0123abcd",
+				},
+			},
+			{
+				URN:          dbURN,
+				Custom:       true,
+				ID:           "orders-db",
+				Type:         dbURN.Type(),
+				Provider:     provRef,
+				Dependencies: []resource.URN{vpcURN},
+				PropertyDependencies: map[resource.PropertyKey][]resource.URN{
+					"vpcId": {vpcURN},
+				},
+				Inputs: map[string]interface{}{
+					"vpcId":    "vpc-0123abcd",
+					"password": "hunter22",
+					"tags":     map[string]interface{}{"Name": "orders-db", "size": float64(3)},
+				},
+				Outputs: map[string]interface{}{
+					"address":  "172.16.4.20",
+					"endpoint": "orders-db.c9akciq32.us-west-2.rds.amazonaws.com",
+					"masterPassword": map[string]interface{}{
+						resource.SigKey: resource.SecretSig,
+						"ciphertext":    "v1:abc:def",
+					},
+				},
+			},
+		},
+	}
+
+	anonymized := AnonymizeDeployment(deployment)
+	bytes, err := json.Marshal(anonymized)
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, s := range []string{"prod", "webshop", "orders-db", "vpc-0123abcd", "0b8f7a3e", "172.16", "hunter22",
+		"alice", "v1:abc:def"} {
+		assert.NotContains(t, string(bytes), s)
+	}
+
+	// The structure of the deployment is preserved, and each value is replaced consistently.
+	if !assert.Len(t, anonymized.Resources, 3) {
+		return
+	}
+	prov, vpc, db := anonymized.Resources[0], anonymized.Resources[1], anonymized.Resources[2]
+	assert.Equal(t, resource.URN("urn:pulumi:stack::project::pulumi:providers:aws::default_1_2_3"), prov.URN)
+	assert.Equal(t, resource.URN("urn:pulumi:stack::project::aws:ec2/vpc:Vpc::resource-1"), vpc.URN)
+	assert.Equal(t, resource.URN("urn:pulumi:stack::project::aws:rds/instance:Instance::resource-2"), db.URN)
+	assert.Equal(t, string(prov.URN)+"::"+string(prov.ID), vpc.Provider)
+	assert.Equal(t, vpc.Provider, db.Provider)
+	assert.Equal(t, []resource.URN{vpc.URN}, db.Dependencies)
+	assert.Equal(t, []resource.URN{vpc.URN}, db.PropertyDependencies["vpcId"])
+	assert.Equal(t, string(vpc.ID), db.Inputs["vpcId"])
+	assert.Equal(t, "10.0.0.1/16", vpc.Outputs["cidrBlock"])
+	assert.Equal(t, "arn:aws:ec2:us-west-2:123456789012:vpc/"+string(vpc.ID), vpc.Outputs["arn"])
+	assert.Equal(t, "resource-2", db.Inputs["tags"].(map[string]interface{})["Name"])
+	assert.Equal(t, float64(3), db.Inputs["tags"].(map[string]interface{})["size"])
+	assert.Equal(t, "value-1", db.Inputs["password"])
+	assert.Equal(t, "", anonymized.Manifest.Plugins[0].Path)
+
+	// The anonymized deployment can still be loaded, with its secrets replaced by a placeholder.
+	snap, err := DeserializeDeploymentV3(*anonymized, DefaultSecretsProvider)
+	if !assert.NoError(t, err) {
+		return
+	}
+	secret := snap.Resources[2].Outputs["masterPassword"]
+	if assert.True(t, secret.IsSecret()) {
+		assert.Equal(t, anonymizedSecret, secret.SecretValue().Element.StringValue())
+	}
+}
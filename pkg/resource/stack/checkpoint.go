@@ -31,11 +31,38 @@ import (
 )
 
 func UnmarshalVersionedCheckpointToLatestCheckpoint(bytes []byte) (*apitype.CheckpointV3, error) {
+	chk, _, err := UnmarshalVersionedCheckpoint(bytes, DefaultSecretsProvider)
+	return chk, err
+}
+
+// UnmarshalVersionedCheckpoint is like UnmarshalVersionedCheckpointToLatestCheckpoint, but also reports whether the
+// checkpoint was encrypted at rest. Encrypted checkpoints are decrypted with the secrets managers of the given
+// provider or, if it is nil, not decrypted at all, in which case no checkpoint is returned.
+func UnmarshalVersionedCheckpoint(bytes []byte, secretsProv SecretsProvider) (*apitype.CheckpointV3, bool, error) {
 	var versionedCheckpoint apitype.VersionedCheckpoint
 	if err := json.Unmarshal(bytes, &versionedCheckpoint); err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
+	encrypted := versionedCheckpoint.Encrypted != nil
+	if encrypted {
+		if secretsProv == nil {
+			return nil, true, nil
+		}
+		decrypted, err := DecryptCheckpoint(&versionedCheckpoint, secretsProv)
+		if err != nil {
+			return nil, true, err
+		}
+		versionedCheckpoint = *decrypted
+	}
+
+	chk, err := latestCheckpoint(bytes, versionedCheckpoint)
+	return chk, encrypted, err
+}
+
+// latestCheckpoint migrates the given versioned checkpoint, which was unmarshaled from the given bytes, to the latest
+// version.
+func latestCheckpoint(bytes []byte, versionedCheckpoint apitype.VersionedCheckpoint) (*apitype.CheckpointV3, error) {
 	switch versionedCheckpoint.Version {
 	case 0:
 		// The happens when we are loading a checkpoint file from before we started to version things. Go's
@@ -135,6 +135,16 @@ func SerializeDeployment(snap *deploy.Snapshot, sm secrets.Manager) (*apitype.De
 func DeserializeUntypedDeployment(
 	deployment *apitype.UntypedDeployment, secretsProv SecretsProvider) (*deploy.Snapshot, error) {
 
+	v3deployment, err := UnmarshalUntypedDeployment(deployment)
+	if err != nil {
+		return nil, err
+	}
+	return DeserializeDeploymentV3(*v3deployment, secretsProv)
+}
+
+// UnmarshalUntypedDeployment unmarshals an untyped deployment, migrating it to the current schema version. Like
+// DeserializeUntypedDeployment, it returns an error if the deployment's version is not supported.
+func UnmarshalUntypedDeployment(deployment *apitype.UntypedDeployment) (*apitype.DeploymentV3, error) {
 	contract.Require(deployment != nil, "deployment")
 	switch {
 	case deployment.Version > apitype.DeploymentSchemaVersionCurrent:
@@ -165,8 +175,7 @@ func DeserializeUntypedDeployment(
 	default:
 		contract.Failf("unrecognized version: %d", deployment.Version)
 	}
-
-	return DeserializeDeploymentV3(v3deployment, secretsProv)
+	return &v3deployment, nil
 }
 
 // DeserializeDeploymentV3 deserializes a typed DeploymentV3 into a `deploy.Snapshot`.
@@ -244,10 +253,12 @@ func SerializeResource(res *resource.State, enc config.Encrypter) (apitype.Resou
 	contract.Assert(res != nil)
 	contract.Assertf(string(res.URN) != "", "Unexpected empty resource resource.URN")
 
-	// Serialize all input and output properties recursively, and add them if non-empty.
+	// Serialize all input and output properties recursively, and add them if non-empty. Secrets are encrypted along
+	// with their locations, for encrypters that record them.
 	var inputs map[string]interface{}
 	if inp := res.Inputs; inp != nil {
-		sinp, err := SerializeProperties(inp, enc)
+		loc := config.ValueLocation{Resource: string(res.URN), Property: "inputs"}
+		sinp, err := serializeProperties(inp, enc, loc)
 		if err != nil {
 			return apitype.ResourceV3{}, err
 		}
@@ -255,7 +266,8 @@ func SerializeResource(res *resource.State, enc config.Encrypter) (apitype.Resou
 	}
 	var outputs map[string]interface{}
 	if outp := res.Outputs; outp != nil {
-		soutp, err := SerializeProperties(outp, enc)
+		loc := config.ValueLocation{Resource: string(res.URN), Property: "outputs"}
+		soutp, err := serializeProperties(outp, enc, loc)
 		if err != nil {
 			return apitype.ResourceV3{}, err
 		}
@@ -272,6 +284,8 @@ func SerializeResource(res *resource.State, enc config.Encrypter) (apitype.Resou
 		Inputs:                  inputs,
 		Outputs:                 outputs,
 		Protect:                 res.Protect,
+		Locked:                  res.Locked,
+		RetainOnDelete:          res.RetainOnDelete,
 		External:                res.External,
 		Dependencies:            res.Dependencies,
 		InitErrors:              res.InitErrors,
@@ -302,9 +316,21 @@ func SerializeOperation(op resource.Operation, enc config.Encrypter) (apitype.Op
 
 // SerializeProperties serializes a resource property bag so that it's suitable for serialization.
 func SerializeProperties(props resource.PropertyMap, enc config.Encrypter) (map[string]interface{}, error) {
+	return serializeProperties(props, enc, config.ValueLocation{})
+}
+
+// serializeProperties serializes a property bag stored at the given location.
+func serializeProperties(props resource.PropertyMap, enc config.Encrypter,
+	loc config.ValueLocation) (map[string]interface{}, error) {
+
 	dst := make(map[string]interface{})
 	for _, k := range props.StableKeys() {
-		v, err := SerializePropertyValue(props[k], enc)
+		elem := loc
+		if elem.Property != "" {
+			elem.Property += "."
+		}
+		elem.Property += string(k)
+		v, err := serializePropertyValue(props[k], enc, elem)
 		if err != nil {
 			return nil, err
 		}
@@ -317,6 +343,13 @@ func SerializeProperties(props resource.PropertyMap, enc config.Encrypter) (map[
 
 // SerializePropertyValue serializes a resource property value so that it's suitable for serialization.
 func SerializePropertyValue(prop resource.PropertyValue, enc config.Encrypter) (interface{}, error) {
+	return serializePropertyValue(prop, enc, config.ValueLocation{})
+}
+
+// serializePropertyValue serializes a property value stored at the given location.
+func serializePropertyValue(prop resource.PropertyValue, enc config.Encrypter,
+	loc config.ValueLocation) (interface{}, error) {
+
 	// Skip nulls and "outputs"; the former needn't be serialized, and the latter happens if there is an output
 	// that hasn't materialized (either because we're serializing inputs or the provider didn't give us the value).
 	if prop.IsComputed() || !prop.HasValue() {
@@ -328,7 +361,9 @@ func SerializePropertyValue(prop resource.PropertyValue, enc config.Encrypter) (
 		srcarr := prop.ArrayValue()
 		dstarr := make([]interface{}, len(srcarr))
 		for i, elem := range prop.ArrayValue() {
-			selem, err := SerializePropertyValue(elem, enc)
+			elemLoc := loc
+			elemLoc.Property += fmt.Sprintf("[%d]", i)
+			selem, err := serializePropertyValue(elem, enc, elemLoc)
 			if err != nil {
 				return nil, err
 			}
@@ -339,7 +374,7 @@ func SerializePropertyValue(prop resource.PropertyValue, enc config.Encrypter) (
 
 	// Also for objects, recurse and use naked properties.
 	if prop.IsObject() {
-		return SerializeProperties(prop.ObjectValue(), enc)
+		return serializeProperties(prop.ObjectValue(), enc, loc)
 	}
 
 	// For assets, we need to serialize them a little carefully, so we can recover them afterwards.
@@ -361,7 +396,7 @@ func SerializePropertyValue(prop resource.PropertyValue, enc config.Encrypter) (
 		if err != nil {
 			return nil, errors.Wrap(err, "encoding serialized property value")
 		}
-		ciphertext, err := enc.EncryptValue(string(bytes))
+		ciphertext, err := config.EncryptValueAt(enc, string(bytes), loc)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to encrypt secret value")
 		}
@@ -388,10 +423,13 @@ func DeserializeResource(res apitype.ResourceV3, dec config.Decrypter) (*resourc
 		return nil, err
 	}
 
-	return resource.NewState(
+	state := resource.NewState(
 		res.Type, res.URN, res.Custom, res.Delete, res.ID,
 		inputs, outputs, res.Parent, res.Protect, res.External, res.Dependencies, res.InitErrors, res.Provider,
-		res.PropertyDependencies, res.PendingReplacement, res.AdditionalSecretOutputs, res.Aliases, res.CustomTimeouts), nil
+		res.PropertyDependencies, res.PendingReplacement, res.AdditionalSecretOutputs, res.Aliases, res.CustomTimeouts)
+	state.Locked = res.Locked
+	state.RetainOnDelete = res.RetainOnDelete
+	return state, nil
 }
 
 func DeserializeOperation(op apitype.OperationV2, dec config.Decrypter) (resource.Operation, error) {
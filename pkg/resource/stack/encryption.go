@@ -0,0 +1,183 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/secrets"
+	"github.com/pulumi/pulumi/pkg/secrets/b64"
+)
+
+// StateEncrypter encrypts a stack's serialized state as a whole, so that backends whose storage does not encrypt it can
+// store it encrypted at rest. Backends apply it to checkpoints just before persisting them, with EncryptCheckpoint.
+type StateEncrypter interface {
+	// SecretsProvider describes the key that state is encrypted with, so that the encrypter can be reconstructed to
+	// decrypt the state later.
+	SecretsProvider() (apitype.SecretsProvidersV1, error)
+	// EncryptState encrypts the given serialized state.
+	EncryptState(plaintext []byte) (string, error)
+	// DecryptState decrypts state that was encrypted by EncryptState.
+	DecryptState(ciphertext string) ([]byte, error)
+}
+
+// NewStateEncrypter returns a StateEncrypter that encrypts state with the key of the given secrets manager, which is
+// normally that of the stack whose state is being encrypted. Secrets managers that do not actually encrypt, such as
+// the base64 manager used by stacks without a secrets provider, are rejected.
+func NewStateEncrypter(sm secrets.Manager) (StateEncrypter, error) {
+	if sm == nil || sm.Type() == b64.Type {
+		return nil, errors.New("state can only be encrypted by a stack with a secrets provider")
+	}
+	return &secretsManagerStateEncrypter{sm: sm}, nil
+}
+
+type secretsManagerStateEncrypter struct {
+	sm secrets.Manager
+}
+
+func (e *secretsManagerStateEncrypter) SecretsProvider() (apitype.SecretsProvidersV1, error) {
+	provider := apitype.SecretsProvidersV1{Type: e.sm.Type()}
+	if state := e.sm.State(); state != nil {
+		rm, err := json.Marshal(state)
+		if err != nil {
+			return apitype.SecretsProvidersV1{}, err
+		}
+		provider.State = rm
+	}
+	return provider, nil
+}
+
+func (e *secretsManagerStateEncrypter) EncryptState(plaintext []byte) (string, error) {
+	enc, err := e.sm.Encrypter()
+	if err != nil {
+		return "", err
+	}
+	return enc.EncryptValue(string(plaintext))
+}
+
+func (e *secretsManagerStateEncrypter) DecryptState(ciphertext string) ([]byte, error) {
+	dec, err := e.sm.Decrypter()
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := dec.DecryptValue(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(plaintext), nil
+}
+
+// EncryptCheckpoint returns the given checkpoint encrypted at rest by the given encrypter.
+func EncryptCheckpoint(chk *apitype.VersionedCheckpoint, enc StateEncrypter) (*apitype.VersionedCheckpoint, error) {
+	if chk.Encrypted != nil {
+		return chk, nil
+	}
+
+	encrypted, err := encryptState(chk.Checkpoint, enc)
+	if err != nil {
+		return nil, err
+	}
+	return &apitype.VersionedCheckpoint{
+		Version:   chk.Version,
+		Encrypted: encrypted,
+	}, nil
+}
+
+// DecryptCheckpoint returns the given checkpoint with its json document decrypted, if it is encrypted at rest. The
+// key it is decrypted with is found by reconstructing the secrets manager that encrypted it with the given provider.
+func DecryptCheckpoint(chk *apitype.VersionedCheckpoint,
+	secretsProv SecretsProvider) (*apitype.VersionedCheckpoint, error) {
+	if chk.Encrypted == nil {
+		return chk, nil
+	}
+
+	plaintext, err := decryptState(chk.Encrypted, secretsProv)
+	if err != nil {
+		return nil, errors.Wrap(err, "decrypting checkpoint")
+	}
+	return &apitype.VersionedCheckpoint{
+		Version:    chk.Version,
+		Checkpoint: plaintext,
+	}, nil
+}
+
+// EncryptDeployment returns the given deployment encrypted at rest by the given encrypter, for backends that persist
+// deployments rather than checkpoints.
+func EncryptDeployment(d *apitype.UntypedDeployment, enc StateEncrypter) (*apitype.UntypedDeployment, error) {
+	if d.Encrypted != nil {
+		return d, nil
+	}
+
+	encrypted, err := encryptState(d.Deployment, enc)
+	if err != nil {
+		return nil, err
+	}
+	return &apitype.UntypedDeployment{
+		Version:   d.Version,
+		Encrypted: encrypted,
+	}, nil
+}
+
+// DecryptDeployment returns the given deployment with its json document decrypted, if it is encrypted at rest. The key
+// it is decrypted with is found by reconstructing the secrets manager that encrypted it with the given provider.
+func DecryptDeployment(d *apitype.UntypedDeployment,
+	secretsProv SecretsProvider) (*apitype.UntypedDeployment, error) {
+	if d.Encrypted == nil {
+		return d, nil
+	}
+
+	plaintext, err := decryptState(d.Encrypted, secretsProv)
+	if err != nil {
+		return nil, errors.Wrap(err, "decrypting deployment")
+	}
+	return &apitype.UntypedDeployment{
+		Version:    d.Version,
+		Deployment: plaintext,
+	}, nil
+}
+
+func encryptState(plaintext json.RawMessage, enc StateEncrypter) (*apitype.EncryptedCheckpointV1, error) {
+	provider, err := enc.SecretsProvider()
+	if err != nil {
+		return nil, errors.Wrap(err, "describing the state's secrets provider")
+	}
+	ciphertext, err := enc.EncryptState(plaintext)
+	if err != nil {
+		return nil, errors.Wrap(err, "encrypting state")
+	}
+	return &apitype.EncryptedCheckpointV1{
+		SecretsProviders: provider,
+		Ciphertext:       ciphertext,
+	}, nil
+}
+
+func decryptState(encrypted *apitype.EncryptedCheckpointV1, secretsProv SecretsProvider) (json.RawMessage, error) {
+	sm, err := secretsProv.OfType(encrypted.SecretsProviders.Type, encrypted.SecretsProviders.State)
+	if err != nil {
+		return nil, err
+	}
+	enc, err := NewStateEncrypter(sm)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := enc.DecryptState(encrypted.Ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(plaintext), nil
+}
@@ -0,0 +1,246 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+)
+
+// SecretPath identifies values of a deployment whose secrets may be revealed: the inputs or outputs of a resource, or
+// the values at a property path within them.
+type SecretPath struct {
+	// URN is the resource whose values are identified, or empty for the stack's root resource, whose outputs are the
+	// stack's outputs.
+	URN resource.URN
+	// Path is the path to the values, which begins with "inputs" or "outputs".
+	Path resource.PropertyPath
+}
+
+// ParseSecretPath parses a path of the form `[<urn>#]{inputs|outputs}[.<property path>]`, such as
+// `outputs.dbPassword`, which identifies the stack output named dbPassword.
+func ParseSecretPath(s string) (SecretPath, error) {
+	var urn resource.URN
+	if strings.HasPrefix(s, "urn:") {
+		i := strings.Index(s, "#")
+		if i < 0 {
+			return SecretPath{}, errors.Errorf("secret path '%s' must separate the URN from the property path "+
+				"with a '#'", s)
+		}
+		urn, s = resource.URN(s[:i]), s[i+1:]
+	}
+
+	path, err := resource.ParsePropertyPath(s)
+	if err != nil {
+		return SecretPath{}, errors.Wrapf(err, "invalid secret path '%s'", s)
+	}
+	if len(path) == 0 || (path[0] != "inputs" && path[0] != "outputs") {
+		return SecretPath{}, errors.Errorf("secret path '%s' must begin with 'inputs' or 'outputs'", s)
+	}
+	return SecretPath{URN: urn, Path: path}, nil
+}
+
+// RevealSecrets decrypts the secrets at the given paths of the deployment -- or every secret, if no paths are given
+// -- with the given decrypter, and replaces them with their plaintext values. A secret is revealed if its path and
+// a given path are the same, or if either contains the other. It returns the number of secrets that were revealed.
+func RevealSecrets(deployment *apitype.DeploymentV3, dec config.Decrypter, paths []SecretPath) (int, error) {
+	// Check that the given resources exist, so that a typo is not mistaken for there being nothing to reveal.
+	urns := make(map[resource.URN]bool)
+	for _, res := range deployment.Resources {
+		urns[res.URN] = true
+	}
+	for _, p := range paths {
+		if p.URN != "" && !urns[p.URN] {
+			return 0, errors.Errorf("the deployment has no resource with URN '%s'", p.URN)
+		}
+	}
+
+	r := &revealer{dec: dec, all: len(paths) == 0}
+	for i, res := range deployment.Resources {
+		r.paths = nil
+		for _, p := range paths {
+			if p.URN == res.URN || (p.URN == "" && res.Type == resource.RootStackType) {
+				r.paths = append(r.paths, p.Path)
+			}
+		}
+		if !r.all && len(r.paths) == 0 {
+			continue
+		}
+
+		inputs, err := r.properties(res.Inputs, resource.PropertyPath{"inputs"})
+		if err != nil {
+			return 0, errors.Wrapf(err, "revealing the inputs of %s", res.URN)
+		}
+		outputs, err := r.properties(res.Outputs, resource.PropertyPath{"outputs"})
+		if err != nil {
+			return 0, errors.Wrapf(err, "revealing the outputs of %s", res.URN)
+		}
+		deployment.Resources[i].Inputs, deployment.Resources[i].Outputs = inputs, outputs
+	}
+	return r.revealed, nil
+}
+
+// RevealPropertySecrets returns a copy of the given inputs or outputs of a resource -- as named by prefix -- in which
+// the secrets at the given paths, or every secret if no paths are given, are replaced with their plaintext values.
+// The other secrets are left as they are.
+func RevealPropertySecrets(res *resource.State, prefix string, props resource.PropertyMap,
+	paths []SecretPath) resource.PropertyMap {
+
+	r := &revealer{all: len(paths) == 0}
+	for _, p := range paths {
+		if p.URN == res.URN || (p.URN == "" && res.Type == resource.RootStackType) {
+			r.paths = append(r.paths, p.Path)
+		}
+	}
+	return r.propertyMap(props, resource.PropertyPath{prefix})
+}
+
+// revealer replaces the secrets of a resource's properties with their plaintext values.
+type revealer struct {
+	dec      config.Decrypter
+	all      bool                    // true if every secret is to be revealed.
+	paths    []resource.PropertyPath // the paths of the current resource's values to reveal.
+	revealed int                     // the number of secrets that have been revealed.
+}
+
+// reveals returns true if the secret at the given path is to be revealed.
+func (r *revealer) reveals(path resource.PropertyPath) bool {
+	if r.all {
+		return true
+	}
+	for _, p := range r.paths {
+		if hasPathPrefix(path, p) || hasPathPrefix(p, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *revealer) properties(props map[string]interface{}, path resource.PropertyPath) (map[string]interface{},
+	error) {
+
+	if props == nil {
+		return nil, nil
+	}
+	result := make(map[string]interface{}, len(props))
+	for k, v := range props {
+		rv, err := r.value(v, append(path[:len(path):len(path)], k))
+		if err != nil {
+			return nil, err
+		}
+		result[k] = rv
+	}
+	return result, nil
+}
+
+func (r *revealer) value(v interface{}, path resource.PropertyPath) (interface{}, error) {
+	switch v := v.(type) {
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, elem := range v {
+			relem, err := r.value(elem, append(path[:len(path):len(path)], i))
+			if err != nil {
+				return nil, err
+			}
+			result[i] = relem
+		}
+		return result, nil
+	case map[string]interface{}:
+		if v[resource.SigKey] != resource.SecretSig {
+			return r.properties(v, path)
+		}
+		if !r.reveals(path) {
+			return v, nil
+		}
+
+		ciphertext, ok := v["ciphertext"].(string)
+		if !ok {
+			return nil, errors.Errorf("malformed secret value at %v: missing ciphertext", path)
+		}
+		plaintext, err := r.dec.DecryptValue(ciphertext)
+		if err != nil {
+			return nil, errors.Wrap(err, "decrypting secret value")
+		}
+		var elem interface{}
+		if err = json.Unmarshal([]byte(plaintext), &elem); err != nil {
+			return nil, err
+		}
+		r.revealed++
+
+		// The elements of a secret are not encrypted again, but may still be marked as secrets; reveal those too.
+		inner := &revealer{dec: config.NopDecrypter, all: true}
+		elem, err = inner.value(elem, path)
+		if err != nil {
+			return nil, err
+		}
+		return elem, nil
+	default:
+		return v, nil
+	}
+}
+
+func (r *revealer) propertyMap(props resource.PropertyMap, path resource.PropertyPath) resource.PropertyMap {
+	if props == nil {
+		return nil
+	}
+	result := make(resource.PropertyMap, len(props))
+	for k, v := range props {
+		result[k] = r.propertyValue(v, append(path[:len(path):len(path)], string(k)))
+	}
+	return result
+}
+
+func (r *revealer) propertyValue(v resource.PropertyValue, path resource.PropertyPath) resource.PropertyValue {
+	switch {
+	case v.IsArray():
+		result := make([]resource.PropertyValue, len(v.ArrayValue()))
+		for i, elem := range v.ArrayValue() {
+			result[i] = r.propertyValue(elem, append(path[:len(path):len(path)], i))
+		}
+		return resource.NewArrayProperty(result)
+	case v.IsObject():
+		return resource.NewObjectProperty(r.propertyMap(v.ObjectValue(), path))
+	case v.IsSecret():
+		if !r.reveals(path) {
+			return v
+		}
+		r.revealed++
+
+		// Reveal any secrets within the secret, too.
+		inner := &revealer{all: true}
+		return inner.propertyValue(v.SecretValue().Element, path)
+	default:
+		return v
+	}
+}
+
+// hasPathPrefix returns true if the given path begins with the given prefix.
+func hasPathPrefix(path, prefix resource.PropertyPath) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
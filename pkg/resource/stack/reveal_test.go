@@ -0,0 +1,174 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/secrets/b64"
+)
+
+func TestParseSecretPath(t *testing.T) {
+	p, err := ParseSecretPath("outputs.dbPassword")
+	assert.NoError(t, err)
+	assert.Equal(t, SecretPath{Path: resource.PropertyPath{"outputs", "dbPassword"}}, p)
+
+	p, err = ParseSecretPath("urn:pulumi:prod::webshop::aws:rds/instance:Instance::db#inputs.users[0]")
+	assert.NoError(t, err)
+	assert.Equal(t, SecretPath{
+		URN:  "urn:pulumi:prod::webshop::aws:rds/instance:Instance::db",
+		Path: resource.PropertyPath{"inputs", "users", 0},
+	}, p)
+
+	_, err = ParseSecretPath("dbPassword")
+	assert.Error(t, err)
+	_, err = ParseSecretPath("urn:pulumi:prod::webshop::aws:rds/instance:Instance::db")
+	assert.Error(t, err)
+}
+
+func TestRevealSecrets(t *testing.T) {
+	sm := b64.NewBase64SecretsManager()
+	enc, err := sm.Encrypter()
+	assert.NoError(t, err)
+	dec, err := sm.Decrypter()
+	assert.NoError(t, err)
+
+	serialize := func(props resource.PropertyMap) map[string]interface{} {
+		// Round trip the properties through JSON, as exported deployments are.
+		serialized, err := SerializeProperties(props, enc)
+		assert.NoError(t, err)
+		bytes, err := json.Marshal(serialized)
+		assert.NoError(t, err)
+		var result map[string]interface{}
+		assert.NoError(t, json.Unmarshal(bytes, &result))
+		return result
+	}
+
+	stackURN := resource.URN("urn:pulumi:prod::webshop::pulumi:pulumi:Stack::webshop-prod")
+	dbURN := resource.URN("urn:pulumi:prod::webshop::aws:rds/instance:Instance::orders-db")
+	newDeployment := func() *apitype.DeploymentV3 {
+		return &apitype.DeploymentV3{
+			Resources: []apitype.ResourceV3{
+				{
+					URN:  stackURN,
+					Type: resource.RootStackType,
+					Outputs: serialize(resource.PropertyMap{
+						"dbPassword": resource.MakeSecret(resource.NewStringProperty("hunter2")),
+						"apiKey":     resource.MakeSecret(resource.NewStringProperty("abc123")),
+					}),
+				},
+				{
+					URN:  dbURN,
+					Type: dbURN.Type(),
+					Inputs: serialize(resource.PropertyMap{
+						"users": resource.NewArrayProperty([]resource.PropertyValue{
+							resource.MakeSecret(resource.NewStringProperty("admin")),
+						}),
+					}),
+					Outputs: serialize(resource.PropertyMap{
+						"password": resource.MakeSecret(resource.NewStringProperty("hunter2")),
+					}),
+				},
+			},
+		}
+	}
+	isSecret := func(v interface{}) bool {
+		m, ok := v.(map[string]interface{})
+		return ok && m[resource.SigKey] == resource.SecretSig
+	}
+
+	// Without paths, every secret is revealed.
+	dep := newDeployment()
+	n, err := RevealSecrets(dep, dec, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, n)
+	assert.Equal(t, "hunter2", dep.Resources[0].Outputs["dbPassword"])
+	assert.Equal(t, []interface{}{"admin"}, dep.Resources[1].Inputs["users"])
+
+	// Paths without a URN refer to the stack's outputs.
+	dep = newDeployment()
+	path, err := ParseSecretPath("outputs.dbPassword")
+	assert.NoError(t, err)
+	n, err = RevealSecrets(dep, dec, []SecretPath{path})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "hunter2", dep.Resources[0].Outputs["dbPassword"])
+	assert.True(t, isSecret(dep.Resources[0].Outputs["apiKey"]))
+	assert.True(t, isSecret(dep.Resources[1].Outputs["password"]))
+
+	// Paths may refer to a whole property map of another resource.
+	dep = newDeployment()
+	path, err = ParseSecretPath(string(dbURN) + "#inputs")
+	assert.NoError(t, err)
+	n, err = RevealSecrets(dep, dec, []SecretPath{path})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []interface{}{"admin"}, dep.Resources[1].Inputs["users"])
+	assert.True(t, isSecret(dep.Resources[1].Outputs["password"]))
+
+	path, err = ParseSecretPath("urn:pulumi:prod::webshop::aws:s3/bucket:Bucket::missing#outputs")
+	assert.NoError(t, err)
+	_, err = RevealSecrets(newDeployment(), dec, []SecretPath{path})
+	assert.Error(t, err)
+}
+
+func TestRevealPropertySecrets(t *testing.T) {
+	root := &resource.State{
+		URN:  resource.NewURN("prod", "webshop", "", resource.RootStackType, "webshop-prod"),
+		Type: resource.RootStackType,
+	}
+	outputs := resource.PropertyMap{
+		"dbPassword": resource.MakeSecret(resource.NewStringProperty("hunter2")),
+		"apiKey":     resource.MakeSecret(resource.NewStringProperty("abc")),
+		"users": resource.NewArrayProperty([]resource.PropertyValue{
+			resource.MakeSecret(resource.NewObjectProperty(resource.PropertyMap{
+				"token": resource.MakeSecret(resource.NewStringProperty("xyz")),
+			})),
+		}),
+	}
+
+	// Only the secrets at the given paths are revealed.
+	p, err := ParseSecretPath("outputs.dbPassword")
+	assert.NoError(t, err)
+	revealed := RevealPropertySecrets(root, "outputs", outputs, []SecretPath{p})
+	assert.Equal(t, resource.NewStringProperty("hunter2"), revealed["dbPassword"])
+	assert.True(t, revealed["apiKey"].IsSecret())
+	assert.True(t, revealed["users"].ArrayValue()[0].IsSecret())
+
+	// Revealing a secret reveals the secrets within it.
+	p, err = ParseSecretPath("outputs.users[0]")
+	assert.NoError(t, err)
+	revealed = RevealPropertySecrets(root, "outputs", outputs, []SecretPath{p})
+	assert.Equal(t, resource.NewObjectProperty(resource.PropertyMap{"token": resource.NewStringProperty("xyz")}),
+		revealed["users"].ArrayValue()[0])
+
+	// Paths that name other resources reveal nothing.
+	p, err = ParseSecretPath("urn:pulumi:prod::webshop::aws:rds/instance:Instance::db#outputs")
+	assert.NoError(t, err)
+	revealed = RevealPropertySecrets(root, "outputs", outputs, []SecretPath{p})
+	assert.Equal(t, outputs, revealed)
+
+	// Without paths, every secret is revealed.
+	revealed = RevealPropertySecrets(root, "outputs", outputs, nil)
+	assert.Equal(t, resource.NewStringProperty("abc"), revealed["apiKey"])
+
+	// The given properties are not changed.
+	assert.True(t, outputs["dbPassword"].IsSecret())
+}
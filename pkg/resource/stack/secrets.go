@@ -24,6 +24,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/secrets/cloud"
 	"github.com/pulumi/pulumi/pkg/secrets/passphrase"
 	"github.com/pulumi/pulumi/pkg/secrets/service"
+	"github.com/pulumi/pulumi/pkg/secrets/vault"
 )
 
 // DefaultSecretsProvider is the default SecretsProvider to use when deserializing deployments.
@@ -54,6 +55,8 @@ func (defaultSecretsProvider) OfType(ty string, state json.RawMessage) (secrets.
 		sm, err = service.NewServiceSecretsManagerFromState(state)
 	case cloud.Type:
 		sm, err = cloud.NewCloudSecretsManagerFromState(state)
+	case vault.Type:
+		sm, err = vault.NewVaultSecretsManagerFromState(state)
 	default:
 		return nil, errors.Errorf("no known secrets provider for type %q", ty)
 	}
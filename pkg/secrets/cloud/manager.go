@@ -28,6 +28,7 @@ import (
 
 	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/secrets"
+	"github.com/pulumi/pulumi/pkg/util/contract"
 )
 
 // Type is the type of secrets managed by this secrets provider
@@ -62,6 +63,7 @@ func GenerateNewDataKey(url string) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer contract.IgnoreClose(keeper)
 	return keeper.Encrypt(context.Background(), plaintextDataKey)
 }
 
@@ -72,6 +74,7 @@ func NewCloudSecretsManager(url string, encryptedDataKey []byte) (*Manager, erro
 	if err != nil {
 		return nil, err
 	}
+	defer contract.IgnoreClose(keeper)
 	plaintextDataKey, err := keeper.Decrypt(context.Background(), encryptedDataKey)
 	if err != nil {
 		return nil, err
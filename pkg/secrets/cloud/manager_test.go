@@ -0,0 +1,72 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	_ "gocloud.dev/secrets/localsecrets" // support for base64key://
+)
+
+func TestCloudSecretsManager(t *testing.T) {
+	// The local keeper stands in for a key management service.
+	url := "base64key://" + base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))
+
+	dataKey, err := GenerateNewDataKey(url)
+	if !assert.NoError(t, err) {
+		return
+	}
+	m, err := NewCloudSecretsManager(url, dataKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, Type, m.Type())
+	assert.Equal(t, dataKey, m.EncryptedKey())
+
+	encrypter, err := m.Encrypter()
+	assert.NoError(t, err)
+	ciphertext, err := encrypter.EncryptValue("hush")
+	assert.NoError(t, err)
+	assert.NotContains(t, ciphertext, "hush")
+
+	// The manager's state holds only the URL and the wrapped data key, and is enough to decrypt its secrets.
+	state, err := json.Marshal(m.State())
+	if !assert.NoError(t, err) {
+		return
+	}
+	var fields map[string]interface{}
+	assert.NoError(t, json.Unmarshal(state, &fields))
+	assert.Len(t, fields, 2)
+	assert.Equal(t, url, fields["url"])
+
+	restored, err := NewCloudSecretsManagerFromState(state)
+	if !assert.NoError(t, err) {
+		return
+	}
+	decrypter, err := restored.Decrypter()
+	assert.NoError(t, err)
+	plaintext, err := decrypter.DecryptValue(ciphertext)
+	assert.NoError(t, err)
+	assert.Equal(t, "hush", plaintext)
+
+	// A data key wrapped by a different key can't be unwrapped.
+	other := "base64key://" + base64.StdEncoding.EncodeToString([]byte(strings.Repeat("o", 32)))
+	_, err = NewCloudSecretsManager(other, dataKey)
+	assert.Error(t, err)
+}
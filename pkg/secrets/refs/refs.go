@@ -0,0 +1,129 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package refs resolves configuration values that refer to secrets held by external secret managers, such as
+// `vault://secret/data/db#password`, `awssm://prod/db?region=us-west-2#password`, or
+// `gcpsm://projects/p/secrets/db`. References are resolved when the configuration is decrypted for a deployment, so the
+// secret values themselves are never stored in configuration files or state.
+package refs
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+)
+
+// Resolver fetches the value of a secret from an external secret manager.
+type Resolver interface {
+	Resolve(ctx context.Context, ref *url.URL) (string, error)
+}
+
+// resolvers maps reference URL schemes to the resolvers that handle them.
+var resolvers = map[string]Resolver{
+	"vault": vaultResolver{},
+	"awssm": awsSecretsManagerResolver{},
+	"gcpsm": gcpSecretManagerResolver{},
+}
+
+// IsReference returns true if the given configuration value is a reference to an external secret.
+func IsReference(value string) bool {
+	i := strings.Index(value, "://")
+	if i == -1 {
+		return false
+	}
+	_, has := resolvers[value[:i]]
+	return has
+}
+
+// ReferenceConfig returns a copy of the given configuration in which every plaintext reference to an external secret
+// has been turned into a secure value whose ciphertext is the reference itself. This ensures that the resolved values
+// are treated as secrets by the engine and never persisted in plaintext. The result must be decrypted with a
+// Decrypter returned by NewDecrypter.
+func ReferenceConfig(cfg config.Map) config.Map {
+	result := make(config.Map, len(cfg))
+	for k, v := range cfg {
+		if !v.Secure() {
+			if s, err := v.Value(config.NopDecrypter); err == nil && IsReference(s) {
+				v = config.NewSecureValue(s)
+			}
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// resolverDecrypter resolves references to external secrets, and delegates all other ciphertexts to an underlying
+// decrypter. Resolved values are cached for the lifetime of the decrypter.
+type resolverDecrypter struct {
+	decrypter config.Decrypter
+	onResolve func(ref string)
+
+	cacheLock sync.Mutex
+	cache     map[string]string
+}
+
+// NewDecrypter returns a decrypter that resolves references to external secrets and delegates all other ciphertexts to
+// the given decrypter. The onResolve callback, if any, is invoked with each reference the first time it is resolved,
+// so that secret accesses can be audited.
+func NewDecrypter(d config.Decrypter, onResolve func(ref string)) config.Decrypter {
+	return &resolverDecrypter{decrypter: d, onResolve: onResolve, cache: make(map[string]string)}
+}
+
+func (d *resolverDecrypter) DecryptValue(ciphertext string) (string, error) {
+	if !IsReference(ciphertext) {
+		return d.decrypter.DecryptValue(ciphertext)
+	}
+
+	d.cacheLock.Lock()
+	defer d.cacheLock.Unlock()
+
+	if value, has := d.cache[ciphertext]; has {
+		return value, nil
+	}
+
+	value, err := Resolve(context.Background(), ciphertext)
+	if err != nil {
+		return "", err
+	}
+	d.cache[ciphertext] = value
+
+	logging.V(5).Infof("resolved external secret reference %s", ciphertext)
+	if d.onResolve != nil {
+		d.onResolve(ciphertext)
+	}
+	return value, nil
+}
+
+// Resolve fetches the value of the given reference to an external secret.
+func Resolve(ctx context.Context, ref string) (string, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing secret reference %s", ref)
+	}
+	resolver, has := resolvers[u.Scheme]
+	if !has {
+		return "", errors.Errorf("unsupported secret reference scheme '%s'", u.Scheme)
+	}
+	value, err := resolver.Resolve(ctx, u)
+	if err != nil {
+		return "", errors.Wrapf(err, "resolving secret reference %s", ref)
+	}
+	return value, nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package refs
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource/config"
+)
+
+// countingResolver resolves every reference to its secret name, counting how often it is called.
+type countingResolver struct {
+	calls *int
+}
+
+func (r countingResolver) Resolve(ctx context.Context, ref *url.URL) (string, error) {
+	*r.calls++
+	return secretName(ref), nil
+}
+
+func TestIsReference(t *testing.T) {
+	assert.True(t, IsReference("vault://secret/data/db#password"))
+	assert.True(t, IsReference("awssm://prod/db?region=us-west-2"))
+	assert.True(t, IsReference("gcpsm://projects/p/secrets/db"))
+	assert.False(t, IsReference("https://example.com"))
+	assert.False(t, IsReference("hunter2"))
+}
+
+func TestResolverDecrypter(t *testing.T) {
+	calls := 0
+	resolvers["test"] = countingResolver{calls: &calls}
+	defer delete(resolvers, "test")
+
+	cfg := ReferenceConfig(config.Map{
+		config.MustMakeKey("proj", "password"): config.NewValue("test://db/password"),
+		config.MustMakeKey("proj", "region"):   config.NewValue("us-west-2"),
+	})
+	assert.True(t, cfg[config.MustMakeKey("proj", "password")].Secure())
+	assert.False(t, cfg[config.MustMakeKey("proj", "region")].Secure())
+
+	var audited []string
+	d := NewDecrypter(config.NewPanicCrypter(), func(ref string) { audited = append(audited, ref) })
+	decrypted, err := cfg.Decrypt(d)
+	assert.NoError(t, err)
+	assert.Equal(t, "db/password", decrypted[config.MustMakeKey("proj", "password")])
+	assert.Equal(t, "us-west-2", decrypted[config.MustMakeKey("proj", "region")])
+
+	// Resolved values are cached and only audited once.
+	_, err = cfg.Decrypt(d)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, []string{"test://db/password"}, audited)
+}
@@ -0,0 +1,183 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package refs
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	vault "github.com/hashicorp/vault/api"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2/google"
+
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+// secretName returns the name of the secret referred to by the given URL, which is made up of its host and path.
+func secretName(ref *url.URL) string {
+	return strings.TrimSuffix(ref.Host+ref.Path, "/")
+}
+
+// selectField returns the given field of a secret whose value is a JSON object. If no field is requested, the value is
+// returned as-is.
+func selectField(value, field string) (string, error) {
+	if field == "" {
+		return value, nil
+	}
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &obj); err != nil {
+		return "", errors.Errorf("secret is not a JSON object, so field '%s' cannot be selected", field)
+	}
+	return fieldOf(obj, field)
+}
+
+// fieldOf returns the given field of a secret, which must be a string.
+func fieldOf(obj map[string]interface{}, field string) (string, error) {
+	v, has := obj[field]
+	if !has {
+		return "", errors.Errorf("secret has no field '%s'", field)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", errors.Errorf("field '%s' of secret is not a string", field)
+	}
+	return s, nil
+}
+
+// vaultResolver resolves references of the form `vault://<path>#<field>` using the Vault server and token configured
+// in the environment (VAULT_ADDR and VAULT_TOKEN). Both KV version 1 and version 2 secrets are supported. The field
+// may be omitted if the secret has exactly one.
+type vaultResolver struct{}
+
+func (vaultResolver) Resolve(ctx context.Context, ref *url.URL) (string, error) {
+	client, err := vault.NewClient(vault.DefaultConfig())
+	if err != nil {
+		return "", err
+	}
+	secret, err := client.Logical().Read(secretName(ref))
+	if err != nil {
+		return "", err
+	}
+	if secret == nil || secret.Data == nil {
+		return "", errors.New("secret not found")
+	}
+
+	data := secret.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		// KV version 2 nests the secret's fields beneath its metadata.
+		data = nested
+	}
+
+	field := ref.Fragment
+	if field == "" {
+		if len(data) != 1 {
+			return "", errors.New("secret has more than one field, so one must be selected with '#<field>'")
+		}
+		for k := range data {
+			field = k
+		}
+	}
+	return fieldOf(data, field)
+}
+
+// awsSecretsManagerResolver resolves references of the form `awssm://<secret-id>?region=<region>#<field>` using AWS
+// Secrets Manager. The region, version stage (`?stage=`), and field are optional.
+type awsSecretsManagerResolver struct{}
+
+func (awsSecretsManagerResolver) Resolve(ctx context.Context, ref *url.URL) (string, error) {
+	cfg := aws.NewConfig()
+	if region := ref.Query().Get("region"); region != "" {
+		cfg = cfg.WithRegion(region)
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *cfg,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	input := &secretsmanager.GetSecretValueInput{SecretId: aws.String(secretName(ref))}
+	if stage := ref.Query().Get("stage"); stage != "" {
+		input.VersionStage = aws.String(stage)
+	}
+	out, err := secretsmanager.New(sess).GetSecretValueWithContext(ctx, input)
+	if err != nil {
+		return "", err
+	}
+
+	var value string
+	switch {
+	case out.SecretString != nil:
+		value = *out.SecretString
+	case out.SecretBinary != nil:
+		value = string(out.SecretBinary)
+	}
+	return selectField(value, ref.Fragment)
+}
+
+// gcpSecretManagerResolver resolves references of the form `gcpsm://projects/<p>/secrets/<s>[/versions/<v>]#<field>`
+// using Google Cloud Secret Manager and the application default credentials. The latest version is used if none is
+// given, and the field is optional.
+type gcpSecretManagerResolver struct{}
+
+const gcpSecretManagerEndpoint = "https://secretmanager.googleapis.com/v1"
+
+func (gcpSecretManagerResolver) Resolve(ctx context.Context, ref *url.URL) (string, error) {
+	name := secretName(ref)
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+
+	client, err := google.DefaultClient(ctx, "https://www.googleapis.com/auth/cloud-platform")
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/%s:access", gcpSecretManagerEndpoint, name), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer contract.IgnoreClose(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("secret manager returned %s", resp.Status)
+	}
+
+	var body struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", errors.Wrap(err, "decoding secret manager response")
+	}
+	value, err := base64.StdEncoding.DecodeString(body.Payload.Data)
+	if err != nil {
+		return "", errors.Wrap(err, "decoding secret payload")
+	}
+	return selectField(string(value), ref.Fragment)
+}
@@ -0,0 +1,269 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend/httpstate/client"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/secrets"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// EscrowKeyEnvVar is the environment variable holding the base64-encoded, 32-byte escrow key used to encrypt secrets
+// when the Pulumi service is unavailable.
+const EscrowKeyEnvVar = "PULUMI_SECRETS_ESCROW_KEY"
+
+// escrowPrefix marks ciphertexts that were encrypted under the escrow key. Since service ciphertexts are plain
+// base64, they can never start with this prefix.
+const escrowPrefix = "escrow:"
+
+// EscrowJournalEntry records a single value that was encrypted under the escrow key, and where it is stored, so that
+// it can later be re-wrapped by the service with `pulumi stack rewrap-secrets`.
+type EscrowJournalEntry struct {
+	config.ValueLocation
+
+	Time       time.Time `json:"time"`
+	Ciphertext string    `json:"ciphertext"`
+}
+
+// IsEscrowed returns true if the given ciphertext was encrypted under the escrow key.
+func IsEscrowed(ciphertext string) bool {
+	return strings.HasPrefix(ciphertext, escrowPrefix)
+}
+
+// escrowCrypter encrypts values with a primary crypter, falling back to a local escrow key if the primary crypter is
+// unavailable. Values encrypted under the escrow key are appended to a journal. If no escrow key is configured, values
+// are never escrowed, and values that were cannot be decrypted.
+type escrowCrypter struct {
+	primary     config.Crypter
+	escrow      config.Crypter // the crypter for the escrow key, or nil if none is configured.
+	journal     string
+	unavailable func(err error) bool
+
+	journalLock sync.Mutex
+}
+
+var _ config.LocatingEncrypter = (*escrowCrypter)(nil)
+
+func (c *escrowCrypter) EncryptValue(plaintext string) (string, error) {
+	return c.EncryptValueAt(plaintext, config.ValueLocation{})
+}
+
+func (c *escrowCrypter) EncryptValueAt(plaintext string, loc config.ValueLocation) (string, error) {
+	ciphertext, err := c.primary.EncryptValue(plaintext)
+	if err == nil || c.escrow == nil || !c.unavailable(err) {
+		return ciphertext, err
+	}
+
+	logging.Warningf("secrets service unavailable, encrypting under escrow key: %v", err)
+	escrowed, escrowErr := c.escrow.EncryptValue(plaintext)
+	if escrowErr != nil {
+		return "", errors.Wrap(escrowErr, "encrypting under escrow key")
+	}
+	escrowed = escrowPrefix + escrowed
+	if journalErr := c.record(escrowed, loc); journalErr != nil {
+		return "", errors.Wrap(journalErr, "journaling escrowed secret")
+	}
+	return escrowed, nil
+}
+
+func (c *escrowCrypter) DecryptValue(ciphertext string) (string, error) {
+	if !IsEscrowed(ciphertext) {
+		return c.primary.DecryptValue(ciphertext)
+	}
+	if c.escrow == nil {
+		return "", errors.Errorf("this secret was encrypted under the escrow key while the Pulumi service was "+
+			"unavailable; set %s to the escrow key to decrypt it, and run `pulumi stack rewrap-secrets` to "+
+			"re-encrypt it with the service", EscrowKeyEnvVar)
+	}
+	return c.escrow.DecryptValue(strings.TrimPrefix(ciphertext, escrowPrefix))
+}
+
+// record appends the given escrowed ciphertext, stored at the given location, to the journal.
+func (c *escrowCrypter) record(ciphertext string, loc config.ValueLocation) error {
+	c.journalLock.Lock()
+	defer c.journalLock.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(c.journal), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(c.journal, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer contract.IgnoreClose(f)
+
+	return json.NewEncoder(f).Encode(EscrowJournalEntry{ValueLocation: loc, Time: time.Now(), Ciphertext: ciphertext})
+}
+
+// isServiceUnavailable returns true if the given error indicates that the service could not be reached or failed to
+// handle the request, as opposed to rejecting it. Requests abandoned because their context was canceled, and responses
+// that could not be read, do not mean that the service is unavailable.
+func isServiceUnavailable(err error) bool {
+	switch err := errors.Cause(err).(type) {
+	case *apitype.ErrorResponse:
+		return err.Code >= http.StatusInternalServerError
+	case *url.Error:
+		return err.Err != context.Canceled
+	case net.Error:
+		return true
+	default:
+		return false
+	}
+}
+
+// getEscrowKey returns the escrow key configured in the environment, if any.
+func getEscrowKey() ([]byte, error) {
+	encoded := os.Getenv(EscrowKeyEnvVar)
+	if encoded == "" {
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decoding %s", EscrowKeyEnvVar)
+	}
+	if len(key) != config.SymmetricCrypterKeyBytes {
+		return nil, errors.Errorf("%s must be a base64-encoded %d byte key", EscrowKeyEnvVar,
+			config.SymmetricCrypterKeyBytes)
+	}
+	return key, nil
+}
+
+// newStackCrypter returns the crypter for the given stack's secrets. If an escrow key is configured locally and the
+// stack's organization allows it, the crypter falls back to the escrow key when the service is unavailable. Values
+// previously encrypted under the escrow key are decrypted with it if it is configured, and otherwise fail to decrypt.
+func newStackCrypter(c *client.Client, id client.StackIdentifier) (*escrowCrypter, error) {
+	crypter := newServiceCrypter(c, id)
+
+	journal, err := workspace.GetSecretsEscrowJournalPath(id.Owner, id.Project, id.Stack)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting escrow journal path")
+	}
+
+	key, err := getEscrowKey()
+	if err != nil {
+		return nil, err
+	} else if key == nil {
+		return &escrowCrypter{primary: crypter, journal: journal, unavailable: isServiceUnavailable}, nil
+	}
+
+	enabled, err := c.GetSecretsEscrowEnabled(context.Background(), id.Owner)
+	if err != nil {
+		// Without confirmation from the org, secrets are never escrowed. Values previously encrypted under the escrow
+		// key can still be decrypted, however.
+		logging.V(5).Infof("could not determine whether secrets escrow is enabled for %s: %v", id.Owner, err)
+		enabled = false
+	}
+
+	unavailable := isServiceUnavailable
+	if !enabled {
+		unavailable = func(error) bool { return false }
+	}
+	return &escrowCrypter{
+		primary:     crypter,
+		escrow:      config.NewSymmetricCrypter(key),
+		journal:     journal,
+		unavailable: unavailable,
+	}, nil
+}
+
+// Rewrapper re-encrypts a stack's values that were encrypted under the escrow key with the Pulumi service, once it is
+// reachable again.
+type Rewrapper struct {
+	crypter *escrowCrypter
+}
+
+// NewRewrapper returns a Rewrapper for the stack whose secrets are managed by the given secrets manager, which must be
+// a Pulumi service secrets manager. The escrow key must be configured in the environment.
+func NewRewrapper(sm secrets.Manager) (*Rewrapper, error) {
+	ssm, ok := sm.(*serviceSecretsManager)
+	if !ok {
+		return nil, errors.New("only secrets encrypted by the Pulumi service can have been escrowed")
+	}
+	if ssm.crypter.escrow == nil {
+		return nil, errors.Errorf("%s must be set to the escrow key to re-wrap escrowed secrets", EscrowKeyEnvVar)
+	}
+	return &Rewrapper{crypter: ssm.crypter}, nil
+}
+
+// Rewrap returns the given ciphertext re-encrypted by the service if it was encrypted under the escrow key, and
+// otherwise returns it as it is. The service must be available.
+func (r *Rewrapper) Rewrap(ciphertext string) (string, error) {
+	if !IsEscrowed(ciphertext) {
+		return ciphertext, nil
+	}
+	plaintext, err := r.crypter.DecryptValue(ciphertext)
+	if err != nil {
+		return "", errors.Wrap(err, "decrypting escrowed value")
+	}
+	rewrapped, err := r.crypter.primary.EncryptValue(plaintext)
+	if err != nil {
+		return "", errors.Wrap(err, "re-encrypting escrowed value with the service")
+	}
+	return rewrapped, nil
+}
+
+// Journal returns the entries of the stack's escrow journal, in the order they were recorded.
+func (r *Rewrapper) Journal() ([]EscrowJournalEntry, error) {
+	r.crypter.journalLock.Lock()
+	defer r.crypter.journalLock.Unlock()
+
+	f, err := os.Open(r.crypter.journal)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer contract.IgnoreClose(f)
+
+	var entries []EscrowJournalEntry
+	dec := json.NewDecoder(f)
+	for dec.More() {
+		var entry EscrowJournalEntry
+		if err = dec.Decode(&entry); err != nil {
+			return nil, errors.Wrapf(err, "reading escrow journal %s", r.crypter.journal)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// ClearJournal removes the stack's escrow journal, once every escrowed value has been re-wrapped.
+func (r *Rewrapper) ClearJournal() error {
+	r.crypter.journalLock.Lock()
+	defer r.crypter.journalLock.Unlock()
+
+	if err := os.Remove(r.crypter.journal); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+)
+
+// flakyCrypter fails to encrypt with the given error, if any, and otherwise prefixes its input.
+type flakyCrypter struct {
+	err error
+}
+
+func (c *flakyCrypter) EncryptValue(plaintext string) (string, error) {
+	if c.err != nil {
+		return "", c.err
+	}
+	return "svc" + plaintext, nil
+}
+
+func (c *flakyCrypter) DecryptValue(ciphertext string) (string, error) {
+	return strings.TrimPrefix(ciphertext, "svc"), nil
+}
+
+func TestEscrowCrypter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-escrow-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	primary := &flakyCrypter{}
+	c := &escrowCrypter{
+		primary:     primary,
+		escrow:      config.NewSymmetricCrypter(make([]byte, config.SymmetricCrypterKeyBytes)),
+		journal:     filepath.Join(dir, "org", "proj", "dev.jsonl"),
+		unavailable: isServiceUnavailable,
+	}
+
+	// While the service is available, it is used.
+	ciphertext, err := c.EncryptValue("hunter2")
+	assert.NoError(t, err)
+	assert.Equal(t, "svchunter2", ciphertext)
+
+	// Rejected requests are not escrowed.
+	primary.err = &apitype.ErrorResponse{Code: 403}
+	_, err = c.EncryptValue("hunter2")
+	assert.Error(t, err)
+
+	// If the service is unavailable, the value is escrowed and journaled, along with where it is stored.
+	primary.err = errors.Wrap(connectionRefused(), "performing HTTP request")
+	loc := config.ValueLocation{ConfigKey: "proj:password"}
+	ciphertext, err = c.EncryptValueAt("hunter2", loc)
+	assert.NoError(t, err)
+	assert.True(t, IsEscrowed(ciphertext))
+
+	rewrapper, err := NewRewrapper(&serviceSecretsManager{crypter: c})
+	if !assert.NoError(t, err) {
+		return
+	}
+	journal, err := rewrapper.Journal()
+	assert.NoError(t, err)
+	if assert.Len(t, journal, 1) {
+		assert.Equal(t, loc, journal[0].ValueLocation)
+		assert.Equal(t, ciphertext, journal[0].Ciphertext)
+	}
+
+	// Both kinds of ciphertext can be decrypted.
+	plaintext, err := c.DecryptValue(ciphertext)
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter2", plaintext)
+	plaintext, err = c.DecryptValue("svchunter2")
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter2", plaintext)
+
+	// Once the service is available again, escrowed values are re-wrapped by it, and others are left as they are.
+	primary.err = nil
+	rewrapped, err := rewrapper.Rewrap(ciphertext)
+	assert.NoError(t, err)
+	assert.Equal(t, "svchunter2", rewrapped)
+	rewrapped, err = rewrapper.Rewrap("svcother")
+	assert.NoError(t, err)
+	assert.Equal(t, "svcother", rewrapped)
+
+	assert.NoError(t, rewrapper.ClearJournal())
+	journal, err = rewrapper.Journal()
+	assert.NoError(t, err)
+	assert.Empty(t, journal)
+
+	// Without the escrow key, escrowed values fail to decrypt, rather than being sent to the service, and cannot be
+	// re-wrapped. Values are never escrowed.
+	c.escrow = nil
+	_, err = c.DecryptValue(ciphertext)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), EscrowKeyEnvVar)
+	}
+	_, err = NewRewrapper(&serviceSecretsManager{crypter: c})
+	assert.Error(t, err)
+	primary.err = errors.Wrap(connectionRefused(), "performing HTTP request")
+	_, err = c.EncryptValue("hunter2")
+	assert.Error(t, err)
+}
+
+// connectionRefused returns the error the HTTP client returns when the service cannot be reached.
+func connectionRefused() error {
+	return &url.Error{
+		Op:  "Post",
+		URL: "https://api.pulumi.com/api/stacks/org/proj/dev/encrypt",
+		Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")},
+	}
+}
+
+func TestIsServiceUnavailable(t *testing.T) {
+	// Server errors and network errors mean the service is unavailable.
+	assert.True(t, isServiceUnavailable(&apitype.ErrorResponse{Code: 503}))
+	assert.True(t, isServiceUnavailable(errors.Wrap(&apitype.ErrorResponse{Code: 500}, "encrypting value")))
+	assert.True(t, isServiceUnavailable(connectionRefused()))
+	assert.True(t, isServiceUnavailable(&net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset")}))
+
+	// Rejected requests, canceled requests, and unreadable responses do not.
+	assert.False(t, isServiceUnavailable(&apitype.ErrorResponse{Code: 400}))
+	assert.False(t, isServiceUnavailable(context.Canceled))
+	assert.False(t, isServiceUnavailable(&url.Error{Op: "Post", URL: "https://api.pulumi.com", Err: context.Canceled}))
+	assert.False(t, isServiceUnavailable(errors.Wrap(errors.New("unexpected EOF"), "unmarshalling response object")))
+}
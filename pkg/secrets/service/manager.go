@@ -60,7 +60,7 @@ var _ secrets.Manager = &serviceSecretsManager{}
 
 type serviceSecretsManager struct {
 	state   serviceSecretsManagerState
-	crypter config.Crypter
+	crypter *escrowCrypter
 }
 
 func (sm *serviceSecretsManager) Type() string {
@@ -82,6 +82,11 @@ func (sm *serviceSecretsManager) Encrypter() (config.Encrypter, error) {
 }
 
 func NewServiceSecretsManager(c *client.Client, id client.StackIdentifier) (secrets.Manager, error) {
+	crypter, err := newStackCrypter(c, id)
+	if err != nil {
+		return nil, err
+	}
+
 	return &serviceSecretsManager{
 		state: serviceSecretsManagerState{
 			URL:     c.URL(),
@@ -89,7 +94,7 @@ func NewServiceSecretsManager(c *client.Client, id client.StackIdentifier) (secr
 			Project: id.Project,
 			Stack:   id.Stack,
 		},
-		crypter: newServiceCrypter(c, id),
+		crypter: crypter,
 	}, nil
 }
 
@@ -117,8 +122,13 @@ func NewServiceSecretsManagerFromState(state json.RawMessage) (secrets.Manager,
 	}
 	c := client.NewClient(s.URL, token, diag.DefaultSink(ioutil.Discard, ioutil.Discard, diag.FormatOptions{}))
 
+	crypter, err := newStackCrypter(c, id)
+	if err != nil {
+		return nil, err
+	}
+
 	return &serviceSecretsManager{
 		state:   s,
-		crypter: newServiceCrypter(c, id),
+		crypter: crypter,
 	}, nil
 }
@@ -0,0 +1,179 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package stacksecrets chooses and configures the secrets manager of a stack from the settings in its configuration
+// file, in the same way for the CLI and for programs that drive stacks through the automation package.
+package stacksecrets
+
+import (
+	cryptorand "crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/filestate"
+	"github.com/pulumi/pulumi/pkg/backend/httpstate"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/secrets"
+	"github.com/pulumi/pulumi/pkg/secrets/cloud"
+	"github.com/pulumi/pulumi/pkg/secrets/passphrase"
+	"github.com/pulumi/pulumi/pkg/secrets/service"
+	"github.com/pulumi/pulumi/pkg/secrets/vault"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// PassphraseManagerFunc returns a secrets manager that protects secrets with a passphrase, for the stack whose settings
+// are in the given configuration file. How the passphrase is obtained is up to the caller.
+type PassphraseManagerFunc func(configFile string) (secrets.Manager, error)
+
+// isCloudSecretsProvider returns true if the given secrets provider is one of those implemented with gocloud.
+func isCloudSecretsProvider(secretsProvider string) bool {
+	return secretsProvider != "" && secretsProvider != "default" && secretsProvider != "passphrase"
+}
+
+// Manager returns the secrets manager of the given stack, whose settings are in the given configuration file. Stacks
+// that use a passphrase get their secrets manager from newPassphraseManager.
+func Manager(s backend.Stack, configFile string,
+	newPassphraseManager PassphraseManagerFunc) (secrets.Manager, error) {
+
+	ps, err := workspace.LoadProjectStack(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if vault.IsVaultSecretsProvider(ps.SecretsProvider) {
+		return NewVaultManager(configFile, ps.SecretsProvider)
+	}
+	if isCloudSecretsProvider(ps.SecretsProvider) {
+		return NewCloudManager(configFile, ps.SecretsProvider)
+	}
+
+	if ps.EncryptionSalt != "" {
+		return newPassphraseManager(configFile)
+	}
+
+	switch stack := s.(type) {
+	case httpstate.Stack:
+		client := stack.Backend().(httpstate.Backend).Client()
+		return service.NewServiceSecretsManager(client, stack.StackIdentifier())
+	case filestate.Stack:
+		return newPassphraseManager(configFile)
+	}
+
+	return nil, errors.Errorf("unknown stack type %s", reflect.TypeOf(s))
+}
+
+// Init configures the given secrets provider in the given configuration file of a stack that is about to be created
+// in the given backend. Stacks that use the passphrase or a key management service must have their secrets provider
+// configured before they are created; the Pulumi service configures its own as part of creating the stack.
+func Init(b backend.Backend, configFile, secretsProvider string, newPassphraseManager PassphraseManagerFunc) error {
+	_, isFileState := b.(filestate.Backend)
+	var err error
+	switch {
+	case vault.IsVaultSecretsProvider(secretsProvider):
+		_, err = NewVaultManager(configFile, secretsProvider)
+	case isCloudSecretsProvider(secretsProvider):
+		_, err = NewCloudManager(configFile, secretsProvider)
+	case isFileState || secretsProvider == "passphrase":
+		_, err = newPassphraseManager(configFile)
+	}
+	return err
+}
+
+// NewPassphraseManager returns a secrets manager that protects secrets with the given passphrase. If the stack whose
+// settings are in the given configuration file has used a passphrase before, the passphrase must match it, or
+// passphrase.ErrIncorrectPassphrase is returned; otherwise, a salt is recorded in the file so that the passphrase can
+// be checked later.
+func NewPassphraseManager(configFile, phrase string) (secrets.Manager, error) {
+	info, err := workspace.LoadProjectStack(configFile)
+	if err != nil {
+		return nil, err
+	}
+	if info.EncryptionSalt != "" {
+		return passphrase.NewPassphaseSecretsManager(phrase, info.EncryptionSalt)
+	}
+
+	// Produce a new salt.
+	salt := make([]byte, 8)
+	_, err = cryptorand.Read(salt)
+	contract.Assertf(err == nil, "could not read from system random")
+
+	// Encrypt a message and store it with the salt so we can test if the password is correct later.
+	crypter := config.NewSymmetricCrypterFromPassphrase(phrase, salt)
+	msg, err := crypter.EncryptValue("pulumi")
+	contract.AssertNoError(err)
+
+	info.EncryptionSalt = fmt.Sprintf("v1:%s:%s", base64.StdEncoding.EncodeToString(salt), msg)
+	if err = info.Save(configFile); err != nil {
+		return nil, err
+	}
+	return passphrase.NewPassphaseSecretsManager(phrase, info.EncryptionSalt)
+}
+
+// NewCloudManager returns a secrets manager that protects secrets with a data key encrypted by the given gocloud
+// secrets provider. If the stack whose settings are in the given configuration file has no data key yet, a new one is
+// generated and recorded in the file.
+func NewCloudManager(configFile, secretsProvider string) (secrets.Manager, error) {
+	dataKey, err := loadDataKey(configFile, secretsProvider, cloud.GenerateNewDataKey)
+	if err != nil {
+		return nil, err
+	}
+	sm, err := cloud.NewCloudSecretsManager(secretsProvider, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return sm, nil
+}
+
+// NewVaultManager returns a secrets manager that protects secrets with a data key encrypted by the given Vault transit
+// key. If the stack whose settings are in the given configuration file has no data key yet, a new one is generated
+// and recorded in the file.
+func NewVaultManager(configFile, secretsProvider string) (secrets.Manager, error) {
+	dataKey, err := loadDataKey(configFile, secretsProvider, vault.GenerateNewDataKey)
+	if err != nil {
+		return nil, err
+	}
+	sm, err := vault.NewVaultSecretsManager(secretsProvider, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return sm, nil
+}
+
+// loadDataKey returns the encrypted data key recorded in the given configuration file, after recording the given
+// secrets provider there, and a data key generated with generate if the file has none.
+func loadDataKey(configFile, secretsProvider string, generate func(string) ([]byte, error)) ([]byte, error) {
+	info, err := workspace.LoadProjectStack(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.EncryptedKey == "" {
+		dataKey, err := generate(secretsProvider)
+		if err != nil {
+			return nil, err
+		}
+		info.EncryptedKey = base64.StdEncoding.EncodeToString(dataKey)
+	}
+	info.SecretsProvider = secretsProvider
+	if err = info.Save(configFile); err != nil {
+		return nil, err
+	}
+
+	return base64.StdEncoding.DecodeString(info.EncryptedKey)
+}
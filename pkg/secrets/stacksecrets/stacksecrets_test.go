@@ -0,0 +1,100 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stacksecrets
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	_ "gocloud.dev/secrets/localsecrets" // support for base64key://
+
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestNewPassphraseManager(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stacksecrets")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	configFile := filepath.Join(dir, "Pulumi.dev.yaml")
+
+	// The first passphrase is recorded with a new salt, which is reused after that.
+	_, err = NewPassphraseManager(configFile, "password")
+	assert.NoError(t, err)
+	ps, err := workspace.LoadProjectStack(configFile)
+	if !assert.NoError(t, err) || !assert.NotEmpty(t, ps.EncryptionSalt) {
+		return
+	}
+
+	_, err = NewPassphraseManager(configFile, "password")
+	assert.NoError(t, err)
+	reloaded, err := workspace.LoadProjectStack(configFile)
+	if assert.NoError(t, err) {
+		assert.Equal(t, ps.EncryptionSalt, reloaded.EncryptionSalt)
+	}
+}
+
+func TestNewCloudManager(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stacksecrets")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	configFile := filepath.Join(dir, "Pulumi.dev.yaml")
+
+	// A data key is generated once, and reused after that.
+	provider := "base64key://" + base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))
+	sm, err := NewCloudManager(configFile, provider)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ps, err := workspace.LoadProjectStack(configFile)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, provider, ps.SecretsProvider)
+	assert.NotEmpty(t, ps.EncryptedKey)
+
+	encrypter, err := sm.Encrypter()
+	if !assert.NoError(t, err) {
+		return
+	}
+	ciphertext, err := encrypter.EncryptValue("hush")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	sm, err = NewCloudManager(configFile, provider)
+	if !assert.NoError(t, err) {
+		return
+	}
+	reloaded, err := workspace.LoadProjectStack(configFile)
+	if assert.NoError(t, err) {
+		assert.Equal(t, ps.EncryptedKey, reloaded.EncryptedKey)
+	}
+	decrypter, err := sm.Decrypter()
+	if !assert.NoError(t, err) {
+		return
+	}
+	plaintext, err := decrypter.DecryptValue(ciphertext)
+	assert.NoError(t, err)
+	assert.Equal(t, "hush", plaintext)
+}
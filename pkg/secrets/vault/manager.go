@@ -0,0 +1,279 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vault implements a secrets manager that protects secrets with a key in the transit secrets engine of a
+// HashiCorp Vault server. As with the cloud secrets providers, secrets are encrypted with a data key, which in turn is
+// encrypted by Vault; the plaintext data key never leaves the process that uses it.
+//
+// Keys are given by URLs of the form `vault://<key>?address=<address>&mount=<mount>&auth=<auth>`. All parameters are
+// optional: the address defaults to that given by the VAULT_ADDR environment variable and the mount of the transit
+// secrets engine defaults to "transit". Clients authenticate with the token given by VAULT_TOKEN unless `auth=approle`
+// is given, in which case they log in using the role and secret IDs given by VAULT_ROLE_ID and VAULT_SECRET_ID. The
+// AppRole auth method's mount defaults to "approle", and may be given by `approle_mount`. Credentials are never part of
+// the URL, as it is stored in the stack's settings.
+//
+// This provider exists alongside gocloud's `hashivault://` provider, which the cloud package supports, because that
+// provider cannot be extended to do what this one does:
+//
+//   - gocloud opens `hashivault://` keys with a client of its own, configured only by VAULT_SERVER_URL and
+//     VAULT_SERVER_TOKEN, and always uses the transit engine at the `transit` mount. There is no way to hand it a
+//     client that has logged in with AppRole, or a different mount, through the URL the stack's settings record.
+//   - gocloud's keepers can only encrypt and decrypt, so rotating the key and rewrapping a stack's data key, which use
+//     the transit engine's rotate and rewrap endpoints, are out of its reach.
+//   - Changing what `hashivault://` URLs mean, or the environment variables they read, would break the stacks that
+//     already use them.
+//
+// Stacks can move from one provider to the other with `pulumi stack change-secrets-provider`.
+package vault
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	vault "github.com/hashicorp/vault/api"
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/secrets"
+)
+
+// Type is the type of secrets managed by this secrets provider
+const Type = "vault"
+
+type vaultSecretsManagerState struct {
+	URL          string `json:"url"`
+	EncryptedKey []byte `json:"encryptedkey"`
+}
+
+// IsVaultSecretsProvider returns true if the given secrets provider is a key in a Vault server.
+func IsVaultSecretsProvider(secretsProvider string) bool {
+	return strings.HasPrefix(secretsProvider, Type+"://")
+}
+
+// NewVaultSecretsManagerFromState deserializes configuration from state and returns a secrets manager that uses a Vault
+// transit key to encrypt/decrypt a data key used for envelope encryption of secrets values.
+func NewVaultSecretsManagerFromState(state json.RawMessage) (secrets.Manager, error) {
+	var s vaultSecretsManagerState
+	if err := json.Unmarshal(state, &s); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling state")
+	}
+
+	return NewVaultSecretsManager(s.URL, s.EncryptedKey)
+}
+
+// GenerateNewDataKey generates a new data key seeded by a fresh random 32-byte key and encrypted using the Vault
+// transit key at the given URL.
+func GenerateNewDataKey(url string) ([]byte, error) {
+	key, err := openTransitKey(url)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintextDataKey := make([]byte, 32)
+	if _, err = rand.Read(plaintextDataKey); err != nil {
+		return nil, err
+	}
+	ciphertext, err := key.encrypt(plaintextDataKey)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(ciphertext), nil
+}
+
+// RewrapDataKey re-encrypts the given encrypted data key with the latest version of the Vault transit key at the given
+// URL. The plaintext data key is unchanged, so secrets encrypted with it remain valid. Rewrapping allows older versions
+// of the transit key to be retired after it is rotated.
+func RewrapDataKey(url string, encryptedDataKey []byte) ([]byte, error) {
+	key, err := openTransitKey(url)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := key.rewrap(string(encryptedDataKey))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(ciphertext), nil
+}
+
+// RotateKey rotates the Vault transit key at the given URL to a new version, then rewraps the given encrypted data key
+// with it. Rotating a key requires permission to update the key in Vault.
+func RotateKey(url string, encryptedDataKey []byte) ([]byte, error) {
+	key, err := openTransitKey(url)
+	if err != nil {
+		return nil, err
+	}
+	if err = key.rotate(); err != nil {
+		return nil, err
+	}
+	ciphertext, err := key.rewrap(string(encryptedDataKey))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(ciphertext), nil
+}
+
+// NewVaultSecretsManager returns a secrets manager that uses the Vault transit key at the given URL to encrypt/decrypt
+// a data key used for envelope encryption of secrets values.
+func NewVaultSecretsManager(url string, encryptedDataKey []byte) (*Manager, error) {
+	key, err := openTransitKey(url)
+	if err != nil {
+		return nil, err
+	}
+	plaintextDataKey, err := key.decrypt(string(encryptedDataKey))
+	if err != nil {
+		return nil, err
+	}
+	crypter := config.NewSymmetricCrypter(plaintextDataKey)
+	return &Manager{
+		crypter: crypter,
+		state: vaultSecretsManagerState{
+			URL:          url,
+			EncryptedKey: encryptedDataKey,
+		},
+	}, nil
+}
+
+// Manager is the secrets.Manager implementation for Vault transit keys
+type Manager struct {
+	state   vaultSecretsManagerState
+	crypter config.Crypter
+}
+
+func (m *Manager) Type() string                         { return Type }
+func (m *Manager) State() interface{}                   { return m.state }
+func (m *Manager) Encrypter() (config.Encrypter, error) { return m.crypter, nil }
+func (m *Manager) Decrypter() (config.Decrypter, error) { return m.crypter, nil }
+func (m *Manager) EncryptedKey() []byte                 { return m.state.EncryptedKey }
+
+// transitKey is a key in a Vault server's transit secrets engine, along with a client authenticated to use it.
+type transitKey struct {
+	client *vault.Client
+	mount  string
+	name   string
+}
+
+// openTransitKey parses the given URL and returns the key it refers to, logging in to Vault if necessary.
+func openTransitKey(rawurl string) (*transitKey, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing Vault key URL")
+	}
+	if u.Scheme != Type {
+		return nil, errors.Errorf("a Vault key URL must have the scheme %s://", Type)
+	}
+	name := strings.Trim(u.Host+u.Path, "/")
+	if name == "" {
+		return nil, errors.New("a Vault key URL must name a key, as in vault://<key>")
+	}
+
+	q := u.Query()
+	cfg := vault.DefaultConfig()
+	if address := q.Get("address"); address != "" {
+		cfg.Address = address
+	}
+	client, err := vault.NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch auth := q.Get("auth"); auth {
+	case "", "token":
+		// The client uses the token given by VAULT_TOKEN.
+		if client.Token() == "" {
+			return nil, errors.New("VAULT_TOKEN must be set to use a Vault key")
+		}
+	case "approle":
+		mount := q.Get("approle_mount")
+		if mount == "" {
+			mount = "approle"
+		}
+		if err = loginAppRole(client, mount); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.Errorf("unknown Vault auth method '%s' (supported values: token, approle)", auth)
+	}
+
+	mount := q.Get("mount")
+	if mount == "" {
+		mount = "transit"
+	}
+	return &transitKey{client: client, mount: mount, name: name}, nil
+}
+
+// loginAppRole logs the given client in to the AppRole auth method at the given mount, using the role and secret IDs
+// given by the environment.
+func loginAppRole(client *vault.Client, mount string) error {
+	roleID, secretID := os.Getenv("VAULT_ROLE_ID"), os.Getenv("VAULT_SECRET_ID")
+	if roleID == "" {
+		return errors.New("VAULT_ROLE_ID must be set to log in to Vault with AppRole")
+	}
+
+	secret, err := client.Logical().Write(path.Join("auth", mount, "login"), map[string]interface{}{
+		"role_id":   roleID,
+		"secret_id": secretID,
+	})
+	if err != nil {
+		return errors.Wrap(err, "logging in to Vault with AppRole")
+	}
+	if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+		return errors.New("logging in to Vault with AppRole returned no token")
+	}
+	client.SetToken(secret.Auth.ClientToken)
+	return nil
+}
+
+func (k *transitKey) encrypt(plaintext []byte) (string, error) {
+	return k.write("encrypt", map[string]interface{}{
+		"plaintext": base64.StdEncoding.EncodeToString(plaintext),
+	}, "ciphertext")
+}
+
+func (k *transitKey) decrypt(ciphertext string) ([]byte, error) {
+	plaintext, err := k.write("decrypt", map[string]interface{}{"ciphertext": ciphertext}, "plaintext")
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(plaintext)
+}
+
+func (k *transitKey) rewrap(ciphertext string) (string, error) {
+	return k.write("rewrap", map[string]interface{}{"ciphertext": ciphertext}, "ciphertext")
+}
+
+func (k *transitKey) rotate() error {
+	_, err := k.client.Logical().Write(path.Join(k.mount, "keys", k.name, "rotate"), nil)
+	return errors.Wrapf(err, "rotating Vault key '%s'", k.name)
+}
+
+// write performs the given transit operation with the key and returns the given field of its result.
+func (k *transitKey) write(op string, data map[string]interface{}, field string) (string, error) {
+	secret, err := k.client.Logical().Write(path.Join(k.mount, op, k.name), data)
+	if err != nil {
+		return "", errors.Wrapf(err, "%s with Vault key '%s'", op, k.name)
+	}
+	if secret == nil {
+		return "", errors.Errorf("%s with Vault key '%s' returned no result", op, k.name)
+	}
+	value, ok := secret.Data[field].(string)
+	if !ok {
+		return "", errors.Errorf("%s with Vault key '%s' returned no %s", op, k.name, field)
+	}
+	return value, nil
+}
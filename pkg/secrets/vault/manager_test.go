@@ -0,0 +1,169 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vault
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTransitServer returns a fake Vault server whose transit key "stack" "encrypts" plaintext by prefixing it with the
+// key's version. It accepts the token "root", and issues it to the AppRole "role".
+func newTransitServer(t *testing.T) *httptest.Server {
+	version := 1
+	prefix := func() string { return "vault:v" + strconv.Itoa(version) + ":" }
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if r.ContentLength > 0 {
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		}
+
+		var resp map[string]interface{}
+		switch {
+		case r.URL.Path == "/v1/auth/approle/login":
+			if body["role_id"] != "role" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			resp = map[string]interface{}{"auth": map[string]interface{}{"client_token": "root"}}
+		case r.Header.Get("X-Vault-Token") != "root":
+			w.WriteHeader(http.StatusForbidden)
+			return
+		case r.URL.Path == "/v1/transit/encrypt/stack":
+			resp = map[string]interface{}{"data": map[string]interface{}{
+				"ciphertext": prefix() + body["plaintext"].(string),
+			}}
+		case r.URL.Path == "/v1/transit/decrypt/stack":
+			ciphertext := body["ciphertext"].(string)
+			resp = map[string]interface{}{"data": map[string]interface{}{
+				"plaintext": ciphertext[strings.LastIndex(ciphertext, ":")+1:],
+			}}
+		case r.URL.Path == "/v1/transit/rewrap/stack":
+			ciphertext := body["ciphertext"].(string)
+			resp = map[string]interface{}{"data": map[string]interface{}{
+				"ciphertext": prefix() + ciphertext[strings.LastIndex(ciphertext, ":")+1:],
+			}}
+		case r.URL.Path == "/v1/transit/keys/stack/rotate":
+			version++
+			w.WriteHeader(http.StatusNoContent)
+			return
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+}
+
+func TestVaultSecretsManager(t *testing.T) {
+	server := newTransitServer(t)
+	defer server.Close()
+	defer os.Setenv("VAULT_TOKEN", os.Getenv("VAULT_TOKEN"))
+	assert.NoError(t, os.Setenv("VAULT_TOKEN", "root"))
+
+	url := "vault://stack?address=" + server.URL
+	dataKey, err := GenerateNewDataKey(url)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, strings.HasPrefix(string(dataKey), "vault:v1:"))
+
+	m, err := NewVaultSecretsManager(url, dataKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	encrypter, err := m.Encrypter()
+	assert.NoError(t, err)
+	ciphertext, err := encrypter.EncryptValue("hush")
+	assert.NoError(t, err)
+
+	// Rotating the key rewraps the data key, which still decrypts existing secrets.
+	rotated, err := RotateKey(url, dataKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, strings.HasPrefix(string(rotated), "vault:v2:"))
+
+	state, err := json.Marshal(vaultSecretsManagerState{URL: url, EncryptedKey: rotated})
+	assert.NoError(t, err)
+	sm, err := NewVaultSecretsManagerFromState(state)
+	if !assert.NoError(t, err) {
+		return
+	}
+	decrypter, err := sm.Decrypter()
+	assert.NoError(t, err)
+	plaintext, err := decrypter.DecryptValue(ciphertext)
+	assert.NoError(t, err)
+	assert.Equal(t, "hush", plaintext)
+
+	// Rewrapping without rotating leaves the key's version as it is.
+	rewrapped, err := RewrapDataKey(url, rotated)
+	assert.NoError(t, err)
+	assert.Equal(t, rotated, rewrapped)
+}
+
+func TestVaultAppRole(t *testing.T) {
+	server := newTransitServer(t)
+	defer server.Close()
+	defer os.Setenv("VAULT_TOKEN", os.Getenv("VAULT_TOKEN"))
+	defer os.Setenv("VAULT_ROLE_ID", os.Getenv("VAULT_ROLE_ID"))
+	assert.NoError(t, os.Unsetenv("VAULT_TOKEN"))
+
+	url := "vault://stack?auth=approle&address=" + server.URL
+	assert.NoError(t, os.Setenv("VAULT_ROLE_ID", "role"))
+	dataKey, err := GenerateNewDataKey(url)
+	assert.NoError(t, err)
+	assert.Equal(t, "vault:v1:", string(dataKey[:9]))
+	_, err = base64.StdEncoding.DecodeString(string(dataKey[9:]))
+	assert.NoError(t, err)
+
+	// Unknown roles can't log in, and without a role ID or token no login is attempted.
+	assert.NoError(t, os.Setenv("VAULT_ROLE_ID", "other"))
+	_, err = GenerateNewDataKey(url)
+	assert.Error(t, err)
+	assert.NoError(t, os.Unsetenv("VAULT_ROLE_ID"))
+	_, err = GenerateNewDataKey(url)
+	assert.Error(t, err)
+	_, err = GenerateNewDataKey("vault://stack?address=" + server.URL)
+	assert.Error(t, err)
+}
+
+func TestOpenTransitKey(t *testing.T) {
+	defer os.Setenv("VAULT_TOKEN", os.Getenv("VAULT_TOKEN"))
+	assert.NoError(t, os.Setenv("VAULT_TOKEN", "root"))
+
+	key, err := openTransitKey("vault://keys/stack/?mount=secrets/transit&address=https://vault:8200")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "keys/stack", key.name)
+		assert.Equal(t, "secrets/transit", key.mount)
+		assert.Equal(t, "https://vault:8200", key.client.Address())
+	}
+
+	_, err = openTransitKey("hashivault://stack")
+	assert.Error(t, err)
+	_, err = openTransitKey("vault://")
+	assert.Error(t, err)
+	_, err = openTransitKey("vault://stack?auth=ldap")
+	assert.Error(t, err)
+}
@@ -22,6 +22,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/pkg/errors"
 	"golang.org/x/crypto/ssh/terminal"
 
 	"github.com/pulumi/pulumi/pkg/util/ciutil"
@@ -46,7 +47,28 @@ var DisableInteractive bool
 // Interactive returns true if we should be running in interactive mode. That is, we have an interactive terminal
 // session, interactivity hasn't been explicitly disabled, and we're not running in a known CI system.
 func Interactive() bool {
-	return !DisableInteractive && InteractiveTerminal() && !ciutil.IsCI()
+	return NonInteractiveReason() == ""
+}
+
+// NonInteractiveReason returns why the CLI is not running in interactive mode, or "" if it is. Every prompt should
+// be guarded by Interactive, so that all of them agree on when prompting is possible.
+func NonInteractiveReason() string {
+	switch {
+	case DisableInteractive:
+		return "--non-interactive or PULUMI_NON_INTERACTIVE is set"
+	case ciutil.IsCI():
+		return "a CI system was detected"
+	case !InteractiveTerminal():
+		return "the console is not an interactive terminal"
+	default:
+		return ""
+	}
+}
+
+// NonInteractiveError returns the error reported when an answer is needed that could only be obtained by prompting,
+// but the CLI is not running in interactive mode. The hint tells the user how to supply the answer instead.
+func NonInteractiveError(needed, hint string) error {
+	return errors.Errorf("%s, but prompting is not possible because %s; %s", needed, NonInteractiveReason(), hint)
 }
 
 // InteractiveTerminal returns true if the current terminal session is interactive.
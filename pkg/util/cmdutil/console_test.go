@@ -0,0 +1,38 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmdutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNonInteractive(t *testing.T) {
+	old := DisableInteractive
+	defer func() { DisableInteractive = old }()
+
+	DisableInteractive = true
+	assert.False(t, Interactive())
+	assert.Equal(t, "--non-interactive or PULUMI_NON_INTERACTIVE is set", NonInteractiveReason())
+	assert.EqualError(t, NonInteractiveError("a stack name is required", "pass it as an argument"),
+		"a stack name is required, but prompting is not possible because --non-interactive or "+
+			"PULUMI_NON_INTERACTIVE is set; pass it as an argument")
+
+	// Tests do not run attached to a terminal, so the CLI is never interactive under test.
+	DisableInteractive = false
+	assert.False(t, Interactive())
+	assert.NotEmpty(t, NonInteractiveReason())
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/util/i18n"
 	"github.com/pulumi/pulumi/pkg/util/logging"
 	"github.com/pulumi/pulumi/pkg/util/result"
 )
@@ -60,6 +61,15 @@ func DetailedError(err error) string {
 	return msg
 }
 
+// commandResult is the result of the command that is running, if it has failed.
+var commandResult result.Result
+
+// CommandResult returns the result of the running command if it has failed, or nil otherwise. Post-run hooks can use
+// it to tell whether the command succeeded, since they run after failed commands too.
+func CommandResult() result.Result {
+	return commandResult
+}
+
 // runPostCommandHooks runs any post-hooks present on the given cobra.Command. This logic is copied directly from
 // cobra itself; see https://github.com/spf13/cobra/blob/4dab30cb33e6633c33c787106bafbfbfdde7842d/command.go#L768-L785
 // for the original.
@@ -110,6 +120,8 @@ func RunFunc(run func(cmd *cobra.Command, args []string) error) func(*cobra.Comm
 func RunResultFunc(run func(cmd *cobra.Command, args []string) result.Result) func(*cobra.Command, []string) {
 	return func(cmd *cobra.Command, args []string) {
 		if res := run(cmd, args); res != nil {
+			commandResult = res
+
 			// Sadly, the fact that we hard-exit below means that it's up to us to replicate the Cobra post-run
 			// behavior here.
 			if postRunErr := runPostCommandHooks(cmd, args); postRunErr != nil {
@@ -135,11 +147,26 @@ func RunResultFunc(run func(cmd *cobra.Command, args []string) result.Result) fu
 				logging.V(3).Infof(DetailedError(err))
 			}
 
-			ExitError(msg)
+			code := -1
+			if exitErr, ok := err.(*ExitCodeError); ok {
+				code = exitErr.Code
+			}
+			exitErrorCode(code, msg)
 		}
 	}
 }
 
+// ExitCodeError is an error that causes the CLI to exit with the given code, rather than the standard error exit
+// code, so that scripts can tell the failure apart from others.
+type ExitCodeError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitCodeError) Error() string {
+	return e.Err.Error()
+}
+
 // Exit exits with a given error.
 func Exit(err error) {
 	ExitError(errorMessage(err))
@@ -147,9 +174,14 @@ func Exit(err error) {
 
 // ExitError issues an error and exits with a standard error exit code.
 func ExitError(msg string) {
+	exitErrorCode(-1, msg)
+}
+
+// exitErrorCode issues an error and exits with the given error exit code.
+func exitErrorCode(code int, msg string) {
 	// Escape percent sign before passing the message as a format string (e.g., msg could contain %PATH% on Windows).
 	format := strings.Replace(msg, "%", "%%", -1)
-	exitErrorCodef(-1, format)
+	exitErrorCodef(code, format)
 }
 
 // exitErrorCodef formats the message with arguments, issues an error and exists with the given error exit code.
@@ -165,7 +197,7 @@ func errorMessage(err error) string {
 		if len(wr) == 1 {
 			return errorMessage(wr[0])
 		}
-		msg := fmt.Sprintf("%d errors occurred:", len(wr))
+		msg := i18n.Sprintf("%d errors occurred:", len(wr))
 		for i, werr := range wr {
 			msg += fmt.Sprintf("\n    %d) %s", i+1, errorMessage(werr))
 		}
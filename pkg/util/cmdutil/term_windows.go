@@ -0,0 +1,48 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build windows
+
+package cmdutil
+
+import (
+	"os"
+	"syscall"
+)
+
+// enableVirtualTerminalProcessing is the console mode flag that turns on ANSI escape sequence processing.
+// See https://docs.microsoft.com/en-us/windows/console/setconsolemode.
+const enableVirtualTerminalProcessing = 0x0004
+
+var procSetConsoleMode = syscall.NewLazyDLL("kernel32.dll").NewProc("SetConsoleMode")
+
+// VirtualTerminal returns true if the console attached to stdout interprets ANSI escape sequences, such as cursor
+// movement, itself. This is the case for ConPTY hosts (Windows Terminal, VS Code, etc.) and the Windows 10 console,
+// where this function enables the processing if it isn't on already. Older consoles only support escape sequences
+// through an emulation layer that cannot faithfully redraw the interactive display.
+func VirtualTerminal() bool {
+	h := syscall.Handle(os.Stdout.Fd())
+
+	var mode uint32
+	if err := syscall.GetConsoleMode(h, &mode); err != nil {
+		// Not a console at all, e.g. a pipe.
+		return false
+	}
+	if mode&enableVirtualTerminalProcessing != 0 {
+		return true
+	}
+
+	r, _, _ := procSetConsoleMode.Call(uintptr(h), uintptr(mode|enableVirtualTerminalProcessing))
+	return r != 0
+}
@@ -0,0 +1,83 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package crash recovers panics on the goroutines that make up the CLI and engine, and keeps a record of recent
+// activity, so that a crash can be reported with enough context to diagnose it.
+package crash
+
+import (
+	"runtime/debug"
+	"sync"
+)
+
+// maxRecentEvents is the number of recent events retained for crash reports.
+const maxRecentEvents = 50
+
+// Handler handles a recovered panic. It is given the panic's payload and the stack of the goroutine that panicked.
+type Handler func(payload interface{}, stack []byte)
+
+var (
+	lock    sync.Mutex
+	handler Handler
+	events  []string
+	next    int
+)
+
+// SetHandler sets the handler for panics recovered by Recover. The handler is expected to terminate the process.
+func SetHandler(h Handler) {
+	lock.Lock()
+	defer lock.Unlock()
+	handler = h
+}
+
+// Recover must be deferred directly at the top of a goroutine. If the goroutine panics, the panic is passed to the
+// handler set by SetHandler. If there is no handler, the goroutine panics again, as if it had not been recovered.
+func Recover() {
+	if payload := recover(); payload != nil {
+		stack := debug.Stack()
+
+		lock.Lock()
+		h := handler
+		lock.Unlock()
+
+		if h == nil {
+			panic(payload)
+		}
+		h(payload, stack)
+	}
+}
+
+// RecordEvent records a description of an event, retaining only the most recent events.
+func RecordEvent(description string) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if len(events) < maxRecentEvents {
+		events = append(events, description)
+	} else {
+		events[next] = description
+	}
+	next = (next + 1) % maxRecentEvents
+}
+
+// RecentEvents returns the most recently recorded events, oldest first.
+func RecentEvents() []string {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if len(events) < maxRecentEvents {
+		return append([]string(nil), events...)
+	}
+	return append(append([]string(nil), events[next:]...), events[:next]...)
+}
@@ -0,0 +1,63 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crash
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecentEvents(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		RecordEvent(fmt.Sprint(i))
+	}
+	assert.Equal(t, []string{"0", "1", "2"}, RecentEvents())
+
+	// Only the most recent events are retained, oldest first.
+	for i := 3; i < maxRecentEvents+5; i++ {
+		RecordEvent(fmt.Sprint(i))
+	}
+	recent := RecentEvents()
+	assert.Len(t, recent, maxRecentEvents)
+	assert.Equal(t, "5", recent[0])
+	assert.Equal(t, fmt.Sprint(maxRecentEvents+4), recent[maxRecentEvents-1])
+}
+
+func TestRecover(t *testing.T) {
+	var recovered interface{}
+	SetHandler(func(payload interface{}, stack []byte) {
+		recovered = payload
+		assert.NotEmpty(t, stack)
+	})
+	defer SetHandler(nil)
+
+	done := make(chan bool)
+	go func() {
+		defer close(done)
+		defer Recover()
+		panic("boom")
+	}()
+	<-done
+	assert.Equal(t, "boom", recovered)
+
+	// Without a handler, the panic continues.
+	SetHandler(nil)
+	assert.Panics(t, func() {
+		defer Recover()
+		panic("boom")
+	})
+}
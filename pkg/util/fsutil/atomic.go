@@ -0,0 +1,62 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fsutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+// WriteFileAtomic writes data to the file at path such that readers observe either the file's old contents or all of
+// the new contents, never a partial write. The data is written to a temporary file in the same directory, flushed to
+// disk, and then renamed over the destination.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := ioutil.TempFile(dir, "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer func() {
+		// If the rename succeeded, this is a no-op.
+		contract.IgnoreError(os.Remove(tmpName))
+	}()
+
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err = os.Chmod(tmpName, perm); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpName, path); err != nil {
+		return err
+	}
+
+	// Flush the rename itself. Directories cannot be synced on all platforms (notably Windows), so this is best-effort.
+	if d, err := os.Open(dir); err == nil {
+		contract.IgnoreError(d.Sync())
+		contract.IgnoreClose(d)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fsutil
+
+import (
+	"strings"
+)
+
+// maxWindowsPath is the longest Windows path that may be used without the extended-length prefix. Directories are
+// limited to MAX_PATH (260) less room for an 8.3 file name, so we use that lower limit for all paths.
+const maxWindowsPath = 248
+
+// extendedLengthPath converts an absolute Windows path that is too long for the legacy Win32 APIs into its
+// extended-length form (e.g. `\\?\C:\...` or `\\?\UNC\server\share\...`). Paths that are short, relative, or already
+// extended are returned unchanged. The path must already be clean and use backslashes.
+func extendedLengthPath(path string) string {
+	if len(path) < maxWindowsPath || strings.HasPrefix(path, `\\?\`) || strings.HasPrefix(path, `\\.\`) {
+		return path
+	}
+
+	switch {
+	case strings.HasPrefix(path, `\\`):
+		// A UNC path: \\server\share\... becomes \\?\UNC\server\share\...
+		return `\\?\UNC\` + path[2:]
+	case len(path) >= 3 && path[1] == ':' && path[2] == '\\':
+		// A drive-letter path: C:\... becomes \\?\C:\...
+		return `\\?\` + path
+	default:
+		// Relative and drive-relative paths cannot be extended.
+		return path
+	}
+}
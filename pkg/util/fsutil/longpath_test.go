@@ -0,0 +1,33 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fsutil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtendedLengthPath(t *testing.T) {
+	long := strings.Repeat(`\dir`, 70)
+
+	assert.Equal(t, `C:\short\path.exe`, extendedLengthPath(`C:\short\path.exe`))
+	assert.Equal(t, `\\?\C:`+long, extendedLengthPath(`C:`+long))
+	assert.Equal(t, `\\?\UNC\server\share`+long, extendedLengthPath(`\\server\share`+long))
+	assert.Equal(t, `\\?\C:`+long, extendedLengthPath(`\\?\C:`+long))
+	assert.Equal(t, `relative`+long, extendedLengthPath(`relative`+long))
+	assert.Equal(t, `C:relative`+long, extendedLengthPath(`C:relative`+long))
+}
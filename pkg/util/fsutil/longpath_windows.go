@@ -0,0 +1,31 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build windows
+
+package fsutil
+
+import (
+	"path/filepath"
+)
+
+// LongPath returns a form of the given path that may be passed to APIs that do not support long paths, such as
+// process creation. Absolute paths longer than MAX_PATH are converted to their extended-length `\\?\` form; the os
+// package already does this for file operations, but os/exec does not.
+func LongPath(path string) string {
+	if !filepath.IsAbs(path) {
+		return path
+	}
+	return extendedLengthPath(filepath.Clean(path))
+}
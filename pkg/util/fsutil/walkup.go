@@ -22,7 +22,11 @@ import (
 
 // WalkUp walks each file in path, passing the full path to `walkFn`. If walkFn returns true,
 // this method returns the path that was passed to walkFn. Before visiting the parent directory,
-// visitParentFn is called, if that returns false, WalkUp stops its search
+// visitParentFn is called, if that returns false, WalkUp stops its search.
+//
+// Parents are found lexically, so a path that passes through a symlink or Windows junction walks up through the
+// directories that contain the link rather than those that contain its target. A directory that is reached twice
+// through different links is only visited once.
 func WalkUp(path string, walkFn func(string) bool, visitParentFn func(string) bool) (string, error) {
 	if visitParentFn == nil {
 		visitParentFn = func(dir string) bool { return true }
@@ -30,17 +34,27 @@ func WalkUp(path string, walkFn func(string) bool, visitParentFn func(string) bo
 
 	curr := pathDir(path)
 
+	var visited []os.FileInfo
 	for {
-		// visit each file
-		files, err := ioutil.ReadDir(curr)
+		// Skip directories that we have already visited under another name.
+		info, err := os.Stat(curr)
 		if err != nil {
 			return "", err
 		}
-		for _, file := range files {
-			name := file.Name()
-			path := filepath.Join(curr, name)
-			if walkFn(path) {
-				return path, nil
+		if !containsSameFile(visited, info) {
+			visited = append(visited, info)
+
+			// visit each file
+			files, err := ioutil.ReadDir(curr)
+			if err != nil {
+				return "", err
+			}
+			for _, file := range files {
+				name := file.Name()
+				path := filepath.Join(curr, name)
+				if walkFn(path) {
+					return path, nil
+				}
 			}
 		}
 
@@ -53,13 +67,28 @@ func WalkUp(path string, walkFn func(string) bool, visitParentFn func(string) bo
 			break
 		}
 
-		// visit the parent
-		curr = filepath.Dir(curr)
+		// visit the parent. Volume roots such as UNC shares may not end in a separator, so also stop if the parent
+		// is the directory itself.
+		next := filepath.Dir(curr)
+		if next == curr || next == filepath.VolumeName(curr)+"." {
+			break
+		}
+		curr = next
 	}
 
 	return "", nil
 }
 
+// containsSameFile returns true if any of the given files is the same file as info.
+func containsSameFile(files []os.FileInfo, info os.FileInfo) bool {
+	for _, f := range files {
+		if os.SameFile(f, info) {
+			return true
+		}
+	}
+	return false
+}
+
 // pathDir returns the nearest directory to the given path (identity if a directory; parent otherwise).
 func pathDir(path string) string {
 	// If the path is a file, we want the directory it is in
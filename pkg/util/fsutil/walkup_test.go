@@ -0,0 +1,61 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fsutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalkUpThroughLink(t *testing.T) {
+	root, err := ioutil.TempDir("", "walkup")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	// root/project/Pulumi.yaml, with root/project/link pointing at root/elsewhere.
+	project, elsewhere := filepath.Join(root, "project"), filepath.Join(root, "elsewhere")
+	assert.NoError(t, os.MkdirAll(project, 0700))
+	assert.NoError(t, os.MkdirAll(elsewhere, 0700))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(project, "Pulumi.yaml"), nil, 0600))
+	link := filepath.Join(project, "link")
+	if err := os.Symlink(elsewhere, link); err != nil {
+		t.Skipf("cannot create symlinks: %v", err)
+	}
+
+	var visited []string
+	isProject := func(path string) bool { return filepath.Base(path) == "Pulumi.yaml" }
+	found, err := WalkUp(link, isProject, func(dir string) bool {
+		visited = append(visited, dir)
+		return dir != root
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(project, "Pulumi.yaml"), found)
+	assert.Equal(t, []string{link}, visited)
+
+	// A directory that is reached a second time through a link is not searched again.
+	assert.NoError(t, os.Symlink(project, filepath.Join(elsewhere, "back")))
+	var walked []string
+	_, err = WalkUp(filepath.Join(link, "back"), func(path string) bool {
+		walked = append(walked, path)
+		return false
+	}, func(dir string) bool { return dir != root })
+	assert.NoError(t, err)
+	assert.Contains(t, walked, filepath.Join(link, "back", "Pulumi.yaml"))
+	assert.NotContains(t, walked, filepath.Join(project, "Pulumi.yaml"))
+}
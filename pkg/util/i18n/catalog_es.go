@@ -0,0 +1,79 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+// The Spanish catalog.
+func init() {
+	Register("es", Catalog{
+		// Diagnostic severities.
+		"debug":   "depuración",
+		"error":   "error",
+		"warning": "advertencia",
+
+		// Update actions and confirmation.
+		"Previewing %s":                     "Vista previa (%s)",
+		"Previewing":                        "Vista previa",
+		"Updating":                          "Actualizando",
+		"Refreshing":                        "Sincronizando",
+		"Destroying":                        "Destruyendo",
+		"Importing":                         "Importando",
+		"Do you want to perform this %s%s?": "¿Desea realizar esta operación (%s)%s?",
+		" without a preview":                " sin vista previa",
+		"No resources will be modified as part of this refresh; just your stack's state will be.": "" +
+			"Ningún recurso se modificará durante esta sincronización; solo el estado de la pila.",
+		"confirmation declined, not proceeding with the %s\n": "" +
+			"confirmación rechazada, no se realizará la operación (%s)\n",
+		"confirmation cancelled, not proceeding with the %s": "" +
+			"confirmación cancelada, no se realizará la operación (%s)",
+
+		// Common CLI errors.
+		"%d errors occurred:": "se produjeron %d errores:",
+		"no stack selected; please use `pulumi stack select` or `pulumi stack init` to choose one": "" +
+			"no hay ninguna pila seleccionada; use `pulumi stack select` o `pulumi stack init` para elegir una",
+		"no stack selected; please use `pulumi stack select` to choose one": "" +
+			"no hay ninguna pila seleccionada; use `pulumi stack select` para elegir una",
+		"no stack selected, but prompting is not possible because %s; please use " +
+			"`pulumi stack select` or `pulumi stack init` to choose one": "" +
+			"no hay ninguna pila seleccionada, y no es posible preguntar porque %s; use `pulumi stack select` o " +
+			"`pulumi stack init` para elegir una",
+		"no stack selected, but prompting is not possible because %s; please use " +
+			"`pulumi stack select` to choose one": "" +
+			"no hay ninguna pila seleccionada, y no es posible preguntar porque %s; use `pulumi stack select` " +
+			"para elegir una",
+		"no Pulumi.yaml project file found (searching upwards from %s). If you have not " +
+			"created a project yet, use `pulumi new` to do so": "" +
+			"no se encontró ningún archivo de proyecto Pulumi.yaml (buscando hacia arriba desde %s). Si aún no ha " +
+			"creado un proyecto, use `pulumi new` para hacerlo",
+
+		// Engine diagnostics.
+		"Plan apply failed: %v": "Error al aplicar el plan: %v",
+		"Duplicate resource URN '%v'; try giving it a unique name": "" +
+			"URN de recurso duplicado '%v'; intente darle un nombre único",
+		"%v resource '%v' has a problem: %v": "el recurso %v '%v' tiene un problema: %v",
+		"%v resource '%v's property '%v' value %v has a problem: %v": "" +
+			"la propiedad '%[3]v' del recurso %[1]v '%[2]v' con valor %[4]v tiene un problema: %[5]v",
+		"Preview failed: %v": "Error en la vista previa: %v",
+		"bad provider reference '%v' for resource '%v': %v": "" +
+			"referencia de proveedor incorrecta '%v' para el recurso '%v': %v",
+		"unknown provider '%v' for resource '%v'": "proveedor desconocido '%v' para el recurso '%v'",
+		"Duplicate resource alias '%v' applied to resource with URN '%v' conflicting with resource with URN '%v'": "" +
+			"El alias de recurso duplicado '%v' aplicado al recurso con URN '%v' entra en conflicto con el recurso " +
+			"con URN '%v'",
+		"%v: '%v' is not permitted by --strict-allow during a strict preview; the call was rejected": "" +
+			"%v: '%v' no está permitido por --strict-allow durante una vista previa estricta; la llamada fue rechazada",
+		"%v: '%v' is not permitted during a strict preview; the call was rejected": "" +
+			"%v: '%v' no está permitido durante una vista previa estricta; la llamada fue rechazada",
+	})
+}
@@ -0,0 +1,114 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package i18n localizes user-facing CLI messages. Messages are identified by their English text, which is also used
+// whenever the selected locale has no catalog or the catalog has no translation for a message. Format strings are
+// translated before they are formatted; translations that need to reorder arguments can use explicit argument indexes,
+// such as `%[2]v`.
+//
+// The locale is taken from the PULUMI_LOCALE environment variable, falling back to the standard LC_ALL, LC_MESSAGES,
+// and LANG variables.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// LocaleEnvVar is the environment variable that selects the locale of CLI messages, such as `es` or `es_ES.UTF-8`.
+const LocaleEnvVar = "PULUMI_LOCALE"
+
+// Catalog maps English messages to their translations.
+type Catalog map[string]string
+
+var (
+	catalogs = map[string]Catalog{}
+
+	localeOnce sync.Once
+	locale     string
+	current    Catalog
+)
+
+// Register registers the catalog for the given locale. It is intended to be called from package initializers.
+func Register(name string, c Catalog) {
+	catalogs[normalizeLocale(name)] = c
+}
+
+// Locale returns the locale selected by the environment, normalized to lowercase with hyphens (e.g. `es-es`). The
+// empty string means that messages are not translated.
+func Locale() string {
+	localeOnce.Do(func() {
+		for _, env := range []string{LocaleEnvVar, "LC_ALL", "LC_MESSAGES", "LANG"} {
+			if v := os.Getenv(env); v != "" {
+				setLocale(v)
+				return
+			}
+		}
+	})
+	return locale
+}
+
+// SetLocale overrides the locale selected by the environment.
+func SetLocale(name string) {
+	localeOnce.Do(func() {})
+	setLocale(name)
+}
+
+func setLocale(name string) {
+	locale, current = normalizeLocale(name), nil
+	if c, has := catalogs[locale]; has {
+		current = c
+	} else if i := strings.IndexByte(locale, '-'); i != -1 {
+		// Fall back from a regional locale (es-mx) to its language (es).
+		current = catalogs[locale[:i]]
+	}
+}
+
+// normalizeLocale turns a POSIX locale such as `es_ES.UTF-8@euro` into `es-es`. The C and POSIX locales select no
+// translation.
+func normalizeLocale(name string) string {
+	if i := strings.IndexAny(name, ".@"); i != -1 {
+		name = name[:i]
+	}
+	name = strings.ToLower(strings.Replace(name, "_", "-", -1))
+	if name == "c" || name == "posix" {
+		return ""
+	}
+	return name
+}
+
+// T returns the translation of the given message in the current locale, or the message itself if it has none.
+func T(msg string) string {
+	Locale()
+	if current != nil {
+		if t, has := current[msg]; has {
+			return t
+		}
+	}
+	return msg
+}
+
+// Sprintf formats the translation of the given format string.
+func Sprintf(format string, args ...interface{}) string {
+	return fmt.Sprintf(T(format), args...)
+}
+
+// Errorf returns an error whose message is the formatted translation of the given format string.
+func Errorf(format string, args ...interface{}) error {
+	return errors.Errorf(T(format), args...)
+}
@@ -0,0 +1,126 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeLocale(t *testing.T) {
+	assert.Equal(t, "es", normalizeLocale("es"))
+	assert.Equal(t, "es-es", normalizeLocale("es_ES.UTF-8"))
+	assert.Equal(t, "de-de", normalizeLocale("de_DE@euro"))
+	assert.Equal(t, "", normalizeLocale("C"))
+	assert.Equal(t, "", normalizeLocale("POSIX.UTF-8"))
+}
+
+func TestTranslate(t *testing.T) {
+	defer SetLocale("")
+
+	SetLocale("es_MX.UTF-8")
+	assert.Equal(t, "es-mx", Locale())
+	assert.Equal(t, "advertencia", T("warning"))
+	assert.Equal(t, "Error en la vista previa: boom", Sprintf("Preview failed: %v", "boom"))
+	assert.Equal(t, "la propiedad 'p' del recurso aws:s3:Bucket 'b' con valor 1 tiene un problema: bad",
+		Sprintf("%v resource '%v's property '%v' value %v has a problem: %v", "aws:s3:Bucket", "b", "p", 1, "bad"))
+
+	// Messages without a translation are returned as-is.
+	assert.Equal(t, "untranslated 42", Sprintf("untranslated %d", 42))
+
+	SetLocale("en_US")
+	assert.Equal(t, "warning", T("warning"))
+	assert.EqualError(t, Errorf("Preview failed: %v", "boom"), "Preview failed: boom")
+}
+
+// TestCatalogKeysInSource ensures that every message in a catalog is still used by the CLI, so that a catalog does not
+// silently stop translating a message whose English text has changed.
+func TestCatalogKeysInSource(t *testing.T) {
+	// Collect every constant string expression in the non-test sources of the cmd and pkg directories.
+	root := filepath.Join("..", "..", "..")
+	literals := map[string]bool{}
+	self := filepath.Join(root, "pkg", "util", "i18n")
+	for _, dir := range []string{"cmd", "pkg"} {
+		err := filepath.Walk(filepath.Join(root, dir), func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				// The catalogs themselves do not count as uses of their messages.
+				if info.Name() == "testdata" || info.Name() == "vendor" || path == self {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+				return nil
+			}
+			f, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+			if err != nil {
+				return err
+			}
+			ast.Inspect(f, func(n ast.Node) bool {
+				if e, ok := n.(ast.Expr); ok {
+					if s, ok := stringConstant(e); ok {
+						literals[s] = true
+					}
+				}
+				return true
+			})
+			return nil
+		})
+		assert.NoError(t, err)
+	}
+
+	for name, c := range catalogs {
+		for msg := range c {
+			assert.True(t, literals[msg], "the %s catalog translates a message that is no longer used: %q", name, msg)
+		}
+	}
+}
+
+// stringConstant returns the value of the given expression if it is a string literal, or a concatenation of them.
+func stringConstant(e ast.Expr) (string, bool) {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(e.Value)
+		return s, err == nil
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return "", false
+		}
+		x, ok := stringConstant(e.X)
+		if !ok {
+			return "", false
+		}
+		y, ok := stringConstant(e.Y)
+		return x + y, ok
+	case *ast.ParenExpr:
+		return stringConstant(e.X)
+	default:
+		return "", false
+	}
+}
@@ -25,6 +25,7 @@ package logging
 import (
 	"flag"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -43,10 +44,6 @@ var LogFlow = false     // true to flow logging settings to child processes.
 var rwLock sync.RWMutex
 var filters []Filter
 
-func V(level glog.Level) glog.Verbose {
-	return glog.V(level)
-}
-
 func Errorf(format string, args ...interface{}) {
 	glog.Errorf("%s", FilterString(fmt.Sprintf(format, args...)))
 }
@@ -69,6 +66,7 @@ func InitLogging(logToStderr bool, verbose int, logFlow bool) {
 	LogToStderr = logToStderr
 	Verbose = verbose
 	LogFlow = logFlow
+	resetLevels()
 
 	// glog uses golang's built in flags package to set configuration values, which is incompatible with how
 	// we use cobra. In order to accommodate this, we call flag.CommandLine.Parse() with an empty array and
@@ -112,6 +110,15 @@ func (f *replacerFilter) Filter(s string) string {
 	return f.replacer.Replace(s)
 }
 
+type regexpFilter struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+func (f *regexpFilter) Filter(s string) string {
+	return f.re.ReplaceAllLiteralString(s, f.replacement)
+}
+
 func AddGlobalFilter(filter Filter) {
 	rwLock.Lock()
 	filters = append(filters, filter)
@@ -135,6 +142,11 @@ func CreateFilter(secrets []string, replacement string) Filter {
 	return &nopFilter{}
 }
 
+// CreateRegexpFilter creates a filter that replaces all text matching the given regular expression.
+func CreateRegexpFilter(re *regexp.Regexp, replacement string) Filter {
+	return &regexpFilter{re: re, replacement: replacement}
+}
+
 func FilterString(msg string) string {
 	var localFilters []Filter
 	rwLock.RLock()
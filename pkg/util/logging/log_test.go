@@ -52,3 +52,58 @@ func TestFilter(t *testing.T) {
 	msg4 := filter4.Filter("These are my secrets: a, my, 123")
 	assert.Equal(t, msg4, "These are my secrets: a, my, [creds]")
 }
+
+func TestParseVerbosity(t *testing.T) {
+	verbose, scoped, err := ParseVerbosity("9")
+	assert.NoError(t, err)
+	assert.Equal(t, 9, verbose)
+	assert.Empty(t, scoped)
+
+	verbose, scoped, err = ParseVerbosity("3,backend=9, plugin=5")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, verbose)
+	assert.Equal(t, map[string]int{"backend": 9, "plugin": 5}, scoped)
+
+	verbose, scoped, err = ParseVerbosity("")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, verbose)
+	assert.Empty(t, scoped)
+
+	for _, setting := range []string{"x", "-1", "backend=", "=3", "backend=high"} {
+		_, _, err = ParseVerbosity(setting)
+		assert.Error(t, err, setting)
+	}
+}
+
+func TestScopedVerbosity(t *testing.T) {
+	prevV := Verbose
+	defer func() {
+		SetScopedVerbosity(nil)
+		InitLogging(LogToStderr, prevV, LogFlow)
+	}()
+	InitLogging(LogToStderr, 1, LogFlow)
+	SetScopedVerbosity(map[string]int{
+		"backend":           9,
+		"httpstate":         2,
+		"backend/httpstate": 4,
+		"plugin":            5,
+		"util":              7,
+	})
+
+	level := func(pkg string) int {
+		scopesLock.RLock()
+		defer scopesLock.RUnlock()
+		return packageLevel(sourceRoot + pkg)
+	}
+	assert.Equal(t, 9, level("pkg/backend"))
+	assert.Equal(t, 9, level("pkg/backend/filestate"))
+	assert.Equal(t, 4, level("pkg/backend/httpstate/client"))
+	assert.Equal(t, 5, level("pkg/resource/plugin"))
+	assert.Equal(t, 1, level("pkg/engine"))
+	assert.Equal(t, 1, level("cmd"))
+
+	// This package is in the util subsystem.
+	assert.Equal(t, 7, callerLevel(1))
+	assert.True(t, bool(V(7)))
+	assert.False(t, bool(V(8)))
+}
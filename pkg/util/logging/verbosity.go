@@ -0,0 +1,148 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+)
+
+// scopes holds the verbosity levels of the subsystems whose logging has been scoped, if any, and levels caches the
+// level that applies to each call site that has asked for one.
+var scopes map[string]int
+var scopesLock sync.RWMutex
+var levels sync.Map
+
+// sourceRoot is the directory that holds the source of the packages whose subsystems may be scoped.
+var sourceRoot = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return strings.TrimSuffix(path.Dir(file), "pkg/util/logging")
+}()
+
+// ParseVerbosity parses a verbosity setting, which is either a level, such as "3", or a comma-separated list of a
+// level and levels scoped to subsystems, such as "3,backend=9,plugin=5". It returns the level of logging that is not
+// scoped to a subsystem and the levels of the subsystems.
+//
+// A subsystem is a package of the CLI or a group of them, named by its path without the leading "pkg/", such as
+// "backend/httpstate", or by any part of that path, such as "httpstate" or "plugin". The logging of a package is
+// scoped by the subsystem that names the innermost part of its path.
+func ParseVerbosity(setting string) (int, map[string]int, error) {
+	verbose, scoped := 0, make(map[string]int)
+	for _, part := range strings.Split(setting, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		subsystem, value := "", part
+		if eq := strings.Index(part, "="); eq != -1 {
+			subsystem, value = strings.Trim(part[:eq], "/"), part[eq+1:]
+			if subsystem == "" {
+				return 0, nil, errors.Errorf("verbosity '%s' is missing the name of a subsystem", part)
+			}
+		}
+		level, err := strconv.Atoi(value)
+		if err != nil || level < 0 {
+			return 0, nil, errors.Errorf("verbosity '%s' must be a non-negative number", part)
+		}
+
+		if subsystem == "" {
+			verbose = level
+		} else {
+			scoped[subsystem] = level
+		}
+	}
+	return verbose, scoped, nil
+}
+
+// SetScopedVerbosity sets the verbosity levels of the given subsystems, replacing any that were set before. The
+// logging of packages that no subsystem scopes is at the level set by InitLogging.
+func SetScopedVerbosity(levels map[string]int) {
+	scopesLock.Lock()
+	defer scopesLock.Unlock()
+
+	scopes = make(map[string]int, len(levels))
+	for subsystem, level := range levels {
+		scopes[subsystem] = level
+	}
+	resetLevels()
+}
+
+// Verbosity returns the verbosity level of the calling package.
+func Verbosity() int {
+	return callerLevel(2)
+}
+
+// V returns true if logging at the given level is enabled for the calling package.
+func V(level glog.Level) glog.Verbose {
+	scopesLock.RLock()
+	scoped := len(scopes) > 0
+	scopesLock.RUnlock()
+	if !scoped {
+		return glog.V(level)
+	}
+	return glog.Verbose(int(level) <= callerLevel(2))
+}
+
+// callerLevel returns the verbosity level of the package of the function the given number of frames up the stack.
+func callerLevel(skip int) int {
+	var pcs [1]uintptr
+	if runtime.Callers(skip+1, pcs[:]) == 0 {
+		return Verbose
+	}
+	if level, ok := levels.Load(pcs[0]); ok {
+		return level.(int)
+	}
+
+	file, _ := runtime.FuncForPC(pcs[0]).FileLine(pcs[0])
+	scopesLock.RLock()
+	level := packageLevel(path.Dir(file))
+	scopesLock.RUnlock()
+	levels.Store(pcs[0], level)
+	return level
+}
+
+// packageLevel returns the verbosity level of the package in the given directory. scopesLock must be held.
+func packageLevel(dir string) int {
+	pkg := strings.TrimPrefix(strings.TrimPrefix(dir, sourceRoot), "pkg/")
+
+	// Of the subsystems that name part of the package's path, the one that names the innermost part wins, and of
+	// those, the one that names the most of it.
+	level, end, length := Verbose, -1, 0
+	for subsystem, l := range scopes {
+		i := strings.LastIndex("/"+pkg+"/", "/"+subsystem+"/")
+		if i == -1 {
+			continue
+		}
+		if e := i + len(subsystem); e > end || e == end && len(subsystem) > length {
+			level, end, length = l, e, len(subsystem)
+		}
+	}
+	return level
+}
+
+// resetLevels forgets the levels of the call sites that have asked for one, since they may have changed.
+func resetLevels() {
+	levels.Range(func(key, value interface{}) bool {
+		levels.Delete(key)
+		return true
+	})
+}
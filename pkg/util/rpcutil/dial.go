@@ -0,0 +1,38 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpcutil
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+)
+
+// UnixSocketScheme prefixes the addresses of gRPC servers that listen on Unix sockets rather than TCP ports.
+const UnixSocketScheme = "unix://"
+
+// WithUnixSockets returns a dial option that lets a gRPC client connect to addresses of the form `unix://path`, as well
+// as to `host:port` addresses, since gRPC only dials TCP addresses itself.
+func WithUnixSockets() grpc.DialOption {
+	return grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+		var d net.Dialer
+		if strings.HasPrefix(addr, UnixSocketScheme) {
+			return d.DialContext(ctx, "unix", strings.TrimPrefix(addr, UnixSocketScheme))
+		}
+		return d.DialContext(ctx, "tcp", addr)
+	})
+}
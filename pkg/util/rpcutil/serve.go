@@ -16,6 +16,7 @@ package rpcutil
 
 import (
 	"net"
+	"os"
 	"strconv"
 	"strings"
 
@@ -43,22 +44,53 @@ func Serve(port int, cancel chan bool, registers []func(*grpc.Server) error) (in
 		return port, nil, errors.Errorf("failed to listen on TCP port ':%v': %v", port, err)
 	}
 
+	// If the port was 0, look up what port the kernel chosen, by accessing the underlying TCP listener/address.
+	if port == 0 {
+		tcpl := lis.(*net.TCPListener)
+		tcpa := tcpl.Addr().(*net.TCPAddr)
+		port = tcpa.Port
+	}
+
+	done, err := serve(lis, cancel, registers)
+	return port, done, err
+}
+
+// SocketEnvVar, if set in the environment of a plugin, names the Unix socket on which ServePlugin listens instead of a
+// TCP port. The engine sets it for language hosts that it runs without network access.
+const SocketEnvVar = "PULUMI_RPC_SOCKET"
+
+// ServePlugin is like Serve with a port of 0, except that it listens on the Unix socket named by SocketEnvVar if that
+// is set, in which case the returned port is 0. The variable is removed from the environment, so that the processes
+// the plugin launches do not inherit it.
+func ServePlugin(registers []func(*grpc.Server) error) (int, chan error, error) {
+	path := os.Getenv(SocketEnvVar)
+	if path == "" {
+		return Serve(0, nil, registers)
+	}
+	if err := os.Unsetenv(SocketEnvVar); err != nil {
+		return 0, nil, err
+	}
+
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		return 0, nil, errors.Errorf("failed to listen on Unix socket '%v': %v", path, err)
+	}
+	done, err := serve(lis, nil, registers)
+	return 0, done, err
+}
+
+// serve creates a new gRPC server, calls out to the supplied registration functions to bind interfaces, and then
+// serves on the given listener. See Serve.
+func serve(lis net.Listener, cancel chan bool, registers []func(*grpc.Server) error) (chan error, error) {
 	// Now new up a gRPC server and register any RPC interfaces the caller wants.
 	srv := grpc.NewServer(grpc.UnaryInterceptor(OpenTracingServerInterceptor()))
 	for _, register := range registers {
 		if err := register(srv); err != nil {
-			return port, nil, errors.Errorf("failed to register RPC handler: %v", err)
+			return nil, errors.Errorf("failed to register RPC handler: %v", err)
 		}
 	}
 	reflection.Register(srv) // enable reflection.
 
-	// If the port was 0, look up what port the kernel chosen, by accessing the underlying TCP listener/address.
-	if port == 0 {
-		tcpl := lis.(*net.TCPListener)
-		tcpa := tcpl.Addr().(*net.TCPAddr)
-		port = tcpa.Port
-	}
-
 	// If the caller provided a cancellation channel, start a goroutine that will gracefully terminate the gRPC server when
 	// that channel is closed or receives a `true` value.
 	if cancel != nil {
@@ -81,5 +113,5 @@ func Serve(port int, cancel chan bool, registers []func(*grpc.Server) error) (in
 		close(done)
 	}()
 
-	return port, done, nil
+	return done, nil
 }
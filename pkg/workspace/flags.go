@@ -0,0 +1,132 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	// UserFlagDefaultsFile is the name of the file in ~/.pulumi that holds the user's CLI flag defaults.
+	UserFlagDefaultsFile = "config"
+	// ProjectFlagDefaultsFile is the name of the file next to Pulumi.yaml that holds a project's CLI flag defaults.
+	ProjectFlagDefaultsFile = ".pulumirc"
+)
+
+// FlagDefaults holds default values for CLI flags, read from a flag defaults file such as:
+//
+//     color: never
+//     non-interactive: true
+//     commands:
+//       up:
+//         parallel: 4
+//
+// Top-level entries apply to every command that has the flag. Entries under `commands` apply only to the named
+// command (e.g. `up` or `stack ls`) and take precedence over top-level entries.
+type FlagDefaults struct {
+	Flags    map[string]string            // defaults for all commands.
+	Commands map[string]map[string]string // defaults for specific commands.
+}
+
+// Lookup returns the default value of the named flag for the given command, if there is one.
+func (fd *FlagDefaults) Lookup(command, flag string) (string, bool) {
+	if fd == nil {
+		return "", false
+	}
+	if v, has := fd.Commands[command][flag]; has {
+		return v, true
+	}
+	v, has := fd.Flags[flag]
+	return v, has
+}
+
+// LoadFlagDefaults reads the flag defaults file at the given path. A missing file yields nil defaults.
+func LoadFlagDefaults(path string) (*FlagDefaults, error) {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]interface{}
+	if err = yaml.Unmarshal(b, &raw); err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", path)
+	}
+
+	fd := &FlagDefaults{Commands: make(map[string]map[string]string)}
+	if commands, has := raw["commands"]; has {
+		delete(raw, "commands")
+		cmds, ok := commands.(map[interface{}]interface{})
+		if !ok {
+			return nil, errors.Errorf("%s: 'commands' must be a map from command names to flag defaults", path)
+		}
+		for name, flags := range cmds {
+			flagsMap, ok := flags.(map[interface{}]interface{})
+			if !ok {
+				return nil, errors.Errorf("%s: flag defaults for command '%v' must be a map", path, name)
+			}
+			m := make(map[string]interface{})
+			for k, v := range flagsMap {
+				m[fmt.Sprint(k)] = v
+			}
+			if fd.Commands[fmt.Sprint(name)], err = flagValues(m); err != nil {
+				return nil, errors.Wrapf(err, "%s: command '%v'", path, name)
+			}
+		}
+	}
+	if fd.Flags, err = flagValues(raw); err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+
+	return fd, nil
+}
+
+// flagValues converts a map of flag names to scalar YAML values into a map of flag names to flag strings.
+func flagValues(m map[string]interface{}) (map[string]string, error) {
+	values := make(map[string]string)
+	for k, v := range m {
+		switch v.(type) {
+		case string, bool, int, float64:
+			values[k] = fmt.Sprint(v)
+		default:
+			return nil, errors.Errorf("the default for flag '%s' must be a string, number, or boolean", k)
+		}
+	}
+	return values, nil
+}
+
+// GetUserFlagDefaultsPath returns the location of the user's CLI flag defaults file, regardless of whether it exists.
+func GetUserFlagDefaultsPath() (string, error) {
+	user, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(user.HomeDir, BookkeepingDir, UserFlagDefaultsFile), nil
+}
+
+// GetProjectFlagDefaultsPath returns the location of the CLI flag defaults file for the project whose Pulumi.yaml is
+// at the given path, regardless of whether it exists.
+func GetProjectFlagDefaultsPath(projectPath string) string {
+	return filepath.Join(filepath.Dir(projectPath), ProjectFlagDefaultsFile)
+}
@@ -25,6 +25,7 @@ import (
 
 	"github.com/pulumi/pulumi/pkg/encoding"
 	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/contract"
 	"github.com/pulumi/pulumi/pkg/util/fsutil"
 )
 
@@ -35,10 +36,18 @@ const (
 	BookkeepingDir = ".pulumi"
 	// ConfigDir is the name of the folder that holds local configuration information.
 	ConfigDir = "config"
+	// CrashDir is the name of the directory that holds crash reports.
+	CrashDir = "crashes"
+	// EscrowDir is the name of the directory containing journals of secrets encrypted under an escrow key.
+	EscrowDir = "escrow"
 	// GitDir is the name of the folder git uses to store information.
 	GitDir = ".git"
 	// HistoryDir is the name of the directory that holds historical information for projects.
 	HistoryDir = "history"
+	// JournalDir is the name of the directory that holds the journals of changes to stacks' checkpoints.
+	JournalDir = "journals"
+	// LockDir is the name of the directory that holds the locks of stacks that are being updated.
+	LockDir = "locks"
 	// PluginDir is the name of the directory containing plugins.
 	PluginDir = "plugins"
 	// PolicyDir is the name of the directory that holds policy packs.
@@ -47,6 +56,8 @@ const (
 	StackDir = "stacks"
 	// TemplateDir is the name of the directory containing templates.
 	TemplateDir = "templates"
+	// PolicyTemplateDir is the name of the directory containing policy pack templates.
+	PolicyTemplateDir = "templates-policy"
 	// WorkspaceDir is the name of the directory that holds workspace information for projects.
 	WorkspaceDir = "workspaces"
 
@@ -59,8 +70,14 @@ const (
 	RepoFile = "settings.json"
 	// WorkspaceFile is the name of the file that holds workspace information.
 	WorkspaceFile = "workspace.json"
+	// AuditLogFile is the name of the default file that audit records are appended to.
+	AuditLogFile = "audit.log"
 	// CachedVersionFile is the name of the file we use to store when we last checked if the CLI was out of date
 	CachedVersionFile = ".cachedVersionInfo"
+	// TimingsFile is the name of the file that records how long resource operations have taken.
+	TimingsFile = "timings.json"
+	// UpdatesFile is the name of the file that holds the summaries of the last operations on a project's stacks.
+	UpdatesFile = "updates.json"
 )
 
 // DetectProjectPath locates the closest project from the current working directory, or an error if not found.
@@ -86,13 +103,108 @@ func DetectProjectStackPath(stackName tokens.QName) (string, error) {
 		return "", err
 	}
 
-	return filepath.Join(filepath.Dir(projPath), proj.Config, fmt.Sprintf("%s.%s%s", ProjectFile, qnameFileName(stackName),
-		filepath.Ext(projPath))), nil
+	return ProjectStackPath(proj, projPath, stackName)
+}
+
+// ProjectStackPath returns the name of the file to store stack specific project settings in for the given project,
+// which was loaded from the file at projPath.
+//
+// Stack files are kept next to the project file unless the project names a config directory, in which case a stack's
+// file may be at the top of the directory or in any directory beneath it, so that projects with many stacks can group
+// their files. A stack whose file does not exist yet is given one at the top of the config directory, unless it still
+// has one next to the project file, from before the project named its config directory. It is an error for more than
+// one directory beneath the config directory to hold the stack's file.
+func ProjectStackPath(proj *Project, projPath string, stackName tokens.QName) (string, error) {
+	root := filepath.Dir(projPath)
+	name := fmt.Sprintf("%s.%s%s", ProjectFile, qnameFileName(stackName), filepath.Ext(projPath))
+	path := filepath.Join(root, proj.Config, name)
+	if proj.Config == "" {
+		return path, nil
+	}
+
+	if fileExists(path) {
+		return path, nil
+	}
+	switch nested := proj.stackFiles(filepath.Join(root, proj.Config)).paths[name]; len(nested) {
+	case 0:
+	case 1:
+		return nested[0], nil
+	default:
+		return "", errors.Errorf("stack '%s' has more than one configuration file: %s; remove all but one of them",
+			stackName, strings.Join(nested, ", "))
+	}
+	if legacy := filepath.Join(root, name); fileExists(legacy) {
+		return legacy, nil
+	}
+	return path, nil
+}
+
+// EnvironmentsDir is the name of the directory, within a project's config directory, that holds its environment files.
+const EnvironmentsDir = "environments"
+
+// ProjectEnvironmentPath returns the name of the file that holds the configuration of the given environment of the
+// project loaded from projPath. Environment files are kept apart from stack files, in the EnvironmentsDir directory
+// of the project's config directory, and are named after their environment, e.g. environments/staging.yaml.
+func ProjectEnvironmentPath(proj *Project, projPath string, env tokens.QName) string {
+	name := qnameFileName(env) + filepath.Ext(projPath)
+	return filepath.Join(filepath.Dir(projPath), proj.Config, EnvironmentsDir, name)
+}
+
+// stackFileIndex records the files in the directories beneath a project's config directory, by name, so that stack
+// files kept in them are found without walking the directory each time.
+type stackFileIndex struct {
+	dir   string
+	paths map[string][]string
+}
+
+// stackFiles returns the index of the files beneath dir, the project's config directory. The directory is walked the
+// first time it is needed, and the index is kept for as long as the project is loaded.
+func (proj *Project) stackFiles(dir string) *stackFileIndex {
+	if proj.configIndex == nil || proj.configIndex.dir != dir {
+		proj.configIndex = indexStackFiles(dir)
+	}
+	return proj.configIndex
+}
+
+// indexStackFiles returns the index of the files in the directories beneath dir, in lexical order. Hidden
+// directories, and directories that cannot be read, are skipped.
+func indexStackFiles(dir string) *stackFileIndex {
+	index := &stackFileIndex{dir: dir, paths: make(map[string][]string)}
+	contract.IgnoreError(filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// Skip directories that cannot be read.
+			return nil
+		}
+		if info.IsDir() {
+			if path != dir && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Dir(path) != dir {
+			index.paths[info.Name()] = append(index.paths[info.Name()], path)
+		}
+		return nil
+	}))
+	return index
+}
+
+// fileExists returns true if path names an existing file.
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
 }
 
 // DetectProjectPathFrom locates the closest project from the given path, searching "upwards" in the directory
 // hierarchy.  If no project is found, an empty path is returned.
 func DetectProjectPathFrom(path string) (string, error) {
+	// Walk up from an absolute path so that parents are found lexically; this keeps symlinks and junctions in the path
+	// intact, so a project that is reached through one is found in the directory the user expects.
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
 	return fsutil.WalkUp(path, isProject, func(s string) bool {
 		return true
 	})
@@ -187,3 +299,44 @@ func GetCachedVersionFilePath() (string, error) {
 
 	return filepath.Join(user.HomeDir, BookkeepingDir, CachedVersionFile), nil
 }
+
+// GetAuditLogPath returns the default location of the local audit log.
+func GetAuditLogPath() (string, error) {
+	user, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(user.HomeDir, BookkeepingDir, AuditLogFile), nil
+}
+
+// GetTimingsPath returns the path of the file that records how long resource operations have taken.
+func GetTimingsPath() (string, error) {
+	user, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(user.HomeDir, BookkeepingDir, TimingsFile), nil
+}
+
+// GetCrashReportDir returns the directory that crash reports are written to.
+func GetCrashReportDir() (string, error) {
+	user, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(user.HomeDir, BookkeepingDir, CrashDir), nil
+}
+
+// GetSecretsEscrowJournalPath returns the location of the journal recording which of the given stack's secrets were
+// encrypted under the local escrow key rather than by the Pulumi service.
+func GetSecretsEscrowJournalPath(owner, project, stack string) (string, error) {
+	user, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(user.HomeDir, BookkeepingDir, EscrowDir, owner, project, stack+".jsonl"), nil
+}
@@ -16,9 +16,14 @@ package workspace
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/util/contract"
@@ -40,6 +45,135 @@ type ProjectTemplate struct {
 	Quickstart string `json:"quickstart,omitempty" yaml:"quickstart,omitempty"`
 	// Config is an optional template config.
 	Config map[string]ProjectTemplateConfigValue `json:"config,omitempty" yaml:"config,omitempty"`
+	// Parameters are optional values that `pulumi new` prompts for, in order, and substitutes for `${NAME}` in the
+	// template's files.
+	Parameters []ProjectTemplateParameter `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+}
+
+// Validate returns an error if any of the template's parameters are malformed.
+func (t *ProjectTemplate) Validate() error {
+	seen := make(map[string]bool)
+	for i, p := range t.Parameters {
+		if err := p.Validate(); err != nil {
+			return errors.Wrapf(err, "parameter #%d", i)
+		}
+		if seen[p.Name] {
+			return errors.Errorf("parameter '%s' is declared more than once", p.Name)
+		}
+		seen[p.Name] = true
+	}
+	return nil
+}
+
+// TemplateParameterTypeChoice is the type of template parameters whose value must be one of a fixed set of choices.
+// Template parameters may also be of type ConfigTypeString, ConfigTypeNumber, or ConfigTypeBoolean.
+const TemplateParameterTypeChoice = "choice"
+
+// templateParameterNameRegexp matches the names of template parameters, which are substituted for `${NAME}`.
+var templateParameterNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ProjectTemplateParameter declares a value that `pulumi new` prompts for when creating a project from the template.
+type ProjectTemplateParameter struct {
+	// Name is the name of the parameter; occurrences of `${Name}` in the template's files are replaced by its value.
+	Name string `json:"name" yaml:"name"`
+	// Description is an optional description of the parameter, which is used as its prompt.
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// Type is the type of the value: string (the default), number, boolean, or choice.
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+	// Default is an optional default value. Parameters without a default value must be given a value.
+	Default interface{} `json:"default,omitempty" yaml:"default,omitempty"`
+	// Choices are the values that a choice parameter may take.
+	Choices []string `json:"choices,omitempty" yaml:"choices,omitempty"`
+	// Pattern is an optional regular expression that string values must match.
+	Pattern string `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+}
+
+// Validate returns an error if the parameter is malformed.
+func (p ProjectTemplateParameter) Validate() error {
+	if !templateParameterNameRegexp.MatchString(p.Name) {
+		return errors.Errorf("'%s' is not a valid parameter name", p.Name)
+	}
+	if p.Name == "PROJECT" || p.Name == "DESCRIPTION" {
+		return errors.Errorf("'%s' is reserved for the project's %s", p.Name, strings.ToLower(p.Name))
+	}
+	switch p.Type {
+	case "", ConfigTypeString, ConfigTypeNumber, ConfigTypeBoolean:
+		if len(p.Choices) != 0 {
+			return errors.Errorf("parameter '%s' has choices but is not of type %s", p.Name, TemplateParameterTypeChoice)
+		}
+	case TemplateParameterTypeChoice:
+		if len(p.Choices) == 0 {
+			return errors.Errorf("parameter '%s' has no choices", p.Name)
+		}
+	default:
+		return errors.Errorf("parameter '%s' has unknown type '%s'", p.Name, p.Type)
+	}
+	if p.Pattern != "" {
+		if p.Type != "" && p.Type != ConfigTypeString {
+			return errors.Errorf("parameter '%s' has a pattern but is not of type %s", p.Name, ConfigTypeString)
+		}
+		if _, err := regexp.Compile(p.Pattern); err != nil {
+			return errors.Wrapf(err, "parameter '%s' has an invalid pattern", p.Name)
+		}
+	}
+	if p.Default != nil {
+		def, ok := p.DefaultValue()
+		if !ok {
+			return errors.Errorf("parameter '%s' must have a string, number, or boolean default value", p.Name)
+		}
+		if _, err := p.Check(def); err != nil {
+			return errors.Wrapf(err, "default value of parameter '%s'", p.Name)
+		}
+	}
+	return nil
+}
+
+// DefaultValue returns the default value as a string, if there is one.
+func (p ProjectTemplateParameter) DefaultValue() (string, bool) {
+	switch d := p.Default.(type) {
+	case string:
+		return d, true
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprintf("%v", d), true
+	default:
+		return "", false
+	}
+}
+
+// Check returns an error if the given value does not conform to the parameter's declaration. Otherwise, it returns
+// the value to substitute into the template's files, in which booleans are spelled "true" or "false".
+func (p ProjectTemplateParameter) Check(value string) (string, error) {
+	switch p.Type {
+	case ConfigTypeNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "", errors.Errorf("%q is not a number", value)
+		}
+	case ConfigTypeBoolean:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", errors.Errorf("%q is not a boolean", value)
+		}
+		return strconv.FormatBool(b), nil
+	case TemplateParameterTypeChoice:
+		for _, c := range p.Choices {
+			if value == c {
+				return value, nil
+			}
+		}
+		return "", errors.Errorf("%q is not one of %s", value, strings.Join(p.Choices, ", "))
+	default:
+		if p.Pattern != "" {
+			// Anchor the pattern so that it must match the whole value.
+			re, err := regexp.Compile("^(?:" + p.Pattern + ")$")
+			if err != nil {
+				return "", err
+			}
+			if !re.MatchString(value) {
+				return "", errors.Errorf("%q does not match the pattern %s", value, p.Pattern)
+			}
+		}
+	}
+	return value, nil
 }
 
 // ProjectTemplateConfigValue is a config value included in the project template manifest.
@@ -58,6 +192,339 @@ type ProjectBackend struct {
 	URL string `json:"url,omitempty" yaml:"url,omitempty"`
 }
 
+// ProjectAuditConfig configures the local audit log of commands that change a project's stacks.
+type ProjectAuditConfig struct {
+	// Path is an optional path of the file to append audit records to. Defaults to ~/.pulumi/audit.log.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+	// Syslog may be set to true to also forward audit records to the system log.
+	Syslog bool `json:"syslog,omitempty" yaml:"syslog,omitempty"`
+}
+
+// ProjectSandboxConfig restricts the program's language host during previews, for projects whose previews run code
+// that may not be trusted, such as that of pull requests. The `pulumi preview` sandbox flags add to these settings.
+type ProjectSandboxConfig struct {
+	// User is an optional name of the OS user to run the language host as.
+	User string `json:"user,omitempty" yaml:"user,omitempty"`
+	// NoNetwork may be set to true to run the language host without network access, except to the engine. This is only
+	// supported on Linux, and requires bubblewrap; elsewhere previews fail.
+	NoNetwork bool `json:"nonetwork,omitempty" yaml:"nonetwork,omitempty"`
+	// ReadOnly may be set to true to mount the project directory as read-only for the language host.
+	ReadOnly bool `json:"readonly,omitempty" yaml:"readonly,omitempty"`
+}
+
+// UpdateMessagePolicy requires the messages of updates to match a template, such as one that includes a ticket ID.
+type UpdateMessagePolicy struct {
+	// Pattern is a regular expression that update messages must match, e.g. `^[A-Z]+-[0-9]+: `.
+	Pattern string `json:"pattern" yaml:"pattern"`
+	// Description optionally describes the required format to users, e.g. "a ticket ID, such as OPS-123: ...".
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// Validate checks that the policy's pattern is a valid regular expression.
+func (p *UpdateMessagePolicy) Validate() error {
+	if p.Pattern == "" {
+		return errors.New("missing 'pattern'")
+	}
+	if _, err := regexp.Compile(p.Pattern); err != nil {
+		return errors.Wrap(err, "invalid 'pattern'")
+	}
+	return nil
+}
+
+// Check returns an error if the given update message does not comply with the policy.
+func (p *UpdateMessagePolicy) Check(msg string) error {
+	re, err := regexp.Compile(p.Pattern)
+	if err != nil {
+		return errors.Wrap(err, "invalid update message pattern")
+	}
+	if !re.MatchString(msg) {
+		format := p.Description
+		if format == "" {
+			format = fmt.Sprintf("a message matching `%s`", p.Pattern)
+		}
+		return errors.Errorf("update messages for this stack must be %s", format)
+	}
+	return nil
+}
+
+// DefaultRedactionReplacement is the text that redacted values are replaced with if a rule does not specify its own.
+const DefaultRedactionReplacement = "[redacted]"
+
+// RedactionRule hides sensitive but unencrypted values, such as internal hostnames, from the CLI's display and
+// logs. A rule either redacts all text matching a pattern, or the value at a property path of matching resources.
+type RedactionRule struct {
+	// Pattern is a regular expression; all displayed or logged text that matches it is redacted.
+	Pattern string `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	// Property is a property path, e.g. `endpoint.host` or `servers[0]`, whose value in the inputs and outputs of
+	// matching resources is redacted.
+	Property string `json:"property,omitempty" yaml:"property,omitempty"`
+	// Type is an optional regular expression; a property rule only applies to resources whose type token matches it.
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+	// Replacement is the text that redacted values are replaced with. Defaults to DefaultRedactionReplacement.
+	Replacement string `json:"replacement,omitempty" yaml:"replacement,omitempty"`
+}
+
+// Validate checks that the rule has exactly one of a pattern or a property, and that its regular expressions are
+// valid. Property paths are checked when the rule is applied.
+func (r *RedactionRule) Validate() error {
+	switch {
+	case r.Pattern == "" && r.Property == "":
+		return errors.New("one of 'pattern' or 'property' is required")
+	case r.Pattern != "" && r.Property != "":
+		return errors.New("only one of 'pattern' or 'property' may be set")
+	case r.Pattern != "" && r.Type != "":
+		return errors.New("'type' may only be set for 'property' rules")
+	}
+	if r.Pattern != "" {
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return errors.Wrap(err, "invalid 'pattern'")
+		}
+	}
+	if r.Type != "" {
+		if _, err := regexp.Compile(r.Type); err != nil {
+			return errors.Wrap(err, "invalid 'type'")
+		}
+	}
+	return nil
+}
+
+// GetReplacement returns the text that values redacted by this rule are replaced with.
+func (r *RedactionRule) GetReplacement() string {
+	if r.Replacement == "" {
+		return DefaultRedactionReplacement
+	}
+	return r.Replacement
+}
+
+// ResourceTransformation is a declarative transformation that the engine applies to every matching resource as it is
+// registered, allowing conventions to be enforced without modifying each program.
+type ResourceTransformation struct {
+	// Type is an optional regular expression; only resources whose type token matches it are transformed.
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+	// Tags are merged into the tag property of matching custom resources that are taggable, overriding any existing
+	// values. The tag property and taggable types are the same as for tag propagation (see TagPropagationConfig).
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// Protect, if set, overrides the protect option of matching resources.
+	Protect *bool `json:"protect,omitempty" yaml:"protect,omitempty"`
+	// Rename optionally rewrites the names of matching resources.
+	Rename *ResourceRename `json:"rename,omitempty" yaml:"rename,omitempty"`
+}
+
+// ResourceRename rewrites a resource's name by replacing matches of a regular expression. Renaming a resource changes
+// its URN, so the engine aliases it to its URN from before the rename, and an existing resource is not replaced.
+type ResourceRename struct {
+	// Pattern is the regular expression to match against the resource's name.
+	Pattern string `json:"pattern" yaml:"pattern"`
+	// Replacement is the replacement text, which may refer to capture groups (e.g. `$1`).
+	Replacement string `json:"replacement" yaml:"replacement"`
+}
+
+// Validate ensures that the transformation's regular expressions are well-formed.
+func (t ResourceTransformation) Validate() error {
+	if t.Type != "" {
+		if _, err := regexp.Compile(t.Type); err != nil {
+			return errors.Wrapf(err, "invalid type pattern %q", t.Type)
+		}
+	}
+	if t.Rename != nil {
+		if t.Rename.Pattern == "" {
+			return errors.New("rename is missing a 'pattern' attribute")
+		}
+		if _, err := regexp.Compile(t.Rename.Pattern); err != nil {
+			return errors.Wrapf(err, "invalid rename pattern %q", t.Rename.Pattern)
+		}
+	}
+	return nil
+}
+
+// AutoNamingConfig configures how the engine generates physical names for custom resources that do not specify one.
+// If neither a default nor a strategy for a resource's type is given, auto-naming is left to the resource's provider.
+// Because providers reject properties their types do not declare, the default strategy only applies to types whose
+// installed schemas declare the name property; a strategy given for a type always applies to it.
+type AutoNamingConfig struct {
+	// Default is the strategy to use for resource types without a more specific strategy.
+	Default *AutoNamingStrategy `json:"default,omitempty" yaml:"default,omitempty"`
+	// Types maps resource type tokens to the strategy to use for those types.
+	Types map[string]AutoNamingStrategy `json:"types,omitempty" yaml:"types,omitempty"`
+}
+
+// Auto-naming modes.
+const (
+	// AutoNamingModeRandom appends a random suffix to each resource's logical name.  This is the default.
+	AutoNamingModeRandom = "random"
+	// AutoNamingModeStable derives the suffix from a hash of the resource's URN, so that a recreated stack gets
+	// identical names.
+	AutoNamingModeStable = "stable"
+)
+
+// AutoNamingStrategy describes how to generate a physical name from a resource's logical name.
+type AutoNamingStrategy struct {
+	// Property is the input property that holds the resource's name.  Defaults to `name`.
+	Property string `json:"property,omitempty" yaml:"property,omitempty"`
+	// Mode is either `random` (the default) or `stable`.
+	Mode string `json:"mode,omitempty" yaml:"mode,omitempty"`
+	// SuffixLength is the number of hex characters in the suffix.  Defaults to 7; 0 means no suffix at all.
+	SuffixLength *int `json:"suffixLength,omitempty" yaml:"suffixLength,omitempty"`
+	// Delimiter separates the logical name from the suffix.  Defaults to `-`.
+	Delimiter *string `json:"delimiter,omitempty" yaml:"delimiter,omitempty"`
+	// Casing is an optional case to convert the whole name to, either `lower` or `upper`.
+	Casing string `json:"casing,omitempty" yaml:"casing,omitempty"`
+}
+
+// Validate ensures that the strategy's settings are legal.
+func (s AutoNamingStrategy) Validate() error {
+	switch s.Mode {
+	case "", AutoNamingModeRandom, AutoNamingModeStable:
+	default:
+		return errors.Errorf("unknown auto-naming mode %q (expected %q or %q)",
+			s.Mode, AutoNamingModeRandom, AutoNamingModeStable)
+	}
+	switch s.Casing {
+	case "", "lower", "upper":
+	default:
+		return errors.Errorf("unknown auto-naming casing %q (expected \"lower\" or \"upper\")", s.Casing)
+	}
+	if s.SuffixLength != nil && (*s.SuffixLength < 0 || *s.SuffixLength > 64) {
+		return errors.Errorf("auto-naming suffix length must be between 0 and 64, not %d", *s.SuffixLength)
+	}
+	return nil
+}
+
+// TagPropagationConfig maps stack metadata onto the tag properties of taggable resources, e.g. for cost attribution.
+type TagPropagationConfig struct {
+	// Tags maps tag names to values, which may refer to `${project}`, `${stack}`, and `${commit}`. Defaults to
+	// DefaultPropagatedTags. Since a tag that refers to `${commit}` changes with every commit, so does every resource
+	// it is applied to.
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// Properties maps provider packages to the input property that holds tags for that provider's resources. Only
+	// resources from the listed packages are tagged. Defaults to DefaultTagProperties.
+	Properties map[string]string `json:"properties,omitempty" yaml:"properties,omitempty"`
+	// Types optionally lists the resource types to tag, e.g. `aws:s3/bucket:Bucket`. If there are none, a resource is
+	// tagged only if the schema installed with its provider declares the tag property for its type.
+	Types []string `json:"types,omitempty" yaml:"types,omitempty"`
+}
+
+// DefaultPropagatedTags is the set of tags propagated if a project does not specify its own. It leaves out the commit,
+// which would change every tagged resource on every commit.
+var DefaultPropagatedTags = map[string]string{
+	"pulumi:project": "${project}",
+	"pulumi:stack":   "${stack}",
+}
+
+// DefaultTagProperties is the set of tag properties used for tag propagation if a project does not specify its own.
+var DefaultTagProperties = map[string]string{
+	"aws":   "tags",
+	"azure": "tags",
+	"gcp":   "labels",
+}
+
+// The types that may be declared for configuration keys.
+const (
+	ConfigTypeString  = "string"
+	ConfigTypeInteger = "integer"
+	ConfigTypeNumber  = "number"
+	ConfigTypeBoolean = "boolean"
+	ConfigTypeArray   = "array"
+	ConfigTypeObject  = "object"
+)
+
+// ProjectConfigType declares the type of a configuration key used by the project, along with an optional project-wide
+// default value that stacks may override.
+type ProjectConfigType struct {
+	// Type is the type of the value: string (the default), integer, number, boolean, array, or object.
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+	// Description is an optional description of the key.
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// Default is an optional scalar default value, used by stacks that do not set the key themselves.
+	Default interface{} `json:"default,omitempty" yaml:"default,omitempty"`
+	// Secret indicates that stacks must store the value encrypted.
+	Secret bool `json:"secret,omitempty" yaml:"secret,omitempty"`
+}
+
+// Validate returns an error if the configuration type is malformed.
+func (t ProjectConfigType) Validate() error {
+	switch t.Type {
+	case "", ConfigTypeString, ConfigTypeInteger, ConfigTypeNumber, ConfigTypeBoolean, ConfigTypeArray, ConfigTypeObject:
+	default:
+		return errors.Errorf("unknown type '%s'", t.Type)
+	}
+	if t.Default != nil {
+		def, ok := t.DefaultValue()
+		if !ok {
+			return errors.New("default values must be strings, numbers, or booleans")
+		}
+		if err := t.Check(def); err != nil {
+			return errors.Wrap(err, "default value")
+		}
+	}
+	return nil
+}
+
+// DefaultValue returns the default value as a configuration string, if there is one.
+func (t ProjectConfigType) DefaultValue() (string, bool) {
+	switch d := t.Default.(type) {
+	case string:
+		return d, true
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprintf("%v", d), true
+	default:
+		return "", false
+	}
+}
+
+// Check returns an error if the given configuration value does not conform to the declared type.
+func (t ProjectConfigType) Check(value string) error {
+	var err error
+	switch t.Type {
+	case ConfigTypeInteger:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case ConfigTypeNumber:
+		_, err = strconv.ParseFloat(value, 64)
+	case ConfigTypeBoolean:
+		_, err = strconv.ParseBool(value)
+	case ConfigTypeArray:
+		var a []interface{}
+		err = json.Unmarshal([]byte(value), &a)
+	case ConfigTypeObject:
+		var o map[string]interface{}
+		err = json.Unmarshal([]byte(value), &o)
+	}
+	if err != nil {
+		return errors.Errorf("%q is not a valid %s", value, t.Type)
+	}
+	return nil
+}
+
+// ProjectOutputType declares the type of one of the outputs exported by a project's stacks, or of one of the outputs
+// that a project reads from another stack.
+type ProjectOutputType struct {
+	// Type is the type of the output: string, integer, number, boolean, array, or object. If it is empty, the output
+	// may have any type.
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+	// Description is an optional description of the output.
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// Secret indicates that the output is a secret.
+	Secret bool `json:"secret,omitempty" yaml:"secret,omitempty"`
+}
+
+// Validate returns an error if the output type is malformed.
+func (t ProjectOutputType) Validate() error {
+	switch t.Type {
+	case "", ConfigTypeString, ConfigTypeInteger, ConfigTypeNumber, ConfigTypeBoolean, ConfigTypeArray, ConfigTypeObject:
+		return nil
+	default:
+		return errors.Errorf("unknown type '%s'", t.Type)
+	}
+}
+
+// ProjectStackReference declares the outputs that a project's programs read from another stack through a stack
+// reference. Each is checked against the outputs of the referenced stack, and against the schema it has published, if
+// any, when the reference is read.
+type ProjectStackReference struct {
+	// Outputs are the outputs read from the stack, and their expected types.
+	Outputs map[string]ProjectOutputType `json:"outputs,omitempty" yaml:"outputs,omitempty"`
+}
+
 // Project is a Pulumi project manifest.
 //
 // We explicitly add yaml tags (instead of using the default behavior from https://github.com/ghodss/yaml which works
@@ -83,13 +550,56 @@ type Project struct {
 	License *string `json:"license,omitempty" yaml:"license,omitempty"`
 
 	// Config indicates where to store the Pulumi.<stack-name>.yaml files, combined with the folder Pulumi.yaml is in.
+	// Stack files may also be kept in any directory beneath it.
 	Config string `json:"config,omitempty" yaml:"config,omitempty"`
 
+	// ConfigSchema optionally declares the types and project-wide defaults of configuration keys. Keys without a
+	// namespace belong to the project.
+	ConfigSchema map[string]ProjectConfigType `json:"configschema,omitempty" yaml:"configschema,omitempty"`
+
+	// ConfigValues optionally sets configuration values shared by all of the project's stacks. They override the
+	// defaults declared by ConfigSchema, and are overridden by environment files and the stacks' own configuration.
+	// Keys without a namespace belong to the project. Secrets are not supported.
+	ConfigValues map[string]config.Value `json:"configvalues,omitempty" yaml:"configvalues,omitempty"`
+
+	// OutputSchema optionally declares the types of the outputs exported by the project's stacks. Together with the
+	// config schema, it is published by backends that support it whenever one of the stacks is updated, so that
+	// projects that reference the stacks can check the outputs they read against it.
+	OutputSchema map[string]ProjectOutputType `json:"outputschema,omitempty" yaml:"outputschema,omitempty"`
+
+	// StackReferences optionally declares the outputs the project's programs read from other stacks, keyed by the
+	// fully qualified names of the stacks.
+	StackReferences map[string]ProjectStackReference `json:"stackreferences,omitempty" yaml:"stackreferences,omitempty"`
+
 	// Template is an optional template manifest, if this project is a template.
 	Template *ProjectTemplate `json:"template,omitempty" yaml:"template,omitempty"`
 
 	// Backend is an optional backend configuration
 	Backend *ProjectBackend `json:"backend,omitempty" yaml:"backend,omitempty"`
+
+	// Transformations is an optional list of transformations to apply to every resource in every stack.
+	Transformations []ResourceTransformation `json:"transformations,omitempty" yaml:"transformations,omitempty"`
+
+	// AutoNaming optionally configures how the engine generates physical names for resources.
+	AutoNaming *AutoNamingConfig `json:"autonaming,omitempty" yaml:"autonaming,omitempty"`
+
+	// TagPropagation optionally applies stack metadata as tags to all taggable resources.
+	TagPropagation *TagPropagationConfig `json:"tagpropagation,omitempty" yaml:"tagpropagation,omitempty"`
+
+	// Audit optionally records every command that changes one of this project's stacks to a local audit log.
+	Audit *ProjectAuditConfig `json:"audit,omitempty" yaml:"audit,omitempty"`
+
+	// UpdateMessage optionally requires the messages of updates to this project's stacks to match a template.
+	UpdateMessage *UpdateMessagePolicy `json:"updatemessage,omitempty" yaml:"updatemessage,omitempty"`
+
+	// Redact optionally hides sensitive but unencrypted values from the CLI's display and logs.
+	Redact []RedactionRule `json:"redact,omitempty" yaml:"redact,omitempty"`
+
+	// Sandbox optionally restricts the program's language host during previews.
+	Sandbox *ProjectSandboxConfig `json:"sandbox,omitempty" yaml:"sandbox,omitempty"`
+
+	// configIndex records the files beneath the project's config directory once it has been walked.
+	configIndex *stackFileIndex
 }
 
 func (proj *Project) Validate() error {
@@ -99,10 +609,196 @@ func (proj *Project) Validate() error {
 	if proj.Runtime.Name() == "" {
 		return errors.New("project is missing a 'runtime' attribute")
 	}
+	if proj.Config != "" {
+		if dir := filepath.Clean(proj.Config); filepath.IsAbs(dir) || dir == ".." ||
+			strings.HasPrefix(dir, ".."+string(filepath.Separator)) {
+			return errors.Errorf("config directory '%s' must be a relative path inside the project", proj.Config)
+		}
+	}
+	for k, t := range proj.ConfigSchema {
+		if _, err := proj.ConfigSchemaKey(k); err != nil {
+			return errors.Wrapf(err, "config schema for '%s'", k)
+		}
+		if err := t.Validate(); err != nil {
+			return errors.Wrapf(err, "config schema for '%s'", k)
+		}
+	}
+	for k, v := range proj.ConfigValues {
+		if _, err := proj.ConfigSchemaKey(k); err != nil {
+			return errors.Wrapf(err, "config value for '%s'", k)
+		}
+		if v.Secure() {
+			return errors.Errorf("config value for '%s' is a secret; secrets must be set in stack configuration", k)
+		}
+	}
+	for k, t := range proj.OutputSchema {
+		if err := t.Validate(); err != nil {
+			return errors.Wrapf(err, "output schema for '%s'", k)
+		}
+	}
+	for name, ref := range proj.StackReferences {
+		for k, t := range ref.Outputs {
+			if err := t.Validate(); err != nil {
+				return errors.Wrapf(err, "stack reference '%s': output '%s'", name, k)
+			}
+		}
+	}
+	for i, t := range proj.Transformations {
+		if err := t.Validate(); err != nil {
+			return errors.Wrapf(err, "transformation #%d", i)
+		}
+	}
+	for i, r := range proj.Redact {
+		if err := r.Validate(); err != nil {
+			return errors.Wrapf(err, "redaction rule #%d", i)
+		}
+	}
+	if proj.UpdateMessage != nil {
+		if err := proj.UpdateMessage.Validate(); err != nil {
+			return errors.Wrap(err, "updatemessage")
+		}
+	}
+	if proj.Template != nil {
+		if err := proj.Template.Validate(); err != nil {
+			return errors.Wrap(err, "template")
+		}
+	}
+	if proj.AutoNaming != nil {
+		if proj.AutoNaming.Default != nil {
+			if err := proj.AutoNaming.Default.Validate(); err != nil {
+				return errors.Wrap(err, "autonaming")
+			}
+		}
+		for t, s := range proj.AutoNaming.Types {
+			if err := s.Validate(); err != nil {
+				return errors.Wrapf(err, "autonaming for type %s", t)
+			}
+		}
+	}
 
 	return nil
 }
 
+// ConfigSchemaKey returns the configuration key declared by the given config schema entry. Keys without a namespace
+// belong to the project.
+func (proj *Project) ConfigSchemaKey(k string) (config.Key, error) {
+	if !strings.Contains(k, tokens.TokenDelimiter) {
+		k = string(proj.Name) + tokens.TokenDelimiter + k
+	}
+	return config.ParseKey(k)
+}
+
+// ConfigDefaults returns the project-wide default values declared by the project's config schema. Stacks inherit these
+// values for any keys they do not set themselves.
+func (proj *Project) ConfigDefaults() (config.Map, error) {
+	defaults := make(config.Map)
+	for k, t := range proj.ConfigSchema {
+		def, ok := t.DefaultValue()
+		if !ok {
+			continue
+		}
+		key, err := proj.ConfigSchemaKey(k)
+		if err != nil {
+			return nil, errors.Wrapf(err, "config schema for '%s'", k)
+		}
+		defaults[key] = config.NewValue(def)
+	}
+	return defaults, nil
+}
+
+// StackConfigLayers returns the layers of the configuration of one of the project's stacks, in order of increasing
+// precedence:
+//
+//     - the defaults declared by the project's config schema;
+//     - the project's config values;
+//     - the stack's environment files, in the order the stack lists them;
+//     - the stack's own configuration.
+//
+// projPath is the path of the project's Pulumi.yaml, and ps holds the stack's settings, which are stored at stackPath.
+// An environment file is kept in the environments directory of the project's config directory (see
+// ProjectEnvironmentPath), so that it is never mistaken for the configuration file of a stack with the same name. It
+// has the same form as a stack configuration file, and may list environments of its own, whose files then come before
+// it. Environment files may not contain secrets, since they are shared by stacks that may use different secrets
+// providers.
+func (proj *Project) StackConfigLayers(projPath string, ps *ProjectStack, stackPath string) (config.Layers, error) {
+	defaults, err := proj.ConfigDefaults()
+	if err != nil {
+		return nil, err
+	}
+	values := make(config.Map)
+	for k, v := range proj.ConfigValues {
+		key, err := proj.ConfigSchemaKey(k)
+		if err != nil {
+			return nil, errors.Wrapf(err, "config value for '%s'", k)
+		}
+		values[key] = v
+	}
+
+	projFile := filepath.Base(projPath)
+	layers := config.Layers{
+		{Name: projFile + " (configschema)", Config: defaults},
+		{Name: projFile + " (configvalues)", Config: values},
+	}
+	envs := &environmentLayers{
+		proj:     proj,
+		projPath: projPath,
+		visiting: map[string]bool{},
+		added:    map[string]bool{},
+	}
+	if err = envs.add(ps.Environments); err != nil {
+		return nil, err
+	}
+	layers = append(layers, envs.layers...)
+	return append(layers, config.Layer{Name: filepath.Base(stackPath), Config: ps.Config}), nil
+}
+
+// environmentLayers collects the configuration layers of a stack's environment files.
+type environmentLayers struct {
+	proj     *Project
+	projPath string
+	visiting map[string]bool // the environments whose files are being added, to detect cycles.
+	added    map[string]bool // the environments whose files have been added.
+	layers   config.Layers
+}
+
+// add adds the layers of the given environments, after those of the environments they list.
+func (e *environmentLayers) add(envs []string) error {
+	for _, env := range envs {
+		if !tokens.IsQName(env) {
+			return errors.Errorf("'%s' is not a valid environment name", env)
+		}
+		if e.visiting[env] {
+			return errors.Errorf("environment '%s' includes itself", env)
+		}
+		if e.added[env] {
+			continue
+		}
+
+		path := ProjectEnvironmentPath(e.proj, e.projPath, tokens.QName(env))
+		if _, err := os.Stat(path); err != nil {
+			return errors.Wrapf(err, "loading the file of environment '%s'", env)
+		}
+		ps, err := LoadProjectStack(path)
+		if err != nil {
+			return errors.Wrapf(err, "loading the file of environment '%s'", env)
+		}
+		name := EnvironmentsDir + "/" + filepath.Base(path)
+		if ps.Config.HasSecureValue() {
+			return errors.Errorf("environment file %s contains secrets; secrets must be set in stack configuration",
+				name)
+		}
+
+		e.visiting[env] = true
+		if err = e.add(ps.Environments); err != nil {
+			return err
+		}
+		delete(e.visiting, env)
+		e.added[env] = true
+		e.layers = append(e.layers, config.Layer{Name: name, Config: ps.Config})
+	}
+	return nil
+}
+
 // TrustResourceDependencies returns whether or not this project's runtime can be trusted to accurately report
 // dependencies. All languages supported by Pulumi today do this correctly. This option remains useful when bringing
 // up new Pulumi languages.
@@ -139,8 +835,115 @@ type ProjectStack struct {
 	// EncryptionSalt is this stack's base64 encoded encryption salt.  Only used for
 	// passphrase-based secrets providers.
 	EncryptionSalt string `json:"encryptionsalt,omitempty" yaml:"encryptionsalt,omitempty"`
+	// Environments optionally lists the environment files whose configuration this stack inherits, in order of
+	// increasing precedence. The stack's own configuration takes precedence over all of them. See
+	// Project.StackConfigLayers.
+	Environments []string `json:"environments,omitempty" yaml:"environments,omitempty"`
 	// Config is an optional config bag.
 	Config config.Map `json:"config,omitempty" yaml:"config,omitempty"`
+	// Transformations is an optional list of transformations to apply to every resource in this stack, after those
+	// specified by the project.
+	Transformations []ResourceTransformation `json:"transformations,omitempty" yaml:"transformations,omitempty"`
+	// NamePrefix is an optional prefix applied to the physical names the engine generates for this stack's resources
+	// (see Project.AutoNaming). If the project does not configure auto-naming, the engine names resources with the
+	// default strategy whenever a prefix or suffix is set, so that it applies to every resource whose type's schema
+	// declares a name property; resources of other types are named by their providers, without the prefix, and a
+	// warning says so. Explicit physical names, logical names, and thus URNs, are unaffected. It may refer to
+	// `${stack}` and `${project}`.
+	NamePrefix string `json:"nameprefix,omitempty" yaml:"nameprefix,omitempty"`
+	// NameSuffix is an optional suffix, analogous to NamePrefix.
+	NameSuffix string `json:"namesuffix,omitempty" yaml:"namesuffix,omitempty"`
+	// Confirmation optionally restricts how updates to this stack may be confirmed.
+	Confirmation *StackConfirmation `json:"confirmation,omitempty" yaml:"confirmation,omitempty"`
+	// Guardrails optionally limit the impact of any single update to this stack.
+	Guardrails *StackGuardrails `json:"guardrails,omitempty" yaml:"guardrails,omitempty"`
+	// UpdateMessage optionally requires the messages of updates to this stack to match a template. It takes
+	// precedence over the project's policy.
+	UpdateMessage *UpdateMessagePolicy `json:"updatemessage,omitempty" yaml:"updatemessage,omitempty"`
+	// SecretMetadata records facts about the stack's secret config values, keyed by config key.
+	SecretMetadata map[string]*SecretMetadata `json:"secretmetadata,omitempty" yaml:"secretmetadata,omitempty"`
+}
+
+// SecretMetadata records facts about a secret config value that cannot be recovered from its ciphertext.
+type SecretMetadata struct {
+	// Rotated is when the value was last set or rotated.
+	Rotated time.Time `json:"rotated" yaml:"rotated"`
+}
+
+// MarkSecretRotated records that the secret value of the given config key was set or rotated at the given time.
+func (ps *ProjectStack) MarkSecretRotated(k config.Key, t time.Time) {
+	if ps.SecretMetadata == nil {
+		ps.SecretMetadata = make(map[string]*SecretMetadata)
+	}
+	ps.SecretMetadata[k.String()] = &SecretMetadata{Rotated: t.UTC()}
+}
+
+// ForgetSecret removes any metadata for the given config key, e.g. because its value is no longer a secret.
+func (ps *ProjectStack) ForgetSecret(k config.Key) {
+	delete(ps.SecretMetadata, k.String())
+	if len(ps.SecretMetadata) == 0 {
+		ps.SecretMetadata = nil
+	}
+}
+
+// StackGuardrails limit the impact of any single update to a stack. An update that would violate them fails during its
+// preview, and the update itself is constrained to the plan its preview records, which is checked before anything has
+// changed. An update that skips its preview must be given a saved plan instead.
+type StackGuardrails struct {
+	// MaxDeletes, if positive, is the largest number of resources that one update may delete.
+	MaxDeletes int `json:"maxdeletes,omitempty" yaml:"maxdeletes,omitempty"`
+	// MaxReplacements, if positive, is the largest number of resources that one update may replace.
+	MaxReplacements int `json:"maxreplacements,omitempty" yaml:"maxreplacements,omitempty"`
+	// DenyDelete lists resource types that may be neither deleted nor replaced.
+	DenyDelete []string `json:"denydelete,omitempty" yaml:"denydelete,omitempty"`
+}
+
+// Validate checks that the guardrails are well-formed.
+func (g *StackGuardrails) Validate() error {
+	if g.MaxDeletes < 0 {
+		return errors.New("guardrails.maxdeletes must not be negative")
+	}
+	if g.MaxReplacements < 0 {
+		return errors.New("guardrails.maxreplacements must not be negative")
+	}
+	for _, t := range g.DenyDelete {
+		if strings.Count(t, ":") != 2 {
+			return errors.Errorf("guardrails.denydelete: '%s' is not a resource type such as aws:s3/bucket:Bucket", t)
+		}
+	}
+	return nil
+}
+
+const (
+	// AutoApproveAlways lets `--yes` approve any update. This is the default.
+	AutoApproveAlways = "always"
+	// AutoApprovePreviewOK lets `--yes` approve only updates whose preview neither deletes nor replaces resources.
+	AutoApprovePreviewOK = "preview-ok"
+	// AutoApproveNever requires every update to be confirmed interactively, even if `--yes` is passed.
+	AutoApproveNever = "never"
+)
+
+// StackConfirmation restricts how updates to a stack may be confirmed, reducing the risk of a blanket `--yes`.
+type StackConfirmation struct {
+	// AutoApprove is one of `always`, `preview-ok`, or `never`, and controls which updates `--yes` may approve.
+	AutoApprove string `json:"autoapprove,omitempty" yaml:"autoapprove,omitempty"`
+	// TypeStackName, if positive, requires the stack's name to be typed to confirm any update that deletes or replaces
+	// at least this many resources, even if `--yes` is passed.
+	TypeStackName int `json:"typestackname,omitempty" yaml:"typestackname,omitempty"`
+}
+
+// Validate checks that the confirmation settings are well-formed.
+func (c *StackConfirmation) Validate() error {
+	switch c.AutoApprove {
+	case "", AutoApproveAlways, AutoApprovePreviewOK, AutoApproveNever:
+	default:
+		return errors.Errorf("confirmation.autoapprove must be one of %s, %s, or %s; got '%s'",
+			AutoApproveAlways, AutoApprovePreviewOK, AutoApproveNever, c.AutoApprove)
+	}
+	if c.TypeStackName < 0 {
+		return errors.New("confirmation.typestackname must not be negative")
+	}
+	return nil
 }
 
 // Save writes a project definition to a file.
@@ -300,6 +1103,21 @@ func LoadProjectStack(path string) (*ProjectStack, error) {
 	if ps.Config == nil {
 		ps.Config = make(config.Map)
 	}
+	if ps.Confirmation != nil {
+		if err = ps.Confirmation.Validate(); err != nil {
+			return nil, errors.Wrapf(err, "validating %s", path)
+		}
+	}
+	if ps.Guardrails != nil {
+		if err = ps.Guardrails.Validate(); err != nil {
+			return nil, errors.Wrapf(err, "validating %s", path)
+		}
+	}
+	if ps.UpdateMessage != nil {
+		if err = ps.UpdateMessage.Validate(); err != nil {
+			return nil, errors.Wrapf(err, "validating %s: updatemessage", path)
+		}
+	}
 
 	return &ps, err
 }
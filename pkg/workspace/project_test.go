@@ -2,10 +2,16 @@ package workspace
 
 import (
 	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v2"
+
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/tokens"
 )
 
 func TestProjectRuntimeInfoRoundtripYAML(t *testing.T) {
@@ -36,3 +42,188 @@ func TestProjectRuntimeInfoRoundtripYAML(t *testing.T) {
 	doTest(yaml.Marshal, yaml.Unmarshal)
 	doTest(json.Marshal, json.Unmarshal)
 }
+
+func TestUpdateMessagePolicy(t *testing.T) {
+	assert.Error(t, (&UpdateMessagePolicy{}).Validate())
+	assert.Error(t, (&UpdateMessagePolicy{Pattern: "OPS-("}).Validate())
+
+	p := &UpdateMessagePolicy{Pattern: `^[A-Z]+-[0-9]+: `}
+	assert.NoError(t, p.Validate())
+	assert.NoError(t, p.Check("OPS-123: rotate keys"))
+	assert.EqualError(t, p.Check("rotate keys"),
+		"update messages for this stack must be a message matching `^[A-Z]+-[0-9]+: `")
+
+	p.Description = "a ticket ID, such as OPS-123: ..."
+	assert.EqualError(t, p.Check(""), "update messages for this stack must be a ticket ID, such as OPS-123: ...")
+}
+
+func TestProjectTemplateParameters(t *testing.T) {
+	assert.Error(t, ProjectTemplateParameter{Name: "not-valid"}.Validate())
+	assert.Error(t, ProjectTemplateParameter{Name: "PROJECT"}.Validate())
+	assert.Error(t, ProjectTemplateParameter{Name: "X", Type: "integer"}.Validate())
+	assert.Error(t, ProjectTemplateParameter{Name: "X", Type: TemplateParameterTypeChoice}.Validate())
+	assert.Error(t, ProjectTemplateParameter{Name: "X", Choices: []string{"a"}}.Validate())
+	assert.Error(t, ProjectTemplateParameter{Name: "X", Type: ConfigTypeNumber, Pattern: "[0-9]+"}.Validate())
+	assert.Error(t, ProjectTemplateParameter{Name: "X", Pattern: "("}.Validate())
+	assert.Error(t, ProjectTemplateParameter{Name: "X", Type: ConfigTypeNumber, Default: "many"}.Validate())
+	assert.Error(t, (&ProjectTemplate{Parameters: []ProjectTemplateParameter{{Name: "X"}, {Name: "X"}}}).Validate())
+
+	str := ProjectTemplateParameter{Name: "BUCKET", Pattern: "[a-z-]+", Default: "logs"}
+	assert.NoError(t, str.Validate())
+	v, err := str.Check("my-bucket")
+	assert.NoError(t, err)
+	assert.Equal(t, "my-bucket", v)
+	_, err = str.Check("my-bucket!") // the pattern must match the whole value
+	assert.Error(t, err)
+
+	num := ProjectTemplateParameter{Name: "COUNT", Type: ConfigTypeNumber, Default: 3}
+	assert.NoError(t, num.Validate())
+	def, ok := num.DefaultValue()
+	assert.True(t, ok)
+	assert.Equal(t, "3", def)
+	_, err = num.Check("three")
+	assert.Error(t, err)
+
+	b := ProjectTemplateParameter{Name: "PUBLIC", Type: ConfigTypeBoolean, Default: true}
+	assert.NoError(t, b.Validate())
+	v, err = b.Check("1")
+	assert.NoError(t, err)
+	assert.Equal(t, "true", v)
+
+	choice := ProjectTemplateParameter{Name: "SIZE", Type: TemplateParameterTypeChoice,
+		Choices: []string{"small", "large"}, Default: "small"}
+	assert.NoError(t, choice.Validate())
+	_, err = choice.Check("medium")
+	assert.Error(t, err)
+}
+
+func TestStackConfigLayers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config-layers")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, content string) {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0700))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+	write("Pulumi.yaml", "name: proj\nruntime: nodejs\n"+
+		"configschema:\n  region:\n    default: us-west-2\n  size:\n    default: small\n"+
+		"configvalues:\n  size: medium\n  team: infra\n")
+	write("environments/shared.yaml", "config:\n  proj:team: platform\n  proj:tier: standard\n")
+	write("environments/staging.yaml", "environments: [shared]\nconfig:\n  proj:tier: premium\n")
+	write("Pulumi.staging-us.yaml", "environments: [staging]\nconfig:\n  proj:region: us-east-1\n")
+
+	// A stack named like an environment does not change the environment's configuration.
+	write("Pulumi.staging.yaml", "config:\n  proj:tier: basic\n")
+
+	projPath := filepath.Join(dir, "Pulumi.yaml")
+	proj, err := LoadProject(projPath)
+	if !assert.NoError(t, err) {
+		return
+	}
+	stackPath := filepath.Join(dir, "Pulumi.staging-us.yaml")
+	ps, err := LoadProjectStack(stackPath)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	layers, err := proj.StackConfigLayers(projPath, ps, stackPath)
+	if !assert.NoError(t, err) {
+		return
+	}
+	var names []string
+	for _, l := range layers {
+		names = append(names, l.Name)
+	}
+	assert.Equal(t, []string{
+		"Pulumi.yaml (configschema)",
+		"Pulumi.yaml (configvalues)",
+		"environments/shared.yaml",
+		"environments/staging.yaml",
+		"Pulumi.staging-us.yaml",
+	}, names)
+
+	cfg := layers.Merge()
+	assert.Equal(t, config.Map{
+		config.MustMakeKey("proj", "region"): config.NewValue("us-east-1"),
+		config.MustMakeKey("proj", "size"):   config.NewValue("medium"),
+		config.MustMakeKey("proj", "team"):   config.NewValue("platform"),
+		config.MustMakeKey("proj", "tier"):   config.NewValue("premium"),
+	}, cfg)
+	source, _ := layers.Source(config.MustMakeKey("proj", "tier"))
+	assert.Equal(t, "environments/staging.yaml", source)
+
+	// Environments may not include themselves, be missing, or contain secrets.
+	write("environments/shared.yaml", "environments: [staging]\n")
+	_, err = proj.StackConfigLayers(projPath, ps, stackPath)
+	assert.Error(t, err)
+
+	_, err = proj.StackConfigLayers(projPath, &ProjectStack{Environments: []string{"missing"}}, stackPath)
+	assert.Error(t, err)
+
+	write("environments/shared.yaml", "config:\n  proj:password:\n    secure: abc\n")
+	_, err = proj.StackConfigLayers(projPath, ps, stackPath)
+	assert.Error(t, err)
+}
+
+func TestProjectStackPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stack-paths")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name string) {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+		assert.NoError(t, ioutil.WriteFile(path, []byte("config: {}\n"), 0600))
+	}
+	projPath := filepath.Join(dir, "Pulumi.yaml")
+
+	stackPath := func(proj *Project, name tokens.QName) string {
+		path, err := ProjectStackPath(proj, projPath, name)
+		assert.NoError(t, err)
+		return path
+	}
+
+	// Without a config directory, stack files are next to the project file.
+	proj := &Project{Name: "proj"}
+	assert.Equal(t, filepath.Join(dir, "Pulumi.dev.yaml"), stackPath(proj, "dev"))
+
+	// New stack files go at the top of the config directory, but existing ones may be nested beneath it.
+	proj.Config = "config"
+	write("config/us-west/Pulumi.prod.yaml")
+	write("config/.hidden/Pulumi.test.yaml")
+	write("config/us-east/Pulumi.qa.yaml")
+	write("config/eu-west/Pulumi.qa.yaml")
+	assert.Equal(t, filepath.Join(dir, "config", "Pulumi.dev.yaml"), stackPath(proj, "dev"))
+	assert.Equal(t, filepath.Join(dir, "config", "us-west", "Pulumi.prod.yaml"), stackPath(proj, "prod"))
+	assert.Equal(t, filepath.Join(dir, "config", "Pulumi.test.yaml"), stackPath(proj, "test"))
+
+	// A stack whose file is in more than one directory is ambiguous.
+	_, err = ProjectStackPath(proj, projPath, "qa")
+	assert.Error(t, err)
+
+	write("config/Pulumi.prod.yaml")
+	assert.Equal(t, filepath.Join(dir, "config", "Pulumi.prod.yaml"), stackPath(proj, "prod"))
+
+	// The config directory is only walked once for each load of the project.
+	write("config/us-west/Pulumi.staging.yaml")
+	assert.Equal(t, filepath.Join(dir, "config", "Pulumi.staging.yaml"), stackPath(proj, "staging"))
+	proj = &Project{Name: "proj", Config: "config"}
+	assert.Equal(t, filepath.Join(dir, "config", "us-west", "Pulumi.staging.yaml"), stackPath(proj, "staging"))
+
+	// Stack files from before the project named its config directory are still found.
+	write("Pulumi.old.yaml")
+	assert.Equal(t, filepath.Join(dir, "Pulumi.old.yaml"), stackPath(proj, "old"))
+
+	// The config directory must be inside the project.
+	proj.Runtime = NewProjectRuntimeInfo("nodejs", nil)
+	assert.NoError(t, proj.Validate())
+	proj.Config = "../config"
+	assert.Error(t, proj.Validate())
+	proj.Config = dir
+	assert.Error(t, proj.Validate())
+}
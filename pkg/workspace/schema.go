@@ -0,0 +1,105 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+// SchemaFileName is the name of the package schema file that may be installed alongside a resource plugin.
+const SchemaFileName = "schema.json"
+
+// PackageSchema is the subset of a package schema that the CLI and engine understand.
+type PackageSchema struct {
+	Name      string                    `json:"name"`
+	Resources map[string]ResourceSchema `json:"resources,omitempty"`
+}
+
+// ResourceSchema describes a resource type's input and output properties.
+type ResourceSchema struct {
+	Description        string                    `json:"description,omitempty"`
+	DeprecationMessage string                    `json:"deprecationMessage,omitempty"`
+	InputProperties    map[string]PropertySchema `json:"inputProperties,omitempty"`
+	RequiredInputs     []string                  `json:"requiredInputs,omitempty"`
+	Properties         map[string]PropertySchema `json:"properties,omitempty"`
+	Required           []string                  `json:"required,omitempty"`
+}
+
+// PropertySchema describes a single property of a resource type.
+type PropertySchema struct {
+	Type                 string          `json:"type,omitempty"`
+	Ref                  string          `json:"$ref,omitempty"`
+	Items                *PropertySchema `json:"items,omitempty"`
+	AdditionalProperties *PropertySchema `json:"additionalProperties,omitempty"`
+	Description          string          `json:"description,omitempty"`
+	DeprecationMessage   string          `json:"deprecationMessage,omitempty"`
+}
+
+// TypeString returns a short, human-readable rendering of the property's type.
+func (p PropertySchema) TypeString() string {
+	switch {
+	case p.Ref != "":
+		return p.Ref[strings.LastIndex(p.Ref, "/")+1:]
+	case p.Type == "array" && p.Items != nil:
+		return "[]" + p.Items.TypeString()
+	case p.Type == "object" && p.AdditionalProperties != nil:
+		return "map[string]" + p.AdditionalProperties.TypeString()
+	case p.Type != "":
+		return p.Type
+	default:
+		return "any"
+	}
+}
+
+// LoadPackageSchema reads a package's schema from the given file.
+func LoadPackageSchema(pkg tokens.Package, path string) (*PackageSchema, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading package schema")
+	}
+	var schema PackageSchema
+	if err = json.Unmarshal(b, &schema); err != nil {
+		return nil, errors.Wrapf(err, "parsing package schema %s", path)
+	}
+	if schema.Name != "" && schema.Name != string(pkg) {
+		return nil, errors.Errorf("the schema in %s is for package %s, not %s", path, schema.Name, pkg)
+	}
+	return &schema, nil
+}
+
+// LoadInstalledPackageSchema reads the schema installed alongside the latest version of a package's resource plugin.
+// It returns nil if the plugin or its schema is not installed.
+func LoadInstalledPackageSchema(pkg tokens.Package) (*PackageSchema, error) {
+	_, pluginPath, err := GetPluginPath(ResourcePlugin, string(pkg), nil)
+	if err != nil {
+		return nil, err
+	}
+	if pluginPath == "" {
+		return nil, nil
+	}
+	path := filepath.Join(filepath.Dir(pluginPath), SchemaFileName)
+	if _, err = os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return LoadPackageSchema(pkg, path)
+}
@@ -0,0 +1,299 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/fsutil"
+)
+
+// TemplateRegistryFile is the name of the file, in the template directory, that records the templates that have been
+// added to it from other sources, alongside the Pulumi templates.
+const TemplateRegistryFile = ".registry.json"
+
+// RegisteredTemplate is a template that was added to the template directory from a URL or path, so that it can be
+// used by name, and without network access, like the Pulumi templates.
+type RegisteredTemplate struct {
+	// Name is the name of the template, which is also the name of its directory within the template directory.
+	Name string `json:"name"`
+	// Source is the URL or absolute path that the template was added from, and is updated from.
+	Source string `json:"source"`
+	// Updated is when the template was last copied from its source.
+	Updated time.Time `json:"updated"`
+}
+
+// registeredTemplateNameRegexp matches the names that templates may be added under. Templates are retrieved by
+// lower-case name, so names must be lower case.
+var registeredTemplateNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// GetRegisteredTemplates returns the templates that have been added to the template directory, sorted by name.
+func GetRegisteredTemplates() ([]RegisteredTemplate, error) {
+	templateDir, err := GetTemplateDir()
+	if err != nil {
+		return nil, err
+	}
+	registryFile := filepath.Join(templateDir, TemplateRegistryFile)
+	unlock, err := lockSettings(registryFile)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return readTemplateRegistry(registryFile)
+}
+
+// AddTemplate copies the template at the given URL or path into the template directory under the given name, and
+// records where it came from so that it can be updated later. The source must contain exactly one template.
+func AddTemplate(name, source string) (RegisteredTemplate, error) {
+	if !registeredTemplateNameRegexp.MatchString(name) {
+		return RegisteredTemplate{}, errors.Errorf("'%s' is not a valid template name; names may only contain "+
+			"lower-case letters, digits, periods, hyphens, and underscores", name)
+	}
+	if !IsTemplateURL(source) {
+		abs, err := filepath.Abs(source)
+		if err != nil {
+			return RegisteredTemplate{}, err
+		}
+		source = abs
+	}
+
+	templateDir, err := GetTemplateDir()
+	if err != nil {
+		return RegisteredTemplate{}, err
+	}
+	registryFile := filepath.Join(templateDir, TemplateRegistryFile)
+	unlock, err := lockSettings(registryFile)
+	if err != nil {
+		return RegisteredTemplate{}, err
+	}
+	defer unlock()
+
+	registry, err := readTemplateRegistry(registryFile)
+	if err != nil {
+		return RegisteredTemplate{}, err
+	}
+	for _, t := range registry {
+		if t.Name == name {
+			return RegisteredTemplate{}, errors.Errorf("a template named '%s' has already been added from %s",
+				name, t.Source)
+		}
+	}
+	if _, err = os.Stat(filepath.Join(templateDir, name)); err == nil {
+		return RegisteredTemplate{}, errors.Errorf("a template named '%s' already exists", name)
+	}
+
+	added := RegisteredTemplate{Name: name, Source: source}
+	if err = fetchRegisteredTemplate(templateDir, &added); err != nil {
+		return RegisteredTemplate{}, err
+	}
+	if err = writeTemplateRegistry(registryFile, append(registry, added)); err != nil {
+		return RegisteredTemplate{}, err
+	}
+	return added, nil
+}
+
+// RemoveTemplate removes the added template with the given name from the template directory. The Pulumi templates
+// cannot be removed.
+func RemoveTemplate(name string) error {
+	templateDir, err := GetTemplateDir()
+	if err != nil {
+		return err
+	}
+	registryFile := filepath.Join(templateDir, TemplateRegistryFile)
+	unlock, err := lockSettings(registryFile)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	registry, err := readTemplateRegistry(registryFile)
+	if err != nil {
+		return err
+	}
+	var remaining []RegisteredTemplate
+	for _, t := range registry {
+		if t.Name != name {
+			remaining = append(remaining, t)
+		}
+	}
+	if len(remaining) == len(registry) {
+		return errors.Errorf("no template named '%s' has been added", name)
+	}
+
+	if err = os.RemoveAll(filepath.Join(templateDir, name)); err != nil {
+		return err
+	}
+	return writeTemplateRegistry(registryFile, remaining)
+}
+
+// UpdateTemplates copies the added templates with the given names, or all of them if no names are given, from their
+// sources again, and returns them.
+func UpdateTemplates(names []string) ([]RegisteredTemplate, error) {
+	templateDir, err := GetTemplateDir()
+	if err != nil {
+		return nil, err
+	}
+	registryFile := filepath.Join(templateDir, TemplateRegistryFile)
+	unlock, err := lockSettings(registryFile)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	registry, err := readTemplateRegistry(registryFile)
+	if err != nil {
+		return nil, err
+	}
+	added := make(map[string]bool)
+	for _, t := range registry {
+		added[t.Name] = true
+	}
+	selected := make(map[string]bool)
+	for _, name := range names {
+		if !added[name] {
+			return nil, errors.Errorf("no template named '%s' has been added", name)
+		}
+		selected[name] = true
+	}
+
+	var updated []RegisteredTemplate
+	for i := range registry {
+		t := &registry[i]
+		if len(names) != 0 && !selected[t.Name] {
+			continue
+		}
+		if err = fetchRegisteredTemplate(templateDir, t); err != nil {
+			return nil, errors.Wrapf(err, "updating template '%s'", t.Name)
+		}
+		updated = append(updated, *t)
+	}
+
+	if err = writeTemplateRegistry(registryFile, registry); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// UpdatePulumiTemplates updates the Pulumi templates in the template directory from their Git repository.
+func UpdatePulumiTemplates() error {
+	_, err := retrievePulumiTemplates("", false /*offline*/)
+	return err
+}
+
+// findRegisteredTemplate returns the added template with the given source, if there is one.
+func findRegisteredTemplate(templateDir, source string) (RegisteredTemplate, bool, error) {
+	registryFile := filepath.Join(templateDir, TemplateRegistryFile)
+	unlock, err := lockSettings(registryFile)
+	if err != nil {
+		return RegisteredTemplate{}, false, err
+	}
+	defer unlock()
+
+	registry, err := readTemplateRegistry(registryFile)
+	if err != nil {
+		return RegisteredTemplate{}, false, err
+	}
+	for _, t := range registry {
+		if t.Source == source {
+			return t, true, nil
+		}
+	}
+	return RegisteredTemplate{}, false, nil
+}
+
+// fetchRegisteredTemplate copies the given template from its source into its directory within the template directory,
+// replacing whatever was there, and records the time at which it did so.
+func fetchRegisteredTemplate(templateDir string, t *RegisteredTemplate) error {
+	var repo TemplateRepository
+	var err error
+	if IsTemplateURL(t.Source) {
+		if repo, err = retrieveURLTemplates(t.Source, false /*offline*/); err != nil {
+			return err
+		}
+	} else {
+		repo = TemplateRepository{Root: t.Source, SubDirectory: t.Source}
+	}
+	defer func() {
+		contract.IgnoreError(repo.Delete())
+	}()
+
+	templates, err := repo.Templates()
+	if err != nil {
+		return err
+	}
+	if len(templates) != 1 {
+		return errors.Errorf("%s contains %d templates; add one of them by its own path or URL",
+			t.Source, len(templates))
+	}
+
+	// Copy the template next to its final location first, so that a failed copy leaves any earlier copy intact. Hidden
+	// directories are not listed as templates.
+	if err = os.MkdirAll(templateDir, 0700); err != nil {
+		return err
+	}
+	staging, err := ioutil.TempDir(templateDir, "."+t.Name+"-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		contract.IgnoreError(os.RemoveAll(staging))
+	}()
+	if err = fsutil.CopyFile(staging, templates[0].Dir, map[string]bool{GitDir: true}); err != nil {
+		return err
+	}
+
+	dest := filepath.Join(templateDir, t.Name)
+	if err = os.RemoveAll(dest); err != nil {
+		return err
+	}
+	if err = os.Rename(staging, dest); err != nil {
+		return err
+	}
+	t.Updated = time.Now()
+	return nil
+}
+
+func readTemplateRegistry(registryFile string) ([]RegisteredTemplate, error) {
+	b, err := ioutil.ReadFile(registryFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var registry []RegisteredTemplate
+	if err = json.Unmarshal(b, &registry); err != nil {
+		return nil, errors.Wrapf(err, "reading template registry from %s", registryFile)
+	}
+	sort.Slice(registry, func(i, j int) bool { return registry[i].Name < registry[j].Name })
+	return registry, nil
+}
+
+func writeTemplateRegistry(registryFile string, registry []RegisteredTemplate) error {
+	b, err := json.MarshalIndent(registry, "", "    ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(registryFile, b, 0600)
+}
@@ -38,6 +38,8 @@ const (
 
 	pulumiTemplateGitRepository = "https://github.com/pulumi/templates.git"
 
+	pulumiPolicyTemplateGitRepository = "https://github.com/pulumi/templates-policy.git"
+
 	// This file will be ignored when copying from the template cache to
 	// a project directory.
 	legacyPulumiTemplateManifestFile = ".pulumi.template.yaml"
@@ -45,6 +47,9 @@ const (
 	// pulumiLocalTemplatePathEnvVar is a path to the folder where templates are stored.
 	// It is used in sandboxed environments where the classic template folder may not be writable.
 	pulumiLocalTemplatePathEnvVar = "PULUMI_TEMPLATE_PATH"
+
+	// pulumiLocalPolicyTemplatePathEnvVar is a path to the folder where policy pack templates are stored.
+	pulumiLocalPolicyTemplatePathEnvVar = "PULUMI_POLICY_TEMPLATE_PATH"
 )
 
 // TemplateRepository represents a repository of templates.
@@ -96,8 +101,8 @@ func (repo TemplateRepository) Templates() ([]Template, error) {
 		if info.IsDir() {
 			name := info.Name()
 
-			// Ignore the .git directory.
-			if name == GitDir {
+			// Ignore the .git directory, and other hidden directories such as those that added templates are staged in.
+			if strings.HasPrefix(name, ".") {
 				continue
 			}
 
@@ -119,17 +124,22 @@ type Template struct {
 	Description string                                // Description of the template.
 	Quickstart  string                                // Optional text to be displayed after template creation.
 	Config      map[string]ProjectTemplateConfigValue // Optional template config.
+	Parameters  []ProjectTemplateParameter            // Optional parameters to substitute into the files.
 
 	ProjectName        string // Name of the project.
 	ProjectDescription string // Optional description of the project.
 }
 
-// cleanupLegacyTemplateDir deletes an existing ~/.pulumi/templates directory if it isn't a git repository.
+// cleanupLegacyTemplateDir deletes an existing ~/.pulumi/templates directory if it isn't a git repository, unless
+// templates have been added to it, in which case it is not a legacy directory.
 func cleanupLegacyTemplateDir() error {
 	templateDir, err := GetTemplateDir()
 	if err != nil {
 		return err
 	}
+	if _, err = os.Stat(filepath.Join(templateDir, TemplateRegistryFile)); err == nil {
+		return nil
+	}
 
 	// See if the template directory is a Git repository.
 	if _, err = git.PlainOpen(templateDir); err != nil {
@@ -167,10 +177,44 @@ func RetrieveTemplates(templateNamePathOrURL string, offline bool) (TemplateRepo
 	return retrievePulumiTemplates(templateNamePathOrURL, offline)
 }
 
+// RetrievePolicyTemplates retrieves a "template repository" of policy pack templates based on the specified name,
+// path, or URL.
+func RetrievePolicyTemplates(templateNamePathOrURL string, offline bool) (TemplateRepository, error) {
+	if IsTemplateURL(templateNamePathOrURL) {
+		return retrieveURLTemplates(templateNamePathOrURL, offline)
+	}
+	if isTemplateFileOrDirectory(templateNamePathOrURL) {
+		return retrieveFileTemplates(templateNamePathOrURL)
+	}
+
+	templateDir, err := GetPolicyTemplateDir()
+	if err != nil {
+		return TemplateRepository{}, err
+	}
+	return retrieveGitTemplates(pulumiPolicyTemplateGitRepository, templateDir, templateNamePathOrURL, offline)
+}
+
 // retrieveURLTemplates retrieves the "template repository" at the specified URL.
 func retrieveURLTemplates(rawurl string, offline bool) (TemplateRepository, error) {
 	if offline {
-		return TemplateRepository{}, errors.Errorf("cannot use %s offline", rawurl)
+		// A template that was added from this URL can be used from the template directory instead.
+		templateDir, err := GetTemplateDir()
+		if err != nil {
+			return TemplateRepository{}, err
+		}
+		added, ok, err := findRegisteredTemplate(templateDir, rawurl)
+		if err != nil {
+			return TemplateRepository{}, err
+		}
+		if !ok {
+			return TemplateRepository{}, errors.Errorf("cannot use %s offline; add it with `pulumi template add` "+
+				"while online to use it offline later", rawurl)
+		}
+		return TemplateRepository{
+			Root:         templateDir,
+			SubDirectory: filepath.Join(templateDir, added.Name),
+			ShouldDelete: false,
+		}, nil
 	}
 
 	var err error
@@ -206,8 +250,6 @@ func retrieveFileTemplates(path string) (TemplateRepository, error) {
 // Instead of retrieving to a temporary directory, the Pulumi templates are managed from
 // ~/.pulumi/templates.
 func retrievePulumiTemplates(templateName string, offline bool) (TemplateRepository, error) {
-	templateName = strings.ToLower(templateName)
-
 	// Cleanup the template directory.
 	if err := cleanupLegacyTemplateDir(); err != nil {
 		return TemplateRepository{}, err
@@ -219,15 +261,28 @@ func retrievePulumiTemplates(templateName string, offline bool) (TemplateReposit
 		return TemplateRepository{}, err
 	}
 
+	return retrieveGitTemplates(pulumiTemplateGitRepository, templateDir, templateName, offline)
+}
+
+// retrieveGitTemplates retrieves the "template repository" for the given Git repository, which is cloned into (or
+// updated in) templateDir.
+func retrieveGitTemplates(repoURL, templateDir, templateName string, offline bool) (TemplateRepository, error) {
+	templateName = strings.ToLower(templateName)
+
 	// Ensure the template directory exists.
 	if err := os.MkdirAll(templateDir, 0700); err != nil {
 		return TemplateRepository{}, err
 	}
 
 	if !offline {
-		// Clone or update the pulumi/templates repo.
-		err := gitutil.GitCloneOrPull(pulumiTemplateGitRepository, plumbing.HEAD, templateDir, false /*shallow*/)
+		// Clone or update the templates repo. The directory may already hold added templates, in which case a failed
+		// clone leaves an empty repository behind; remove it so that the next attempt clones afresh.
+		_, statErr := os.Stat(filepath.Join(templateDir, GitDir))
+		err := gitutil.GitCloneOrPull(repoURL, plumbing.HEAD, templateDir, false /*shallow*/)
 		if err != nil {
+			if os.IsNotExist(statErr) {
+				contract.IgnoreError(os.RemoveAll(filepath.Join(templateDir, GitDir)))
+			}
 			return TemplateRepository{}, err
 		}
 	}
@@ -314,6 +369,7 @@ func LoadTemplate(path string) (Template, error) {
 		template.Description = proj.Template.Description
 		template.Quickstart = proj.Template.Quickstart
 		template.Config = proj.Template.Config
+		template.Parameters = proj.Template.Parameters
 	}
 	if proj.Description != nil {
 		template.ProjectDescription = *proj.Description
@@ -341,9 +397,10 @@ func (template Template) CopyTemplateFilesDryRun(destDir string) error {
 	return nil
 }
 
-// CopyTemplateFiles does the actual copy operation to a destination directory.
+// CopyTemplateFiles does the actual copy operation to a destination directory. The values of the template's
+// parameters, keyed by name, are substituted into the copied files.
 func (template Template) CopyTemplateFiles(
-	destDir string, force bool, projectName string, projectDescription string) error {
+	destDir string, force bool, projectName string, projectDescription string, parameters map[string]string) error {
 
 	return walkFiles(template.Dir, destDir, func(info os.FileInfo, source string, dest string) error {
 		if info.IsDir() {
@@ -360,7 +417,7 @@ func (template Template) CopyTemplateFiles(
 		// Transform only if it isn't a binary file.
 		result := b
 		if !isBinary(b) {
-			transformed := transform(string(b), projectName, projectDescription)
+			transformed := transform(string(b), projectName, projectDescription, parameters)
 			result = []byte(transformed)
 		}
 
@@ -393,6 +450,20 @@ func GetTemplateDir() (string, error) {
 	return dir, nil
 }
 
+// GetPolicyTemplateDir returns the directory in which policy pack templates on the current machine are stored.
+func GetPolicyTemplateDir() (string, error) {
+	// Allow the folder we use to store policy pack templates to be overridden.
+	if dir := os.Getenv(pulumiLocalPolicyTemplatePathEnvVar); dir != "" {
+		return dir, nil
+	}
+
+	u, err := user.Current()
+	if u == nil || err != nil {
+		return "", errors.Wrap(err, "getting user home directory")
+	}
+	return filepath.Join(u.HomeDir, BookkeepingDir, PolicyTemplateDir), nil
+}
+
 // We are moving towards a world where these restrictions will be enforced by all our backends. When we get there,
 // we can consider removing this code in favor of exported functions in the backend package. For now, these are more
 // restrictive that what the backend enforces, but we want to "stop the bleeding" for new projects created via
@@ -625,14 +696,24 @@ func newTemplateNotFoundError(templateDir string, templateName string) error {
 }
 
 // transform returns a new string with ${PROJECT} and ${DESCRIPTION} replaced by
-// the value of projectName and projectDescription.
-func transform(content string, projectName string, projectDescription string) string {
+// the value of projectName and projectDescription, and ${NAME} replaced by the
+// value of each of the given parameters.
+func transform(content string, projectName string, projectDescription string, parameters map[string]string) string {
 	// On Windows, we need to replace \n with \r\n because go-git does not currently handle it.
 	if runtime.GOOS == "windows" {
 		content = strings.Replace(content, "\n", "\r\n", -1)
 	}
 	content = strings.Replace(content, "${PROJECT}", projectName, -1)
 	content = strings.Replace(content, "${DESCRIPTION}", projectDescription, -1)
+
+	// Substitute all of the parameters in one pass, so that values are never substituted into each other.
+	if len(parameters) > 0 {
+		var oldnew []string
+		for name, value := range parameters {
+			oldnew = append(oldnew, "${"+name+"}", value)
+		}
+		content = strings.NewReplacer(oldnew...).Replace(content)
+	}
 	return content
 }
 
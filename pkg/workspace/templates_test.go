@@ -15,8 +15,10 @@
 package workspace
 
 import (
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -148,3 +150,81 @@ func TestRetrieveFileTemplate(t *testing.T) {
 	assert.Equal(t, ".", repository.Root)
 	assert.Equal(t, ".", repository.SubDirectory)
 }
+
+func TestTemplateRegistry(t *testing.T) {
+	templateDir, err := ioutil.TempDir("", "templates")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(templateDir)
+	sourceDir, err := ioutil.TempDir("", "template-source")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(sourceDir)
+
+	oldTemplatePath := os.Getenv(pulumiLocalTemplatePathEnvVar)
+	defer os.Setenv(pulumiLocalTemplatePathEnvVar, oldTemplatePath)
+	assert.NoError(t, os.Setenv(pulumiLocalTemplatePathEnvVar, templateDir))
+
+	writeProject := func(description string) {
+		proj := "name: ${PROJECT}\nruntime: nodejs\ntemplate:\n  description: " + description + "\n"
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(sourceDir, "Pulumi.yaml"), []byte(proj), 0600))
+	}
+	writeProject("first")
+
+	_, err = AddTemplate("Not Valid", sourceDir)
+	assert.Error(t, err)
+
+	added, err := AddTemplate("mine", sourceDir)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, sourceDir, added.Source)
+	_, err = AddTemplate("mine", sourceDir)
+	assert.Error(t, err)
+
+	// The added template can be retrieved by name offline, and is listed alongside any other templates.
+	repo, err := RetrieveTemplates("mine", true /*offline*/)
+	if !assert.NoError(t, err) {
+		return
+	}
+	templates, err := repo.Templates()
+	if assert.NoError(t, err) && assert.Len(t, templates, 1) {
+		assert.Equal(t, "first", templates[0].Description)
+	}
+
+	writeProject("second")
+	updated, err := UpdateTemplates(nil)
+	assert.NoError(t, err)
+	assert.Len(t, updated, 1)
+	templates, err = repo.Templates()
+	if assert.NoError(t, err) && assert.Len(t, templates, 1) {
+		assert.Equal(t, "second", templates[0].Description)
+	}
+	_, err = UpdateTemplates([]string{"missing"})
+	assert.Error(t, err)
+
+	registered, err := GetRegisteredTemplates()
+	assert.NoError(t, err)
+	assert.Len(t, registered, 1)
+
+	assert.NoError(t, RemoveTemplate("mine"))
+	assert.Error(t, RemoveTemplate("mine"))
+	_, err = os.Stat(filepath.Join(templateDir, "mine"))
+	assert.True(t, os.IsNotExist(err))
+	registered, err = GetRegisteredTemplates()
+	assert.NoError(t, err)
+	assert.Empty(t, registered)
+}
+
+func TestTransformParameters(t *testing.T) {
+	content := "name: ${PROJECT}\nbucket: ${BUCKET}\nsize: ${SIZE}\nother: ${OTHER}\n"
+	transformed := transform(content, "proj", "desc", map[string]string{
+		"BUCKET": "${SIZE}",
+		"SIZE":   "large",
+	})
+	// Values are not substituted into each other, and unknown names are left alone.
+	assert.Equal(t, "name: proj\nbucket: ${SIZE}\nsize: large\nother: ${OTHER}\n",
+		strings.Replace(transformed, "\r\n", "\n", -1))
+}
@@ -0,0 +1,121 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/fsutil"
+)
+
+// maxTimingSamples bounds the number of samples an operation's expected duration averages over, so that the estimate
+// follows changes in how long operations take.
+const maxTimingSamples = 20
+
+// OperationTiming is the expected duration of an operation on a resource type.
+type OperationTiming struct {
+	// Samples is the number of operations the average is taken over, up to maxTimingSamples.
+	Samples int `json:"samples"`
+	// Seconds is the average duration of the operation.
+	Seconds float64 `json:"seconds"`
+}
+
+// OperationTimings records how long operations on each resource type have taken, so that the duration of future
+// updates can be estimated. It is safe for concurrent use.
+type OperationTimings struct {
+	lock       sync.Mutex
+	path       string
+	Operations map[string]OperationTiming `json:"operations"` // keyed by type and operation.
+}
+
+func operationTimingKey(t tokens.Type, op string) string {
+	return string(t) + " " + op
+}
+
+// Record adds the duration of an operation on a resource type to the history.
+func (t *OperationTimings) Record(typ tokens.Type, op string, d time.Duration) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.Operations == nil {
+		t.Operations = make(map[string]OperationTiming)
+	}
+	key := operationTimingKey(typ, op)
+	timing := t.Operations[key]
+	if timing.Samples < maxTimingSamples {
+		timing.Samples++
+	}
+	timing.Seconds += (d.Seconds() - timing.Seconds) / float64(timing.Samples)
+	t.Operations[key] = timing
+}
+
+// Expected returns the expected duration of an operation on a resource type, and false if it has never been recorded.
+func (t *OperationTimings) Expected(typ tokens.Type, op string) (time.Duration, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	timing, has := t.Operations[operationTimingKey(typ, op)]
+	if !has {
+		return 0, false
+	}
+	return time.Duration(timing.Seconds * float64(time.Second)), true
+}
+
+// Save writes the history back to the file it was loaded from.
+func (t *OperationTimings) Save() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	b, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(t.path), 0700); err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(t.path, b, 0600)
+}
+
+// GetOperationTimings loads the history of how long resource operations have taken from the given file, or from
+// ~/.pulumi/timings.json if the path is empty. A missing file is an empty history.
+func GetOperationTimings(path string) (*OperationTimings, error) {
+	if path == "" {
+		p, err := GetTimingsPath()
+		if err != nil {
+			return nil, err
+		}
+		path = p
+	}
+
+	timings := &OperationTimings{path: path}
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return timings, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(b, timings); err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", path)
+	}
+	return timings, nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/util/fsutil"
+)
+
+// UpdateSummary summarizes an operation on a stack -- an update, preview, refresh, or destroy -- so that it can be
+// shown again later without asking the stack's backend.
+type UpdateSummary struct {
+	// Stack is the name of the stack.
+	Stack string `json:"stack"`
+	// Kind is the kind of operation, such as "update" or "refresh".
+	Kind string `json:"kind"`
+	// DryRun is true if the operation only previewed its changes.
+	DryRun bool `json:"dryRun,omitempty"`
+	// StartTime and EndTime are when the operation started and finished.
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	// Succeeded is true if the operation succeeded.
+	Succeeded bool `json:"succeeded"`
+	// ResourceChanges counts the resources that the operation changed, by the kind of change.
+	ResourceChanges map[string]int `json:"resourceChanges,omitempty"`
+	// Errors are the errors that the operation reported, if it failed.
+	Errors []string `json:"errors,omitempty"`
+	// Permalink is the link to the operation's persisted result, if its backend has one.
+	Permalink string `json:"permalink,omitempty"`
+}
+
+func (pw *projectWorkspace) LastUpdate(stack string) (*UpdateSummary, error) {
+	updates, err := loadUpdateSummaries(pw.updatesPath())
+	if err != nil {
+		return nil, err
+	}
+	return findUpdateSummary(updates, stack), nil
+}
+
+func (pw *projectWorkspace) SaveLastUpdate(summary *UpdateSummary) error {
+	return saveUpdateSummary(pw.updatesPath(), summary)
+}
+
+// findUpdateSummary returns the summary of the last operation on the given stack. A stack may be named by the full
+// name it was recorded under, or, if that is not ambiguous, by the last component of that name.
+func findUpdateSummary(updates map[string]*UpdateSummary, stack string) *UpdateSummary {
+	if summary, ok := updates[stack]; ok {
+		return summary
+	}
+
+	var found *UpdateSummary
+	for name, summary := range updates {
+		if strings.HasSuffix(name, "/"+stack) {
+			if found != nil {
+				return nil
+			}
+			found = summary
+		}
+	}
+	return found
+}
+
+// loadUpdateSummaries reads the summaries in the given file, by stack. A missing file has no summaries.
+func loadUpdateSummaries(updatesFile string) (map[string]*UpdateSummary, error) {
+	unlock, err := lockSettings(updatesFile)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return readUpdateSummaries(updatesFile)
+}
+
+func readUpdateSummaries(updatesFile string) (map[string]*UpdateSummary, error) {
+	b, err := ioutil.ReadFile(updatesFile)
+	if os.IsNotExist(err) {
+		return map[string]*UpdateSummary{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var updates map[string]*UpdateSummary
+	if err = json.Unmarshal(b, &updates); err != nil {
+		return nil, errors.Wrapf(err, "reading update summaries from %s", updatesFile)
+	}
+	if updates == nil {
+		updates = map[string]*UpdateSummary{}
+	}
+	return updates, nil
+}
+
+// saveUpdateSummary records the given summary in the given file, replacing any earlier summary of the same stack.
+func saveUpdateSummary(updatesFile string, summary *UpdateSummary) error {
+	unlock, err := lockSettings(updatesFile)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// A file that cannot be read only holds summaries of earlier operations, so it is simply replaced.
+	updates, err := readUpdateSummaries(updatesFile)
+	if err != nil {
+		updates = map[string]*UpdateSummary{}
+	}
+	updates[summary.Stack] = summary
+
+	b, err := json.MarshalIndent(updates, "", "    ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(updatesFile, b, 0600)
+}
@@ -27,14 +27,21 @@ import (
 	"sync"
 
 	"github.com/pkg/errors"
+
 	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/fsutil"
+	"github.com/pulumi/pulumi/pkg/util/i18n"
+	"github.com/pulumi/pulumi/pkg/util/logging"
 )
 
 // W offers functionality for interacting with Pulumi workspaces.
 type W interface {
 	Settings() *Settings // returns a mutable pointer to the optional workspace settings info.
 	Save() error         // saves any modifications to the workspace.
+
+	LastUpdate(stack string) (*UpdateSummary, error) // returns the summary of the last operation on a stack, if any.
+	SaveLastUpdate(summary *UpdateSummary) error     // records the summary of the last operation on a stack.
 }
 
 type projectWorkspace struct {
@@ -89,7 +96,7 @@ func NewFrom(dir string) (W, error) {
 	if err != nil {
 		return nil, err
 	} else if path == "" {
-		return nil, errors.Errorf("no Pulumi.yaml project file found (searching upwards from %s). If you have not "+
+		return nil, i18n.Errorf("no Pulumi.yaml project file found (searching upwards from %s). If you have not "+
 			"created a project yet, use `pulumi new` to do so", dir)
 	}
 
@@ -117,53 +124,120 @@ func (pw *projectWorkspace) Settings() *Settings {
 }
 
 func (pw *projectWorkspace) Save() error {
-	settingsFile := pw.settingsPath()
+	return saveSettings(pw.settingsPath(), pw.settings)
+}
+
+func (pw *projectWorkspace) readSettings() error {
+	settings, err := loadSettings(pw.settingsPath())
+	if err != nil {
+		return err
+	}
+
+	pw.settings = settings
+	return nil
+}
+
+// settingsBackupSuffix is appended to a settings file's path to name the copy of its last good contents.
+const settingsBackupSuffix = ".bak"
+
+var settingsLocks = make(map[string]*fsutil.FileMutex)
+var settingsLocksMutex sync.Mutex
+
+// lockSettings acquires the advisory lock that serializes access to the settings files in the given settings file's
+// directory, both within this process and across concurrent CLI invocations. The returned function releases it.
+func lockSettings(settingsFile string) (func(), error) {
+	dir := filepath.Dir(settingsFile)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	lockFile := filepath.Join(dir, ".lock")
+	settingsLocksMutex.Lock()
+	mutex, ok := settingsLocks[lockFile]
+	if !ok {
+		mutex = fsutil.NewFileMutex(lockFile)
+		settingsLocks[lockFile] = mutex
+	}
+	settingsLocksMutex.Unlock()
+
+	if err := mutex.Lock(); err != nil {
+		return nil, errors.Wrapf(err, "locking workspace settings in %s", dir)
+	}
+	return func() {
+		contract.IgnoreError(mutex.Unlock())
+	}, nil
+}
+
+// saveSettings atomically writes the given settings to settingsFile while holding the settings lock. The file's
+// previous contents, if they were valid, are kept as a backup to recover from should the file later be corrupted.
+func saveSettings(settingsFile string, settings *Settings) error {
+	unlock, err := lockSettings(settingsFile)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	// If the settings file is empty, don't write an new one, and delete the old one if present. Since we put workspaces
 	// under ~/.pulumi/workspaces, cleaning them out when possible prevents us from littering a bunch of files in the
 	// home directory.
-	if pw.settings.IsEmpty() {
-		err := os.Remove(settingsFile)
-		if err != nil && !os.IsNotExist(err) {
-			return err
+	if settings.IsEmpty() {
+		for _, file := range []string{settingsFile, settingsFile + settingsBackupSuffix} {
+			if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+				return err
+			}
 		}
 		return nil
 	}
 
-	err := os.MkdirAll(filepath.Dir(settingsFile), 0700)
+	b, err := json.MarshalIndent(settings, "", "    ")
 	if err != nil {
 		return err
 	}
 
-	b, err := json.MarshalIndent(pw.settings, "", "    ")
-	if err != nil {
-		return err
+	// Roll the current contents over into the backup, but only if they are worth recovering.
+	if old, err := ioutil.ReadFile(settingsFile); err == nil && json.Valid(old) {
+		if err = fsutil.WriteFileAtomic(settingsFile+settingsBackupSuffix, old, 0600); err != nil {
+			return err
+		}
 	}
 
-	return ioutil.WriteFile(settingsFile, b, 0600)
+	return fsutil.WriteFileAtomic(settingsFile, b, 0600)
 }
 
-func (pw *projectWorkspace) readSettings() error {
-	settingsPath := pw.settingsPath()
+// loadSettings reads the settings in settingsFile while holding the settings lock. A missing file yields empty
+// settings. If the file cannot be parsed, the settings are recovered from its backup, if there is a valid one.
+func loadSettings(settingsFile string) (*Settings, error) {
+	unlock, err := lockSettings(settingsFile)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
-	b, err := ioutil.ReadFile(settingsPath)
+	b, err := ioutil.ReadFile(settingsFile)
 	if err != nil && os.IsNotExist(err) {
 		// not an error to not have an existing settings file.
-		pw.settings = &Settings{}
-		return nil
+		return &Settings{}, nil
 	} else if err != nil {
-		return err
+		return nil, err
 	}
 
 	var settings Settings
-
 	err = json.Unmarshal(b, &settings)
-	if err != nil {
-		return err
+	if err == nil {
+		return &settings, nil
 	}
 
-	pw.settings = &settings
-	return nil
+	backup, backupErr := ioutil.ReadFile(settingsFile + settingsBackupSuffix)
+	if backupErr != nil || json.Unmarshal(backup, &settings) != nil {
+		return nil, errors.Wrapf(err, "workspace settings file %s is corrupt and could not be recovered; "+
+			"delete it to reset this project's workspace settings", settingsFile)
+	}
+
+	logging.Warningf("workspace settings file %s is corrupt (%v); restoring it from its backup", settingsFile, err)
+	if err = fsutil.WriteFileAtomic(settingsFile, backup, 0600); err != nil {
+		return nil, err
+	}
+	return &settings, nil
 }
 
 func (pw *projectWorkspace) settingsPath() string {
@@ -174,6 +248,11 @@ func (pw *projectWorkspace) settingsPath() string {
 	return filepath.Join(user.HomeDir, BookkeepingDir, WorkspaceDir, uniqueFileName)
 }
 
+func (pw *projectWorkspace) updatesPath() string {
+	settingsPath := pw.settingsPath()
+	return strings.TrimSuffix(settingsPath, WorkspaceFile) + UpdatesFile
+}
+
 // sha1HexString returns a hex string of the sha1 hash of value.
 func sha1HexString(value string) string {
 	// nolint: gosec
@@ -0,0 +1,133 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSettingsRecovery(t *testing.T) {
+	dir, err := ioutil.TempDir("", "workspace")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "workspaces", "proj-workspace.json")
+
+	// A missing file loads as empty settings.
+	settings, err := loadSettings(path)
+	assert.NoError(t, err)
+	assert.True(t, settings.IsEmpty())
+
+	// The second save keeps the first as the backup.
+	assert.NoError(t, saveSettings(path, &Settings{Stack: "dev"}))
+	assert.NoError(t, saveSettings(path, &Settings{Stack: "prod"}))
+	settings, err = loadSettings(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "prod", settings.Stack)
+
+	// A corrupt file is restored from the backup.
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{"stack": "pr`), 0600))
+	settings, err = loadSettings(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "dev", settings.Stack)
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"dev"`)
+
+	// A corrupt backup is never rolled over a good one.
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`garbage`), 0600))
+	assert.NoError(t, saveSettings(path, &Settings{Stack: "test"}))
+	b, err = ioutil.ReadFile(path + settingsBackupSuffix)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"dev"`)
+
+	// Without a valid backup, corruption is an error.
+	assert.NoError(t, os.Remove(path+settingsBackupSuffix))
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`garbage`), 0600))
+	_, err = loadSettings(path)
+	assert.Error(t, err)
+
+	// Emptying the settings removes both files.
+	assert.NoError(t, saveSettings(path, &Settings{}))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSettingsConcurrentSaves(t *testing.T) {
+	dir, err := ioutil.TempDir("", "workspace")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "proj-workspace.json")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, saveSettings(path, &Settings{Stack: fmt.Sprintf("stack-%d", i)}))
+			_, err := loadSettings(path)
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	settings, err := loadSettings(path)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, settings.Stack)
+}
+
+func TestUpdateSummaries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "workspace")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "workspaces", "proj-updates.json")
+
+	updates, err := loadUpdateSummaries(path)
+	assert.NoError(t, err)
+	assert.Empty(t, updates)
+
+	// Each stack's latest summary replaces its earlier one.
+	assert.NoError(t, saveUpdateSummary(path, &UpdateSummary{Stack: "org/dev", Kind: "update"}))
+	assert.NoError(t, saveUpdateSummary(path, &UpdateSummary{Stack: "org/prod", Kind: "update"}))
+	assert.NoError(t, saveUpdateSummary(path, &UpdateSummary{Stack: "org/dev", Kind: "refresh", Errors: []string{"x"}}))
+	updates, err = loadUpdateSummaries(path)
+	assert.NoError(t, err)
+	assert.Len(t, updates, 2)
+
+	// Stacks may be found by their short names, unless that is ambiguous.
+	if summary := findUpdateSummary(updates, "dev"); assert.NotNil(t, summary) {
+		assert.Equal(t, "refresh", summary.Kind)
+		assert.Equal(t, []string{"x"}, summary.Errors)
+	}
+	assert.NotNil(t, findUpdateSummary(updates, "org/prod"))
+	assert.Nil(t, findUpdateSummary(updates, "test"))
+	updates["other/dev"] = &UpdateSummary{Stack: "other/dev"}
+	assert.Nil(t, findUpdateSummary(updates, "dev"))
+
+	// A corrupt file is replaced by the next summary.
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`garbage`), 0600))
+	_, err = loadUpdateSummaries(path)
+	assert.Error(t, err)
+	assert.NoError(t, saveUpdateSummary(path, &UpdateSummary{Stack: "dev"}))
+	updates, err = loadUpdateSummaries(path)
+	assert.NoError(t, err)
+	assert.Len(t, updates, 1)
+}
@@ -52,8 +52,8 @@ func main() {
 	}
 	engineAddress := args[0]
 
-	// Fire up a gRPC server, letting the kernel choose a free port.
-	port, done, err := rpcutil.Serve(0, nil, []func(*grpc.Server) error{
+	// Fire up a gRPC server, letting the kernel choose a free port unless the engine asks for a Unix socket.
+	port, done, err := rpcutil.ServePlugin([]func(*grpc.Server) error{
 		func(srv *grpc.Server) error {
 			host := newLanguageHost(engineAddress, tracing)
 			pulumirpc.RegisterLanguageRuntimeServer(srv, host)
@@ -0,0 +1,81 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// pulumi-resource-pulumi-go is the resource provider plugin for Go dynamic providers.  Since Go cannot serialize
+// closures, the provider logic lives in the program binary itself: this plugin locates that binary and re-launches
+// it in provider mode, passing through its arguments and standard streams so that the binary serves the provider
+// RPC interface to the engine directly.
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/workspace"
+	"github.com/pulumi/pulumi/sdk/go/pulumi/dynamic"
+)
+
+func main() {
+	// Plugins are launched in the project directory, so we can find the program by way of the project's name, just
+	// as the Go language host does.
+	proj, err := workspace.DetectProject()
+	if err != nil {
+		cmdutil.Exit(errors.Wrap(err, "could not locate the Pulumi project for the Go dynamic provider"))
+	}
+	program, err := findProgram(string(proj.Name))
+	if err != nil {
+		cmdutil.Exit(err)
+	}
+
+	logging.V(5).Infof("dynamic provider launching program: %s", program)
+
+	cmd := exec.Command(program, os.Args[1:]...)
+	cmd.Env = append(os.Environ(), dynamic.EnvProviderMode+"=true")
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		if exiterr, ok := err.(*exec.ExitError); ok {
+			if status, stok := exiterr.Sys().(syscall.WaitStatus); stok {
+				os.Exit(status.ExitStatus())
+			}
+		}
+		cmdutil.Exit(errors.Wrap(err, "could not run Go dynamic provider"))
+	}
+}
+
+// findProgram looks for the program binary using the same search order as the Go language host: the current
+// directory, then $GOPATH/bin, then the $PATH.
+func findProgram(program string) (string, error) {
+	if cwd, err := os.Getwd(); err == nil {
+		if info, err := os.Stat(filepath.Join(cwd, program)); err == nil && !info.IsDir() {
+			return filepath.Join(cwd, program), nil
+		}
+	}
+	if goPath := os.Getenv("GOPATH"); goPath != "" {
+		if info, err := os.Stat(filepath.Join(goPath, "bin", program)); err == nil && !info.IsDir() {
+			return filepath.Join(goPath, "bin", program), nil
+		}
+	}
+	if fullPath, err := exec.LookPath(program); err == nil {
+		return fullPath, nil
+	}
+	return "", errors.Errorf("unable to find program: %s", program)
+}
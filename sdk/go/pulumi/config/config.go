@@ -110,6 +110,12 @@ func (c *Config) GetUint64(key string) uint64 {
 	return GetUint64(c.ctx, c.fullKey(key))
 }
 
+// GetObject loads an optional structured configuration value by its key into output, or leaves output untouched if
+// it doesn't exist.
+func (c *Config) GetObject(key string, output interface{}) error {
+	return GetObject(c.ctx, c.fullKey(key), output)
+}
+
 // Require loads a configuration value by its key, or panics if it doesn't exist.
 func (c *Config) Require(key string) string {
 	return Require(c.ctx, c.fullKey(key))
@@ -180,6 +186,11 @@ func (c *Config) RequireUint64(key string) uint64 {
 	return RequireUint64(c.ctx, c.fullKey(key))
 }
 
+// RequireObject loads a structured configuration value by its key into output, or panics if it doesn't exist.
+func (c *Config) RequireObject(key string, output interface{}) {
+	RequireObject(c.ctx, c.fullKey(key), output)
+}
+
 // Try loads a configuration value by its key, returning a non-nil error if it doesn't exist.
 func (c *Config) Try(key string) (string, error) {
 	return Try(c.ctx, c.fullKey(key))
@@ -249,3 +260,8 @@ func (c *Config) TryUint32(key string) (uint32, error) {
 func (c *Config) TryUint64(key string) (uint64, error) {
 	return TryUint64(c.ctx, c.fullKey(key))
 }
+
+// TryObject loads a structured configuration value by its key into output, or returns an error if it doesn't exist.
+func (c *Config) TryObject(key string, output interface{}) error {
+	return TryObject(c.ctx, c.fullKey(key), output)
+}
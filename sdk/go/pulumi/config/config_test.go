@@ -31,6 +31,7 @@ func TestConfig(t *testing.T) {
 			"testpkg:bbb":    "true",
 			"testpkg:intint": "42",
 			"testpkg:fpfpfp": "99.963",
+			"testpkg:obj":    `{"subnets":["10.0.0.0/24"],"zones":2}`,
 		},
 	})
 	assert.Nil(t, err)
@@ -76,4 +77,20 @@ func TestConfig(t *testing.T) {
 	assert.Equal(t, 99.963, k4)
 	_, err = cfg.Try("missing")
 	assert.NotNil(t, err)
+
+	// Test the object getters, which decode structured values.
+	type vpc struct {
+		Subnets []string `json:"subnets"`
+		Zones   int      `json:"zones"`
+	}
+	var v1, v2, v3 vpc
+	assert.Nil(t, cfg.GetObject("obj", &v1))
+	assert.Equal(t, vpc{Subnets: []string{"10.0.0.0/24"}, Zones: 2}, v1)
+	assert.Nil(t, cfg.GetObject("missing", &v1))
+	cfg.RequireObject("obj", &v2)
+	assert.Equal(t, v1, v2)
+	assert.Nil(t, cfg.TryObject("obj", &v3))
+	assert.Equal(t, v1, v3)
+	assert.NotNil(t, cfg.TryObject("missing", &v3))
+	assert.NotNil(t, cfg.TryObject("sss", &v3))
 }
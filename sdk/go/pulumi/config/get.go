@@ -15,6 +15,8 @@
 package config
 
 import (
+	"encoding/json"
+
 	"github.com/spf13/cast"
 
 	"github.com/pulumi/pulumi/sdk/go/pulumi"
@@ -129,3 +131,12 @@ func GetUint64(ctx *pulumi.Context, key string) uint64 {
 	}
 	return 0
 }
+
+// GetObject loads an optional structured configuration value by its key, decoding its JSON representation into
+// output, or leaves output untouched if it doesn't exist. An error is returned if the value cannot be decoded.
+func GetObject(ctx *pulumi.Context, key string, output interface{}) error {
+	if v, ok := ctx.GetConfig(key); ok {
+		return json.Unmarshal([]byte(v), output)
+	}
+	return nil
+}
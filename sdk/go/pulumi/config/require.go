@@ -15,6 +15,8 @@
 package config
 
 import (
+	"encoding/json"
+
 	"github.com/spf13/cast"
 
 	"github.com/pulumi/pulumi/pkg/util/contract"
@@ -107,3 +109,10 @@ func RequireUint64(ctx *pulumi.Context, key string) uint64 {
 	v := Require(ctx, key)
 	return cast.ToUint64(v)
 }
+
+// RequireObject loads a structured configuration value by its key, decoding its JSON representation into output, or
+// panics if it doesn't exist or cannot be decoded.
+func RequireObject(ctx *pulumi.Context, key string, output interface{}) {
+	v := Require(ctx, key)
+	contract.AssertNoErrorf(json.Unmarshal([]byte(v), output), "configuration variable '%s' is not a valid object", key)
+}
@@ -15,6 +15,8 @@
 package config
 
 import (
+	"encoding/json"
+
 	"github.com/pkg/errors"
 	"github.com/spf13/cast"
 
@@ -147,3 +149,14 @@ func TryUint64(ctx *pulumi.Context, key string) (uint64, error) {
 	}
 	return cast.ToUint64(v), nil
 }
+
+// TryObject loads a structured configuration value by its key, decoding its JSON representation into output, or
+// returns an error if it doesn't exist or cannot be decoded.
+func TryObject(ctx *pulumi.Context, key string, output interface{}) error {
+	v, err := Try(ctx, key)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(json.Unmarshal([]byte(v), output),
+		"configuration variable '%s' is not a valid object", key)
+}
@@ -25,6 +25,7 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/util/rpcutil"
 	pulumirpc "github.com/pulumi/pulumi/sdk/proto/go"
 )
 
@@ -49,7 +50,7 @@ func NewContext(ctx context.Context, info RunInfo) (*Context, error) {
 	var monitorConn *grpc.ClientConn
 	var monitor pulumirpc.ResourceMonitorClient
 	if addr := info.MonitorAddr; addr != "" {
-		conn, err := grpc.Dial(info.MonitorAddr, grpc.WithInsecure())
+		conn, err := grpc.Dial(info.MonitorAddr, grpc.WithInsecure(), rpcutil.WithUnixSockets())
 		if err != nil {
 			return nil, errors.Wrap(err, "connecting to resource monitor over RPC")
 		}
@@ -60,7 +61,7 @@ func NewContext(ctx context.Context, info RunInfo) (*Context, error) {
 	var engineConn *grpc.ClientConn
 	var engine pulumirpc.EngineClient
 	if addr := info.EngineAddr; addr != "" {
-		conn, err := grpc.Dial(info.EngineAddr, grpc.WithInsecure())
+		conn, err := grpc.Dial(info.EngineAddr, grpc.WithInsecure(), rpcutil.WithUnixSockets())
 		if err != nil {
 			return nil, errors.Wrap(err, "connecting to engine over RPC")
 		}
@@ -295,6 +296,8 @@ func (ctx *Context) RegisterResource(
 			DeleteBeforeReplace:  inputs.deleteBeforeReplace,
 			ImportId:             inputs.importID,
 			CustomTimeouts:       inputs.customTimeouts,
+			RetainOnDelete:       inputs.retainOnDelete,
+			Locked:               inputs.locked,
 		})
 		if err != nil {
 			logging.V(9).Infof("RegisterResource(%s, %s): error: %v", t, name, err)
@@ -416,6 +419,8 @@ type resourceInputs struct {
 	deleteBeforeReplace bool
 	importID            string
 	customTimeouts      *pulumirpc.RegisterResourceRequest_CustomTimeouts
+	retainOnDelete      bool
+	locked              bool
 }
 
 // prepareResourceInputs prepares the inputs for a resource operation, shared between read and register.
@@ -428,6 +433,8 @@ func (ctx *Context) prepareResourceInputs(props map[string]interface{}, opts ...
 	}
 
 	timeouts := ctx.getTimeouts(opts...)
+	retainOnDelete := ctx.getRetainOnDelete(opts...)
+	locked := ctx.getLocked(opts...)
 
 	// Serialize all properties, first by awaiting them, and then marshaling them to the requisite gRPC values.
 	rpcProps, propertyDeps, rpcDeps, err := marshalInputs(props)
@@ -474,6 +481,8 @@ func (ctx *Context) prepareResourceInputs(props map[string]interface{}, opts ...
 		deleteBeforeReplace: deleteBeforeReplace,
 		importID:            string(importID),
 		customTimeouts:      timeouts,
+		retainOnDelete:      retainOnDelete,
+		locked:              locked,
 	}, nil
 }
 
@@ -496,6 +505,26 @@ func (ctx *Context) getTimeouts(opts ...ResourceOpt) *pulumirpc.RegisterResource
 	return &timeouts
 }
 
+// getRetainOnDelete returns true if any of the given options asks for the resource to be retained on deletion.
+func (ctx *Context) getRetainOnDelete(opts ...ResourceOpt) bool {
+	for _, opt := range opts {
+		if opt.RetainOnDelete {
+			return true
+		}
+	}
+	return false
+}
+
+// getLocked returns true if any of the given options asks for the resource to be locked.
+func (ctx *Context) getLocked(opts ...ResourceOpt) bool {
+	for _, opt := range opts {
+		if opt.Locked {
+			return true
+		}
+	}
+	return false
+}
+
 // getOpts returns a set of resource options from an array of them. This includes the parent URN, any dependency URNs,
 // a boolean indicating whether the resource is to be protected, and the URN and ID of the resource's provider, if any.
 func (ctx *Context) getOpts(opts ...ResourceOpt) (URN, []URN, bool, string, bool, ID, error) {
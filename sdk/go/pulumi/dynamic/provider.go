@@ -0,0 +1,138 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dynamic allows Go programs to define resource providers inline.
+//
+// A dynamic provider is registered by name with Register, and resources managed by it are created with NewResource.
+// The name of the provider is recorded in each resource's state, so that the provider logic can be located again in
+// subsequent updates. The provider itself is executed by the pulumi-go helper plugin, which re-launches the program
+// binary in "provider mode"; for this to work, the program's main must call dynamic.Run instead of pulumi.Run, and
+// providers must be registered before Run is called (for example, in an init function).
+package dynamic
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/sdk/go/pulumi"
+)
+
+const (
+	// ResourceType is the type token used for all resources managed by Go dynamic providers.
+	ResourceType = "pulumi-go:dynamic:Resource"
+
+	// EnvProviderMode is the envvar set by the pulumi-go helper plugin to ask a program to serve its dynamic providers.
+	EnvProviderMode = "PULUMI_GO_DYNAMIC_PROVIDER"
+
+	// providerKey is the property in which a resource's provider name is serialized.
+	providerKey = "__provider"
+)
+
+// CheckFailure indicates that a call to Check failed; it contains the property and reason for the failure.
+type CheckFailure struct {
+	Property string
+	Reason   string
+}
+
+// DiffResult describes the changes a hypothetical update would make to a resource.
+type DiffResult struct {
+	Changes             bool     // true if the update would change the resource.
+	Replaces            []string // the properties that, when changed, require the resource to be replaced.
+	DeleteBeforeReplace bool     // true if the old resource must be deleted before its replacement is created.
+}
+
+// ResourceProvider is the minimal set of operations a dynamic provider must implement.
+type ResourceProvider interface {
+	// Create allocates a new instance of a resource and returns its ID and output properties.
+	Create(inputs resource.PropertyMap) (string, resource.PropertyMap, error)
+	// Update updates an existing resource with new values and returns its new output properties.
+	Update(id string, olds, news resource.PropertyMap) (resource.PropertyMap, error)
+	// Delete tears down an existing resource.
+	Delete(id string, props resource.PropertyMap) error
+}
+
+// Checker may be implemented by providers that validate their inputs.  By default, inputs are accepted as-is.
+type Checker interface {
+	Check(olds, news resource.PropertyMap) (resource.PropertyMap, []CheckFailure, error)
+}
+
+// Differ may be implemented by providers that compute their own diffs.  The olds passed to Diff are the resource's
+// outputs from its last Create, Read or Update.  By default, any change to the resource's inputs results in an update.
+type Differ interface {
+	Diff(id string, olds, news resource.PropertyMap) (DiffResult, error)
+}
+
+// Reader may be implemented by providers that can read the live state of a resource.  By default, the recorded state
+// is returned unchanged.
+type Reader interface {
+	Read(id string, props resource.PropertyMap) (resource.PropertyMap, error)
+}
+
+var providers = make(map[string]ResourceProvider)
+var providersLock sync.RWMutex
+
+// Register makes the given provider available under the given name.  Providers must be registered before Run is
+// called, so that they are available when the program is launched in provider mode.
+func Register(name string, provider ResourceProvider) {
+	providersLock.Lock()
+	defer providersLock.Unlock()
+	if _, has := providers[name]; has {
+		panic(fmt.Sprintf("dynamic provider '%s' has already been registered", name))
+	}
+	providers[name] = provider
+}
+
+// getProvider returns the provider registered under the given name, if any.
+func getProvider(name string) (ResourceProvider, error) {
+	providersLock.RLock()
+	defer providersLock.RUnlock()
+	provider, has := providers[name]
+	if !has {
+		return nil, errors.Errorf("unknown dynamic provider '%s'; providers must be registered before calling Run", name)
+	}
+	return provider, nil
+}
+
+// NewResource registers a resource whose lifecycle is managed by the dynamic provider with the given name.
+func NewResource(ctx *pulumi.Context, name, provider string, props map[string]interface{},
+	opts ...pulumi.ResourceOpt) (*pulumi.ResourceState, error) {
+	if _, err := getProvider(provider); err != nil {
+		return nil, err
+	}
+
+	inputs := make(map[string]interface{}, len(props)+1)
+	for k, v := range props {
+		inputs[k] = v
+	}
+	inputs[providerKey] = provider
+	return ctx.RegisterResource(ResourceType, name, true, inputs, opts...)
+}
+
+// Run executes the body of a Pulumi program that uses dynamic providers.  If the program has been launched by the
+// pulumi-go helper plugin, this serves the registered providers instead of running the body.
+func Run(body pulumi.RunFunc) {
+	if os.Getenv(EnvProviderMode) == "" {
+		pulumi.Run(body)
+		return
+	}
+
+	if err := serve(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: dynamic provider failed: %v\n", err)
+		os.Exit(1)
+	}
+}
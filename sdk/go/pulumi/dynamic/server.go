@@ -0,0 +1,337 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dynamic
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	pbempty "github.com/golang/protobuf/ptypes/empty"
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/util/rpcutil"
+	"github.com/pulumi/pulumi/pkg/version"
+	pulumirpc "github.com/pulumi/pulumi/sdk/proto/go"
+)
+
+// serve fires up a gRPC server for the registered dynamic providers and blocks until it stops serving.
+func serve() error {
+	port, done, err := rpcutil.Serve(0, nil, []func(*grpc.Server) error{
+		func(srv *grpc.Server) error {
+			pulumirpc.RegisterResourceProviderServer(srv, &server{
+				strict: os.Getenv(plugin.StrictPreviewEnvVar) != "",
+			})
+			return nil
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "could not start dynamic provider RPC server")
+	}
+
+	// Print out the port so that the engine knows how to reach us.
+	fmt.Printf("%d\n", port)
+
+	if err := <-done; err != nil {
+		return errors.Wrap(err, "dynamic provider RPC stopped serving")
+	}
+	return nil
+}
+
+// server implements the ResourceProviderServer interface on top of the registered dynamic providers.  Each request
+// is dispatched to the provider whose name is serialized in the resource's properties.
+//
+// During a strict preview, only the providers' Read callbacks are run: inputs are accepted as-is, diffs are left to
+// the engine, and any mutating operation is refused.
+type server struct {
+	strict bool // true if the engine launched this provider for a strict preview.
+}
+
+// checkStrict returns an error if the given mutating operation was requested during a strict preview.
+func (s *server) checkStrict(op string) error {
+	if s.strict {
+		return errors.Errorf("dynamic providers may not %s resources during a strict preview", op)
+	}
+	return nil
+}
+
+func unmarshal(props *structpb.Struct) (resource.PropertyMap, error) {
+	return plugin.UnmarshalProperties(props, plugin.MarshalOptions{KeepUnknowns: true, KeepSecrets: true})
+}
+
+func marshal(props resource.PropertyMap) (*structpb.Struct, error) {
+	return plugin.MarshalProperties(props, plugin.MarshalOptions{KeepUnknowns: true, KeepSecrets: true})
+}
+
+// providerFor returns the provider named by the given property map, along with a copy of the map that omits the
+// provider reference.
+func providerFor(props resource.PropertyMap) (string, ResourceProvider, resource.PropertyMap, error) {
+	v, has := props[providerKey]
+	if !has || !v.IsString() {
+		return "", nil, nil, errors.New("resource is missing its dynamic provider reference")
+	}
+	name := v.StringValue()
+	provider, err := getProvider(name)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	rest := make(resource.PropertyMap, len(props))
+	for k, v := range props {
+		if k != providerKey {
+			rest[k] = v
+		}
+	}
+	return name, provider, rest, nil
+}
+
+// withProvider returns a copy of the given property map with the provider reference added back in.
+func withProvider(props resource.PropertyMap, name string) resource.PropertyMap {
+	result := make(resource.PropertyMap, len(props)+1)
+	for k, v := range props {
+		result[k] = v
+	}
+	result[providerKey] = resource.NewStringProperty(name)
+	return result
+}
+
+func (s *server) CheckConfig(ctx context.Context, req *pulumirpc.CheckRequest) (*pulumirpc.CheckResponse, error) {
+	return &pulumirpc.CheckResponse{Inputs: req.GetNews()}, nil
+}
+
+func (s *server) DiffConfig(ctx context.Context, req *pulumirpc.DiffRequest) (*pulumirpc.DiffResponse, error) {
+	return &pulumirpc.DiffResponse{Changes: pulumirpc.DiffResponse_DIFF_NONE}, nil
+}
+
+func (s *server) Configure(ctx context.Context,
+	req *pulumirpc.ConfigureRequest) (*pulumirpc.ConfigureResponse, error) {
+	return &pulumirpc.ConfigureResponse{AcceptSecrets: true}, nil
+}
+
+func (s *server) Invoke(ctx context.Context, req *pulumirpc.InvokeRequest) (*pulumirpc.InvokeResponse, error) {
+	return nil, errors.Errorf("unknown function '%s'; dynamic providers do not support invokes", req.GetTok())
+}
+
+func (s *server) Check(ctx context.Context, req *pulumirpc.CheckRequest) (*pulumirpc.CheckResponse, error) {
+	news, err := unmarshal(req.GetNews())
+	if err != nil {
+		return nil, err
+	}
+	name, provider, inputs, err := providerFor(news)
+	if err != nil {
+		return nil, err
+	}
+
+	checker, ok := provider.(Checker)
+	if !ok || s.strict {
+		return &pulumirpc.CheckResponse{Inputs: req.GetNews()}, nil
+	}
+
+	olds, err := unmarshal(req.GetOlds())
+	if err != nil {
+		return nil, err
+	}
+	delete(olds, providerKey)
+	checked, failures, err := checker.Check(olds, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	rpcInputs, err := marshal(withProvider(checked, name))
+	if err != nil {
+		return nil, err
+	}
+	var rpcFailures []*pulumirpc.CheckFailure
+	for _, f := range failures {
+		rpcFailures = append(rpcFailures, &pulumirpc.CheckFailure{Property: f.Property, Reason: f.Reason})
+	}
+	return &pulumirpc.CheckResponse{Inputs: rpcInputs, Failures: rpcFailures}, nil
+}
+
+func (s *server) Diff(ctx context.Context, req *pulumirpc.DiffRequest) (*pulumirpc.DiffResponse, error) {
+	olds, err := unmarshal(req.GetOlds())
+	if err != nil {
+		return nil, err
+	}
+	news, err := unmarshal(req.GetNews())
+	if err != nil {
+		return nil, err
+	}
+	oldName, _, oldProps, err := providerFor(olds)
+	if err != nil {
+		return nil, err
+	}
+	newName, provider, newProps, err := providerFor(news)
+	if err != nil {
+		return nil, err
+	}
+
+	// Switching providers always requires a replacement, since the new provider knows nothing of the old resource.
+	if oldName != newName {
+		return &pulumirpc.DiffResponse{
+			Changes:  pulumirpc.DiffResponse_DIFF_SOME,
+			Replaces: []string{providerKey},
+		}, nil
+	}
+
+	// Without a Differ, leave the comparison to the engine, which compares the resource's old inputs with its new
+	// ones. The olds sent to a provider are its outputs, which may contain fields that its inputs never do.
+	differ, ok := provider.(Differ)
+	if !ok || s.strict {
+		return &pulumirpc.DiffResponse{Changes: pulumirpc.DiffResponse_DIFF_UNKNOWN}, nil
+	}
+	diff, err := differ.Diff(req.GetId(), oldProps, newProps)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := pulumirpc.DiffResponse_DIFF_NONE
+	if diff.Changes || len(diff.Replaces) > 0 {
+		changes = pulumirpc.DiffResponse_DIFF_SOME
+	}
+	return &pulumirpc.DiffResponse{
+		Changes:             changes,
+		Replaces:            diff.Replaces,
+		DeleteBeforeReplace: diff.DeleteBeforeReplace,
+	}, nil
+}
+
+func (s *server) Create(ctx context.Context, req *pulumirpc.CreateRequest) (*pulumirpc.CreateResponse, error) {
+	if err := s.checkStrict("create"); err != nil {
+		return nil, err
+	}
+	props, err := unmarshal(req.GetProperties())
+	if err != nil {
+		return nil, err
+	}
+	name, provider, inputs, err := providerFor(props)
+	if err != nil {
+		return nil, err
+	}
+
+	id, outs, err := provider.Create(inputs)
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		return nil, errors.Errorf("dynamic provider '%s' returned an empty ID from Create", name)
+	}
+
+	// Always record the provider reference in the outputs, so that later operations can locate the provider.
+	rpcOuts, err := marshal(withProvider(outs, name))
+	if err != nil {
+		return nil, err
+	}
+	return &pulumirpc.CreateResponse{Id: id, Properties: rpcOuts}, nil
+}
+
+func (s *server) Read(ctx context.Context, req *pulumirpc.ReadRequest) (*pulumirpc.ReadResponse, error) {
+	props, err := unmarshal(req.GetProperties())
+	if err != nil {
+		return nil, err
+	}
+	name, provider, state, err := providerFor(props)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, ok := provider.(Reader)
+	if !ok {
+		return &pulumirpc.ReadResponse{Id: req.GetId(), Properties: req.GetProperties(), Inputs: req.GetInputs()}, nil
+	}
+
+	outs, err := reader.Read(req.GetId(), state)
+	if err != nil {
+		return nil, err
+	}
+	if outs == nil {
+		// The resource no longer exists.
+		return &pulumirpc.ReadResponse{}, nil
+	}
+	rpcOuts, err := marshal(withProvider(outs, name))
+	if err != nil {
+		return nil, err
+	}
+	return &pulumirpc.ReadResponse{Id: req.GetId(), Properties: rpcOuts, Inputs: req.GetInputs()}, nil
+}
+
+func (s *server) Update(ctx context.Context, req *pulumirpc.UpdateRequest) (*pulumirpc.UpdateResponse, error) {
+	if err := s.checkStrict("update"); err != nil {
+		return nil, err
+	}
+	olds, err := unmarshal(req.GetOlds())
+	if err != nil {
+		return nil, err
+	}
+	news, err := unmarshal(req.GetNews())
+	if err != nil {
+		return nil, err
+	}
+	_, _, oldProps, err := providerFor(olds)
+	if err != nil {
+		return nil, err
+	}
+	name, provider, newProps, err := providerFor(news)
+	if err != nil {
+		return nil, err
+	}
+
+	outs, err := provider.Update(req.GetId(), oldProps, newProps)
+	if err != nil {
+		return nil, err
+	}
+	rpcOuts, err := marshal(withProvider(outs, name))
+	if err != nil {
+		return nil, err
+	}
+	return &pulumirpc.UpdateResponse{Properties: rpcOuts}, nil
+}
+
+func (s *server) Delete(ctx context.Context, req *pulumirpc.DeleteRequest) (*pbempty.Empty, error) {
+	if err := s.checkStrict("delete"); err != nil {
+		return nil, err
+	}
+	props, err := unmarshal(req.GetProperties())
+	if err != nil {
+		return nil, err
+	}
+	_, provider, state, err := providerFor(props)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = provider.Delete(req.GetId(), state); err != nil {
+		return nil, err
+	}
+	return &pbempty.Empty{}, nil
+}
+
+func (s *server) GetLogs(ctx context.Context, req *pulumirpc.GetLogsRequest) (*pulumirpc.GetLogsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "dynamic providers do not support logs")
+}
+
+func (s *server) Cancel(ctx context.Context, req *pbempty.Empty) (*pbempty.Empty, error) {
+	return &pbempty.Empty{}, nil
+}
+
+func (s *server) GetPluginInfo(ctx context.Context, req *pbempty.Empty) (*pulumirpc.PluginInfo, error) {
+	return &pulumirpc.PluginInfo{Version: version.Version}, nil
+}
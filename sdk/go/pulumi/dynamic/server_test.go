@@ -0,0 +1,140 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dynamic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	pulumirpc "github.com/pulumi/pulumi/sdk/proto/go"
+)
+
+type testProvider struct {
+	deleted []string
+}
+
+func (p *testProvider) Create(inputs resource.PropertyMap) (string, resource.PropertyMap, error) {
+	outs := resource.PropertyMap{"length": resource.NewNumberProperty(float64(len(inputs["value"].StringValue())))}
+	return "id-" + inputs["value"].StringValue(), outs, nil
+}
+
+func (p *testProvider) Update(id string, olds, news resource.PropertyMap) (resource.PropertyMap, error) {
+	return news, nil
+}
+
+func (p *testProvider) Delete(id string, props resource.PropertyMap) error {
+	p.deleted = append(p.deleted, id)
+	return nil
+}
+
+func TestServerLifecycle(t *testing.T) {
+	provider := &testProvider{}
+	Register("test", provider)
+	s := &server{}
+
+	inputs, err := marshal(resource.PropertyMap{
+		providerKey: resource.NewStringProperty("test"),
+		"value":     resource.NewStringProperty("hello"),
+	})
+	assert.NoError(t, err)
+
+	// Create should record the provider reference alongside the provider's own outputs.
+	created, err := s.Create(context.Background(), &pulumirpc.CreateRequest{Properties: inputs})
+	assert.NoError(t, err)
+	assert.Equal(t, "id-hello", created.GetId())
+	outs, err := unmarshal(created.GetProperties())
+	assert.NoError(t, err)
+	assert.Equal(t, "test", outs[providerKey].StringValue())
+	assert.Equal(t, float64(5), outs["length"].NumberValue())
+
+	// Without a Differ, the diff is left to the engine, which compares old inputs with new ones. The outputs sent as
+	// the olds contain fields that the inputs do not, so comparing them with the inputs would always find a change.
+	diff, err := s.Diff(context.Background(), &pulumirpc.DiffRequest{
+		Id:   "id-hello",
+		Olds: created.GetProperties(),
+		News: inputs,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, pulumirpc.DiffResponse_DIFF_UNKNOWN, diff.GetChanges())
+	assert.Empty(t, diff.GetReplaces())
+
+	// Delete should find the provider through the serialized reference in the resource's outputs.
+	_, err = s.Delete(context.Background(), &pulumirpc.DeleteRequest{Id: "id-hello", Properties: created.GetProperties()})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"id-hello"}, provider.deleted)
+
+	// Unknown providers are reported as errors.
+	unknown, err := marshal(resource.PropertyMap{providerKey: resource.NewStringProperty("missing")})
+	assert.NoError(t, err)
+	_, err = s.Create(context.Background(), &pulumirpc.CreateRequest{Properties: unknown})
+	assert.Error(t, err)
+}
+
+type strictTestProvider struct {
+	testProvider
+	checked bool
+	diffed  bool
+}
+
+func (p *strictTestProvider) Check(olds, news resource.PropertyMap) (resource.PropertyMap, []CheckFailure, error) {
+	p.checked = true
+	return news, []CheckFailure{{Property: "value", Reason: "rejected"}}, nil
+}
+
+func (p *strictTestProvider) Diff(id string, olds, news resource.PropertyMap) (DiffResult, error) {
+	p.diffed = true
+	return DiffResult{Replaces: []string{"value"}}, nil
+}
+
+func TestServerStrictPreview(t *testing.T) {
+	provider := &strictTestProvider{}
+	Register("strict", provider)
+	s := &server{strict: true}
+
+	inputs, err := marshal(resource.PropertyMap{
+		providerKey: resource.NewStringProperty("strict"),
+		"value":     resource.NewStringProperty("hello"),
+	})
+	assert.NoError(t, err)
+	news, err := marshal(resource.PropertyMap{
+		providerKey: resource.NewStringProperty("strict"),
+		"value":     resource.NewStringProperty("world"),
+	})
+	assert.NoError(t, err)
+
+	// Check and Diff must not call into the provider; the inputs are accepted and the diff is left to the engine.
+	checked, err := s.Check(context.Background(), &pulumirpc.CheckRequest{Olds: inputs, News: news})
+	assert.NoError(t, err)
+	assert.Empty(t, checked.GetFailures())
+	assert.False(t, provider.checked)
+
+	diff, err := s.Diff(context.Background(), &pulumirpc.DiffRequest{Id: "id-hello", Olds: inputs, News: news})
+	assert.NoError(t, err)
+	assert.Equal(t, pulumirpc.DiffResponse_DIFF_UNKNOWN, diff.GetChanges())
+	assert.Empty(t, diff.GetReplaces())
+	assert.False(t, provider.diffed)
+
+	// Mutating operations are refused outright.
+	_, err = s.Create(context.Background(), &pulumirpc.CreateRequest{Properties: inputs})
+	assert.Error(t, err)
+	_, err = s.Update(context.Background(), &pulumirpc.UpdateRequest{Id: "id-hello", Olds: inputs, News: news})
+	assert.Error(t, err)
+	_, err = s.Delete(context.Background(), &pulumirpc.DeleteRequest{Id: "id-hello", Properties: inputs})
+	assert.Error(t, err)
+	assert.Empty(t, provider.deleted)
+}
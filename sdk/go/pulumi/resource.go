@@ -69,6 +69,12 @@ type ResourceOpt struct {
 	Import ID
 	// CustomTimeouts is an optional configuration block used for CRUD operations
 	CustomTimeouts *CustomTimeouts
+	// RetainOnDelete, when set to true, ensures that deleting this resource only removes it from the stack's state,
+	// leaving the cloud resource itself in place for whatever manages it next.
+	RetainOnDelete bool
+	// Locked, when set to true, locks this resource so that updates that would change, replace or delete it are
+	// refused. The lock stays in the stack's state until it is removed with `pulumi state unlock`.
+	Locked bool
 }
 
 // InvokeOpt contains optional settings that control an invoke's behavior.
@@ -32,6 +32,15 @@ const statusproto = require("../../proto/status_pb.js");
 
 const providerKey: string = "__provider";
 
+// During a strict preview, the engine forbids anything but reads. Since deserializing a dynamic provider runs
+// arbitrary user code, only readRPC loads the provider: check accepts its inputs as-is, diff leaves the comparison to
+// the engine, and create, update and delete are refused.
+const strictPreview: boolean = !!process.env.PULUMI_STRICT_PREVIEW;
+
+function strictPreviewError(op: string): Error {
+    return new Error(`dynamic providers may not ${op} resources during a strict preview`);
+}
+
 function getProvider(props: any): dynamic.ResourceProvider {
     // TODO[pulumi/pulumi#414]: investigate replacing requireFromString with eval
     return requireFromString(props[providerKey]).handler();
@@ -72,6 +81,11 @@ async function checkRPC(call: any, callback: any): Promise<void> {
 
         const olds = req.getOlds().toJavaScript();
         const news = req.getNews().toJavaScript();
+        if (strictPreview) {
+            resp.setInputs(req.getNews());
+            callback(undefined, resp);
+            return;
+        }
         const provider = getProvider(news[providerKey] === runtime.unknownValue ? olds : news);
 
         let inputs: any = news;
@@ -130,6 +144,10 @@ async function diffRPC(call: any, callback: any): Promise<void> {
         // implementation changed. This made iteration painful, especially if the dynamic resource was managing a
         // physical resource--in this case, the physical resource would be unnecessarily deleted and recreated each
         // time the provider was updated.
+        if (strictPreview) {
+            callback(undefined, resp);
+            return;
+        }
         const olds = req.getOlds().toJavaScript();
         const news = req.getNews().toJavaScript();
         const provider = getProvider(news[providerKey] === runtime.unknownValue ? olds : news);
@@ -167,6 +185,9 @@ function diffConfigRPC(call: any, callback: any): void {
 }
 
 async function createRPC(call: any, callback: any): Promise<void> {
+    if (strictPreview) {
+        return callback(strictPreviewError("create"), undefined);
+    }
     try {
         const req: any = call.request;
         const resp = new provproto.CreateResponse();
@@ -213,6 +234,9 @@ async function readRPC(call: any, callback: any): Promise<void> {
 }
 
 async function updateRPC(call: any, callback: any): Promise<void> {
+    if (strictPreview) {
+        return callback(strictPreviewError("update"), undefined);
+    }
     try {
         const req: any = call.request;
         const resp = new provproto.UpdateResponse();
@@ -236,6 +260,9 @@ async function updateRPC(call: any, callback: any): Promise<void> {
 }
 
 async function deleteRPC(call: any, callback: any): Promise<void> {
+    if (strictPreview) {
+        return callback(strictPreviewError("delete"), undefined);
+    }
     try {
         const req: any = call.request;
         const props: any = req.getProperties().toJavaScript();
@@ -20,6 +20,7 @@ goog.exportSymbol('proto.pulumirpc.AnalyzeResponse', null, global);
 goog.exportSymbol('proto.pulumirpc.AnalyzerInfo', null, global);
 goog.exportSymbol('proto.pulumirpc.EnforcementLevel', null, global);
 goog.exportSymbol('proto.pulumirpc.PolicyInfo', null, global);
+goog.exportSymbol('proto.pulumirpc.Remediation', null, global);
 
 /**
  * Generated by JsPbCodeGenerator.
@@ -229,7 +230,7 @@ if (goog.DEBUG && !COMPILED) {
  * @private {!Array<number>}
  * @const
  */
-proto.pulumirpc.AnalyzeResponse.repeatedFields_ = [2];
+proto.pulumirpc.AnalyzeResponse.repeatedFields_ = [2,3];
 
 
 
@@ -261,7 +262,9 @@ proto.pulumirpc.AnalyzeResponse.prototype.toObject = function(opt_includeInstanc
 proto.pulumirpc.AnalyzeResponse.toObject = function(includeInstance, msg) {
   var f, obj = {
     diagnosticsList: jspb.Message.toObjectList(msg.getDiagnosticsList(),
-    proto.pulumirpc.AnalyzeDiagnostic.toObject, includeInstance)
+    proto.pulumirpc.AnalyzeDiagnostic.toObject, includeInstance),
+    remediationsList: jspb.Message.toObjectList(msg.getRemediationsList(),
+    proto.pulumirpc.Remediation.toObject, includeInstance)
   };
 
   if (includeInstance) {
@@ -303,6 +306,11 @@ proto.pulumirpc.AnalyzeResponse.deserializeBinaryFromReader = function(msg, read
       reader.readMessage(value,proto.pulumirpc.AnalyzeDiagnostic.deserializeBinaryFromReader);
       msg.addDiagnostics(value);
       break;
+    case 3:
+      var value = new proto.pulumirpc.Remediation;
+      reader.readMessage(value,proto.pulumirpc.Remediation.deserializeBinaryFromReader);
+      msg.addRemediations(value);
+      break;
     default:
       reader.skipField();
       break;
@@ -340,6 +348,14 @@ proto.pulumirpc.AnalyzeResponse.serializeBinaryToWriter = function(message, writ
       proto.pulumirpc.AnalyzeDiagnostic.serializeBinaryToWriter
     );
   }
+  f = message.getRemediationsList();
+  if (f.length > 0) {
+    writer.writeRepeatedMessage(
+      3,
+      f,
+      proto.pulumirpc.Remediation.serializeBinaryToWriter
+    );
+  }
 };
 
 
@@ -374,6 +390,37 @@ proto.pulumirpc.AnalyzeResponse.prototype.clearDiagnosticsList = function() {
 };
 
 
+/**
+ * repeated Remediation remediations = 3;
+ * @return {!Array.<!proto.pulumirpc.Remediation>}
+ */
+proto.pulumirpc.AnalyzeResponse.prototype.getRemediationsList = function() {
+  return /** @type{!Array.<!proto.pulumirpc.Remediation>} */ (
+    jspb.Message.getRepeatedWrapperField(this, proto.pulumirpc.Remediation, 3));
+};
+
+
+/** @param {!Array.<!proto.pulumirpc.Remediation>} value */
+proto.pulumirpc.AnalyzeResponse.prototype.setRemediationsList = function(value) {
+  jspb.Message.setRepeatedWrapperField(this, 3, value);
+};
+
+
+/**
+ * @param {!proto.pulumirpc.Remediation=} opt_value
+ * @param {number=} opt_index
+ * @return {!proto.pulumirpc.Remediation}
+ */
+proto.pulumirpc.AnalyzeResponse.prototype.addRemediations = function(opt_value, opt_index) {
+  return jspb.Message.addToRepeatedWrapperField(this, 3, opt_value, proto.pulumirpc.Remediation, opt_index);
+};
+
+
+proto.pulumirpc.AnalyzeResponse.prototype.clearRemediationsList = function() {
+  this.setRemediationsList([]);
+};
+
+
 
 /**
  * Generated by JsPbCodeGenerator.
@@ -700,6 +747,273 @@ proto.pulumirpc.AnalyzeDiagnostic.prototype.setEnforcementlevel = function(value
 
 
 
+/**
+ * Generated by JsPbCodeGenerator.
+ * @param {Array=} opt_data Optional initial data array, typically from a
+ * server response, or constructed directly in Javascript. The array is used
+ * in place and becomes part of the constructed object. It is not cloned.
+ * If no data is provided, the constructed object will be empty, but still
+ * valid.
+ * @extends {jspb.Message}
+ * @constructor
+ */
+proto.pulumirpc.Remediation = function(opt_data) {
+  jspb.Message.initialize(this, opt_data, 0, -1, null, null);
+};
+goog.inherits(proto.pulumirpc.Remediation, jspb.Message);
+if (goog.DEBUG && !COMPILED) {
+  proto.pulumirpc.Remediation.displayName = 'proto.pulumirpc.Remediation';
+}
+
+
+if (jspb.Message.GENERATE_TO_OBJECT) {
+/**
+ * Creates an object representation of this proto suitable for use in Soy templates.
+ * Field names that are reserved in JavaScript and will be renamed to pb_name.
+ * To access a reserved field use, foo.pb_<name>, eg, foo.pb_default.
+ * For the list of reserved names please see:
+ *     com.google.apps.jspb.JsClassTemplate.JS_RESERVED_WORDS.
+ * @param {boolean=} opt_includeInstance Whether to include the JSPB instance
+ *     for transitional soy proto support: http://goto/soy-param-migration
+ * @return {!Object}
+ */
+proto.pulumirpc.Remediation.prototype.toObject = function(opt_includeInstance) {
+  return proto.pulumirpc.Remediation.toObject(opt_includeInstance, this);
+};
+
+
+/**
+ * Static version of the {@see toObject} method.
+ * @param {boolean|undefined} includeInstance Whether to include the JSPB
+ *     instance for transitional soy proto support:
+ *     http://goto/soy-param-migration
+ * @param {!proto.pulumirpc.Remediation} msg The msg instance to transform.
+ * @return {!Object}
+ * @suppress {unusedLocalVariables} f is only used for nested messages
+ */
+proto.pulumirpc.Remediation.toObject = function(includeInstance, msg) {
+  var f, obj = {
+    policyname: jspb.Message.getFieldWithDefault(msg, 1, ""),
+    policypackname: jspb.Message.getFieldWithDefault(msg, 2, ""),
+    policypackversion: jspb.Message.getFieldWithDefault(msg, 3, ""),
+    description: jspb.Message.getFieldWithDefault(msg, 4, ""),
+    properties: (f = msg.getProperties()) && google_protobuf_struct_pb.Struct.toObject(includeInstance, f)
+  };
+
+  if (includeInstance) {
+    obj.$jspbMessageInstance = msg;
+  }
+  return obj;
+};
+}
+
+
+/**
+ * Deserializes binary data (in protobuf wire format).
+ * @param {jspb.ByteSource} bytes The bytes to deserialize.
+ * @return {!proto.pulumirpc.Remediation}
+ */
+proto.pulumirpc.Remediation.deserializeBinary = function(bytes) {
+  var reader = new jspb.BinaryReader(bytes);
+  var msg = new proto.pulumirpc.Remediation;
+  return proto.pulumirpc.Remediation.deserializeBinaryFromReader(msg, reader);
+};
+
+
+/**
+ * Deserializes binary data (in protobuf wire format) from the
+ * given reader into the given message object.
+ * @param {!proto.pulumirpc.Remediation} msg The message object to deserialize into.
+ * @param {!jspb.BinaryReader} reader The BinaryReader to use.
+ * @return {!proto.pulumirpc.Remediation}
+ */
+proto.pulumirpc.Remediation.deserializeBinaryFromReader = function(msg, reader) {
+  while (reader.nextField()) {
+    if (reader.isEndGroup()) {
+      break;
+    }
+    var field = reader.getFieldNumber();
+    switch (field) {
+    case 1:
+      var value = /** @type {string} */ (reader.readString());
+      msg.setPolicyname(value);
+      break;
+    case 2:
+      var value = /** @type {string} */ (reader.readString());
+      msg.setPolicypackname(value);
+      break;
+    case 3:
+      var value = /** @type {string} */ (reader.readString());
+      msg.setPolicypackversion(value);
+      break;
+    case 4:
+      var value = /** @type {string} */ (reader.readString());
+      msg.setDescription(value);
+      break;
+    case 5:
+      var value = new google_protobuf_struct_pb.Struct;
+      reader.readMessage(value,google_protobuf_struct_pb.Struct.deserializeBinaryFromReader);
+      msg.setProperties(value);
+      break;
+    default:
+      reader.skipField();
+      break;
+    }
+  }
+  return msg;
+};
+
+
+/**
+ * Serializes the message to binary data (in protobuf wire format).
+ * @return {!Uint8Array}
+ */
+proto.pulumirpc.Remediation.prototype.serializeBinary = function() {
+  var writer = new jspb.BinaryWriter();
+  proto.pulumirpc.Remediation.serializeBinaryToWriter(this, writer);
+  return writer.getResultBuffer();
+};
+
+
+/**
+ * Serializes the given message to binary data (in protobuf wire
+ * format), writing to the given BinaryWriter.
+ * @param {!proto.pulumirpc.Remediation} message
+ * @param {!jspb.BinaryWriter} writer
+ * @suppress {unusedLocalVariables} f is only used for nested messages
+ */
+proto.pulumirpc.Remediation.serializeBinaryToWriter = function(message, writer) {
+  var f = undefined;
+  f = message.getPolicyname();
+  if (f.length > 0) {
+    writer.writeString(
+      1,
+      f
+    );
+  }
+  f = message.getPolicypackname();
+  if (f.length > 0) {
+    writer.writeString(
+      2,
+      f
+    );
+  }
+  f = message.getPolicypackversion();
+  if (f.length > 0) {
+    writer.writeString(
+      3,
+      f
+    );
+  }
+  f = message.getDescription();
+  if (f.length > 0) {
+    writer.writeString(
+      4,
+      f
+    );
+  }
+  f = message.getProperties();
+  if (f != null) {
+    writer.writeMessage(
+      5,
+      f,
+      google_protobuf_struct_pb.Struct.serializeBinaryToWriter
+    );
+  }
+};
+
+
+/**
+ * optional string policyName = 1;
+ * @return {string}
+ */
+proto.pulumirpc.Remediation.prototype.getPolicyname = function() {
+  return /** @type {string} */ (jspb.Message.getFieldWithDefault(this, 1, ""));
+};
+
+
+/** @param {string} value */
+proto.pulumirpc.Remediation.prototype.setPolicyname = function(value) {
+  jspb.Message.setProto3StringField(this, 1, value);
+};
+
+
+/**
+ * optional string policyPackName = 2;
+ * @return {string}
+ */
+proto.pulumirpc.Remediation.prototype.getPolicypackname = function() {
+  return /** @type {string} */ (jspb.Message.getFieldWithDefault(this, 2, ""));
+};
+
+
+/** @param {string} value */
+proto.pulumirpc.Remediation.prototype.setPolicypackname = function(value) {
+  jspb.Message.setProto3StringField(this, 2, value);
+};
+
+
+/**
+ * optional string policyPackVersion = 3;
+ * @return {string}
+ */
+proto.pulumirpc.Remediation.prototype.getPolicypackversion = function() {
+  return /** @type {string} */ (jspb.Message.getFieldWithDefault(this, 3, ""));
+};
+
+
+/** @param {string} value */
+proto.pulumirpc.Remediation.prototype.setPolicypackversion = function(value) {
+  jspb.Message.setProto3StringField(this, 3, value);
+};
+
+
+/**
+ * optional string description = 4;
+ * @return {string}
+ */
+proto.pulumirpc.Remediation.prototype.getDescription = function() {
+  return /** @type {string} */ (jspb.Message.getFieldWithDefault(this, 4, ""));
+};
+
+
+/** @param {string} value */
+proto.pulumirpc.Remediation.prototype.setDescription = function(value) {
+  jspb.Message.setProto3StringField(this, 4, value);
+};
+
+
+/**
+ * optional google.protobuf.Struct properties = 5;
+ * @return {?proto.google.protobuf.Struct}
+ */
+proto.pulumirpc.Remediation.prototype.getProperties = function() {
+  return /** @type{?proto.google.protobuf.Struct} */ (
+    jspb.Message.getWrapperField(this, google_protobuf_struct_pb.Struct, 5));
+};
+
+
+/** @param {?proto.google.protobuf.Struct|undefined} value */
+proto.pulumirpc.Remediation.prototype.setProperties = function(value) {
+  jspb.Message.setWrapperField(this, 5, value);
+};
+
+
+proto.pulumirpc.Remediation.prototype.clearProperties = function() {
+  this.setProperties(undefined);
+};
+
+
+/**
+ * Returns whether this field is set.
+ * @return {!boolean}
+ */
+proto.pulumirpc.Remediation.prototype.hasProperties = function() {
+  return jspb.Message.getField(this, 5) != null;
+};
+
+
+
 /**
  * Generated by JsPbCodeGenerator.
  * @param {Array=} opt_data Optional initial data array, typically from a
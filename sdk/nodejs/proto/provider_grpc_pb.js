@@ -132,6 +132,28 @@ function deserialize_pulumirpc_DiffResponse(buffer_arg) {
   return provider_pb.DiffResponse.deserializeBinary(new Uint8Array(buffer_arg));
 }
 
+function serialize_pulumirpc_GetLogsRequest(arg) {
+  if (!(arg instanceof provider_pb.GetLogsRequest)) {
+    throw new Error('Expected argument of type pulumirpc.GetLogsRequest');
+  }
+  return Buffer.from(arg.serializeBinary());
+}
+
+function deserialize_pulumirpc_GetLogsRequest(buffer_arg) {
+  return provider_pb.GetLogsRequest.deserializeBinary(new Uint8Array(buffer_arg));
+}
+
+function serialize_pulumirpc_GetLogsResponse(arg) {
+  if (!(arg instanceof provider_pb.GetLogsResponse)) {
+    throw new Error('Expected argument of type pulumirpc.GetLogsResponse');
+  }
+  return Buffer.from(arg.serializeBinary());
+}
+
+function deserialize_pulumirpc_GetLogsResponse(buffer_arg) {
+  return provider_pb.GetLogsResponse.deserializeBinary(new Uint8Array(buffer_arg));
+}
+
 function serialize_pulumirpc_InvokeRequest(arg) {
   if (!(arg instanceof provider_pb.InvokeRequest)) {
     throw new Error('Expected argument of type pulumirpc.InvokeRequest');
@@ -339,6 +361,19 @@ var ResourceProviderService = exports.ResourceProviderService = {
     responseSerialize: serialize_google_protobuf_Empty,
     responseDeserialize: deserialize_google_protobuf_Empty,
   },
+  // GetLogs returns the log entries written by the given resources, such as those of the functions or containers they
+  // run. Providers whose resources do not write logs need not implement it.
+  getLogs: {
+    path: '/pulumirpc.ResourceProvider/GetLogs',
+    requestStream: false,
+    responseStream: false,
+    requestType: provider_pb.GetLogsRequest,
+    responseType: provider_pb.GetLogsResponse,
+    requestSerialize: serialize_pulumirpc_GetLogsRequest,
+    requestDeserialize: deserialize_pulumirpc_GetLogsRequest,
+    responseSerialize: serialize_pulumirpc_GetLogsResponse,
+    responseDeserialize: deserialize_pulumirpc_GetLogsResponse,
+  },
   // Cancel signals the provider to abort all outstanding resource operations.
   cancel: {
     path: '/pulumirpc.ResourceProvider/Cancel',
@@ -1046,7 +1046,9 @@ proto.pulumirpc.RegisterResourceRequest.toObject = function(includeInstance, msg
     aliasesList: jspb.Message.getRepeatedField(msg, 15),
     importid: jspb.Message.getFieldWithDefault(msg, 16, ""),
     customtimeouts: (f = msg.getCustomtimeouts()) && proto.pulumirpc.RegisterResourceRequest.CustomTimeouts.toObject(includeInstance, f),
-    deletebeforereplacedefined: jspb.Message.getFieldWithDefault(msg, 18, false)
+    deletebeforereplacedefined: jspb.Message.getFieldWithDefault(msg, 18, false),
+    retainondelete: jspb.Message.getFieldWithDefault(msg, 19, false),
+    locked: jspb.Message.getFieldWithDefault(msg, 20, false)
   };
 
   if (includeInstance) {
@@ -1159,6 +1161,14 @@ proto.pulumirpc.RegisterResourceRequest.deserializeBinaryFromReader = function(m
       var value = /** @type {boolean} */ (reader.readBool());
       msg.setDeletebeforereplacedefined(value);
       break;
+    case 19:
+      var value = /** @type {boolean} */ (reader.readBool());
+      msg.setRetainondelete(value);
+      break;
+    case 20:
+      var value = /** @type {boolean} */ (reader.readBool());
+      msg.setLocked(value);
+      break;
     default:
       reader.skipField();
       break;
@@ -1313,6 +1323,20 @@ proto.pulumirpc.RegisterResourceRequest.serializeBinaryToWriter = function(messa
       f
     );
   }
+  f = message.getRetainondelete();
+  if (f) {
+    writer.writeBool(
+      19,
+      f
+    );
+  }
+  f = message.getLocked();
+  if (f) {
+    writer.writeBool(
+      20,
+      f
+    );
+  }
 };
 
 
@@ -2044,6 +2068,40 @@ proto.pulumirpc.RegisterResourceRequest.prototype.setDeletebeforereplacedefined
 };
 
 
+/**
+ * optional bool retainOnDelete = 19;
+ * Note that Boolean fields may be set to 0/1 when serialized from a Java server.
+ * You should avoid comparisons like {@code val === true/false} in those cases.
+ * @return {boolean}
+ */
+proto.pulumirpc.RegisterResourceRequest.prototype.getRetainondelete = function() {
+  return /** @type {boolean} */ (jspb.Message.getFieldWithDefault(this, 19, false));
+};
+
+
+/** @param {boolean} value */
+proto.pulumirpc.RegisterResourceRequest.prototype.setRetainondelete = function(value) {
+  jspb.Message.setProto3BooleanField(this, 19, value);
+};
+
+
+/**
+ * optional bool locked = 20;
+ * Note that Boolean fields may be set to 0/1 when serialized from a Java server.
+ * You should avoid comparisons like {@code val === true/false} in those cases.
+ * @return {boolean}
+ */
+proto.pulumirpc.RegisterResourceRequest.prototype.getLocked = function() {
+  return /** @type {boolean} */ (jspb.Message.getFieldWithDefault(this, 20, false));
+};
+
+
+/** @param {boolean} value */
+proto.pulumirpc.RegisterResourceRequest.prototype.setLocked = function(value) {
+  jspb.Message.setProto3BooleanField(this, 20, value);
+};
+
+
 
 /**
  * Generated by JsPbCodeGenerator.
@@ -466,6 +466,11 @@ export interface ResourceOptions {
      * An optional customTimeouts configuration block.
      */
     customTimeouts?: CustomTimeouts;
+    /**
+     * When set to true, retainOnDelete ensures that deleting this resource only removes it from the stack's state,
+     * leaving the cloud resource itself in place.
+     */
+    retainOnDelete?: boolean;
 
     // !!! IMPORTANT !!! If you add a new field to this type, make sure to add test that verifies
     // that mergeOptions works properly for it.
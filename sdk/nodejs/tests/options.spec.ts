@@ -53,6 +53,10 @@ describe("options", () => {
                 const result: any = mergeOptions({ id: Promise.resolve("a") }, { id: Promise.resolve("b") });
                 assert.strictEqual(await result.id.promise(), "b");
             }));
+            it("merges retainOnDelete like other scalars", asyncTest(async () => {
+                assert.strictEqual(mergeOptions({ retainOnDelete: true }, {}).retainOnDelete, true);
+                assert.strictEqual(mergeOptions({ retainOnDelete: true }, { retainOnDelete: false }).retainOnDelete, false);
+            }));
         });
 
         describe("array", () => {
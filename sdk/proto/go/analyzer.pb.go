@@ -46,7 +46,7 @@ func (x EnforcementLevel) String() string {
 	return proto.EnumName(EnforcementLevel_name, int32(x))
 }
 func (EnforcementLevel) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_analyzer_eb1faef40b250be3, []int{0}
+	return fileDescriptor_analyzer_73caa130c2404709, []int{0}
 }
 
 type AnalyzeRequest struct {
@@ -61,7 +61,7 @@ func (m *AnalyzeRequest) Reset()         { *m = AnalyzeRequest{} }
 func (m *AnalyzeRequest) String() string { return proto.CompactTextString(m) }
 func (*AnalyzeRequest) ProtoMessage()    {}
 func (*AnalyzeRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_analyzer_eb1faef40b250be3, []int{0}
+	return fileDescriptor_analyzer_73caa130c2404709, []int{0}
 }
 func (m *AnalyzeRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_AnalyzeRequest.Unmarshal(m, b)
@@ -97,6 +97,7 @@ func (m *AnalyzeRequest) GetProperties() *_struct.Struct {
 
 type AnalyzeResponse struct {
 	Diagnostics          []*AnalyzeDiagnostic `protobuf:"bytes,2,rep,name=diagnostics" json:"diagnostics,omitempty"`
+	Remediations         []*Remediation       `protobuf:"bytes,3,rep,name=remediations" json:"remediations,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
 	XXX_unrecognized     []byte               `json:"-"`
 	XXX_sizecache        int32                `json:"-"`
@@ -106,7 +107,7 @@ func (m *AnalyzeResponse) Reset()         { *m = AnalyzeResponse{} }
 func (m *AnalyzeResponse) String() string { return proto.CompactTextString(m) }
 func (*AnalyzeResponse) ProtoMessage()    {}
 func (*AnalyzeResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_analyzer_eb1faef40b250be3, []int{1}
+	return fileDescriptor_analyzer_73caa130c2404709, []int{1}
 }
 func (m *AnalyzeResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_AnalyzeResponse.Unmarshal(m, b)
@@ -133,6 +134,13 @@ func (m *AnalyzeResponse) GetDiagnostics() []*AnalyzeDiagnostic {
 	return nil
 }
 
+func (m *AnalyzeResponse) GetRemediations() []*Remediation {
+	if m != nil {
+		return m.Remediations
+	}
+	return nil
+}
+
 type AnalyzeDiagnostic struct {
 	PolicyName           string           `protobuf:"bytes,1,opt,name=policyName" json:"policyName,omitempty"`
 	PolicyPackName       string           `protobuf:"bytes,2,opt,name=policyPackName" json:"policyPackName,omitempty"`
@@ -150,7 +158,7 @@ func (m *AnalyzeDiagnostic) Reset()         { *m = AnalyzeDiagnostic{} }
 func (m *AnalyzeDiagnostic) String() string { return proto.CompactTextString(m) }
 func (*AnalyzeDiagnostic) ProtoMessage()    {}
 func (*AnalyzeDiagnostic) Descriptor() ([]byte, []int) {
-	return fileDescriptor_analyzer_eb1faef40b250be3, []int{2}
+	return fileDescriptor_analyzer_73caa130c2404709, []int{2}
 }
 func (m *AnalyzeDiagnostic) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_AnalyzeDiagnostic.Unmarshal(m, b)
@@ -219,6 +227,77 @@ func (m *AnalyzeDiagnostic) GetEnforcementLevel() EnforcementLevel {
 	return EnforcementLevel_ADVISORY
 }
 
+// Remediation is a modification of a resource's properties, made by a policy so that the resource complies with it.
+type Remediation struct {
+	PolicyName           string          `protobuf:"bytes,1,opt,name=policyName" json:"policyName,omitempty"`
+	PolicyPackName       string          `protobuf:"bytes,2,opt,name=policyPackName" json:"policyPackName,omitempty"`
+	PolicyPackVersion    string          `protobuf:"bytes,3,opt,name=policyPackVersion" json:"policyPackVersion,omitempty"`
+	Description          string          `protobuf:"bytes,4,opt,name=description" json:"description,omitempty"`
+	Properties           *_struct.Struct `protobuf:"bytes,5,opt,name=properties" json:"properties,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *Remediation) Reset()         { *m = Remediation{} }
+func (m *Remediation) String() string { return proto.CompactTextString(m) }
+func (*Remediation) ProtoMessage()    {}
+func (*Remediation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_analyzer_73caa130c2404709, []int{3}
+}
+func (m *Remediation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Remediation.Unmarshal(m, b)
+}
+func (m *Remediation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Remediation.Marshal(b, m, deterministic)
+}
+func (dst *Remediation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Remediation.Merge(dst, src)
+}
+func (m *Remediation) XXX_Size() int {
+	return xxx_messageInfo_Remediation.Size(m)
+}
+func (m *Remediation) XXX_DiscardUnknown() {
+	xxx_messageInfo_Remediation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Remediation proto.InternalMessageInfo
+
+func (m *Remediation) GetPolicyName() string {
+	if m != nil {
+		return m.PolicyName
+	}
+	return ""
+}
+
+func (m *Remediation) GetPolicyPackName() string {
+	if m != nil {
+		return m.PolicyPackName
+	}
+	return ""
+}
+
+func (m *Remediation) GetPolicyPackVersion() string {
+	if m != nil {
+		return m.PolicyPackVersion
+	}
+	return ""
+}
+
+func (m *Remediation) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+func (m *Remediation) GetProperties() *_struct.Struct {
+	if m != nil {
+		return m.Properties
+	}
+	return nil
+}
+
 // AnalyzerInfo provides metadata about a PolicyPack inside an analyzer.
 type AnalyzerInfo struct {
 	Name                 string        `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
@@ -233,7 +312,7 @@ func (m *AnalyzerInfo) Reset()         { *m = AnalyzerInfo{} }
 func (m *AnalyzerInfo) String() string { return proto.CompactTextString(m) }
 func (*AnalyzerInfo) ProtoMessage()    {}
 func (*AnalyzerInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_analyzer_eb1faef40b250be3, []int{3}
+	return fileDescriptor_analyzer_73caa130c2404709, []int{4}
 }
 func (m *AnalyzerInfo) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_AnalyzerInfo.Unmarshal(m, b)
@@ -290,7 +369,7 @@ func (m *PolicyInfo) Reset()         { *m = PolicyInfo{} }
 func (m *PolicyInfo) String() string { return proto.CompactTextString(m) }
 func (*PolicyInfo) ProtoMessage()    {}
 func (*PolicyInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_analyzer_eb1faef40b250be3, []int{4}
+	return fileDescriptor_analyzer_73caa130c2404709, []int{5}
 }
 func (m *PolicyInfo) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PolicyInfo.Unmarshal(m, b)
@@ -349,6 +428,7 @@ func init() {
 	proto.RegisterType((*AnalyzeRequest)(nil), "pulumirpc.AnalyzeRequest")
 	proto.RegisterType((*AnalyzeResponse)(nil), "pulumirpc.AnalyzeResponse")
 	proto.RegisterType((*AnalyzeDiagnostic)(nil), "pulumirpc.AnalyzeDiagnostic")
+	proto.RegisterType((*Remediation)(nil), "pulumirpc.Remediation")
 	proto.RegisterType((*AnalyzerInfo)(nil), "pulumirpc.AnalyzerInfo")
 	proto.RegisterType((*PolicyInfo)(nil), "pulumirpc.PolicyInfo")
 	proto.RegisterEnum("pulumirpc.EnforcementLevel", EnforcementLevel_name, EnforcementLevel_value)
@@ -498,40 +578,43 @@ var _Analyzer_serviceDesc = grpc.ServiceDesc{
 	Metadata: "analyzer.proto",
 }
 
-func init() { proto.RegisterFile("analyzer.proto", fileDescriptor_analyzer_eb1faef40b250be3) }
-
-var fileDescriptor_analyzer_eb1faef40b250be3 = []byte{
-	// 506 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x9c, 0x54, 0xc1, 0x6e, 0xd3, 0x40,
-	0x10, 0x8d, 0x93, 0xb4, 0x69, 0xa6, 0x69, 0x9a, 0xae, 0x04, 0x35, 0x6e, 0x85, 0x2a, 0x1f, 0x10,
-	0x42, 0xc8, 0x11, 0xe1, 0xc0, 0x0d, 0x11, 0x94, 0xaa, 0xaa, 0x04, 0x25, 0xb8, 0xa8, 0x12, 0x07,
-	0x0e, 0xae, 0x99, 0x5a, 0x16, 0xb6, 0x77, 0xd9, 0x5d, 0x83, 0xcc, 0xe7, 0xf0, 0x33, 0xfc, 0x01,
-	0xdf, 0xd3, 0xf5, 0xda, 0x71, 0x1c, 0xbb, 0xea, 0xa1, 0xb7, 0x9d, 0x79, 0x6f, 0xde, 0xce, 0xbe,
-	0xf1, 0x18, 0xc6, 0x5e, 0xe2, 0x45, 0xd9, 0x1f, 0xe4, 0x0e, 0xe3, 0x54, 0x52, 0x32, 0x64, 0x69,
-	0x94, 0xc6, 0x21, 0x67, 0xbe, 0x35, 0x62, 0x51, 0x1a, 0x84, 0x49, 0x01, 0x58, 0x47, 0x01, 0xa5,
-	0x41, 0x84, 0x53, 0x1d, 0x5d, 0xa7, 0x37, 0x53, 0x8c, 0x99, 0xcc, 0x4a, 0xf0, 0xb8, 0x09, 0x0a,
-	0xc9, 0x53, 0x5f, 0x16, 0xa8, 0xfd, 0x0d, 0xc6, 0xf3, 0xe2, 0x16, 0x17, 0x7f, 0xa6, 0x28, 0x24,
-	0x21, 0xd0, 0x97, 0x19, 0x43, 0xd3, 0x38, 0x31, 0x9e, 0x0f, 0x5d, 0x7d, 0x26, 0x6f, 0x00, 0x14,
-	0x9d, 0x21, 0x97, 0x21, 0x0a, 0xb3, 0xab, 0x90, 0xdd, 0xd9, 0xa1, 0x53, 0x08, 0x3b, 0x2b, 0x61,
-	0xe7, 0x52, 0x0b, 0xbb, 0x35, 0xaa, 0xfd, 0x19, 0xf6, 0x2b, 0x79, 0xc1, 0x68, 0x22, 0x90, 0xbc,
-	0x85, 0xdd, 0xef, 0xa1, 0x17, 0x24, 0x54, 0xc8, 0xd0, 0xcf, 0xc5, 0x7a, 0x4a, 0xec, 0xd8, 0xa9,
-	0xde, 0xe6, 0x94, 0x05, 0x8b, 0x8a, 0xe4, 0xd6, 0x0b, 0xec, 0xbf, 0x5d, 0x38, 0x68, 0x51, 0xc8,
-	0x53, 0xd5, 0x21, 0x8d, 0x42, 0x3f, 0xbb, 0xf0, 0xe2, 0x55, 0xef, 0xb5, 0x0c, 0x79, 0x06, 0xe3,
-	0x22, 0x5a, 0x7a, 0xfe, 0x0f, 0xcd, 0xe9, 0x6a, 0x4e, 0x23, 0x4b, 0x5e, 0xc2, 0xc1, 0x3a, 0x73,
-	0x85, 0x5c, 0x84, 0x34, 0x31, 0x7b, 0x9a, 0xda, 0x06, 0xc8, 0x89, 0x7a, 0x0b, 0x0a, 0x9f, 0x87,
-	0x4c, 0xe6, 0xbc, 0xbe, 0xe6, 0xd5, 0x53, 0xc4, 0x84, 0x41, 0x8c, 0x42, 0x78, 0x01, 0x9a, 0x5b,
-	0x1a, 0x5d, 0x85, 0xda, 0x67, 0x2f, 0x10, 0xe6, 0xb6, 0x32, 0x20, 0xf7, 0x59, 0x9d, 0xc9, 0x19,
-	0x4c, 0x30, 0xb9, 0xa1, 0xdc, 0xc7, 0x18, 0x13, 0xf9, 0x01, 0x7f, 0x61, 0x64, 0x0e, 0x54, 0xd9,
-	0x78, 0x76, 0x54, 0x33, 0xe8, 0xb4, 0x41, 0x71, 0x5b, 0x45, 0xf6, 0x6f, 0x18, 0x95, 0x1e, 0xf1,
-	0x73, 0x85, 0xe5, 0x97, 0x25, 0x6b, 0x63, 0xf4, 0x59, 0x37, 0x1f, 0x0a, 0x16, 0x79, 0x59, 0xcd,
-	0x8f, 0x7a, 0x8a, 0xbc, 0x82, 0x1d, 0xfd, 0xe6, 0x7c, 0xe8, 0x3d, 0x3d, 0xa7, 0x47, 0xb5, 0x36,
-	0x96, 0xda, 0x8e, 0x5c, 0xde, 0xad, 0x68, 0xf6, 0x3f, 0x03, 0x60, 0x0d, 0x3c, 0xf0, 0xde, 0x86,
-	0xad, 0xbd, 0x7b, 0x6d, 0xed, 0x6f, 0xda, 0x7a, 0x97, 0x85, 0x5b, 0x0f, 0xb0, 0xf0, 0xc5, 0x14,
-	0x26, 0x4d, 0x16, 0x19, 0xc1, 0xce, 0x7c, 0x71, 0x75, 0x7e, 0xf9, 0xc9, 0xfd, 0x3a, 0xe9, 0x90,
-	0x3d, 0x18, 0x7e, 0x9c, 0x5f, 0x2c, 0xe6, 0x5f, 0xf2, 0xd0, 0x98, 0xfd, 0x37, 0x14, 0x5a, 0x9a,
-	0x4e, 0xde, 0xc3, 0xa0, 0x3c, 0x93, 0x27, 0xed, 0x6f, 0xbb, 0xdc, 0x35, 0xcb, 0xba, 0x0b, 0x2a,
-	0xf6, 0xc4, 0xee, 0x90, 0x05, 0xec, 0x9f, 0xa1, 0xdc, 0x98, 0xe3, 0xe3, 0xd6, 0xd2, 0x9d, 0xe6,
-	0xab, 0x6e, 0x1d, 0xb6, 0x85, 0x74, 0x81, 0x52, 0x79, 0x07, 0x7b, 0x4a, 0x65, 0xa9, 0xff, 0x17,
-	0xf7, 0x6a, 0x6c, 0xcc, 0xb6, 0xa2, 0xdb, 0x9d, 0xeb, 0x6d, 0x4d, 0x7c, 0x7d, 0x1b, 0x00, 0x00,
-	0xff, 0xff, 0x83, 0xde, 0x75, 0xe5, 0x90, 0x04, 0x00, 0x00,
+func init() { proto.RegisterFile("analyzer.proto", fileDescriptor_analyzer_73caa130c2404709) }
+
+var fileDescriptor_analyzer_73caa130c2404709 = []byte{
+	// 553 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x54, 0xcd, 0x6e, 0xd3, 0x40,
+	0x10, 0x8e, 0x9b, 0xa4, 0x49, 0x26, 0x69, 0x9a, 0xae, 0x44, 0x6b, 0xd2, 0x0a, 0x45, 0x3e, 0xa0,
+	0x08, 0x21, 0x47, 0x84, 0x03, 0x12, 0x07, 0x44, 0x50, 0xaa, 0xa8, 0x12, 0x94, 0xc8, 0x45, 0x95,
+	0x38, 0x70, 0x70, 0x9d, 0x89, 0xb5, 0xc2, 0xf6, 0x2e, 0xbb, 0x6b, 0x50, 0x78, 0x07, 0x5e, 0x82,
+	0x97, 0xe1, 0x0d, 0x38, 0xf2, 0x2c, 0xc8, 0xeb, 0xfc, 0x6c, 0xe2, 0xaa, 0x42, 0x3d, 0x71, 0xdb,
+	0x9d, 0xef, 0x9b, 0x6f, 0x3d, 0x33, 0x9f, 0x07, 0xda, 0x7e, 0xe2, 0x47, 0x8b, 0xef, 0x28, 0x5c,
+	0x2e, 0x98, 0x62, 0xa4, 0xc1, 0xd3, 0x28, 0x8d, 0xa9, 0xe0, 0x41, 0xb7, 0xc5, 0xa3, 0x34, 0xa4,
+	0x49, 0x0e, 0x74, 0x4f, 0x43, 0xc6, 0xc2, 0x08, 0x07, 0xfa, 0x76, 0x93, 0xce, 0x07, 0x18, 0x73,
+	0xb5, 0x58, 0x82, 0x67, 0xbb, 0xa0, 0x54, 0x22, 0x0d, 0x54, 0x8e, 0x3a, 0x9f, 0xa0, 0x3d, 0xca,
+	0x5f, 0xf1, 0xf0, 0x4b, 0x8a, 0x52, 0x11, 0x02, 0x15, 0xb5, 0xe0, 0x68, 0x5b, 0x3d, 0xab, 0xdf,
+	0xf0, 0xf4, 0x99, 0xbc, 0x00, 0xe0, 0x82, 0x71, 0x14, 0x8a, 0xa2, 0xb4, 0xf7, 0x7a, 0x56, 0xbf,
+	0x39, 0x3c, 0x71, 0x73, 0x61, 0x77, 0x25, 0xec, 0x5e, 0x69, 0x61, 0xcf, 0xa0, 0x3a, 0x3f, 0x2c,
+	0x38, 0x5c, 0xeb, 0x4b, 0xce, 0x12, 0x89, 0xe4, 0x15, 0x34, 0x67, 0xd4, 0x0f, 0x13, 0x26, 0x15,
+	0x0d, 0x32, 0xb5, 0x72, 0xbf, 0x39, 0x3c, 0x73, 0xd7, 0xc5, 0xb9, 0xcb, 0x84, 0xf1, 0x9a, 0xe4,
+	0x99, 0x09, 0xe4, 0x25, 0xb4, 0x04, 0xc6, 0x38, 0xa3, 0xbe, 0xa2, 0x2c, 0x91, 0x76, 0x59, 0x0b,
+	0x1c, 0x1b, 0x02, 0xde, 0x06, 0xf6, 0xb6, 0xb8, 0xce, 0xcf, 0x3d, 0x38, 0x2a, 0xc8, 0x93, 0x47,
+	0x00, 0x9c, 0x45, 0x34, 0x58, 0x5c, 0xfa, 0xf1, 0xaa, 0x70, 0x23, 0x42, 0x1e, 0x43, 0x3b, 0xbf,
+	0x4d, 0xfd, 0xe0, 0xb3, 0xe6, 0xec, 0x69, 0xce, 0x4e, 0x94, 0x3c, 0x85, 0xa3, 0x4d, 0xe4, 0x1a,
+	0x85, 0xa4, 0x2c, 0xb1, 0xcb, 0x9a, 0x5a, 0x04, 0x48, 0x0f, 0x9a, 0x33, 0x94, 0x81, 0xa0, 0x3c,
+	0xfb, 0x36, 0xbb, 0xa2, 0x79, 0x66, 0x88, 0xd8, 0x50, 0x8b, 0x51, 0x4a, 0x3f, 0x44, 0xbb, 0xaa,
+	0xd1, 0xd5, 0x55, 0x0f, 0xc9, 0x0f, 0xa5, 0xbd, 0xdf, 0x2b, 0xeb, 0x21, 0xf9, 0xa1, 0x24, 0x13,
+	0xe8, 0x60, 0x32, 0x67, 0x22, 0xc0, 0x18, 0x13, 0xf5, 0x16, 0xbf, 0x62, 0x64, 0xd7, 0x7a, 0x56,
+	0xbf, 0x3d, 0x3c, 0x35, 0x7a, 0x73, 0xbe, 0x43, 0xf1, 0x0a, 0x49, 0xce, 0x1f, 0x0b, 0x9a, 0x46,
+	0x0b, 0xff, 0xdb, 0xf6, 0x6c, 0xbb, 0xb2, 0xfa, 0xef, 0xae, 0xfc, 0x06, 0xad, 0xa5, 0x09, 0xc4,
+	0x45, 0x32, 0x67, 0x59, 0x37, 0x93, 0x4d, 0x69, 0xfa, 0xac, 0x9f, 0xa7, 0x92, 0x47, 0xfe, 0xc2,
+	0xa8, 0xc8, 0x0c, 0x91, 0x67, 0x50, 0xd7, 0x5f, 0x4d, 0x71, 0xe5, 0xc1, 0x07, 0x46, 0x9f, 0xa7,
+	0xba, 0xa0, 0x4c, 0xde, 0x5b, 0xd3, 0x9c, 0x5f, 0x16, 0xc0, 0x06, 0xb8, 0xe7, 0xbb, 0x3b, 0x8d,
+	0x29, 0xdf, 0xe9, 0x9b, 0xca, 0xb6, 0x6f, 0x6e, 0xf3, 0x48, 0xf5, 0x1e, 0x1e, 0x79, 0x32, 0x80,
+	0xce, 0x2e, 0x8b, 0xb4, 0xa0, 0x3e, 0x1a, 0x5f, 0x5f, 0x5c, 0xbd, 0xf7, 0x3e, 0x76, 0x4a, 0xe4,
+	0x00, 0x1a, 0xef, 0x46, 0x97, 0xe3, 0xd1, 0x87, 0xec, 0x6a, 0x0d, 0x7f, 0x5b, 0x50, 0x5f, 0x35,
+	0x9d, 0xbc, 0x81, 0xda, 0xf2, 0x4c, 0x1e, 0x16, 0x7f, 0xfc, 0xe5, 0x26, 0xea, 0x76, 0x6f, 0x83,
+	0xf2, 0x25, 0xe2, 0x94, 0xc8, 0x18, 0x0e, 0x27, 0xa8, 0xb6, 0xe6, 0x78, 0x5c, 0x18, 0xfe, 0x79,
+	0xb6, 0x08, 0xbb, 0x27, 0x45, 0x21, 0x9d, 0xe0, 0x94, 0xc8, 0x6b, 0x38, 0x98, 0xa0, 0x9a, 0xea,
+	0x6d, 0x7a, 0xa7, 0xc6, 0xd6, 0x6c, 0xd7, 0x74, 0xa7, 0x74, 0xb3, 0xaf, 0x89, 0xcf, 0xff, 0x0e,
+	0x00, 0x63, 0xad, 0xba, 0x30, 0xae, 0x05, 0x00, 0x00,
 }
@@ -57,7 +57,7 @@ func (x PropertyDiff_Kind) String() string {
 	return proto.EnumName(PropertyDiff_Kind_name, int32(x))
 }
 func (PropertyDiff_Kind) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_provider_31a5eda36dbe30f0, []int{9, 0}
+	return fileDescriptor_provider_0bb3ac224532c65f, []int{9, 0}
 }
 
 type DiffResponse_DiffChanges int32
@@ -83,7 +83,7 @@ func (x DiffResponse_DiffChanges) String() string {
 	return proto.EnumName(DiffResponse_DiffChanges_name, int32(x))
 }
 func (DiffResponse_DiffChanges) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_provider_31a5eda36dbe30f0, []int{10, 0}
+	return fileDescriptor_provider_0bb3ac224532c65f, []int{10, 0}
 }
 
 type ConfigureRequest struct {
@@ -99,7 +99,7 @@ func (m *ConfigureRequest) Reset()         { *m = ConfigureRequest{} }
 func (m *ConfigureRequest) String() string { return proto.CompactTextString(m) }
 func (*ConfigureRequest) ProtoMessage()    {}
 func (*ConfigureRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_31a5eda36dbe30f0, []int{0}
+	return fileDescriptor_provider_0bb3ac224532c65f, []int{0}
 }
 func (m *ConfigureRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ConfigureRequest.Unmarshal(m, b)
@@ -151,7 +151,7 @@ func (m *ConfigureResponse) Reset()         { *m = ConfigureResponse{} }
 func (m *ConfigureResponse) String() string { return proto.CompactTextString(m) }
 func (*ConfigureResponse) ProtoMessage()    {}
 func (*ConfigureResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_31a5eda36dbe30f0, []int{1}
+	return fileDescriptor_provider_0bb3ac224532c65f, []int{1}
 }
 func (m *ConfigureResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ConfigureResponse.Unmarshal(m, b)
@@ -190,7 +190,7 @@ func (m *ConfigureErrorMissingKeys) Reset()         { *m = ConfigureErrorMissing
 func (m *ConfigureErrorMissingKeys) String() string { return proto.CompactTextString(m) }
 func (*ConfigureErrorMissingKeys) ProtoMessage()    {}
 func (*ConfigureErrorMissingKeys) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_31a5eda36dbe30f0, []int{2}
+	return fileDescriptor_provider_0bb3ac224532c65f, []int{2}
 }
 func (m *ConfigureErrorMissingKeys) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ConfigureErrorMissingKeys.Unmarshal(m, b)
@@ -229,7 +229,7 @@ func (m *ConfigureErrorMissingKeys_MissingKey) Reset()         { *m = ConfigureE
 func (m *ConfigureErrorMissingKeys_MissingKey) String() string { return proto.CompactTextString(m) }
 func (*ConfigureErrorMissingKeys_MissingKey) ProtoMessage()    {}
 func (*ConfigureErrorMissingKeys_MissingKey) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_31a5eda36dbe30f0, []int{2, 0}
+	return fileDescriptor_provider_0bb3ac224532c65f, []int{2, 0}
 }
 func (m *ConfigureErrorMissingKeys_MissingKey) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ConfigureErrorMissingKeys_MissingKey.Unmarshal(m, b)
@@ -277,7 +277,7 @@ func (m *InvokeRequest) Reset()         { *m = InvokeRequest{} }
 func (m *InvokeRequest) String() string { return proto.CompactTextString(m) }
 func (*InvokeRequest) ProtoMessage()    {}
 func (*InvokeRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_31a5eda36dbe30f0, []int{3}
+	return fileDescriptor_provider_0bb3ac224532c65f, []int{3}
 }
 func (m *InvokeRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_InvokeRequest.Unmarshal(m, b)
@@ -337,7 +337,7 @@ func (m *InvokeResponse) Reset()         { *m = InvokeResponse{} }
 func (m *InvokeResponse) String() string { return proto.CompactTextString(m) }
 func (*InvokeResponse) ProtoMessage()    {}
 func (*InvokeResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_31a5eda36dbe30f0, []int{4}
+	return fileDescriptor_provider_0bb3ac224532c65f, []int{4}
 }
 func (m *InvokeResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_InvokeResponse.Unmarshal(m, b)
@@ -384,7 +384,7 @@ func (m *CheckRequest) Reset()         { *m = CheckRequest{} }
 func (m *CheckRequest) String() string { return proto.CompactTextString(m) }
 func (*CheckRequest) ProtoMessage()    {}
 func (*CheckRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_31a5eda36dbe30f0, []int{5}
+	return fileDescriptor_provider_0bb3ac224532c65f, []int{5}
 }
 func (m *CheckRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CheckRequest.Unmarshal(m, b)
@@ -437,7 +437,7 @@ func (m *CheckResponse) Reset()         { *m = CheckResponse{} }
 func (m *CheckResponse) String() string { return proto.CompactTextString(m) }
 func (*CheckResponse) ProtoMessage()    {}
 func (*CheckResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_31a5eda36dbe30f0, []int{6}
+	return fileDescriptor_provider_0bb3ac224532c65f, []int{6}
 }
 func (m *CheckResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CheckResponse.Unmarshal(m, b)
@@ -483,7 +483,7 @@ func (m *CheckFailure) Reset()         { *m = CheckFailure{} }
 func (m *CheckFailure) String() string { return proto.CompactTextString(m) }
 func (*CheckFailure) ProtoMessage()    {}
 func (*CheckFailure) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_31a5eda36dbe30f0, []int{7}
+	return fileDescriptor_provider_0bb3ac224532c65f, []int{7}
 }
 func (m *CheckFailure) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CheckFailure.Unmarshal(m, b)
@@ -532,7 +532,7 @@ func (m *DiffRequest) Reset()         { *m = DiffRequest{} }
 func (m *DiffRequest) String() string { return proto.CompactTextString(m) }
 func (*DiffRequest) ProtoMessage()    {}
 func (*DiffRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_31a5eda36dbe30f0, []int{8}
+	return fileDescriptor_provider_0bb3ac224532c65f, []int{8}
 }
 func (m *DiffRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_DiffRequest.Unmarshal(m, b)
@@ -599,7 +599,7 @@ func (m *PropertyDiff) Reset()         { *m = PropertyDiff{} }
 func (m *PropertyDiff) String() string { return proto.CompactTextString(m) }
 func (*PropertyDiff) ProtoMessage()    {}
 func (*PropertyDiff) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_31a5eda36dbe30f0, []int{9}
+	return fileDescriptor_provider_0bb3ac224532c65f, []int{9}
 }
 func (m *PropertyDiff) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PropertyDiff.Unmarshal(m, b)
@@ -680,7 +680,7 @@ func (m *DiffResponse) Reset()         { *m = DiffResponse{} }
 func (m *DiffResponse) String() string { return proto.CompactTextString(m) }
 func (*DiffResponse) ProtoMessage()    {}
 func (*DiffResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_31a5eda36dbe30f0, []int{10}
+	return fileDescriptor_provider_0bb3ac224532c65f, []int{10}
 }
 func (m *DiffResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_DiffResponse.Unmarshal(m, b)
@@ -762,7 +762,7 @@ func (m *CreateRequest) Reset()         { *m = CreateRequest{} }
 func (m *CreateRequest) String() string { return proto.CompactTextString(m) }
 func (*CreateRequest) ProtoMessage()    {}
 func (*CreateRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_31a5eda36dbe30f0, []int{11}
+	return fileDescriptor_provider_0bb3ac224532c65f, []int{11}
 }
 func (m *CreateRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CreateRequest.Unmarshal(m, b)
@@ -815,7 +815,7 @@ func (m *CreateResponse) Reset()         { *m = CreateResponse{} }
 func (m *CreateResponse) String() string { return proto.CompactTextString(m) }
 func (*CreateResponse) ProtoMessage()    {}
 func (*CreateResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_31a5eda36dbe30f0, []int{12}
+	return fileDescriptor_provider_0bb3ac224532c65f, []int{12}
 }
 func (m *CreateResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CreateResponse.Unmarshal(m, b)
@@ -863,7 +863,7 @@ func (m *ReadRequest) Reset()         { *m = ReadRequest{} }
 func (m *ReadRequest) String() string { return proto.CompactTextString(m) }
 func (*ReadRequest) ProtoMessage()    {}
 func (*ReadRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_31a5eda36dbe30f0, []int{13}
+	return fileDescriptor_provider_0bb3ac224532c65f, []int{13}
 }
 func (m *ReadRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ReadRequest.Unmarshal(m, b)
@@ -924,7 +924,7 @@ func (m *ReadResponse) Reset()         { *m = ReadResponse{} }
 func (m *ReadResponse) String() string { return proto.CompactTextString(m) }
 func (*ReadResponse) ProtoMessage()    {}
 func (*ReadResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_31a5eda36dbe30f0, []int{14}
+	return fileDescriptor_provider_0bb3ac224532c65f, []int{14}
 }
 func (m *ReadResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ReadResponse.Unmarshal(m, b)
@@ -981,7 +981,7 @@ func (m *UpdateRequest) Reset()         { *m = UpdateRequest{} }
 func (m *UpdateRequest) String() string { return proto.CompactTextString(m) }
 func (*UpdateRequest) ProtoMessage()    {}
 func (*UpdateRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_31a5eda36dbe30f0, []int{15}
+	return fileDescriptor_provider_0bb3ac224532c65f, []int{15}
 }
 func (m *UpdateRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_UpdateRequest.Unmarshal(m, b)
@@ -1054,7 +1054,7 @@ func (m *UpdateResponse) Reset()         { *m = UpdateResponse{} }
 func (m *UpdateResponse) String() string { return proto.CompactTextString(m) }
 func (*UpdateResponse) ProtoMessage()    {}
 func (*UpdateResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_31a5eda36dbe30f0, []int{16}
+	return fileDescriptor_provider_0bb3ac224532c65f, []int{16}
 }
 func (m *UpdateResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_UpdateResponse.Unmarshal(m, b)
@@ -1095,7 +1095,7 @@ func (m *DeleteRequest) Reset()         { *m = DeleteRequest{} }
 func (m *DeleteRequest) String() string { return proto.CompactTextString(m) }
 func (*DeleteRequest) ProtoMessage()    {}
 func (*DeleteRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_31a5eda36dbe30f0, []int{17}
+	return fileDescriptor_provider_0bb3ac224532c65f, []int{17}
 }
 func (m *DeleteRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_DeleteRequest.Unmarshal(m, b)
@@ -1159,7 +1159,7 @@ func (m *ErrorResourceInitFailed) Reset()         { *m = ErrorResourceInitFailed
 func (m *ErrorResourceInitFailed) String() string { return proto.CompactTextString(m) }
 func (*ErrorResourceInitFailed) ProtoMessage()    {}
 func (*ErrorResourceInitFailed) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_31a5eda36dbe30f0, []int{18}
+	return fileDescriptor_provider_0bb3ac224532c65f, []int{18}
 }
 func (m *ErrorResourceInitFailed) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ErrorResourceInitFailed.Unmarshal(m, b)
@@ -1207,6 +1207,216 @@ func (m *ErrorResourceInitFailed) GetInputs() *_struct.Struct {
 	return nil
 }
 
+type GetLogsRequest struct {
+	Resources            []*LogResource `protobuf:"bytes,1,rep,name=resources" json:"resources,omitempty"`
+	StartTime            int64          `protobuf:"varint,2,opt,name=startTime" json:"startTime,omitempty"`
+	EndTime              int64          `protobuf:"varint,3,opt,name=endTime" json:"endTime,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *GetLogsRequest) Reset()         { *m = GetLogsRequest{} }
+func (m *GetLogsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetLogsRequest) ProtoMessage()    {}
+func (*GetLogsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_provider_0bb3ac224532c65f, []int{19}
+}
+func (m *GetLogsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetLogsRequest.Unmarshal(m, b)
+}
+func (m *GetLogsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetLogsRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetLogsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetLogsRequest.Merge(dst, src)
+}
+func (m *GetLogsRequest) XXX_Size() int {
+	return xxx_messageInfo_GetLogsRequest.Size(m)
+}
+func (m *GetLogsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetLogsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetLogsRequest proto.InternalMessageInfo
+
+func (m *GetLogsRequest) GetResources() []*LogResource {
+	if m != nil {
+		return m.Resources
+	}
+	return nil
+}
+
+func (m *GetLogsRequest) GetStartTime() int64 {
+	if m != nil {
+		return m.StartTime
+	}
+	return 0
+}
+
+func (m *GetLogsRequest) GetEndTime() int64 {
+	if m != nil {
+		return m.EndTime
+	}
+	return 0
+}
+
+// LogResource identifies a resource whose log entries are requested.
+type LogResource struct {
+	Id                   string          `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Urn                  string          `protobuf:"bytes,2,opt,name=urn" json:"urn,omitempty"`
+	Properties           *_struct.Struct `protobuf:"bytes,3,opt,name=properties" json:"properties,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *LogResource) Reset()         { *m = LogResource{} }
+func (m *LogResource) String() string { return proto.CompactTextString(m) }
+func (*LogResource) ProtoMessage()    {}
+func (*LogResource) Descriptor() ([]byte, []int) {
+	return fileDescriptor_provider_0bb3ac224532c65f, []int{20}
+}
+func (m *LogResource) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LogResource.Unmarshal(m, b)
+}
+func (m *LogResource) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LogResource.Marshal(b, m, deterministic)
+}
+func (dst *LogResource) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LogResource.Merge(dst, src)
+}
+func (m *LogResource) XXX_Size() int {
+	return xxx_messageInfo_LogResource.Size(m)
+}
+func (m *LogResource) XXX_DiscardUnknown() {
+	xxx_messageInfo_LogResource.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LogResource proto.InternalMessageInfo
+
+func (m *LogResource) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *LogResource) GetUrn() string {
+	if m != nil {
+		return m.Urn
+	}
+	return ""
+}
+
+func (m *LogResource) GetProperties() *_struct.Struct {
+	if m != nil {
+		return m.Properties
+	}
+	return nil
+}
+
+type GetLogsResponse struct {
+	Entries              []*LogEntry `protobuf:"bytes,1,rep,name=entries" json:"entries,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *GetLogsResponse) Reset()         { *m = GetLogsResponse{} }
+func (m *GetLogsResponse) String() string { return proto.CompactTextString(m) }
+func (*GetLogsResponse) ProtoMessage()    {}
+func (*GetLogsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_provider_0bb3ac224532c65f, []int{21}
+}
+func (m *GetLogsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetLogsResponse.Unmarshal(m, b)
+}
+func (m *GetLogsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetLogsResponse.Marshal(b, m, deterministic)
+}
+func (dst *GetLogsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetLogsResponse.Merge(dst, src)
+}
+func (m *GetLogsResponse) XXX_Size() int {
+	return xxx_messageInfo_GetLogsResponse.Size(m)
+}
+func (m *GetLogsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetLogsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetLogsResponse proto.InternalMessageInfo
+
+func (m *GetLogsResponse) GetEntries() []*LogEntry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+// LogEntry is a single entry in the logs of a resource.
+type LogEntry struct {
+	Urn                  string   `protobuf:"bytes,1,opt,name=urn" json:"urn,omitempty"`
+	Id                   string   `protobuf:"bytes,2,opt,name=id" json:"id,omitempty"`
+	Timestamp            int64    `protobuf:"varint,3,opt,name=timestamp" json:"timestamp,omitempty"`
+	Message              string   `protobuf:"bytes,4,opt,name=message" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LogEntry) Reset()         { *m = LogEntry{} }
+func (m *LogEntry) String() string { return proto.CompactTextString(m) }
+func (*LogEntry) ProtoMessage()    {}
+func (*LogEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_provider_0bb3ac224532c65f, []int{22}
+}
+func (m *LogEntry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LogEntry.Unmarshal(m, b)
+}
+func (m *LogEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LogEntry.Marshal(b, m, deterministic)
+}
+func (dst *LogEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LogEntry.Merge(dst, src)
+}
+func (m *LogEntry) XXX_Size() int {
+	return xxx_messageInfo_LogEntry.Size(m)
+}
+func (m *LogEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_LogEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LogEntry proto.InternalMessageInfo
+
+func (m *LogEntry) GetUrn() string {
+	if m != nil {
+		return m.Urn
+	}
+	return ""
+}
+
+func (m *LogEntry) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *LogEntry) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *LogEntry) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*ConfigureRequest)(nil), "pulumirpc.ConfigureRequest")
 	proto.RegisterMapType((map[string]string)(nil), "pulumirpc.ConfigureRequest.VariablesEntry")
@@ -1230,6 +1440,10 @@ func init() {
 	proto.RegisterType((*UpdateResponse)(nil), "pulumirpc.UpdateResponse")
 	proto.RegisterType((*DeleteRequest)(nil), "pulumirpc.DeleteRequest")
 	proto.RegisterType((*ErrorResourceInitFailed)(nil), "pulumirpc.ErrorResourceInitFailed")
+	proto.RegisterType((*GetLogsRequest)(nil), "pulumirpc.GetLogsRequest")
+	proto.RegisterType((*LogResource)(nil), "pulumirpc.LogResource")
+	proto.RegisterType((*GetLogsResponse)(nil), "pulumirpc.GetLogsResponse")
+	proto.RegisterType((*LogEntry)(nil), "pulumirpc.LogEntry")
 	proto.RegisterEnum("pulumirpc.PropertyDiff_Kind", PropertyDiff_Kind_name, PropertyDiff_Kind_value)
 	proto.RegisterEnum("pulumirpc.DiffResponse_DiffChanges", DiffResponse_DiffChanges_name, DiffResponse_DiffChanges_value)
 }
@@ -1271,6 +1485,9 @@ type ResourceProviderClient interface {
 	Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error)
 	// Delete tears down an existing resource with the given ID.  If it fails, the resource is assumed to still exist.
 	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	// GetLogs returns the log entries written by the given resources, such as those of the functions or containers they
+	// run. Providers whose resources do not write logs need not implement it.
+	GetLogs(ctx context.Context, in *GetLogsRequest, opts ...grpc.CallOption) (*GetLogsResponse, error)
 	// Cancel signals the provider to abort all outstanding resource operations.
 	Cancel(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error)
 	// GetPluginInfo returns generic information about this plugin, like its version.
@@ -1375,6 +1592,15 @@ func (c *resourceProviderClient) Delete(ctx context.Context, in *DeleteRequest,
 	return out, nil
 }
 
+func (c *resourceProviderClient) GetLogs(ctx context.Context, in *GetLogsRequest, opts ...grpc.CallOption) (*GetLogsResponse, error) {
+	out := new(GetLogsResponse)
+	err := grpc.Invoke(ctx, "/pulumirpc.ResourceProvider/GetLogs", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *resourceProviderClient) Cancel(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error) {
 	out := new(empty.Empty)
 	err := grpc.Invoke(ctx, "/pulumirpc.ResourceProvider/Cancel", in, out, c.cc, opts...)
@@ -1422,6 +1648,9 @@ type ResourceProviderServer interface {
 	Update(context.Context, *UpdateRequest) (*UpdateResponse, error)
 	// Delete tears down an existing resource with the given ID.  If it fails, the resource is assumed to still exist.
 	Delete(context.Context, *DeleteRequest) (*empty.Empty, error)
+	// GetLogs returns the log entries written by the given resources, such as those of the functions or containers they
+	// run. Providers whose resources do not write logs need not implement it.
+	GetLogs(context.Context, *GetLogsRequest) (*GetLogsResponse, error)
 	// Cancel signals the provider to abort all outstanding resource operations.
 	Cancel(context.Context, *empty.Empty) (*empty.Empty, error)
 	// GetPluginInfo returns generic information about this plugin, like its version.
@@ -1612,6 +1841,24 @@ func _ResourceProvider_Delete_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ResourceProvider_GetLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLogsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResourceProviderServer).GetLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pulumirpc.ResourceProvider/GetLogs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResourceProviderServer).GetLogs(ctx, req.(*GetLogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ResourceProvider_Cancel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(empty.Empty)
 	if err := dec(in); err != nil {
@@ -1692,6 +1939,10 @@ var _ResourceProvider_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Delete",
 			Handler:    _ResourceProvider_Delete_Handler,
 		},
+		{
+			MethodName: "GetLogs",
+			Handler:    _ResourceProvider_GetLogs_Handler,
+		},
 		{
 			MethodName: "Cancel",
 			Handler:    _ResourceProvider_Cancel_Handler,
@@ -1705,83 +1956,92 @@ var _ResourceProvider_serviceDesc = grpc.ServiceDesc{
 	Metadata: "provider.proto",
 }
 
-func init() { proto.RegisterFile("provider.proto", fileDescriptor_provider_31a5eda36dbe30f0) }
-
-var fileDescriptor_provider_31a5eda36dbe30f0 = []byte{
-	// 1196 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xc4, 0x57, 0xcd, 0x72, 0xe3, 0x44,
-	0x10, 0x5e, 0xd9, 0x8a, 0x13, 0xb7, 0x1d, 0xaf, 0x77, 0x80, 0xc4, 0xab, 0xcd, 0x21, 0x25, 0x38,
-	0x04, 0x28, 0x1c, 0x2a, 0x7b, 0x80, 0xdd, 0xda, 0x2d, 0x48, 0x62, 0x07, 0x52, 0xd9, 0xfc, 0xa0,
-	0x10, 0x7e, 0x4e, 0x8b, 0x22, 0x8d, 0x1d, 0x55, 0x14, 0x49, 0x48, 0x23, 0x53, 0xe1, 0xcc, 0x81,
-	0x07, 0xe0, 0xc2, 0x43, 0x50, 0x54, 0xf1, 0x04, 0xdc, 0x79, 0x06, 0x1e, 0x81, 0x77, 0x60, 0xfe,
-	0x24, 0xcf, 0xc4, 0x4e, 0xd6, 0x49, 0x6d, 0xc1, 0x6d, 0x5a, 0xdd, 0xd3, 0x3f, 0xdf, 0x74, 0x7f,
-	0x33, 0x82, 0x56, 0x92, 0xc6, 0xa3, 0xc0, 0xc7, 0x69, 0x97, 0x2e, 0x48, 0x8c, 0xea, 0x49, 0x1e,
-	0xe6, 0x17, 0x41, 0x9a, 0x78, 0x56, 0x33, 0x09, 0xf3, 0x61, 0x10, 0x09, 0x85, 0xf5, 0x68, 0x18,
-	0xc7, 0xc3, 0x10, 0xaf, 0x73, 0xe9, 0x34, 0x1f, 0xac, 0xe3, 0x8b, 0x84, 0x5c, 0x4a, 0xe5, 0xca,
-	0x55, 0x65, 0x46, 0xd2, 0xdc, 0x23, 0x42, 0x6b, 0xff, 0x63, 0x40, 0x7b, 0x3b, 0x8e, 0x06, 0xc1,
-	0x30, 0x4f, 0xb1, 0x83, 0xbf, 0xcf, 0x71, 0x46, 0xd0, 0xe7, 0x50, 0x1f, 0xb9, 0x69, 0xe0, 0x9e,
-	0x86, 0x38, 0xeb, 0x18, 0xab, 0xd5, 0xb5, 0xc6, 0xc6, 0x7b, 0xdd, 0x32, 0x78, 0xf7, 0xaa, 0x7d,
-	0xf7, 0xab, 0xc2, 0xb8, 0x1f, 0x91, 0xf4, 0xd2, 0x19, 0x6f, 0x46, 0xef, 0x83, 0xe9, 0xa6, 0xc3,
-	0xac, 0x53, 0x59, 0x35, 0xa8, 0x93, 0xe5, 0xae, 0xc8, 0xa5, 0x5b, 0xe4, 0xd2, 0x3d, 0xe6, 0xb9,
-	0x38, 0xdc, 0x08, 0xbd, 0x03, 0x8b, 0xae, 0xe7, 0xe1, 0x84, 0x1c, 0x63, 0x2f, 0xc5, 0x24, 0xeb,
-	0x54, 0xe9, 0xae, 0x05, 0x47, 0xff, 0x68, 0x3d, 0x83, 0x96, 0x1e, 0x0f, 0xb5, 0xa1, 0x7a, 0x8e,
-	0x2f, 0x69, 0xa2, 0xc6, 0x5a, 0xdd, 0x61, 0x4b, 0xf4, 0x26, 0xcc, 0x8d, 0xdc, 0x30, 0xc7, 0x3c,
-	0x6e, 0xdd, 0x11, 0xc2, 0xd3, 0xca, 0xc7, 0x86, 0xfd, 0x04, 0x1e, 0x28, 0xe9, 0x67, 0x49, 0x1c,
-	0x65, 0x78, 0x32, 0xb0, 0x31, 0x25, 0xb0, 0xfd, 0x87, 0x01, 0x0f, 0xcb, 0xbd, 0xfd, 0x34, 0x8d,
-	0xd3, 0xfd, 0x20, 0xcb, 0x82, 0x68, 0xb8, 0x87, 0x2f, 0x33, 0xf4, 0x05, 0x34, 0x2e, 0xc6, 0xa2,
-	0x44, 0x6d, 0x7d, 0x1a, 0x6a, 0x57, 0xb7, 0x76, 0xc7, 0x6b, 0x47, 0xf5, 0x61, 0x6d, 0x01, 0x8c,
-	0x55, 0x08, 0x81, 0x19, 0xb9, 0x17, 0x58, 0x96, 0xc9, 0xd7, 0x68, 0x15, 0x1a, 0x3e, 0xce, 0xbc,
-	0x34, 0x48, 0x48, 0x10, 0x47, 0xb2, 0x5a, 0xf5, 0x93, 0xfd, 0x93, 0x01, 0x8b, 0xbb, 0xd1, 0x28,
-	0x3e, 0x2f, 0x0f, 0x97, 0xa2, 0x45, 0xe2, 0xf3, 0x02, 0x2d, 0xba, 0xbc, 0xdd, 0x21, 0x59, 0xb0,
-	0x50, 0xb4, 0x25, 0x3f, 0x9f, 0xba, 0x53, 0xca, 0xa8, 0x03, 0xf3, 0x23, 0x9c, 0x66, 0x2c, 0x15,
-	0x93, 0xab, 0x0a, 0xd1, 0x1e, 0x41, 0xab, 0xc8, 0x42, 0x62, 0xbe, 0x0e, 0x35, 0x8a, 0x6a, 0x9e,
-	0x46, 0x3c, 0x93, 0x1b, 0xc2, 0x4a, 0x33, 0xf4, 0x18, 0x16, 0x06, 0x6e, 0x10, 0x52, 0x00, 0x59,
-	0xa6, 0x55, 0xbe, 0x45, 0x41, 0xf7, 0x0c, 0x7b, 0xe7, 0x3b, 0x42, 0xef, 0x94, 0x86, 0xf6, 0x8f,
-	0xd0, 0xe4, 0x1a, 0xa5, 0xf8, 0x22, 0x24, 0x2d, 0x9e, 0xb9, 0xa5, 0xc5, 0xc7, 0xa1, 0xff, 0xea,
-	0xe2, 0x99, 0x11, 0x33, 0x8e, 0xf0, 0x0f, 0xa2, 0x31, 0x6f, 0x32, 0x66, 0x46, 0x76, 0x0e, 0x8b,
-	0x32, 0xf6, 0xb8, 0xe4, 0x20, 0x4a, 0x72, 0xd9, 0x5f, 0x37, 0x95, 0x2c, 0xcc, 0xee, 0x56, 0xf2,
-	0x96, 0x2c, 0x59, 0x6a, 0xe4, 0x81, 0x25, 0x38, 0x25, 0xc5, 0x88, 0x94, 0x32, 0x5a, 0x62, 0x87,
-	0xe0, 0x66, 0x65, 0xeb, 0x48, 0xc9, 0xfe, 0xdd, 0x80, 0x46, 0x2f, 0x18, 0x0c, 0x0a, 0xd8, 0x5a,
-	0x50, 0x09, 0x7c, 0xb9, 0x9b, 0xae, 0x0a, 0x18, 0x2b, 0x93, 0x30, 0x56, 0x6f, 0x03, 0xa3, 0x39,
-	0x03, 0x8c, 0x6c, 0x38, 0x83, 0x61, 0x14, 0xa7, 0x78, 0xfb, 0xcc, 0x8d, 0x86, 0x14, 0x89, 0x39,
-	0x8a, 0x44, 0xdd, 0xd1, 0x3f, 0xda, 0x7f, 0x1a, 0xd0, 0x3c, 0x92, 0x65, 0xb1, 0xcc, 0xd1, 0x87,
-	0x60, 0x9e, 0x07, 0x91, 0x48, 0xba, 0xb5, 0xb1, 0xa2, 0xe0, 0xa6, 0x9a, 0x75, 0xf7, 0xa8, 0x8d,
-	0xc3, 0x2d, 0xd1, 0x0a, 0xd4, 0x39, 0xee, 0xec, 0x3b, 0x2f, 0x6d, 0xc1, 0x19, 0x7f, 0xb0, 0xbf,
-	0x03, 0x93, 0xd9, 0xa2, 0x79, 0xa8, 0x6e, 0xf6, 0x7a, 0xed, 0x7b, 0xe8, 0x3e, 0x34, 0xe8, 0xe2,
-	0xa5, 0xd3, 0x3f, 0x7a, 0xb1, 0xb9, 0xdd, 0x6f, 0x1b, 0x08, 0xa0, 0xd6, 0xeb, 0xbf, 0xe8, 0x7f,
-	0xd9, 0x6f, 0x57, 0xe8, 0xb0, 0xb6, 0xc4, 0xba, 0xd4, 0x57, 0x99, 0xfe, 0xe4, 0xa8, 0xb7, 0x49,
-	0xf5, 0x26, 0xd3, 0x8b, 0x75, 0xa9, 0x9f, 0xb3, 0xff, 0xae, 0x42, 0x53, 0x80, 0x2e, 0xfb, 0x85,
-	0x9e, 0x5c, 0x8a, 0x93, 0xd0, 0xf5, 0x24, 0x0b, 0xd3, 0x93, 0x2b, 0x64, 0x36, 0x6a, 0x19, 0x11,
-	0x04, 0x5d, 0xe1, 0xaa, 0x42, 0xa4, 0x85, 0xbf, 0xe1, 0xe3, 0x10, 0x13, 0xbc, 0x85, 0x07, 0x31,
-	0x23, 0x39, 0xbe, 0x43, 0x72, 0xe9, 0x34, 0x15, 0x7a, 0x0e, 0xf3, 0x9e, 0xc4, 0xd6, 0xe4, 0x68,
-	0xbd, 0xad, 0xa0, 0xa5, 0x66, 0xc4, 0x05, 0x89, 0xb8, 0x53, 0xec, 0x61, 0x64, 0xeb, 0xd3, 0xef,
-	0xc5, 0xc1, 0x08, 0x01, 0xed, 0x43, 0xd3, 0xc7, 0x84, 0xf6, 0x20, 0xf6, 0x39, 0xa0, 0x35, 0xde,
-	0xbf, 0xef, 0x5e, 0xeb, 0x59, 0xb1, 0x15, 0xb7, 0x88, 0xb6, 0x1d, 0xad, 0xc1, 0xfd, 0x33, 0x37,
-	0x53, 0xad, 0x3a, 0xf3, 0xbc, 0xa2, 0xab, 0x9f, 0xad, 0x6f, 0xe0, 0xc1, 0x84, 0xb3, 0x29, 0x57,
-	0xc4, 0x07, 0xea, 0x15, 0xa1, 0x0f, 0x96, 0xda, 0x20, 0xea, 0xdd, 0xf1, 0x5c, 0x0c, 0x85, 0x04,
-	0x80, 0xfa, 0x6c, 0xf6, 0x76, 0x77, 0x76, 0x5e, 0x9e, 0x1c, 0xec, 0x1d, 0x1c, 0x7e, 0x7d, 0x40,
-	0x5b, 0x62, 0x11, 0xea, 0xfc, 0xcb, 0xc1, 0xe1, 0x01, 0x6b, 0x88, 0x42, 0x3c, 0x3e, 0xdc, 0xa7,
-	0x3d, 0x61, 0x13, 0xca, 0x07, 0x74, 0xbe, 0x08, 0xbe, 0x9e, 0x8c, 0x3e, 0x02, 0x90, 0xb3, 0x19,
-	0xe0, 0x57, 0x52, 0x92, 0x62, 0xca, 0xda, 0x81, 0x04, 0x17, 0x38, 0xce, 0x09, 0x3f, 0x68, 0xc3,
-	0x29, 0x44, 0xfb, 0x5b, 0x68, 0x15, 0x51, 0x65, 0x5b, 0x5d, 0x1d, 0xe6, 0xbb, 0x06, 0xb5, 0x7f,
-	0xa5, 0x2c, 0xe1, 0x60, 0xd7, 0x9f, 0x9d, 0x25, 0xf4, 0x50, 0xd5, 0xd9, 0xeb, 0x1b, 0x53, 0xa7,
-	0x39, 0x13, 0x75, 0xda, 0x3f, 0x53, 0x3e, 0x10, 0xb9, 0xbd, 0xe6, 0xaa, 0x95, 0x54, 0xaa, 0xb3,
-	0xa5, 0xf2, 0x17, 0xbd, 0x82, 0x4f, 0x12, 0x5f, 0x39, 0xf8, 0xff, 0x93, 0x4e, 0x95, 0x4e, 0x99,
-	0xd3, 0x3a, 0x65, 0x92, 0x68, 0x6b, 0xd3, 0x88, 0x76, 0x97, 0x32, 0x97, 0x2c, 0x46, 0x22, 0xab,
-	0x23, 0x69, 0xcc, 0xde, 0x3f, 0xec, 0x6d, 0xd2, 0xe3, 0x7c, 0xf4, 0x1f, 0x74, 0x90, 0x52, 0xb7,
-	0xa9, 0x4f, 0xc8, 0x6f, 0x06, 0x2c, 0xf3, 0x37, 0x19, 0xad, 0x28, 0xce, 0x53, 0x0f, 0xef, 0x46,
-	0x01, 0xd9, 0xe1, 0x04, 0xf2, 0xfa, 0xba, 0x86, 0x86, 0x17, 0x77, 0x2b, 0x4b, 0x9a, 0xf3, 0xb5,
-	0x14, 0x6f, 0xdd, 0xda, 0x1b, 0xbf, 0xd4, 0xa0, 0x5d, 0xa4, 0x7a, 0x54, 0x3c, 0xbd, 0xb6, 0xa0,
-	0xc1, 0x6f, 0x7d, 0xf1, 0xca, 0x44, 0x13, 0xef, 0x04, 0x89, 0xb0, 0xd5, 0x99, 0x54, 0x88, 0x63,
-	0xb4, 0xef, 0xa1, 0x4f, 0x00, 0x38, 0xbf, 0x09, 0x17, 0x4b, 0x13, 0x54, 0x2d, 0x3c, 0x2c, 0x5f,
-	0x43, 0xe1, 0xd4, 0x01, 0xfd, 0x6f, 0x28, 0x5f, 0xb9, 0xe8, 0xd1, 0x0d, 0x7f, 0x0c, 0xd6, 0xca,
-	0x74, 0xa5, 0x92, 0x4a, 0x4d, 0xbc, 0x17, 0x91, 0x9a, 0xb0, 0xf6, 0x90, 0xb5, 0x1e, 0x4e, 0xd1,
-	0x94, 0x0e, 0x9e, 0xc1, 0x1c, 0x2f, 0xef, 0x6e, 0x48, 0x3c, 0x01, 0x93, 0xdf, 0x3a, 0x77, 0xc0,
-	0x80, 0x66, 0x2e, 0xf8, 0x56, 0xcb, 0x5c, 0x23, 0x7e, 0x2d, 0x73, 0x9d, 0x9c, 0x45, 0x6c, 0x46,
-	0x5c, 0x5a, 0x6c, 0x85, 0x65, 0xb5, 0xd8, 0x2a, 0xc3, 0x89, 0xd8, 0x62, 0x36, 0xb5, 0xd8, 0x1a,
-	0xf7, 0x68, 0xb1, 0xf5, 0x41, 0xe6, 0xa8, 0xd5, 0xc4, 0x40, 0x6a, 0x0e, 0xb4, 0x19, 0xb5, 0x96,
-	0x26, 0xfa, 0xb3, 0xcf, 0xfe, 0x36, 0xe9, 0xee, 0xa7, 0xb4, 0x74, 0x37, 0xf2, 0x70, 0x88, 0xae,
-	0xb1, 0xb9, 0x61, 0xef, 0xa7, 0xb0, 0xf8, 0x19, 0x26, 0x47, 0xfc, 0xaf, 0x76, 0x37, 0x1a, 0xc4,
-	0xd7, 0xba, 0x78, 0x4b, 0xbd, 0xa8, 0x4b, 0x73, 0xfb, 0xde, 0x69, 0x8d, 0x1b, 0x3e, 0xfe, 0x37,
-	0x00, 0x00, 0xff, 0xff, 0x37, 0xd1, 0x77, 0xc4, 0x36, 0x0f, 0x00, 0x00,
+func init() { proto.RegisterFile("provider.proto", fileDescriptor_provider_0bb3ac224532c65f) }
+
+var fileDescriptor_provider_0bb3ac224532c65f = []byte{
+	// 1338 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x58, 0xcd, 0x72, 0x1b, 0x45,
+	0x10, 0xf6, 0x6a, 0x65, 0xd9, 0xdb, 0xfa, 0x89, 0x32, 0x01, 0x5b, 0xde, 0xf8, 0xe0, 0x5a, 0x38,
+	0x18, 0xa8, 0xc8, 0x29, 0x87, 0x2a, 0x48, 0x2a, 0xa9, 0xc4, 0xb6, 0xe4, 0xe0, 0x8a, 0xe3, 0x98,
+	0x4d, 0xc2, 0xcf, 0x29, 0x6c, 0xb4, 0x23, 0x79, 0xca, 0xd2, 0xee, 0x32, 0x3b, 0x6b, 0xca, 0x1c,
+	0x38, 0x71, 0xe0, 0x15, 0x78, 0x08, 0x8a, 0x2a, 0x9e, 0x80, 0x3b, 0x2f, 0xc0, 0x85, 0x47, 0xe0,
+	0x1d, 0xa8, 0xf9, 0x5b, 0xcd, 0x5a, 0xb2, 0x23, 0xbb, 0x02, 0xdc, 0xa6, 0xa7, 0x7b, 0xa6, 0xbb,
+	0xbf, 0xe9, 0xf9, 0x7a, 0x76, 0xa1, 0x91, 0xd0, 0xf8, 0x84, 0x84, 0x98, 0xb6, 0x13, 0x1a, 0xb3,
+	0x18, 0x39, 0x49, 0x36, 0xcc, 0x46, 0x84, 0x26, 0x3d, 0xb7, 0x96, 0x0c, 0xb3, 0x01, 0x89, 0xa4,
+	0xc2, 0xbd, 0x39, 0x88, 0xe3, 0xc1, 0x10, 0x6f, 0x08, 0xe9, 0x75, 0xd6, 0xdf, 0xc0, 0xa3, 0x84,
+	0x9d, 0x2a, 0xe5, 0xea, 0x59, 0x65, 0xca, 0x68, 0xd6, 0x63, 0x52, 0xeb, 0xfd, 0x6d, 0x41, 0x73,
+	0x27, 0x8e, 0xfa, 0x64, 0x90, 0x51, 0xec, 0xe3, 0x6f, 0x33, 0x9c, 0x32, 0xf4, 0x19, 0x38, 0x27,
+	0x01, 0x25, 0xc1, 0xeb, 0x21, 0x4e, 0x5b, 0xd6, 0x9a, 0xbd, 0x5e, 0xdd, 0xfc, 0xb0, 0x9d, 0x3b,
+	0x6f, 0x9f, 0xb5, 0x6f, 0x7f, 0xa1, 0x8d, 0xbb, 0x11, 0xa3, 0xa7, 0xfe, 0x78, 0x31, 0xfa, 0x08,
+	0xca, 0x01, 0x1d, 0xa4, 0xad, 0xd2, 0x9a, 0xb5, 0x5e, 0xdd, 0x5c, 0x6e, 0xcb, 0x58, 0xda, 0x3a,
+	0x96, 0xf6, 0x73, 0x11, 0x8b, 0x2f, 0x8c, 0xd0, 0xfb, 0x50, 0x0f, 0x7a, 0x3d, 0x9c, 0xb0, 0xe7,
+	0xb8, 0x47, 0x31, 0x4b, 0x5b, 0xf6, 0x9a, 0xb5, 0xbe, 0xe8, 0x17, 0x27, 0xdd, 0xfb, 0xd0, 0x28,
+	0xfa, 0x43, 0x4d, 0xb0, 0x8f, 0xf1, 0x69, 0xcb, 0x5a, 0xb3, 0xd6, 0x1d, 0x9f, 0x0f, 0xd1, 0x3b,
+	0x30, 0x7f, 0x12, 0x0c, 0x33, 0x2c, 0xfc, 0x3a, 0xbe, 0x14, 0xee, 0x95, 0x3e, 0xb5, 0xbc, 0xbb,
+	0x70, 0xdd, 0x08, 0x3f, 0x4d, 0xe2, 0x28, 0xc5, 0x93, 0x8e, 0xad, 0x29, 0x8e, 0xbd, 0xdf, 0x2c,
+	0x58, 0xc9, 0xd7, 0x76, 0x29, 0x8d, 0xe9, 0x53, 0x92, 0xa6, 0x24, 0x1a, 0x3c, 0xc1, 0xa7, 0x29,
+	0xfa, 0x1c, 0xaa, 0xa3, 0xb1, 0xa8, 0x50, 0xdb, 0x98, 0x86, 0xda, 0xd9, 0xa5, 0xed, 0xf1, 0xd8,
+	0x37, 0xf7, 0x70, 0xb7, 0x01, 0xc6, 0x2a, 0x84, 0xa0, 0x1c, 0x05, 0x23, 0xac, 0xd2, 0x14, 0x63,
+	0xb4, 0x06, 0xd5, 0x10, 0xa7, 0x3d, 0x4a, 0x12, 0x46, 0xe2, 0x48, 0x65, 0x6b, 0x4e, 0x79, 0x3f,
+	0x5a, 0x50, 0xdf, 0x8b, 0x4e, 0xe2, 0xe3, 0xfc, 0x70, 0x9b, 0x60, 0xb3, 0xf8, 0x58, 0xa3, 0xc5,
+	0xe2, 0xe3, 0xcb, 0x1d, 0x92, 0x0b, 0x8b, 0xba, 0x2c, 0xc5, 0xf9, 0x38, 0x7e, 0x2e, 0xa3, 0x16,
+	0x2c, 0x9c, 0x60, 0x9a, 0xf2, 0x50, 0xca, 0x42, 0xa5, 0x45, 0xef, 0x04, 0x1a, 0x3a, 0x0a, 0x85,
+	0xf9, 0x06, 0x54, 0x28, 0x66, 0x19, 0x8d, 0x5a, 0xd6, 0xc5, 0x6e, 0x95, 0x19, 0xba, 0x03, 0x8b,
+	0xfd, 0x80, 0x0c, 0x33, 0x8a, 0x79, 0xa4, 0xb6, 0x58, 0x62, 0xa0, 0x7b, 0x84, 0x7b, 0xc7, 0xbb,
+	0x52, 0xef, 0xe7, 0x86, 0xde, 0xf7, 0x50, 0x13, 0x1a, 0x23, 0x79, 0xed, 0xd2, 0xf1, 0xf9, 0x90,
+	0x27, 0x1f, 0x0f, 0xc3, 0x37, 0x27, 0xcf, 0x8d, 0xb8, 0x71, 0x84, 0xbf, 0x93, 0x85, 0x79, 0x91,
+	0x31, 0x37, 0xf2, 0x32, 0xa8, 0x2b, 0xdf, 0xe3, 0x94, 0x49, 0x94, 0x64, 0xaa, 0xbe, 0x2e, 0x4a,
+	0x59, 0x9a, 0x5d, 0x2d, 0xe5, 0x6d, 0xa8, 0x99, 0x1a, 0x75, 0x60, 0x09, 0xa6, 0x4c, 0x5f, 0x91,
+	0x5c, 0x46, 0x4b, 0xfc, 0x10, 0x82, 0x34, 0x2f, 0x1d, 0x25, 0x79, 0xbf, 0x5a, 0x50, 0xed, 0x90,
+	0x7e, 0x5f, 0xc3, 0xd6, 0x80, 0x12, 0x09, 0xd5, 0xea, 0x12, 0x09, 0x35, 0x8c, 0xa5, 0x49, 0x18,
+	0xed, 0xcb, 0xc0, 0x58, 0x9e, 0x01, 0x46, 0x7e, 0x39, 0xc9, 0x20, 0x8a, 0x29, 0xde, 0x39, 0x0a,
+	0xa2, 0x01, 0x4e, 0x5b, 0xf3, 0x6b, 0xf6, 0xba, 0xe3, 0x17, 0x27, 0xbd, 0xdf, 0x2d, 0xa8, 0x1d,
+	0xaa, 0xb4, 0x78, 0xe4, 0xe8, 0x36, 0x94, 0x8f, 0x49, 0x24, 0x83, 0x6e, 0x6c, 0xae, 0x1a, 0xb8,
+	0x99, 0x66, 0xed, 0x27, 0x24, 0x0a, 0x7d, 0x61, 0x89, 0x56, 0xc1, 0x11, 0xb8, 0xf3, 0x79, 0x91,
+	0xda, 0xa2, 0x3f, 0x9e, 0xf0, 0xbe, 0x81, 0x32, 0xb7, 0x45, 0x0b, 0x60, 0x6f, 0x75, 0x3a, 0xcd,
+	0x39, 0x74, 0x0d, 0xaa, 0x5b, 0x9d, 0xce, 0x2b, 0xbf, 0x7b, 0xb8, 0xbf, 0xb5, 0xd3, 0x6d, 0x5a,
+	0x08, 0xa0, 0xd2, 0xe9, 0xee, 0x77, 0x5f, 0x74, 0x9b, 0x25, 0x84, 0xa0, 0x21, 0xc7, 0xb9, 0xde,
+	0xe6, 0xfa, 0x97, 0x87, 0x9d, 0xad, 0x17, 0xdd, 0x66, 0x99, 0xeb, 0xe5, 0x38, 0xd7, 0xcf, 0x7b,
+	0x7f, 0xd9, 0x50, 0x93, 0xa0, 0xab, 0x7a, 0x71, 0x61, 0x91, 0xe2, 0x64, 0x18, 0xf4, 0x14, 0x0b,
+	0x3b, 0x7e, 0x2e, 0xf3, 0xab, 0x96, 0x32, 0x49, 0xd0, 0x25, 0xa1, 0xd2, 0x22, 0xba, 0x0d, 0x37,
+	0x42, 0x3c, 0xc4, 0x0c, 0x6f, 0xe3, 0x7e, 0xcc, 0x49, 0x4e, 0xac, 0x50, 0x5c, 0x3a, 0x4d, 0x85,
+	0x1e, 0xc0, 0x42, 0x4f, 0x61, 0x5b, 0x16, 0x68, 0xbd, 0x67, 0xa0, 0x65, 0x46, 0x24, 0x04, 0x85,
+	0xb8, 0xaf, 0xd7, 0x70, 0xb2, 0x0d, 0x49, 0xbf, 0xaf, 0x0f, 0x46, 0x0a, 0xe8, 0x29, 0xd4, 0x42,
+	0xcc, 0x02, 0x32, 0xc4, 0xa1, 0x00, 0xb4, 0x22, 0xea, 0xf7, 0x83, 0x73, 0x77, 0x36, 0x6c, 0x65,
+	0x17, 0x29, 0x2c, 0x47, 0xeb, 0x70, 0xed, 0x28, 0x48, 0x4d, 0xab, 0xd6, 0x82, 0xc8, 0xe8, 0xec,
+	0xb4, 0xfb, 0x15, 0x5c, 0x9f, 0xd8, 0x6c, 0x4a, 0x8b, 0xb8, 0x65, 0xb6, 0x88, 0xe2, 0xc5, 0x32,
+	0x0b, 0xc4, 0xec, 0x1d, 0x0f, 0xa0, 0x6a, 0x00, 0x80, 0x9a, 0x50, 0xeb, 0xec, 0xed, 0xee, 0xbe,
+	0x7a, 0x79, 0xf0, 0xe4, 0xe0, 0xd9, 0x97, 0x07, 0xcd, 0x39, 0x54, 0x07, 0x47, 0xcc, 0x1c, 0x3c,
+	0x3b, 0xe0, 0x05, 0xa1, 0xc5, 0xe7, 0xcf, 0x9e, 0x76, 0x9b, 0x25, 0x8f, 0x41, 0x7d, 0x87, 0xe2,
+	0x80, 0xe1, 0xf3, 0xc9, 0xe8, 0x13, 0x00, 0x75, 0x37, 0x09, 0x7e, 0x23, 0x25, 0x19, 0xa6, 0xbc,
+	0x1c, 0x18, 0x19, 0xe1, 0x38, 0x63, 0xe2, 0xa0, 0x2d, 0x5f, 0x8b, 0xde, 0xd7, 0xd0, 0xd0, 0x5e,
+	0x55, 0x59, 0x9d, 0xbd, 0xcc, 0x57, 0x75, 0xea, 0xfd, 0x6c, 0x41, 0xd5, 0xc7, 0x41, 0x38, 0x3b,
+	0x4b, 0x14, 0x5d, 0xd9, 0xb3, 0xe7, 0x37, 0xa6, 0xce, 0xf2, 0x4c, 0xd4, 0xe9, 0xfd, 0x64, 0x41,
+	0x4d, 0xc6, 0xf6, 0x96, 0xb3, 0x36, 0x42, 0xb1, 0x67, 0x0b, 0xe5, 0x0f, 0x0b, 0xea, 0x2f, 0x93,
+	0xd0, 0x38, 0xf8, 0xff, 0x93, 0x4e, 0x8d, 0x4a, 0x99, 0x2f, 0x54, 0xca, 0x24, 0xd1, 0x56, 0xa6,
+	0x11, 0xed, 0x1e, 0x34, 0x74, 0x32, 0x0a, 0xd9, 0x22, 0x92, 0xd6, 0xec, 0xf5, 0xc3, 0xdf, 0x26,
+	0x1d, 0xc1, 0x47, 0xff, 0x41, 0x05, 0x19, 0x79, 0x97, 0x8b, 0x37, 0xe4, 0x17, 0x0b, 0x96, 0xc5,
+	0x9b, 0xcc, 0xc7, 0x69, 0x9c, 0xd1, 0x1e, 0xde, 0x8b, 0x08, 0xdb, 0x15, 0x04, 0xf2, 0xf6, 0xaa,
+	0xa6, 0x05, 0x0b, 0xb2, 0xb7, 0xf2, 0xa0, 0x05, 0x5f, 0x2b, 0xf1, 0xf2, 0xa5, 0xfd, 0x03, 0x34,
+	0x1e, 0x63, 0xb6, 0x1f, 0x0f, 0x52, 0x0d, 0xdb, 0xc7, 0xe0, 0x50, 0x15, 0xbb, 0x7e, 0x79, 0x2e,
+	0x19, 0x7c, 0xb6, 0x1f, 0x0f, 0x74, 0x6a, 0xfe, 0xd8, 0x90, 0xf7, 0xbb, 0x94, 0x05, 0x94, 0xbd,
+	0x20, 0x23, 0xc9, 0x82, 0xb6, 0x3f, 0x9e, 0xe0, 0x01, 0xe3, 0x28, 0x14, 0x3a, 0x5b, 0xe8, 0xb4,
+	0xe8, 0x1d, 0x41, 0xd5, 0xd8, 0xf1, 0x5f, 0x3c, 0x33, 0xef, 0x11, 0x5c, 0xcb, 0x33, 0x55, 0xc5,
+	0x76, 0x8b, 0x87, 0xc5, 0x28, 0xc9, 0x13, 0xbd, 0x51, 0x4c, 0x54, 0xf6, 0x0e, 0x6d, 0xe3, 0x85,
+	0xb0, 0xa8, 0x27, 0xa7, 0xd0, 0xad, 0x0c, 0xbd, 0x94, 0x87, 0xbe, 0x0a, 0x0e, 0x2f, 0x8a, 0x94,
+	0x05, 0xa3, 0x44, 0x65, 0x3d, 0x9e, 0xe0, 0x88, 0x8c, 0x70, 0x9a, 0x06, 0x03, 0xac, 0x5f, 0xb7,
+	0x4a, 0xdc, 0xfc, 0xb3, 0x02, 0x4d, 0x8d, 0xc7, 0xa1, 0x7e, 0x0c, 0x6f, 0x43, 0x55, 0xbc, 0xc3,
+	0xe4, 0xbb, 0x1f, 0x4d, 0xbc, 0xdc, 0xd4, 0xe1, 0xb9, 0xad, 0x49, 0x85, 0xcc, 0xd5, 0x9b, 0x43,
+	0x0f, 0x01, 0x44, 0xc7, 0x91, 0x5b, 0x2c, 0x4d, 0x34, 0x4f, 0xb9, 0xc3, 0xf2, 0x39, 0x4d, 0xd5,
+	0x9b, 0xe3, 0x5f, 0x72, 0xf9, 0x77, 0x07, 0xba, 0x79, 0xc1, 0x37, 0x9c, 0xbb, 0x3a, 0x5d, 0x69,
+	0x84, 0x52, 0x91, 0x2f, 0x78, 0x64, 0x06, 0x5c, 0xf8, 0xb4, 0x70, 0x57, 0xa6, 0x68, 0xf2, 0x0d,
+	0xee, 0xc3, 0xbc, 0x48, 0xef, 0x6a, 0x48, 0xdc, 0x85, 0xb2, 0x78, 0x07, 0x5c, 0x01, 0x83, 0x87,
+	0x50, 0x91, 0x1d, 0xb0, 0x10, 0x79, 0xa1, 0x15, 0xbb, 0x2b, 0x53, 0x34, 0xa6, 0x6f, 0xde, 0x4a,
+	0x0a, 0xbe, 0x8d, 0xbe, 0xe7, 0x2e, 0x4f, 0xcc, 0x9b, 0xbe, 0x25, 0x5b, 0x16, 0x7c, 0x17, 0xba,
+	0x81, 0xbb, 0x32, 0x45, 0x63, 0xa0, 0x56, 0x91, 0x14, 0x59, 0xd8, 0xa0, 0xc0, 0x9a, 0xee, 0xd2,
+	0xc4, 0x5d, 0xea, 0xf2, 0xef, 0x7f, 0x6f, 0x0e, 0x6d, 0xc3, 0x82, 0xba, 0x40, 0xc8, 0xf4, 0x52,
+	0xa4, 0x0f, 0xd7, 0x9d, 0xa6, 0xca, 0x23, 0xb8, 0x07, 0x95, 0x9d, 0x20, 0xea, 0xe1, 0x21, 0x3a,
+	0xc7, 0xcf, 0x05, 0xfe, 0x1f, 0x41, 0xfd, 0x31, 0x66, 0x87, 0xe2, 0x5f, 0xc5, 0x5e, 0xd4, 0x8f,
+	0xcf, 0xdd, 0xe2, 0x5d, 0xf3, 0xf9, 0x95, 0x9b, 0x7b, 0x73, 0xaf, 0x2b, 0xc2, 0xf0, 0xce, 0x3f,
+	0x03, 0x00, 0x0e, 0xac, 0x1f, 0xd4, 0x0c, 0x11, 0x00, 0x00,
 }
@@ -38,7 +38,7 @@ func (m *SupportsFeatureRequest) Reset()         { *m = SupportsFeatureRequest{}
 func (m *SupportsFeatureRequest) String() string { return proto.CompactTextString(m) }
 func (*SupportsFeatureRequest) ProtoMessage()    {}
 func (*SupportsFeatureRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_resource_0e7314448ddfd7ea, []int{0}
+	return fileDescriptor_resource_643712172d035551, []int{0}
 }
 func (m *SupportsFeatureRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SupportsFeatureRequest.Unmarshal(m, b)
@@ -76,7 +76,7 @@ func (m *SupportsFeatureResponse) Reset()         { *m = SupportsFeatureResponse
 func (m *SupportsFeatureResponse) String() string { return proto.CompactTextString(m) }
 func (*SupportsFeatureResponse) ProtoMessage()    {}
 func (*SupportsFeatureResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_resource_0e7314448ddfd7ea, []int{1}
+	return fileDescriptor_resource_643712172d035551, []int{1}
 }
 func (m *SupportsFeatureResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SupportsFeatureResponse.Unmarshal(m, b)
@@ -125,7 +125,7 @@ func (m *ReadResourceRequest) Reset()         { *m = ReadResourceRequest{} }
 func (m *ReadResourceRequest) String() string { return proto.CompactTextString(m) }
 func (*ReadResourceRequest) ProtoMessage()    {}
 func (*ReadResourceRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_resource_0e7314448ddfd7ea, []int{2}
+	return fileDescriptor_resource_643712172d035551, []int{2}
 }
 func (m *ReadResourceRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ReadResourceRequest.Unmarshal(m, b)
@@ -235,7 +235,7 @@ func (m *ReadResourceResponse) Reset()         { *m = ReadResourceResponse{} }
 func (m *ReadResourceResponse) String() string { return proto.CompactTextString(m) }
 func (*ReadResourceResponse) ProtoMessage()    {}
 func (*ReadResourceResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_resource_0e7314448ddfd7ea, []int{3}
+	return fileDescriptor_resource_643712172d035551, []int{3}
 }
 func (m *ReadResourceResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ReadResourceResponse.Unmarshal(m, b)
@@ -289,6 +289,8 @@ type RegisterResourceRequest struct {
 	ImportId                   string                                                   `protobuf:"bytes,16,opt,name=importId" json:"importId,omitempty"`
 	CustomTimeouts             *RegisterResourceRequest_CustomTimeouts                  `protobuf:"bytes,17,opt,name=customTimeouts" json:"customTimeouts,omitempty"`
 	DeleteBeforeReplaceDefined bool                                                     `protobuf:"varint,18,opt,name=deleteBeforeReplaceDefined" json:"deleteBeforeReplaceDefined,omitempty"`
+	RetainOnDelete             bool                                                     `protobuf:"varint,19,opt,name=retainOnDelete" json:"retainOnDelete,omitempty"`
+	Locked                     bool                                                     `protobuf:"varint,20,opt,name=locked" json:"locked,omitempty"`
 	XXX_NoUnkeyedLiteral       struct{}                                                 `json:"-"`
 	XXX_unrecognized           []byte                                                   `json:"-"`
 	XXX_sizecache              int32                                                    `json:"-"`
@@ -298,7 +300,7 @@ func (m *RegisterResourceRequest) Reset()         { *m = RegisterResourceRequest
 func (m *RegisterResourceRequest) String() string { return proto.CompactTextString(m) }
 func (*RegisterResourceRequest) ProtoMessage()    {}
 func (*RegisterResourceRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_resource_0e7314448ddfd7ea, []int{4}
+	return fileDescriptor_resource_643712172d035551, []int{4}
 }
 func (m *RegisterResourceRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_RegisterResourceRequest.Unmarshal(m, b)
@@ -444,6 +446,20 @@ func (m *RegisterResourceRequest) GetDeleteBeforeReplaceDefined() bool {
 	return false
 }
 
+func (m *RegisterResourceRequest) GetRetainOnDelete() bool {
+	if m != nil {
+		return m.RetainOnDelete
+	}
+	return false
+}
+
+func (m *RegisterResourceRequest) GetLocked() bool {
+	if m != nil {
+		return m.Locked
+	}
+	return false
+}
+
 // PropertyDependencies describes the resources that a particular property depends on.
 type RegisterResourceRequest_PropertyDependencies struct {
 	Urns                 []string `protobuf:"bytes,1,rep,name=urns" json:"urns,omitempty"`
@@ -460,7 +476,7 @@ func (m *RegisterResourceRequest_PropertyDependencies) String() string {
 }
 func (*RegisterResourceRequest_PropertyDependencies) ProtoMessage() {}
 func (*RegisterResourceRequest_PropertyDependencies) Descriptor() ([]byte, []int) {
-	return fileDescriptor_resource_0e7314448ddfd7ea, []int{4, 0}
+	return fileDescriptor_resource_643712172d035551, []int{4, 0}
 }
 func (m *RegisterResourceRequest_PropertyDependencies) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_RegisterResourceRequest_PropertyDependencies.Unmarshal(m, b)
@@ -503,7 +519,7 @@ func (m *RegisterResourceRequest_CustomTimeouts) Reset() {
 func (m *RegisterResourceRequest_CustomTimeouts) String() string { return proto.CompactTextString(m) }
 func (*RegisterResourceRequest_CustomTimeouts) ProtoMessage()    {}
 func (*RegisterResourceRequest_CustomTimeouts) Descriptor() ([]byte, []int) {
-	return fileDescriptor_resource_0e7314448ddfd7ea, []int{4, 1}
+	return fileDescriptor_resource_643712172d035551, []int{4, 1}
 }
 func (m *RegisterResourceRequest_CustomTimeouts) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_RegisterResourceRequest_CustomTimeouts.Unmarshal(m, b)
@@ -561,7 +577,7 @@ func (m *RegisterResourceResponse) Reset()         { *m = RegisterResourceRespon
 func (m *RegisterResourceResponse) String() string { return proto.CompactTextString(m) }
 func (*RegisterResourceResponse) ProtoMessage()    {}
 func (*RegisterResourceResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_resource_0e7314448ddfd7ea, []int{5}
+	return fileDescriptor_resource_643712172d035551, []int{5}
 }
 func (m *RegisterResourceResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_RegisterResourceResponse.Unmarshal(m, b)
@@ -629,7 +645,7 @@ func (m *RegisterResourceOutputsRequest) Reset()         { *m = RegisterResource
 func (m *RegisterResourceOutputsRequest) String() string { return proto.CompactTextString(m) }
 func (*RegisterResourceOutputsRequest) ProtoMessage()    {}
 func (*RegisterResourceOutputsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_resource_0e7314448ddfd7ea, []int{6}
+	return fileDescriptor_resource_643712172d035551, []int{6}
 }
 func (m *RegisterResourceOutputsRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_RegisterResourceOutputsRequest.Unmarshal(m, b)
@@ -880,61 +896,63 @@ var _ResourceMonitor_serviceDesc = grpc.ServiceDesc{
 	Metadata: "resource.proto",
 }
 
-func init() { proto.RegisterFile("resource.proto", fileDescriptor_resource_0e7314448ddfd7ea) }
-
-var fileDescriptor_resource_0e7314448ddfd7ea = []byte{
-	// 844 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x9c, 0x56, 0xcd, 0x72, 0xe3, 0x44,
-	0x10, 0x5e, 0xdb, 0xbb, 0xfe, 0x69, 0x67, 0x9d, 0x30, 0x9b, 0x8a, 0xb5, 0x82, 0x0a, 0x8b, 0xe0,
-	0xb0, 0x70, 0x70, 0xd8, 0x70, 0xd8, 0x85, 0xa2, 0xa0, 0x8a, 0x24, 0x54, 0xe5, 0x90, 0x02, 0x14,
-	0x0e, 0x40, 0x15, 0x54, 0xc9, 0x52, 0xc7, 0x11, 0x91, 0x35, 0xc3, 0x68, 0xe4, 0x2a, 0xdf, 0x78,
-	0x0e, 0x2e, 0xbc, 0x0a, 0xcf, 0xc4, 0x13, 0x30, 0x3f, 0x1a, 0x63, 0xfd, 0xf8, 0x07, 0x4e, 0x9e,
-	0xfe, 0xba, 0xa7, 0x35, 0xf3, 0xf5, 0xd7, 0x3d, 0x86, 0x11, 0xc7, 0x8c, 0xe6, 0x3c, 0xc4, 0x09,
-	0xe3, 0x54, 0x50, 0x32, 0x60, 0x79, 0x92, 0xcf, 0x63, 0xce, 0x42, 0xf7, 0xed, 0x19, 0xa5, 0xb3,
-	0x04, 0xcf, 0xb4, 0x63, 0x9a, 0xdf, 0x9d, 0xe1, 0x9c, 0x89, 0xa5, 0x89, 0x73, 0xdf, 0xa9, 0x3a,
-	0x33, 0xc1, 0xf3, 0x50, 0x14, 0xde, 0x91, 0xfc, 0x59, 0xc4, 0x11, 0x72, 0x63, 0x7b, 0x2f, 0xe1,
-	0xe4, 0x36, 0x67, 0x8c, 0x72, 0x91, 0x7d, 0x8d, 0x81, 0xc8, 0x39, 0xfa, 0xf8, 0x5b, 0x8e, 0x99,
-	0x20, 0x23, 0x68, 0xc7, 0x91, 0xd3, 0x7a, 0xd1, 0x7a, 0x39, 0xf0, 0xe5, 0xca, 0xfb, 0x14, 0xc6,
-	0xb5, 0xc8, 0x8c, 0xd1, 0x34, 0x43, 0x72, 0x0a, 0x70, 0x1f, 0x64, 0x85, 0x57, 0x6f, 0xe9, 0xfb,
-	0x6b, 0x88, 0xf7, 0x77, 0x1b, 0x9e, 0xf9, 0x18, 0x44, 0x7e, 0x71, 0xa3, 0x0d, 0x9f, 0x20, 0x04,
-	0x1e, 0x8b, 0x25, 0x43, 0xa7, 0xad, 0x11, 0xbd, 0x56, 0x58, 0x1a, 0xcc, 0xd1, 0xe9, 0x18, 0x4c,
-	0xad, 0xc9, 0x09, 0x74, 0x59, 0xc0, 0x31, 0x15, 0xce, 0x63, 0x8d, 0x16, 0x16, 0x79, 0x0d, 0x20,
-	0x6f, 0xc5, 0x90, 0x8b, 0x18, 0x33, 0xe7, 0x89, 0xf4, 0x0d, 0xcf, 0xc7, 0x13, 0xc3, 0xc7, 0xc4,
-	0xf2, 0x31, 0xb9, 0xd5, 0x7c, 0xf8, 0x6b, 0xa1, 0xc4, 0x83, 0x83, 0x08, 0x19, 0xa6, 0x11, 0xa6,
-	0xa1, 0xda, 0xda, 0x7d, 0xd1, 0x91, 0x69, 0x4b, 0x18, 0x71, 0xa1, 0x6f, 0xb9, 0x73, 0x7a, 0xfa,
-	0xb3, 0x2b, 0x9b, 0x38, 0xd0, 0x5b, 0x20, 0xcf, 0x62, 0x9a, 0x3a, 0x7d, 0xed, 0xb2, 0x26, 0xf9,
-	0x00, 0x9e, 0x06, 0x61, 0x88, 0x4c, 0xdc, 0x62, 0xc8, 0x51, 0x64, 0xce, 0x40, 0xb3, 0x53, 0x06,
-	0xc9, 0x1b, 0x18, 0x07, 0x51, 0x14, 0x0b, 0xb9, 0x23, 0x48, 0x0c, 0xf8, 0x4d, 0x2e, 0x58, 0x2e,
-	0xe3, 0x41, 0x1f, 0x65, 0x93, 0x5b, 0x7d, 0x39, 0x48, 0xe2, 0x20, 0x93, 0x87, 0x1e, 0xea, 0x48,
-	0x6b, 0x7a, 0x01, 0x1c, 0x97, 0x39, 0x2f, 0x8a, 0x75, 0x04, 0x9d, 0x9c, 0xa7, 0x05, 0xeb, 0x6a,
-	0x59, 0xa1, 0xad, 0xbd, 0x37, 0x6d, 0xde, 0x1f, 0x7d, 0x18, 0xfb, 0x38, 0x8b, 0x33, 0x81, 0xbc,
-	0x5a, 0x5b, 0x5b, 0xcb, 0x56, 0x43, 0x2d, 0xdb, 0x8d, 0xb5, 0xec, 0x94, 0x6a, 0x29, 0xf1, 0x30,
-	0xcf, 0x04, 0x9d, 0xeb, 0x1a, 0xf7, 0xfd, 0xc2, 0x22, 0x67, 0xd0, 0xa5, 0xd3, 0x5f, 0x31, 0x14,
-	0xbb, 0xea, 0x5b, 0x84, 0x29, 0x86, 0x94, 0x4b, 0xed, 0xe8, 0xea, 0x4c, 0xd6, 0xac, 0x55, 0xbd,
-	0xb7, 0xa3, 0xea, 0xfd, 0x4a, 0xd5, 0x19, 0x1c, 0x17, 0x64, 0x2c, 0x2f, 0xd7, 0xf3, 0x0c, 0x64,
-	0x9e, 0xe1, 0xf9, 0xe7, 0x93, 0x55, 0xc3, 0x4e, 0x36, 0x90, 0x34, 0xf9, 0xb6, 0x61, 0xfb, 0x55,
-	0x2a, 0xf8, 0xd2, 0x6f, 0xcc, 0x4c, 0x3e, 0x86, 0x67, 0x11, 0x26, 0x28, 0xf0, 0x2b, 0xbc, 0xa3,
-	0xaa, 0x01, 0x59, 0x12, 0x84, 0x28, 0x35, 0xa2, 0xee, 0xd5, 0xe4, 0x5a, 0x57, 0xe6, 0xb0, 0xa6,
-	0xcc, 0x78, 0x96, 0xca, 0xd0, 0x8b, 0xfb, 0x20, 0x9d, 0xc9, 0x63, 0x1f, 0xe8, 0xeb, 0x97, 0xc1,
-	0xba, 0x7e, 0x9f, 0xfe, 0x47, 0xfd, 0x8e, 0xf6, 0xd6, 0xef, 0x61, 0x49, 0xbf, 0x8a, 0xf9, 0x78,
-	0xae, 0xc6, 0xc7, 0x75, 0xe4, 0x1c, 0x19, 0xe6, 0xad, 0x4d, 0x7e, 0x84, 0x91, 0x91, 0xc3, 0xf7,
-	0xf1, 0x1c, 0xa9, 0xfa, 0xcc, 0x5b, 0x5a, 0x0c, 0xaf, 0xf6, 0xe0, 0xfc, 0xa2, 0xb4, 0xd1, 0xaf,
-	0x24, 0x22, 0x5f, 0x80, 0xdb, 0xc0, 0xe3, 0x25, 0xde, 0xc5, 0x29, 0x46, 0x0e, 0xd1, 0xb7, 0xdf,
-	0x12, 0xe1, 0x7e, 0x04, 0xc7, 0x4d, 0x55, 0x55, 0xda, 0x97, 0xbd, 0x96, 0xc9, 0x7e, 0x50, 0xb7,
-	0xd4, 0x6b, 0xf7, 0x07, 0x18, 0x95, 0x4f, 0xa3, 0x55, 0xcf, 0xe5, 0x74, 0xb5, 0x7d, 0x53, 0x58,
-	0x0a, 0xcf, 0x59, 0xa4, 0x70, 0xd3, 0x3b, 0x85, 0xa5, 0x70, 0x73, 0x16, 0xdb, 0x3d, 0xc6, 0x72,
-	0x7f, 0x6f, 0xc1, 0xf3, 0x8d, 0xe2, 0x52, 0x23, 0xe0, 0x01, 0x97, 0x76, 0x04, 0xc8, 0x25, 0xb9,
-	0x81, 0x27, 0x8b, 0x20, 0xc9, 0xb1, 0xe8, 0xfe, 0xd7, 0xff, 0x53, 0xbb, 0xbe, 0xc9, 0xf2, 0x59,
-	0xfb, 0x4d, 0xcb, 0xfb, 0xb3, 0x05, 0x4e, 0x7d, 0xef, 0xc6, 0x21, 0x64, 0xde, 0x82, 0xf6, 0xea,
-	0x2d, 0xf8, 0xb7, 0xcf, 0x3b, 0xfb, 0xf5, 0xb9, 0xa4, 0x22, 0x13, 0xc1, 0x34, 0x41, 0x3b, 0x30,
-	0x8c, 0xa5, 0x14, 0x66, 0x56, 0xea, 0x45, 0xd0, 0x0a, 0x2b, 0x4c, 0x0f, 0xe1, 0xb4, 0x7a, 0xc0,
-	0x42, 0x96, 0x76, 0x88, 0xd5, 0x8f, 0xf9, 0x0a, 0x7a, 0xb4, 0x50, 0xf6, 0x8e, 0x41, 0x69, 0xe3,
-	0xce, 0xff, 0xea, 0xc0, 0xa1, 0xcd, 0x7f, 0x43, 0xd3, 0x58, 0x50, 0x4e, 0x7e, 0x82, 0xc3, 0xca,
-	0x63, 0x4a, 0xde, 0x5b, 0xe3, 0xbc, 0xf9, 0x49, 0x76, 0xbd, 0x6d, 0x21, 0x86, 0x59, 0xef, 0x11,
-	0xf9, 0x12, 0xba, 0xd7, 0xe9, 0x82, 0x3e, 0xc8, 0xab, 0xaf, 0xc5, 0x1b, 0xc8, 0x66, 0x7a, 0xde,
-	0xe0, 0x59, 0x25, 0xf8, 0x0e, 0x0e, 0xd6, 0x5f, 0x0e, 0x72, 0x5a, 0x52, 0x43, 0xed, 0x19, 0x77,
-	0xdf, 0xdd, 0xe8, 0x5f, 0xa5, 0xfc, 0x19, 0x8e, 0xaa, 0x54, 0x13, 0x6f, 0xb7, 0xc8, 0xdc, 0xf7,
-	0xb7, 0xc6, 0xac, 0xd2, 0xff, 0x52, 0x7f, 0x87, 0xec, 0x80, 0xf9, 0x70, 0x4b, 0x86, 0x72, 0xb5,
-	0xdd, 0x93, 0x5a, 0x29, 0xaf, 0xd4, 0xff, 0x2a, 0xef, 0xd1, 0xb4, 0xab, 0x91, 0x4f, 0xfe, 0x09,
-	0x00, 0x00, 0xff, 0xff, 0xee, 0xb7, 0xd0, 0x4b, 0x94, 0x09, 0x00, 0x00,
+func init() { proto.RegisterFile("resource.proto", fileDescriptor_resource_643712172d035551) }
+
+var fileDescriptor_resource_643712172d035551 = []byte{
+	// 871 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x56, 0xdd, 0x6e, 0x23, 0x35,
+	0x14, 0xde, 0x24, 0xbb, 0x69, 0x72, 0xda, 0x4d, 0x8b, 0x5b, 0x25, 0xde, 0x01, 0x95, 0x32, 0x20,
+	0x14, 0xb8, 0x48, 0xd9, 0x72, 0xb1, 0x0b, 0x42, 0x20, 0xb1, 0x5d, 0xa4, 0xbd, 0x58, 0x2d, 0x4c,
+	0xb9, 0x00, 0x24, 0x90, 0xdc, 0x99, 0xd3, 0x74, 0xe8, 0xc4, 0x36, 0xb6, 0xa7, 0x52, 0xee, 0x78,
+	0x13, 0x5e, 0x85, 0xe7, 0xe0, 0x31, 0x78, 0x02, 0x64, 0x7b, 0x26, 0x64, 0x7e, 0xd2, 0x06, 0xee,
+	0x7c, 0x7e, 0x7c, 0x3c, 0xfe, 0xbe, 0xef, 0x1c, 0x0f, 0x8c, 0x14, 0x6a, 0x91, 0xab, 0x18, 0x67,
+	0x52, 0x09, 0x23, 0xc8, 0x50, 0xe6, 0x59, 0xbe, 0x48, 0x95, 0x8c, 0x83, 0xb7, 0xe7, 0x42, 0xcc,
+	0x33, 0x3c, 0x75, 0x81, 0xcb, 0xfc, 0xea, 0x14, 0x17, 0xd2, 0x2c, 0x7d, 0x5e, 0xf0, 0x4e, 0x3d,
+	0xa8, 0x8d, 0xca, 0x63, 0x53, 0x44, 0x47, 0x52, 0x89, 0xdb, 0x34, 0x41, 0xe5, 0xed, 0x70, 0x0a,
+	0xe3, 0x8b, 0x5c, 0x4a, 0xa1, 0x8c, 0xfe, 0x06, 0x99, 0xc9, 0x15, 0x46, 0xf8, 0x5b, 0x8e, 0xda,
+	0x90, 0x11, 0x74, 0xd3, 0x84, 0x76, 0x4e, 0x3a, 0xd3, 0x61, 0xd4, 0x4d, 0x93, 0xf0, 0x33, 0x98,
+	0x34, 0x32, 0xb5, 0x14, 0x5c, 0x23, 0x39, 0x06, 0xb8, 0x66, 0xba, 0x88, 0xba, 0x2d, 0x83, 0x68,
+	0xcd, 0x13, 0xfe, 0xdd, 0x85, 0xc3, 0x08, 0x59, 0x12, 0x15, 0x37, 0xda, 0x70, 0x04, 0x21, 0xf0,
+	0xd0, 0x2c, 0x25, 0xd2, 0xae, 0xf3, 0xb8, 0xb5, 0xf5, 0x71, 0xb6, 0x40, 0xda, 0xf3, 0x3e, 0xbb,
+	0x26, 0x63, 0xe8, 0x4b, 0xa6, 0x90, 0x1b, 0xfa, 0xd0, 0x79, 0x0b, 0x8b, 0x3c, 0x03, 0x90, 0x4a,
+	0x48, 0x54, 0x26, 0x45, 0x4d, 0x1f, 0x9d, 0x74, 0xa6, 0xbb, 0x67, 0x93, 0x99, 0xc7, 0x63, 0x56,
+	0xe2, 0x31, 0xbb, 0x70, 0x78, 0x44, 0x6b, 0xa9, 0x24, 0x84, 0xbd, 0x04, 0x25, 0xf2, 0x04, 0x79,
+	0x6c, 0xb7, 0xf6, 0x4f, 0x7a, 0xd3, 0x61, 0x54, 0xf1, 0x91, 0x00, 0x06, 0x25, 0x76, 0x74, 0xc7,
+	0x1d, 0xbb, 0xb2, 0x09, 0x85, 0x9d, 0x5b, 0x54, 0x3a, 0x15, 0x9c, 0x0e, 0x5c, 0xa8, 0x34, 0xc9,
+	0x07, 0xf0, 0x98, 0xc5, 0x31, 0x4a, 0x73, 0x81, 0xb1, 0x42, 0xa3, 0xe9, 0xd0, 0xa1, 0x53, 0x75,
+	0x92, 0xe7, 0x30, 0x61, 0x49, 0x92, 0x9a, 0x54, 0x70, 0x96, 0x79, 0xe7, 0x9b, 0xdc, 0xc8, 0xdc,
+	0x68, 0x0a, 0xee, 0x53, 0x36, 0x85, 0xed, 0xc9, 0x2c, 0x4b, 0x99, 0x46, 0x4d, 0x77, 0x5d, 0x66,
+	0x69, 0x86, 0x0c, 0x8e, 0xaa, 0x98, 0x17, 0x64, 0x1d, 0x40, 0x2f, 0x57, 0xbc, 0x40, 0xdd, 0x2e,
+	0x6b, 0xb0, 0x75, 0xb7, 0x86, 0x2d, 0xfc, 0x6b, 0x00, 0x93, 0x08, 0xe7, 0xa9, 0x36, 0xa8, 0xea,
+	0xdc, 0x96, 0x5c, 0x76, 0x5a, 0xb8, 0xec, 0xb6, 0x72, 0xd9, 0xab, 0x70, 0x39, 0x86, 0x7e, 0x9c,
+	0x6b, 0x23, 0x16, 0x8e, 0xe3, 0x41, 0x54, 0x58, 0xe4, 0x14, 0xfa, 0xe2, 0xf2, 0x57, 0x8c, 0xcd,
+	0x7d, 0xfc, 0x16, 0x69, 0x16, 0x21, 0x1b, 0xb2, 0x3b, 0xfa, 0xae, 0x52, 0x69, 0x36, 0x58, 0xdf,
+	0xb9, 0x87, 0xf5, 0x41, 0x8d, 0x75, 0x09, 0x47, 0x05, 0x18, 0xcb, 0xf3, 0xf5, 0x3a, 0xc3, 0x93,
+	0xde, 0x74, 0xf7, 0xec, 0x8b, 0xd9, 0xaa, 0x61, 0x67, 0x1b, 0x40, 0x9a, 0x7d, 0xdb, 0xb2, 0xfd,
+	0x25, 0x37, 0x6a, 0x19, 0xb5, 0x56, 0x26, 0x9f, 0xc0, 0x61, 0x82, 0x19, 0x1a, 0xfc, 0x1a, 0xaf,
+	0x84, 0xc2, 0x08, 0x65, 0xc6, 0x62, 0xa4, 0xe0, 0xee, 0xd5, 0x16, 0x5a, 0x57, 0xe6, 0x6e, 0x43,
+	0x99, 0xe9, 0x9c, 0x0b, 0x85, 0x2f, 0xae, 0x19, 0x9f, 0xa3, 0xa6, 0x7b, 0xee, 0xfa, 0x55, 0x67,
+	0x53, 0xbf, 0x8f, 0xff, 0xa3, 0x7e, 0x47, 0x5b, 0xeb, 0x77, 0xbf, 0xa2, 0x5f, 0x8b, 0x7c, 0xba,
+	0x90, 0x42, 0x99, 0x57, 0x09, 0x3d, 0xf0, 0xc8, 0x97, 0x36, 0xf9, 0x11, 0x46, 0x5e, 0x0e, 0xdf,
+	0xa7, 0x0b, 0x14, 0xf6, 0x98, 0xb7, 0x9c, 0x18, 0x9e, 0x6e, 0x81, 0xf9, 0x8b, 0xca, 0xc6, 0xa8,
+	0x56, 0x88, 0x7c, 0x09, 0x41, 0x0b, 0x8e, 0xe7, 0x78, 0x95, 0x72, 0x4c, 0x28, 0x71, 0xb7, 0xbf,
+	0x23, 0x83, 0x7c, 0x68, 0x07, 0xb7, 0x61, 0x29, 0x7f, 0xc3, 0xcf, 0x5d, 0x16, 0x3d, 0x74, 0x7b,
+	0x6a, 0x5e, 0xab, 0xef, 0x4c, 0xc4, 0x37, 0x98, 0xd0, 0x23, 0xaf, 0x6f, 0x6f, 0x05, 0x1f, 0xc3,
+	0x51, 0x9b, 0x2a, 0x6c, 0xef, 0xe4, 0x8a, 0x6b, 0xda, 0x71, 0x28, 0xb9, 0x75, 0xf0, 0x03, 0x8c,
+	0xaa, 0xb7, 0x71, 0x5d, 0xa3, 0x90, 0x99, 0xb2, 0xef, 0x0a, 0xcb, 0xfa, 0x73, 0x99, 0x30, 0x53,
+	0xf6, 0x5e, 0x61, 0x59, 0xbf, 0xbf, 0x4b, 0xd9, 0x7d, 0xde, 0x0a, 0x7e, 0xef, 0xc0, 0x93, 0x8d,
+	0xe2, 0xb4, 0x23, 0xe4, 0x06, 0x97, 0xe5, 0x08, 0xb9, 0xc1, 0x25, 0x79, 0x0d, 0x8f, 0x6e, 0x59,
+	0x96, 0x63, 0x31, 0x3d, 0x9e, 0xfd, 0x4f, 0xed, 0x47, 0xbe, 0xca, 0xe7, 0xdd, 0xe7, 0x9d, 0xf0,
+	0x8f, 0x0e, 0xd0, 0xe6, 0xde, 0x8d, 0x43, 0xcc, 0xbf, 0x25, 0xdd, 0xd5, 0x5b, 0xf2, 0xef, 0x9c,
+	0xe8, 0x6d, 0x37, 0x27, 0xc6, 0xd0, 0xd7, 0x86, 0x5d, 0x66, 0x58, 0x0e, 0x1c, 0x6f, 0x59, 0x85,
+	0xfa, 0x95, 0x7d, 0x51, 0x9c, 0x42, 0x0b, 0x33, 0x44, 0x38, 0xae, 0x7f, 0x60, 0x21, 0xeb, 0x72,
+	0x08, 0x36, 0x3f, 0xf3, 0x29, 0xec, 0x88, 0xa2, 0x33, 0xee, 0x19, 0xb4, 0x65, 0xde, 0xd9, 0x9f,
+	0x3d, 0xd8, 0x2f, 0xeb, 0xbf, 0x16, 0x3c, 0x35, 0x42, 0x91, 0x9f, 0x60, 0xbf, 0xf6, 0x18, 0x93,
+	0xf7, 0xd6, 0x30, 0x6f, 0x7f, 0xd2, 0x83, 0xf0, 0xae, 0x14, 0x8f, 0x6c, 0xf8, 0x80, 0x7c, 0x05,
+	0xfd, 0x57, 0xfc, 0x56, 0xdc, 0x20, 0xa1, 0x6b, 0xf9, 0xde, 0x55, 0x56, 0x7a, 0xd2, 0x12, 0x59,
+	0x15, 0xf8, 0x0e, 0xf6, 0xd6, 0x5f, 0x1e, 0x72, 0x5c, 0x51, 0x43, 0xe3, 0x37, 0x20, 0x78, 0x77,
+	0x63, 0x7c, 0x55, 0xf2, 0x67, 0x38, 0xa8, 0x43, 0x4d, 0xc2, 0xfb, 0x45, 0x16, 0xbc, 0x7f, 0x67,
+	0xce, 0xaa, 0xfc, 0x2f, 0x30, 0xd9, 0xc0, 0x24, 0xf9, 0xe8, 0x8e, 0x0a, 0x55, 0xb6, 0x83, 0x71,
+	0x83, 0xca, 0x97, 0xf6, 0xbf, 0x2c, 0x7c, 0x70, 0xd9, 0x77, 0x9e, 0x4f, 0xff, 0x19, 0x00, 0x65,
+	0xa8, 0xf2, 0xb3, 0xd4, 0x09, 0x00, 0x00,
 }
@@ -284,6 +284,12 @@ class ResourceOptions:
     property must be removed from the resource's options.
     """
 
+    retain_on_delete: Optional[bool]
+    """
+    If provided and True, deleting this resource only removes it from the stack's state, leaving the
+    cloud resource itself in place.
+    """
+
     # pylint: disable=redefined-builtin
     def __init__(self,
                  parent: Optional['Resource'] = None,
@@ -298,7 +304,8 @@ class ResourceOptions:
                  additional_secret_outputs: Optional[List[str]] = None,
                  id: Optional['Input[str]'] = None,
                  import_: Optional[str] = None,
-                 custom_timeouts: Optional['CustomTimeouts'] = None) -> None:
+                 custom_timeouts: Optional['CustomTimeouts'] = None,
+                 retain_on_delete: Optional[bool] = None) -> None:
         """
         :param Optional[Resource] parent: If provided, the currently-constructing resource should be the child of
                the provided parent resource.
@@ -321,6 +328,8 @@ class ResourceOptions:
                import its state from the cloud resource with the given ID. The inputs to the resource's constructor must align
                with the resource's current state. Once a resource has been imported, the import property must be removed from
                the resource's options.
+        :param Optional[bool] retain_on_delete: If provided and True, deleting this resource only removes it from the
+               stack's state, leaving the cloud resource itself in place.
         """
 
         # Expose 'merge' again this this object, but this time as an instance method.
@@ -340,6 +349,7 @@ class ResourceOptions:
         self.custom_timeouts = custom_timeouts
         self.id = id
         self.import_ = import_
+        self.retain_on_delete = retain_on_delete
 
         if depends_on is not None:
             for dep in depends_on:
@@ -409,6 +419,7 @@ class ResourceOptions:
         dest.custom_timeouts = dest.custom_timeouts if source.custom_timeouts is None else source.custom_timeouts
         dest.id = dest.id if source.id is None else source.id
         dest.import_ = dest.import_ if source.import_ is None else source.import_
+        dest.retain_on_delete = dest.retain_on_delete if source.retain_on_delete is None else source.retain_on_delete
 
         # Now, if we are left with a .providers that is just a single key/value pair, then
         # collapse that down into .provider form.
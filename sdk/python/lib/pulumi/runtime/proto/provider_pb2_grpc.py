@@ -67,6 +67,11 @@ class ResourceProviderStub(object):
         request_serializer=provider__pb2.DeleteRequest.SerializeToString,
         response_deserializer=google_dot_protobuf_dot_empty__pb2.Empty.FromString,
         )
+    self.GetLogs = channel.unary_unary(
+        '/pulumirpc.ResourceProvider/GetLogs',
+        request_serializer=provider__pb2.GetLogsRequest.SerializeToString,
+        response_deserializer=provider__pb2.GetLogsResponse.FromString,
+        )
     self.Cancel = channel.unary_unary(
         '/pulumirpc.ResourceProvider/Cancel',
         request_serializer=google_dot_protobuf_dot_empty__pb2.Empty.SerializeToString,
@@ -160,6 +165,14 @@ class ResourceProviderServicer(object):
     context.set_details('Method not implemented!')
     raise NotImplementedError('Method not implemented!')
 
+  def GetLogs(self, request, context):
+    """GetLogs returns the log entries written by the given resources, such as those of the functions or containers they
+    run. Providers whose resources do not write logs need not implement it.
+    """
+    context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+    context.set_details('Method not implemented!')
+    raise NotImplementedError('Method not implemented!')
+
   def Cancel(self, request, context):
     """Cancel signals the provider to abort all outstanding resource operations.
     """
@@ -227,6 +240,11 @@ def add_ResourceProviderServicer_to_server(servicer, server):
           request_deserializer=provider__pb2.DeleteRequest.FromString,
           response_serializer=google_dot_protobuf_dot_empty__pb2.Empty.SerializeToString,
       ),
+      'GetLogs': grpc.unary_unary_rpc_method_handler(
+          servicer.GetLogs,
+          request_deserializer=provider__pb2.GetLogsRequest.FromString,
+          response_serializer=provider__pb2.GetLogsResponse.SerializeToString,
+      ),
       'Cancel': grpc.unary_unary_rpc_method_handler(
           servicer.Cancel,
           request_deserializer=google_dot_protobuf_dot_empty__pb2.Empty.FromString,
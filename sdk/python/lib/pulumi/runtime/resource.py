@@ -360,6 +360,7 @@ def register_resource(res: 'Resource', ty: str, name: str, custom: bool, props:
                 importId=opts.import_,
                 customTimeouts=opts.custom_timeouts,
                 aliases=resolver.aliases,
+                retainOnDelete=opts.retain_on_delete or False,
             )
 
             from ..resource import create_urn